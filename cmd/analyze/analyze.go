@@ -0,0 +1,55 @@
+// analyze.go analyze command code
+package analyze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/analysis"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command creates the analyze command for analyzing a single audio file
+func Command(settings *conf.Settings) *cobra.Command {
+	var trace bool
+
+	cmd := &cobra.Command{
+		Use:   "analyze [input.wav]",
+		Short: "Analyze an audio file, optionally tracing every pipeline decision",
+		Long: "Analyze a single audio file for bird calls and songs. With --trace nothing is written; instead " +
+			"every stage decision is printed: analysis windows, raw model results, thresholds applied, filters " +
+			"and the actions that would fire in realtime mode.",
+		Example: "  birdnet-go analyze --trace recording.wav",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+			defer stop()
+
+			settings.Input.Path = args[0]
+
+			var err error
+			if trace {
+				err = analysis.TraceAnalysis(settings, ctx, os.Stdout)
+			} else {
+				err = analysis.FileAnalysis(settings, ctx)
+			}
+			if errors.Is(err, context.Canceled) || errors.Is(err, analysis.ErrAnalysisCanceled) {
+				fmt.Println("\nAnalysis canceled")
+				return nil
+			}
+			return err
+		},
+	}
+
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	cmd.Flags().BoolVar(&trace, "trace", false, "Print every pipeline decision without writing results")
+
+	return cmd
+}
@@ -0,0 +1,165 @@
+// backfill.go backfill command code
+package backfillcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/backfill"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Command creates the backfill command
+func Command(settings *conf.Settings) *cobra.Command {
+	var statePath string
+	var utc, restart, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "backfill <directory>",
+		Short: "Analyze a directory of historical recordings into the database",
+		Long: "Analyze the WAV, FLAC and MP3 files of a directory tree, such as the SD card of an AudioMoth, and save " +
+			"the detections with the date and time they were recorded. Start times are read from AudioMoth and GUANO " +
+			"metadata, or from file names such as 20250510_061500.WAV. Finished recordings are kept in a state file, " +
+			"so an interrupted backfill resumes where it stopped and detections already in the database are skipped.",
+		Example: "  birdnet-go backfill /media/sdcard --dry-run\n" +
+			"  birdnet-go backfill /media/sdcard --utc --state ~/audiomoth-2025.json",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := args[0]
+			if info, err := os.Stat(root); err != nil || !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", root)
+			}
+
+			loc := time.Local
+			if utc {
+				loc = time.UTC
+			}
+			if dryRun {
+				return listRecordings(root, loc)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+			defer stop()
+
+			return runBackfill(ctx, settings, root, &backfill.Options{
+				StatePath: statePath,
+				Location:  loc,
+				Restart:   restart,
+				Progress:  printProgress,
+			})
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&statePath, "state", "", "Resume state file, defaults to "+backfill.StateFileName+" in the directory")
+	cmd.Flags().BoolVar(&utc, "utc", false, "Read times in file names as UTC instead of local time")
+	cmd.Flags().BoolVar(&restart, "restart", false, "Analyze recordings finished by earlier runs again")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the recordings and their start times without analyzing them")
+
+	return cmd
+}
+
+// runBackfill opens the configured datastore and BirdNET and backfills the directory
+func runBackfill(ctx context.Context, settings *conf.Settings, root string, opts *backfill.Options) error {
+	ds := datastore.New(settings)
+	if ds == nil {
+		return fmt.Errorf("no database configured, enable output.sqlite or output.mysql in the config file")
+	}
+	if err := ds.Open(); err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	defer func() { _ = ds.Close() }()
+
+	store, ok := ds.(backfill.Store)
+	if !ok {
+		return fmt.Errorf("the configured database does not support backfills")
+	}
+
+	bn, err := birdnet.NewBirdNET(settings)
+	if err != nil {
+		return fmt.Errorf("failed to initialize BirdNET: %w", err)
+	}
+	defer bn.Delete()
+
+	summary, err := backfill.Run(ctx, settings, root, bn, store, opts)
+	printSummary(&summary)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Backfill interrupted, run the command again to resume")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("backfill stopped: %w", err)
+	}
+	return nil
+}
+
+// listRecordings prints the recordings of a directory with their start times
+func listRecordings(root string, loc *time.Location) error {
+	recordings, err := backfill.FindRecordings(root, loc)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RECORDING\tSTART (LOCAL)\tFROM")
+	var missing int
+	for i := range recordings {
+		rec := &recordings[i]
+		start, source := "unknown, skipped", "-"
+		if !rec.Start.IsZero() {
+			start, source = rec.Start.Local().Format(time.DateTime), rec.TimeSource
+		} else {
+			missing++
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", rec.Name, start, source)
+	}
+	_ = tw.Flush()
+
+	fmt.Printf("%d recordings, %d without a start time\n", len(recordings), missing)
+	return nil
+}
+
+// printProgress prints a line for each recording the backfill is done with
+func printProgress(p backfill.Progress) {
+	width := len(fmt.Sprint(p.Total))
+	prefix := fmt.Sprintf("[%*d/%d] %s", width, p.Index, p.Total, filepath.ToSlash(p.Recording.Name))
+
+	switch {
+	case p.Err != nil:
+		fmt.Printf("%s: failed: %v\n", prefix, p.Err)
+	case p.Skipped && p.Recording.Start.IsZero():
+		fmt.Printf("%s: skipped, no start time in metadata or file name\n", prefix)
+	case p.Skipped:
+		fmt.Printf("%s: finished by an earlier run\n", prefix)
+	default:
+		line := fmt.Sprintf("%s: %s, %d detections", prefix, p.Recording.Start.Local().Format(time.DateTime), p.Detections)
+		if p.Duplicates > 0 {
+			line += fmt.Sprintf(" (%d already saved)", p.Duplicates)
+		}
+		if p.Remaining > 0 {
+			line += fmt.Sprintf(", %s left", birdnet.FormatDuration(p.Remaining))
+		}
+		fmt.Println(line)
+	}
+}
+
+// printSummary prints the backfill counts
+func printSummary(s *backfill.Summary) {
+	fmt.Printf("Analyzed %d of %d recordings (%s of audio), saved %d detections (%d already saved)\n",
+		s.Analyzed, s.Recordings, s.AudioLength.Round(time.Second), s.Detections, s.Duplicates)
+	if s.Resumed > 0 || s.NoStartTime > 0 || s.Failed > 0 {
+		fmt.Printf("Skipped: %d finished by earlier runs, %d without a start time, %d failed\n",
+			s.Resumed, s.NoStartTime, s.Failed)
+	}
+}
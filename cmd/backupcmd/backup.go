@@ -0,0 +1,202 @@
+// backup.go backup command code
+package backupcmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command creates the backup parent command
+func Command(settings *conf.Settings) *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "List and restore backups stored in the configured backup targets",
+		Long: "Backups are created by the realtime mode on the configured schedule. These commands use " +
+			"the backup.targets of the config file to find stored backups and restore them.",
+	}
+
+	backupCmd.AddCommand(listCommand(settings), restoreCommand(settings))
+
+	return backupCmd
+}
+
+// listCommand creates the backup list subcommand
+func listCommand(settings *conf.Settings) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List backups in all enabled backup targets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := newManager(settings)
+			if err != nil {
+				return err
+			}
+
+			backups, err := manager.ListBackups(cmd.Context())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			if len(backups) == 0 {
+				fmt.Println("No backups found")
+				return nil
+			}
+
+			fmt.Printf("%-32s %-20s %10s  %-9s %s\n", "ID", "CREATED", "SIZE", "ENCRYPTED", "TARGET")
+			for i := range backups {
+				b := &backups[i]
+				encrypted := "no"
+				if b.Encrypted {
+					encrypted = "yes"
+				}
+				fmt.Printf("%-32s %-20s %10s  %-9s %s\n", b.ID, b.Timestamp.Local().Format(time.DateTime),
+					formatSize(b.Size), encrypted, b.Target)
+			}
+			return nil
+		},
+	}
+}
+
+// restoreCommand creates the backup restore subcommand
+func restoreCommand(settings *conf.Settings) *cobra.Command {
+	var output string
+	var replaceDatabase bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <backup-id>",
+		Short: "Download, decrypt and extract a backup",
+		Long: "Download a backup from the first target holding it, decrypt it with the configured encryption key " +
+			"and extract the database copy, metadata and sanitized configuration into the output directory. " +
+			"With --database the configured SQLite database is replaced by the restored copy; stop BirdNET-Go first.",
+		Example: "  birdnet-go backup list\n" +
+			"  birdnet-go backup restore birdnet-20250510-030000 --output ./restored\n" +
+			"  birdnet-go backup restore birdnet-20250510-030000 --database",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			if replaceDatabase && settings.Output.SQLite.Path == "" {
+				return fmt.Errorf("--database requires output.sqlite.path to be set in the config file")
+			}
+			if output == "" {
+				output = "restore-" + id
+			}
+
+			manager, err := newManager(settings)
+			if err != nil {
+				return err
+			}
+
+			result, err := manager.RestoreBackup(cmd.Context(), id, output)
+			if err != nil {
+				return fmt.Errorf("restore failed: %w", err)
+			}
+			fmt.Printf("Restored backup %s from %s target created %s\n", result.Metadata.ID, result.Target,
+				result.Metadata.Timestamp.Local().Format(time.DateTime))
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+
+			if !replaceDatabase {
+				return nil
+			}
+			previous, err := replaceSQLiteDatabase(settings.Output.SQLite.Path, result.DataFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Replaced database %s, the previous database was kept as %s\n", settings.Output.SQLite.Path, previous)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "", "Directory to extract the backup into (default \"restore-<backup-id>\")")
+	cmd.Flags().BoolVar(&replaceDatabase, "database", false, "Replace the configured SQLite database with the restored copy")
+
+	return cmd
+}
+
+// newManager creates a backup manager with the enabled targets of the configuration
+func newManager(settings *conf.Settings) (*backup.Manager, error) {
+	// Only warnings and errors are logged so they do not mix with the command output
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	stateManager, err := backup.NewStateManager(logger)
+	if err != nil {
+		return nil, fmt.Errorf("error opening backup state: %w", err)
+	}
+	manager, err := backup.NewManager(settings, logger, stateManager, settings.Version)
+	if err != nil {
+		return nil, fmt.Errorf("error creating backup manager: %w", err)
+	}
+
+	registered, err := targets.RegisterTargets(manager, &settings.Backup, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if registered == 0 {
+		return nil, fmt.Errorf("no usable backup targets, enable at least one entry of backup.targets in the config file")
+	}
+	return manager, nil
+}
+
+// replaceSQLiteDatabase moves the database at dbPath and its WAL files aside and copies the
+// restored database in its place. It returns the path the previous database was moved to.
+func replaceSQLiteDatabase(dbPath, restoredPath string) (string, error) {
+	suffix := ".pre-restore-" + time.Now().Format("20060102-150405")
+	previous := dbPath + suffix
+
+	// Leftover WAL files would be applied to the restored database, move them with it
+	for _, ext := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(dbPath+ext, previous+ext); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("error moving %s aside: %w", dbPath+ext, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return "", fmt.Errorf("error creating database directory: %w", err)
+	}
+	if err := copyFile(restoredPath, dbPath); err != nil {
+		return "", fmt.Errorf("error copying restored database: %w", err)
+	}
+	return previous, nil
+}
+
+// copyFile copies src to a new file at dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// formatSize returns a byte count in human readable units
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
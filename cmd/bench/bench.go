@@ -0,0 +1,183 @@
+// bench.go analysis pipeline benchmark command code
+package bench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime/pprof"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
+)
+
+// Command creates the bench command
+func Command(settings *conf.Settings) *cobra.Command {
+	var duration time.Duration
+	var sources int
+	var cpuProfile string
+
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure the latency of each analysis pipeline stage on this system",
+		Long: "Run synthetic bird song through the analysis pipeline with the configured model, threads and " +
+			"inference workers, and report the latency of each stage. Several sources can be simulated to " +
+			"check whether the system keeps up with them in real time. Action execution needs a running node, " +
+			"its latencies are reported by GET /api/v2/debug/pipeline.",
+		Example: "  birdnet-go bench\n" +
+			"  birdnet-go bench --sources 4 --duration 1m --cpuprofile bench.pprof",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sources < 1 {
+				return fmt.Errorf("--sources must be at least 1")
+			}
+			if duration <= 0 {
+				return fmt.Errorf("--duration must be positive")
+			}
+			return runBench(settings, duration, sources, cpuProfile)
+		},
+	}
+
+	benchCmd.Flags().DurationVarP(&duration, "duration", "d", 30*time.Second, "How long to run the benchmark")
+	benchCmd.Flags().IntVarP(&sources, "sources", "s", 1, "Number of audio sources analyzed in parallel")
+	benchCmd.Flags().StringVar(&cpuProfile, "cpuprofile", "", "Write a pprof CPU profile of the benchmark to this file")
+
+	return benchCmd
+}
+
+// runBench analyzes synthetic chunks of sources in parallel for duration and prints the
+// stage latencies
+func runBench(settings *conf.Settings, duration time.Duration, sources int, cpuProfile string) error {
+	bn, err := birdnet.NewBirdNET(settings)
+	if err != nil {
+		return fmt.Errorf("failed to initialize BirdNET: %w", err)
+	}
+	defer bn.Delete()
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			return fmt.Errorf("error creating CPU profile: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("error starting CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	chunk := syntheticChunk()
+	tracker := pipeline.NewTracker()
+
+	fmt.Printf("⏳ Analyzing %d source(s) for %v...\n", sources, duration)
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, sources)
+	for range sources {
+		wg.Go(func() {
+			for time.Since(start) < duration {
+				if err := analyzeChunk(bn, settings, chunk, tracker); err != nil {
+					errs <- err
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	printReport(tracker.Snapshot(), settings, sources, elapsed)
+	return nil
+}
+
+// analyzeChunk runs a chunk through the stages that run without a live node, recording
+// their latencies: conversion of the captured audio into an analysis segment, inference and
+// filtering of the results
+func analyzeChunk(bn *birdnet.BirdNET, settings *conf.Settings, chunk []byte, tracker *pipeline.Tracker) error {
+	stageStart := time.Now()
+	sample, err := myaudio.ConvertToFloat32(chunk, conf.BitDepth)
+	if err != nil {
+		return fmt.Errorf("error converting audio: %w", err)
+	}
+	tracker.Record(pipeline.StageSegment, time.Since(stageStart))
+
+	stageStart = time.Now()
+	results, err := bn.Predict(sample)
+	if conf.BitDepth == 16 && len(sample[0]) == myaudio.Float32BufferSize {
+		myaudio.ReturnFloat32Buffer(sample[0])
+	}
+	if err != nil {
+		return fmt.Errorf("prediction failed: %w", err)
+	}
+	tracker.Record(pipeline.StageInference, time.Since(stageStart))
+
+	stageStart = time.Now()
+	for _, result := range results {
+		if float64(result.Confidence) < settings.BirdNET.Threshold {
+			continue
+		}
+		bn.EnrichResultWithTaxonomy(result.Species)
+	}
+	tracker.Record(pipeline.StagePostProcessing, time.Since(stageStart))
+	return nil
+}
+
+// syntheticChunk returns an analysis chunk of 16-bit PCM, the size read from the analysis
+// buffer, with a song sweeping between 2 and 6 kHz over background noise
+func syntheticChunk() []byte {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // G404: deterministic benchmark audio
+	samples := conf.SampleRate * conf.CaptureLength
+	pcm := make([]byte, conf.BufferSize)
+	var phase float64
+	for i := range samples {
+		phase += 2 * math.Pi * (4000 + 2000*math.Sin(float64(i)/6000)) / conf.SampleRate
+		value := 6000*math.Sin(phase) + 200*rng.NormFloat64()
+		sample := int16(max(math.MinInt16, min(math.MaxInt16, value)))
+		pcm[i*2] = byte(sample)
+		pcm[i*2+1] = byte(sample >> 8)
+	}
+	return pcm
+}
+
+// printReport prints the stage latencies and whether the system keeps up with the sources
+func printReport(report pipeline.Report, settings *conf.Settings, sources int, elapsed time.Duration) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "\nStage\tCount\tMean\tp50\tp95\tp99\tMax")
+	var chunks int64
+	for _, stage := range report.Stages {
+		if stage.Count == 0 {
+			continue
+		}
+		if stage.Stage == pipeline.StageInference {
+			chunks = stage.Count
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%.1f ms\t%.1f ms\t%.1f ms\t%.1f ms\t%.1f ms\n", stage.Stage, stage.Count,
+			stage.MeanMs, stage.P50Ms, stage.P95Ms, stage.P99Ms, stage.MaxMs)
+	}
+	_, _ = fmt.Fprintf(w, "%s\t-\tmeasured on a running node, GET /api/v2/debug/pipeline\n", pipeline.StageActions)
+	_ = w.Flush()
+
+	// Each source produces a chunk every hop, the system keeps up if it analyzes them faster
+	hop := conf.AnalysisHopDuration(settings.BirdNET.Overlap)
+	required := float64(sources) / hop.Seconds()
+	achieved := float64(chunks) / elapsed.Seconds()
+	fmt.Printf("\nThroughput: %.2f chunks/s, %d source(s) with %.1f s overlap need %.2f chunks/s\n",
+		achieved, sources, settings.BirdNET.Overlap, required)
+	if achieved >= required {
+		fmt.Printf("✅ System keeps up with %d source(s) in real time (%.0f%% of capacity used)\n",
+			sources, required/achieved*100)
+	} else {
+		fmt.Printf("❌ System is too slow for %d source(s), chunks will be dropped and detections missed\n", sources)
+		fmt.Println("   Consider a lower overlap, more threads or birdnet.scheduler.workers, or fewer sources")
+	}
+}
@@ -0,0 +1,178 @@
+// import.go import command code
+package importcmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/importer"
+)
+
+// importFlags holds the flags shared by the import subcommands
+type importFlags struct {
+	minConfidence float64
+	dryRun        bool
+}
+
+// addFlags registers the shared flags on cmd
+func (f *importFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64Var(&f.minConfidence, "min-confidence", 0, "Skip detections below this confidence, between 0.0 and 1.0")
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "Report what would be imported without writing anything")
+}
+
+// options validates the flags and returns the import options
+func (f *importFlags) options(settings *conf.Settings) (*importer.Options, error) {
+	if f.minConfidence < 0 || f.minConfidence > 1 {
+		return nil, fmt.Errorf("--min-confidence must be between 0.0 and 1.0")
+	}
+	return &importer.Options{
+		NodeName:      settings.Main.Name,
+		MinConfidence: f.minConfidence,
+		ClipsDest:     settings.Realtime.Audio.Export.Path,
+		DryRun:        f.dryRun,
+	}, nil
+}
+
+// Command creates the import parent command
+func Command(settings *conf.Settings) *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import detections from BirdNET-Pi or BirdNET-Analyzer",
+		Long: "Migrate detections into the configured database. Detections that already exist with the same " +
+			"date, time and species are skipped, so an import can be repeated safely. Confidence values are kept as recorded.",
+	}
+
+	importCmd.AddCommand(birdnetPiCommand(settings), analyzerCommand(settings))
+
+	return importCmd
+}
+
+// birdnetPiCommand creates the import birdnet-pi subcommand
+func birdnetPiCommand(settings *conf.Settings) *cobra.Command {
+	var flags importFlags
+	var clipsDir string
+
+	cmd := &cobra.Command{
+		Use:   "birdnet-pi <birds.db>",
+		Short: "Import detections and clips from a BirdNET-Pi database",
+		Example: "  birdnet-go import birdnet-pi ~/BirdNET-Pi/scripts/birds.db --clips ~/BirdSongs/Extracted\n" +
+			"  birdnet-go import birdnet-pi birds.db --dry-run",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.options(settings)
+			if err != nil {
+				return err
+			}
+			opts.ClipsDir = clipsDir
+			if clipsDir != "" && opts.ClipsDest == "" {
+				return fmt.Errorf("--clips requires realtime.audio.export.path to be set in the config file")
+			}
+
+			return runImport(settings, opts, func(store importer.Store) (importer.Summary, error) {
+				return importer.ImportBirdNETPi(args[0], store, opts)
+			})
+		},
+	}
+
+	flags.addFlags(cmd)
+	cmd.Flags().StringVar(&clipsDir, "clips", "", "BirdNET-Pi Extracted directory to copy clips from, e.g. ~/BirdSongs/Extracted")
+
+	return cmd
+}
+
+// analyzerCommand creates the import analyzer subcommand
+func analyzerCommand(settings *conf.Settings) *cobra.Command {
+	var flags importFlags
+	var start string
+
+	cmd := &cobra.Command{
+		Use:   "analyzer <results.csv>...",
+		Short: "Import detections from BirdNET-Analyzer CSV results",
+		Long: "Import BirdNET-Analyzer results written with --rtype csv. Detection times are offsets into the " +
+			"recording, so the recording start is read from file names such as 20250510_061500.wav unless --start is set.",
+		Example: "  birdnet-go import analyzer 20250510_061500.BirdNET.results.csv\n" +
+			"  birdnet-go import analyzer results.csv --start 2025-05-10T06:15:00",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.options(settings)
+			if err != nil {
+				return err
+			}
+			var recordingStart time.Time
+			if start != "" {
+				if recordingStart, err = time.ParseInLocation("2006-01-02T15:04:05", start, time.Local); err != nil {
+					return fmt.Errorf("invalid --start %q, expected YYYY-MM-DDTHH:MM:SS", start)
+				}
+			}
+
+			return runImport(settings, opts, func(store importer.Store) (importer.Summary, error) {
+				var total importer.Summary
+				for _, path := range args {
+					summary, err := importer.ImportAnalyzerCSV(path, recordingStart, store, opts)
+					total = addSummary(total, summary)
+					if err != nil {
+						return total, err
+					}
+					fmt.Printf("%s: %d imported, %d duplicates, %d skipped\n", path, summary.Imported, summary.Duplicates, summary.Skipped)
+				}
+				return total, nil
+			})
+		},
+	}
+
+	flags.addFlags(cmd)
+	cmd.Flags().StringVar(&start, "start", "", "Local start time of the recording (YYYY-MM-DDTHH:MM:SS), overrides file names")
+
+	return cmd
+}
+
+// runImport opens the configured datastore, runs the import and prints its summary
+func runImport(settings *conf.Settings, opts *importer.Options, run func(importer.Store) (importer.Summary, error)) error {
+	ds := datastore.New(settings)
+	if ds == nil {
+		return fmt.Errorf("no database configured, enable output.sqlite or output.mysql in the config file")
+	}
+	if err := ds.Open(); err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	defer func() { _ = ds.Close() }()
+
+	store, ok := ds.(importer.Store)
+	if !ok {
+		return fmt.Errorf("the configured database does not support imports")
+	}
+
+	summary, err := run(store)
+	printSummary(&summary, opts.DryRun)
+	if err != nil {
+		return fmt.Errorf("import stopped: %w", err)
+	}
+	return nil
+}
+
+// printSummary prints the import counts
+func printSummary(s *importer.Summary, dryRun bool) {
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d detections (%d duplicates, %d skipped)\n", verb, s.Imported, s.Read, s.Duplicates, s.Skipped)
+	if s.ClipsCopied > 0 || s.ClipsMissing > 0 {
+		fmt.Printf("Clips: %d copied, %d not found\n", s.ClipsCopied, s.ClipsMissing)
+	}
+}
+
+// addSummary returns the sum of two summaries
+func addSummary(a, b importer.Summary) importer.Summary {
+	return importer.Summary{
+		Read:         a.Read + b.Read,
+		Imported:     a.Imported + b.Imported,
+		Duplicates:   a.Duplicates + b.Duplicates,
+		Skipped:      a.Skipped + b.Skipped,
+		ClipsCopied:  a.ClipsCopied + b.ClipsCopied,
+		ClipsMissing: a.ClipsMissing + b.ClipsMissing,
+	}
+}
@@ -0,0 +1,338 @@
+// detections.go query detections and export command code
+package query
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatJSON  = "json"
+)
+
+// pageSize is the number of detections fetched per database query
+const pageSize = 200
+
+// detectionFilter holds the filter flags shared by the detections and export subcommands
+type detectionFilter struct {
+	dates         dateRange
+	species       string
+	minConfidence float64
+}
+
+// addFlags registers the filter flags on cmd
+func (f *detectionFilter) addFlags(cmd *cobra.Command, defaultDays int) {
+	f.dates.addFlags(cmd, defaultDays)
+	cmd.Flags().StringVar(&f.species, "species", "", "Only include species whose common or scientific name contains this text")
+	cmd.Flags().Float64Var(&f.minConfidence, "min-confidence", 0, "Minimum confidence between 0.0 and 1.0")
+}
+
+// searchFilters converts the flags to datastore search filters
+func (f *detectionFilter) searchFilters(ctx context.Context, sortBy string) (*datastore.SearchFilters, error) {
+	from, to, err := f.dates.resolve(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if f.minConfidence < 0 || f.minConfidence > 1 {
+		return nil, fmt.Errorf("--min-confidence must be between 0.0 and 1.0")
+	}
+
+	return &datastore.SearchFilters{
+		Species:       f.species,
+		DateStart:     from,
+		DateEnd:       to,
+		ConfidenceMin: f.minConfidence,
+		SortBy:        sortBy,
+		PerPage:       pageSize,
+		Ctx:           ctx,
+	}, nil
+}
+
+// detectionsCommand creates the query detections subcommand
+func detectionsCommand(settings *conf.Settings) *cobra.Command {
+	var filter detectionFilter
+	var format string
+	var limit int
+
+	detectionsCmd := &cobra.Command{
+		Use:   "detections",
+		Short: "List detections in a date range, newest first",
+		Example: "  birdnet-go query detections --days 1\n" +
+			"  birdnet-go query detections --species \"great tit\" --min-confidence 0.8 --format json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filters, err := filter.searchFilters(cmd.Context(), "")
+			if err != nil {
+				return err
+			}
+			if limit < 0 {
+				return fmt.Errorf("--limit must not be negative")
+			}
+			return runDetections(settings, filters, format, limit, os.Stdout)
+		},
+	}
+
+	filter.addFlags(detectionsCmd, 1)
+	detectionsCmd.Flags().StringVarP(&format, "format", "f", formatTable, "Output format: table, csv or json")
+	detectionsCmd.Flags().IntVarP(&limit, "limit", "n", 50, "Maximum number of detections to list, 0 for all")
+
+	return detectionsCmd
+}
+
+// exportCommand creates the query export subcommand
+func exportCommand(settings *conf.Settings) *cobra.Command {
+	var filter detectionFilter
+	var format, output string
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export detections in a date range as CSV or JSON, oldest first",
+		Example: "  birdnet-go query export --from 2025-01-01 --to 2025-12-31 --output detections-2025.csv\n" +
+			"  birdnet-go query export --days 0 --format json > all.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != formatCSV && format != formatJSON {
+				return fmt.Errorf("invalid format %q, must be csv or json", format)
+			}
+			filters, err := filter.searchFilters(cmd.Context(), "date_asc")
+			if err != nil {
+				return err
+			}
+			return runExport(settings, filters, format, output)
+		},
+	}
+
+	filter.addFlags(exportCmd, 7)
+	exportCmd.Flags().StringVarP(&format, "format", "f", formatCSV, "Output format: csv or json")
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "Output file, defaults to standard output")
+
+	return exportCmd
+}
+
+// runDetections writes up to limit matching detections to w
+func runDetections(settings *conf.Settings, filters *datastore.SearchFilters, format string, limit int, w io.Writer) error {
+	writer, err := newRecordWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	ds, err := openStore(settings)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ds.Close() }()
+
+	count, err := forEachDetection(ds, filters, limit, writer.Write)
+	if err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if count == 0 && format == formatTable {
+		_, _ = fmt.Fprintln(w, "No detections found")
+	}
+	return nil
+}
+
+// runExport writes all matching detections to the output file or standard output
+func runExport(settings *conf.Settings, filters *datastore.SearchFilters, format, output string) error {
+	ds, err := openStore(settings)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ds.Close() }()
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		file, err := os.Create(output) //nolint:gosec // output path is provided by the user running the command
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	writer, err := newRecordWriter(format, w)
+	if err != nil {
+		return err
+	}
+	count, err := forEachDetection(ds, filters, 0, writer.Write)
+	if err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error writing export: %w", err)
+	}
+
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "✅ Exported %d detections to %s\n", count, output)
+	}
+	return nil
+}
+
+// forEachDetection pages through the detections matching filters and calls fn for each,
+// stopping after limit detections when limit is positive. It returns the number of
+// detections passed to fn.
+func forEachDetection(ds datastore.Interface, filters *datastore.SearchFilters, limit int, fn func(*datastore.DetectionRecord) error) (int, error) {
+	count := 0
+	for page := 1; ; page++ {
+		filters.Page = page
+		records, total, err := ds.SearchDetections(filters)
+		if err != nil {
+			return count, fmt.Errorf("error querying detections: %w", err)
+		}
+
+		for i := range records {
+			if err := fn(&records[i]); err != nil {
+				return count, fmt.Errorf("error writing detection: %w", err)
+			}
+			count++
+			if limit > 0 && count >= limit {
+				return count, nil
+			}
+		}
+
+		if len(records) < pageSize || page*pageSize >= total {
+			return count, nil
+		}
+	}
+}
+
+// recordWriter writes detections in an output format
+type recordWriter interface {
+	Write(record *datastore.DetectionRecord) error
+	Close() error
+}
+
+// newRecordWriter returns a writer for format
+func newRecordWriter(format string, w io.Writer) (recordWriter, error) {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "ID\tDATE\tTIME\tCOMMON NAME\tSCIENTIFIC NAME\tCONF\tSOURCE\tVERIFIED")
+		return &tableWriter{tw: tw}, nil
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{cw: cw}, nil
+	case formatJSON:
+		return &jsonWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("invalid format %q, must be table, csv or json", format)
+	}
+}
+
+// tableWriter writes detections as an aligned text table
+type tableWriter struct {
+	tw *tabwriter.Writer
+}
+
+// Write adds a detection row to the table
+func (t *tableWriter) Write(r *datastore.DetectionRecord) error {
+	_, err := fmt.Fprintf(t.tw, "%s\t%s\t%s\t%s\t%s\t%.0f%%\t%s\t%s\n",
+		r.ID, r.Timestamp.Format(time.DateOnly), r.Timestamp.Format(time.TimeOnly),
+		r.CommonName, r.ScientificName, r.Confidence*100, detectionSource(r), valueOrDash(r.Verified))
+	return err
+}
+
+// Close flushes the table
+func (t *tableWriter) Close() error {
+	return t.tw.Flush()
+}
+
+// csvHeader is the header row of CSV output
+var csvHeader = []string{"id", "date", "time", "scientific_name", "common_name", "confidence", "latitude", "longitude", "source", "verified", "locked", "clip"}
+
+// csvWriter writes detections as CSV rows
+type csvWriter struct {
+	cw *csv.Writer
+}
+
+// Write adds a detection row
+func (c *csvWriter) Write(r *datastore.DetectionRecord) error {
+	return c.cw.Write([]string{
+		r.ID,
+		r.Timestamp.Format(time.DateOnly),
+		r.Timestamp.Format(time.TimeOnly),
+		r.ScientificName,
+		r.CommonName,
+		strconv.FormatFloat(r.Confidence, 'f', 4, 64),
+		strconv.FormatFloat(r.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+		detectionSource(r),
+		r.Verified,
+		strconv.FormatBool(r.Locked),
+		r.AudioFilePath,
+	})
+}
+
+// Close flushes buffered rows
+func (c *csvWriter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+// jsonWriter streams detections as a JSON array so large exports are not held in memory
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+// Write appends a detection to the array
+func (j *jsonWriter) Write(r *datastore.DetectionRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if j.count == 0 {
+		prefix = "[\n  "
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+// Close terminates the array
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+// detectionSource returns the audio source of a detection
+func detectionSource(r *datastore.DetectionRecord) string {
+	if r.Source != "" {
+		return r.Source
+	}
+	return valueOrDash(r.Device)
+}
+
+// valueOrDash returns s, or a dash when s is empty
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// pagedStore serves total detections in pages of the requested size. Other datastore
+// methods are not used by the query commands under test and panic if called.
+type pagedStore struct {
+	datastore.Interface
+	total     int // detections matching the filters
+	reported  int // total reported to the caller, defaults to total
+	failPage  int // page returning an error, 0 for none
+	pages     []int
+	emptyFrom int // page from which no records are returned although total says otherwise, 0 for none
+}
+
+func (s *pagedStore) SearchDetections(filters *datastore.SearchFilters) ([]datastore.DetectionRecord, int, error) {
+	s.pages = append(s.pages, filters.Page)
+	if filters.Page == s.failPage {
+		return nil, 0, errors.New("database unavailable")
+	}
+
+	reported := s.total
+	if s.reported > 0 {
+		reported = s.reported
+	}
+	if s.emptyFrom > 0 && filters.Page >= s.emptyFrom {
+		return nil, reported, nil
+	}
+
+	start := min((filters.Page-1)*filters.PerPage, s.total)
+	end := min(start+filters.PerPage, s.total)
+	records := make([]datastore.DetectionRecord, 0, end-start)
+	for i := start; i < end; i++ {
+		records = append(records, datastore.DetectionRecord{ID: strconv.Itoa(i + 1)})
+	}
+	return records, reported, nil
+}
+
+func TestForEachDetectionPaging(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		store     *pagedStore
+		limit     int
+		wantCount int
+		wantPages []int
+		wantErr   bool
+	}{
+		{"no detections", &pagedStore{}, 0, 0, []int{1}, false},
+		{"single partial page", &pagedStore{total: 42}, 0, 42, []int{1}, false},
+		{"exact page multiple stops without an extra query", &pagedStore{total: 2 * pageSize}, 0, 2 * pageSize, []int{1, 2}, false},
+		{"last page partial", &pagedStore{total: 2*pageSize + 1}, 0, 2*pageSize + 1, []int{1, 2, 3}, false},
+		{"limit within first page", &pagedStore{total: 3 * pageSize}, 10, 10, []int{1}, false},
+		{"limit at page boundary", &pagedStore{total: 3 * pageSize}, pageSize, pageSize, []int{1}, false},
+		{"limit beyond total", &pagedStore{total: 5}, 50, 5, []int{1}, false},
+		{"reported total smaller than records", &pagedStore{total: 3 * pageSize, reported: pageSize}, 0, pageSize, []int{1}, false},
+		{"empty page despite larger total", &pagedStore{total: 3 * pageSize, emptyFrom: 2}, 0, pageSize, []int{1, 2}, false},
+		{"query error", &pagedStore{total: 3 * pageSize, failPage: 2}, 0, pageSize, []int{1, 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			filters := &datastore.SearchFilters{PerPage: pageSize}
+			seen := 0
+			count, err := forEachDetection(tt.store, filters, tt.limit, func(*datastore.DetectionRecord) error {
+				seen++
+				return nil
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCount, count)
+			assert.Equal(t, tt.wantCount, seen)
+			assert.Equal(t, tt.wantPages, tt.store.pages)
+		})
+	}
+}
+
+func TestForEachDetectionStopsOnWriteError(t *testing.T) {
+	t.Parallel()
+
+	store := &pagedStore{total: 3 * pageSize}
+	count, err := forEachDetection(store, &datastore.SearchFilters{PerPage: pageSize}, 0, func(r *datastore.DetectionRecord) error {
+		if r.ID == "3" {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error writing detection")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []int{1}, store.pages)
+}
+
+func TestJSONWriterOutputsArray(t *testing.T) {
+	t.Parallel()
+
+	var empty bytes.Buffer
+	writer, err := newRecordWriter(formatJSON, &empty)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	assert.Equal(t, "[]\n", empty.String())
+
+	var out bytes.Buffer
+	writer, err = newRecordWriter(formatJSON, &out)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(&datastore.DetectionRecord{ID: "1"}))
+	require.NoError(t, writer.Write(&datastore.DetectionRecord{ID: "2"}))
+	require.NoError(t, writer.Close())
+
+	var records []datastore.DetectionRecord
+	require.NoError(t, json.Unmarshal(out.Bytes(), &records))
+	require.Len(t, records, 2)
+	assert.Equal(t, "2", records[1].ID)
+}
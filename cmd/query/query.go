@@ -0,0 +1,81 @@
+// query.go query command code
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Command creates the query parent command
+func Command(settings *conf.Settings) *cobra.Command {
+	queryCmd := &cobra.Command{
+		Use:   "query",
+		Short: "Query detections from the configured database",
+		Long: "Inspect detections stored in the configured SQLite or MySQL database without the web interface, " +
+			"e.g. on a headless station over SSH. The database may be queried while realtime analysis is running.",
+	}
+
+	queryCmd.AddCommand(topCommand(settings), detectionsCommand(settings), exportCommand(settings))
+
+	return queryCmd
+}
+
+// openStore opens the datastore configured in settings
+func openStore(settings *conf.Settings) (datastore.Interface, error) {
+	ds := datastore.New(settings)
+	if ds == nil {
+		return nil, fmt.Errorf("no database configured, enable output.sqlite or output.mysql in the config file")
+	}
+	if err := ds.Open(); err != nil {
+		return nil, fmt.Errorf("error opening database: %w", err)
+	}
+	return ds, nil
+}
+
+// dateRange holds the date range flags shared by the query subcommands
+type dateRange struct {
+	from string
+	to   string
+	days int
+}
+
+// addFlags registers the date range flags on cmd
+func (r *dateRange) addFlags(cmd *cobra.Command, defaultDays int) {
+	cmd.Flags().StringVar(&r.from, "from", "", "First date to include (YYYY-MM-DD), defaults to --days before --to")
+	cmd.Flags().StringVar(&r.to, "to", "", "Last date to include (YYYY-MM-DD), defaults to today")
+	cmd.Flags().IntVar(&r.days, "days", defaultDays, "Number of days to include when --from is not set, 0 for all dates")
+}
+
+// resolve returns the validated first and last date of the range. An empty from date
+// means no lower bound.
+func (r *dateRange) resolve(now time.Time) (from, to string, err error) {
+	end := now
+	if r.to != "" {
+		if end, err = time.ParseInLocation(time.DateOnly, r.to, time.Local); err != nil {
+			return "", "", fmt.Errorf("invalid --to date %q, expected YYYY-MM-DD", r.to)
+		}
+	}
+	to = end.Format(time.DateOnly)
+
+	switch {
+	case r.from != "":
+		start, err := time.ParseInLocation(time.DateOnly, r.from, time.Local)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid --from date %q, expected YYYY-MM-DD", r.from)
+		}
+		if start.After(end) {
+			return "", "", fmt.Errorf("--from date %s is after --to date %s", r.from, to)
+		}
+		from = r.from
+	case r.days > 0:
+		from = end.AddDate(0, 0, -(r.days - 1)).Format(time.DateOnly)
+	case r.days < 0:
+		return "", "", fmt.Errorf("--days must not be negative")
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,53 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateRangeResolve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 3, 1, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		dates    dateRange
+		wantFrom string
+		wantTo   string
+		wantErr  string
+	}{
+		{"days back from today", dateRange{days: 7}, "2025-02-23", "2025-03-01", ""},
+		{"one day is today only", dateRange{days: 1}, "2025-03-01", "2025-03-01", ""},
+		{"days back from to date across leap day", dateRange{to: "2024-03-01", days: 2}, "2024-02-29", "2024-03-01", ""},
+		{"zero days has no lower bound", dateRange{days: 0}, "", "2025-03-01", ""},
+		{"zero days with to date", dateRange{to: "2024-12-31"}, "", "2024-12-31", ""},
+		{"from date overrides days", dateRange{from: "2025-01-15", days: 7}, "2025-01-15", "2025-03-01", ""},
+		{"from today", dateRange{from: "2025-03-01"}, "2025-03-01", "2025-03-01", ""},
+		{"from equals to", dateRange{from: "2024-06-01", to: "2024-06-01"}, "2024-06-01", "2024-06-01", ""},
+		{"from after to", dateRange{from: "2024-06-02", to: "2024-06-01"}, "", "", "is after --to date"},
+		{"from in the future", dateRange{from: "2025-03-02"}, "", "", "is after --to date"},
+		{"negative days", dateRange{days: -1}, "", "", "--days must not be negative"},
+		{"negative days ignored with from", dateRange{from: "2025-02-01", days: -1}, "2025-02-01", "2025-03-01", ""},
+		{"invalid to date", dateRange{to: "2025/03/01"}, "", "", "invalid --to date"},
+		{"invalid from date", dateRange{from: "2025-02-30"}, "", "", "invalid --from date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			from, to, err := tt.dates.resolve(now)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, from)
+			assert.Equal(t, tt.wantTo, to)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// top.go query top command code
+package query
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// topCommand creates the query top subcommand
+func topCommand(settings *conf.Settings) *cobra.Command {
+	var dates dateRange
+	var limit int
+
+	topCmd := &cobra.Command{
+		Use:   "top",
+		Short: "List the most detected species",
+		Example: "  birdnet-go query top --days 30\n" +
+			"  birdnet-go query top --from 2025-05-01 --to 2025-05-31 --limit 20",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to, err := dates.resolve(time.Now())
+			if err != nil {
+				return err
+			}
+			if limit < 0 {
+				return fmt.Errorf("--limit must not be negative")
+			}
+			return runTop(settings, from, to, limit)
+		},
+	}
+
+	dates.addFlags(topCmd, 7)
+	topCmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of species to list, 0 for all")
+
+	return topCmd
+}
+
+// runTop prints species ordered by detection count for the date range
+func runTop(settings *conf.Settings, from, to string, limit int) error {
+	ds, err := openStore(settings)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ds.Close() }()
+
+	summary, err := ds.GetSpeciesSummaryData(from, to)
+	if err != nil {
+		return fmt.Errorf("error querying species summary: %w", err)
+	}
+	if limit > 0 && len(summary) > limit {
+		summary = summary[:limit]
+	}
+
+	if len(summary) == 0 {
+		fmt.Println("No detections found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "#\tCOMMON NAME\tSCIENTIFIC NAME\tCOUNT\tMAX CONF\tFIRST SEEN\tLAST SEEN")
+	for i := range summary {
+		s := &summary[i]
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%.0f%%\t%s\t%s\n",
+			i+1, s.CommonName, s.ScientificName, s.Count, s.MaxConfidence*100,
+			formatTime(s.FirstSeen), formatTime(s.LastSeen))
+	}
+	return tw.Flush()
+}
+
+// formatTime formats t for table output, or a dash for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,113 @@
+// rescore.go rescore command code
+package rescore
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Command creates the rescore command
+func Command(settings *conf.Settings) *cobra.Command {
+	var from, to string
+	var dryRun bool
+	var limit int
+
+	rescoreCmd := &cobra.Command{
+		Use:   "rescore",
+		Short: "Re-evaluate stored detections against the current thresholds and species filters",
+		Long: "Re-evaluate stored detections after thresholds or the exclude list were changed. Detections that " +
+			"would no longer be reported are marked as disqualified, detections that qualify again are unmarked. " +
+			"Nothing is deleted, and locked detections or detections verified as correct are left unchanged.",
+		Example: "  birdnet-go rescore --dry-run\n" +
+			"  birdnet-go rescore --from 2025-05-01 --to 2025-05-31",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, date := range []string{from, to} {
+				if date == "" {
+					continue
+				}
+				if _, err := time.Parse(time.DateOnly, date); err != nil {
+					return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+				}
+			}
+			if from != "" && to != "" && from > to {
+				return fmt.Errorf("--from date %s is after --to date %s", from, to)
+			}
+			return runRescore(cmd, settings, from, to, dryRun, limit)
+		},
+	}
+
+	rescoreCmd.Flags().StringVar(&from, "from", "", "First date to re-evaluate (YYYY-MM-DD), defaults to the oldest detection")
+	rescoreCmd.Flags().StringVar(&to, "to", "", "Last date to re-evaluate (YYYY-MM-DD), defaults to the newest detection")
+	rescoreCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the summary without marking detections")
+	rescoreCmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of species listed in the summary, 0 for all")
+
+	return rescoreCmd
+}
+
+// runRescore re-evaluates the detections in the date range and prints a summary
+func runRescore(cmd *cobra.Command, settings *conf.Settings, from, to string, dryRun bool, limit int) error {
+	ds := datastore.New(settings)
+	if ds == nil {
+		return fmt.Errorf("no database configured, enable output.sqlite or output.mysql in the config file")
+	}
+	if err := ds.Open(); err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	defer func() { _ = ds.Close() }()
+
+	rescorer, ok := ds.(datastore.DetectionRescorer)
+	if !ok {
+		return fmt.Errorf("the configured database does not support re-scoring")
+	}
+
+	summary, err := rescorer.RescoreDetections(cmd.Context(), from, to, datastore.RescoreCriteriaFromSettings(settings), dryRun)
+	if err != nil {
+		return fmt.Errorf("error re-scoring detections: %w", err)
+	}
+
+	printSummary(summary, dryRun, limit)
+	return nil
+}
+
+// printSummary prints the statistics of a re-scoring run
+func printSummary(summary *datastore.RescoreSummary, dryRun bool, limit int) {
+	if dryRun {
+		fmt.Println("Dry run, no detections were changed")
+	}
+	fmt.Printf("Evaluated:      %d\n", summary.Evaluated)
+	fmt.Printf("Disqualified:   %d\n", summary.Disqualified)
+	fmt.Printf("Newly marked:   %d\n", summary.NewlyMarked)
+	fmt.Printf("Qualify again:  %d\n", summary.Cleared)
+	fmt.Printf("Skipped:        %d (locked or verified as correct)\n", summary.Skipped)
+
+	for _, reason := range slices.Sorted(maps.Keys(summary.Reasons)) {
+		fmt.Printf("  %-14s %d\n", reason+":", summary.Reasons[reason])
+	}
+
+	if len(summary.Species) == 0 {
+		return
+	}
+	species := slices.SortedFunc(maps.Keys(summary.Species), func(a, b string) int {
+		return cmp.Or(cmp.Compare(summary.Species[b], summary.Species[a]), cmp.Compare(a, b))
+	})
+	if limit > 0 && len(species) > limit {
+		species = species[:limit]
+	}
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "COMMON NAME\tDISQUALIFIED")
+	for _, name := range species {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\n", name, summary.Species[name])
+	}
+	_ = tw.Flush()
+}
@@ -7,14 +7,22 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/tphakala/birdnet-go/cmd/analyze"
 	"github.com/tphakala/birdnet-go/cmd/authors"
+	"github.com/tphakala/birdnet-go/cmd/backfillcmd"
+	"github.com/tphakala/birdnet-go/cmd/backupcmd"
+	"github.com/tphakala/birdnet-go/cmd/bench"
 	"github.com/tphakala/birdnet-go/cmd/benchmark"
 	"github.com/tphakala/birdnet-go/cmd/directory"
 	"github.com/tphakala/birdnet-go/cmd/file"
+	"github.com/tphakala/birdnet-go/cmd/importcmd"
 	"github.com/tphakala/birdnet-go/cmd/license"
+	"github.com/tphakala/birdnet-go/cmd/query"
 	"github.com/tphakala/birdnet-go/cmd/rangefilter"
 	"github.com/tphakala/birdnet-go/cmd/realtime"
+	"github.com/tphakala/birdnet-go/cmd/rescore"
 	"github.com/tphakala/birdnet-go/cmd/support"
+	"github.com/tphakala/birdnet-go/cmd/update"
 	"github.com/tphakala/birdnet-go/internal/conf"
 )
 
@@ -41,6 +49,14 @@ func RootCommand(settings *conf.Settings) *cobra.Command {
 	rangeCmd := rangefilter.Command(settings)
 	supportCmd := support.Command(settings)
 	benchmarkCmd := benchmark.Command(settings)
+	benchCmd := bench.Command(settings)
+	updateCmd := update.Command(settings)
+	queryCmd := query.Command(settings)
+	importCmd := importcmd.Command(settings)
+	backupCmd := backupcmd.Command(settings)
+	rescoreCmd := rescore.Command(settings)
+	analyzeCmd := analyze.Command(settings)
+	backfillCmd := backfillcmd.Command(settings)
 
 	subcommands := []*cobra.Command{
 		fileCmd,
@@ -51,6 +67,14 @@ func RootCommand(settings *conf.Settings) *cobra.Command {
 		rangeCmd,
 		supportCmd,
 		benchmarkCmd,
+		benchCmd,
+		updateCmd,
+		queryCmd,
+		importCmd,
+		backupCmd,
+		rescoreCmd,
+		analyzeCmd,
+		backfillCmd,
 	}
 
 	rootCmd.AddCommand(subcommands...)
@@ -82,7 +106,7 @@ func setupFlags(rootCmd *cobra.Command, settings *conf.Settings) error {
 	rootCmd.PersistentFlags().IntVarP(&settings.BirdNET.Threads, "threads", "j", viper.GetInt("birdnet.threads"), "Number of CPU threads to use for analysis (default 0 which is all CPUs)")
 	rootCmd.PersistentFlags().Float64VarP(&settings.BirdNET.Sensitivity, "sensitivity", "s", viper.GetFloat64("birdnet.sensitivity"), "Sigmoid sensitivity value between 0.0 and 1.5")
 	rootCmd.PersistentFlags().Float64VarP(&settings.BirdNET.Threshold, "threshold", "t", viper.GetFloat64("birdnet.threshold"), "Confidency threshold for detections, value between 0.1 to 1.0")
-	rootCmd.PersistentFlags().Float64Var(&settings.BirdNET.Overlap, "overlap", viper.GetFloat64("birdnet.overlap"), "Overlap value between 0.0 and 2.9")
+	rootCmd.PersistentFlags().Float64Var(&settings.BirdNET.Overlap, "overlap", viper.GetFloat64("birdnet.overlap"), fmt.Sprintf("Overlap value between %.1f and %.2f", conf.OverlapMin, conf.MaxAnalysisOverlap))
 	rootCmd.PersistentFlags().Float64Var(&settings.BirdNET.Latitude, "latitude", viper.GetFloat64("birdnet.latitude"), "Latitude for species prediction")
 	rootCmd.PersistentFlags().Float64Var(&settings.BirdNET.Longitude, "longitude", viper.GetFloat64("birdnet.longitude"), "Longitude for species prediction")
 
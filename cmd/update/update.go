@@ -0,0 +1,73 @@
+// update.go update command code
+package update
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/updater"
+)
+
+// Command creates the update command
+func Command(settings *conf.Settings) *cobra.Command {
+	var checkOnly bool
+
+	updateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the BirdNET-Go binary to the latest release",
+		Long: "Check the release feed for a newer release on the configured channel, verify its signature and " +
+			"replace this binary with it. The previous binary is kept and restored if the update fails its health check.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpdate(cmd.Context(), settings, checkOnly)
+		},
+	}
+
+	updateCmd.Flags().BoolVar(&checkOnly, "check", false, "Only check whether an update is available")
+	updateCmd.Flags().StringVar(&settings.Update.Channel, "channel", settings.Update.Channel, "Release channel, stable or beta")
+
+	return updateCmd
+}
+
+// runUpdate checks for an update and installs it unless checkOnly is set
+func runUpdate(ctx context.Context, settings *conf.Settings, checkOnly bool) error {
+	if settings.Update.Channel != conf.UpdateChannelStable && settings.Update.Channel != conf.UpdateChannelBeta {
+		return fmt.Errorf("invalid channel %q, must be stable or beta", settings.Update.Channel)
+	}
+
+	u, err := updater.New(settings, "")
+	if err != nil {
+		return fmt.Errorf("self-update unavailable: %w", err)
+	}
+
+	result, err := u.Check(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking for updates: %w", err)
+	}
+
+	if !result.Available {
+		fmt.Printf("BirdNET-Go %s is up to date on the %s channel\n", result.CurrentVersion, result.Channel)
+		return nil
+	}
+
+	fmt.Printf("Update available on the %s channel: %s -> %s\n", result.Channel, result.CurrentVersion, result.Latest.Version)
+	if checkOnly {
+		return nil
+	}
+
+	if err := u.Apply(ctx, result.Latest); err != nil {
+		return fmt.Errorf("error installing update: %w", err)
+	}
+	fmt.Printf("✅ Installed BirdNET-Go %s\n", result.Latest.Version)
+
+	if settings.Update.RestartCommand == "" {
+		fmt.Println("Restart the BirdNET-Go service to run the new version")
+		return nil
+	}
+	if err := updater.Restart(settings.Update.RestartCommand); err != nil {
+		return fmt.Errorf("error restarting service: %w", err)
+	}
+	fmt.Println("🔄 Restarting BirdNET-Go service")
+	return nil
+}
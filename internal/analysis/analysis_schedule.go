@@ -0,0 +1,155 @@
+// analysis_schedule.go: pause analysis outside a sunrise/sunset based daily window
+package analysis
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+// scheduleCheckInterval is how often the analysis window is re-evaluated
+const scheduleCheckInterval = time.Minute
+
+// AnalysisSchedule tracks whether the current time is within the configured analysis window
+type AnalysisSchedule struct {
+	settings *conf.AnalysisScheduleSettings
+	sunCalc  *suncalc.SunCalc
+	active   atomic.Bool
+}
+
+// NewAnalysisSchedule creates a schedule for the configured location and window
+func NewAnalysisSchedule(settings *conf.Settings) *AnalysisSchedule {
+	s := &AnalysisSchedule{
+		settings: &settings.Realtime.Schedule,
+		sunCalc:  suncalc.NewSunCalc(settings.BirdNET.Latitude, settings.BirdNET.Longitude),
+	}
+	s.active.Store(true)
+	return s
+}
+
+// Active reports whether analysis is currently allowed
+func (s *AnalysisSchedule) Active() bool {
+	return s.active.Load()
+}
+
+// windowEvents returns the start and end of the window for the configured mode
+func (s *AnalysisSchedule) windowEvents() (start, end conf.SunEventOffset) {
+	switch s.settings.Mode {
+	case conf.ScheduleModeDay:
+		return conf.SunEventOffset{Event: "civildawn"}, conf.SunEventOffset{Event: "civildusk"}
+	case conf.ScheduleModeNight:
+		return conf.SunEventOffset{Event: "civildusk"}, conf.SunEventOffset{Event: "civildawn"}
+	default:
+		return s.settings.Start, s.settings.End
+	}
+}
+
+// Window returns the analysis window start and end times for the day of now. When the
+// end is before the start the window spans midnight.
+func (s *AnalysisSchedule) Window(now time.Time) (start, end time.Time, err error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	times, err := s.sunCalc.GetSunEventTimes(day)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startEvent, endEvent := s.windowEvents()
+	return sunEventTime(times, startEvent), sunEventTime(times, endEvent), nil
+}
+
+// IsWithinWindow reports whether analysis should run at the given time. Analysis stays
+// enabled if the sun events cannot be calculated, e.g. during polar night.
+func (s *AnalysisSchedule) IsWithinWindow(now time.Time) bool {
+	start, end, err := s.Window(now)
+	if err != nil {
+		GetLogger().Warn("Failed to calculate analysis window, analysis stays enabled",
+			"error", err,
+			"operation", "analysis_schedule_window")
+		return true
+	}
+	return withinWindow(now, start, end)
+}
+
+// withinWindow reports whether t is in [start, end), wrapping over midnight when end is before start
+func withinWindow(t, start, end time.Time) bool {
+	if !end.Before(start) {
+		return !t.Before(start) && t.Before(end)
+	}
+	return !t.Before(start) || t.Before(end)
+}
+
+// sunEventTime returns the time of a sun event plus its offset
+func sunEventTime(times suncalc.SunEventTimes, event conf.SunEventOffset) time.Time {
+	var base time.Time
+	switch event.Event {
+	case "civildawn":
+		base = times.CivilDawn
+	case "sunrise":
+		base = times.Sunrise
+	case "sunset":
+		base = times.Sunset
+	default:
+		base = times.CivilDusk
+	}
+	return base.Add(time.Duration(event.Offset) * time.Minute)
+}
+
+// update re-evaluates the window and logs transitions between active and paused
+func (s *AnalysisSchedule) update(now time.Time) {
+	active := s.IsWithinWindow(now)
+	if s.active.Swap(active) == active {
+		return
+	}
+
+	if active {
+		GetLogger().Info("Entering analysis window, resuming analysis",
+			"mode", s.settings.Mode,
+			"operation", "analysis_schedule_resume")
+	} else {
+		GetLogger().Info("Leaving analysis window, pausing analysis",
+			"mode", s.settings.Mode,
+			"operation", "analysis_schedule_pause")
+	}
+}
+
+// Run evaluates the window every minute until quitChan is closed
+func (s *AnalysisSchedule) Run(quitChan chan struct{}) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quitChan:
+			return
+		case now := <-ticker.C:
+			s.update(now)
+		}
+	}
+}
+
+// startAnalysisSchedule gates analysis with the configured window and keeps it updated in a new goroutine.
+func startAnalysisSchedule(wg *sync.WaitGroup, settings *conf.Settings, quitChan chan struct{}) {
+	schedule := NewAnalysisSchedule(settings)
+	schedule.active.Store(schedule.IsWithinWindow(time.Now()))
+	myaudio.SetAnalysisGate(schedule.Active)
+
+	start, end, err := schedule.Window(time.Now())
+	if err == nil {
+		GetLogger().Info("Analysis schedule enabled",
+			"mode", settings.Realtime.Schedule.Mode,
+			"window_start", start.Format("15:04"),
+			"window_end", end.Format("15:04"),
+			"active", schedule.Active(),
+			"operation", "initialize_analysis_schedule")
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer myaudio.SetAnalysisGate(nil)
+		schedule.Run(quitChan)
+	}()
+}
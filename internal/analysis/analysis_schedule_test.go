@@ -0,0 +1,77 @@
+// analysis_schedule_test.go: Tests for the sunrise/sunset based analysis window
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+func TestWithinWindow(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+
+	// Daytime window
+	assert.True(t, withinWindow(at(12), at(5), at(22)))
+	assert.True(t, withinWindow(at(5), at(5), at(22)), "start is inclusive")
+	assert.False(t, withinWindow(at(22), at(5), at(22)), "end is exclusive")
+	assert.False(t, withinWindow(at(3), at(5), at(22)))
+
+	// Night window spanning midnight
+	assert.True(t, withinWindow(at(23), at(22), at(4)))
+	assert.True(t, withinWindow(at(2), at(22), at(4)))
+	assert.False(t, withinWindow(at(12), at(22), at(4)))
+}
+
+func TestSunEventTime(t *testing.T) {
+	base := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	times := suncalc.SunEventTimes{
+		CivilDawn: base.Add(4 * time.Hour),
+		Sunrise:   base.Add(5 * time.Hour),
+		Sunset:    base.Add(21 * time.Hour),
+		CivilDusk: base.Add(22 * time.Hour),
+	}
+
+	assert.Equal(t, base.Add(3*time.Hour), sunEventTime(times, conf.SunEventOffset{Event: "civildawn", Offset: -60}))
+	assert.Equal(t, base.Add(5*time.Hour), sunEventTime(times, conf.SunEventOffset{Event: "sunrise"}))
+	assert.Equal(t, base.Add(21*time.Hour+30*time.Minute), sunEventTime(times, conf.SunEventOffset{Event: "sunset", Offset: 30}))
+	assert.Equal(t, base.Add(24*time.Hour), sunEventTime(times, conf.SunEventOffset{Event: "civildusk", Offset: 120}))
+}
+
+func TestAnalysisScheduleModes(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.BirdNET.Latitude = 60.17
+	settings.BirdNET.Longitude = 24.94
+	settings.Realtime.Schedule = conf.AnalysisScheduleSettings{
+		Enabled: true,
+		Mode:    conf.ScheduleModeCustom,
+		Start:   conf.SunEventOffset{Event: "civildawn", Offset: -60},
+		End:     conf.SunEventOffset{Event: "civildusk", Offset: 120},
+	}
+
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	schedule := NewAnalysisSchedule(settings)
+	assert.True(t, schedule.Active(), "schedule should be active until first evaluated")
+
+	start, end, err := schedule.Window(now)
+	require.NoError(t, err)
+	times, err := schedule.sunCalc.GetSunEventTimes(now)
+	require.NoError(t, err)
+	assert.Equal(t, times.CivilDawn.Add(-time.Hour), start)
+	assert.Equal(t, times.CivilDusk.Add(2*time.Hour), end)
+	assert.True(t, schedule.IsWithinWindow(times.Sunrise))
+
+	settings.Realtime.Schedule.Mode = conf.ScheduleModeNight
+	assert.False(t, schedule.IsWithinWindow(times.Sunrise.Add(2*time.Hour)), "night mode should pause during the day")
+	assert.True(t, schedule.IsWithinWindow(times.CivilDusk.Add(time.Minute)))
+
+	settings.Realtime.Schedule.Mode = conf.ScheduleModeDay
+	schedule.update(times.CivilDusk.Add(time.Minute))
+	assert.False(t, schedule.Active())
+	schedule.update(times.Sunrise)
+	assert.True(t, schedule.Active())
+}
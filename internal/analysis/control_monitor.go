@@ -460,8 +460,9 @@ func (cm *ControlMonitor) handleUpdateDetectionIntervals() {
 	// get the current tracker here and perform cleanup before replacement
 
 	// Create a new EventTracker with updated settings
-	newTracker := processor.NewEventTrackerWithConfig(
+	newTracker := processor.NewEventTrackerWithIntervals(
 		globalInterval,
+		processor.NewEventTrackerConfig(&settings.Realtime.EventIntervals),
 		settings.Realtime.Species.Config,
 	)
 
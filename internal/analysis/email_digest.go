@@ -0,0 +1,545 @@
+// email_digest.go: scheduled email digest of detections, new species, top clips and station health
+package analysis
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/species"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/diskmanager"
+	"github.com/tphakala/birdnet-go/internal/email"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// emailDigestSendTimeout limits how long building and sending a digest may take
+const emailDigestSendTimeout = 2 * time.Minute
+
+// defaultEmailDigestTemplate is the built-in HTML template of the digest email
+//
+//go:embed email_digest.html
+var defaultEmailDigestTemplate string
+
+// emailDigestStore is the subset of the datastore used to build the digest
+type emailDigestStore interface {
+	GetSpeciesSummaryData(startDate, endDate string) ([]datastore.SpeciesSummaryData, error)
+	GetNewSpeciesDetections(startDate, endDate string, limit, offset int) ([]datastore.NewSpeciesData, error)
+	SearchDetections(filters *datastore.SearchFilters) ([]datastore.DetectionRecord, int, error)
+}
+
+// emailDigestTracker provides first detection times from the new species tracker
+type emailDigestTracker interface {
+	GetBatchSpeciesStatus(scientificNames []string, currentTime time.Time) map[string]species.SpeciesStatus
+}
+
+// emailDigestSender delivers the rendered digest
+type emailDigestSender interface {
+	Send(ctx context.Context, msg *email.Message) error
+}
+
+// EmailDigestSpecies describes a single species in the digest
+type EmailDigestSpecies struct {
+	CommonName     string
+	ScientificName string
+	Count          int
+	New            bool // true if the species was detected for the first time during the period
+}
+
+// EmailDigestClip is one of the highest confidence detections of the period
+type EmailDigestClip struct {
+	ID                string
+	CommonName        string
+	ScientificName    string
+	ConfidencePercent int
+	Timestamp         time.Time
+	AudioURL          string // empty when no base URL is configured
+	SpectrogramURL    string
+	DetailURL         string
+}
+
+// EmailDigestHealth summarizes the station health during the period
+type EmailDigestHealth struct {
+	Version   string
+	Uptime    string  // uptime of the current run, empty if unknown
+	Restarts  int     // runs started during the period
+	Crashes   int     // runs started during the period after the previous run crashed
+	DiskUsage float64 // percent used on the clip storage disk, negative if unknown
+}
+
+// EmailDigest is the content of a digest email
+type EmailDigest struct {
+	Subject         string
+	StationName     string
+	Interval        string
+	StartDate       string // first day of the period, inclusive
+	EndDate         string // last day of the period, inclusive
+	TotalDetections int
+	Species         []EmailDigestSpecies
+	NewSpecies      []EmailDigestSpecies
+	TopClips        []EmailDigestClip
+	Health          EmailDigestHealth
+}
+
+// EmailDigestScheduler sends the digest email daily or weekly at a configured local time
+type EmailDigestScheduler struct {
+	settings *conf.Settings
+	store    emailDigestStore
+	tracker  emailDigestTracker
+	sender   emailDigestSender
+	template *template.Template
+	hour     int
+	minute   int
+	weekday  time.Weekday
+	now      func() time.Time
+}
+
+// NewEmailDigestScheduler creates a digest scheduler. The tracker may be nil, in which case
+// new species are looked up in the datastore.
+func NewEmailDigestScheduler(settings *conf.Settings, store emailDigestStore, tracker emailDigestTracker, sender emailDigestSender) (*EmailDigestScheduler, error) {
+	if store == nil || sender == nil {
+		return nil, errors.Newf("email digest requires a datastore and a sender").
+			Component("analysis.emaildigest").
+			Category(errors.CategoryConfiguration).
+			Build()
+	}
+
+	digest := &settings.Email.Digest
+	hour, minute, err := conf.ParseClockTime(digest.Time)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("analysis.emaildigest").
+			Category(errors.CategoryConfiguration).
+			Context("time", digest.Time).
+			Build()
+	}
+
+	weekday := time.Monday
+	if digest.Interval == conf.DigestIntervalWeekly {
+		if weekday, err = conf.ParseWeekday(digest.Weekday); err != nil {
+			return nil, errors.New(err).
+				Component("analysis.emaildigest").
+				Category(errors.CategoryConfiguration).
+				Context("weekday", digest.Weekday).
+				Build()
+		}
+	}
+
+	tmpl, err := loadEmailDigestTemplate(digest.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailDigestScheduler{
+		settings: settings,
+		store:    store,
+		tracker:  tracker,
+		sender:   sender,
+		template: tmpl,
+		hour:     hour,
+		minute:   minute,
+		weekday:  weekday,
+		now:      time.Now,
+	}, nil
+}
+
+// loadEmailDigestTemplate parses a custom template file, or the built-in template if path is empty
+func loadEmailDigestTemplate(path string) (*template.Template, error) {
+	text := defaultEmailDigestTemplate
+	if path != "" {
+		data, err := os.ReadFile(path) // #nosec G304 -- template path comes from the local configuration file
+		if err != nil {
+			return nil, errors.New(err).
+				Component("analysis.emaildigest").
+				Category(errors.CategoryFileIO).
+				Context("template", path).
+				Build()
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New("email_digest").Parse(text)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("analysis.emaildigest").
+			Category(errors.CategoryConfiguration).
+			Context("template", path).
+			Build()
+	}
+	return tmpl, nil
+}
+
+// NextRun returns the next time the digest should be sent after the given time
+func (d *EmailDigestScheduler) NextRun(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), d.hour, d.minute, 0, 0, after.Location())
+	if d.settings.Email.Digest.Interval == conf.DigestIntervalWeekly {
+		next = next.AddDate(0, 0, (int(d.weekday)-int(next.Weekday())+7)%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Period returns the complete days covered by a digest sent at the given time:
+// the previous day for a daily digest, the previous seven days for a weekly digest.
+func (d *EmailDigestScheduler) Period(at time.Time) (start, end time.Time) {
+	today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	days := 1
+	if d.settings.Email.Digest.Interval == conf.DigestIntervalWeekly {
+		days = 7
+	}
+	return today.AddDate(0, 0, -days), today.AddDate(0, 0, -1)
+}
+
+// Build collects the digest content for the period ending before the given time
+func (d *EmailDigestScheduler) Build(at time.Time) (*EmailDigest, error) {
+	start, end := d.Period(at)
+	startDate, endDate := start.Format(time.DateOnly), end.Format(time.DateOnly)
+
+	summary, err := d.store.GetSpeciesSummaryData(startDate, endDate)
+	if err != nil {
+		return nil, digestDatabaseError(err, "get_species_summary", startDate, endDate)
+	}
+
+	newSpecies, err := d.newSpecies(summary, start, end.AddDate(0, 0, 1), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &EmailDigest{
+		StationName: d.settings.Main.Name,
+		Interval:    d.settings.Email.Digest.Interval,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Species:     make([]EmailDigestSpecies, 0, len(summary)),
+	}
+	for i := range summary {
+		entry := EmailDigestSpecies{
+			CommonName:     summary[i].CommonName,
+			ScientificName: summary[i].ScientificName,
+			Count:          summary[i].Count,
+		}
+		_, entry.New = newSpecies[entry.ScientificName]
+		digest.TotalDetections += entry.Count
+		digest.Species = append(digest.Species, entry)
+		if entry.New {
+			digest.NewSpecies = append(digest.NewSpecies, entry)
+		}
+	}
+
+	// Most detected species first, alphabetical for ties to keep output stable
+	sort.SliceStable(digest.Species, func(i, j int) bool {
+		if digest.Species[i].Count != digest.Species[j].Count {
+			return digest.Species[i].Count > digest.Species[j].Count
+		}
+		return digest.Species[i].CommonName < digest.Species[j].CommonName
+	})
+
+	if digest.TopClips, err = d.topClips(startDate, endDate); err != nil {
+		return nil, err
+	}
+	digest.Health = d.health(at, start)
+	digest.Subject = digestSubject(digest)
+
+	return digest, nil
+}
+
+// newSpecies returns the scientific names of species first detected within [start, end).
+// The new species tracker is preferred as it is kept in memory; without it the
+// datastore is queried for first detections in the period.
+func (d *EmailDigestScheduler) newSpecies(summary []datastore.SpeciesSummaryData, start, end time.Time, startDate, endDate string) (map[string]struct{}, error) {
+	result := make(map[string]struct{})
+
+	if d.tracker != nil {
+		names := make([]string, 0, len(summary))
+		for i := range summary {
+			names = append(names, summary[i].ScientificName)
+		}
+		for name, status := range d.tracker.GetBatchSpeciesStatus(names, end) {
+			if !status.FirstSeenTime.IsZero() && !status.FirstSeenTime.Before(start) && status.FirstSeenTime.Before(end) {
+				result[name] = struct{}{}
+			}
+		}
+		return result, nil
+	}
+
+	firsts, err := d.store.GetNewSpeciesDetections(startDate, endDate, len(summary), 0)
+	if err != nil {
+		return nil, digestDatabaseError(err, "get_new_species", startDate, endDate)
+	}
+	for i := range firsts {
+		result[firsts[i].ScientificName] = struct{}{}
+	}
+	return result, nil
+}
+
+// topClips returns the highest confidence detections with audio, at most one per species
+func (d *EmailDigestScheduler) topClips(startDate, endDate string) ([]EmailDigestClip, error) {
+	maxClips := d.settings.Email.Digest.MaxClips
+	if maxClips <= 0 {
+		return nil, nil
+	}
+
+	records, _, err := d.store.SearchDetections(&datastore.SearchFilters{
+		DateStart: startDate,
+		DateEnd:   endDate,
+		SortBy:    "confidence_desc",
+		PerPage:   200,
+		Ctx:       context.Background(),
+	})
+	if err != nil {
+		return nil, digestDatabaseError(err, "get_top_clips", startDate, endDate)
+	}
+
+	baseURL := strings.TrimRight(d.settings.Email.Digest.BaseURL, "/")
+	seen := make(map[string]struct{})
+	clips := make([]EmailDigestClip, 0, maxClips)
+	for i := range records {
+		if len(clips) == maxClips {
+			break
+		}
+		record := &records[i]
+		if !record.HasAudio {
+			continue
+		}
+		if _, ok := seen[record.ScientificName]; ok {
+			continue
+		}
+		seen[record.ScientificName] = struct{}{}
+
+		clip := EmailDigestClip{
+			ID:                record.ID,
+			CommonName:        record.CommonName,
+			ScientificName:    record.ScientificName,
+			ConfidencePercent: int(record.Confidence*100 + 0.5),
+			Timestamp:         record.Timestamp,
+		}
+		if baseURL != "" {
+			clip.AudioURL = baseURL + "/api/v2/audio/" + record.ID
+			clip.SpectrogramURL = baseURL + "/api/v2/spectrogram/" + record.ID
+			clip.DetailURL = baseURL + "/ui/detections/" + record.ID
+		}
+		clips = append(clips, clip)
+	}
+	return clips, nil
+}
+
+// health collects station health statistics for the period starting at start
+func (d *EmailDigestScheduler) health(at, start time.Time) EmailDigestHealth {
+	health := EmailDigestHealth{Version: d.settings.Version, DiskUsage: -1}
+
+	if usage, err := diskmanager.GetDiskUsage(d.settings.Realtime.Audio.Export.Path); err == nil {
+		health.DiskUsage = usage
+	}
+
+	ledger, ok := d.store.(datastore.RunLedger)
+	if !ok {
+		return health
+	}
+	runs, err := ledger.GetRuns(100)
+	if err != nil {
+		GetLogger().Warn("Failed to load run history for email digest",
+			"error", err,
+			"operation", "email_digest_health")
+		return health
+	}
+	for i := range runs {
+		if runs[i].StartedAt.Before(start) {
+			continue
+		}
+		health.Restarts++
+		if runs[i].StartReason == datastore.RunStartCrashRecovery {
+			health.Crashes++
+		}
+	}
+	if len(runs) > 0 && runs[0].StoppedAt == nil {
+		health.Uptime = at.Sub(runs[0].StartedAt).Truncate(time.Minute).String()
+	}
+	return health
+}
+
+// digestSubject returns the email subject line for a digest
+func digestSubject(digest *EmailDigest) string {
+	period := "Daily"
+	if digest.Interval == conf.DigestIntervalWeekly {
+		period = "Weekly"
+	}
+	subject := fmt.Sprintf("%s bird digest: %d species", period, len(digest.Species))
+	if len(digest.NewSpecies) > 0 {
+		subject += fmt.Sprintf(", %d new", len(digest.NewSpecies))
+	}
+	return subject
+}
+
+// Render converts the digest into an email with an HTML body and a plain text alternative
+func (d *EmailDigestScheduler) Render(digest *EmailDigest) (*email.Message, error) {
+	var html bytes.Buffer
+	if err := d.template.Execute(&html, digest); err != nil {
+		return nil, errors.New(err).
+			Component("analysis.emaildigest").
+			Category(errors.CategoryProcessing).
+			Context("operation", "render_email_digest").
+			Build()
+	}
+
+	return &email.Message{
+		Subject:  digest.Subject,
+		HTMLBody: html.String(),
+		TextBody: digest.Text(),
+	}, nil
+}
+
+// Text returns the plain text version of the digest
+func (digest *EmailDigest) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n%s, %s", digest.Subject, digest.StationName, digest.StartDate)
+	if digest.EndDate != digest.StartDate {
+		fmt.Fprintf(&sb, " to %s", digest.EndDate)
+	}
+	fmt.Fprintf(&sb, "\n\n%d detections of %d species.\n", digest.TotalDetections, len(digest.Species))
+
+	if len(digest.NewSpecies) > 0 {
+		sb.WriteString("\nNew species:\n")
+		for i := range digest.NewSpecies {
+			fmt.Fprintf(&sb, "- %s (%s): %d\n", digest.NewSpecies[i].CommonName, digest.NewSpecies[i].ScientificName, digest.NewSpecies[i].Count)
+		}
+	}
+
+	if len(digest.TopClips) > 0 {
+		sb.WriteString("\nTop clips:\n")
+		for i := range digest.TopClips {
+			clip := &digest.TopClips[i]
+			fmt.Fprintf(&sb, "- %s %d%% at %s", clip.CommonName, clip.ConfidencePercent, clip.Timestamp.Format("2006-01-02 15:04"))
+			if clip.AudioURL != "" {
+				fmt.Fprintf(&sb, " %s", clip.DetailURL)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(digest.Species) > 0 {
+		sb.WriteString("\nSpecies counts:\n")
+		for i := range digest.Species {
+			fmt.Fprintf(&sb, "- %s: %d\n", digest.Species[i].CommonName, digest.Species[i].Count)
+		}
+	}
+
+	health := &digest.Health
+	fmt.Fprintf(&sb, "\nStation health:\n- Version: %s\n", health.Version)
+	if health.Uptime != "" {
+		fmt.Fprintf(&sb, "- Uptime: %s\n", health.Uptime)
+	}
+	fmt.Fprintf(&sb, "- Restarts: %d", health.Restarts)
+	if health.Crashes > 0 {
+		fmt.Fprintf(&sb, " (%d after a crash)", health.Crashes)
+	}
+	sb.WriteString("\n")
+	if health.DiskUsage >= 0 {
+		fmt.Fprintf(&sb, "- Clip storage disk usage: %s%%\n", strconv.FormatFloat(health.DiskUsage, 'f', 0, 64))
+	}
+
+	return sb.String()
+}
+
+// Send builds the digest for the period before the current time and emails it
+func (d *EmailDigestScheduler) Send() error {
+	digest, err := d.Build(d.now())
+	if err != nil {
+		return err
+	}
+	msg, err := d.Render(digest)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), emailDigestSendTimeout)
+	defer cancel()
+	return d.sender.Send(ctx, msg)
+}
+
+// Run waits for the configured send time and sends the digest until quitChan is closed
+func (d *EmailDigestScheduler) Run(quitChan <-chan struct{}) {
+	for {
+		now := d.now()
+		timer := time.NewTimer(d.NextRun(now).Sub(now))
+
+		select {
+		case <-quitChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := d.Send(); err != nil {
+				GetLogger().Error("Failed to send email digest",
+					"error", err,
+					"operation", "email_digest_send")
+				continue
+			}
+			GetLogger().Info("Email digest sent",
+				"interval", d.settings.Email.Digest.Interval,
+				"recipients", len(d.settings.Email.SMTP.To),
+				"operation", "email_digest_send")
+		}
+	}
+}
+
+// digestDatabaseError wraps a datastore error raised while building the digest
+func digestDatabaseError(err error, operation, startDate, endDate string) error {
+	return errors.New(err).
+		Component("analysis.emaildigest").
+		Category(errors.CategoryDatabase).
+		Context("operation", operation).
+		Context("start_date", startDate).
+		Context("end_date", endDate).
+		Build()
+}
+
+// startEmailDigest initializes and starts the email digest scheduler in a new goroutine.
+func startEmailDigest(wg *sync.WaitGroup, settings *conf.Settings, dataStore datastore.Interface, tracker *species.SpeciesTracker, quitChan chan struct{}) {
+	sender, err := email.NewSender(&settings.Email.SMTP)
+	if err != nil {
+		GetLogger().Error("Failed to initialize email sender",
+			"error", err,
+			"operation", "initialize_email_digest")
+		return
+	}
+
+	// Avoid storing a typed nil pointer in the interface
+	var digestTracker emailDigestTracker
+	if tracker != nil {
+		digestTracker = tracker
+	}
+
+	scheduler, err := NewEmailDigestScheduler(settings, dataStore, digestTracker, sender)
+	if err != nil {
+		GetLogger().Error("Failed to initialize email digest",
+			"error", err,
+			"operation", "initialize_email_digest")
+		return
+	}
+
+	GetLogger().Info("Email digest scheduled",
+		"interval", settings.Email.Digest.Interval,
+		"time", settings.Email.Digest.Time,
+		"next_run", scheduler.NextRun(time.Now()),
+		"operation", "initialize_email_digest")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		scheduler.Run(quitChan)
+	}()
+}
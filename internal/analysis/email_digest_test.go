@@ -0,0 +1,209 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/species"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/email"
+)
+
+// mockEmailDigestStore serves fixed digest data
+type mockEmailDigestStore struct {
+	summary    []datastore.SpeciesSummaryData
+	newSpecies []datastore.NewSpeciesData
+	records    []datastore.DetectionRecord
+	filters    *datastore.SearchFilters
+}
+
+func (m *mockEmailDigestStore) GetSpeciesSummaryData(startDate, endDate string) ([]datastore.SpeciesSummaryData, error) {
+	return m.summary, nil
+}
+
+func (m *mockEmailDigestStore) GetNewSpeciesDetections(startDate, endDate string, limit, offset int) ([]datastore.NewSpeciesData, error) {
+	return m.newSpecies, nil
+}
+
+func (m *mockEmailDigestStore) SearchDetections(filters *datastore.SearchFilters) ([]datastore.DetectionRecord, int, error) {
+	m.filters = filters
+	return m.records, len(m.records), nil
+}
+
+// mockEmailDigestTracker returns fixed first detection times
+type mockEmailDigestTracker struct {
+	firstSeen map[string]time.Time
+}
+
+func (m *mockEmailDigestTracker) GetBatchSpeciesStatus(names []string, now time.Time) map[string]species.SpeciesStatus {
+	result := make(map[string]species.SpeciesStatus, len(names))
+	for _, name := range names {
+		result[name] = species.SpeciesStatus{FirstSeenTime: m.firstSeen[name]}
+	}
+	return result
+}
+
+// mockEmailSender records sent messages
+type mockEmailSender struct {
+	sent []*email.Message
+}
+
+func (m *mockEmailSender) Send(ctx context.Context, msg *email.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func newEmailDigestTestSettings(interval string) *conf.Settings {
+	settings := &conf.Settings{Version: "1.2.3"}
+	settings.Main.Name = "Garden"
+	settings.Email.Digest = conf.EmailDigestSettings{
+		Interval: interval,
+		Time:     "07:00",
+		Weekday:  "monday",
+		MaxClips: 2,
+		BaseURL:  "https://birdnet.example.com/",
+	}
+	return settings
+}
+
+func newEmailDigestTestStore() *mockEmailDigestStore {
+	return &mockEmailDigestStore{
+		summary: []datastore.SpeciesSummaryData{
+			{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Count: 12},
+			{ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", Count: 1},
+			{ScientificName: "Parus major", CommonName: "Great Tit", Count: 30},
+		},
+		records: []datastore.DetectionRecord{
+			{ID: "10", ScientificName: "Parus major", CommonName: "Great Tit", Confidence: 0.97, HasAudio: true},
+			{ID: "11", ScientificName: "Parus major", CommonName: "Great Tit", Confidence: 0.95, HasAudio: true},
+			{ID: "12", ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", Confidence: 0.91, HasAudio: false},
+			{ID: "13", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.88, HasAudio: true},
+			{ID: "14", ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", Confidence: 0.80, HasAudio: true},
+		},
+	}
+}
+
+func TestEmailDigestNextRun(t *testing.T) {
+	daily, err := NewEmailDigestScheduler(newEmailDigestTestSettings(conf.DigestIntervalDaily), &mockEmailDigestStore{}, nil, &mockEmailSender{})
+	require.NoError(t, err)
+
+	// Saturday 2025-05-10
+	assert.Equal(t, time.Date(2025, 5, 10, 7, 0, 0, 0, time.Local), daily.NextRun(time.Date(2025, 5, 10, 6, 0, 0, 0, time.Local)))
+	assert.Equal(t, time.Date(2025, 5, 11, 7, 0, 0, 0, time.Local), daily.NextRun(time.Date(2025, 5, 10, 7, 0, 0, 0, time.Local)))
+
+	weekly, err := NewEmailDigestScheduler(newEmailDigestTestSettings(conf.DigestIntervalWeekly), &mockEmailDigestStore{}, nil, &mockEmailSender{})
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local), weekly.NextRun(time.Date(2025, 5, 10, 6, 0, 0, 0, time.Local)))
+	assert.Equal(t, time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local), weekly.NextRun(time.Date(2025, 5, 12, 6, 59, 0, 0, time.Local)))
+	assert.Equal(t, time.Date(2025, 5, 19, 7, 0, 0, 0, time.Local), weekly.NextRun(time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local)))
+}
+
+func TestEmailDigestPeriod(t *testing.T) {
+	at := time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local)
+
+	daily, err := NewEmailDigestScheduler(newEmailDigestTestSettings(conf.DigestIntervalDaily), &mockEmailDigestStore{}, nil, &mockEmailSender{})
+	require.NoError(t, err)
+	start, end := daily.Period(at)
+	assert.Equal(t, "2025-05-11", start.Format(time.DateOnly))
+	assert.Equal(t, "2025-05-11", end.Format(time.DateOnly))
+
+	weekly, err := NewEmailDigestScheduler(newEmailDigestTestSettings(conf.DigestIntervalWeekly), &mockEmailDigestStore{}, nil, &mockEmailSender{})
+	require.NoError(t, err)
+	start, end = weekly.Period(at)
+	assert.Equal(t, "2025-05-05", start.Format(time.DateOnly))
+	assert.Equal(t, "2025-05-11", end.Format(time.DateOnly))
+}
+
+func TestEmailDigestBuild(t *testing.T) {
+	store := newEmailDigestTestStore()
+	tracker := &mockEmailDigestTracker{firstSeen: map[string]time.Time{
+		"Turdus merula": time.Date(2024, 3, 1, 6, 0, 0, 0, time.Local),
+		"Bubo bubo":     time.Date(2025, 5, 11, 23, 10, 0, 0, time.Local),
+		"Parus major":   time.Date(2024, 1, 5, 8, 0, 0, 0, time.Local),
+	}}
+	scheduler, err := NewEmailDigestScheduler(newEmailDigestTestSettings(conf.DigestIntervalDaily), store, tracker, &mockEmailSender{})
+	require.NoError(t, err)
+
+	digest, err := scheduler.Build(time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-05-11", digest.StartDate)
+	assert.Equal(t, 43, digest.TotalDetections)
+	require.Len(t, digest.Species, 3)
+	assert.Equal(t, "Great Tit", digest.Species[0].CommonName, "species should be sorted by count")
+	require.Len(t, digest.NewSpecies, 1)
+	assert.Equal(t, "Eurasian Eagle-Owl", digest.NewSpecies[0].CommonName)
+	assert.Equal(t, "Daily bird digest: 3 species, 1 new", digest.Subject)
+
+	assert.Equal(t, "confidence_desc", store.filters.SortBy)
+	require.Len(t, digest.TopClips, 2, "clips are limited to MaxClips with one per species")
+	assert.Equal(t, "10", digest.TopClips[0].ID)
+	assert.Equal(t, 97, digest.TopClips[0].ConfidencePercent)
+	assert.Equal(t, "https://birdnet.example.com/api/v2/audio/10", digest.TopClips[0].AudioURL)
+	assert.Equal(t, "https://birdnet.example.com/api/v2/spectrogram/10", digest.TopClips[0].SpectrogramURL)
+	assert.Equal(t, "13", digest.TopClips[1].ID, "clips without audio are skipped")
+}
+
+func TestEmailDigestBuildWithoutTracker(t *testing.T) {
+	store := newEmailDigestTestStore()
+	store.newSpecies = []datastore.NewSpeciesData{{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"}}
+	settings := newEmailDigestTestSettings(conf.DigestIntervalWeekly)
+	settings.Email.Digest.BaseURL = ""
+
+	scheduler, err := NewEmailDigestScheduler(settings, store, nil, &mockEmailSender{})
+	require.NoError(t, err)
+
+	digest, err := scheduler.Build(time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+
+	require.Len(t, digest.NewSpecies, 1)
+	assert.Equal(t, "Eurasian Blackbird", digest.NewSpecies[0].CommonName)
+	assert.Equal(t, "Weekly bird digest: 3 species, 1 new", digest.Subject)
+	require.NotEmpty(t, digest.TopClips)
+	assert.Empty(t, digest.TopClips[0].AudioURL, "links are omitted without a base URL")
+}
+
+func TestEmailDigestSend(t *testing.T) {
+	sender := &mockEmailSender{}
+	scheduler, err := NewEmailDigestScheduler(newEmailDigestTestSettings(conf.DigestIntervalDaily), newEmailDigestTestStore(), nil, sender)
+	require.NoError(t, err)
+	scheduler.now = func() time.Time { return time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local) }
+
+	require.NoError(t, scheduler.Send())
+	require.Len(t, sender.sent, 1)
+
+	msg := sender.sent[0]
+	assert.Equal(t, "Daily bird digest: 3 species", msg.Subject)
+	assert.Contains(t, msg.HTMLBody, "Eurasian Eagle-Owl")
+	assert.Contains(t, msg.HTMLBody, "https://birdnet.example.com/api/v2/spectrogram/10")
+	assert.Contains(t, msg.HTMLBody, "Version: 1.2.3")
+	assert.Contains(t, msg.TextBody, "43 detections of 3 species")
+	assert.Contains(t, msg.TextBody, "- Great Tit: 30")
+}
+
+func TestEmailDigestCustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digest.html")
+	require.NoError(t, os.WriteFile(path, []byte("<p>{{.StationName}}: {{.TotalDetections}}</p>"), 0o600))
+
+	settings := newEmailDigestTestSettings(conf.DigestIntervalDaily)
+	settings.Email.Digest.Template = path
+	scheduler, err := NewEmailDigestScheduler(settings, newEmailDigestTestStore(), nil, &mockEmailSender{})
+	require.NoError(t, err)
+
+	digest, err := scheduler.Build(time.Date(2025, 5, 12, 7, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	msg, err := scheduler.Render(digest)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Garden: 43</p>", msg.HTMLBody)
+
+	settings.Email.Digest.Template = filepath.Join(t.TempDir(), "missing.html")
+	_, err = NewEmailDigestScheduler(settings, newEmailDigestTestStore(), nil, &mockEmailSender{})
+	assert.Error(t, err)
+}
@@ -52,6 +52,13 @@ type JobStatsSnapshot struct {
 	ActionStats map[string]ActionStats // Key is the type name of the action
 }
 
+// ActiveJobSummary describes the unfinished jobs of one action type
+type ActiveJobSummary struct {
+	Queued          int       // Jobs waiting to run, including those waiting for a retry
+	InFlight        int       // Jobs currently executing
+	OldestCreatedAt time.Time // Creation time of the oldest unfinished job
+}
+
 // ActionStats tracks statistics for a specific action type
 type ActionStats struct {
 	// Type identifier information
@@ -968,3 +968,47 @@ func (q *JobQueue) GetPendingJobs() []*Job {
 
 	return pendingJobs
 }
+
+// ActiveJobCount returns the number of jobs that are pending, running or waiting for a retry
+func (q *JobQueue) ActiveJobCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := 0
+	for _, job := range q.jobs {
+		switch job.Status {
+		case JobStatusPending, JobStatusRunning, JobStatusRetrying:
+			count++
+		case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		}
+	}
+
+	return count
+}
+
+// ActiveJobsByType returns the pending, running and retrying jobs grouped by the type name
+// of their action
+func (q *JobQueue) ActiveJobsByType() map[string]ActiveJobSummary {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	summaries := make(map[string]ActiveJobSummary)
+	for _, job := range q.jobs {
+		typeName := fmt.Sprintf("%T", job.Action)
+		summary := summaries[typeName]
+		switch job.Status {
+		case JobStatusPending, JobStatusRetrying:
+			summary.Queued++
+		case JobStatusRunning:
+			summary.InFlight++
+		case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+			continue
+		}
+		if summary.OldestCreatedAt.IsZero() || job.CreatedAt.Before(summary.OldestCreatedAt) {
+			summary.OldestCreatedAt = job.CreatedAt
+		}
+		summaries[typeName] = summary
+	}
+
+	return summaries
+}
@@ -1692,6 +1692,33 @@ func TestLongRunningJobs(t *testing.T) {
 }
 
 // TestJobTypeStatistics tests that job statistics are tracked correctly per action type
+// TestActiveJobsByType tests that unfinished jobs are grouped by action type
+func TestActiveJobsByType(t *testing.T) {
+	t.Parallel()
+
+	queue := NewJobQueueWithOptions(10, 10, false)
+	start := time.Date(2025, 5, 10, 6, 0, 0, 0, time.UTC)
+
+	type UploadActionType struct{ MockAction }
+
+	first := &Job{Action: &MockAction{}, CreatedAt: start, Status: JobStatusRunning}
+	queue.jobs = []*Job{
+		first,
+		{Action: &MockAction{}, CreatedAt: start.Add(time.Minute), Status: JobStatusRetrying},
+		{Action: &UploadActionType{}, CreatedAt: start.Add(time.Minute), Status: JobStatusPending},
+		{Action: &UploadActionType{}, CreatedAt: start.Add(-time.Hour), Status: JobStatusFailed},
+	}
+
+	summaries := queue.ActiveJobsByType()
+	require.Len(t, summaries, 2)
+	assert.Equal(t, ActiveJobSummary{Queued: 1, InFlight: 1, OldestCreatedAt: start}, summaries["*jobqueue.MockAction"])
+	assert.Equal(t, ActiveJobSummary{Queued: 1, OldestCreatedAt: start.Add(time.Minute)}, summaries["*jobqueue.UploadActionType"])
+
+	first.Status = JobStatusCompleted
+
+	assert.Equal(t, ActiveJobSummary{Queued: 1, OldestCreatedAt: start.Add(time.Minute)}, queue.ActiveJobsByType()["*jobqueue.MockAction"])
+}
+
 func TestJobTypeStatistics(t *testing.T) {
 	// Create a context for manual control
 	ctx, cancel := context.WithCancel(context.Background())
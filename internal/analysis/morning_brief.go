@@ -0,0 +1,265 @@
+// morning_brief.go: scheduled daily summary notification of species detected since midnight
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+const (
+	// morningBriefMaxListed limits the number of species listed in the notification message
+	morningBriefMaxListed = 10
+	// morningBriefExpiry is how long a morning brief notification stays visible
+	morningBriefExpiry = 24 * time.Hour
+)
+
+// morningBriefStore is the subset of the datastore used to build the morning brief
+type morningBriefStore interface {
+	GetSpeciesSummaryData(startDate, endDate string) ([]datastore.SpeciesSummaryData, error)
+}
+
+// MorningBriefSpecies describes a single species in the morning brief
+type MorningBriefSpecies struct {
+	CommonName     string `json:"commonName"`
+	ScientificName string `json:"scientificName"`
+	Count          int    `json:"count"`
+	NewArrival     bool   `json:"newArrival"` // true if not detected during the comparison period
+}
+
+// MorningBrief is a summary of species detected since midnight
+type MorningBrief struct {
+	Date            string                `json:"date"`
+	TotalDetections int                   `json:"totalDetections"`
+	Species         []MorningBriefSpecies `json:"species"`
+	NewArrivals     []MorningBriefSpecies `json:"newArrivals"`
+}
+
+// MorningBriefScheduler sends the morning brief notification once a day at a configured local time
+type MorningBriefScheduler struct {
+	store       morningBriefStore
+	notify      func(*notification.Notification) error
+	hour        int
+	minute      int
+	compareDays int
+	now         func() time.Time
+}
+
+// NewMorningBriefScheduler creates a scheduler from morning brief settings.
+// The notify function receives the finished notification; when nil the global
+// notification service is used.
+func NewMorningBriefScheduler(settings *conf.MorningBriefSettings, store morningBriefStore, notify func(*notification.Notification) error) (*MorningBriefScheduler, error) {
+	if store == nil {
+		return nil, errors.Newf("morning brief requires a datastore").
+			Component("analysis.morningbrief").
+			Category(errors.CategoryConfiguration).
+			Build()
+	}
+
+	hour, minute, err := conf.ParseClockTime(settings.Time)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("analysis.morningbrief").
+			Category(errors.CategoryConfiguration).
+			Context("time", settings.Time).
+			Build()
+	}
+
+	if notify == nil {
+		notify = sendMorningBriefNotification
+	}
+
+	compareDays := settings.CompareDays
+	if compareDays < 1 {
+		compareDays = 7
+	}
+
+	return &MorningBriefScheduler{
+		store:       store,
+		notify:      notify,
+		hour:        hour,
+		minute:      minute,
+		compareDays: compareDays,
+		now:         time.Now,
+	}, nil
+}
+
+// NextRun returns the next time the brief should be sent after the given time
+func (m *MorningBriefScheduler) NextRun(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), m.hour, m.minute, 0, 0, after.Location())
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Build collects species detected since midnight of the given day and marks new
+// arrivals compared to the preceding comparison period.
+func (m *MorningBriefScheduler) Build(day time.Time) (*MorningBrief, error) {
+	date := day.Format("2006-01-02")
+	today, err := m.store.GetSpeciesSummaryData(date, date)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("analysis.morningbrief").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_today_species").
+			Context("date", date).
+			Build()
+	}
+
+	compareStart := day.AddDate(0, 0, -m.compareDays).Format("2006-01-02")
+	compareEnd := day.AddDate(0, 0, -1).Format("2006-01-02")
+	previous, err := m.store.GetSpeciesSummaryData(compareStart, compareEnd)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("analysis.morningbrief").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_previous_species").
+			Context("start_date", compareStart).
+			Context("end_date", compareEnd).
+			Build()
+	}
+
+	seenBefore := make(map[string]struct{}, len(previous))
+	for i := range previous {
+		seenBefore[previous[i].ScientificName] = struct{}{}
+	}
+
+	brief := &MorningBrief{
+		Date:    date,
+		Species: make([]MorningBriefSpecies, 0, len(today)),
+	}
+	for i := range today {
+		_, seen := seenBefore[today[i].ScientificName]
+		entry := MorningBriefSpecies{
+			CommonName:     today[i].CommonName,
+			ScientificName: today[i].ScientificName,
+			Count:          today[i].Count,
+			NewArrival:     !seen,
+		}
+		brief.TotalDetections += entry.Count
+		brief.Species = append(brief.Species, entry)
+		if entry.NewArrival {
+			brief.NewArrivals = append(brief.NewArrivals, entry)
+		}
+	}
+
+	// Most detected species first, alphabetical for ties to keep output stable
+	sort.SliceStable(brief.Species, func(i, j int) bool {
+		if brief.Species[i].Count != brief.Species[j].Count {
+			return brief.Species[i].Count > brief.Species[j].Count
+		}
+		return brief.Species[i].CommonName < brief.Species[j].CommonName
+	})
+
+	return brief, nil
+}
+
+// Notification converts the brief into a notification ready to be sent
+func (b *MorningBrief) Notification() *notification.Notification {
+	title := fmt.Sprintf("Morning brief: %d species heard today", len(b.Species))
+
+	var sb strings.Builder
+	if len(b.Species) == 0 {
+		sb.WriteString("No species detected since midnight.")
+	} else {
+		fmt.Fprintf(&sb, "%d detections since midnight.", b.TotalDetections)
+		for i := range b.Species {
+			if i == morningBriefMaxListed {
+				fmt.Fprintf(&sb, "\n…and %d more", len(b.Species)-morningBriefMaxListed)
+				break
+			}
+			fmt.Fprintf(&sb, "\n%s: %d", b.Species[i].CommonName, b.Species[i].Count)
+			if b.Species[i].NewArrival {
+				sb.WriteString(" (new)")
+			}
+		}
+	}
+
+	if len(b.NewArrivals) > 0 {
+		names := make([]string, 0, len(b.NewArrivals))
+		for i := range b.NewArrivals {
+			names = append(names, b.NewArrivals[i].CommonName)
+		}
+		fmt.Fprintf(&sb, "\nNew arrivals: %s", strings.Join(names, ", "))
+	}
+
+	return notification.NewNotification(notification.TypeInfo, notification.PriorityLow, title, sb.String()).
+		WithComponent("morning-brief").
+		WithMetadata("date", b.Date).
+		WithMetadata("species_count", len(b.Species)).
+		WithMetadata("total_detections", b.TotalDetections).
+		WithMetadata("species", b.Species).
+		WithMetadata("new_arrivals", b.NewArrivals).
+		WithExpiry(morningBriefExpiry)
+}
+
+// Send builds the brief for the current day and delivers it through the notification subsystem
+func (m *MorningBriefScheduler) Send() error {
+	brief, err := m.Build(m.now())
+	if err != nil {
+		return err
+	}
+	return m.notify(brief.Notification())
+}
+
+// Run waits for the configured time each day and sends the brief until quitChan is closed
+func (m *MorningBriefScheduler) Run(quitChan <-chan struct{}) {
+	for {
+		now := m.now()
+		timer := time.NewTimer(m.NextRun(now).Sub(now))
+
+		select {
+		case <-quitChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := m.Send(); err != nil {
+				GetLogger().Error("Failed to send morning brief",
+					"error", err,
+					"operation", "morning_brief_send")
+			}
+		}
+	}
+}
+
+// sendMorningBriefNotification delivers a notification through the global notification service
+func sendMorningBriefNotification(n *notification.Notification) error {
+	service := notification.GetService()
+	if service == nil {
+		return errors.Newf("notification service not initialized").
+			Component("analysis.morningbrief").
+			Category(errors.CategorySystem).
+			Build()
+	}
+	return service.CreateWithMetadata(n)
+}
+
+// startMorningBrief initializes and starts the morning brief scheduler in a new goroutine.
+func startMorningBrief(wg *sync.WaitGroup, settings *conf.Settings, dataStore datastore.Interface, quitChan chan struct{}) {
+	scheduler, err := NewMorningBriefScheduler(&settings.Realtime.MorningBrief, dataStore, nil)
+	if err != nil {
+		GetLogger().Error("Failed to initialize morning brief",
+			"error", err,
+			"operation", "initialize_morning_brief")
+		return
+	}
+
+	GetLogger().Info("Morning brief scheduled",
+		"time", settings.Realtime.MorningBrief.Time,
+		"compare_days", settings.Realtime.MorningBrief.CompareDays,
+		"operation", "initialize_morning_brief")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		scheduler.Run(quitChan)
+	}()
+}
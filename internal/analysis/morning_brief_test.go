@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// mockMorningBriefStore returns species summaries keyed by "start|end" date range
+type mockMorningBriefStore struct {
+	data map[string][]datastore.SpeciesSummaryData
+}
+
+func (m *mockMorningBriefStore) GetSpeciesSummaryData(startDate, endDate string) ([]datastore.SpeciesSummaryData, error) {
+	return m.data[startDate+"|"+endDate], nil
+}
+
+func TestMorningBriefNextRun(t *testing.T) {
+	scheduler, err := NewMorningBriefScheduler(&conf.MorningBriefSettings{Time: "07:30", CompareDays: 7}, &mockMorningBriefStore{}, func(*notification.Notification) error { return nil })
+	require.NoError(t, err)
+
+	before := time.Date(2025, 5, 10, 6, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2025, 5, 10, 7, 30, 0, 0, time.Local), scheduler.NextRun(before))
+
+	exactly := time.Date(2025, 5, 10, 7, 30, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2025, 5, 11, 7, 30, 0, 0, time.Local), scheduler.NextRun(exactly))
+
+	after := time.Date(2025, 5, 10, 22, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2025, 5, 11, 7, 30, 0, 0, time.Local), scheduler.NextRun(after))
+}
+
+func TestMorningBriefInvalidTime(t *testing.T) {
+	_, err := NewMorningBriefScheduler(&conf.MorningBriefSettings{Time: "late"}, &mockMorningBriefStore{}, nil)
+	assert.Error(t, err)
+}
+
+func TestMorningBriefBuildAndSend(t *testing.T) {
+	store := &mockMorningBriefStore{data: map[string][]datastore.SpeciesSummaryData{
+		"2025-05-10|2025-05-10": {
+			{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Count: 4},
+			{ScientificName: "Cuculus canorus", CommonName: "Common Cuckoo", Count: 1},
+			{ScientificName: "Parus major", CommonName: "Great Tit", Count: 9},
+		},
+		"2025-05-03|2025-05-09": {
+			{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Count: 30},
+			{ScientificName: "Parus major", CommonName: "Great Tit", Count: 50},
+		},
+	}}
+
+	var sent *notification.Notification
+	scheduler, err := NewMorningBriefScheduler(&conf.MorningBriefSettings{Time: "08:00", CompareDays: 7}, store, func(n *notification.Notification) error {
+		sent = n
+		return nil
+	})
+	require.NoError(t, err)
+	scheduler.now = func() time.Time { return time.Date(2025, 5, 10, 8, 0, 0, 0, time.Local) }
+
+	brief, err := scheduler.Build(scheduler.now())
+	require.NoError(t, err)
+	assert.Equal(t, 14, brief.TotalDetections)
+	require.Len(t, brief.Species, 3)
+	assert.Equal(t, "Great Tit", brief.Species[0].CommonName, "species should be sorted by count")
+	require.Len(t, brief.NewArrivals, 1)
+	assert.Equal(t, "Cuculus canorus", brief.NewArrivals[0].ScientificName)
+
+	require.NoError(t, scheduler.Send())
+	require.NotNil(t, sent)
+	assert.Equal(t, notification.TypeInfo, sent.Type)
+	assert.Equal(t, "morning-brief", sent.Component)
+	assert.Contains(t, sent.Message, "Common Cuckoo: 1 (new)")
+	assert.Contains(t, sent.Message, "New arrivals: Common Cuckoo")
+}
+
+func TestMorningBriefEmptyDay(t *testing.T) {
+	scheduler, err := NewMorningBriefScheduler(&conf.MorningBriefSettings{Time: "08:00", CompareDays: 7}, &mockMorningBriefStore{}, nil)
+	require.NoError(t, err)
+
+	brief, err := scheduler.Build(time.Date(2025, 1, 1, 8, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Empty(t, brief.Species)
+	assert.Contains(t, brief.Notification().Message, "No species detected")
+}
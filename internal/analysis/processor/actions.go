@@ -27,6 +27,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/myaudio"
 	"github.com/tphakala/birdnet-go/internal/notification"
 	"github.com/tphakala/birdnet-go/internal/observation"
+	"github.com/tphakala/birdnet-go/internal/privacy"
 )
 
 // Timeout and interval constants
@@ -47,6 +48,10 @@ const (
 	// Typed as int64 to match os.FileInfo.Size() return type
 	MinAudioFileSize int64 = 1024
 
+	// ClipCaptureTimeout is how long to wait for the end of a detection clip to be captured
+	// after its end time, e.g. while a stream reconnects
+	ClipCaptureTimeout = 10 * time.Second
+
 	// MQTTPublishTimeout is the timeout for MQTT publish operations
 	MQTTPublishTimeout = 10 * time.Second
 
@@ -88,9 +93,11 @@ type DatabaseAction struct {
 	Ds                datastore.Interface
 	Note              datastore.Note
 	Results           []datastore.Results
+	Sources           []datastore.NoteSource // Contributing audio sources, set when cross-source correlation is enabled
 	EventTracker      *EventTracker
-	NewSpeciesTracker *species.SpeciesTracker // Add reference to new species tracker
-	processor         *Processor              // Add reference to processor for source name resolution
+	NewSpeciesTracker *species.SpeciesTracker  // Add reference to new species tracker
+	processor         *Processor               // Add reference to processor for source name resolution
+	IDPromise         *datastore.NoteIDPromise // Resolved with the database ID for actions using the provisional ID, may be nil
 	Description       string
 	CorrelationID     string     // Detection correlation ID for log tracking
 	mu                sync.Mutex // Protect concurrent access to Note and Results
@@ -114,8 +121,10 @@ type BirdWeatherAction struct {
 	EventTracker  *EventTracker
 	RetryConfig   jobqueue.RetryConfig // Configuration for retry behavior
 	Description   string
-	CorrelationID string     // Detection correlation ID for log tracking
-	mu            sync.Mutex // Protect concurrent access to Note and pcmData
+	CorrelationID string       // Detection correlation ID for log tracking
+	quota         *uploadQuota // Daily uploads per species, nil for no limits
+	quotaCounted  bool         // true once the upload is counted, so retries are not counted again
+	mu            sync.Mutex   // Protect concurrent access to Note and pcmData
 }
 
 type MqttAction struct {
@@ -126,8 +135,10 @@ type MqttAction struct {
 	EventTracker   *EventTracker
 	RetryConfig    jobqueue.RetryConfig // Configuration for retry behavior
 	Description    string
-	CorrelationID  string     // Detection correlation ID for log tracking
-	mu             sync.Mutex // Protect concurrent access to Note
+	CorrelationID  string            // Detection correlation ID for log tracking
+	batcher        *mqttBatcher      // Collects messages when batched publishing is enabled
+	deadline       detectionDeadline // Publishing is shed after the processing deadline
+	mu             sync.Mutex        // Protect concurrent access to Note
 }
 
 type UpdateRangeFilterAction struct {
@@ -144,13 +155,17 @@ type SSEAction struct {
 	EventTracker   *EventTracker
 	RetryConfig    jobqueue.RetryConfig // Configuration for retry behavior
 	Description    string
-	CorrelationID  string     // Detection correlation ID for log tracking
-	mu             sync.Mutex // Protect concurrent access to Note
+	CorrelationID  string            // Detection correlation ID for log tracking
+	mu             sync.Mutex        // Protect concurrent access to Note
+	deadline       detectionDeadline // Broadcasting is shed after the processing deadline
 	// SSEBroadcaster is a function that broadcasts detection data
 	// This allows the action to be independent of the specific API implementation
 	SSEBroadcaster func(note *datastore.Note, birdImage *imageprovider.BirdImage) error
 	// Datastore interface for querying the database to get the assigned ID
 	Ds datastore.Interface
+	// IDPromise provides the ID of a note saved concurrently by a DatabaseAction, broadcast
+	// without waiting for the save. Without it the database is polled for the ID.
+	IDPromise *datastore.NoteIDPromise
 }
 
 // CompositeAction executes multiple actions sequentially, ensuring proper dependency management.
@@ -514,11 +529,80 @@ func (a *LogAction) Execute(data interface{}) error {
 	return nil
 }
 
+// saveNote saves the note and results to the database. While the database is unavailable the
+// note is buffered on disk instead, to be saved once it recovers, and saved is false. The new
+// species state is buffered with the note for the detection events published on replay.
+func (a *DatabaseAction) saveNote(isNewSpecies bool, daysSinceFirstSeen int) (saved bool, err error) {
+	var spool *detectionSpool
+	if a.processor != nil {
+		spool = a.processor.spool
+	}
+
+	if spool.buffering() {
+		return false, a.spoolNote(spool, nil, isNewSpecies, daysSinceFirstSeen)
+	}
+
+	if err := a.Ds.Save(&a.Note, a.Results); err != nil {
+		// Add structured logging
+		GetLogger().Error("Failed to save note and results to database",
+			"component", "analysis.processor.actions",
+			"detection_id", a.CorrelationID,
+			"error", err,
+			"species", a.Note.CommonName,
+			"scientific_name", a.Note.ScientificName,
+			"confidence", a.Note.Confidence,
+			"clip_name", a.Note.ClipName,
+			"operation", "database_save")
+		log.Printf("❌ Failed to save note and results to database")
+		// Only an unreachable or busy database is waited out, refused notes are not buffered
+		if spool == nil || !datastore.IsUnavailableError(err) {
+			return false, err
+		}
+		if spoolErr := a.spoolNote(spool, err, isNewSpecies, daysSinceFirstSeen); spoolErr != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// spoolNote buffers the note in the detection spool, entering degraded mode with cause
+func (a *DatabaseAction) spoolNote(spool *detectionSpool, cause error, isNewSpecies bool, daysSinceFirstSeen int) error {
+	entry := newSpooledNote(&a.Note, a.Results, a.Sources)
+	entry.IsNewSpecies, entry.DaysSinceFirstSeen = isNewSpecies, daysSinceFirstSeen
+	entry.NoteIDToken = a.IDPromise.Buffer()
+	if err := spool.add(entry, cause); err != nil {
+		GetLogger().Error("Failed to buffer detection, detection lost",
+			"component", "analysis.processor.actions",
+			"detection_id", a.CorrelationID,
+			"error", err,
+			"species", a.Note.CommonName,
+			"operation", "spool_detection")
+		return err
+	}
+	GetLogger().Debug("Buffered detection while the database is unavailable",
+		"component", "analysis.processor.actions",
+		"detection_id", a.CorrelationID,
+		"species", a.Note.CommonName,
+		"operation", "spool_detection")
+	return nil
+}
+
 // Execute saves the note to the database
 func (a *DatabaseAction) Execute(data interface{}) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Resolve the provisional ID with the database ID, or as not saved when skipped or failed.
+	// A buffered note stays pending until the replay of the buffer saves it.
+	var savedID uint
+	var buffered bool
+	defer func() {
+		if !buffered {
+			a.IDPromise.Resolve(savedID)
+		}
+	}()
+
 	speciesName := strings.ToLower(a.Note.CommonName)
 
 	// Check event frequency
@@ -535,24 +619,25 @@ func (a *DatabaseAction) Execute(data interface{}) error {
 		isNewSpecies, daysSinceFirstSeen = a.NewSpeciesTracker.CheckAndUpdateSpecies(a.Note.ScientificName, time.Now())
 	}
 
-	// Save note to database
-	if err := a.Ds.Save(&a.Note, a.Results); err != nil {
-		// Add structured logging
-		GetLogger().Error("Failed to save note and results to database",
-			"component", "analysis.processor.actions",
-			"detection_id", a.CorrelationID,
-			"error", err,
-			"species", a.Note.CommonName,
-			"scientific_name", a.Note.ScientificName,
-			"confidence", a.Note.Confidence,
-			"clip_name", a.Note.ClipName,
-			"operation", "database_save")
-		log.Printf("❌ Failed to save note and results to database")
+	// Attach the weather at detection time for detections-by-weather analytics
+	a.attachWeather()
+
+	// Save note to database, or buffer it while the database is unavailable
+	saved, err := a.saveNote(isNewSpecies, daysSinceFirstSeen)
+	if err != nil {
 		return err
 	}
+	buffered = !saved
 
-	// After successful save, publish detection event for new species
-	a.publishNewSpeciesDetectionEvent(isNewSpecies, daysSinceFirstSeen)
+	if saved {
+		savedID = a.Note.ID
+
+		// Record all sources that heard this detection
+		a.saveNoteSources()
+
+		// After successful save, publish detection events for statistics consumers and new species
+		a.publishDetectionEvents(isNewSpecies, daysSinceFirstSeen)
+	}
 
 	// Save audio clip to file if enabled
 	if a.Settings.Realtime.Audio.Export.Enabled {
@@ -568,7 +653,7 @@ func (a *DatabaseAction) Execute(data interface{}) error {
 			"operation", "note_begin_end_capture_length")
 
 		// export audio clip from capture buffer
-		pcmData, err := myaudio.ReadSegmentFromCaptureBuffer(a.Note.Source.ID, a.Note.BeginTime, captureLength)
+		pcmData, err := myaudio.ReadSegmentFromCaptureBuffer(context.Background(), a.Note.Source.ID, a.Note.BeginTime, captureLength, ClipCaptureTimeout)
 		if err != nil {
 			// Add structured logging
 			GetLogger().Error("Failed to read audio segment from buffer",
@@ -584,6 +669,12 @@ func (a *DatabaseAction) Execute(data interface{}) error {
 			return err
 		}
 
+		// Apply the calibration gain offset of the source, captured audio is never calibrated
+		pcmData = myaudio.ApplySourceCalibration(a.Note.Source.ID, pcmData)
+
+		// Mute human voices in the clip when the privacy filter redacts audio
+		pcmData = a.processor.redactHumanVoices(a.Note.Source.ID, a.Note.BeginTime, pcmData, a.CorrelationID)
+
 		// Create a SaveAudioAction and execute it
 		saveAudioAction := &SaveAudioAction{
 			Settings: a.Settings,
@@ -636,6 +727,15 @@ func isEOFError(err error) bool {
 	return strings.Contains(strings.ToLower(err.Error()), "eof")
 }
 
+// publishDetectionEvents publishes the detection events of a saved note
+func (a *DatabaseAction) publishDetectionEvents(isNewSpecies bool, daysSinceFirstSeen int) {
+	a.publishSavedDetectionEvent(daysSinceFirstSeen)
+	a.publishNewSpeciesDetectionEvent(isNewSpecies, daysSinceFirstSeen)
+	if !isNewSpecies {
+		a.publishRareSpeciesDetectionEvent(daysSinceFirstSeen)
+	}
+}
+
 // publishNewSpeciesDetectionEvent publishes a detection event for new species
 // This helper method handles event bus retrieval, event creation, publishing, and debug logging
 func (a *DatabaseAction) publishNewSpeciesDetectionEvent(isNewSpecies bool, daysSinceFirstSeen int) {
@@ -720,6 +820,99 @@ func (a *DatabaseAction) publishNewSpeciesDetectionEvent(isNewSpecies bool, days
 	}
 }
 
+// publishSavedDetectionEvent publishes a detection event for every saved note, for consumers
+// that keep running statistics of all detections. Notification consumers ignore these events.
+func (a *DatabaseAction) publishSavedDetectionEvent(daysSinceFirstSeen int) {
+	if !events.IsInitialized() {
+		return
+	}
+	eventBus := events.GetEventBus()
+	if eventBus == nil {
+		return
+	}
+
+	detectionEvent, err := events.NewDetectionEvent(
+		a.Note.CommonName,
+		a.Note.ScientificName,
+		a.Note.Confidence,
+		a.Note.Source.DisplayName,
+		false,
+		daysSinceFirstSeen,
+	)
+	if err != nil {
+		GetLogger().Debug("Failed to create saved detection event",
+			"component", "analysis.processor.actions",
+			"detection_id", a.CorrelationID,
+			"error", err,
+			"operation", "create_saved_detection_event")
+		return
+	}
+	detectionEvent.GetMetadata()[events.MetadataNoteID] = a.Note.ID
+
+	// Dropped events only make the statistics miss a detection
+	eventBus.TryPublishDetection(detectionEvent)
+}
+
+// publishRareSpeciesDetectionEvent publishes a detection event when the rarity score of the
+// note reaches the configured notification threshold. New species are notified separately.
+func (a *DatabaseAction) publishRareSpeciesDetectionEvent(daysSinceFirstSeen int) {
+	threshold := a.Settings.Realtime.Rarity.NotifyThreshold
+	if threshold <= 0 || a.Note.RarityScore == nil || *a.Note.RarityScore < threshold {
+		return
+	}
+	if a.processor == nil || a.processor.rarity == nil || !events.IsInitialized() {
+		return
+	}
+	eventBus := events.GetEventBus()
+	if eventBus == nil {
+		return
+	}
+
+	now := time.Now()
+	if !a.processor.rarity.shouldNotify(a.Note.ScientificName, now) {
+		if a.Settings.Debug {
+			GetLogger().Debug("Suppressing repeated rare species notification",
+				"component", "analysis.processor.actions",
+				"detection_id", a.CorrelationID,
+				"species", a.Note.CommonName,
+				"operation", "suppress_rarity_notification")
+		}
+		return
+	}
+
+	detectionEvent, err := events.NewDetectionEvent(
+		a.Note.CommonName,
+		a.Note.ScientificName,
+		a.Note.Confidence,
+		a.Note.Source.DisplayName,
+		false,
+		daysSinceFirstSeen,
+	)
+	if err != nil {
+		GetLogger().Debug("Failed to create rare species detection event",
+			"component", "analysis.processor.actions",
+			"detection_id", a.CorrelationID,
+			"error", err,
+			"operation", "create_rarity_detection_event")
+		return
+	}
+	detectionEvent.GetMetadata()[events.MetadataRarityScore] = *a.Note.RarityScore
+
+	if !eventBus.TryPublishDetection(detectionEvent) {
+		return
+	}
+	a.processor.rarity.recordNotification(a.Note.ScientificName, now)
+
+	if a.Settings.Debug {
+		GetLogger().Debug("Published rare species detection event",
+			"component", "analysis.processor.actions",
+			"detection_id", a.CorrelationID,
+			"species", a.Note.CommonName,
+			"rarity_score", *a.Note.RarityScore,
+			"operation", "publish_rarity_detection_event")
+	}
+}
+
 // Execute saves the audio clip to a file
 func (a *SaveAudioAction) Execute(data interface{}) error {
 	a.mu.Lock()
@@ -757,9 +950,10 @@ func (a *SaveAudioAction) Execute(data interface{}) error {
 			return err
 		}
 	} else {
-		if err := myaudio.ExportAudioWithFFmpeg(a.pcmData, outputPath, &a.Settings.Realtime.Audio); err != nil {
+		// Exported with FFmpeg, or with SoX when FFmpeg is not available
+		if err := myaudio.ExportAudio(a.pcmData, outputPath, &a.Settings.Realtime.Audio); err != nil {
 			// Add structured logging
-			GetLogger().Error("Failed to export audio clip with FFmpeg",
+			GetLogger().Error("Failed to export audio clip",
 				"component", "analysis.processor.actions",
 				"detection_id", a.CorrelationID,
 				"error", err,
@@ -767,7 +961,7 @@ func (a *SaveAudioAction) Execute(data interface{}) error {
 				"clip_name", a.ClipName,
 				"format", a.Settings.Realtime.Audio.Export.Type,
 				"operation", "ffmpeg_export")
-			log.Printf("❌ Error exporting audio clip with FFmpeg")
+			log.Printf("❌ Error exporting audio clip")
 			return err
 		}
 	}
@@ -809,6 +1003,24 @@ func (a *BirdWeatherAction) Execute(data interface{}) error {
 		return nil
 	}
 
+	// Check the daily upload limit of the species, once per detection
+	if a.quota != nil && !a.quotaCounted {
+		quotaSettings := &a.Settings.Realtime.Birdweather.UploadQuota
+		if !a.quota.allow(quotaSettings, a.Note.CommonName, a.Note.ScientificName, time.Now()) {
+			if a.Settings.Debug {
+				GetLogger().Debug("Skipping BirdWeather upload due to daily species quota",
+					"component", "analysis.processor.actions",
+					"detection_id", a.CorrelationID,
+					"species", speciesName,
+					"limit", speciesLimit(quotaSettings, a.Note.CommonName, a.Note.ScientificName),
+					"operation", "birdweather_quota_check")
+				log.Printf("⛔ Skipping BirdWeather upload for %s: daily upload quota reached\n", speciesName)
+			}
+			return nil
+		}
+		a.quotaCounted = true
+	}
+
 	// Safe check for nil BwClient
 	if a.BwClient == nil {
 		// Client initialization failures indicate configuration issues that require
@@ -890,11 +1102,24 @@ type NoteWithBirdImage struct {
 	BirdImage imageprovider.BirdImage
 }
 
+// scrubMQTTSource anonymizes the audio source of a note published over MQTT with the
+// privacy policy, the sanitized connection string still holds host names and stream paths
+func scrubMQTTSource(source *datastore.AudioSource) {
+	policy := privacy.CurrentPolicy()
+	source.SafeString = policy.Scrub(source.SafeString)
+	source.DisplayName = policy.Scrub(source.DisplayName)
+}
+
 // Execute sends the note to the MQTT broker
 func (a *MqttAction) Execute(data interface{}) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Skip publishing detections that fell behind under overload
+	if a.deadline.shed("mqtt", a.CorrelationID, a.Note.CommonName) {
+		return nil
+	}
+
 	// Rely on background reconnect; fail action if not currently connected.
 	if !a.MqttClient.IsConnected() {
 		// Log slightly differently to indicate it's waiting for background reconnect
@@ -958,6 +1183,9 @@ func (a *MqttAction) Execute(data interface{}) error {
 				"operation", "get_bird_image")
 			log.Printf("⚠️ Error getting bird image from cache for %s: %v", a.Note.ScientificName, err)
 			// Continue with the default empty image
+		} else {
+			// Record license/author usage in the attribution ledger
+			a.BirdImageCache.RecordUsage(&birdImage, imageprovider.UsageMQTT)
 		}
 	} else {
 		// Log if the cache is nil, maybe helpful for debugging setup issues
@@ -973,6 +1201,9 @@ func (a *MqttAction) Execute(data interface{}) error {
 
 	// Create a copy of the Note (source is already sanitized in SafeString field)
 	noteCopy := a.Note
+	if a.Settings.Privacy.ScrubMQTT {
+		scrubMQTTSource(&noteCopy.Source)
+	}
 
 	// Wrap note with bird image (using copy)
 	noteWithBirdImage := NoteWithBirdImage{Note: noteCopy, BirdImage: birdImage}
@@ -992,6 +1223,12 @@ func (a *MqttAction) Execute(data interface{}) error {
 		return err
 	}
 
+	// Queue the message when detections are published in batches
+	if a.batcher != nil && a.Settings.Realtime.MQTT.Batch.Enabled {
+		a.batcher.add(a.Settings.Realtime.MQTT.Topic, noteJson)
+		return nil
+	}
+
 	// Create a context with timeout for publishing
 	ctx, cancel := context.WithTimeout(context.Background(), MQTTPublishTimeout)
 	defer cancel()
@@ -1100,6 +1337,11 @@ func (a *SSEAction) Execute(data interface{}) error {
 		return nil // Silently skip if no broadcaster is configured
 	}
 
+	// Skip the broadcast of detections that fell behind under overload
+	if a.deadline.shed("sse", a.CorrelationID, a.Note.CommonName) {
+		return nil
+	}
+
 	speciesName := strings.ToLower(a.Note.CommonName)
 
 	// Check event frequency
@@ -1132,7 +1374,11 @@ func (a *SSEAction) Execute(data interface{}) error {
 
 	// Wait for database ID to be assigned if Note.ID is 0 (new detection)
 	// This ensures the frontend can properly load audio/spectrogram via API endpoints
-	if a.Note.ID == 0 {
+	if a.Note.ID == 0 && a.IDPromise != nil {
+		// The database ID if already saved, otherwise the provisional ID that API endpoints
+		// resolve to the database ID once the save completes
+		a.Note.ID = a.IDPromise.ID()
+	} else if a.Note.ID == 0 {
 		if err := a.waitForDatabaseID(); err != nil {
 			// Log warning but don't fail the SSE broadcast
 			// Add structured logging
@@ -1164,6 +1410,9 @@ func (a *SSEAction) Execute(data interface{}) error {
 				"operation", "get_bird_image")
 			log.Printf("⚠️ Error getting bird image from cache for %s: %v", a.Note.ScientificName, err)
 			// Continue with the default empty image
+		} else {
+			// Record license/author usage in the attribution ledger
+			a.BirdImageCache.RecordUsage(&birdImage, imageprovider.UsageSSE)
 		}
 	} else {
 		// Log if the cache is nil, maybe helpful for debugging setup issues
@@ -0,0 +1,37 @@
+// analysis_snapshot.go: model and analysis settings recorded with each detection
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// analysisSnapshot returns the model, application version and analysis settings in effect for
+// a detection from a source of sourceType
+func (p *Processor) analysisSnapshot(sourceType myaudio.SourceType) *datastore.AnalysisSnapshot {
+	var modelVersion string
+	if p.Bn != nil {
+		modelVersion = p.Bn.ModelInfo.ID
+		if p.Bn.ModelInfo.CustomPath != "" {
+			modelVersion += " (" + filepath.Base(p.Bn.ModelInfo.CustomPath) + ")"
+		}
+	}
+
+	return &datastore.AnalysisSnapshot{
+		ModelVersion: modelVersion,
+		AppVersion:   p.Settings.Version,
+		Overlap:      p.Settings.BirdNET.Overlap,
+		Sensitivity:  p.Settings.BirdNET.Sensitivity,
+		Threshold:    p.Settings.BirdNET.Threshold,
+		SourceFormat: sourceFormat(sourceType),
+	}
+}
+
+// sourceFormat describes the source type and the audio format fed to the model
+func sourceFormat(sourceType myaudio.SourceType) string {
+	return fmt.Sprintf("%s %dHz %dbit %dch", sourceType, conf.SampleRate, conf.BitDepth, conf.NumChannels)
+}
@@ -0,0 +1,266 @@
+// collection.go: training data collection mode storing analyzed segments with their results
+package processor
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+const (
+	// collectionMaxWrites is the number of segments written concurrently, further segments
+	// are dropped while the disk is busy so analysis is never held up
+	collectionMaxWrites = 2
+
+	// collectionTopResults is the number of BirdNET results stored with each segment
+	collectionTopResults = 10
+
+	// collectionCleanupInterval is how often expired segments are removed
+	collectionCleanupInterval = time.Hour
+)
+
+// collectedSegment is the metadata file stored next to each collected segment
+type collectedSegment struct {
+	Source     string            `json:"source"`
+	Profile    string            `json:"profile,omitempty"`
+	StartTime  time.Time         `json:"startTime"`
+	Duration   float64           `json:"duration"` // seconds
+	SampleRate int               `json:"sampleRate"`
+	Level      float64           `json:"level"` // RMS level in dBFS
+	Latitude   float64           `json:"latitude"`
+	Longitude  float64           `json:"longitude"`
+	Results    []collectedResult `json:"results"` // highest confidence BirdNET results
+}
+
+// collectedResult is a BirdNET result of a collected segment
+type collectedResult struct {
+	Species    string  `json:"species"` // BirdNET label, "Scientific name_Common name"
+	Confidence float32 `json:"confidence"`
+}
+
+// segmentCollector stores analyzed segments above the level threshold while collection mode
+// is enabled, enforcing the disk quota and retention. It is shared by the default pipeline
+// and its profiles.
+type segmentCollector struct {
+	settings *conf.Settings
+	writes   chan struct{} // limits concurrent writes to collectionMaxWrites
+	wg       sync.WaitGroup
+
+	mu          sync.Mutex
+	usedBytes   int64     // size of the collected segments, valid once cleaned up
+	lastCleanup time.Time // zero until the collection folder has been scanned
+}
+
+// newSegmentCollector creates a collector for the collection mode settings
+func newSegmentCollector(settings *conf.Settings) *segmentCollector {
+	return &segmentCollector{
+		settings: settings,
+		writes:   make(chan struct{}, collectionMaxWrites),
+	}
+}
+
+// collect stores the segment in the background when collection mode is enabled and the
+// segment is loud enough
+func (c *segmentCollector) collect(item *birdnet.Results, profile string) {
+	if c == nil || len(item.PCMdata) == 0 {
+		return
+	}
+	settings := c.settings.Realtime.Collection
+	if !settings.Enabled {
+		return
+	}
+
+	level := segmentLevel(item.PCMdata)
+	if level < settings.MinLevel {
+		return
+	}
+
+	select {
+	case c.writes <- struct{}{}:
+	default:
+		GetLogger().Debug("Collection writes busy, skipping segment",
+			"component", "analysis.processor.collection",
+			"source", item.Source.ID,
+			"start_time", item.StartTime)
+		return
+	}
+
+	segment := collectedSegment{
+		Source:     item.Source.ID,
+		Profile:    profile,
+		StartTime:  item.StartTime,
+		Duration:   float64(len(item.PCMdata)) / float64(conf.SampleRate*conf.BitDepth/8),
+		SampleRate: conf.SampleRate,
+		Level:      level,
+		Latitude:   c.settings.BirdNET.Latitude,
+		Longitude:  c.settings.BirdNET.Longitude,
+		Results:    topResults(item.Results, collectionTopResults),
+	}
+	pcm := slices.Clone(item.PCMdata) // pooled audio is only valid while the item is processed
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() { <-c.writes }()
+		c.save(&settings, &segment, pcm)
+	}()
+}
+
+// save writes a segment and its metadata and enforces the quota and retention
+func (c *segmentCollector) save(settings *conf.CollectionSettings, segment *collectedSegment, pcm []byte) {
+	dir := filepath.Join(settings.Path, segment.StartTime.Format(time.DateOnly))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		GetLogger().Warn("Could not create collection directory", "directory", dir, "error", err)
+		return
+	}
+
+	base := filepath.Join(dir, collectionFileName(segment.Source, segment.StartTime))
+	metadata, err := json.MarshalIndent(segment, "", "  ")
+	if err != nil {
+		GetLogger().Warn("Could not encode collected segment metadata", "error", err)
+		return
+	}
+	if err := myaudio.SavePCMDataToWAV(base+".wav", pcm); err != nil {
+		GetLogger().Warn("Could not save collected segment", "filename", base+".wav", "error", err)
+		return
+	}
+	if err := os.WriteFile(base+".json", metadata, 0o644); err != nil { //nolint:gosec // metadata is not sensitive
+		GetLogger().Warn("Could not save collected segment metadata", "filename", base+".json", "error", err)
+		_ = os.Remove(base + ".wav")
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usedBytes += int64(len(pcm)) + int64(len(metadata))
+	quota := int64(settings.MaxSizeMB) * 1024 * 1024
+	if c.lastCleanup.IsZero() || c.usedBytes > quota || time.Since(c.lastCleanup) >= collectionCleanupInterval {
+		c.cleanupLocked(settings, time.Now())
+	}
+}
+
+// collectionFile is a collected file considered for removal
+type collectionFile struct {
+	path string
+	day  string
+	size int64
+}
+
+// cleanupLocked removes the segments of expired days, then the oldest segments until the
+// collection fits the quota. The caller must hold c.mu.
+func (c *segmentCollector) cleanupLocked(settings *conf.CollectionSettings, now time.Time) {
+	c.lastCleanup = now
+	cutoff := now.AddDate(0, 0, -settings.RetentionDays).Format(time.DateOnly)
+
+	var files []collectionFile
+	var used int64
+	days, err := os.ReadDir(settings.Path)
+	if err != nil {
+		GetLogger().Warn("Could not read collection directory", "directory", settings.Path, "error", err)
+		return
+	}
+	for _, day := range days {
+		if !day.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, day.Name()); err != nil {
+			continue // not a collection day folder
+		}
+		dayDir := filepath.Join(settings.Path, day.Name())
+		if day.Name() < cutoff {
+			if err := os.RemoveAll(dayDir); err != nil {
+				GetLogger().Warn("Could not remove expired collection folder", "directory", dayDir, "error", err)
+			}
+			continue
+		}
+
+		entries, err := os.ReadDir(dayDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() {
+				continue
+			}
+			files = append(files, collectionFile{path: filepath.Join(dayDir, entry.Name()), day: dayDir, size: info.Size()})
+			used += info.Size()
+		}
+	}
+
+	// File names start with the segment time within a day folder, so sorting by path
+	// orders segments from the oldest
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	quota := int64(settings.MaxSizeMB) * 1024 * 1024
+	for _, file := range files {
+		if used <= quota {
+			break
+		}
+		if err := os.Remove(file.path); err != nil {
+			GetLogger().Warn("Could not remove collected file", "filename", file.path, "error", err)
+			continue
+		}
+		used -= file.size
+		_ = os.Remove(file.day) // removes the day folder once empty
+	}
+	c.usedBytes = used
+}
+
+// close waits for segments being written
+func (c *segmentCollector) close() {
+	c.wg.Wait()
+}
+
+// collectionFileName returns the file name of a segment without extension, starting with
+// its time so names sort in collection order
+func collectionFileName(source string, startTime time.Time) string {
+	safeSource := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, source)
+	return startTime.Format("150405.000") + "_" + safeSource
+}
+
+// segmentLevel returns the RMS level of 16-bit PCM audio in dBFS
+func segmentLevel(pcm []byte) float64 {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return math.Inf(-1)
+	}
+	var sum float64
+	for i := range samples {
+		sample := float64(int16(uint16(pcm[i*2])|uint16(pcm[i*2+1])<<8)) / 32768
+		sum += sample * sample
+	}
+	rms := math.Sqrt(sum / float64(samples))
+	if rms == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(rms)
+}
+
+// topResults returns up to n results with the highest confidence
+func topResults(results []datastore.Results, n int) []collectedResult {
+	top := make([]collectedResult, 0, len(results))
+	for _, result := range results {
+		top = append(top, collectedResult{Species: result.Species, Confidence: result.Confidence})
+	}
+	sort.SliceStable(top, func(i, j int) bool { return top[i].Confidence > top[j].Confidence })
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
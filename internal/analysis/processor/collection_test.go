@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// pcmTone returns 16-bit PCM audio of constant amplitude
+func pcmTone(samples int, amplitude int16) []byte {
+	pcm := make([]byte, samples*2)
+	for i := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(amplitude))
+	}
+	return pcm
+}
+
+func TestSegmentCollectorStoresLoudSegments(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Collection = conf.CollectionSettings{
+		Enabled: true, Path: t.TempDir(), MinLevel: -40, MaxSizeMB: 10, RetentionDays: 14,
+	}
+	collector := newSegmentCollector(settings)
+
+	start := time.Now()
+	loud := &birdnet.Results{
+		StartTime: start,
+		PCMdata:   pcmTone(4800, 8192), // about -12 dBFS
+		Source:    datastore.AudioSource{ID: "rtsp_1"},
+		Results: []datastore.Results{
+			{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.4},
+			{Species: "Parus major_Great Tit", Confidence: 0.9},
+		},
+	}
+	quiet := &birdnet.Results{
+		StartTime: start.Add(3 * time.Second),
+		PCMdata:   pcmTone(4800, 10), // about -70 dBFS
+		Source:    datastore.AudioSource{ID: "rtsp_1"},
+	}
+	collector.collect(loud, "")
+	collector.collect(quiet, "")
+	collector.close()
+
+	dayDir := filepath.Join(settings.Realtime.Collection.Path, start.Format(time.DateOnly))
+	entries, err := os.ReadDir(dayDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "only the loud segment and its metadata are stored")
+
+	base := filepath.Join(dayDir, collectionFileName("rtsp_1", start))
+	assert.FileExists(t, base+".wav")
+	data, err := os.ReadFile(base + ".json")
+	require.NoError(t, err)
+	var segment collectedSegment
+	require.NoError(t, json.Unmarshal(data, &segment))
+	assert.Equal(t, "rtsp_1", segment.Source)
+	assert.InDelta(t, 0.1, segment.Duration, 0.001)
+	assert.InDelta(t, -12, segment.Level, 0.1)
+	require.Len(t, segment.Results, 2)
+	assert.Equal(t, "Parus major_Great Tit", segment.Results[0].Species, "results sorted by confidence")
+}
+
+func TestSegmentCollectorCleanup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	settings := conf.CollectionSettings{Enabled: true, Path: dir, MaxSizeMB: 1, RetentionDays: 7}
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.Local)
+
+	writeFile := func(day, name string, size int) string {
+		t.Helper()
+		path := filepath.Join(dir, day, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0o600))
+		return path
+	}
+	expired := writeFile("2026-05-01", "060000.000_rtsp_1.wav", 1024)
+	oldest := writeFile("2026-05-19", "060000.000_rtsp_1.wav", 600*1024)
+	newest := writeFile("2026-05-20", "060000.000_rtsp_1.wav", 600*1024)
+
+	collector := newSegmentCollector(&conf.Settings{})
+	collector.mu.Lock()
+	collector.cleanupLocked(&settings, now)
+	collector.mu.Unlock()
+
+	assert.NoFileExists(t, expired, "expired day is removed")
+	assert.NoDirExists(t, filepath.Dir(expired))
+	assert.NoFileExists(t, oldest, "oldest segment is removed to fit the quota")
+	assert.NoDirExists(t, filepath.Dir(oldest), "empty day folder is removed")
+	assert.FileExists(t, newest)
+	assert.Equal(t, int64(600*1024), collector.usedBytes)
+}
@@ -0,0 +1,237 @@
+// command_guard.go: rate limits of ExecuteCommand actions
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// commandGuardWindow is the rolling window of the run and rejection limits
+const commandGuardWindow = time.Hour
+
+// commandLimits are the limits of a single command, 0 for no limit
+type commandLimits struct {
+	maxPerHour    int
+	maxConcurrent int
+	tripAfter     int
+}
+
+// commandLimitsFor returns the limits of a species action, its own limits take precedence
+// over the global ones
+func commandLimitsFor(settings *conf.CommandGuardSettings, action *conf.SpeciesAction) commandLimits {
+	if !settings.Enabled {
+		return commandLimits{}
+	}
+	limits := commandLimits{
+		maxPerHour:    settings.MaxPerHour,
+		maxConcurrent: settings.MaxConcurrent,
+		tripAfter:     settings.TripAfter,
+	}
+	if action.MaxPerHour > 0 {
+		limits.maxPerHour = action.MaxPerHour
+	}
+	if action.MaxConcurrent > 0 {
+		limits.maxConcurrent = action.MaxConcurrent
+	}
+	return limits
+}
+
+// commandState holds the counters of a single command
+type commandState struct {
+	runs          []time.Time // run start times within the window, oldest first
+	rejections    []time.Time // rejection times within the window, oldest first
+	running       int
+	totalRuns     int64
+	totalRejected int64
+	lastReason    string    // reason of the most recent rejection
+	disabledAt    time.Time // zero while the command is enabled
+}
+
+// prune drops run and rejection times that have left the window
+func (s *commandState) prune(now time.Time) {
+	cutoff := now.Add(-commandGuardWindow)
+	s.runs = dropBefore(s.runs, cutoff)
+	s.rejections = dropBefore(s.rejections, cutoff)
+}
+
+// dropBefore removes the leading times before cutoff from a sorted slice
+func dropBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := sort.Search(len(times), func(i int) bool { return !times[i].Before(cutoff) })
+	return times[i:]
+}
+
+// CommandGuardStatus describes the counters of a command run by ExecuteCommand actions
+type CommandGuardStatus struct {
+	Command            string     `json:"command"`
+	Running            int        `json:"running"`
+	RunsLastHour       int        `json:"runs_last_hour"`
+	RejectionsLastHour int        `json:"rejections_last_hour"`
+	TotalRuns          int64      `json:"total_runs"`
+	TotalRejected      int64      `json:"total_rejected"`
+	LastRejection      string     `json:"last_rejection,omitempty"`
+	Disabled           bool       `json:"disabled"`
+	DisabledAt         *time.Time `json:"disabled_at,omitempty"`
+}
+
+// commandGuard enforces the run limits of ExecuteCommand actions per command and disables
+// commands that keep exceeding them. It is shared by the default pipeline and its profiles.
+type commandGuard struct {
+	mu       sync.Mutex
+	commands map[string]*commandState // keyed by cleaned command path
+}
+
+// newCommandGuard creates a command guard without tracked commands
+func newCommandGuard() *commandGuard {
+	return &commandGuard{commands: make(map[string]*commandState)}
+}
+
+// acquire counts a run of command at now when it is within its limits, and returns a
+// function to call when the run has finished. A rejected run is counted towards the trip
+// limit, reaching it disables the command and sends a notification.
+func (g *commandGuard) acquire(command string, limits commandLimits, now time.Time) (release func(), err error) {
+	if g == nil {
+		return func() {}, nil
+	}
+	command = filepath.Clean(command)
+
+	g.mu.Lock()
+	state, ok := g.commands[command]
+	if !ok {
+		state = &commandState{}
+		g.commands[command] = state
+	}
+	state.prune(now)
+
+	var reason string
+	switch {
+	case !state.disabledAt.IsZero():
+		reason = "disabled"
+	case limits.maxConcurrent > 0 && state.running >= limits.maxConcurrent:
+		reason = fmt.Sprintf("%d runs already in progress", state.running)
+	case limits.maxPerHour > 0 && len(state.runs) >= limits.maxPerHour:
+		reason = fmt.Sprintf("%d runs within the last hour", len(state.runs))
+	}
+
+	if reason == "" {
+		state.runs = append(state.runs, now)
+		state.running++
+		state.totalRuns++
+		g.mu.Unlock()
+
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				g.mu.Lock()
+				state.running--
+				g.mu.Unlock()
+			})
+		}, nil
+	}
+
+	state.totalRejected++
+	state.lastReason = reason
+	tripped := false
+	if state.disabledAt.IsZero() {
+		state.rejections = append(state.rejections, now)
+		if limits.tripAfter > 0 && len(state.rejections) >= limits.tripAfter {
+			state.disabledAt = now
+			tripped = true
+		}
+	}
+	rejections := len(state.rejections)
+	g.mu.Unlock()
+
+	if tripped {
+		GetLogger().Warn("Command disabled after repeatedly exceeding its limits",
+			"command", command,
+			"rejections", rejections,
+			"reason", reason,
+			"operation", "command_guard_trip")
+		notification.NotifyWarning("analysis.processor",
+			"Custom action command disabled",
+			fmt.Sprintf("%s was rejected %d times within an hour (%s) and has been disabled. Check the species action configuration and re-enable it in the control API.",
+				command, rejections, reason))
+	}
+
+	return nil, errors.Newf("command %s rejected by rate limits: %s", command, reason).
+		Component("analysis.processor").
+		Category(errors.CategoryLimit).
+		Context("operation", "execute_command").
+		Context("reason", reason).
+		Context("retryable", false).
+		Build()
+}
+
+// status returns the counters of all tracked commands sorted by command
+func (g *commandGuard) status(now time.Time) []CommandGuardStatus {
+	if g == nil {
+		return []CommandGuardStatus{}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status := make([]CommandGuardStatus, 0, len(g.commands))
+	for command, state := range g.commands {
+		state.prune(now)
+		entry := CommandGuardStatus{
+			Command:            command,
+			Running:            state.running,
+			RunsLastHour:       len(state.runs),
+			RejectionsLastHour: len(state.rejections),
+			TotalRuns:          state.totalRuns,
+			TotalRejected:      state.totalRejected,
+			LastRejection:      state.lastReason,
+			Disabled:           !state.disabledAt.IsZero(),
+		}
+		if entry.Disabled {
+			disabledAt := state.disabledAt
+			entry.DisabledAt = &disabledAt
+		}
+		status = append(status, entry)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Command < status[j].Command })
+	return status
+}
+
+// enable re-enables a command and clears its rejections of the current window. It reports
+// false when the command has never been run.
+func (g *commandGuard) enable(command string) bool {
+	if g == nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state, ok := g.commands[filepath.Clean(command)]
+	if !ok {
+		return false
+	}
+	state.disabledAt = time.Time{}
+	state.rejections = nil
+	return true
+}
+
+// CommandGuardStatus returns the run counters and disabled state of the commands run by
+// ExecuteCommand actions
+func (p *Processor) CommandGuardStatus() []CommandGuardStatus {
+	return p.cmdGuard.status(time.Now())
+}
+
+// EnableCommand re-enables a command disabled for exceeding its limits. It reports false
+// when the command has never been run.
+func (p *Processor) EnableCommand(command string) bool {
+	enabled := p.cmdGuard.enable(command)
+	if enabled {
+		GetLogger().Info("Command re-enabled", "command", command, "operation", "command_guard_enable")
+	}
+	return enabled
+}
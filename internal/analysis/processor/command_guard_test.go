@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestCommandLimitsFor(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.CommandGuardSettings{Enabled: true, MaxPerHour: 60, MaxConcurrent: 2, TripAfter: 20}
+
+	assert.Equal(t, commandLimits{maxPerHour: 60, maxConcurrent: 2, tripAfter: 20},
+		commandLimitsFor(settings, &conf.SpeciesAction{}))
+	assert.Equal(t, commandLimits{maxPerHour: 5, maxConcurrent: 1, tripAfter: 20},
+		commandLimitsFor(settings, &conf.SpeciesAction{MaxPerHour: 5, MaxConcurrent: 1}), "action limits take precedence")
+
+	settings.Enabled = false
+	assert.Equal(t, commandLimits{}, commandLimitsFor(settings, &conf.SpeciesAction{MaxPerHour: 5}))
+}
+
+func TestCommandGuardLimits(t *testing.T) {
+	t.Parallel()
+
+	guard := newCommandGuard()
+	limits := commandLimits{maxPerHour: 3, maxConcurrent: 1}
+	now := time.Date(2026, 5, 10, 6, 0, 0, 0, time.Local)
+
+	release, err := guard.acquire("/usr/local/bin/notify.sh", limits, now)
+	require.NoError(t, err)
+	_, err = guard.acquire("/usr/local/bin/notify.sh", limits, now)
+	require.Error(t, err, "a run is already in progress")
+	release()
+	release() // releasing twice counts once
+
+	for i := range 2 {
+		release, err = guard.acquire("/usr/local/bin/notify.sh", limits, now.Add(time.Duration(i+1)*time.Minute))
+		require.NoError(t, err)
+		release()
+	}
+	_, err = guard.acquire("/usr/local/bin/notify.sh", limits, now.Add(10*time.Minute))
+	require.Error(t, err, "hourly limit reached")
+
+	release, err = guard.acquire("/usr/local/bin/notify.sh", limits, now.Add(time.Hour+time.Second))
+	require.NoError(t, err, "the first run has left the window")
+	release()
+
+	status := guard.status(now.Add(time.Hour + time.Second))
+	require.Len(t, status, 1)
+	assert.Equal(t, "/usr/local/bin/notify.sh", status[0].Command)
+	assert.Equal(t, 3, status[0].RunsLastHour)
+	assert.Equal(t, int64(4), status[0].TotalRuns)
+	assert.Equal(t, int64(2), status[0].TotalRejected)
+	assert.Zero(t, status[0].Running)
+	assert.False(t, status[0].Disabled)
+}
+
+func TestCommandGuardTripsAndReenables(t *testing.T) {
+	t.Parallel()
+
+	guard := newCommandGuard()
+	limits := commandLimits{maxPerHour: 1, tripAfter: 3}
+	now := time.Date(2026, 5, 10, 6, 0, 0, 0, time.Local)
+
+	release, err := guard.acquire("/opt/scripts/../scripts/run.sh", limits, now)
+	require.NoError(t, err)
+	release()
+	for i := range 3 {
+		_, err = guard.acquire("/opt/scripts/run.sh", limits, now.Add(time.Duration(i+1)*time.Minute))
+		require.Error(t, err)
+	}
+
+	status := guard.status(now.Add(5 * time.Minute))
+	require.Len(t, status, 1, "commands are tracked by cleaned path")
+	assert.True(t, status[0].Disabled)
+	require.NotNil(t, status[0].DisabledAt)
+
+	_, err = guard.acquire("/opt/scripts/run.sh", limits, now.Add(2*time.Hour))
+	require.Error(t, err, "a disabled command stays disabled after the window")
+
+	assert.False(t, guard.enable("/opt/scripts/other.sh"), "unknown command")
+	require.True(t, guard.enable("/opt/scripts/run.sh"))
+
+	release, err = guard.acquire("/opt/scripts/run.sh", limits, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	release()
+	status = guard.status(now.Add(2 * time.Hour))
+	assert.False(t, status[0].Disabled)
+	assert.Zero(t, status[0].RejectionsLastHour)
+}
+
+func TestNilCommandGuard(t *testing.T) {
+	t.Parallel()
+
+	var guard *commandGuard
+	release, err := guard.acquire("/usr/local/bin/notify.sh", commandLimits{maxPerHour: 1}, time.Now())
+	require.NoError(t, err)
+	release()
+	assert.Empty(t, guard.status(time.Now()))
+	assert.False(t, guard.enable("/usr/local/bin/notify.sh"))
+}
@@ -0,0 +1,255 @@
+// datastore_spool.go: degraded mode buffering detections on disk while the database is unavailable
+package processor
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/events"
+)
+
+// spoolRejectAttempts is the number of failed saves of a buffered detection, while the database
+// is reachable, after which the detection is moved to the rejected file instead of blocking the
+// detections buffered after it
+const spoolRejectAttempts = 5
+
+// detectionSpool runs the degraded mode of the database. When a detection cannot be saved the
+// mode is entered and detections are buffered on disk instead of being saved, until all buffered
+// detections were saved in order by the replay loop. Shared with profile processors.
+type detectionSpool struct {
+	spool    *datastore.Spool
+	ds       datastore.Interface
+	limit    int
+	interval time.Duration
+	onSaved  func(entry *datastore.SpooledNote) // runs the post-save side effects of a replayed detection, may be nil
+
+	degraded atomic.Bool // detections are buffered instead of saved
+	full     atomic.Bool // the buffer filled up since degraded mode was entered
+
+	// Replay loop state
+	replayed int // buffered detections saved since degraded mode was entered
+	failures int // failed saves of the oldest buffered detection while the database is reachable
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newDetectionSpool opens the detection buffer and starts its replay loop, or returns nil
+// when buffering is disabled or the buffer cannot be opened. Detections buffered by a previous
+// run keep the degraded mode until they are saved. onSaved is called with each replayed
+// detection once saved, with the database ID set.
+func newDetectionSpool(settings *conf.Settings, ds datastore.Interface, onSaved func(entry *datastore.SpooledNote)) *detectionSpool {
+	spoolSettings := settings.Output.Spool
+	if !spoolSettings.Enabled || ds == nil {
+		return nil
+	}
+
+	spool, err := datastore.OpenSpool(spoolSettings.Path, spoolSettings.MaxDetections)
+	if err != nil {
+		GetLogger().Error("Failed to open detection buffer, detections will be lost while the database is unavailable",
+			"path", spoolSettings.Path,
+			"error", err,
+			"operation", "open_detection_spool")
+		log.Printf("❌ Failed to open detection buffer %s: %v", spoolSettings.Path, err)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &detectionSpool{
+		spool:    spool,
+		ds:       ds,
+		limit:    spoolSettings.MaxDetections,
+		interval: time.Duration(spoolSettings.RetryInterval) * time.Second,
+		onSaved:  onSaved,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	if pending := spool.Len(); pending > 0 {
+		s.degraded.Store(true)
+		GetLogger().Info("Saving detections buffered while the database was unavailable",
+			"buffered_detections", pending,
+			"operation", "replay_detection_spool")
+	}
+
+	go s.run(ctx)
+	return s
+}
+
+// buffering reports whether detections are buffered instead of saved
+func (s *detectionSpool) buffering() bool {
+	return s != nil && s.degraded.Load()
+}
+
+// newSpooledNote returns a detection to buffer. Database IDs assigned by a failed save are
+// cleared, the detection is saved as new on replay.
+func newSpooledNote(note *datastore.Note, results []datastore.Results, sources []datastore.NoteSource) *datastore.SpooledNote {
+	entry := &datastore.SpooledNote{
+		Note:      *note,
+		Results:   make([]datastore.Results, len(results)),
+		Sources:   make([]datastore.NoteSource, len(sources)),
+		SpooledAt: time.Now(),
+	}
+	entry.Note.ID = 0
+	for i := range results {
+		entry.Results[i] = datastore.Results{Species: results[i].Species, Confidence: results[i].Confidence}
+	}
+	for i := range sources {
+		entry.Sources[i] = sources[i]
+		entry.Sources[i].ID = 0
+		entry.Sources[i].NoteID = 0
+	}
+	return entry
+}
+
+// add buffers a detection, entering degraded mode with cause if not yet in it
+func (s *detectionSpool) add(entry *datastore.SpooledNote, cause error) error {
+	if cause != nil && s.degraded.CompareAndSwap(false, true) {
+		GetLogger().Error("Database unavailable, buffering detections until it recovers",
+			"error", cause,
+			"buffer_limit", s.limit,
+			"operation", "enter_degraded_mode")
+		log.Printf("⚠️ Database unavailable, buffering detections on disk until it recovers")
+		s.publish(s.spool.Len(), events.SeverityWarning, map[string]interface{}{"error": cause.Error()})
+	}
+
+	err := s.spool.Add(entry)
+	if errors.Is(err, datastore.ErrSpoolFull) && s.full.CompareAndSwap(false, true) {
+		GetLogger().Error("Detection buffer full, detections are lost until the database recovers",
+			"buffer_limit", s.limit,
+			"operation", "detection_spool_full")
+		log.Printf("❌ Detection buffer full with %d detections, detections are lost until the database recovers", s.limit)
+		s.publish(s.limit, events.SeverityCritical, nil)
+	}
+	return err
+}
+
+// run replays the buffered detections every interval until ctx is cancelled
+func (s *detectionSpool) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.replay()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// replay saves the buffered detections and leaves degraded mode once all were saved
+func (s *detectionSpool) replay() {
+	if s.spool.Len() > 0 {
+		saved, err := s.spool.Replay(s.save)
+		s.replayed += saved
+		if err != nil {
+			GetLogger().Debug("Database still unavailable, keeping buffered detections",
+				"saved", saved,
+				"buffered_detections", s.spool.Len(),
+				"error", err,
+				"operation", "replay_detection_spool")
+			return
+		}
+	}
+
+	if s.spool.Len() > 0 || !s.degraded.Load() {
+		return
+	}
+	s.degraded.Store(false)
+	s.full.Store(false)
+	GetLogger().Info("Database recovered, saved buffered detections",
+		"saved", s.replayed,
+		"operation", "exit_degraded_mode")
+	log.Printf("✅ Database recovered, saved %d buffered detections", s.replayed)
+	s.publish(s.replayed, events.SeverityRecovery, nil)
+	s.replayed = 0
+}
+
+// save saves a buffered detection with its results and sources and runs its post-save side
+// effects. A detection the reachable database keeps refusing is moved to the rejected file, so
+// it does not block the detections after it.
+func (s *detectionSpool) save(entry *datastore.SpooledNote) error {
+	note := entry.Note
+	if err := s.ds.Save(&note, entry.Results); err != nil {
+		if datastore.IsUnavailableError(err) {
+			return err
+		}
+		if _, probeErr := s.ds.GetLastDetections(1); probeErr != nil {
+			return err
+		}
+		s.failures++
+		if s.failures < spoolRejectAttempts {
+			return err
+		}
+		s.failures = 0
+		datastore.ResolveBufferedNoteID(entry.NoteIDToken, 0)
+		GetLogger().Error("Database refuses buffered detection, moving it to the rejected detections",
+			"species", entry.Note.CommonName,
+			"begin_time", entry.Note.BeginTime,
+			"attempts", spoolRejectAttempts,
+			"error", err,
+			"operation", "reject_spooled_detection")
+		return s.spool.Reject(entry)
+	}
+	s.failures = 0
+	entry.Note.ID = note.ID
+	datastore.ResolveBufferedNoteID(entry.NoteIDToken, note.ID)
+
+	if sourceStore, ok := s.ds.(datastore.NoteSourceStore); ok && len(entry.Sources) > 0 {
+		if err := sourceStore.SaveNoteSources(note.ID, entry.Sources); err != nil {
+			GetLogger().Warn("Failed to save sources of buffered detection",
+				"note_id", note.ID,
+				"source_count", len(entry.Sources),
+				"error", err,
+				"operation", "save_note_sources")
+		}
+	}
+
+	if s.onSaved != nil {
+		s.onSaved(entry)
+	}
+	return nil
+}
+
+// replayedDetectionSaved publishes the detection events of a buffered detection once the replay
+// saved it, as DatabaseAction does for detections saved right away
+func (p *Processor) replayedDetectionSaved(entry *datastore.SpooledNote) {
+	action := &DatabaseAction{
+		Settings:          p.Settings,
+		Ds:                p.Ds,
+		Note:              entry.Note,
+		Results:           entry.Results,
+		NewSpeciesTracker: p.NewSpeciesTracker,
+		processor:         p,
+	}
+	action.publishDetectionEvents(entry.IsNewSpecies, entry.DaysSinceFirstSeen)
+}
+
+// publish publishes a degraded mode event on the event bus
+func (s *detectionSpool) publish(detections int, severity string, metadata map[string]interface{}) {
+	eventBus := events.GetEventBus()
+	if eventBus == nil {
+		return
+	}
+	if !eventBus.TryPublishResource(events.NewDatastoreEvent(detections, s.limit, severity, metadata)) {
+		GetLogger().Debug("Datastore event not published",
+			"severity", severity,
+			"operation", "publish_datastore_event")
+	}
+}
+
+// close stops the replay loop. Buffered detections stay on disk for the next run.
+func (s *detectionSpool) close() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
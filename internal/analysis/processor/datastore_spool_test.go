@@ -0,0 +1,161 @@
+package processor
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// flakyStore is a datastore that fails saves while down, or refuses them while reachable
+type flakyStore struct {
+	datastore.Interface
+	down   bool
+	refuse string // species whose saves fail while reachable
+	saved  []datastore.Note
+}
+
+func (f *flakyStore) Save(note *datastore.Note, results []datastore.Results) error {
+	if f.down {
+		return errors.New("database is locked")
+	}
+	if note.CommonName == f.refuse {
+		return errors.New("CHECK constraint failed: confidence")
+	}
+	note.ID = uint(len(f.saved) + 1)
+	f.saved = append(f.saved, *note)
+	return nil
+}
+
+func (f *flakyStore) GetLastDetections(numDetections int) ([]datastore.Note, error) {
+	if f.down {
+		return nil, errors.New("database is locked")
+	}
+	return nil, nil
+}
+
+// newTestDetectionSpool returns a detection spool in dir on store without a replay loop
+func newTestDetectionSpool(t *testing.T, dir string, store datastore.Interface, limit int) *detectionSpool {
+	t.Helper()
+	spool, err := datastore.OpenSpool(dir, limit)
+	require.NoError(t, err)
+	return &detectionSpool{spool: spool, ds: store, limit: limit}
+}
+
+func TestDatabaseActionBuffersWhileDatabaseUnavailable(t *testing.T) {
+	store := &flakyStore{down: true}
+	spool := newTestDetectionSpool(t, t.TempDir(), store, 10)
+	p := &Processor{Settings: &conf.Settings{}, spool: spool}
+
+	action := &DatabaseAction{
+		Settings:  p.Settings,
+		processor: p,
+		Ds:        store,
+		Note:      datastore.Note{CommonName: "Eurasian Blackbird"},
+	}
+	saved, err := action.saveNote(false, 0)
+	require.NoError(t, err, "a detection buffered on disk is not lost")
+	assert.False(t, saved)
+	assert.True(t, spool.buffering(), "a failed save enters degraded mode")
+
+	// Detections are buffered without trying the database while degraded
+	store.down = false
+	action.Note = datastore.Note{CommonName: "Great Tit"}
+	saved, err = action.saveNote(false, 0)
+	require.NoError(t, err)
+	assert.False(t, saved)
+	assert.Empty(t, store.saved)
+	assert.Equal(t, 2, spool.spool.Len())
+
+	spool.replay()
+	assert.False(t, spool.buffering(), "degraded mode ends once the buffer is saved")
+	require.Len(t, store.saved, 2)
+	assert.Equal(t, "Eurasian Blackbird", store.saved[0].CommonName, "buffered detections are saved in order")
+	assert.Equal(t, 0, spool.spool.Len())
+
+	action.Note = datastore.Note{CommonName: "Common Chaffinch"}
+	saved, err = action.saveNote(false, 0)
+	require.NoError(t, err)
+	assert.True(t, saved)
+}
+
+func TestDetectionSpoolKeepsDetectionsWhileDatabaseDown(t *testing.T) {
+	store := &flakyStore{down: true}
+	spool := newTestDetectionSpool(t, t.TempDir(), store, 1)
+
+	require.NoError(t, spool.add(newSpooledNote(&datastore.Note{CommonName: "Eurasian Wren"}, nil, nil), errors.New("database is locked")))
+	err := spool.add(newSpooledNote(&datastore.Note{CommonName: "Great Tit"}, nil, nil), nil)
+	require.ErrorIs(t, err, datastore.ErrSpoolFull)
+
+	for range spoolRejectAttempts + 1 {
+		spool.replay()
+	}
+	assert.True(t, spool.buffering(), "detections are kept while the database is down")
+	assert.Equal(t, 1, spool.spool.Len())
+}
+
+func TestDetectionSpoolRejectsRefusedDetection(t *testing.T) {
+	store := &flakyStore{refuse: "Eurasian Wren"}
+	dir := t.TempDir()
+	spool := newTestDetectionSpool(t, dir, store, 10)
+
+	require.NoError(t, spool.add(newSpooledNote(&datastore.Note{CommonName: "Eurasian Wren"}, nil, nil), errors.New("database is locked")))
+	require.NoError(t, spool.add(newSpooledNote(&datastore.Note{CommonName: "Great Tit"}, nil, nil), nil))
+
+	for range spoolRejectAttempts - 1 {
+		spool.replay()
+		assert.True(t, spool.buffering())
+	}
+	spool.replay()
+	assert.False(t, spool.buffering(), "a refused detection does not block the detections after it")
+	require.Len(t, store.saved, 1)
+	assert.Equal(t, "Great Tit", store.saved[0].CommonName)
+	assert.FileExists(t, filepath.Join(dir, "rejected.jsonl"))
+}
+
+func TestDatabaseActionDoesNotBufferRefusedNote(t *testing.T) {
+	store := &flakyStore{refuse: "Eurasian Wren"}
+	spool := newTestDetectionSpool(t, t.TempDir(), store, 10)
+	p := &Processor{Settings: &conf.Settings{}, spool: spool}
+
+	action := &DatabaseAction{
+		Settings:  p.Settings,
+		processor: p,
+		Ds:        store,
+		Note:      datastore.Note{CommonName: "Eurasian Wren"},
+	}
+	saved, err := action.saveNote(false, 0)
+	require.Error(t, err, "a note the reachable database refuses is not buffered")
+	assert.False(t, saved)
+	assert.False(t, spool.buffering(), "a refused note does not enter degraded mode")
+	assert.Equal(t, 0, spool.spool.Len())
+}
+
+func TestDetectionSpoolRunsSideEffectsOnReplay(t *testing.T) {
+	store := &flakyStore{down: true}
+	spool := newTestDetectionSpool(t, t.TempDir(), store, 10)
+	var replayed []datastore.SpooledNote
+	spool.onSaved = func(entry *datastore.SpooledNote) { replayed = append(replayed, *entry) }
+	p := &Processor{Settings: &conf.Settings{}, spool: spool}
+
+	action := &DatabaseAction{
+		Settings:  p.Settings,
+		processor: p,
+		Ds:        store,
+		Note:      datastore.Note{CommonName: "Eurasian Hoopoe"},
+	}
+	_, err := action.saveNote(true, 0)
+	require.NoError(t, err)
+	assert.Empty(t, replayed, "side effects wait for the save")
+
+	store.down = false
+	spool.replay()
+	require.Len(t, replayed, 1)
+	assert.Equal(t, "Eurasian Hoopoe", replayed[0].Note.CommonName)
+	assert.Equal(t, uint(1), replayed[0].Note.ID, "side effects get the database ID")
+	assert.True(t, replayed[0].IsNewSpecies, "the new species state of the detection is kept")
+}
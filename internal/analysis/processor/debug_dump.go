@@ -0,0 +1,68 @@
+// debug_dump.go: record raw BirdNET results while a debug capture session is active
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
+)
+
+// debugDumpDir is the directory BirdNET results are recorded to during debug capture
+var debugDumpDir = filepath.Join("debug", "processor")
+
+// debugDumpMu serializes appends to the results file across processors
+var debugDumpMu sync.Mutex
+
+// debugResultsEntry is one line of the debug results file
+type debugResultsEntry struct {
+	Profile   string              `json:"profile"`
+	Source    string              `json:"source"`
+	StartTime time.Time           `json:"startTime"`
+	ElapsedMs int64               `json:"elapsedMs"`
+	Results   []datastore.Results `json:"results"`
+}
+
+// dumpResults appends the raw results of an analyzed chunk to a daily JSON lines file when
+// debug capture is active for the processor, so filtering decisions can be traced afterwards
+func (p *Processor) dumpResults(item *birdnet.Results) {
+	if !debugcapture.Active(debugcapture.ComponentProcessor) {
+		return
+	}
+
+	line, err := json.Marshal(debugResultsEntry{
+		Profile:   p.profileName(),
+		Source:    item.Source.ID,
+		StartTime: item.StartTime,
+		ElapsedMs: item.ElapsedTime.Milliseconds(),
+		Results:   item.Results,
+	})
+	if err != nil {
+		GetLogger().Warn("Could not encode debug results", "error", err)
+		return
+	}
+
+	debugDumpMu.Lock()
+	defer debugDumpMu.Unlock()
+
+	if err := os.MkdirAll(debugDumpDir, 0o750); err != nil {
+		GetLogger().Warn("Could not create debug results directory", "directory", debugDumpDir, "error", err)
+		return
+	}
+	filename := filepath.Join(debugDumpDir, "results_"+item.StartTime.Format("20060102")+".jsonl")
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // fixed debug directory
+	if err != nil {
+		GetLogger().Warn("Could not open debug results file", "filename", filename, "error", err)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		GetLogger().Warn("Could not write debug results", "filename", filename, "error", err)
+	}
+}
@@ -0,0 +1,153 @@
+// detectors.go: additional acoustic event detectors sharing the detection pipeline
+package processor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Detector is an acoustic event detector run next to BirdNET, e.g. for gunshots, chainsaws,
+// amphibians or bat calls recorded through an ultrasonic front-end. Detectors receive the
+// same audio segments as BirdNET; their detections are held, filtered and acted on like bird
+// detections and are saved with the detector name.
+type Detector interface {
+	// Name identifies the detector in logs and is recorded with its detections
+	Name() string
+
+	// Detect analyzes an audio segment. It runs in the detection pipeline of the source and
+	// should return well within the analysis hop duration.
+	Detect(segment *AudioSegment) ([]DetectorResult, error)
+}
+
+// AudioSegment is a segment of audio analyzed by BirdNET
+type AudioSegment struct {
+	Source    datastore.AudioSource
+	StartTime time.Time
+	PCMData   []byte // mono PCM at conf.SampleRate and conf.BitDepth, read only and pooled, valid until Detect returns
+}
+
+// DetectorResult is an event found by a detector
+type DetectorResult struct {
+	ScientificName string  // scientific name, empty for events without a species, e.g. gunshots
+	CommonName     string  // name of the species or event
+	Confidence     float32 // confidence from 0 to 1, compared against the detection thresholds
+}
+
+// label returns the result as a BirdNET style "Scientific_Common" label. Events without a
+// species use their name for both parts, as BirdNET does for its non-bird classes.
+func (r *DetectorResult) label() string {
+	scientific := r.ScientificName
+	if scientific == "" {
+		scientific = r.CommonName
+	}
+	return scientific + "_" + r.CommonName
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []Detector
+)
+
+// RegisterDetector adds a detector run on the audio of all sources analyzed with the main
+// model. Detector names must be unique.
+func RegisterDetector(detector Detector) error {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+
+	name := detector.Name()
+	if name == "" {
+		return errors.Newf("detector name must not be empty").
+			Component("analysis.processor").
+			Category(errors.CategoryValidation).
+			Context("operation", "register_detector").
+			Build()
+	}
+	for _, registered := range detectors {
+		if registered.Name() == name {
+			return errors.New(fmt.Errorf("detector %q is already registered", name)).
+				Component("analysis.processor").
+				Category(errors.CategoryValidation).
+				Context("operation", "register_detector").
+				Context("detector", name).
+				Build()
+		}
+	}
+
+	detectors = append(detectors, detector)
+	GetLogger().Info("Registered acoustic event detector",
+		"detector", name,
+		"operation", "register_detector")
+	return nil
+}
+
+// UnregisterDetector removes a detector by name
+func UnregisterDetector(name string) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+
+	for i, registered := range detectors {
+		if registered.Name() == name {
+			detectors = append(detectors[:i:i], detectors[i+1:]...)
+			return
+		}
+	}
+}
+
+// registeredDetectors returns the registered detectors
+func registeredDetectors() []Detector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	return detectors
+}
+
+// runDetectors passes the audio of a BirdNET results item to the registered detectors and
+// returns their results as items for the detection pipeline. Pipelines running a profile
+// model analyze their audio with that model only.
+func (p *Processor) runDetectors(item *birdnet.Results) []birdnet.Results {
+	if item.Detector != "" || len(item.PCMdata) == 0 || p.usesProfileModel() {
+		return nil
+	}
+
+	var items []birdnet.Results
+	segment := &AudioSegment{Source: item.Source, StartTime: item.StartTime, PCMData: item.PCMdata}
+	for _, detector := range registeredDetectors() {
+		detectStart := time.Now()
+		results, err := detector.Detect(segment)
+		if err != nil {
+			GetLogger().Warn("Acoustic event detector failed",
+				"detector", detector.Name(),
+				"source", item.Source.DisplayName,
+				"error", err,
+				"operation", "run_detector")
+			continue
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		detected := birdnet.Results{
+			StartTime:   item.StartTime,
+			PCMdata:     item.PCMdata,
+			Results:     make([]datastore.Results, 0, len(results)),
+			ElapsedTime: time.Since(detectStart),
+			Source:      item.Source,
+			Detector:    detector.Name(),
+		}
+		for i := range results {
+			if results[i].CommonName == "" {
+				continue
+			}
+			detected.Results = append(detected.Results, datastore.Results{
+				Species:    results[i].label(),
+				Confidence: results[i].Confidence,
+			})
+		}
+		items = append(items, detected)
+	}
+	return items
+}
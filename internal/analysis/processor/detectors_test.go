@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+type fakeDetector struct {
+	name     string
+	results  []DetectorResult
+	err      error
+	segments []*AudioSegment
+}
+
+func (d *fakeDetector) Name() string { return d.name }
+
+func (d *fakeDetector) Detect(segment *AudioSegment) ([]DetectorResult, error) {
+	d.segments = append(d.segments, segment)
+	return d.results, d.err
+}
+
+func TestRegisterDetector(t *testing.T) {
+	chainsaw := &fakeDetector{name: "chainsaw"}
+	require.NoError(t, RegisterDetector(chainsaw))
+	t.Cleanup(func() { UnregisterDetector("chainsaw") })
+
+	assert.Error(t, RegisterDetector(&fakeDetector{name: "chainsaw"}), "names must be unique")
+	assert.Error(t, RegisterDetector(&fakeDetector{}), "names must not be empty")
+	assert.Contains(t, registeredDetectors(), Detector(chainsaw))
+
+	UnregisterDetector("chainsaw")
+	assert.NotContains(t, registeredDetectors(), Detector(chainsaw))
+}
+
+func TestRunDetectors(t *testing.T) {
+	gunshot := &fakeDetector{name: "gunshot", results: []DetectorResult{
+		{CommonName: "Gunshot", Confidence: 0.9},
+		{CommonName: "", Confidence: 0.5},
+	}}
+	frogs := &fakeDetector{name: "frogs", results: []DetectorResult{
+		{ScientificName: "Rana temporaria", CommonName: "Common Frog", Confidence: 0.7},
+	}}
+	quiet := &fakeDetector{name: "quiet"}
+	broken := &fakeDetector{name: "broken", err: errors.New("model not loaded")}
+	for _, detector := range []*fakeDetector{gunshot, frogs, quiet, broken} {
+		require.NoError(t, RegisterDetector(detector))
+		t.Cleanup(func() { UnregisterDetector(detector.name) })
+	}
+
+	item := birdnet.Results{
+		StartTime: time.Date(2025, 5, 10, 4, 30, 0, 0, time.UTC),
+		PCMdata:   make([]byte, 1024),
+		Source:    datastore.AudioSource{ID: "rtsp_1", DisplayName: "Forest"},
+	}
+	p := &Processor{Settings: &conf.Settings{}}
+
+	items := p.runDetectors(&item)
+	require.Len(t, items, 2, "detectors without results or failing are skipped")
+
+	assert.Equal(t, "gunshot", items[0].Detector)
+	assert.Equal(t, []datastore.Results{{Species: "Gunshot_Gunshot", Confidence: 0.9}}, items[0].Results)
+	assert.Equal(t, item.Source, items[0].Source)
+	assert.Equal(t, item.StartTime, items[0].StartTime)
+
+	assert.Equal(t, "frogs", items[1].Detector)
+	assert.Equal(t, []datastore.Results{{Species: "Rana temporaria_Common Frog", Confidence: 0.7}}, items[1].Results)
+
+	require.Len(t, quiet.segments, 1)
+	assert.Equal(t, "rtsp_1", quiet.segments[0].Source.ID)
+	assert.Len(t, quiet.segments[0].PCMData, 1024)
+
+	// Detector results and profile model pipelines are not passed to detectors again
+	assert.Empty(t, p.runDetectors(&items[0]))
+	profilePipeline := &Processor{Settings: p.Settings, profile: &conf.ProcessingProfile{Name: "bats", ModelPath: "bats.tflite"}}
+	assert.Empty(t, profilePipeline.runDetectors(&item))
+}
@@ -0,0 +1,161 @@
+// drain.go: stop intake and finish held detections and queued actions before shutdown
+package processor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// drainPollInterval is how often outstanding work is checked while draining
+const drainPollInterval = 100 * time.Millisecond
+
+// StartDrain stops accepting new detections. Held detections are flushed on the next
+// flusher tick instead of waiting for their deadline, as no further results can extend
+// them. Calling StartDrain more than once has no further effect.
+func (p *Processor) StartDrain() {
+	if p.draining.Swap(true) {
+		return
+	}
+
+	for _, profileProcessor := range p.profiles {
+		profileProcessor.StartDrain()
+	}
+
+	now := time.Now()
+	p.pendingMutex.Lock()
+	for species, item := range p.pendingDetections {
+		item.FlushDeadline = now
+		p.pendingDetections[species] = item
+	}
+	p.pendingMutex.Unlock()
+
+	GetLogger().Info("Detection intake stopped for drain",
+		"profile", p.profileName(),
+		"operation", "drain_start")
+}
+
+// Draining reports whether the processor has stopped accepting new detections
+func (p *Processor) Draining() bool {
+	return p.draining.Load()
+}
+
+// Drain stops intake and waits until held detections are flushed and queued actions have
+// finished, including those of processing profiles. It returns an error if ctx is done first.
+func (p *Processor) Drain(ctx context.Context) error {
+	p.StartDrain()
+	start := time.Now()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		outstanding := p.OutstandingWork()
+		if outstanding == 0 {
+			GetLogger().Info("Drain completed",
+				"duration_ms", time.Since(start).Milliseconds(),
+				"operation", "drain_complete")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New(ctx.Err()).
+				Component("analysis.processor").
+				Category(errors.CategoryTimeout).
+				Context("operation", "drain").
+				Context("outstanding", outstanding).
+				Build()
+		case <-ticker.C:
+		}
+	}
+}
+
+// OutstandingWork returns the number of held detections and active queued jobs of the
+// processor and its processing profiles
+func (p *Processor) OutstandingWork() int {
+	p.pendingMutex.Lock()
+	outstanding := len(p.pendingDetections)
+	p.pendingMutex.Unlock()
+
+	if p.JobQueue != nil {
+		outstanding += p.JobQueue.ActiveJobCount()
+	}
+	for _, profileProcessor := range p.profiles {
+		outstanding += profileProcessor.OutstandingWork()
+	}
+
+	return outstanding
+}
+
+// QueueStatus is a snapshot of the work left before the processor can be stopped
+type QueueStatus struct {
+	Draining       bool                `json:"draining"`
+	Drained        bool                `json:"drained"` // draining and no work is left
+	HeldDetections int                 `json:"held_detections"`
+	Actions        []ActionQueueStatus `json:"actions"`
+}
+
+// ActionQueueStatus reports the unfinished queued jobs of one action type
+type ActionQueueStatus struct {
+	Type             string  `json:"type"`
+	Queued           int     `json:"queued"`    // waiting to run or waiting for a retry
+	InFlight         int     `json:"in_flight"` // currently executing
+	OldestAgeSeconds float64 `json:"oldest_age_seconds"`
+}
+
+// QueueStatus returns the held detections and unfinished queued jobs per action type of the
+// processor and its processing profiles
+func (p *Processor) QueueStatus() QueueStatus {
+	now := time.Now()
+	status := QueueStatus{Draining: p.Draining(), Actions: []ActionQueueStatus{}}
+	oldest := make(map[string]time.Time)
+	p.collectQueueStatus(&status, oldest)
+
+	for i := range status.Actions {
+		status.Actions[i].OldestAgeSeconds = now.Sub(oldest[status.Actions[i].Type]).Seconds()
+	}
+	sort.Slice(status.Actions, func(i, j int) bool { return status.Actions[i].Type < status.Actions[j].Type })
+	status.Drained = status.Draining && status.HeldDetections == 0 && len(status.Actions) == 0
+
+	return status
+}
+
+// collectQueueStatus adds the work of the processor and its profiles to status, tracking
+// the oldest job creation time per action type
+func (p *Processor) collectQueueStatus(status *QueueStatus, oldest map[string]time.Time) {
+	p.pendingMutex.Lock()
+	status.HeldDetections += len(p.pendingDetections)
+	p.pendingMutex.Unlock()
+
+	if p.JobQueue != nil {
+		for typeName, summary := range p.JobQueue.ActiveJobsByType() {
+			// Report "*processor.DatabaseAction" as "DatabaseAction"
+			name := typeName[strings.LastIndex(typeName, ".")+1:]
+
+			index := -1
+			for i := range status.Actions {
+				if status.Actions[i].Type == name {
+					index = i
+					break
+				}
+			}
+			if index < 0 {
+				status.Actions = append(status.Actions, ActionQueueStatus{Type: name})
+				index = len(status.Actions) - 1
+			}
+			status.Actions[index].Queued += summary.Queued
+			status.Actions[index].InFlight += summary.InFlight
+			if current, ok := oldest[name]; !ok || summary.OldestCreatedAt.Before(current) {
+				oldest[name] = summary.OldestCreatedAt
+			}
+		}
+	}
+
+	for _, profileProcessor := range p.profiles {
+		profileProcessor.collectQueueStatus(status, oldest)
+	}
+}
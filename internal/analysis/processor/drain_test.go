@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestDrainWaitsForHeldDetections(t *testing.T) {
+	t.Parallel()
+
+	deadline := time.Now().Add(time.Hour)
+	p := &Processor{
+		Settings: &conf.Settings{},
+		JobQueue: jobqueue.NewJobQueue(),
+		pendingDetections: map[string]PendingDetection{
+			"great tit": {FlushDeadline: deadline},
+		},
+	}
+
+	assert.False(t, p.Draining())
+	assert.Equal(t, 1, p.OutstandingWork())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	require.Error(t, p.Drain(ctx), "drain should time out while a detection is held")
+
+	assert.True(t, p.Draining())
+	assert.False(t, p.pendingDetections["great tit"].FlushDeadline.After(time.Now()), "held detections are flushed without waiting for their deadline")
+
+	p.pendingMutex.Lock()
+	delete(p.pendingDetections, "great tit")
+	p.pendingMutex.Unlock()
+
+	require.NoError(t, p.Drain(context.Background()))
+	assert.Equal(t, 0, p.OutstandingWork())
+}
+
+func TestDrainingDropsNewResults(t *testing.T) {
+	t.Parallel()
+
+	p := &Processor{
+		Settings:          &conf.Settings{},
+		JobQueue:          jobqueue.NewJobQueue(),
+		pendingDetections: map[string]PendingDetection{},
+	}
+	p.StartDrain()
+
+	p.processDetections(birdnet.Results{
+		StartTime: time.Now(),
+		Source:    datastore.AudioSource{ID: "malgo_1"},
+		Results:   []datastore.Results{{Species: "Parus major_Great Tit", Confidence: 0.9}},
+	})
+
+	assert.Empty(t, p.pendingDetections)
+}
+
+// blockingAction blocks until released so it stays in flight
+type blockingAction struct {
+	release chan struct{}
+}
+
+func (a *blockingAction) Execute(data any) error {
+	<-a.release
+	return nil
+}
+
+func (a *blockingAction) GetDescription() string {
+	return "Blocking action"
+}
+
+func TestQueueStatus(t *testing.T) {
+	t.Parallel()
+
+	queue := jobqueue.NewJobQueue()
+	queue.SetProcessingInterval(10 * time.Millisecond)
+	queue.Start()
+	t.Cleanup(func() { _ = queue.StopWithTimeout(time.Second) })
+
+	p := &Processor{
+		Settings: &conf.Settings{},
+		JobQueue: queue,
+		pendingDetections: map[string]PendingDetection{
+			"great tit": {FlushDeadline: time.Now().Add(time.Hour)},
+		},
+	}
+
+	action := &blockingAction{release: make(chan struct{})}
+	_, err := queue.Enqueue(context.Background(), action, nil, jobqueue.RetryConfig{})
+	require.NoError(t, err)
+
+	var status QueueStatus
+	require.Eventually(t, func() bool {
+		status = p.QueueStatus()
+		return len(status.Actions) == 1 && status.Actions[0].InFlight == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "blockingAction", status.Actions[0].Type)
+	assert.Zero(t, status.Actions[0].Queued)
+	assert.GreaterOrEqual(t, status.Actions[0].OldestAgeSeconds, 0.0)
+	assert.Equal(t, 1, status.HeldDetections)
+	assert.False(t, status.Draining)
+	assert.False(t, status.Drained)
+
+	p.StartDrain()
+	close(action.release)
+	p.pendingMutex.Lock()
+	delete(p.pendingDetections, "great tit")
+	p.pendingMutex.Unlock()
+
+	require.Eventually(t, func() bool {
+		return p.QueueStatus().Drained
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, p.QueueStatus().Actions)
+}
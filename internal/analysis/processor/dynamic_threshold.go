@@ -1,11 +1,56 @@
 package processor
 
 import (
+	"sort"
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/datastore"
 )
 
+const (
+	// dynamicThresholdHistorySize is the number of adjustments kept per species for inspection
+	dynamicThresholdHistorySize = 20
+
+	// dynamicThresholdPersistInterval is how often changed dynamic thresholds are saved
+	dynamicThresholdPersistInterval = 5 * time.Minute
+)
+
+// Dynamic threshold adjustment reasons
+const (
+	ThresholdAdjustHighConfidence = "high_confidence" // Lowered after a high confidence detection
+	ThresholdAdjustExpired        = "expired"         // Reset to the base threshold after the timer expired
+	ThresholdAdjustRestored       = "restored"        // Restored from the datastore at startup
+)
+
+// ThresholdAdjustment records one change of a dynamic threshold
+type ThresholdAdjustment struct {
+	Time   time.Time `json:"time"`
+	Level  int       `json:"level"`
+	Value  float64   `json:"value"`
+	Reason string    `json:"reason"`
+}
+
+// DynamicThresholdStatus is a snapshot of the dynamic threshold of a species
+type DynamicThresholdStatus struct {
+	Species       string                `json:"species"`
+	Profile       string                `json:"profile,omitempty"` // empty for the default pipeline
+	Level         int                   `json:"level"`
+	CurrentValue  float64               `json:"current_value"`
+	ExpiresAt     time.Time             `json:"expires_at"`
+	HighConfCount int                   `json:"high_conf_count"`
+	ValidHours    int                   `json:"valid_hours"`
+	History       []ThresholdAdjustment `json:"history"` // oldest first
+}
+
+// recordAdjustment appends an adjustment to the history of the threshold, keeping the most
+// recent dynamicThresholdHistorySize entries
+func (dt *DynamicThreshold) recordAdjustment(reason string, at time.Time) {
+	dt.History = append(dt.History, ThresholdAdjustment{Time: at, Level: dt.Level, Value: dt.CurrentValue, Reason: reason})
+	if len(dt.History) > dynamicThresholdHistorySize {
+		dt.History = dt.History[len(dt.History)-dynamicThresholdHistorySize:]
+	}
+}
+
 // addSpeciesToDynamicThresholds adds a species to the dynamic thresholds map if it doesn't already exist.
 func (p *Processor) addSpeciesToDynamicThresholds(speciesLowercase string, baseThreshold float32) {
 	// Lock the mutex to ensure thread-safe access to the DynamicThresholds map
@@ -28,6 +73,7 @@ func (p *Processor) addSpeciesToDynamicThresholds(speciesLowercase string, baseT
 			HighConfCount: 0,
 			ValidHours:    p.Settings.Realtime.DynamicThreshold.ValidHours,
 		}
+		p.thresholdsDirty = true
 	}
 }
 
@@ -46,9 +92,13 @@ func (p *Processor) getAdjustedConfidenceThreshold(speciesLowercase string, resu
 	}
 
 	// If the detection confidence exceeds the trigger threshold
+	now := time.Now()
+	previousLevel := dt.Level
+	adjustReason := ThresholdAdjustHighConfidence
 	if result.Confidence > float32(p.Settings.Realtime.DynamicThreshold.Trigger) {
 		dt.HighConfCount++
-		dt.Timer = time.Now().Add(time.Duration(dt.ValidHours) * time.Hour)
+		dt.Timer = now.Add(time.Duration(dt.ValidHours) * time.Hour)
+		p.thresholdsDirty = true
 
 		// Adjust the dynamic threshold based on the number of high-confidence detections
 		switch dt.HighConfCount {
@@ -62,11 +112,12 @@ func (p *Processor) getAdjustedConfidenceThreshold(speciesLowercase string, resu
 			dt.Level = 3
 			dt.CurrentValue = float64(baseThreshold * 0.25)
 		}
-	} else if time.Now().After(dt.Timer) {
+	} else if now.After(dt.Timer) {
 		// Reset the dynamic threshold if the timer has expired
 		dt.Level = 0
 		dt.CurrentValue = float64(baseThreshold)
 		dt.HighConfCount = 0
+		adjustReason = ThresholdAdjustExpired
 	}
 
 	// Ensure the dynamic threshold doesn't fall below the minimum threshold
@@ -74,6 +125,12 @@ func (p *Processor) getAdjustedConfidenceThreshold(speciesLowercase string, resu
 		dt.CurrentValue = p.Settings.Realtime.DynamicThreshold.Min
 	}
 
+	// Keep the level changes for inspection
+	if dt.Level != previousLevel {
+		dt.recordAdjustment(adjustReason, now)
+		p.thresholdsDirty = true
+	}
+
 	return float32(dt.CurrentValue)
 }
 
@@ -90,6 +147,7 @@ func (p *Processor) updateDynamicThreshold(commonName string, confidence float64
 			dt.Timer = time.Now().Add(time.Duration(dt.ValidHours) * time.Hour)
 			// Since we're modifying a struct in the map, we need to reassign it
 			p.DynamicThresholds[commonName] = dt
+			p.thresholdsDirty = true
 		}
 	}
 }
@@ -117,6 +175,125 @@ func (p *Processor) cleanUpDynamicThresholds() {
 			}
 			// Remove the stale threshold from the map
 			delete(p.DynamicThresholds, species)
+			p.thresholdsDirty = true
+		}
+	}
+}
+
+// restoreDynamicThresholds loads the dynamic thresholds saved by the previous run, skipping
+// those that have become stale since
+func (p *Processor) restoreDynamicThresholds() {
+	store, ok := p.Ds.(datastore.DynamicThresholdStore)
+	if !ok || !p.Settings.Realtime.DynamicThreshold.Enabled {
+		return
+	}
+
+	states, err := store.GetDynamicThresholds(p.profileName())
+	if err != nil {
+		GetLogger().Warn("Failed to restore dynamic thresholds",
+			"profile", p.profileName(),
+			"error", err,
+			"operation", "restore_dynamic_thresholds")
+		return
+	}
+
+	now := time.Now()
+	staleDuration := time.Duration(p.Settings.Realtime.DynamicThreshold.ValidHours) * time.Hour
+	p.thresholdsMutex.Lock()
+	defer p.thresholdsMutex.Unlock()
+	for i := range states {
+		state := &states[i]
+		if now.Sub(state.Timer) > staleDuration {
+			continue
+		}
+		dt := &DynamicThreshold{
+			Level:         state.Level,
+			CurrentValue:  state.CurrentValue,
+			Timer:         state.Timer,
+			HighConfCount: state.HighConfCount,
+			ValidHours:    state.ValidHours,
+		}
+		dt.recordAdjustment(ThresholdAdjustRestored, now)
+		p.DynamicThresholds[state.Species] = dt
+	}
+
+	GetLogger().Info("Restored dynamic thresholds",
+		"profile", p.profileName(),
+		"restored", len(p.DynamicThresholds),
+		"stored", len(states),
+		"operation", "restore_dynamic_thresholds")
+}
+
+// persistDynamicThresholds saves the dynamic thresholds when they changed since the last save
+func (p *Processor) persistDynamicThresholds() {
+	store, ok := p.Ds.(datastore.DynamicThresholdStore)
+	if !ok || !p.Settings.Realtime.DynamicThreshold.Enabled {
+		return
+	}
+
+	p.thresholdsMutex.Lock()
+	if !p.thresholdsDirty {
+		p.thresholdsMutex.Unlock()
+		return
+	}
+	states := make([]datastore.DynamicThresholdState, 0, len(p.DynamicThresholds))
+	for species, dt := range p.DynamicThresholds {
+		states = append(states, datastore.DynamicThresholdState{
+			Species:       species,
+			Level:         dt.Level,
+			CurrentValue:  dt.CurrentValue,
+			Timer:         dt.Timer,
+			HighConfCount: dt.HighConfCount,
+			ValidHours:    dt.ValidHours,
+		})
+	}
+	p.thresholdsDirty = false
+	p.thresholdsMutex.Unlock()
+
+	if err := store.SaveDynamicThresholds(p.profileName(), states); err != nil {
+		// Retry on the next save
+		p.thresholdsMutex.Lock()
+		p.thresholdsDirty = true
+		p.thresholdsMutex.Unlock()
+		GetLogger().Warn("Failed to persist dynamic thresholds",
+			"profile", p.profileName(),
+			"count", len(states),
+			"error", err,
+			"operation", "persist_dynamic_thresholds")
+	}
+}
+
+// DynamicThresholdStatus returns the dynamic thresholds of the processor and its processing
+// profiles, sorted by profile and species
+func (p *Processor) DynamicThresholdStatus() []DynamicThresholdStatus {
+	status := p.collectDynamicThresholdStatus(nil)
+	for _, profileProcessor := range p.profiles {
+		status = profileProcessor.collectDynamicThresholdStatus(status)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Profile != status[j].Profile {
+			return status[i].Profile < status[j].Profile
 		}
+		return status[i].Species < status[j].Species
+	})
+	return status
+}
+
+// collectDynamicThresholdStatus appends the dynamic thresholds of the processor to status
+func (p *Processor) collectDynamicThresholdStatus(status []DynamicThresholdStatus) []DynamicThresholdStatus {
+	p.thresholdsMutex.RLock()
+	defer p.thresholdsMutex.RUnlock()
+	for species, dt := range p.DynamicThresholds {
+		status = append(status, DynamicThresholdStatus{
+			Species:       species,
+			Profile:       p.profileName(),
+			Level:         dt.Level,
+			CurrentValue:  dt.CurrentValue,
+			ExpiresAt:     dt.Timer,
+			HighConfCount: dt.HighConfCount,
+			ValidHours:    dt.ValidHours,
+			History:       append([]ThresholdAdjustment{}, dt.History...),
+		})
 	}
+	return status
 }
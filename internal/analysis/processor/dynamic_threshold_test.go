@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// thresholdStoreStub implements datastore.DynamicThresholdStore on top of an unused datastore.Interface
+type thresholdStoreStub struct {
+	datastore.Interface
+	states map[string][]datastore.DynamicThresholdState
+	saves  int
+}
+
+func (s *thresholdStoreStub) SaveDynamicThresholds(profile string, states []datastore.DynamicThresholdState) error {
+	s.states[profile] = states
+	s.saves++
+	return nil
+}
+
+func (s *thresholdStoreStub) GetDynamicThresholds(profile string) ([]datastore.DynamicThresholdState, error) {
+	return s.states[profile], nil
+}
+
+func TestDynamicThresholdPersistence(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.DynamicThreshold = conf.DynamicThresholdSettings{Enabled: true, Trigger: 0.9, Min: 0.2, ValidHours: 24}
+	now := time.Now()
+	store := &thresholdStoreStub{states: map[string][]datastore.DynamicThresholdState{
+		"": {
+			{Species: "great tit", Level: 2, CurrentValue: 0.4, Timer: now.Add(time.Hour), HighConfCount: 2, ValidHours: 24},
+			{Species: "eurasian blackbird", Level: 1, CurrentValue: 0.6, Timer: now.Add(-48 * time.Hour), HighConfCount: 1, ValidHours: 24},
+		},
+	}}
+
+	p := &Processor{Settings: settings, Ds: store, DynamicThresholds: make(map[string]*DynamicThreshold)}
+	p.restoreDynamicThresholds()
+	require.Len(t, p.DynamicThresholds, 1, "stale threshold is not restored")
+	restored := p.DynamicThresholds["great tit"]
+	require.NotNil(t, restored)
+	assert.Equal(t, 2, restored.Level)
+	assert.InDelta(t, 0.4, restored.CurrentValue, 1e-9)
+	require.Len(t, restored.History, 1)
+	assert.Equal(t, ThresholdAdjustRestored, restored.History[0].Reason)
+
+	// Nothing changed since the restore
+	p.persistDynamicThresholds()
+	assert.Zero(t, store.saves)
+
+	// A high confidence detection lowers the threshold further and is saved
+	threshold := p.getAdjustedConfidenceThreshold("great tit", datastore.Results{Confidence: 0.95}, 0.8)
+	assert.InDelta(t, 0.2, threshold, 1e-6)
+	assert.Equal(t, ThresholdAdjustHighConfidence, restored.History[len(restored.History)-1].Reason)
+
+	p.persistDynamicThresholds()
+	assert.Equal(t, 1, store.saves)
+	require.Len(t, store.states[""], 1)
+	assert.Equal(t, 3, store.states[""][0].Level)
+
+	status := p.DynamicThresholdStatus()
+	require.Len(t, status, 1)
+	assert.Equal(t, "great tit", status[0].Species)
+	assert.Len(t, status[0].History, 2)
+}
+
+func TestDynamicThresholdHistoryIsBounded(t *testing.T) {
+	t.Parallel()
+
+	dt := &DynamicThreshold{}
+	for i := range dynamicThresholdHistorySize + 5 {
+		dt.Level = i
+		dt.recordAdjustment(ThresholdAdjustHighConfidence, time.Now())
+	}
+	require.Len(t, dt.History, dynamicThresholdHistorySize)
+	assert.Equal(t, 5, dt.History[0].Level, "oldest adjustments are dropped")
+}
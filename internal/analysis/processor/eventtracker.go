@@ -82,10 +82,11 @@ type EventTracker struct {
 	Handlers        map[EventType]*EventHandler
 	SpeciesConfigs  map[string]conf.SpeciesConfig // Add this: Store species-specific configurations
 	DefaultInterval time.Duration                 // Add this: Store the global default interval
+	Intervals       map[EventType]time.Duration   // Intervals per event type overriding DefaultInterval
 	Mutex           sync.RWMutex                  // Mutex to ensure thread-safe access
 }
 
-// Add this new struct to hold configuration
+// EventTrackerConfig holds the intervals per event type, zero uses the default interval
 type EventTrackerConfig struct {
 	DatabaseSaveInterval      time.Duration
 	LogToFileInterval         time.Duration
@@ -95,6 +96,36 @@ type EventTrackerConfig struct {
 	SSEBroadcastInterval      time.Duration
 }
 
+// NewEventTrackerConfig returns the intervals per event type of the duplicate suppression settings
+func NewEventTrackerConfig(settings *conf.EventIntervalSettings) EventTrackerConfig {
+	return EventTrackerConfig{
+		DatabaseSaveInterval:      time.Duration(settings.Database) * time.Second,
+		LogToFileInterval:         time.Duration(settings.Log) * time.Second,
+		NotificationInterval:      time.Duration(settings.Notification) * time.Second,
+		BirdWeatherSubmitInterval: time.Duration(settings.BirdWeather) * time.Second,
+		MQTTPublishInterval:       time.Duration(settings.MQTT) * time.Second,
+		SSEBroadcastInterval:      time.Duration(settings.SSE) * time.Second,
+	}
+}
+
+// byEventType returns the configured intervals keyed by event type
+func (c EventTrackerConfig) byEventType() map[EventType]time.Duration {
+	intervals := make(map[EventType]time.Duration)
+	for eventType, interval := range map[EventType]time.Duration{
+		DatabaseSave:      c.DatabaseSaveInterval,
+		LogToFile:         c.LogToFileInterval,
+		SendNotification:  c.NotificationInterval,
+		BirdWeatherSubmit: c.BirdWeatherSubmitInterval,
+		MQTTPublish:       c.MQTTPublishInterval,
+		SSEBroadcast:      c.SSEBroadcastInterval,
+	} {
+		if interval > 0 {
+			intervals[eventType] = interval
+		}
+	}
+	return intervals
+}
+
 // initEventTracker is a helper function that initializes an EventTracker with common setup
 func initEventTracker(interval time.Duration, speciesConfigs map[string]conf.SpeciesConfig) *EventTracker {
 	// Create normalized species configs map
@@ -115,6 +146,7 @@ func initEventTracker(interval time.Duration, speciesConfigs map[string]conf.Spe
 			SSEBroadcast:      NewEventHandler(interval, StandardEventBehavior),
 		},
 		SpeciesConfigs: normalizedSpeciesConfigs, // Always initialized, even if empty
+		Intervals:      make(map[EventType]time.Duration),
 	}
 }
 
@@ -128,6 +160,14 @@ func NewEventTrackerWithConfig(defaultInterval time.Duration, speciesConfigs map
 	return initEventTracker(defaultInterval, speciesConfigs)
 }
 
+// NewEventTrackerWithIntervals creates a new EventTracker with a default interval, intervals per
+// event type and species-specific configurations. Species intervals take precedence over both.
+func NewEventTrackerWithIntervals(defaultInterval time.Duration, intervals EventTrackerConfig, speciesConfigs map[string]conf.SpeciesConfig) *EventTracker {
+	et := initEventTracker(defaultInterval, speciesConfigs)
+	et.Intervals = intervals.byEventType()
+	return et
+}
+
 // TrackEvent checks if an event for a given species and event type should be processed.
 // It utilizes the respective event handler to make this determination, considering species-specific intervals.
 func (et *EventTracker) TrackEvent(species string, eventType EventType) bool {
@@ -147,6 +187,9 @@ func (et *EventTracker) TrackEvent(species string, eventType EventType) bool {
 
 	// Determine the effective timeout for this species and event type
 	effectiveTimeout := et.DefaultInterval // Start with the global default
+	if interval, ok := et.Intervals[eventType]; ok {
+		effectiveTimeout = interval
+	}
 
 	if speciesConfig, ok := et.SpeciesConfigs[normalizedSpecies]; ok {
 		if speciesConfig.Interval > 0 {
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestEventTrackerIntervalsPerIntegration(t *testing.T) {
+	t.Parallel()
+
+	intervals := NewEventTrackerConfig(&conf.EventIntervalSettings{MQTT: 3600})
+	tracker := NewEventTrackerWithIntervals(0, intervals, map[string]conf.SpeciesConfig{
+		"Eurasian Blackbird": {Interval: 3600},
+	})
+
+	// Without an integration interval the zero default interval allows every event
+	assert.True(t, tracker.TrackEvent("Great Tit", DatabaseSave))
+	assert.True(t, tracker.TrackEvent("Great Tit", DatabaseSave))
+
+	// The MQTT interval suppresses repeated publishes
+	assert.True(t, tracker.TrackEvent("Great Tit", MQTTPublish))
+	assert.False(t, tracker.TrackEvent("Great Tit", MQTTPublish))
+
+	// Species intervals take precedence over the default and integration intervals
+	assert.True(t, tracker.TrackEvent("Eurasian Blackbird", DatabaseSave))
+	assert.False(t, tracker.TrackEvent("eurasian blackbird", DatabaseSave))
+}
+
+func TestNewEventTrackerConfig(t *testing.T) {
+	t.Parallel()
+
+	config := NewEventTrackerConfig(&conf.EventIntervalSettings{Database: 60, SSE: 5})
+	assert.Equal(t, map[EventType]time.Duration{
+		DatabaseSave: time.Minute,
+		SSEBroadcast: 5 * time.Second,
+	}, config.byEventType(), "zero intervals fall back to the default interval")
+}
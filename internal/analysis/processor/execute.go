@@ -20,6 +20,9 @@ import (
 type ExecuteCommandAction struct {
 	Command string
 	Params  map[string]any
+
+	guard  *commandGuard // run limits shared by all actions, nil for no limits
+	limits commandLimits // limits of this action's command
 }
 
 // GetDescription returns a description of the action
@@ -79,6 +82,13 @@ func (a ExecuteCommandAction) ExecuteContext(ctx context.Context, data any) erro
 			Build()
 	}
 
+	// Reject runs beyond the rate and concurrency limits of the command
+	release, err := a.guard.acquire(cmdPath, a.limits, time.Now())
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	logger.Debug("Executing command with arguments", "command_path", cmdPath, "args", args)
 
 	// Create command with timeout, inheriting from parent context
@@ -4,13 +4,22 @@
 // with the jobqueue package.
 package processor
 
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
+)
+
 // ActionAdapter adapts the processor.Action interface to the jobqueue.Action interface
 type ActionAdapter struct {
 	action Action
 }
 
-// Execute implements the jobqueue.Action interface
+// Execute implements the jobqueue.Action interface, recording the execution time in the
+// pipeline latencies
 func (a *ActionAdapter) Execute(data interface{}) error {
+	start := time.Now()
+	defer func() { pipeline.Record(pipeline.StageActions, time.Since(start)) }()
 	return a.action.Execute(data)
 }
 
@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
 	"github.com/tphakala/birdnet-go/internal/logging"
 )
 
@@ -44,6 +45,8 @@ func init() {
 		processorLogger = slog.New(fbHandler).With("service", serviceName)
 		processorCloseFunc = func() error { return nil } // No-op closer
 	}
+
+	debugcapture.Register(debugcapture.ComponentProcessor, processorLevelVar)
 }
 
 // GetLogger returns the processor package logger
@@ -12,6 +12,9 @@ import (
 
 // GetMQTTClient safely returns the current MQTT client
 func (p *Processor) GetMQTTClient() mqtt.Client {
+	if p.parent != nil {
+		return p.parent.GetMQTTClient()
+	}
 	p.mqttMutex.RLock()
 	defer p.mqttMutex.RUnlock()
 	return p.MqttClient
@@ -21,9 +24,23 @@ func (p *Processor) GetMQTTClient() mqtt.Client {
 func (p *Processor) SetMQTTClient(client mqtt.Client) {
 	p.mqttMutex.Lock()
 	defer p.mqttMutex.Unlock()
+	if client != nil && p.mqttControlChan != nil {
+		client.SetControlChannel(p.mqttControlChan)
+	}
 	p.MqttClient = client
 }
 
+// SetMQTTControlChannel sets the control channel receiving commands of the MQTT command
+// topic, for the current client and clients set later on reconfiguration
+func (p *Processor) SetMQTTControlChannel(ch chan string) {
+	p.mqttMutex.Lock()
+	defer p.mqttMutex.Unlock()
+	p.mqttControlChan = ch
+	if p.MqttClient != nil {
+		p.MqttClient.SetControlChannel(ch)
+	}
+}
+
 // DisconnectMQTTClient safely disconnects and removes the MQTT client
 func (p *Processor) DisconnectMQTTClient() {
 	p.mqttMutex.Lock()
@@ -36,6 +53,9 @@ func (p *Processor) DisconnectMQTTClient() {
 
 // PublishMQTT safely publishes a message using the MQTT client if available
 func (p *Processor) PublishMQTT(ctx context.Context, topic, payload string) error {
+	if p.parent != nil {
+		return p.parent.PublishMQTT(ctx, topic, payload)
+	}
 	p.mqttMutex.RLock()
 	client := p.MqttClient
 	p.mqttMutex.RUnlock()
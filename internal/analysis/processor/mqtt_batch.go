@@ -0,0 +1,163 @@
+// mqtt_batch.go: aggregate MQTT detection messages into batches
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// mqttBatchMaxPending is the maximum number of messages kept per topic while publishing fails.
+// The oldest messages are dropped beyond it.
+const mqttBatchMaxPending = 1000
+
+// mqttPublishFunc publishes a payload to an MQTT topic
+type mqttPublishFunc func(ctx context.Context, topic, payload string) error
+
+// mqttBatcher collects detection messages per topic and publishes them as one JSON array
+// when the batch interval has passed or the batch reaches its maximum size. It is shared
+// by the default pipeline and its profiles.
+type mqttBatcher struct {
+	mu       sync.Mutex
+	settings *conf.Settings
+	publish  mqttPublishFunc
+	pending  map[string][]json.RawMessage // messages waiting to be published, per topic
+	timers   map[string]*time.Timer       // interval timers of topics with pending messages
+}
+
+// newMQTTBatcher creates a batcher that publishes batches with publish
+func newMQTTBatcher(settings *conf.Settings, publish mqttPublishFunc) *mqttBatcher {
+	return &mqttBatcher{
+		settings: settings,
+		publish:  publish,
+		pending:  make(map[string][]json.RawMessage),
+		timers:   make(map[string]*time.Timer),
+	}
+}
+
+// add queues a message for topic. The batch is published right away when it reaches
+// the maximum size, otherwise when the batch interval has passed since its first message.
+func (b *mqttBatcher) add(topic string, message []byte) {
+	batch := b.settings.Realtime.MQTT.Batch
+
+	b.mu.Lock()
+	b.pending[topic] = append(b.pending[topic], json.RawMessage(message))
+	full := len(b.pending[topic]) >= max(batch.MaxSize, 1)
+	if !full && b.timers[topic] == nil {
+		interval := time.Duration(max(batch.Interval, 1)) * time.Second
+		b.timers[topic] = time.AfterFunc(interval, func() { b.flush(topic) })
+	}
+	b.mu.Unlock()
+
+	if full {
+		b.flush(topic)
+	}
+}
+
+// flush publishes the pending messages of topic. Messages are requeued when publishing fails
+// and published with the next batch.
+func (b *mqttBatcher) flush(topic string) {
+	b.mu.Lock()
+	messages := b.pending[topic]
+	delete(b.pending, topic)
+	if timer := b.timers[topic]; timer != nil {
+		timer.Stop()
+		delete(b.timers, topic)
+	}
+	b.mu.Unlock()
+
+	if len(messages) == 0 {
+		return
+	}
+
+	payload := encodeMQTTBatch(messages)
+	ctx, cancel := context.WithTimeout(context.Background(), MQTTPublishTimeout)
+	defer cancel()
+
+	if err := b.publish(ctx, topic, payload); err != nil {
+		requeued := b.requeue(topic, messages)
+		GetLogger().Warn("Failed to publish MQTT batch, requeued for the next batch",
+			"component", "analysis.processor.mqtt_batch",
+			"topic", topic,
+			"messages", len(messages),
+			"requeued", requeued,
+			"error", sanitizeError(err),
+			"operation", "mqtt_batch_publish")
+		return
+	}
+
+	if b.settings.Debug {
+		GetLogger().Debug("Published MQTT batch",
+			"component", "analysis.processor.mqtt_batch",
+			"topic", topic,
+			"messages", len(messages),
+			"operation", "mqtt_batch_publish")
+	}
+}
+
+// requeue puts messages that failed to publish back in front of the pending messages of
+// topic and schedules the next attempt. It returns the number of messages kept.
+func (b *mqttBatcher) requeue(topic string, messages []json.RawMessage) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending := append(messages, b.pending[topic]...)
+	if len(pending) > mqttBatchMaxPending {
+		pending = pending[len(pending)-mqttBatchMaxPending:]
+	}
+	b.pending[topic] = pending
+
+	if b.timers[topic] == nil {
+		interval := time.Duration(max(b.settings.Realtime.MQTT.Batch.Interval, 1)) * time.Second
+		b.timers[topic] = time.AfterFunc(interval, func() { b.flush(topic) })
+	}
+	return len(pending)
+}
+
+// close publishes the pending messages of all topics and stops the interval timers, used on
+// shutdown. Messages that still cannot be published are dropped.
+func (b *mqttBatcher) close() {
+	b.mu.Lock()
+	topics := make([]string, 0, len(b.pending))
+	for topic := range b.pending {
+		topics = append(topics, topic)
+	}
+	b.mu.Unlock()
+
+	for _, topic := range topics {
+		b.flush(topic)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for topic, timer := range b.timers {
+		timer.Stop()
+		delete(b.timers, topic)
+	}
+	for topic, messages := range b.pending {
+		GetLogger().Warn("Dropping unpublished MQTT batch on shutdown",
+			"component", "analysis.processor.mqtt_batch",
+			"topic", topic,
+			"messages", len(messages),
+			"operation", "mqtt_batch_close")
+		delete(b.pending, topic)
+	}
+}
+
+// encodeMQTTBatch joins JSON messages into a JSON array
+func encodeMQTTBatch(messages []json.RawMessage) string {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, message := range messages {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(message)
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// batchPublisher records published MQTT batches
+type batchPublisher struct {
+	mu       sync.Mutex
+	payloads map[string][]string
+	fail     bool
+}
+
+func (p *batchPublisher) publish(ctx context.Context, topic, payload string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail {
+		return fmt.Errorf("broker unavailable")
+	}
+	if p.payloads == nil {
+		p.payloads = make(map[string][]string)
+	}
+	p.payloads[topic] = append(p.payloads[topic], payload)
+	return nil
+}
+
+func (p *batchPublisher) published(topic string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.payloads[topic]...)
+}
+
+func (p *batchPublisher) setFail(fail bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fail = fail
+}
+
+func newBatchTestSettings(interval, maxSize int) *conf.Settings {
+	settings := &conf.Settings{}
+	settings.Realtime.MQTT.Batch = conf.MQTTBatchSettings{Enabled: true, Interval: interval, MaxSize: maxSize}
+	return settings
+}
+
+func TestMQTTBatcherPublishesAtMaxSize(t *testing.T) {
+	t.Parallel()
+
+	publisher := &batchPublisher{}
+	batcher := newMQTTBatcher(newBatchTestSettings(3600, 3), publisher.publish)
+
+	batcher.add("birdnet", []byte(`{"n":1}`))
+	batcher.add("birdnet", []byte(`{"n":2}`))
+	batcher.add("birdnet/other", []byte(`{"n":3}`))
+	assert.Empty(t, publisher.published("birdnet"), "batch should wait until it is full")
+
+	batcher.add("birdnet", []byte(`{"n":4}`))
+	require.Equal(t, []string{`[{"n":1},{"n":2},{"n":4}]`}, publisher.published("birdnet"))
+
+	var decoded []map[string]int
+	require.NoError(t, json.Unmarshal([]byte(publisher.published("birdnet")[0]), &decoded))
+	assert.Len(t, decoded, 3)
+
+	// Topics are batched separately
+	batcher.close()
+	assert.Equal(t, []string{`[{"n":3}]`}, publisher.published("birdnet/other"))
+}
+
+func TestMQTTBatcherPublishesAfterInterval(t *testing.T) {
+	t.Parallel()
+
+	publisher := &batchPublisher{}
+	batcher := newMQTTBatcher(newBatchTestSettings(1, 50), publisher.publish)
+
+	batcher.add("birdnet", []byte(`{"n":1}`))
+	batcher.add("birdnet", []byte(`{"n":2}`))
+
+	require.Eventually(t, func() bool {
+		return len(publisher.published("birdnet")) == 1
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, `[{"n":1},{"n":2}]`, publisher.published("birdnet")[0])
+}
+
+func TestMQTTBatcherRequeuesFailedBatch(t *testing.T) {
+	t.Parallel()
+
+	publisher := &batchPublisher{fail: true}
+	batcher := newMQTTBatcher(newBatchTestSettings(3600, 2), publisher.publish)
+
+	batcher.add("birdnet", []byte(`{"n":1}`))
+	batcher.add("birdnet", []byte(`{"n":2}`))
+	assert.Empty(t, publisher.published("birdnet"))
+
+	publisher.setFail(false)
+	batcher.add("birdnet", []byte(`{"n":3}`))
+	assert.Equal(t, []string{`[{"n":1},{"n":2},{"n":3}]`}, publisher.published("birdnet"),
+		"failed messages should be published first with the next batch")
+}
+
+func TestMQTTBatcherCloseDropsUnpublished(t *testing.T) {
+	t.Parallel()
+
+	publisher := &batchPublisher{fail: true}
+	batcher := newMQTTBatcher(newBatchTestSettings(3600, 50), publisher.publish)
+
+	batcher.add("birdnet", []byte(`{"n":1}`))
+	batcher.close()
+
+	batcher.mu.Lock()
+	defer batcher.mu.Unlock()
+	assert.Empty(t, batcher.pending)
+	assert.Empty(t, batcher.timers)
+}
@@ -204,3 +204,33 @@ func TestMqttAction_OmitsOccurrenceWhenZero(t *testing.T) {
 	assert.InDelta(t, 0.85, confidence, 0.001)
 }
 
+
+func TestMqttAction_ScrubsSource(t *testing.T) {
+	source := datastore.AudioSource{
+		ID:          "rtsp_87b89761",
+		SafeString:  "rtsp://192.168.1.10:554/garden",
+		DisplayName: "rtsp://192.168.1.10:554/garden",
+	}
+
+	for _, scrub := range []bool{false, true} {
+		mockClient := &MockMqttClientWithCapture{Connected: true}
+		settings := &conf.Settings{}
+		settings.Realtime.MQTT = conf.MQTTSettings{Enabled: true, Topic: "birdnet/detections"}
+		settings.Privacy.ScrubMQTT = scrub
+
+		action := &MqttAction{
+			Settings:     settings,
+			Note:         datastore.Note{CommonName: "Great Tit", ScientificName: "Parus major", Source: source},
+			MqttClient:   mockClient,
+			EventTracker: NewEventTracker(60 * time.Second),
+		}
+		require.NoError(t, action.Execute(nil))
+
+		if scrub {
+			assert.NotContains(t, mockClient.PublishedData, "192.168.1.10", "source URL is scrubbed")
+			assert.Contains(t, mockClient.PublishedData, "rtsp_87b89761", "source ID is kept")
+		} else {
+			assert.Contains(t, mockClient.PublishedData, "rtsp://192.168.1.10:554/garden")
+		}
+	}
+}
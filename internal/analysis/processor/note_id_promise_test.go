@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestDatabaseActionResolvesIDPromise(t *testing.T) {
+	store := &flakyStore{}
+	promise := datastore.NewNoteIDPromise()
+	action := &DatabaseAction{
+		Settings:     &conf.Settings{},
+		EventTracker: NewEventTracker(0),
+		Ds:           store,
+		Note:         datastore.Note{CommonName: "Eurasian Blackbird"},
+		IDPromise:    promise,
+	}
+	require.NoError(t, action.Execute(nil))
+
+	id, err := promise.Wait(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), id, "the provisional ID resolves to the database ID")
+	assert.Equal(t, uint(1), promise.ID())
+
+	// A note that could not be saved resolves as not saved
+	store.down = true
+	promise = datastore.NewNoteIDPromise()
+	action = &DatabaseAction{
+		Settings:     &conf.Settings{},
+		EventTracker: NewEventTracker(0),
+		Ds:           store,
+		Note:         datastore.Note{CommonName: "Great Tit"},
+		IDPromise:    promise,
+	}
+	require.Error(t, action.Execute(nil))
+	_, err = promise.Wait(t.Context())
+	require.ErrorIs(t, err, datastore.ErrNoteNotSaved)
+	assert.Equal(t, promise.Provisional(), promise.ID())
+}
+
+func TestBufferedNoteResolvesIDPromiseOnReplay(t *testing.T) {
+	store := &flakyStore{down: true}
+	spool := newTestDetectionSpool(t, t.TempDir(), store, 10)
+	p := &Processor{Settings: &conf.Settings{}, spool: spool}
+
+	promise := datastore.NewNoteIDPromise()
+	action := &DatabaseAction{
+		Settings:     p.Settings,
+		EventTracker: NewEventTracker(0),
+		processor:    p,
+		Ds:           store,
+		Note:         datastore.Note{CommonName: "Eurasian Blackbird"},
+		IDPromise:    promise,
+	}
+	require.NoError(t, action.Execute(nil))
+
+	_, err := promise.Wait(t.Context())
+	require.ErrorIs(t, err, datastore.ErrNotePending, "a buffered note is pending instead of not saved")
+	assert.Equal(t, promise.Provisional(), promise.ID())
+
+	store.down = false
+	spool.replay()
+	id, err := promise.Wait(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), id, "the provisional ID resolves once the replay saves the note")
+}
@@ -0,0 +1,55 @@
+// overload.go: per-detection processing deadline and shedding of optional actions
+package processor
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+)
+
+// detectionDeadline is the end-to-end processing deadline of a detection. Optional actions
+// such as SSE broadcasts and MQTT publishes are shed when they start after the deadline, so
+// a backlog under overload drains without delaying database saves further.
+type detectionDeadline struct {
+	at      time.Time               // zero when shedding is disabled
+	metrics *metrics.BirdNETMetrics // nil when telemetry is disabled
+}
+
+// actionDeadline returns the processing deadline of a detection
+func (p *Processor) actionDeadline(detection *Detections) detectionDeadline {
+	var deadline detectionDeadline
+	settings := p.Settings.Realtime.Overload
+	if !settings.Enabled || settings.Deadline <= 0 || detection.Note.BeginTime.IsZero() {
+		return deadline
+	}
+
+	deadline.at = detection.Note.BeginTime.Add(time.Duration(settings.Deadline) * time.Second)
+	if p.Settings.Realtime.Telemetry.Enabled && p.Metrics != nil {
+		deadline.metrics = p.Metrics.BirdNET
+	}
+	return deadline
+}
+
+// shed reports whether an optional action has missed the deadline and must be skipped,
+// recording the skipped action
+func (d detectionDeadline) shed(action, correlationID, species string) bool {
+	if d.at.IsZero() {
+		return false
+	}
+	late := time.Since(d.at)
+	if late < 0 {
+		return false
+	}
+
+	GetLogger().Warn("Detection missed processing deadline, skipping optional action",
+		"component", "analysis.processor.overload",
+		"detection_id", correlationID,
+		"species", species,
+		"action", action,
+		"late_ms", late.Milliseconds(),
+		"operation", "shed_action")
+	if d.metrics != nil {
+		d.metrics.RecordShedAction(action)
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
+	"github.com/tphakala/birdnet-go/internal/observability"
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+)
+
+func TestActionDeadline(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Overload = conf.OverloadSettings{Enabled: true, Deadline: 120}
+	p := &Processor{Settings: settings}
+
+	begin := time.Date(2026, 5, 10, 6, 0, 0, 0, time.Local)
+	detection := &Detections{Note: datastore.Note{BeginTime: begin}}
+	assert.Equal(t, begin.Add(2*time.Minute), p.actionDeadline(detection).at)
+
+	settings.Realtime.Overload.Enabled = false
+	assert.True(t, p.actionDeadline(detection).at.IsZero(), "shedding disabled")
+}
+
+func TestOverdueOptionalActionsAreShed(t *testing.T) {
+	t.Parallel()
+
+	birdnetMetrics, err := metrics.NewBirdNETMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	settings := &conf.Settings{}
+	settings.Realtime.Overload = conf.OverloadSettings{Enabled: true, Deadline: 30}
+	settings.Realtime.Telemetry.Enabled = true
+	p := &Processor{Settings: settings, Metrics: &observability.Metrics{BirdNET: birdnetMetrics}}
+
+	// Detected ten minutes ago, long past the deadline
+	detection := &Detections{Note: datastore.Note{CommonName: "Eurasian Blackbird", BeginTime: time.Now().Add(-10 * time.Minute)}}
+
+	broadcasts := 0
+	sse := &SSEAction{
+		Settings: settings,
+		Note:     detection.Note,
+		SSEBroadcaster: func(note *datastore.Note, birdImage *imageprovider.BirdImage) error {
+			broadcasts++
+			return nil
+		},
+		deadline: p.actionDeadline(detection),
+	}
+	require.NoError(t, sse.Execute(nil))
+	assert.Zero(t, broadcasts)
+
+	// The MQTT client is never used when publishing is shed
+	mqttAction := &MqttAction{Settings: settings, Note: detection.Note, deadline: p.actionDeadline(detection)}
+	require.NoError(t, mqttAction.Execute(nil))
+
+	assert.InDelta(t, 1, testutil.ToFloat64(birdnetMetrics.ShedActionsTotal.WithLabelValues("sse")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(birdnetMetrics.ShedActionsTotal.WithLabelValues("mqtt")), 0)
+}
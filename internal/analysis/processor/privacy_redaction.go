@@ -0,0 +1,131 @@
+// privacy_redaction.go: muting of human voices in the audio of detections kept by the
+// privacy filter redact mode
+package processor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+const (
+	// humanSegmentRetention is how long analysis segments with a human voice are remembered
+	// for redacting the clips of detections not yet saved
+	humanSegmentRetention = 10 * time.Minute
+
+	// redactionPadding is muted on both sides of a human segment to cover speech starting
+	// or ending between analysis segments
+	redactionPadding = time.Second
+)
+
+// mutedRange is a range of audio to mute, relative to the start of the audio
+type mutedRange struct {
+	from, to time.Duration
+}
+
+// humanSegments records the analysis segments with a human voice per audio source. It is
+// shared by the default pipeline and its profiles.
+type humanSegments struct {
+	mu     sync.Mutex
+	starts map[string][]time.Time // audio start of each segment per source ID, oldest first
+}
+
+// newHumanSegments creates an empty human segment record
+func newHumanSegments() *humanSegments {
+	return &humanSegments{starts: make(map[string][]time.Time)}
+}
+
+// add records a segment with a human voice and forgets segments past the retention
+func (h *humanSegments) add(source string, start time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := start.Add(-humanSegmentRetention)
+	starts := h.starts[source]
+	expired := 0
+	for expired < len(starts) && starts[expired].Before(cutoff) {
+		expired++
+	}
+	h.starts[source] = append(starts[expired:], start)
+}
+
+// mutedRanges returns the human segments of a source overlapping audio of the given length
+// starting at audioStart, as ranges relative to the audio start
+func (h *humanSegments) mutedRanges(source string, audioStart time.Time, length time.Duration) []mutedRange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var ranges []mutedRange
+	for _, start := range h.starts[source] {
+		from := start.Sub(audioStart) - redactionPadding
+		to := from + conf.AnalysisWindow + 2*redactionPadding
+		if to <= 0 || from >= length {
+			continue
+		}
+		ranges = append(ranges, mutedRange{from: max(from, 0), to: min(to, length)})
+	}
+	return ranges
+}
+
+// recordHumanSegment remembers an analysis segment with a human voice when the privacy
+// filter redacts audio
+func (p *Processor) recordHumanSegment(source string, startTime time.Time) {
+	if p.humanSegments == nil || p.Settings.Realtime.PrivacyFilter.Mode != conf.PrivacyFilterModeRedact {
+		return
+	}
+	p.humanSegments.add(source, p.clipTime(startTime))
+}
+
+// clipTime returns the time the audio of an analysis segment starts at in detection clips.
+// Analysis start times are set the pre-capture length before the analyzed audio so a clip
+// starting at the note begin time includes the pre-capture audio.
+func (p *Processor) clipTime(analysisStart time.Time) time.Time {
+	preCapture := time.Duration(p.Settings.Realtime.Audio.Export.PreCapture) * time.Second
+	return analysisStart.Add(preCapture)
+}
+
+// redactHumanVoices returns the audio of a source starting at audioStart with the human
+// voices heard around it muted, or the audio unchanged when nothing needs to be redacted
+func (p *Processor) redactHumanVoices(source string, audioStart time.Time, pcm []byte, correlationID string) []byte {
+	if p == nil || p.humanSegments == nil || p.Settings.Realtime.PrivacyFilter.Mode != conf.PrivacyFilterModeRedact {
+		return pcm
+	}
+
+	length := time.Duration(len(pcm)) * time.Second / time.Duration(conf.SampleRate*conf.BitDepth/8)
+	ranges := p.humanSegments.mutedRanges(source, audioStart, length)
+	if len(ranges) == 0 {
+		return pcm
+	}
+
+	GetLogger().Info("Muting human voice in detection audio",
+		"component", "analysis.processor.privacy",
+		"detection_id", correlationID,
+		"source", source,
+		"muted_ranges", len(ranges),
+		"operation", "privacy_redact")
+	return redactPCM(pcm, ranges)
+}
+
+// redactPCM returns a copy of 16-bit PCM audio with the ranges silenced
+func redactPCM(pcm []byte, ranges []mutedRange) []byte {
+	redacted := make([]byte, len(pcm))
+	copy(redacted, pcm)
+
+	bytesPerSample := conf.BitDepth / 8
+	for _, r := range ranges {
+		from := sampleOffset(r.from, bytesPerSample)
+		to := min(sampleOffset(r.to, bytesPerSample), len(redacted))
+		if from >= to {
+			continue
+		}
+		clear(redacted[from:to])
+	}
+	return redacted
+}
+
+// sampleOffset returns the byte offset of a time in PCM audio, aligned to a sample
+func sampleOffset(offset time.Duration, bytesPerSample int) int {
+	samples := int(offset * time.Duration(conf.SampleRate) / time.Second)
+	return samples * bytesPerSample
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// constantPCM returns 16-bit PCM audio of the given length with every sample set to 1000
+func constantPCM(length time.Duration) []byte {
+	pcm := make([]byte, sampleOffset(length, conf.BitDepth/8))
+	for i := 0; i < len(pcm); i += 2 {
+		pcm[i], pcm[i+1] = 0xe8, 0x03
+	}
+	return pcm
+}
+
+// silentSeconds reports for each second of 16-bit PCM audio whether it is fully silent
+func silentSeconds(pcm []byte) []bool {
+	second := sampleOffset(time.Second, conf.BitDepth/8)
+	var silent []bool
+	for start := 0; start < len(pcm); start += second {
+		isSilent := true
+		for _, b := range pcm[start:min(start+second, len(pcm))] {
+			if b != 0 {
+				isSilent = false
+				break
+			}
+		}
+		silent = append(silent, isSilent)
+	}
+	return silent
+}
+
+func TestRedactHumanVoices(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.PrivacyFilter.Mode = conf.PrivacyFilterModeRedact
+	settings.Realtime.Audio.Export.PreCapture = 2
+	p := &Processor{Settings: settings, humanSegments: newHumanSegments()}
+
+	clipStart := time.Date(2026, 5, 10, 6, 0, 0, 0, time.UTC)
+	// Analyzed audio starts two seconds after the analysis start time, at 5s into the clip
+	p.recordHumanSegment("rtsp_1", clipStart.Add(3*time.Second))
+	p.recordHumanSegment("rtsp_2", clipStart)
+
+	pcm := constantPCM(12 * time.Second)
+	redacted := p.redactHumanVoices("rtsp_1", clipStart, pcm, "test")
+
+	// The 3 second segment is muted with one second of padding on both sides
+	assert.Equal(t, []bool{false, false, false, false, true, true, true, true, true, false, false, false}, silentSeconds(redacted))
+	assert.Equal(t, constantPCM(12*time.Second), pcm, "the original audio is not modified")
+
+	assert.Equal(t, pcm, p.redactHumanVoices("rtsp_3", clipStart, pcm, "test"), "no human voice heard by the source")
+
+	settings.Realtime.PrivacyFilter.Mode = conf.PrivacyFilterModeDiscard
+	assert.Equal(t, pcm, p.redactHumanVoices("rtsp_1", clipStart, pcm, "test"), "audio is only redacted in redact mode")
+}
+
+func TestHumanSegmentsRetention(t *testing.T) {
+	t.Parallel()
+
+	segments := newHumanSegments()
+	start := time.Now()
+	segments.add("rtsp_1", start)
+	segments.add("rtsp_1", start.Add(humanSegmentRetention+time.Minute))
+
+	require.Len(t, segments.starts["rtsp_1"], 1, "expired segments are forgotten")
+	assert.Empty(t, segments.mutedRanges("rtsp_1", start, time.Minute))
+}
+
+func TestShouldDiscardDetectionRedactMode(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.PrivacyFilter = conf.PrivacyFilterSettings{Enabled: true, Confidence: 0.05, Mode: conf.PrivacyFilterModeRedact}
+	detected := time.Now()
+	p := &Processor{
+		Settings:           settings,
+		LastDogDetection:   map[string]time.Time{},
+		LastHumanDetection: map[string]time.Time{"rtsp_1": detected.Add(time.Second)},
+	}
+
+	discard, _ := p.shouldDiscardDetection(&PendingDetection{
+		Detection:     Detections{Note: datastore.Note{CommonName: "Great Tit", Source: datastore.AudioSource{ID: "rtsp_1"}}},
+		Source:        "rtsp_1",
+		FirstDetected: detected,
+		Count:         1,
+	}, 1)
+	assert.False(t, discard, "detections are kept in redact mode")
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"math"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,7 +24,10 @@ import (
 	"github.com/tphakala/birdnet-go/internal/mqtt"
 	"github.com/tphakala/birdnet-go/internal/myaudio"
 	"github.com/tphakala/birdnet-go/internal/observability"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
+	"github.com/tphakala/birdnet-go/internal/observation"
 	"github.com/tphakala/birdnet-go/internal/privacy"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
 )
 
 // Species identification constants for filtering
@@ -41,6 +45,7 @@ type Processor struct {
 	bwClientMutex       sync.RWMutex // Mutex to protect BwClient access
 	MqttClient          mqtt.Client
 	mqttMutex           sync.RWMutex // Mutex to protect MQTT client access
+	mqttControlChan     chan string  // control channel of commands received over MQTT, applied to new clients
 	BirdImageCache      *imageprovider.BirdImageCache
 	EventTracker        *EventTracker
 	eventTrackerMu      sync.RWMutex            // Mutex to protect EventTracker access
@@ -54,6 +59,7 @@ type Processor struct {
 	Metrics             *observability.Metrics
 	DynamicThresholds   map[string]*DynamicThreshold
 	thresholdsMutex     sync.RWMutex // Mutex to protect access to DynamicThresholds
+	thresholdsDirty     bool         // DynamicThresholds changed since they were last persisted
 	pendingDetections   map[string]PendingDetection
 	pendingMutex        sync.Mutex // Mutex to protect access to pendingDetections
 	lastDogDetectionLog map[string]time.Time
@@ -73,6 +79,24 @@ type Processor struct {
 
 	// Log deduplication (extracted to separate type for SRP)
 	logDedup *LogDeduplicator // Handles log deduplication logic
+
+	// Processing profile fields
+	profile         *conf.ProcessingProfile // Profile run by this processor, nil for the default pipeline
+	profileOverride *conf.SourceOverride    // Species lists of the profile applied to all its sources
+	parent          *Processor              // Processor owning shared integrations, nil for the default pipeline
+	profiles        []*Processor            // Profile processors started by the default pipeline
+
+	draining atomic.Bool // true once intake has stopped for a graceful drain
+
+	rarity    *rarityScorer     // Rarity score history cache, shared with profile processors
+	mqttBatch *mqttBatcher      // Batched MQTT publishing, shared with profile processors
+	bwQuota   *uploadQuota      // Daily BirdWeather uploads per species, shared with profile processors
+	cmdGuard  *commandGuard     // ExecuteCommand run limits per command, shared with profile processors
+	collector *segmentCollector // Training data collection mode, shared with profile processors
+	sunCalc   *suncalc.SunCalc  // Sun events of the day and night conditions of species actions
+
+	humanSegments *humanSegments  // Human voices to mute in clips in privacy redact mode, shared with profile processors
+	spool         *detectionSpool // Detections buffered while the database is unavailable, shared with profile processors
 }
 
 // DynamicThreshold represents the dynamic threshold configuration for a species.
@@ -82,25 +106,29 @@ type DynamicThreshold struct {
 	Timer         time.Time
 	HighConfCount int
 	ValidHours    int
+	History       []ThresholdAdjustment // Recent level changes, oldest first
 }
 
 type Detections struct {
-	CorrelationID string              // Unique detection identifier for log correlation
-	pcmData3s     []byte              // 3s PCM data containing the detection
-	Note          datastore.Note      // Note containing highest match
-	Results       []datastore.Results // Full BirdNET prediction results
+	CorrelationID string                 // Unique detection identifier for log correlation
+	pcmData3s     []byte                 // 3s PCM data containing the detection
+	pcmStart      time.Time              // Analysis start time of pcmData3s
+	Note          datastore.Note         // Note containing highest match
+	Results       []datastore.Results    // Full BirdNET prediction results
+	Sources       []datastore.NoteSource // Contributing audio sources when cross-source correlation is enabled
 }
 
 // PendingDetection struct represents a single detection held in memory,
 // including its last updated timestamp and a deadline for flushing it to the worker queue.
 type PendingDetection struct {
-	Detection     Detections // The detection data
-	Confidence    float64    // Confidence level of the detection
-	Source        string     // Audio source of the detection, RTSP URL or audio card name
-	FirstDetected time.Time  // Time the detection was first detected
-	LastUpdated   time.Time  // Last time this detection was updated
-	FlushDeadline time.Time  // Deadline by which the detection must be processed
-	Count         int        // Number of times this detection has been updated
+	Detection     Detections                      // The detection data
+	Confidence    float64                         // Confidence level of the detection
+	Source        string                          // Audio source of the detection, RTSP URL or audio card name
+	FirstDetected time.Time                       // Time the detection was first detected
+	LastUpdated   time.Time                       // Last time this detection was updated
+	FlushDeadline time.Time                       // Deadline by which the detection must be processed
+	Count         int                             // Number of times this detection has been updated
+	Sources       map[string]datastore.NoteSource // Contributing audio sources keyed by source ID, tracked when cross-source correlation is enabled
 }
 
 // mutex is used to synchronize access to the PendingDetections map,
@@ -114,8 +142,9 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		Ds:             ds,
 		Bn:             bn,
 		BirdImageCache: birdImageCache,
-		EventTracker: NewEventTrackerWithConfig(
+		EventTracker: NewEventTrackerWithIntervals(
 			time.Duration(settings.Realtime.Interval)*time.Second,
+			NewEventTrackerConfig(&settings.Realtime.EventIntervals),
 			settings.Realtime.Species.Config,
 		),
 		Metrics:             metrics,
@@ -126,7 +155,14 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		lastDogDetectionLog: make(map[string]time.Time),
 		controlChan:         make(chan string, 10),  // Buffered channel to prevent blocking
 		JobQueue:            jobqueue.NewJobQueue(), // Initialize the job queue
+		rarity:              newRarityScorer(),
+		bwQuota:             newUploadQuota(),
+		cmdGuard:            newCommandGuard(),
+		sunCalc:             suncalc.NewSunCalc(settings.BirdNET.Latitude, settings.BirdNET.Longitude),
+		collector:           newSegmentCollector(settings),
+		humanSegments:       newHumanSegments(),
 	}
+	p.mqttBatch = newMQTTBatcher(settings, p.PublishMQTT)
 
 	// Initialize log deduplicator with configuration from settings
 	// This addresses separation of concerns by extracting deduplication logic
@@ -202,8 +238,14 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		}
 	}
 
-	// Start the detection processor
-	p.startDetectionProcessor()
+	// Open the detection buffer once the species tracker used by replayed detections is set
+	p.spool = newDetectionSpool(settings, ds, p.replayedDetectionSaved)
+
+	// Restore dynamic thresholds of the previous run
+	p.restoreDynamicThresholds()
+
+	// Start the detection processor, routing sources to profile pipelines if configured
+	p.startDetectionProcessor(p.startProfiles())
 
 	// Start the worker pool for action processing
 	p.startWorkerPool()
@@ -237,15 +279,20 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 }
 
 // Start goroutine to process detections from the queue
-func (p *Processor) startDetectionProcessor() {
+func (p *Processor) startDetectionProcessor(input <-chan birdnet.Results) {
 	// Add structured logging for detection processor startup
 	GetLogger().Info("Starting detection processor",
+		"profile", p.profileName(),
 		"operation", "detection_processor_startup")
 	go func() {
-		// ResultsQueue is fed by myaudio.ProcessData()
-		for item := range birdnet.ResultsQueue {
+		// Input is birdnet.ResultsQueue fed by myaudio.ProcessData(), or a profile route
+		for item := range input {
 			// Pass by value since we own the data (see queue.go ownership comment)
+			start := time.Now()
 			p.processDetections(item)
+			pipeline.Record(pipeline.StagePostProcessing, time.Since(start))
+			// Pooled audio returns to its pool once all pipelines processed it
+			item.PCMRef.Release()
 		}
 		// Add structured logging when processor stops
 		GetLogger().Info("Detection processor stopped",
@@ -266,6 +313,25 @@ func (p *Processor) processDetections(item birdnet.Results) {
 		"elapsed_time_ms", item.ElapsedTime.Milliseconds(),
 		"operation", "process_detections_entry")
 
+	// Drop new results once draining, only held detections are still processed
+	if p.Draining() {
+		return
+	}
+
+	if p.usesProfileModel() {
+		if err := p.predictWithProfileModel(&item); err != nil {
+			GetLogger().Error("Processing profile model prediction failed",
+				"profile", p.profileName(),
+				"source", item.Source.DisplayName,
+				"error", err,
+				"operation", "profile_model_predict")
+			return
+		}
+	}
+
+	p.dumpResults(&item)
+	p.collector.collect(&item, p.profileName())
+
 	// Detection window sets wait time before a detection is considered final and is flushed.
 	captureLength := time.Duration(p.Settings.Realtime.Audio.Export.Length) * time.Second
 	preCaptureLength := time.Duration(p.Settings.Realtime.Audio.Export.PreCapture) * time.Second
@@ -280,6 +346,20 @@ func (p *Processor) processDetections(item birdnet.Results) {
 	// Log processing results with deduplication to prevent spam
 	p.logDetectionResults(item.Source.ID, len(item.Results), len(detectionResults))
 
+	// Detections of additional acoustic event detectors share the held detections and actions
+	for _, detected := range p.runDetectors(&item) {
+		detectionResults = append(detectionResults, p.processResults(detected)...)
+	}
+
+	// Held detections outlive the pooled audio of the item, they share one copy of it
+	var heldPCM []byte
+	holdPCM := func(detection *Detections) {
+		if heldPCM == nil {
+			heldPCM = slices.Clone(item.PCMdata)
+		}
+		detection.pcmData3s = heldPCM
+	}
+
 	for i := 0; i < len(detectionResults); i++ {
 		detection := detectionResults[i]
 		commonName := strings.ToLower(detection.Note.CommonName)
@@ -292,6 +372,7 @@ func (p *Processor) processDetections(item birdnet.Results) {
 			// Update the existing detection if it's already in pendingDetections map
 			oldConfidence := existing.Confidence
 			if confidence > existing.Confidence {
+				holdPCM(&detection)
 				existing.Detection = detection
 				existing.Confidence = confidence
 				existing.Source = item.Source.ID
@@ -305,6 +386,9 @@ func (p *Processor) processDetections(item birdnet.Results) {
 					"operation", "update_pending_detection")
 			}
 			existing.Count++
+			if p.sourceCorrelationEnabled() {
+				existing.addSourceContribution(item.Source.ID, item.Source.DisplayName, confidence)
+			}
 			p.pendingDetections[commonName] = existing
 		} else {
 			// Create a new pending detection if it doesn't exist
@@ -315,7 +399,8 @@ func (p *Processor) processDetections(item birdnet.Results) {
 				"source", item.Source.DisplayName,
 				"flush_deadline", item.StartTime.Add(detectionWindow),
 				"operation", "create_pending_detection")
-			p.pendingDetections[commonName] = PendingDetection{
+			holdPCM(&detection)
+			pending := PendingDetection{
 				Detection:     detection,
 				Confidence:    confidence,
 				Source:        item.Source.ID,
@@ -323,6 +408,10 @@ func (p *Processor) processDetections(item birdnet.Results) {
 				FlushDeadline: item.StartTime.Add(detectionWindow),
 				Count:         1,
 			}
+			if p.sourceCorrelationEnabled() {
+				pending.addSourceContribution(item.Source.ID, item.Source.DisplayName, confidence)
+			}
+			p.pendingDetections[commonName] = pending
 		}
 
 		// Update the dynamic threshold for this species if enabled
@@ -348,6 +437,15 @@ func (p *Processor) processResults(item birdnet.Results) []Detections {
 	// Sync species tracker if needed
 	p.syncSpeciesTrackerIfNeeded()
 
+	// Per-source threshold and species filter overrides, nil when none configured
+	sourceOverride := p.getSourceOverride(&item.Source)
+	privacyFilter := p.privacyFilter(sourceOverride)
+	dogBarkFilter := p.dogBarkFilter(sourceOverride)
+
+	// The range model only knows the species of the main model, its list and occurrence
+	// probabilities do not apply to profile models and additional detectors
+	rangeFilter := !p.usesProfileModel() && item.Detector == ""
+
 	// Process each result in item.Results
 	for _, result := range item.Results {
 		// Parse and validate species information
@@ -369,14 +467,18 @@ func (p *Processor) processResults(item birdnet.Results) []Detections {
 
 		// Handle dog and human detection, this sets LastDogDetection and LastHumanDetection which is
 		// later used to discard detection if privacy filter or dog bark filters are enabled in settings.
-		p.handleDogDetection(item, speciesLowercase, result)
-		p.handleHumanDetection(item, speciesLowercase, result)
+		p.handleDogDetection(item, speciesLowercase, result, dogBarkFilter)
+		p.handleHumanDetection(item, speciesLowercase, result, privacyFilter)
 
 		// Determine confidence threshold and check filters
-		baseThreshold := p.getBaseConfidenceThreshold(speciesLowercase)
+		baseThreshold := p.applySourceThreshold(sourceOverride, speciesLowercase, p.getBaseConfidenceThreshold(speciesLowercase))
+		var seasonal *seasonalPrior
+		if rangeFilter {
+			baseThreshold, seasonal = p.applySeasonalPrior(result.Species, speciesLowercase, item.StartTime, baseThreshold)
+		}
 
 		// Check if detection should be filtered
-		shouldSkip, _ := p.shouldFilterDetection(result, commonName, speciesLowercase, baseThreshold, item.Source.ID)
+		shouldSkip, _ := p.shouldFilterDetection(result, scientificName, commonName, speciesLowercase, baseThreshold, item.Source.ID, sourceOverride, rangeFilter)
 		if shouldSkip {
 			continue
 		}
@@ -387,7 +489,7 @@ func (p *Processor) processResults(item birdnet.Results) []Detections {
 		}
 
 		// Create the detection
-		detection := p.createDetection(item, result, scientificName, commonName, speciesCode)
+		detection := p.createDetection(item, result, scientificName, commonName, speciesCode, seasonal)
 		detections = append(detections, detection)
 	}
 
@@ -435,7 +537,7 @@ func (p *Processor) parseAndValidateSpecies(result datastore.Results, item birdn
 }
 
 // shouldFilterDetection checks if a detection should be filtered out
-func (p *Processor) shouldFilterDetection(result datastore.Results, commonName, speciesLowercase string, baseThreshold float32, source string) (shouldFilter bool, confidenceThreshold float32) {
+func (p *Processor) shouldFilterDetection(result datastore.Results, scientificName, commonName, speciesLowercase string, baseThreshold float32, source string, sourceOverride *conf.SourceOverride, rangeFilter bool) (shouldFilter bool, confidenceThreshold float32) {
 	// Check human detection privacy filter
 	if strings.Contains(strings.ToLower(commonName), speciesHuman) && result.Confidence > baseThreshold {
 		return true, 0 // Filter out human detections for privacy
@@ -461,8 +563,21 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 		return true, confidenceThreshold
 	}
 
-	// Check species inclusion filter
-	if !p.Settings.IsSpeciesIncluded(result.Species) {
+	// Check per-source species exclusion filter
+	if sourceOverride != nil && sourceOverride.ExcludesSpecies(scientificName, commonName) {
+		if p.Settings.Debug {
+			GetLogger().Debug("Species excluded for source",
+				"species", result.Species,
+				"confidence", result.Confidence,
+				"source", p.getDisplayNameForSource(source),
+				"operation", "source_species_filter")
+		}
+		return true, confidenceThreshold
+	}
+
+	// Check species inclusion filter, per-source include list bypasses the global list
+	if rangeFilter && !p.Settings.IsSpeciesIncluded(result.Species) &&
+		(sourceOverride == nil || !sourceOverride.IncludesSpecies(scientificName, commonName)) {
 		if p.Settings.Debug {
 			GetLogger().Debug("Species not on included list",
 				"species", result.Species,
@@ -479,7 +594,7 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 // createDetection creates a detection object with all necessary information
 //
 //nolint:gocritic // hugeParam: Pass by value is intentional - avoids pointer dereferencing in hot path
-func (p *Processor) createDetection(item birdnet.Results, result datastore.Results, scientificName, commonName, speciesCode string) Detections {
+func (p *Processor) createDetection(item birdnet.Results, result datastore.Results, scientificName, commonName, speciesCode string, seasonal *seasonalPrior) Detections {
 	// Create file name for audio clip
 	clipName := p.generateClipName(scientificName, result.Confidence)
 
@@ -491,8 +606,11 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 	beginTime := item.StartTime
 	endTime := item.StartTime.Add(captureLength - preCaptureLength)
 
-	// Get occurrence probability for this species at detection time
-	occurrence := p.Bn.GetSpeciesOccurrenceAtTime(result.Species, item.StartTime)
+	// Get occurrence probability for this species at detection time, unknown for detector events
+	var occurrence float64
+	if item.Detector == "" {
+		occurrence = p.Bn.GetSpeciesOccurrenceAtTime(result.Species, item.StartTime)
+	}
 
 	// Create the note
 	note := p.NewWithSpeciesInfo(
@@ -501,6 +619,11 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 		float64(result.Confidence),
 		item.Source.ID, clipName,
 		item.ElapsedTime, occurrence)
+	recordSeasonalPrior(&note, seasonal)
+	note.Detector = item.Detector
+	if item.Detector == "" {
+		note.RarityScore = p.scoreRarity(scientificName, item.StartTime, occurrence)
+	}
 
 	// Update species tracker if enabled
 	p.speciesTrackerMu.RLock()
@@ -517,6 +640,7 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 	return Detections{
 		CorrelationID: correlationID,
 		pcmData3s:     item.PCMdata,
+		pcmStart:      item.StartTime,
 		Note:          note,
 		Results:       item.Results,
 	}
@@ -554,16 +678,16 @@ func (p *Processor) syncSpeciesTrackerIfNeeded() {
 // handleDogDetection handles the detection of dog barks and updates the last detection timestamp.
 //
 //nolint:gocritic // hugeParam: Pass by value is intentional - avoids pointer dereferencing in hot path
-func (p *Processor) handleDogDetection(item birdnet.Results, speciesLowercase string, result datastore.Results) {
-	if p.Settings.Realtime.DogBarkFilter.Enabled && strings.Contains(speciesLowercase, speciesDog) &&
-		result.Confidence > p.Settings.Realtime.DogBarkFilter.Confidence {
+func (p *Processor) handleDogDetection(item birdnet.Results, speciesLowercase string, result datastore.Results, filter sourceFilter) {
+	if filter.enabled && strings.Contains(speciesLowercase, speciesDog) &&
+		result.Confidence > filter.confidence {
 		// Add structured logging
 		GetLogger().Info("Dog detection filtered",
 			"confidence", result.Confidence,
-			"threshold", p.Settings.Realtime.DogBarkFilter.Confidence,
+			"threshold", filter.confidence,
 			"source", item.Source.DisplayName,
 			"operation", "dog_bark_filter")
-		log.Printf("Dog detected with confidence %.3f/%.3f from source %s", result.Confidence, p.Settings.Realtime.DogBarkFilter.Confidence, item.Source.DisplayName)
+		log.Printf("Dog detected with confidence %.3f/%.3f from source %s", result.Confidence, filter.confidence, item.Source.DisplayName)
 		p.detectionMutex.Lock()
 		p.LastDogDetection[item.Source.ID] = item.StartTime
 		p.detectionMutex.Unlock()
@@ -573,29 +697,30 @@ func (p *Processor) handleDogDetection(item birdnet.Results, speciesLowercase st
 // handleHumanDetection handles the detection of human vocalizations and updates the last detection timestamp.
 //
 //nolint:gocritic // hugeParam: Pass by value is intentional - avoids pointer dereferencing in hot path
-func (p *Processor) handleHumanDetection(item birdnet.Results, speciesLowercase string, result datastore.Results) {
+func (p *Processor) handleHumanDetection(item birdnet.Results, speciesLowercase string, result datastore.Results, filter sourceFilter) {
 	// only check this if privacy filter is enabled
-	if p.Settings.Realtime.PrivacyFilter.Enabled && strings.Contains(speciesLowercase, "human ") &&
-		result.Confidence > p.Settings.Realtime.PrivacyFilter.Confidence {
+	if filter.enabled && strings.Contains(speciesLowercase, "human ") &&
+		result.Confidence > filter.confidence {
 		// Add structured logging
 		GetLogger().Info("Human detection filtered",
 			"confidence", result.Confidence,
-			"threshold", p.Settings.Realtime.PrivacyFilter.Confidence,
+			"threshold", filter.confidence,
 			"source", item.Source.DisplayName,
 			"operation", "privacy_filter")
-		log.Printf("Human detected with confidence %.3f/%.3f from source %s", result.Confidence, p.Settings.Realtime.PrivacyFilter.Confidence, item.Source.DisplayName)
+		log.Printf("Human detected with confidence %.3f/%.3f from source %s", result.Confidence, filter.confidence, item.Source.DisplayName)
 		// put human detection timestamp into LastHumanDetection map. This is used to discard
-		// bird detections if a human vocalization is detected after the first detection
+		// bird detections if a human vocalization is detected within the privacy filter window
 		p.detectionMutex.Lock()
 		p.LastHumanDetection[item.Source.ID] = item.StartTime
 		p.detectionMutex.Unlock()
+		p.recordHumanSegment(item.Source.ID, item.StartTime)
 	}
 }
 
 // getBaseConfidenceThreshold retrieves the confidence threshold for a species, using custom or global thresholds.
 func (p *Processor) getBaseConfidenceThreshold(speciesLowercase string) float32 {
 	// Check if species has a custom threshold in the new structure
-	if config, exists := p.Settings.Realtime.Species.Config[speciesLowercase]; exists {
+	if config, exists := p.getSpeciesConfig(speciesLowercase); exists {
 		if p.Settings.Debug {
 			// Add structured logging
 			GetLogger().Debug("Using custom confidence threshold",
@@ -607,8 +732,8 @@ func (p *Processor) getBaseConfidenceThreshold(speciesLowercase string) float32
 		return float32(config.Threshold)
 	}
 
-	// Fall back to global threshold
-	return float32(p.Settings.BirdNET.Threshold)
+	// Fall back to global (or profile) threshold
+	return p.getGlobalThreshold()
 }
 
 // generateClipName generates a clip name for the given scientific name and confidence.
@@ -654,12 +779,17 @@ func (p *Processor) shouldDiscardDetection(item *PendingDetection, minDetections
 		return true, fmt.Sprintf("false positive, matched %d/%d times", item.Count, minDetections)
 	}
 
-	// Check privacy filter
-	if p.Settings.Realtime.PrivacyFilter.Enabled {
+	sourceOverride := p.getSourceOverride(&item.Detection.Note.Source)
+
+	// Check privacy filter, a human voice during the detection or within the filter window
+	// before it discards the detection. In redact mode the detection is kept and the human
+	// voice is muted in its audio instead.
+	privacyFilter := p.privacyFilter(sourceOverride)
+	if privacyFilter.enabled && p.Settings.Realtime.PrivacyFilter.Mode != conf.PrivacyFilterModeRedact {
 		p.detectionMutex.RLock()
 		lastHumanDetection, exists := p.LastHumanDetection[item.Source]
 		p.detectionMutex.RUnlock()
-		if exists && lastHumanDetection.After(item.FirstDetected) {
+		if exists && lastHumanDetection.After(item.FirstDetected.Add(-privacyFilter.window)) {
 			// Add structured logging for privacy filter
 			GetLogger().Debug("Detection discarded by privacy filter",
 				"species", item.Detection.Note.CommonName,
@@ -672,7 +802,7 @@ func (p *Processor) shouldDiscardDetection(item *PendingDetection, minDetections
 	}
 
 	// Check dog bark filter
-	if p.Settings.Realtime.DogBarkFilter.Enabled {
+	if dogBarkFilter := p.dogBarkFilter(sourceOverride); dogBarkFilter.enabled {
 		if p.Settings.Realtime.DogBarkFilter.Debug {
 			p.detectionMutex.RLock()
 			// Add structured logging
@@ -685,8 +815,8 @@ func (p *Processor) shouldDiscardDetection(item *PendingDetection, minDetections
 		p.detectionMutex.RLock()
 		lastDogDetection := p.LastDogDetection[item.Source]
 		p.detectionMutex.RUnlock()
-		if p.CheckDogBarkFilter(item.Detection.Note.CommonName, lastDogDetection) ||
-			p.CheckDogBarkFilter(item.Detection.Note.ScientificName, lastDogDetection) {
+		if p.CheckDogBarkFilter(item.Detection.Note.CommonName, lastDogDetection, dogBarkFilter.window) ||
+			p.CheckDogBarkFilter(item.Detection.Note.ScientificName, lastDogDetection, dogBarkFilter.window) {
 			// Add structured logging for dog bark filter
 			GetLogger().Debug("Detection discarded by dog bark filter",
 				"species", item.Detection.Note.CommonName,
@@ -721,6 +851,14 @@ func (p *Processor) processApprovedDetection(item *PendingDetection, speciesName
 		speciesName, p.getDisplayNameForSource(item.Source), item.Count)
 
 	item.Detection.Note.BeginTime = item.FirstDetected
+	item.Detection.Sources = item.correlatedSources()
+	if len(item.Detection.Sources) > 1 {
+		GetLogger().Info("Detection heard by multiple sources",
+			"species", speciesName,
+			"primary_source", p.getDisplayNameForSource(item.Source),
+			"source_count", len(item.Detection.Sources),
+			"operation", "correlate_sources")
+	}
 	actionList := p.getActionsForItem(&item.Detection)
 	for _, action := range actionList {
 		task := &Task{Type: TaskTypeAction, Detection: item.Detection, Action: action}
@@ -756,8 +894,8 @@ func (p *Processor) processApprovedDetection(item *PendingDetection, speciesName
 // and flushes them to the worker queue if their deadline has passed.
 func (p *Processor) pendingDetectionsFlusher() {
 	// Calculate minimum detections based on overlap setting
-	segmentLength := math.Max(0.1, 3.0-p.Settings.BirdNET.Overlap)
-	minDetections := int(math.Max(1, 3/segmentLength))
+	segmentLength := math.Max(0.1, conf.AnalysisHop(p.Settings.BirdNET.Overlap))
+	minDetections := int(math.Max(1, conf.CaptureLength/segmentLength))
 
 	// Add structured logging for pending detections flusher startup
 	GetLogger().Info("Starting pending detections flusher",
@@ -768,6 +906,7 @@ func (p *Processor) pendingDetectionsFlusher() {
 	go func() {
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
+		lastThresholdPersist := time.Now()
 
 		for {
 			<-ticker.C
@@ -808,6 +947,10 @@ func (p *Processor) pendingDetectionsFlusher() {
 			p.pendingMutex.Unlock()
 
 			p.cleanUpDynamicThresholds()
+			if now.Sub(lastThresholdPersist) >= dynamicThresholdPersistInterval {
+				p.persistDynamicThresholds()
+				lastThresholdPersist = now
+			}
 		}
 	}()
 }
@@ -817,7 +960,7 @@ func (p *Processor) getActionsForItem(detection *Detections) []Action {
 	speciesName := strings.ToLower(detection.Note.CommonName)
 
 	// Check if species has custom configuration
-	if speciesConfig, exists := p.Settings.Realtime.Species.Config[speciesName]; exists {
+	if speciesConfig, exists := p.getSpeciesConfig(speciesName); exists {
 		if p.Settings.Debug {
 			// Add structured logging
 			GetLogger().Debug("Species config exists for custom actions",
@@ -828,24 +971,37 @@ func (p *Processor) getActionsForItem(detection *Detections) []Action {
 
 		var actions []Action
 		var executeDefaults bool
+		hasUploadActions, uploadConditionMet := false, false
 
-		// Add custom actions from the new structure
-		for _, actionConfig := range speciesConfig.Actions {
+		// Add custom actions from the new structure, followed by the actions of recipes
+		for _, actionConfig := range speciesConfig.ExpandedActions() {
+			// If any action has ExecuteDefaults set to true, we'll include default actions
+			if actionConfig.ExecuteDefaults {
+				executeDefaults = true
+			}
+
+			conditionMet := p.actionConditionMet(&actionConfig.When, &detection.Note)
 			switch actionConfig.Type {
-			case "ExecuteCommand":
-				if len(actionConfig.Parameters) > 0 {
+			case conf.ActionTypeExecuteCommand:
+				if conditionMet && len(actionConfig.Parameters) > 0 {
 					actions = append(actions, &ExecuteCommandAction{
 						Command: actionConfig.Command,
 						Params:  parseCommandParams(actionConfig.Parameters, detection),
+						guard:   p.cmdGuard,
+						limits:  commandLimitsFor(&p.Settings.Realtime.CommandGuard, &actionConfig),
 					})
 				}
-			case "SendNotification":
-				// Add notification action handling
-				// ... implementation ...
-			}
-			// If any action has ExecuteDefaults set to true, we'll include default actions
-			if actionConfig.ExecuteDefaults {
-				executeDefaults = true
+			case conf.ActionTypeSendNotification:
+				if conditionMet {
+					actions = append(actions, &NotificationAction{
+						Note:          detection.Note,
+						CorrelationID: detection.CorrelationID,
+					})
+				}
+			case conf.ActionTypeBirdWeatherUpload:
+				// Gates the default BirdWeather upload instead of adding an action
+				hasUploadActions = true
+				uploadConditionMet = uploadConditionMet || conditionMet
 			}
 		}
 
@@ -854,11 +1010,13 @@ func (p *Processor) getActionsForItem(detection *Detections) []Action {
 			return actions
 		}
 
-		// If executeDefaults is true, combine custom and default actions
-		if len(actions) > 0 && executeDefaults {
-			defaultActions := p.getDefaultActions(detection)
-			return append(actions, defaultActions...)
+		// Otherwise combine custom and default actions. BirdWeather upload actions keep the
+		// default upload only when one of their conditions is met.
+		defaultActions := p.getDefaultActions(detection)
+		if hasUploadActions && !uploadConditionMet {
+			defaultActions = withoutBirdWeather(defaultActions)
 		}
+		return append(actions, defaultActions...)
 	}
 
 	// Fall back to default actions if no custom actions or if custom actions should be combined
@@ -916,6 +1074,7 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 			processor:         p, // Add processor reference for source name resolution
 			Note:              detection.Note,
 			Results:           detection.Results,
+			Sources:           detection.Sources,
 			Ds:                p.Ds,
 			CorrelationID:     detection.CorrelationID,
 		}
@@ -941,39 +1100,32 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 			SSEBroadcaster: sseBroadcaster,
 			Ds:             p.Ds,
 			CorrelationID:  detection.CorrelationID,
+			deadline:       p.actionDeadline(detection),
 		}
 	}
 
 	// CRITICAL FIX for GitHub issue #1158: Race condition between DatabaseAction and SSEAction
 	//
-	// Problem: When both database and SSE are enabled, they execute concurrently via the job queue.
-	// SSEAction polls for database records that haven't been saved yet, causing timeout errors:
+	// Problem: SSEAction needs the database ID of the note, so the frontend can load its
+	// audio and spectrogram, but polling for the ID while the note is saved concurrently
+	// timed out on slow storage:
 	//   - "database ID not assigned for Eastern Wood-Pewee after 10s timeout"
-	//   - "note not found in database"
-	//   - "audio file ... not ready after 5s timeout"
-	//
-	// Solution: Combine DatabaseAction and SSEAction into a CompositeAction that executes them
-	// sequentially. This ensures the database save completes before SSE attempts to broadcast,
-	// eliminating the race condition while maintaining all other actions' concurrent execution.
 	//
-	// This is particularly important on resource-constrained hardware (e.g., Raspberry Pi with
-	// SD cards) where database writes can take several seconds to complete.
+	// Solution: DatabaseAction and SSEAction run concurrently and share a NoteIDPromise. SSEAction
+	// broadcasts its provisional ID without waiting for the save and DatabaseAction resolves it
+	// with the database ID once Ds.Save returns. A note buffered while the database is unavailable
+	// stays pending until the buffer replay saves it. API endpoints taking a note ID resolve
+	// provisional IDs, waiting for the save if it is still running.
 	if databaseAction != nil && sseAction != nil {
-		// Create composite action for sequential execution
-		compositeAction := &CompositeAction{
-			Actions:       []Action{databaseAction, sseAction},
-			Description:   "Database save and SSE broadcast (sequential)",
-			CorrelationID: detection.CorrelationID,
-		}
-		actions = append(actions, compositeAction)
-	} else {
-		// Add them individually if only one is enabled
-		if databaseAction != nil {
-			actions = append(actions, databaseAction)
-		}
-		if sseAction != nil {
-			actions = append(actions, sseAction)
-		}
+		idPromise := datastore.NewNoteIDPromise()
+		databaseAction.IDPromise = idPromise
+		sseAction.IDPromise = idPromise
+	}
+	if databaseAction != nil {
+		actions = append(actions, databaseAction)
+	}
+	if sseAction != nil {
+		actions = append(actions, sseAction)
 	}
 
 	// Add BirdWeatherAction if enabled and client is initialized
@@ -989,14 +1141,21 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 				Multiplier:   p.Settings.Realtime.Birdweather.RetrySettings.BackoffMultiplier,
 			}
 
+			// Analyzed audio carries the calibration gain offset only when it is applied to analysis
+			uploadPCM := detection.pcmData3s
+			if !p.Settings.Realtime.Audio.Calibration.ApplyToAnalysis {
+				uploadPCM = myaudio.ApplySourceCalibration(detection.Note.Source.ID, uploadPCM)
+			}
+
 			actions = append(actions, &BirdWeatherAction{
 				Settings:      p.Settings,
 				EventTracker:  p.GetEventTracker(),
 				BwClient:      bwClient,
 				Note:          detection.Note,
-				pcmData:       detection.pcmData3s,
+				pcmData:       p.redactHumanVoices(detection.Note.Source.ID, p.clipTime(detection.pcmStart), uploadPCM, detection.CorrelationID),
 				RetryConfig:   bwRetryConfig,
 				CorrelationID: detection.CorrelationID,
+				quota:         p.bwQuota,
 			})
 		}
 	}
@@ -1022,6 +1181,8 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 				BirdImageCache: p.BirdImageCache,
 				RetryConfig:    mqttRetryConfig,
 				CorrelationID:  detection.CorrelationID,
+				batcher:        p.mqttBatch,
+				deadline:       p.actionDeadline(detection),
 			})
 		}
 	}
@@ -1047,6 +1208,9 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 
 // GetBwClient safely returns the current BirdWeather client
 func (p *Processor) GetBwClient() *birdweather.BwClient {
+	if p.parent != nil {
+		return p.parent.GetBwClient()
+	}
 	p.bwClientMutex.RLock()
 	defer p.bwClientMutex.RUnlock()
 	return p.BwClient
@@ -1110,6 +1274,9 @@ func (p *Processor) SetSSEBroadcaster(broadcaster func(note *datastore.Note, bir
 
 // GetSSEBroadcaster safely returns the current SSE broadcaster function
 func (p *Processor) GetSSEBroadcaster() func(note *datastore.Note, birdImage *imageprovider.BirdImage) error {
+	if p.parent != nil {
+		return p.parent.GetSSEBroadcaster()
+	}
 	p.sseBroadcasterMutex.RLock()
 	defer p.sseBroadcasterMutex.RUnlock()
 	return p.SSEBroadcaster
@@ -1183,6 +1350,16 @@ func (p *Processor) getDisplayNameForSource(sourceID string) string {
 
 // Shutdown gracefully stops all processor components
 func (p *Processor) Shutdown() error {
+	// Shut down profile pipelines first, they share integrations with this processor
+	for _, profileProcessor := range p.profiles {
+		if err := profileProcessor.Shutdown(); err != nil {
+			GetLogger().Warn("Failed to shut down processing profile",
+				"profile", profileProcessor.profileName(),
+				"error", err,
+				"operation", "profile_shutdown")
+		}
+	}
+
 	// Cancel all worker goroutines
 	if p.workerCancel != nil {
 		p.workerCancel()
@@ -1198,9 +1375,33 @@ func (p *Processor) Shutdown() error {
 		log.Printf("Warning: job queue shutdown timed out: %v", err)
 	}
 
+	// Save dynamic thresholds for the next run
+	p.persistDynamicThresholds()
+
+	// Integrations and the species tracker are owned by the default pipeline
+	if p.parent != nil {
+		GetLogger().Info("Processing profile shutdown complete",
+			"profile", p.profileName(),
+			"operation", "profile_shutdown")
+		return nil
+	}
+
+	// Stop saving buffered detections, the remaining ones are saved by the next run
+	p.spool.close()
+
 	// Disconnect BirdWeather client
 	p.DisconnectBwClient()
 
+	// Publish batched MQTT messages before disconnecting
+	if p.mqttBatch != nil {
+		p.mqttBatch.close()
+	}
+
+	// Finish writing collected segments
+	if p.collector != nil {
+		p.collector.close()
+	}
+
 	// Disconnect MQTT client if connected
 	mqttClient := p.GetMQTTClient()
 	if mqttClient != nil && mqttClient.IsConnected() {
@@ -1239,14 +1440,12 @@ func (p *Processor) NewWithSpeciesInfo(
 	elapsedTime time.Duration,
 	occurrence float64) datastore.Note {
 
-	// detectionTime is time now minus 3 seconds to account for the delay in the detection
-	now := time.Now()
-	date := now.Format("2006-01-02")
-	detectionTime := now.Add(-2 * time.Second)
-	timeStr := detectionTime.Format("15:04:05")
+	date, timeStr := observation.Timestamp(p.Settings, beginTime)
 
 	var sourceStruct datastore.AudioSource
+	sourceType := myaudio.SourceTypeUnknown
 	if p.Settings.Input.Path != "" {
+		sourceType = myaudio.SourceTypeFile
 		// For file input, create simple source struct
 		sourceStruct = datastore.AudioSource{
 			ID:          source, // Use original source as ID for file operations
@@ -1259,6 +1458,7 @@ func (p *Processor) NewWithSpeciesInfo(
 		if registry != nil {
 			// Try to get existing source by connection string
 			if existingSource, exists := registry.GetSourceByConnection(source); exists {
+				sourceType = existingSource.Type
 				sourceStruct = datastore.AudioSource{
 					ID:          existingSource.ID,          // Use source ID for buffer operations
 					SafeString:  existingSource.SafeString,  // Use sanitized string for logging
@@ -1267,6 +1467,7 @@ func (p *Processor) NewWithSpeciesInfo(
 			} else {
 				// Try to get by ID directly
 				if registrySource, exists := registry.GetSourceByID(source); exists {
+					sourceType = registrySource.Type
 					sourceStruct = datastore.AudioSource{
 						ID:          registrySource.ID,
 						SafeString:  registrySource.SafeString,
@@ -1294,7 +1495,10 @@ func (p *Processor) NewWithSpeciesInfo(
 	// Round confidence to two decimal places
 	roundedConfidence := math.Round(confidence*100) / 100
 
-	// Return a new Note struct populated with the provided parameters and the current date and time
+	// Store the common name in the configured UI locale, the scientific name stays canonical
+	commonName = birdnet.LocalizedCommonName(p.Settings, scientificName, commonName)
+
+	// Return a new Note struct populated with the provided parameters and the detection date and time
 	return datastore.Note{
 		SourceNode:     p.Settings.Main.Name,           // From the provided configuration settings
 		Date:           date,                           // Use ISO 8601 date format
@@ -1313,6 +1517,9 @@ func (p *Processor) NewWithSpeciesInfo(
 		ClipName:       clipName,                       // Name of the audio clip
 		ProcessingTime: elapsedTime,                    // Time taken to process the observation
 		Occurrence:     occurrence,                     // Runtime occurrence probability (not persisted to DB)
+		Profile:        p.profileName(),                // Processing profile that made the detection, empty for the default pipeline
+
+		AnalysisSnapshot: p.analysisSnapshot(sourceType), // Model and settings in effect, stored as a shared snapshot
 	}
 }
 
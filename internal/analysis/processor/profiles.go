@@ -0,0 +1,317 @@
+// profiles.go: independent analysis pipelines for sources assigned to processing profiles
+package processor
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// getSpeciesConfig returns the custom configuration for a species. Processors running a
+// profile use the profile's species configuration first and fall back to the global one.
+func (p *Processor) getSpeciesConfig(speciesLowercase string) (conf.SpeciesConfig, bool) {
+	if p.profile != nil {
+		if config, exists := lookupSpeciesConfig(p.profile.Species.Config, speciesLowercase); exists {
+			return config, true
+		}
+	}
+	config, exists := p.Settings.Realtime.Species.Config[speciesLowercase]
+	return config, exists
+}
+
+// lookupSpeciesConfig finds a species configuration by case-insensitive name
+func lookupSpeciesConfig(configs map[string]conf.SpeciesConfig, speciesLowercase string) (conf.SpeciesConfig, bool) {
+	if config, exists := configs[speciesLowercase]; exists {
+		return config, true
+	}
+	for name, config := range configs {
+		if strings.EqualFold(name, speciesLowercase) {
+			return config, true
+		}
+	}
+	return conf.SpeciesConfig{}, false
+}
+
+// getGlobalThreshold returns the confidence threshold used for species without a custom threshold
+func (p *Processor) getGlobalThreshold() float32 {
+	if p.profile != nil && p.profile.Threshold > 0 {
+		return float32(p.profile.Threshold)
+	}
+	return float32(p.Settings.BirdNET.Threshold)
+}
+
+// profileName returns the name of the processing profile, empty for the default pipeline
+func (p *Processor) profileName() string {
+	if p.profile == nil {
+		return ""
+	}
+	return p.profile.Name
+}
+
+// startProfiles creates a processor for each configured processing profile and starts routing
+// results from birdnet.ResultsQueue by audio source. A source assigned to several profiles
+// feeds all of them. It returns the channel feeding the default pipeline, which is the
+// results queue itself when no profiles are configured.
+func (p *Processor) startProfiles() <-chan birdnet.Results {
+	if len(p.Settings.Realtime.Profiles) == 0 {
+		return birdnet.ResultsQueue
+	}
+
+	// Profiles whose custom model fails to load are not started, their sources stay on
+	// the other pipelines they are assigned to
+	var profiles []*conf.ProcessingProfile
+	var inputs []*pipelineInput
+	for i := range p.Settings.Realtime.Profiles {
+		profile := &p.Settings.Realtime.Profiles[i]
+		model, err := p.profileModel(profile)
+		if err != nil {
+			GetLogger().Error("Failed to load processing profile model, profile disabled",
+				"profile", profile.Name,
+				"model_path", profile.ModelPath,
+				"error", err,
+				"operation", "profile_model_load")
+			continue
+		}
+		input := newPipelineInput(profile.Name)
+		profiles = append(profiles, profile)
+		inputs = append(inputs, input)
+		p.profiles = append(p.profiles, p.newProfileProcessor(profile, model, input.ch))
+	}
+
+	defaultInput := newPipelineInput("default")
+	resultsQueue := birdnet.ResultsQueue
+	go func() {
+		defer func() {
+			close(defaultInput.ch)
+			for _, input := range inputs {
+				close(input.ch)
+			}
+		}()
+
+		routes := make(map[string]profileRoute)
+		for item := range resultsQueue {
+			route, known := routes[item.Source.ID]
+			if !known {
+				route = routeSource(profiles, &item.Source)
+				routes[item.Source.ID] = route
+				GetLogger().Info("Routing audio source to processing pipelines",
+					"source", item.Source.DisplayName,
+					"pipelines", route.names(profiles),
+					"operation", "route_source_profile")
+			}
+
+			// Profiles only read the shared results and audio, so all receive the same item
+			// and each releases its reference to the audio after processing it. A pipeline
+			// that falls behind misses items instead of holding up the others.
+			if route.defaultPipeline {
+				defaultInput.send(item)
+			}
+			for _, index := range route.profiles {
+				inputs[index].send(item)
+			}
+			item.PCMRef.Release()
+		}
+	}()
+
+	GetLogger().Info("Processing profiles enabled",
+		"profile_count", len(profiles),
+		"operation", "profiles_startup")
+
+	return defaultInput.ch
+}
+
+// pipelineDropLogInterval limits how often dropped results of a pipeline are logged
+const pipelineDropLogInterval = time.Minute
+
+// pipelineInput is the results channel of a processing pipeline fed by the profile router
+type pipelineInput struct {
+	name    string
+	ch      chan birdnet.Results
+	dropped atomic.Uint64 // results discarded because the pipeline's channel was full
+	lastLog time.Time     // when dropped results were last logged, used by the router only
+}
+
+// newPipelineInput returns the input of the named pipeline
+func newPipelineInput(name string) *pipelineInput {
+	return &pipelineInput{
+		name: name,
+		ch:   make(chan birdnet.Results, birdnet.DefaultQueueSize),
+	}
+}
+
+// send passes a results item to the pipeline without blocking. The item is dropped when the
+// pipeline's channel is full, so a slow pipeline cannot stall routing to the others. It
+// reports whether the item was accepted.
+func (in *pipelineInput) send(item birdnet.Results) bool {
+	item.PCMRef.Retain()
+	select {
+	case in.ch <- item:
+		return true
+	default:
+	}
+
+	item.PCMRef.Release()
+	dropped := in.dropped.Add(1)
+	if now := time.Now(); now.Sub(in.lastLog) >= pipelineDropLogInterval {
+		in.lastLog = now
+		GetLogger().Warn("Processing pipeline is falling behind, dropping results",
+			"pipeline", in.name,
+			"source", item.Source.DisplayName,
+			"dropped_total", dropped,
+			"operation", "route_source_profile")
+	}
+	return false
+}
+
+// profileRoute lists the pipelines the results of an audio source are sent to
+type profileRoute struct {
+	profiles        []int // indexes of the profiles the source is assigned to
+	defaultPipeline bool  // the default pipeline also receives the results
+}
+
+// names returns the names of the route's pipelines for logging
+func (r profileRoute) names(profiles []*conf.ProcessingProfile) []string {
+	names := make([]string, 0, len(r.profiles)+1)
+	if r.defaultPipeline {
+		names = append(names, "default")
+	}
+	for _, index := range r.profiles {
+		names = append(names, profiles[index].Name)
+	}
+	return names
+}
+
+// routeSource returns the pipelines of an audio source. A source leaves the default pipeline
+// when a profile analyzing the results of the main model is assigned to it; profiles running
+// their own model add to the default pipeline instead of replacing it.
+func routeSource(profiles []*conf.ProcessingProfile, source *datastore.AudioSource) profileRoute {
+	route := profileRoute{defaultPipeline: true}
+	for i, profile := range profiles {
+		if !slices.ContainsFunc(profile.Sources, func(match string) bool { return sourceMatches(match, source) }) {
+			continue
+		}
+		route.profiles = append(route.profiles, i)
+		if profile.ModelPath == "" {
+			route.defaultPipeline = false
+		}
+	}
+	return route
+}
+
+// profileModel loads the custom model of a profile, nil when the profile analyzes the
+// results of the main model
+func (p *Processor) profileModel(profile *conf.ProcessingProfile) (*birdnet.BirdNET, error) {
+	if profile.ModelPath == "" {
+		return nil, nil
+	}
+
+	// The profile model uses the main model settings apart from its model and labels
+	settings := *p.Settings
+	settings.BirdNET.ModelPath = profile.ModelPath
+	settings.BirdNET.LabelPath = profile.LabelPath
+	return birdnet.NewBirdNET(&settings)
+}
+
+// usesProfileModel reports whether the processor runs the custom model of its profile
+func (p *Processor) usesProfileModel() bool {
+	return p.profile != nil && p.profile.ModelPath != ""
+}
+
+// predictWithProfileModel replaces the results of the main model with the predictions of the
+// profile model for the same audio
+func (p *Processor) predictWithProfileModel(item *birdnet.Results) error {
+	predictStart := time.Now()
+	sampleData, err := myaudio.ConvertToFloat32(item.PCMdata, conf.BitDepth)
+	if err != nil {
+		return err
+	}
+	results, err := p.Bn.Predict(sampleData)
+	if len(sampleData) > 0 {
+		myaudio.ReturnFloat32Buffer(sampleData[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	item.Results = results
+	item.ElapsedTime = time.Since(predictStart)
+	return nil
+}
+
+// newProfileProcessor creates a processor for a single profile. It has its own pending
+// detections, dynamic thresholds, event tracker and job queue, and shares the datastore,
+// species tracker and integration clients with the parent processor. A profile with a custom
+// model analyzes the audio with that model instead of using the results of the main one.
+func (p *Processor) newProfileProcessor(profile *conf.ProcessingProfile, model *birdnet.BirdNET, input <-chan birdnet.Results) *Processor {
+	// Profile species configuration overrides the global one for event intervals
+	speciesConfig := make(map[string]conf.SpeciesConfig, len(p.Settings.Realtime.Species.Config)+len(profile.Species.Config))
+	for name, config := range p.Settings.Realtime.Species.Config {
+		speciesConfig[name] = config
+	}
+	for name, config := range profile.Species.Config {
+		speciesConfig[strings.ToLower(name)] = config
+	}
+
+	if model == nil {
+		model = p.Bn
+	}
+
+	child := &Processor{
+		Settings:       p.Settings,
+		Ds:             p.Ds,
+		Bn:             model,
+		BirdImageCache: p.BirdImageCache,
+		EventTracker: NewEventTrackerWithIntervals(
+			time.Duration(p.Settings.Realtime.Interval)*time.Second,
+			NewEventTrackerConfig(&p.Settings.Realtime.EventIntervals),
+			speciesConfig,
+		),
+		NewSpeciesTracker:   p.NewSpeciesTracker,
+		Metrics:             p.Metrics,
+		LastDogDetection:    make(map[string]time.Time),
+		LastHumanDetection:  make(map[string]time.Time),
+		DynamicThresholds:   make(map[string]*DynamicThreshold),
+		pendingDetections:   make(map[string]PendingDetection),
+		lastDogDetectionLog: make(map[string]time.Time),
+		controlChan:         make(chan string, 10),
+		JobQueue:            jobqueue.NewJobQueue(),
+		logDedup:            p.logDedup,
+		rarity:              p.rarity,
+		mqttBatch:           p.mqttBatch,
+		collector:           p.collector,
+		humanSegments:       p.humanSegments,
+		spool:               p.spool,
+		bwQuota:             p.bwQuota,
+		cmdGuard:            p.cmdGuard,
+		sunCalc:             p.sunCalc,
+		parent:              p,
+		profile:             profile,
+		profileOverride: &conf.SourceOverride{
+			Source:  profile.Name,
+			Include: profile.Species.Include,
+			Exclude: profile.Species.Exclude,
+		},
+	}
+
+	GetLogger().Info("Starting processing profile",
+		"profile", profile.Name,
+		"sources", len(profile.Sources),
+		"threshold", child.getGlobalThreshold(),
+		"model", child.Bn.ModelInfo.ID,
+		"operation", "profile_startup")
+
+	child.restoreDynamicThresholds()
+	child.startDetectionProcessor(input)
+	child.startWorkerPool()
+	child.pendingDetectionsFlusher()
+	child.JobQueue.Start()
+
+	return child
+}
@@ -0,0 +1,159 @@
+// rarity.go: score how unusual a detection is for this station and week of the year
+package processor
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// rarityNotificationCooldown is the minimum time between rare species notifications for a species
+const rarityNotificationCooldown = 24 * time.Hour
+
+// rarityScorer caches the local detection history used for rarity scores and tracks
+// rare species notifications. It is shared by the default pipeline and its profiles.
+type rarityScorer struct {
+	mu       sync.Mutex
+	date     string               // detection date the history was loaded for
+	counts   map[string]int       // detections per scientific name in the history windows
+	maxCount int                  // detections of the most detected species in the history windows
+	notified map[string]time.Time // last rare species notification per scientific name
+}
+
+// newRarityScorer creates an empty rarity scorer
+func newRarityScorer() *rarityScorer {
+	return &rarityScorer{notified: make(map[string]time.Time)}
+}
+
+// scoreRarity returns the rarity score of a species detected at detectionTime, or nil when
+// scoring is disabled or neither the range model nor the local history is available.
+// occurrence is the range model occurrence probability for the detection week.
+func (p *Processor) scoreRarity(scientificName string, detectionTime time.Time, occurrence float64) *float64 {
+	settings := &p.Settings.Realtime.Rarity
+	if !settings.Enabled || p.rarity == nil {
+		return nil
+	}
+
+	var rangeOccurrence *float64
+	if p.seasonalPriorAvailable() {
+		rangeOccurrence = &occurrence
+	}
+	count, maxCount := p.rarity.history(p.Ds, settings, scientificName, detectionTime)
+
+	score, ok := rarityScore(settings.RangeWeight, rangeOccurrence, count, maxCount)
+	if !ok {
+		return nil
+	}
+	score = math.Round(score*10000) / 10000
+	return &score
+}
+
+// rarityScore combines the range model occurrence and the local detection history into a score
+// from 0 for species that are common here this time of year to 1 for species that are not
+// expected. The local history is the detection count of the species relative to the most
+// detected species on a logarithmic scale, so regular but less vocal species are not rare.
+// When only one of the inputs is available it is used alone, without either ok is false.
+func rarityScore(rangeWeight float64, occurrence *float64, count, maxCount int) (score float64, ok bool) {
+	hasHistory := maxCount > 0
+	var history float64
+	if hasHistory {
+		history = math.Log1p(float64(count)) / math.Log1p(float64(maxCount))
+	}
+
+	var commonness float64
+	switch {
+	case occurrence != nil && hasHistory:
+		commonness = rangeWeight*clamp01(*occurrence) + (1-rangeWeight)*history
+	case occurrence != nil:
+		commonness = clamp01(*occurrence)
+	case hasHistory:
+		commonness = history
+	default:
+		return 0, false
+	}
+
+	return clamp01(1 - commonness), true
+}
+
+// clamp01 limits v to the range 0 to 1
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// history returns the number of local detections of a species and of the most detected species
+// around the day of the year of detectionTime. The history covers the same window in each of the
+// past HistoryYears years and the days before the detection in the current year. It is loaded
+// once per detection date, so detections of the current day never lower the score of each other.
+func (r *rarityScorer) history(ds datastore.Interface, settings *conf.RaritySettings, scientificName string, detectionTime time.Time) (count, maxCount int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	date := detectionTime.Format(time.DateOnly)
+	if r.date != date {
+		r.counts, r.maxCount = loadRarityHistory(ds, settings, detectionTime)
+		r.date = date
+	}
+	return r.counts[scientificName], r.maxCount
+}
+
+// loadRarityHistory counts the local detections per species in the history windows around detectionTime
+func loadRarityHistory(ds datastore.Interface, settings *conf.RaritySettings, detectionTime time.Time) (counts map[string]int, maxCount int) {
+	counts = make(map[string]int)
+	if ds == nil {
+		return counts, 0
+	}
+
+	day := time.Date(detectionTime.Year(), detectionTime.Month(), detectionTime.Day(), 0, 0, 0, 0, detectionTime.Location())
+	window := settings.WindowDays
+	for years := 0; years <= settings.HistoryYears; years++ {
+		center := day.AddDate(-years, 0, 0)
+		start := center.AddDate(0, 0, -window)
+		end := center.AddDate(0, 0, window)
+		if years == 0 {
+			// Only the days before the detection in the current year
+			end = day.AddDate(0, 0, -1)
+			if end.Before(start) {
+				continue
+			}
+		}
+
+		summaries, err := ds.GetSpeciesSummaryData(start.Format(time.DateOnly), end.Format(time.DateOnly))
+		if err != nil {
+			GetLogger().Warn("Failed to load detection history for rarity scores",
+				"start_date", start.Format(time.DateOnly),
+				"end_date", end.Format(time.DateOnly),
+				"error", err,
+				"operation", "load_rarity_history")
+			continue
+		}
+		for i := range summaries {
+			counts[summaries[i].ScientificName] += summaries[i].Count
+		}
+	}
+
+	for _, count := range counts {
+		maxCount = max(maxCount, count)
+	}
+	return counts, maxCount
+}
+
+// shouldNotify reports whether a rare species notification may be sent for a species, each
+// species is notified at most once per cooldown period
+func (r *rarityScorer) shouldNotify(scientificName string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last, ok := r.notified[scientificName]
+	return !ok || now.Sub(last) >= rarityNotificationCooldown
+}
+
+// recordNotification records that a rare species notification was sent for a species
+func (r *rarityScorer) recordNotification(scientificName string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notified[scientificName] = now
+}
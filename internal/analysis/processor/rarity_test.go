@@ -0,0 +1,123 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// summaryStoreStub implements GetSpeciesSummaryData on top of an unused datastore.Interface
+type summaryStoreStub struct {
+	datastore.Interface
+	summaries map[string][]datastore.SpeciesSummaryData // keyed by start date
+	windows   [][2]string
+}
+
+func (s *summaryStoreStub) GetSpeciesSummaryData(startDate, endDate string) ([]datastore.SpeciesSummaryData, error) {
+	s.windows = append(s.windows, [2]string{startDate, endDate})
+	return s.summaries[startDate], nil
+}
+
+func TestRarityScore(t *testing.T) {
+	t.Parallel()
+
+	occurrence := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name       string
+		occurrence *float64
+		count      int
+		maxCount   int
+		want       float64
+		wantOK     bool
+	}{
+		{"no inputs", nil, 0, 0, 0, false},
+		{"range model only", occurrence(0.2), 0, 0, 0.8, true},
+		{"history only, most detected species", nil, 50, 50, 0, true},
+		{"history only, never detected", nil, 0, 50, 1, true},
+		{"expected and common", occurrence(1), 50, 50, 0, true},
+		{"out of range and never detected", occurrence(0), 0, 50, 1, true},
+		{"weighted combination", occurrence(0.6), 0, 50, 0.7, true},
+		{"occurrence is clamped", occurrence(1.5), 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := rarityScore(0.5, tt.occurrence, tt.count, tt.maxCount)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+
+	// Fewer detections than the most detected species is rarer on a logarithmic scale
+	common, _ := rarityScore(0.5, nil, 10, 100)
+	assert.Greater(t, common, 0.0)
+	assert.Less(t, common, 0.5)
+}
+
+func TestLoadRarityHistory(t *testing.T) {
+	t.Parallel()
+
+	store := &summaryStoreStub{summaries: map[string][]datastore.SpeciesSummaryData{
+		"2024-05-12": {{ScientificName: "Turdus merula", Count: 20}, {ScientificName: "Sitta europaea", Count: 2}},
+		"2023-05-12": {{ScientificName: "Turdus merula", Count: 30}},
+	}}
+	settings := &conf.RaritySettings{Enabled: true, HistoryYears: 2, WindowDays: 3}
+
+	counts, maxCount := loadRarityHistory(store, settings, time.Date(2024, 5, 15, 8, 30, 0, 0, time.UTC))
+
+	assert.Equal(t, 50, counts["Turdus merula"])
+	assert.Equal(t, 2, counts["Sitta europaea"])
+	assert.Equal(t, 50, maxCount)
+	assert.Equal(t, [][2]string{
+		{"2024-05-12", "2024-05-14"}, // current year ends the day before the detection
+		{"2023-05-12", "2023-05-18"},
+		{"2022-05-12", "2022-05-18"},
+	}, store.windows)
+}
+
+func TestLoadRarityHistoryWithoutWindowSkipsCurrentYear(t *testing.T) {
+	t.Parallel()
+
+	store := &summaryStoreStub{}
+	settings := &conf.RaritySettings{Enabled: true, HistoryYears: 1, WindowDays: 0}
+
+	_, maxCount := loadRarityHistory(store, settings, time.Date(2024, 5, 15, 8, 30, 0, 0, time.UTC))
+
+	assert.Zero(t, maxCount)
+	assert.Equal(t, [][2]string{{"2023-05-15", "2023-05-15"}}, store.windows)
+}
+
+func TestRarityScorerHistoryCachedPerDate(t *testing.T) {
+	t.Parallel()
+
+	store := &summaryStoreStub{}
+	settings := &conf.RaritySettings{Enabled: true, HistoryYears: 1, WindowDays: 1}
+	scorer := newRarityScorer()
+
+	morning := time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC)
+	scorer.history(store, settings, "Turdus merula", morning)
+	scorer.history(store, settings, "Sitta europaea", morning.Add(6*time.Hour))
+	require.Len(t, store.windows, 2, "history should be loaded once per date")
+
+	scorer.history(store, settings, "Turdus merula", morning.AddDate(0, 0, 1))
+	assert.Len(t, store.windows, 4, "history should be reloaded for a new date")
+}
+
+func TestRarityScorerNotificationCooldown(t *testing.T) {
+	t.Parallel()
+
+	scorer := newRarityScorer()
+	now := time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC)
+
+	assert.True(t, scorer.shouldNotify("Upupa epops", now))
+	scorer.recordNotification("Upupa epops", now)
+	assert.False(t, scorer.shouldNotify("Upupa epops", now.Add(time.Hour)))
+	assert.True(t, scorer.shouldNotify("Sitta europaea", now.Add(time.Hour)))
+	assert.True(t, scorer.shouldNotify("Upupa epops", now.Add(rarityNotificationCooldown)))
+}
@@ -0,0 +1,85 @@
+// seasonal_prior.go: adjust species confidence thresholds by seasonal occurrence probability
+package processor
+
+import (
+	"math"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// seasonalPrior records the seasonal threshold adjustment applied to a detection
+type seasonalPrior struct {
+	occurrence float64 // range model occurrence probability for the detection week
+	adjustment float64 // amount added to the confidence threshold, negative when lowered
+}
+
+// applySeasonalPrior adjusts the confidence threshold of a species by its occurrence probability
+// for the week of the detection. The range filter only decides whether a species is included at
+// all; the seasonal prior raises the threshold of species that are unlikely this time of year
+// and lowers it for species at their seasonal peak. Species with a custom threshold and setups
+// without a range model are left unchanged, in which case a nil prior is returned.
+func (p *Processor) applySeasonalPrior(species, speciesLowercase string, detectionTime time.Time, baseThreshold float32) (float32, *seasonalPrior) {
+	settings := &p.Settings.Realtime.SeasonalPrior
+	if !settings.Enabled || !p.seasonalPriorAvailable() {
+		return baseThreshold, nil
+	}
+	if _, exists := p.getSpeciesConfig(speciesLowercase); exists {
+		return baseThreshold, nil
+	}
+
+	occurrence := p.Bn.GetSpeciesOccurrenceAtTime(species, detectionTime)
+	threshold, adjustment := seasonalThreshold(settings, occurrence, float64(baseThreshold))
+
+	if p.Settings.Debug {
+		GetLogger().Debug("Applied seasonal prior",
+			"species", speciesLowercase,
+			"occurrence", occurrence,
+			"base_threshold", baseThreshold,
+			"adjusted_threshold", threshold,
+			"operation", "seasonal_prior")
+	}
+
+	return float32(threshold), &seasonalPrior{occurrence: occurrence, adjustment: adjustment}
+}
+
+// seasonalPriorAvailable reports whether the range model can provide occurrence probabilities
+func (p *Processor) seasonalPriorAvailable() bool {
+	if p.Bn == nil || p.Bn.RangeInterpreter == nil {
+		return false
+	}
+	return p.Settings.BirdNET.Latitude != 0 || p.Settings.BirdNET.Longitude != 0
+}
+
+// seasonalThreshold returns the adjusted threshold and the applied adjustment for an occurrence
+// probability. The adjustment scales linearly from +strength at zero occurrence to nothing at the
+// pivot and down to -strength at full occurrence. The adjustment never moves the threshold past
+// the configured bounds, a base threshold already outside of them is not pulled back in.
+func seasonalThreshold(settings *conf.SeasonalPriorSettings, occurrence, baseThreshold float64) (threshold, adjustment float64) {
+	occurrence = math.Max(0, math.Min(1, occurrence))
+
+	switch {
+	case occurrence < settings.Pivot:
+		adjustment = settings.Strength * (settings.Pivot - occurrence) / settings.Pivot
+		threshold = math.Min(baseThreshold+adjustment, math.Max(settings.Max, baseThreshold))
+	case occurrence > settings.Pivot:
+		adjustment = -settings.Strength * (occurrence - settings.Pivot) / (1 - settings.Pivot)
+		threshold = math.Max(baseThreshold+adjustment, math.Min(settings.Min, baseThreshold))
+	default:
+		threshold = baseThreshold
+	}
+
+	return threshold, threshold - baseThreshold
+}
+
+// recordSeasonalPrior stores the seasonal adjustment on the note for auditability
+func recordSeasonalPrior(note *datastore.Note, prior *seasonalPrior) {
+	if prior == nil {
+		return
+	}
+	occurrence := prior.occurrence
+	adjustment := math.Round(prior.adjustment*10000) / 10000
+	note.SeasonalOccurrence = &occurrence
+	note.SeasonalAdjustment = &adjustment
+}
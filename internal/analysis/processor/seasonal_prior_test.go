@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestSeasonalThreshold(t *testing.T) {
+	t.Parallel()
+	settings := &conf.SeasonalPriorSettings{Enabled: true, Strength: 0.1, Pivot: 0.25, Min: 0.2, Max: 0.9}
+
+	tests := []struct {
+		name           string
+		occurrence     float64
+		base           float64
+		wantThreshold  float64
+		wantAdjustment float64
+	}{
+		{"out of season raises threshold", 0, 0.7, 0.8, 0.1},
+		{"below pivot raises proportionally", 0.125, 0.7, 0.75, 0.05},
+		{"at pivot is unchanged", 0.25, 0.7, 0.7, 0},
+		{"seasonal peak lowers threshold", 1, 0.7, 0.6, -0.1},
+		{"clamped to max", 0, 0.85, 0.9, 0.05},
+		{"clamped to min", 1, 0.25, 0.2, -0.05},
+		{"base above max is not lowered by clamping", 0, 0.95, 0.95, 0},
+		{"occurrence out of range is clamped", 1.5, 0.7, 0.6, -0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			threshold, adjustment := seasonalThreshold(settings, tt.occurrence, tt.base)
+			assert.InDelta(t, tt.wantThreshold, threshold, 0.0001)
+			assert.InDelta(t, tt.wantAdjustment, adjustment, 0.0001)
+		})
+	}
+}
+
+func TestApplySeasonalPriorUnavailable(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.Realtime.SeasonalPrior = conf.SeasonalPriorSettings{Enabled: true, Strength: 0.1, Pivot: 0.25, Min: 0.1, Max: 0.95}
+	p := &Processor{Settings: settings}
+
+	threshold, prior := p.applySeasonalPrior("Parus major_Great Tit", "great tit", time.Now(), 0.7)
+	assert.InDelta(t, 0.7, threshold, 0.0001, "threshold must be unchanged without a range model")
+	assert.Nil(t, prior)
+}
+
+func TestRecordSeasonalPrior(t *testing.T) {
+	t.Parallel()
+
+	var note datastore.Note
+	recordSeasonalPrior(&note, nil)
+	assert.Nil(t, note.SeasonalOccurrence)
+	assert.Nil(t, note.SeasonalAdjustment)
+
+	recordSeasonalPrior(&note, &seasonalPrior{occurrence: 0.05, adjustment: 0.080004})
+	require.NotNil(t, note.SeasonalOccurrence)
+	require.NotNil(t, note.SeasonalAdjustment)
+	assert.InDelta(t, 0.05, *note.SeasonalOccurrence, 0.0001)
+	assert.InDelta(t, 0.08, *note.SeasonalAdjustment, 0.00001)
+}
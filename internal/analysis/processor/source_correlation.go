@@ -0,0 +1,83 @@
+// source_correlation.go: cross-source correlation of detections heard by multiple audio sources
+package processor
+
+import (
+	"sort"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// sourceCorrelationEnabled reports whether contributing sources should be tracked
+// for pending detections
+func (p *Processor) sourceCorrelationEnabled() bool {
+	return p.Settings != nil && p.Settings.Realtime.RTSP.CorrelateSources
+}
+
+// addSourceContribution records a detection from an audio source on a pending detection.
+// Each source keeps its highest confidence and the number of matches it contributed.
+// Caller must hold pendingMutex.
+func (item *PendingDetection) addSourceContribution(sourceID, displayName string, confidence float64) {
+	if item.Sources == nil {
+		item.Sources = make(map[string]datastore.NoteSource)
+	}
+
+	contribution, exists := item.Sources[sourceID]
+	if !exists {
+		contribution = datastore.NoteSource{
+			SourceID:   sourceID,
+			SourceName: displayName,
+		}
+	}
+	if confidence > contribution.Confidence {
+		contribution.Confidence = confidence
+	}
+	contribution.Count++
+	item.Sources[sourceID] = contribution
+}
+
+// correlatedSources returns the contributing sources of a pending detection ordered
+// by confidence, with the source of the winning detection tagged as primary.
+// Returns nil when no sources were tracked.
+func (item *PendingDetection) correlatedSources() []datastore.NoteSource {
+	if len(item.Sources) == 0 {
+		return nil
+	}
+
+	sources := make([]datastore.NoteSource, 0, len(item.Sources))
+	for _, source := range item.Sources {
+		source.IsPrimary = source.SourceID == item.Source
+		sources = append(sources, source)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Confidence != sources[j].Confidence {
+			return sources[i].Confidence > sources[j].Confidence
+		}
+		return sources[i].SourceID < sources[j].SourceID
+	})
+
+	return sources
+}
+
+// saveNoteSources stores the contributing sources of a saved note when the
+// datastore supports it
+func (a *DatabaseAction) saveNoteSources() {
+	if len(a.Sources) == 0 || a.Note.ID == 0 {
+		return
+	}
+
+	sourceStore, ok := a.Ds.(datastore.NoteSourceStore)
+	if !ok {
+		return
+	}
+
+	if err := sourceStore.SaveNoteSources(a.Note.ID, a.Sources); err != nil {
+		GetLogger().Warn("Failed to save detection sources",
+			"component", "analysis.processor.actions",
+			"detection_id", a.CorrelationID,
+			"note_id", a.Note.ID,
+			"source_count", len(a.Sources),
+			"error", err,
+			"operation", "save_note_sources")
+	}
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// noteSourceRecorder implements datastore.NoteSourceStore on top of an unused datastore.Interface
+type noteSourceRecorder struct {
+	datastore.Interface
+	noteID  uint
+	sources []datastore.NoteSource
+}
+
+func (r *noteSourceRecorder) SaveNoteSources(noteID uint, sources []datastore.NoteSource) error {
+	r.noteID = noteID
+	r.sources = sources
+	return nil
+}
+
+func (r *noteSourceRecorder) GetNoteSources(noteID uint) ([]datastore.NoteSource, error) {
+	return r.sources, nil
+}
+
+func TestCorrelatedSources(t *testing.T) {
+	t.Parallel()
+
+	item := &PendingDetection{Source: "rtsp_2"}
+	assert.Nil(t, item.correlatedSources(), "no sources should be reported when none were tracked")
+
+	item.addSourceContribution("rtsp_1", "Garden", 0.72)
+	item.addSourceContribution("rtsp_2", "Pond", 0.91)
+	item.addSourceContribution("rtsp_1", "Garden", 0.65)
+	item.addSourceContribution("rtsp_3", "Roof", 0.40)
+
+	sources := item.correlatedSources()
+	require.Len(t, sources, 3)
+
+	assert.Equal(t, "rtsp_2", sources[0].SourceID)
+	assert.True(t, sources[0].IsPrimary)
+	assert.Equal(t, "rtsp_1", sources[1].SourceID)
+	assert.False(t, sources[1].IsPrimary)
+	assert.InDelta(t, 0.72, sources[1].Confidence, 0.0001, "source should keep its highest confidence")
+	assert.Equal(t, 2, sources[1].Count)
+	assert.Equal(t, "Garden", sources[1].SourceName)
+	assert.Equal(t, "rtsp_3", sources[2].SourceID)
+}
+
+func TestSourceCorrelationEnabled(t *testing.T) {
+	t.Parallel()
+
+	p := &Processor{Settings: &conf.Settings{}}
+	assert.False(t, p.sourceCorrelationEnabled())
+
+	p.Settings.Realtime.RTSP.CorrelateSources = true
+	assert.True(t, p.sourceCorrelationEnabled())
+}
+
+func TestDatabaseActionSaveNoteSources(t *testing.T) {
+	t.Parallel()
+
+	recorder := &noteSourceRecorder{}
+	action := &DatabaseAction{
+		Ds:   recorder,
+		Note: datastore.Note{ID: 7},
+		Sources: []datastore.NoteSource{
+			{SourceID: "rtsp_1", Confidence: 0.9, Count: 2, IsPrimary: true},
+			{SourceID: "rtsp_2", Confidence: 0.6, Count: 1},
+		},
+	}
+
+	action.saveNoteSources()
+	assert.Equal(t, uint(7), recorder.noteID)
+	assert.Len(t, recorder.sources, 2)
+
+	// Nothing is stored for unsaved notes
+	recorder = &noteSourceRecorder{}
+	action.Ds = recorder
+	action.Note.ID = 0
+	action.saveNoteSources()
+	assert.Nil(t, recorder.sources)
+}
@@ -0,0 +1,103 @@
+// source_overrides.go: per audio source confidence threshold, species and privacy filter overrides
+package processor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// getSourceOverride returns the configured override for an audio source, or nil if none applies.
+// Overrides are matched against the source ID, display name or connection string; RTSP URLs
+// are compared in sanitized form so credentials in the configuration do not need to match.
+// Processors running a profile fall back to the profile's species lists.
+func (p *Processor) getSourceOverride(source *datastore.AudioSource) *conf.SourceOverride {
+	if source != nil {
+		overrides := p.Settings.Realtime.SourceOverrides
+		for i := range overrides {
+			if sourceMatches(overrides[i].Source, source) {
+				return &overrides[i]
+			}
+		}
+	}
+
+	return p.profileOverride
+}
+
+// sourceMatches reports whether a configured source reference identifies the audio source
+func sourceMatches(match string, source *datastore.AudioSource) bool {
+	match = strings.TrimSpace(match)
+	if match == "" || source == nil {
+		return false
+	}
+	return match == source.ID ||
+		strings.EqualFold(match, source.DisplayName) ||
+		(source.SafeString != "" && privacy.SanitizeRTSPUrl(match) == source.SafeString)
+}
+
+// applySourceThreshold returns the confidence threshold for a species heard by a source
+// with an override. Per-species custom thresholds take precedence over the source threshold;
+// species configured only for actions or intervals get the source threshold.
+func (p *Processor) applySourceThreshold(override *conf.SourceOverride, speciesLowercase string, baseThreshold float32) float32 {
+	if override == nil || override.Threshold <= 0 {
+		return baseThreshold
+	}
+	if config, exists := p.getSpeciesConfig(speciesLowercase); exists && config.Threshold > 0 {
+		return baseThreshold
+	}
+	return float32(override.Threshold)
+}
+
+// sourceFilter is the privacy or dog bark filter configuration effective for an audio source
+type sourceFilter struct {
+	enabled    bool
+	confidence float32
+	window     time.Duration // how long detections are discarded after a human voice or dog bark
+}
+
+// privacyFilter returns the privacy filter settings for a source with an optional override
+func (p *Processor) privacyFilter(override *conf.SourceOverride) sourceFilter {
+	global := p.Settings.Realtime.PrivacyFilter
+	filter := sourceFilter{
+		enabled:    global.Enabled,
+		confidence: global.Confidence,
+		window:     time.Duration(global.Window) * time.Second,
+	}
+	if override != nil {
+		filter.apply(override.PrivacyFilter)
+	}
+	return filter
+}
+
+// dogBarkFilter returns the dog bark filter settings for a source with an optional override
+func (p *Processor) dogBarkFilter(override *conf.SourceOverride) sourceFilter {
+	global := p.Settings.Realtime.DogBarkFilter
+	filter := sourceFilter{
+		enabled:    global.Enabled,
+		confidence: global.Confidence,
+		window:     time.Duration(global.Remember) * time.Minute,
+	}
+	if override != nil {
+		filter.apply(override.DogBarkFilter)
+	}
+	return filter
+}
+
+// apply replaces the filter settings set in a source filter override
+func (f *sourceFilter) apply(override *conf.SourceFilterOverride) {
+	if override == nil {
+		return
+	}
+	if override.Enabled != nil {
+		f.enabled = *override.Enabled
+	}
+	if override.Confidence > 0 {
+		f.confidence = override.Confidence
+	}
+	if override.WindowSeconds > 0 {
+		f.window = time.Duration(override.WindowSeconds) * time.Second
+	}
+}
@@ -0,0 +1,91 @@
+// species_actions.go: conditions and notifications of custom species actions
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// NotificationAction creates a detection notification for a SendNotification species action
+type NotificationAction struct {
+	Note          datastore.Note
+	CorrelationID string
+}
+
+// GetDescription returns a description of the action
+func (a *NotificationAction) GetDescription() string {
+	return fmt.Sprintf("Send notification for %s", a.Note.CommonName)
+}
+
+// Execute creates the detection notification
+func (a *NotificationAction) Execute(_ any) error {
+	metadata := map[string]any{
+		"scientific_name": a.Note.ScientificName,
+		"date":            a.Note.Date,
+		"time":            a.Note.Time,
+		"correlation_id":  a.CorrelationID,
+	}
+	if a.Note.Source.DisplayName != "" {
+		metadata["source"] = a.Note.Source.DisplayName
+	}
+	if a.Note.RarityScore != nil {
+		metadata["rarity_score"] = *a.Note.RarityScore
+	}
+	notification.NotifyDetection(a.Note.CommonName, a.Note.Confidence, metadata)
+	return nil
+}
+
+// actionConditionMet reports whether a detection meets every set field of the condition of
+// a species action. Unscored detections don't meet a rarity condition.
+func (p *Processor) actionConditionMet(condition *conf.ActionCondition, note *datastore.Note) bool {
+	if condition.MinRarity > 0 && (note.RarityScore == nil || *note.RarityScore < condition.MinRarity) {
+		return false
+	}
+
+	if condition.Period != "" {
+		detectionTime := note.BeginTime
+		if detectionTime.IsZero() {
+			detectionTime = time.Now()
+		}
+		night, err := p.isNight(detectionTime)
+		if err != nil {
+			GetLogger().Warn("Failed to determine detection period, skipping species action",
+				"species", note.CommonName,
+				"error", err,
+				"operation", "species_action_condition")
+			return false
+		}
+		if night != (condition.Period == conf.ActionPeriodNight) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isNight reports whether t is between sunset and sunrise at the station
+func (p *Processor) isNight(t time.Time) (bool, error) {
+	if p.sunCalc == nil {
+		return false, fmt.Errorf("sun event calculator not initialized")
+	}
+	times, err := p.sunCalc.GetSunEventTimes(t)
+	if err != nil {
+		return false, err
+	}
+	return t.Before(times.Sunrise) || !t.Before(times.Sunset), nil
+}
+
+// withoutBirdWeather returns the actions without the BirdWeather upload
+func withoutBirdWeather(actions []Action) []Action {
+	filtered := actions[:0:0]
+	for _, action := range actions {
+		if _, ok := action.(*BirdWeatherAction); !ok {
+			filtered = append(filtered, action)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+func TestActionConditionMet(t *testing.T) {
+	t.Parallel()
+
+	p := &Processor{sunCalc: suncalc.NewSunCalc(60.17, 24.94)}
+	times, err := p.sunCalc.GetSunEventTimes(time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	day := times.Sunrise.Add(time.Hour)
+	night := times.Sunrise.Add(-time.Hour)
+	rarity := 0.8
+
+	tests := []struct {
+		name      string
+		condition conf.ActionCondition
+		note      datastore.Note
+		want      bool
+	}{
+		{"no condition", conf.ActionCondition{}, datastore.Note{}, true},
+		{"night at night", conf.ActionCondition{Period: conf.ActionPeriodNight}, datastore.Note{BeginTime: night}, true},
+		{"night during the day", conf.ActionCondition{Period: conf.ActionPeriodNight}, datastore.Note{BeginTime: day}, false},
+		{"day during the day", conf.ActionCondition{Period: conf.ActionPeriodDay}, datastore.Note{BeginTime: day}, true},
+		{"rare enough", conf.ActionCondition{MinRarity: 0.7}, datastore.Note{RarityScore: &rarity}, true},
+		{"too common", conf.ActionCondition{MinRarity: 0.9}, datastore.Note{RarityScore: &rarity}, false},
+		{"unscored", conf.ActionCondition{MinRarity: 0.1}, datastore.Note{}, false},
+		{"all fields", conf.ActionCondition{Period: conf.ActionPeriodNight, MinRarity: 0.7}, datastore.Note{BeginTime: day, RarityScore: &rarity}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, p.actionConditionMet(&tt.condition, &tt.note))
+		})
+	}
+
+	_, err = (&Processor{}).isNight(day)
+	assert.Error(t, err, "without a sun calculator the period is unknown")
+}
+
+func TestGetActionsForItemRecipes(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.BirdNET.RangeFilter.LastUpdated = time.Now() // no range filter update among the default actions
+	settings.Realtime.CommandGuard = conf.CommandGuardSettings{Enabled: true, MaxPerHour: 60, MaxConcurrent: 2}
+	settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{
+		"great horned owl": {Recipes: []conf.SpeciesRecipe{
+			{Name: "notify-at-night"},
+			{Name: "trigger-camera", Command: "/usr/local/bin/camera.sh"},
+		}},
+	}
+	p := &Processor{Settings: settings, sunCalc: suncalc.NewSunCalc(60.17, 24.94), cmdGuard: newCommandGuard()}
+	times, err := p.sunCalc.GetSunEventTimes(time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+
+	detection := &Detections{Note: datastore.Note{CommonName: "Great Horned Owl", BeginTime: times.Sunset.Add(time.Hour)}}
+	actions := p.getActionsForItem(detection)
+	require.Len(t, actions, 2)
+	assert.IsType(t, &NotificationAction{}, actions[0])
+	command, ok := actions[1].(*ExecuteCommandAction)
+	require.True(t, ok)
+	assert.Equal(t, "/usr/local/bin/camera.sh", command.Command)
+	assert.Equal(t, 1, command.limits.maxConcurrent, "the recipe runs one camera command at a time")
+
+	detection.Note.BeginTime = times.Sunset.Add(-time.Hour)
+	actions = p.getActionsForItem(detection)
+	require.Len(t, actions, 1, "the night notification is skipped during the day")
+	assert.IsType(t, &ExecuteCommandAction{}, actions[0])
+}
+
+func TestWithoutBirdWeather(t *testing.T) {
+	t.Parallel()
+
+	actions := []Action{&LogAction{}, &BirdWeatherAction{}, &NotificationAction{}}
+	filtered := withoutBirdWeather(actions)
+	assert.Equal(t, []Action{&LogAction{}, &NotificationAction{}}, filtered)
+	assert.Len(t, actions, 3, "the actions are not modified")
+}
@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestNoteTimestamps(t *testing.T) {
+	t.Parallel()
+
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	// A segment analyzed late, e.g. after a backlog or from a recording
+	begin := time.Date(2025, 5, 10, 23, 59, 58, 0, time.UTC)
+
+	newNote := func(timestamps, timeZone string, begin time.Time) (date, clock string) {
+		settings := &conf.Settings{}
+		settings.Main.Timestamps = timestamps
+		settings.Main.TimeZone = timeZone
+		p := &Processor{Settings: settings}
+		note := p.NewWithSpeciesInfo(begin, begin.Add(3*time.Second), "Turdus merula", "Eurasian Blackbird", "eurbla",
+			0.9, "test", "", 0, 0)
+		return note.Date, note.Time
+	}
+
+	// Segment timestamps are the begin time in the configured time zone
+	date, clock := newNote(conf.TimestampsSegment, "Europe/Helsinki", begin)
+	assert.Equal(t, "2025-05-11", date)
+	assert.Equal(t, "02:59:58", clock)
+
+	// Empty selects segment timestamps in the system time zone
+	date, clock = newNote("", "", begin)
+	assert.Equal(t, begin.Local().Format(time.DateOnly), date)
+	assert.Equal(t, begin.Local().Format(time.TimeOnly), clock)
+
+	// Clock timestamps and file offsets use the time of the detection
+	for _, tc := range []struct {
+		timestamps string
+		begin      time.Time
+	}{
+		{conf.TimestampsClock, begin},
+		{conf.TimestampsSegment, time.Time{}.Add(90 * time.Second)},
+	} {
+		before := time.Now().In(helsinki).Add(-3 * time.Second)
+		date, clock = newNote(tc.timestamps, "Europe/Helsinki", tc.begin)
+		stamped, err := time.ParseInLocation(time.DateTime, date+" "+clock, helsinki)
+		assert.NoError(t, err)
+		assert.WithinRange(t, stamped, before.Truncate(time.Second), time.Now().In(helsinki), tc.timestamps)
+	}
+}
@@ -0,0 +1,61 @@
+// upload_quota.go: daily upload limits per species for BirdWeather
+package processor
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// uploadQuota counts the uploads of each species on the current local day. It is shared
+// by the default pipeline and its profiles.
+type uploadQuota struct {
+	mu     sync.Mutex
+	date   string         // local date the counters belong to
+	counts map[string]int // uploads per lowercase scientific name on date
+}
+
+// newUploadQuota creates an upload quota with empty counters
+func newUploadQuota() *uploadQuota {
+	return &uploadQuota{counts: make(map[string]int)}
+}
+
+// speciesLimit returns the daily upload limit of a species, 0 for no limit. A limit of the
+// common name takes precedence over one of the scientific name.
+func speciesLimit(settings *conf.UploadQuotaSettings, commonName, scientificName string) int {
+	for _, name := range []string{commonName, scientificName} {
+		if limit, ok := settings.Species[strings.ToLower(name)]; ok {
+			return limit
+		}
+	}
+	return settings.DefaultLimit
+}
+
+// allow reports whether an upload of the species at now is within its daily limit and
+// counts it when it is. Counters reset when the local date changes.
+func (q *uploadQuota) allow(settings *conf.UploadQuotaSettings, commonName, scientificName string, now time.Time) bool {
+	if !settings.Enabled {
+		return true
+	}
+	limit := speciesLimit(settings, commonName, scientificName)
+	if limit == 0 {
+		return true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if date := now.Format(time.DateOnly); date != q.date {
+		q.date = date
+		clear(q.counts)
+	}
+
+	key := strings.ToLower(scientificName)
+	if q.counts[key] >= limit {
+		return false
+	}
+	q.counts[key]++
+	return true
+}
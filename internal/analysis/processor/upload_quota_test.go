@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestUploadQuotaAllow(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.UploadQuotaSettings{
+		Enabled:      true,
+		DefaultLimit: 3,
+		Species:      map[string]int{"house sparrow": 2, "turdus merula": 0},
+	}
+	quota := newUploadQuota()
+	morning := time.Date(2026, 5, 10, 6, 0, 0, 0, time.Local)
+
+	assert.True(t, quota.allow(settings, "House Sparrow", "Passer domesticus", morning))
+	assert.True(t, quota.allow(settings, "House Sparrow", "Passer domesticus", morning.Add(time.Hour)))
+	assert.False(t, quota.allow(settings, "House Sparrow", "Passer domesticus", morning.Add(2*time.Hour)), "species limit reached")
+
+	for i := range 3 {
+		assert.True(t, quota.allow(settings, "Great Tit", "Parus major", morning), "upload %d within default limit", i+1)
+	}
+	assert.False(t, quota.allow(settings, "Great Tit", "Parus major", morning), "default limit reached")
+
+	for range 10 {
+		assert.True(t, quota.allow(settings, "Eurasian Blackbird", "Turdus merula", morning), "zero limit is unlimited")
+	}
+
+	nextDay := time.Date(2026, 5, 11, 0, 0, 1, 0, time.Local)
+	assert.True(t, quota.allow(settings, "House Sparrow", "Passer domesticus", nextDay), "counters reset at local midnight")
+}
+
+func TestUploadQuotaDisabled(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.UploadQuotaSettings{Enabled: false, DefaultLimit: 1}
+	quota := newUploadQuota()
+	now := time.Now()
+
+	assert.True(t, quota.allow(settings, "House Sparrow", "Passer domesticus", now))
+	assert.True(t, quota.allow(settings, "House Sparrow", "Passer domesticus", now))
+}
+
+func TestBirdWeatherActionCountsQuotaOnce(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Birdweather.Enabled = true
+	settings.Realtime.Birdweather.UploadQuota = conf.UploadQuotaSettings{Enabled: true, DefaultLimit: 1}
+	quota := newUploadQuota()
+
+	newAction := func() *BirdWeatherAction {
+		action := &BirdWeatherAction{Settings: settings, EventTracker: NewEventTracker(0), quota: quota}
+		action.Note.CommonName = "House Sparrow"
+		action.Note.ScientificName = "Passer domesticus"
+		action.Note.Confidence = 0.9
+		return action
+	}
+
+	// Without a client the upload fails, a retry of the same action is not counted again
+	first := newAction()
+	assert.Error(t, first.Execute(nil))
+	assert.Error(t, first.Execute(nil))
+
+	// A second detection is over the quota and skipped before the upload
+	assert.NoError(t, newAction().Execute(nil))
+}
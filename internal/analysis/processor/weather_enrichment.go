@@ -0,0 +1,71 @@
+// weather_enrichment.go: attach the nearest weather reading to detections before saving
+package processor
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// minWeatherWindow is the shortest window searched for a weather reading around a detection
+const minWeatherWindow = time.Hour
+
+// weatherEnabled reports whether a weather provider is configured
+func weatherEnabled(settings *conf.Settings) bool {
+	provider := settings.Realtime.Weather.Provider
+	return provider != "" && provider != "none"
+}
+
+// weatherWindow returns how far from a detection a weather reading may be to be attached.
+// Readings are recorded once per poll interval, so allow up to two intervals.
+func weatherWindow(settings *conf.Settings) time.Duration {
+	window := 2 * time.Duration(settings.Realtime.Weather.PollInterval) * time.Minute
+	if window < minWeatherWindow {
+		return minWeatherWindow
+	}
+	return window
+}
+
+// attachWeather copies the weather reading nearest to the detection time onto the note.
+// The note is saved without weather data if no recent reading is available.
+func (a *DatabaseAction) attachWeather() {
+	if a.Settings == nil || !weatherEnabled(a.Settings) {
+		return
+	}
+	weatherStore, ok := a.Ds.(datastore.WeatherDetectionStore)
+	if !ok {
+		return
+	}
+
+	detectionTime := a.Note.BeginTime
+	if detectionTime.IsZero() {
+		detectionTime = time.Now()
+	}
+
+	reading, err := weatherStore.NearestHourlyWeather(detectionTime, weatherWindow(a.Settings))
+	if err != nil {
+		GetLogger().Warn("Failed to look up weather for detection",
+			"detection_id", a.CorrelationID,
+			"error", err,
+			"operation", "attach_weather")
+		return
+	}
+	if reading == nil {
+		return
+	}
+
+	applyWeather(&a.Note, reading)
+}
+
+// applyWeather sets the weather columns of a note from a weather reading
+func applyWeather(note *datastore.Note, reading *datastore.HourlyWeather) {
+	temperature := reading.Temperature
+	windSpeed := reading.WindSpeed
+	precipitation := reading.Precipitation
+
+	note.WeatherTemperature = &temperature
+	note.WeatherWindSpeed = &windSpeed
+	note.WeatherPrecipitation = &precipitation
+	note.WeatherIcon = reading.WeatherIcon
+}
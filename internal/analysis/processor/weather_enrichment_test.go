@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// weatherStoreStub implements datastore.WeatherDetectionStore on top of an unused datastore.Interface
+type weatherStoreStub struct {
+	datastore.Interface
+	reading *datastore.HourlyWeather
+	at      time.Time
+	window  time.Duration
+}
+
+func (s *weatherStoreStub) NearestHourlyWeather(t time.Time, window time.Duration) (*datastore.HourlyWeather, error) {
+	s.at = t
+	s.window = window
+	return s.reading, nil
+}
+
+func (s *weatherStoreStub) GetDetectionsByWeather(startDate, endDate string) (*datastore.WeatherDetectionSummary, error) {
+	return &datastore.WeatherDetectionSummary{}, nil
+}
+
+func TestAttachWeather(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Weather.Provider = "openmeteo"
+	settings.Realtime.Weather.PollInterval = 60
+
+	begin := time.Date(2024, 6, 1, 12, 10, 0, 0, time.UTC)
+	store := &weatherStoreStub{reading: &datastore.HourlyWeather{
+		Temperature:   14.2,
+		WindSpeed:     3.4,
+		Precipitation: 0.6,
+		WeatherIcon:   "10",
+	}}
+	action := &DatabaseAction{Settings: settings, Ds: store, Note: datastore.Note{BeginTime: begin}}
+
+	action.attachWeather()
+	assert.Equal(t, begin, store.at)
+	assert.Equal(t, 2*time.Hour, store.window)
+	require.NotNil(t, action.Note.WeatherTemperature)
+	assert.InDelta(t, 14.2, *action.Note.WeatherTemperature, 0.0001)
+	assert.InDelta(t, 3.4, *action.Note.WeatherWindSpeed, 0.0001)
+	assert.InDelta(t, 0.6, *action.Note.WeatherPrecipitation, 0.0001)
+	assert.Equal(t, "10", action.Note.WeatherIcon)
+
+	// Nothing is attached without a recent reading or when weather is disabled
+	store.reading = nil
+	action.Note = datastore.Note{BeginTime: begin}
+	action.attachWeather()
+	assert.Nil(t, action.Note.WeatherTemperature)
+
+	settings.Realtime.Weather.Provider = "none"
+	store.reading = &datastore.HourlyWeather{Temperature: 10}
+	action.attachWeather()
+	assert.Nil(t, action.Note.WeatherTemperature)
+}
+
+func TestWeatherWindow(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Weather.PollInterval = 15
+	assert.Equal(t, time.Hour, weatherWindow(settings), "window should not be shorter than an hour")
+
+	settings.Realtime.Weather.PollInterval = 90
+	assert.Equal(t, 3*time.Hour, weatherWindow(settings))
+}
@@ -18,11 +18,14 @@ import (
 	"github.com/tphakala/birdnet-go/internal/analysis/processor"
 	"github.com/tphakala/birdnet-go/internal/audiocore/adapter"
 	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/sources"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/diskmanager"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/eventsink"
 	"github.com/tphakala/birdnet-go/internal/httpcontroller"
 	"github.com/tphakala/birdnet-go/internal/httpcontroller/handlers"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
@@ -34,12 +37,19 @@ import (
 	"github.com/tphakala/birdnet-go/internal/privacy"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
 	"github.com/tphakala/birdnet-go/internal/weather"
+	"github.com/tphakala/birdnet-go/internal/webpush"
 )
 
 // Constants for system operations
 const (
 	// shutdownTimeout is the maximum time allowed for graceful shutdown (9s for Docker's 10s default)
 	shutdownTimeout = 9 * time.Second
+
+	// trashPurgeInterval is how often detections past the trash retention are purged
+	trashPurgeInterval = time.Hour
+
+	// eventSinkShutdownTimeout bounds publishing the queued events to the message broker at shutdown
+	eventSinkShutdownTimeout = 2 * time.Second
 )
 
 // audioLevelChan is a channel to send audio level updates
@@ -126,6 +136,9 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 	// Print system details and configuration
 	printSystemDetails(settings)
 
+	// Detect FFmpeg capabilities so exports pick compatible arguments
+	detectFFmpegCapabilities(settings)
+
 	// Initialize database access.
 	dataStore := datastore.New(settings)
 
@@ -226,6 +239,25 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 			Build()
 	}
 
+	// Forward events to an external message broker if configured
+	if err := eventsink.Initialize(settings); err != nil {
+		GetLogger().Error("Failed to start event forwarding",
+			"error", err,
+			"operation", "initialize_event_sink")
+		log.Printf("Warning: Failed to start event forwarding: %v", err)
+	}
+
+	// Push detection notifications to subscribed browsers if enabled
+	if settings.WebPush.Enabled {
+		store, _ := dataStore.(datastore.WebPushStore)
+		if err := webpush.Configure(settings, store); err != nil {
+			GetLogger().Error("Failed to start web push notifications",
+				"error", err,
+				"operation", "initialize_web_push")
+			log.Printf("Warning: Failed to start web push notifications: %v", err)
+		}
+	}
+
 	// Initialize system monitor if monitoring is enabled
 	systemMonitor := initializeSystemMonitor(settings)
 
@@ -236,6 +268,14 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 	// Initialize the wait group to wait for all goroutines to finish
 	var wg sync.WaitGroup
 
+	// Record this run so gaps in detection data can be explained later
+	runTracker := startRunTracking(&wg, settings, dataStore, quitChan)
+
+	// Confirm a freshly installed self-update is healthy, rolling back if it is not
+	if settings.Update.Enabled {
+		startUpdateVerification(&wg, settings, quitChan)
+	}
+
 	// Initialize the buffer manager
 	bufferManager := MustNewBufferManager(bn, quitChan, &wg)
 
@@ -287,11 +327,34 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 		startClipCleanupMonitor(&wg, quitChan, dataStore)
 	}
 
+	// start purging detections past the trash retention
+	startTrashPurgeMonitor(&wg, quitChan, dataStore)
+
 	// start weather polling
 	if settings.Realtime.Weather.Provider != "none" {
 		startWeatherPolling(&wg, settings, dataStore, metrics, quitChan)
 	}
 
+	// start morning brief notifications
+	if settings.Realtime.MorningBrief.Enabled {
+		startMorningBrief(&wg, settings, dataStore, quitChan)
+	}
+
+	// start email digest reports
+	if settings.Email.Enabled {
+		startEmailDigest(&wg, settings, dataStore, proc.NewSpeciesTracker, quitChan)
+	}
+
+	// pause analysis outside the sunrise/sunset based window
+	if settings.Realtime.Schedule.Enabled {
+		startAnalysisSchedule(&wg, settings, quitChan)
+	}
+
+	// prefetch images of species added to the range filter
+	if birdImageCache != nil && settings.Realtime.Dashboard.Thumbnails.Prefetch.Enabled {
+		startImagePrefetch(&wg, settings, birdImageCache, quitChan)
+	}
+
 	// Telemetry endpoint initialization is now handled by control monitor for hot reload support.
 	// Unlike other services that start directly here, telemetry is managed by the control monitor
 	// to allow users to dynamically enable/disable metrics and change the listen address without
@@ -304,6 +367,9 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 	// start shutdown signal monitor
 	monitorShutdownSignals(quitChan)
 
+	// tell systemd the service is ready and keep its watchdog fed while the pipeline makes progress
+	startSystemdNotify(&wg, settings, quitChan)
+
 	// Track the HTTP server, system monitor and control monitor for clean shutdown
 	httpServerRef := httpServer
 	systemMonitorRef := systemMonitor
@@ -318,6 +384,12 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 				"shutdown_timeout_seconds", shutdownTimeout.Seconds(),
 				"operation", "graceful_shutdown")
 			log.Println("🛑 Initiating graceful shutdown sequence...")
+
+			// Finish held detections and queued actions before tearing anything down
+			if settings.Realtime.Drain.Enabled {
+				drainBeforeShutdown(proc, time.Duration(settings.Realtime.Drain.Timeout)*time.Second)
+			}
+
 			shutdownStart := time.Now()
 
 			// Create context with timeout for the entire shutdown process
@@ -474,6 +546,7 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 				}
 
 				// Step 8: Stop notification service
+				webpush.Shutdown()
 				if notification.IsInitialized() {
 					// Add structured logging
 					GetLogger().Info("Shutdown step 8: Stopping notification service",
@@ -485,6 +558,13 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 					}
 				}
 
+				// Publish the events still queued for the message broker
+				if err := eventsink.Shutdown(eventSinkShutdownTimeout); err != nil {
+					GetLogger().Warn("Events left unpublished at shutdown",
+						"error", err,
+						"operation", "shutdown_event_sink")
+				}
+
 				if ctx.Err() != nil {
 					// Add structured logging
 					GetLogger().Warn("Shutdown context cancelled after step 8",
@@ -508,12 +588,18 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 					"duration_ms", time.Since(shutdownStart).Milliseconds(),
 					"operation", "shutdown_complete")
 				log.Printf("✅ Graceful shutdown completed in %v", time.Since(shutdownStart))
+
+				// Close the service log files once all services have stopped
+				if err := logging.CloseAll(); err != nil {
+					log.Printf("⚠️ Failed to close log files: %v", err)
+				}
 			}()
 
 			// Wait for shutdown to complete or context timeout
 			select {
 			case <-shutdownComplete:
 				// Shutdown completed successfully
+				runTracker.stop(datastore.RunStopUserRequest, shutdownDetail())
 				cancel()
 				return nil
 			case <-ctx.Done():
@@ -522,6 +608,7 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 					"timeout_seconds", shutdownTimeout.Seconds(),
 					"operation", "shutdown_forced_exit")
 				log.Printf("⚠️ Shutdown timeout exceeded (%v), forcing exit", shutdownTimeout)
+				runTracker.stop(datastore.RunStopUserRequest, strings.TrimSpace(shutdownDetail()+" (shutdown timeout exceeded)"))
 				cancel()
 				return nil
 			}
@@ -618,6 +705,63 @@ func startClipCleanupMonitor(wg *sync.WaitGroup, quitChan chan struct{}, dataSto
 	}()
 }
 
+// startTrashPurgeMonitor purges deleted detections kept in the trash past the retention
+// window, at startup and then every trashPurgeInterval.
+func startTrashPurgeMonitor(wg *sync.WaitGroup, quitChan chan struct{}, dataStore datastore.Interface) {
+	store, ok := dataStore.(datastore.TrashStore)
+	if !ok {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purgeTrash(store)
+			select {
+			case <-quitChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeTrash permanently deletes the detections trashed before the retention window and
+// removes their clips
+func purgeTrash(store datastore.TrashStore) {
+	settings := conf.Setting()
+	purged, err := store.PurgeTrash(time.Now().AddDate(0, 0, -settings.Trash.RetentionDays))
+	if err != nil {
+		GetLogger().Error("Failed to purge trash",
+			"error", err,
+			"operation", "trash_purge")
+	}
+	if len(purged) == 0 {
+		return
+	}
+
+	var clipPaths []string
+	for i := range purged {
+		if purged[i].ClipName != "" {
+			clipPaths = append(clipPaths, filepath.Join(settings.Realtime.Audio.Export.Path, filepath.FromSlash(purged[i].ClipName)))
+		}
+	}
+	result := diskmanager.DeleteTrashedClips(clipPaths, settings.Realtime.Audio.Export.Retention.Debug)
+	if result.Err != nil {
+		GetLogger().Warn("Failed to remove clips of purged detections",
+			"error", result.Err,
+			"operation", "trash_purge")
+	}
+	GetLogger().Info("Purged detections from trash",
+		"detections_purged", len(purged),
+		"clips_removed", result.ClipsRemoved,
+		"retention_days", settings.Trash.RetentionDays,
+		"operation", "trash_purge")
+}
+
 // startWeatherPolling initializes and starts the weather polling routine in a new goroutine.
 func startWeatherPolling(wg *sync.WaitGroup, settings *conf.Settings, dataStore datastore.Interface, metrics *observability.Metrics, quitChan chan struct{}) {
 	// Create new weather service
@@ -657,6 +801,29 @@ func startTelemetryEndpoint(wg *sync.WaitGroup, settings *conf.Settings, metrics
 	}
 }
 
+// drainBeforeShutdown stops detection intake and waits up to timeout for held detections
+// and queued actions to finish, so saves and uploads in flight are not lost on shutdown.
+func drainBeforeShutdown(proc *processor.Processor, timeout time.Duration) {
+	GetLogger().Info("Draining queued actions before shutdown",
+		"timeout_seconds", timeout.Seconds(),
+		"outstanding", proc.OutstandingWork(),
+		"operation", "shutdown_drain")
+	log.Printf("⏳ Draining queued actions before shutdown (timeout %v)...", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := proc.Drain(ctx); err != nil {
+		GetLogger().Warn("Drain timed out, continuing shutdown",
+			"outstanding", proc.OutstandingWork(),
+			"error", err,
+			"operation", "shutdown_drain")
+		log.Printf("⚠️ Drain timed out with %d queued actions remaining", proc.OutstandingWork())
+		return
+	}
+	log.Println("✅ Queued actions finished")
+}
+
 // monitorShutdownSignals listens for shutdown signals (SIGINT, SIGTERM) and triggers the application shutdown process.
 func monitorShutdownSignals(quitChan chan struct{}) {
 	go func() {
@@ -666,6 +833,7 @@ func monitorShutdownSignals(quitChan chan struct{}) {
 		defer signal.Stop(sigChan) // Stop signal delivery when done to prevent leaks
 
 		sig := <-sigChan // Block until a signal is received
+		shutdownSignal.Store(sig.String())
 
 		// Add structured logging
 		GetLogger().Info("Received shutdown signal",
@@ -980,9 +1148,63 @@ func setupImageProviderRegistry(ds datastore.Interface, metrics *observability.M
 		log.Println("Using existing AviCommons image provider")
 	}
 
+	thumbnails := conf.Setting().Realtime.Dashboard.Thumbnails
+
+	// Register the folder of custom photos if configured
+	if _, ok := registry.GetCache("local"); !ok && thumbnails.LocalPath != "" {
+		if err := imageprovider.RegisterLocalProvider(registry, thumbnails.LocalPath, metrics, ds); err != nil {
+			GetLogger().Error("Failed to register local image provider",
+				"error", err,
+				"provider", "local",
+				"operation", "register_image_provider")
+			log.Printf("Failed to register local image provider: %v", err)
+			errs = append(errs, errors.New(err).
+				Component("realtime-analysis").
+				Category(errors.CategoryImageProvider).
+				Context("operation", "register_local_provider").
+				Context("provider", "local").
+				Build())
+		}
+	}
+
+	// Register the Macaulay Library if enabled
+	if _, ok := registry.GetCache("macaulay"); !ok && thumbnails.Macaulay {
+		if err := imageprovider.RegisterMacaulayProvider(registry, metrics, ds); err != nil {
+			GetLogger().Error("Failed to register Macaulay Library image provider",
+				"error", err,
+				"provider", "macaulay",
+				"operation", "register_image_provider")
+			log.Printf("Failed to register Macaulay Library image provider: %v", err)
+			errs = append(errs, errors.New(err).
+				Component("realtime-analysis").
+				Category(errors.CategoryImageProvider).
+				Context("operation", "register_macaulay_provider").
+				Context("provider", "macaulay").
+				Build())
+		}
+	}
+
+	// Keep copies of the images on disk if enabled
+	var diskCache *imageprovider.DiskCache
+	if thumbnails.DiskCache.Enabled {
+		var err error
+		diskCache, err = imageprovider.NewDiskCache(thumbnails.DiskCache.Path)
+		if err != nil {
+			GetLogger().Error("Failed to create image disk cache",
+				"error", err,
+				"path", thumbnails.DiskCache.Path,
+				"operation", "setup_image_registry")
+			log.Printf("Failed to create image disk cache: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
 	// Set the registry in each provider for fallback support
 	registry.RangeProviders(func(name string, cache *imageprovider.BirdImageCache) bool {
 		cache.SetRegistry(registry)
+		if diskCache != nil {
+			cache.SetDiskCache(diskCache)
+		}
 		return true // Continue ranging
 	})
 
@@ -1256,10 +1478,33 @@ func initBirdImageCache(ds datastore.Interface, metrics *observability.Metrics)
 	return defaultCache
 }
 
+// imagePrefetchDelay is the pause after each species image fetched by the prefetcher
+const imagePrefetchDelay = 2 * time.Second
+
+// startImagePrefetch fetches the images of all included species in the background and of
+// species added by later range filter updates
+func startImagePrefetch(wg *sync.WaitGroup, settings *conf.Settings, cache *imageprovider.BirdImageCache, quitChan chan struct{}) {
+	prefetcher := imageprovider.NewPrefetcher(cache, settings.Realtime.Dashboard.Thumbnails.Prefetch.BandwidthLimit, imagePrefetchDelay)
+	removeListener := conf.OnIncludedSpeciesUpdate(prefetcher.Schedule)
+	prefetcher.Schedule(settings.GetIncludedSpecies())
+
+	GetLogger().Info("Species image prefetch started",
+		"bandwidth_limit_kib", settings.Realtime.Dashboard.Thumbnails.Prefetch.BandwidthLimit,
+		"operation", "start_image_prefetch")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer removeListener()
+		prefetcher.Run(quitChan)
+	}()
+}
+
 // startControlMonitor handles various control signals for realtime analysis mode
 func startControlMonitor(wg *sync.WaitGroup, controlChan chan string, quitChan, restartChan chan struct{}, notificationChan chan handlers.Notification, bufferManager *BufferManager, proc *processor.Processor, httpServer *httpcontroller.Server, metrics *observability.Metrics) *ControlMonitor {
 	ctrlMonitor := NewControlMonitor(wg, controlChan, quitChan, restartChan, notificationChan, bufferManager, proc, audioLevelChan, soundLevelChan, metrics)
 	ctrlMonitor.httpServer = httpServer
+	proc.SetMQTTControlChannel(controlChan)
 	ctrlMonitor.Start()
 	return ctrlMonitor
 }
@@ -1464,6 +1709,7 @@ func initializeBackupSystem(settings *conf.Settings, backupLogger *slog.Logger)
 
 	// Start backupManager and backupScheduler if backup is enabled
 	if settings.Backup.Enabled {
+		registerBackupSourcesAndTargets(settings, backupManager, backupLogger)
 		backupLogger.Info("Starting backup manager")
 		if err := backupManager.Start(); err != nil {
 			// Log the error but don't necessarily stop initialization
@@ -1477,6 +1723,25 @@ func initializeBackupSystem(settings *conf.Settings, backupLogger *slog.Logger)
 	return backupManager, backupScheduler, nil
 }
 
+// registerBackupSourcesAndTargets registers the SQLite database as backup source and the
+// enabled backup targets of the configuration with the backup manager.
+func registerBackupSourcesAndTargets(settings *conf.Settings, backupManager *backup.Manager, backupLogger *slog.Logger) {
+	if settings.Output.SQLite.Enabled {
+		if err := backupManager.RegisterSource(sources.NewSQLiteSource(settings, backupLogger)); err != nil {
+			backupLogger.Error("Failed to register SQLite backup source", "error", err)
+		}
+	} else {
+		backupLogger.Warn("Backups are enabled but only SQLite databases can be backed up")
+	}
+
+	registered, err := targets.RegisterTargets(backupManager, &settings.Backup, backupLogger)
+	if err != nil {
+		// Targets that failed are skipped, the others are still used
+		backupLogger.Error("Failed to register backup targets", "error", err)
+	}
+	backupLogger.Info("Registered backup targets", "count", registered)
+}
+
 // initializeSystemMonitor initializes and starts the system resource monitor if enabled
 func initializeSystemMonitor(settings *conf.Settings) *monitor.SystemMonitor {
 	logging.Info("initializeSystemMonitor called",
@@ -1552,7 +1817,7 @@ func initializeAudioSources(settings *conf.Settings) ([]string, error) {
 				source, err := registry.RegisterSource(url, myaudio.SourceConfig{
 					ID:          "", // Let registry generate UUID
 					DisplayName: "", // Let auto-generation use SafeString
-					Type:        myaudio.SourceTypeRTSP,
+					Type:        myaudio.StreamSourceType(url),
 				})
 				if err != nil {
 					safeURL := privacy.SanitizeRTSPUrl(url)
@@ -1599,6 +1864,31 @@ func initializeAudioSources(settings *conf.Settings) ([]string, error) {
 }
 
 // printSystemDetails prints system information and analyzer configuration
+// detectFFmpegCapabilities detects the version and features of the configured FFmpeg and warns
+// about missing filters and encoders
+func detectFFmpegCapabilities(settings *conf.Settings) {
+	if settings.Realtime.Audio.FfmpegPath == "" {
+		return
+	}
+	caps, err := myaudio.DetectFFmpegCapabilities(context.Background(), settings.Realtime.Audio.FfmpegPath)
+	if err != nil {
+		GetLogger().Warn("FFmpeg capability detection failed, using default arguments",
+			"error", err,
+			"operation", "detect_ffmpeg_capabilities")
+		log.Printf("⚠️ FFmpeg capability detection failed, using default arguments: %v", err)
+		return
+	}
+
+	GetLogger().Info("FFmpeg capabilities detected",
+		"version", caps.Version,
+		"encoders", caps.Encoders,
+		"loudnorm", caps.Loudnorm)
+	for _, warning := range caps.Warnings {
+		GetLogger().Warn("FFmpeg incompatibility", "warning", warning, "version", caps.Version)
+		log.Printf("⚠️ FFmpeg %s: %s", caps.Version, warning)
+	}
+}
+
 func printSystemDetails(settings *conf.Settings) {
 	// Get system details with gopsutil
 	info, err := host.Info()
@@ -0,0 +1,101 @@
+// run_tracker.go: record application runs with start and stop reasons in the datastore
+package analysis
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// runHeartbeatInterval is how often a running run is marked alive. After a crash the
+// previous run is closed at its last heartbeat, so this bounds the error of the crash time.
+const runHeartbeatInterval = 5 * time.Minute
+
+// shutdownSignal holds the name of the OS signal that triggered shutdown
+var shutdownSignal atomic.Value
+
+// runTracker records the current application run in the datastore
+type runTracker struct {
+	ledger   datastore.RunLedger
+	run      *datastore.Run
+	stopOnce sync.Once
+}
+
+// startRunTracking records the start of this run and keeps it alive with periodic heartbeats
+// until quitChan is closed. It returns nil if the datastore does not record runs.
+func startRunTracking(wg *sync.WaitGroup, settings *conf.Settings, dataStore datastore.Interface, quitChan chan struct{}) *runTracker {
+	ledger, ok := dataStore.(datastore.RunLedger)
+	if !ok {
+		return nil
+	}
+
+	run, err := ledger.StartRun(settings.Version, time.Now())
+	if err != nil {
+		GetLogger().Warn("Failed to record application start",
+			"error", err,
+			"operation", "record_run_start")
+		return nil
+	}
+
+	GetLogger().Info("Recorded application start",
+		"run_id", run.ID,
+		"start_reason", run.StartReason,
+		"version", run.Version,
+		"operation", "record_run_start")
+
+	tracker := &runTracker{ledger: ledger, run: run}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(runHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-quitChan:
+				return
+			case now := <-ticker.C:
+				if err := ledger.HeartbeatRun(run.ID, now); err != nil {
+					GetLogger().Debug("Failed to record run heartbeat",
+						"run_id", run.ID,
+						"error", err,
+						"operation", "record_run_heartbeat")
+				}
+			}
+		}
+	}()
+
+	return tracker
+}
+
+// stop records the end of the run with the given reason. Only the first call has an effect.
+func (t *runTracker) stop(reason, detail string) {
+	if t == nil {
+		return
+	}
+	t.stopOnce.Do(func() {
+		if err := t.ledger.StopRun(t.run.ID, reason, detail, time.Now()); err != nil {
+			GetLogger().Warn("Failed to record application stop",
+				"run_id", t.run.ID,
+				"error", err,
+				"operation", "record_run_stop")
+			return
+		}
+		GetLogger().Info("Recorded application stop",
+			"run_id", t.run.ID,
+			"stop_reason", reason,
+			"stop_detail", detail,
+			"operation", "record_run_stop")
+	})
+}
+
+// shutdownDetail describes what triggered the shutdown for the run record
+func shutdownDetail() string {
+	if sig, ok := shutdownSignal.Load().(string); ok {
+		return "signal: " + sig
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// runLedgerRecorder implements datastore.RunLedger on top of an unused datastore.Interface
+type runLedgerRecorder struct {
+	datastore.Interface
+	version     string
+	stopReasons []string
+	stopDetail  string
+}
+
+func (r *runLedgerRecorder) StartRun(version string, startedAt time.Time) (*datastore.Run, error) {
+	r.version = version
+	return &datastore.Run{ID: 1, Version: version, StartedAt: startedAt, StartReason: datastore.RunStartNormal}, nil
+}
+
+func (r *runLedgerRecorder) HeartbeatRun(runID uint, at time.Time) error { return nil }
+
+func (r *runLedgerRecorder) StopRun(runID uint, reason, detail string, at time.Time) error {
+	r.stopReasons = append(r.stopReasons, reason)
+	r.stopDetail = detail
+	return nil
+}
+
+func (r *runLedgerRecorder) GetRuns(limit int) ([]datastore.Run, error) { return nil, nil }
+
+func TestRunTracking(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.Version = "1.2.3"
+	ledger := &runLedgerRecorder{}
+
+	var wg sync.WaitGroup
+	quitChan := make(chan struct{})
+	tracker := startRunTracking(&wg, settings, ledger, quitChan)
+	require.NotNil(t, tracker)
+	assert.Equal(t, "1.2.3", ledger.version)
+
+	close(quitChan)
+	wg.Wait()
+
+	tracker.stop(datastore.RunStopUserRequest, "signal: interrupt")
+	tracker.stop(datastore.RunStopError, "")
+	assert.Equal(t, []string{datastore.RunStopUserRequest}, ledger.stopReasons, "only the first stop is recorded")
+	assert.Equal(t, "signal: interrupt", ledger.stopDetail)
+
+	// Datastores without run history are ignored
+	var nilTracker *runTracker
+	plainStore := struct{ datastore.Interface }{}
+	assert.Nil(t, startRunTracking(&wg, settings, plainStore, quitChan))
+	nilTracker.stop(datastore.RunStopUserRequest, "")
+}
@@ -0,0 +1,58 @@
+// self_update.go: confirm or roll back a freshly installed binary update
+package analysis
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/updater"
+)
+
+// startUpdateVerification checks a pending self-update against the web server health
+// endpoint. If the update is rolled back the service is restarted to run the previous binary.
+func startUpdateVerification(wg *sync.WaitGroup, settings *conf.Settings, quitChan chan struct{}) {
+	u, err := updater.New(settings, "")
+	if err != nil {
+		GetLogger().Warn("Self-update verification unavailable",
+			"error", err,
+			"operation", "verify_pending_update")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+
+		// Abort the health check on shutdown, it is retried on the next start
+		go func() {
+			select {
+			case <-quitChan:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		rolledBack, err := u.VerifyPendingUpdate(ctx, updater.HealthURL(settings))
+		if err != nil {
+			GetLogger().Error("Failed to verify pending update",
+				"error", err,
+				"operation", "verify_pending_update")
+			return
+		}
+		if !rolledBack {
+			return
+		}
+
+		log.Println("⚠️ Update failed its health check, restarting with the previous version")
+		if err := updater.Restart(settings.Update.RestartCommand); err != nil {
+			GetLogger().Error("Failed to restart after update rollback",
+				"error", err,
+				"operation", "restart_after_rollback")
+		}
+	}()
+}
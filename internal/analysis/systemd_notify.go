@@ -0,0 +1,156 @@
+// systemd_notify.go: readiness and watchdog notifications to systemd tied to pipeline progress
+package analysis
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/sdnotify"
+)
+
+// pipelineProgress is the progress of the capture and analysis loops
+type pipelineProgress struct {
+	lastAudio        time.Time // audio last written to an analysis buffer
+	lastChunk        time.Time // a buffer monitor last read a chunk
+	inferenceStalled bool      // chunks wait without any finishing inference
+}
+
+// currentPipelineProgress returns the progress of the running pipeline
+func currentPipelineProgress(timeout time.Duration) pipelineProgress {
+	return pipelineProgress{
+		lastAudio:        myaudio.LastAudioTime(),
+		lastChunk:        myaudio.LastChunkTime(),
+		inferenceStalled: myaudio.InferenceStalled(timeout),
+	}
+}
+
+// stall returns why the pipeline is stalled at now, or an empty string while it makes
+// progress. Progress before started counts as made at started, so capture and analysis have
+// timeout to begin. Without configured audio sources only inference can stall.
+func (p pipelineProgress) stall(now, started time.Time, timeout time.Duration, captureConfigured bool) string {
+	since := func(t time.Time) time.Duration {
+		if t.Before(started) {
+			t = started
+		}
+		return now.Sub(t)
+	}
+
+	if captureConfigured {
+		if idle := since(p.lastAudio); idle > timeout {
+			return fmt.Sprintf("no audio captured for %s", idle.Round(time.Second))
+		}
+	}
+	// Chunks are read whenever audio arrives, even while analysis is paused
+	if !p.lastAudio.IsZero() && since(p.lastAudio) <= timeout {
+		if idle := since(p.lastChunk); idle > timeout {
+			return fmt.Sprintf("analysis buffers not read for %s", idle.Round(time.Second))
+		}
+	}
+	if p.inferenceStalled {
+		return fmt.Sprintf("no chunk finished inference for over %s", timeout)
+	}
+	return ""
+}
+
+// captureConfigured reports whether any audio source is configured
+func captureConfigured(settings *conf.Settings) bool {
+	return settings.Realtime.Audio.Source != "" || len(settings.Realtime.RTSP.URLs) > 0
+}
+
+// startSystemdNotify tells systemd the service is ready and, when the unit sets WatchdogSec,
+// sends watchdog heartbeats while the capture and analysis loops make progress. Heartbeats
+// stop while the pipeline is stalled, so systemd restarts the service.
+func startSystemdNotify(wg *sync.WaitGroup, settings *conf.Settings, quitChan chan struct{}) {
+	if !settings.Realtime.Systemd.Enabled {
+		return
+	}
+
+	sent, err := sdnotify.Notify(sdnotify.Ready + "\n" + sdnotify.Status("Capturing and analyzing audio"))
+	if err != nil {
+		GetLogger().Warn("Failed to notify systemd of readiness",
+			"error", err,
+			"operation", "systemd_notify")
+		return
+	}
+	if !sent {
+		GetLogger().Info("systemd notifications enabled but NOTIFY_SOCKET is not set, run as a Type=notify service",
+			"operation", "systemd_notify")
+		return
+	}
+	GetLogger().Info("Notified systemd of readiness", "operation", "systemd_notify")
+
+	watchdogTimeout, ok := sdnotify.WatchdogTimeout()
+	if !ok {
+		// Without a watchdog, only tell systemd that shutdown started
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-quitChan
+			notifySystemd(sdnotify.Stopping)
+		}()
+		return
+	}
+
+	stallTimeout := time.Duration(settings.Realtime.Systemd.StallTimeout) * time.Second
+	GetLogger().Info("systemd watchdog enabled",
+		"watchdog_timeout_seconds", watchdogTimeout.Seconds(),
+		"stall_timeout_seconds", stallTimeout.Seconds(),
+		"operation", "systemd_watchdog")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runWatchdog(settings, quitChan, watchdogTimeout/2, stallTimeout)
+	}()
+}
+
+// runWatchdog sends a heartbeat every interval while the pipeline makes progress, until quitChan
+// is closed
+func runWatchdog(settings *conf.Settings, quitChan chan struct{}, interval, stallTimeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	started := time.Now()
+	var stalled string
+	for {
+		now := time.Now()
+		reason := currentPipelineProgress(stallTimeout).stall(now, started, stallTimeout, captureConfigured(settings))
+		switch {
+		case reason == "":
+			if stalled != "" {
+				GetLogger().Info("Analysis pipeline recovered, resuming watchdog heartbeats",
+					"operation", "systemd_watchdog")
+				log.Println("✅ Analysis pipeline recovered, resuming systemd watchdog heartbeats")
+				notifySystemd(sdnotify.Status("Capturing and analyzing audio"))
+			}
+			notifySystemd(sdnotify.Watchdog)
+		case reason != stalled:
+			GetLogger().Error("Analysis pipeline stalled, stopping watchdog heartbeats so systemd restarts the service",
+				"reason", reason,
+				"operation", "systemd_watchdog")
+			log.Printf("❌ Analysis pipeline stalled (%s), systemd will restart the service", reason)
+			notifySystemd(sdnotify.Status("Stalled: " + reason))
+		}
+		stalled = reason
+
+		select {
+		case <-quitChan:
+			notifySystemd(sdnotify.Stopping)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifySystemd sends a state to systemd, logging failures
+func notifySystemd(state string) {
+	if _, err := sdnotify.Notify(state); err != nil {
+		GetLogger().Warn("Failed to notify systemd",
+			"error", err,
+			"operation", "systemd_notify")
+	}
+}
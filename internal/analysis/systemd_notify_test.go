@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineProgressStall(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	timeout := 2 * time.Minute
+	now := started.Add(10 * time.Minute)
+	recent := now.Add(-5 * time.Second)
+	old := now.Add(-5 * time.Minute)
+
+	tests := []struct {
+		name       string
+		progress   pipelineProgress
+		now        time.Time
+		capture    bool
+		wantStall  bool
+		wantReason string
+	}{
+		{"healthy", pipelineProgress{lastAudio: recent, lastChunk: recent}, now, true, false, ""},
+		{"starting up", pipelineProgress{}, started.Add(time.Minute), true, false, ""},
+		{"no audio since start", pipelineProgress{}, now, true, true, "no audio captured for 10m0s"},
+		{"capture stopped", pipelineProgress{lastAudio: old, lastChunk: old}, now, true, true, "no audio captured for 5m0s"},
+		{"no sources configured", pipelineProgress{}, now, false, false, ""},
+		{"buffer monitors hung", pipelineProgress{lastAudio: recent, lastChunk: old}, now, true, true, "analysis buffers not read for 5m0s"},
+		{"progress before start", pipelineProgress{lastAudio: recent, lastChunk: started.Add(-time.Hour)}, started.Add(time.Minute), true, false, ""},
+		{"inference hung", pipelineProgress{lastAudio: recent, lastChunk: recent, inferenceStalled: true}, now, true, true, "no chunk finished inference for over 2m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reason := tt.progress.stall(tt.now, started, timeout, tt.capture)
+			assert.Equal(t, tt.wantStall, reason != "")
+			if tt.wantReason != "" {
+				assert.Equal(t, tt.wantReason, reason)
+			}
+		})
+	}
+}
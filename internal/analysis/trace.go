@@ -0,0 +1,272 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// traceMinConfidence is the lowest raw confidence listed in a trace, lower results are
+// counted but not printed
+const traceMinConfidence = 0.05
+
+// traceVerdict is the decision the detection pipeline makes for one raw result
+type traceVerdict struct {
+	Threshold       float32 // Confidence threshold applied to the result
+	ThresholdSource string  // "custom" for a species threshold, "global" otherwise
+	Accepted        bool    // true when the result would become a pending detection
+	Reason          string  // Why the result was rejected
+}
+
+// TraceAnalysis runs an audio file through the detection pipeline without storing anything
+// and writes a human-readable trace of every stage decision to w: analysis windows, raw model
+// results, thresholds applied, filters and the actions that would fire in realtime mode.
+func TraceAnalysis(settings *conf.Settings, ctx context.Context, w io.Writer) error {
+	if err := initializeBirdNET(settings); err != nil {
+		return err
+	}
+
+	if err := validateAudioFile(settings.Input.Path); err != nil {
+		return err
+	}
+
+	audioInfo, err := myaudio.GetAudioInfo(settings.Input.Path)
+	if err != nil {
+		return fmt.Errorf("error getting audio info: %w", err)
+	}
+
+	writeTraceHeader(w, settings, &audioInfo)
+
+	hop := bn.HopDuration()
+	window := 0
+	accepted := make(map[string]int)
+	var rawCount, listedCount int
+
+	err = myaudio.ReadAudioFileBuffered(settings, func(chunk []float32, _ bool) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		start := time.Duration(window) * hop
+		window++
+		_, _ = fmt.Fprintf(w, "\nWindow %d  %s - %s\n", window, formatTraceOffset(start), formatTraceOffset(start+bn.WindowDuration()))
+
+		results, err := bn.Predict([][]float32{chunk})
+		if err != nil {
+			return err
+		}
+		rawCount += len(results)
+
+		for _, result := range results {
+			if result.Confidence < traceMinConfidence {
+				continue
+			}
+			listedCount++
+
+			scientificName, commonName, _ := bn.EnrichResultWithTaxonomy(result.Species)
+			verdict := traceDecision(settings, result.Species, scientificName, commonName, result.Confidence)
+			_, _ = fmt.Fprintf(w, "  %-40s %.3f  threshold %.2f (%s)\n", displaySpecies(scientificName, commonName, result.Species),
+				result.Confidence, verdict.Threshold, verdict.ThresholdSource)
+
+			for _, note := range traceFilterNotes(settings, commonName, result.Confidence) {
+				_, _ = fmt.Fprintf(w, "    ! %s\n", note)
+			}
+
+			if !verdict.Accepted {
+				_, _ = fmt.Fprintf(w, "    - rejected: %s\n", verdict.Reason)
+				continue
+			}
+			accepted[commonName]++
+			_, _ = fmt.Fprintf(w, "    + accepted, would fire: %s\n", strings.Join(traceActions(settings, commonName), ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ErrAnalysisCanceled
+		}
+		return fmt.Errorf("error processing audio: %w", err)
+	}
+
+	writeTraceSummary(w, settings, window, rawCount, listedCount, accepted)
+	return nil
+}
+
+// writeTraceHeader prints the file and the analysis settings the trace is run with
+func writeTraceHeader(w io.Writer, settings *conf.Settings, audioInfo *myaudio.AudioInfo) {
+	duration := time.Duration(float64(audioInfo.TotalSamples) / float64(audioInfo.SampleRate) * float64(time.Second))
+
+	_, _ = fmt.Fprintf(w, "File:         %s (%s, %d Hz, %d channel(s))\n", filepath.Base(settings.Input.Path),
+		formatTraceOffset(duration), audioInfo.SampleRate, audioInfo.NumChannels)
+	_, _ = fmt.Fprintf(w, "Model:        %s\n", bn.ModelInfo.ID)
+	_, _ = fmt.Fprintf(w, "Windows:      %s long, %s hop (overlap %.1fs)\n", bn.WindowDuration(), bn.HopDuration(), settings.BirdNET.Overlap)
+	_, _ = fmt.Fprintf(w, "Sensitivity:  %.2f\n", settings.BirdNET.Sensitivity)
+	_, _ = fmt.Fprintf(w, "Threshold:    %.2f (global), %d custom species threshold(s)\n", settings.BirdNET.Threshold, len(settings.Realtime.Species.Config))
+	if settings.BirdNET.Latitude != 0 || settings.BirdNET.Longitude != 0 {
+		_, _ = fmt.Fprintf(w, "Range filter: %s model, threshold %.2f, %d species included\n", settings.BirdNET.RangeFilter.Model,
+			settings.BirdNET.RangeFilter.Threshold, len(settings.BirdNET.RangeFilter.Species))
+	} else {
+		_, _ = fmt.Fprintf(w, "Range filter: disabled, no location configured (%d species included)\n", len(settings.BirdNET.RangeFilter.Species))
+	}
+	_, _ = fmt.Fprintf(w, "Results below %.2f confidence are not listed\n", traceMinConfidence)
+}
+
+// writeTraceSummary prints the totals of a trace run
+func writeTraceSummary(w io.Writer, settings *conf.Settings, windows, rawCount, listedCount int, accepted map[string]int) {
+	_, _ = fmt.Fprintf(w, "\nSummary: %d window(s), %d raw result(s), %d listed, %d accepted\n", windows, rawCount, listedCount, sumCounts(accepted))
+	for _, name := range slices.Sorted(maps.Keys(accepted)) {
+		_, _ = fmt.Fprintf(w, "  %-40s accepted in %d window(s)\n", name, accepted[name])
+	}
+
+	// Realtime mode only reports species matched in enough overlapping windows
+	segmentLength := math.Max(0.1, conf.AnalysisHop(settings.BirdNET.Overlap))
+	minDetections := int(math.Max(1, conf.CaptureLength/segmentLength))
+	_, _ = fmt.Fprintf(w, "In realtime mode a species must be accepted in %d consecutive window(s) before it is reported\n", minDetections)
+}
+
+// traceDecision evaluates a raw result the way the realtime processor does: human privacy
+// filter, confidence threshold, exclude list and the range filter species list
+func traceDecision(settings *conf.Settings, species, scientificName, commonName string, confidence float32) traceVerdict {
+	verdict := traceVerdict{Threshold: float32(settings.BirdNET.Threshold), ThresholdSource: "global"}
+	if scientificName == "" || commonName == "" {
+		verdict.Reason = "label could not be parsed"
+		return verdict
+	}
+
+	speciesLowercase := strings.ToLower(commonName)
+	for name, config := range settings.Realtime.Species.Config {
+		if strings.EqualFold(name, speciesLowercase) {
+			verdict.Threshold = float32(config.Threshold)
+			verdict.ThresholdSource = "custom"
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(speciesLowercase, "human") && confidence > verdict.Threshold:
+		verdict.Reason = "human vocalization, never stored for privacy"
+	case confidence <= verdict.Threshold:
+		verdict.Reason = fmt.Sprintf("confidence %.3f is not above threshold %.2f", confidence, verdict.Threshold)
+	case isTraceExcluded(settings.Realtime.Species.Exclude, scientificName, commonName):
+		verdict.Reason = "species is on the exclude list"
+	case !settings.IsSpeciesIncluded(species):
+		verdict.Reason = "species is not on the range filter species list"
+	default:
+		verdict.Accepted = true
+	}
+	return verdict
+}
+
+// traceFilterNotes describes the filters a result triggers that discard other detections
+func traceFilterNotes(settings *conf.Settings, commonName string, confidence float32) []string {
+	var notes []string
+	speciesLowercase := strings.ToLower(commonName)
+	if settings.Realtime.PrivacyFilter.Enabled && strings.Contains(speciesLowercase, "human ") &&
+		confidence > settings.Realtime.PrivacyFilter.Confidence {
+		notes = append(notes, fmt.Sprintf("privacy filter triggered (%.3f > %.2f), pending detections would be discarded",
+			confidence, settings.Realtime.PrivacyFilter.Confidence))
+	}
+	if settings.Realtime.DogBarkFilter.Enabled && strings.Contains(speciesLowercase, "dog") &&
+		confidence > settings.Realtime.DogBarkFilter.Confidence {
+		notes = append(notes, fmt.Sprintf("dog bark filter triggered (%.3f > %.2f), species on the dog bark list would be discarded",
+			confidence, settings.Realtime.DogBarkFilter.Confidence))
+	}
+	return notes
+}
+
+// traceActions lists the actions realtime mode would run for an accepted detection
+func traceActions(settings *conf.Settings, commonName string) []string {
+	var actions []string
+	executeDefaults := true
+	for name, config := range settings.Realtime.Species.Config {
+		if !strings.EqualFold(name, commonName) {
+			continue
+		}
+		var custom []string
+		executeDefaults = false
+		for _, action := range config.Actions {
+			if action.Type == "ExecuteCommand" && len(action.Parameters) > 0 {
+				custom = append(custom, "execute "+action.Command)
+			}
+			if action.ExecuteDefaults {
+				executeDefaults = true
+			}
+		}
+		if len(custom) == 0 {
+			executeDefaults = true
+		}
+		actions = append(actions, custom...)
+		break
+	}
+	if !executeDefaults {
+		return actions
+	}
+
+	if settings.Realtime.Log.Enabled {
+		actions = append(actions, "log to "+settings.Realtime.Log.Path)
+	}
+	if settings.Output.SQLite.Enabled || settings.Output.MySQL.Enabled {
+		actions = append(actions, "save to database")
+		if settings.Realtime.Audio.Export.Enabled {
+			actions = append(actions, "export audio clip")
+		}
+	}
+	if settings.WebServer.Enabled {
+		actions = append(actions, "broadcast to web UI")
+	}
+	if settings.Realtime.Birdweather.Enabled {
+		actions = append(actions, "upload to BirdWeather")
+	}
+	if settings.Realtime.MQTT.Enabled {
+		actions = append(actions, "publish to MQTT")
+	}
+	if len(actions) == 0 {
+		actions = append(actions, "none")
+	}
+	return actions
+}
+
+// isTraceExcluded reports whether a species is on the exclude list by common or scientific name
+func isTraceExcluded(exclude []string, scientificName, commonName string) bool {
+	for _, entry := range exclude {
+		entry = strings.TrimSpace(entry)
+		if strings.EqualFold(entry, scientificName) || strings.EqualFold(entry, commonName) {
+			return true
+		}
+	}
+	return false
+}
+
+// displaySpecies returns the name a result is listed with in a trace
+func displaySpecies(scientificName, commonName, label string) string {
+	if commonName == "" || scientificName == "" {
+		return label
+	}
+	return commonName + " (" + scientificName + ")"
+}
+
+// formatTraceOffset formats an offset into the audio file as minutes, seconds and tenths
+func formatTraceOffset(d time.Duration) string {
+	return fmt.Sprintf("%02d:%04.1f", int(d.Minutes()), math.Mod(d.Seconds(), 60))
+}
+
+// sumCounts returns the sum of the counts in a map
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
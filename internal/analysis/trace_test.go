@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/observation"
+)
+
+func TestTraceDecision(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	settings.BirdNET.RangeFilter.Species = []string{"Parus major_Great Tit", "Prunella modularis_Dunnock"}
+	settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{"dunnock": {Threshold: 0.5}}
+	settings.Realtime.Species.Exclude = []string{"Prunella modularis"}
+
+	tests := []struct {
+		name       string
+		species    string
+		confidence float32
+		accepted   bool
+		source     string
+		reason     string
+	}{
+		{"accepted", "Parus major_Great Tit", 0.9, true, "global", ""},
+		{"below global threshold", "Parus major_Great Tit", 0.8, false, "global", "confidence 0.800 is not above threshold 0.80"},
+		{"custom threshold applied", "Prunella modularis_Dunnock", 0.6, false, "custom", "species is on the exclude list"},
+		{"not on species list", "Turdus merula_Eurasian Blackbird", 0.9, false, "global", "species is not on the range filter species list"},
+		{"human never stored", "Human vocal_Human vocal", 0.9, false, "global", "human vocalization, never stored for privacy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scientificName, commonName, _ := observation.ParseSpeciesString(tt.species)
+			verdict := traceDecision(settings, tt.species, scientificName, commonName, tt.confidence)
+			assert.Equal(t, tt.accepted, verdict.Accepted)
+			assert.Equal(t, tt.source, verdict.ThresholdSource)
+			assert.Equal(t, tt.reason, verdict.Reason)
+		})
+	}
+}
+
+func TestTraceActions(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Realtime.Audio.Export.Enabled = true
+	settings.Realtime.MQTT.Enabled = true
+	settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{
+		"great tit": {Actions: []conf.SpeciesAction{{Type: "ExecuteCommand", Command: "/bin/notify", Parameters: []string{"CommonName"}}}},
+		"dunnock":   {Actions: []conf.SpeciesAction{{Type: "ExecuteCommand", Command: "/bin/notify", Parameters: []string{"CommonName"}, ExecuteDefaults: true}}},
+		"wren":      {Threshold: 0.5},
+	}
+
+	defaults := []string{"save to database", "export audio clip", "publish to MQTT"}
+	assert.Equal(t, defaults, traceActions(settings, "Eurasian Blackbird"))
+	assert.Equal(t, defaults, traceActions(settings, "Wren"), "threshold-only config keeps the default actions")
+	assert.Equal(t, []string{"execute /bin/notify"}, traceActions(settings, "Great Tit"))
+	assert.Equal(t, append([]string{"execute /bin/notify"}, defaults...), traceActions(settings, "Dunnock"))
+
+	assert.Equal(t, []string{"none"}, traceActions(&conf.Settings{}, "Great Tit"))
+}
@@ -105,6 +105,9 @@ func (c *Controller) initAnalyticsRoutes() {
 	speciesGroup.GET("/summary", c.GetSpeciesSummary)
 	speciesGroup.GET("/detections/new", c.GetNewSpeciesDetections) // Renamed endpoint
 	speciesGroup.GET("/thumbnails", c.GetSpeciesThumbnails)        // Batch thumbnail endpoint
+	speciesGroup.GET("/live", c.GetLiveSpeciesStats)               // Rolling statistics from detection events
+
+	c.initLiveStats()
 
 	// Time analytics routes (can be implemented later)
 	timeGroup := analyticsGroup.Group("/time")
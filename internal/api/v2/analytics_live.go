@@ -0,0 +1,54 @@
+// internal/api/v2/analytics_live.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/detectionstats"
+	"github.com/tphakala/birdnet-go/internal/events"
+)
+
+// defaultLiveStatsLimit is the number of top species returned by default
+const defaultLiveStatsLimit = 10
+
+// initLiveStats registers the rolling detection statistics with the event bus
+func (c *Controller) initLiveStats() {
+	if c.liveStats != nil || !events.IsInitialized() {
+		return
+	}
+	eventBus := events.GetEventBus()
+	if eventBus == nil {
+		return
+	}
+
+	aggregator := detectionstats.New()
+	if err := eventBus.RegisterConsumer(aggregator); err != nil {
+		c.logger.Printf("Warning: Failed to register live detection statistics: %v", err)
+		return
+	}
+	c.liveStats = aggregator
+}
+
+// GetLiveSpeciesStats handles GET /api/v2/analytics/species/live
+// Returns the top species and detection rate of the last hour and day from in-memory statistics
+func (c *Controller) GetLiveSpeciesStats(ctx echo.Context) error {
+	if c.liveStats == nil {
+		return c.HandleError(ctx, fmt.Errorf("event bus not available"),
+			"Live detection statistics are not available", http.StatusServiceUnavailable)
+	}
+
+	limit := defaultLiveStatsLimit
+	if limitStr := ctx.QueryParam("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 || parsedLimit > 100 {
+			return c.HandleError(ctx, fmt.Errorf("invalid limit %q", limitStr),
+				"Limit must be a number between 1 and 100", http.StatusBadRequest)
+		}
+		limit = parsedLimit
+	}
+
+	return ctx.JSON(http.StatusOK, c.liveStats.Snapshot(limit))
+}
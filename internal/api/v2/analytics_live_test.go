@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/detectionstats"
+)
+
+// TestGetLiveSpeciesStats tests the live species statistics endpoint
+func TestGetLiveSpeciesStats(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	controller := &Controller{Echo: e, logger: log.Default(), liveStats: detectionstats.New()}
+
+	now := time.Now()
+	controller.liveStats.Add("Eurasian Blackbird", "Turdus merula", now.Add(-2*time.Minute))
+	controller.liveStats.Add("Eurasian Blackbird", "Turdus merula", now.Add(-time.Minute))
+	controller.liveStats.Add("Great Tit", "Parus major", now)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/species/live?limit=1", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetLiveSpeciesStats(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var snapshot detectionstats.Snapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+	assert.Equal(t, 3, snapshot.LastHour.Total)
+	assert.Equal(t, 2, snapshot.LastHour.Species)
+	require.Len(t, snapshot.LastHour.Top, 1)
+	assert.Equal(t, "Turdus merula", snapshot.LastHour.Top[0].ScientificName)
+	assert.Equal(t, 2, snapshot.LastHour.Top[0].Count)
+}
+
+// TestGetLiveSpeciesStatsErrors tests the live species statistics endpoint error responses
+func TestGetLiveSpeciesStatsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		liveStats  *detectionstats.Aggregator
+		query      string
+		wantStatus int
+	}{
+		{"event bus not available", nil, "", http.StatusServiceUnavailable},
+		{"invalid limit", detectionstats.New(), "?limit=abc", http.StatusBadRequest},
+		{"limit too large", detectionstats.New(), "?limit=101", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			controller := &Controller{Echo: e, logger: log.Default(), liveStats: tt.liveStats}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/species/live"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+			require.NoError(t, controller.GetLiveSpeciesStats(e.NewContext(req, rec)))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
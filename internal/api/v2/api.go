@@ -25,8 +25,10 @@ import (
 	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/detectionstats"
 	"github.com/tphakala/birdnet-go/internal/ebird"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/export"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/logging"
 	"github.com/tphakala/birdnet-go/internal/observability"
@@ -48,6 +50,7 @@ type Controller struct {
 	logger              *log.Logger
 	controlChan         chan string
 	speciesExcludeMutex sync.RWMutex // Mutex for species exclude list operations
+	updateMutex         sync.Mutex   // Prevents concurrent binary self-updates
 	// DisableSaveSettings prevents persisting settings changes to disk.
 	// When set to true, all settings modifications remain in memory only.
 	// This is primarily used in testing but can be used in production for read-only mode.
@@ -73,6 +76,12 @@ type Controller struct {
 	// SSE related fields
 	sseManager *SSEManager // Manager for Server-Sent Events connections
 
+	// exportManager runs bulk detection exports, nil when the datastore does not support them
+	exportManager *export.Manager
+
+	// liveStats keeps rolling detection statistics, nil when the event bus is not available
+	liveStats *detectionstats.Aggregator
+
 	// Cleanup related fields
 	ctx    context.Context    // Context for managing goroutines
 	cancel context.CancelFunc // Cancel function for graceful shutdown
@@ -420,6 +429,14 @@ func (c *Controller) LoggingMiddleware() echo.MiddlewareFunc {
 func (c *Controller) initRoutes() {
 	// Health check endpoint - publicly accessible
 	c.Group.GET("/health", c.HealthCheck)
+	c.Group.GET("/health/live", c.LivenessCheck)
+	c.Group.GET("/health/ready", c.ReadinessCheck)
+
+	// Conventional probe paths for container orchestration and uptime monitors
+	if c.Echo != nil {
+		c.Echo.GET("/healthz", c.ComponentHealth)
+		c.Echo.GET("/readyz", c.ReadinessCheck)
+	}
 
 	// Initialize route groups with proper error handling and logging
 	routeInitializers := []struct {
@@ -437,13 +454,21 @@ func (c *Controller) initRoutes() {
 		{"integration routes", c.initIntegrationsRoutes},
 		{"control routes", c.initControlRoutes},
 		{"auth routes", c.initAuthRoutes},
+		{"auth user routes", c.initAuthUserRoutes},
 		{"media routes", c.initMediaRoutes},
 		{"range routes", c.initRangeRoutes},
 		{"sse routes", c.initSSERoutes},
 		{"notification routes", c.initNotificationRoutes},
+		{"web push routes", c.initWebPushRoutes},
 		{"support routes", c.initSupportRoutes},
 		{"debug routes", c.initDebugRoutes},
+		{"pipeline debug routes", c.initPipelineDebugRoutes},
 		{"species routes", c.initSpeciesRoutes},
+		{"species alias routes", c.initSpeciesAliasRoutes},
+		{"export routes", c.initExportRoutes},
+		{"deployment routes", c.initDeploymentRoutes},
+		{"log routes", c.initLogRoutes},
+		{"source routes", c.initSourceRoutes},
 	}
 
 	for _, initializer := range routeInitializers {
@@ -546,6 +571,11 @@ func (c *Controller) Shutdown() {
 	// Wait for all goroutines to finish
 	c.wg.Wait()
 
+	// Interrupt running export jobs so they can be resumed after restart
+	if c.exportManager != nil {
+		c.exportManager.Stop()
+	}
+
 	// Close the API logger if it was initialized
 	if c.apiLoggerClose != nil {
 		if err := c.apiLoggerClose(); err != nil {
@@ -711,8 +741,8 @@ func (c *Controller) logAPIRequest(ctx echo.Context, level slog.Level, msg strin
 }
 
 // handleTokenAuth attempts authentication using a Bearer token from the Authorization header.
-// It returns true if authentication succeeds.
-// It returns false and a specific error (errMalformedAuthHeader, errInvalidAuthToken, errAuthServiceNil, or nil for no header)
+// It returns true if authentication succeeds and stores the username and role of the token in the context.
+// It returns false and a specific error (errMalformedAuthHeader, errInvalidAuthToken, auth.ErrInsufficientScope, errAuthServiceNil, or nil for no header)
 // if authentication fails or is not attempted.
 // It no longer writes the HTTP response directly.
 func (c *Controller) handleTokenAuth(ctx echo.Context) (bool, error) {
@@ -742,11 +772,23 @@ func (c *Controller) handleTokenAuth(ctx echo.Context) (bool, error) {
 	}
 
 	token := parts[1]
-	validationErr := c.AuthService.ValidateToken(token) // Capture the error
+	username, role, validationErr := c.AuthService.TokenIdentity(token) // Capture the error
 	if validationErr == nil {
 		if c.apiLogger != nil {
-			c.apiLogger.Debug("Token authentication successful", "path", ctx.Request().URL.Path, "ip", ctx.RealIP())
+			c.apiLogger.Debug("Token authentication successful", "path", ctx.Request().URL.Path, "ip", ctx.RealIP(), "role", role)
 		}
+		if err := auth.CheckTokenScopes(ctx, c.AuthService.TokenScopes(token)); err != nil {
+			if c.apiLogger != nil {
+				c.apiLogger.Warn("Token scope does not allow request",
+					"path", ctx.Request().URL.Path,
+					"method", ctx.Request().Method,
+					"ip", ctx.RealIP(),
+				)
+			}
+			return false, err
+		}
+		ctx.Set("username", username)
+		ctx.Set("role", role)
 		return true, nil // Token validation successful
 	}
 
@@ -763,7 +805,7 @@ func (c *Controller) handleTokenAuth(ctx echo.Context) (bool, error) {
 }
 
 // handleSessionAuth attempts authentication using the existing session.
-// It returns true if authentication succeeds, false otherwise.
+// It returns true if authentication succeeds and stores the username and role of the login in the context, false otherwise.
 // It now uses the Controller's AuthService instance.
 func (c *Controller) handleSessionAuth(ctx echo.Context) bool {
 	if c.AuthService == nil {
@@ -778,6 +820,13 @@ func (c *Controller) handleSessionAuth(ctx echo.Context) bool {
 		if c.apiLogger != nil {
 			c.apiLogger.Debug("Session authentication successful", "path", ctx.Request().URL.Path, "ip", ctx.RealIP())
 		}
+		ctx.Set("role", conf.RoleAdmin)
+		if username, role, ok := c.AuthService.SessionIdentity(ctx); ok {
+			ctx.Set("username", username)
+			ctx.Set("role", role)
+		} else {
+			ctx.Set("username", c.AuthService.GetUsername(ctx))
+		}
 		return true
 	}
 
@@ -823,7 +872,8 @@ func (c *Controller) handleUnauthorized(ctx echo.Context) error {
 // check in AuthMiddleware.
 func (c *Controller) isAuthRequiredWithoutService(ctx echo.Context) bool {
 	// Assume auth is required if any provider is enabled
-	authWouldBeRequired := c.Settings.Security.BasicAuth.Enabled || c.Settings.Security.GoogleAuth.Enabled || c.Settings.Security.GithubAuth.Enabled
+	authWouldBeRequired := c.Settings.Security.BasicAuth.Enabled || c.Settings.Security.GoogleAuth.Enabled || c.Settings.Security.GithubAuth.Enabled || c.Settings.Security.OIDCAuth.Enabled ||
+		len(c.Settings.Security.Users) > 0
 
 	// Check for subnet bypass only if auth would otherwise be required
 	if authWouldBeRequired && c.Settings.Security.AllowSubnetBypass.Enabled {
@@ -900,6 +950,7 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			}
 			ctx.Set("isAuthenticated", false)
 			ctx.Set("authMethod", auth.AuthMethodUnknown) // Use defined enum for 'none'
+			ctx.Set("role", conf.RoleAdmin)
 			return next(ctx)
 		}
 
@@ -910,17 +961,29 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			}
 			ctx.Set("isAuthenticated", false)
 			ctx.Set("authMethod", auth.AuthMethodUnknown) // Use defined enum for 'none'
+			ctx.Set("role", authService.BypassRole(ctx))
+
+			// Clients bypassing with a lesser role may still log in for full access,
+			// invalid credentials are ignored as they are not needed
+			if auth.RoleFromContext(ctx) != conf.RoleAdmin {
+				if authenticated, _ := c.handleTokenAuth(ctx); authenticated {
+					ctx.Set("isAuthenticated", true)
+					ctx.Set("authMethod", auth.AuthMethodToken)
+				} else if username, role, ok := authService.SessionIdentity(ctx); ok {
+					ctx.Set("isAuthenticated", true)
+					ctx.Set("username", username)
+					ctx.Set("role", role)
+					ctx.Set("authMethod", auth.AuthMethodBrowserSession)
+				}
+			}
 			return next(ctx)
 		}
 
 		// Try token authentication first
 		authenticated, tokenErr := c.handleTokenAuth(ctx)
 		if authenticated {
-			// Token auth successful
+			// Token auth successful, username and role were set by handleTokenAuth
 			ctx.Set("isAuthenticated", true)
-			// NOTE: Cannot reliably get username from opaque token via current AuthService.
-			// Username will be empty unless the session also exists and contains it.
-			// ctx.Set("username", authService.GetUsername(ctx)) // Removed this line
 			ctx.Set("authMethod", auth.AuthMethodToken) // Store enum directly
 			return next(ctx)
 		}
@@ -935,6 +998,9 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 				// Logged in handleTokenAuth already
 				ctx.Response().Header().Set("WWW-Authenticate", `Bearer realm="api"`)
 				return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid Authorization header"})
+			case errors.Is(tokenErr, auth.ErrInsufficientScope):
+				// Logged in handleTokenAuth already
+				return auth.RespondInsufficientScope(ctx)
 			case errors.Is(tokenErr, errInvalidAuthToken):
 				// Logged in handleTokenAuth already
 				ctx.Response().Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="Invalid or expired token"`)
@@ -951,7 +1017,6 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		// Token auth not attempted (no header) or failed, try session auth
 		if c.handleSessionAuth(ctx) {
 			ctx.Set("isAuthenticated", true)
-			ctx.Set("username", authService.GetUsername(ctx))
 			ctx.Set("authMethod", auth.AuthMethodBrowserSession) // Use defined enum for session
 			return next(ctx)
 		}
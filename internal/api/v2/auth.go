@@ -16,6 +16,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	auth "github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/security"
 )
 
@@ -47,6 +48,7 @@ type AuthStatus struct {
 	Authenticated bool   `json:"authenticated"`
 	Username      string `json:"username,omitempty"`
 	Method        string `json:"auth_method,omitempty"`
+	Role          string `json:"role"`
 }
 
 // initAuthRoutes registers all authentication-related API endpoints
@@ -136,8 +138,9 @@ func (c *Controller) Login(ctx echo.Context) error {
 			"Authentication service unavailable", http.StatusInternalServerError)
 	}
 
-	// If authentication is not required, act as if the login was successful
-	if !authService.IsAuthRequired(ctx) {
+	// If authentication is not required, act as if the login was successful. Clients
+	// bypassing with a lesser role than admin may still log in for full access.
+	if !authService.IsAuthRequired(ctx) && authService.BypassRole(ctx) == conf.RoleAdmin {
 		if c.apiLogger != nil {
 			c.apiLogger.Info("Authentication not required",
 				"username", req.Username,
@@ -326,6 +329,7 @@ func (c *Controller) GetAuthStatus(ctx echo.Context) error {
 		Authenticated: isAuthenticated,
 		Username:      username,
 		Method:        authMethod,
+		Role:          auth.RoleFromContext(ctx),
 	}
 
 	if c.apiLogger != nil {
@@ -333,6 +337,7 @@ func (c *Controller) GetAuthStatus(ctx echo.Context) error {
 			"authenticated", status.Authenticated,
 			"username", status.Username,
 			"method", status.Method,
+			"role", status.Role,
 			"ip", ctx.RealIP(),
 			"path", ctx.Request().URL.Path,
 			"user_agent", ctx.Request().Header.Get("User-Agent"),
@@ -9,6 +9,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/markbates/goth/gothic"
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/security"
 )
 
@@ -135,20 +136,70 @@ func AuthMethodFromString(s string) AuthMethod {
 }
 
 // ValidateToken checks if a bearer token is valid by calling the underlying OAuth2Server.
+// Access tokens issued at login and configured API tokens are accepted.
 // Returns the specific error from OAuth2Server.ValidateAccessToken if validation fails,
 // or nil if the token is valid.
 func (a *SecurityAdapter) ValidateToken(token string) error {
-	// Directly return the error from the underlying validation method.
-	return a.OAuth2Server.ValidateAccessToken(token)
+	_, _, err := a.TokenIdentity(token)
+	return err
+}
+
+// TokenIdentity returns the user name and role of a bearer token. Access tokens are
+// checked first, then the configured API tokens.
+func (a *SecurityAdapter) TokenIdentity(token string) (username, role string, err error) {
+	username, role, err = a.OAuth2Server.AccessTokenIdentity(token)
+	if err == nil {
+		return username, role, nil
+	}
+	if name, apiRole, apiErr := a.OAuth2Server.ValidateAPIToken(token); apiErr == nil {
+		return name, apiRole, nil
+	}
+	// Return the access token error, it tells whether the token expired
+	return "", "", err
+}
+
+// TokenScopes returns the scopes of a configured API token. Access tokens issued at
+// login have no scopes.
+func (a *SecurityAdapter) TokenScopes(token string) []string {
+	return a.OAuth2Server.APITokenScopes(token)
+}
+
+// SessionIdentity returns the user name and role of the login stored in the session
+func (a *SecurityAdapter) SessionIdentity(c echo.Context) (username, role string, ok bool) {
+	return a.OAuth2Server.SessionIdentity(c)
+}
+
+// BypassRole returns the role of a client that does not need to authenticate. Clients in
+// an allowed subnet get the configured subnet bypass role, all others are admins as no
+// login is configured.
+func (a *SecurityAdapter) BypassRole(c echo.Context) string {
+	if a.OAuth2Server.IsRequestFromAllowedSubnet(c.RealIP()) {
+		return a.OAuth2Server.SubnetBypassRole()
+	}
+	return conf.RoleAdmin
 }
 
 // AuthenticateBasic handles basic authentication with username/password.
-// NOTE: This application does not support multiple user accounts or authorization levels.
-// Basic authentication relies on a single, fixed username/password combination
-// configured in settings (Security.BasicAuth.ClientID and Security.BasicAuth.Password).
-// The provided username MUST match the configured ClientID.
+// Configured user accounts (Security.Users) are checked first and log in with their role.
+// Otherwise the credentials must match the single admin account configured in
+// Security.BasicAuth, where the username is the ClientID.
 // Returns auth code on success, error on failure.
 func (a *SecurityAdapter) AuthenticateBasic(c echo.Context, username, password string) (string, error) {
+	if a.OAuth2Server.HasUser(username) {
+		role, ok := a.OAuth2Server.AuthenticateUser(username, password)
+		if !ok {
+			return "", ErrInvalidCredentials
+		}
+		authCode, err := a.OAuth2Server.GenerateAuthCodeForUser(username, role)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Error("Failed to generate auth code during user login", "error", err.Error())
+			}
+			return "", ErrInvalidCredentials
+		}
+		return authCode, nil
+	}
+
 	// For basic auth, check against configured ClientID and Password
 	storedPassword := a.OAuth2Server.Settings.Security.BasicAuth.Password
 	storedClientID := a.OAuth2Server.Settings.Security.BasicAuth.ClientID // Use ClientID as the username
@@ -225,6 +276,7 @@ func (a *SecurityAdapter) Logout(c echo.Context) error {
 	gothic.StoreInSession("access_token", "", c.Request(), c.Response()) //nolint:errcheck // Error checking not critical during logout
 	gothic.StoreInSession("google", "", c.Request(), c.Response())       //nolint:errcheck // Error checking not critical during logout
 	gothic.StoreInSession("github", "", c.Request(), c.Response())       //nolint:errcheck // Error checking not critical during logout
+	security.ClearOIDCLogin(c)                                           //nolint:errcheck // Error checking not critical during logout
 
 	// Log out from gothic session
 	return gothic.Logout(c.Response().Writer, c.Request())
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/security"
 )
 
@@ -58,6 +59,12 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			// Set context to indicate bypass
 			c.Set("isAuthenticated", false)
 			c.Set("authMethod", AuthMethodNone)
+			c.Set("role", m.AuthService.BypassRole(c))
+
+			// Clients bypassing with a lesser role may still log in for full access
+			if RoleFromContext(c) != conf.RoleAdmin {
+				m.upgradeBypassRole(c)
+			}
 			return next(c)
 		}
 
@@ -72,15 +79,25 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 				token := strings.TrimSpace(parts[1]) // Trim whitespace from token
 
 				// Validate the token, check if the returned error is nil
-				if err := m.AuthService.ValidateToken(token); err == nil {
+				if username, role, err := m.AuthService.TokenIdentity(token); err == nil {
 					// Token is valid
 					if m.logger != nil {
-						m.logger.Debug("Token authentication successful", "path", path, "ip", ip)
+						m.logger.Debug("Token authentication successful", "path", path, "ip", ip, "role", role)
+					}
+					if err := CheckTokenScopes(c, m.AuthService.TokenScopes(token)); err != nil {
+						if m.logger != nil {
+							m.logger.Warn("Token scope does not allow request", "path", path, "ip", ip, "method", c.Request().Method)
+						}
+						return RespondInsufficientScope(c)
 					}
 					// Set context values on successful authentication
+					if username == "" {
+						username = m.AuthService.GetUsername(c)
+					}
 					c.Set("isAuthenticated", true)
-					c.Set("username", m.AuthService.GetUsername(c))
+					c.Set("username", username)
 					c.Set("authMethod", AuthMethodToken)
+					c.Set("role", role)
 					return next(c)
 				}
 
@@ -121,7 +138,13 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			// Set context values on successful authentication
 			c.Set("isAuthenticated", true)
 			c.Set("authMethod", m.AuthService.GetAuthMethod(c))
-			c.Set("username", m.AuthService.GetUsername(c))
+			c.Set("role", conf.RoleAdmin)
+			if username, role, ok := m.AuthService.SessionIdentity(c); ok {
+				c.Set("username", username)
+				c.Set("role", role)
+			} else {
+				c.Set("username", m.AuthService.GetUsername(c))
+			}
 			return next(c)
 		}
 
@@ -130,6 +153,37 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// upgradeBypassRole replaces the role of a client that bypassed authentication with the
+// role of its bearer token or session login, if any. Invalid credentials and tokens whose
+// scopes do not allow the request are ignored as the client may use the service without them.
+func (m *Middleware) upgradeBypassRole(c echo.Context) {
+	if token, ok := bearerToken(c); ok {
+		username, role, err := m.AuthService.TokenIdentity(token)
+		if err == nil && CheckTokenScopes(c, m.AuthService.TokenScopes(token)) == nil {
+			c.Set("isAuthenticated", true)
+			c.Set("username", username)
+			c.Set("authMethod", AuthMethodToken)
+			c.Set("role", role)
+		}
+		return
+	}
+	if username, role, ok := m.AuthService.SessionIdentity(c); ok {
+		c.Set("isAuthenticated", true)
+		c.Set("username", username)
+		c.Set("authMethod", AuthMethodBrowserSession)
+		c.Set("role", role)
+	}
+}
+
+// bearerToken returns the token of a bearer Authorization header
+func bearerToken(c echo.Context) (string, bool) {
+	parts := strings.SplitN(c.Request().Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(parts[1]), true
+}
+
 // handleUnauthenticated determines the appropriate response for unauthenticated requests
 func (m *Middleware) handleUnauthenticated(c echo.Context) error {
 	ip := c.RealIP()
@@ -0,0 +1,33 @@
+// internal/api/v2/auth/roles.go
+package auth
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// RoleFromContext returns the role stored in the context by the authentication middleware.
+// Requests without a role, such as those on routes the middleware did not run for, get the
+// least privileged viewer role; the middleware sets the admin role explicitly when
+// authentication is not required.
+func RoleFromContext(c echo.Context) string {
+	if role, ok := c.Get("role").(string); ok && role != "" {
+		return role
+	}
+	return conf.RoleViewer
+}
+
+// RequireAdmin is middleware that rejects requests of clients without the admin role.
+// It must run after the authentication middleware.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if RoleFromContext(c) != conf.RoleAdmin {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Admin role required",
+			})
+		}
+		return next(c)
+	}
+}
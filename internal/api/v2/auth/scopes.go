@@ -0,0 +1,80 @@
+// internal/api/v2/auth/scopes.go
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// ErrInsufficientScope is returned when the scopes of a token do not allow a request
+var ErrInsufficientScope = errors.New("token scope does not allow this request")
+
+// apiPathPrefix is the path prefix of the API routes
+const apiPathPrefix = "/api/v2"
+
+// scopeRule gives the scopes needed for the endpoints below a path
+type scopeRule struct {
+	path       string // path below /api/v2, matches the path and everything below it
+	readScope  string // scope for GET and HEAD requests
+	writeScope string // scope for all other requests
+}
+
+// scopeRules lists the endpoints scoped tokens may use. Endpoints that are not listed,
+// such as user and token management, need a token without scopes.
+var scopeRules = []scopeRule{
+	{"/detections", conf.ScopeDetectionsRead, conf.ScopeDetectionsWrite},
+	{"/analytics", conf.ScopeDetectionsRead, conf.ScopeDetectionsRead},
+	{"/species", conf.ScopeDetectionsRead, conf.ScopeDetectionsRead},
+	{"/media", conf.ScopeDetectionsRead, conf.ScopeDetectionsRead},
+	{"/search", conf.ScopeDetectionsRead, conf.ScopeDetectionsRead},
+	{"/export", conf.ScopeDetectionsRead, conf.ScopeDetectionsRead},
+	{"/settings", conf.ScopeSettingsRead, conf.ScopeSettingsWrite},
+	{"/control", conf.ScopeControlRestart, conf.ScopeControlRestart},
+	{"/system", conf.ScopeSystemRead, ""},
+}
+
+// RequiredScope returns the scope a scoped token needs for a request. ok is false when
+// scoped tokens may not make the request.
+func RequiredScope(method, path string) (scope string, ok bool) {
+	path, found := strings.CutPrefix(path, apiPathPrefix)
+	if !found {
+		return "", false
+	}
+	for _, rule := range scopeRules {
+		if path != rule.path && !strings.HasPrefix(path, rule.path+"/") {
+			continue
+		}
+		scope = rule.writeScope
+		if method == http.MethodGet || method == http.MethodHead {
+			scope = rule.readScope
+		}
+		return scope, scope != ""
+	}
+	return "", false
+}
+
+// CheckTokenScopes returns ErrInsufficientScope when a token limited to scopes may not
+// make the request. Tokens without scopes may make any request their role allows.
+func CheckTokenScopes(c echo.Context, scopes []string) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+	scope, ok := RequiredScope(c.Request().Method, c.Request().URL.Path)
+	if !ok || !slices.Contains(scopes, scope) {
+		return ErrInsufficientScope
+	}
+	return nil
+}
+
+// RespondInsufficientScope writes the response to a request outside the scopes of its token
+func RespondInsufficientScope(c echo.Context) error {
+	c.Response().Header().Set("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope"`)
+	return c.JSON(http.StatusForbidden, map[string]string{
+		"error": "Token scope does not allow this request",
+	})
+}
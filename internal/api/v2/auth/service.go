@@ -53,6 +53,21 @@ type Service interface {
 	// Returns nil on success, or ErrInvalidToken on failure.
 	ValidateToken(token string) error
 
+	// TokenIdentity returns the user name and role of a valid bearer token, an access
+	// token issued at login or a configured API token.
+	TokenIdentity(token string) (username, role string, err error)
+
+	// TokenScopes returns the scopes a bearer token is limited to, nil when the token may
+	// use all endpoints its role allows
+	TokenScopes(token string) []string
+
+	// SessionIdentity returns the user name and role of the login stored in the session.
+	// ok is false when the session has no login.
+	SessionIdentity(c echo.Context) (username, role string, ok bool)
+
+	// BypassRole returns the role of a client that does not need to authenticate
+	BypassRole(c echo.Context) string
+
 	// AuthenticateBasic handles basic authentication with username/password.
 	// Returns the auth code on success, or error on failure.
 	AuthenticateBasic(c echo.Context, username, password string) (string, error)
@@ -0,0 +1,300 @@
+// internal/api/v2/auth_users.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/security"
+)
+
+// minPasswordLength is the minimum length of user account passwords
+const minPasswordLength = 8
+
+// UserAccountInfo describes a user account without its password hash
+type UserAccountInfo struct {
+	Username      string                        `json:"username"`
+	Role          string                        `json:"role"`
+	Notifications conf.UserNotificationSettings `json:"notifications,omitzero"`
+}
+
+// UserAccountRequest creates or updates a user account. An empty password keeps the
+// current password when updating.
+type UserAccountRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// APITokenInfo describes an API token without its hash
+type APITokenInfo struct {
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// APITokenRequest creates an API token. Without scopes the token may use all endpoints
+// its role allows.
+type APITokenRequest struct {
+	Name   string   `json:"name"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+}
+
+// APITokenCreatedResponse is returned once when an API token is created. The token is
+// not stored and cannot be retrieved later.
+type APITokenCreatedResponse struct {
+	APITokenInfo
+	Token string `json:"token"`
+}
+
+// initAuthUserRoutes registers the user account and API token management endpoints
+func (c *Controller) initAuthUserRoutes() {
+	adminGroup := c.Group.Group("/auth", c.AuthMiddleware, auth.RequireAdmin)
+
+	adminGroup.GET("/users", c.GetUserAccounts)
+	adminGroup.POST("/users", c.CreateUserAccount)
+	adminGroup.PUT("/users/:username", c.UpdateUserAccount)
+	adminGroup.DELETE("/users/:username", c.DeleteUserAccount)
+	adminGroup.PUT("/users/:username/notifications", c.UpdateUserNotificationPreferences)
+
+	adminGroup.GET("/tokens", c.GetAPITokens)
+	adminGroup.POST("/tokens", c.CreateAPIToken)
+	adminGroup.DELETE("/tokens/:name", c.DeleteAPIToken)
+}
+
+// GetUserAccounts handles GET /api/v2/auth/users
+func (c *Controller) GetUserAccounts(ctx echo.Context) error {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	users := make([]UserAccountInfo, 0, len(c.Settings.Security.Users))
+	for _, user := range c.Settings.Security.Users {
+		users = append(users, UserAccountInfo{Username: user.Username, Role: user.Role, Notifications: user.Notifications})
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{"users": users})
+}
+
+// CreateUserAccount handles POST /api/v2/auth/users
+func (c *Controller) CreateUserAccount(ctx echo.Context) error {
+	var req UserAccountRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" {
+		return c.HandleError(ctx, fmt.Errorf("username is required"), "Username is required", http.StatusBadRequest)
+	}
+	if err := validateRole(req.Role); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	if len(req.Password) < minPasswordLength {
+		return c.HandleError(ctx, fmt.Errorf("password too short"),
+			fmt.Sprintf("Password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+	}
+
+	hash, err := security.HashPassword(req.Password)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to hash password", http.StatusInternalServerError)
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	if userAccountIndex(c.Settings.Security.Users, req.Username) >= 0 {
+		return c.HandleError(ctx, fmt.Errorf("user %s already exists", req.Username), "User already exists", http.StatusConflict)
+	}
+
+	oldSettings := *c.Settings
+	// Replace the slice instead of appending in place, the security package reads it without the lock
+	c.Settings.Security.Users = append(slices.Clone(c.Settings.Security.Users),
+		conf.UserAccount{Username: req.Username, PasswordHash: hash, Role: req.Role})
+	if err := c.saveAuthSettings(ctx, &oldSettings); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusCreated, UserAccountInfo{Username: req.Username, Role: req.Role})
+}
+
+// UpdateUserAccount handles PUT /api/v2/auth/users/:username
+// Changes the role and, when a password is given, the password of a user.
+func (c *Controller) UpdateUserAccount(ctx echo.Context) error {
+	var req UserAccountRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if err := validateRole(req.Role); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	var hash string
+	if req.Password != "" {
+		if len(req.Password) < minPasswordLength {
+			return c.HandleError(ctx, fmt.Errorf("password too short"),
+				fmt.Sprintf("Password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+		}
+		var err error
+		if hash, err = security.HashPassword(req.Password); err != nil {
+			return c.HandleError(ctx, err, "Failed to hash password", http.StatusInternalServerError)
+		}
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	index := userAccountIndex(c.Settings.Security.Users, ctx.Param("username"))
+	if index < 0 {
+		return c.HandleError(ctx, fmt.Errorf("user %s not found", ctx.Param("username")), "User not found", http.StatusNotFound)
+	}
+
+	oldSettings := *c.Settings
+	users := slices.Clone(c.Settings.Security.Users)
+	users[index].Role = req.Role
+	if hash != "" {
+		users[index].PasswordHash = hash
+	}
+	c.Settings.Security.Users = users
+	if err := c.saveAuthSettings(ctx, &oldSettings); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, UserAccountInfo{Username: users[index].Username, Role: users[index].Role, Notifications: users[index].Notifications})
+}
+
+// DeleteUserAccount handles DELETE /api/v2/auth/users/:username
+func (c *Controller) DeleteUserAccount(ctx echo.Context) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	index := userAccountIndex(c.Settings.Security.Users, ctx.Param("username"))
+	if index < 0 {
+		return c.HandleError(ctx, fmt.Errorf("user %s not found", ctx.Param("username")), "User not found", http.StatusNotFound)
+	}
+
+	oldSettings := *c.Settings
+	c.Settings.Security.Users = slices.Delete(slices.Clone(c.Settings.Security.Users), index, index+1)
+	if err := c.saveAuthSettings(ctx, &oldSettings); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetAPITokens handles GET /api/v2/auth/tokens
+func (c *Controller) GetAPITokens(ctx echo.Context) error {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	tokens := make([]APITokenInfo, 0, len(c.Settings.Security.APITokens))
+	for _, token := range c.Settings.Security.APITokens {
+		tokens = append(tokens, APITokenInfo{Name: token.Name, Role: token.Role, Scopes: token.Scopes, CreatedAt: token.CreatedAt})
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{"tokens": tokens})
+}
+
+// CreateAPIToken handles POST /api/v2/auth/tokens
+// Returns the new token, which is shown only once. Scopes that allow changes need the admin role.
+func (c *Controller) CreateAPIToken(ctx echo.Context) error {
+	var req APITokenRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.HandleError(ctx, fmt.Errorf("name is required"), "Token name is required", http.StatusBadRequest)
+	}
+	if err := validateRole(req.Role); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	var scopes []string
+	if len(req.Scopes) > 0 {
+		scopes = slices.Compact(slices.Sorted(slices.Values(req.Scopes)))
+	}
+	if err := conf.ValidateAPITokenScopes(scopes, req.Role); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	token, hash, err := security.GenerateAPIToken()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to generate token", http.StatusInternalServerError)
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	if apiTokenIndex(c.Settings.Security.APITokens, req.Name) >= 0 {
+		return c.HandleError(ctx, fmt.Errorf("token %s already exists", req.Name), "Token already exists", http.StatusConflict)
+	}
+
+	info := APITokenInfo{Name: req.Name, Role: req.Role, Scopes: scopes, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	oldSettings := *c.Settings
+	c.Settings.Security.APITokens = append(slices.Clone(c.Settings.Security.APITokens),
+		conf.APIToken{Name: info.Name, TokenHash: hash, Role: info.Role, Scopes: scopes, CreatedAt: info.CreatedAt})
+	if err := c.saveAuthSettings(ctx, &oldSettings); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusCreated, APITokenCreatedResponse{APITokenInfo: info, Token: token})
+}
+
+// DeleteAPIToken handles DELETE /api/v2/auth/tokens/:name
+func (c *Controller) DeleteAPIToken(ctx echo.Context) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	index := apiTokenIndex(c.Settings.Security.APITokens, ctx.Param("name"))
+	if index < 0 {
+		return c.HandleError(ctx, fmt.Errorf("token %s not found", ctx.Param("name")), "Token not found", http.StatusNotFound)
+	}
+
+	oldSettings := *c.Settings
+	c.Settings.Security.APITokens = slices.Delete(slices.Clone(c.Settings.Security.APITokens), index, index+1)
+	if err := c.saveAuthSettings(ctx, &oldSettings); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// saveAuthSettings saves changed users or API tokens and records the change in the
+// settings audit log. The caller must hold settingsMutex. If saving fails the settings
+// are restored to oldSettings.
+func (c *Controller) saveAuthSettings(ctx echo.Context, oldSettings *conf.Settings) error {
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			*c.Settings = *oldSettings
+			return err
+		}
+	}
+	c.recordSettingsChanges(oldSettings, c.Settings, settingsChangeActor(ctx), settingsChangeSourceAPI, ctx.RealIP())
+	return nil
+}
+
+// validateRole checks that role is a known user role
+func validateRole(role string) error {
+	if role != conf.RoleAdmin && role != conf.RoleViewer {
+		return fmt.Errorf("role must be %s or %s", conf.RoleAdmin, conf.RoleViewer)
+	}
+	return nil
+}
+
+// userAccountIndex returns the index of the user with the name, or -1
+func userAccountIndex(users []conf.UserAccount, username string) int {
+	return slices.IndexFunc(users, func(user conf.UserAccount) bool {
+		return strings.EqualFold(user.Username, username)
+	})
+}
+
+// apiTokenIndex returns the index of the API token with the name, or -1
+func apiTokenIndex(tokens []conf.APIToken, name string) int {
+	return slices.IndexFunc(tokens, func(token conf.APIToken) bool {
+		return token.Name == name
+	})
+}
@@ -0,0 +1,236 @@
+// auth_users_test.go: tests for user account and API token management and admin-only routes
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/security"
+)
+
+// newAuthUsersRequest returns a context for an auth management request with the path parameter
+func newAuthUsersRequest(e *echo.Echo, method, target, body, paramName, paramValue string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	if paramName != "" {
+		ctx.SetParamNames(paramName)
+		ctx.SetParamValues(paramValue)
+	}
+	return ctx, rec
+}
+
+func TestRequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	handler := auth.RequireAdmin(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{"no role set", "", http.StatusForbidden},
+		{"admin", conf.RoleAdmin, http.StatusOK},
+		{"viewer", conf.RoleViewer, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPut, "/api/v2/settings", http.NoBody)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			if tt.role != "" {
+				ctx.Set("role", tt.role)
+			}
+			require.NoError(t, handler(ctx))
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method    string
+		path      string
+		wantScope string
+		wantOK    bool
+	}{
+		{http.MethodGet, "/api/v2/detections", conf.ScopeDetectionsRead, true},
+		{http.MethodGet, "/api/v2/detections/42", conf.ScopeDetectionsRead, true},
+		{http.MethodDelete, "/api/v2/detections/42", conf.ScopeDetectionsWrite, true},
+		{http.MethodPost, "/api/v2/search", conf.ScopeDetectionsRead, true},
+		{http.MethodGet, "/api/v2/settings/birdnet", conf.ScopeSettingsRead, true},
+		{http.MethodPatch, "/api/v2/settings/birdnet", conf.ScopeSettingsWrite, true},
+		{http.MethodPost, "/api/v2/control/restart", conf.ScopeControlRestart, true},
+		{http.MethodGet, "/api/v2/system/info", conf.ScopeSystemRead, true},
+		{http.MethodPost, "/api/v2/system/update", "", false},
+		{http.MethodGet, "/api/v2/auth/tokens", "", false},
+		{http.MethodGet, "/api/v2/settingsbackup", "", false},
+		{http.MethodGet, "/detections", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			t.Parallel()
+			scope, ok := auth.RequiredScope(tt.method, tt.path)
+			assert.Equal(t, tt.wantScope, scope)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestCheckTokenScopes(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	newContext := func(method, target string) echo.Context {
+		return e.NewContext(httptest.NewRequest(method, target, http.NoBody), httptest.NewRecorder())
+	}
+
+	readOnly := []string{conf.ScopeDetectionsRead}
+	require.NoError(t, auth.CheckTokenScopes(newContext(http.MethodGet, "/api/v2/detections"), readOnly))
+	require.ErrorIs(t, auth.CheckTokenScopes(newContext(http.MethodPost, "/api/v2/detections/1/review"), readOnly), auth.ErrInsufficientScope)
+	require.ErrorIs(t, auth.CheckTokenScopes(newContext(http.MethodGet, "/api/v2/notifications"), readOnly), auth.ErrInsufficientScope)
+
+	// Tokens without scopes are limited only by their role
+	require.NoError(t, auth.CheckTokenScopes(newContext(http.MethodGet, "/api/v2/notifications"), nil))
+}
+
+func TestUserAccountManagement(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+
+	// Create
+	ctx, rec := newAuthUsersRequest(e, http.MethodPost, "/api/v2/auth/users",
+		`{"username":"guest","password":"viewer-password","role":"viewer"}`, "", "")
+	require.NoError(t, controller.CreateUserAccount(ctx))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Len(t, controller.Settings.Security.Users, 1)
+	firstHash := controller.Settings.Security.Users[0].PasswordHash
+	assert.True(t, strings.HasPrefix(firstHash, "$2"), "password is stored as bcrypt hash")
+
+	for _, body := range []string{
+		`{"username":"Guest","password":"viewer-password","role":"viewer"}`, // duplicate
+		`{"username":"other","password":"short","role":"viewer"}`,
+		`{"username":"other","password":"viewer-password","role":"owner"}`,
+		`{"username":" ","password":"viewer-password","role":"viewer"}`,
+	} {
+		ctx, rec = newAuthUsersRequest(e, http.MethodPost, "/api/v2/auth/users", body, "", "")
+		require.NoError(t, controller.CreateUserAccount(ctx))
+		assert.GreaterOrEqual(t, rec.Code, http.StatusBadRequest, body)
+	}
+	assert.Len(t, controller.Settings.Security.Users, 1)
+
+	// List does not expose the password hash
+	ctx, rec = newAuthUsersRequest(e, http.MethodGet, "/api/v2/auth/users", "", "", "")
+	require.NoError(t, controller.GetUserAccounts(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), firstHash)
+	assert.JSONEq(t, `{"users":[{"username":"guest","role":"viewer"}]}`, rec.Body.String())
+
+	// Update role only keeps the password
+	ctx, rec = newAuthUsersRequest(e, http.MethodPut, "/api/v2/auth/users/GUEST", `{"role":"admin"}`, "username", "GUEST")
+	require.NoError(t, controller.UpdateUserAccount(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, conf.RoleAdmin, controller.Settings.Security.Users[0].Role)
+	assert.Equal(t, firstHash, controller.Settings.Security.Users[0].PasswordHash)
+
+	// Update password
+	ctx, rec = newAuthUsersRequest(e, http.MethodPut, "/api/v2/auth/users/guest", `{"role":"admin","password":"new-password"}`, "username", "guest")
+	require.NoError(t, controller.UpdateUserAccount(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, firstHash, controller.Settings.Security.Users[0].PasswordHash)
+
+	// Delete
+	ctx, rec = newAuthUsersRequest(e, http.MethodDelete, "/api/v2/auth/users/guest", "", "username", "guest")
+	require.NoError(t, controller.DeleteUserAccount(ctx))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, controller.Settings.Security.Users)
+
+	ctx, rec = newAuthUsersRequest(e, http.MethodDelete, "/api/v2/auth/users/guest", "", "username", "guest")
+	require.NoError(t, controller.DeleteUserAccount(ctx))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPITokenManagement(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+
+	ctx, rec := newAuthUsersRequest(e, http.MethodPost, "/api/v2/auth/tokens", `{"name":"home-assistant","role":"viewer"}`, "", "")
+	require.NoError(t, controller.CreateAPIToken(ctx))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created APITokenCreatedResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "home-assistant", created.Name)
+	assert.Equal(t, conf.RoleViewer, created.Role)
+	require.NotEmpty(t, created.Token)
+
+	require.Len(t, controller.Settings.Security.APITokens, 1)
+	stored := controller.Settings.Security.APITokens[0]
+	assert.Equal(t, security.HashAPIToken(created.Token), stored.TokenHash, "only the hash of the token is stored")
+
+	// Duplicate name
+	ctx, rec = newAuthUsersRequest(e, http.MethodPost, "/api/v2/auth/tokens", `{"name":"home-assistant","role":"admin"}`, "", "")
+	require.NoError(t, controller.CreateAPIToken(ctx))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// List does not expose tokens or hashes
+	ctx, rec = newAuthUsersRequest(e, http.MethodGet, "/api/v2/auth/tokens", "", "", "")
+	require.NoError(t, controller.GetAPITokens(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), created.Token)
+	assert.NotContains(t, rec.Body.String(), stored.TokenHash)
+
+	// Scoped token
+	ctx, rec = newAuthUsersRequest(e, http.MethodPost, "/api/v2/auth/tokens",
+		`{"name":"node-red","role":"admin","scopes":["control:restart","detections:read","control:restart"]}`, "", "")
+	require.NoError(t, controller.CreateAPIToken(ctx))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Len(t, controller.Settings.Security.APITokens, 2)
+	assert.Equal(t, []string{conf.ScopeControlRestart, conf.ScopeDetectionsRead}, controller.Settings.Security.APITokens[1].Scopes)
+
+	for _, body := range []string{
+		`{"name":"script","role":"viewer","scopes":["settings:write"]}`, // write scope needs admin
+		`{"name":"script","role":"admin","scopes":["everything"]}`,
+	} {
+		ctx, rec = newAuthUsersRequest(e, http.MethodPost, "/api/v2/auth/tokens", body, "", "")
+		require.NoError(t, controller.CreateAPIToken(ctx))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+
+	ctx, rec = newAuthUsersRequest(e, http.MethodDelete, "/api/v2/auth/tokens/node-red", "", "name", "node-red")
+	require.NoError(t, controller.DeleteAPIToken(ctx))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	ctx, rec = newAuthUsersRequest(e, http.MethodDelete, "/api/v2/auth/tokens/home-assistant", "", "name", "home-assistant")
+	require.NoError(t, controller.DeleteAPIToken(ctx))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, controller.Settings.Security.APITokens)
+}
+
+func TestSettingsUpdateCannotReplaceUsers(t *testing.T) {
+	t.Parallel()
+
+	blocked, ok := getBlockedFieldMap()["Security"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, true, blocked["Users"])
+	assert.Equal(t, true, blocked["APITokens"])
+}
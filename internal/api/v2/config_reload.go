@@ -0,0 +1,164 @@
+// internal/api/v2/config_reload.go
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/telemetry"
+)
+
+// configReloadDebounce is the time to wait for further changes to the config file before reloading it.
+// Editors and SaveSettings write the file in several steps, each producing its own event.
+const configReloadDebounce = time.Second
+
+// ReloadConfig handles POST /api/v2/control/reload-config
+// Reloads the configuration file and applies the changed settings without restarting
+func (c *Controller) ReloadConfig(ctx echo.Context) error {
+	c.logAPIRequest(ctx, slog.LevelInfo, "Configuration reload requested")
+
+	if err := c.reloadConfigFile("api"); err != nil {
+		return c.HandleError(ctx, err, "Failed to reload configuration", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, ControlResult{
+		Success:   true,
+		Message:   "Configuration reloaded successfully",
+		Action:    ActionReloadConfig,
+		Timestamp: time.Now(),
+	})
+}
+
+// reloadConfigFile reads the configuration file and applies it to the current settings.
+// trigger describes what requested the reload and is only used for logging.
+func (c *Controller) reloadConfigFile(trigger string) error {
+	updated, err := conf.LoadFromFile()
+	if err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Error("Failed to read configuration file for reload", "trigger", trigger, "error", err)
+		}
+		return err
+	}
+
+	if err := c.applyReloadedSettings(updated); err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Error("Failed to apply reloaded configuration", "trigger", trigger, "error", err)
+		}
+		return err
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Configuration reloaded", "trigger", trigger)
+	}
+	return nil
+}
+
+// applyReloadedSettings copies the settings that may change at runtime from updated into the
+// current settings and triggers the reconfiguration of the components whose settings changed.
+// Runtime-only and protected fields keep their current values, the same as for settings updates
+// through the API. The current settings are restored when the changes cannot be applied.
+func (c *Controller) applyReloadedSettings(updated *conf.Settings) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	settings := c.Settings
+	if settings == nil {
+		settings = conf.Setting()
+	}
+
+	oldSettings := *settings
+
+	if _, err := updateAllowedSettingsWithTracking(settings, updated); err != nil {
+		*settings = oldSettings
+		return err
+	}
+
+	// Users and API tokens are blocked in the settings API but may be edited in the file
+	settings.Security.Users = updated.Security.Users
+	settings.Security.APITokens = updated.Security.APITokens
+
+	if err := c.handleSettingsChanges(&oldSettings, settings); err != nil {
+		*settings = oldSettings
+		return err
+	}
+
+	telemetry.UpdateTelemetryEnabled()
+
+	c.recordSettingsChanges(&oldSettings, settings, settingsChangeActorConfigFile, settingsChangeSourceReload, "")
+	return nil
+}
+
+// startConfigWatcher reloads the configuration when the config file changes or the process
+// receives SIGHUP, until ctx is cancelled
+func (c *Controller) startConfigWatcher(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// The file watcher is optional, SIGHUP and the API keep working without it
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	configPath, err := conf.FindConfigFile()
+	if err == nil {
+		configPath, err = filepath.Abs(configPath)
+	}
+	var watcher *fsnotify.Watcher
+	if err == nil {
+		watcher, err = fsnotify.NewWatcher()
+	}
+	if err == nil {
+		defer func() { _ = watcher.Close() }()
+		// Watch the directory, the file itself is replaced when it is saved
+		err = watcher.Add(filepath.Dir(configPath))
+	}
+	if err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Warn("Config file watcher not started, reload with SIGHUP or the API instead", "error", err)
+		}
+	} else {
+		events = watcher.Events
+		watchErrors = watcher.Errors
+		if c.apiLogger != nil {
+			c.apiLogger.Info("Watching configuration file for changes", "path", configPath)
+		}
+	}
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) != configPath || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			reload = time.After(configReloadDebounce)
+		case err, ok := <-watchErrors:
+			if !ok {
+				watchErrors = nil
+				continue
+			}
+			if c.apiLogger != nil {
+				c.apiLogger.Warn("Config file watcher error", "error", err)
+			}
+		case <-reload:
+			reload = nil
+			_ = c.reloadConfigFile("file")
+		case <-hup:
+			_ = c.reloadConfigFile("signal")
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyReloadedSettings verifies reloaded settings are applied and trigger reconfiguration
+func TestApplyReloadedSettings(t *testing.T) {
+	settings := getTestSettings(t)
+	settings.Version = "1.2.3"
+	settings.BirdNET.RangeFilter.Species = []string{"Turdus merula"}
+
+	controller := &Controller{
+		Echo:        echo.New(),
+		Settings:    settings,
+		controlChan: make(chan string, 10),
+	}
+
+	// Settings read from the file do not carry runtime fields
+	updated := *settings
+	updated.Version = ""
+	updated.BirdNET.RangeFilter.Species = nil
+	updated.BirdNET.Threshold = 0.6
+	updated.Realtime.MQTT.Enabled = true
+
+	require.NoError(t, controller.applyReloadedSettings(&updated))
+
+	assert.InDelta(t, 0.6, settings.BirdNET.Threshold, 1e-9)
+	assert.True(t, settings.Realtime.MQTT.Enabled)
+	assert.Equal(t, "1.2.3", settings.Version, "runtime fields should be preserved")
+	assert.Equal(t, []string{"Turdus merula"}, settings.BirdNET.RangeFilter.Species, "runtime fields should be preserved")
+
+	select {
+	case signal := <-controller.controlChan:
+		assert.Equal(t, "reconfigure_mqtt", signal)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected MQTT reconfiguration signal")
+	}
+}
+
+// TestApplyReloadedSettingsUnchanged verifies reloading an unchanged file triggers no reconfiguration
+func TestApplyReloadedSettingsUnchanged(t *testing.T) {
+	settings := getTestSettings(t)
+
+	controller := &Controller{
+		Echo:        echo.New(),
+		Settings:    settings,
+		controlChan: make(chan string, 10),
+	}
+
+	updated := *settings
+	require.NoError(t, controller.applyReloadedSettings(&updated))
+
+	select {
+	case signal := <-controller.controlChan:
+		t.Fatalf("unexpected control signal %q", signal)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
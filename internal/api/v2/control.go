@@ -2,11 +2,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 )
 
 // ControlAction represents a control action request
@@ -23,11 +26,43 @@ type ControlResult struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// defaultDrainTimeout is used when no drain timeout is configured
+const defaultDrainTimeout = 20 * time.Second
+
+// DrainResult is returned by the drain endpoint
+type DrainResult struct {
+	Drained     bool      `json:"drained"`
+	Outstanding int       `json:"outstanding"`
+	DurationMs  int64     `json:"duration_ms"`
+	Message     string    `json:"message"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// DynamicThresholdsResponse is returned by the dynamic thresholds endpoint
+type DynamicThresholdsResponse struct {
+	Enabled    bool                               `json:"enabled"`
+	Thresholds []processor.DynamicThresholdStatus `json:"thresholds"`
+	Timestamp  time.Time                          `json:"timestamp"`
+}
+
+// CommandGuardResponse is returned by the command guard endpoint
+type CommandGuardResponse struct {
+	Enabled   bool                           `json:"enabled"`
+	Commands  []processor.CommandGuardStatus `json:"commands"`
+	Timestamp time.Time                      `json:"timestamp"`
+}
+
+// EnableCommandRequest names a command to re-enable
+type EnableCommandRequest struct {
+	Command string `json:"command"`
+}
+
 // Available control actions
 const (
 	ActionRestartAnalysis = "restart_analysis"
 	ActionReloadModel     = "reload_model"
 	ActionRebuildFilter   = "rebuild_filter"
+	ActionReloadConfig    = "reload_config"
 )
 
 // Control channel signals
@@ -43,15 +78,28 @@ func (c *Controller) initControlRoutes() {
 		c.apiLogger.Info("Initializing control routes")
 	}
 
-	// Create control API group with auth middleware
-	controlGroup := c.Group.Group("/control", c.AuthMiddleware)
+	// Create control API group with auth middleware, admin only
+	controlGroup := c.Group.Group("/control", c.AuthMiddleware, auth.RequireAdmin)
 
 	// Control routes
 	controlGroup.POST("/restart", c.RestartAnalysis)
 	controlGroup.POST("/reload", c.ReloadModel)
 	controlGroup.POST("/rebuild-filter", c.RebuildFilter)
+	controlGroup.POST("/reload-config", c.ReloadConfig)
+	controlGroup.POST("/drain", c.DrainAnalysis)
+	controlGroup.GET("/queue", c.GetQueueStatus)
+	controlGroup.GET("/dynamic-thresholds", c.GetDynamicThresholds)
+	controlGroup.GET("/commands", c.GetCommandGuardStatus)
+	controlGroup.POST("/commands/enable", c.EnableCommand)
 	controlGroup.GET("/actions", c.GetAvailableActions)
 
+	// Reload the configuration when the config file changes or on SIGHUP
+	if c.ctx != nil {
+		c.wg.Go(func() {
+			c.startConfigWatcher(c.ctx)
+		})
+	}
+
 	if c.apiLogger != nil {
 		c.apiLogger.Info("Control routes initialized successfully")
 	}
@@ -80,6 +128,10 @@ func (c *Controller) GetAvailableActions(ctx echo.Context) error {
 			Action:      ActionRebuildFilter,
 			Description: "Rebuild the species filter based on current location",
 		},
+		{
+			Action:      ActionReloadConfig,
+			Description: "Reload settings from the configuration file",
+		},
 	}
 
 	if c.apiLogger != nil {
@@ -182,3 +234,137 @@ func (c *Controller) RebuildFilter(ctx echo.Context) error {
 	return c.handleControlSignal(ctx, SignalRebuildFilter, ActionRebuildFilter,
 		"Received request to rebuild species filter", "Filter rebuild signal sent")
 }
+
+// DrainAnalysis handles POST /api/v2/control/drain
+// Stops accepting new detections and waits until queued actions have finished, up to the
+// configured drain timeout. Intended as a Kubernetes preStop hook so in-flight database
+// saves and uploads complete before the container receives SIGTERM. With wait=false the
+// drain is only started and its progress can be followed with GET /control/queue.
+func (c *Controller) DrainAnalysis(ctx echo.Context) error {
+	if c.Processor == nil {
+		return c.HandleError(ctx, nil, "Processor not available", http.StatusServiceUnavailable)
+	}
+
+	if ctx.QueryParam("wait") == "false" {
+		c.Processor.StartDrain()
+		if c.apiLogger != nil {
+			c.apiLogger.Info("Drain started",
+				"ip", ctx.RealIP(),
+				"path", ctx.Request().URL.Path,
+			)
+		}
+		return ctx.JSON(http.StatusAccepted, c.Processor.QueueStatus())
+	}
+
+	timeout := time.Duration(c.Settings.Realtime.Drain.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Drain requested",
+			"timeout_seconds", timeout.Seconds(),
+			"ip", ctx.RealIP(),
+			"path", ctx.Request().URL.Path,
+		)
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx.Request().Context(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Processor.Drain(drainCtx)
+	result := DrainResult{
+		Drained:     err == nil,
+		Outstanding: c.Processor.OutstandingWork(),
+		DurationMs:  time.Since(start).Milliseconds(),
+		Message:     "Queued actions finished",
+		Timestamp:   time.Now(),
+	}
+
+	if err != nil {
+		result.Message = "Drain timed out with queued actions remaining"
+		if c.apiLogger != nil {
+			c.apiLogger.Warn("Drain timed out",
+				"outstanding", result.Outstanding,
+				"error", err.Error(),
+			)
+		}
+		return ctx.JSON(http.StatusGatewayTimeout, result)
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetQueueStatus handles GET /api/v2/control/queue
+// Reports held detections and in-flight and queued actions per action type, and whether a
+// drain has finished so the service can be stopped safely.
+func (c *Controller) GetQueueStatus(ctx echo.Context) error {
+	if c.Processor == nil {
+		return c.HandleError(ctx, nil, "Processor not available", http.StatusServiceUnavailable)
+	}
+
+	return ctx.JSON(http.StatusOK, c.Processor.QueueStatus())
+}
+
+// GetDynamicThresholds handles GET /api/v2/control/dynamic-thresholds
+// Lists the current dynamic threshold of each species with its level, expiry and recent
+// adjustments, for debugging threshold behavior.
+func (c *Controller) GetDynamicThresholds(ctx echo.Context) error {
+	if c.Processor == nil {
+		return c.HandleError(ctx, nil, "Processor not available", http.StatusServiceUnavailable)
+	}
+
+	return ctx.JSON(http.StatusOK, DynamicThresholdsResponse{
+		Enabled:    c.Settings.Realtime.DynamicThreshold.Enabled,
+		Thresholds: c.Processor.DynamicThresholdStatus(),
+		Timestamp:  time.Now(),
+	})
+}
+
+// GetCommandGuardStatus handles GET /api/v2/control/commands
+// Returns the run and rejection counters of the commands run by ExecuteCommand actions
+func (c *Controller) GetCommandGuardStatus(ctx echo.Context) error {
+	if c.Processor == nil {
+		return c.HandleError(ctx, nil, "Processor not available", http.StatusServiceUnavailable)
+	}
+
+	return ctx.JSON(http.StatusOK, CommandGuardResponse{
+		Enabled:   c.Settings.Realtime.CommandGuard.Enabled,
+		Commands:  c.Processor.CommandGuardStatus(),
+		Timestamp: time.Now(),
+	})
+}
+
+// EnableCommand handles POST /api/v2/control/commands/enable
+// Re-enables a command disabled for repeatedly exceeding its rate limits
+func (c *Controller) EnableCommand(ctx echo.Context) error {
+	if c.Processor == nil {
+		return c.HandleError(ctx, nil, "Processor not available", http.StatusServiceUnavailable)
+	}
+
+	var req EnableCommandRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if req.Command == "" {
+		return c.HandleError(ctx, nil, "Command is required", http.StatusBadRequest)
+	}
+
+	if !c.Processor.EnableCommand(req.Command) {
+		return c.HandleError(ctx, nil, "Command not found", http.StatusNotFound)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Command re-enabled",
+			"command", req.Command,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, ControlResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Command %s enabled", req.Command),
+		Action:    "enable_command",
+		Timestamp: time.Now(),
+	})
+}
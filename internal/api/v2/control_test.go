@@ -22,6 +22,8 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
 )
 
 // runControlEndpointTest runs a control endpoint test with the given parameters
@@ -200,10 +202,10 @@ func TestGetAvailableActions(t *testing.T) {
 		require.NoError(t, err)
 
 		// Check response content
-		require.Len(t, actions, 3, "Should have 3 control actions")
+		require.Len(t, actions, 4, "Should have 4 control actions")
 
 		// Verify actions include all expected types
-		var hasRestartAction, hasReloadAction, hasRebuildFilterAction bool
+		var hasRestartAction, hasReloadAction, hasRebuildFilterAction, hasReloadConfigAction bool
 		for _, action := range actions {
 			switch action.Action {
 			case ActionRestartAnalysis:
@@ -215,6 +217,9 @@ func TestGetAvailableActions(t *testing.T) {
 			case ActionRebuildFilter:
 				hasRebuildFilterAction = true
 				assert.Contains(t, action.Description, "Rebuild")
+			case ActionReloadConfig:
+				hasReloadConfigAction = true
+				assert.Contains(t, action.Description, "configuration file")
 			}
 		}
 
@@ -222,6 +227,7 @@ func TestGetAvailableActions(t *testing.T) {
 		assert.True(t, hasRestartAction, "Missing restart_analysis action")
 		assert.True(t, hasReloadAction, "Missing reload_model action")
 		assert.True(t, hasRebuildFilterAction, "Missing rebuild_filter action")
+		assert.True(t, hasReloadConfigAction, "Missing reload_config action")
 	}
 }
 
@@ -325,10 +331,14 @@ func TestInitControlRoutesRegistration(t *testing.T) {
 
 	// Define the control routes we expect to find
 	expectedRoutes := map[string]bool{
-		"GET /api/v2/control/actions":         false,
-		"POST /api/v2/control/restart":        false,
-		"POST /api/v2/control/reload":         false,
-		"POST /api/v2/control/rebuild-filter": false,
+		"GET /api/v2/control/actions":            false,
+		"POST /api/v2/control/restart":           false,
+		"POST /api/v2/control/reload":            false,
+		"POST /api/v2/control/rebuild-filter":    false,
+		"POST /api/v2/control/reload-config":     false,
+		"POST /api/v2/control/drain":             false,
+		"GET /api/v2/control/queue":              false,
+		"GET /api/v2/control/dynamic-thresholds": false,
 	}
 
 	// Check each route
@@ -716,3 +726,115 @@ func TestInvalidPayloads_Advanced(t *testing.T) {
 		assert.Fail(t, "Control signal was not sent")
 	}
 }
+
+// TestDrainAnalysis tests that the drain endpoint stops intake and reports completion
+func TestDrainAnalysis(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.Settings.Realtime.Drain.Timeout = 1
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/control/drain", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.DrainAnalysis(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "drain requires a processor")
+
+	controller.Processor = &processor.Processor{JobQueue: jobqueue.NewJobQueue()}
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.DrainAnalysis(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result DrainResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.True(t, result.Drained)
+	assert.Zero(t, result.Outstanding)
+	assert.True(t, controller.Processor.Draining())
+}
+
+// TestQueueStatusAndNonBlockingDrain tests the queue status endpoint and starting a drain without waiting
+func TestQueueStatusAndNonBlockingDrain(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/control/queue", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetQueueStatus(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "queue status requires a processor")
+
+	controller.Processor = &processor.Processor{JobQueue: jobqueue.NewJobQueue()}
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetQueueStatus(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var status processor.QueueStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.False(t, status.Draining)
+	assert.Empty(t, status.Actions)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v2/control/drain?wait=false", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.DrainAnalysis(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.True(t, status.Draining)
+	assert.True(t, status.Drained, "nothing is queued")
+}
+
+// TestGetDynamicThresholds tests listing the dynamic thresholds of the processor
+func TestGetDynamicThresholds(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.Settings.Realtime.DynamicThreshold.Enabled = true
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/control/dynamic-thresholds", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetDynamicThresholds(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "dynamic thresholds require a processor")
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	controller.Processor = &processor.Processor{
+		DynamicThresholds: map[string]*processor.DynamicThreshold{
+			"great tit": {Level: 1, CurrentValue: 0.6, Timer: expires, HighConfCount: 1, ValidHours: 24,
+				History: []processor.ThresholdAdjustment{{Time: expires, Level: 1, Value: 0.6, Reason: processor.ThresholdAdjustHighConfidence}}},
+			"eurasian blackbird": {CurrentValue: 0.8, Timer: expires, ValidHours: 24},
+		},
+	}
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetDynamicThresholds(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response DynamicThresholdsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.Enabled)
+	require.Len(t, response.Thresholds, 2)
+	assert.Equal(t, "eurasian blackbird", response.Thresholds[0].Species, "sorted by species")
+	greatTit := response.Thresholds[1]
+	assert.Equal(t, 1, greatTit.Level)
+	assert.InDelta(t, 0.6, greatTit.CurrentValue, 1e-9)
+	assert.True(t, expires.Equal(greatTit.ExpiresAt))
+	require.Len(t, greatTit.History, 1)
+	assert.Equal(t, processor.ThresholdAdjustHighConfidence, greatTit.History[0].Reason)
+}
+
+// TestCommandGuardEndpoints tests listing and re-enabling the commands of ExecuteCommand actions
+func TestCommandGuardEndpoints(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.Settings.Realtime.CommandGuard.Enabled = true
+
+	rec := deploymentRequest(t, e, controller.GetCommandGuardStatus, http.MethodGet, "/api/v2/control/commands", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "command status requires a processor")
+
+	controller.Processor = &processor.Processor{}
+
+	rec = deploymentRequest(t, e, controller.GetCommandGuardStatus, http.MethodGet, "/api/v2/control/commands", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response CommandGuardResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.Enabled)
+	assert.Empty(t, response.Commands)
+
+	rec = deploymentRequest(t, e, controller.EnableCommand, http.MethodPost, "/api/v2/control/commands/enable", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = deploymentRequest(t, e, controller.EnableCommand, http.MethodPost, "/api/v2/control/commands/enable",
+		`{"command":"/usr/local/bin/notify.sh"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "the command has never run")
+}
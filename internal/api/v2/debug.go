@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/eventsink"
 	"github.com/tphakala/birdnet-go/internal/notification"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
 )
@@ -38,10 +40,11 @@ type DebugResponse struct {
 
 // DebugSystemStatus represents the current system status for debugging
 type DebugSystemStatus struct {
-	Timestamp     string         `json:"timestamp"`
-	Debug         bool           `json:"debug"`
-	Telemetry     map[string]any `json:"telemetry,omitempty"`
-	Notifications map[string]any `json:"notifications,omitempty"`
+	Timestamp     string           `json:"timestamp"`
+	Debug         bool             `json:"debug"`
+	Telemetry     map[string]any   `json:"telemetry,omitempty"`
+	Notifications map[string]any   `json:"notifications,omitempty"`
+	EventSink     *eventsink.Stats `json:"eventSink,omitempty"` // events forwarded to a message broker
 }
 
 // initDebugRoutes registers debug-related routes
@@ -53,7 +56,7 @@ func (c *Controller) initDebugRoutes() {
 	}
 
 	// Debug endpoints require authentication
-	debugGroup := c.Group.Group("/debug", c.getEffectiveAuthMiddleware())
+	debugGroup := c.Group.Group("/debug", c.getEffectiveAuthMiddleware(), auth.RequireAdmin)
 	
 	debugGroup.POST("/trigger-error", c.DebugTriggerError)
 	debugGroup.POST("/trigger-notification", c.DebugTriggerNotification)
@@ -223,6 +226,11 @@ func (c *Controller) DebugSystemStatus(ctx echo.Context) error {
 		}
 	}
 
+	// Get event forwarding status
+	if stats, ok := eventsink.GetStats(); ok {
+		status.EventSink = &stats
+	}
+
 	return ctx.JSON(http.StatusOK, status)
 }
 
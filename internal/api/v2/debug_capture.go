@@ -0,0 +1,92 @@
+// internal/api/v2/debug_capture.go
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// defaultDebugCaptureMinutes is the session length when the request does not specify one
+const defaultDebugCaptureMinutes = 15
+
+// DebugCaptureRequest is the request body for starting a debug capture session
+type DebugCaptureRequest struct {
+	Minutes int `json:"minutes"` // session length, defaults to 15 minutes
+}
+
+// DebugCaptureStatus lists the components supporting debug capture and the active sessions
+type DebugCaptureStatus struct {
+	Components []string               `json:"components"`
+	Sessions   []debugcapture.Session `json:"sessions"`
+	MaxMinutes int                    `json:"maxMinutes"`
+}
+
+// GetDebugCaptureStatus handles GET /api/v2/system/debug-capture
+func (c *Controller) GetDebugCaptureStatus(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, DebugCaptureStatus{
+		Components: debugcapture.Components(),
+		Sessions:   debugcapture.Sessions(),
+		MaxMinutes: int(debugcapture.MaxDuration / time.Minute),
+	})
+}
+
+// StartDebugCapture handles POST /api/v2/system/debug-capture/:component
+// Raises the log level of the component to debug and enables its debug file dumps for the
+// requested number of minutes, after which both revert automatically. Starting a session
+// for a component that already has one extends it.
+func (c *Controller) StartDebugCapture(ctx echo.Context) error {
+	component := ctx.Param("component")
+
+	var req DebugCaptureRequest
+	if ctx.Request().ContentLength != 0 {
+		if err := ctx.Bind(&req); err != nil {
+			return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+		}
+	}
+	if req.Minutes == 0 {
+		req.Minutes = defaultDebugCaptureMinutes
+	}
+
+	session, err := debugcapture.Enable(component, time.Duration(req.Minutes)*time.Minute)
+	if err != nil {
+		code := http.StatusBadRequest
+		var enhanced *errors.EnhancedError
+		if errors.As(err, &enhanced) && enhanced.Category == errors.CategoryNotFound {
+			code = http.StatusNotFound
+		}
+		return c.HandleError(ctx, err, "Failed to start debug capture", code)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Debug capture started",
+			"component", component,
+			"minutes", req.Minutes,
+			"expires_at", session.ExpiresAt.Format(time.RFC3339),
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, session)
+}
+
+// StopDebugCapture handles DELETE /api/v2/system/debug-capture/:component
+func (c *Controller) StopDebugCapture(ctx echo.Context) error {
+	component := ctx.Param("component")
+
+	if !debugcapture.Disable(component) {
+		return c.HandleError(ctx, nil, "No active debug capture for component", http.StatusNotFound)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Debug capture stopped",
+			"component", component,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
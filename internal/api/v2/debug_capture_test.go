@@ -0,0 +1,61 @@
+// debug_capture_test.go: tests for the time-boxed debug capture endpoints
+
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
+)
+
+func TestDebugCaptureEndpoints(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	debugcapture.Register("api-test", new(slog.LevelVar))
+
+	call := func(method, component, body string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v2/system/debug-capture/"+component, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("component")
+		ctx.SetParamValues(component)
+		require.NoError(t, handler(ctx))
+		return rec
+	}
+
+	rec := call(http.MethodPost, "api-test", `{"minutes": 5}`, controller.StartDebugCapture)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var session debugcapture.Session
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &session))
+	assert.Equal(t, "api-test", session.Component)
+	assert.True(t, debugcapture.Active("api-test"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/system/debug-capture", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetDebugCaptureStatus(e.NewContext(req, rec)))
+	var status DebugCaptureStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Contains(t, status.Components, "api-test")
+	require.NotEmpty(t, status.Sessions)
+
+	rec = call(http.MethodPost, "api-test", `{"minutes": 1000}`, controller.StartDebugCapture)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "sessions longer than the maximum are rejected")
+
+	rec = call(http.MethodPost, "no-such-component", "", controller.StartDebugCapture)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = call(http.MethodDelete, "api-test", "", controller.StopDebugCapture)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.False(t, debugcapture.Active("api-test"))
+
+	rec = call(http.MethodDelete, "api-test", "", controller.StopDebugCapture)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
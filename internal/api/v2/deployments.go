@@ -0,0 +1,209 @@
+// internal/api/v2/deployments.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// maxDeploymentFieldLength limits the free-form text fields of a deployment
+const maxDeploymentFieldLength = 200
+
+// ErrDeploymentsNotAvailable is returned when the datastore does not record deployments
+var ErrDeploymentsNotAvailable = errors.NewStd("deployment history not available")
+
+// DeploymentRequest starts a new deployment at the current station location
+type DeploymentRequest struct {
+	Name        string `json:"name"`
+	Microphone  string `json:"microphone"`
+	Gain        string `json:"gain"`
+	Placement   string `json:"placement"`
+	Description string `json:"description"`
+}
+
+// DeploymentInfo describes a period the station ran at one location with the same equipment
+type DeploymentInfo struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	Microphone  string     `json:"microphone,omitempty"`
+	Gain        string     `json:"gain,omitempty"`
+	Placement   string     `json:"placement,omitempty"`
+	StartedAt   time.Time  `json:"startedAt"`
+	EndedAt     *time.Time `json:"endedAt,omitempty"` // nil for the current deployment
+	Description string     `json:"description,omitempty"`
+}
+
+// initDeploymentRoutes registers the deployment endpoints
+func (c *Controller) initDeploymentRoutes() {
+	deploymentGroup := c.Group.Group("/deployments", c.getEffectiveAuthMiddleware())
+	deploymentGroup.GET("", c.GetDeployments)
+	deploymentGroup.GET("/current", c.GetCurrentDeployment)
+	deploymentGroup.POST("", c.StartDeployment, auth.RequireAdmin)
+	deploymentGroup.POST("/end", c.EndDeployment, auth.RequireAdmin)
+}
+
+// trim removes surrounding white space from the fields of the request
+func (r *DeploymentRequest) trim() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Microphone = strings.TrimSpace(r.Microphone)
+	r.Gain = strings.TrimSpace(r.Gain)
+	r.Placement = strings.TrimSpace(r.Placement)
+	r.Description = strings.TrimSpace(r.Description)
+}
+
+// Validate checks the length of the request fields
+func (r *DeploymentRequest) Validate() error {
+	for field, value := range map[string]string{
+		"name":       r.Name,
+		"microphone": r.Microphone,
+		"gain":       r.Gain,
+		"placement":  r.Placement,
+	} {
+		if len(value) > maxDeploymentFieldLength {
+			return fmt.Errorf("%s must be at most %d characters", field, maxDeploymentFieldLength)
+		}
+	}
+	return nil
+}
+
+// StartDeployment handles POST /api/v2/deployments
+// Starts a new deployment at the current station location, ending the current one. Use it
+// when the microphone, gain or placement changes; moving the station is done through
+// POST /api/v2/settings/location.
+func (c *Controller) StartDeployment(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.DeploymentStore)
+	if !ok {
+		return c.HandleError(ctx, ErrDeploymentsNotAvailable, "Deployment history unavailable", http.StatusServiceUnavailable)
+	}
+
+	var req DeploymentRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	req.trim()
+	if err := req.Validate(); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	c.settingsMutex.RLock()
+	latitude, longitude := c.Settings.BirdNET.Latitude, c.Settings.BirdNET.Longitude
+	locationName := c.Settings.BirdNET.DeploymentID
+	c.settingsMutex.RUnlock()
+
+	next := &datastore.Deployment{
+		Name:        req.Name,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Microphone:  req.Microphone,
+		Gain:        req.Gain,
+		Placement:   req.Placement,
+		StartedAt:   time.Now(),
+		Description: req.Description,
+	}
+	previous := &datastore.Deployment{Name: locationName, Latitude: latitude, Longitude: longitude}
+	if err := store.StartDeployment(previous, next); err != nil {
+		return c.HandleError(ctx, err, "Failed to start deployment", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Deployment started",
+			"deployment_id", next.ID,
+			"name", next.Name,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusCreated, deploymentInfo(next))
+}
+
+// EndDeployment handles POST /api/v2/deployments/end
+// Ends the current deployment, for example when the station is taken down. Detections made
+// afterwards reference no deployment until a new one is started.
+func (c *Controller) EndDeployment(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.DeploymentStore)
+	if !ok {
+		return c.HandleError(ctx, ErrDeploymentsNotAvailable, "Deployment history unavailable", http.StatusServiceUnavailable)
+	}
+
+	ended, err := store.EndDeployment(time.Now())
+	if err != nil {
+		var enhanced *errors.EnhancedError
+		if errors.As(err, &enhanced) && enhanced.Category == errors.CategoryNotFound {
+			return c.HandleError(ctx, err, "No deployment is active", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to end deployment", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Deployment ended",
+			"deployment_id", ended.ID,
+			"name", ended.Name,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, deploymentInfo(ended))
+}
+
+// GetCurrentDeployment handles GET /api/v2/deployments/current
+func (c *Controller) GetCurrentDeployment(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.DeploymentStore)
+	if !ok {
+		return c.HandleError(ctx, ErrDeploymentsNotAvailable, "Deployment history unavailable", http.StatusServiceUnavailable)
+	}
+
+	current, err := store.GetCurrentDeployment()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get current deployment", http.StatusInternalServerError)
+	}
+	if current == nil {
+		return c.HandleError(ctx, fmt.Errorf("no current deployment"), "No deployment is active", http.StatusNotFound)
+	}
+
+	return ctx.JSON(http.StatusOK, deploymentInfo(current))
+}
+
+// GetDeployments handles GET /api/v2/deployments
+// Lists the deployments of the station, the current deployment first.
+func (c *Controller) GetDeployments(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.DeploymentStore)
+	if !ok {
+		return c.HandleError(ctx, ErrDeploymentsNotAvailable, "Deployment history unavailable", http.StatusServiceUnavailable)
+	}
+
+	deployments, err := store.GetDeployments()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get deployments", http.StatusInternalServerError)
+	}
+
+	result := make([]DeploymentInfo, 0, len(deployments))
+	for i := range deployments {
+		result = append(result, deploymentInfo(&deployments[i]))
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"deployments": result,
+	})
+}
+
+// deploymentInfo converts a stored deployment to its API representation
+func deploymentInfo(deployment *datastore.Deployment) DeploymentInfo {
+	return DeploymentInfo{
+		ID:          deployment.ID,
+		Name:        deployment.Name,
+		Latitude:    deployment.Latitude,
+		Longitude:   deployment.Longitude,
+		Microphone:  deployment.Microphone,
+		Gain:        deployment.Gain,
+		Placement:   deployment.Placement,
+		StartedAt:   deployment.StartedAt,
+		EndedAt:     deployment.EndedAt,
+		Description: deployment.Description,
+	}
+}
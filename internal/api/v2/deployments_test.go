@@ -0,0 +1,133 @@
+// deployments_test.go: tests for the deployment endpoints
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// mockDeploymentStore adds the optional deployment capability to MockDataStore
+type mockDeploymentStore struct {
+	*MockDataStore
+	deployments []datastore.Deployment // oldest first
+}
+
+func (m *mockDeploymentStore) StartDeployment(previous, next *datastore.Deployment) error {
+	if len(m.deployments) == 0 && previous != nil {
+		endedAt := next.StartedAt
+		previous.ID = 1
+		previous.EndedAt = &endedAt
+		m.deployments = append(m.deployments, *previous)
+	} else if current := m.current(); current != nil {
+		endedAt := next.StartedAt
+		current.EndedAt = &endedAt
+	}
+	next.ID = uint(len(m.deployments) + 1)
+	m.deployments = append(m.deployments, *next)
+	return nil
+}
+
+func (m *mockDeploymentStore) EndDeployment(endedAt time.Time) (*datastore.Deployment, error) {
+	current := m.current()
+	if current == nil {
+		return nil, errors.Newf("deployment not found").Category(errors.CategoryNotFound).Build()
+	}
+	current.EndedAt = &endedAt
+	ended := *current
+	return &ended, nil
+}
+
+func (m *mockDeploymentStore) GetCurrentDeployment() (*datastore.Deployment, error) {
+	if current := m.current(); current != nil {
+		deployment := *current
+		return &deployment, nil
+	}
+	return nil, nil
+}
+
+func (m *mockDeploymentStore) GetDeployments() ([]datastore.Deployment, error) {
+	return m.deployments, nil
+}
+
+func (m *mockDeploymentStore) current() *datastore.Deployment {
+	for i := range m.deployments {
+		if m.deployments[i].EndedAt == nil {
+			return &m.deployments[i]
+		}
+	}
+	return nil
+}
+
+// deploymentRequest runs a deployment handler with a JSON body
+func deploymentRequest(t *testing.T, e *echo.Echo, handler echo.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	return rec
+}
+
+func TestDeploymentEndpoints(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.Settings.BirdNET.Latitude = 60.1
+	controller.Settings.BirdNET.Longitude = 24.9
+
+	// Plain datastore without deployment support
+	rec := deploymentRequest(t, e, controller.GetDeployments, http.MethodGet, "/api/v2/deployments", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	store := &mockDeploymentStore{MockDataStore: mockDS}
+	controller.DS = store
+
+	rec = deploymentRequest(t, e, controller.GetCurrentDeployment, http.MethodGet, "/api/v2/deployments/current", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = deploymentRequest(t, e, controller.StartDeployment, http.MethodPost, "/api/v2/deployments",
+		`{"microphone":"`+strings.Repeat("x", maxDeploymentFieldLength+1)+`"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = deploymentRequest(t, e, controller.StartDeployment, http.MethodPost, "/api/v2/deployments",
+		`{"name":" garden ","microphone":"Clippy EM272","gain":"preamp +20 dB","placement":"2 m, facing north"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var started DeploymentInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+	assert.Equal(t, "garden", started.Name)
+	assert.Equal(t, "Clippy EM272", started.Microphone)
+	assert.InDelta(t, 60.1, started.Latitude, 1e-9, "deployment starts at the station location")
+
+	rec = deploymentRequest(t, e, controller.GetCurrentDeployment, http.MethodGet, "/api/v2/deployments/current", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var current DeploymentInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &current))
+	assert.Equal(t, started.ID, current.ID)
+
+	rec = deploymentRequest(t, e, controller.EndDeployment, http.MethodPost, "/api/v2/deployments/end", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var ended DeploymentInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ended))
+	assert.Equal(t, started.ID, ended.ID)
+	assert.NotNil(t, ended.EndedAt)
+
+	rec = deploymentRequest(t, e, controller.EndDeployment, http.MethodPost, "/api/v2/deployments/end", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "no deployment is active")
+
+	rec = deploymentRequest(t, e, controller.GetDeployments, http.MethodGet, "/api/v2/deployments", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Deployments []DeploymentInfo `json:"deployments"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list.Deployments, 2, "the station before the first deployment is recorded too")
+}
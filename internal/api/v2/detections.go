@@ -2,6 +2,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,9 +14,12 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/patrickmn/go-cache"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/observation"
 	"github.com/tphakala/birdnet-go/internal/suncalc"
 )
 
@@ -59,16 +63,54 @@ func (c *Controller) initDetectionRoutes() {
 	// To get weather information for a specific detection, use the
 	// /api/v2/weather/detection/:id endpoint after fetching the detection.
 	c.Group.GET("/detections", c.GetDetections)
-	c.Group.GET("/detections/:id", c.GetDetection)
+	c.Group.GET("/detections/:id", c.GetDetection, c.resolveNoteID)
 	c.Group.GET("/detections/recent", c.GetRecentDetections)
-	c.Group.GET("/detections/:id/time-of-day", c.GetDetectionTimeOfDay)
-
-	// Protected detection management endpoints
-	detectionGroup := c.Group.Group("/detections", c.AuthMiddleware)
-	detectionGroup.DELETE("/:id", c.DeleteDetection)
-	detectionGroup.POST("/:id/review", c.ReviewDetection)
-	detectionGroup.POST("/:id/lock", c.LockDetection)
+	c.Group.GET("/detections/geojson", c.GetDetectionsGeoJSON)
+	c.Group.GET("/detections/:id/time-of-day", c.GetDetectionTimeOfDay, c.resolveNoteID)
+
+	// Protected detection management endpoints, admin only
+	detectionGroup := c.Group.Group("/detections", c.AuthMiddleware, auth.RequireAdmin)
+	detectionGroup.DELETE("/:id", c.DeleteDetection, c.resolveNoteID)
+	detectionGroup.POST("/:id/review", c.ReviewDetection, c.resolveNoteID)
+	detectionGroup.POST("/:id/lock", c.LockDetection, c.resolveNoteID)
 	detectionGroup.POST("/ignore", c.IgnoreSpecies)
+	detectionGroup.GET("/review-queue", c.GetReviewQueue)
+	detectionGroup.GET("/trash", c.GetTrashedDetections)
+	detectionGroup.POST("/:id/restore", c.RestoreDetection, c.resolveNoteID)
+}
+
+// provisionalIDTimeout is how long a request with a provisional note ID waits for the note
+// to be saved
+const provisionalIDTimeout = 30 * time.Second
+
+// resolveNoteID replaces a provisional note ID in the id path parameter with the database ID.
+// Live detections are broadcast with a provisional ID while the note is saved in the
+// background, a request arriving before the save completes waits for it.
+func (c *Controller) resolveNoteID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		waitCtx, cancel := context.WithTimeout(ctx.Request().Context(), provisionalIDTimeout)
+		defer cancel()
+
+		id, err := datastore.ResolveNoteID(waitCtx, ctx.Param("id"))
+		switch {
+		case errors.Is(err, datastore.ErrNoteNotSaved):
+			return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+		case errors.Is(err, datastore.ErrNotePending):
+			return c.HandleError(ctx, err, "Detection is buffered until the database recovers, try again later", http.StatusServiceUnavailable)
+		case err != nil:
+			return c.HandleError(ctx, err, "Detection is still being saved, try again later", http.StatusServiceUnavailable)
+		}
+
+		names := ctx.ParamNames()
+		values := slices.Clone(ctx.ParamValues())
+		for i, name := range names {
+			if name == "id" && i < len(values) {
+				values[i] = id
+			}
+		}
+		ctx.SetParamValues(values...)
+		return next(ctx)
+	}
 }
 
 // DetectionResponse represents a detection in the API response
@@ -97,6 +139,42 @@ type DetectionResponse struct {
 	DaysThisYear       int          `json:"daysThisYear,omitempty"`       // Days since first this year
 	DaysThisSeason     int          `json:"daysThisSeason,omitempty"`     // Days since first this season
 	CurrentSeason      string       `json:"currentSeason,omitempty"`      // Current season name
+
+	// Seasonal prior threshold adjustment applied when the detection was made
+	SeasonalOccurrence *float64 `json:"seasonalOccurrence,omitempty"` // Range model occurrence probability for the detection week
+	SeasonalAdjustment *float64 `json:"seasonalAdjustment,omitempty"` // Amount added to the confidence threshold
+
+	RarityScore *float64 `json:"rarityScore,omitempty"` // How unusual the species is for the station and week, 0 to 1
+
+	Profile  string `json:"profile,omitempty"`  // Processing profile that made the detection, empty for the default pipeline
+	Detector string `json:"detector,omitempty"` // Acoustic event detector that made the detection, empty for BirdNET
+
+	DisqualifiedReason string `json:"disqualifiedReason,omitempty"` // Set when re-scoring found the detection no longer meets the current filters
+
+	DeploymentID *uint `json:"deploymentId,omitempty"` // Deployment the detection was made in
+
+	Analysis *AnalysisSnapshotInfo `json:"analysis,omitempty"` // Model and analysis settings in effect, single detections only
+
+	Review *ReviewInfo `json:"review,omitempty"` // Who reviewed the detection and when, with the species correction if any
+}
+
+// ReviewInfo represents the last review of a detection
+type ReviewInfo struct {
+	Reviewer                string    `json:"reviewer,omitempty"`
+	ReviewedAt              time.Time `json:"reviewedAt"`
+	CorrectedScientificName string    `json:"correctedScientificName,omitempty"` // Species actually heard, misidentified detections only
+	CorrectedCommonName     string    `json:"correctedCommonName,omitempty"`
+}
+
+// AnalysisSnapshotInfo represents the model, application version and analysis settings in
+// effect when a detection was made
+type AnalysisSnapshotInfo struct {
+	ModelVersion string  `json:"modelVersion"`
+	AppVersion   string  `json:"appVersion"`
+	Overlap      float64 `json:"overlap"`
+	Sensitivity  float64 `json:"sensitivity"`
+	Threshold    float64 `json:"threshold"`
+	SourceFormat string  `json:"sourceFormat"`
 }
 
 // WeatherInfo represents weather data for a detection
@@ -116,6 +194,9 @@ type DetectionRequest struct {
 	Comment       string `json:"comment,omitempty"`
 	Verified      string `json:"verified,omitempty"`
 	IgnoreSpecies string `json:"ignoreSpecies,omitempty"`
+	// CorrectedSpecies is the scientific or common name of the species actually heard,
+	// required when Verified is "misidentified"
+	CorrectedSpecies string `json:"correctedSpecies,omitempty"`
 	Locked        bool   `json:"locked,omitempty"`
 	LockDetection bool   `json:"lock_detection,omitempty"`
 }
@@ -154,6 +235,7 @@ type detectionQueryParams struct {
 	Verified   string
 	Location   string
 	Locked     string
+	SortBy     string // "rarity" sorts by rarity score, default is newest first
 	// Include additional data
 	IncludeWeather bool
 }
@@ -175,6 +257,7 @@ func (c *Controller) parseDetectionQueryParams(ctx echo.Context) (*detectionQuer
 		Verified:   ctx.QueryParam("verified"),
 		Location:   ctx.QueryParam("location"),
 		Locked:     ctx.QueryParam("locked"),
+		SortBy:     ctx.QueryParam("sortBy"),
 		// Include weather data
 		IncludeWeather: ctx.QueryParam("includeWeather") == "true",
 	}
@@ -191,6 +274,10 @@ func (c *Controller) parseDetectionQueryParams(ctx echo.Context) (*detectionQuer
 		return nil, err
 	}
 
+	if params.SortBy != "" && params.SortBy != "rarity" {
+		return nil, fmt.Errorf("invalid sortBy parameter '%s', allowed values: rarity", params.SortBy)
+	}
+
 	// Parse and validate numResults
 	numResults, err := c.parseNumResults(ctx.QueryParam("numResults"))
 	if err != nil {
@@ -432,7 +519,7 @@ func (c *Controller) getDetectionsByQueryType(params *detectionQueryParams) ([]d
 	// Check if advanced filters are present
 	hasAdvancedFilters := params.Confidence != "" || params.TimeOfDay != "" ||
 		params.HourRange != "" || params.Verified != "" ||
-		params.Location != "" || params.Locked != ""
+		params.Location != "" || params.Locked != "" || params.SortBy != ""
 
 	switch params.QueryType {
 	case "hourly":
@@ -480,11 +567,34 @@ func (c *Controller) noteToDetectionResponse(note *datastore.Note, includeWeathe
 		EndTime:        note.EndTime.Format(time.RFC3339),
 		SpeciesCode:    note.SpeciesCode,
 		ScientificName: note.ScientificName,
-		CommonName:     note.CommonName,
+		CommonName:     birdnet.LocalizedCommonName(c.Settings, note.ScientificName, note.CommonName),
 		Confidence:     note.Confidence,
 		Locked:         note.Locked,
 	}
 
+	// Seasonal prior audit fields, nil when no adjustment was applied
+	detection.SeasonalOccurrence = note.SeasonalOccurrence
+	detection.SeasonalAdjustment = note.SeasonalAdjustment
+	detection.RarityScore = note.RarityScore
+	detection.Profile = note.Profile
+	detection.Detector = note.Detector
+	detection.DeploymentID = note.DeploymentID
+	if note.Disqualified {
+		detection.DisqualifiedReason = note.DisqualifiedReason
+	}
+
+	// Analysis snapshot, loaded when a single detection is retrieved
+	if snapshot := note.AnalysisSnapshot; snapshot != nil {
+		detection.Analysis = &AnalysisSnapshotInfo{
+			ModelVersion: snapshot.ModelVersion,
+			AppVersion:   snapshot.AppVersion,
+			Overlap:      snapshot.Overlap,
+			Sensitivity:  snapshot.Sensitivity,
+			Threshold:    snapshot.Threshold,
+			SourceFormat: snapshot.SourceFormat,
+		}
+	}
+
 	// Add species tracking metadata if processor has tracker
 	if c.Processor != nil && c.Processor.NewSpeciesTracker != nil {
 		status := c.Processor.NewSpeciesTracker.GetSpeciesStatus(note.ScientificName, time.Now())
@@ -501,6 +611,14 @@ func (c *Controller) noteToDetectionResponse(note *datastore.Note, includeWeathe
 
 	// Handle verification status
 	detection.Verified = c.mapVerificationStatus(note.Verified)
+	if note.Review != nil && note.Review.Verified != "" {
+		detection.Review = &ReviewInfo{
+			Reviewer:                note.Review.Reviewer,
+			ReviewedAt:              note.Review.UpdatedAt,
+			CorrectedScientificName: note.Review.CorrectedScientificName,
+			CorrectedCommonName:     note.Review.CorrectedCommonName,
+		}
+	}
 
 	// Get comments if any
 	if len(note.Comments) > 0 {
@@ -567,6 +685,8 @@ func (c *Controller) mapVerificationStatus(status string) string {
 		return "correct"
 	case "false_positive":
 		return "false_positive"
+	case "misidentified":
+		return "misidentified"
 	default:
 		return "unverified"
 	}
@@ -722,6 +842,7 @@ func (c *Controller) getSearchDetectionsAdvanced(params *detectionQueryParams) (
 		Limit:         params.NumResults,
 		Offset:        params.Offset,
 		SortAscending: false, // Default to descending
+		SortBy:        params.SortBy,
 	}
 
 	// Parse confidence filter
@@ -999,7 +1120,7 @@ func (c *Controller) GetRecentDetections(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, detections)
 }
 
-// DeleteDetection deletes a detection by ID
+// DeleteDetection moves a detection to the trash by ID
 func (c *Controller) DeleteDetection(ctx echo.Context) error {
 	idStr := ctx.Param("id")
 	note, err := c.DS.Get(idStr)
@@ -1107,18 +1228,27 @@ func (c *Controller) ReviewDetection(ctx echo.Context) error {
 
 	// Handle verification if provided
 	if req.Verified != "" {
-		var verified bool
+		review := &datastore.NoteReview{
+			NoteID:   note.ID,
+			Verified: req.Verified,
+			Reviewer: settingsChangeActor(ctx),
+		}
 		switch req.Verified {
-		case "correct":
-			verified = true
-		case "false_positive":
-			verified = false
+		case datastore.ReviewCorrect, datastore.ReviewFalsePositive:
+		case datastore.ReviewMisidentified:
+			// Misidentified detections record the species actually heard
+			scientificName, commonName, found := c.lookupSpeciesLabel(req.CorrectedSpecies)
+			if !found {
+				return c.HandleError(ctx, fmt.Errorf("unknown corrected species %q", req.CorrectedSpecies), "Corrected species is missing or unknown", http.StatusBadRequest)
+			}
+			review.CorrectedScientificName = scientificName
+			review.CorrectedCommonName = commonName
 		default:
 			return c.HandleError(ctx, fmt.Errorf("invalid verification status"), "Invalid verification status", http.StatusBadRequest)
 		}
 
 		// Save review using the datastore method for reviews
-		err = c.AddReview(note.ID, verified)
+		err = c.AddReview(review)
 		if err != nil {
 			return c.HandleError(ctx, err, fmt.Sprintf("Failed to update verification: %v", err), http.StatusInternalServerError)
 		}
@@ -1164,6 +1294,122 @@ func (c *Controller) ReviewDetection(ctx echo.Context) error {
 	})
 }
 
+// ReviewQueueItem is an unreviewed detection in the review queue
+type ReviewQueueItem struct {
+	DetectionResponse
+	SpeciesCount int64 `json:"speciesCount"` // detections of the species
+}
+
+// GetReviewQueue handles GET /api/v2/detections/review-queue
+// Returns a page of the unreviewed detections, least confident first or, with sort=novelty,
+// detections of the least detected species first.
+func (c *Controller) GetReviewQueue(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.ReviewQueueStore)
+	if !ok {
+		return c.HandleError(ctx, nil, "Review queue is not available", http.StatusServiceUnavailable)
+	}
+
+	order := ctx.QueryParam("sort")
+	if order == "" {
+		order = datastore.ReviewQueueByConfidence
+	}
+	if order != datastore.ReviewQueueByConfidence && order != datastore.ReviewQueueByNovelty {
+		return c.HandleError(ctx, fmt.Errorf("invalid sort %q", order), "Invalid sort parameter, expected confidence or novelty", http.StatusBadRequest)
+	}
+	numResults, err := c.parseNumResults(ctx.QueryParam("numResults"))
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	offset, err := c.parseOffset(ctx.QueryParam("offset"))
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	queue, total, err := store.GetReviewQueue(order, numResults, offset)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get review queue", http.StatusInternalServerError)
+	}
+
+	items := make([]ReviewQueueItem, 0, len(queue))
+	for i := range queue {
+		items = append(items, ReviewQueueItem{
+			DetectionResponse: c.noteToDetectionResponse(&queue[i].Note, false, nil),
+			SpeciesCount:      queue[i].SpeciesCount,
+		})
+	}
+	response := c.createPaginatedResponse(nil, total, numResults, offset)
+	response.Data = items
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// TrashedDetection is a deleted detection in the trash
+type TrashedDetection struct {
+	DetectionResponse
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"` // when the detection is permanently deleted
+}
+
+// GetTrashedDetections handles GET /api/v2/detections/trash
+// Returns a page of the deleted detections that can still be restored, most recently
+// deleted first
+func (c *Controller) GetTrashedDetections(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.TrashStore)
+	if !ok {
+		return c.HandleError(ctx, nil, "Trash is not available", http.StatusServiceUnavailable)
+	}
+
+	numResults, err := c.parseNumResults(ctx.QueryParam("numResults"))
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	offset, err := c.parseOffset(ctx.QueryParam("offset"))
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	notes, total, err := store.GetTrashedNotes(numResults, offset)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get trashed detections", http.StatusInternalServerError)
+	}
+
+	retentionDays := c.Settings.Trash.RetentionDays
+	items := make([]TrashedDetection, 0, len(notes))
+	for i := range notes {
+		deletedAt := notes[i].DeletedAt.Time
+		items = append(items, TrashedDetection{
+			DetectionResponse: c.noteToDetectionResponse(&notes[i], false, nil),
+			DeletedAt:         deletedAt,
+			PurgeAt:           deletedAt.AddDate(0, 0, retentionDays),
+		})
+	}
+	response := c.createPaginatedResponse(nil, total, numResults, offset)
+	response.Data = items
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// RestoreDetection handles POST /api/v2/detections/:id/restore
+// Moves a deleted detection out of the trash
+func (c *Controller) RestoreDetection(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.TrashStore)
+	if !ok {
+		return c.HandleError(ctx, nil, "Trash is not available", http.StatusServiceUnavailable)
+	}
+
+	idStr := ctx.Param("id")
+	if err := store.RestoreNote(idStr); err != nil {
+		var enhanced *errors.EnhancedError
+		if errors.As(err, &enhanced) && enhanced.Category == errors.CategoryNotFound {
+			return c.HandleError(ctx, err, "Detection not found in trash", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to restore detection", http.StatusInternalServerError)
+	}
+
+	// Invalidate cache so the restored detection is listed again
+	c.invalidateDetectionCache()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 // LockDetection locks or unlocks a detection
 func (c *Controller) LockDetection(ctx echo.Context) error {
 	idStr := ctx.Param("id")
@@ -1288,24 +1534,29 @@ func (c *Controller) AddComment(noteID uint, commentText string) error {
 	return c.DS.SaveNoteComment(comment)
 }
 
-// AddReview creates or updates a review for a note
-func (c *Controller) AddReview(noteID uint, verified bool) error {
-	// Convert bool to string value
-	verifiedStr := map[bool]string{
-		true:  "correct",
-		false: "false_positive",
-	}[verified]
-
-	review := &datastore.NoteReview{
-		NoteID:    noteID,
-		Verified:  verifiedStr,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+// AddReview creates or updates a review for a note, timestamped with the current time
+func (c *Controller) AddReview(review *datastore.NoteReview) error {
+	review.CreatedAt = time.Now()
+	review.UpdatedAt = review.CreatedAt
 
 	return c.DS.SaveNoteReview(review)
 }
 
+// lookupSpeciesLabel finds a species in the BirdNET labels by scientific or common name
+func (c *Controller) lookupSpeciesLabel(species string) (scientificName, commonName string, found bool) {
+	species = strings.TrimSpace(species)
+	if species == "" || c.Settings == nil {
+		return "", "", false
+	}
+	for _, label := range c.Settings.BirdNET.Labels {
+		labelScientific, labelCommon, _ := observation.ParseSpeciesString(label)
+		if strings.EqualFold(labelScientific, species) || strings.EqualFold(labelCommon, species) {
+			return labelScientific, labelCommon, true
+		}
+	}
+	return "", "", false
+}
+
 // AddLock creates or removes a lock for a note
 func (c *Controller) AddLock(noteID uint, locked bool) error {
 	noteIDStr := strconv.FormatUint(uint64(noteID), 10)
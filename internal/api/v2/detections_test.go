@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
@@ -20,6 +21,8 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/tphakala/birdnet-go/internal/datastore"
+	intErrors "github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
 )
 
 // decodePaginated is a helper to unmarshal a response body into a PaginatedResponse
@@ -713,8 +716,37 @@ func TestReviewDetection(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:        "Misidentified with corrected species",
+			detectionID: "7",
+			requestBody: `{"verified": "misidentified", "correctedSpecies": "eurasian blackbird"}`,
+			mockSetup: func(m *mock.Mock) {
+				m.On("Get", "7").Return(datastore.Note{ID: 7, Locked: false}, nil)
+				m.On("IsNoteLocked", "7").Return(false, nil)
+				m.On("SaveNoteReview", mock.MatchedBy(func(review *datastore.NoteReview) bool {
+					return review.Verified == "misidentified" &&
+						review.CorrectedScientificName == "Turdus merula" &&
+						review.CorrectedCommonName == "Eurasian Blackbird" &&
+						!review.UpdatedAt.IsZero()
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Misidentified with unknown species",
+			detectionID: "8",
+			requestBody: `{"verified": "misidentified", "correctedSpecies": "Dodo"}`,
+			mockSetup: func(m *mock.Mock) {
+				m.On("Get", "8").Return(datastore.Note{ID: 8, Locked: false}, nil)
+				m.On("IsNoteLocked", "8").Return(false, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
+	// Labels the corrected species are looked up in
+	controller.Settings.BirdNET.Labels = []string{"Turdus merula_Eurasian Blackbird"}
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup mock expectations
@@ -913,6 +945,112 @@ func TestIgnoreSpecies(t *testing.T) {
 	}
 }
 
+// reviewTestDataStore adds the review queue methods to the mock datastore
+type reviewTestDataStore struct {
+	*MockDataStore
+	reviewed []datastore.Note
+}
+
+func (reviewTestDataStore) GetReviewQueue(order string, limit, offset int) ([]datastore.ReviewQueueItem, int64, error) {
+	items := []datastore.ReviewQueueItem{
+		{Note: datastore.Note{ID: 7, ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.4}, SpeciesCount: 1},
+	}
+	return items, 3, nil
+}
+
+func (s reviewTestDataStore) GetReviewedNotes(startDate, endDate string) ([]datastore.Note, error) {
+	return s.reviewed, nil
+}
+
+// TestGetReviewQueue tests listing unreviewed detections for review
+func TestGetReviewQueue(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// The mock datastore has no review queue
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/review-queue", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetReviewQueue(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	controller.DS = reviewTestDataStore{MockDataStore: mockDS}
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/detections/review-queue?sort=novelty&numResults=1", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetReviewQueue(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	items, response := decodePaginated(t, rec.Body.Bytes())
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(3), response.Total)
+	assert.Equal(t, 3, response.TotalPages)
+	assert.InDelta(t, 7, items[0]["id"], 0)
+	assert.InDelta(t, 1, items[0]["speciesCount"], 0)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/detections/review-queue?sort=random", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetReviewQueue(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// trashTestDataStore adds the trash methods to the mock datastore
+type trashTestDataStore struct {
+	*MockDataStore
+	trashed []datastore.Note
+}
+
+func (s trashTestDataStore) GetTrashedNotes(limit, offset int) ([]datastore.Note, int64, error) {
+	return s.trashed, int64(len(s.trashed)), nil
+}
+
+func (s trashTestDataStore) RestoreNote(id string) error {
+	for i := range s.trashed {
+		if fmt.Sprint(s.trashed[i].ID) == id {
+			return nil
+		}
+	}
+	return intErrors.Newf("trashed note not found").Category(intErrors.CategoryNotFound).Build()
+}
+
+func (trashTestDataStore) PurgeTrash(before time.Time) ([]datastore.Note, error) {
+	return nil, nil
+}
+
+// TestTrashedDetections tests listing and restoring deleted detections
+func TestTrashedDetections(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.Settings.Trash.RetentionDays = 30
+
+	// The mock datastore has no trash
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/trash", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetTrashedDetections(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	trashed := datastore.Note{ID: 7, ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.4}
+	trashed.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	controller.DS = trashTestDataStore{MockDataStore: mockDS, trashed: []datastore.Note{trashed}}
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetTrashedDetections(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	items, response := decodePaginated(t, rec.Body.Bytes())
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(1), response.Total)
+	assert.InDelta(t, 7, items[0]["id"], 0)
+	assert.Equal(t, "2024-05-31T12:00:00Z", items[0]["purgeAt"])
+
+	for id, want := range map[string]int{"7": http.StatusNoContent, "8": http.StatusNotFound} {
+		req = httptest.NewRequest(http.MethodPost, "/api/v2/detections/"+id+"/restore", http.NoBody)
+		rec = httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+		require.NoError(t, controller.RestoreDetection(ctx))
+		assert.Equal(t, want, rec.Code, "restore detection %s", id)
+	}
+}
+
 // TestAddCommentMethod tests the AddComment method directly
 func TestAddCommentMethod(t *testing.T) {
 	// Setup
@@ -1523,3 +1661,40 @@ func TestTrueConcurrentPlatformSpecific(t *testing.T) {
 	assert.Equal(t, int32(0), failures, "There should be no unexpected failures")
 	assert.Equal(t, int32(numConcurrent), successes+conflicts, "All requests should either succeed or get conflict") // #nosec G115 -- numConcurrent is a small test constant (3-10), no overflow risk
 }
+
+// TestResolveNoteID tests that provisional IDs of live detections are replaced with database IDs
+func TestResolveNoteID(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	handler := controller.resolveNoteID(func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, ctx.Param("id"))
+	})
+	request := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/"+id, http.NoBody)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+		_ = handler(ctx)
+		return rec
+	}
+
+	rec := request("17")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "17", rec.Body.String(), "database IDs are passed through")
+
+	saved := datastore.NewNoteIDPromise()
+	saved.Resolve(42)
+	rec = request(fmt.Sprint(saved.Provisional()))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "42", rec.Body.String())
+
+	notSaved := datastore.NewNoteIDPromise()
+	notSaved.Resolve(0)
+	rec = request(fmt.Sprint(notSaved.Provisional()))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	buffered := datastore.NewNoteIDPromise()
+	buffered.Buffer()
+	rec = request(fmt.Sprint(buffered.Provisional()))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "a buffered detection is reported as pending")
+}
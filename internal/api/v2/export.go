@@ -0,0 +1,388 @@
+// internal/api/v2/export.go
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/export"
+)
+
+// ExportJobResponse is an export job with its progress and output directory
+type ExportJobResponse struct {
+	export.Job
+	Progress  float64 `json:"progress"`  // percentage of completed date partitions
+	Directory string  `json:"directory"` // directory containing the date partitions
+}
+
+// initExportRoutes registers the bulk export endpoints and loads jobs from previous runs
+func (c *Controller) initExportRoutes() {
+	if store, ok := c.DS.(export.Store); ok && c.Settings != nil {
+		manager, err := export.NewManager(store, c.Settings.DataExport.Path)
+		if err != nil {
+			c.logger.Printf("Failed to initialize export manager: %v", err)
+		} else {
+			c.exportManager = manager
+		}
+	}
+
+	exportGroup := c.Group.Group("/export", c.getEffectiveAuthMiddleware())
+	exportGroup.GET("/jobs", c.ListExportJobs)
+	exportGroup.POST("/jobs", c.StartExportJob)
+	exportGroup.GET("/jobs/:id", c.GetExportJob)
+	exportGroup.POST("/jobs/:id/resume", c.ResumeExportJob)
+	exportGroup.DELETE("/jobs/:id", c.CancelExportJob)
+	exportGroup.GET("/ebird", c.ExportEBird)
+	exportGroup.GET("/raven", c.ExportRaven)
+	exportGroup.GET("/audacity", c.ExportAudacity)
+	exportGroup.POST("/training", c.ExportTraining)
+}
+
+// ListExportJobs handles GET /api/v2/export/jobs
+func (c *Controller) ListExportJobs(ctx echo.Context) error {
+	if c.exportManager == nil {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	jobs := c.exportManager.List()
+	response := make([]ExportJobResponse, 0, len(jobs))
+	for i := range jobs {
+		response = append(response, c.exportJobResponse(&jobs[i]))
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// StartExportJob handles POST /api/v2/export/jobs
+// Starts a background export of the requested date range and returns the new job.
+func (c *Controller) StartExportJob(ctx echo.Context) error {
+	if c.exportManager == nil {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	var opts export.Options
+	if err := ctx.Bind(&opts); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if opts.Format == "" {
+		opts.Format = export.FormatCSV
+	}
+
+	job, err := c.exportManager.Start(opts)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to start export", exportErrorStatus(err))
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Export job started",
+			"job_id", job.ID,
+			"format", opts.Format,
+			"start_date", opts.StartDate,
+			"end_date", opts.EndDate,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusAccepted, c.exportJobResponse(&job))
+}
+
+// GetExportJob handles GET /api/v2/export/jobs/:id
+func (c *Controller) GetExportJob(ctx echo.Context) error {
+	if c.exportManager == nil {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	job, exists := c.exportManager.Get(ctx.Param("id"))
+	if !exists {
+		return c.HandleError(ctx, nil, "Export job not found", http.StatusNotFound)
+	}
+	return ctx.JSON(http.StatusOK, c.exportJobResponse(&job))
+}
+
+// ResumeExportJob handles POST /api/v2/export/jobs/:id/resume
+// Continues a failed, cancelled or interrupted job from its first incomplete partition.
+func (c *Controller) ResumeExportJob(ctx echo.Context) error {
+	if c.exportManager == nil {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	job, err := c.exportManager.Resume(ctx.Param("id"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to resume export", exportErrorStatus(err))
+	}
+	return ctx.JSON(http.StatusAccepted, c.exportJobResponse(&job))
+}
+
+// CancelExportJob handles DELETE /api/v2/export/jobs/:id
+// Stops a running job. Completed partitions are kept and the job can be resumed.
+func (c *Controller) CancelExportJob(ctx echo.Context) error {
+	if c.exportManager == nil {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	if err := c.exportManager.Cancel(ctx.Param("id")); err != nil {
+		return c.HandleError(ctx, err, "Failed to cancel export", exportErrorStatus(err))
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ExportEBird handles GET /api/v2/export/ebird
+// Returns the detections between startDate and endDate as an eBird Record Format CSV file.
+// The grouping and minConfidence query parameters override the configured defaults.
+func (c *Controller) ExportEBird(ctx echo.Context) error {
+	store, ok := c.DS.(export.Store)
+	if !ok {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+	if c.Processor == nil || c.Processor.Bn == nil {
+		return c.HandleError(ctx, nil, "Species taxonomy is not available", http.StatusServiceUnavailable)
+	}
+
+	settings := c.Settings.DataExport.EBird
+	opts := export.EBirdOptions{
+		StartDate:     ctx.QueryParam("startDate"),
+		EndDate:       ctx.QueryParam("endDate"),
+		Grouping:      settings.Grouping,
+		MinConfidence: settings.MinConfidence,
+		LocationName:  settings.LocationName,
+		Latitude:      c.Settings.BirdNET.Latitude,
+		Longitude:     c.Settings.BirdNET.Longitude,
+		StateCode:     settings.StateCode,
+		CountryCode:   settings.CountryCode,
+	}
+	if opts.LocationName == "" {
+		opts.LocationName = c.Settings.Main.Name
+	}
+	if grouping := ctx.QueryParam("grouping"); grouping != "" {
+		opts.Grouping = grouping
+	}
+	if minConfidence := ctx.QueryParam("minConfidence"); minConfidence != "" {
+		value, err := strconv.ParseFloat(minConfidence, 64)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid minConfidence parameter", http.StatusBadRequest)
+		}
+		opts.MinConfidence = value
+	}
+
+	var buf bytes.Buffer
+	summary, err := export.WriteEBird(&buf, store, c.Processor.Bn, &opts)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to export eBird records", exportErrorStatus(err))
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("eBird export created",
+			"start_date", opts.StartDate,
+			"end_date", opts.EndDate,
+			"grouping", opts.Grouping,
+			"checklists", summary.Checklists,
+			"records", summary.Records,
+			"skipped", summary.Skipped,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	filename := fmt.Sprintf("ebird_%s_%s.csv", opts.StartDate, opts.EndDate)
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Response().Header().Set("X-Export-Checklists", strconv.Itoa(summary.Checklists))
+	ctx.Response().Header().Set("X-Export-Skipped", strconv.Itoa(summary.Skipped))
+	return ctx.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ExportRaven handles GET /api/v2/export/raven
+// Returns the detections between startDate and endDate as a zip archive of Raven Pro
+// selection tables. The grouping and minConfidence query parameters override the defaults.
+func (c *Controller) ExportRaven(ctx echo.Context) error {
+	store, ok := c.DS.(export.Store)
+	if !ok {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	settings := c.Settings.DataExport.Raven
+	opts := export.RavenOptions{
+		StartDate:  ctx.QueryParam("startDate"),
+		EndDate:    ctx.QueryParam("endDate"),
+		Grouping:   settings.Grouping,
+		PreCapture: time.Duration(c.Settings.Realtime.Audio.Export.PreCapture) * time.Second,
+		Frequency:  export.FrequencyRange{Low: settings.LowFreq, High: settings.HighFreq},
+		Species:    make(map[string]export.FrequencyRange, len(settings.Species)),
+	}
+	for species, bounds := range settings.Species {
+		opts.Species[strings.ToLower(species)] = export.FrequencyRange{Low: bounds.LowFreq, High: bounds.HighFreq}
+	}
+	if grouping := ctx.QueryParam("grouping"); grouping != "" {
+		opts.Grouping = grouping
+	}
+	if minConfidence := ctx.QueryParam("minConfidence"); minConfidence != "" {
+		value, err := strconv.ParseFloat(minConfidence, 64)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid minConfidence parameter", http.StatusBadRequest)
+		}
+		opts.MinConfidence = value
+	}
+
+	var buf bytes.Buffer
+	summary, err := export.WriteRavenArchive(&buf, store, &opts)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to export Raven selection tables", exportErrorStatus(err))
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Raven export created",
+			"start_date", opts.StartDate,
+			"end_date", opts.EndDate,
+			"grouping", opts.Grouping,
+			"tables", summary.Tables,
+			"selections", summary.Selections,
+			"skipped", summary.Skipped,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	filename := fmt.Sprintf("raven_%s_%s.zip", opts.StartDate, opts.EndDate)
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Response().Header().Set("X-Export-Tables", strconv.Itoa(summary.Tables))
+	ctx.Response().Header().Set("X-Export-Skipped", strconv.Itoa(summary.Skipped))
+	return ctx.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ExportAudacity handles GET /api/v2/export/audacity
+// Returns a zip archive of Audacity label files per day and audio source. Label times are
+// relative to recordingStart (HH:MM, default 00:00) so they line up with day-long recordings.
+func (c *Controller) ExportAudacity(ctx echo.Context) error {
+	store, ok := c.DS.(export.Store)
+	if !ok {
+		return c.HandleError(ctx, nil, "Export is not available", http.StatusServiceUnavailable)
+	}
+
+	opts := export.AudacityOptions{
+		StartDate: ctx.QueryParam("startDate"),
+		EndDate:   ctx.QueryParam("endDate"),
+		Source:    ctx.QueryParam("source"),
+	}
+	if recordingStart := ctx.QueryParam("recordingStart"); recordingStart != "" {
+		t, err := time.Parse("15:04", recordingStart)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid recordingStart parameter, expected HH:MM", http.StatusBadRequest)
+		}
+		opts.Offset = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if minConfidence := ctx.QueryParam("minConfidence"); minConfidence != "" {
+		value, err := strconv.ParseFloat(minConfidence, 64)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid minConfidence parameter", http.StatusBadRequest)
+		}
+		opts.MinConfidence = value
+	}
+
+	var buf bytes.Buffer
+	summary, err := export.WriteAudacityArchive(&buf, store, &opts)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to export Audacity labels", exportErrorStatus(err))
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Audacity export created",
+			"start_date", opts.StartDate,
+			"end_date", opts.EndDate,
+			"source", opts.Source,
+			"files", summary.Files,
+			"labels", summary.Labels,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	filename := fmt.Sprintf("audacity_%s_%s.zip", opts.StartDate, opts.EndDate)
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Response().Header().Set("X-Export-Files", strconv.Itoa(summary.Files))
+	return ctx.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// TrainingExportRequest selects the reviewed detections of a training dataset export
+type TrainingExportRequest struct {
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate"`
+	IncludeNoise bool   `json:"includeNoise"` // export false positives as a Noise class
+}
+
+// TrainingExportResponse reports a written training dataset
+type TrainingExportResponse struct {
+	export.TrainingSummary
+	Directory string `json:"directory"`
+}
+
+// ExportTraining handles POST /api/v2/export/training
+// Copies the clips of reviewed detections into a folder per species under the export
+// directory, with a labels.csv file, for fine-tuning custom models.
+func (c *Controller) ExportTraining(ctx echo.Context) error {
+	store, ok := c.DS.(export.TrainingStore)
+	if !ok {
+		return c.HandleError(ctx, nil, "Training export is not available", http.StatusServiceUnavailable)
+	}
+
+	var req TrainingExportRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	opts := export.TrainingOptions{
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		ClipsDir:     c.Settings.Realtime.Audio.Export.Path,
+		IncludeNoise: req.IncludeNoise,
+	}
+	if err := opts.Validate(); err != nil {
+		return c.HandleError(ctx, err, "Invalid training export request", exportErrorStatus(err))
+	}
+
+	name := fmt.Sprintf("%s_%s_%s", opts.StartDate, opts.EndDate, time.Now().Format("20060102T150405"))
+	dir := filepath.Join(c.Settings.DataExport.Path, export.TrainingDirName, name)
+	summary, err := export.WriteTrainingDataset(dir, store, &opts)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to export training dataset", exportErrorStatus(err))
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Training dataset exported",
+			"start_date", opts.StartDate,
+			"end_date", opts.EndDate,
+			"directory", dir,
+			"clips", summary.Clips,
+			"classes", summary.Classes,
+			"skipped", summary.Skipped,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, TrainingExportResponse{TrainingSummary: summary, Directory: dir})
+}
+
+// exportJobResponse adds the progress and directory to a job
+func (c *Controller) exportJobResponse(job *export.Job) ExportJobResponse {
+	return ExportJobResponse{
+		Job:       *job,
+		Progress:  job.Progress(),
+		Directory: c.exportManager.JobDir(job.ID),
+	}
+}
+
+// exportErrorStatus maps export errors to HTTP status codes
+func exportErrorStatus(err error) int {
+	var enhanced *errors.EnhancedError
+	if errors.As(err, &enhanced) {
+		switch enhanced.Category {
+		case errors.CategoryValidation:
+			return http.StatusBadRequest
+		case errors.CategoryNotFound:
+			return http.StatusNotFound
+		case errors.CategoryConflict:
+			return http.StatusConflict
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,205 @@
+// export_test.go: tests for the bulk export job endpoints
+
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/export"
+)
+
+// exportTestStore serves a single detection per date
+type exportTestStore struct{}
+
+func (exportTestStore) GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error) {
+	return []datastore.Note{{ID: 1, Date: date, Time: "06:00:00", CommonName: "Eurasian Blackbird", Confidence: 0.9}}, nil
+}
+
+func (exportTestStore) GetHourlyWeather(date string) ([]datastore.HourlyWeather, error) {
+	return nil, nil
+}
+
+func TestExportJobEndpoints(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	manager, err := export.NewManager(exportTestStore{}, t.TempDir())
+	require.NoError(t, err)
+	controller.exportManager = manager
+	t.Cleanup(manager.Stop)
+
+	call := func(method, id, body string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v2/export/jobs", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		if id != "" {
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(id)
+		}
+		require.NoError(t, handler(ctx))
+		return rec
+	}
+
+	rec := call(http.MethodPost, "", `{"format":"csv","startDate":"2025-05-10","endDate":"2025-05-11"}`, controller.StartExportJob)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var started ExportJobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+	assert.Equal(t, 2, started.TotalDays)
+	assert.NotEmpty(t, started.Directory)
+
+	var job ExportJobResponse
+	require.Eventually(t, func() bool {
+		rec := call(http.MethodGet, started.ID, "", controller.GetExportJob)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+		return job.Status == export.StatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.InDelta(t, 100.0, job.Progress, 0.001)
+	assert.Equal(t, int64(2), job.Rows)
+
+	rec = call(http.MethodGet, "", "", controller.ListExportJobs)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var jobs []ExportJobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &jobs))
+	require.Len(t, jobs, 1)
+
+	rec = call(http.MethodPost, "", `{"format":"xlsx","startDate":"2025-05-10","endDate":"2025-05-11"}`, controller.StartExportJob)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = call(http.MethodPost, started.ID, "", controller.ResumeExportJob)
+	assert.Equal(t, http.StatusConflict, rec.Code, "completed jobs cannot be resumed")
+
+	rec = call(http.MethodDelete, started.ID, "", controller.CancelExportJob)
+	assert.Equal(t, http.StatusConflict, rec.Code, "completed jobs cannot be cancelled")
+
+	rec = call(http.MethodGet, "missing", "", controller.GetExportJob)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = call(http.MethodDelete, "missing", "", controller.CancelExportJob)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestExportJobEndpointsUnavailable(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/export/jobs", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ListExportJobs(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestExportEBirdUnavailable(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/export/ebird?startDate=2025-05-10&endDate=2025-05-10", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ExportEBird(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+// ravenTestDataStore adds the export store methods to the mock datastore
+type ravenTestDataStore struct {
+	*MockDataStore
+}
+
+func (ravenTestDataStore) GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error) {
+	return exportTestStore{}.GetNotesByDate(date, includeResults)
+}
+
+func (ravenTestDataStore) GetHourlyWeather(date string) ([]datastore.HourlyWeather, error) {
+	return nil, nil
+}
+
+func TestExportRaven(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DS = ravenTestDataStore{mockDS}
+	controller.Settings.DataExport.Raven = conf.RavenExportSettings{Grouping: conf.RavenTablePerDay, HighFreq: 15000}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/export/raven?startDate=2025-05-10&endDate=2025-05-11", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ExportRaven(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "2", rec.Header().Get("X-Export-Tables"))
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	assert.Equal(t, "2025-05-10.Table.1.selections.txt", zr.File[0].Name)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/export/raven?startDate=2025-05-10&endDate=2025-05-11&grouping=file", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.ExportRaven(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExportAudacity(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DS = ravenTestDataStore{mockDS}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/export/audacity?startDate=2025-05-10&endDate=2025-05-10&recordingStart=05:00", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ExportAudacity(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-Export-Files"))
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "3600.000000\t3603.000000\tEurasian Blackbird (90%)\n", string(content))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/export/audacity?startDate=2025-05-10&endDate=2025-05-10&recordingStart=25:00", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.ExportAudacity(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExportTraining(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	clipsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(clipsDir, "owl.wav"), []byte("owl"), 0o644))
+	controller.Settings.Realtime.Audio.Export.Path = clipsDir
+	controller.Settings.DataExport.Path = t.TempDir()
+	controller.DS = reviewTestDataStore{MockDataStore: mockDS, reviewed: []datastore.Note{
+		{ID: 7, Date: "2025-05-10", ScientificName: "Strix aluco", CommonName: "Tawny Owl", ClipName: "owl.wav",
+			Review: &datastore.NoteReview{Verified: datastore.ReviewCorrect}},
+	}}
+
+	body := `{"startDate":"2025-05-10","endDate":"2025-05-10"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/export/training", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ExportTraining(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response TrainingExportResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Clips)
+	assert.FileExists(t, filepath.Join(response.Directory, "Strix aluco_Tawny Owl", "7_owl.wav"))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v2/export/training", strings.NewReader(`{"startDate":"2025-05-10"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.ExportTraining(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,45 @@
+// internal/api/v2/ffmpeg_capabilities.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// FFmpegCapabilitiesResponse describes the FFmpeg build used for exports and streaming
+type FFmpegCapabilitiesResponse struct {
+	Available    bool                        `json:"available"`              // false when FFmpeg is not configured
+	Capabilities *myaudio.FFmpegCapabilities `json:"capabilities,omitempty"` // Detected version and features
+	Error        string                      `json:"error,omitempty"`        // Why detection failed
+}
+
+// GetFFmpegCapabilities handles GET /api/v2/system/ffmpeg
+// Returns the detected FFmpeg version, available encoders and filters, and the incompatibilities
+// that made exports fall back to other arguments. Capabilities are detected on first use when
+// they were not detected at startup or FFmpeg has been reconfigured since.
+func (c *Controller) GetFFmpegCapabilities(ctx echo.Context) error {
+	ffmpegPath := c.Settings.Realtime.Audio.FfmpegPath
+	if ffmpegPath == "" {
+		return ctx.JSON(http.StatusOK, FFmpegCapabilitiesResponse{})
+	}
+
+	caps := myaudio.GetFFmpegCapabilities()
+	if caps == nil || caps.Path != ffmpegPath {
+		detected, err := myaudio.DetectFFmpegCapabilities(ctx.Request().Context(), ffmpegPath)
+		if err != nil {
+			if c.apiLogger != nil {
+				c.apiLogger.Warn("FFmpeg capability detection failed",
+					"error", err.Error(),
+					"path", ctx.Request().URL.Path,
+					"ip", ctx.RealIP(),
+				)
+			}
+			return ctx.JSON(http.StatusOK, FFmpegCapabilitiesResponse{Available: true, Error: err.Error()})
+		}
+		caps = detected
+	}
+
+	return ctx.JSON(http.StatusOK, FFmpegCapabilitiesResponse{Available: true, Capabilities: caps})
+}
@@ -0,0 +1,42 @@
+// ffmpeg_capabilities_test.go: tests for the FFmpeg capabilities endpoint
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFFmpegCapabilities(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	controller.Settings.Realtime.Audio.FfmpegPath = ""
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/system/ffmpeg", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetFFmpegCapabilities(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"available":false}`, rec.Body.String(), "FFmpeg is not configured")
+
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not available")
+	}
+	controller.Settings.Realtime.Audio.FfmpegPath = ffmpegPath
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/system/ffmpeg", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetFFmpegCapabilities(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response FFmpegCapabilitiesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.Available)
+	require.NotNil(t, response.Capabilities, response.Error)
+	assert.Equal(t, ffmpegPath, response.Capabilities.Path)
+	assert.NotEmpty(t, response.Capabilities.Version)
+}
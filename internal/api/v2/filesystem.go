@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 )
 
 // FileSystemItem represents a file or directory for the frontend file browser
@@ -40,7 +41,7 @@ func (c *Controller) initFileSystemRoutes() {
 	}
 
 	// Create filesystem API group with authentication
-	fsGroup := c.Group.Group("/filesystem", c.getEffectiveAuthMiddleware())
+	fsGroup := c.Group.Group("/filesystem", c.getEffectiveAuthMiddleware(), auth.RequireAdmin)
 
 	// GET /api/v2/filesystem/browse - Browse files and directories
 	fsGroup.GET("/browse", c.BrowseFileSystem)
@@ -0,0 +1,185 @@
+// internal/api/v2/geojson.go
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// GeoJSON limits for the number of features in one response
+const (
+	geoJSONDefaultLimit = 1000
+	geoJSONMaxLimit     = 10000
+)
+
+// geoJSONContentType is the media type of GeoJSON documents (RFC 7946)
+const geoJSONContentType = "application/geo+json"
+
+// GeoJSONFeatureCollection is a GeoJSON FeatureCollection of detections
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"` // always "FeatureCollection"
+	Features []GeoJSONFeature `json:"features"`
+	Total    int64            `json:"total"` // number of matching detections, foreign member
+}
+
+// GeoJSONFeature is a detection as a GeoJSON Feature. Geometry is null when the
+// location privacy policy hides coordinates or the location is unknown.
+type GeoJSONFeature struct {
+	Type       string                     `json:"type"` // always "Feature"
+	ID         uint                       `json:"id"`
+	Geometry   *GeoJSONPoint              `json:"geometry"`
+	Properties GeoJSONDetectionProperties `json:"properties"`
+}
+
+// GeoJSONPoint is a GeoJSON Point geometry with coordinates in longitude, latitude order
+type GeoJSONPoint struct {
+	Type        string     `json:"type"` // always "Point"
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// GeoJSONDetectionProperties are the properties of a detection feature
+type GeoJSONDetectionProperties struct {
+	Station        string  `json:"station"`
+	Date           string  `json:"date"`
+	Time           string  `json:"time"`
+	Source         string  `json:"source"`
+	SpeciesCode    string  `json:"speciesCode"`
+	ScientificName string  `json:"scientificName"`
+	CommonName     string  `json:"commonName"`
+	Confidence     float64 `json:"confidence"`
+	Verified       string  `json:"verified"`
+}
+
+// GetDetectionsGeoJSON handles GET /api/v2/detections/geojson
+// Returns detections as a GeoJSON FeatureCollection, newest first, with coordinates
+// reduced according to the configured location privacy policy.
+// Query parameters: species (optional, comma-separated scientific names or species codes),
+// start_date and end_date (optional, YYYY-MM-DD), limit (optional, default 1000, max 10000), offset (optional)
+func (c *Controller) GetDetectionsGeoJSON(ctx echo.Context) error {
+	filters, err := parseGeoJSONFilters(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	notes, total, err := c.DS.SearchNotesAdvanced(filters)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to retrieve detections", http.StatusInternalServerError)
+	}
+
+	collection := GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]GeoJSONFeature, 0, len(notes)),
+		Total:    total,
+	}
+	for i := range notes {
+		collection.Features = append(collection.Features, c.noteToGeoJSONFeature(&notes[i]))
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("GeoJSON detections retrieved",
+			"species", filters.Species,
+			"count", len(collection.Features),
+			"total", total,
+			"policy", c.Settings.WebServer.LocationPrivacy.Policy,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to encode GeoJSON", http.StatusInternalServerError)
+	}
+	return ctx.Blob(http.StatusOK, geoJSONContentType, data)
+}
+
+// parseGeoJSONFilters converts the query parameters of the GeoJSON endpoint to search filters
+func parseGeoJSONFilters(ctx echo.Context) (*datastore.AdvancedSearchFilters, error) {
+	filters := &datastore.AdvancedSearchFilters{Limit: geoJSONDefaultLimit}
+
+	if species := ctx.QueryParam("species"); species != "" {
+		for name := range strings.SplitSeq(species, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				filters.Species = append(filters.Species, name)
+			}
+		}
+	}
+
+	startDate, endDate := ctx.QueryParam("start_date"), ctx.QueryParam("end_date")
+	if err := validateDateParam(startDate, "start_date"); err != nil {
+		return nil, err
+	}
+	if err := validateDateParam(endDate, "end_date"); err != nil {
+		return nil, err
+	}
+	if startDate != "" || endDate != "" {
+		dateRange := &datastore.DateRange{Start: time.Time{}, End: time.Now()}
+		if startDate != "" {
+			dateRange.Start, _ = time.Parse(time.DateOnly, startDate)
+		}
+		if endDate != "" {
+			dateRange.End, _ = time.Parse(time.DateOnly, endDate)
+		}
+		if dateRange.End.Before(dateRange.Start) {
+			return nil, fmt.Errorf("end_date must not be before start_date")
+		}
+		filters.DateRange = dateRange
+	}
+
+	if limitParam := ctx.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > geoJSONMaxLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", geoJSONMaxLimit)
+		}
+		filters.Limit = limit
+	}
+
+	if offsetParam := ctx.QueryParam("offset"); offsetParam != "" {
+		offset, err := strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("offset must be a non-negative number")
+		}
+		filters.Offset = offset
+	}
+
+	return filters, nil
+}
+
+// noteToGeoJSONFeature converts a note to a GeoJSON feature. Notes without coordinates
+// are placed at the station location.
+func (c *Controller) noteToGeoJSONFeature(note *datastore.Note) GeoJSONFeature {
+	feature := GeoJSONFeature{
+		Type: "Feature",
+		ID:   note.ID,
+		Properties: GeoJSONDetectionProperties{
+			Station:        c.Settings.Main.Name,
+			Date:           note.Date,
+			Time:           note.Time,
+			Source:         note.Source.SafeString,
+			SpeciesCode:    note.SpeciesCode,
+			ScientificName: note.ScientificName,
+			CommonName:     note.CommonName,
+			Confidence:     note.Confidence,
+			Verified:       c.mapVerificationStatus(note.Verified),
+		},
+	}
+
+	latitude, longitude := note.Latitude, note.Longitude
+	if latitude == 0 && longitude == 0 {
+		latitude, longitude = c.Settings.BirdNET.Latitude, c.Settings.BirdNET.Longitude
+	}
+	if latitude == 0 && longitude == 0 {
+		return feature
+	}
+
+	if lat, lon, ok := c.Settings.WebServer.LocationPrivacy.Apply(latitude, longitude); ok {
+		feature.Geometry = &GeoJSONPoint{Type: "Point", Coordinates: [2]float64{lon, lat}}
+	}
+	return feature
+}
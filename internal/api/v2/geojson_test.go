@@ -0,0 +1,111 @@
+// geojson_test.go: tests for the detection GeoJSON endpoint
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestGetDetectionsGeoJSON(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.Settings.Main.Name = "backyard"
+	controller.Settings.BirdNET.Latitude = 60.123456
+	controller.Settings.BirdNET.Longitude = 24.987654
+	controller.Settings.WebServer.LocationPrivacy = conf.LocationPrivacySettings{Policy: conf.LocationPolicyRounded, Precision: 2}
+
+	notes := []datastore.Note{
+		{ID: 2, Date: "2024-06-02", Time: "05:10:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.91, Latitude: 61.505, Longitude: 23.761},
+		{ID: 1, Date: "2024-06-01", Time: "04:55:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.85},
+	}
+	mockDS.On("SearchNotesAdvanced", mock.MatchedBy(func(filters *datastore.AdvancedSearchFilters) bool {
+		return len(filters.Species) == 2 && filters.Species[0] == "Turdus merula" &&
+			filters.DateRange != nil && filters.DateRange.Start.Format("2006-01-02") == "2024-06-01" &&
+			filters.Limit == 50
+	})).Return(notes, int64(2), nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v2/detections/geojson?species=Turdus%20merula,eurbla&start_date=2024-06-01&end_date=2024-06-02&limit=50", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetDetectionsGeoJSON(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, geoJSONContentType, rec.Header().Get("Content-Type"))
+
+	var collection GeoJSONFeatureCollection
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &collection))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	assert.Equal(t, int64(2), collection.Total)
+	require.Len(t, collection.Features, 2)
+
+	feature := collection.Features[0]
+	assert.Equal(t, "Feature", feature.Type)
+	assert.Equal(t, "backyard", feature.Properties.Station)
+	require.NotNil(t, feature.Geometry)
+	assert.Equal(t, [2]float64{23.76, 61.51}, feature.Geometry.Coordinates, "coordinates are longitude, latitude rounded to two decimals")
+
+	// Notes without coordinates use the station location
+	require.NotNil(t, collection.Features[1].Geometry)
+	assert.Equal(t, [2]float64{24.99, 60.12}, collection.Features[1].Geometry.Coordinates)
+	mockDS.AssertExpectations(t)
+}
+
+func TestGetDetectionsGeoJSONInvalidParameters(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	for _, query := range []string{
+		"start_date=2024-13-01",
+		"start_date=2024-06-02&end_date=2024-06-01",
+		"limit=0",
+		"limit=10001",
+		"offset=-1",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/geojson?"+query, http.NoBody)
+		rec := httptest.NewRecorder()
+		require.NoError(t, controller.GetDetectionsGeoJSON(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestLocationPrivacyApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		privacy conf.LocationPrivacySettings
+		wantLat float64
+		wantLon float64
+		wantOK  bool
+	}{
+		{"exact", conf.LocationPrivacySettings{Policy: conf.LocationPolicyExact}, 60.123456, 24.987654, true},
+		{"rounded", conf.LocationPrivacySettings{Policy: conf.LocationPolicyRounded, Precision: 1}, 60.1, 25.0, true},
+		{"empty policy rounds", conf.LocationPrivacySettings{Precision: 3}, 60.123, 24.988, true},
+		{"hidden", conf.LocationPrivacySettings{Policy: conf.LocationPolicyHidden}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lat, lon, ok := tt.privacy.Apply(60.123456, 24.987654)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.wantLat, lat, 1e-9)
+			assert.InDelta(t, tt.wantLon, lon, 1e-9)
+		})
+	}
+}
+
+func TestNoteToGeoJSONFeatureHiddenLocation(t *testing.T) {
+	_, _, controller := setupTestEnvironment(t)
+	controller.Settings.WebServer.LocationPrivacy.Policy = conf.LocationPolicyHidden
+
+	feature := controller.noteToGeoJSONFeature(&datastore.Note{ID: 7, Latitude: 61.5, Longitude: 23.7})
+	assert.Nil(t, feature.Geometry)
+	assert.Equal(t, uint(7), feature.ID)
+}
@@ -0,0 +1,287 @@
+// internal/api/v2/health.go
+package api
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/birdweather"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// audioStaleTimeout is how long without audio from any source before the instance is not ready
+const audioStaleTimeout = 30 * time.Second
+
+// jobQueueHighUtilization is the job queue fill percentage above which the queue is reported
+// unhealthy, new detection actions are dropped once it is full
+const jobQueueHighUtilization = 90.0
+
+// Overall component status reported by /healthz
+const (
+	componentStatusOK       = "ok"        // all components healthy
+	componentStatusDegraded = "degraded"  // an optional component such as MQTT is unhealthy
+	componentStatusFailing  = "unhealthy" // a component needed for detections is unhealthy
+)
+
+// ProbeCheck is the result of a single readiness check. Only failing critical checks make the
+// instance not ready, the others report integrations whose outage does not stop detections.
+type ProbeCheck struct {
+	Name     string `json:"name"`
+	Ready    bool   `json:"ready"`
+	Critical bool   `json:"critical"`
+	Message  string `json:"message,omitempty"`
+	Details  any    `json:"details,omitempty"`
+}
+
+// SourceProbe is the capture state of one audio source in the audio check
+type SourceProbe struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name,omitempty"`
+	Alive            bool      `json:"alive"`
+	LastAudio        time.Time `json:"lastAudio,omitzero"`
+	SecondsSinceData float64   `json:"secondsSinceData,omitempty"`
+}
+
+// ComponentHealthResponse is returned by /healthz
+type ComponentHealthResponse struct {
+	Status    string       `json:"status"`
+	Ready     bool         `json:"ready"`
+	Draining  bool         `json:"draining"`
+	Checks    []ProbeCheck `json:"checks"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// ReadinessResponse is returned by the readiness probe
+type ReadinessResponse struct {
+	Ready     bool         `json:"ready"`
+	Draining  bool         `json:"draining"`
+	Checks    []ProbeCheck `json:"checks"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// LivenessCheck handles GET /api/v2/health/live
+// Reports that the process is running and serving requests. It does not depend on the
+// model, audio sources or database, so a restart is only triggered when the process hangs.
+func (c *Controller) LivenessCheck(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"status":    "alive",
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// ReadinessCheck handles GET /api/v2/health/ready and GET /readyz
+// Reports whether the model is loaded, audio is streaming and the database is reachable,
+// together with the state of MQTT, BirdWeather and the job queue. Returns 503 when a critical
+// check fails or the instance is draining.
+func (c *Controller) ReadinessCheck(ctx echo.Context) error {
+	response := c.readiness(time.Now())
+	if !response.Ready {
+		return ctx.JSON(http.StatusServiceUnavailable, response)
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ComponentHealth handles GET /healthz
+// Reports the status of each component for monitors such as Uptime Kuma: ok, degraded when
+// an optional integration is unhealthy, or unhealthy when a critical check fails. It always
+// returns 200 while the process serves requests, so it can also back a liveness probe;
+// /readyz returns 503 when the instance should not receive traffic.
+func (c *Controller) ComponentHealth(ctx echo.Context) error {
+	readiness := c.readiness(time.Now())
+	response := ComponentHealthResponse{
+		Status:    componentStatusOK,
+		Ready:     readiness.Ready,
+		Draining:  readiness.Draining,
+		Checks:    readiness.Checks,
+		Timestamp: readiness.Timestamp,
+	}
+	for _, check := range response.Checks {
+		switch {
+		case check.Ready:
+		case check.Critical:
+			response.Status = componentStatusFailing
+		case response.Status == componentStatusOK:
+			response.Status = componentStatusDegraded
+		}
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// readiness runs all checks at now
+func (c *Controller) readiness(now time.Time) ReadinessResponse {
+	response := ReadinessResponse{
+		Checks: []ProbeCheck{
+			c.checkModel(),
+			c.checkAudio(now),
+			c.checkDatabase(),
+			c.checkMQTT(),
+			c.checkBirdWeather(),
+			c.checkJobQueue(),
+		},
+		Timestamp: now,
+	}
+	response.Draining = c.Processor != nil && c.Processor.Draining()
+
+	response.Ready = !response.Draining
+	for _, check := range response.Checks {
+		if check.Critical {
+			response.Ready = response.Ready && check.Ready
+		}
+	}
+	return response
+}
+
+// checkModel reports whether the BirdNET model is loaded
+func (c *Controller) checkModel() ProbeCheck {
+	if c.Processor == nil || c.Processor.GetBn() == nil {
+		return ProbeCheck{Name: "model", Critical: true, Message: "model not loaded"}
+	}
+	return ProbeCheck{Name: "model", Critical: true, Ready: true}
+}
+
+// checkAudio reports whether audio has been received recently from any source. Sources
+// without recent audio are listed, but only fail the check when no source is alive.
+func (c *Controller) checkAudio(now time.Time) ProbeCheck {
+	check := ProbeCheck{Name: "audio", Critical: true}
+	if c.Settings.Realtime.Audio.Source == "" && len(c.Settings.Realtime.RTSP.URLs) == 0 {
+		check.Ready = true
+		check.Message = "no audio sources configured"
+		return check
+	}
+
+	sources := captureSources(now)
+	if len(sources) > 0 {
+		check.Details = sources
+	}
+
+	last := myaudio.LastAudioTime()
+	if last.IsZero() {
+		check.Message = "no audio received yet"
+		return check
+	}
+	if since := now.Sub(last); since > audioStaleTimeout {
+		check.Message = "no audio received for " + since.Round(time.Second).String()
+		return check
+	}
+
+	check.Ready = true
+	var stale []string
+	for _, source := range sources {
+		if !source.Alive {
+			stale = append(stale, cmp.Or(source.Name, source.ID))
+		}
+	}
+	if len(stale) > 0 {
+		check.Message = fmt.Sprintf("no recent audio from %d of %d sources: %s", len(stale), len(sources), strings.Join(stale, ", "))
+	}
+	return check
+}
+
+// captureSources returns the capture state of each source with an analysis buffer
+func captureSources(now time.Time) []SourceProbe {
+	registry := myaudio.GetRegistry()
+	times := myaudio.SourceAudioTimes()
+	sources := make([]SourceProbe, 0, len(times))
+	for id, last := range times {
+		source := SourceProbe{ID: id, LastAudio: last}
+		if registry != nil {
+			if registered, ok := registry.GetSourceByID(id); ok {
+				source.Name = registered.DisplayName
+			}
+		}
+		if !last.IsZero() {
+			since := now.Sub(last)
+			source.Alive = since <= audioStaleTimeout
+			source.SecondsSinceData = since.Round(time.Second).Seconds()
+		}
+		sources = append(sources, source)
+	}
+	slices.SortFunc(sources, func(a, b SourceProbe) int { return strings.Compare(a.ID, b.ID) })
+	return sources
+}
+
+// checkDatabase reports whether the database is reachable
+func (c *Controller) checkDatabase() ProbeCheck {
+	if c.DS == nil {
+		return ProbeCheck{Name: "database", Critical: true, Message: "datastore not available"}
+	}
+	if _, err := c.DS.GetLastDetections(1); err != nil {
+		return ProbeCheck{Name: "database", Critical: true, Message: err.Error()}
+	}
+	return ProbeCheck{Name: "database", Critical: true, Ready: true}
+}
+
+// checkMQTT reports whether the MQTT client is connected when MQTT is enabled
+func (c *Controller) checkMQTT() ProbeCheck {
+	check := ProbeCheck{Name: "mqtt"}
+	if !c.Settings.Realtime.MQTT.Enabled {
+		check.Ready = true
+		check.Message = "disabled"
+		return check
+	}
+	if c.Processor == nil || c.Processor.GetMQTTClient() == nil {
+		check.Message = "client not initialized"
+		return check
+	}
+	if !c.Processor.GetMQTTClient().IsConnected() {
+		check.Message = "not connected to broker"
+		return check
+	}
+	check.Ready = true
+	return check
+}
+
+// checkBirdWeather reports the upload circuit breaker state when BirdWeather is enabled
+func (c *Controller) checkBirdWeather() ProbeCheck {
+	check := ProbeCheck{Name: "birdweather"}
+	if !c.Settings.Realtime.Birdweather.Enabled {
+		check.Ready = true
+		check.Message = "disabled"
+		return check
+	}
+	if c.Processor == nil || c.Processor.GetBwClient() == nil {
+		check.Message = "client not initialized"
+		return check
+	}
+
+	status := c.Processor.GetBwClient().CircuitState()
+	check.Details = status
+	switch status.State {
+	case birdweather.CircuitOpen:
+		check.Message = fmt.Sprintf("uploads paused after %d consecutive failures", status.ConsecutiveFailures)
+	case birdweather.CircuitHalfOpen:
+		check.Message = "retrying uploads after failures"
+	default:
+		check.Ready = true
+	}
+	return check
+}
+
+// checkJobQueue reports the depth of the detection action queue
+func (c *Controller) checkJobQueue() ProbeCheck {
+	check := ProbeCheck{Name: "jobQueue"}
+	if c.Processor == nil || c.Processor.JobQueue == nil {
+		check.Ready = true
+		check.Message = "not running"
+		return check
+	}
+
+	stats := c.Processor.JobQueue.GetStats()
+	check.Details = map[string]any{
+		"pending":     stats.PendingJobs,
+		"capacity":    stats.MaxQueueSize,
+		"utilization": stats.QueueUtilization,
+		"dropped":     stats.DroppedJobs,
+	}
+	if stats.QueueUtilization >= jobQueueHighUtilization {
+		check.Message = fmt.Sprintf("queue %.0f%% full, %d of %d jobs pending", stats.QueueUtilization, stats.PendingJobs, stats.MaxQueueSize)
+		return check
+	}
+	check.Ready = true
+	return check
+}
@@ -0,0 +1,153 @@
+// health_test.go: tests for the liveness and readiness probes
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/birdweather"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+func TestLivenessCheck(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/health/live", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.LivenessCheck(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"alive"`)
+}
+
+func TestReadinessCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		processor   *processor.Processor
+		dbErr       error
+		drain       bool
+		wantCode    int
+		wantFailing string
+	}{
+		{"ready", &processor.Processor{Bn: &birdnet.BirdNET{}}, nil, false, http.StatusOK, ""},
+		{"model not loaded", nil, nil, false, http.StatusServiceUnavailable, "model"},
+		{"database unreachable", &processor.Processor{Bn: &birdnet.BirdNET{}}, errors.NewStd("database is locked"), false, http.StatusServiceUnavailable, "database"},
+		{"draining", &processor.Processor{Bn: &birdnet.BirdNET{}}, nil, true, http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, mockDS, controller := setupTestEnvironment(t)
+			mockDS.On("GetLastDetections", 1).Return([]datastore.Note{}, tt.dbErr)
+			controller.Processor = tt.processor
+			if tt.drain {
+				tt.processor.StartDrain()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/health/ready", http.NoBody)
+			rec := httptest.NewRecorder()
+			require.NoError(t, controller.ReadinessCheck(e.NewContext(req, rec)))
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			var response ReadinessResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode == http.StatusOK, response.Ready)
+			assert.Equal(t, tt.drain, response.Draining)
+			for _, check := range response.Checks {
+				assert.Equal(t, check.Name != tt.wantFailing, check.Ready, "check %s", check.Name)
+			}
+		})
+	}
+}
+
+func TestCheckAudio(t *testing.T) {
+	_, _, controller := setupTestEnvironment(t)
+
+	check := controller.checkAudio(time.Now())
+	assert.True(t, check.Ready, "no configured sources should not block readiness")
+
+	controller.Settings.Realtime.RTSP.URLs = []string{"rtsp://camera.local/stream"}
+	check = controller.checkAudio(time.Now().Add(time.Hour))
+	assert.False(t, check.Ready, "stale or missing audio should fail readiness")
+}
+
+// probeMQTTClient is an MQTT client that only reports its connection state
+type probeMQTTClient struct {
+	mqtt.Client
+	connected bool
+}
+
+func (m *probeMQTTClient) IsConnected() bool { return m.connected }
+
+func TestComponentHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		mqttUp     bool
+		dbErr      error
+		wantStatus string
+		wantReady  bool
+	}{
+		{"ok", true, nil, componentStatusOK, true},
+		{"mqtt disconnected", false, nil, componentStatusDegraded, true},
+		{"database unreachable", true, errors.NewStd("database is locked"), componentStatusFailing, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, mockDS, controller := setupTestEnvironment(t)
+			mockDS.On("GetLastDetections", 1).Return([]datastore.Note{}, tt.dbErr)
+			controller.Settings.Realtime.MQTT.Enabled = true
+			controller.Processor = &processor.Processor{Bn: &birdnet.BirdNET{}}
+			controller.Processor.SetMQTTClient(&probeMQTTClient{connected: tt.mqttUp})
+
+			req := httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody)
+			rec := httptest.NewRecorder()
+			require.NoError(t, controller.ComponentHealth(e.NewContext(req, rec)))
+			assert.Equal(t, http.StatusOK, rec.Code, "/healthz reports failures in the body")
+
+			var response ComponentHealthResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantStatus, response.Status)
+			assert.Equal(t, tt.wantReady, response.Ready)
+
+			names := make([]string, 0, len(response.Checks))
+			for _, check := range response.Checks {
+				names = append(names, check.Name)
+			}
+			assert.Equal(t, []string{"model", "audio", "database", "mqtt", "birdweather", "jobQueue"}, names)
+
+			// Optional integrations do not affect readiness
+			rec = httptest.NewRecorder()
+			require.NoError(t, controller.ReadinessCheck(e.NewContext(req, rec)))
+			assert.Equal(t, tt.wantReady, rec.Code == http.StatusOK)
+		})
+	}
+}
+
+func TestCheckBirdWeather(t *testing.T) {
+	_, _, controller := setupTestEnvironment(t)
+
+	check := controller.checkBirdWeather()
+	assert.True(t, check.Ready, "disabled BirdWeather should be healthy")
+	assert.False(t, check.Critical)
+
+	controller.Settings.Realtime.Birdweather.Enabled = true
+	controller.Processor = &processor.Processor{}
+	check = controller.checkBirdWeather()
+	assert.False(t, check.Ready, "enabled BirdWeather without a client should be unhealthy")
+
+	controller.Processor.SetBwClient(&birdweather.BwClient{})
+	check = controller.checkBirdWeather()
+	assert.True(t, check.Ready)
+	assert.Equal(t, birdweather.CircuitClosed, check.Details.(birdweather.CircuitStatus).State)
+}
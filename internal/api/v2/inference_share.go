@@ -0,0 +1,32 @@
+// internal/api/v2/inference_share.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// InferenceShareResponse lists the inference time used by each audio source
+type InferenceShareResponse struct {
+	Sources []myaudio.InferenceShare `json:"sources"`
+}
+
+// GetInferenceShare handles GET /api/v2/system/audio/inference-share
+// Returns the share of BirdNET inference time each audio source used since startup and the
+// number of chunks analyzed, dropped and skipped as silent, to spot sources starving the
+// others and to check that the silence gate does not skip audible windows.
+func (c *Controller) GetInferenceShare(ctx echo.Context) error {
+	response := InferenceShareResponse{Sources: myaudio.GetInferenceShares()}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Inference share retrieved",
+			"sources", len(response.Sources),
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,43 @@
+// inference_share_test.go: tests for the inference share endpoint
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInferenceShare(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/system/audio/inference-share", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetInferenceShare(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"sources":[]}`, rec.Body.String(), "no inference runs without audio sources")
+}
+
+func TestValidateBirdNETSectionQueueLimit(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateBirdNETSection([]byte(`{"scheduler":{"queueLimit":3}}`)))
+	require.Error(t, validateBirdNETSection([]byte(`{"scheduler":{"queueLimit":0}}`)))
+	require.Error(t, validateBirdNETSection([]byte(`{"scheduler":{"queueLimit":21}}`)))
+	require.NoError(t, validateBirdNETSection([]byte(`{"scheduler":{"workers":4,"batchSize":2}}`)))
+	require.Error(t, validateBirdNETSection([]byte(`{"scheduler":{"workers":0}}`)))
+	require.Error(t, validateBirdNETSection([]byte(`{"scheduler":{"batchSize":9}}`)))
+}
+
+func TestGetSourceHealth(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/sources/health", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSourceHealth(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"sources":[],"healthy":0,"total":0}`, rec.Body.String(), "no sources without RTSP streams")
+}
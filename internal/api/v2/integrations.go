@@ -5,14 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/birdweather"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
 	"github.com/tphakala/birdnet-go/internal/mqtt"
 	"github.com/tphakala/birdnet-go/internal/weather"
 )
@@ -42,6 +45,12 @@ type BirdWeatherStatus struct {
 	LastError        string  `json:"last_error,omitempty"` // Most recent error message, if any issues occurred
 }
 
+// BirdWeatherRecordings lists the recorded BirdWeather API requests and responses
+type BirdWeatherRecordings struct {
+	Enabled   bool                   `json:"enabled"`   // Whether request recording is enabled
+	Exchanges []birdweather.Exchange `json:"exchanges"` // Recorded exchanges, oldest first
+}
+
 // initIntegrationsRoutes registers all integration-related API endpoints
 func (c *Controller) initIntegrationsRoutes() {
 	if c.apiLogger != nil {
@@ -54,16 +63,18 @@ func (c *Controller) initIntegrationsRoutes() {
 	// MQTT routes
 	mqttGroup := integrationsGroup.Group("/mqtt")
 	mqttGroup.GET("/status", c.GetMQTTStatus)
-	mqttGroup.POST("/test", c.TestMQTTConnection)
+	mqttGroup.POST("/test", c.TestMQTTConnection, auth.RequireAdmin)
 
 	// BirdWeather routes
 	bwGroup := integrationsGroup.Group("/birdweather")
 	bwGroup.GET("/status", c.GetBirdWeatherStatus)
-	bwGroup.POST("/test", c.TestBirdWeatherConnection)
+	bwGroup.POST("/test", c.TestBirdWeatherConnection, auth.RequireAdmin)
+	bwGroup.GET("/recordings", c.GetBirdWeatherRecordings)
+	bwGroup.DELETE("/recordings", c.ClearBirdWeatherRecordings, auth.RequireAdmin)
 
 	// Weather routes
 	weatherGroup := integrationsGroup.Group("/weather")
-	weatherGroup.POST("/test", c.TestWeatherConnection)
+	weatherGroup.POST("/test", c.TestWeatherConnection, auth.RequireAdmin)
 
 	// Other integration routes could be added here:
 	// - External media storage
@@ -205,6 +216,26 @@ func (c *Controller) GetBirdWeatherStatus(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, status)
 }
 
+// GetBirdWeatherRecordings handles GET /api/v2/integrations/birdweather/recordings
+// Returns the most recent BirdWeather API requests and responses with the station token and
+// credentials removed, so failed uploads can be diagnosed without a packet capture.
+func (c *Controller) GetBirdWeatherRecordings(ctx echo.Context) error {
+	exchanges, enabled := birdweather.RecordedExchanges()
+	return ctx.JSON(http.StatusOK, BirdWeatherRecordings{
+		Enabled:   enabled,
+		Exchanges: exchanges,
+	})
+}
+
+// ClearBirdWeatherRecordings handles DELETE /api/v2/integrations/birdweather/recordings
+func (c *Controller) ClearBirdWeatherRecordings(ctx echo.Context) error {
+	birdweather.ClearRecordedExchanges()
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Cleared BirdWeather recordings", "ip", ctx.RealIP())
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 // TestMQTTConnection handles POST /api/v2/integrations/mqtt/test
 func (c *Controller) TestMQTTConnection(ctx echo.Context) error {
 	// Get MQTT configuration from settings
@@ -379,11 +410,13 @@ func (c *Controller) TestBirdWeatherConnection(ctx echo.Context) error {
 				Threshold:        request.Threshold,
 				LocationAccuracy: request.LocationAccuracy,
 				Debug:            request.Debug,
+				Recorder:         c.Settings.Realtime.Birdweather.Recorder,
 			},
 		},
 	}
-	// Copy main settings
+	// Copy main and outbound TLS settings
 	testSettings.Main = c.Settings.Main
+	testSettings.OutboundTLS = c.Settings.OutboundTLS
 
 	// Create test BirdWeather client with the test configuration
 	client, err := birdweather.New(testSettings)
@@ -563,6 +596,7 @@ func (c *Controller) TestWeatherConnection(ctx echo.Context) error {
 				Wunderground: request.Wunderground,
 			},
 		},
+		OutboundTLS: c.Settings.OutboundTLS,
 	}
 
 	// Create test context with timeout
@@ -645,6 +679,16 @@ func (c *Controller) TestWeatherConnection(ctx echo.Context) error {
 	return nil
 }
 
+// newWeatherTestHTTPClient returns the HTTP client of weather connection tests, using the
+// outbound proxy and TLS settings being tested
+func newWeatherTestHTTPClient(settings *conf.Settings) (*http.Client, error) {
+	return httpclient.New(settings, httpclient.Options{
+		Integration:        "weather",
+		Timeout:            5 * time.Second,
+		InsecureSkipVerify: settings.OutboundTLS.Weather.InsecureSkipVerify,
+	})
+}
+
 // testWeatherAPIConnectivity tests basic connectivity to the weather API
 func (c *Controller) testWeatherAPIConnectivity(ctx context.Context, settings *conf.Settings) (string, error) {
 	var testURL string
@@ -657,11 +701,18 @@ func (c *Controller) testWeatherAPIConnectivity(ctx context.Context, settings *c
 		testURL = "https://api.openweathermap.org"
 	case "wunderground":
 		testURL = "https://api.weather.com"
+	case "openmeteo":
+		testURL = "https://api.open-meteo.com"
+	case "mqtt":
+		return "Local sensor readings are received from the configured MQTT broker", nil
 	default:
 		return "", fmt.Errorf("unsupported weather provider: %s", provider)
 	}
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client, err := newWeatherTestHTTPClient(settings)
+	if err != nil {
+		return "", err
+	}
 	req, err := http.NewRequestWithContext(ctx, "GET", testURL, http.NoBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -695,7 +746,10 @@ func (c *Controller) testWeatherAuthentication(ctx context.Context, settings *co
 
 		testURL := fmt.Sprintf("%s?lat=0&lon=0&appid=%s", endpoint, apiKey)
 
-		client := &http.Client{Timeout: 5 * time.Second}
+		client, err := newWeatherTestHTTPClient(settings)
+		if err != nil {
+			return "", err
+		}
 		req, err := http.NewRequestWithContext(ctx, "GET", testURL, http.NoBody)
 		if err != nil {
 			return "", fmt.Errorf("failed to create authentication request: %w", err)
@@ -730,6 +784,11 @@ func (c *Controller) testWeatherAuthentication(ctx context.Context, settings *co
 
 // testWeatherDataFetch tests fetching actual weather data
 func (c *Controller) testWeatherDataFetch(ctx context.Context, settings *conf.Settings) (string, error) {
+	client, err := weather.NewHTTPClient(settings)
+	if err != nil {
+		return "", err
+	}
+
 	var provider weather.Provider
 	switch settings.Realtime.Weather.Provider {
 	case "yrno":
@@ -737,11 +796,24 @@ func (c *Controller) testWeatherDataFetch(ctx context.Context, settings *conf.Se
 	case "openweather":
 		provider = weather.NewOpenWeatherProvider()
 	case "wunderground":
-		provider = weather.NewWundergroundProvider(nil)
+		provider = weather.NewWundergroundProvider(client)
+	case "openmeteo":
+		provider = weather.NewOpenMeteoProvider(client)
+	case "mqtt":
+		return c.testWeatherSensorReading(ctx, settings)
 	default:
 		return "", fmt.Errorf("unsupported weather provider: %s", settings.Realtime.Weather.Provider)
 	}
 
+	// Release connections held by the test provider
+	if closer, ok := provider.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				c.logger.Printf("warning: failed to close weather provider: %v", err)
+			}
+		}()
+	}
+
 	weatherData, err := provider.FetchWeather(settings)
 	if err != nil {
 		// Extract the actual error message instead of wrapping it
@@ -761,6 +833,31 @@ func (c *Controller) testWeatherDataFetch(ctx context.Context, settings *conf.Se
 		weatherData.Description), nil
 }
 
+// weatherSensorTestWait limits how long a weather sensor test waits for a reading
+const weatherSensorTestWait = 3 * time.Second
+
+// testWeatherSensorReading tests the local MQTT sensor by connecting and subscribing to its
+// topic. Sensors publish on their own schedule, so only a reading the broker retained or one
+// arriving within a short wait can be shown; a connection without a reading still passes.
+func (c *Controller) testWeatherSensorReading(ctx context.Context, settings *conf.Settings) (string, error) {
+	provider := weather.NewMQTTProvider()
+	defer func() {
+		if err := provider.Close(); err != nil {
+			c.logger.Printf("warning: failed to close weather provider: %v", err)
+		}
+	}()
+
+	weatherData, err := provider.TestConnection(ctx, settings, weatherSensorTestWait)
+	if err != nil {
+		return "", err
+	}
+	if weatherData == nil {
+		return fmt.Sprintf("Subscribed to %s, no reading received yet. Readings appear once the sensor publishes",
+			settings.Realtime.Weather.MQTT.Topic), nil
+	}
+	return fmt.Sprintf("Successfully received weather sensor reading. Temperature: %.1f°", weatherData.Temperature.Current), nil
+}
+
 // getProviderDisplayName returns a user-friendly name for the weather provider
 func getProviderDisplayName(provider string) string {
 	switch provider {
@@ -770,6 +867,10 @@ func getProviderDisplayName(provider string) string {
 		return "OpenWeather"
 	case "wunderground":
 		return "Weather Underground"
+	case "openmeteo":
+		return "Open-Meteo"
+	case "mqtt":
+		return "local MQTT sensor"
 	default:
 		// Simple capitalization for unknown providers
 		if provider != "" {
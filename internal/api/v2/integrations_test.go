@@ -123,10 +123,12 @@ func TestInitIntegrationsRoutesRegistration(t *testing.T) {
 
 	// Define the integration routes we expect to find
 	expectedRoutes := map[string]bool{
-		"GET /api/v2/integrations/mqtt/status":        false,
-		"POST /api/v2/integrations/mqtt/test":         false,
-		"GET /api/v2/integrations/birdweather/status": false,
-		"POST /api/v2/integrations/birdweather/test":  false,
+		"GET /api/v2/integrations/mqtt/status":               false,
+		"POST /api/v2/integrations/mqtt/test":                false,
+		"GET /api/v2/integrations/birdweather/status":        false,
+		"POST /api/v2/integrations/birdweather/test":         false,
+		"GET /api/v2/integrations/birdweather/recordings":    false,
+		"DELETE /api/v2/integrations/birdweather/recordings": false,
 	}
 
 	// Check each route
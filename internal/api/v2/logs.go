@@ -0,0 +1,148 @@
+// internal/api/v2/logs.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/logging"
+)
+
+// Limits of the number of log entries returned by GetLogs
+const (
+	defaultLogEntriesLimit = 200
+	maxLogEntriesLimit     = 1000
+)
+
+// LogsResponse is the result of a log query
+type LogsResponse struct {
+	Entries []logging.Entry `json:"entries"`
+	Count   int             `json:"count"`
+	Limit   int             `json:"limit"`
+}
+
+// initLogRoutes registers the log viewer and log level endpoints. Logs can contain station
+// details, so they are restricted to administrators.
+func (c *Controller) initLogRoutes() {
+	logGroup := c.Group.Group("/logs", c.getEffectiveAuthMiddleware(), auth.RequireAdmin)
+	logGroup.GET("", c.GetLogs)
+	logGroup.GET("/services", c.GetLogServices)
+	logGroup.GET("/levels", c.GetLogLevels)
+	logGroup.PUT("/levels/:component", c.SetLogLevel)
+}
+
+// LogLevelRequest changes the log level of a component
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// GetLogs handles GET /api/v2/logs
+// Returns the recent entries of the service log files, newest first.
+// Query parameters:
+// - service: service name
+// - level: minimum level (TRACE, DEBUG, INFO, WARN, ERROR or FATAL)
+// - from, to: time range in RFC3339 format
+// - q: text searched in the message and attribute values
+// - limit: maximum number of entries (default: 200, max: 1000)
+func (c *Controller) GetLogs(ctx echo.Context) error {
+	query := logging.Query{
+		Service: ctx.QueryParam("service"),
+		Level:   ctx.QueryParam("level"),
+		Text:    ctx.QueryParam("q"),
+		Limit:   defaultLogEntriesLimit,
+	}
+
+	if query.Level != "" && !logging.IsLevelName(query.Level) {
+		return c.HandleError(ctx, fmt.Errorf("unknown log level %q", query.Level), "Invalid level parameter", http.StatusBadRequest)
+	}
+
+	var err error
+	if query.From, err = parseLogTime(ctx.QueryParam("from")); err != nil {
+		return c.HandleError(ctx, err, "Invalid from parameter, expected RFC3339 time", http.StatusBadRequest)
+	}
+	if query.To, err = parseLogTime(ctx.QueryParam("to")); err != nil {
+		return c.HandleError(ctx, err, "Invalid to parameter, expected RFC3339 time", http.StatusBadRequest)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return c.HandleError(ctx, fmt.Errorf("to is before from"), "Invalid time range", http.StatusBadRequest)
+	}
+
+	if limitParam := ctx.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return c.HandleError(ctx, fmt.Errorf("invalid limit %q", limitParam), "Invalid limit parameter", http.StatusBadRequest)
+		}
+		query.Limit = min(limit, maxLogEntriesLimit)
+	}
+
+	entries := logging.QueryEntries(query)
+	return ctx.JSON(http.StatusOK, LogsResponse{
+		Entries: entries,
+		Count:   len(entries),
+		Limit:   query.Limit,
+	})
+}
+
+// GetLogServices handles GET /api/v2/logs/services
+// Lists the services with recent log entries, for the service filter of the log viewer.
+func (c *Controller) GetLogServices(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"services": logging.IndexedServices(),
+	})
+}
+
+// GetLogLevels handles GET /api/v2/logs/levels
+// Returns the current log level of every component.
+func (c *Controller) GetLogLevels(ctx echo.Context) error {
+	levels := make(map[string]string)
+	for component, level := range logging.ComponentLevels() {
+		levels[component] = logging.LevelName(level)
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"levels": levels,
+	})
+}
+
+// SetLogLevel handles PUT /api/v2/logs/levels/:component
+// Changes the log level of a component until restart. Levels kept across restarts are
+// configured in the logging settings section.
+func (c *Controller) SetLogLevel(ctx echo.Context) error {
+	component := ctx.Param("component")
+
+	var req LogLevelRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid level", http.StatusBadRequest)
+	}
+
+	if err := logging.SetComponentLevel(component, level); err != nil {
+		return c.HandleError(ctx, err, "Unknown log component", http.StatusNotFound)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Log level changed",
+			"component", component,
+			"log_level", logging.LevelName(level),
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"component": component,
+		"level":     logging.LevelName(level),
+	})
+}
+
+// parseLogTime parses an optional RFC3339 time parameter
+func parseLogTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,107 @@
+// logs_test.go: tests for the log viewer endpoints
+
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/logging"
+)
+
+func TestLogEndpoints(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	logger, closeLogger, err := logging.NewFileLogger(filepath.Join(t.TempDir(), "viewer.log"), "log-viewer-test", new(slog.LevelVar))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeLogger() })
+	logger.Info("capture started", "source", "sysdefault")
+	logger.Error("capture failed", "source", "sysdefault")
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		rec := httptest.NewRecorder()
+		handler := controller.GetLogs
+		if target == "/api/v2/logs/services" {
+			handler = controller.GetLogServices
+		}
+		require.NoError(t, handler(e.NewContext(req, rec)))
+		return rec
+	}
+
+	rec := get("/api/v2/logs?service=log-viewer-test&level=error")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var logs LogsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &logs))
+	require.Equal(t, 1, logs.Count)
+	assert.Equal(t, "capture failed", logs.Entries[0].Message)
+	assert.Equal(t, defaultLogEntriesLimit, logs.Limit)
+
+	rec = get("/api/v2/logs?service=log-viewer-test&q=STARTED&limit=5000")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &logs))
+	require.Equal(t, 1, logs.Count)
+	assert.Equal(t, "capture started", logs.Entries[0].Message)
+	assert.Equal(t, maxLogEntriesLimit, logs.Limit, "limit is capped")
+
+	for _, target := range []string{
+		"/api/v2/logs?level=verbose",
+		"/api/v2/logs?from=yesterday",
+		"/api/v2/logs?from=2025-05-02T00:00:00Z&to=2025-05-01T00:00:00Z",
+		"/api/v2/logs?limit=0",
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(target).Code, target)
+	}
+
+	rec = get("/api/v2/logs/services")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var services struct {
+		Services []string `json:"services"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &services))
+	assert.Contains(t, services.Services, "log-viewer-test")
+}
+
+func TestLogLevelEndpoints(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	t.Cleanup(func() { _ = logging.ApplyComponentLevels(nil) })
+
+	levelVar := new(slog.LevelVar)
+	logging.RegisterLevel("level-endpoint-test", levelVar)
+
+	setLevel := func(component, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v2/logs/levels/"+component, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("component")
+		ctx.SetParamValues(component)
+		require.NoError(t, controller.SetLogLevel(ctx))
+		return rec
+	}
+
+	rec := setLevel("level-endpoint-test", `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, slog.LevelDebug, levelVar.Level())
+
+	assert.Equal(t, http.StatusBadRequest, setLevel("level-endpoint-test", `{"level":"verbose"}`).Code)
+	assert.Equal(t, http.StatusNotFound, setLevel("not-registered", `{"level":"debug"}`).Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/logs/levels", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetLogLevels(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var levels struct {
+		Levels map[string]string `json:"levels"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &levels))
+	assert.Equal(t, "DEBUG", levels.Levels["level-endpoint-test"])
+}
@@ -21,10 +21,14 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/logging"
 	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/resources"
 	"github.com/tphakala/birdnet-go/internal/securefs"
 	"golang.org/x/sync/singleflight"
 )
@@ -110,8 +114,9 @@ var (
 	ErrSpectrogramGeneration = errors.NewStd("failed to generate spectrogram")
 
 	// Image errors
-	ErrImageNotFound             = errors.NewStd("image not found")
-	ErrImageProviderNotAvailable = errors.NewStd("image provider not available")
+	ErrImageNotFound                 = errors.NewStd("image not found")
+	ErrImageProviderNotAvailable     = errors.NewStd("image provider not available")
+	ErrAttributionLedgerNotAvailable = errors.NewStd("image attribution ledger not available")
 
 	// Sentinel errors for nilnil cases
 	ErrSpectrogramExists       = errors.NewStd("spectrogram already exists")
@@ -142,15 +147,23 @@ func (c *Controller) initMediaRoutes() {
 	c.Group.GET("/media/spectrogram/:filename", c.ServeSpectrogram)
 
 	// ID-based routes using SFS
-	c.Echo.GET("/api/v2/audio/:id", c.ServeAudioByID)
-	c.Echo.GET("/api/v2/spectrogram/:id", c.ServeSpectrogramByID)
-	c.Echo.GET("/api/v2/spectrogram/:id/status", c.GetSpectrogramStatus)
+	c.Echo.GET("/api/v2/audio/snapshot", c.GetAudioSnapshot, c.getEffectiveAuthMiddleware())
+	c.Echo.GET("/api/v2/audio/:id", c.ServeAudioByID, c.resolveNoteID)
+	c.Echo.GET("/api/v2/audio/:id/stream", c.StreamAudioByID, c.getEffectiveAuthMiddleware(), c.resolveNoteID)
+	c.Echo.GET("/api/v2/spectrogram/:id", c.ServeSpectrogramByID, c.resolveNoteID)
+	c.Echo.GET("/api/v2/spectrogram/:id/status", c.GetSpectrogramStatus, c.resolveNoteID)
 
 	// Convenient combined endpoint (redirects to ID-based internally)
 	c.Group.GET("/media/audio", c.ServeAudioByQueryID)
 
 	// Bird image endpoint
 	c.Group.GET("/media/species-image", c.GetSpeciesImage)
+	c.Group.GET("/media/species-image/local/:file", c.ServeLocalSpeciesImage)
+	c.Group.DELETE("/media/species-image", c.PurgeSpeciesImage, c.getEffectiveAuthMiddleware(), auth.RequireAdmin)
+	c.Group.POST("/media/species-image/refresh", c.RefreshSpeciesImage, c.getEffectiveAuthMiddleware(), auth.RequireAdmin)
+
+	// Image attribution ledger for CC license compliance
+	c.Group.GET("/media/attributions", c.GetImageAttributions)
 
 	if c.apiLogger != nil {
 		c.apiLogger.Info("Media routes initialized successfully")
@@ -624,10 +637,10 @@ func (c *Controller) ServeAudioByQueryID(ctx echo.Context) error {
 		return c.HandleError(ctx, fmt.Errorf("missing ID"), "Note ID is required as query parameter", http.StatusBadRequest)
 	}
 
-	// Delegate to the ID handler
+	// Delegate to the ID handler, resolving a provisional ID of a live detection
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(noteID)
-	return c.ServeAudioByID(ctx)
+	return c.resolveNoteID(c.ServeAudioByID)(ctx)
 }
 
 // ServeSpectrogram serves a spectrogram image by filename using SecureFS
@@ -755,10 +768,11 @@ func (c *Controller) GetSpectrogramStatus(ctx echo.Context) error {
 }
 
 // maxConcurrentSpectrograms limits concurrent spectrogram generations to avoid overloading the system.
-// Set to 4 to match the number of CPU cores on Raspberry Pi 4/5, which is the most common
-// deployment platform for BirdNET-Go. This prevents severe CPU contention and ensures
-// responsive performance on resource-constrained devices.
-const maxConcurrentSpectrograms = 4
+// Capped at 4 to match the number of CPU cores on Raspberry Pi 4/5, which is the most common
+// deployment platform for BirdNET-Go, and lowered further when a container CPU quota allows
+// fewer cores. This prevents severe CPU contention and ensures responsive performance on
+// resource-constrained devices.
+var maxConcurrentSpectrograms = resources.Detect().FFmpegConcurrency()
 
 // spectrogramRetryAfterSeconds is the suggested retry delay in seconds for 503 responses
 // when audio files are not yet ready for processing
@@ -1937,8 +1951,152 @@ func (c *Controller) GetSpeciesImage(ctx echo.Context) error {
 	// Cache with immutable flag to prevent revalidation
 	ctx.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", ImageCacheSeconds))
 
+	// Record license/author usage in the attribution ledger
+	c.BirdImageCache.RecordUsage(&birdImage, imageprovider.UsageWeb)
+
+	// Serve the image from the disk cache if enabled, falling back to the redirect
+	if diskCache := c.BirdImageCache.GetDiskCache(); diskCache != nil && diskCache.Cacheable(&birdImage) {
+		file, err := diskCache.File(ctx.Request().Context(), &birdImage)
+		if err == nil {
+			return ctx.File(file)
+		}
+		if c.apiLogger != nil {
+			c.apiLogger.Warn("Failed to serve species image from disk cache",
+				"scientific_name", scientificName,
+				"provider", birdImage.SourceProvider,
+				"error", err.Error())
+		}
+	}
+
 	// Redirect to the image URL
 	return ctx.Redirect(http.StatusFound, birdImage.URL)
 }
 
+// ServeLocalSpeciesImage serves a custom species photo from the local image folder
+func (c *Controller) ServeLocalSpeciesImage(ctx echo.Context) error {
+	file, err := imageprovider.LocalImagePath(c.Settings.Realtime.Dashboard.Thumbnails.LocalPath, ctx.Param("file"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Image not found", http.StatusNotFound)
+	}
+
+	// Local photos may be replaced at any time, revalidate them by modification time
+	ctx.Response().Header().Set("Cache-Control", "no-cache")
+	return ctx.File(file)
+}
+
+// speciesImageName returns the trimmed scientific name query parameter of species image requests
+func speciesImageName(ctx echo.Context) (string, bool) {
+	scientificName := strings.TrimSpace(ctx.QueryParam("name"))
+	return scientificName, scientificName != ""
+}
+
+// purgeSpeciesImage removes the cached image of a species from all registered image providers
+func (c *Controller) purgeSpeciesImage(scientificName string) error {
+	if registry := c.BirdImageCache.GetRegistry(); registry != nil {
+		return registry.Purge(scientificName)
+	}
+	return c.BirdImageCache.Purge(scientificName)
+}
+
+// PurgeSpeciesImage removes the cached image of a species from all image providers, so the
+// next request fetches it again
+func (c *Controller) PurgeSpeciesImage(ctx echo.Context) error {
+	scientificName, ok := speciesImageName(ctx)
+	if !ok {
+		return c.HandleError(ctx, fmt.Errorf("missing scientific name"), "Scientific name is required", http.StatusBadRequest)
+	}
+	if c.BirdImageCache == nil {
+		return c.HandleError(ctx, ErrImageProviderNotAvailable, "Image service unavailable", http.StatusServiceUnavailable)
+	}
+
+	if err := c.purgeSpeciesImage(scientificName); err != nil {
+		return c.HandleError(ctx, err, "Failed to purge species image", http.StatusInternalServerError)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Purged cached species image", "scientific_name", scientificName)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// RefreshSpeciesImage purges the cached image of a species and fetches it again
+func (c *Controller) RefreshSpeciesImage(ctx echo.Context) error {
+	scientificName, ok := speciesImageName(ctx)
+	if !ok {
+		return c.HandleError(ctx, fmt.Errorf("missing scientific name"), "Scientific name is required", http.StatusBadRequest)
+	}
+	if c.BirdImageCache == nil {
+		return c.HandleError(ctx, ErrImageProviderNotAvailable, "Image service unavailable", http.StatusServiceUnavailable)
+	}
+
+	if err := c.purgeSpeciesImage(scientificName); err != nil {
+		return c.HandleError(ctx, err, "Failed to purge species image", http.StatusInternalServerError)
+	}
+	birdImage, err := c.BirdImageCache.Get(scientificName)
+	if err != nil {
+		if errors.Is(err, imageprovider.ErrImageNotFound) {
+			return c.HandleError(ctx, err, "Image not found for species", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to refresh species image", http.StatusInternalServerError)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Refreshed species image", "scientific_name", scientificName, "provider", birdImage.SourceProvider)
+	return ctx.JSON(http.StatusOK, ImageAttribution{
+		ScientificName: birdImage.ScientificName,
+		SourceProvider: birdImage.SourceProvider,
+		URL:            birdImage.URL,
+		LicenseName:    birdImage.LicenseName,
+		LicenseURL:     birdImage.LicenseURL,
+		AuthorName:     birdImage.AuthorName,
+		AuthorURL:      birdImage.AuthorURL,
+	})
+}
+
+// ImageAttribution is the API representation of an attribution ledger entry
+type ImageAttribution struct {
+	ScientificName string    `json:"scientificName"`
+	SourceProvider string    `json:"sourceProvider"`
+	URL            string    `json:"url"`
+	LicenseName    string    `json:"licenseName"`
+	LicenseURL     string    `json:"licenseUrl"`
+	AuthorName     string    `json:"authorName"`
+	AuthorURL      string    `json:"authorUrl"`
+	LastUsage      string    `json:"lastUsage"`
+	UsageCount     int       `json:"usageCount"`
+	FirstUsedAt    time.Time `json:"firstUsedAt"`
+	LastUsedAt     time.Time `json:"lastUsedAt"`
+}
+
+// GetImageAttributions returns license and author details for species images that
+// have been displayed or shared, optionally filtered by the "species" query parameter
+func (c *Controller) GetImageAttributions(ctx echo.Context) error {
+	ledger, ok := c.DS.(datastore.AttributionLedger)
+	if !ok {
+		return c.HandleError(ctx, ErrAttributionLedgerNotAvailable, "Attribution ledger unavailable", http.StatusServiceUnavailable)
+	}
+
+	species := strings.TrimSpace(ctx.QueryParam("species"))
+	entries, err := ledger.GetImageAttributions(species)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to retrieve image attributions", http.StatusInternalServerError)
+	}
+
+	attributions := make([]ImageAttribution, 0, len(entries))
+	for i := range entries {
+		attributions = append(attributions, ImageAttribution{
+			ScientificName: entries[i].ScientificName,
+			SourceProvider: entries[i].SourceProvider,
+			URL:            entries[i].URL,
+			LicenseName:    entries[i].LicenseName,
+			LicenseURL:     entries[i].LicenseURL,
+			AuthorName:     entries[i].AuthorName,
+			AuthorURL:      entries[i].AuthorURL,
+			LastUsage:      entries[i].LastUsage,
+			UsageCount:     entries[i].UsageCount,
+			FirstUsedAt:    entries[i].FirstUsedAt,
+			LastUsedAt:     entries[i].LastUsedAt,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, attributions)
+}
+
 // HandleError method should exist on Controller, typically defined in controller.go or api.go
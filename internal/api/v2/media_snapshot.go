@@ -0,0 +1,107 @@
+// internal/api/v2/media_snapshot.go
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Capture buffer snapshot limits
+const (
+	snapshotDefaultSeconds = 10               // Length of a snapshot when no length is requested
+	snapshotEncodeTimeout  = 30 * time.Second // Limits how long encoding a snapshot may take
+	snapshotCaptureTimeout = 5 * time.Second  // Limits the wait for the latest audio of a stalled source
+)
+
+// ErrSnapshotSourceRequired is returned when a snapshot request does not select one of several sources
+var ErrSnapshotSourceRequired = errors.NewStd("audio source is required")
+
+// GetAudioSnapshot returns the last seconds of audio captured from a source as a WAV or FLAC
+// download, for listening back to a sound that did not result in a detection.
+// Route: GET /api/v2/audio/snapshot
+// Query parameters: source (optional when only one source is capturing), seconds (optional,
+// default 10, at most the capture buffer length), format (wav or flac, default wav)
+func (c *Controller) GetAudioSnapshot(ctx echo.Context) error {
+	sourceID := strings.TrimSpace(ctx.QueryParam("source"))
+	if sourceID == "" {
+		sources := myaudio.GetCaptureBufferSources()
+		if len(sources) != 1 {
+			return c.HandleError(ctx, ErrSnapshotSourceRequired,
+				fmt.Sprintf("Audio source is required, %d sources are capturing", len(sources)), http.StatusBadRequest)
+		}
+		sourceID = sources[0]
+	}
+
+	bufferDuration, exists := myaudio.GetCaptureBufferDuration(sourceID)
+	if !exists {
+		return c.HandleError(ctx, fmt.Errorf("no capture buffer for source"), "Audio source not found", http.StatusNotFound)
+	}
+	maxSeconds := int(bufferDuration.Seconds())
+
+	seconds := snapshotDefaultSeconds
+	if secondsParam := ctx.QueryParam("seconds"); secondsParam != "" {
+		parsed, err := strconv.Atoi(secondsParam)
+		if err != nil || parsed < 1 || parsed > maxSeconds {
+			return c.HandleError(ctx, fmt.Errorf("invalid seconds: %s", secondsParam),
+				fmt.Sprintf("Seconds must be between 1 and %d", maxSeconds), http.StatusBadRequest)
+		}
+		seconds = parsed
+	}
+	seconds = min(seconds, maxSeconds)
+
+	format := strings.ToLower(ctx.QueryParam("format"))
+	if format == "" {
+		format = "wav"
+	}
+	if format != "wav" && format != "flac" {
+		return c.HandleError(ctx, fmt.Errorf("unsupported snapshot format: %s", format),
+			"Format must be wav or flac", http.StatusBadRequest)
+	}
+
+	end := time.Now()
+	pcmData, err := myaudio.ReadSegmentFromCaptureBuffer(ctx.Request().Context(), sourceID,
+		end.Add(-time.Duration(seconds)*time.Second), seconds, snapshotCaptureTimeout)
+	if err != nil {
+		return c.HandleError(ctx, err, "Requested audio is not available in the capture buffer yet", http.StatusConflict)
+	}
+
+	encodeCtx, cancel := context.WithTimeout(ctx.Request().Context(), snapshotEncodeTimeout)
+	defer cancel()
+
+	var audio *bytes.Buffer
+	mimeType := MimeTypeWAV
+	if format == "flac" {
+		mimeType = MimeTypeFLAC
+		audio, err = myaudio.EncodeFLAC(encodeCtx, "snapshot_flac", pcmData, 0)
+	} else {
+		audio, err = myaudio.EncodePCMtoWAVWithContext(encodeCtx, pcmData)
+	}
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to encode audio snapshot", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Audio snapshot served",
+			"seconds", seconds,
+			"format", format,
+			"bytes", audio.Len(),
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	filename := fmt.Sprintf("snapshot_%s.%s", end.Format("20060102T150405"), format)
+	header := ctx.Response().Header()
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	header.Set("Cache-Control", "no-store")
+	return ctx.Blob(http.StatusOK, mimeType, audio.Bytes())
+}
@@ -0,0 +1,82 @@
+// media_snapshot_test.go: tests for the capture buffer snapshot endpoint
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+func TestGetAudioSnapshot(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	const sourceID = "snapshot_test_source"
+	require.NoError(t, myaudio.AllocateCaptureBuffer(10, conf.SampleRate, conf.BitDepth/8, sourceID))
+	t.Cleanup(func() { _ = myaudio.RemoveCaptureBuffer(sourceID) })
+
+	// Two seconds of audio, then wait until a full second lies behind the buffer start while the
+	// source keeps delivering audio, as snapshots wait for the audio up to the request
+	require.NoError(t, myaudio.WriteToCaptureBuffer(sourceID, make([]byte, 2*conf.SampleRate*conf.BitDepth/8)))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				_ = myaudio.WriteToCaptureBuffer(sourceID, make([]byte, conf.SampleRate*conf.BitDepth/8/50))
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+	time.Sleep(1100 * time.Millisecond)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/snapshot?"+query, http.NoBody)
+		rec := httptest.NewRecorder()
+		require.NoError(t, controller.GetAudioSnapshot(e.NewContext(req, rec)))
+		return rec
+	}
+
+	rec := get("source=" + sourceID + "&seconds=1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, MimeTypeWAV, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment; filename=\"snapshot_")
+	assert.Equal(t, "RIFF", rec.Body.String()[:4])
+	assert.Equal(t, 44+conf.SampleRate*conf.BitDepth/8, rec.Body.Len(), "one second of 16-bit mono PCM plus the WAV header")
+
+	rec = get("source=" + sourceID + "&seconds=1&format=flac")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, MimeTypeFLAC, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "fLaC", rec.Body.String()[:4], "FLAC snapshots are encoded natively without FFmpeg")
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"unknown source", "source=missing", http.StatusNotFound},
+		{"seconds below range", "source=" + sourceID + "&seconds=0", http.StatusBadRequest},
+		{"seconds beyond buffer", "source=" + sourceID + "&seconds=11", http.StatusBadRequest},
+		{"unsupported format", "source=" + sourceID + "&format=mp3", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, get(tt.query).Code)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// internal/api/v2/media_stream.go
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"golang.org/x/sync/singleflight"
+)
+
+// streamFormats maps the formats clips can be transcoded to for streaming to their MIME types
+var streamFormats = map[string]string{
+	"mp3":  MimeTypeMP3,
+	"opus": MimeTypeOGG,
+}
+
+// Streaming transcode settings
+const (
+	streamDefaultBitrate = "128k"         // Bitrate used when no export bitrate is configured
+	streamCacheMaxAge    = 24 * time.Hour // Transcoded clips older than this are removed
+)
+
+var (
+	streamSemaphore = make(chan struct{}, maxConcurrentSpectrograms)
+	streamGroup     singleflight.Group // Prevents duplicate transcodes of the same clip
+
+	// ErrUnsupportedStreamFormat is returned for stream formats clips cannot be transcoded to
+	ErrUnsupportedStreamFormat = errors.NewStd("unsupported stream format")
+)
+
+// StreamAudioByID streams the audio clip of a detection with HTTP range support. Without a
+// format the stored clip is served as is; with ?format=mp3 or ?format=opus the clip is
+// transcoded once with FFmpeg and the transcoded copy is served, so browsers can seek within
+// long clips without downloading them in full.
+// Route: GET /api/v2/audio/:id/stream
+func (c *Controller) StreamAudioByID(ctx echo.Context) error {
+	format := strings.ToLower(strings.TrimSpace(ctx.QueryParam("format")))
+	if format == "" {
+		return c.ServeAudioByID(ctx)
+	}
+	mimeType, ok := streamFormats[format]
+	if !ok {
+		return c.HandleError(ctx, ErrUnsupportedStreamFormat,
+			fmt.Sprintf("Unsupported format %q, supported formats are mp3 and opus", format), http.StatusBadRequest)
+	}
+
+	noteID := ctx.Param("id")
+	if noteID == "" {
+		return c.HandleError(ctx, fmt.Errorf("missing ID"), "Note ID is required", http.StatusBadRequest)
+	}
+
+	clipPath, err := c.DS.GetNoteClipPath(noteID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || strings.Contains(err.Error(), "not found") {
+			return c.HandleError(ctx, err, "Clip path not found for note ID", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to get clip path for note", http.StatusInternalServerError)
+	}
+	if clipPath == "" {
+		return c.HandleError(ctx, fmt.Errorf("no audio file found"), "No audio clip available for this note", http.StatusNotFound)
+	}
+
+	relClipPath, err := c.normalizeAndValidatePathWithLogger(clipPath, c.apiLogger)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid clip path", http.StatusBadRequest)
+	}
+
+	// Clips already stored in the requested format need no transcoding
+	if strings.EqualFold(strings.TrimPrefix(filepath.Ext(relClipPath), "."), format) {
+		return c.ServeAudioByID(ctx)
+	}
+
+	clipInfo, err := c.SFS.StatRel(relClipPath)
+	if err != nil {
+		return c.translateSecureFSError(ctx, err, "Failed to access audio clip")
+	}
+
+	if c.Settings.Realtime.Audio.FfmpegPath == "" {
+		return c.HandleError(ctx, ErrFFmpegNotConfigured, "Transcoding is not available, FFmpeg is not configured", http.StatusServiceUnavailable)
+	}
+
+	absClipPath := filepath.Join(c.SFS.BaseDir(), relClipPath)
+	streamPath := streamCachePath(relClipPath, clipInfo.ModTime(), format)
+	if err := c.transcodeForStream(ctx.Request().Context(), absClipPath, streamPath, format); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return ctx.NoContent(StatusClientClosedRequest)
+		}
+		return c.HandleError(ctx, err, "Failed to transcode audio clip", http.StatusInternalServerError)
+	}
+
+	file, err := os.Open(streamPath) //nolint:gosec // G304: path is derived from a hash inside the stream cache directory
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to open transcoded audio clip", http.StatusInternalServerError)
+	}
+	defer func() { _ = file.Close() }()
+
+	streamName := strings.TrimSuffix(filepath.Base(relClipPath), filepath.Ext(relClipPath)) + "." + format
+	header := ctx.Response().Header()
+	header.Set("Content-Type", mimeType)
+	header.Set("Accept-Ranges", "bytes")
+	if isValidFilename(streamName) {
+		header.Set("Content-Disposition", fmt.Sprintf("inline; filename*=UTF-8''%s", url.QueryEscape(streamName)))
+	}
+
+	// http.ServeContent answers Range and conditional requests
+	http.ServeContent(ctx.Response(), ctx.Request(), streamName, clipInfo.ModTime(), file)
+	return nil
+}
+
+// transcodeForStream transcodes a clip into the stream cache unless a transcoded copy exists
+func (c *Controller) transcodeForStream(ctx context.Context, absClipPath, streamPath, format string) error {
+	if _, err := os.Stat(streamPath); err == nil {
+		return nil
+	}
+
+	_, err, _ := streamGroup.Do(streamPath, func() (any, error) {
+		if _, err := os.Stat(streamPath); err == nil {
+			return nil, nil
+		}
+
+		select {
+		case streamSemaphore <- struct{}{}:
+			defer func() { <-streamSemaphore }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		bitrate := c.Settings.Realtime.Audio.Export.Bitrate
+		if bitrate == "" {
+			bitrate = streamDefaultBitrate
+		}
+		// Transcoding is not tied to the request so a canceled request does not waste the work
+		if err := myaudio.TranscodeAudioFile(context.Background(), c.Settings.Realtime.Audio.FfmpegPath,
+			absClipPath, streamPath, format, bitrate); err != nil {
+			return nil, err
+		}
+
+		pruneStreamCache(filepath.Dir(streamPath), streamCacheMaxAge)
+		return nil, nil
+	})
+	return err
+}
+
+// streamCacheDir returns the directory transcoded clips are cached in
+func streamCacheDir() string {
+	return filepath.Join(os.TempDir(), "birdnet-go-stream")
+}
+
+// streamCachePath returns the cache path of a transcoded clip. The modification time of the
+// clip is part of the key so a replaced clip is transcoded again.
+func streamCachePath(relClipPath string, modTime time.Time, format string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%d|%s", filepath.ToSlash(relClipPath), modTime.UnixNano(), format))
+	return filepath.Join(streamCacheDir(), hex.EncodeToString(sum[:16])+"."+format)
+}
+
+// pruneStreamCache removes transcoded clips that have not been written for longer than maxAge
+func pruneStreamCache(dir string, maxAge time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, entry.Name()))
+	}
+}
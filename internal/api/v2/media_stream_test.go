@@ -0,0 +1,120 @@
+// media_stream_test.go: Tests for the audio clip streaming endpoint
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStreamAudioByID tests range requests and format validation of the streaming endpoint
+func TestStreamAudioByID(t *testing.T) {
+	e, controller, tempDir := setupMediaTestEnvironment(t)
+
+	testFilename := "2024-01-15_14-30-45_Turdus_migratorius.wav"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, testFilename), []byte("0123456789abcdef"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "clip.mp3"), []byte("0123456789abcdef"), 0o600))
+
+	mockDS := &MockDataStore{}
+	mockDS.On("GetNoteClipPath", "123").Return(testFilename, nil)
+	mockDS.On("GetNoteClipPath", "124").Return("clip.mp3", nil)
+	controller.DS = mockDS
+	controller.Settings.Realtime.Audio.FfmpegPath = ""
+
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		rangeHeader    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"original clip", "123", "", "", http.StatusOK, "0123456789abcdef"},
+		{"original clip byte range", "123", "", "bytes=4-7", http.StatusPartialContent, "4567"},
+		{"same format needs no transcoding", "124", "?format=MP3", "bytes=10-", http.StatusPartialContent, "abcdef"},
+		{"unsupported format", "123", "?format=aiff", "", http.StatusBadRequest, ""},
+		{"transcoding without ffmpeg", "123", "?format=mp3", "", http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/"+tc.id+"/stream"+tc.query, http.NoBody)
+			if tc.rangeHeader != "" {
+				req.Header.Set("Range", tc.rangeHeader)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.id)
+
+			_ = controller.StreamAudioByID(c)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, rec.Body.String())
+				assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+			}
+		})
+	}
+}
+
+// TestStreamAudioByIDTranscode tests transcoding a clip and serving a range of the result
+func TestStreamAudioByIDTranscode(t *testing.T) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not available")
+	}
+
+	e, controller, tempDir := setupMediaTestEnvironment(t)
+	testFilename := "transcode_test.wav"
+	clipPath := filepath.Join(tempDir, testFilename)
+	out, err := exec.Command(ffmpegPath, "-hide_banner", "-loglevel", "error", "-f", "lavfi",
+		"-i", "sine=frequency=1000:duration=2", "-y", clipPath).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	mockDS := &MockDataStore{}
+	mockDS.On("GetNoteClipPath", "42").Return(testFilename, nil)
+	controller.DS = mockDS
+	controller.Settings.Realtime.Audio.FfmpegPath = ffmpegPath
+
+	info, err := os.Stat(clipPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Remove(streamCachePath(testFilename, info.ModTime(), "mp3")) })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/42/stream?format=mp3", http.NoBody)
+	req.Header.Set("Range", "bytes=0-99")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+
+	require.NoError(t, controller.StreamAudioByID(c))
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, MimeTypeMP3, rec.Header().Get("Content-Type"))
+	assert.Equal(t, 100, rec.Body.Len())
+	assert.FileExists(t, streamCachePath(testFilename, info.ModTime(), "mp3"), "transcoded clip is cached")
+}
+
+// TestPruneStreamCache tests that only stale transcoded clips are removed
+func TestPruneStreamCache(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "stale.mp3")
+	fresh := filepath.Join(dir, "fresh.mp3")
+	require.NoError(t, os.WriteFile(stale, []byte("x"), 0o600))
+	require.NoError(t, os.WriteFile(fresh, []byte("x"), 0o600))
+	old := time.Now().Add(-2 * streamCacheMaxAge)
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	pruneStreamCache(dir, streamCacheMaxAge)
+
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, fresh)
+}
@@ -3,6 +3,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/securefs"
 )
 
@@ -1248,3 +1250,54 @@ func TestIsValidFilename(t *testing.T) {
 		})
 	}
 }
+
+// mockAttributionStore adds the optional attribution ledger capability to MockDataStore
+type mockAttributionStore struct {
+	*MockDataStore
+	entries []datastore.ImageAttribution
+}
+
+func (m *mockAttributionStore) RecordImageAttribution(entry *datastore.ImageAttribution) error {
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockAttributionStore) GetImageAttributions(scientificName string) ([]datastore.ImageAttribution, error) {
+	var result []datastore.ImageAttribution
+	for _, entry := range m.entries {
+		if scientificName == "" || entry.ScientificName == scientificName {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// TestGetImageAttributions tests the image attribution ledger endpoint
+func TestGetImageAttributions(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// Plain datastore without ledger support
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/media/attributions", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetImageAttributions(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	store := &mockAttributionStore{MockDataStore: mockDS, entries: []datastore.ImageAttribution{
+		{ScientificName: "Turdus merula", SourceProvider: "wikimedia", URL: "https://example.com/a.jpg", LicenseName: "CC BY-SA 4.0", AuthorName: "Alice", UsageCount: 3},
+		{ScientificName: "Parus major", SourceProvider: "avicommons", URL: "https://example.com/b.jpg", LicenseName: "CC BY 4.0", AuthorName: "Bob", UsageCount: 1},
+	}}
+	controller.DS = store
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/media/attributions?species=Turdus%20merula", http.NoBody)
+	rec = httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	require.NoError(t, controller.GetImageAttributions(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var attributions []ImageAttribution
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &attributions))
+	require.Len(t, attributions, 1)
+	assert.Equal(t, "Alice", attributions[0].AuthorName)
+	assert.Equal(t, "CC BY-SA 4.0", attributions[0].LicenseName)
+	assert.Equal(t, 3, attributions[0].UsageCount)
+}
@@ -0,0 +1,95 @@
+// internal/api/v2/notification_preferences.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// GetNotificationPreferences handles GET /api/v2/notifications/preferences
+// Returns the notification preferences of the signed-in user
+func (c *Controller) GetNotificationPreferences(ctx echo.Context) error {
+	username, _ := ctx.Get("username").(string)
+
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	index := userAccountIndex(c.Settings.Security.Users, username)
+	if index < 0 {
+		return c.HandleError(ctx, fmt.Errorf("no user account for %q", username),
+			"Notification preferences require a user account", http.StatusNotFound)
+	}
+	return ctx.JSON(http.StatusOK, c.Settings.Security.Users[index].Notifications)
+}
+
+// UpdateNotificationPreferences handles PUT /api/v2/notifications/preferences
+// Replaces the notification channels, species of interest and quiet hours of the signed-in user
+func (c *Controller) UpdateNotificationPreferences(ctx echo.Context) error {
+	username, _ := ctx.Get("username").(string)
+	return c.saveNotificationPreferences(ctx, username)
+}
+
+// UpdateUserNotificationPreferences handles PUT /api/v2/auth/users/:username/notifications
+// Replaces the notification preferences of a user
+func (c *Controller) UpdateUserNotificationPreferences(ctx echo.Context) error {
+	return c.saveNotificationPreferences(ctx, ctx.Param("username"))
+}
+
+// saveNotificationPreferences replaces the notification preferences of a user account with
+// the preferences of the request body
+func (c *Controller) saveNotificationPreferences(ctx echo.Context, username string) error {
+	var prefs conf.UserNotificationSettings
+	if err := ctx.Bind(&prefs); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	normalizeNotificationPreferences(&prefs)
+	if err := prefs.Validate(); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	index := userAccountIndex(c.Settings.Security.Users, username)
+	if index < 0 {
+		return c.HandleError(ctx, fmt.Errorf("no user account for %q", username),
+			"Notification preferences require a user account", http.StatusNotFound)
+	}
+
+	oldSettings := *c.Settings
+	// Replace the slice instead of modifying it, the notification router reads it without the lock
+	users := slices.Clone(c.Settings.Security.Users)
+	users[index].Notifications = prefs
+	c.Settings.Security.Users = users
+	if err := c.saveAuthSettings(ctx, &oldSettings); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, prefs)
+}
+
+// normalizeNotificationPreferences trims the species and channels and removes empty and
+// repeated entries
+func normalizeNotificationPreferences(prefs *conf.UserNotificationSettings) {
+	prefs.Channels = uniqueTrimmed(prefs.Channels)
+	prefs.Species = uniqueTrimmed(prefs.Species)
+	prefs.QuietHours.Start = strings.TrimSpace(prefs.QuietHours.Start)
+	prefs.QuietHours.End = strings.TrimSpace(prefs.QuietHours.End)
+}
+
+// uniqueTrimmed returns the trimmed, non-empty values without repeats, in their order
+func uniqueTrimmed(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.ContainsFunc(result, func(v string) bool { return strings.EqualFold(v, value) }) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
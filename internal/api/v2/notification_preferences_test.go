@@ -0,0 +1,74 @@
+// notification_preferences_test.go: tests for the notification preferences of user accounts
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+	controller.Settings.Security.Users = []conf.UserAccount{{Username: "bob", PasswordHash: "$2a$10$hash", Role: conf.RoleViewer}}
+
+	// The basic auth admin has no user account to store preferences in
+	ctx, rec := newAuthUsersRequest(e, http.MethodGet, "/api/v2/notifications/preferences", "", "", "")
+	require.NoError(t, controller.GetNotificationPreferences(ctx))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	for _, body := range []string{
+		`{"channels":["sms"]}`,
+		`{"quietHours":{"enabled":true,"start":"25:00","end":"07:00"}}`,
+	} {
+		ctx, rec = newAuthUsersRequest(e, http.MethodPut, "/api/v2/notifications/preferences", body, "", "")
+		ctx.Set("username", "bob")
+		require.NoError(t, controller.UpdateNotificationPreferences(ctx))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+
+	ctx, rec = newAuthUsersRequest(e, http.MethodPut, "/api/v2/notifications/preferences",
+		`{"channels":["push","push"],"species":[" Strix aluco ",""],"quietHours":{"enabled":true,"start":"22:00","end":"07:00"}}`, "", "")
+	ctx.Set("username", "Bob")
+	require.NoError(t, controller.UpdateNotificationPreferences(ctx))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, conf.UserNotificationSettings{
+		Channels:   []string{conf.NotificationChannelPush},
+		Species:    []string{"Strix aluco"},
+		QuietHours: conf.QuietHoursSettings{Enabled: true, Start: "22:00", End: "07:00"},
+	}, controller.Settings.Security.Users[0].Notifications)
+
+	ctx, rec = newAuthUsersRequest(e, http.MethodGet, "/api/v2/notifications/preferences", "", "", "")
+	ctx.Set("username", "bob")
+	require.NoError(t, controller.GetNotificationPreferences(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var prefs conf.UserNotificationSettings
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &prefs))
+	assert.Equal(t, []string{"Strix aluco"}, prefs.Species)
+
+	// The dashboard stream follows the chosen channels, toasts are always sent
+	owl := notification.NewNotification(notification.TypeDetection, notification.PriorityHigh, "New Species Detected: Tawny Owl", "").
+		WithMetadata("species", "Tawny Owl").
+		WithMetadata("scientific_name", "Strix aluco")
+	toast := notification.NewNotification(notification.TypeInfo, notification.PriorityLow, "Settings saved", "").
+		WithMetadata(notification.MetadataKeyIsToast, true)
+	assert.False(t, controller.dashboardNotificationAllowed("bob", owl))
+	assert.True(t, controller.dashboardNotificationAllowed("bob", toast))
+	assert.True(t, controller.dashboardNotificationAllowed("", owl))
+
+	// Admins set the preferences of other users
+	ctx, rec = newAuthUsersRequest(e, http.MethodPut, "/api/v2/auth/users/bob/notifications", `{"channels":["dashboard"]}`, "username", "bob")
+	require.NoError(t, controller.UpdateUserNotificationPreferences(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, controller.dashboardNotificationAllowed("bob", owl))
+
+	ctx, rec = newAuthUsersRequest(e, http.MethodPut, "/api/v2/auth/users/carol/notifications", `{}`, "username", "carol")
+	require.NoError(t, controller.UpdateUserNotificationPreferences(ctx))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/notification"
 	"github.com/tphakala/birdnet-go/internal/observability/metrics"
@@ -52,6 +53,7 @@ type UnifiedSSEEvent struct {
 // NotificationClient represents a connected notification SSE client
 type NotificationClient struct {
 	ID           string
+	Username     string // user whose notification preferences apply, empty without a user
 	Channel      chan *notification.Notification
 	Request      *http.Request
 	Response     http.ResponseWriter
@@ -98,6 +100,10 @@ func (c *Controller) SetupNotificationRoutes() {
 	c.Group.PUT("/notifications/:id/acknowledge", c.MarkNotificationAcknowledged)
 	c.Group.DELETE("/notifications/:id", c.DeleteNotification)
 	c.Group.GET("/notifications/unread/count", c.GetUnreadCount)
+
+	// Notification preferences of the signed-in user
+	c.Group.GET("/notifications/preferences", c.GetNotificationPreferences, c.getEffectiveAuthMiddleware())
+	c.Group.PUT("/notifications/preferences", c.UpdateNotificationPreferences, c.getEffectiveAuthMiddleware())
 }
 
 // StreamNotifications handles the SSE connection for real-time notification streaming
@@ -177,6 +183,7 @@ func (c *Controller) setupNotificationSSEClient(ctx echo.Context) (*Notification
 		SubscriberCh: notificationCh,
 		Context:      notificationCtx,
 	}
+	client.Username, _ = ctx.Get("username").(string)
 
 	// Send initial connection message
 	if err := c.sendSSEMessage(ctx, "connected", map[string]string{
@@ -240,6 +247,9 @@ func (c *Controller) runNotificationEventLoop(ctx echo.Context, client *Notifica
 				// Channel closed, service is shutting down
 				return nil
 			}
+			if !c.dashboardNotificationAllowed(client.Username, notif) {
+				continue
+			}
 			
 			if err := c.processNotificationEvent(ctx, client.ID, notif); err != nil {
 				return err
@@ -273,6 +283,16 @@ func (c *Controller) runNotificationEventLoop(ctx echo.Context, client *Notifica
 	}
 }
 
+// dashboardNotificationAllowed reports whether the notification preferences of a user allow
+// a notification on the dashboard stream. Toasts are feedback to the actions of the user and
+// always sent.
+func (c *Controller) dashboardNotificationAllowed(username string, notif *notification.Notification) bool {
+	if isToast, _ := notif.Metadata[notification.MetadataKeyIsToast].(bool); isToast {
+		return true
+	}
+	return notification.NewRouter(c.Settings).Allows(username, conf.NotificationChannelDashboard, notif)
+}
+
 // processNotificationEvent processes a single notification event
 func (c *Controller) processNotificationEvent(ctx echo.Context, clientID string, notif *notification.Notification) error {
 	// Check if this is a toast notification
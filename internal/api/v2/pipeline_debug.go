@@ -0,0 +1,68 @@
+// internal/api/v2/pipeline_debug.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
+)
+
+// initPipelineDebugRoutes registers the analysis pipeline latencies and the pprof profiles.
+// Unlike the other debug routes they are available without debug mode, so slow stages behind
+// missed detections can be diagnosed on any node; the profiles are served only while
+// webserver.profiling is enabled.
+func (c *Controller) initPipelineDebugRoutes() {
+	debugGroup := c.Group.Group("/debug", c.getEffectiveAuthMiddleware(), auth.RequireAdmin)
+
+	debugGroup.GET("/pipeline", c.GetPipelineLatencies)
+	debugGroup.DELETE("/pipeline", c.ResetPipelineLatencies)
+
+	profileGroup := debugGroup.Group("/pprof", c.requireProfiling)
+	profileGroup.GET("/", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	profileGroup.GET("/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	profileGroup.GET("/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	profileGroup.GET("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	profileGroup.GET("/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	for _, profile := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		profileGroup.GET("/"+profile, echo.WrapHandler(pprof.Handler(profile)))
+	}
+}
+
+// requireProfiling rejects profile requests while webserver.profiling is disabled. The flag
+// is read on each request, so profiling can be switched in the settings without a restart.
+func (c *Controller) requireProfiling(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		c.settingsMutex.RLock()
+		enabled := c.Settings.WebServer.Profiling
+		c.settingsMutex.RUnlock()
+		if !enabled {
+			return c.HandleError(ctx, fmt.Errorf("profiling is disabled"),
+				"Profiling is disabled, enable webserver.profiling in the settings", http.StatusForbidden)
+		}
+		return next(ctx)
+	}
+}
+
+// GetPipelineLatencies handles GET /api/v2/debug/pipeline
+// Returns the latency of each analysis pipeline stage: capture to segment, inference queue,
+// inference, post-processing and action execution.
+func (c *Controller) GetPipelineLatencies(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, pipeline.Snapshot())
+}
+
+// ResetPipelineLatencies handles DELETE /api/v2/debug/pipeline
+// Clears the recorded latencies, e.g. to measure after a settings change.
+func (c *Controller) ResetPipelineLatencies(ctx echo.Context) error {
+	pipeline.Reset()
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Pipeline latencies reset",
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+// pipeline_debug_test.go: tests for the pipeline latency and profiling endpoints
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
+)
+
+func TestGetPipelineLatencies(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	pipeline.Record(pipeline.StageInference, 120*time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/debug/pipeline", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetPipelineLatencies(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report pipeline.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	stages := make(map[string]pipeline.StageStats)
+	for _, stage := range report.Stages {
+		stages[stage.Stage] = stage
+	}
+	for _, stage := range pipeline.Stages {
+		assert.Contains(t, stages, stage, "every stage is reported")
+	}
+	assert.Positive(t, stages[pipeline.StageInference].Count)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v2/debug/pipeline", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.ResetPipelineLatencies(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestRequireProfiling(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	handler := controller.requireProfiling(func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "profile")
+	})
+
+	tests := []struct {
+		name      string
+		profiling bool
+		wantCode  int
+	}{
+		{"disabled", false, http.StatusForbidden},
+		{"enabled", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller.Settings.WebServer.Profiling = tt.profiling
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/debug/pprof/heap", http.NoBody)
+			rec := httptest.NewRecorder()
+			_ = handler(e.NewContext(req, rec))
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
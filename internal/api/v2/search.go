@@ -334,6 +334,7 @@ func (c *Controller) validateSearchSortBy(path, ip string, req *SearchRequest) e
 		"date_asc":        {},
 		"species_asc":     {},
 		"confidence_desc": {},
+		"rarity_desc":     {},
 	}
 	if req.SortBy != "" { // Allow empty string for default sorting (handled by datastore)
 		if _, ok := allowedSortBy[req.SortBy]; !ok {
@@ -15,9 +15,14 @@ import (
 	"unicode/utf8"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
+	"github.com/tphakala/birdnet-go/internal/logging"
+	"github.com/tphakala/birdnet-go/internal/privacy"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
+	"github.com/tphakala/birdnet-go/internal/webpush"
 )
 
 // UpdateRequest represents a request to update settings
@@ -35,21 +40,26 @@ func (c *Controller) initSettingsRoutes() {
 	// Create settings API group
 	settingsGroup := c.Group.Group("/settings", c.AuthMiddleware)
 
-	// Routes for settings
+	// Routes for settings. Settings hold secrets, so reading and changing them requires
+	// the admin role.
 	// GET /api/v2/settings - Retrieves all application settings
-	settingsGroup.GET("", c.GetAllSettings)
+	settingsGroup.GET("", c.GetAllSettings, auth.RequireAdmin)
 	// GET /api/v2/settings/locales - Retrieves available locales for BirdNET (must be before /:section)
 	settingsGroup.GET("/locales", c.GetLocales)
 	// GET /api/v2/settings/imageproviders - Retrieves available image providers (must be before /:section)
 	settingsGroup.GET("/imageproviders", c.GetImageProviders)
 	// GET /api/v2/settings/systemid - Retrieves the system ID for support tracking (must be before /:section)
 	settingsGroup.GET("/systemid", c.GetSystemID)
+	// GET /api/v2/settings/audit - Retrieves the audit log of settings changes (must be before /:section)
+	settingsGroup.GET("/audit", c.GetSettingsAuditLog, auth.RequireAdmin)
+	// POST /api/v2/settings/location - Moves the station to a new location and deployment
+	settingsGroup.POST("/location", c.ChangeLocation, auth.RequireAdmin)
 	// GET /api/v2/settings/:section - Retrieves settings for a specific section (e.g., birdnet, webserver)
-	settingsGroup.GET("/:section", c.GetSectionSettings)
+	settingsGroup.GET("/:section", c.GetSectionSettings, auth.RequireAdmin)
 	// PUT /api/v2/settings - Updates multiple settings sections with complete replacement
-	settingsGroup.PUT("", c.UpdateSettings)
+	settingsGroup.PUT("", c.UpdateSettings, auth.RequireAdmin)
 	// PATCH /api/v2/settings/:section - Updates a specific settings section with partial replacement
-	settingsGroup.PATCH("/:section", c.UpdateSectionSettings)
+	settingsGroup.PATCH("/:section", c.UpdateSectionSettings, auth.RequireAdmin)
 
 	if c.apiLogger != nil {
 		c.apiLogger.Info("Settings routes initialized successfully")
@@ -196,6 +206,8 @@ func (c *Controller) UpdateSettings(ctx echo.Context) error {
 	// Update the cached telemetry state after settings change
 	telemetry.UpdateTelemetryEnabled()
 
+	c.recordSettingsChanges(&oldSettings, settings, settingsChangeActor(ctx), settingsChangeSourceAPI, ctx.RealIP())
+
 	c.logAPIRequest(ctx, slog.LevelInfo, "Settings updated and saved successfully", "skipped_fields_count", len(skippedFields))
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"message":       "Settings updated successfully",
@@ -529,6 +541,8 @@ func (c *Controller) UpdateSectionSettings(ctx echo.Context) error {
 	// Update the cached telemetry state after settings change
 	telemetry.UpdateTelemetryEnabled()
 
+	c.recordSettingsChanges(&oldSettings, settings, settingsChangeActor(ctx), settingsChangeSourceAPI, ctx.RealIP())
+
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"message":       fmt.Sprintf("%s settings updated successfully", section),
 		"skippedFields": skippedFields,
@@ -710,6 +724,12 @@ func getSettingsSectionValue(settings *conf.Settings, section string) (any, erro
 		return &settings.Realtime.Telemetry, nil
 	case "sentry":
 		return &settings.Sentry, nil
+	case "privacy":
+		return &settings.Privacy, nil
+	case "logging":
+		return &settings.Logging, nil
+	case "ui":
+		return &settings.UI, nil
 	default:
 		return nil, fmt.Errorf("unknown settings section: %s", section)
 	}
@@ -802,6 +822,7 @@ var securitySectionAllowedFields = map[string]bool{
 	"basicAuth":         true, // Basic authentication settings
 	"googleAuth":        true, // Google OAuth settings
 	"githubAuth":        true, // GitHub OAuth settings
+	"oidcAuth":          true, // OpenID Connect settings
 	"allowSubnetBypass": true, // Subnet bypass settings
 	"redirectToHttps":   true, // HTTPS redirect setting
 	// sessionSecret is NOT allowed - it's generated internally
@@ -838,6 +859,9 @@ func validateSecuritySectionValues(updateMap map[string]any) error {
 	if err := validateOAuthSettings("githubAuth", updateMap); err != nil {
 		return err
 	}
+	if err := validateOAuthSettings("oidcAuth", updateMap); err != nil {
+		return err
+	}
 
 	// Validate allowSubnetBypass
 	if err := validateSubnetBypassField(updateMap); err != nil {
@@ -1048,6 +1072,25 @@ func validateBirdNETSection(data json.RawMessage) error {
 		}
 	}
 
+	// Validate inference scheduler queue limit, workers and batch size
+	if scheduler, ok := updateMap["scheduler"].(map[string]any); ok {
+		if limit, ok := scheduler["queueLimit"].(float64); ok {
+			if limit < 1 || limit > conf.MaxInferenceQueueLimit {
+				return fmt.Errorf("scheduler queue limit must be between 1 and %d", conf.MaxInferenceQueueLimit)
+			}
+		}
+		if workers, ok := scheduler["workers"].(float64); ok {
+			if workers < 1 || workers > conf.MaxInferenceWorkers {
+				return fmt.Errorf("scheduler workers must be between 1 and %d", conf.MaxInferenceWorkers)
+			}
+		}
+		if batchSize, ok := scheduler["batchSize"].(float64); ok {
+			if batchSize < 1 || batchSize > conf.MaxInferenceBatchSize {
+				return fmt.Errorf("scheduler batch size must be between 1 and %d", conf.MaxInferenceBatchSize)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -1122,6 +1165,11 @@ func validateRealtimeSection(data json.RawMessage) error {
 			return fmt.Errorf("species config for '%s': threshold must be between 0 and 1, got %f", speciesName, config.Threshold)
 		}
 	}
+
+	// Validate duplicate suppression intervals per integration
+	if err := realtimeSettings.EventIntervals.Validate(); err != nil {
+		return err
+	}
 	
 	return nil
 }
@@ -1233,6 +1281,7 @@ func getBlockedFieldMap() map[string]any {
 		"BuildDate":          true, // Build time info
 		"SystemID":           true, // Unique system identifier
 		"ValidationWarnings": true, // Runtime validation state
+		"ConfigVersion":      true, // Schema version, updated by configuration migrations
 		"Input":              true, // File/directory analysis mode config
 
 		// BirdNET section - block runtime fields
@@ -1250,6 +1299,8 @@ func getBlockedFieldMap() map[string]any {
 		"Security": map[string]any{
 			"SessionSecret":   true, // Generated internally, never updated via API
 			"SessionDuration": true, // Runtime setting
+			"Users":           true, // Managed through /api/v2/auth/users, password hashes are not in the API
+			"APITokens":       true, // Managed through /api/v2/auth/tokens, token hashes are not in the API
 			// Note: The following OAuth2 server internal fields are in BasicAuth struct
 			"BasicAuth": map[string]any{
 				"ClientID":       true, // OAuth2 server internal field
@@ -1264,6 +1315,12 @@ func getBlockedFieldMap() map[string]any {
 			"Audio": getAudioBlockedFields(),
 		},
 
+		// WebPush section - the VAPID keys are generated and replaced through /api/v2/notifications/push/key
+		"WebPush": map[string]any{
+			"PublicKey":  true,
+			"PrivateKey": true,
+		},
+
 		// All other fields are allowed by default
 	}
 }
@@ -1290,7 +1347,8 @@ func (c *Controller) handleSettingsChanges(oldSettings, currentSettings *conf.Se
 	}
 
 	// Check species interval settings
-	if speciesIntervalSettingsChanged(oldSettings, currentSettings) || oldSettings.Realtime.Interval != currentSettings.Realtime.Interval {
+	if speciesIntervalSettingsChanged(oldSettings, currentSettings) || oldSettings.Realtime.Interval != currentSettings.Realtime.Interval ||
+		oldSettings.Realtime.EventIntervals != currentSettings.Realtime.EventIntervals {
 		c.Debug("Species interval settings changed, triggering update")
 		reconfigActions = append(reconfigActions, "update_detection_intervals")
 		// Send toast notification
@@ -1329,6 +1387,38 @@ func (c *Controller) handleSettingsChanges(oldSettings, currentSettings *conf.Se
 		_ = c.SendToast("Reconfiguring telemetry settings...", "info", 3000)
 	}
 
+	// Apply anonymization policy changes immediately, no service needs restarting
+	if !reflect.DeepEqual(oldSettings.Privacy, currentSettings.Privacy) {
+		c.Debug("Privacy settings changed, applying anonymization policy")
+		privacy.SetPolicy(currentSettings.Privacy.Policy())
+	}
+
+	// Apply component log level changes immediately
+	if !reflect.DeepEqual(oldSettings.Logging.Levels, currentSettings.Logging.Levels) {
+		c.Debug("Log levels changed, applying component levels")
+		if err := logging.ApplyComponentLevels(currentSettings.Logging.Levels); err != nil {
+			return err
+		}
+	}
+
+	// Reconnect the syslog and journald outputs when their settings change
+	if !reflect.DeepEqual(oldSettings.Logging.Syslog, currentSettings.Logging.Syslog) ||
+		!reflect.DeepEqual(oldSettings.Logging.Journald, currentSettings.Logging.Journald) {
+		c.Debug("Log output settings changed, reconfiguring syslog and journald outputs")
+		if err := logging.ConfigureOutputs(currentSettings); err != nil {
+			return err
+		}
+	}
+
+	// Start, stop or update Web Push notifications when their settings change
+	if !reflect.DeepEqual(oldSettings.WebPush, currentSettings.WebPush) {
+		c.Debug("Web push settings changed, reconfiguring push notifications")
+		store, _ := c.DS.(datastore.WebPushStore)
+		if err := webpush.Configure(currentSettings, store); err != nil {
+			return err
+		}
+	}
+
 	// Handle audio settings changes
 	audioActions, err := c.handleAudioSettingsChanges(oldSettings, currentSettings)
 	if err != nil {
@@ -1378,6 +1468,12 @@ func birdnetSettingsChanged(oldSettings, currentSettings *conf.Settings) bool {
 		return true
 	}
 
+	// Check for changes in the inference workers and batch size, the interpreters are recreated
+	if oldSettings.BirdNET.Scheduler.Workers != currentSettings.BirdNET.Scheduler.Workers ||
+		oldSettings.BirdNET.Scheduler.BatchSize != currentSettings.BirdNET.Scheduler.BatchSize {
+		return true
+	}
+
 	return false
 }
 
@@ -0,0 +1,289 @@
+// internal/api/v2/settings_audit.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Sources of recorded settings changes
+const (
+	settingsChangeSourceAPI    = "api"           // changed through the settings API
+	settingsChangeSourceReload = "config_reload" // changed by editing the configuration file
+)
+
+// settingsChangeActorConfigFile is the actor of changes picked up from the configuration file
+const settingsChangeActorConfigFile = "config file"
+
+// maskedSettingValue replaces the values of secrets in the audit log
+const maskedSettingValue = `"********"`
+
+// sensitiveSettingNames are parts of setting names whose values are never stored in the audit log
+var sensitiveSettingNames = []string{"password", "secret", "token", "apikey", "accesskey", "encryptionkey"}
+
+// sensitiveSettingPaths are settings holding secrets whose names do not tell so
+var sensitiveSettingPaths = map[string]bool{
+	"realtime.birdweather.id": true, // station token
+	"webpush.privatekey":      true, // VAPID signing key
+}
+
+// ErrSettingsAuditNotAvailable is returned when the datastore does not record settings changes
+var ErrSettingsAuditNotAvailable = errors.New("settings audit log not available")
+
+// SettingsChangeInfo describes one recorded change of a setting
+type SettingsChangeInfo struct {
+	ID        uint            `json:"id"`
+	ChangedAt time.Time       `json:"changed_at"`
+	Actor     string          `json:"actor"`
+	Source    string          `json:"source"`
+	RemoteIP  string          `json:"remote_ip,omitempty"`
+	Section   string          `json:"section"`
+	Path      string          `json:"path"`
+	OldValue  json.RawMessage `json:"old_value"`
+	NewValue  json.RawMessage `json:"new_value"`
+}
+
+// GetSettingsAuditLog handles GET /api/v2/settings/audit
+// Returns recorded settings changes, newest first.
+// Query parameters: section (optional), limit (optional, default 100, max 1000), offset (optional)
+func (c *Controller) GetSettingsAuditLog(ctx echo.Context) error {
+	audit, ok := c.DS.(datastore.SettingsAuditLog)
+	if !ok {
+		return c.HandleError(ctx, ErrSettingsAuditNotAvailable, "Settings audit log unavailable", http.StatusServiceUnavailable)
+	}
+
+	limit := 100
+	if limitParam := ctx.QueryParam("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			return c.HandleError(ctx, fmt.Errorf("invalid limit: %s", limitParam), "Limit must be between 1 and 1000", http.StatusBadRequest)
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if offsetParam := ctx.QueryParam("offset"); offsetParam != "" {
+		parsed, err := strconv.Atoi(offsetParam)
+		if err != nil || parsed < 0 {
+			return c.HandleError(ctx, fmt.Errorf("invalid offset: %s", offsetParam), "Offset must be a non-negative number", http.StatusBadRequest)
+		}
+		offset = parsed
+	}
+
+	section := strings.ToLower(ctx.QueryParam("section"))
+	changes, total, err := audit.GetSettingsChanges(section, limit, offset)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get settings audit log", http.StatusInternalServerError)
+	}
+
+	result := make([]SettingsChangeInfo, 0, len(changes))
+	for i := range changes {
+		change := &changes[i]
+		result = append(result, SettingsChangeInfo{
+			ID:        change.ID,
+			ChangedAt: change.ChangedAt,
+			Actor:     change.Actor,
+			Source:    change.Source,
+			RemoteIP:  change.RemoteIP,
+			Section:   change.Section,
+			Path:      change.Path,
+			OldValue:  json.RawMessage(change.OldValue),
+			NewValue:  json.RawMessage(change.NewValue),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"changes": result,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// recordSettingsChanges stores the differences between oldSettings and newSettings in the
+// audit log. Failures are logged and do not fail the settings update.
+func (c *Controller) recordSettingsChanges(oldSettings, newSettings *conf.Settings, actor, source, remoteIP string) {
+	audit, ok := c.DS.(datastore.SettingsAuditLog)
+	if !ok {
+		return
+	}
+
+	changes := diffSettings(oldSettings, newSettings)
+	if len(changes) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for i := range changes {
+		changes[i].ChangedAt = now
+		changes[i].Actor = actor
+		changes[i].Source = source
+		changes[i].RemoteIP = remoteIP
+	}
+
+	if err := audit.SaveSettingsChanges(changes); err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Error("Failed to record settings changes in audit log", "error", err, "changes", len(changes))
+		}
+		return
+	}
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Recorded settings changes in audit log", "actor", actor, "source", source, "changes", len(changes))
+	}
+}
+
+// settingsChangeActor returns who made an API request, the user name when known and
+// otherwise the authentication method
+func settingsChangeActor(ctx echo.Context) string {
+	if username, ok := ctx.Get("username").(string); ok && username != "" {
+		return username
+	}
+	if method, ok := ctx.Get("authMethod").(auth.AuthMethod); ok {
+		return method.String()
+	}
+	return auth.AuthMethodUnknown.String()
+}
+
+// diffSettings returns a change for every setting that differs between oldSettings and
+// newSettings. Runtime-only fields are skipped and secrets are masked.
+func diffSettings(oldSettings, newSettings *conf.Settings) []datastore.SettingsChange {
+	var changes []datastore.SettingsChange
+	collectSettingsChanges("", reflect.ValueOf(oldSettings).Elem(), reflect.ValueOf(newSettings).Elem(), &changes)
+	return changes
+}
+
+// collectSettingsChanges compares oldValue and newValue and appends the changed settings
+// below path to changes
+func collectSettingsChanges(path string, oldValue, newValue reflect.Value, changes *[]datastore.SettingsChange) {
+	if oldValue.Kind() == reflect.Struct && oldValue.Type() != reflect.TypeOf(time.Time{}) {
+		for i := 0; i < oldValue.NumField(); i++ {
+			field := oldValue.Type().Field(i)
+			name, ok := settingFieldName(field)
+			if !ok {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			collectSettingsChanges(name, oldValue.Field(i), newValue.Field(i), changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(oldValue.Interface(), newValue.Interface()) {
+		return
+	}
+
+	section, _, _ := strings.Cut(path, ".")
+	change := datastore.SettingsChange{
+		Section:  strings.ToLower(section),
+		Path:     path,
+		OldValue: maskedSettingValue,
+		NewValue: maskedSettingValue,
+	}
+	if !isSensitiveSetting(path) {
+		change.OldValue = settingValueJSON(path, oldValue)
+		change.NewValue = settingValueJSON(path, newValue)
+	}
+	*changes = append(*changes, change)
+}
+
+// settingFieldName returns the JSON name of a settings field. Unexported fields and
+// runtime-only fields, which are not stored in the configuration file, are skipped.
+func settingFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(field.Tag.Get("yaml"), ",")
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, true
+}
+
+// isSensitiveSetting reports whether the setting at path holds a secret
+func isSensitiveSetting(path string) bool {
+	lowerPath := strings.ToLower(path)
+	if sensitiveSettingPaths[lowerPath] {
+		return true
+	}
+	name := lowerPath[strings.LastIndex(lowerPath, ".")+1:]
+	for _, sensitive := range sensitiveSettingNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// settingValueJSON returns the JSON encoding of the setting value at path. Lists and maps,
+// such as backup targets, are stored as one value, so the secrets they contain are masked
+// by their keys like settings.
+func settingValueJSON(path string, value reflect.Value) string {
+	composite := false
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+		composite = true
+	}
+
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		if composite {
+			return maskedSettingValue // the secrets in it cannot be told apart
+		}
+		return strconv.Quote(fmt.Sprint(value.Interface()))
+	}
+	if !composite {
+		return string(data)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return maskedSettingValue
+	}
+	masked, err := json.Marshal(maskNestedSecrets(path, decoded))
+	if err != nil {
+		return maskedSettingValue
+	}
+	return string(masked)
+}
+
+// maskNestedSecrets replaces the values of sensitive keys within a decoded JSON value at path
+func maskNestedSecrets(path string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			keyPath := path + "." + key
+			if isSensitiveSetting(keyPath) {
+				v[key] = json.RawMessage(maskedSettingValue)
+				continue
+			}
+			v[key] = maskNestedSecrets(keyPath, nested)
+		}
+	case []any:
+		for i, nested := range v {
+			v[i] = maskNestedSecrets(path, nested)
+		}
+	}
+	return value
+}
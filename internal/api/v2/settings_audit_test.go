@@ -0,0 +1,172 @@
+// settings_audit_test.go: tests for the settings audit log
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// mockSettingsAuditLog adds the optional settings audit capability to MockDataStore
+type mockSettingsAuditLog struct {
+	*MockDataStore
+	changes []datastore.SettingsChange
+	section string
+}
+
+func (m *mockSettingsAuditLog) SaveSettingsChanges(changes []datastore.SettingsChange) error {
+	m.changes = append(m.changes, changes...)
+	return nil
+}
+
+func (m *mockSettingsAuditLog) GetSettingsChanges(section string, limit, offset int) ([]datastore.SettingsChange, int64, error) {
+	m.section = section
+	return m.changes, int64(len(m.changes)), nil
+}
+
+func TestDiffSettings(t *testing.T) {
+	t.Parallel()
+
+	oldSettings := &conf.Settings{}
+	oldSettings.BirdNET.Threshold = 0.8
+	oldSettings.Realtime.MQTT.Password = "old-secret"
+	oldSettings.Realtime.Birdweather.ID = "old-token"
+	oldSettings.Realtime.Species.Include = []string{"Eurasian Blue Tit"}
+
+	newSettings := &conf.Settings{}
+	*newSettings = *oldSettings
+	newSettings.BirdNET.Threshold = 0.7
+	newSettings.Realtime.MQTT.Password = "new-secret"
+	newSettings.Realtime.Birdweather.ID = "new-token"
+	newSettings.Realtime.Species.Include = []string{"Eurasian Blue Tit", "Great Tit"}
+	newSettings.ValidationWarnings = []string{"runtime only"}
+
+	changes := diffSettings(oldSettings, newSettings)
+	byPath := make(map[string]datastore.SettingsChange, len(changes))
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+	require.Len(t, byPath, 4, "unexpected changes: %v", changes)
+
+	threshold := byPath["birdnet.threshold"]
+	assert.Equal(t, "birdnet", threshold.Section)
+	assert.Equal(t, "0.8", threshold.OldValue)
+	assert.Equal(t, "0.7", threshold.NewValue)
+
+	include := byPath["realtime.species.include"]
+	assert.Equal(t, "realtime", include.Section)
+	assert.JSONEq(t, `["Eurasian Blue Tit","Great Tit"]`, include.NewValue)
+
+	for _, path := range []string{"realtime.mqtt.password", "realtime.birdweather.id"} {
+		secret, ok := byPath[path]
+		require.True(t, ok, "missing change of %s", path)
+		assert.Equal(t, maskedSettingValue, secret.OldValue)
+		assert.Equal(t, maskedSettingValue, secret.NewValue)
+	}
+
+	assert.Empty(t, diffSettings(oldSettings, oldSettings))
+}
+
+func TestDiffSettingsMasksSecretsInLists(t *testing.T) {
+	t.Parallel()
+
+	target := func(password, secretKey string) conf.BackupTarget {
+		return conf.BackupTarget{Type: "sftp", Enabled: true, Settings: map[string]any{
+			"host":            "backup.example.com",
+			"port":            22,
+			"password":        password,
+			"secretaccesskey": secretKey,
+			"options":         map[string]any{"token": password},
+		}}
+	}
+	oldSettings := &conf.Settings{}
+	oldSettings.Backup.Targets = []conf.BackupTarget{target("old-password", "old-key")}
+	newSettings := &conf.Settings{}
+	newSettings.Backup.Targets = []conf.BackupTarget{target("new-password", "new-key")}
+
+	changes := diffSettings(oldSettings, newSettings)
+	require.Len(t, changes, 1, "unexpected changes: %v", changes)
+	assert.Equal(t, "backup.targets", changes[0].Path)
+	for _, value := range []string{changes[0].OldValue, changes[0].NewValue} {
+		assert.NotContains(t, value, "password\":\"old")
+		for _, secret := range []string{"old-password", "new-password", "old-key", "new-key"} {
+			assert.NotContains(t, value, secret)
+		}
+	}
+	assert.JSONEq(t, `[{"type":"sftp","enabled":true,"settings":{
+		"host":"backup.example.com","port":22,"password":"********","secretaccesskey":"********",
+		"options":{"token":"********"}}}]`, changes[0].NewValue)
+}
+
+func TestRecordSettingsChanges(t *testing.T) {
+	_, mockDS, controller := setupTestEnvironment(t)
+
+	oldSettings := &conf.Settings{}
+	newSettings := &conf.Settings{}
+	newSettings.Main.Name = "backyard"
+
+	// Plain datastore without audit support is a no-op
+	controller.recordSettingsChanges(oldSettings, newSettings, "admin", settingsChangeSourceAPI, "192.0.2.1")
+
+	store := &mockSettingsAuditLog{MockDataStore: mockDS}
+	controller.DS = store
+	controller.recordSettingsChanges(oldSettings, newSettings, "admin", settingsChangeSourceAPI, "192.0.2.1")
+
+	require.Len(t, store.changes, 1)
+	change := store.changes[0]
+	assert.Equal(t, "main.name", change.Path)
+	assert.Equal(t, "main", change.Section)
+	assert.Equal(t, "admin", change.Actor)
+	assert.Equal(t, settingsChangeSourceAPI, change.Source)
+	assert.Equal(t, "192.0.2.1", change.RemoteIP)
+	assert.Equal(t, `""`, change.OldValue)
+	assert.Equal(t, `"backyard"`, change.NewValue)
+	assert.False(t, change.ChangedAt.IsZero())
+}
+
+func TestGetSettingsAuditLog(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// Plain datastore without audit support
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/settings/audit", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSettingsAuditLog(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	store := &mockSettingsAuditLog{MockDataStore: mockDS, changes: []datastore.SettingsChange{
+		{ID: 1, Actor: "admin", Source: settingsChangeSourceAPI, Section: "birdnet", Path: "birdnet.threshold", OldValue: "0.8", NewValue: "0.7"},
+	}}
+	controller.DS = store
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/settings/audit?section=BirdNET&limit=10", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetSettingsAuditLog(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "birdnet", store.section)
+
+	var response struct {
+		Changes []SettingsChangeInfo `json:"changes"`
+		Total   int64                `json:"total"`
+		Limit   int                  `json:"limit"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Changes, 1)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, 10, response.Limit)
+	assert.Equal(t, "birdnet.threshold", response.Changes[0].Path)
+	assert.JSONEq(t, "0.7", string(response.Changes[0].NewValue))
+
+	for _, query := range []string{"limit=0", "limit=1001", "offset=-1"} {
+		req = httptest.NewRequest(http.MethodGet, "/api/v2/settings/audit?"+query, http.NoBody)
+		rec = httptest.NewRecorder()
+		require.NoError(t, controller.GetSettingsAuditLog(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,138 @@
+// internal/api/v2/settings_location.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// LocationChangeRequest moves the station to a new location, starting a new deployment
+type LocationChangeRequest struct {
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Name        string  `json:"name"`        // name of the new deployment, saved as the deployment ID of the settings
+	Placement   string  `json:"placement"`   // microphone placement at the new location
+	Description string  `json:"description"` // notes stored with the deployment
+
+	// DeploymentID is the name field of earlier clients, used when Name is empty
+	DeploymentID string `json:"deploymentId"`
+}
+
+// LocationChangeResponse is returned after the station location was changed
+type LocationChangeResponse struct {
+	Message            string         `json:"message"`
+	Deployment         DeploymentInfo `json:"deployment"`
+	RangeFilterRebuild bool           `json:"rangeFilterRebuild"` // the range filter is rebuilt for the new location
+}
+
+// Validate checks the coordinates and text fields of the request
+func (r *LocationChangeRequest) Validate() error {
+	if r.Latitude < -90 || r.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90, got %v", r.Latitude)
+	}
+	if r.Longitude < -180 || r.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180, got %v", r.Longitude)
+	}
+	if len(r.Name) > maxDeploymentFieldLength || len(r.Placement) > maxDeploymentFieldLength {
+		return fmt.Errorf("name and placement must be at most %d characters", maxDeploymentFieldLength)
+	}
+	return nil
+}
+
+// ChangeLocation handles POST /api/v2/settings/location
+// Moves the station: the current deployment is ended and a new one started at the new
+// location with the same microphone and gain, the coordinates and deployment name are saved
+// to the settings and the range filter is rebuilt. Existing detections keep their coordinates
+// and deployment.
+func (c *Controller) ChangeLocation(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.DeploymentStore)
+	if !ok {
+		return c.HandleError(ctx, ErrDeploymentsNotAvailable, "Deployment history unavailable", http.StatusServiceUnavailable)
+	}
+
+	var req LocationChangeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = strings.TrimSpace(req.DeploymentID)
+	}
+	req.Placement = strings.TrimSpace(req.Placement)
+	if err := req.Validate(); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	oldSettings := *c.Settings
+	if oldSettings.BirdNET.Latitude == req.Latitude && oldSettings.BirdNET.Longitude == req.Longitude &&
+		oldSettings.BirdNET.DeploymentID == req.Name {
+		return c.HandleError(ctx, fmt.Errorf("location unchanged"), "Location and deployment name are unchanged", http.StatusBadRequest)
+	}
+
+	current, err := store.GetCurrentDeployment()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get current deployment", http.StatusInternalServerError)
+	}
+	next := &datastore.Deployment{
+		Name:        req.Name,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Placement:   req.Placement,
+		StartedAt:   time.Now(),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if current != nil {
+		// The equipment moves with the station
+		next.Microphone = current.Microphone
+		next.Gain = current.Gain
+	}
+	previous := &datastore.Deployment{
+		Name:      oldSettings.BirdNET.DeploymentID,
+		Latitude:  oldSettings.BirdNET.Latitude,
+		Longitude: oldSettings.BirdNET.Longitude,
+	}
+	if err := store.StartDeployment(previous, next); err != nil {
+		return c.HandleError(ctx, err, "Failed to record deployment", http.StatusInternalServerError)
+	}
+
+	c.Settings.BirdNET.Latitude = req.Latitude
+	c.Settings.BirdNET.Longitude = req.Longitude
+	c.Settings.BirdNET.DeploymentID = next.Name
+
+	// Rebuilds the range filter for the new coordinates
+	if err := c.handleSettingsChanges(&oldSettings, c.Settings); err != nil {
+		*c.Settings = oldSettings
+		return c.HandleError(ctx, err, "Failed to apply settings changes, rolled back to previous settings", http.StatusInternalServerError)
+	}
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			*c.Settings = oldSettings
+			return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+		}
+	}
+	c.recordSettingsChanges(&oldSettings, c.Settings, settingsChangeActor(ctx), settingsChangeSourceAPI, ctx.RealIP())
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Station location changed",
+			"deployment_id", next.ID,
+			"name", next.Name,
+			"latitude", next.Latitude,
+			"longitude", next.Longitude,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, LocationChangeResponse{
+		Message:            "Station location changed",
+		Deployment:         deploymentInfo(next),
+		RangeFilterRebuild: rangeFilterSettingsChanged(&oldSettings, c.Settings),
+	})
+}
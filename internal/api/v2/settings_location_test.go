@@ -0,0 +1,88 @@
+// settings_location_test.go: tests for the station location change workflow
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestChangeLocation(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+	controller.Settings.BirdNET.Latitude = 60.1
+	controller.Settings.BirdNET.Longitude = 24.9
+	controller.Settings.BirdNET.DeploymentID = "home"
+
+	post := func(body string) int {
+		return deploymentRequest(t, e, controller.ChangeLocation, http.MethodPost, "/api/v2/settings/location", body).Code
+	}
+
+	// Plain datastore without deployment support
+	assert.Equal(t, http.StatusServiceUnavailable, post(`{"latitude":61.5,"longitude":23.8}`))
+
+	store := &mockDeploymentStore{MockDataStore: mockDS, deployments: []datastore.Deployment{
+		{ID: 1, Name: "home", Latitude: 60.1, Longitude: 24.9, Microphone: "Clippy EM272", Gain: "preamp +20 dB", StartedAt: time.Now().AddDate(0, -1, 0)},
+	}}
+	controller.DS = store
+
+	for _, body := range []string{
+		`{"latitude":91,"longitude":23.8}`,
+		`{"latitude":61.5,"longitude":-181}`,
+		`{"latitude":60.1,"longitude":24.9,"name":"home"}`, // unchanged
+		`{"latitude":61.5,"longitude":23.8,"name":"` + strings.Repeat("x", maxDeploymentFieldLength+1) + `"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, post(body), body)
+	}
+	assert.Len(t, store.deployments, 1)
+
+	rec := deploymentRequest(t, e, controller.ChangeLocation, http.MethodPost, "/api/v2/settings/location",
+		`{"latitude":61.5,"longitude":23.8,"name":" garden ","placement":"fence post","description":"moved to the garden"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response LocationChangeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.RangeFilterRebuild)
+	assert.Equal(t, "garden", response.Deployment.Name)
+	assert.Equal(t, "Clippy EM272", response.Deployment.Microphone, "equipment moves with the station")
+	assert.Equal(t, "preamp +20 dB", response.Deployment.Gain)
+	assert.Nil(t, response.Deployment.EndedAt)
+
+	assert.InDelta(t, 61.5, controller.Settings.BirdNET.Latitude, 1e-9)
+	assert.InDelta(t, 23.8, controller.Settings.BirdNET.Longitude, 1e-9)
+	assert.Equal(t, "garden", controller.Settings.BirdNET.DeploymentID)
+
+	// The previous deployment is kept in the history
+	require.Len(t, store.deployments, 2)
+	assert.InDelta(t, 60.1, store.deployments[0].Latitude, 1e-9)
+	require.NotNil(t, store.deployments[0].EndedAt)
+
+	// The range filter is rebuilt for the new coordinates
+	select {
+	case action := <-controller.controlChan:
+		assert.Equal(t, "rebuild_range_filter", action)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "range filter rebuild was not triggered")
+	}
+
+	// Earlier clients name the deployment with deploymentId
+	rec = deploymentRequest(t, e, controller.ChangeLocation, http.MethodPost, "/api/v2/settings/location",
+		`{"latitude":61.5,"longitude":23.8,"deploymentId":"garden-2024"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "garden-2024", controller.Settings.BirdNET.DeploymentID)
+
+	rec = deploymentRequest(t, e, controller.GetDeployments, http.MethodGet, "/api/v2/deployments", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Deployments []DeploymentInfo `json:"deployments"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list.Deployments, 3)
+}
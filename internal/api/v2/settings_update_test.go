@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -365,6 +366,49 @@ func TestSpeciesConfigUpdate(t *testing.T) {
 	}
 }
 
+// TestEventIntervalsUpdate verifies per-integration duplicate intervals are updated at runtime
+func TestEventIntervalsUpdate(t *testing.T) {
+	initialSettings := getTestSettings(t)
+	initialSettings.Realtime.EventIntervals = conf.EventIntervalSettings{Database: 60}
+
+	e := echo.New()
+	controller := &Controller{
+		Echo:                e,
+		Settings:            initialSettings,
+		controlChan:         make(chan string, 10),
+		DisableSaveSettings: true,
+		logger:              log.New(io.Discard, "TEST: ", log.LstdFlags),
+	}
+
+	update := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v2/settings/realtime", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("section")
+		ctx.SetParamValues("realtime")
+		_ = controller.UpdateSectionSettings(ctx)
+		return rec
+	}
+
+	rec := update(`{"eventIntervals": {"mqtt": 300}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 300, controller.Settings.Realtime.EventIntervals.MQTT) // Changed
+	assert.Equal(t, 60, controller.Settings.Realtime.EventIntervals.Database) // Preserved
+
+	// The event tracker is rebuilt with the new intervals
+	select {
+	case action := <-controller.controlChan:
+		assert.Equal(t, "update_detection_intervals", action)
+	case <-time.After(time.Second):
+		t.Fatal("interval change did not trigger an event tracker update")
+	}
+
+	rec = update(`{"eventIntervals": {"sse": -5}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 0, controller.Settings.Realtime.EventIntervals.SSE)
+}
+
 // TestEmptyUpdatePreservesEverything verifies empty updates don't change anything
 func TestEmptyUpdatePreservesEverything(t *testing.T) {
 	// Get initial settings and override some values for testing
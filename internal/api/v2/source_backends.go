@@ -0,0 +1,48 @@
+// internal/api/v2/source_backends.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// InputBackendsResponse lists the input backends of the system and the configured sources
+type InputBackendsResponse struct {
+	Backends []myaudio.InputBackendInfo `json:"backends"`
+	Source   string                     `json:"source"`  // realtime.audio.source
+	Backend  string                     `json:"backend"` // backend of the source, empty for the default of the platform
+	Streams  []string                   `json:"streams"` // realtime.rtsp.urls, without credentials
+}
+
+// GetInputBackends handles GET /api/v2/sources/backends
+// Enumerates the input backends with the capture devices they offer, each with the declaration
+// that selects it as realtime.audio.source, for the audio source picker of the settings UI.
+func (c *Controller) GetInputBackends(ctx echo.Context) error {
+	response := InputBackendsResponse{
+		Backends: myaudio.ListInputBackends(c.Settings),
+		Source:   c.Settings.Realtime.Audio.Source,
+		Backend:  myaudio.ParseSourceSpec(c.Settings.Realtime.Audio.Source).Backend,
+		Streams:  make([]string, 0, len(c.Settings.Realtime.RTSP.URLs)),
+	}
+	for _, url := range c.Settings.Realtime.RTSP.URLs {
+		response.Streams = append(response.Streams, privacy.SanitizeRTSPUrl(url))
+	}
+
+	if c.apiLogger != nil {
+		devices := 0
+		for i := range response.Backends {
+			devices += len(response.Backends[i].Devices)
+		}
+		c.apiLogger.Info("Input backends retrieved",
+			"backends", len(response.Backends),
+			"devices", devices,
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
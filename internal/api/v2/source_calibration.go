@@ -0,0 +1,153 @@
+// internal/api/v2/source_calibration.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// CalibrationResponse lists the calibration profiles of the audio sources
+type CalibrationResponse struct {
+	TargetLUFS      float64                   `json:"targetLufs"`
+	Window          int                       `json:"window"` // seconds a calibration measures
+	ApplyToAnalysis bool                      `json:"applyToAnalysis"`
+	Profiles        []conf.CalibrationProfile `json:"profiles"`
+	Calibrating     []string                  `json:"calibrating"` // IDs of the sources being calibrated
+}
+
+// CalibrationStartResponse describes a calibration started in the background
+type CalibrationStartResponse struct {
+	SourceID string `json:"sourceId"`
+	Source   string `json:"source"` // sound card, or stream URL without credentials
+	Window   int    `json:"window"` // seconds until the profile is stored
+}
+
+// GetSourceCalibration handles GET /api/v2/sources/calibration
+// Returns the stored calibration profiles and the sources being calibrated.
+func (c *Controller) GetSourceCalibration(ctx echo.Context) error {
+	c.settingsMutex.RLock()
+	calibration := c.Settings.Realtime.Audio.Calibration
+	c.settingsMutex.RUnlock()
+
+	response := CalibrationResponse{
+		TargetLUFS:      calibration.TargetLUFS,
+		Window:          calibration.Window,
+		ApplyToAnalysis: calibration.ApplyToAnalysis,
+		Profiles:        slices.Clone(calibration.Profiles),
+		Calibrating:     []string{},
+	}
+	if response.Profiles == nil {
+		response.Profiles = []conf.CalibrationProfile{}
+	}
+	for _, source := range myaudio.GetRegistry().ListSources() {
+		if myaudio.IsCalibrating(source.ID) {
+			response.Calibrating = append(response.Calibrating, source.ID)
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// StartSourceCalibration handles POST /api/v2/sources/:id/calibrate
+// Starts measuring the typical loudness of a source over the calibration window. When the
+// window has passed the gain offset of the source is stored in the settings, replacing its
+// previous profile.
+func (c *Controller) StartSourceCalibration(ctx echo.Context) error {
+	sourceID := ctx.Param("id")
+	source, exists := myaudio.GetRegistry().GetSourceByID(sourceID)
+	if !exists {
+		return c.HandleError(ctx, fmt.Errorf("source %s not found", sourceID), "Audio source not found", http.StatusNotFound)
+	}
+	if myaudio.IsCalibrating(sourceID) {
+		return c.HandleError(ctx, fmt.Errorf("source %s is already being calibrated", sourceID),
+			"Audio source is already being calibrated", http.StatusConflict)
+	}
+	if c.ctx == nil {
+		return c.HandleError(ctx, fmt.Errorf("background tasks are not available"),
+			"Calibration is not available", http.StatusServiceUnavailable)
+	}
+
+	c.settingsMutex.RLock()
+	calibration := c.Settings.Realtime.Audio.Calibration
+	c.settingsMutex.RUnlock()
+
+	actor, remoteIP := settingsChangeActor(ctx), ctx.RealIP()
+	window := time.Duration(calibration.Window) * time.Second
+	c.wg.Go(func() {
+		c.runSourceCalibration(c.ctx, sourceID, window, calibration.TargetLUFS, actor, remoteIP)
+	})
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Source calibration started",
+			"source_id", sourceID,
+			"window_seconds", calibration.Window,
+			"path", ctx.Request().URL.Path,
+			"ip", remoteIP,
+		)
+	}
+
+	return ctx.JSON(http.StatusAccepted, CalibrationStartResponse{
+		SourceID: sourceID,
+		Source:   source.SafeString,
+		Window:   calibration.Window,
+	})
+}
+
+// runSourceCalibration calibrates a source and stores its profile
+func (c *Controller) runSourceCalibration(ctx context.Context, sourceID string, window time.Duration, targetLUFS float64, actor, remoteIP string) {
+	profile, err := myaudio.CalibrateSource(ctx, sourceID, window, targetLUFS)
+	if err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Warn("Source calibration failed", "source_id", sourceID, "error", err)
+		}
+		_ = c.SendToast("Calibration of audio source failed", "error", 5000)
+		return
+	}
+
+	if err := c.storeCalibrationProfile(profile, actor, remoteIP); err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Error("Failed to save calibration profile", "source_id", sourceID, "error", err)
+		}
+		_ = c.SendToast("Failed to save calibration of audio source", "error", 5000)
+		return
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Source calibrated",
+			"source_id", sourceID,
+			"measured_lufs", profile.MeasuredLUFS,
+			"gain_db", profile.Gain,
+		)
+	}
+	_ = c.SendToast(fmt.Sprintf("Audio source calibrated, gain offset %+.1f dB", profile.Gain), "success", 5000)
+}
+
+// storeCalibrationProfile saves a calibration profile, replacing the previous profile of its
+// source, and records the change in the settings audit log. If saving fails the settings are
+// left unchanged.
+func (c *Controller) storeCalibrationProfile(profile conf.CalibrationProfile, actor, remoteIP string) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	oldSettings := *c.Settings
+	// Replace the slice instead of updating it in place, myaudio reads it without the lock
+	profiles := slices.DeleteFunc(slices.Clone(c.Settings.Realtime.Audio.Calibration.Profiles),
+		func(p conf.CalibrationProfile) bool { return p.Source == profile.Source })
+	c.Settings.Realtime.Audio.Calibration.Profiles = append(profiles, profile)
+
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			*c.Settings = oldSettings
+			return err
+		}
+	}
+	c.recordSettingsChanges(&oldSettings, c.Settings, actor, settingsChangeSourceAPI, remoteIP)
+	return nil
+}
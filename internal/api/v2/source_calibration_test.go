@@ -0,0 +1,71 @@
+// source_calibration_test.go: tests for the source calibration endpoints
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestGetSourceCalibration(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.Settings.Realtime.Audio.Calibration = conf.CalibrationSettings{
+		TargetLUFS: -30,
+		Window:     300,
+		Profiles:   []conf.CalibrationProfile{{Source: "rtsp://camera.local/stream", Gain: 12, MeasuredLUFS: -42}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/sources/calibration", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSourceCalibration(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response CalibrationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.InDelta(t, -30, response.TargetLUFS, 0)
+	assert.Equal(t, 300, response.Window)
+	require.Len(t, response.Profiles, 1)
+	assert.InDelta(t, 12, response.Profiles[0].Gain, 0)
+	assert.Empty(t, response.Calibrating)
+}
+
+func TestStartSourceCalibrationUnknownSource(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/sources/rtsp_missing/calibrate", http.NoBody)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("rtsp_missing")
+	require.NoError(t, controller.StartSourceCalibration(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestStoreCalibrationProfile(t *testing.T) {
+	_, _, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+	controller.Settings.Realtime.Audio.Calibration.Profiles = []conf.CalibrationProfile{
+		{Source: "sysdefault", Gain: 3},
+		{Source: "rtsp://camera.local/stream", Gain: 12},
+	}
+	previous := controller.Settings.Realtime.Audio.Calibration.Profiles
+
+	calibrated := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, controller.storeCalibrationProfile(
+		conf.CalibrationProfile{Source: "rtsp://camera.local/stream", Gain: 8, MeasuredLUFS: -38, CalibratedAt: calibrated}, "admin", ""))
+
+	profiles := controller.Settings.Realtime.Audio.Calibration.Profiles
+	require.Len(t, profiles, 2, "the profile of the source is replaced")
+	profile, ok := controller.Settings.Realtime.Audio.Calibration.Profile("rtsp://camera.local/stream")
+	require.True(t, ok)
+	assert.InDelta(t, 8, profile.Gain, 0)
+	assert.Equal(t, calibrated, profile.CalibratedAt)
+	assert.InDelta(t, 12, previous[1].Gain, 0, "the previous slice is not modified")
+}
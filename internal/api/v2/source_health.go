@@ -0,0 +1,50 @@
+// internal/api/v2/source_health.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// SourceHealthResponse lists the health of the RTSP sources
+type SourceHealthResponse struct {
+	Sources []myaudio.SourceHealth `json:"sources"`
+	Healthy int                    `json:"healthy"`
+	Total   int                    `json:"total"`
+}
+
+// initSourceRoutes registers the audio source routes
+func (c *Controller) initSourceRoutes() {
+	sourceGroup := c.Group.Group("/sources", c.getEffectiveAuthMiddleware())
+	sourceGroup.GET("/health", c.GetSourceHealth)
+	sourceGroup.GET("/backends", c.GetInputBackends)
+	sourceGroup.GET("/calibration", c.GetSourceCalibration)
+	sourceGroup.POST("/:id/calibrate", c.StartSourceCalibration, auth.RequireAdmin)
+}
+
+// GetSourceHealth handles GET /api/v2/sources/health
+// Returns the liveness of each RTSP source: when it last delivered audio, its data and frame
+// rate, and its restarts. Changes of source health are also published on the event bus.
+func (c *Controller) GetSourceHealth(ctx echo.Context) error {
+	response := SourceHealthResponse{Sources: myaudio.GetRTSPSourceHealth()}
+	response.Total = len(response.Sources)
+	for i := range response.Sources {
+		if response.Sources[i].Healthy {
+			response.Healthy++
+		}
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Source health retrieved",
+			"sources", response.Total,
+			"healthy", response.Healthy,
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
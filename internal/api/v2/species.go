@@ -9,8 +9,10 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/ebird"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/observation"
 )
 
@@ -59,6 +61,7 @@ func (c *Controller) initSpeciesRoutes() {
 	// Public endpoints for species information
 	c.Group.GET("/species", c.GetSpeciesInfo)
 	c.Group.GET("/species/taxonomy", c.GetSpeciesTaxonomy)
+	c.Group.GET("/species/recipes", c.GetSpeciesActionRecipes)
 	
 	// RESTful thumbnail endpoint - uses species code from path
 	c.Group.GET("/species/:code/thumbnail", c.GetSpeciesThumbnail)
@@ -504,6 +507,17 @@ func (c *Controller) GetSpeciesThumbnail(ctx echo.Context) error {
 		)
 	}
 
+	// Record license/author usage in the attribution ledger
+	c.BirdImageCache.RecordUsage(&birdImage, imageprovider.UsageWeb)
+
 	// Redirect to the image URL
 	return ctx.Redirect(http.StatusFound, birdImage.URL)
-}
\ No newline at end of file
+}
+
+// GetSpeciesActionRecipes handles GET /api/v2/species/recipes
+// Lists the built-in action recipes selectable by name in the recipes of species configs.
+func (c *Controller) GetSpeciesActionRecipes(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"recipes": conf.ActionRecipes(),
+	})
+}
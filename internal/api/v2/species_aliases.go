@@ -0,0 +1,190 @@
+// internal/api/v2/species_aliases.go
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// ErrSpeciesAliasesNotAvailable is returned when the datastore does not support species aliases
+var ErrSpeciesAliasesNotAvailable = errors.NewStd("species aliases not available")
+
+// SpeciesAliasRequest links a scientific name replaced by a taxonomy update to the current name
+type SpeciesAliasRequest struct {
+	OldScientificName string `json:"oldScientificName"`
+	ScientificName    string `json:"scientificName"`
+	CommonName        string `json:"commonName"`
+	SpeciesCode       string `json:"speciesCode"`
+	Reason            string `json:"reason"`
+}
+
+// SpeciesAliasInfo describes a species alias
+type SpeciesAliasInfo struct {
+	OldScientificName string    `json:"oldScientificName"`
+	ScientificName    string    `json:"scientificName"`
+	CommonName        string    `json:"commonName,omitempty"`
+	SpeciesCode       string    `json:"speciesCode,omitempty"`
+	Reason            string    `json:"reason,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+// initSpeciesAliasRoutes registers the species alias endpoints
+func (c *Controller) initSpeciesAliasRoutes() {
+	aliasGroup := c.Group.Group("/species/aliases", c.getEffectiveAuthMiddleware())
+	aliasGroup.GET("", c.GetSpeciesAliases)
+	aliasGroup.POST("", c.SaveSpeciesAlias, auth.RequireAdmin)
+	aliasGroup.POST("/merge", c.MergeSpeciesAliases, auth.RequireAdmin)
+	aliasGroup.DELETE("/:name", c.DeleteSpeciesAlias, auth.RequireAdmin)
+}
+
+// GetSpeciesAliases handles GET /api/v2/species/aliases
+func (c *Controller) GetSpeciesAliases(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.SpeciesAliasStore)
+	if !ok {
+		return c.HandleError(ctx, ErrSpeciesAliasesNotAvailable, "Species aliases unavailable", http.StatusServiceUnavailable)
+	}
+
+	aliases, err := store.GetSpeciesAliases()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get species aliases", http.StatusInternalServerError)
+	}
+
+	result := make([]SpeciesAliasInfo, 0, len(aliases))
+	for i := range aliases {
+		result = append(result, speciesAliasInfo(&aliases[i]))
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"aliases": result,
+	})
+}
+
+// SaveSpeciesAlias handles POST /api/v2/species/aliases
+// Adds an alias, or replaces the alias of the same old name. New detections with the old name
+// are stored under the current name right away; historical notes are moved by
+// POST /api/v2/species/aliases/merge.
+func (c *Controller) SaveSpeciesAlias(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.SpeciesAliasStore)
+	if !ok {
+		return c.HandleError(ctx, ErrSpeciesAliasesNotAvailable, "Species aliases unavailable", http.StatusServiceUnavailable)
+	}
+
+	var req SpeciesAliasRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+
+	alias := &datastore.SpeciesAlias{
+		OldScientificName: req.OldScientificName,
+		ScientificName:    req.ScientificName,
+		CommonName:        req.CommonName,
+		SpeciesCode:       req.SpeciesCode,
+		Reason:            req.Reason,
+	}
+	if err := store.SaveSpeciesAlias(alias); err != nil {
+		var enhanced *errors.EnhancedError
+		if errors.As(err, &enhanced) && enhanced.Category == errors.CategoryValidation {
+			return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+		}
+		return c.HandleError(ctx, err, "Failed to save species alias", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Species alias saved",
+			"old_scientific_name", alias.OldScientificName,
+			"scientific_name", alias.ScientificName,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, speciesAliasInfo(alias))
+}
+
+// DeleteSpeciesAlias handles DELETE /api/v2/species/aliases/:name
+// Removes the alias of an old scientific name. Notes already merged keep the current name.
+func (c *Controller) DeleteSpeciesAlias(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.SpeciesAliasStore)
+	if !ok {
+		return c.HandleError(ctx, ErrSpeciesAliasesNotAvailable, "Species aliases unavailable", http.StatusServiceUnavailable)
+	}
+
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid scientific name", http.StatusBadRequest)
+	}
+
+	if err := store.DeleteSpeciesAlias(name); err != nil {
+		var enhanced *errors.EnhancedError
+		if errors.As(err, &enhanced) && enhanced.Category == errors.CategoryNotFound {
+			return c.HandleError(ctx, err, "Species alias not found", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to delete species alias", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Species alias deleted",
+			"old_scientific_name", name,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// MergeSpeciesAliases handles POST /api/v2/species/aliases/merge
+// Moves the notes recorded under old names to the current names and returns the migration
+// report of affected notes per alias.
+// Query parameters:
+// - dryRun: only report the affected notes (default: false)
+func (c *Controller) MergeSpeciesAliases(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.SpeciesAliasStore)
+	if !ok {
+		return c.HandleError(ctx, ErrSpeciesAliasesNotAvailable, "Species aliases unavailable", http.StatusServiceUnavailable)
+	}
+
+	dryRun := false
+	if param := ctx.QueryParam("dryRun"); param != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(param); err != nil {
+			return c.HandleError(ctx, err, "Invalid dryRun parameter", http.StatusBadRequest)
+		}
+	}
+
+	reports, err := store.MergeSpeciesAliases(ctx.Request().Context(), dryRun)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to merge species aliases", http.StatusInternalServerError)
+	}
+
+	var merged int64
+	for i := range reports {
+		merged += reports[i].Notes
+	}
+	if !dryRun && c.apiLogger != nil {
+		c.apiLogger.Info("Species aliases merged",
+			"aliases", len(reports),
+			"notes", merged,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"dryRun":  dryRun,
+		"notes":   merged,
+		"aliases": reports,
+	})
+}
+
+// speciesAliasInfo converts a stored species alias to its API representation
+func speciesAliasInfo(alias *datastore.SpeciesAlias) SpeciesAliasInfo {
+	return SpeciesAliasInfo{
+		OldScientificName: alias.OldScientificName,
+		ScientificName:    alias.ScientificName,
+		CommonName:        alias.CommonName,
+		SpeciesCode:       alias.SpeciesCode,
+		Reason:            alias.Reason,
+		CreatedAt:         alias.CreatedAt,
+	}
+}
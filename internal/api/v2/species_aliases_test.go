@@ -0,0 +1,126 @@
+// species_aliases_test.go: tests for the species alias endpoints
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// mockSpeciesAliasStore adds the optional species alias capability to MockDataStore
+type mockSpeciesAliasStore struct {
+	*MockDataStore
+	aliases map[string]datastore.SpeciesAlias
+	notes   map[string]int64 // notes per scientific name
+}
+
+func (m *mockSpeciesAliasStore) GetSpeciesAliases() ([]datastore.SpeciesAlias, error) {
+	aliases := make([]datastore.SpeciesAlias, 0, len(m.aliases))
+	for _, alias := range m.aliases {
+		aliases = append(aliases, alias)
+	}
+	return aliases, nil
+}
+
+func (m *mockSpeciesAliasStore) SaveSpeciesAlias(alias *datastore.SpeciesAlias) error {
+	if alias.OldScientificName == "" || alias.OldScientificName == alias.ScientificName {
+		return errors.Newf("invalid alias").Category(errors.CategoryValidation).Build()
+	}
+	m.aliases[alias.OldScientificName] = *alias
+	return nil
+}
+
+func (m *mockSpeciesAliasStore) DeleteSpeciesAlias(oldScientificName string) error {
+	if _, ok := m.aliases[oldScientificName]; !ok {
+		return errors.Newf("species alias not found").Category(errors.CategoryNotFound).Build()
+	}
+	delete(m.aliases, oldScientificName)
+	return nil
+}
+
+func (m *mockSpeciesAliasStore) MergeSpeciesAliases(_ context.Context, dryRun bool) ([]datastore.SpeciesAliasReport, error) {
+	reports := []datastore.SpeciesAliasReport{}
+	for _, alias := range m.aliases {
+		reports = append(reports, datastore.SpeciesAliasReport{
+			OldScientificName: alias.OldScientificName,
+			ScientificName:    alias.ScientificName,
+			Notes:             m.notes[alias.OldScientificName],
+		})
+		if !dryRun {
+			m.notes[alias.ScientificName] += m.notes[alias.OldScientificName]
+			delete(m.notes, alias.OldScientificName)
+		}
+	}
+	return reports, nil
+}
+
+func TestSpeciesAliasEndpoints(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// Plain datastore without species alias support
+	rec := deploymentRequest(t, e, controller.GetSpeciesAliases, http.MethodGet, "/api/v2/species/aliases", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	store := &mockSpeciesAliasStore{
+		MockDataStore: mockDS,
+		aliases:       make(map[string]datastore.SpeciesAlias),
+		notes:         map[string]int64{"Cyanocitta cristata": 3},
+	}
+	controller.DS = store
+
+	rec = deploymentRequest(t, e, controller.SaveSpeciesAlias, http.MethodPost, "/api/v2/species/aliases",
+		`{"oldScientificName":"Cyanocitta cristata","scientificName":"Cyanocitta cristata"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = deploymentRequest(t, e, controller.SaveSpeciesAlias, http.MethodPost, "/api/v2/species/aliases",
+		`{"oldScientificName":"Cyanocitta cristata","scientificName":"Cyanocitta cristatus","reason":"rename"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = deploymentRequest(t, e, controller.GetSpeciesAliases, http.MethodGet, "/api/v2/species/aliases", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Aliases []SpeciesAliasInfo `json:"aliases"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Aliases, 1)
+	assert.Equal(t, "Cyanocitta cristatus", list.Aliases[0].ScientificName)
+
+	var report struct {
+		DryRun  bool                           `json:"dryRun"`
+		Notes   int64                          `json:"notes"`
+		Aliases []datastore.SpeciesAliasReport `json:"aliases"`
+	}
+	rec = deploymentRequest(t, e, controller.MergeSpeciesAliases, http.MethodPost, "/api/v2/species/aliases/merge?dryRun=true", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, int64(3), report.Notes)
+	assert.Equal(t, int64(3), store.notes["Cyanocitta cristata"], "dry run leaves the notes unchanged")
+
+	rec = deploymentRequest(t, e, controller.MergeSpeciesAliases, http.MethodPost, "/api/v2/species/aliases/merge", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(3), store.notes["Cyanocitta cristatus"])
+
+	rec = deploymentRequest(t, e, controller.MergeSpeciesAliases, http.MethodPost, "/api/v2/species/aliases/merge?dryRun=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	deleteAlias := func(name string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v2/species/aliases/"+name, http.NoBody)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("name")
+		ctx.SetParamValues(name)
+		require.NoError(t, controller.DeleteSpeciesAlias(ctx))
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusNoContent, deleteAlias("Cyanocitta%20cristata"))
+	assert.Equal(t, http.StatusNotFound, deleteAlias("Cyanocitta%20cristata"))
+}
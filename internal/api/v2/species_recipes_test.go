@@ -0,0 +1,40 @@
+// species_recipes_test.go: tests for the species action recipe listing
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpeciesActionRecipes(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	rec := deploymentRequest(t, e, controller.GetSpeciesActionRecipes, http.MethodGet, "/api/v2/species/recipes", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response struct {
+		Recipes []struct {
+			Name            string `json:"name"`
+			Description     string `json:"description"`
+			RequiresCommand bool   `json:"requiresCommand"`
+		} `json:"recipes"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	requiresCommand := make(map[string]bool)
+	for _, recipe := range response.Recipes {
+		assert.NotEmpty(t, recipe.Description, recipe.Name)
+		requiresCommand[recipe.Name] = recipe.RequiresCommand
+	}
+	assert.Equal(t, map[string]bool{
+		"notify":           false,
+		"notify-at-night":  false,
+		"upload-rare-only": false,
+		"trigger-camera":   true,
+	}, requiresCommand)
+}
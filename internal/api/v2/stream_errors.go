@@ -0,0 +1,55 @@
+// internal/api/v2/stream_errors.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Limits for the number of errors and streams in the stream errors response
+const (
+	streamErrorsDefaultLimit = 10
+	streamErrorsMaxLimit     = 100
+)
+
+// StreamErrorsResponse lists recurring FFmpeg errors and the streams failing most often
+type StreamErrorsResponse struct {
+	Errors  []myaudio.FFmpegErrorSummary   `json:"errors"`
+	Streams []myaudio.FFmpegStreamFailures `json:"streams"`
+}
+
+// GetStreamErrors handles GET /api/v2/system/audio/stream-errors
+// Returns the most frequent FFmpeg errors since startup, grouped by fingerprint, and the
+// streams with the most errors, to find the flakiest camera.
+// Query parameters: limit (optional, default 10, max 100)
+func (c *Controller) GetStreamErrors(ctx echo.Context) error {
+	limit := streamErrorsDefaultLimit
+	if limitParam := ctx.QueryParam("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > streamErrorsMaxLimit {
+			return c.HandleError(ctx, fmt.Errorf("invalid limit: %s", limitParam),
+				fmt.Sprintf("Limit must be between 1 and %d", streamErrorsMaxLimit), http.StatusBadRequest)
+		}
+		limit = parsed
+	}
+
+	response := StreamErrorsResponse{
+		Errors:  myaudio.GetTopFFmpegErrors(limit),
+		Streams: myaudio.GetTopFailingStreams(limit),
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Stream errors retrieved",
+			"errors", len(response.Errors),
+			"streams", len(response.Streams),
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,29 @@
+// stream_errors_test.go: tests for the FFmpeg stream errors endpoint
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStreamErrors(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/system/audio/stream-errors", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetStreamErrors(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"errors":[],"streams":[]}`, rec.Body.String(), "no errors are recorded without running streams")
+
+	for _, query := range []string{"limit=0", "limit=101", "limit=abc"} {
+		req = httptest.NewRequest(http.MethodGet, "/api/v2/system/audio/stream-errors?"+query, http.NoBody)
+		rec = httptest.NewRecorder()
+		require.NoError(t, controller.GetStreamErrors(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/support"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
@@ -258,8 +259,8 @@ func (c *Controller) GetSupportStatus(ctx echo.Context) error {
 // initSupportRoutes registers support-related routes
 func (c *Controller) initSupportRoutes() {
 	// Support endpoints require authentication
-	c.Group.POST("/support/generate", c.GenerateSupportDump, c.authMiddlewareFn)
-	c.Group.GET("/support/download/:id", c.DownloadSupportDump, c.authMiddlewareFn)
+	c.Group.POST("/support/generate", c.GenerateSupportDump, c.authMiddlewareFn, auth.RequireAdmin)
+	c.Group.GET("/support/download/:id", c.DownloadSupportDump, c.authMiddlewareFn, auth.RequireAdmin)
 	c.Group.GET("/support/status", c.GetSupportStatus, c.authMiddlewareFn)
 
 	// Start cleanup goroutine for old support dumps with proper context
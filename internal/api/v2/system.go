@@ -4,6 +4,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -23,8 +24,11 @@ import (
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/process"
 	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/updater"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -305,12 +309,23 @@ func (c *Controller) initSystemRoutes() {
 	protectedGroup.GET("/jobs", c.GetJobQueueStats)
 	protectedGroup.GET("/processes", c.GetProcessInfo)
 	protectedGroup.GET("/temperature/cpu", c.GetSystemCPUTemperature)
+	protectedGroup.GET("/runs", c.GetRunHistory)
+	protectedGroup.GET("/update", c.CheckForUpdate)
+	protectedGroup.GET("/ffmpeg", c.GetFFmpegCapabilities)
+	protectedGroup.POST("/update", c.ApplyUpdate, auth.RequireAdmin)
+
+	// Time-boxed debug capture routes (all protected)
+	protectedGroup.GET("/debug-capture", c.GetDebugCaptureStatus)
+	protectedGroup.POST("/debug-capture/:component", c.StartDebugCapture, auth.RequireAdmin)
+	protectedGroup.DELETE("/debug-capture/:component", c.StopDebugCapture, auth.RequireAdmin)
 
 	// Audio device routes (all protected)
 	audioGroup := protectedGroup.Group("/audio")
 	audioGroup.GET("/devices", c.GetAudioDevices)
 	audioGroup.GET("/active", c.GetActiveAudioDevice)
 	audioGroup.GET("/equalizer/config", c.GetEqualizerConfig)
+	audioGroup.GET("/stream-errors", c.GetStreamErrors)
+	audioGroup.GET("/inference-share", c.GetInferenceShare)
 
 	if c.apiLogger != nil {
 		c.apiLogger.Info("System routes initialized successfully")
@@ -1448,3 +1463,184 @@ func (c *Controller) GetEqualizerConfig(ctx echo.Context) error {
 	// Return the equalizer filter configuration
 	return ctx.JSON(http.StatusOK, conf.EqFilterConfig)
 }
+
+// RunInfo describes one application run and the gap in detection data before it
+type RunInfo struct {
+	ID                 uint       `json:"id"`
+	StartedAt          time.Time  `json:"started_at"`
+	StoppedAt          *time.Time `json:"stopped_at,omitempty"`
+	Running            bool       `json:"running"`
+	Version            string     `json:"version"`
+	StartReason        string     `json:"start_reason"`
+	StopReason         string     `json:"stop_reason,omitempty"`
+	StopDetail         string     `json:"stop_detail,omitempty"`
+	DowntimeBeforeSecs int64      `json:"downtime_before_seconds"` // time between the previous run stopping and this run starting
+}
+
+// ErrRunHistoryNotAvailable is returned when the datastore does not record application runs
+var ErrRunHistoryNotAvailable = errors.New("run history not available")
+
+// GetRunHistory handles GET /api/v2/system/runs
+// Returns recent application runs with start and stop reasons, newest first.
+// Query parameters: limit (optional, default 50, max 500)
+func (c *Controller) GetRunHistory(ctx echo.Context) error {
+	ledger, ok := c.DS.(datastore.RunLedger)
+	if !ok {
+		return c.HandleError(ctx, ErrRunHistoryNotAvailable, "Run history unavailable", http.StatusServiceUnavailable)
+	}
+
+	limit := 50
+	if limitParam := ctx.QueryParam("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > 500 {
+			return c.HandleError(ctx, fmt.Errorf("invalid limit: %s", limitParam), "Limit must be between 1 and 500", http.StatusBadRequest)
+		}
+		limit = parsed
+	}
+
+	// Fetch one extra run to calculate the downtime before the oldest returned run
+	runs, err := ledger.GetRuns(limit + 1)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get run history", http.StatusInternalServerError)
+	}
+
+	count := min(len(runs), limit)
+	result := make([]RunInfo, 0, count)
+	for i := 0; i < count; i++ {
+		run := &runs[i]
+		info := RunInfo{
+			ID:          run.ID,
+			StartedAt:   run.StartedAt,
+			StoppedAt:   run.StoppedAt,
+			Running:     run.StoppedAt == nil && i == 0,
+			Version:     run.Version,
+			StartReason: run.StartReason,
+			StopReason:  run.StopReason,
+			StopDetail:  run.StopDetail,
+		}
+		if i+1 < len(runs) && runs[i+1].StoppedAt != nil {
+			info.DowntimeBeforeSecs = max(0, int64(run.StartedAt.Sub(*runs[i+1].StoppedAt).Seconds()))
+		}
+		result = append(result, info)
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// updateRestartDelay gives the update response time to reach the client before restarting
+const updateRestartDelay = 2 * time.Second
+
+// SelfUpdateRequest is the optional body of POST /api/v2/system/update
+type SelfUpdateRequest struct {
+	Channel string `json:"channel"` // release channel override, "stable" or "beta"
+}
+
+// newUpdater creates an updater for the running binary using the given channel,
+// or the configured channel if empty
+func (c *Controller) newUpdater(channel string) (*updater.Updater, error) {
+	return updater.New(c.Settings, channel)
+}
+
+// handleUpdateError maps self-update errors to HTTP responses
+func (c *Controller) handleUpdateError(ctx echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, updater.ErrUpdatesDisabled):
+		return c.HandleError(ctx, err, "Self-update is disabled", http.StatusForbidden)
+	case errors.Is(err, updater.ErrContainerInstall):
+		return c.HandleError(ctx, err, "Self-update is not supported in containers, update the container image instead", http.StatusConflict)
+	case errors.Is(err, updater.ErrNoUpdateAvailable), errors.Is(err, updater.ErrNoAssetForPlatform):
+		return c.HandleError(ctx, err, "No update available for this platform", http.StatusConflict)
+	case errors.Is(err, updater.ErrSignatureInvalid), errors.Is(err, updater.ErrChecksumMismatch):
+		return c.HandleError(ctx, err, "Release verification failed", http.StatusBadGateway)
+	default:
+		return c.HandleError(ctx, err, message, http.StatusInternalServerError)
+	}
+}
+
+// validUpdateChannel reports whether channel is empty or a known release channel
+func validUpdateChannel(channel string) bool {
+	return channel == "" || channel == conf.UpdateChannelStable || channel == conf.UpdateChannelBeta
+}
+
+// CheckForUpdate handles GET /api/v2/system/update
+// Checks the release feed for a newer release.
+// Query parameters: channel (optional, stable or beta, defaults to the configured channel)
+func (c *Controller) CheckForUpdate(ctx echo.Context) error {
+	channel := ctx.QueryParam("channel")
+	if !validUpdateChannel(channel) {
+		return c.HandleError(ctx, fmt.Errorf("invalid channel: %s", channel), "Channel must be stable or beta", http.StatusBadRequest)
+	}
+
+	u, err := c.newUpdater(channel)
+	if err != nil {
+		return c.handleUpdateError(ctx, err, "Failed to initialize updater")
+	}
+
+	result, err := u.Check(ctx.Request().Context())
+	if err != nil {
+		return c.handleUpdateError(ctx, err, "Failed to check for updates")
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// ApplyUpdate handles POST /api/v2/system/update
+// Installs the latest release of the channel and restarts the service. The previous
+// binary is restored if the new one fails its health check after the restart.
+func (c *Controller) ApplyUpdate(ctx echo.Context) error {
+	var req SelfUpdateRequest
+	if ctx.Request().ContentLength > 0 {
+		if err := ctx.Bind(&req); err != nil {
+			return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+		}
+	}
+	if !validUpdateChannel(req.Channel) {
+		return c.HandleError(ctx, fmt.Errorf("invalid channel: %s", req.Channel), "Channel must be stable or beta", http.StatusBadRequest)
+	}
+
+	if !c.updateMutex.TryLock() {
+		return c.HandleError(ctx, fmt.Errorf("update already in progress"), "An update is already in progress", http.StatusConflict)
+	}
+	defer c.updateMutex.Unlock()
+
+	u, err := c.newUpdater(req.Channel)
+	if err != nil {
+		return c.handleUpdateError(ctx, err, "Failed to initialize updater")
+	}
+
+	reqCtx := ctx.Request().Context()
+	result, err := u.Check(reqCtx)
+	if err != nil {
+		return c.handleUpdateError(ctx, err, "Failed to check for updates")
+	}
+	if !result.Available {
+		return c.handleUpdateError(ctx, updater.ErrNoUpdateAvailable, "No update available")
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Installing update",
+			"from_version", result.CurrentVersion,
+			"to_version", result.Latest.Version,
+			"channel", result.Channel,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	if err := u.Apply(reqCtx, result.Latest); err != nil {
+		return c.handleUpdateError(ctx, err, "Failed to install update")
+	}
+
+	restartCommand := c.Settings.Update.RestartCommand
+	time.AfterFunc(updateRestartDelay, func() {
+		if err := updater.Restart(restartCommand); err != nil && c.apiLogger != nil {
+			c.apiLogger.Error("Failed to restart after update", "error", err.Error())
+		}
+	})
+
+	return ctx.JSON(http.StatusOK, ControlResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Update to %s installed, restarting", result.Latest.Version),
+		Action:    "update",
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,127 @@
+// system_test.go: Package api provides tests for API v2 system endpoints.
+
+package api
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/updater"
+)
+
+// mockRunLedger adds the optional run history capability to MockDataStore
+type mockRunLedger struct {
+	*MockDataStore
+	runs  []datastore.Run
+	limit int
+}
+
+func (m *mockRunLedger) StartRun(version string, startedAt time.Time) (*datastore.Run, error) {
+	return &datastore.Run{Version: version, StartedAt: startedAt}, nil
+}
+
+func (m *mockRunLedger) HeartbeatRun(runID uint, at time.Time) error { return nil }
+
+func (m *mockRunLedger) StopRun(runID uint, reason, detail string, at time.Time) error { return nil }
+
+func (m *mockRunLedger) GetRuns(limit int) ([]datastore.Run, error) {
+	m.limit = limit
+	if limit < len(m.runs) {
+		return m.runs[:limit], nil
+	}
+	return m.runs, nil
+}
+
+func TestGetRunHistory(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// Plain datastore without run history support
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/system/runs", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetRunHistory(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	base := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	crashedAt := base.Add(3 * time.Hour)
+	stoppedAt := base.Add(time.Hour)
+	store := &mockRunLedger{MockDataStore: mockDS, runs: []datastore.Run{
+		{ID: 3, StartedAt: base.Add(4 * time.Hour), Version: "1.1.0", StartReason: datastore.RunStartCrashRecovery},
+		{ID: 2, StartedAt: base.Add(2 * time.Hour), StoppedAt: &crashedAt, StartReason: datastore.RunStartNormal, StopReason: datastore.RunStopCrash},
+		{ID: 1, StartedAt: base, StoppedAt: &stoppedAt, StartReason: datastore.RunStartFirst, StopReason: datastore.RunStopUserRequest},
+	}}
+	controller.DS = store
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/system/runs?limit=2", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetRunHistory(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 3, store.limit, "one extra run is needed for the downtime of the oldest run")
+
+	var runs []RunInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &runs))
+	require.Len(t, runs, 2)
+	assert.True(t, runs[0].Running)
+	assert.Equal(t, int64(3600), runs[0].DowntimeBeforeSecs)
+	assert.Equal(t, datastore.RunStopCrash, runs[1].StopReason)
+	assert.Equal(t, int64(3600), runs[1].DowntimeBeforeSecs)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/system/runs?limit=0", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetRunHistory(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	feed := updater.Feed{Channels: map[string]updater.Release{
+		conf.UpdateChannelStable: {Version: "v99.0.0", Assets: map[string]updater.Asset{
+			runtime.GOOS + "/" + runtime.GOARCH: {URL: "https://example.com/birdnet-go"},
+		}},
+	}}
+	feedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(feed)
+	}))
+	defer feedServer.Close()
+
+	// Self-update is disabled by default
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/system/update", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.CheckForUpdate(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	controller.Settings.Version = "v1.0.0"
+	controller.Settings.Update = conf.UpdateSettings{
+		Enabled:   true,
+		Channel:   conf.UpdateChannelStable,
+		FeedURL:   feedServer.URL,
+		PublicKey: base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize)),
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/system/update", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.CheckForUpdate(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result updater.CheckResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.True(t, result.Available)
+	assert.Equal(t, "v1.0.0", result.CurrentVersion)
+	require.NotNil(t, result.Latest)
+	assert.Equal(t, "v99.0.0", result.Latest.Version)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/system/update?channel=nightly", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.CheckForUpdate(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
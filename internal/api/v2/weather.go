@@ -25,21 +25,22 @@ type DailyWeatherResponse struct {
 
 // HourlyWeatherResponse represents the API response for hourly weather data
 type HourlyWeatherResponse struct {
-	Time        string  `json:"time"`
-	Temperature float64 `json:"temperature"`
-	FeelsLike   float64 `json:"feels_like"`
-	TempMin     float64 `json:"temp_min,omitempty"`
-	TempMax     float64 `json:"temp_max,omitempty"`
-	Pressure    int     `json:"pressure,omitempty"`
-	Humidity    int     `json:"humidity,omitempty"`
-	Visibility  int     `json:"visibility,omitempty"`
-	WindSpeed   float64 `json:"wind_speed,omitempty"`
-	WindDeg     int     `json:"wind_deg,omitempty"`
-	WindGust    float64 `json:"wind_gust,omitempty"`
-	Clouds      int     `json:"clouds,omitempty"`
-	WeatherMain string  `json:"weather_main,omitempty"`
-	WeatherDesc string  `json:"weather_desc,omitempty"`
-	WeatherIcon string  `json:"weather_icon,omitempty"`
+	Time          string  `json:"time"`
+	Temperature   float64 `json:"temperature"`
+	FeelsLike     float64 `json:"feels_like"`
+	TempMin       float64 `json:"temp_min,omitempty"`
+	TempMax       float64 `json:"temp_max,omitempty"`
+	Pressure      int     `json:"pressure,omitempty"`
+	Humidity      int     `json:"humidity,omitempty"`
+	Visibility    int     `json:"visibility,omitempty"`
+	WindSpeed     float64 `json:"wind_speed,omitempty"`
+	WindDeg       int     `json:"wind_deg,omitempty"`
+	WindGust      float64 `json:"wind_gust,omitempty"`
+	Precipitation float64 `json:"precipitation,omitempty"`
+	Clouds        int     `json:"clouds,omitempty"`
+	WeatherMain   string  `json:"weather_main,omitempty"`
+	WeatherDesc   string  `json:"weather_desc,omitempty"`
+	WeatherIcon   string  `json:"weather_icon,omitempty"`
 }
 
 // DetectionWeatherResponse represents weather data associated with a detection
@@ -68,13 +69,16 @@ func (c *Controller) initWeatherRoutes() {
 	weatherGroup.GET("/hourly/:date/:hour", c.GetHourlyWeatherForHour)
 
 	// Weather for a specific detection
-	weatherGroup.GET("/detection/:id", c.GetWeatherForDetection)
+	weatherGroup.GET("/detection/:id", c.GetWeatherForDetection, c.resolveNoteID)
 
 	// Latest weather data
 	weatherGroup.GET("/latest", c.GetLatestWeather)
 
 	// Sun times endpoint using SunCalc
 	weatherGroup.GET("/sun/:date", c.GetSunTimes)
+
+	// Detections grouped by the weather they were heard in
+	weatherGroup.GET("/detections", c.GetDetectionsByWeather)
 }
 
 // buildDailyWeatherResponse creates a DailyWeatherResponse from a DailyEvents struct
@@ -254,21 +258,22 @@ func (c *Controller) GetHourlyWeatherForDay(ctx echo.Context) error {
 	for i := range hourlyWeather {
 		hw := &hourlyWeather[i]
 		response = append(response, HourlyWeatherResponse{
-			Time:        hw.Time.Format("15:04:05"),
-			Temperature: hw.Temperature,
-			FeelsLike:   hw.FeelsLike,
-			TempMin:     hw.TempMin,
-			TempMax:     hw.TempMax,
-			Pressure:    hw.Pressure,
-			Humidity:    hw.Humidity,
-			Visibility:  hw.Visibility,
-			WindSpeed:   hw.WindSpeed,
-			WindDeg:     hw.WindDeg,
-			WindGust:    hw.WindGust,
-			Clouds:      hw.Clouds,
-			WeatherMain: hw.WeatherMain,
-			WeatherDesc: hw.WeatherDesc,
-			WeatherIcon: hw.WeatherIcon,
+			Time:          hw.Time.Format("15:04:05"),
+			Temperature:   hw.Temperature,
+			FeelsLike:     hw.FeelsLike,
+			TempMin:       hw.TempMin,
+			TempMax:       hw.TempMax,
+			Pressure:      hw.Pressure,
+			Humidity:      hw.Humidity,
+			Visibility:    hw.Visibility,
+			WindSpeed:     hw.WindSpeed,
+			WindDeg:       hw.WindDeg,
+			WindGust:      hw.WindGust,
+			Precipitation: hw.Precipitation,
+			Clouds:        hw.Clouds,
+			WeatherMain:   hw.WeatherMain,
+			WeatherDesc:   hw.WeatherDesc,
+			WeatherIcon:   hw.WeatherIcon,
 		})
 	}
 
@@ -323,21 +328,22 @@ func (c *Controller) GetHourlyWeatherForHour(ctx echo.Context) error {
 
 		if storedHour == requestedHour {
 			response := HourlyWeatherResponse{
-				Time:        hw.Time.Format("15:04:05"),
-				Temperature: hw.Temperature,
-				FeelsLike:   hw.FeelsLike,
-				TempMin:     hw.TempMin,
-				TempMax:     hw.TempMax,
-				Pressure:    hw.Pressure,
-				Humidity:    hw.Humidity,
-				Visibility:  hw.Visibility,
-				WindSpeed:   hw.WindSpeed,
-				WindDeg:     hw.WindDeg,
-				WindGust:    hw.WindGust,
-				Clouds:      hw.Clouds,
-				WeatherMain: hw.WeatherMain,
-				WeatherDesc: hw.WeatherDesc,
-				WeatherIcon: hw.WeatherIcon,
+				Time:          hw.Time.Format("15:04:05"),
+				Temperature:   hw.Temperature,
+				FeelsLike:     hw.FeelsLike,
+				TempMin:       hw.TempMin,
+				TempMax:       hw.TempMax,
+				Pressure:      hw.Pressure,
+				Humidity:      hw.Humidity,
+				Visibility:    hw.Visibility,
+				WindSpeed:     hw.WindSpeed,
+				WindDeg:       hw.WindDeg,
+				WindGust:      hw.WindGust,
+				Precipitation: hw.Precipitation,
+				Clouds:        hw.Clouds,
+				WeatherMain:   hw.WeatherMain,
+				WeatherDesc:   hw.WeatherDesc,
+				WeatherIcon:   hw.WeatherIcon,
 			}
 			targetHourData = &response
 			break
@@ -543,21 +549,22 @@ func (c *Controller) findClosestHourlyWeather(detectionTime time.Time, hourlyWea
 // buildHourlyWeatherResponse creates an HourlyWeatherResponse from an HourlyWeather struct
 func (c *Controller) buildHourlyWeatherResponse(hw *datastore.HourlyWeather) HourlyWeatherResponse {
 	return HourlyWeatherResponse{
-		Time:        hw.Time.Format("15:04:05"), // Consider if Timezone matters here
-		Temperature: hw.Temperature,
-		FeelsLike:   hw.FeelsLike,
-		TempMin:     hw.TempMin,
-		TempMax:     hw.TempMax,
-		Pressure:    hw.Pressure,
-		Humidity:    hw.Humidity,
-		Visibility:  hw.Visibility,
-		WindSpeed:   hw.WindSpeed,
-		WindDeg:     hw.WindDeg,
-		WindGust:    hw.WindGust,
-		Clouds:      hw.Clouds,
-		WeatherMain: hw.WeatherMain,
-		WeatherDesc: hw.WeatherDesc,
-		WeatherIcon: hw.WeatherIcon,
+		Time:          hw.Time.Format("15:04:05"), // Consider if Timezone matters here
+		Temperature:   hw.Temperature,
+		FeelsLike:     hw.FeelsLike,
+		TempMin:       hw.TempMin,
+		TempMax:       hw.TempMax,
+		Pressure:      hw.Pressure,
+		Humidity:      hw.Humidity,
+		Visibility:    hw.Visibility,
+		WindSpeed:     hw.WindSpeed,
+		WindDeg:       hw.WindDeg,
+		WindGust:      hw.WindGust,
+		Precipitation: hw.Precipitation,
+		Clouds:        hw.Clouds,
+		WeatherMain:   hw.WeatherMain,
+		WeatherDesc:   hw.WeatherDesc,
+		WeatherIcon:   hw.WeatherIcon,
 	}
 }
 
@@ -597,21 +604,22 @@ func (c *Controller) GetLatestWeather(ctx echo.Context) error {
 		Daily: nil,
 		// Always include hourly data since we have it
 		Hourly: HourlyWeatherResponse{
-			Time:        latestWeather.Time.Format("15:04:05"),
-			Temperature: latestWeather.Temperature,
-			FeelsLike:   latestWeather.FeelsLike,
-			TempMin:     latestWeather.TempMin,
-			TempMax:     latestWeather.TempMax,
-			Pressure:    latestWeather.Pressure,
-			Humidity:    latestWeather.Humidity,
-			Visibility:  latestWeather.Visibility,
-			WindSpeed:   latestWeather.WindSpeed,
-			WindDeg:     latestWeather.WindDeg,
-			WindGust:    latestWeather.WindGust,
-			Clouds:      latestWeather.Clouds,
-			WeatherMain: latestWeather.WeatherMain,
-			WeatherDesc: latestWeather.WeatherDesc,
-			WeatherIcon: latestWeather.WeatherIcon,
+			Time:          latestWeather.Time.Format("15:04:05"),
+			Temperature:   latestWeather.Temperature,
+			FeelsLike:     latestWeather.FeelsLike,
+			TempMin:       latestWeather.TempMin,
+			TempMax:       latestWeather.TempMax,
+			Pressure:      latestWeather.Pressure,
+			Humidity:      latestWeather.Humidity,
+			Visibility:    latestWeather.Visibility,
+			WindSpeed:     latestWeather.WindSpeed,
+			WindDeg:       latestWeather.WindDeg,
+			WindGust:      latestWeather.WindGust,
+			Precipitation: latestWeather.Precipitation,
+			Clouds:        latestWeather.Clouds,
+			WeatherMain:   latestWeather.WeatherMain,
+			WeatherDesc:   latestWeather.WeatherDesc,
+			WeatherIcon:   latestWeather.WeatherIcon,
 		},
 		Time: time.Now().Format(time.RFC3339),
 	}
@@ -768,3 +776,44 @@ func (c *Controller) GetSunTimes(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, response)
 }
+
+// ErrWeatherAnalyticsNotAvailable is returned when the datastore cannot aggregate detections by weather
+var ErrWeatherAnalyticsNotAvailable = errors.New("weather analytics not available")
+
+// GetDetectionsByWeather handles GET /api/v2/weather/detections
+// Returns detection counts grouped by temperature, wind speed and precipitation for a date range.
+// Query parameters: start_date (required, YYYY-MM-DD), end_date (optional, defaults to start_date)
+func (c *Controller) GetDetectionsByWeather(ctx echo.Context) error {
+	weatherStore, ok := c.DS.(datastore.WeatherDetectionStore)
+	if !ok {
+		return c.HandleError(ctx, ErrWeatherAnalyticsNotAvailable, "Weather analytics unavailable", http.StatusServiceUnavailable)
+	}
+
+	startDate := ctx.QueryParam("start_date")
+	endDate := ctx.QueryParam("end_date")
+	if endDate == "" {
+		endDate = startDate
+	}
+	if !dateRegex.MatchString(startDate) || !dateRegex.MatchString(endDate) {
+		return c.HandleError(ctx, ErrInvalidStartDate, "Invalid date format. Use YYYY-MM-DD for start_date and end_date", http.StatusBadRequest)
+	}
+	if err := parseAndValidateDateRange(startDate, endDate); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Getting detections by weather",
+			"start_date", startDate,
+			"end_date", endDate,
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	summary, err := weatherStore.GetDetectionsByWeather(startDate, endDate)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get detections by weather", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, summary)
+}
@@ -718,3 +718,54 @@ func TestGetLatestWeather(t *testing.T) {
 	// Verify mock expectations
 	mockDS.AssertExpectations(t)
 }
+
+// mockWeatherDetectionStore adds datastore.WeatherDetectionStore to the mock datastore
+type mockWeatherDetectionStore struct {
+	*MockDataStore
+	startDate, endDate string
+}
+
+func (m *mockWeatherDetectionStore) NearestHourlyWeather(t time.Time, window time.Duration) (*datastore.HourlyWeather, error) {
+	return nil, nil
+}
+
+func (m *mockWeatherDetectionStore) GetDetectionsByWeather(startDate, endDate string) (*datastore.WeatherDetectionSummary, error) {
+	m.startDate, m.endDate = startDate, endDate
+	return &datastore.WeatherDetectionSummary{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Detections:  4,
+		Temperature: []datastore.WeatherDetectionBucket{{Label: "10..15 °C", Detections: 4, Species: 2}},
+	}, nil
+}
+
+// TestGetDetectionsByWeather tests detections grouped by weather conditions
+func TestGetDetectionsByWeather(t *testing.T) {
+	e, mockDS, controller := setupWeatherTestEnvironment(t)
+
+	// Plain datastore without weather analytics support
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/weather/detections?start_date=2024-06-01", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetDetectionsByWeather(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	store := &mockWeatherDetectionStore{MockDataStore: mockDS}
+	controller.DS = store
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/weather/detections?start_date=2024-06-01", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetDetectionsByWeather(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2024-06-01", store.endDate, "end date should default to start date")
+
+	var summary datastore.WeatherDetectionSummary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.Equal(t, int64(4), summary.Detections)
+	require.Len(t, summary.Temperature, 1)
+	assert.Equal(t, 2, summary.Temperature[0].Species)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/weather/detections?start_date=2024-06-05&end_date=2024-06-01", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetDetectionsByWeather(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
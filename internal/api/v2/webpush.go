@@ -0,0 +1,311 @@
+// internal/api/v2/webpush.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/webpush"
+)
+
+// ErrWebPushNotAvailable is returned when the datastore does not support push subscriptions
+var ErrWebPushNotAvailable = errors.NewStd("push subscriptions not available")
+
+// PushSubscriptionRequest is the PushSubscription of a browser, as returned by
+// PushSubscription.toJSON(), with the device name and species filter of the user
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+	Name    string   `json:"name"`
+	Species []string `json:"species"` // empty to receive every detection
+}
+
+// PushSubscriptionUpdate changes the device name and species filter of a subscription
+type PushSubscriptionUpdate struct {
+	Name    string   `json:"name"`
+	Species []string `json:"species"`
+}
+
+// PushSubscriptionInfo describes a push subscription. The keys of the browser are not returned.
+type PushSubscriptionInfo struct {
+	ID         uint       `json:"id"`
+	Endpoint   string     `json:"endpoint"`
+	Username   string     `json:"username,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Species    []string   `json:"species"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSentAt *time.Time `json:"lastSentAt,omitempty"`
+	Failures   int        `json:"failures"`
+}
+
+// initWebPushRoutes registers the Web Push endpoints
+func (c *Controller) initWebPushRoutes() {
+	pushGroup := c.Group.Group("/notifications/push", c.getEffectiveAuthMiddleware())
+	pushGroup.GET("/key", c.GetWebPushKey)
+	pushGroup.POST("/key", c.RegenerateWebPushKeys, auth.RequireAdmin)
+	pushGroup.GET("/subscriptions", c.GetPushSubscriptions)
+	pushGroup.POST("/subscriptions", c.SavePushSubscription)
+	pushGroup.PUT("/subscriptions/:id", c.UpdatePushSubscription)
+	pushGroup.DELETE("/subscriptions/:id", c.DeletePushSubscription)
+	pushGroup.POST("/subscriptions/:id/test", c.TestPushSubscription)
+}
+
+// GetWebPushKey handles GET /api/v2/notifications/push/key
+// Returns the VAPID public key browsers pass as applicationServerKey to pushManager.subscribe
+func (c *Controller) GetWebPushKey(ctx echo.Context) error {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"enabled":   c.Settings.WebPush.Enabled,
+		"publicKey": c.Settings.WebPush.PublicKey,
+	})
+}
+
+// RegenerateWebPushKeys handles POST /api/v2/notifications/push/key
+// Replaces the VAPID key pair. Push services reject messages signed with the new key for
+// existing subscriptions, so all subscriptions are removed and browsers must subscribe again.
+func (c *Controller) RegenerateWebPushKeys(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.WebPushStore)
+	if !ok {
+		return c.HandleError(ctx, ErrWebPushNotAvailable, "Push subscriptions unavailable", http.StatusServiceUnavailable)
+	}
+
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to generate VAPID keys", http.StatusInternalServerError)
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	oldSettings := *c.Settings
+	c.Settings.WebPush.PublicKey, c.Settings.WebPush.PrivateKey = publicKey, privateKey
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			*c.Settings = oldSettings
+			return c.HandleError(ctx, err, "Failed to save settings, rolled back to previous settings", http.StatusInternalServerError)
+		}
+	}
+	c.recordSettingsChanges(&oldSettings, c.Settings, settingsChangeActor(ctx), settingsChangeSourceAPI, ctx.RealIP())
+
+	removed, err := store.DeleteWebPushSubscriptions()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to remove push subscriptions", http.StatusInternalServerError)
+	}
+	if err := webpush.Configure(c.Settings, store); err != nil {
+		return c.HandleError(ctx, err, "Failed to apply VAPID keys", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("VAPID keys regenerated",
+			"removed_subscriptions", removed,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"publicKey":            publicKey,
+		"removedSubscriptions": removed,
+	})
+}
+
+// GetPushSubscriptions handles GET /api/v2/notifications/push/subscriptions
+// Admins see all subscriptions, other users their own
+func (c *Controller) GetPushSubscriptions(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.WebPushStore)
+	if !ok {
+		return c.HandleError(ctx, ErrWebPushNotAvailable, "Push subscriptions unavailable", http.StatusServiceUnavailable)
+	}
+
+	subscriptions, err := store.GetWebPushSubscriptions()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get push subscriptions", http.StatusInternalServerError)
+	}
+
+	result := make([]PushSubscriptionInfo, 0, len(subscriptions))
+	for i := range subscriptions {
+		if canManagePushSubscription(ctx, &subscriptions[i]) {
+			result = append(result, pushSubscriptionInfo(&subscriptions[i]))
+		}
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"subscriptions": result,
+	})
+}
+
+// SavePushSubscription handles POST /api/v2/notifications/push/subscriptions
+// Stores the subscription of a browser. A browser subscribing again updates its subscription.
+func (c *Controller) SavePushSubscription(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.WebPushStore)
+	if !ok {
+		return c.HandleError(ctx, ErrWebPushNotAvailable, "Push subscriptions unavailable", http.StatusServiceUnavailable)
+	}
+
+	var req PushSubscriptionRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+
+	username, _ := ctx.Get("username").(string)
+	sub := &datastore.WebPushSubscription{
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+		Username: username,
+		Name:     req.Name,
+	}
+	sub.SetSpeciesList(req.Species)
+	if err := store.SaveWebPushSubscription(sub); err != nil {
+		return c.handlePushSubscriptionError(ctx, err, "Failed to save push subscription")
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Push subscription saved",
+			"subscription_id", sub.ID,
+			"name", sub.Name,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.JSON(http.StatusCreated, pushSubscriptionInfo(sub))
+}
+
+// UpdatePushSubscription handles PUT /api/v2/notifications/push/subscriptions/:id
+func (c *Controller) UpdatePushSubscription(ctx echo.Context) error {
+	store, sub, err := c.getPushSubscription(ctx)
+	if err != nil || sub == nil {
+		return err
+	}
+
+	var req PushSubscriptionUpdate
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+
+	sub.Name = req.Name
+	sub.SetSpeciesList(req.Species)
+	if err := store.SaveWebPushSubscription(sub); err != nil {
+		return c.handlePushSubscriptionError(ctx, err, "Failed to save push subscription")
+	}
+
+	return ctx.JSON(http.StatusOK, pushSubscriptionInfo(sub))
+}
+
+// DeletePushSubscription handles DELETE /api/v2/notifications/push/subscriptions/:id
+func (c *Controller) DeletePushSubscription(ctx echo.Context) error {
+	store, sub, err := c.getPushSubscription(ctx)
+	if err != nil || sub == nil {
+		return err
+	}
+
+	if err := store.DeleteWebPushSubscription(sub.ID); err != nil {
+		return c.handlePushSubscriptionError(ctx, err, "Failed to delete push subscription")
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Push subscription deleted",
+			"subscription_id", sub.ID,
+			"name", sub.Name,
+			"actor", settingsChangeActor(ctx))
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// TestPushSubscription handles POST /api/v2/notifications/push/subscriptions/:id/test
+// Sends a test notification to the subscription
+func (c *Controller) TestPushSubscription(ctx echo.Context) error {
+	store, sub, err := c.getPushSubscription(ctx)
+	if err != nil || sub == nil {
+		return err
+	}
+
+	sender := webpush.GetSender()
+	if sender == nil {
+		return c.HandleError(ctx, webpush.ErrNotEnabled, "Web push notifications are not enabled", http.StatusServiceUnavailable)
+	}
+
+	if err := sender.SendTest(ctx.Request().Context(), sub); err != nil {
+		if errors.Is(err, webpush.ErrSubscriptionGone) {
+			if err := store.DeleteWebPushSubscription(sub.ID); err != nil {
+				return c.HandleError(ctx, err, "Failed to remove expired push subscription", http.StatusInternalServerError)
+			}
+			return c.HandleError(ctx, err, "Push subscription expired and was removed", http.StatusGone)
+		}
+		return c.HandleError(ctx, err, "Failed to send test notification", http.StatusBadGateway)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"message": "Test notification sent",
+	})
+}
+
+// getPushSubscription returns the subscription of the id parameter. When the subscription
+// cannot be returned the error response is written and a nil subscription is returned.
+func (c *Controller) getPushSubscription(ctx echo.Context) (datastore.WebPushStore, *datastore.WebPushSubscription, error) {
+	store, ok := c.DS.(datastore.WebPushStore)
+	if !ok {
+		return nil, nil, c.HandleError(ctx, ErrWebPushNotAvailable, "Push subscriptions unavailable", http.StatusServiceUnavailable)
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil, c.HandleError(ctx, err, "Invalid subscription ID", http.StatusBadRequest)
+	}
+
+	sub, err := store.GetWebPushSubscription(uint(id))
+	if err != nil {
+		return nil, nil, c.handlePushSubscriptionError(ctx, err, "Failed to get push subscription")
+	}
+	// Subscriptions of other users are reported as missing
+	if !canManagePushSubscription(ctx, sub) {
+		return nil, nil, c.HandleError(ctx, errors.NewStd("push subscription not found"), "Push subscription not found", http.StatusNotFound)
+	}
+	return store, sub, nil
+}
+
+// handlePushSubscriptionError writes the error response of a failed datastore operation
+func (c *Controller) handlePushSubscriptionError(ctx echo.Context, err error, message string) error {
+	var enhanced *errors.EnhancedError
+	if errors.As(err, &enhanced) {
+		switch enhanced.Category {
+		case errors.CategoryValidation:
+			return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+		case errors.CategoryNotFound:
+			return c.HandleError(ctx, err, "Push subscription not found", http.StatusNotFound)
+		}
+	}
+	return c.HandleError(ctx, err, message, http.StatusInternalServerError)
+}
+
+// canManagePushSubscription reports whether the client may see and change a subscription:
+// admins manage every subscription, other users their own
+func canManagePushSubscription(ctx echo.Context, sub *datastore.WebPushSubscription) bool {
+	if auth.RoleFromContext(ctx) == conf.RoleAdmin {
+		return true
+	}
+	username, _ := ctx.Get("username").(string)
+	return username != "" && username == sub.Username
+}
+
+// pushSubscriptionInfo converts a stored push subscription to its API representation
+func pushSubscriptionInfo(sub *datastore.WebPushSubscription) PushSubscriptionInfo {
+	return PushSubscriptionInfo{
+		ID:         sub.ID,
+		Endpoint:   sub.Endpoint,
+		Username:   sub.Username,
+		Name:       sub.Name,
+		Species:    append([]string{}, sub.SpeciesList()...),
+		CreatedAt:  sub.CreatedAt,
+		LastSentAt: sub.LastSentAt,
+		Failures:   sub.Failures,
+	}
+}
@@ -0,0 +1,171 @@
+// webpush_test.go: tests for the Web Push endpoints
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// mockWebPushStore adds the optional push subscription capability to MockDataStore
+type mockWebPushStore struct {
+	*MockDataStore
+	subscriptions map[uint]datastore.WebPushSubscription
+	nextID        uint
+}
+
+func (m *mockWebPushStore) GetWebPushSubscriptions() ([]datastore.WebPushSubscription, error) {
+	subscriptions := make([]datastore.WebPushSubscription, 0, len(m.subscriptions))
+	for id := uint(1); id <= m.nextID; id++ {
+		if sub, ok := m.subscriptions[id]; ok {
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (m *mockWebPushStore) GetWebPushSubscription(id uint) (*datastore.WebPushSubscription, error) {
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return nil, errors.Newf("web push subscription not found").Category(errors.CategoryNotFound).Build()
+	}
+	return &sub, nil
+}
+
+func (m *mockWebPushStore) SaveWebPushSubscription(sub *datastore.WebPushSubscription) error {
+	if !strings.HasPrefix(sub.Endpoint, "https://") {
+		return errors.Newf("endpoint must be an https URL").Category(errors.CategoryValidation).Build()
+	}
+	if sub.ID == 0 {
+		m.nextID++
+		sub.ID = m.nextID
+	}
+	m.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+func (m *mockWebPushStore) DeleteWebPushSubscription(id uint) error {
+	if _, ok := m.subscriptions[id]; !ok {
+		return errors.Newf("web push subscription not found").Category(errors.CategoryNotFound).Build()
+	}
+	delete(m.subscriptions, id)
+	return nil
+}
+
+func (m *mockWebPushStore) DeleteWebPushSubscriptions() (int64, error) {
+	count := int64(len(m.subscriptions))
+	clear(m.subscriptions)
+	return count, nil
+}
+
+func (m *mockWebPushStore) RecordWebPushDelivery(uint, bool) error {
+	return nil
+}
+
+// pushRequest calls a push handler as a user with a role, with the id parameter if not empty
+func pushRequest(t *testing.T, e *echo.Echo, handler echo.HandlerFunc, method, username, role, id, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/v2/notifications/push/subscriptions", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("username", username)
+	ctx.Set("role", role)
+	if id != "" {
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+	}
+	require.NoError(t, handler(ctx))
+	return rec
+}
+
+func TestWebPushEndpoints(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+
+	// Plain datastore without push subscription support
+	rec := pushRequest(t, e, controller.GetPushSubscriptions, http.MethodGet, "alice", conf.RoleViewer, "", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	store := &mockWebPushStore{MockDataStore: mockDS, subscriptions: make(map[uint]datastore.WebPushSubscription)}
+	controller.DS = store
+
+	rec = pushRequest(t, e, controller.SavePushSubscription, http.MethodPost, "alice", conf.RoleViewer, "",
+		`{"endpoint":"http://push.example.org/1","keys":{"p256dh":"a","auth":"b"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = pushRequest(t, e, controller.SavePushSubscription, http.MethodPost, "alice", conf.RoleViewer, "",
+		`{"endpoint":"https://push.example.org/1","keys":{"p256dh":"a","auth":"b"},"name":"Phone","species":["Strix aluco"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created PushSubscriptionInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "alice", created.Username)
+	assert.Equal(t, []string{"Strix aluco"}, created.Species)
+	assert.NotContains(t, rec.Body.String(), "p256dh", "browser keys are not returned")
+	id := strconv.FormatUint(uint64(created.ID), 10)
+
+	listed := func(username, role string) []PushSubscriptionInfo {
+		rec := pushRequest(t, e, controller.GetPushSubscriptions, http.MethodGet, username, role, "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var list struct {
+			Subscriptions []PushSubscriptionInfo `json:"subscriptions"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		return list.Subscriptions
+	}
+	assert.Len(t, listed("alice", conf.RoleViewer), 1)
+	assert.Empty(t, listed("bob", conf.RoleViewer), "users only see their own subscriptions")
+	assert.Len(t, listed("admin", conf.RoleAdmin), 1)
+
+	rec = pushRequest(t, e, controller.UpdatePushSubscription, http.MethodPut, "bob", conf.RoleViewer, id, `{"name":"Hijacked"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = pushRequest(t, e, controller.UpdatePushSubscription, http.MethodPut, "alice", conf.RoleViewer, id, `{"name":"Pixel","species":[]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Pixel", store.subscriptions[created.ID].Name)
+	assert.Empty(t, store.subscriptions[created.ID].Species)
+
+	rec = pushRequest(t, e, controller.TestPushSubscription, http.MethodPost, "alice", conf.RoleViewer, id, "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "push notifications are not enabled")
+
+	rec = pushRequest(t, e, controller.DeletePushSubscription, http.MethodDelete, "alice", conf.RoleViewer, "abc", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = pushRequest(t, e, controller.DeletePushSubscription, http.MethodDelete, "admin", conf.RoleAdmin, id, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = pushRequest(t, e, controller.DeletePushSubscription, http.MethodDelete, "admin", conf.RoleAdmin, id, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRegenerateWebPushKeys(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+	store := &mockWebPushStore{MockDataStore: mockDS, subscriptions: make(map[uint]datastore.WebPushSubscription)}
+	controller.DS = store
+	require.NoError(t, store.SaveWebPushSubscription(&datastore.WebPushSubscription{Endpoint: "https://push.example.org/1"}))
+
+	rec := pushRequest(t, e, controller.RegenerateWebPushKeys, http.MethodPost, "admin", conf.RoleAdmin, "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result struct {
+		PublicKey            string `json:"publicKey"`
+		RemovedSubscriptions int64  `json:"removedSubscriptions"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, controller.Settings.WebPush.PublicKey, result.PublicKey)
+	assert.NotEmpty(t, controller.Settings.WebPush.PrivateKey)
+	assert.Equal(t, int64(1), result.RemovedSubscriptions)
+	assert.Empty(t, store.subscriptions, "subscriptions of the old key are removed")
+
+	rec = pushRequest(t, e, controller.GetWebPushKey, http.MethodGet, "bob", conf.RoleViewer, "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), result.PublicKey)
+}
@@ -0,0 +1,455 @@
+// Package backfill analyzes directories of historical recordings, such as the SD cards of
+// AudioMoth recorders, and saves the detections into the datastore with the date and time
+// they were recorded. Finished recordings are kept in a state file, so an interrupted
+// backfill resumes with the first unfinished recording.
+package backfill
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/observation"
+)
+
+// Extensions are the audio file extensions a backfill analyzes. MP3 files are decoded with
+// FFmpeg.
+var Extensions = []string{".wav", ".flac", ".mp3"}
+
+// Analyzer predicts the species of analysis windows. *birdnet.BirdNET implements Analyzer.
+type Analyzer interface {
+	ProcessChunk(chunk []float32, predStart time.Time) ([]datastore.Note, error)
+	GetProbableSpecies(date time.Time, week float32) ([]birdnet.SpeciesScore, error)
+}
+
+// Store is the datastore the detections are saved into. *datastore.DataStore and the stores
+// embedding it implement Store.
+type Store interface {
+	GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error)
+	Save(note *datastore.Note, results []datastore.Results) error
+}
+
+// Options controls a backfill
+type Options struct {
+	StatePath string         // resume state file, StateFileName in the directory when empty
+	Location  *time.Location // time zone of file name times, local time when nil
+	Restart   bool           // analyze recordings finished by earlier runs again
+	Progress  func(Progress) // called after each recording, may be nil
+}
+
+// Recording is an audio file found by a backfill
+type Recording struct {
+	Path       string    // path of the file
+	Name       string    // path relative to the backfilled directory
+	Size       int64     // file size in bytes
+	ModTime    time.Time // file modification time
+	Start      time.Time // recording start, zero when unknown
+	TimeSource string    // where the start was read from, TimeSourceMetadata or TimeSourceFileName
+}
+
+// Progress reports a recording a backfill is done with
+type Progress struct {
+	Index      int           // position of the recording, starting at 1
+	Total      int           // number of recordings
+	Recording  *Recording    // the recording
+	Detections int           // detections saved from the recording
+	Duplicates int           // detections already in the datastore
+	Skipped    bool          // recording finished by an earlier run or without a start time
+	Err        error         // error analyzing the recording
+	Remaining  time.Duration // estimated time left, zero until a recording was analyzed
+}
+
+// Summary reports the outcome of a backfill
+type Summary struct {
+	Recordings  int           // recordings found
+	Analyzed    int           // recordings analyzed by this run
+	Resumed     int           // recordings finished by earlier runs
+	NoStartTime int           // recordings skipped because their start time is unknown
+	Failed      int           // recordings that could not be analyzed
+	Detections  int           // detections saved
+	Duplicates  int           // detections already in the datastore
+	AudioLength time.Duration // length of the analyzed audio
+}
+
+// FindRecordings returns the audio files under root, sorted by path, with their start times.
+// File name times are read in loc.
+func FindRecordings(root string, loc *time.Location) ([]Recording, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var recordings []Recording
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip hidden directories such as .Trashes on SD cards
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || !slices.Contains(Extensions, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rec := Recording{
+			Path:    path,
+			Name:    filepath.ToSlash(name),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		rec.Start, rec.TimeSource = RecordingStart(path, loc)
+		recordings = append(recordings, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backfill").
+			Category(errors.CategoryFileIO).
+			Context("directory", root).
+			Build()
+	}
+	return recordings, nil
+}
+
+// chunkResult is a detection with the predictions of its analysis window
+type chunkResult struct {
+	note    datastore.Note
+	results []datastore.Results
+}
+
+// backfiller analyzes recordings and saves their detections
+type backfiller struct {
+	settings *conf.Settings
+	analyzer Analyzer
+	store    Store
+	criteria datastore.RescoreCriteria
+	species  map[string]map[string]bool // date -> scientific names passing the range filter
+	seen     map[string]map[string]bool // date -> detection keys in the datastore
+	decode   func(ctx context.Context, input, output string) error
+}
+
+// Run analyzes the recordings under root and saves their detections into store. Recordings
+// without a start time are skipped, recordings finished by an earlier run are skipped unless
+// opts.Restart is set. Detections get the date and time they were recorded; they are
+// filtered by the thresholds, exclude list and range filter of the settings for the date of
+// the recording, and detections of a species within the detection interval are merged into
+// the most confident one, as realtime analysis does.
+func Run(ctx context.Context, settings *conf.Settings, root string, analyzer Analyzer, store Store, opts *Options) (Summary, error) {
+	statePath := opts.StatePath
+	if statePath == "" {
+		statePath = filepath.Join(root, StateFileName)
+	}
+	st := &state{Files: make(map[string]fileState)}
+	if !opts.Restart {
+		var err error
+		if st, err = loadState(statePath); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	recordings, err := FindRecordings(root, opts.Location)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	b := &backfiller{
+		settings: settings,
+		analyzer: analyzer,
+		store:    store,
+		criteria: datastore.RescoreCriteriaFromSettings(settings),
+		species:  make(map[string]map[string]bool),
+		seen:     make(map[string]map[string]bool),
+		decode: func(ctx context.Context, input, output string) error {
+			return myaudio.DecodeAudioFile(ctx, settings.Realtime.Audio.FfmpegPath, input, output)
+		},
+	}
+
+	summary := Summary{Recordings: len(recordings)}
+	started := time.Now()
+	var analyzedBytes, remainingBytes int64
+	for i := range recordings {
+		if !st.finished(&recordings[i]) {
+			remainingBytes += recordings[i].Size
+		}
+	}
+
+	for i := range recordings {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		rec := &recordings[i]
+		progress := Progress{Index: i + 1, Total: len(recordings), Recording: rec}
+		switch {
+		case st.finished(rec):
+			summary.Resumed++
+			progress.Skipped = true
+		case rec.Start.IsZero():
+			summary.NoStartTime++
+			remainingBytes -= rec.Size
+			progress.Skipped = true
+		default:
+			saved, duplicates, length, err := b.backfillRecording(ctx, rec)
+			remainingBytes -= rec.Size
+			progress.Detections, progress.Duplicates = saved, duplicates
+			summary.Detections += saved
+			summary.Duplicates += duplicates
+			if err != nil {
+				if ctx.Err() != nil {
+					return summary, ctx.Err()
+				}
+				summary.Failed++
+				progress.Err = err
+				break
+			}
+			summary.Analyzed++
+			summary.AudioLength += length
+			analyzedBytes += rec.Size
+			if err := st.markFinished(statePath, rec, saved); err != nil {
+				return summary, err
+			}
+		}
+
+		// Estimate the time left from the analysis speed so far, by file size
+		if analyzedBytes > 0 {
+			progress.Remaining = time.Duration(float64(time.Since(started)) * float64(remainingBytes) / float64(analyzedBytes))
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	return summary, nil
+}
+
+// backfillRecording analyzes a recording and saves its detections. It returns the number
+// of saved and duplicate detections and the length of the recording.
+func (b *backfiller) backfillRecording(ctx context.Context, rec *Recording) (saved, duplicates int, length time.Duration, err error) {
+	detections, length, err := b.analyze(ctx, rec)
+	if err != nil {
+		return 0, 0, length, err
+	}
+
+	for _, d := range mergeDetections(detections, b.interval) {
+		isNew, err := b.save(&d)
+		if err != nil {
+			return saved, duplicates, length, err
+		}
+		if isNew {
+			saved++
+		} else {
+			duplicates++
+		}
+	}
+	return saved, duplicates, length, nil
+}
+
+// analyze runs the analysis windows of a recording through the analyzer and returns the
+// detections passing the filters, in the order of their start times
+func (b *backfiller) analyze(ctx context.Context, rec *Recording) ([]chunkResult, time.Duration, error) {
+	input := rec.Path
+	if strings.EqualFold(filepath.Ext(rec.Path), ".mp3") {
+		tmpDir, err := os.MkdirTemp("", "birdnet-go-backfill-")
+		if err != nil {
+			return nil, 0, analysisError(err, rec)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		input = filepath.Join(tmpDir, "decoded.wav")
+		if err := b.decode(ctx, rec.Path, input); err != nil {
+			return nil, 0, analysisError(err, rec)
+		}
+	}
+
+	hop := time.Duration(conf.AnalysisHopSamples(b.settings.BirdNET.Overlap, conf.SampleRate)) * time.Second / conf.SampleRate
+	var detections []chunkResult
+	var offset time.Duration
+
+	b.settings.Input.Path = input
+	err := myaudio.ReadAudioFileBuffered(b.settings, func(chunk []float32, _ bool) error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		begin := rec.Start.Add(offset)
+		offset += hop
+		notes, err := b.analyzer.ProcessChunk(chunk, begin)
+		if err != nil {
+			return err
+		}
+
+		results := make([]datastore.Results, 0, len(notes))
+		for i := range notes {
+			results = append(results, datastore.Results{
+				Species:    notes[i].ScientificName + "_" + notes[i].CommonName,
+				Confidence: float32(notes[i].Confidence),
+			})
+		}
+		for i := range notes {
+			note := notes[i]
+			included, err := b.includes(&note, begin)
+			if err != nil {
+				return err
+			}
+			if !included {
+				continue
+			}
+			b.setRecordingTime(&note, rec, begin)
+			detections = append(detections, chunkResult{note: note, results: slices.Clone(results)})
+		}
+		return nil
+	})
+	length := offset
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, length, ctxErr
+		}
+		return nil, length, analysisError(err, rec)
+	}
+	return detections, length, nil
+}
+
+// includes reports whether a detection passes the thresholds, the exclude list and the range
+// filter of the date it was recorded
+func (b *backfiller) includes(note *datastore.Note, begin time.Time) (bool, error) {
+	if b.criteria.Evaluate(note) != "" {
+		return false, nil
+	}
+
+	date := begin.In(time.Local).Format(time.DateOnly)
+	species, ok := b.species[date]
+	if !ok {
+		day, _ := time.ParseInLocation(time.DateOnly, date, time.Local)
+		scores, err := b.analyzer.GetProbableSpecies(day, 0)
+		if err != nil {
+			return false, err
+		}
+		species = make(map[string]bool, len(scores))
+		for _, score := range scores {
+			scientificName, _, _ := strings.Cut(score.Label, "_")
+			species[strings.ToLower(scientificName)] = true
+		}
+		b.species[date] = species
+	}
+	return species[strings.ToLower(note.ScientificName)], nil
+}
+
+// setRecordingTime replaces the analysis time of a detection with the time it was recorded
+// and the recording it was found in
+func (b *backfiller) setRecordingTime(note *datastore.Note, rec *Recording, begin time.Time) {
+	local := observation.LocalTime(b.settings, begin)
+	note.Date = local.Format(time.DateOnly)
+	note.Time = local.Format(time.TimeOnly)
+	note.BeginTime = begin
+	note.EndTime = begin.Add(conf.AnalysisWindow)
+	note.Source = datastore.AudioSource{
+		SafeString:  rec.Path,
+		DisplayName: filepath.Base(rec.Path),
+	}
+}
+
+// interval returns the detection interval of a species, within which detections are merged
+func (b *backfiller) interval(note *datastore.Note) time.Duration {
+	for name, config := range b.settings.Realtime.Species.Config {
+		name = strings.TrimSpace(name)
+		if config.Interval > 0 && (strings.EqualFold(name, note.CommonName) || strings.EqualFold(name, note.ScientificName)) {
+			return time.Duration(config.Interval) * time.Second
+		}
+	}
+	return time.Duration(b.settings.Realtime.Interval) * time.Second
+}
+
+// mergeDetections keeps the most confident detection of each group of detections of a
+// species starting within the interval of the first detection of the group
+func mergeDetections(detections []chunkResult, interval func(*datastore.Note) time.Duration) []chunkResult {
+	merged := make([]chunkResult, 0, len(detections))
+	groups := make(map[string]int) // lowercase scientific name -> index of the open group in merged
+	starts := make(map[string]time.Time)
+
+	for _, d := range detections {
+		key := strings.ToLower(d.note.ScientificName)
+		if i, ok := groups[key]; ok && d.note.BeginTime.Sub(starts[key]) < interval(&d.note) {
+			if d.note.Confidence > merged[i].note.Confidence {
+				merged[i] = d
+			}
+			continue
+		}
+		groups[key] = len(merged)
+		starts[key] = d.note.BeginTime
+		merged = append(merged, d)
+	}
+	return merged
+}
+
+// detectionKey identifies a detection by its time to the second and species
+func detectionKey(clock, scientificName string) string {
+	return clock + "|" + strings.ToLower(scientificName)
+}
+
+// save saves a detection unless the datastore has one of the species at the same time, and
+// reports whether it was saved
+func (b *backfiller) save(d *chunkResult) (bool, error) {
+	keys, ok := b.seen[d.note.Date]
+	if !ok {
+		notes, err := b.store.GetNotesByDate(d.note.Date, false)
+		if err != nil {
+			return false, databaseError(err, d.note.Date)
+		}
+		keys = make(map[string]bool, len(notes))
+		for i := range notes {
+			keys[detectionKey(notes[i].Time, notes[i].ScientificName)] = true
+		}
+		b.seen[d.note.Date] = keys
+	}
+
+	key := detectionKey(d.note.Time, d.note.ScientificName)
+	if keys[key] {
+		return false, nil
+	}
+	if err := b.store.Save(&d.note, d.results); err != nil {
+		return false, databaseError(err, d.note.Date)
+	}
+	keys[key] = true
+	return true, nil
+}
+
+// analysisError reports a recording that could not be analyzed
+func analysisError(err error, rec *Recording) error {
+	return errors.New(err).
+		Component("backfill").
+		Category(errors.CategoryAudio).
+		Context("recording", rec.Name).
+		Build()
+}
+
+// databaseError reports a failure reading or writing the detections of a date
+func databaseError(err error, date string) error {
+	return errors.New(err).
+		Component("backfill").
+		Category(errors.CategoryDatabase).
+		Context("date", date).
+		Build()
+}
@@ -0,0 +1,215 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// mockStore keeps saved notes in memory
+type mockStore struct {
+	notes []datastore.Note
+}
+
+func (m *mockStore) GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error) {
+	var notes []datastore.Note
+	for i := range m.notes {
+		if m.notes[i].Date == date {
+			notes = append(notes, m.notes[i])
+		}
+	}
+	return notes, nil
+}
+
+func (m *mockStore) Save(note *datastore.Note, results []datastore.Results) error {
+	note.Results = results
+	m.notes = append(m.notes, *note)
+	return nil
+}
+
+// mockAnalyzer detects a blackbird in every window, most confidently on the quarter minute,
+// and a robin and a low confidence owl in the first window of each minute
+type mockAnalyzer struct {
+	chunks int
+}
+
+func (m *mockAnalyzer) ProcessChunk(chunk []float32, predStart time.Time) ([]datastore.Note, error) {
+	m.chunks++
+	confidence := 0.8
+	if predStart.Second()%15 == 0 {
+		confidence = 0.95
+	}
+	notes := []datastore.Note{{
+		ScientificName: "Turdus merula",
+		CommonName:     "Eurasian Blackbird",
+		Confidence:     confidence,
+		BeginTime:      predStart,
+		Date:           time.Now().Format(time.DateOnly),
+	}}
+	if predStart.Second() == 0 {
+		notes = append(notes,
+			datastore.Note{ScientificName: "Erithacus rubecula", CommonName: "European Robin", Confidence: 0.9, BeginTime: predStart},
+			datastore.Note{ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.3, BeginTime: predStart})
+	}
+	return notes, nil
+}
+
+func (m *mockAnalyzer) GetProbableSpecies(date time.Time, week float32) ([]birdnet.SpeciesScore, error) {
+	return []birdnet.SpeciesScore{
+		{Label: "Turdus merula_Eurasian Blackbird", Score: 0.9},
+		{Label: "Strix aluco_Tawny Owl", Score: 0.5},
+	}, nil
+}
+
+// writeWAV writes seconds of 16-bit mono silence at 48 kHz with optional LIST and GUANO chunks
+func writeWAV(t *testing.T, path string, seconds int, comment, guano string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	chunk := func(id string, data []byte) {
+		body.WriteString(id)
+		_ = binary.Write(&body, binary.LittleEndian, uint32(len(data)))
+		body.Write(data)
+		if len(data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	var format bytes.Buffer
+	for _, v := range []any{uint16(1), uint16(1), uint32(conf.SampleRate), uint32(conf.SampleRate * 2), uint16(2), uint16(16)} {
+		_ = binary.Write(&format, binary.LittleEndian, v)
+	}
+	body.WriteString("WAVE")
+	chunk("fmt ", format.Bytes())
+	if comment != "" {
+		var list bytes.Buffer
+		list.WriteString("INFO")
+		list.WriteString("ICMT")
+		_ = binary.Write(&list, binary.LittleEndian, uint32(len(comment)+1))
+		list.WriteString(comment + "\x00")
+		if (len(comment)+1)%2 == 1 {
+			list.WriteByte(0)
+		}
+		chunk("LIST", list.Bytes())
+	}
+	chunk("data", make([]byte, seconds*conf.SampleRate*2))
+	if guano != "" {
+		chunk("guan", []byte(guano))
+	}
+
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	_ = binary.Write(&file, binary.LittleEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o644))
+}
+
+func TestFileNameTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want time.Time
+	}{
+		{"20250510_061500.WAV", time.Date(2025, 5, 10, 6, 15, 0, 0, time.UTC)},
+		{"SM4_20250510T061500.wav", time.Date(2025, 5, 10, 6, 15, 0, 0, time.UTC)},
+		{"2025-05-10_06-15-00.flac", time.Date(2025, 5, 10, 6, 15, 0, 0, time.UTC)},
+		{"garden 2025-05-10 06:15:00.mp3", time.Date(2025, 5, 10, 6, 15, 0, 0, time.UTC)},
+		{"recording.wav", time.Time{}},
+		{"12345678_123456.wav", time.Time{}},
+	}
+	for _, tt := range tests {
+		assert.True(t, tt.want.Equal(fileNameTime(tt.name, time.UTC)), tt.name)
+	}
+}
+
+func TestWAVMetadataTime(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	audioMoth := filepath.Join(dir, "audiomoth.wav")
+	writeWAV(t, audioMoth, 1, "Recorded at 21:00:00 24/02/2019 (UTC+1) by AudioMoth 24A04F085A0E1F2B at medium gain.", "")
+	start, source := RecordingStart(audioMoth, time.Local)
+	assert.Equal(t, TimeSourceMetadata, source)
+	assert.True(t, time.Date(2019, 2, 24, 20, 0, 0, 0, time.UTC).Equal(start), start)
+
+	// GUANO metadata follows the audio data, and wins over the file name
+	guano := filepath.Join(dir, "20200101_000000.wav")
+	writeWAV(t, guano, 1, "", "GUANO|Version: 1.0\nMake: Wildlife Acoustics\nTimestamp: 2021-06-01T04:30:00-05:00\n")
+	start, source = RecordingStart(guano, time.Local)
+	assert.Equal(t, TimeSourceMetadata, source)
+	assert.True(t, time.Date(2021, 6, 1, 9, 30, 0, 0, time.UTC).Equal(start), start)
+
+	plain := filepath.Join(dir, "20200101_000000_plain.wav")
+	writeWAV(t, plain, 1, "", "")
+	start, source = RecordingStart(plain, time.UTC)
+	assert.Equal(t, TimeSourceFileName, source)
+	assert.True(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Equal(start), start)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeWAV(t, filepath.Join(dir, "card1", "20250510_061500.WAV"), 30, "", "")
+	writeWAV(t, filepath.Join(dir, "card1", "20250511_061500.WAV"), 6, "", "")
+	writeWAV(t, filepath.Join(dir, "card2", "notes.wav"), 3, "", "")
+	writeWAV(t, filepath.Join(dir, ".Trashes", "20250512_061500.WAV"), 3, "", "")
+
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.7
+	settings.Realtime.Interval = 15
+
+	store := &mockStore{}
+	var progress []Progress
+	opts := &Options{Location: time.UTC, Progress: func(p Progress) { progress = append(progress, p) }}
+
+	summary, err := Run(context.Background(), settings, dir, &mockAnalyzer{}, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Recordings)
+	assert.Equal(t, 2, summary.Analyzed)
+	assert.Equal(t, 1, summary.NoStartTime)
+	assert.Equal(t, 36*time.Second, summary.AudioLength)
+	require.Len(t, progress, 3)
+	assert.Equal(t, "card1/20250510_061500.WAV", progress[0].Recording.Name)
+
+	// 30 seconds of blackbirds are two detections 15 seconds apart; the robin is outside the
+	// range filter and the owl below the threshold
+	require.Len(t, store.notes, 3)
+	first := store.notes[0].BeginTime.In(time.Local)
+	assert.Equal(t, first.Format(time.DateOnly), store.notes[0].Date)
+	assert.Equal(t, first.Format(time.TimeOnly), store.notes[0].Time)
+	assert.True(t, time.Date(2025, 5, 10, 6, 15, 0, 0, time.UTC).Equal(store.notes[0].BeginTime))
+	assert.True(t, time.Date(2025, 5, 10, 6, 15, 15, 0, time.UTC).Equal(store.notes[1].BeginTime))
+	assert.True(t, time.Date(2025, 5, 11, 6, 15, 0, 0, time.UTC).Equal(store.notes[2].BeginTime))
+	for i := range store.notes {
+		assert.Equal(t, "Turdus merula", store.notes[i].ScientificName)
+		assert.NotEmpty(t, store.notes[i].Results)
+	}
+	assert.Equal(t, "20250510_061500.WAV", store.notes[0].Source.DisplayName)
+
+	// A second run resumes: finished recordings are skipped
+	analyzer := &mockAnalyzer{}
+	summary, err = Run(context.Background(), settings, dir, analyzer, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Resumed)
+	assert.Zero(t, analyzer.chunks)
+
+	// A restart analyzes everything again without saving detections twice
+	opts.Restart = true
+	summary, err = Run(context.Background(), settings, dir, &mockAnalyzer{}, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Analyzed)
+	assert.Equal(t, 3, summary.Duplicates)
+	assert.Len(t, store.notes, 3)
+}
@@ -0,0 +1,96 @@
+// state.go: resume state of a backfill
+package backfill
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// StateFileName is the name of the state file written to the root of the backfilled
+// directory unless another path is given
+const StateFileName = ".birdnet-go-backfill.json"
+
+// state records the recordings a backfill has finished, so an interrupted backfill resumes
+// with the first unfinished recording
+type state struct {
+	Files map[string]fileState `json:"files"` // by path relative to the backfilled directory
+}
+
+// fileState identifies a finished recording by its size and modification time, so a
+// replaced recording is analyzed again
+type fileState struct {
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"modTime"`
+	Detections int       `json:"detections"`
+	Finished   time.Time `json:"finished"`
+}
+
+// loadState reads the state file, an empty state when it does not exist yet
+func loadState(path string) (*state, error) {
+	s := &state{Files: make(map[string]fileState)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, stateError(err, path)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, stateError(err, path)
+	}
+	if s.Files == nil {
+		s.Files = make(map[string]fileState)
+	}
+	return s, nil
+}
+
+// finished reports whether a recording was finished by an earlier run and has not changed
+func (s *state) finished(rec *Recording) bool {
+	f, ok := s.Files[rec.Name]
+	return ok && f.Size == rec.Size && f.ModTime.Equal(rec.ModTime)
+}
+
+// markFinished records a finished recording and writes the state file
+func (s *state) markFinished(path string, rec *Recording, detections int) error {
+	s.Files[rec.Name] = fileState{
+		Size:       rec.Size,
+		ModTime:    rec.ModTime,
+		Detections: detections,
+		Finished:   time.Now(),
+	}
+	return s.save(path)
+}
+
+// save writes the state to a temporary file and renames it, so an interruption never leaves
+// a truncated state file behind
+func (s *state) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return stateError(err, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return stateError(err, path)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return stateError(err, path)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return stateError(err, path)
+	}
+	return nil
+}
+
+// stateError reports a state file that cannot be read or written
+func stateError(err error, path string) error {
+	return errors.New(err).
+		Component("backfill").
+		Category(errors.CategoryFileIO).
+		Context("state_file", path).
+		Build()
+}
@@ -0,0 +1,169 @@
+// timestamp.go: recording start times from file names and WAV metadata
+package backfill
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Sources of recording start times
+const (
+	TimeSourceMetadata = "metadata"  // AudioMoth comment or GUANO timestamp of a WAV file
+	TimeSourceFileName = "file name" // date and time in the file name
+)
+
+var (
+	// compactTimePattern matches file names such as 20250510_061500.WAV written by AudioMoth
+	// and most field recorders
+	compactTimePattern = regexp.MustCompile(`(\d{8})[_T-]?(\d{6})`)
+
+	// isoTimePattern matches file names such as 2025-05-10_06-15-00.flac
+	isoTimePattern = regexp.MustCompile(`(\d{4}-\d{2}-\d{2})[ _T-]+(\d{2})[-:.]?(\d{2})[-:.]?(\d{2})`)
+
+	// audioMothCommentPattern matches the comment AudioMoth writes into its WAV files, e.g.
+	// "Recorded at 21:00:00 24/02/2019 (UTC+1) by AudioMoth 24A04F085A0E1F2B ..."
+	audioMothCommentPattern = regexp.MustCompile(`Recorded at (\d{2}:\d{2}:\d{2}) (\d{2}/\d{2}/\d{4}) \(UTC(?:([+-])(\d{1,2})(?::(\d{2}))?)?\)`)
+)
+
+// maxMetadataChunk limits the size of WAV metadata chunks read for timestamps
+const maxMetadataChunk = 64 * 1024
+
+// RecordingStart returns the start time of a recording and where it was read from, or the
+// zero time when neither the metadata nor the file name has one. Metadata carries its time
+// zone and is preferred; file name times are read in loc.
+func RecordingStart(path string, loc *time.Location) (start time.Time, source string) {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		if t := wavMetadataTime(path); !t.IsZero() {
+			return t, TimeSourceMetadata
+		}
+	}
+	if t := fileNameTime(filepath.Base(path), loc); !t.IsZero() {
+		return t, TimeSourceFileName
+	}
+	return time.Time{}, ""
+}
+
+// fileNameTime returns the time encoded in a file name, or the zero time when it has none
+func fileNameTime(name string, loc *time.Location) time.Time {
+	var t time.Time
+	var err error
+	if match := isoTimePattern.FindStringSubmatch(name); match != nil {
+		t, err = time.ParseInLocation("2006-01-02150405", match[1]+match[2]+match[3]+match[4], loc)
+	} else if match := compactTimePattern.FindStringSubmatch(name); match != nil {
+		t, err = time.ParseInLocation("20060102150405", match[1]+match[2], loc)
+	} else {
+		return time.Time{}
+	}
+	if err != nil || !plausible(t) {
+		return time.Time{}
+	}
+	return t
+}
+
+// plausible reports whether a parsed time can be a recording start rather than a serial
+// number or other digits that happen to look like a date
+func plausible(t time.Time) bool {
+	return t.Year() >= 1990 && t.Before(time.Now().Add(24*time.Hour))
+}
+
+// wavMetadataTime returns the recording start stored in the AudioMoth comment (LIST/INFO ICMT)
+// or GUANO chunk of a WAV file, or the zero time when it has neither
+func wavMetadataTime(path string) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer func() { _ = f.Close() }()
+
+	var header [12]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return time.Time{}
+	}
+
+	// Walk the chunks, skipping the audio data; GUANO metadata usually follows it
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			return time.Time{}
+		}
+		id, size := string(chunk[0:4]), int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		next := size + size%2 // chunks are padded to an even size
+
+		if (id == "LIST" || id == "guan") && size <= maxMetadataChunk {
+			body := make([]byte, next)
+			if _, err := io.ReadFull(f, body); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return time.Time{}
+			}
+			var t time.Time
+			if id == "LIST" {
+				t = audioMothTime(body)
+			} else {
+				t = guanoTime(body)
+			}
+			if !t.IsZero() {
+				return t
+			}
+			continue
+		}
+		if _, err := f.Seek(next, io.SeekCurrent); err != nil {
+			return time.Time{}
+		}
+	}
+}
+
+// audioMothTime returns the recording start in an AudioMoth comment of a LIST chunk
+func audioMothTime(list []byte) time.Time {
+	match := audioMothCommentPattern.FindSubmatch(list)
+	if match == nil {
+		return time.Time{}
+	}
+
+	offset := 0
+	if len(match[3]) > 0 {
+		hours, _ := strconv.Atoi(string(match[4]))
+		minutes, _ := strconv.Atoi(string(match[5]))
+		offset = hours*3600 + minutes*60
+		if string(match[3]) == "-" {
+			offset = -offset
+		}
+	}
+	zone := time.FixedZone("", offset)
+	t, err := time.ParseInLocation("02/01/2006 15:04:05", string(match[2])+" "+string(match[1]), zone)
+	if err != nil || !plausible(t) {
+		return time.Time{}
+	}
+	return t
+}
+
+// guanoTime returns the Timestamp field of GUANO metadata. Timestamps without a time zone
+// are local time.
+func guanoTime(guano []byte) time.Time {
+	scanner := bufio.NewScanner(bytes.NewReader(guano))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "Timestamp" {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil && plausible(t) {
+			return t
+		}
+		for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05"} {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil && plausible(t) {
+				return t
+			}
+		}
+		return time.Time{}
+	}
+	return time.Time{}
+}
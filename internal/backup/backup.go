@@ -56,6 +56,12 @@ type Target interface {
 	Validate() error
 }
 
+// Retriever is implemented by targets that can download stored backups for restoring
+type Retriever interface {
+	// Retrieve writes the archive of the backup with the given ID to w
+	Retrieve(ctx context.Context, id string, w io.Writer) error
+}
+
 // Metadata contains information about a backup
 type Metadata struct {
 	Version      int       `json:"version"`                 // Version of the metadata format
@@ -154,10 +160,12 @@ func sanitizeConfig(config *conf.Settings) *conf.Settings {
 	sanitized.Security.BasicAuth.ClientSecret = ""
 	sanitized.Security.GoogleAuth.ClientSecret = ""
 	sanitized.Security.GithubAuth.ClientSecret = ""
+	sanitized.Security.OIDCAuth.ClientSecret = ""
 	sanitized.Security.SessionSecret = ""
 	sanitized.Output.MySQL.Password = ""
 	sanitized.Realtime.MQTT.Password = ""
 	sanitized.Realtime.Weather.OpenWeather.APIKey = ""
+	sanitized.OutboundProxy.URL = sanitized.OutboundProxy.RedactedURL()
 
 	return &sanitized
 }
@@ -450,7 +458,13 @@ func (m *Manager) processBackupSource(ctx context.Context, sourceName string, so
 	//     m.logger.Warn("Failed to calculate checksum", "path", finalArchivePath, "error", err)
 	// }
 
-	// 8. Store the final archive in all registered targets
+	// 8. Verify the final archive before storing it, a broken backup must not replace good
+	// ones through the retention policy
+	if err := m.verifyBackup(ctx, source, finalArchivePath, metadata, tempDir); err != nil {
+		return tempDirs, fmt.Errorf("backup verification failed: %w", err)
+	}
+
+	// 9. Store the final archive in all registered targets
 	if err := m.storeBackupInTargets(ctx, finalArchivePath, metadata); err != nil {
 		return tempDirs, fmt.Errorf("failed to store backup in targets: %w", err)
 	}
@@ -767,29 +781,25 @@ func (m *Manager) addBackupDataToArchive(ctx context.Context, tw *tar.Writer, re
 	// Example: Use source name with a common extension
 	backupFilename := fmt.Sprintf("backup.%s", strings.ToLower(metadata.Source)) // e.g., backup.sqlite
 
-	// We don't know the size beforehand for streaming backup.
-	// Write the data directly. TAR format supports this.
-	// Create TAR header for the backup data
-	hdr := &tar.Header{
-		Name:    backupFilename,
-		Mode:    0o644, // Standard file permissions
-		ModTime: metadata.Timestamp,
-		// Size is unknown for streaming, tar writer handles this.
-	}
-
-	// Write header
-	if err := tw.WriteHeader(hdr); err != nil {
+	// Tar headers need the size of the data, which is unknown for a streamed backup.
+	// Spool the stream to a temporary file first to learn it.
+	spool, err := os.CreateTemp("", "birdnet-go-backup-data-*")
+	if err != nil {
 		return errors.New(err).
 			Component("backup").
 			Category(errors.CategoryFileIO).
-			Context("operation", "write_backup_data_tar_header").
+			Context("operation", "create_backup_data_spool").
 			Build()
 	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
 
-	// Copy data from source reader to tar writer
+	// Copy data from source reader to the spool file
 	// Wrap the reader with a context checker if possible/needed,
 	// although source.Backup should handle context internally.
-	copiedBytes, err := io.Copy(tw, reader)
+	copiedBytes, err := io.Copy(spool, reader)
 	if err != nil {
 		// Check for context cancellation specifically if possible
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
@@ -803,10 +813,42 @@ func (m *Manager) addBackupDataToArchive(ctx context.Context, tw *tar.Writer, re
 		return errors.New(err).
 			Component("backup").
 			Category(errors.CategoryFileIO).
-			Context("operation", "stream_backup_data_to_tar").
+			Context("operation", "stream_backup_data_to_spool").
 			Context("bytes_copied", copiedBytes).
 			Build()
 	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "rewind_backup_data_spool").
+			Build()
+	}
+
+	// Create TAR header for the backup data
+	hdr := &tar.Header{
+		Name:    backupFilename,
+		Mode:    0o644, // Standard file permissions
+		Size:    copiedBytes,
+		ModTime: metadata.Timestamp,
+	}
+
+	// Write header
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_backup_data_tar_header").
+			Build()
+	}
+
+	if _, err := io.Copy(tw, spool); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "stream_backup_data_to_tar").
+			Build()
+	}
 
 	m.logger.Debug("Finished adding backup data stream",
 		"backup_id", metadata.ID,
@@ -0,0 +1,251 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// RestoreResult describes the files extracted from a restored backup
+type RestoreResult struct {
+	Metadata Metadata // Metadata stored in the archive
+	Target   string   // Name of the target the backup was downloaded from
+	DataFile string   // Path of the extracted backup data, e.g. the database copy
+	Files    []string // Paths of all extracted files
+}
+
+// RestoreBackup downloads the backup with the given ID from a target holding it, decrypts it
+// when needed and extracts the archive into destDir. The live data is not touched, callers
+// decide what to do with the extracted files.
+func (m *Manager) RestoreBackup(ctx context.Context, id, destDir string) (*RestoreResult, error) {
+	if id == "" {
+		return nil, NewError(ErrValidation, "backup ID cannot be empty", nil)
+	}
+	m.logger.Info("Restoring backup", "backup_id", id, "destination", destDir)
+	start := time.Now()
+
+	// A partial listing is fine as long as one target holding the backup answered
+	allBackups, listErr := m.ListBackups(ctx)
+
+	m.mu.RLock()
+	var candidates []Target
+	var metadata *Metadata
+	for i := range allBackups {
+		if allBackups[i].ID != id {
+			continue
+		}
+		target := m.targets[allBackups[i].Target]
+		if _, ok := target.(Retriever); !ok {
+			continue
+		}
+		candidates = append(candidates, target)
+		if metadata == nil {
+			metadata = &allBackups[i].Metadata
+		}
+	}
+	m.mu.RUnlock()
+
+	if len(candidates) == 0 {
+		if listErr != nil {
+			return nil, fmt.Errorf("backup %s not found, listing targets failed: %w", id, listErr)
+		}
+		return nil, NewError(ErrNotFound, fmt.Sprintf("backup with ID '%s' not found in a target that supports restoring", id), nil)
+	}
+
+	if err := os.MkdirAll(destDir, 0o700); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_restore_directory").
+			Context("path", destDir).
+			Build()
+	}
+
+	var errs []error
+	for _, target := range candidates {
+		result, err := m.restoreFromTarget(ctx, target, metadata, destDir)
+		if err != nil {
+			m.logger.Warn("Failed to restore backup from target", "backup_id", id, "target_name", target.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("target %s: %w", target.Name(), err))
+			continue
+		}
+		m.logger.Info("Backup restored",
+			"backup_id", id,
+			"target_name", target.Name(),
+			"files", len(result.Files),
+			"duration_ms", time.Since(start).Milliseconds())
+		return result, nil
+	}
+	return nil, combineErrors(errs)
+}
+
+// restoreFromTarget downloads a backup from one target and extracts it into destDir
+func (m *Manager) restoreFromTarget(ctx context.Context, target Target, metadata *Metadata, destDir string) (*RestoreResult, error) {
+	retrieveCtx, cancel := context.WithTimeout(ctx, m.getStoreTimeout())
+	defer cancel()
+
+	tempFile, err := os.CreateTemp("", "birdnet-go-restore-*")
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_restore_temp_file").
+			Build()
+	}
+	defer func() {
+		_ = tempFile.Close()
+		_ = os.Remove(tempFile.Name())
+	}()
+
+	if err := target.(Retriever).Retrieve(retrieveCtx, metadata.ID, tempFile); err != nil {
+		return nil, err
+	}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "rewind_restore_temp_file").
+			Build()
+	}
+
+	archive, err := m.archiveReader(tempFile, metadata.Encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := extractArchive(archive, destDir)
+	if err != nil {
+		return nil, err
+	}
+	result.Target = target.Name()
+	if result.Metadata.ID == "" {
+		result.Metadata = *metadata
+	}
+	return result, nil
+}
+
+// archiveReader returns a reader of the plain archive in r, decrypting it when encrypted is set
+func (m *Manager) archiveReader(r io.Reader, encrypted bool) (io.Reader, error) {
+	if !encrypted {
+		return r, nil
+	}
+	ciphertext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_encrypted_archive").
+			Build()
+	}
+	plaintext, err := m.DecryptData(ciphertext)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "decrypt_backup").
+			Context("hint", "the encryption key of the installation that made the backup is required").
+			Build()
+	}
+	return bytes.NewReader(plaintext), nil
+}
+
+// extractArchive writes the files of a backup archive into destDir. Entry names are reduced
+// to their base name so an archive cannot write outside destDir.
+func extractArchive(r io.Reader, destDir string) (*RestoreResult, error) {
+	result := &RestoreResult{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "read_backup_archive").
+				Build()
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.Base(filepath.Clean(hdr.Name))
+		if name == "." || name == string(filepath.Separator) {
+			continue
+		}
+		path := filepath.Join(destDir, name)
+
+		if name == "metadata.json" {
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, errors.New(err).
+					Component("backup").
+					Category(errors.CategoryValidation).
+					Context("operation", "read_backup_metadata").
+					Build()
+			}
+			if err := json.Unmarshal(data, &result.Metadata); err != nil {
+				return nil, errors.New(err).
+					Component("backup").
+					Category(errors.CategoryValidation).
+					Context("operation", "parse_backup_metadata").
+					Build()
+			}
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				return nil, restoreWriteError(err, path)
+			}
+		} else if err := writeArchiveEntry(tr, path); err != nil {
+			return nil, err
+		}
+
+		result.Files = append(result.Files, path)
+		if strings.HasPrefix(name, "backup.") {
+			result.DataFile = path
+		}
+	}
+
+	if result.DataFile == "" {
+		return nil, errors.Newf("backup archive contains no backup data").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "read_backup_archive").
+			Build()
+	}
+	return result, nil
+}
+
+// writeArchiveEntry copies the current archive entry to path
+func writeArchiveEntry(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return restoreWriteError(err, path)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return restoreWriteError(err, path)
+	}
+	if err := f.Close(); err != nil {
+		return restoreWriteError(err, path)
+	}
+	return nil
+}
+
+// restoreWriteError reports a failure to write an extracted file
+func restoreWriteError(err error, path string) error {
+	return errors.New(err).
+		Component("backup").
+		Category(errors.CategoryFileIO).
+		Context("operation", "write_restored_file").
+		Context("path", path).
+		Build()
+}
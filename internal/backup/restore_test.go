@@ -0,0 +1,152 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// memoryTarget keeps stored archives in memory
+type memoryTarget struct {
+	name     string
+	archives map[string][]byte
+	metadata map[string]Metadata
+}
+
+func newMemoryTarget(name string) *memoryTarget {
+	return &memoryTarget{name: name, archives: map[string][]byte{}, metadata: map[string]Metadata{}}
+}
+
+func (t *memoryTarget) Name() string    { return t.name }
+func (t *memoryTarget) Validate() error { return nil }
+
+func (t *memoryTarget) Store(ctx context.Context, sourcePath string, metadata *Metadata) error {
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	t.archives[metadata.ID] = data
+	t.metadata[metadata.ID] = *metadata
+	return nil
+}
+
+func (t *memoryTarget) List(ctx context.Context) ([]BackupInfo, error) {
+	backups := make([]BackupInfo, 0, len(t.metadata))
+	for id := range t.metadata {
+		backups = append(backups, BackupInfo{Metadata: t.metadata[id], Target: t.name})
+	}
+	return backups, nil
+}
+
+func (t *memoryTarget) Delete(ctx context.Context, id string) error {
+	delete(t.archives, id)
+	delete(t.metadata, id)
+	return nil
+}
+
+func (t *memoryTarget) Retrieve(ctx context.Context, id string, w io.Writer) error {
+	_, err := w.Write(t.archives[id])
+	return err
+}
+
+// listOnlyTarget is a target that cannot download backups
+type listOnlyTarget struct {
+	*memoryTarget
+}
+
+func (t listOnlyTarget) Retrieve() {}
+
+// newTestManager returns a manager with the given targets registered
+func newTestManager(t *testing.T, targets ...Target) *Manager {
+	t.Helper()
+	settings := &conf.Settings{}
+	m := &Manager{
+		config:     &settings.Backup,
+		fullConfig: settings,
+		sources:    map[string]Source{},
+		targets:    map[string]Target{},
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	for _, target := range targets {
+		if err := m.RegisterTarget(target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+// storeTestBackup creates an archive of data and stores it in target
+func storeTestBackup(t *testing.T, m *Manager, target Target, id string, data []byte) {
+	t.Helper()
+	metadata := &Metadata{Version: 1, ID: id, Timestamp: time.Date(2025, 5, 10, 3, 0, 0, 0, time.UTC), Source: "birdnet"}
+	archivePath := filepath.Join(t.TempDir(), id+".tar")
+	if err := m.createArchive(context.Background(), archivePath, bytes.NewReader(data), metadata); err != nil {
+		t.Fatalf("createArchive() error = %v", err)
+	}
+	if err := target.Store(context.Background(), archivePath, metadata); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	t.Parallel()
+
+	target := newMemoryTarget("memory")
+	m := newTestManager(t, target)
+	storeTestBackup(t, m, target, "birdnet-20250510-030000", []byte("database contents"))
+
+	destDir := filepath.Join(t.TempDir(), "restore")
+	result, err := m.RestoreBackup(context.Background(), "birdnet-20250510-030000", destDir)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+
+	if result.Target != "memory" || result.Metadata.ID != "birdnet-20250510-030000" {
+		t.Errorf("RestoreBackup() = %+v, want the backup from the memory target", result)
+	}
+	if len(result.Files) != 3 {
+		t.Errorf("extracted %v, want metadata, config and data files", result.Files)
+	}
+	if result.DataFile != filepath.Join(destDir, "backup.birdnet") {
+		t.Errorf("DataFile = %q, want backup.birdnet in the restore directory", result.DataFile)
+	}
+	data, err := os.ReadFile(result.DataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "database contents" {
+		t.Errorf("restored data = %q", data)
+	}
+}
+
+func TestRestoreBackupNotFound(t *testing.T) {
+	t.Parallel()
+
+	target := newMemoryTarget("memory")
+	listOnly := listOnlyTarget{newMemoryTarget("list-only")}
+	m := newTestManager(t, target, listOnly)
+	storeTestBackup(t, m, listOnly, "birdnet-20250510-030000", []byte("database contents"))
+
+	for _, id := range []string{"", "birdnet-20250101-030000", "birdnet-20250510-030000"} {
+		_, err := m.RestoreBackup(context.Background(), id, t.TempDir())
+		if err == nil {
+			t.Errorf("RestoreBackup(%q) succeeded, want error", id)
+		}
+	}
+}
+
+func TestExtractArchiveRejectsArchiveWithoutData(t *testing.T) {
+	t.Parallel()
+
+	if _, err := extractArchive(strings.NewReader("not a tar archive"), t.TempDir()); err == nil {
+		t.Error("extractArchive() of invalid data succeeded")
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -24,6 +26,9 @@ import (
 type SQLiteSource struct {
 	config *conf.Settings
 	logger *slog.Logger
+
+	mu        sync.Mutex
+	rowCounts map[string]int64 // Rows per table in the snapshot of the last backup, used by Verify
 }
 
 // NewSQLiteSource creates a new SQLite backup source
@@ -68,13 +73,12 @@ func (dc *DatabaseConnection) Close() error {
 // openDatabase opens a database connection with the given path
 func (s *SQLiteSource) openDatabase(dbPath string, readOnly bool) (*DatabaseConnection, error) {
 	// Build DSN with additional safety parameters
-	dsn := dbPath
+	dsn := dbPath + "?_busy_timeout=30000" // 30 second timeout
 	if readOnly {
-		dsn += "?mode=ro"
+		dsn += "&mode=ro"
 	}
-	dsn += "&_busy_timeout=30000" // 30 second timeout
-	dsn += "&_journal_mode=WAL"   // Ensure WAL mode
-	dsn += "&_sync=NORMAL"        // Less aggressive syncing for better performance
+	dsn += "&_journal_mode=WAL" // Ensure WAL mode
+	dsn += "&_sync=NORMAL"      // Less aggressive syncing for better performance
 
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
@@ -590,6 +594,24 @@ func (s *SQLiteSource) streamBackupToWriter(ctx context.Context, db *sql.DB, w i
 		return err
 	}
 
+	// The destination is in WAL mode, move all pages into the database file before it is copied
+	if _, err := dstConn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "checkpoint_backup_database").
+			Build()
+	}
+
+	// Record the row counts of the snapshot so Verify can compare the archived copy against them
+	rowCounts, err := countTableRows(ctx, destDB)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.rowCounts = rowCounts
+	s.mu.Unlock()
+
 	// Open the temporary backup file for reading with secure path validation
 	secureOp := backup.NewSecureFileOp("backup")
 	backupFile, cleanTempPath, err := secureOp.SecureOpen(tempPath)
@@ -750,3 +772,101 @@ func (s *SQLiteSource) validateConfig() (string, error) {
 
 	return dbPath, nil
 }
+
+// Verify opens the database copy extracted from a backup archive, runs an integrity check
+// and compares its row counts with the snapshot taken in the last Backup call
+func (s *SQLiteSource) Verify(ctx context.Context, path string) (*backup.VerificationResult, error) {
+	s.mu.Lock()
+	expected := s.rowCounts
+	s.mu.Unlock()
+
+	result := &backup.VerificationResult{}
+	err := s.withDatabase(path, true, func(conn *DatabaseConnection) error {
+		if err := s.verifyDatabaseIntegrity(conn.db); err != nil {
+			result.Integrity = "failed"
+			return err
+		}
+		result.Integrity = "ok"
+
+		rowCounts, err := countTableRows(ctx, conn.db)
+		if err != nil {
+			return err
+		}
+		result.RowCounts = rowCounts
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if expected == nil {
+		s.logger.Warn("No row counts recorded for the last backup, skipping row count comparison")
+		return result, nil
+	}
+	for table, want := range expected {
+		got, ok := result.RowCounts[table]
+		if !ok || got != want {
+			result.Mismatches = append(result.Mismatches, table)
+			s.logger.Error("Backup row count mismatch", "table", table, "expected_rows", want, "backup_rows", got)
+		}
+	}
+	if len(result.Mismatches) > 0 {
+		sort.Strings(result.Mismatches)
+		return result, errors.Newf("row counts of %d tables differ from the backed up database: %s",
+			len(result.Mismatches), strings.Join(result.Mismatches, ", ")).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "compare_row_counts").
+			Build()
+	}
+	return result, nil
+}
+
+// countTableRows returns the number of rows in each user table of the database
+func countTableRows(ctx context.Context, db *sql.DB) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "list_tables").
+			Build()
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "list_tables").
+				Build()
+		}
+		tables = append(tables, name)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "list_tables").
+			Build()
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, strings.ReplaceAll(table, `"`, `""`))
+		if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "count_table_rows").
+				Context("table", table).
+				Build()
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
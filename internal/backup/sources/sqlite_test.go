@@ -0,0 +1,102 @@
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// newTestDatabase creates a WAL mode SQLite database with a few notes
+func newTestDatabase(t *testing.T, notes int) string {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "birdnet.db")
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, common_name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < notes; i++ {
+		if _, err := db.Exec(`INSERT INTO notes (common_name) VALUES ('Eurasian Blackbird')`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dbPath
+}
+
+// backupToFile runs a backup of source and writes the stream to a file
+func backupToFile(t *testing.T, source *SQLiteSource) string {
+	t.Helper()
+	reader, err := source.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading backup stream: %v", err)
+	}
+	backupPath := filepath.Join(t.TempDir(), "backup.birdnet")
+	if err := os.WriteFile(backupPath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return backupPath
+}
+
+func TestSQLiteSourceVerify(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = newTestDatabase(t, 25)
+	source := NewSQLiteSource(settings, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	backupPath := backupToFile(t, source)
+	result, err := source.Verify(context.Background(), backupPath)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Integrity != "ok" || result.RowCounts["notes"] != 25 {
+		t.Errorf("Verify() = %+v, want passed integrity check and 25 notes", result)
+	}
+}
+
+func TestSQLiteSourceVerifyRowCountMismatch(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = newTestDatabase(t, 10)
+	source := NewSQLiteSource(settings, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_ = backupToFile(t, source)
+
+	// A database with fewer rows than the snapshot of the backup
+	result, err := source.Verify(context.Background(), newTestDatabase(t, 9))
+	if err == nil {
+		t.Fatal("Verify() of a database with missing rows succeeded")
+	}
+	if len(result.Mismatches) != 1 || result.Mismatches[0] != "notes" {
+		t.Errorf("Mismatches = %v, want [notes]", result.Mismatches)
+	}
+}
+
+func TestSQLiteSourceVerifyCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	source := NewSQLiteSource(&conf.Settings{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	path := filepath.Join(t.TempDir(), "backup.birdnet")
+	if err := os.WriteFile(path, []byte("not a database"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Verify(context.Background(), path); err == nil {
+		t.Error("Verify() of a corrupt file succeeded")
+	}
+}
@@ -0,0 +1,78 @@
+package targets
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// isBackupFile reports whether name is the archive or metadata file of the backup with the
+// given ID. Archives are named after the backup ID with a .tar or .tar.enc extension.
+func isBackupFile(name, id string) bool {
+	return id != "" && strings.HasPrefix(name, id+".")
+}
+
+// NewTarget creates the backup target described by a target configuration
+func NewTarget(config *conf.BackupTarget, logger *slog.Logger) (backup.Target, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	settings := config.Settings
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	switch strings.ToLower(config.Type) {
+	case "local":
+		path, _ := settings["path"].(string)
+		debug, _ := settings["debug"].(bool)
+		return NewLocalTarget(LocalTargetConfig{Path: path, Debug: debug}, nil)
+	case "ftp":
+		return NewFTPTargetFromMap(settings)
+	case "sftp":
+		return NewSFTPTarget(settings, logger)
+	case "rsync":
+		return NewRsyncTarget(settings)
+	case "gdrive", "googledrive":
+		return NewGDriveTargetFromMap(settings)
+	case "s3":
+		return NewS3Target(settings, logger)
+	case "webdav":
+		return NewWebDAVTarget(settings, logger)
+	default:
+		return nil, errors.Newf("unsupported backup target type %q", config.Type).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_target").
+			Build()
+	}
+}
+
+// RegisterTargets creates the enabled targets of the backup configuration and registers
+// them with the manager. A target that cannot be created or validated does not prevent the
+// others from being registered, its error is included in the returned error.
+func RegisterTargets(manager *backup.Manager, config *conf.BackupConfig, logger *slog.Logger) (int, error) {
+	registered := 0
+	var errs []error
+	for i := range config.Targets {
+		targetConfig := &config.Targets[i]
+		if !targetConfig.Enabled {
+			continue
+		}
+
+		target, err := NewTarget(targetConfig, logger)
+		if err == nil {
+			err = manager.RegisterTarget(target)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s target: %w", targetConfig.Type, err))
+			continue
+		}
+		registered++
+	}
+	return registered, errors.Join(errs...)
+}
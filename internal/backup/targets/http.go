@@ -0,0 +1,104 @@
+package targets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	defaultHTTPTimeout      = 30 * time.Second
+	defaultHTTPMaxRetries   = 3
+	defaultHTTPRetryBackoff = time.Second
+	maxHTTPErrorBodySize    = 1024
+)
+
+// httpStatusError reports a response with an unexpected status code
+type httpStatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface
+func (e *httpStatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected status %d", e.Method, e.URL, e.StatusCode)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// isTransientHTTPError checks if a failed request is worth retrying
+func isTransientHTTPError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	// Transport errors such as refused or reset connections
+	return true
+}
+
+// doHTTPRequest sends the request built by newRequest and returns the response when its status
+// is one of okStatus, retrying transient failures with a linear backoff. newRequest is called
+// for every attempt so request bodies are read from the start again. The caller closes the
+// body of the returned response.
+func doHTTPRequest(ctx context.Context, client *http.Client, maxRetries int, backoff time.Duration, newRequest func() (*http.Request, error), okStatus ...int) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, errors.New(ctx.Err()).
+					Component("backup").
+					Category(errors.CategorySystem).
+					Context("operation", "http_retry").
+					Context("error_type", "cancelled").
+					Build()
+			case <-time.After(backoff * time.Duration(attempt)):
+			}
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err == nil {
+			if slices.Contains(okStatus, resp.StatusCode) {
+				return resp, nil
+			}
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTTPErrorBodySize))
+			_ = resp.Body.Close()
+			err = &httpStatusError{
+				Method:     req.Method,
+				URL:        req.URL.Redacted(),
+				StatusCode: resp.StatusCode,
+				Body:       strings.TrimSpace(string(body)),
+			}
+		}
+
+		lastErr = err
+		if !isTransientHTTPError(err) {
+			break
+		}
+	}
+
+	return nil, errors.New(lastErr).
+		Component("backup").
+		Category(errors.CategoryNetwork).
+		Context("operation", "http_request").
+		Context("max_retries", maxRetries).
+		Build()
+}
@@ -563,43 +563,106 @@ func (t *LocalTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 	return backups, nil
 }
 
-// Delete removes a backup
+// Delete removes the archive and metadata files of a backup
 func (t *LocalTarget) Delete(ctx context.Context, backupID string) error {
 	if t.debug {
 		t.logger.Printf("🔄 Deleting backup %s from local target", backupID)
 	}
 
-	// Delete both the backup file and its metadata
-	backupPath := filepath.Join(t.path, backupID)
-	metadataPath := backupPath + ".meta"
+	paths, err := t.backupFiles(backupID)
+	if err != nil {
+		return err
+	}
 
-	// Delete backup file
-	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
-		return errors.New(err).
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "delete_backup_file").
+				Context("backup_id", backupID).
+				Context("path", path).
+				Build()
+		}
+	}
+
+	if t.debug {
+		t.logger.Printf("✅ Successfully deleted backup %s", backupID)
+	}
+
+	return nil
+}
+
+// Retrieve copies the archive of a backup to w
+func (t *LocalTarget) Retrieve(ctx context.Context, backupID string, w io.Writer) error {
+	paths, err := t.backupFiles(backupID)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, metadataFileExt) {
+			continue
+		}
+
+		secureOp := backup.NewSecureFileOp("backup")
+		file, cleanPath, err := secureOp.SecureOpen(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				t.logger.Printf("local: failed to close backup file %s: %v", cleanPath, err)
+			}
+		}()
+
+		if _, err := io.Copy(w, file); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "read_backup_file").
+				Context("backup_id", backupID).
+				Context("path", cleanPath).
+				Build()
+		}
+		return nil
+	}
+
+	return backup.NewError(backup.ErrNotFound, fmt.Sprintf("local: backup %s not found", backupID), nil)
+}
+
+// backupFiles returns the paths of the archive and metadata files of a backup. Backups are
+// looked up by ID, or by archive file name as accepted by earlier versions.
+func (t *LocalTarget) backupFiles(backupID string) ([]string, error) {
+	if backupID == "" || filepath.Base(backupID) != backupID {
+		return nil, errors.Newf("invalid backup ID %q", backupID).
 			Component("backup").
-			Category(errors.CategoryFileIO).
-			Context("operation", "delete_backup_file").
-			Context("backup_id", backupID).
-			Context("path", backupPath).
+			Category(errors.CategoryValidation).
+			Context("operation", "resolve_backup_files").
 			Build()
 	}
 
-	// Delete metadata file
-	if err := os.Remove(metadataPath); err != nil && !os.IsNotExist(err) {
-		return errors.New(err).
+	entries, err := os.ReadDir(t.path)
+	if err != nil {
+		return nil, errors.New(err).
 			Component("backup").
 			Category(errors.CategoryFileIO).
-			Context("operation", "delete_metadata_file").
-			Context("backup_id", backupID).
-			Context("path", metadataPath).
+			Context("operation", "list_backups").
+			Context("path", t.path).
 			Build()
 	}
 
-	if t.debug {
-		t.logger.Printf("✅ Successfully deleted backup %s", backupID)
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if name == backupID || name == backupID+metadataFileExt || isBackupFile(name, backupID) {
+			paths = append(paths, filepath.Join(t.path, name))
+		}
 	}
-
-	return nil
+	return paths, nil
 }
 
 // Validate checks if the target configuration is valid
@@ -0,0 +1,587 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	s3Service         = "s3"
+	s3SigningAlgo     = "AWS4-HMAC-SHA256"
+	s3AmzDateFormat   = "20060102T150405Z"
+	s3ScopeDateFormat = "20060102"
+)
+
+// S3TargetConfig holds configuration for the S3 target
+type S3TargetConfig struct {
+	Endpoint        string // host[:port] or URL of the S3 API, defaults to AWS for the region
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Prefix          string // key prefix the backups are stored under
+	UseSSL          bool
+	Timeout         time.Duration
+	Debug           bool
+	MaxRetries      int
+	RetryBackoff    time.Duration
+}
+
+// S3Target implements the backup.Target interface for S3-compatible object storage such as
+// AWS S3, MinIO, Backblaze B2 and Wasabi. Requests are signed with AWS Signature Version 4
+// and use path-style addressing, which all of these services accept.
+type S3Target struct {
+	config   S3TargetConfig
+	endpoint *url.URL
+	client   *http.Client
+	logger   *slog.Logger
+	now      func() time.Time // clock used for signing, replaced in tests
+}
+
+// s3ListResult is the response of a ListObjectsV2 request
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// NewS3Target creates a new S3 target with the given configuration
+func NewS3Target(settings map[string]any, logger *slog.Logger) (*S3Target, error) {
+	config := S3TargetConfig{
+		UseSSL:       true,
+		Timeout:      defaultHTTPTimeout,
+		MaxRetries:   defaultHTTPMaxRetries,
+		RetryBackoff: defaultHTTPRetryBackoff,
+	}
+
+	for key, dest := range map[string]*string{
+		"bucket":          &config.Bucket,
+		"region":          &config.Region,
+		"accesskeyid":     &config.AccessKeyID,
+		"secretaccesskey": &config.SecretAccessKey,
+	} {
+		value, ok := settings[key].(string)
+		if !ok || value == "" {
+			return nil, errors.Newf("s3: %s is required", key).
+				Component("backup").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "create_s3_target").
+				Build()
+		}
+		*dest = value
+	}
+
+	if endpoint, ok := settings["endpoint"].(string); ok {
+		config.Endpoint = endpoint
+	}
+	if prefix, ok := settings["prefix"].(string); ok {
+		config.Prefix = strings.Trim(prefix, "/")
+	}
+	if useSSL, ok := settings["usessl"].(bool); ok {
+		config.UseSSL = useSSL
+	}
+	if timeout, ok := settings["timeout"].(string); ok {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "parse_timeout").
+				Build()
+		}
+		config.Timeout = duration
+	}
+	if debug, ok := settings["debug"].(bool); ok {
+		config.Debug = debug
+	}
+
+	endpoint, err := s3EndpointURL(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &S3Target{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: config.Timeout},
+		logger:   logger.With("backup_target", "s3"),
+		now:      time.Now,
+	}, nil
+}
+
+// s3EndpointURL returns the base URL of the S3 API for the configuration
+func s3EndpointURL(config *S3TargetConfig) (*url.URL, error) {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("s3.%s.amazonaws.com", config.Region)
+	}
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https"
+		if !config.UseSSL {
+			scheme = "http"
+		}
+		endpoint = scheme + "://" + endpoint
+	}
+
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		if err == nil {
+			err = fmt.Errorf("s3: invalid endpoint %q", config.Endpoint)
+		}
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_s3_endpoint").
+			Build()
+	}
+	return u, nil
+}
+
+// Name returns the name of this target
+func (t *S3Target) Name() string {
+	return "s3"
+}
+
+// objectKey returns the key of a file stored by this target
+func (t *S3Target) objectKey(name string) string {
+	if t.config.Prefix == "" {
+		return name
+	}
+	return t.config.Prefix + "/" + name
+}
+
+// objectURL returns the path-style URL of an object, or of the bucket when key is empty
+func (t *S3Target) objectURL(key string, query url.Values) string {
+	u := t.endpoint.String() + "/" + s3Encode(t.config.Bucket, false)
+	if key != "" {
+		u += "/" + s3Encode(key, false)
+	}
+	if len(query) > 0 {
+		u += "?" + s3CanonicalQuery(query)
+	}
+	return u
+}
+
+// newRequest creates a signed request. payloadHash is the hex SHA-256 of the body.
+func (t *S3Target) newRequest(ctx context.Context, method, rawURL string, body io.Reader, contentLength int64, payloadHash string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "create_s3_request").
+			Build()
+	}
+	req.ContentLength = contentLength
+	t.sign(req, payloadHash)
+	return req, nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (t *S3Target) sign(req *http.Request, payloadHash string) {
+	now := t.now().UTC()
+	amzDate := now.Format(s3AmzDateFormat)
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", now.Format(s3ScopeDateFormat), t.config.Region, s3Service)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{s3SigningAlgo, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+t.config.SecretAccessKey), now.Format(s3ScopeDateFormat))
+	key = hmacSHA256(key, t.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgo, t.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// do sends a request built by newRequest with retries
+func (t *S3Target) do(ctx context.Context, newRequest func() (*http.Request, error), okStatus ...int) (*http.Response, error) {
+	return doHTTPRequest(ctx, t.client, t.config.MaxRetries, t.config.RetryBackoff, newRequest, okStatus...)
+}
+
+// putObject uploads the data returned by open to key. open is called for every attempt and
+// the HTTP client closes the body once it is sent.
+func (t *S3Target) putObject(ctx context.Context, key string, size int64, payloadHash string, open func() (io.ReadCloser, error)) error {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		body, err := open()
+		if err != nil {
+			return nil, err
+		}
+		req, err := t.newRequest(ctx, http.MethodPut, t.objectURL(key, nil), body, size, payloadHash)
+		if err != nil {
+			_ = body.Close()
+		}
+		return req, err
+	}, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// listObjects returns the objects whose keys start with prefix
+func (t *S3Target) listObjects(ctx context.Context, prefix string, maxKeys int) (*s3ListResult, error) {
+	all := &s3ListResult{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if maxKeys > 0 {
+			query.Set("max-keys", fmt.Sprint(maxKeys))
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := t.do(ctx, func() (*http.Request, error) {
+			return t.newRequest(ctx, http.MethodGet, t.objectURL("", query), nil, 0, sha256Hex(nil))
+		}, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+
+		var page s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "parse_s3_list").
+				Build()
+		}
+
+		all.Contents = append(all.Contents, page.Contents...)
+		if !page.IsTruncated || page.NextContinuationToken == "" || maxKeys > 0 {
+			return all, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// backupKeys returns the keys of the archive and metadata objects of a backup
+func (t *S3Target) backupKeys(ctx context.Context, id string) ([]string, error) {
+	result, err := t.listObjects(ctx, t.objectKey(id+"."), 0)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(result.Contents))
+	for i := range result.Contents {
+		if isBackupFile(path.Base(result.Contents[i].Key), id) {
+			keys = append(keys, result.Contents[i].Key)
+		}
+	}
+	return keys, nil
+}
+
+// Store uploads a backup archive and its metadata
+func (t *S3Target) Store(ctx context.Context, sourcePath string, metadata *backup.Metadata) error {
+	if t.config.Debug {
+		t.logger.Debug("S3: Storing backup", "source", sourcePath, "bucket", t.config.Bucket)
+	}
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "marshal_metadata").
+			Build()
+	}
+
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "stat_source_file").
+			Context("source_path", sourcePath).
+			Build()
+	}
+	payloadHash, err := fileSHA256(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	// Upload the archive before its metadata so a listed backup is always complete
+	archiveKey := t.objectKey(filepath.Base(sourcePath))
+	err = t.putObject(ctx, archiveKey, info.Size(), payloadHash, func() (io.ReadCloser, error) {
+		file, err := os.Open(sourcePath)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "open_source_file").
+				Context("source_path", sourcePath).
+				Build()
+		}
+		return file, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.putObject(ctx, archiveKey+metadataFileExt, int64(len(metadataBytes)), sha256Hex(metadataBytes), func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(metadataBytes)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if t.config.Debug {
+		t.logger.Debug("S3: Stored backup", "key", archiveKey, "size", info.Size())
+	}
+	return nil
+}
+
+// List returns the backups stored under the configured prefix
+func (t *S3Target) List(ctx context.Context) ([]backup.BackupInfo, error) {
+	prefix := ""
+	if t.config.Prefix != "" {
+		prefix = t.config.Prefix + "/"
+	}
+	result, err := t.listObjects(ctx, prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(result.Contents))
+	for i := range result.Contents {
+		keys[result.Contents[i].Key] = true
+	}
+
+	var backups []backup.BackupInfo
+	for i := range result.Contents {
+		key := result.Contents[i].Key
+		if !strings.HasSuffix(key, metadataFileExt) || !keys[strings.TrimSuffix(key, metadataFileExt)] {
+			continue
+		}
+
+		metadata, err := t.readMetadata(ctx, key)
+		if err != nil {
+			t.logger.Warn("S3: Skipping backup with unreadable metadata", "key", key, "error", err)
+			continue
+		}
+		backups = append(backups, backup.BackupInfo{Metadata: *metadata, Target: t.Name()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+	return backups, nil
+}
+
+// readMetadata downloads and decodes a metadata object
+func (t *S3Target) readMetadata(ctx context.Context, key string) (*backup.Metadata, error) {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		return t.newRequest(ctx, http.MethodGet, t.objectURL(key, nil), nil, 0, sha256Hex(nil))
+	}, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var metadata backup.Metadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "decode_metadata").
+			Build()
+	}
+	return &metadata, nil
+}
+
+// Retrieve downloads the archive of a backup
+func (t *S3Target) Retrieve(ctx context.Context, id string, w io.Writer) error {
+	keys, err := t.backupKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if strings.HasSuffix(key, metadataFileExt) {
+			continue
+		}
+		resp, err := t.do(ctx, func() (*http.Request, error) {
+			return t.newRequest(ctx, http.MethodGet, t.objectURL(key, nil), nil, 0, sha256Hex(nil))
+		}, http.StatusOK)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if _, err := io.Copy(w, resp.Body); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "download_backup").
+				Context("key", key).
+				Build()
+		}
+		return nil
+	}
+	return backup.NewError(backup.ErrNotFound, fmt.Sprintf("s3: backup %s not found", id), nil)
+}
+
+// Delete removes the archive and metadata of a backup
+func (t *S3Target) Delete(ctx context.Context, id string) error {
+	keys, err := t.backupKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		resp, err := t.do(ctx, func() (*http.Request, error) {
+			return t.newRequest(ctx, http.MethodDelete, t.objectURL(key, nil), nil, 0, sha256Hex(nil))
+		}, http.StatusOK, http.StatusNoContent)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+	}
+
+	if t.config.Debug {
+		t.logger.Debug("S3: Deleted backup", "id", id, "objects", len(keys))
+	}
+	return nil
+}
+
+// Validate checks the configuration and that the bucket can be listed with the credentials
+func (t *S3Target) Validate() error {
+	if t.config.Bucket == "" || t.config.Region == "" {
+		return errors.Newf("s3: bucket and region are required").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "validate_s3_target").
+			Build()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+	defer cancel()
+	if _, err := t.listObjects(ctx, t.config.Prefix, 1); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "validate_s3_access").
+			Context("bucket", t.config.Bucket).
+			Build()
+	}
+	return nil
+}
+
+// s3Encode URI-encodes a value as required by Signature Version 4. Slashes are kept when
+// encoding object keys and escaped when encoding query values.
+func s3Encode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3CanonicalQuery returns the query parameters sorted and encoded for signing
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Encode(key, true)+"="+s3Encode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// fileSHA256 returns the hex SHA-256 of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "open_source_file").
+			Context("source_path", path).
+			Build()
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "hash_source_file").
+			Context("source_path", path).
+			Build()
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sha256Hex returns the hex SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
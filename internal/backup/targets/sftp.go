@@ -748,6 +748,7 @@ func (t *SFTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 
 	var backups []backup.BackupInfo
 	err := t.withRetry(ctx, func(client *sftp.Client) error {
+		backups = nil // Start over when the listing is retried
 		entries, err := client.ReadDir(t.config.BasePath)
 		if err != nil {
 			if strings.Contains(err.Error(), "no such file") {
@@ -760,22 +761,35 @@ func (t *SFTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 				Build()
 		}
 
+		files := make(map[string]bool, len(entries))
 		for _, entry := range entries {
-			if !entry.IsDir() && !strings.HasPrefix(entry.Name(), "sftp-upload-") {
-				// Skip metadata files
-				if strings.HasSuffix(entry.Name(), sftpMetadataFileExt) {
-					continue
-				}
+			files[entry.Name()] = true
+		}
 
-				backups = append(backups, backup.BackupInfo{
-					Target: entry.Name(),
-					Metadata: backup.Metadata{
-						Timestamp: entry.ModTime(),
-						Size:      entry.Size(),
-					},
-				})
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || strings.HasPrefix(name, "sftp-upload-") || strings.HasSuffix(name, sftpMetadataFileExt) {
+				continue
 			}
 
+			info := backup.BackupInfo{
+				Target: t.Name(),
+				Metadata: backup.Metadata{
+					ID:        name,
+					Timestamp: entry.ModTime(),
+					Size:      entry.Size(),
+				},
+			}
+			// Prefer the metadata stored next to the archive, which carries the backup ID
+			if files[name+sftpMetadataFileExt] {
+				if metadata, err := t.readMetadata(client, path.Join(t.config.BasePath, name+sftpMetadataFileExt)); err == nil {
+					info.Metadata = *metadata
+				} else if t.config.Debug {
+					t.logger.Debug("SFTP: Failed to read backup metadata", "name", name, "error", err)
+				}
+			}
+			backups = append(backups, info)
+
 			select {
 			case <-ctx.Done():
 				return errors.New(ctx.Err()).
@@ -797,7 +811,47 @@ func (t *SFTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 	return backups, nil
 }
 
-// Delete implements the backup.Target interface
+// readMetadata reads and decodes a metadata file
+func (t *SFTPTarget) readMetadata(client *sftp.Client, metadataPath string) (*backup.Metadata, error) {
+	file, err := client.Open(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var metadata backup.Metadata
+	if err := json.NewDecoder(file).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// backupFiles returns the names of the archive and metadata files of a backup. Backups are
+// looked up by ID, or by archive file name for backups stored without metadata.
+func (t *SFTPTarget) backupFiles(client *sftp.Client, id string) ([]string, error) {
+	entries, err := client.ReadDir(t.config.BasePath)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "list_backups").
+			Build()
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if name == id || name == id+sftpMetadataFileExt || isBackupFile(name, id) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Delete removes the archive and metadata files of a backup
 func (t *SFTPTarget) Delete(ctx context.Context, target string) error {
 	if t.config.Debug {
 		t.logger.Debug("SFTP: Deleting backup",
@@ -812,19 +866,21 @@ func (t *SFTPTarget) Delete(ctx context.Context, target string) error {
 	}
 
 	return t.withRetry(ctx, func(client *sftp.Client) error {
-		backupPath := path.Join(t.config.BasePath, target)
-		if err := client.Remove(backupPath); err != nil {
-			return errors.New(err).
-				Component("backup").
-				Category(errors.CategoryNetwork).
-				Context("operation", "delete_backup").
-				Context("target", target).
-				Build()
+		names, err := t.backupFiles(client, target)
+		if err != nil {
+			return err
 		}
 
-		// Try to delete metadata file if it exists
-		metadataPath := backupPath + sftpMetadataFileExt
-		_ = client.Remove(metadataPath)
+		for _, name := range names {
+			if err := client.Remove(path.Join(t.config.BasePath, name)); err != nil {
+				return errors.New(err).
+					Component("backup").
+					Category(errors.CategoryNetwork).
+					Context("operation", "delete_backup").
+					Context("target", target).
+					Build()
+			}
+		}
 
 		if t.config.Debug {
 			t.logger.Debug("SFTP: Successfully deleted backup",
@@ -835,6 +891,53 @@ func (t *SFTPTarget) Delete(ctx context.Context, target string) error {
 	})
 }
 
+// Retrieve downloads the archive of a backup
+func (t *SFTPTarget) Retrieve(ctx context.Context, id string, w io.Writer) error {
+	if err := t.validatePath(path.Join(t.config.BasePath, id)); err != nil {
+		return err
+	}
+
+	// The download is not retried, a partial copy may already have been written to w
+	client, err := t.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.returnConnection(client)
+
+	names, err := t.backupFiles(client, id)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, sftpMetadataFileExt) {
+			continue
+		}
+
+		file, err := client.Open(path.Join(t.config.BasePath, name))
+		if err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "open_backup").
+				Context("name", name).
+				Build()
+		}
+		defer func() { _ = file.Close() }()
+
+		if _, err := file.WriteTo(w); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "download_backup").
+				Context("name", name).
+				Build()
+		}
+		return nil
+	}
+
+	return backup.NewError(backup.ErrNotFound, fmt.Sprintf("sftp: backup %s not found", id), nil)
+}
+
 // Validate checks if the target configuration is valid
 func (t *SFTPTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
@@ -0,0 +1,476 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	webdavTempFilePrefix = ".upload-"
+	webdavPropfindBody   = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/></d:prop></d:propfind>`
+)
+
+// WebDAVTargetConfig holds configuration for the WebDAV target
+type WebDAVTargetConfig struct {
+	URL          string // URL of the collection backups are stored in
+	Username     string
+	Password     string
+	Timeout      time.Duration
+	Debug        bool
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
+// WebDAVTarget implements the backup.Target interface for WebDAV servers such as Nextcloud,
+// ownCloud and rclone serve webdav. Archives are uploaded under a temporary name and moved
+// into place so an interrupted upload never looks like a complete backup.
+type WebDAVTarget struct {
+	config  WebDAVTargetConfig
+	baseURL *url.URL
+	client  *http.Client
+	logger  *slog.Logger
+}
+
+// webdavMultistatus is the response of a PROPFIND request
+type webdavMultistatus struct {
+	Responses []struct {
+		Href       string    `xml:"href"`
+		Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+	} `xml:"response"`
+}
+
+// NewWebDAVTarget creates a new WebDAV target with the given configuration
+func NewWebDAVTarget(settings map[string]any, logger *slog.Logger) (*WebDAVTarget, error) {
+	config := WebDAVTargetConfig{
+		Timeout:      defaultHTTPTimeout,
+		MaxRetries:   defaultHTTPMaxRetries,
+		RetryBackoff: defaultHTTPRetryBackoff,
+	}
+
+	rawURL, ok := settings["url"].(string)
+	if !ok || rawURL == "" {
+		return nil, errors.Newf("webdav: url is required").
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_webdav_target").
+			Build()
+	}
+	config.URL = rawURL
+
+	baseURL, err := url.Parse(rawURL)
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		if err == nil {
+			err = fmt.Errorf("webdav: url must be an http or https URL, got %q", rawURL)
+		}
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_webdav_url").
+			Build()
+	}
+	// Collection URLs end with a slash so relative file names resolve inside them
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+		baseURL.RawPath = ""
+	}
+
+	if username, ok := settings["username"].(string); ok {
+		config.Username = username
+	}
+	if password, ok := settings["password"].(string); ok {
+		config.Password = password
+	}
+	if timeout, ok := settings["timeout"].(string); ok {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "parse_timeout").
+				Build()
+		}
+		config.Timeout = duration
+	}
+	if debug, ok := settings["debug"].(bool); ok {
+		config.Debug = debug
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &WebDAVTarget{
+		config:  config,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: config.Timeout},
+		logger:  logger.With("backup_target", "webdav"),
+	}, nil
+}
+
+// Name returns the name of this target
+func (t *WebDAVTarget) Name() string {
+	return "webdav"
+}
+
+// fileURL returns the URL of a file in the backup collection
+func (t *WebDAVTarget) fileURL(name string) string {
+	return t.baseURL.ResolveReference(&url.URL{Path: name}).String()
+}
+
+// newRequest creates an authenticated request
+func (t *WebDAVTarget) newRequest(ctx context.Context, method, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "create_webdav_request").
+			Build()
+	}
+	if t.config.Username != "" {
+		req.SetBasicAuth(t.config.Username, t.config.Password)
+	}
+	return req, nil
+}
+
+// do sends a request built by newRequest with retries
+func (t *WebDAVTarget) do(ctx context.Context, newRequest func() (*http.Request, error), okStatus ...int) (*http.Response, error) {
+	return doHTTPRequest(ctx, t.client, t.config.MaxRetries, t.config.RetryBackoff, newRequest, okStatus...)
+}
+
+// send performs a request without a body and discards the response
+func (t *WebDAVTarget) send(ctx context.Context, method, rawURL string, header http.Header, okStatus ...int) error {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, method, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		return req, nil
+	}, okStatus...)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// ensureCollection creates the backup collection and its missing parents
+func (t *WebDAVTarget) ensureCollection(ctx context.Context) error {
+	if _, err := t.listFiles(ctx); err == nil {
+		return nil
+	}
+	return t.mkcol(ctx, t.baseURL)
+}
+
+// mkcol creates a collection. Servers answer 409 Conflict when the parent is missing, in
+// which case the parent is created first.
+func (t *WebDAVTarget) mkcol(ctx context.Context, u *url.URL) error {
+	// 405 Method Not Allowed means the collection already exists
+	err := t.send(ctx, "MKCOL", u.String(), nil, http.StatusCreated, http.StatusMethodNotAllowed)
+	var statusErr *httpStatusError
+	if err == nil || !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusConflict || u.Path == "/" {
+		return err
+	}
+
+	parent := *u
+	parent.Path = path.Dir(strings.TrimSuffix(u.Path, "/")) + "/"
+	parent.RawPath = ""
+	if parent.Path == "//" {
+		parent.Path = "/"
+	}
+	if err := t.mkcol(ctx, &parent); err != nil {
+		return err
+	}
+	return t.send(ctx, "MKCOL", u.String(), nil, http.StatusCreated, http.StatusMethodNotAllowed)
+}
+
+// upload PUTs a file under a temporary name and moves it to name
+func (t *WebDAVTarget) upload(ctx context.Context, name string, open func() (io.ReadCloser, int64, error)) error {
+	tempName := webdavTempFilePrefix + name
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		body, size, err := open()
+		if err != nil {
+			return nil, err
+		}
+		req, err := t.newRequest(ctx, http.MethodPut, t.fileURL(tempName), body)
+		if err != nil {
+			_ = body.Close()
+			return nil, err
+		}
+		req.ContentLength = size
+		return req, nil
+	}, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	header := http.Header{
+		"Destination": {t.fileURL(name)},
+		"Overwrite":   {"T"},
+	}
+	if err := t.send(ctx, "MOVE", t.fileURL(tempName), header, http.StatusCreated, http.StatusNoContent); err != nil {
+		// Do not leave the partial upload behind
+		_ = t.send(ctx, http.MethodDelete, t.fileURL(tempName), nil, http.StatusOK, http.StatusNoContent, http.StatusNotFound)
+		return err
+	}
+	return nil
+}
+
+// listFiles returns the names of the files in the backup collection
+func (t *WebDAVTarget) listFiles(ctx context.Context) ([]string, error) {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, "PROPFIND", t.baseURL.String(), strings.NewReader(webdavPropfindBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Depth", "1")
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+		return req, nil
+	}, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result webdavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "parse_webdav_propfind").
+			Build()
+	}
+
+	names := make([]string, 0, len(result.Responses))
+	for i := range result.Responses {
+		if result.Responses[i].Collection != nil {
+			continue
+		}
+		href, err := url.Parse(result.Responses[i].Href)
+		if err != nil {
+			continue
+		}
+		names = append(names, path.Base(href.Path))
+	}
+	return names, nil
+}
+
+// Store uploads a backup archive and its metadata
+func (t *WebDAVTarget) Store(ctx context.Context, sourcePath string, metadata *backup.Metadata) error {
+	if t.config.Debug {
+		t.logger.Debug("WebDAV: Storing backup", "source", sourcePath, "url", t.baseURL.Redacted())
+	}
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "marshal_metadata").
+			Build()
+	}
+
+	if err := t.ensureCollection(ctx); err != nil {
+		return err
+	}
+
+	// Upload the archive before its metadata so a listed backup is always complete
+	name := filepath.Base(sourcePath)
+	err = t.upload(ctx, name, func() (io.ReadCloser, int64, error) {
+		file, err := os.Open(sourcePath)
+		if err != nil {
+			return nil, 0, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "open_source_file").
+				Context("source_path", sourcePath).
+				Build()
+		}
+		info, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return nil, 0, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "stat_source_file").
+				Context("source_path", sourcePath).
+				Build()
+		}
+		return file, info.Size(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.upload(ctx, name+metadataFileExt, func() (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(metadataBytes)), int64(len(metadataBytes)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if t.config.Debug {
+		t.logger.Debug("WebDAV: Stored backup", "name", name)
+	}
+	return nil
+}
+
+// List returns the backups stored in the collection
+func (t *WebDAVTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
+	names, err := t.listFiles(ctx)
+	if err != nil {
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, nil // Nothing has been stored yet
+		}
+		return nil, err
+	}
+
+	files := make(map[string]bool, len(names))
+	for _, name := range names {
+		files[name] = true
+	}
+
+	var backups []backup.BackupInfo
+	for _, name := range names {
+		if strings.HasPrefix(name, webdavTempFilePrefix) ||
+			!strings.HasSuffix(name, metadataFileExt) || !files[strings.TrimSuffix(name, metadataFileExt)] {
+			continue
+		}
+
+		metadata, err := t.readMetadata(ctx, name)
+		if err != nil {
+			t.logger.Warn("WebDAV: Skipping backup with unreadable metadata", "name", name, "error", err)
+			continue
+		}
+		backups = append(backups, backup.BackupInfo{Metadata: *metadata, Target: t.Name()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+	return backups, nil
+}
+
+// get downloads a file of the collection
+func (t *WebDAVTarget) get(ctx context.Context, name string) (*http.Response, error) {
+	return t.do(ctx, func() (*http.Request, error) {
+		return t.newRequest(ctx, http.MethodGet, t.fileURL(name), nil)
+	}, http.StatusOK)
+}
+
+// readMetadata downloads and decodes a metadata file
+func (t *WebDAVTarget) readMetadata(ctx context.Context, name string) (*backup.Metadata, error) {
+	resp, err := t.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var metadata backup.Metadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "decode_metadata").
+			Build()
+	}
+	return &metadata, nil
+}
+
+// backupFiles returns the names of the archive and metadata files of a backup
+func (t *WebDAVTarget) backupFiles(ctx context.Context, id string) ([]string, error) {
+	names, err := t.listFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range names {
+		if isBackupFile(name, id) {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// Retrieve downloads the archive of a backup
+func (t *WebDAVTarget) Retrieve(ctx context.Context, id string, w io.Writer) error {
+	files, err := t.backupFiles(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if strings.HasSuffix(name, metadataFileExt) {
+			continue
+		}
+		resp, err := t.get(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if _, err := io.Copy(w, resp.Body); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "download_backup").
+				Context("name", name).
+				Build()
+		}
+		return nil
+	}
+	return backup.NewError(backup.ErrNotFound, fmt.Sprintf("webdav: backup %s not found", id), nil)
+}
+
+// Delete removes the archive and metadata of a backup
+func (t *WebDAVTarget) Delete(ctx context.Context, id string) error {
+	files, err := t.backupFiles(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if err := t.send(ctx, http.MethodDelete, t.fileURL(name), nil, http.StatusOK, http.StatusNoContent, http.StatusNotFound); err != nil {
+			return err
+		}
+	}
+
+	if t.config.Debug {
+		t.logger.Debug("WebDAV: Deleted backup", "id", id, "files", len(files))
+	}
+	return nil
+}
+
+// Validate checks that the collection exists or can be created with the credentials
+func (t *WebDAVTarget) Validate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+	defer cancel()
+	if err := t.ensureCollection(ctx); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "validate_webdav_access").
+			Context("url", t.baseURL.Redacted()).
+			Build()
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"golang.org/x/net/webdav"
+)
+
+// newWebDAVTestServer starts an in-memory WebDAV server requiring basic authentication
+func newWebDAVTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	handler := &webdav.Handler{FileSystem: webdav.NewMemFS(), LockSystem: webdav.NewMemLS()}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "birdnet" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeTestArchive writes a fake backup archive and returns its path and metadata
+func writeTestArchive(t *testing.T, id string, content []byte) (string, *backup.Metadata) {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), id+".tar")
+	if err := os.WriteFile(archivePath, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath, &backup.Metadata{
+		Version:   1,
+		ID:        id,
+		Timestamp: time.Date(2025, 5, 10, 3, 0, 0, 0, time.UTC),
+		Size:      int64(len(content)),
+		Source:    "birdnet",
+	}
+}
+
+func TestWebDAVTarget(t *testing.T) {
+	t.Parallel()
+
+	server := newWebDAVTestServer(t)
+	target, err := NewWebDAVTarget(map[string]any{
+		"url":      server.URL + "/backups/birdnet-go",
+		"username": "birdnet",
+		"password": "secret",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewWebDAVTarget() error = %v", err)
+	}
+	if err := target.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, missing collections should be created", err)
+	}
+
+	ctx := context.Background()
+	content := []byte("archive data")
+	for i, id := range []string{"birdnet-20250510-030000", "birdnet-20250511-030000"} {
+		archivePath, metadata := writeTestArchive(t, id, content)
+		metadata.Timestamp = metadata.Timestamp.AddDate(0, 0, i)
+		if err := target.Store(ctx, archivePath, metadata); err != nil {
+			t.Fatalf("Store(%s) error = %v", id, err)
+		}
+	}
+
+	backups, err := target.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(backups) != 2 || backups[0].ID != "birdnet-20250511-030000" || backups[0].Target != "webdav" {
+		t.Fatalf("List() = %+v, want both backups newest first", backups)
+	}
+
+	var buf bytes.Buffer
+	if err := target.Retrieve(ctx, "birdnet-20250510-030000", &buf); err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("Retrieve() = %q, want %q", buf.Bytes(), content)
+	}
+
+	if err := target.Delete(ctx, "birdnet-20250510-030000"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	backups, err = target.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(backups) != 1 {
+		t.Errorf("List() after Delete returned %d backups, want 1", len(backups))
+	}
+	if err := target.Retrieve(ctx, "birdnet-20250510-030000", &buf); err == nil {
+		t.Error("Retrieve() of a deleted backup succeeded")
+	}
+}
+
+func TestWebDAVTargetAuthenticationFailure(t *testing.T) {
+	t.Parallel()
+
+	server := newWebDAVTestServer(t)
+	target, err := NewWebDAVTarget(map[string]any{"url": server.URL + "/backups", "username": "birdnet", "password": "wrong"}, nil)
+	if err != nil {
+		t.Fatalf("NewWebDAVTarget() error = %v", err)
+	}
+	if err := target.Validate(); err == nil {
+		t.Error("Validate() succeeded with invalid credentials")
+	}
+}
+
+func TestNewWebDAVTargetInvalidURL(t *testing.T) {
+	t.Parallel()
+
+	for _, settings := range []map[string]any{
+		{},
+		{"url": "ftp://example.com/backups"},
+		{"url": "not a url"},
+	} {
+		if _, err := NewWebDAVTarget(settings, nil); err == nil {
+			t.Errorf("NewWebDAVTarget(%v) succeeded, want error", settings)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// Verifier is implemented by sources that can check the data of a finished backup
+type Verifier interface {
+	// Verify checks the backup data extracted from the archive to path against the data
+	// the source produced in its last Backup call
+	Verify(ctx context.Context, path string) (*VerificationResult, error)
+}
+
+// VerificationResult describes the checks run on the data of a backup
+type VerificationResult struct {
+	Integrity  string           // Result of the integrity check, "ok" when it passed
+	RowCounts  map[string]int64 // Number of rows per table in the backup
+	Mismatches []string         // Tables whose row count differs from the backed up data
+}
+
+// TotalRows returns the number of rows in all tables of the backup
+func (r *VerificationResult) TotalRows() int64 {
+	var total int64
+	for _, count := range r.RowCounts {
+		total += count
+	}
+	return total
+}
+
+// verifyBackup extracts the final archive into workDir and lets the source check the
+// extracted data. Sources that do not implement Verifier are not verified. A failed
+// verification is reported as a high priority error so it reaches notifications and telemetry.
+func (m *Manager) verifyBackup(ctx context.Context, source Source, archivePath string, metadata *Metadata, workDir string) error {
+	verifier, ok := source.(Verifier)
+	if !ok {
+		m.logger.Debug("Source does not support verification, skipping", "source_name", source.Name())
+		return nil
+	}
+
+	start := time.Now()
+	m.logger.Info("Verifying backup", "backup_id", metadata.ID, "source_name", source.Name())
+
+	result, err := m.verifyArchive(ctx, verifier, archivePath, metadata, workDir)
+	if err != nil {
+		verifyErr := errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Priority(errors.PriorityHigh).
+			Context("operation", "verify_backup").
+			Context("backup_id", metadata.ID).
+			Context("source", source.Name())
+		if result != nil && len(result.Mismatches) > 0 {
+			verifyErr = verifyErr.Context("mismatched_tables", result.Mismatches)
+		}
+		m.logger.Error("Backup verification failed", "backup_id", metadata.ID, "source_name", source.Name(), "error", err)
+		return verifyErr.Build()
+	}
+
+	m.logger.Info("Backup verification passed",
+		"backup_id", metadata.ID,
+		"source_name", source.Name(),
+		"tables", len(result.RowCounts),
+		"rows", result.TotalRows(),
+		"duration_ms", time.Since(start).Milliseconds())
+	notification.NotifyInfo("Backup verified",
+		fmt.Sprintf("Backup %s passed verification: integrity check %s, %d tables with %d rows",
+			metadata.ID, result.Integrity, len(result.RowCounts), result.TotalRows()))
+	return nil
+}
+
+// verifyArchive extracts the archive at archivePath into workDir and runs the verifier on the backup data
+func (m *Manager) verifyArchive(ctx context.Context, verifier Verifier, archivePath string, metadata *Metadata, workDir string) (*VerificationResult, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "open_archive_for_verification").
+			Context("archive_path", archivePath).
+			Build()
+	}
+	defer func() { _ = f.Close() }()
+
+	archive, err := m.archiveReader(f, metadata.Encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	extractDir := filepath.Join(workDir, "verify")
+	if err := os.MkdirAll(extractDir, 0o700); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_verification_directory").
+			Build()
+	}
+	defer func() {
+		if err := os.RemoveAll(extractDir); err != nil {
+			m.logger.Warn("Failed to remove verification directory", "path", extractDir, "error", err)
+		}
+	}()
+
+	extracted, err := extractArchive(archive, extractDir)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Verify(ctx, extracted.DataFile)
+}
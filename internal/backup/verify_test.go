@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// verifyingSource is a source whose verification compares the backup data with its contents
+type verifyingSource struct {
+	data     []byte
+	verified []byte // Backup data seen by Verify
+}
+
+func (s *verifyingSource) Name() string    { return "birdnet" }
+func (s *verifyingSource) Validate() error { return nil }
+
+func (s *verifyingSource) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+func (s *verifyingSource) Verify(ctx context.Context, path string) (*VerificationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s.verified = data
+	if !bytes.Equal(data, []byte("database contents")) {
+		return &VerificationResult{Integrity: "failed"}, errors.New("unexpected backup data")
+	}
+	return &VerificationResult{Integrity: "ok", RowCounts: map[string]int64{"notes": 1}}, nil
+}
+
+func TestProcessBackupSourceVerifiesBackup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		data       string
+		wantStored bool
+	}{
+		{"passed verification is stored", "database contents", true},
+		{"failed verification is not stored", "corrupted contents", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := newMemoryTarget("memory")
+			m := newTestManager(t, target)
+			source := &verifyingSource{data: []byte(tt.data)}
+
+			tempDirs, err := m.processBackupSource(context.Background(), "birdnet", source, time.Now().UTC(), true, false)
+			m.cleanupTempDirectories(tempDirs)
+
+			if (err == nil) != tt.wantStored {
+				t.Errorf("processBackupSource() error = %v, want error %v", err, !tt.wantStored)
+			}
+			if string(source.verified) != tt.data {
+				t.Errorf("Verify() saw %q, want the backup data %q", source.verified, tt.data)
+			}
+			if stored := len(target.archives) == 1; stored != tt.wantStored {
+				t.Errorf("backup stored = %v, want %v", stored, tt.wantStored)
+			}
+		})
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"time"
 
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/observation"
@@ -28,7 +30,8 @@ func (bn *BirdNET) Predict(sample [][]float32) ([]datastore.Results, error) {
 	return bn.PredictWithContext(context.Background(), sample)
 }
 
-// PredictWithContext performs inference with tracing support
+// PredictWithContext performs inference with tracing support. Predictions of concurrent
+// callers run in parallel on the interpreters of the inference pool.
 func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) ([]datastore.Results, error) {
 	span, _ := StartSpan(ctx, "birdnet.predict", "Species prediction")
 	defer span.Finish()
@@ -40,23 +43,13 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 		span.SetData("sample_size", len(sample[0]))
 	}
 
-	// implement locking to prevent concurrent access to the interpreter, not
-	// necessarily best way to manage multiple audio sources but works for now
-	bn.mu.Lock()
-	defer bn.mu.Unlock()
+	// Hold the read lock so a model reload waits until the prediction is done
+	bn.mu.RLock()
+	defer bn.mu.RUnlock()
 
-	// Get the input tensor from the interpreter
-	inputTensor := bn.AnalysisInterpreter.GetInputTensor(0)
-	if inputTensor == nil {
-		err := errors.New(fmt.Errorf("cannot get input tensor")).
-			Category(errors.CategoryModelInit).
-			ModelContext(bn.Settings.BirdNET.ModelPath, bn.ModelInfo.ID).
-			Context("interpreter_state", "initialized").
-			Build()
-
-		// Record error in metrics via span finish
+	results, err := bn.pool.predict(ctx, sample[0], bn.invokeBatch)
+	if err != nil {
 		span.SetTag("error", "true")
-		span.SetData("error_type", "input_tensor_nil")
 
 		// Record error in metrics directly
 		if globalMetrics != nil {
@@ -66,87 +59,100 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 		return nil, err
 	}
 
-	// Preparing input tensor with the sample data
-	copy(inputTensor.Float32s(), sample[0])
+	// Log prediction timing for performance monitoring
+	duration := time.Since(start)
+	bn.Debug("Prediction completed in %v with %d results", duration, len(results))
+
+	// Record metrics
+	span.SetData("total_duration_ms", duration.Milliseconds())
+	span.SetData("result_count", len(results))
+	span.SetTag("error", "false")
 
-	// DEBUG: Log the length of the sample data
-	//log.Printf("Invoking tensor with sample length: %d", len(sample[0]))
+	// The span.Finish() will automatically record the prediction metrics
+
+	// Return the top 10 results
+	return results, nil
+}
+
+// invokeBatch analyzes a batch of chunks on an interpreter of the inference pool and sets
+// the top 10 results of each chunk. Slots of the batch without a chunk are analyzed as
+// silence.
+func (bn *BirdNET) invokeBatch(w *inferenceWorker, batch []*predictRequest) {
+	start := time.Now()
+	fail := func(err error) {
+		for _, req := range batch {
+			req.err = err
+		}
+	}
+
+	// Get the input tensor from the interpreter
+	inputTensor := w.interpreter.GetInputTensor(0)
+	if inputTensor == nil {
+		fail(errors.New(fmt.Errorf("cannot get input tensor")).
+			Category(errors.CategoryModelInit).
+			ModelContext(bn.Settings.BirdNET.ModelPath, bn.ModelInfo.ID).
+			Context("interpreter_state", "initialized").
+			Build())
+		return
+	}
+
+	// Preparing input tensor with the sample data, one chunk per batch slot
+	input := inputTensor.Float32s()
+	chunkSize := len(input) / w.batch
+	for i := range w.batch {
+		chunk := input[i*chunkSize : (i+1)*chunkSize]
+		if i < len(batch) {
+			chunk = chunk[copy(chunk, batch[i].sample):]
+		}
+		clear(chunk)
+	}
 
 	// Invoke the interpreter to perform inference
 	invokeStart := time.Now()
-	if status := bn.AnalysisInterpreter.Invoke(); status != tflite.OK {
-		err := errors.Newf("tensor invoke failed: %v", status).
+	if status := w.interpreter.Invoke(); status != tflite.OK {
+		fail(errors.Newf("tensor invoke failed: %v", status).
 			Category(errors.CategoryAudio).
 			ModelContext(bn.Settings.BirdNET.ModelPath, bn.ModelInfo.ID).
-			Context("sample_length", len(sample[0])).
+			Context("sample_length", len(batch[0].sample)).
+			Context("batch_size", len(batch)).
 			Context("status_code", status).
 			Timing("prediction-invoke", time.Since(start)).
-			Build()
-
-		span.SetTag("error", "true")
-		span.SetData("error_type", "invoke_failed")
-		span.SetData("status_code", status)
-
-		// Record error in metrics
-		if globalMetrics != nil {
-			globalMetrics.RecordPrediction(bn.ModelInfo.ID, time.Since(start).Seconds(), err)
-		}
-
-		return nil, err
+			Build())
+		return
 	}
 
-	invokeDuration := time.Since(invokeStart)
-	span.SetData("invoke_duration_ms", invokeDuration.Milliseconds())
-
 	// Record model invoke timing separately
 	if globalMetrics != nil {
-		globalMetrics.RecordModelInvoke(bn.ModelInfo.ID, invokeDuration.Seconds())
+		globalMetrics.RecordModelInvoke(bn.ModelInfo.ID, time.Since(invokeStart).Seconds())
+		globalMetrics.RecordInferenceBatch(len(batch))
 	}
 
-	// Read the results from the output tensor
-	outputTensor := bn.AnalysisInterpreter.GetOutputTensor(0)
-	predictions := extractPredictions(outputTensor)
+	// Read the results of each chunk from the output tensor
+	outputTensor := w.interpreter.GetOutputTensor(0)
+	output := outputTensor.Float32s()
+	classes := outputTensor.Dim(outputTensor.NumDims() - 1)
+	for i, req := range batch {
+		predictions := output[i*classes : (i+1)*classes]
 
-	// Use optimized sigmoid function with buffer reuse
-	confidence := applySigmoidToPredictionsReuse(predictions, bn.Settings.BirdNET.Sensitivity, bn.confidenceBuffer)
+		// Use optimized sigmoid function with buffer reuse
+		confidence := applySigmoidToPredictionsReuse(predictions, bn.Settings.BirdNET.Sensitivity, w.confidence)
 
-	// Use the pre-allocated buffer to reduce memory allocations
-	results, err := pairLabelsAndConfidenceReuse(bn.Settings.BirdNET.Labels, confidence, bn.resultsBuffer)
-	if err != nil {
-		err = errors.New(err).
-			Category(errors.CategoryValidation).
-			Context("label_count", len(bn.Settings.BirdNET.Labels)).
-			Context("confidence_count", len(confidence)).
-			Timing("prediction-total", time.Since(start)).
-			Build()
-
-		span.SetTag("error", "true")
-		span.SetData("error_type", "label_mismatch")
-
-		// Record error in metrics
-		if globalMetrics != nil {
-			globalMetrics.RecordPrediction(bn.ModelInfo.ID, time.Since(start).Seconds(), err)
+		// Use the pre-allocated buffer to reduce memory allocations
+		results, err := pairLabelsAndConfidenceReuse(bn.Settings.BirdNET.Labels, confidence, w.results)
+		if err != nil {
+			req.err = errors.New(err).
+				Category(errors.CategoryValidation).
+				Context("label_count", len(bn.Settings.BirdNET.Labels)).
+				Context("confidence_count", len(confidence)).
+				Timing("prediction-total", time.Since(start)).
+				Build()
+			continue
 		}
 
-		return nil, err
+		// Use optimized top-k algorithm instead of full sort + trim, copied out of the buffer
+		// the next invoke of the interpreter reuses
+		req.results = slices.Clone(getTopKResults(results, 10))
 	}
-
-	// Use optimized top-k algorithm instead of full sort + trim
-	topResults := getTopKResults(results, 10)
-
-	// Log prediction timing for performance monitoring
-	duration := time.Since(start)
-	bn.Debug("Prediction completed in %v with %d results", duration, len(topResults))
-
-	// Record metrics
-	span.SetData("total_duration_ms", duration.Milliseconds())
-	span.SetData("result_count", len(topResults))
-	span.SetTag("error", "false")
-
-	// The span.Finish() will automatically record the prediction metrics
-
-	// Return the top 10 results
-	return topResults, nil
 }
 
 // AnalyzeAudio processes audio data in chunks and predicts species using the BirdNET model.
@@ -165,13 +171,24 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 		}
 
 		observations = append(observations, chunkResults...)
-		predStart += 3.0 - bn.Settings.BirdNET.Overlap // Adjust for overlap.
+		predStart += conf.AnalysisHop(bn.Settings.BirdNET.Overlap) // Adjust for overlap.
 	}
 
 	fmt.Printf("\r\033[KAnalysis completed in %s\n", FormatDuration(time.Since(startTime)))
 	return observations, nil
 }*/
 
+// WindowDuration returns the length of audio the model analyzes at once
+func (bn *BirdNET) WindowDuration() time.Duration {
+	return conf.AnalysisWindow
+}
+
+// HopDuration returns the time between the starts of consecutive analysis windows with
+// the configured overlap
+func (bn *BirdNET) HopDuration() time.Duration {
+	return conf.AnalysisHopDuration(bn.Settings.BirdNET.Overlap)
+}
+
 // processChunk handles the prediction for a single chunk of audio data.
 func (bn *BirdNET) ProcessChunk(chunk []float32, predStart time.Time) ([]datastore.Note, error) {
 	return bn.ProcessChunkWithContext(context.Background(), chunk, predStart)
@@ -198,7 +215,7 @@ func (bn *BirdNET) ProcessChunkWithContext(ctx context.Context, chunk []float32,
 	}
 
 	// calculate predEnd time based on settings.BirdNET.Overlap
-	predEnd := predStart.Add(time.Duration((3.0 - bn.Settings.BirdNET.Overlap) * float64(time.Second)))
+	predEnd := predStart.Add(bn.HopDuration())
 
 	var source = ""
 	var clipName = ""
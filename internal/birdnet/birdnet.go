@@ -17,11 +17,10 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/cpuspec"
-	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/resources"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
 	tflite "github.com/tphakala/go-tflite"
-	"github.com/tphakala/go-tflite/delegates/xnnpack"
 )
 
 // Default model version for the embedded model
@@ -46,9 +45,8 @@ type BirdNET struct {
 	TaxonomyMap         TaxonomyMap         // Mapping of species codes to names and vice versa
 	ScientificIndex     ScientificNameIndex // Index for fast scientific name lookups
 	TaxonomyPath        string              // Path to custom taxonomy file, if used
-	mu                  sync.Mutex
-	resultsBuffer       []datastore.Results // Pre-allocated buffer for results to reduce allocations
-	confidenceBuffer    []float32           // Pre-allocated buffer for confidence values to reduce allocations
+	mu                  sync.RWMutex        // Held for reading by predictions and for writing by model reloads
+	pool                *inferencePool      // Analysis interpreters, AnalysisInterpreter is the first of them
 	
 	// Species occurrence cache to avoid repeated GetProbableSpecies calls within same day
 	speciesCacheMu      sync.RWMutex
@@ -174,38 +172,14 @@ func (bn *BirdNET) initializeModel() error {
 	// Determine the number of threads for the interpreter based on settings and system capacity.
 	threads := bn.determineThreadCount(bn.Settings.BirdNET.Threads)
 
-	// Configure interpreter options.
-	options := tflite.NewInterpreterOptions()
-
-	// Try to use XNNPACK delegate if enabled in settings
-	if bn.Settings.BirdNET.UseXNNPACK {
-		delegate := xnnpack.New(xnnpack.DelegateOptions{NumThreads: int32(max(1, threads-1))}) //nolint:gosec // G115: thread count bounded by CPU count, safe conversion
-		if delegate == nil {
-			fmt.Println("⚠️ Failed to create XNNPACK delegate, falling back to default CPU")
-			fmt.Println("Please download updated tensorflow lite C API library from:")
-			fmt.Println("https://github.com/tphakala/tflite_c/releases/tag/v2.17.1")
-			fmt.Println("and install it to enable use of XNNPACK delegate")
-			options.SetNumThread(threads)
-		} else {
-			options.AddDelegate(delegate)
-			options.SetNumThread(1)
-		}
-	} else {
-		options.SetNumThread(threads)
-	}
-
-	options.SetErrorReporter(func(msg string, user_data interface{}) {
-		fmt.Println(msg)
-	}, nil)
-
-	// Create and allocate the TensorFlow Lite interpreter.
-	bn.AnalysisInterpreter = tflite.NewInterpreter(model, options)
-	if bn.AnalysisInterpreter == nil {
-		return fmt.Errorf("cannot create interpreter")
-	}
-	if status := bn.AnalysisInterpreter.AllocateTensors(); status != tflite.OK {
-		return fmt.Errorf("tensor allocation failed")
+	// Create and allocate the TensorFlow Lite interpreters, which share the threads.
+	workers, err := newInferenceWorkers(model, bn.Settings.BirdNET.UseXNNPACK, threads,
+		bn.Settings.BirdNET.Scheduler.Workers, bn.Settings.BirdNET.Scheduler.BatchSize)
+	if err != nil {
+		return err
 	}
+	bn.pool = newInferencePool(workers)
+	bn.AnalysisInterpreter = workers[0].interpreter
 	
 	// Force garbage collection to reclaim memory from model loading
 	// The model data is no longer needed as TFLite has created its own internal copy
@@ -233,11 +207,14 @@ func (bn *BirdNET) initializeModel() error {
 				modelVersion, threads, spec.PerformanceCores, runtime.NumCPU())
 		} else {
 			initMessage = fmt.Sprintf("%s model initialized, using %v threads of available %v CPUs",
-				modelVersion, threads, runtime.NumCPU())
+				modelVersion, threads, resources.Detect().CPUs)
 		}
 	} else {
 		initMessage = fmt.Sprintf("%s model initialized, using configured %v threads of available %v CPUs",
-			modelVersion, threads, runtime.NumCPU())
+			modelVersion, threads, resources.Detect().CPUs)
+	}
+	if len(workers) > 1 || bn.pool.batchSize() > 1 {
+		initMessage += fmt.Sprintf(", %d interpreters analyzing up to %d chunks per invoke", len(workers), bn.pool.batchSize())
 	}
 	fmt.Println(initMessage)
 	return nil
@@ -372,8 +349,9 @@ func (bn *BirdNET) initializeMetaModel() error {
 }
 
 // determineThreadCount calculates the appropriate number of threads to use based on settings and system capabilities.
+// Inside a container the CPU count honors the cgroup CPU quota rather than the host core count.
 func (bn *BirdNET) determineThreadCount(configuredThreads int) int {
-	systemCpuCount := runtime.NumCPU()
+	systemCpuCount := resources.Detect().CPUs
 
 	// If threads are configured to 0, try to get optimal count from cpuspec
 	if configuredThreads == 0 {
@@ -623,7 +601,9 @@ func (bn *BirdNET) getCachedSpeciesScores(targetDate time.Time) (map[string]floa
 
 // Delete releases resources used by the TensorFlow Lite interpreters.
 func (bn *BirdNET) Delete() {
-	if bn.AnalysisInterpreter != nil {
+	if bn.pool != nil {
+		bn.pool.delete()
+	} else if bn.AnalysisInterpreter != nil {
 		bn.AnalysisInterpreter.Delete()
 	}
 	if bn.RangeInterpreter != nil {
@@ -859,15 +839,8 @@ func (bn *BirdNET) validateModelAndLabels() error {
 			Build()
 	}
 
-	// Pre-allocate results buffer with the model's output size
-	if bn.resultsBuffer == nil || len(bn.resultsBuffer) != modelOutputSize {
-		bn.resultsBuffer = make([]datastore.Results, modelOutputSize)
-	}
-
-	// Pre-allocate confidence buffer with the model's output size
-	if bn.confidenceBuffer == nil || len(bn.confidenceBuffer) != modelOutputSize {
-		bn.confidenceBuffer = make([]float32, modelOutputSize)
-	}
+	// Pre-allocate the results and confidence buffers of each interpreter with the model's output size
+	bn.pool.allocateBuffers(modelOutputSize)
 
 	bn.Debug("\033[32m✅ Model validation successful: %d labels match model output size\033[0m", modelOutputSize)
 	return nil
@@ -882,6 +855,7 @@ func (bn *BirdNET) ReloadModel() error {
 
 	// Store old interpreters to clean up after successful reload
 	oldAnalysisInterpreter := bn.AnalysisInterpreter
+	oldPool := bn.pool
 	oldRangeInterpreter := bn.RangeInterpreter
 
 	// Re-determine model info if using a custom model path
@@ -909,12 +883,11 @@ func (bn *BirdNET) ReloadModel() error {
 
 	// Initialize new meta model
 	if err := bn.initializeMetaModel(); err != nil {
-		// Clean up the newly created analysis interpreter if meta model fails
-		if bn.AnalysisInterpreter != nil {
-			bn.AnalysisInterpreter.Delete()
-		}
+		// Clean up the newly created analysis interpreters if meta model fails
+		bn.pool.delete()
 		// Restore the old interpreters
 		bn.AnalysisInterpreter = oldAnalysisInterpreter
+		bn.pool = oldPool
 		bn.RangeInterpreter = oldRangeInterpreter
 		return fmt.Errorf("\033[31m❌ failed to reload meta model: %w\033[0m", err)
 	}
@@ -923,14 +896,13 @@ func (bn *BirdNET) ReloadModel() error {
 	// Reload labels
 	if err := bn.loadLabels(); err != nil {
 		// Clean up the newly created interpreters if label loading fails
-		if bn.AnalysisInterpreter != nil {
-			bn.AnalysisInterpreter.Delete()
-		}
+		bn.pool.delete()
 		if bn.RangeInterpreter != nil {
 			bn.RangeInterpreter.Delete()
 		}
 		// Restore the old interpreters
 		bn.AnalysisInterpreter = oldAnalysisInterpreter
+		bn.pool = oldPool
 		bn.RangeInterpreter = oldRangeInterpreter
 		return fmt.Errorf("\033[31m❌ failed to reload labels: %w\033[0m", err)
 	}
@@ -939,20 +911,21 @@ func (bn *BirdNET) ReloadModel() error {
 	// Validate that the model and labels match
 	if err := bn.validateModelAndLabels(); err != nil {
 		// Clean up the newly created interpreters if validation fails
-		if bn.AnalysisInterpreter != nil {
-			bn.AnalysisInterpreter.Delete()
-		}
+		bn.pool.delete()
 		if bn.RangeInterpreter != nil {
 			bn.RangeInterpreter.Delete()
 		}
 		// Restore the old interpreters
 		bn.AnalysisInterpreter = oldAnalysisInterpreter
+		bn.pool = oldPool
 		bn.RangeInterpreter = oldRangeInterpreter
 		return fmt.Errorf("\033[31m❌ model validation failed: %w\033[0m", err)
 	}
 
 	// Clean up old interpreters after successful reload
-	if oldAnalysisInterpreter != nil {
+	if oldPool != nil {
+		oldPool.delete()
+	} else if oldAnalysisInterpreter != nil {
 		oldAnalysisInterpreter.Delete()
 	}
 	if oldRangeInterpreter != nil {
@@ -971,6 +944,11 @@ func (bn *BirdNET) GetSpeciesCode(label string) (string, bool) {
 	return GetSpeciesCodeFromName(bn.TaxonomyMap, bn.ScientificIndex, label)
 }
 
+// GetSpeciesNameFromCode returns the "Scientific_Common" eBird name for a species code
+func (bn *BirdNET) GetSpeciesNameFromCode(code string) (string, bool) {
+	return GetSpeciesNameFromCode(bn.TaxonomyMap, code)
+}
+
 // GetSpeciesWithScientificAndCommonName returns the scientific name and common name for a label
 func (bn *BirdNET) GetSpeciesWithScientificAndCommonName(label string) (scientific, common string) {
 	return SplitSpeciesName(label)
@@ -0,0 +1,76 @@
+// common_names.go contains the lookup of localized species common names
+package birdnet
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// CommonNames maps scientific names to the common names of one locale
+type CommonNames struct {
+	Locale string            // Locale of the common names
+	names  map[string]string // Common names by lowercase scientific name
+}
+
+// commonNamesCache holds the common names loaded per locale
+var commonNamesCache sync.Map // locale -> *CommonNames
+
+// LoadCommonNames loads the common names of a locale from the embedded label files of the
+// default model. Unsupported locales fall back to English.
+func LoadCommonNames(locale string) (*CommonNames, error) {
+	normalized, err := conf.NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := commonNamesCache.Load(normalized); ok {
+		return cached.(*CommonNames), nil
+	}
+
+	data, err := GetLabelFileData(DefaultModelVersion, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	names := &CommonNames{Locale: normalized, names: make(map[string]string, GetExpectedLinesV24())}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		scientific, common, found := strings.Cut(strings.TrimSpace(scanner.Text()), "_")
+		if found && scientific != "" && common != "" {
+			names.names[strings.ToLower(scientific)] = common
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	cached, _ := commonNamesCache.LoadOrStore(normalized, names)
+	return cached.(*CommonNames), nil
+}
+
+// Lookup returns the common name of a species, or fallback when the locale has no name for it
+func (c *CommonNames) Lookup(scientificName, fallback string) string {
+	if c == nil {
+		return fallback
+	}
+	if name, ok := c.names[strings.ToLower(strings.TrimSpace(scientificName))]; ok {
+		return name
+	}
+	return fallback
+}
+
+// LocalizedCommonName returns the common name of a species in the configured UI locale. The
+// fallback is returned when no UI locale is configured or the locale has no name for the species.
+func LocalizedCommonName(settings *conf.Settings, scientificName, fallback string) string {
+	if settings == nil || settings.UI.Locale == "" {
+		return fallback
+	}
+	names, err := LoadCommonNames(settings.UI.Locale)
+	if err != nil {
+		return fallback
+	}
+	return names.Lookup(scientificName, fallback)
+}
@@ -0,0 +1,38 @@
+package birdnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestLoadCommonNames(t *testing.T) {
+	t.Parallel()
+
+	names, err := LoadCommonNames("de")
+	require.NoError(t, err)
+	assert.Equal(t, "de", names.Locale)
+	assert.Equal(t, "Amsel", names.Lookup("Turdus merula", "Eurasian Blackbird"))
+	assert.Equal(t, "Amsel", names.Lookup("turdus merula ", "Eurasian Blackbird"), "lookup ignores case and whitespace")
+	assert.Equal(t, "Unknown bird", names.Lookup("Avis ignota", "Unknown bird"))
+
+	again, err := LoadCommonNames("German")
+	require.NoError(t, err)
+	assert.Same(t, names, again, "names are loaded once per locale")
+
+	_, err = LoadCommonNames("xx")
+	assert.Error(t, err)
+}
+
+func TestLocalizedCommonName(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	assert.Equal(t, "Eurasian Blackbird", LocalizedCommonName(settings, "Turdus merula", "Eurasian Blackbird"), "no UI locale keeps the stored name")
+
+	settings.UI.Locale = "fi"
+	assert.Equal(t, "mustarastas", LocalizedCommonName(settings, "Turdus merula", "Eurasian Blackbird"))
+	assert.Equal(t, "Eurasian Blackbird", LocalizedCommonName(nil, "Turdus merula", "Eurasian Blackbird"))
+}
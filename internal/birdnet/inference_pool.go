@@ -0,0 +1,295 @@
+// inference_pool.go: analysis interpreters running BirdNET inference in parallel
+package birdnet
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	tflite "github.com/tphakala/go-tflite"
+	"github.com/tphakala/go-tflite/delegates/xnnpack"
+)
+
+// inferenceWorker is an analysis interpreter with its own input and result buffers. With a
+// batch above one the input tensor holds several chunks, which one invoke analyzes together.
+type inferenceWorker struct {
+	interpreter *tflite.Interpreter
+	batch       int                 // chunks analyzed per invoke
+	confidence  []float32           // pre-allocated buffer for confidence values of a chunk
+	results     []datastore.Results // pre-allocated buffer for results of a chunk
+}
+
+// predictRequest is a chunk waiting for or in inference
+type predictRequest struct {
+	sample  []float32
+	results []datastore.Results
+	err     error
+	done    chan struct{} // closed when results or err are set
+}
+
+// inferencePool shares the analysis interpreters between concurrent predictions. A
+// prediction queues its chunk and waits for either its results or an idle interpreter. The
+// caller that takes an idle interpreter analyzes as many waiting chunks as the interpreter
+// batch holds, its own or those of other callers, so chunks arriving together share an invoke.
+type inferencePool struct {
+	workers []*inferenceWorker
+	idle    chan *inferenceWorker // interpreters not running inference
+
+	mu      sync.Mutex
+	pending []*predictRequest // chunks waiting for an interpreter, oldest first
+
+	busy atomic.Int32 // interpreters running inference
+}
+
+// newInferencePool returns a pool of the given interpreters, all idle
+func newInferencePool(workers []*inferenceWorker) *inferencePool {
+	p := &inferencePool{
+		workers: workers,
+		idle:    make(chan *inferenceWorker, len(workers)),
+	}
+	for _, w := range workers {
+		p.idle <- w
+	}
+	return p
+}
+
+// newInferenceWorkers creates count interpreters of model sharing threads CPU threads. When
+// batch is above one the interpreters analyze batch chunks per invoke, unless the model does
+// not accept a batch dimension, then each invoke analyzes one chunk.
+func newInferenceWorkers(model *tflite.Model, useXNNPACK bool, threads, count, batch int) ([]*inferenceWorker, error) {
+	count = max(count, 1)
+	threadsPerWorker := max(threads/count, 1)
+
+	workers := make([]*inferenceWorker, 0, count)
+	for range count {
+		interpreter := tflite.NewInterpreter(model, newInterpreterOptions(useXNNPACK, threadsPerWorker))
+		if interpreter == nil {
+			deleteWorkers(workers)
+			return nil, fmt.Errorf("cannot create interpreter")
+		}
+		if status := interpreter.AllocateTensors(); status != tflite.OK {
+			interpreter.Delete()
+			deleteWorkers(workers)
+			return nil, fmt.Errorf("tensor allocation failed")
+		}
+
+		worker := &inferenceWorker{interpreter: interpreter, batch: 1}
+		if batch > 1 {
+			batched, err := resizeInputBatch(interpreter, batch)
+			if err != nil {
+				interpreter.Delete()
+				deleteWorkers(workers)
+				return nil, err
+			}
+			if batched {
+				worker.batch = batch
+			}
+		}
+		workers = append(workers, worker)
+	}
+
+	if batch > 1 && workers[0].batch == 1 {
+		fmt.Println("⚠️ Model does not accept batched input, analyzing one chunk per invoke")
+	}
+	return workers, nil
+}
+
+// newInterpreterOptions returns interpreter options using threads CPU threads, on the XNNPACK
+// delegate if enabled and available
+func newInterpreterOptions(useXNNPACK bool, threads int) *tflite.InterpreterOptions {
+	options := tflite.NewInterpreterOptions()
+
+	// Try to use XNNPACK delegate if enabled in settings
+	if useXNNPACK {
+		delegate := xnnpack.New(xnnpack.DelegateOptions{NumThreads: int32(max(1, threads-1))}) //nolint:gosec // G115: thread count bounded by CPU count, safe conversion
+		if delegate == nil {
+			fmt.Println("⚠️ Failed to create XNNPACK delegate, falling back to default CPU")
+			fmt.Println("Please download updated tensorflow lite C API library from:")
+			fmt.Println("https://github.com/tphakala/tflite_c/releases/tag/v2.17.1")
+			fmt.Println("and install it to enable use of XNNPACK delegate")
+			options.SetNumThread(threads)
+		} else {
+			options.AddDelegate(delegate)
+			options.SetNumThread(1)
+		}
+	} else {
+		options.SetNumThread(threads)
+	}
+
+	options.SetErrorReporter(func(msg string, user_data interface{}) {
+		fmt.Println(msg)
+	}, nil)
+
+	return options
+}
+
+// resizeInputBatch resizes the input of an allocated interpreter to hold batch chunks. It
+// reports false and restores the single chunk input when the model output does not follow
+// the batch dimension.
+func resizeInputBatch(interpreter *tflite.Interpreter, batch int) (bool, error) {
+	input := interpreter.GetInputTensor(0)
+	if input == nil || input.NumDims() < 2 {
+		return false, nil
+	}
+	dims := make([]int32, input.NumDims())
+	for i := range dims {
+		dims[i] = int32(input.Dim(i)) //nolint:gosec // G115: tensor dimensions fit in int32
+	}
+
+	dims[0] = int32(batch) //nolint:gosec // G115: batch size is validated to be small
+	if interpreter.ResizeInputTensor(0, dims) == tflite.OK && interpreter.AllocateTensors() == tflite.OK {
+		if output := interpreter.GetOutputTensor(0); output != nil && output.NumDims() >= 2 && output.Dim(0) == batch {
+			return true, nil
+		}
+	}
+
+	dims[0] = 1
+	if interpreter.ResizeInputTensor(0, dims) != tflite.OK || interpreter.AllocateTensors() != tflite.OK {
+		return false, fmt.Errorf("tensor allocation failed after batch resize")
+	}
+	return false, nil
+}
+
+// deleteWorkers releases the interpreters of workers
+func deleteWorkers(workers []*inferenceWorker) {
+	for _, w := range workers {
+		if w.interpreter != nil {
+			w.interpreter.Delete()
+		}
+	}
+}
+
+// delete releases the interpreters of the pool. No prediction may be running.
+func (p *inferencePool) delete() {
+	deleteWorkers(p.workers)
+}
+
+// allocateBuffers sizes the result buffers of every interpreter for outputSize classes
+func (p *inferencePool) allocateBuffers(outputSize int) {
+	for _, w := range p.workers {
+		if len(w.results) != outputSize {
+			w.results = make([]datastore.Results, outputSize)
+		}
+		if len(w.confidence) != outputSize {
+			w.confidence = make([]float32, outputSize)
+		}
+	}
+}
+
+// batchSize returns the number of chunks an interpreter analyzes per invoke
+func (p *inferencePool) batchSize() int {
+	return p.workers[0].batch
+}
+
+// predict analyzes a chunk with run and returns its results. run analyzes a batch of chunks
+// on an interpreter, setting the results or error of each.
+func (p *inferencePool) predict(ctx context.Context, sample []float32, run func(w *inferenceWorker, batch []*predictRequest)) ([]datastore.Results, error) {
+	req := &predictRequest{sample: sample, done: make(chan struct{})}
+	p.enqueue(req)
+
+	for {
+		select {
+		case <-req.done:
+			return req.results, req.err
+		case w := <-p.idle:
+			p.runBatch(w, run)
+		case <-ctx.Done():
+			if p.cancel(req) {
+				return nil, ctx.Err()
+			}
+			// Another caller is analyzing the chunk
+			<-req.done
+			return req.results, req.err
+		}
+	}
+}
+
+// enqueue adds a chunk to the waiting chunks
+func (p *inferencePool) enqueue(req *predictRequest) {
+	p.mu.Lock()
+	p.pending = append(p.pending, req)
+	length := len(p.pending)
+	p.mu.Unlock()
+	p.updateQueueLength(length)
+}
+
+// cancel removes a waiting chunk, reporting false if it is already being analyzed
+func (p *inferencePool) cancel(req *predictRequest) bool {
+	p.mu.Lock()
+	index := slices.Index(p.pending, req)
+	if index >= 0 {
+		p.pending = slices.Delete(p.pending, index, index+1)
+	}
+	length := len(p.pending)
+	p.mu.Unlock()
+
+	if index < 0 {
+		return false
+	}
+	p.updateQueueLength(length)
+	return true
+}
+
+// take removes up to n of the oldest waiting chunks
+func (p *inferencePool) take(n int) []*predictRequest {
+	p.mu.Lock()
+	n = min(n, len(p.pending))
+	batch := slices.Clone(p.pending[:n])
+	p.pending = slices.Delete(p.pending, 0, n)
+	length := len(p.pending)
+	p.mu.Unlock()
+
+	if n > 0 {
+		p.updateQueueLength(length)
+	}
+	return batch
+}
+
+// runBatch analyzes the waiting chunks that fit the batch of w and returns w to the idle
+// interpreters. If run panics the chunks of the batch fail and the panic continues.
+func (p *inferencePool) runBatch(w *inferenceWorker, run func(w *inferenceWorker, batch []*predictRequest)) {
+	batch := p.take(w.batch)
+	defer func() { p.idle <- w }()
+	if len(batch) == 0 {
+		return
+	}
+
+	p.updateBusy(p.busy.Add(1))
+	defer func() {
+		p.updateBusy(p.busy.Add(-1))
+		r := recover()
+		for _, req := range batch {
+			if r != nil {
+				req.results = nil
+				req.err = errors.Newf("inference panicked: %v", r).
+					Component("birdnet").
+					Category(errors.CategorySystem).
+					Build()
+			}
+			close(req.done)
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	run(w, batch)
+}
+
+// updateQueueLength publishes the number of chunks waiting for an interpreter
+func (p *inferencePool) updateQueueLength(length int) {
+	if m := getMetrics(); m != nil {
+		m.SetInferenceQueueLength(length)
+	}
+}
+
+// updateBusy publishes the number of interpreters running inference
+func (p *inferencePool) updateBusy(count int32) {
+	if m := getMetrics(); m != nil {
+		m.SetInferenceWorkersBusy(int(count))
+	}
+}
@@ -0,0 +1,97 @@
+package birdnet
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// batchRecorder analyzes chunks by returning their first sample as the confidence and
+// records the size of each batch. The first batch waits until released.
+type batchRecorder struct {
+	mu      sync.Mutex
+	sizes   []int
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (r *batchRecorder) run(w *inferenceWorker, batch []*predictRequest) {
+	first := false
+	r.once.Do(func() { first = true })
+	if first {
+		close(r.started)
+		<-r.release
+	}
+	r.mu.Lock()
+	r.sizes = append(r.sizes, len(batch))
+	r.mu.Unlock()
+	for _, req := range batch {
+		req.results = []datastore.Results{{Species: "test", Confidence: req.sample[0]}}
+	}
+}
+
+func TestInferencePool_BatchesWaitingChunks(t *testing.T) {
+	t.Parallel()
+
+	pool := newInferencePool([]*inferenceWorker{{batch: 3}})
+	rec := &batchRecorder{started: make(chan struct{}), release: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	results := make([]float32, 5)
+	predict := func(i int) {
+		defer wg.Done()
+		res, err := pool.predict(context.Background(), []float32{float32(i)}, rec.run)
+		assert.NoError(t, err)
+		if assert.Len(t, res, 1) {
+			results[i] = res[0].Confidence
+		}
+	}
+
+	// The first chunk holds the only interpreter while the others queue
+	wg.Add(1)
+	go predict(0)
+	<-rec.started
+	for i := 1; i < 5; i++ {
+		wg.Add(1)
+		go predict(i)
+	}
+	require.Eventually(t, func() bool {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return len(pool.pending) == 4
+	}, 5*time.Second, time.Millisecond)
+	close(rec.release)
+	wg.Wait()
+
+	assert.Equal(t, []float32{0, 1, 2, 3, 4}, results, "each caller gets the results of its chunk")
+	assert.Equal(t, []int{1, 3, 1}, rec.sizes, "waiting chunks share an invoke up to the batch size")
+	assert.Len(t, pool.idle, 1, "the interpreter is idle again")
+}
+
+func TestInferencePool_CancelWaitingChunk(t *testing.T) {
+	t.Parallel()
+
+	pool := newInferencePool([]*inferenceWorker{{batch: 1}})
+	rec := &batchRecorder{started: make(chan struct{}), release: make(chan struct{})}
+
+	go func() {
+		_, _ = pool.predict(context.Background(), []float32{1}, rec.run)
+	}()
+	<-rec.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := pool.predict(ctx, []float32{2}, rec.run)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	pool.mu.Lock()
+	assert.Empty(t, pool.pending, "a canceled chunk leaves the queue")
+	pool.mu.Unlock()
+	close(rec.release)
+}
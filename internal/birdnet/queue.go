@@ -1,6 +1,7 @@
 package birdnet
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/datastore"
@@ -14,6 +15,38 @@ type Results struct {
 	ElapsedTime time.Duration            // Time taken for analysis
 	ClipName    string                   // Name of the audio clip
 	Source      datastore.AudioSource    // Audio source with ID, SafeString, and DisplayName
+	Detector    string                   // Additional detector that produced the results, empty for BirdNET
+	PCMRef      *PCMRef                  // Consumers of pooled PCMdata, nil when the audio is not pooled
+}
+
+// PCMRef counts the consumers of pooled audio shared by results items. The audio returns to
+// its pool when the last consumer releases it, so consumers keeping the audio after they
+// processed an item must copy it. All methods are safe on a nil reference.
+type PCMRef struct {
+	refs    atomic.Int32
+	release func()
+}
+
+// NewPCMRef returns a reference held by its creator, release is called once the creator and
+// all retaining consumers released it
+func NewPCMRef(release func()) *PCMRef {
+	r := &PCMRef{release: release}
+	r.refs.Store(1)
+	return r
+}
+
+// Retain adds a consumer of the audio
+func (r *PCMRef) Retain() {
+	if r != nil {
+		r.refs.Add(1)
+	}
+}
+
+// Release removes a consumer of the audio, returning it to its pool after the last one
+func (r *PCMRef) Release() {
+	if r != nil && r.refs.Add(-1) == 0 {
+		r.release()
+	}
 }
 
 // Default buffer size for the results queue
@@ -22,7 +55,8 @@ const DefaultQueueSize = 100
 // ResultsQueue is a channel for sending analysis results.
 // OWNERSHIP: Once a Results struct is sent to this queue, the sender must not
 // modify it. The receiver takes full ownership of the data. This allows us to
-// avoid unnecessary deep copies of the PCM audio data.
+// avoid unnecessary deep copies of the PCM audio data. Pooled PCM data is only
+// valid until the receiver releases PCMRef.
 var ResultsQueue = make(chan Results, DefaultQueueSize)
 
 // Copy creates a deep copy of the Results struct.
@@ -36,6 +70,7 @@ func (r Results) Copy() Results { //nolint:gocritic // This is a copy function,
 		ElapsedTime: r.ElapsedTime,
 		ClipName:    r.ClipName,
 		Source:      r.Source,
+		Detector:    r.Detector,
 	}
 
 	// Deep copy PCMdata
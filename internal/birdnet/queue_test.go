@@ -0,0 +1,28 @@
+package birdnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPCMRef(t *testing.T) {
+	t.Parallel()
+
+	released := 0
+	ref := NewPCMRef(func() { released++ })
+
+	// Shared with two pipelines, the creator releases its reference after handing it out
+	ref.Retain()
+	ref.Retain()
+	ref.Release()
+	ref.Release()
+	assert.Equal(t, 0, released, "audio is in use until the last consumer releases it")
+	ref.Release()
+	assert.Equal(t, 1, released)
+
+	// Audio that is not pooled has no reference
+	var unpooled *PCMRef
+	unpooled.Retain()
+	unpooled.Release()
+}
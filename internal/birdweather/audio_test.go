@@ -278,7 +278,7 @@ func TestEncodeFlacUsingFFmpeg(t *testing.T) {
 	// Encode PCM to FLAC with normalization
 	// Pass a background context since this test doesn't need timeout control itself
 	ctx := context.Background()
-	flacBuffer, err := encodeFlacUsingFFmpeg(ctx, pcmData, ffmpegPathForTest, settings)
+	flacBuffer, err := encodeFlacUsingFFmpeg(ctx, serviceLogger, pcmData, ffmpegPathForTest, settings, "")
 	if err != nil {
 		t.Errorf("encodeFlacUsingFFmpeg failed with valid input: %v", err)
 		return
@@ -309,6 +309,38 @@ func TestEncodeFlacUsingFFmpeg(t *testing.T) {
 	t.Logf("Successfully encoded PCM to normalized FLAC, size: %d bytes", flacBuffer.Len())
 }
 
+func TestEncodeFlacNormalizationModes(t *testing.T) {
+	if !conf.IsFfmpegAvailable() {
+		t.Skip("FFmpeg not available, skipping FLAC normalization test")
+	}
+
+	// 3 seconds of a quiet 2 kHz tone, long enough for the dynamic mode of loudnorm
+	sampleCount := conf.SampleRate * 3
+	pcmData := make([]byte, sampleCount*2)
+	for i := range sampleCount {
+		value := int16(1000.0 * math.Sin(2.0*math.Pi*2000.0*float64(i)/float64(conf.SampleRate)))
+		binary.LittleEndian.PutUint16(pcmData[i*2:], uint16(value)) //nolint:gosec // G115: audio sample conversion within 16-bit range
+	}
+
+	for _, mode := range []string{conf.NormalizationTwoPass, conf.NormalizationSinglePass, conf.NormalizationCached} {
+		t.Run(mode, func(t *testing.T) {
+			settings := &conf.Settings{}
+			settings.Realtime.Birdweather.Normalization = mode
+
+			// The cached mode analyzes the first soundscape of a source and reuses its gain
+			for range 2 {
+				flacBuffer, err := encodeFlacUsingFFmpeg(context.Background(), serviceLogger, pcmData, getFFmpegPath(), settings, "test_"+mode)
+				if err != nil {
+					t.Fatalf("encodeFlacUsingFFmpeg failed: %v", err)
+				}
+				if !bytes.HasPrefix(flacBuffer.Bytes(), []byte("fLaC")) {
+					t.Fatal("FLAC signature not found")
+				}
+			}
+		})
+	}
+}
+
 func getFFmpegPath() string {
 	// Try to get FFmpeg path from environment variable first
 	path := os.Getenv("FFMPEG_PATH")
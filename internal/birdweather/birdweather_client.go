@@ -18,22 +18,24 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
 	"github.com/tphakala/birdnet-go/internal/logging" // Import the new logging package
 	"github.com/tphakala/birdnet-go/internal/myaudio"
 )
 
-// Package-level logger specific to birdweather service
+// Package-level file logger, the default log sink of clients created without a logger
 var (
 	serviceLogger   *slog.Logger
 	serviceLevelVar = new(slog.LevelVar) // Dynamic level control
-	closeLogger     func() error
 )
 
 func init() {
@@ -45,23 +47,29 @@ func init() {
 
 	// Initialize the service-specific file logger
 	// Using Debug level for file logging to capture more detail
-	serviceLogger, closeLogger, err = logging.NewFileLogger(logFilePath, "birdweather", serviceLevelVar)
+	// The log file is owned by the logging registry and closed by logging.CloseAll on shutdown,
+	// clients share the package logger so closing a client must not close it
+	serviceLogger, _, err = logging.NewFileLogger(logFilePath, "birdweather", serviceLevelVar)
 	if err != nil {
 		// Fallback: Log error to standard log and potentially disable service logging
 		log.Printf("FATAL: Failed to initialize birdweather file logger at %s: %v. Service logging disabled.", logFilePath, err)
 		// Set logger to a disabled handler to prevent nil panics, but respects level var
 		fbHandler := slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: serviceLevelVar})
 		serviceLogger = slog.New(fbHandler).With("service", "birdweather")
-		closeLogger = func() error { return nil } // No-op closer
 		// Consider whether to panic or continue without file logging
 		// panic(fmt.Sprintf("Failed to initialize birdweather file logger: %v", err))
 	}
+
+	debugcapture.Register(debugcapture.ComponentBirdWeather, serviceLevelVar)
 }
 
 // targetIntegratedLoudnessLUFS defines the target loudness for normalization.
 // EBU R128 standard target is -23 LUFS.
 const targetIntegratedLoudnessLUFS = -23.0
 
+// targetTruePeakDBTP limits the gain of soundscapes normalized without FFmpeg
+const targetTruePeakDBTP = -2.0
+
 // SoundscapeResponse represents the JSON structure of the response from the Birdweather API when uploading a soundscape.
 type SoundscapeResponse struct {
 	Success    bool `json:"success"`
@@ -84,6 +92,17 @@ type BwClient struct {
 	Latitude      float64
 	Longitude     float64
 	HTTPClient    *http.Client
+	Logger        *slog.Logger // Log sink of the client, the package file logger when nil
+
+	breaker circuitBreaker // pauses uploads while BirdWeather is unreachable
+}
+
+// logger returns the log sink of the client
+func (b *BwClient) logger() *slog.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
+	return serviceLogger
 }
 
 // maskURL masks sensitive BirdWeatherID tokens in URLs for safe logging
@@ -103,10 +122,29 @@ type Interface interface {
 	Close()
 }
 
-// New creates and initializes a new BwClient with the given settings.
-// The HTTP client is configured with a 45-second timeout to prevent hanging requests.
+// New creates and initializes a new BwClient with the given settings, logging to the
+// package file logger. The HTTP client is configured with a 45-second timeout to prevent
+// hanging requests and uses the shared outbound proxy and TLS settings.
 func New(settings *conf.Settings) (*BwClient, error) {
-	serviceLogger.Info("Creating new BirdWeather client")
+	return NewWithLogger(settings, nil)
+}
+
+// NewWithLogger creates a new BwClient like New that logs to the given logger, or to the
+// package file logger when nil, so clients running side by side keep separate log sinks.
+func NewWithLogger(settings *conf.Settings, logger *slog.Logger) (*BwClient, error) {
+	if logger == nil {
+		logger = serviceLogger
+	}
+	logger.Info("Creating new BirdWeather client")
+	httpClient, err := httpclient.New(settings, httpclient.Options{
+		Integration:        "birdweather",
+		Timeout:            45 * time.Second,
+		UserAgent:          "BirdNET-Go",
+		InsecureSkipVerify: settings.OutboundTLS.BirdWeather.InsecureSkipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
 	// We expect that Birdweather ID is validated before this function is called
 	client := &BwClient{
 		Settings:      settings,
@@ -114,7 +152,17 @@ func New(settings *conf.Settings) (*BwClient, error) {
 		Accuracy:      settings.Realtime.Birdweather.LocationAccuracy,
 		Latitude:      settings.BirdNET.Latitude,
 		Longitude:     settings.BirdNET.Longitude,
-		HTTPClient:    &http.Client{Timeout: 45 * time.Second},
+		HTTPClient:    httpClient,
+		Logger:        logger,
+	}
+
+	// Record recent requests and responses for the diagnostics API when enabled
+	recorderSettings := settings.Realtime.Birdweather.Recorder
+	if !recorderSettings.Enabled {
+		configureRecorder(0, 0, "")
+	} else if r := configureRecorder(recorderSettings.Size, recorderSettings.MaxBodySize, client.BirdweatherID); r != nil {
+		client.HTTPClient.Transport = &recordingTransport{next: client.HTTPClient.Transport, recorder: r}
+		logger.Info("BirdWeather request recording enabled", "size", recorderSettings.Size)
 	}
 	return client, nil
 }
@@ -137,7 +185,7 @@ func (b *BwClient) RandomizeLocation(radiusMeters float64) (latitude, longitude
 	latitude = math.Floor((b.Latitude+latOffset)*10000) / 10000
 	longitude = math.Floor((b.Longitude+lonOffset)*10000) / 10000
 
-	serviceLogger.Debug("Randomized location",
+	b.logger().Debug("Randomized location",
 		"original_lat", b.Latitude, "original_lon", b.Longitude,
 		"radius_meters", radiusMeters,
 		"fuzzed_lat", latitude, "fuzzed_lon", longitude)
@@ -146,7 +194,7 @@ func (b *BwClient) RandomizeLocation(radiusMeters float64) (latitude, longitude
 }
 
 // handleNetworkError handles network errors and returns a more specific error message.
-func handleNetworkError(err error, url string, timeout time.Duration, operation string) *errors.EnhancedError {
+func handleNetworkError(logger *slog.Logger, err error, url string, timeout time.Duration, operation string) *errors.EnhancedError {
 	if err == nil {
 		return errors.New(fmt.Errorf("nil error")).
 			Component("birdweather").
@@ -157,7 +205,7 @@ func handleNetworkError(err error, url string, timeout time.Duration, operation
 	if errors.As(err, &netErr) && netErr.Timeout() {
 		// Create descriptive error message with operation context
 		descriptiveErr := fmt.Errorf("BirdWeather %s timeout: %w", operation, err)
-		serviceLogger.Warn("Network request timed out", "operation", operation, "error", err)
+		logger.Warn("Network request timed out", "operation", operation, "error", err)
 		return errors.New(descriptiveErr).
 			Component("birdweather").
 			Category(errors.CategoryNetwork).
@@ -171,7 +219,7 @@ func handleNetworkError(err error, url string, timeout time.Duration, operation
 		var dnsErr *net.DNSError
 		if errors.As(urlErr.Err, &dnsErr) {
 			descriptiveErr := fmt.Errorf("BirdWeather %s DNS resolution failed: %w", operation, err)
-			serviceLogger.Error("DNS resolution failed", "operation", operation, "url", url, "error", err)
+			logger.Error("DNS resolution failed", "operation", operation, "url", url, "error", err)
 			return errors.New(descriptiveErr).
 				Component("birdweather").
 				Category(errors.CategoryNetwork).
@@ -182,7 +230,7 @@ func handleNetworkError(err error, url string, timeout time.Duration, operation
 		}
 	}
 	descriptiveErr := fmt.Errorf("BirdWeather %s network error: %w", operation, err)
-	serviceLogger.Error("Network error occurred", "operation", operation, "error", err)
+	logger.Error("Network error occurred", "operation", operation, "error", err)
 	return errors.New(descriptiveErr).
 		Component("birdweather").
 		Category(errors.CategoryNetwork).
@@ -261,12 +309,12 @@ func extractHTMLError(htmlContent string) string {
 }
 
 // handleHTTPResponse processes HTTP response and handles both JSON and HTML responses
-func handleHTTPResponse(resp *http.Response, expectedStatus int, operation, maskedURL string) ([]byte, error) {
+func handleHTTPResponse(logger *slog.Logger, resp *http.Response, expectedStatus int, operation, maskedURL string) ([]byte, error) {
 	// Check status code first
 	if resp.StatusCode != expectedStatus {
 		responseBody, readErr := io.ReadAll(resp.Body)
 		if readErr != nil {
-			serviceLogger.Error("Failed to read response body after non-expected status",
+			logger.Error("Failed to read response body after non-expected status",
 				"operation", operation,
 				"url", maskedURL,
 				"expected_status", expectedStatus,
@@ -278,7 +326,7 @@ func handleHTTPResponse(resp *http.Response, expectedStatus int, operation, mask
 		// Check if response is HTML
 		if isHTMLResponse(resp) {
 			htmlError := extractHTMLError(string(responseBody))
-			serviceLogger.Error("Received HTML error response instead of JSON",
+			logger.Error("Received HTML error response instead of JSON",
 				"operation", operation,
 				"url", maskedURL,
 				"status_code", resp.StatusCode,
@@ -303,7 +351,7 @@ func handleHTTPResponse(resp *http.Response, expectedStatus int, operation, mask
 
 		// Not HTML, return the raw response
 		err := fmt.Errorf("%s failed with status %d: %s", operation, resp.StatusCode, string(responseBody))
-		serviceLogger.Error("Request failed with non-expected status",
+		logger.Error("Request failed with non-expected status",
 			"operation", operation,
 			"url", maskedURL,
 			"expected_status", expectedStatus,
@@ -320,7 +368,7 @@ func handleHTTPResponse(resp *http.Response, expectedStatus int, operation, mask
 	// Status is OK, read the body
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		serviceLogger.Error("Failed to read response body",
+		logger.Error("Failed to read response body",
 			"operation", operation,
 			"url", maskedURL,
 			"status_code", resp.StatusCode,
@@ -335,51 +383,67 @@ func handleHTTPResponse(resp *http.Response, expectedStatus int, operation, mask
 // It applies a simple gain adjustment instead of dynamic loudness normalization to avoid pumping effects.
 // This avoids writing temporary files to disk.
 // It accepts a context for timeout/cancellation control and the explicit path to the FFmpeg executable.
-func encodeFlacUsingFFmpeg(ctx context.Context, pcmData []byte, ffmpegPath string, settings *conf.Settings) (*bytes.Buffer, error) {
-	serviceLogger.Debug("Starting FLAC encoding process")
+// The normalization mode of the settings selects how the gain is found: twopass analyzes every
+// soundscape before encoding it, singlepass normalizes with the dynamic loudnorm filter while
+// encoding, and cached reuses the gain last analyzed for the audio source for up to an hour.
+func encodeFlacUsingFFmpeg(ctx context.Context, logger *slog.Logger, pcmData []byte, ffmpegPath string, settings *conf.Settings, source string) (*bytes.Buffer, error) {
+	logger.Debug("Starting FLAC encoding process")
 	// Add check for empty pcmData
 	if len(pcmData) == 0 {
-		serviceLogger.Error("FLAC encoding failed: PCM data is empty")
+		logger.Error("FLAC encoding failed: PCM data is empty")
 		return nil, fmt.Errorf("pcmData is empty")
 	}
 
 	// ffmpegPath is now passed directly
-	serviceLogger.Debug("Using ffmpeg path", "path", ffmpegPath)
+	logger.Debug("Using ffmpeg path", "path", ffmpegPath)
+
+	start := time.Now()
+	var mode string
+	var native bool
+	if settings != nil {
+		mode = settings.Realtime.Birdweather.Normalization
+		native = settings.Realtime.Audio.Export.Encoder.FlacEncoder == "native"
+	}
+	switch mode {
+	case conf.NormalizationSinglePass:
+		return encodeFlacSinglePass(ctx, logger, pcmData, ffmpegPath, start)
+	case conf.NormalizationCached:
+		if gain, ok := sourceGains.get(source, start); ok {
+			logger.Debug("Using cached gain of audio source", "source", source, "gain_db", gain)
+			buffer, err := encodeFlacWithGain(ctx, logger, pcmData, ffmpegPath, gain, native)
+			if err != nil {
+				return nil, err
+			}
+			recordEncodeDuration(conf.NormalizationCached, time.Since(start))
+			return buffer, nil
+		}
+	}
 
 	// --- Pass 1: Analyze Loudness ---
 	// Use the provided context for the analysis
-	serviceLogger.Debug("Performing loudness analysis (Pass 1)")
+	logger.Debug("Performing loudness analysis (Pass 1)")
 	loudnessStats, err := myaudio.AnalyzeAudioLoudnessWithContext(ctx, pcmData, ffmpegPath)
 	if err != nil {
 		// Check if the error is due to context cancellation
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			serviceLogger.Warn("Loudness analysis cancelled or timed out", "error", err)
+			logger.Warn("Loudness analysis cancelled or timed out", "error", err)
 			return nil, err // Propagate context error
 		}
 
-		serviceLogger.Warn("Loudness analysis (Pass 1) failed, falling back to fixed gain adjustment", "error", err)
-		// Fallback to a conservative fixed gain adjustment
-		// A fixed gain of 15dB is a reasonable middle ground for bird call recordings
-		gainValue := 15.0
-		volumeArgs := fmt.Sprintf("volume=%.1fdB", gainValue)
-		customArgs := []string{
-			"-af", volumeArgs, // Simple gain adjustment
-			"-c:a", "flac",
-			"-f", "flac",
-		}
-
-		// Use the provided context for the fallback export operation
-		serviceLogger.Debug("Starting fallback FLAC export with fixed gain", "gain_db", gainValue)
-		buffer, err := myaudio.ExportAudioWithCustomFFmpegArgsContext(ctx, pcmData, ffmpegPath, customArgs)
+		logger.Warn("Loudness analysis (Pass 1) failed, falling back to fixed gain adjustment", "error", err)
+		// Fallback to the gain of the calibration of the source, or a conservative fixed gain
+		gainValue := fallbackGain(settings, source)
+		logger.Debug("Starting fallback FLAC export with fixed gain", "gain_db", gainValue)
+		buffer, err := encodeFlacWithGain(ctx, logger, pcmData, ffmpegPath, gainValue, native)
 		if err != nil {
-			serviceLogger.Error("Fallback FLAC export with fixed gain failed", "gain_db", gainValue, "error", err)
+			logger.Error("Fallback FLAC export with fixed gain failed", "gain_db", gainValue, "error", err)
 			return nil, fmt.Errorf("fallback FLAC export with fixed gain failed: %w", err)
 		}
-		serviceLogger.Info("Encoded PCM to FLAC using fixed gain (fallback)", "gain_db", gainValue)
+		logger.Info("Encoded PCM to FLAC using fixed gain (fallback)", "gain_db", gainValue)
 		return buffer, nil
 	}
 
-	serviceLogger.Debug("Loudness analysis results",
+	logger.Debug("Loudness analysis results",
 		"input_i", loudnessStats.InputI,
 		"input_lra", loudnessStats.InputLRA,
 		"input_tp", loudnessStats.InputTP,
@@ -393,23 +457,55 @@ func encodeFlacUsingFFmpeg(ctx context.Context, pcmData []byte, ffmpegPath strin
 	maxGain := 30.0 // Maximum gain in dB (absolute value)
 	gainLimited := false
 	if gainNeeded > maxGain {
-		serviceLogger.Warn("Limiting gain to prevent excessive amplification",
+		logger.Warn("Limiting gain to prevent excessive amplification",
 			"calculated_gain", gainNeeded, "max_gain", maxGain)
 		gainNeeded = maxGain
 		gainLimited = true
 	} else if gainNeeded < -maxGain {
-		serviceLogger.Warn("Limiting gain to prevent excessive attenuation",
+		logger.Warn("Limiting gain to prevent excessive attenuation",
 			"calculated_gain", gainNeeded, "min_gain", -maxGain)
 		gainNeeded = -maxGain
 		gainLimited = true
 	}
-	serviceLogger.Debug("Calculated gain adjustment", "gain_db", gainNeeded, "target_lufs", targetIntegratedLoudnessLUFS, "measured_lufs", inputLUFS, "limited", gainLimited)
+	logger.Debug("Calculated gain adjustment", "gain_db", gainNeeded, "target_lufs", targetIntegratedLoudnessLUFS, "measured_lufs", inputLUFS, "limited", gainLimited)
+	recordLoudnessError(conf.NormalizationTwoPass, inputLUFS+gainNeeded-targetIntegratedLoudnessLUFS)
+
+	// A recalibration shows how far off the cached gain would have been for this soundscape
+	if mode == conf.NormalizationCached {
+		if previous, ok := sourceGains.store(source, gainNeeded, start); ok {
+			recordLoudnessError(conf.NormalizationCached, previous-gainNeeded)
+		}
+	}
 
 	// --- Pass 2: Apply simple gain adjustment and encode ---
-	serviceLogger.Debug("Applying gain adjustment and encoding to FLAC (Pass 2)", "gain_db", gainNeeded)
+	buffer, err := encodeFlacWithGain(ctx, logger, pcmData, ffmpegPath, gainNeeded, native)
+	if err != nil {
+		return nil, err
+	}
+	recordEncodeDuration(conf.NormalizationTwoPass, time.Since(start))
+
+	// Return the buffer containing the FLAC data
+	return buffer, nil
+}
+
+// encodeFlacWithGain encodes PCM data to FLAC, adjusting its volume by gainDB. The native
+// encoder applies the gain itself when it is the preferred FLAC encoder, so FFmpeg only
+// analyzes the loudness, otherwise FFmpeg encodes with a volume filter.
+func encodeFlacWithGain(ctx context.Context, logger *slog.Logger, pcmData []byte, ffmpegPath string, gainDB float64, native bool) (*bytes.Buffer, error) {
+	logger.Debug("Applying gain adjustment and encoding to FLAC (Pass 2)", "gain_db", gainDB, "native", native)
+
+	if native {
+		buffer, err := myaudio.EncodeFLAC(ctx, "birdweather_flac_gain", pcmData, gainDB)
+		if err != nil {
+			logger.Error("Native FLAC encoding with gain adjustment failed", "gain_db", gainDB, "error", err)
+			return nil, fmt.Errorf("failed to encode PCM to FLAC with gain adjustment: %w", err)
+		}
+		logger.Info("Encoded PCM to FLAC natively with gain adjustment", "gain_db", gainDB)
+		return buffer, nil
+	}
 
 	// Use simple volume filter instead of loudnorm
-	volumeArgs := fmt.Sprintf("volume=%.2fdB", gainNeeded)
+	volumeArgs := fmt.Sprintf("volume=%.2fdB", gainDB)
 
 	customArgs := []string{
 		"-af", volumeArgs, // Simple gain adjustment filter
@@ -420,16 +516,87 @@ func encodeFlacUsingFFmpeg(ctx context.Context, pcmData []byte, ffmpegPath strin
 	// Use the provided context for the final encoding operation
 	buffer, err := myaudio.ExportAudioWithCustomFFmpegArgsContext(ctx, pcmData, ffmpegPath, customArgs)
 	if err != nil {
-		serviceLogger.Error("FFmpeg FLAC encoding with gain adjustment failed", "gain_db", gainNeeded, "error", err)
+		logger.Error("FFmpeg FLAC encoding with gain adjustment failed", "gain_db", gainDB, "error", err)
 		return nil, fmt.Errorf("failed to export PCM to FLAC with gain adjustment: %w", err)
 	}
 
-	serviceLogger.Info("Encoded PCM to FLAC with gain adjustment", "gain_db", gainNeeded)
+	logger.Info("Encoded PCM to FLAC with gain adjustment", "gain_db", gainDB)
+	return buffer, nil
+}
+
+// encodeFlacSinglePass encodes PCM data to FLAC with FFmpeg, normalizing it with the dynamic
+// loudnorm filter in the same run. This halves the FFmpeg work of an upload at the cost of
+// the gain varying within the soundscape.
+func encodeFlacSinglePass(ctx context.Context, logger *slog.Logger, pcmData []byte, ffmpegPath string, start time.Time) (*bytes.Buffer, error) {
+	logger.Debug("Normalizing and encoding to FLAC in a single pass")
+	buffer, stats, err := myaudio.ExportAudioWithLoudnormContext(ctx, pcmData, ffmpegPath,
+		targetIntegratedLoudnessLUFS, targetLoudnessRangeLU, targetTruePeakDBTP,
+		[]string{"-c:a", "flac", "-f", "flac"})
+	if err != nil {
+		logger.Error("FFmpeg FLAC encoding with single pass normalization failed", "error", err)
+		return nil, fmt.Errorf("failed to export PCM to FLAC with single pass normalization: %w", err)
+	}
+	recordEncodeDuration(conf.NormalizationSinglePass, time.Since(start))
 
-	// Return the buffer containing the FLAC data
+	if stats != nil {
+		outputLUFS := parseDouble(stats.OutputI, math.NaN())
+		if !math.IsNaN(outputLUFS) {
+			recordLoudnessError(conf.NormalizationSinglePass, outputLUFS-targetIntegratedLoudnessLUFS)
+		}
+		logger.Info("Encoded PCM to FLAC with single pass normalization", "input_lufs", stats.InputI, "output_lufs", stats.OutputI)
+	} else {
+		logger.Info("Encoded PCM to FLAC with single pass normalization")
+	}
 	return buffer, nil
 }
 
+// encodeFlacUsingSox converts PCM data to FLAC format using SoX when FFmpeg is not available.
+// The loudness is estimated from the RMS level and corrected with a single gain adjustment,
+// like the FFmpeg pipeline, with SoX's limiter preventing clipping.
+func encodeFlacUsingSox(ctx context.Context, logger *slog.Logger, pcmData []byte, soxPath string) (*bytes.Buffer, error) {
+	measured := myaudio.EstimatePCMLoudness(pcmData)
+	gainNeeded := myaudio.LoudnessGain(measured, targetIntegratedLoudnessLUFS)
+	logger.Debug("Encoding PCM to FLAC with SoX", "gain_db", gainNeeded, "estimated_loudness", measured)
+
+	buffer, err := myaudio.EncodePCMWithSoxContext(ctx, pcmData, soxPath, "flac",
+		[]string{"gain", "-l", strconv.FormatFloat(gainNeeded, 'f', 2, 64)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export PCM to FLAC with SoX: %w", err)
+	}
+	return buffer, nil
+}
+
+// encodeWithoutExternalEncoder encodes PCM data to FLAC with the native encoder, normalized by
+// the estimated loudness of the soundscape, or to WAV if that fails. It returns the encoded
+// audio and its file extension.
+func (b *BwClient) encodeWithoutExternalEncoder(pcmData []byte, timestamp string) (*bytes.Buffer, string, error) {
+	gain := myaudio.NormalizationGain(pcmData, targetIntegratedLoudnessLUFS, targetTruePeakDBTP)
+	flacCtx, cancelFlac := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelFlac()
+	audioBuffer, err := myaudio.EncodeFLAC(flacCtx, "birdweather_flac_native", pcmData, gain)
+	if err == nil {
+		b.logger().Info("Using FLAC format encoded natively for upload", "timestamp", timestamp, "gain_db", gain)
+		return audioBuffer, "flac", nil
+	}
+	b.logger().Warn("Native FLAC encoding failed, falling back to WAV", "timestamp", timestamp, "error", err)
+
+	wavCtx, cancelWav := context.WithTimeout(context.Background(), 30*time.Second) // Fresh timeout for WAV
+	defer cancelWav()
+	audioBuffer, err = myaudio.EncodePCMtoWAVWithContext(wavCtx, pcmData)
+	if err != nil {
+		enhancedErr := errors.New(err).
+			Component("birdweather").
+			Category(errors.CategoryAudio).
+			Context("timestamp", timestamp).
+			Context("fallback_encoding", "wav").
+			Build()
+		b.logger().Error("Failed to encode PCM to WAV", "timestamp", timestamp, "error", err)
+		return nil, "", enhancedErr
+	}
+	b.logger().Info("Using WAV format for upload (fallback)", "timestamp", timestamp)
+	return audioBuffer, "wav", nil
+}
+
 // parseDouble safely parses a string to float64, returning defaultValue on error.
 func parseDouble(s string, defaultValue float64) float64 {
 	val, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
@@ -442,6 +609,12 @@ func parseDouble(s string, defaultValue float64) float64 {
 // UploadSoundscape uploads a soundscape file to the Birdweather API and returns the soundscape ID if successful.
 // It handles the PCM to WAV conversion, compresses the data, and manages HTTP request creation and response handling safely.
 func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscapeID string, err error) {
+	return b.uploadSoundscape(timestamp, "", pcmData)
+}
+
+// uploadSoundscape uploads a soundscape recorded by source, the audio source whose cached
+// normalization gain applies
+func (b *BwClient) uploadSoundscape(timestamp, source string, pcmData []byte) (soundscapeID string, err error) {
 	// Track performance timing for telemetry
 	startTime := time.Now()
 	defer func() {
@@ -462,13 +635,13 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 					Context("timestamp", timestamp).
 					Build()
 			}
-			serviceLogger.Warn("Soundscape upload failed", "timestamp", timestamp, "duration_ms", duration.Milliseconds(), "error", err)
+			b.logger().Warn("Soundscape upload failed", "timestamp", timestamp, "duration_ms", duration.Milliseconds(), "error", err)
 		} else {
-			serviceLogger.Info("Soundscape upload completed", "timestamp", timestamp, "duration_ms", duration.Milliseconds(), "soundscape_id", soundscapeID)
+			b.logger().Info("Soundscape upload completed", "timestamp", timestamp, "duration_ms", duration.Milliseconds(), "soundscape_id", soundscapeID)
 		}
 	}()
 
-	serviceLogger.Info("Starting soundscape upload", "timestamp", timestamp)
+	b.logger().Info("Starting soundscape upload", "timestamp", timestamp)
 	// Add check for empty pcmData
 	if len(pcmData) == 0 {
 		enhancedErr := errors.New(fmt.Errorf("pcmData is empty")).
@@ -476,7 +649,7 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 			Category(errors.CategoryValidation).
 			Context("timestamp", timestamp).
 			Build()
-		serviceLogger.Error("Soundscape upload failed: PCM data is empty", "timestamp", timestamp)
+		b.logger().Error("Soundscape upload failed: PCM data is empty", "timestamp", timestamp)
 		return "", enhancedErr
 	}
 
@@ -493,69 +666,57 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 	// and is either an explicit valid path, a path found in PATH, or empty if unavailable.
 	ffmpegPathForExec, _ := exec.LookPath(conf.GetFfmpegBinaryName())
 	ffmpegAvailable := ffmpegPathForExec != ""
-	serviceLogger.Debug("Checking FFmpeg availability", "path", ffmpegPathForExec, "available", ffmpegAvailable)
+	b.logger().Debug("Checking FFmpeg availability", "path", ffmpegPathForExec, "available", ffmpegAvailable)
 
-	// Use FLAC if FFmpeg is available, otherwise fall back to WAV
+	// Use FLAC encoded with FFmpeg if it is available, otherwise with SoX or the native encoder,
+	// whichever is preferred, falling back to WAV
+	nativeFirst := b.Settings.Realtime.Audio.Export.Encoder.FlacEncoder == "native"
 	if ffmpegAvailable {
 		// Encode PCM data to FLAC format with normalization, passing the context and validated path
-		audioBuffer, err = encodeFlacUsingFFmpeg(ctx, pcmData, ffmpegPathForExec, b.Settings)
+		audioBuffer, err = encodeFlacUsingFFmpeg(ctx, b.logger(), pcmData, ffmpegPathForExec, b.Settings, source)
 		if err != nil {
-			serviceLogger.Warn("FLAC encoding failed, falling back to WAV", "timestamp", timestamp, "error", err)
+			b.logger().Warn("FLAC encoding failed, falling back to the native encoder", "timestamp", timestamp, "error", err)
 			// Log the FLAC encoding error
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-				log.Printf("⚠️ FLAC encoding timed out or was cancelled, falling back to WAV: %v\n", err)
+				log.Printf("⚠️ FLAC encoding timed out or was cancelled, falling back to the native encoder: %v\n", err)
 			} else {
-				log.Printf("❌ Failed to encode/normalize PCM to FLAC, falling back to WAV: %v\n", err)
+				log.Printf("❌ Failed to encode/normalize PCM to FLAC, falling back to the native encoder: %v\n", err)
 			}
-
-			// Fall back to WAV if FLAC encoding fails, using a *new* context
-			wavCtx, cancelWav := context.WithTimeout(context.Background(), 30*time.Second) // Fresh timeout for WAV
-			defer cancelWav()
-			serviceLogger.Debug("Encoding to WAV (fallback)", "timestamp", timestamp)
-			audioBuffer, err = myaudio.EncodePCMtoWAVWithContext(wavCtx, pcmData)
+			audioBuffer, audioExt, err = b.encodeWithoutExternalEncoder(pcmData, timestamp)
 			if err != nil {
-				enhancedErr := errors.New(err).
-					Component("birdweather").
-					Category(errors.CategoryAudio).
-					Context("timestamp", timestamp).
-					Context("fallback_encoding", "wav").
-					Build()
-				serviceLogger.Error("Failed to encode PCM to WAV after FLAC failure", "timestamp", timestamp, "error", err)
-				return "", enhancedErr
+				return "", err
+			}
+		} else {
+			audioExt = "flac"
+			b.logger().Info("Using FLAC format for upload", "timestamp", timestamp)
+		}
+	} else if soxPath := b.Settings.Realtime.Audio.SoxPath; !nativeFirst && soxPath != "" && slices.Contains(b.Settings.Realtime.Audio.SoxAudioTypes, "flac") {
+		// Encode PCM data to FLAC with SoX, falling back to the native encoder if that fails
+		audioBuffer, err = encodeFlacUsingSox(ctx, b.logger(), pcmData, soxPath)
+		if err != nil {
+			b.logger().Warn("SoX FLAC encoding failed, falling back to the native encoder", "timestamp", timestamp, "error", err)
+			audioBuffer, audioExt, err = b.encodeWithoutExternalEncoder(pcmData, timestamp)
+			if err != nil {
+				return "", err
 			}
-			audioExt = "wav"
-			serviceLogger.Info("Using WAV format for upload (fallback)", "timestamp", timestamp)
 		} else {
 			audioExt = "flac"
-			serviceLogger.Info("Using FLAC format for upload", "timestamp", timestamp)
+			b.logger().Info("Using FLAC format encoded with SoX for upload", "timestamp", timestamp)
 		}
 	} else {
-		log.Println("🔊 FFmpeg not available (checked configured path and system PATH), encoding to WAV format")
-		serviceLogger.Info("FFmpeg not available, encoding to WAV format", "timestamp", timestamp)
-		// Encode PCM data to WAV format using a dedicated context
-		wavCtx, cancelWav := context.WithTimeout(context.Background(), 30*time.Second) // Fresh timeout for WAV
-		defer cancelWav()
-		audioBuffer, err = myaudio.EncodePCMtoWAVWithContext(wavCtx, pcmData)
+		log.Println("🔊 FFmpeg not available (checked configured path and system PATH), encoding to FLAC with the native encoder")
+		audioBuffer, audioExt, err = b.encodeWithoutExternalEncoder(pcmData, timestamp)
 		if err != nil {
-			enhancedErr := errors.New(err).
-				Component("birdweather").
-				Category(errors.CategoryAudio).
-				Context("timestamp", timestamp).
-				Context("encoding_format", "wav").
-				Build()
-			serviceLogger.Error("Failed to encode PCM to WAV", "timestamp", timestamp, "error", err)
-			return "", enhancedErr
+			return "", err
 		}
-		audioExt = "wav"
-		serviceLogger.Info("Using WAV format for upload", "timestamp", timestamp)
 	}
 
 	// If debug is enabled, save the audio file locally with timestamp information
-	if b.Settings.Realtime.Birdweather.Debug {
+	if b.debugEnabled() {
 		// Parse the timestamp
 		parsedTime, parseErr := time.Parse("2006-01-02T15:04:05.000-0700", timestamp)
 		if parseErr != nil {
-			serviceLogger.Warn("Could not parse timestamp for debug file saving", "timestamp", timestamp, "format", audioExt, "error", parseErr)
+			b.logger().Warn("Could not parse timestamp for debug file saving", "timestamp", timestamp, "format", audioExt, "error", parseErr)
 		} else {
 			// Create a debug directory for audio files
 			debugDir := filepath.Join("debug", "birdweather", audioExt)
@@ -564,15 +725,15 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 			debugFilename := filepath.Join(debugDir, fmt.Sprintf("bw_debug_%s.%s",
 				parsedTime.Format("20060102_150405"), audioExt))
 
-			// Calculate the end time (3 seconds after start)
-			endTime := parsedTime.Add(3 * time.Second)
+			// Calculate the end time (one analysis window after start)
+			endTime := parsedTime.Add(conf.AnalysisWindow)
 
 			// Save the audio buffer with timestamp information
 			audioCopy := bytes.NewBuffer(audioBuffer.Bytes())
 			if saveErr := saveBufferToFile(audioCopy, debugFilename, parsedTime, endTime); saveErr != nil {
-				serviceLogger.Warn("Could not save debug file", "filename", debugFilename, "error", saveErr)
+				b.logger().Warn("Could not save debug file", "filename", debugFilename, "error", saveErr)
 			} else {
-				serviceLogger.Debug("Saved debug file", "filename", debugFilename)
+				b.logger().Debug("Saved debug file", "filename", debugFilename)
 			}
 		}
 	}
@@ -580,25 +741,25 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 	// Compress the audio data
 	var gzipAudioData bytes.Buffer
 	gzipWriter := gzip.NewWriter(&gzipAudioData)
-	serviceLogger.Debug("Compressing audio data", "format", audioExt, "timestamp", timestamp)
+	b.logger().Debug("Compressing audio data", "format", audioExt, "timestamp", timestamp)
 	if _, err := io.Copy(gzipWriter, audioBuffer); err != nil {
-		serviceLogger.Error("Failed to compress audio data", "format", audioExt, "timestamp", timestamp, "error", err)
+		b.logger().Error("Failed to compress audio data", "format", audioExt, "timestamp", timestamp, "error", err)
 		return "", fmt.Errorf("failed to compress %s data: %w", audioExt, err)
 	}
 	if err := gzipWriter.Close(); err != nil {
-		serviceLogger.Error("Failed to finalize audio compression", "format", audioExt, "timestamp", timestamp, "error", err)
+		b.logger().Error("Failed to finalize audio compression", "format", audioExt, "timestamp", timestamp, "error", err)
 		return "", fmt.Errorf("failed to finalize compression: %w", err)
 	}
-	serviceLogger.Debug("Audio data compressed", "format", audioExt, "original_size", audioBuffer.Len(), "compressed_size", gzipAudioData.Len())
+	b.logger().Debug("Audio data compressed", "format", audioExt, "original_size", audioBuffer.Len(), "compressed_size", gzipAudioData.Len())
 
 	// Create and execute the POST request
 	soundscapeURL := fmt.Sprintf("https://app.birdweather.com/api/v1/stations/%s/soundscapes?timestamp=%s&type=%s",
 		b.BirdweatherID, neturl.QueryEscape(timestamp), audioExt)
 	maskedURL := strings.ReplaceAll(soundscapeURL, b.BirdweatherID, "***")
-	serviceLogger.Debug("Creating soundscape upload request", "url", maskedURL)
+	b.logger().Debug("Creating soundscape upload request", "url", maskedURL)
 	req, err := http.NewRequest("POST", soundscapeURL, &gzipAudioData)
 	if err != nil {
-		serviceLogger.Error("Failed to create soundscape POST request", "url", maskedURL, "error", err)
+		b.logger().Error("Failed to create soundscape POST request", "url", maskedURL, "error", err)
 		return "", fmt.Errorf("failed to create POST request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/octet-stream")
@@ -606,31 +767,31 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 	req.Header.Set("User-Agent", "BirdNET-Go")
 
 	// Execute the request
-	serviceLogger.Info("Uploading soundscape", "url", maskedURL, "format", audioExt)
+	b.logger().Info("Uploading soundscape", "url", maskedURL, "format", audioExt)
 	resp, err := b.HTTPClient.Do(req)
 	if err != nil {
-		serviceLogger.Error("Soundscape upload request failed", "url", maskedURL, "error", err)
-		return "", handleNetworkError(err, maskedURL, 45*time.Second, "soundscape upload")
+		b.logger().Error("Soundscape upload request failed", "url", maskedURL, "error", err)
+		return "", handleNetworkError(b.logger(), err, maskedURL, 45*time.Second, "soundscape upload")
 	}
 	if resp == nil {
-		serviceLogger.Error("Soundscape upload received nil response", "url", maskedURL)
+		b.logger().Error("Soundscape upload received nil response", "url", maskedURL)
 		return "", fmt.Errorf("received nil response")
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			serviceLogger.Debug("Failed to close response body", "error", err)
+			b.logger().Debug("Failed to close response body", "error", err)
 		}
 	}()
-	serviceLogger.Debug("Received soundscape upload response", "url", maskedURL, "status_code", resp.StatusCode)
+	b.logger().Debug("Received soundscape upload response", "url", maskedURL, "status_code", resp.StatusCode)
 
 	// Process the response using the new handler
-	responseBody, err := handleHTTPResponse(resp, http.StatusCreated, "soundscape upload", maskedURL)
+	responseBody, err := handleHTTPResponse(b.logger(), resp, http.StatusCreated, "soundscape upload", maskedURL)
 	if err != nil {
 		return "", err
 	}
 
-	if b.Settings.Realtime.Birdweather.Debug {
-		serviceLogger.Debug("Soundscape response body", "body", string(responseBody))
+	if b.debugEnabled() {
+		b.logger().Debug("Soundscape response body", "body", string(responseBody))
 	}
 
 	var sdata SoundscapeResponse
@@ -638,7 +799,7 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 		// Check if this might be HTML even though we got 200 OK
 		if strings.Contains(string(responseBody), "<") && strings.Contains(string(responseBody), ">") {
 			htmlError := extractHTMLError(string(responseBody))
-			serviceLogger.Error("Received HTML response with 200 OK status",
+			b.logger().Error("Received HTML response with 200 OK status",
 				"operation", "soundscape upload",
 				"url", maskedURL,
 				"html_error", htmlError,
@@ -650,17 +811,17 @@ func (b *BwClient) UploadSoundscape(timestamp string, pcmData []byte) (soundscap
 				Context("operation", "soundscape upload").
 				Build()
 		}
-		serviceLogger.Error("Failed to decode soundscape JSON response", "url", maskedURL, "status_code", resp.StatusCode, "body", string(responseBody), "error", err)
+		b.logger().Error("Failed to decode soundscape JSON response", "url", maskedURL, "status_code", resp.StatusCode, "body", string(responseBody), "error", err)
 		return "", fmt.Errorf("failed to decode JSON response: %w", err)
 	}
 
 	if !sdata.Success {
-		serviceLogger.Error("Soundscape upload was not successful according to API response", "url", maskedURL, "status_code", resp.StatusCode, "response", sdata)
+		b.logger().Error("Soundscape upload was not successful according to API response", "url", maskedURL, "status_code", resp.StatusCode, "response", sdata)
 		return "", fmt.Errorf("upload failed, response reported failure")
 	}
 
 	soundscapeID = fmt.Sprintf("%d", sdata.Soundscape.ID)
-	serviceLogger.Info("Soundscape uploaded successfully", "timestamp", timestamp, "soundscape_id", soundscapeID, "url", maskedURL)
+	b.logger().Info("Soundscape uploaded successfully", "timestamp", timestamp, "soundscape_id", soundscapeID, "url", maskedURL)
 	return soundscapeID, nil
 }
 
@@ -687,13 +848,13 @@ func (b *BwClient) PostDetection(soundscapeID, timestamp, commonName, scientific
 					Context("timestamp", timestamp).
 					Build()
 			}
-			serviceLogger.Warn("Detection post failed", "soundscape_id", soundscapeID, "duration_ms", duration.Milliseconds(), "error", err)
+			b.logger().Warn("Detection post failed", "soundscape_id", soundscapeID, "duration_ms", duration.Milliseconds(), "error", err)
 		} else {
-			serviceLogger.Info("Detection post completed", "soundscape_id", soundscapeID, "duration_ms", duration.Milliseconds())
+			b.logger().Info("Detection post completed", "soundscape_id", soundscapeID, "duration_ms", duration.Milliseconds())
 		}
 	}()
 
-	serviceLogger.Info("Starting detection post", "soundscape_id", soundscapeID, "timestamp", timestamp, "common_name", commonName, "scientific_name", scientificName, "confidence", confidence)
+	b.logger().Info("Starting detection post", "soundscape_id", soundscapeID, "timestamp", timestamp, "common_name", commonName, "scientific_name", scientificName, "confidence", confidence)
 	// Simple input validation
 	if soundscapeID == "" || timestamp == "" || commonName == "" || scientificName == "" {
 		enhancedErr := errors.New(fmt.Errorf("invalid input: all string parameters must be non-empty")).
@@ -704,7 +865,7 @@ func (b *BwClient) PostDetection(soundscapeID, timestamp, commonName, scientific
 			Context("common_name", commonName).
 			Context("scientific_name", scientificName).
 			Build()
-		serviceLogger.Error("Detection post failed: Invalid input",
+		b.logger().Error("Detection post failed: Invalid input",
 			"soundscape_id", soundscapeID, "timestamp", timestamp, "common_name", commonName, "scientific_name", scientificName, "error", enhancedErr)
 		return enhancedErr
 	}
@@ -718,11 +879,11 @@ func (b *BwClient) PostDetection(soundscapeID, timestamp, commonName, scientific
 	// Convert timestamp to time.Time and calculate end time
 	parsedTime, err := time.Parse("2006-01-02T15:04:05.000-0700", timestamp)
 	if err != nil {
-		serviceLogger.Error("Failed to parse timestamp for detection post", "timestamp", timestamp, "error", err)
+		b.logger().Error("Failed to parse timestamp for detection post", "timestamp", timestamp, "error", err)
 		return fmt.Errorf("failed to parse timestamp: %w", err)
 	}
-	endTime := parsedTime.Add(3 * time.Second).Format("2006-01-02T15:04:05.000-0700") // Add 3 seconds to timestamp for endTime
-	serviceLogger.Debug("Calculated detection time range", "start_time", timestamp, "end_time", endTime)
+	endTime := parsedTime.Add(conf.AnalysisWindow).Format("2006-01-02T15:04:05.000-0700") // Add one analysis window to timestamp for endTime
+	b.logger().Debug("Calculated detection time range", "start_time", timestamp, "end_time", endTime)
 
 	// Prepare JSON payload for POST request
 	postData := struct {
@@ -752,34 +913,34 @@ func (b *BwClient) PostDetection(soundscapeID, timestamp, commonName, scientific
 	// Marshal JSON data
 	postDataBytes, err := json.Marshal(postData)
 	if err != nil {
-		serviceLogger.Error("Failed to marshal detection JSON data", "error", err)
+		b.logger().Error("Failed to marshal detection JSON data", "error", err)
 		return fmt.Errorf("failed to marshal JSON data: %w", err)
 	}
 
-	if b.Settings.Realtime.Birdweather.Debug {
-		serviceLogger.Debug("Detection JSON Payload", "payload", string(postDataBytes))
+	if b.debugEnabled() {
+		b.logger().Debug("Detection JSON Payload", "payload", string(postDataBytes))
 	}
 
 	// Execute POST request
-	serviceLogger.Info("Posting detection", "url", maskedDetectionURL, "soundscape_id", soundscapeID, "scientific_name", scientificName)
+	b.logger().Info("Posting detection", "url", maskedDetectionURL, "soundscape_id", soundscapeID, "scientific_name", scientificName)
 	resp, err := b.HTTPClient.Post(detectionURL, "application/json", bytes.NewBuffer(postDataBytes))
 	if err != nil {
-		serviceLogger.Error("Detection post request failed", "url", maskedDetectionURL, "soundscape_id", soundscapeID, "error", err)
-		return handleNetworkError(err, maskedDetectionURL, 45*time.Second, "detection post")
+		b.logger().Error("Detection post request failed", "url", maskedDetectionURL, "soundscape_id", soundscapeID, "error", err)
+		return handleNetworkError(b.logger(), err, maskedDetectionURL, 45*time.Second, "detection post")
 	}
 	if resp == nil {
-		serviceLogger.Error("Detection post received nil response", "url", maskedDetectionURL, "soundscape_id", soundscapeID)
+		b.logger().Error("Detection post received nil response", "url", maskedDetectionURL, "soundscape_id", soundscapeID)
 		return fmt.Errorf("received nil response")
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			serviceLogger.Debug("Failed to close response body", "error", err)
+			b.logger().Debug("Failed to close response body", "error", err)
 		}
 	}()
-	serviceLogger.Debug("Received detection post response", "url", maskedDetectionURL, "soundscape_id", soundscapeID, "status_code", resp.StatusCode)
+	b.logger().Debug("Received detection post response", "url", maskedDetectionURL, "soundscape_id", soundscapeID, "status_code", resp.StatusCode)
 
 	// Handle response using the new handler
-	_, err = handleHTTPResponse(resp, http.StatusCreated, "detection post", maskedDetectionURL)
+	_, err = handleHTTPResponse(b.logger(), resp, http.StatusCreated, "detection post", maskedDetectionURL)
 	if err != nil {
 		// Add additional context for detection-specific error
 		var enhancedErr *errors.EnhancedError
@@ -790,7 +951,7 @@ func (b *BwClient) PostDetection(soundscapeID, timestamp, commonName, scientific
 		return err
 	}
 
-	serviceLogger.Info("Detection posted successfully", "soundscape_id", soundscapeID, "scientific_name", scientificName)
+	b.logger().Info("Detection posted successfully", "soundscape_id", soundscapeID, "scientific_name", scientificName)
 	return nil
 }
 
@@ -818,13 +979,13 @@ func (b *BwClient) Publish(note *datastore.Note, pcmData []byte) (err error) {
 					Context("scientific_name", note.ScientificName).
 					Build()
 			}
-			serviceLogger.Warn("Publish failed", "common_name", note.CommonName, "scientific_name", note.ScientificName, "duration_ms", duration.Milliseconds(), "error", err)
+			b.logger().Warn("Publish failed", "common_name", note.CommonName, "scientific_name", note.ScientificName, "duration_ms", duration.Milliseconds(), "error", err)
 		} else {
-			serviceLogger.Info("Publish completed", "common_name", note.CommonName, "scientific_name", note.ScientificName, "duration_ms", duration.Milliseconds())
+			b.logger().Info("Publish completed", "common_name", note.CommonName, "scientific_name", note.ScientificName, "duration_ms", duration.Milliseconds())
 		}
 	}()
 
-	serviceLogger.Info("Starting publish process", "date", note.Date, "time", note.Time, "common_name", note.CommonName, "scientific_name", note.ScientificName, "confidence", note.Confidence)
+	b.logger().Info("Starting publish process", "date", note.Date, "time", note.Time, "common_name", note.CommonName, "scientific_name", note.ScientificName, "confidence", note.Confidence)
 	// Add check for empty pcmData
 	if len(pcmData) == 0 {
 		enhancedErr := errors.New(fmt.Errorf("pcmData is empty")).
@@ -833,7 +994,7 @@ func (b *BwClient) Publish(note *datastore.Note, pcmData []byte) (err error) {
 			Context("common_name", note.CommonName).
 			Context("scientific_name", note.ScientificName).
 			Build()
-		serviceLogger.Error("Publish failed: PCM data is empty", "note", note, "error", enhancedErr)
+		b.logger().Error("Publish failed: PCM data is empty", "note", note, "error", enhancedErr)
 		return enhancedErr
 	}
 
@@ -846,86 +1007,94 @@ func (b *BwClient) Publish(note *datastore.Note, pcmData []byte) (err error) {
 	// Parse the timestamp using the given format and the system's local timezone
 	parsedTime, err := time.ParseInLocation("2006-01-02T15:04:05", dateTimeString, loc)
 	if err != nil {
-		serviceLogger.Error("Error parsing date/time for publish", "date", note.Date, "time", note.Time, "error", err)
+		b.logger().Error("Error parsing date/time for publish", "date", note.Date, "time", note.Time, "error", err)
 		return fmt.Errorf("error parsing date: %w", err)
 	}
 
 	// Format the parsed time to the required timestamp format with timezone information
 	timestamp := parsedTime.Format("2006-01-02T15:04:05.000-0700")
-	serviceLogger.Debug("Formatted timestamp for publish", "timestamp", timestamp)
+	b.logger().Debug("Formatted timestamp for publish", "timestamp", timestamp)
 
 	// If debug is enabled, save the raw PCM data to help diagnose issues
-	if b.Settings.Realtime.Birdweather.Debug {
+	if b.debugEnabled() {
 		debugDir := filepath.Join("debug", "birdweather", "pcm")
 		debugFilename := filepath.Join(debugDir, fmt.Sprintf("bw_pcm_debug_%s.raw",
 			parsedTime.Format("20060102_150405")))
 
 		// Create directory if it doesn't exist
 		if err := createDebugDirectory(debugDir); err != nil {
-			serviceLogger.Warn("Could not create debug PCM directory", "directory", debugDir, "error", err)
+			b.logger().Warn("Could not create debug PCM directory", "directory", debugDir, "error", err)
 		} else {
 			// Save raw PCM data
 			if err := os.WriteFile(debugFilename, pcmData, 0o600); err != nil {
-				serviceLogger.Warn("Could not save debug PCM file", "filename", debugFilename, "error", err)
+				b.logger().Warn("Could not save debug PCM file", "filename", debugFilename, "error", err)
 			} else {
-				serviceLogger.Debug("Saved debug PCM file", "filename", debugFilename)
+				b.logger().Debug("Saved debug PCM file", "filename", debugFilename)
 				// ... (metadata saving logs omitted for brevity, assumed okay) ...
 			}
 		}
 	}
 
+	// Skip the upload while BirdWeather is failing, the job queue retries it later
+	if !b.breaker.allow(time.Now()) {
+		status := b.CircuitState()
+		return errors.Newf("BirdWeather uploads paused after %d consecutive failures", status.ConsecutiveFailures).
+			Component("birdweather").
+			Category(errors.CategoryNetwork).
+			Context("circuit_state", status.State).
+			Context("retry_at", status.RetryAt.Format(time.RFC3339)).
+			Build()
+	}
+	defer func() { b.breaker.record(err, time.Now()) }()
+
 	// Upload the soundscape to Birdweather and retrieve the soundscape ID
-	serviceLogger.Debug("Calling UploadSoundscape", "timestamp", timestamp)
-	soundscapeID, err := b.UploadSoundscape(timestamp, pcmData)
+	b.logger().Debug("Calling UploadSoundscape", "timestamp", timestamp)
+	soundscapeID, err := b.uploadSoundscape(timestamp, note.Source.ID, pcmData)
 	if err != nil {
-		serviceLogger.Error("Publish failed: Error during soundscape upload", "timestamp", timestamp, "error", err)
+		b.logger().Error("Publish failed: Error during soundscape upload", "timestamp", timestamp, "error", err)
 		return fmt.Errorf("failed to upload soundscape to Birdweather: %w", err)
 	}
-	serviceLogger.Debug("UploadSoundscape completed", "timestamp", timestamp, "soundscape_id", soundscapeID)
+	b.logger().Debug("UploadSoundscape completed", "timestamp", timestamp, "soundscape_id", soundscapeID)
 
 	// Post the detection details to Birdweather using the retrieved soundscape ID
-	serviceLogger.Debug("Calling PostDetection", "soundscape_id", soundscapeID, "timestamp", timestamp, "note", note)
+	b.logger().Debug("Calling PostDetection", "soundscape_id", soundscapeID, "timestamp", timestamp, "note", note)
 	err = b.PostDetection(soundscapeID, timestamp, note.CommonName, note.ScientificName, note.Confidence)
 	if err != nil {
-		serviceLogger.Error("Publish failed: Error during detection post", "soundscape_id", soundscapeID, "timestamp", timestamp, "note", note, "error", err)
+		b.logger().Error("Publish failed: Error during detection post", "soundscape_id", soundscapeID, "timestamp", timestamp, "note", note, "error", err)
 		return fmt.Errorf("failed to post detection to Birdweather: %w", err)
 	}
-	serviceLogger.Debug("PostDetection completed", "soundscape_id", soundscapeID)
+	b.logger().Debug("PostDetection completed", "soundscape_id", soundscapeID)
 
-	serviceLogger.Info("Publish process completed successfully", "soundscape_id", soundscapeID, "scientific_name", note.ScientificName)
+	b.logger().Info("Publish process completed successfully", "soundscape_id", soundscapeID, "scientific_name", note.ScientificName)
 	return nil
 }
 
 // Close properly cleans up the BwClient resources
-// Currently this just cancels any pending HTTP requests and closes the file logger
+// Currently this just cancels any pending HTTP requests, the package file logger is
+// shared by all clients and closed on shutdown
 func (b *BwClient) Close() {
-	serviceLogger.Info("Closing BirdWeather client")
+	b.logger().Info("Closing BirdWeather client")
 	if b.HTTPClient != nil && b.HTTPClient.Transport != nil {
 		// If the transport implements the CloseIdleConnections method, call it
 		type transporter interface {
 			CloseIdleConnections()
 		}
 		if transport, ok := b.HTTPClient.Transport.(transporter); ok {
-			serviceLogger.Debug("Closing idle HTTP connections")
+			b.logger().Debug("Closing idle HTTP connections")
 			transport.CloseIdleConnections()
 		}
 		// Cancel any in-flight requests by using a new client
 		b.HTTPClient = nil // Allow GC to collect the old client/transport
 	}
 
-	// Close the service-specific file logger
-	if closeLogger != nil {
-		serviceLogger.Debug("Closing birdweather service log file")
-		if err := closeLogger(); err != nil {
-			// Log closing error to standard logger as service logger might be closed
-			log.Printf("ERROR: Failed to close birdweather log file: %v", err)
-		}
-		closeLogger = nil // Prevent multiple closes
+	if b.debugEnabled() {
+		b.logger().Info("BirdWeather client closed") // Log one last time
 	}
+}
 
-	if b.Settings.Realtime.Birdweather.Debug {
-		serviceLogger.Info("BirdWeather client closed") // Log one last time
-	}
+// debugEnabled reports whether debug dumps are enabled in settings or by a debug capture session
+func (b *BwClient) debugEnabled() bool {
+	return b.Settings.Realtime.Birdweather.Debug || debugcapture.Active(debugcapture.ComponentBirdWeather)
 }
 
 // createDebugDirectory creates a directory for debug files and returns any error encountered
@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
@@ -156,6 +158,37 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithLogger(t *testing.T) {
+	var first, second bytes.Buffer
+	firstClient, err := NewWithLogger(MockSettings(), slog.New(slog.NewTextHandler(&first, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if err != nil {
+		t.Fatalf("Failed to create new BwClient: %v", err)
+	}
+	secondClient, err := NewWithLogger(MockSettings(), slog.New(slog.NewTextHandler(&second, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if err != nil {
+		t.Fatalf("Failed to create new BwClient: %v", err)
+	}
+
+	firstClient.RandomizeLocation(100)
+	firstClient.Close()
+	secondClient.RandomizeLocation(100)
+
+	if !strings.Contains(first.String(), "Randomized location") {
+		t.Errorf("Expected first client to log to its own logger, got %q", first.String())
+	}
+	if strings.Count(second.String(), "Randomized location") != 1 {
+		t.Errorf("Expected second client to log only its own messages after the first client closed, got %q", second.String())
+	}
+
+	defaultClient, err := New(MockSettings())
+	if err != nil {
+		t.Fatalf("Failed to create new BwClient: %v", err)
+	}
+	if defaultClient.Logger != serviceLogger {
+		t.Error("Expected New to use the package logger")
+	}
+}
+
 func TestRandomizeLocation(t *testing.T) {
 	settings := MockSettings()
 	client, _ := New(settings)
@@ -252,7 +285,7 @@ func TestHandleNetworkError(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			resultErr := handleNetworkError(tc.err, "https://test.example.com", 30*time.Second, "test operation")
+			resultErr := handleNetworkError(serviceLogger, tc.err, "https://test.example.com", 30*time.Second, "test operation")
 
 			if resultErr == nil {
 				t.Fatal("handleNetworkError should never return nil")
@@ -0,0 +1,108 @@
+// circuit_breaker.go: pauses BirdWeather uploads while the service is unreachable
+package birdweather
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Circuit breaker states reported by CircuitState
+const (
+	CircuitClosed   = "closed"    // uploads are sent
+	CircuitOpen     = "open"      // uploads are rejected until the cooldown has passed
+	CircuitHalfOpen = "half-open" // one trial upload decides whether uploads resume
+)
+
+const (
+	// circuitFailureThreshold is the number of consecutive failed uploads that opens the circuit
+	circuitFailureThreshold = 5
+	// circuitCooldown is how long uploads are rejected before a trial upload is allowed
+	circuitCooldown = 2 * time.Minute
+)
+
+// CircuitStatus is the state of the upload circuit breaker of a client
+type CircuitStatus struct {
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	RetryAt             time.Time `json:"retryAt,omitzero"` // when a trial upload is allowed while open
+}
+
+// circuitBreaker counts consecutive failed uploads. After circuitFailureThreshold failures
+// uploads are rejected without a request for circuitCooldown, then a single trial upload
+// closes the circuit on success or opens it for another cooldown on failure. The zero value
+// is a closed circuit.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool // a trial upload is running
+}
+
+// allow reports whether an upload may be sent at now
+func (cb *circuitBreaker) allow(now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < circuitFailureThreshold {
+		return true
+	}
+	if cb.probing || now.Sub(cb.openedAt) < circuitCooldown {
+		return false
+	}
+	cb.probing = true
+	return true
+}
+
+// record counts the result of an upload sent at now. Success closes the circuit and a
+// service failure counts towards opening it; other errors only end a trial upload.
+func (cb *circuitBreaker) record(err error, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+
+	switch {
+	case err == nil:
+		cb.failures = 0
+	case isServiceFailure(err):
+		cb.failures++
+		if cb.failures >= circuitFailureThreshold {
+			cb.openedAt = now
+		}
+	}
+}
+
+// status returns the state of the circuit at now
+func (cb *circuitBreaker) status(now time.Time) CircuitStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := CircuitStatus{State: CircuitClosed, ConsecutiveFailures: cb.failures}
+	if cb.failures < circuitFailureThreshold {
+		return status
+	}
+	if retryAt := cb.openedAt.Add(circuitCooldown); !cb.probing && now.Before(retryAt) {
+		status.State = CircuitOpen
+		status.RetryAt = retryAt
+		return status
+	}
+	status.State = CircuitHalfOpen
+	return status
+}
+
+// isServiceFailure reports whether an upload error means BirdWeather is unreachable or failing,
+// as opposed to a problem with the detection or its audio
+func isServiceFailure(err error) bool {
+	var enhancedErr *errors.EnhancedError
+	if !errors.As(err, &enhancedErr) {
+		return false
+	}
+	category := enhancedErr.GetCategory()
+	return category == string(errors.CategoryNetwork) || category == string(errors.CategoryTimeout)
+}
+
+// CircuitState returns the state of the upload circuit breaker
+func (b *BwClient) CircuitState() CircuitStatus {
+	return b.breaker.status(time.Now())
+}
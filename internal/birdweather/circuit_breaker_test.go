@@ -0,0 +1,53 @@
+package birdweather
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var cb circuitBreaker
+	now := time.Now()
+	outage := errors.Newf("connection refused").Component("birdweather").Category(errors.CategoryNetwork).Build()
+
+	// Errors that are not service failures do not count
+	cb.record(errors.Newf("pcmData is empty").Category(errors.CategoryValidation).Build(), now)
+	cb.record(fmt.Errorf("error parsing date"), now)
+	assert.Equal(t, CircuitClosed, cb.status(now).State)
+
+	for range circuitFailureThreshold - 1 {
+		assert.True(t, cb.allow(now))
+		cb.record(fmt.Errorf("failed to upload soundscape: %w", outage), now)
+	}
+	assert.Equal(t, CircuitClosed, cb.status(now).State)
+
+	cb.record(outage, now)
+	status := cb.status(now)
+	assert.Equal(t, CircuitOpen, status.State)
+	assert.Equal(t, circuitFailureThreshold, status.ConsecutiveFailures)
+	assert.Equal(t, now.Add(circuitCooldown), status.RetryAt)
+	assert.False(t, cb.allow(now.Add(circuitCooldown/2)), "uploads are rejected while open")
+
+	// After the cooldown a single trial upload is allowed
+	later := now.Add(circuitCooldown)
+	assert.Equal(t, CircuitHalfOpen, cb.status(later).State)
+	assert.True(t, cb.allow(later))
+	assert.False(t, cb.allow(later), "only one trial upload at a time")
+
+	// A failed trial opens the circuit for another cooldown
+	cb.record(outage, later)
+	assert.Equal(t, CircuitOpen, cb.status(later).State)
+	assert.False(t, cb.allow(later))
+
+	// A successful trial closes it
+	later = later.Add(circuitCooldown)
+	assert.True(t, cb.allow(later))
+	cb.record(nil, later)
+	assert.Equal(t, CircuitStatus{State: CircuitClosed}, cb.status(later))
+}
@@ -0,0 +1,98 @@
+// normalization.go: loudness normalization modes of soundscapes encoded with FFmpeg
+package birdweather
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+)
+
+// targetLoudnessRangeLU is the loudness range of soundscapes normalized in a single pass
+const targetLoudnessRangeLU = 7.0
+
+// gainCalibrationInterval is how long the cached gain of an audio source is used before the
+// next soundscape of the source is analyzed again
+const gainCalibrationInterval = time.Hour
+
+// defaultFallbackGainDB is the gain of soundscapes of uncalibrated sources whose loudness
+// could not be analyzed, a middle ground for bird call recordings
+const defaultFallbackGainDB = 15.0
+
+// uploadMetrics records the loudness and encode time of uploads once metrics are enabled
+var uploadMetrics atomic.Pointer[metrics.BirdWeatherMetrics]
+
+// SetMetrics sets the metrics recorder of soundscape uploads
+func SetMetrics(m *metrics.BirdWeatherMetrics) {
+	uploadMetrics.Store(m)
+}
+
+// recordLoudnessError records the difference between the loudness of a soundscape normalized
+// in mode and the target loudness
+func recordLoudnessError(mode string, errorLU float64) {
+	if m := uploadMetrics.Load(); m != nil {
+		m.RecordLoudnessError(mode, errorLU)
+	}
+}
+
+// recordEncodeDuration records the time taken to normalize and encode a soundscape in mode
+func recordEncodeDuration(mode string, duration time.Duration) {
+	if m := uploadMetrics.Load(); m != nil {
+		m.RecordEncodeDuration(mode, duration.Seconds())
+	}
+}
+
+// cachedGain is the gain measured for an audio source
+type cachedGain struct {
+	gain       float64
+	calibrated time.Time
+}
+
+// gainCache holds the normalization gain of each audio source. Soundscapes of a source are
+// recorded by the same microphone at a similar distance from the birds, so a gain measured
+// once is close enough for the soundscapes that follow and spares their loudness analysis.
+type gainCache struct {
+	mu    sync.Mutex
+	gains map[string]cachedGain
+}
+
+// sourceGains holds the gains of the cached normalization mode
+var sourceGains gainCache
+
+// get returns the gain of source if it was calibrated within gainCalibrationInterval of now
+func (c *gainCache) get(source string, now time.Time) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.gains[source]
+	if !ok || now.Sub(cached.calibrated) >= gainCalibrationInterval {
+		return 0, false
+	}
+	return cached.gain, true
+}
+
+// store records a gain calibrated for source at now and returns the gain it replaces, if any
+func (c *gainCache) store(source string, gain float64, now time.Time) (previous float64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gains == nil {
+		c.gains = make(map[string]cachedGain)
+	}
+	cached, ok := c.gains[source]
+	c.gains[source] = cachedGain{gain: gain, calibrated: now}
+	return cached.gain, ok
+}
+
+// fallbackGain returns the gain of a soundscape of source whose loudness could not be
+// analyzed. Soundscapes of calibrated sources are already at the calibration target, so only
+// the difference to the upload target is applied.
+func fallbackGain(settings *conf.Settings, source string) float64 {
+	if settings != nil {
+		if _, ok := myaudio.SourceCalibration(source); ok {
+			return targetIntegratedLoudnessLUFS - settings.Realtime.Audio.Calibration.TargetLUFS
+		}
+	}
+	return defaultFallbackGainDB
+}
@@ -0,0 +1,36 @@
+package birdweather
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGainCache(t *testing.T) {
+	var cache gainCache
+	now := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	if _, ok := cache.get("rtsp_1", now); ok {
+		t.Fatal("get() found a gain in an empty cache")
+	}
+
+	if _, ok := cache.store("rtsp_1", 12.5, now); ok {
+		t.Error("store() reported a previous gain for a new source")
+	}
+	if gain, ok := cache.get("rtsp_1", now.Add(59*time.Minute)); !ok || gain != 12.5 {
+		t.Errorf("get() = %v, %v, want 12.5, true", gain, ok)
+	}
+	if _, ok := cache.get("rtsp_2", now); ok {
+		t.Error("get() returned the gain of another source")
+	}
+	if _, ok := cache.get("rtsp_1", now.Add(gainCalibrationInterval)); ok {
+		t.Error("get() returned a gain due for calibration")
+	}
+
+	previous, ok := cache.store("rtsp_1", 10, now.Add(gainCalibrationInterval))
+	if !ok || previous != 12.5 {
+		t.Errorf("store() = %v, %v, want 12.5, true", previous, ok)
+	}
+	if gain, ok := cache.get("rtsp_1", now.Add(gainCalibrationInterval)); !ok || gain != 10 {
+		t.Errorf("get() after calibration = %v, %v, want 10, true", gain, ok)
+	}
+}
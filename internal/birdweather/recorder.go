@@ -0,0 +1,233 @@
+// recorder.go: in-memory recording of BirdWeather API requests and responses for troubleshooting
+package birdweather
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scrubbedHeaders are replaced with a placeholder in recorded exchanges
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// Exchange is a recorded request and its response with credentials removed
+type Exchange struct {
+	Time            time.Time           `json:"time"`
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	RequestHeaders  map[string][]string `json:"requestHeaders"`
+	RequestBody     string              `json:"requestBody,omitempty"`
+	Status          int                 `json:"status,omitempty"`
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseBody    string              `json:"responseBody,omitempty"`
+	Truncated       bool                `json:"truncated"` // true when a body was cut at the size limit
+	DurationMs      int64               `json:"durationMs"`
+	Error           string              `json:"error,omitempty"`
+}
+
+// recorder keeps the most recent exchanges in a ring buffer
+type recorder struct {
+	mu          sync.Mutex
+	exchanges   []Exchange
+	next        int
+	full        bool
+	maxBodySize int
+	secret      string
+}
+
+var (
+	recorderMu     sync.RWMutex
+	activeRecorder *recorder
+)
+
+// configureRecorder sets up the package recorder and returns it. The existing recorder and
+// its exchanges are kept when the configuration is unchanged, so recreating the client does
+// not lose history. A size of zero disables recording and discards recorded exchanges.
+func configureRecorder(size, maxBodySize int, secret string) *recorder {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+
+	if size <= 0 {
+		activeRecorder = nil
+		return nil
+	}
+	if r := activeRecorder; r != nil && len(r.exchanges) == size && r.maxBodySize == maxBodySize && r.secret == secret {
+		return r
+	}
+	activeRecorder = &recorder{
+		exchanges:   make([]Exchange, size),
+		maxBodySize: maxBodySize,
+		secret:      secret,
+	}
+	return activeRecorder
+}
+
+// RecordedExchanges returns the recorded exchanges, oldest first, and whether recording is enabled
+func RecordedExchanges() (exchanges []Exchange, enabled bool) {
+	recorderMu.RLock()
+	r := activeRecorder
+	recorderMu.RUnlock()
+
+	if r == nil {
+		return []Exchange{}, false
+	}
+	return r.list(), true
+}
+
+// ClearRecordedExchanges discards the recorded exchanges
+func ClearRecordedExchanges() {
+	recorderMu.RLock()
+	r := activeRecorder
+	recorderMu.RUnlock()
+
+	if r != nil {
+		r.mu.Lock()
+		clear(r.exchanges)
+		r.next, r.full = 0, false
+		r.mu.Unlock()
+	}
+}
+
+// add stores an exchange, replacing the oldest when the buffer is full
+func (r *recorder) add(exchange *Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.exchanges[r.next] = *exchange
+	r.next = (r.next + 1) % len(r.exchanges)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns the exchanges, oldest first
+func (r *recorder) list() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]Exchange{}, r.exchanges[:r.next]...)
+	}
+	return append(append([]Exchange{}, r.exchanges[r.next:]...), r.exchanges[:r.next]...)
+}
+
+// recordingTransport records every request and response passing through it
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder *recorder
+}
+
+// RoundTrip sends the request and records it together with the response. Bodies are
+// buffered so the caller still receives them unchanged.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := &Exchange{
+		Time:           time.Now(),
+		Method:         req.Method,
+		URL:            t.recorder.scrub(req.URL.String()),
+		RequestHeaders: t.recorder.scrubHeaders(req.Header),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.RequestBody = t.recorder.body(body, req.Header, &exchange.Truncated)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	exchange.DurationMs = time.Since(exchange.Time).Milliseconds()
+	if err != nil {
+		exchange.Error = t.recorder.scrub(err.Error())
+		t.recorder.add(exchange)
+		return nil, err
+	}
+
+	exchange.Status = resp.StatusCode
+	exchange.ResponseHeaders = t.recorder.scrubHeaders(resp.Header)
+	if resp.Body != nil {
+		body, readErr := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.ResponseBody = t.recorder.body(body, resp.Header, &exchange.Truncated)
+		if readErr != nil {
+			exchange.Error = t.recorder.scrub(readErr.Error())
+		}
+	}
+
+	t.recorder.add(exchange)
+	return resp, nil
+}
+
+// body returns a printable, scrubbed and truncated copy of a body. Binary bodies such as
+// FLAC uploads are summarized by their size and content type.
+func (r *recorder) body(body []byte, header http.Header, truncated *bool) string {
+	if len(body) == 0 || r.maxBodySize == 0 {
+		return ""
+	}
+	if !isTextContent(header) {
+		return "[" + formatSize(len(body)) + " of " + header.Get("Content-Type") + "]"
+	}
+	if len(body) > r.maxBodySize {
+		*truncated = true
+		body = body[:r.maxBodySize]
+	}
+	return r.scrub(strings.ToValidUTF8(string(body), "?"))
+}
+
+// scrub removes the station token from a string
+func (r *recorder) scrub(s string) string {
+	if r.secret == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, r.secret, "***")
+}
+
+// scrubHeaders copies the headers with credentials and the station token removed
+func (r *recorder) scrubHeaders(header http.Header) map[string][]string {
+	scrubbed := make(map[string][]string, len(header))
+	for name, values := range header {
+		copied := make([]string, len(values))
+		for i, value := range values {
+			copied[i] = r.scrub(value)
+		}
+		scrubbed[name] = copied
+	}
+	for _, name := range scrubbedHeaders {
+		if _, exists := scrubbed[name]; exists {
+			scrubbed[name] = []string{"***"}
+		}
+	}
+	return scrubbed
+}
+
+// isTextContent reports whether a body can be shown as text
+func isTextContent(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// Responses without a content type are usually short text or HTML error pages
+		return header.Get("Content-Type") == ""
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/x-www-form-urlencoded"
+}
+
+// formatSize formats a byte count for binary body summaries
+func formatSize(n int) string {
+	if n < 1024 {
+		return strconv.Itoa(n) + " bytes"
+	}
+	return strconv.Itoa(n/1024) + " KiB"
+}
@@ -0,0 +1,107 @@
+package birdweather
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorderCapturesScrubbedExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = fmt.Fprint(w, "<html><body><h1>502 Bad Gateway</h1>"+strings.Repeat("x", 200)+"</body></html>")
+	}))
+	defer server.Close()
+
+	settings := MockSettings()
+	settings.Realtime.Birdweather.Recorder.Enabled = true
+	settings.Realtime.Birdweather.Recorder.Size = 2
+	settings.Realtime.Birdweather.Recorder.MaxBodySize = 64
+	client, err := New(settings)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer configureRecorder(0, 0, "")
+
+	transport, ok := client.HTTPClient.Transport.(*recordingTransport)
+	if !ok {
+		t.Fatalf("expected recording transport, got %T", client.HTTPClient.Transport)
+	}
+	transport.next = &mockTransport{server: server}
+
+	if err := client.PostDetection("1", "2023-01-01T12:00:00.000-0500", "Eurasian Blackbird", "Turdus merula", 0.9); err == nil {
+		t.Fatal("expected an error for the HTML error page")
+	}
+
+	exchanges, enabled := RecordedExchanges()
+	if !enabled || len(exchanges) != 1 {
+		t.Fatalf("expected one recorded exchange, got %d (enabled %v)", len(exchanges), enabled)
+	}
+
+	exchange := exchanges[0]
+	if strings.Contains(exchange.URL, settings.Realtime.Birdweather.ID) || !strings.Contains(exchange.URL, "***") {
+		t.Errorf("station token not scrubbed from URL %q", exchange.URL)
+	}
+	if exchange.Method != http.MethodPost || exchange.Status != http.StatusBadGateway {
+		t.Errorf("unexpected method %q or status %d", exchange.Method, exchange.Status)
+	}
+	if !strings.HasPrefix(exchange.RequestBody, `{"timestamp":"2023-01-01T12:00:00.000-0500"`) {
+		t.Errorf("expected JSON request body, got %q", exchange.RequestBody)
+	}
+	if !strings.HasPrefix(exchange.ResponseBody, "<html><body><h1>502 Bad Gateway</h1>") || len(exchange.ResponseBody) != 64 || !exchange.Truncated {
+		t.Errorf("expected response body truncated to 64 bytes, got %d bytes %q", len(exchange.ResponseBody), exchange.ResponseBody)
+	}
+	if got := exchange.ResponseHeaders["Set-Cookie"]; len(got) != 1 || got[0] != "***" {
+		t.Errorf("Set-Cookie header not scrubbed: %v", got)
+	}
+
+	ClearRecordedExchanges()
+	if exchanges, _ := RecordedExchanges(); len(exchanges) != 0 {
+		t.Errorf("expected no exchanges after clear, got %d", len(exchanges))
+	}
+}
+
+func TestRecorderKeepsMostRecent(t *testing.T) {
+	r := &recorder{exchanges: make([]Exchange, 2)}
+	for i := range 3 {
+		r.add(&Exchange{Status: 200 + i})
+	}
+
+	exchanges := r.list()
+	if len(exchanges) != 2 || exchanges[0].Status != 201 || exchanges[1].Status != 202 {
+		t.Errorf("expected the two most recent exchanges oldest first, got %+v", exchanges)
+	}
+}
+
+func TestRecorderSummarizesBinaryBodies(t *testing.T) {
+	r := &recorder{maxBodySize: 1024}
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Encoding", "gzip")
+
+	var truncated bool
+	if got := r.body(make([]byte, 4096), header, &truncated); got != "[4 KiB of application/octet-stream]" {
+		t.Errorf("unexpected binary body summary %q", got)
+	}
+	if truncated {
+		t.Error("binary summaries are not truncated")
+	}
+}
+
+func TestRecorderDisabled(t *testing.T) {
+	settings := MockSettings()
+	client, err := New(settings)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, recording := client.HTTPClient.Transport.(*recordingTransport); recording {
+		t.Error("expected no recording transport when recording is disabled")
+	}
+	if _, enabled := RecordedExchanges(); enabled {
+		t.Error("recording should be disabled")
+	}
+}
@@ -165,7 +165,7 @@ func TestDescriptiveErrorMessages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := handleNetworkError(tt.baseErr, "https://test.com", 30*time.Second, tt.operation)
+			result := handleNetworkError(serviceLogger, tt.baseErr, "https://test.com", 30*time.Second, tt.operation)
 
 			if result == nil {
 				t.Fatal("Expected non-nil error")
@@ -0,0 +1,116 @@
+// conf/action_recipes.go built-in species action recipes
+package conf
+
+import (
+	"slices"
+	"strings"
+)
+
+// Species action types
+const (
+	ActionTypeExecuteCommand    = "ExecuteCommand"    // run a script with detection values as arguments
+	ActionTypeSendNotification  = "SendNotification"  // create a detection notification
+	ActionTypeBirdWeatherUpload = "BirdWeatherUpload" // upload to BirdWeather only when the action conditions are met
+)
+
+// Detection periods of action conditions
+const (
+	ActionPeriodDay   = "day"   // from sunrise to sunset
+	ActionPeriodNight = "night" // from sunset to sunrise
+)
+
+// defaultRecipeMinRarity is the rarity score of rarity recipes without their own
+const defaultRecipeMinRarity = 0.7
+
+// ActionRecipe is a ready-made species action configuration selected by name in the
+// recipes of a species configuration, so common automations need no action YAML
+type ActionRecipe struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	RequiresCommand bool   `json:"requiresCommand"` // true when the recipe runs the command of its selection
+
+	actions func(recipe *SpeciesRecipe) []SpeciesAction
+}
+
+// actionRecipes is the library of built-in recipes. Recipe actions always run the default
+// actions too, so selecting a recipe never stops detections from being saved.
+var actionRecipes = []ActionRecipe{
+	{
+		Name:        "notify",
+		Description: "Create a notification for every detection",
+		actions: func(*SpeciesRecipe) []SpeciesAction {
+			return []SpeciesAction{{Type: ActionTypeSendNotification, ExecuteDefaults: true}}
+		},
+	},
+	{
+		Name:        "notify-at-night",
+		Description: "Create a notification for detections between sunset and sunrise, e.g. for owls",
+		actions: func(*SpeciesRecipe) []SpeciesAction {
+			return []SpeciesAction{{
+				Type:            ActionTypeSendNotification,
+				ExecuteDefaults: true,
+				When:            ActionCondition{Period: ActionPeriodNight},
+			}}
+		},
+	},
+	{
+		Name:        "upload-rare-only",
+		Description: "Upload to BirdWeather only detections with a rarity score of at least minRarity (default 0.7)",
+		actions: func(recipe *SpeciesRecipe) []SpeciesAction {
+			minRarity := recipe.MinRarity
+			if minRarity == 0 {
+				minRarity = defaultRecipeMinRarity
+			}
+			return []SpeciesAction{{
+				Type:            ActionTypeBirdWeatherUpload,
+				ExecuteDefaults: true,
+				When:            ActionCondition{MinRarity: minRarity},
+			}}
+		},
+	},
+	{
+		Name:            "trigger-camera",
+		Description:     "Run a camera script with the species, confidence, date and time, one run at a time",
+		RequiresCommand: true,
+		actions: func(recipe *SpeciesRecipe) []SpeciesAction {
+			return []SpeciesAction{{
+				Type:            ActionTypeExecuteCommand,
+				Command:         recipe.Command,
+				Parameters:      []string{"CommonName", "ScientificName", "Confidence", "Date", "Time"},
+				ExecuteDefaults: true,
+				MaxConcurrent:   1,
+			}}
+		},
+	},
+}
+
+// ActionRecipes returns the built-in action recipes
+func ActionRecipes() []ActionRecipe {
+	return slices.Clone(actionRecipes)
+}
+
+// LookupActionRecipe returns the built-in recipe of a case-insensitive name
+func LookupActionRecipe(name string) (ActionRecipe, bool) {
+	for i := range actionRecipes {
+		if strings.EqualFold(actionRecipes[i].Name, strings.TrimSpace(name)) {
+			return actionRecipes[i], true
+		}
+	}
+	return ActionRecipe{}, false
+}
+
+// ExpandedActions returns the actions of the species configuration followed by the actions
+// of its recipes. Unknown recipes, rejected by validation, are skipped.
+func (c *SpeciesConfig) ExpandedActions() []SpeciesAction {
+	if len(c.Recipes) == 0 {
+		return c.Actions
+	}
+
+	actions := slices.Clone(c.Actions)
+	for i := range c.Recipes {
+		if recipe, ok := LookupActionRecipe(c.Recipes[i].Name); ok {
+			actions = append(actions, recipe.actions(&c.Recipes[i])...)
+		}
+	}
+	return actions
+}
@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandedActions(t *testing.T) {
+	t.Parallel()
+
+	config := SpeciesConfig{
+		Actions: []SpeciesAction{{Type: ActionTypeExecuteCommand, Command: "/usr/local/bin/log.sh", Parameters: []string{"CommonName"}}},
+		Recipes: []SpeciesRecipe{
+			{Name: "Notify-At-Night"},
+			{Name: "upload-rare-only"},
+			{Name: "upload-rare-only", MinRarity: 0.9},
+			{Name: "trigger-camera", Command: "/usr/local/bin/camera.sh"},
+			{Name: "unknown"},
+		},
+	}
+
+	actions := config.ExpandedActions()
+	require.Len(t, actions, 5, "configured actions come first and unknown recipes are skipped")
+	assert.Equal(t, config.Actions[0], actions[0])
+	assert.Equal(t, SpeciesAction{Type: ActionTypeSendNotification, ExecuteDefaults: true, When: ActionCondition{Period: ActionPeriodNight}}, actions[1])
+	assert.InDelta(t, defaultRecipeMinRarity, actions[2].When.MinRarity, 0)
+	assert.InDelta(t, 0.9, actions[3].When.MinRarity, 0)
+	assert.Equal(t, "/usr/local/bin/camera.sh", actions[4].Command)
+	assert.Equal(t, 1, actions[4].MaxConcurrent)
+	assert.Len(t, config.Actions, 1, "the configured actions are not modified")
+
+	plain := SpeciesConfig{Actions: config.Actions}
+	assert.Equal(t, plain.Actions, plain.ExpandedActions())
+}
+
+func TestValidateSpeciesRecipes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  SpeciesConfig
+		wantErr bool
+	}{
+		{"recipes", SpeciesConfig{Threshold: 0.8, Recipes: []SpeciesRecipe{{Name: "notify"}, {Name: "trigger-camera", Command: "/opt/camera.sh"}}}, false},
+		{"unknown recipe", SpeciesConfig{Recipes: []SpeciesRecipe{{Name: "notify-always"}}}, true},
+		{"recipe without command", SpeciesConfig{Recipes: []SpeciesRecipe{{Name: "trigger-camera"}}}, true},
+		{"relative command", SpeciesConfig{Recipes: []SpeciesRecipe{{Name: "trigger-camera", Command: "camera.sh"}}}, true},
+		{"recipe rarity out of range", SpeciesConfig{Recipes: []SpeciesRecipe{{Name: "upload-rare-only", MinRarity: 1.5}}}, true},
+		{"action condition", SpeciesConfig{Actions: []SpeciesAction{{Type: ActionTypeSendNotification, When: ActionCondition{Period: ActionPeriodDay, MinRarity: 0.5}}}}, false},
+		{"unknown period", SpeciesConfig{Actions: []SpeciesAction{{Type: ActionTypeSendNotification, When: ActionCondition{Period: "dusk"}}}}, true},
+		{"negative rarity", SpeciesConfig{Actions: []SpeciesAction{{Type: ActionTypeSendNotification, When: ActionCondition{MinRarity: -0.1}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			settings := &SpeciesSettings{Config: map[string]SpeciesConfig{"great horned owl": tt.config}}
+			err := validateSpeciesConfigSettings(settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSpeciesConfigSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,14 +8,17 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/privacy"
 	"gopkg.in/yaml.v3"
 )
 
@@ -40,6 +43,35 @@ type EqualizerFilter struct {
 	Passes    int     `json:"passes"` // Filter passes for added attenuation or gain
 }
 
+// CalibrationSettings contains the gain offsets measured for audio sources. Microphones and
+// cameras deliver audio at very different levels; a calibration measures the typical loudness
+// of a source and stores the offset bringing it to a common level, applied to exported clips
+// and uploads, and optionally to the audio analyzed.
+type CalibrationSettings struct {
+	TargetLUFS      float64              `json:"targetLufs"`      // typical loudness calibrated sources are brought to
+	Window          int                  `json:"window"`          // seconds of audio a calibration measures
+	ApplyToAnalysis bool                 `json:"applyToAnalysis"` // true to apply the offsets to the audio analyzed
+	Profiles        []CalibrationProfile `json:"profiles"`        // measured offsets, one per source
+}
+
+// CalibrationProfile is the gain offset measured for an audio source
+type CalibrationProfile struct {
+	Source       string    `json:"source"`       // sound card, or stream URL without credentials
+	Gain         float64   `json:"gain"`         // gain offset in dB
+	MeasuredLUFS float64   `json:"measuredLufs"` // typical loudness of the source without the offset
+	CalibratedAt time.Time `json:"calibratedAt"` // time of the calibration
+}
+
+// Profile returns the calibration profile of source
+func (s *CalibrationSettings) Profile(source string) (CalibrationProfile, bool) {
+	for _, profile := range s.Profiles {
+		if profile.Source == source {
+			return profile, true
+		}
+	}
+	return CalibrationProfile{}, false
+}
+
 // EqualizerSettings is a struct for audio EQ settings
 type EqualizerSettings struct {
 	Enabled bool              `json:"enabled"` // global flag to enable/disable equalizer filters
@@ -57,6 +89,16 @@ type ExportSettings struct {
 	PreCapture    int                   `json:"preCapture" mapstructure:"preCapture"`       // pre-capture in seconds
 	Gain          float64               `json:"gain" mapstructure:"gain"`                   // gain in dB for audio capture
 	Normalization NormalizationSettings `json:"normalization" mapstructure:"normalization"` // audio normalization settings (EBU R128)
+	Encoder       EncoderSettings       `json:"encoder" mapstructure:"encoder"`             // encoder selection and limits of the shared encoder pool
+}
+
+// EncoderSettings selects the FLAC encoder and limits the FFmpeg and SoX processes encoding
+// clips, uploads and snapshots
+type EncoderSettings struct {
+	Workers     int    `json:"workers" mapstructure:"workers"`         // encoder processes run at once
+	QueueSize   int    `json:"queueSize" mapstructure:"queueSize"`     // jobs waiting for a worker before new jobs are rejected
+	Timeout     int    `json:"timeout" mapstructure:"timeout"`         // seconds a job may run, not counting time queued
+	FlacEncoder string `json:"flacEncoder" mapstructure:"flacEncoder"` // preferred FLAC encoder, "native" or "ffmpeg"
 }
 
 // NormalizationSettings contains audio normalization configuration based on EBU R128 standard
@@ -86,14 +128,15 @@ type SoundLevelSettings struct {
 }
 
 type AudioSettings struct {
-	Source          string             `yaml:"source" mapstructure:"source" json:"source"`                   // audio source to use for analysis
-	FfmpegPath      string             `yaml:"ffmpegpath" mapstructure:"ffmpegpath" json:"ffmpegPath"`       // path to ffmpeg, runtime value
-	SoxPath         string             `yaml:"soxpath" mapstructure:"soxpath" json:"soxPath"`                // path to sox, runtime value
-	SoxAudioTypes   []string           `yaml:"-" json:"-"`                                                   // supported audio types of sox, runtime value
-	StreamTransport string             `json:"streamTransport"`                                              // preferred transport for audio streaming: "auto", "sse", or "ws"
-	Export          ExportSettings     `json:"export"`                                                       // export settings
-	SoundLevel      SoundLevelSettings `json:"soundLevel"`                                                   // sound level monitoring settings
-	UseAudioCore    bool               `yaml:"useaudiocore" mapstructure:"useaudiocore" json:"useAudioCore"` // true to use new audiocore package instead of myaudio
+	Source          string              `yaml:"source" mapstructure:"source" json:"source"`                   // audio source to use for analysis
+	FfmpegPath      string              `yaml:"ffmpegpath" mapstructure:"ffmpegpath" json:"ffmpegPath"`       // path to ffmpeg, runtime value
+	SoxPath         string              `yaml:"soxpath" mapstructure:"soxpath" json:"soxPath"`                // path to sox, runtime value
+	SoxAudioTypes   []string            `yaml:"-" json:"-"`                                                   // supported audio types of sox, runtime value
+	StreamTransport string              `json:"streamTransport"`                                              // preferred transport for audio streaming: "auto", "sse", or "ws"
+	Export          ExportSettings      `json:"export"`                                                       // export settings
+	Calibration     CalibrationSettings `json:"calibration"`                                                  // gain offsets of audio sources
+	SoundLevel      SoundLevelSettings  `json:"soundLevel"`                                                   // sound level monitoring settings
+	UseAudioCore    bool                `yaml:"useaudiocore" mapstructure:"useaudiocore" json:"useAudioCore"` // true to use new audiocore package instead of myaudio
 
 	Equalizer EqualizerSettings `json:"equalizer"` // equalizer settings
 }
@@ -101,8 +144,28 @@ type Thumbnails struct {
 	Debug          bool   `json:"debug"`          // true to enable debug mode
 	Summary        bool   `json:"summary"`        // show thumbnails on summary table
 	Recent         bool   `json:"recent"`         // show thumbnails on recent table
-	ImageProvider  string `json:"imageProvider"`  // preferred image provider: "auto", "wikimedia", "avicommons"
+	ImageProvider  string `json:"imageProvider"`  // preferred image provider: "auto", "wikimedia", "avicommons", "macaulay", "local"
 	FallbackPolicy string `json:"fallbackPolicy"` // fallback policy: "none", "all" - try all available providers if preferred fails
+
+	ProviderPriority []string               `json:"providerPriority"` // order of providers tried by the fallback policy, unlisted providers follow
+	LocalPath        string                 `json:"localPath"`        // folder of custom species photos named by scientific name, empty to disable
+	Macaulay         bool                   `json:"macaulay"`         // true to enable images from the Macaulay Library
+	DiskCache        ImageDiskCacheSettings `json:"diskCache"`        // on-disk cache of downloaded images
+	Prefetch         ImagePrefetchSettings  `json:"prefetch"`         // background fetching of images for newly included species
+}
+
+// ImageDiskCacheSettings contains settings for keeping downloaded species images on disk, so
+// they are served locally and survive restarts
+type ImageDiskCacheSettings struct {
+	Enabled bool   `json:"enabled"` // true to serve species images from the disk cache
+	Path    string `json:"path"`    // folder of the cached image files
+}
+
+// ImagePrefetchSettings contains settings for fetching the images of species added to the range
+// filter in the background, so the first detection of a species does not wait on a fetch
+type ImagePrefetchSettings struct {
+	Enabled        bool `json:"enabled"`        // true to prefetch images after range filter updates
+	BandwidthLimit int  `json:"bandwidthLimit"` // maximum download rate of prefetched images in KiB/s, 0 for no limit
 }
 
 // Dashboard contains settings for the web dashboard.
@@ -122,6 +185,26 @@ type DynamicThresholdSettings struct {
 	ValidHours int     `json:"validHours"` // number of hours to consider for dynamic threshold
 }
 
+// SeasonalPriorSettings contains settings for adjusting species confidence thresholds by
+// the week-of-year occurrence probability reported by the range filter model.
+type SeasonalPriorSettings struct {
+	Enabled  bool    `json:"enabled"`  // true to enable seasonal threshold adjustment
+	Strength float64 `json:"strength"` // maximum threshold adjustment in either direction
+	Pivot    float64 `json:"pivot"`    // occurrence probability at which the threshold is left unchanged
+	Min      float64 `json:"min"`      // adjusted threshold will not go lower than this
+	Max      float64 `json:"max"`      // adjusted threshold will not go higher than this
+}
+
+// RaritySettings contains settings for scoring how unusual a detection is for the station and
+// week of the year by combining the range filter occurrence with the local detection history.
+type RaritySettings struct {
+	Enabled         bool    `json:"enabled"`         // true to store a rarity score with each detection
+	RangeWeight     float64 `json:"rangeWeight"`     // weight of the range model occurrence, the local history gets the rest
+	HistoryYears    int     `json:"historyYears"`    // number of past years of local detections considered
+	WindowDays      int     `json:"windowDays"`      // days before and after the detection date included from each year
+	NotifyThreshold float64 `json:"notifyThreshold"` // create a notification for detections scoring at least this, 0 to disable
+}
+
 // RetrySettings contains common settings for retry mechanisms
 type RetrySettings struct {
 	Enabled           bool    `json:"enabled"`           // true to enable retry mechanism
@@ -133,12 +216,38 @@ type RetrySettings struct {
 
 // BirdweatherSettings contains settings for BirdWeather API integration.
 type BirdweatherSettings struct {
-	Enabled          bool          `json:"enabled"`          // true to enable birdweather uploads
-	Debug            bool          `json:"debug"`            // true to enable debug mode
-	ID               string        `json:"id"`               // birdweather ID
-	Threshold        float64       `json:"threshold"`        // threshold for prediction confidence for uploads
-	LocationAccuracy float64       `json:"locationAccuracy"` // accuracy of location in meters
-	RetrySettings    RetrySettings `json:"retrySettings"`    // settings for retry mechanism
+	Enabled          bool                 `json:"enabled"`          // true to enable birdweather uploads
+	Debug            bool                 `json:"debug"`            // true to enable debug mode
+	ID               string               `json:"id"`               // birdweather ID
+	Threshold        float64              `json:"threshold"`        // threshold for prediction confidence for uploads
+	LocationAccuracy float64              `json:"locationAccuracy"` // accuracy of location in meters
+	RetrySettings    RetrySettings        `json:"retrySettings"`    // settings for retry mechanism
+	Recorder         HTTPRecorderSettings `json:"recorder"`         // recording of API requests for troubleshooting
+	UploadQuota      UploadQuotaSettings  `json:"uploadQuota"`      // daily upload limits per species
+	Normalization    string               `json:"normalization"`    // loudness normalization of soundscapes encoded with FFmpeg
+}
+
+// Loudness normalization modes of BirdWeather soundscapes encoded with FFmpeg
+const (
+	NormalizationTwoPass    = "twopass"    // analyze every soundscape, then encode it with the gain it needs
+	NormalizationSinglePass = "singlepass" // encode with the dynamic loudnorm filter in one FFmpeg run
+	NormalizationCached     = "cached"     // reuse the gain of the audio source, analyzed again every hour
+)
+
+// UploadQuotaSettings contains daily upload limits per species. Counters reset at local
+// midnight, detections over the limit are still saved locally.
+type UploadQuotaSettings struct {
+	Enabled      bool           `json:"enabled"`      // true to limit daily uploads per species
+	DefaultLimit int            `json:"defaultLimit"` // daily uploads of species without own limit, 0 for no limit
+	Species      map[string]int `json:"species"`      // daily uploads per common or scientific name, 0 for no limit
+}
+
+// HTTPRecorderSettings contains settings for keeping recent HTTP requests and responses in
+// memory for troubleshooting
+type HTTPRecorderSettings struct {
+	Enabled     bool `json:"enabled"`     // true to record requests and responses
+	Size        int  `json:"size"`        // number of request/response pairs to keep
+	MaxBodySize int  `json:"maxBodySize"` // bytes of each request and response body to keep
 }
 
 // EBirdSettings contains settings for eBird API integration.
@@ -151,11 +260,21 @@ type EBirdSettings struct {
 
 // WeatherSettings contains all weather-related settings
 type WeatherSettings struct {
-	Provider     string               `json:"provider"`     // "none", "yrno", "openweather", or "wunderground"
+	Provider     string               `json:"provider"`     // "none", "yrno", "openweather", "wunderground", "openmeteo" or "mqtt"
 	PollInterval int                  `json:"pollInterval"` // weather data polling interval in minutes
 	Debug        bool                 `json:"debug"`        // true to enable debug mode
 	OpenWeather  OpenWeatherSettings  `json:"openWeather"`  // OpenWeather integration settings
 	Wunderground WundergroundSettings `json:"wunderground"` // WeatherUnderground integration settings
+	MQTT         WeatherMQTTSettings  `json:"mqtt"`         // local weather sensor settings
+}
+
+// WeatherMQTTSettings contains settings for reading weather data from a local sensor
+// publishing JSON readings to an MQTT topic.
+type WeatherMQTTSettings struct {
+	Broker   string `json:"broker"`   // MQTT broker URL
+	Topic    string `json:"topic"`    // topic the sensor publishes readings to
+	Username string `json:"username"` // MQTT username
+	Password string `json:"password"` // MQTT password
 }
 
 // WundergroundSettings contains settings for WeatherUnderground integration.
@@ -175,11 +294,19 @@ type OpenWeatherSettings struct {
 	Language string `json:"language"` // language code for the response
 }
 
+// Privacy filter modes
+const (
+	PrivacyFilterModeDiscard = "discard" // discard detections coinciding with a human voice
+	PrivacyFilterModeRedact  = "redact"  // keep detections and mute the human voice in their audio
+)
+
 // PrivacyFilterSettings contains settings for the privacy filter.
 type PrivacyFilterSettings struct {
 	Debug      bool    `json:"debug"`      // true to enable debug mode
 	Enabled    bool    `json:"enabled"`    // true to enable privacy filter
 	Confidence float32 `json:"confidence"` // confidence threshold for human detection
+	Window     int     `json:"window"`     // seconds detections are discarded after a human voice, 0 to discard only detections overlapping it
+	Mode       string  `json:"mode"`       // "discard" to drop detections or "redact" to keep them with the human voice muted in clips
 }
 
 // DogBarkFilterSettings contains settings for the dog bark filter.
@@ -191,10 +318,19 @@ type DogBarkFilterSettings struct {
 	Species    []string `json:"species"`    // species list for filtering
 }
 
+// Restart policies of RTSP streams
+const (
+	RTSPRestartAlways = "always" // restart streams whose FFmpeg exits or that stop delivering audio
+	RTSPRestartExit   = "exit"   // restart streams whose FFmpeg exits, only report stalled streams
+)
+
 // RTSPHealthSettings contains settings for RTSP stream health monitoring.
 type RTSPHealthSettings struct {
-	HealthyDataThreshold int `json:"healthyDataThreshold"` // seconds before stream considered unhealthy (default: 60)
-	MonitoringInterval   int `json:"monitoringInterval"`   // health check interval in seconds (default: 30)
+	HealthyDataThreshold int    `json:"healthyDataThreshold"` // seconds before stream considered unhealthy (default: 60)
+	MonitoringInterval   int    `json:"monitoringInterval"`   // health check interval in seconds (default: 30)
+	RestartPolicy        string `json:"restartPolicy"`        // always or exit, see RTSPRestartAlways (default: always)
+	InitialBackoff       int    `json:"initialBackoff"`       // seconds before the first restart, doubled for each consecutive restart (default: 5)
+	MaxBackoff           int    `json:"maxBackoff"`           // longest wait between restarts in seconds (default: 120)
 }
 
 // RTSPSettings contains settings for RTSP streaming.
@@ -203,19 +339,42 @@ type RTSPSettings struct {
 	URLs             []string           `json:"urls"`             // RTSP stream URL
 	Health           RTSPHealthSettings `json:"health"`           // health monitoring settings
 	FFmpegParameters []string           `json:"ffmpegParameters"` // optional custom FFmpeg parameters
+	CorrelateSources bool               `json:"correlateSources"` // true to record all sources contributing to a detection
 }
 
 // MQTTSettings contains settings for MQTT integration.
 type MQTTSettings struct {
-	Enabled       bool            `json:"enabled"`       // true to enable MQTT
-	Debug         bool            `json:"debug"`         // true to enable MQTT debug
-	Broker        string          `json:"broker"`        // MQTT broker URL
-	Topic         string          `json:"topic"`         // MQTT topic
-	Username      string          `json:"username"`      // MQTT username
-	Password      string          `json:"password"`      // MQTT password
-	Retain        bool            `json:"retain"`        // true to retain messages
-	RetrySettings RetrySettings   `json:"retrySettings"` // settings for retry mechanism
-	TLS           MQTTTLSSettings `json:"tls"`           // TLS/SSL configuration
+	Enabled       bool              `json:"enabled"`       // true to enable MQTT
+	Debug         bool              `json:"debug"`         // true to enable MQTT debug
+	Broker        string            `json:"broker"`        // MQTT broker URL
+	Topic         string            `json:"topic"`         // MQTT topic
+	Username      string            `json:"username"`      // MQTT username
+	Password      string            `json:"password"`      // MQTT password
+	Retain        bool              `json:"retain"`        // true to retain messages
+	RetrySettings RetrySettings     `json:"retrySettings"` // settings for retry mechanism
+	TLS           MQTTTLSSettings   `json:"tls"`           // TLS/SSL configuration
+	Batch         MQTTBatchSettings `json:"batch"`         // batched publishing of detections
+	V5            MQTTV5Settings    `json:"v5"`            // MQTT 5 protocol features
+}
+
+// MQTTV5Settings contains settings for connecting with MQTT 5. Messages published with
+// MQTT 5 carry a content type, an optional expiry and user properties with the payload
+// schema version and the station name. Stations of an HA cluster subscribe to the command
+// topic with the same shared group, so the broker delivers each command to one of them.
+type MQTTV5Settings struct {
+	Enabled       bool   `json:"enabled"`       // true to connect with MQTT 5 instead of MQTT 3.1.1
+	MessageExpiry int    `json:"messageExpiry"` // seconds the broker keeps undelivered messages, 0 to keep them without expiry
+	ContentType   string `json:"contentType"`   // content type of published messages
+	CommandTopic  string `json:"commandTopic"`  // topic to receive control commands from, empty to not subscribe
+	SharedGroup   string `json:"sharedGroup"`   // shared subscription group of the command topic, empty for a regular subscription
+}
+
+// MQTTBatchSettings contains settings for publishing detections in batches. Batching reduces
+// the message rate during busy periods such as the dawn chorus on constrained networks.
+type MQTTBatchSettings struct {
+	Enabled  bool `json:"enabled"`  // true to publish detections as a JSON array per interval instead of one message each
+	Interval int  `json:"interval"` // seconds to collect detections before publishing a batch
+	MaxSize  int  `json:"maxSize"`  // number of detections that publishes a batch before the interval ends
 }
 
 // MQTTTLSSettings contains TLS/SSL configuration for secure MQTT connections
@@ -227,6 +386,31 @@ type MQTTTLSSettings struct {
 	ClientKey          string `yaml:"clientkey,omitempty" json:"clientKey,omitempty"`   // path to client key file (managed internally)
 }
 
+// OutboundTLSSettings contains TLS settings shared by the connections to external services:
+// BirdWeather, the MQTT broker and the weather providers. The CA bundle is trusted in addition
+// to the system roots, and the client certificate is presented to servers that request one.
+type OutboundTLSSettings struct {
+	CACert      string              `json:"caCert"`      // path to a PEM CA bundle, empty for system roots only
+	ClientCert  string              `json:"clientCert"`  // path to a PEM client certificate for mutual TLS
+	ClientKey   string              `json:"clientKey"`   // path to the PEM key of the client certificate
+	BirdWeather TLSEndpointSettings `json:"birdweather"` // BirdWeather API connections
+	Weather     TLSEndpointSettings `json:"weather"`     // weather provider connections
+}
+
+// OutboundProxySettings contains the proxy for connections to external services: BirdWeather,
+// telemetry, image providers and weather providers. Without a proxy URL the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables apply.
+type OutboundProxySettings struct {
+	URL     string `json:"url"`     // http, https or socks5 proxy URL, may include user and password
+	NoProxy string `json:"noProxy"` // comma-separated hosts, domains and CIDR ranges reached directly
+}
+
+// TLSEndpointSettings contains TLS settings of the connections of one integration. MQTT uses
+// the insecureSkipVerify setting of its own TLS settings.
+type TLSEndpointSettings struct {
+	InsecureSkipVerify bool `json:"insecureSkipVerify"` // true to skip server certificate verification
+}
+
 // TelemetrySettings contains settings for telemetry.
 type TelemetrySettings struct {
 	Enabled bool   `json:"enabled"` // true to enable Prometheus compatible telemetry endpoint
@@ -265,13 +449,217 @@ type SentrySettings struct {
 	Debug   bool `json:"debug"`   // true to enable transparent telemetry logging
 }
 
+// PrivacySettings configures the anonymization policy scrubbing sensitive data from
+// telemetry, support bundle logs, notifications and, when enabled, MQTT payloads
+type PrivacySettings struct {
+	Scrubbers    []string          `json:"scrubbers"`    // scrubbers to run: urls, emails, uuids, ips, coordinates, tokens; empty for all
+	Replacements map[string]string `json:"replacements"` // replacement token per scrubber name, e.g. emails: "<email>"
+	AllowedHosts []string          `json:"allowedHosts"` // host names and IP addresses that are never scrubbed
+	ScrubMQTT    bool              `json:"scrubMqtt"`    // true to scrub audio source names and URLs in MQTT payloads
+}
+
+// Policy returns the anonymization policy of the privacy settings
+func (s *PrivacySettings) Policy() *privacy.Policy {
+	return &privacy.Policy{
+		Scrubbers:    s.Scrubbers,
+		Replacements: s.Replacements,
+		AllowedHosts: s.AllowedHosts,
+	}
+}
+
+// Self-update release channels
+const (
+	UpdateChannelStable = "stable" // tagged releases
+	UpdateChannelBeta   = "beta"   // release candidates and nightly builds
+)
+
+// UpdateSettings contains settings for the binary self-update mechanism. Releases are
+// listed in a feed per channel and every binary must carry an ed25519 signature made
+// with the private key matching PublicKey.
+type UpdateSettings struct {
+	Enabled        bool   `json:"enabled"`        // true to allow updating the binary from the CLI and API
+	Channel        string `json:"channel"`        // release channel, "stable" or "beta"
+	FeedURL        string `json:"feedUrl"`        // URL of the release feed
+	PublicKey      string `json:"publicKey"`      // base64 encoded ed25519 public key used to verify releases
+	RestartCommand string `json:"restartCommand"` // command that restarts the service, empty to exit and rely on the service manager restart policy
+	HealthTimeout  int    `json:"healthTimeout"`  // seconds the updated binary has to pass its health check before it is rolled back
+}
+
+// SMTP connection encryption modes
+const (
+	SMTPEncryptionNone     = "none"     // plain connection, only suitable for a local relay
+	SMTPEncryptionStartTLS = "starttls" // upgrade a plain connection with STARTTLS, usually port 587
+	SMTPEncryptionTLS      = "tls"      // implicit TLS, usually port 465
+)
+
+// Email digest intervals
+const (
+	DigestIntervalDaily  = "daily"  // digest of the previous day
+	DigestIntervalWeekly = "weekly" // digest of the previous seven days
+)
+
+// EmailSettings contains settings for email reports
+type EmailSettings struct {
+	Enabled bool                `json:"enabled"` // true to enable email reports
+	SMTP    SMTPSettings        `json:"smtp"`    // outgoing mail server settings
+	Digest  EmailDigestSettings `json:"digest"`  // detection digest settings
+}
+
+// SMTPSettings contains outgoing mail server settings
+type SMTPSettings struct {
+	Host               string   `json:"host"`               // SMTP server hostname
+	Port               int      `json:"port"`               // SMTP server port
+	Username           string   `json:"username"`           // username for SMTP authentication, empty for none
+	Password           string   `json:"password"`           // password for SMTP authentication
+	From               string   `json:"from"`               // sender address
+	To                 []string `json:"to"`                 // recipient addresses
+	Encryption         string   `json:"encryption"`         // connection encryption: "none", "starttls" or "tls"
+	InsecureSkipVerify bool     `json:"insecureSkipVerify"` // true to skip TLS certificate verification, e.g. for self-signed relays
+}
+
+// EmailDigestSettings contains settings for the scheduled detection digest email
+type EmailDigestSettings struct {
+	Interval string `json:"interval"` // "daily" or "weekly"
+	Time     string `json:"time"`     // local time of day to send the digest, in HH:MM format
+	Weekday  string `json:"weekday"`  // day of the week to send a weekly digest, e.g. "monday"
+	MaxClips int    `json:"maxClips"` // maximum number of top clips to include
+	BaseURL  string `json:"baseUrl"`  // external URL of the web interface used for clip links, empty to omit links
+	Template string `json:"template"` // path to a custom HTML template, empty for the built-in template
+}
+
+// eBird checklist grouping rules
+const (
+	ChecklistGroupingHourly = "hourly" // one checklist per hour with detections
+	ChecklistGroupingDaily  = "daily"  // one checklist per day with detections
+)
+
+// Raven selection table grouping rules
+const (
+	RavenTablePerDay  = "day"  // one table per day, times relative to midnight
+	RavenTablePerClip = "clip" // one table per saved audio clip, times relative to the clip start
+)
+
+// DataExportSettings contains settings for bulk detection exports
+type DataExportSettings struct {
+	Path  string              `json:"path"`  // directory where export jobs write their files
+	EBird EBirdExportSettings `json:"ebird"` // eBird Record Format export
+	Raven RavenExportSettings `json:"raven"` // Raven Pro selection table export
+}
+
+// TrashSettings contains settings for deleted detections, which are kept in the trash and
+// can be restored until the retention window passes
+type TrashSettings struct {
+	RetentionDays int `json:"retentionDays"` // days deleted detections are kept, 0 to purge them at the next hourly purge
+}
+
+// LoggingSettings contains the log levels of individual components, changeable at runtime,
+// and the outputs forwarding log records to central log collection
+type LoggingSettings struct {
+	Levels   map[string]string `json:"levels"`   // component name, such as "mqtt" or "birdweather", to level name
+	Syslog   SyslogSettings    `json:"syslog"`   // forwarding to a syslog server
+	Journald JournaldSettings  `json:"journald"` // forwarding to the systemd journal
+}
+
+// SyslogSettings forwards the log records of the file loggers to a syslog server as
+// RFC 5424 messages, with their attributes as structured data. TLS connections use the
+// CA bundle and client certificate of the outbound TLS settings.
+type SyslogSettings struct {
+	Enabled            bool   `json:"enabled"`            // true to forward log records
+	Network            string `json:"network"`            // udp, tcp or tls
+	Address            string `json:"address"`            // host:port of the syslog server
+	Facility           string `json:"facility"`           // syslog facility, e.g. daemon or local0
+	Tag                string `json:"tag"`                // application name of the messages
+	Level              string `json:"level"`              // minimum level forwarded, empty for every logged record
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"` // true to skip server certificate verification with tls
+}
+
+// JournaldSettings forwards the log records of the file loggers to the systemd journal,
+// with their attributes as journal fields
+type JournaldSettings struct {
+	Enabled bool   `json:"enabled"` // true to forward log records
+	Level   string `json:"level"`   // minimum level forwarded, empty for every logged record
+}
+
+// EventSinkSettings forwards error, resource and detection events of the event bus to an
+// external message broker, so fleet operators can aggregate the events of many nodes. Events
+// stay queued until the broker acknowledges them and may be delivered more than once. TLS
+// connections use the CA bundle and client certificate of the outbound TLS settings.
+type EventSinkSettings struct {
+	Enabled            bool            `json:"enabled"`            // true to forward events
+	Broker             string          `json:"broker"`             // nats, kafka or redis
+	Address            string          `json:"address"`            // host:port of the broker, comma-separated bootstrap brokers for kafka
+	TLS                bool            `json:"tls"`                // true to connect with TLS
+	InsecureSkipVerify bool            `json:"insecureSkipVerify"` // true to skip broker certificate verification with tls
+	Username           string          `json:"username"`           // NATS user or Redis ACL user
+	Password           string          `json:"password"`           // NATS or Redis password, a NATS token without a username
+	JetStream          bool            `json:"jetStream"`          // true to wait for NATS JetStream acknowledgements
+	Topics             EventSinkTopics `json:"topics"`             // topic, subject or stream key per event type
+	BatchSize          int             `json:"batchSize"`          // events published at once
+	FlushInterval      int             `json:"flushInterval"`      // seconds before a partial batch is published
+	MaxPending         int             `json:"maxPending"`         // events queued while the broker is unreachable
+	StreamMaxLen       int             `json:"streamMaxLen"`       // approximate length Redis streams are trimmed to, 0 to keep all
+}
+
+// EventSinkTopics maps event types to topics, NATS subjects or Redis stream keys. Topics may
+// contain the {node} placeholder, and {component}, {resource} or {species} for errors,
+// resources and detections. Events of a type with an empty topic are not forwarded.
+type EventSinkTopics struct {
+	Errors     string `json:"errors"`     // topic of error events
+	Resources  string `json:"resources"`  // topic of resource monitoring events
+	Detections string `json:"detections"` // topic of detection events
+}
+
+// Web Push urgencies, from notifications a device may deliver when convenient to ones it
+// delivers at once
+var WebPushUrgencies = []string{"very-low", "low", "normal", "high"}
+
+// WebPushSettings contains settings for Web Push notifications, sent through the push service
+// of the browser to dashboards installed as PWAs, e.g. on phones. Detection notifications are
+// pushed to the subscriptions stored in the datastore. The VAPID key pair identifying this
+// server to push services is generated when push is enabled without keys; replacing it
+// invalidates all subscriptions.
+type WebPushSettings struct {
+	Enabled    bool   `json:"enabled"`    // true to push detection notifications to subscribed browsers
+	Subject    string `json:"subject"`    // contact of the operator for push services, a mailto: or https: URL
+	PublicKey  string `json:"publicKey"`  // VAPID public key, base64url encoded
+	PrivateKey string `json:"privateKey"` // VAPID private key, base64url encoded
+	TTL        int    `json:"ttl"`        // seconds push services keep notifications for offline devices
+	Urgency    string `json:"urgency"`    // very-low, low, normal or high
+}
+
+// RavenExportSettings contains settings for exporting detections as Raven Pro selection tables
+type RavenExportSettings struct {
+	Grouping string                         `json:"grouping"` // "day" or "clip" tables
+	LowFreq  float64                        `json:"lowFreq"`  // default lower bound of selections in Hz
+	HighFreq float64                        `json:"highFreq"` // default upper bound of selections in Hz
+	Species  map[string]RavenFrequencyRange `json:"species"`  // frequency bounds by lowercase scientific or common name
+}
+
+// RavenFrequencyRange is the frequency band of a species in Raven selections
+type RavenFrequencyRange struct {
+	LowFreq  float64 `json:"lowFreq"`  // lower bound in Hz
+	HighFreq float64 `json:"highFreq"` // upper bound in Hz
+}
+
+// EBirdExportSettings contains settings for exporting detections in eBird Record Format
+type EBirdExportSettings struct {
+	Grouping      string  `json:"grouping"`      // "hourly" or "daily" checklists
+	MinConfidence float64 `json:"minConfidence"` // minimum detection confidence to include
+	LocationName  string  `json:"locationName"`  // eBird location name, empty to use the node name
+	StateCode     string  `json:"stateCode"`     // state or province code, e.g. "CA" or "ON"
+	CountryCode   string  `json:"countryCode"`   // two-letter country code, e.g. "US"
+}
+
 // RealtimeSettings contains all settings related to realtime processing.
 type RealtimeSettings struct {
 	Interval         int                      `json:"interval"`         // minimum interval between log messages in seconds
+	EventIntervals   EventIntervalSettings    `json:"eventIntervals"`   // Duplicate suppression intervals per integration
 	ProcessingTime   bool                     `json:"processingTime"`   // true to report processing time for each prediction
 	Audio            AudioSettings            `json:"audio"`            // Audio processing settings
 	Dashboard        Dashboard                `json:"dashboard"`        // Dashboard settings
 	DynamicThreshold DynamicThresholdSettings `json:"dynamicThreshold"` // Dynamic threshold settings
+	SeasonalPrior    SeasonalPriorSettings    `json:"seasonalPrior"`    // Seasonal occurrence threshold adjustment settings
+	Rarity           RaritySettings           `json:"rarity"`           // Detection rarity scoring settings
 	Log              struct {
 		Enabled bool   `json:"enabled"` // true to enable OBS chat log
 		Path    string `json:"path"`    // path to OBS chat log
@@ -289,21 +677,207 @@ type RealtimeSettings struct {
 	Species          SpeciesSettings          `json:"species"`          // Custom thresholds and actions for species
 	Weather          WeatherSettings          `json:"weather"`          // Weather provider related settings
 	SpeciesTracking  SpeciesTrackingSettings  `json:"speciesTracking"`  // New species tracking settings
+	MorningBrief     MorningBriefSettings     `json:"morningBrief"`     // Daily morning brief notification settings
+	SourceOverrides  []SourceOverride         `json:"sourceOverrides"`  // Per audio source threshold and species filter overrides
+	Profiles         []ProcessingProfile      `json:"profiles"`         // Independent analysis pipelines for assigned sources
+	Schedule         AnalysisScheduleSettings `json:"schedule"`         // Sunrise/sunset based analysis window
+	Drain            DrainSettings            `json:"drain"`            // Graceful drain before shutdown
+	Systemd          SystemdSettings          `json:"systemd"`          // Readiness and watchdog notifications to systemd
+	Overload         OverloadSettings         `json:"overload"`         // Shedding of optional actions under overload
+	Collection       CollectionSettings       `json:"collection"`       // Training data collection mode
+	CommandGuard     CommandGuardSettings     `json:"commandGuard"`     // Rate limits of ExecuteCommand actions
+}
+
+// CollectionSettings controls the training data collection mode. While enabled, every
+// analyzed segment above the level threshold is stored with its BirdNET results, whether
+// or not it produced a detection, as a dataset for building custom regional models.
+type CollectionSettings struct {
+	Enabled       bool    `json:"enabled"`       // true to store analyzed segments
+	Path          string  `json:"path"`          // folder of the collected segments
+	MinLevel      float64 `json:"minLevel"`      // minimum RMS level of stored segments in dBFS
+	MaxSizeMB     int     `json:"maxSizeMB"`     // disk quota, the oldest segments are removed beyond it
+	RetentionDays int     `json:"retentionDays"` // segments are removed after this many days
+}
+
+// OverloadSettings controls shedding under extreme load, e.g. many audio sources on a slow
+// SD card. Detections not processed within the deadline skip optional actions such as SSE
+// broadcasts and MQTT publishes, the database save always completes.
+type OverloadSettings struct {
+	Enabled  bool `json:"enabled"`  // true to shed optional actions of late detections
+	Deadline int  `json:"deadline"` // seconds from the start of a detection until its optional actions are shed
+}
+
+// CommandGuardSettings limits how often ExecuteCommand species actions run their command,
+// so a misconfigured action can't spawn a script for every detection. A command rejected
+// by the limits TripAfter times within an hour is disabled until it is re-enabled through
+// the control API or the application restarts.
+type CommandGuardSettings struct {
+	Enabled       bool `json:"enabled"`       // true to apply the limits
+	MaxPerHour    int  `json:"maxPerHour"`    // runs of a command per rolling hour, 0 for no limit
+	MaxConcurrent int  `json:"maxConcurrent"` // simultaneous runs of a command, 0 for no limit
+	TripAfter     int  `json:"tripAfter"`     // rejections within an hour that disable a command, 0 to never disable
+}
+
+// EventIntervalSettings contains the minimum interval in seconds between repeated events
+// of a species per integration. Zero uses the global realtime interval, and a species
+// interval in realtime.species.config takes precedence over both.
+type EventIntervalSettings struct {
+	Database     int `json:"database"`     // database saves
+	Log          int `json:"log"`          // log file entries
+	Notification int `json:"notification"` // notifications
+	BirdWeather  int `json:"birdweather"`  // BirdWeather uploads
+	MQTT         int `json:"mqtt"`         // MQTT publishes
+	SSE          int `json:"sse"`          // live detection broadcasts
+}
+
+// Validate checks that the intervals are between zero and one day
+func (s *EventIntervalSettings) Validate() error {
+	intervals := []struct {
+		integration string
+		interval    int
+	}{
+		{"database", s.Database},
+		{"log", s.Log},
+		{"notification", s.Notification},
+		{"birdweather", s.BirdWeather},
+		{"mqtt", s.MQTT},
+		{"sse", s.SSE},
+	}
+	for _, i := range intervals {
+		if i.interval < 0 || i.interval > 86400 {
+			return errors.Newf("%s event interval must be between 0 and 86400 seconds, got %d", i.integration, i.interval).
+				Component("config").
+				Category(errors.CategoryValidation).
+				Context("integration", i.integration).
+				Build()
+		}
+	}
+	return nil
+}
+
+// DrainSettings controls draining on shutdown. While draining, new detections are no
+// longer accepted and readiness probes fail, but held detections and queued actions such
+// as database saves and uploads are allowed to finish before the process exits.
+type DrainSettings struct {
+	Enabled bool `json:"enabled"` // true to drain on SIGTERM before shutting down
+	Timeout int  `json:"timeout"` // maximum seconds to wait for queued actions to finish
+}
+
+// SystemdSettings controls the sd_notify integration when running as a systemd service with
+// Type=notify. READY=1 is sent once capture and analysis have started, and when the unit sets
+// WatchdogSec, WATCHDOG=1 heartbeats are sent only while the capture and analysis loops make
+// progress, so systemd restarts a service whose pipeline stalled even though the process is alive.
+type SystemdSettings struct {
+	Enabled      bool `json:"enabled"`      // true to send notifications when NOTIFY_SOCKET is set
+	StallTimeout int  `json:"stallTimeout"` // seconds without pipeline progress before heartbeats stop
+}
+
+// DatastoreSpoolSettings controls the degraded mode entered when the database becomes
+// unavailable. Detections that cannot be saved are buffered on disk, up to a limit, and saved
+// in order once the database recovers; an event is emitted on entering and leaving the mode.
+type DatastoreSpoolSettings struct {
+	Enabled       bool   `json:"enabled"`       // true to buffer detections while the database is unavailable
+	Path          string `json:"path"`          // directory of the buffered detections
+	MaxDetections int    `json:"maxDetections"` // detections buffered at most, newer ones are lost when full
+	RetryInterval int    `json:"retryInterval"` // seconds between attempts to save the buffered detections
+}
+
+// Analysis schedule modes
+const (
+	ScheduleModeDay    = "day"    // analyze from civil dawn to civil dusk
+	ScheduleModeNight  = "night"  // analyze from civil dusk to civil dawn, e.g. for nightjars and owls
+	ScheduleModeCustom = "custom" // analyze between the configured start and end events
+)
+
+// AnalysisScheduleSettings restricts analysis to a daily window relative to sun events
+// calculated from the BirdNET latitude and longitude. Analysis is paused outside the
+// window to save power; a window ending before it starts spans midnight.
+type AnalysisScheduleSettings struct {
+	Enabled bool           `json:"enabled"` // true to pause analysis outside the window
+	Mode    string         `json:"mode"`    // "day", "night" or "custom"
+	Start   SunEventOffset `json:"start"`   // window start in custom mode
+	End     SunEventOffset `json:"end"`     // window end in custom mode
+}
+
+// SunEventOffset is a time of day relative to a sun event
+type SunEventOffset struct {
+	Event  string `json:"event"`  // "civildawn", "sunrise", "sunset" or "civildusk"
+	Offset int    `json:"offset"` // minutes from the event, negative for before
+}
+
+// ProcessingProfile defines an independent analysis pipeline with its own threshold,
+// species lists, actions and optionally its own model. Detections from the assigned sources
+// are handled by a separate processor instance that shares the datastore and integrations.
+// A source can be assigned to several profiles and feeds all of them; sources not assigned
+// to a profile using the main model also feed the default pipeline.
+type ProcessingProfile struct {
+	Name      string          `json:"name"`      // profile name used in logs and recorded with detections
+	Sources   []string        `json:"sources"`   // assigned audio sources: source ID, display name or RTSP URL
+	Threshold float64         `json:"threshold"` // confidence threshold, 0 to use the global threshold
+	ModelPath string          `json:"modelPath"` // custom model run on the audio of the sources, empty to use the main model
+	LabelPath string          `json:"labelPath"` // label file of the custom model
+	Species   SpeciesSettings `json:"species"`   // include/exclude lists and per-species thresholds and actions
+}
+
+// SourceOverride contains detection settings that apply to a single audio source,
+// e.g. a higher threshold for a noisy RTSP camera than for a quality microphone.
+// The source threshold replaces the global threshold; per-species thresholds still take precedence.
+type SourceOverride struct {
+	Source    string   `json:"source"`    // audio source to match: source ID, display name or RTSP URL
+	Threshold float64  `json:"threshold"` // confidence threshold for this source, 0 to use the global threshold
+	Include   []string `json:"include"`   // species accepted from this source even if not on the range filter list
+	Exclude   []string `json:"exclude"`   // species ignored from this source
+
+	PrivacyFilter *SourceFilterOverride `json:"privacyFilter,omitempty"` // privacy filter settings for this source, nil to use the global settings
+	DogBarkFilter *SourceFilterOverride `json:"dogBarkFilter,omitempty"` // dog bark filter settings for this source, nil to use the global settings
+}
+
+// SourceFilterOverride replaces the global privacy or dog bark filter settings for an
+// audio source, e.g. a stricter privacy filter for a microphone near a patio.
+// Unset and zero fields use the global filter settings.
+type SourceFilterOverride struct {
+	Enabled       *bool   `json:"enabled,omitempty"` // true or false to enable or disable the filter for this source
+	Confidence    float32 `json:"confidence"`        // confidence threshold for human voice or dog bark detection
+	WindowSeconds int     `json:"windowSeconds"`     // seconds detections are discarded after a human voice or dog bark
+}
+
+// MorningBriefSettings contains settings for the scheduled morning brief notification
+type MorningBriefSettings struct {
+	Enabled     bool   `json:"enabled"`     // true to send a daily morning brief notification
+	Time        string `json:"time"`        // local time of day to send the brief, in HH:MM format (default: "08:00")
+	CompareDays int    `json:"compareDays"` // number of previous days used to detect new arrivals (default: 7)
 }
 
 // SpeciesAction represents a single action configuration
 type SpeciesAction struct {
-	Type            string   `yaml:"type" json:"type"`                       // Type of action (ExecuteCommand, etc)
-	Command         string   `yaml:"command" json:"command"`                 // Path to the command to execute
-	Parameters      []string `yaml:"parameters" json:"parameters"`           // Action parameters
-	ExecuteDefaults bool     `yaml:"executeDefaults" json:"executeDefaults"` // Whether to also execute default actions
+	Type            string          `yaml:"type" json:"type"`                                       // Type of action (ExecuteCommand, etc)
+	Command         string          `yaml:"command" json:"command"`                                 // Path to the command to execute
+	Parameters      []string        `yaml:"parameters" json:"parameters"`                           // Action parameters
+	ExecuteDefaults bool            `yaml:"executeDefaults" json:"executeDefaults"`                 // Whether to also execute default actions
+	MaxPerHour      int             `yaml:"maxPerHour,omitempty" json:"maxPerHour,omitempty"`       // Runs per hour, overrides realtime.commandguard when set
+	MaxConcurrent   int             `yaml:"maxConcurrent,omitempty" json:"maxConcurrent,omitempty"` // Simultaneous runs, overrides realtime.commandguard when set
+	When            ActionCondition `yaml:"when,omitempty" json:"when,omitempty"`                   // Conditions the detection must meet, the action always runs when empty
+}
+
+// ActionCondition restricts a species action to detections meeting all of its set fields
+type ActionCondition struct {
+	Period    string  `yaml:"period,omitempty" json:"period,omitempty"`       // "day" from sunrise to sunset or "night", empty for any time
+	MinRarity float64 `yaml:"minRarity,omitempty" json:"minRarity,omitempty"` // minimum rarity score, requires realtime.rarity
+}
+
+// SpeciesRecipe selects a built-in action recipe by name, see ActionRecipes
+type SpeciesRecipe struct {
+	Name      string  `yaml:"name" json:"name"`                               // recipe name, e.g. notify-at-night
+	Command   string  `yaml:"command,omitempty" json:"command,omitempty"`     // script of recipes running a command
+	MinRarity float64 `yaml:"minRarity,omitempty" json:"minRarity,omitempty"` // rarity score of rarity recipes, 0 for the recipe default
 }
 
 // SpeciesConfig represents configuration for a specific species
 type SpeciesConfig struct {
-	Threshold float64         `yaml:"threshold" json:"threshold"` // Confidence threshold
-	Interval  int             `yaml:"interval" json:"interval"`   // Custom interval in seconds (0 = use default)
-	Actions   []SpeciesAction `yaml:"actions" json:"actions"`     // List of actions to execute
+	Threshold float64         `yaml:"threshold" json:"threshold"`                 // Confidence threshold
+	Interval  int             `yaml:"interval" json:"interval"`                   // Custom interval in seconds (0 = use default)
+	Actions   []SpeciesAction `yaml:"actions" json:"actions"`                     // List of actions to execute
+	Recipes   []SpeciesRecipe `yaml:"recipes,omitempty" json:"recipes,omitempty"` // Built-in action recipes, expanded after the actions
 }
 
 // RealtimeSpeciesSettings contains all species-specific settings
@@ -579,19 +1153,44 @@ type InputConfig struct {
 }
 
 type BirdNETConfig struct {
-	Debug       bool                `json:"debug"`       // true to enable debug mode
-	Sensitivity float64             `json:"sensitivity"` // birdnet analysis sigmoid sensitivity
-	Threshold   float64             `json:"threshold"`   // threshold for prediction confidence to report
-	Overlap     float64             `json:"overlap"`     // birdnet analysis overlap between chunks
-	Longitude   float64             `json:"longitude"`   // longitude of recording location for prediction filtering
-	Latitude    float64             `json:"latitude"`    // latitude of recording location for prediction filtering
-	Threads     int                 `json:"threads"`     // number of CPU threads to use for analysis
-	Locale      string              `json:"locale"`      // language to use for labels
-	RangeFilter RangeFilterSettings `json:"rangeFilter"` // range filter settings
-	ModelPath   string              `json:"modelPath"`   // path to external model file (empty for embedded)
-	LabelPath   string              `json:"labelPath"`   // path to external label file (empty for embedded)
-	Labels      []string            `yaml:"-" json:"-"`  // list of available species labels, runtime value
-	UseXNNPACK  bool                `json:"useXnnpack"`  // true to use XNNPACK delegate for inference acceleration
+	Debug        bool                `json:"debug"`        // true to enable debug mode
+	Sensitivity  float64             `json:"sensitivity"`  // birdnet analysis sigmoid sensitivity
+	Threshold    float64             `json:"threshold"`    // threshold for prediction confidence to report
+	Overlap      float64             `json:"overlap"`      // birdnet analysis overlap between chunks
+	Longitude    float64             `json:"longitude"`    // longitude of recording location for prediction filtering
+	Latitude     float64             `json:"latitude"`     // latitude of recording location for prediction filtering
+	DeploymentID string              `json:"deploymentId"` // name of the deployment at the station location, set by the location change workflow
+	Threads      int                 `json:"threads"`      // number of CPU threads to use for analysis
+	Locale       string              `json:"locale"`       // language to use for labels
+	RangeFilter  RangeFilterSettings `json:"rangeFilter"`  // range filter settings
+	ModelPath    string              `json:"modelPath"`    // path to external model file (empty for embedded)
+	LabelPath    string              `json:"labelPath"`    // path to external label file (empty for embedded)
+	Labels       []string            `yaml:"-" json:"-"`   // list of available species labels, runtime value
+	UseXNNPACK   bool                `json:"useXnnpack"`   // true to use XNNPACK delegate for inference acceleration
+	Scheduler    SchedulerSettings   `json:"scheduler"`    // inference scheduling across audio sources
+	SilenceGate  SilenceGateSettings `json:"silenceGate"`  // skipping inference on silent windows
+}
+
+// SchedulerSettings contains settings for sharing BirdNET inference between audio sources.
+// Sources take turns, so a source with a backlog cannot delay the others. With several
+// workers, chunks of different sources are analyzed in parallel, and with a batch size above
+// one, chunks waiting at the same time are analyzed in a single model invoke.
+type SchedulerSettings struct {
+	QueueLimit int `json:"queueLimit"` // chunks queued per source, the oldest chunk is dropped when full
+	Workers    int `json:"workers"`    // model interpreters running inference in parallel
+	BatchSize  int `json:"batchSize"`  // chunks analyzed per model invoke, used if the model accepts batches
+}
+
+// SilenceGateSettings contains settings for skipping BirdNET inference on analysis windows
+// that are effectively silent, such as quiet nights. A window is silent when its loudest
+// frame stays below the threshold. After silence, analysis resumes when a frame exceeds the
+// threshold by the hysteresis, or when a quieter sound stands out from the background by its
+// low energy entropy.
+type SilenceGateSettings struct {
+	Enabled    bool    `json:"enabled"`    // true to skip inference on silent windows
+	Threshold  float64 `json:"threshold"`  // level of the loudest frame in dBFS below which a window is silent
+	Hysteresis float64 `json:"hysteresis"` // dB above the threshold that resume analysis after silence
+	MaxEntropy float64 `json:"maxEntropy"` // energy entropy, 0-1, below which a window above the threshold resumes analysis, 0 to disable
 }
 
 // RangeFilterSettings contains settings for the range filter
@@ -624,9 +1223,147 @@ type SocialProvider struct {
 	UserId       string `json:"userId"`       // valid user id for OAuth2
 }
 
+// OIDCProvider holds settings for an OpenID Connect identity provider such as Authelia,
+// Keycloak or Google. Users are given a role from the groups in their token.
+type OIDCProvider struct {
+	Enabled      bool     `json:"enabled"`      // true to enable OpenID Connect login
+	Name         string   `json:"name"`         // provider name shown on the login page
+	IssuerURL    string   `json:"issuerUrl"`    // issuer URL, the discovery document is read from it
+	ClientID     string   `json:"clientId"`     // client id registered at the provider
+	ClientSecret string   `json:"clientSecret"` // client secret registered at the provider
+	RedirectURI  string   `json:"redirectUri"`  // callback URL registered at the provider
+	Scopes       []string `json:"scopes"`       // requested scopes, openid is always requested
+	GroupsClaim  string   `json:"groupsClaim"`  // claim holding the groups of the user
+	AdminGroups  []string `json:"adminGroups"`  // groups given the admin role
+	ViewerGroups []string `json:"viewerGroups"` // groups given the viewer role
+}
+
 type AllowSubnetBypass struct {
 	Enabled bool   `json:"enabled"` // true to enable subnet bypass
 	Subnet  string `json:"subnet"`  // disable OAuth2 in subnet
+	Role    string `json:"role"`    // role of clients in the subnet without login, admin or viewer
+}
+
+// User roles of the web UI and API
+const (
+	RoleAdmin  = "admin"  // full access, including settings changes, deletions and control
+	RoleViewer = "viewer" // read-only access
+)
+
+// UserAccount is a user of the web UI and API, in addition to the basic auth admin
+type UserAccount struct {
+	Username      string                   `json:"username"`      // login name
+	PasswordHash  string                   `json:"-"`             // bcrypt hash of the password, never exposed by the API
+	Role          string                   `json:"role"`          // admin or viewer
+	Notifications UserNotificationSettings `json:"notifications"` // notification preferences of the user
+}
+
+// Channels users receive notifications through
+const (
+	NotificationChannelDashboard = "dashboard" // live notifications in the web dashboard
+	NotificationChannelPush      = "push"      // Web Push notifications on the devices of the user
+)
+
+// NotificationChannels lists the notification channels users can choose
+var NotificationChannels = []string{NotificationChannelDashboard, NotificationChannelPush}
+
+// UserNotificationSettings are the notification preferences of a user, applied when
+// notifications are delivered to the user
+type UserNotificationSettings struct {
+	Channels   []string           `json:"channels"`   // channels to notify the user through, empty for all
+	Species    []string           `json:"species"`    // species of interest by common or scientific name, empty for all
+	QuietHours QuietHoursSettings `json:"quietHours"` // daily hours without notifications
+}
+
+// QuietHoursSettings is a daily period in which a user receives only critical notifications
+type QuietHoursSettings struct {
+	Enabled bool   `json:"enabled"` // true to hold back notifications during the period
+	Start   string `json:"start"`   // local start time in HH:MM format
+	End     string `json:"end"`     // local end time in HH:MM format, before start for periods over midnight
+}
+
+// Validate checks that the channels are known and the quiet hours are valid times
+func (s *UserNotificationSettings) Validate() error {
+	for _, channel := range s.Channels {
+		if !slices.Contains(NotificationChannels, channel) {
+			return errors.Newf("notification channel must be one of %s, got %q", strings.Join(NotificationChannels, ", "), channel).
+				Component("config").
+				Category(errors.CategoryValidation).
+				Context("channel", channel).
+				Build()
+		}
+	}
+	if !s.QuietHours.Enabled {
+		return nil
+	}
+	for _, value := range []string{s.QuietHours.Start, s.QuietHours.End} {
+		if _, _, err := ParseClockTime(value); err != nil {
+			return errors.Newf("quiet hours must be times in HH:MM format, got %q", value).
+				Component("config").
+				Category(errors.CategoryValidation).
+				Build()
+		}
+	}
+	if strings.TrimSpace(s.QuietHours.Start) == strings.TrimSpace(s.QuietHours.End) {
+		return errors.Newf("quiet hours start and end must differ").
+			Component("config").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	return nil
+}
+
+// Contains reports whether a time is within the quiet hours, in the location of the time
+func (s *QuietHoursSettings) Contains(t time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	startHour, startMinute, err := ParseClockTime(s.Start)
+	if err != nil {
+		return false
+	}
+	endHour, endMinute, err := ParseClockTime(s.End)
+	if err != nil {
+		return false
+	}
+	start, end := startHour*60+startMinute, endHour*60+endMinute
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// The period continues over midnight
+	return minute >= start || minute < end
+}
+
+// Scopes limiting API tokens to parts of the API
+const (
+	ScopeDetectionsRead  = "detections:read"  // detections, analytics, species, media and exports
+	ScopeDetectionsWrite = "detections:write" // review, lock, ignore and delete detections
+	ScopeSettingsRead    = "settings:read"    // read settings
+	ScopeSettingsWrite   = "settings:write"   // change settings
+	ScopeControlRestart  = "control:restart"  // restart analysis, reload model and config, rebuild filter
+	ScopeSystemRead      = "system:read"      // system information and audio device status
+)
+
+// APITokenScopes lists the known API token scopes
+var APITokenScopes = []string{
+	ScopeDetectionsRead, ScopeDetectionsWrite,
+	ScopeSettingsRead, ScopeSettingsWrite,
+	ScopeControlRestart, ScopeSystemRead,
+}
+
+// AdminScope reports whether a scope allows changes that need the admin role
+func AdminScope(scope string) bool {
+	return scope == ScopeDetectionsWrite || scope == ScopeSettingsWrite || scope == ScopeControlRestart
+}
+
+// APIToken is a long-lived bearer token for API clients
+type APIToken struct {
+	Name      string    `json:"name"`      // name of the client using the token
+	TokenHash string    `json:"-"`         // SHA-256 hash of the token, hex encoded, never exposed by the API
+	Role      string    `json:"role"`      // admin or viewer
+	Scopes    []string  `json:"scopes"`    // parts of the API the token may use, all its role allows when empty
+	CreatedAt time.Time `json:"createdAt"` // creation time of the token
 }
 
 // SecurityConfig handles all security-related settings and validations
@@ -648,16 +1385,48 @@ type Security struct {
 	BasicAuth         BasicAuth         `json:"basicAuth"`         // password authentication configuration
 	GoogleAuth        SocialProvider    `json:"googleAuth"`        // Google OAuth2 configuration
 	GithubAuth        SocialProvider    `json:"githubAuth"`        // Github OAuth2 configuration
+	OIDCAuth          OIDCProvider      `json:"oidcAuth"`          // OpenID Connect configuration
 	SessionSecret     string            `json:"sessionSecret"`     // secret for session cookie
 	SessionDuration   time.Duration     `json:"sessionDuration"`   // duration for browser session cookies
+	Users             []UserAccount     `json:"users"`             // additional users with roles
+	APITokens         []APIToken        `json:"apiTokens"`         // long-lived API tokens with roles
 }
 
 type WebServerSettings struct {
-	Debug      bool               `json:"debug"`      // true to enable debug mode
-	Enabled    bool               `json:"enabled"`    // true to enable web server
-	Port       string             `json:"port"`       // port for web server
-	Log        LogConfig          `json:"log"`        // logging configuration for web server
-	LiveStream LiveStreamSettings `json:"liveStream"` // live stream configuration
+	Debug           bool                    `json:"debug"`           // true to enable debug mode
+	Enabled         bool                    `json:"enabled"`         // true to enable web server
+	Port            string                  `json:"port"`            // port for web server
+	Log             LogConfig               `json:"log"`             // logging configuration for web server
+	LiveStream      LiveStreamSettings      `json:"liveStream"`      // live stream configuration
+	LocationPrivacy LocationPrivacySettings `json:"locationPrivacy"` // coordinates exposed by the API
+	Profiling       bool                    `json:"profiling"`       // true to serve pprof profiles to admins at /api/v2/debug/pprof
+}
+
+// Location privacy policies for coordinates exposed by the API
+const (
+	LocationPolicyExact   = "exact"   // coordinates as recorded
+	LocationPolicyRounded = "rounded" // coordinates rounded to Precision decimal places
+	LocationPolicyHidden  = "hidden"  // no coordinates
+)
+
+// LocationPrivacySettings controls how precisely detection coordinates are exposed by the API
+type LocationPrivacySettings struct {
+	Policy    string `json:"policy"`    // exact, rounded or hidden
+	Precision int    `json:"precision"` // decimal places kept by the rounded policy, 2 is about 1 km
+}
+
+// Apply returns the coordinates as exposed under the policy. ok is false when the policy
+// hides coordinates. An empty policy is treated as rounded.
+func (p *LocationPrivacySettings) Apply(latitude, longitude float64) (lat, lon float64, ok bool) {
+	switch p.Policy {
+	case LocationPolicyExact:
+		return latitude, longitude, true
+	case LocationPolicyHidden:
+		return 0, 0, false
+	default:
+		scale := math.Pow(10, float64(p.Precision))
+		return math.Round(latitude*scale) / scale, math.Round(longitude*scale) / scale, true
+	}
 }
 
 type LiveStreamSettings struct {
@@ -666,6 +1435,8 @@ type LiveStreamSettings struct {
 	SampleRate     int    `json:"sampleRate"`     // sample rate for live stream in Hz
 	SegmentLength  int    `json:"segmentLength"`  // length of each segment in seconds
 	FfmpegLogLevel string `json:"ffmpegLogLevel"` // log level for ffmpeg
+	Continuous     bool   `json:"continuous"`     // true to keep a stream running for every audio source, even without listeners
+	LowLatency     bool   `json:"lowLatency"`     // true to use one second segments and unbuffered encoding to reduce stream delay
 }
 
 // BackupRetention defines backup retention policy
@@ -797,7 +1568,7 @@ func (s *GoogleDriveBackupSettings) Validate() error {
 
 // BackupTarget defines settings for a backup target
 type BackupTarget struct {
-	Type     string         `yaml:"type" json:"type"`         // Specifies the type of the backup target (e.g., "local", "s3", "ftp", "sftp"). This determines the storage mechanism.
+	Type     string         `yaml:"type" json:"type"`         // Specifies the type of the backup target (e.g., "local", "ftp", "sftp", "rsync", "gdrive", "s3", "webdav"). This determines the storage mechanism.
 	Enabled  bool           `yaml:"enabled" json:"enabled"`   // If true, this backup target will be used for storing backups. At least one target should be enabled for backups to be stored.
 	Settings map[string]any `yaml:"settings" json:"settings"` // A map of key-value pairs for target-specific settings. TODO: Consider using BackupTargetSettings interface for type safety after implementing custom YAML unmarshaling.
 }
@@ -831,9 +1602,16 @@ type BackupConfig struct {
 	} `json:"operationTimeouts"`
 }
 
+// Sources of the date and time stored with a detection
+const (
+	TimestampsSegment = "segment" // start time of the analyzed audio segment
+	TimestampsClock   = "clock"   // wall clock time when the detection was created
+)
+
 // Settings contains all configuration options for the BirdNET-Go application.
 type Settings struct {
-	Debug bool `json:"debug"` // true to enable debug mode
+	Debug         bool `json:"debug"`         // true to enable debug mode
+	ConfigVersion int  `json:"configVersion"` // configuration schema version, see CurrentConfigVersion
 
 	// Runtime values, not stored in config file
 	Version            string   `yaml:"-" json:"version,omitempty"`            // Version from build
@@ -843,18 +1621,30 @@ type Settings struct {
 
 	Main struct {
 		Name      string    `json:"name"`      // name of BirdNET-Go node, can be used to identify source of notes
-		TimeAs24h bool      `json:"timeAs24h"` // true 24-hour time format, false 12-hour time format
-		Log       LogConfig `json:"log"`       // logging configuration
+		TimeAs24h  bool      `json:"timeAs24h"`  // true 24-hour time format, false 12-hour time format
+		Timestamps string    `json:"timestamps"` // date and time of detections: segment for the start of the analyzed audio, clock for the time it was analyzed
+		TimeZone   string    `json:"timeZone"`   // IANA time zone of detection dates and times, empty for the system time zone
+		Log        LogConfig `json:"log"`        // logging configuration
 	} `json:"main"`
 
 	BirdNET BirdNETConfig `json:"birdnet"` // BirdNET configuration
 
+	UI UISettings `json:"ui"` // species name presentation settings
+
 	Input InputConfig `yaml:"-" json:"-"` // Input configuration for file and directory analysis
 
-	Realtime  RealtimeSettings  `json:"realtime"`  // Realtime processing settings
-	WebServer WebServerSettings `json:"webServer"` // web server configuration
-	Security  Security          `json:"security"`  // security configuration
-	Sentry    SentrySettings    `json:"sentry"`    // Sentry error tracking configuration
+	Realtime   RealtimeSettings   `json:"realtime"`   // Realtime processing settings
+	WebServer  WebServerSettings  `json:"webServer"`  // web server configuration
+	Security   Security           `json:"security"`   // security configuration
+	Sentry     SentrySettings     `json:"sentry"`     // Sentry error tracking configuration
+	Privacy    PrivacySettings    `json:"privacy"`    // anonymization policy of telemetry, logs and MQTT
+	Update     UpdateSettings     `json:"update"`     // binary self-update configuration
+	Email      EmailSettings      `json:"email"`      // email report configuration
+	DataExport DataExportSettings `json:"dataExport"` // bulk detection export configuration
+	Trash      TrashSettings      `json:"trash"`      // retention of deleted detections
+	Logging    LoggingSettings    `json:"logging"`    // log levels per component
+	EventSink  EventSinkSettings  `json:"eventSink"`  // forwarding of events to an external message broker
+	WebPush    WebPushSettings    `json:"webPush"`    // Web Push detection notifications to browsers
 
 	Output struct {
 		File struct {
@@ -876,9 +1666,20 @@ type Settings struct {
 			Host     string `json:"host"`     // host for mysql database
 			Port     string `json:"port"`     // port for mysql database
 		} `json:"mysql"`
+
+		Spool DatastoreSpoolSettings `json:"spool"` // buffering of detections while the database is unavailable
 	} `json:"output"`
 
 	Backup BackupConfig `json:"backup"` // Backup configuration
+
+	OutboundTLS   OutboundTLSSettings   `json:"outboundTls"`   // TLS settings for connections to external services
+	OutboundProxy OutboundProxySettings `json:"outboundProxy"` // proxy for connections to external services
+}
+
+// UISettings contains settings for how species are presented in the dashboard, API and
+// notifications. Scientific names stored in the database are not affected.
+type UISettings struct {
+	Locale string `json:"locale"` // language of species common names, empty to use the names of the birdnet label locale
 }
 
 // LogConfig defines the configuration for a log file
@@ -888,6 +1689,9 @@ type LogConfig struct {
 	Rotation    RotationType `json:"rotation"`    // Type of log rotation
 	MaxSize     int64        `json:"maxSize"`     // Max size in bytes for RotationSize
 	RotationDay string       `json:"rotationDay"` // Day of the week for RotationWeekly (as a string: "Sunday", "Monday", etc.)
+	MaxBackups  int          `json:"maxBackups"`  // Number of rotated files kept, 0 to keep all
+	MaxAge      int          `json:"maxAge"`      // Days rotated files are kept, 0 to keep them regardless of age
+	Compress    bool         `json:"compress"`    // true to gzip rotated files
 }
 
 // RotationType defines different types of log rotations.
@@ -922,13 +1726,15 @@ func Load() (*Settings, error) {
 			Build()
 	}
 
+	// Upgrade configuration files written by older versions
+	fromVersion, migrated := migrateConfig(viper.GetViper())
+
 	// Unmarshal the config into settings
-	if err := viper.Unmarshal(settings); err != nil {
-		return nil, errors.New(err).
-			Category(errors.CategoryConfiguration).
-			Context("operation", "unmarshal-config").
-			Build()
+	unknownKeys, err := decodeSettings(viper.GetViper(), settings)
+	if err != nil {
+		return nil, err
 	}
+	reportUnknownKeys(settings, unknownKeys)
 
 	// Auto-generate SessionSecret if not set (for backward compatibility)
 	if settings.Security.SessionSecret == "" {
@@ -967,6 +1773,26 @@ func Load() (*Settings, error) {
 	}
 
 	// Validate settings
+	if err := validateLoadedSettings(settings); err != nil {
+		return nil, err
+	}
+
+	// Write the migrated configuration back so the file matches the current schema
+	if configFile := viper.ConfigFileUsed(); migrated && configFile != "" {
+		if err := writeMigratedConfig(configFile, fromVersion, settings); err != nil {
+			// The migrated settings are used for this session and the migration is retried on next start
+			log.Printf("Warning: Failed to write migrated config file: %v", err)
+		}
+	}
+
+	// Save settings instance
+	settingsInstance = settings
+	return settingsInstance, nil
+}
+
+// validateLoadedSettings validates settings read from the configuration file. Warnings
+// such as fallbacks are stored in ValidationWarnings, other validation errors are returned.
+func validateLoadedSettings(settings *Settings) error {
 	if err := ValidateSettings(settings); err != nil {
 		// Check if it's just a validation warning (contains fallback info)
 		var validationErr ValidationError
@@ -982,7 +1808,7 @@ func Load() (*Settings, error) {
 					// Note: Telemetry reporting will happen later in birdnet package when Sentry is initialized
 				} else {
 					// This is a real validation error - fail the config load
-					return nil, errors.New(err).
+					return errors.New(err).
 						Category(errors.CategoryValidation).
 						Context("component", "settings").
 						Context("error_msg", errMsg).
@@ -991,16 +1817,13 @@ func Load() (*Settings, error) {
 			}
 		} else {
 			// Other validation errors should fail the config load
-			return nil, errors.New(err).
+			return errors.New(err).
 				Category(errors.CategoryValidation).
 				Context("component", "settings").
 				Build()
 		}
 	}
-
-	// Save settings instance
-	settingsInstance = settings
-	return settingsInstance, nil
+	return nil
 }
 
 // initViper initializes viper with default values and reads the configuration file.
@@ -1120,6 +1943,34 @@ func GetSettings() *Settings {
 	return settingsInstance
 }
 
+// LoadFromFile reads the configuration file again and returns the settings it contains.
+// The current settings instance is not changed, callers decide which settings to apply.
+func LoadFromFile() (*Settings, error) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, errors.New(err).
+			Category(errors.CategoryFileIO).
+			Context("operation", "reload-config-file").
+			Build()
+	}
+
+	migrateConfig(viper.GetViper())
+
+	settings := &Settings{}
+	unknownKeys, err := decodeSettings(viper.GetViper(), settings)
+	if err != nil {
+		return nil, err
+	}
+	reportUnknownKeys(settings, unknownKeys)
+
+	if err := validateLoadedSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
 // SaveSettings saves the current settings to the configuration file.
 // It uses UpdateYAMLConfig to handle the atomic write process.
 func SaveSettings() error {
@@ -1326,6 +2177,8 @@ const (
 	WeatherYrNo         WeatherProvider = "yrno"
 	WeatherOpenWeather  WeatherProvider = "openweather"
 	WeatherWunderground WeatherProvider = "wunderground"
+	WeatherOpenMeteo    WeatherProvider = "openmeteo"
+	WeatherMQTT         WeatherProvider = "mqtt"
 )
 
 // Prefer explicit settings return to avoid confusion at call sites.
@@ -1336,7 +2189,9 @@ func (s *Settings) GetWeatherProvider() (provider WeatherProvider, settings any)
 		return WeatherOpenWeather, s.Realtime.Weather.OpenWeather
 	case string(WeatherWunderground):
 		return WeatherWunderground, s.Realtime.Weather.Wunderground
-	case string(WeatherYrNo), string(WeatherNone):
+	case string(WeatherMQTT):
+		return WeatherMQTT, s.Realtime.Weather.MQTT
+	case string(WeatherYrNo), string(WeatherOpenMeteo), string(WeatherNone):
 		return WeatherProvider(p), nil
 	default:
 		// Sensible default for legacy configs
@@ -161,9 +161,11 @@ func TestSettingsSaveAndLoad(t *testing.T) {
 	// Create settings with species config containing zero values
 	settings := &Settings{
 		Main: struct {
-			Name      string    `json:"name"`
-			TimeAs24h bool      `json:"timeAs24h"`
-			Log       LogConfig `json:"log"`
+			Name       string    `json:"name"`
+			TimeAs24h  bool      `json:"timeAs24h"`
+			Timestamps string    `json:"timestamps"`
+			TimeZone   string    `json:"timeZone"`
+			Log        LogConfig `json:"log"`
 		}{
 			Name: "TestNode",
 		},
@@ -7,6 +7,22 @@ const (
 	NumChannels   = 1     // Number of channels of the audio fed to BirdNET Analyzer
 	CaptureLength = 3     // Length of audio data fed to BirdNET Analyzer in seconds
 
+	// MaxAnalysisOverlap is the largest overlap of consecutive analysis windows in seconds,
+	// the windows must start at least 10 ms apart
+	MaxAnalysisOverlap = CaptureLength - 0.01
+
+	// AnalysisWindowSamples is the number of samples per channel in an analysis window
+	AnalysisWindowSamples = SampleRate * CaptureLength
+
+	// MaxInferenceQueueLimit is the largest number of analysis chunks queued per audio source
+	MaxInferenceQueueLimit = 20
+
+	// MaxInferenceWorkers is the largest number of model interpreters running inference in parallel
+	MaxInferenceWorkers = 8
+
+	// MaxInferenceBatchSize is the largest number of analysis chunks analyzed per model invoke
+	MaxInferenceBatchSize = 8
+
 	SpeciesConfigCSV  = "species_config.csv"
 	SpeciesActionsCSV = "species_actions.csv"
 
@@ -14,11 +14,16 @@ func setDefaultConfig() {
 	// Main configuration
 	viper.SetDefault("main.name", "BirdNET-Go")
 	viper.SetDefault("main.timeas24h", true)
+	viper.SetDefault("main.timestamps", TimestampsSegment)
+	viper.SetDefault("main.timezone", "")
 	viper.SetDefault("main.log.enabled", true)
 	viper.SetDefault("main.log.path", "birdnet.log")
 	viper.SetDefault("main.log.rotation", RotationDaily)
 	viper.SetDefault("main.log.maxsize", 1048576)
 	viper.SetDefault("main.log.rotationday", "Sunday")
+	viper.SetDefault("main.log.maxbackups", 10)
+	viper.SetDefault("main.log.maxage", 30)
+	viper.SetDefault("main.log.compress", true)
 
 	// BirdNET configuration
 	viper.SetDefault("birdnet.debug", false)
@@ -29,9 +34,20 @@ func setDefaultConfig() {
 	viper.SetDefault("birdnet.locale", DefaultFallbackLocale)
 	viper.SetDefault("birdnet.latitude", 0.000)
 	viper.SetDefault("birdnet.longitude", 0.000)
+	viper.SetDefault("birdnet.deploymentid", "")
 	viper.SetDefault("birdnet.modelpath", "")
 	viper.SetDefault("birdnet.labelpath", "")
 	viper.SetDefault("birdnet.usexnnpack", true)
+	viper.SetDefault("birdnet.scheduler.queuelimit", 3)
+	viper.SetDefault("birdnet.scheduler.workers", 1)
+	viper.SetDefault("birdnet.scheduler.batchsize", 1)
+	viper.SetDefault("birdnet.silencegate.enabled", false)
+	viper.SetDefault("birdnet.silencegate.threshold", -70.0)
+	viper.SetDefault("birdnet.silencegate.hysteresis", 6.0)
+	viper.SetDefault("birdnet.silencegate.maxentropy", 0.85)
+
+	// Species name presentation configuration
+	viper.SetDefault("ui.locale", "")
 
 	// Range filter configuration
 	viper.SetDefault("birdnet.rangefilter.debug", false)
@@ -42,11 +58,25 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.interval", 15)
 	viper.SetDefault("realtime.processingtime", false)
 
+	// Duplicate suppression intervals per integration, zero uses realtime.interval
+	viper.SetDefault("realtime.eventintervals.database", 0)
+	viper.SetDefault("realtime.eventintervals.log", 0)
+	viper.SetDefault("realtime.eventintervals.notification", 0)
+	viper.SetDefault("realtime.eventintervals.birdweather", 0)
+	viper.SetDefault("realtime.eventintervals.mqtt", 0)
+	viper.SetDefault("realtime.eventintervals.sse", 0)
+
 	// Audio source configuration
 	viper.SetDefault("realtime.audio.useaudiocore", false) // true to use new audiocore package instead of myaudio
 	viper.SetDefault("realtime.audio.source", "sysdefault")
 	viper.SetDefault("realtime.audio.streamtransport", "sse")
 
+	// Calibration of the gain of audio sources
+	viper.SetDefault("realtime.audio.calibration.targetlufs", -30.0)
+	viper.SetDefault("realtime.audio.calibration.window", 300)
+	viper.SetDefault("realtime.audio.calibration.applytoanalysis", false)
+	viper.SetDefault("realtime.audio.calibration.profiles", []map[string]any{})
+
 	// Sound level monitoring configuration
 	viper.SetDefault("realtime.audio.soundlevel.enabled", false)
 	viper.SetDefault("realtime.audio.soundlevel.interval", 10)
@@ -67,6 +97,12 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.audio.export.normalization.loudnessRange", 7.0) // typical range for broadcast
 	viper.SetDefault("realtime.audio.export.normalization.truePeak", -2.0)     // headroom to prevent clipping
 
+	// Encoder pool shared by clip exports, uploads and snapshots
+	viper.SetDefault("realtime.audio.export.encoder.workers", 2)
+	viper.SetDefault("realtime.audio.export.encoder.queueSize", 32)
+	viper.SetDefault("realtime.audio.export.encoder.timeout", 30)
+	viper.SetDefault("realtime.audio.export.encoder.flacEncoder", "native")
+
 	// Audio equalizer configuration
 	viper.SetDefault("realtime.audio.equalizer.enabled", false)
 	viper.SetDefault("realtime.audio.equalizer.filters", []map[string]any{
@@ -90,6 +126,13 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.dashboard.thumbnails.recent", true)
 	viper.SetDefault("realtime.dashboard.thumbnails.imageprovider", "avicommons")
 	viper.SetDefault("realtime.dashboard.thumbnails.fallbackpolicy", "none")
+	viper.SetDefault("realtime.dashboard.thumbnails.providerpriority", []string{})
+	viper.SetDefault("realtime.dashboard.thumbnails.localpath", "")
+	viper.SetDefault("realtime.dashboard.thumbnails.macaulay", false)
+	viper.SetDefault("realtime.dashboard.thumbnails.diskcache.enabled", false)
+	viper.SetDefault("realtime.dashboard.thumbnails.diskcache.path", "imagecache")
+	viper.SetDefault("realtime.dashboard.thumbnails.prefetch.enabled", true)
+	viper.SetDefault("realtime.dashboard.thumbnails.prefetch.bandwidthlimit", 256)
 	viper.SetDefault("realtime.dashboard.summarylimit", 30)
 	viper.SetDefault("realtime.dashboard.locale", "en") // Default UI locale
 	viper.SetDefault("realtime.dashboard.newui", false) // Enable redirect from old HTMX UI to new Svelte UI
@@ -110,6 +153,20 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.dynamicthreshold.min", 0.20)
 	viper.SetDefault("realtime.dynamicthreshold.validhours", 24)
 
+	// Seasonal prior configuration
+	viper.SetDefault("realtime.seasonalprior.enabled", false)
+	viper.SetDefault("realtime.seasonalprior.strength", 0.10)
+	viper.SetDefault("realtime.seasonalprior.pivot", 0.30)
+	viper.SetDefault("realtime.seasonalprior.min", 0.10)
+	viper.SetDefault("realtime.seasonalprior.max", 0.95)
+
+	// Rarity scoring configuration
+	viper.SetDefault("realtime.rarity.enabled", true)
+	viper.SetDefault("realtime.rarity.rangeweight", 0.5)
+	viper.SetDefault("realtime.rarity.historyyears", 3)
+	viper.SetDefault("realtime.rarity.windowdays", 3)
+	viper.SetDefault("realtime.rarity.notifythreshold", 0.0)
+
 	// Log configuration
 	viper.SetDefault("realtime.log.enabled", false)
 	viper.SetDefault("realtime.log.path", "birdnet.txt")
@@ -125,6 +182,13 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.birdweather.retrysettings.initialdelay", 60)
 	viper.SetDefault("realtime.birdweather.retrysettings.maxdelay", 3600)
 	viper.SetDefault("realtime.birdweather.retrysettings.backoffmultiplier", 2.0)
+	viper.SetDefault("realtime.birdweather.recorder.enabled", false)
+	viper.SetDefault("realtime.birdweather.recorder.size", 20)
+	viper.SetDefault("realtime.birdweather.recorder.maxbodysize", 4096)
+	viper.SetDefault("realtime.birdweather.uploadquota.enabled", false)
+	viper.SetDefault("realtime.birdweather.uploadquota.defaultlimit", 0)
+	viper.SetDefault("realtime.birdweather.uploadquota.species", map[string]int{})
+	viper.SetDefault("realtime.birdweather.normalization", "twopass")
 
 	// eBird configuration
 	viper.SetDefault("realtime.ebird.enabled", false)
@@ -160,12 +224,22 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.weather.wunderground.endpoint", "https://api.weather.com/v2/pws/observations/current")
 	viper.SetDefault("realtime.weather.wunderground.units", "m") // m=metric, e=imperial, h=UK hybrid
 
+	// Local weather sensor over MQTT
+	viper.SetDefault("realtime.weather.mqtt.broker", "")
+	viper.SetDefault("realtime.weather.mqtt.topic", "birdnet/weather")
+	viper.SetDefault("realtime.weather.mqtt.username", "")
+	viper.SetDefault("realtime.weather.mqtt.password", "")
+
 	// RTSP configuration
 	viper.SetDefault("realtime.rtsp.urls", []string{})
 	viper.SetDefault("realtime.rtsp.transport", "tcp")
 	viper.SetDefault("realtime.rtsp.health.healthydatathreshold", 60)
 	viper.SetDefault("realtime.rtsp.health.monitoringinterval", 30)
+	viper.SetDefault("realtime.rtsp.health.restartpolicy", RTSPRestartAlways)
+	viper.SetDefault("realtime.rtsp.health.initialbackoff", 5)
+	viper.SetDefault("realtime.rtsp.health.maxbackoff", 120)
 	viper.SetDefault("realtime.rtsp.ffmpegparameters", []string{})
+	viper.SetDefault("realtime.rtsp.correlatesources", false)
 
 	// MQTT configuration
 	viper.SetDefault("realtime.mqtt.enabled", false)
@@ -180,11 +254,21 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.mqtt.retrysettings.initialdelay", 30)
 	viper.SetDefault("realtime.mqtt.retrysettings.maxdelay", 3600)
 	viper.SetDefault("realtime.mqtt.retrysettings.backoffmultiplier", 2.0)
+	viper.SetDefault("realtime.mqtt.batch.enabled", false)
+	viper.SetDefault("realtime.mqtt.batch.interval", 10)
+	viper.SetDefault("realtime.mqtt.batch.maxsize", 50)
+	viper.SetDefault("realtime.mqtt.v5.enabled", false)
+	viper.SetDefault("realtime.mqtt.v5.messageexpiry", 0)
+	viper.SetDefault("realtime.mqtt.v5.contenttype", "application/json")
+	viper.SetDefault("realtime.mqtt.v5.commandtopic", "")
+	viper.SetDefault("realtime.mqtt.v5.sharedgroup", "")
 
 	// Privacy filter configuration
 	viper.SetDefault("realtime.privacyfilter.enabled", true)
 	viper.SetDefault("realtime.privacyfilter.debug", false)
 	viper.SetDefault("realtime.privacyfilter.confidence", 0.05)
+	viper.SetDefault("realtime.privacyfilter.window", 0)
+	viper.SetDefault("realtime.privacyfilter.mode", PrivacyFilterModeDiscard)
 
 	// Dog bark filter configuration
 	viper.SetDefault("realtime.dogbarkfilter.enabled", false)
@@ -242,6 +326,44 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.speciestracking.seasonaltracking.seasons.winter.startmonth", 12)
 	viper.SetDefault("realtime.speciestracking.seasonaltracking.seasons.winter.startday", 21)
 
+	// Morning brief configuration
+	viper.SetDefault("realtime.morningbrief.enabled", false)
+	viper.SetDefault("realtime.morningbrief.time", "08:00")
+	viper.SetDefault("realtime.morningbrief.comparedays", 7)
+
+	// Sunrise/sunset analysis window
+	viper.SetDefault("realtime.schedule.enabled", false)
+	viper.SetDefault("realtime.schedule.mode", ScheduleModeCustom)
+	viper.SetDefault("realtime.schedule.start.event", "civildawn")
+	viper.SetDefault("realtime.schedule.start.offset", -60)
+	viper.SetDefault("realtime.schedule.end.event", "civildusk")
+	viper.SetDefault("realtime.schedule.end.offset", 120)
+
+	// Graceful drain before shutdown
+	viper.SetDefault("realtime.drain.enabled", false)
+	viper.SetDefault("realtime.drain.timeout", 20)
+
+	// Readiness and watchdog notifications to systemd
+	viper.SetDefault("realtime.systemd.enabled", false)
+	viper.SetDefault("realtime.systemd.stalltimeout", 120)
+
+	// Shedding of optional actions under overload
+	viper.SetDefault("realtime.overload.enabled", true)
+	viper.SetDefault("realtime.overload.deadline", 120)
+
+	// Rate limits of ExecuteCommand actions
+	viper.SetDefault("realtime.commandguard.enabled", true)
+	viper.SetDefault("realtime.commandguard.maxperhour", 60)
+	viper.SetDefault("realtime.commandguard.maxconcurrent", 2)
+	viper.SetDefault("realtime.commandguard.tripafter", 20)
+
+	// Training data collection mode
+	viper.SetDefault("realtime.collection.enabled", false)
+	viper.SetDefault("realtime.collection.path", "collection")
+	viper.SetDefault("realtime.collection.minlevel", -50.0)
+	viper.SetDefault("realtime.collection.maxsizemb", 2048)
+	viper.SetDefault("realtime.collection.retentiondays", 14)
+
 	// Webserver configuration
 	viper.SetDefault("webserver.debug", false)
 	viper.SetDefault("webserver.enabled", true)
@@ -260,6 +382,15 @@ func setDefaultConfig() {
 	viper.SetDefault("webserver.livestream.sampleRate", 48000)
 	viper.SetDefault("webserver.livestream.segmentLength", 2)
 	viper.SetDefault("webserver.livestream.ffmpegLogLevel", "warning")
+	viper.SetDefault("webserver.livestream.continuous", false)
+	viper.SetDefault("webserver.livestream.lowlatency", false)
+
+	// Location privacy of coordinates exposed by the API
+	viper.SetDefault("webserver.locationprivacy.policy", LocationPolicyRounded)
+	viper.SetDefault("webserver.locationprivacy.precision", 2)
+
+	// pprof profiles of the running node for admins
+	viper.SetDefault("webserver.profiling", false)
 
 	// File output configuration
 	viper.SetDefault("output.file.enabled", true)
@@ -278,6 +409,12 @@ func setDefaultConfig() {
 	viper.SetDefault("output.mysql.host", "localhost")
 	viper.SetDefault("output.mysql.port", 3306)
 
+	// Buffering of detections while the database is unavailable
+	viper.SetDefault("output.spool.enabled", true)
+	viper.SetDefault("output.spool.path", "spool")
+	viper.SetDefault("output.spool.maxdetections", 10000)
+	viper.SetDefault("output.spool.retryinterval", 30)
+
 	// Security configuration
 	viper.SetDefault("security.debug", false)
 	viper.SetDefault("security.host", "")
@@ -285,6 +422,7 @@ func setDefaultConfig() {
 	viper.SetDefault("security.redirecttohttps", false)
 	viper.SetDefault("security.allowsubnetbypass.enabled", false)
 	viper.SetDefault("security.allowsubnetbypass.subnet", "")
+	viper.SetDefault("security.allowsubnetbypass.role", RoleAdmin)
 	viper.SetDefault("security.sessionduration", "168h") // 7 days
 
 	// Basic authentication configuration
@@ -309,9 +447,115 @@ func setDefaultConfig() {
 	viper.SetDefault("security.githubauth.redirecturi", "/settings")
 	viper.SetDefault("security.githubauth.userid", "")
 
+	// OpenID Connect configuration
+	viper.SetDefault("security.oidcauth.enabled", false)
+	viper.SetDefault("security.oidcauth.name", "SSO")
+	viper.SetDefault("security.oidcauth.issuerurl", "")
+	viper.SetDefault("security.oidcauth.clientid", "")
+	viper.SetDefault("security.oidcauth.clientsecret", "")
+	viper.SetDefault("security.oidcauth.redirecturi", "")
+	viper.SetDefault("security.oidcauth.scopes", []string{"openid", "profile", "email", "groups"})
+	viper.SetDefault("security.oidcauth.groupsclaim", "groups")
+	viper.SetDefault("security.oidcauth.admingroups", []string{})
+	viper.SetDefault("security.oidcauth.viewergroups", []string{})
+
 	// Sentry configuration
 	viper.SetDefault("sentry.enabled", false)
 	viper.SetDefault("sentry.dsn", "")
 	viper.SetDefault("sentry.samplerate", 1.0)
 	viper.SetDefault("sentry.debug", false)
+
+	// Anonymization policy configuration
+	viper.SetDefault("privacy.scrubbers", []string{})
+	viper.SetDefault("privacy.replacements", map[string]string{})
+	viper.SetDefault("privacy.allowedhosts", []string{})
+	viper.SetDefault("privacy.scrubmqtt", false)
+
+	// Self-update configuration
+	viper.SetDefault("update.enabled", false)
+	viper.SetDefault("update.channel", UpdateChannelStable)
+	viper.SetDefault("update.feedurl", "")
+	viper.SetDefault("update.publickey", "")
+	viper.SetDefault("update.restartcommand", "")
+	viper.SetDefault("update.healthtimeout", 120)
+
+	// Email report configuration
+	viper.SetDefault("email.enabled", false)
+	viper.SetDefault("email.smtp.host", "")
+	viper.SetDefault("email.smtp.port", 587)
+	viper.SetDefault("email.smtp.username", "")
+	viper.SetDefault("email.smtp.password", "")
+	viper.SetDefault("email.smtp.from", "")
+	viper.SetDefault("email.smtp.to", []string{})
+	viper.SetDefault("email.smtp.encryption", "starttls")
+	viper.SetDefault("email.smtp.insecureskipverify", false)
+	viper.SetDefault("email.digest.interval", "daily")
+	viper.SetDefault("email.digest.time", "07:00")
+	viper.SetDefault("email.digest.weekday", "monday")
+	viper.SetDefault("email.digest.maxclips", 5)
+	viper.SetDefault("email.digest.baseurl", "")
+	viper.SetDefault("email.digest.template", "")
+
+	// Trash configuration
+	viper.SetDefault("trash.retentiondays", 30)
+
+	// Component log levels
+	viper.SetDefault("logging.levels", map[string]string{})
+	viper.SetDefault("logging.syslog.enabled", false)
+	viper.SetDefault("logging.syslog.network", "udp")
+	viper.SetDefault("logging.syslog.address", "")
+	viper.SetDefault("logging.syslog.facility", "daemon")
+	viper.SetDefault("logging.syslog.tag", "birdnet-go")
+	viper.SetDefault("logging.syslog.level", "info")
+	viper.SetDefault("logging.syslog.insecureskipverify", false)
+	viper.SetDefault("logging.journald.enabled", false)
+	viper.SetDefault("logging.journald.level", "info")
+
+	// Event forwarding to an external message broker
+	viper.SetDefault("eventsink.enabled", false)
+	viper.SetDefault("eventsink.broker", "nats")
+	viper.SetDefault("eventsink.address", "")
+	viper.SetDefault("eventsink.tls", false)
+	viper.SetDefault("eventsink.insecureskipverify", false)
+	viper.SetDefault("eventsink.username", "")
+	viper.SetDefault("eventsink.password", "")
+	viper.SetDefault("eventsink.jetstream", false)
+	viper.SetDefault("eventsink.topics.errors", "birdnet.{node}.errors")
+	viper.SetDefault("eventsink.topics.resources", "birdnet.{node}.resources")
+	viper.SetDefault("eventsink.topics.detections", "birdnet.{node}.detections")
+	viper.SetDefault("eventsink.batchsize", 100)
+	viper.SetDefault("eventsink.flushinterval", 2)
+	viper.SetDefault("eventsink.maxpending", 10000)
+	viper.SetDefault("eventsink.streammaxlen", 100000)
+
+	// Web Push notification configuration
+	viper.SetDefault("webpush.enabled", false)
+	viper.SetDefault("webpush.subject", "")
+	viper.SetDefault("webpush.publickey", "")
+	viper.SetDefault("webpush.privatekey", "")
+	viper.SetDefault("webpush.ttl", 3600)
+	viper.SetDefault("webpush.urgency", "normal")
+
+	// Bulk export configuration
+	viper.SetDefault("dataexport.path", "exports")
+	viper.SetDefault("dataexport.ebird.grouping", "hourly")
+	viper.SetDefault("dataexport.ebird.minconfidence", 0.8)
+	viper.SetDefault("dataexport.ebird.locationname", "")
+	viper.SetDefault("dataexport.ebird.statecode", "")
+	viper.SetDefault("dataexport.ebird.countrycode", "")
+	viper.SetDefault("dataexport.raven.grouping", "day")
+	viper.SetDefault("dataexport.raven.lowfreq", 0)
+	viper.SetDefault("dataexport.raven.highfreq", 15000)
+	viper.SetDefault("dataexport.raven.species", map[string]any{})
+
+	// Outbound TLS configuration
+	viper.SetDefault("outboundtls.cacert", "")
+	viper.SetDefault("outboundtls.clientcert", "")
+	viper.SetDefault("outboundtls.clientkey", "")
+	viper.SetDefault("outboundtls.birdweather.insecureskipverify", false)
+	viper.SetDefault("outboundtls.weather.insecureskipverify", false)
+
+	// Outbound proxy configuration
+	viper.SetDefault("outboundproxy.url", "")
+	viper.SetDefault("outboundproxy.noproxy", "")
 }
@@ -78,9 +78,9 @@ const (
 	ThresholdMin = 0.0
 	ThresholdMax = 1.0
 
-	// Audio overlap range
+	// Audio overlap range, shared with config file validation
 	OverlapMin = 0.0
-	OverlapMax = 2.9
+	OverlapMax = MaxAnalysisOverlap
 
 	// Thread count minimum (no maximum enforced)
 	ThreadsMin = 0
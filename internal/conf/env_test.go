@@ -427,7 +427,8 @@ func TestValidateEnvOverlap(t *testing.T) {
 		wantErr bool
 	}{
 		{"valid min", "0.0", false},
-		{"valid max", "2.9", false},
+		{"valid max", "2.99", false},
+		{"valid legacy max", "2.9", false},
 		{"valid middle", "1.5", false},
 		{"valid decimal", "2.5", false},
 		// Whitespace handling
@@ -438,6 +439,7 @@ func TestValidateEnvOverlap(t *testing.T) {
 		// Edge cases and errors
 		{"too low", "-0.1", true},
 		{"too high", "3.0", true},
+		{"just above max", "2.995", true},
 		{"negative", "-1.0", true},
 		{"way too high", "5.0", true},
 		{"not a number", "large", true},
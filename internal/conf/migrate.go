@@ -0,0 +1,197 @@
+// conf/migrate.go: configuration schema versions and migrations of old configuration files
+package conf
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// CurrentConfigVersion is the configuration schema version written by this build.
+// Configuration files without a version are treated as version 0.
+const CurrentConfigVersion = 2
+
+// configMigration upgrades a configuration from version-1 to version
+type configMigration struct {
+	version     int
+	description string
+	migrate     func(v *viper.Viper)
+}
+
+// configMigrations lists the migrations in version order. Add a migration and increase
+// CurrentConfigVersion when configuration keys are renamed or moved.
+var configMigrations = []configMigration{
+	{
+		version:     1,
+		description: "move legacy realtime.openweather settings to realtime.weather",
+		migrate:     migrateLegacyOpenWeather,
+	},
+	{
+		version:     2,
+		description: "rename the window of source filter overrides to windowseconds",
+		migrate:     migrateSourceFilterWindow,
+	},
+}
+
+// migrateLegacyOpenWeather moves the settings of the OpenWeather integration from
+// realtime.openweather to realtime.weather.openweather and selects OpenWeather as the
+// weather provider when the legacy integration was enabled.
+func migrateLegacyOpenWeather(v *viper.Viper) {
+	if !v.InConfig("realtime.openweather") {
+		return
+	}
+
+	for _, key := range []string{"apikey", "endpoint", "units", "language"} {
+		legacyKey := "realtime.openweather." + key
+		if v.InConfig(legacyKey) && !v.InConfig("realtime.weather.openweather."+key) {
+			v.Set("realtime.weather.openweather."+key, v.Get(legacyKey))
+		}
+	}
+
+	if v.GetBool("realtime.openweather.enabled") && !v.InConfig("realtime.weather.provider") {
+		v.Set("realtime.weather.provider", string(WeatherOpenWeather))
+	}
+}
+
+// migrateSourceFilterWindow renames the window of the privacy and dog bark filter overrides
+// of audio sources to windowseconds, so the seconds are not mistaken for the minutes of the
+// global dog bark filter.
+func migrateSourceFilterWindow(v *viper.Viper) {
+	overrides, ok := v.Get("realtime.sourceoverrides").([]any)
+	if !ok {
+		return
+	}
+
+	changed := false
+	for _, item := range overrides {
+		override, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, filter := range []string{"privacyfilter", "dogbarkfilter"} {
+			settings, ok := override[filter].(map[string]any)
+			if !ok {
+				continue
+			}
+			window, exists := settings["window"]
+			if !exists {
+				continue
+			}
+			if _, exists := settings["windowseconds"]; !exists {
+				settings["windowseconds"] = window
+			}
+			delete(settings, "window")
+			changed = true
+		}
+	}
+
+	if changed {
+		v.Set("realtime.sourceoverrides", overrides)
+	}
+}
+
+// configFileVersion returns the schema version of the configuration file read by v
+func configFileVersion(v *viper.Viper) int {
+	if !v.InConfig("configversion") {
+		return 0
+	}
+	return v.GetInt("configversion")
+}
+
+// migrateConfig applies the migrations newer than the version of the configuration file
+// to v. It returns the version of the file and whether any migration was applied.
+func migrateConfig(v *viper.Viper) (fromVersion int, migrated bool) {
+	fromVersion = configFileVersion(v)
+	if fromVersion > CurrentConfigVersion {
+		log.Printf("Configuration file version %d is newer than the supported version %d, unknown settings are ignored",
+			fromVersion, CurrentConfigVersion)
+		return fromVersion, false
+	}
+
+	for _, migration := range configMigrations {
+		if migration.version <= fromVersion {
+			continue
+		}
+		migration.migrate(v)
+		log.Printf("Migrated configuration to version %d: %s", migration.version, migration.description)
+		migrated = true
+	}
+
+	v.Set("configversion", max(fromVersion, CurrentConfigVersion))
+	return fromVersion, migrated
+}
+
+// decodeSettings unmarshals the configuration read by v into settings. Keys of the
+// configuration file that do not match any setting are returned so that typos are
+// reported instead of silently ignored.
+func decodeSettings(v *viper.Viper, settings *Settings) (unknownKeys []string, err error) {
+	var metadata mapstructure.Metadata
+	if err := v.Unmarshal(settings, func(config *mapstructure.DecoderConfig) {
+		config.Metadata = &metadata
+	}); err != nil {
+		return nil, errors.New(err).
+			Component("conf").
+			Category(errors.CategoryValidation).
+			Context("operation", "decode-config").
+			Build()
+	}
+
+	for _, key := range metadata.Unused {
+		key = strings.ToLower(key)
+		// Keys set only by defaults or environment variables are not reported
+		if v.InConfig(key) {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	return unknownKeys, nil
+}
+
+// reportUnknownKeys logs configuration keys that do not match any setting and stores
+// them as validation warnings
+func reportUnknownKeys(settings *Settings, unknownKeys []string) {
+	for _, key := range unknownKeys {
+		warning := fmt.Sprintf("unknown configuration key %q is ignored, check the spelling and indentation", key)
+		log.Printf("Configuration warning: %s", warning)
+		settings.ValidationWarnings = append(settings.ValidationWarnings, warning)
+	}
+}
+
+// writeMigratedConfig keeps a copy of the configuration file as it was before the
+// migration and writes the migrated settings back to configPath.
+func writeMigratedConfig(configPath string, fromVersion int, settings *Settings) error {
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		return errors.New(err).
+			Component("conf").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read-config-before-migration").
+			Context("path", configPath).
+			Build()
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", configPath, fromVersion)
+	if err := os.WriteFile(backupPath, original, 0o600); err != nil {
+		return errors.New(err).
+			Component("conf").
+			Category(errors.CategoryFileIO).
+			Context("operation", "backup-config-before-migration").
+			Context("path", backupPath).
+			Build()
+	}
+
+	if err := SaveYAMLConfig(configPath, settings); err != nil {
+		return err
+	}
+	if err := os.Chmod(configPath, 0o600); err != nil {
+		log.Printf("Warning: Failed to set secure permissions on config file: %v", err)
+	}
+
+	log.Printf("Configuration file %s migrated from version %d to %d, previous file saved as %s",
+		configPath, fromVersion, settings.ConfigVersion, backupPath)
+	return nil
+}
@@ -0,0 +1,188 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestViper returns a viper instance that has read configYAML as its configuration file
+func newTestViper(t *testing.T, configYAML string) *viper.Viper {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(configYAML)))
+	return v
+}
+
+func TestMigrateLegacyOpenWeather(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, `
+realtime:
+  openweather:
+    enabled: true
+    apikey: secret
+    units: imperial
+`)
+
+	fromVersion, migrated := migrateConfig(v)
+	assert.Equal(t, 0, fromVersion)
+	assert.True(t, migrated)
+	assert.Equal(t, CurrentConfigVersion, v.GetInt("configversion"))
+	assert.Equal(t, "openweather", v.GetString("realtime.weather.provider"))
+	assert.Equal(t, "secret", v.GetString("realtime.weather.openweather.apikey"))
+	assert.Equal(t, "imperial", v.GetString("realtime.weather.openweather.units"))
+}
+
+func TestMigrateLegacyOpenWeatherKeepsCurrentSettings(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, `
+realtime:
+  openweather:
+    enabled: true
+    apikey: old
+  weather:
+    provider: yrno
+    openweather:
+      apikey: new
+`)
+
+	migrateConfig(v)
+	assert.Equal(t, "yrno", v.GetString("realtime.weather.provider"))
+	assert.Equal(t, "new", v.GetString("realtime.weather.openweather.apikey"))
+}
+
+func TestMigrateSourceFilterWindow(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, `
+configversion: 1
+realtime:
+  sourceoverrides:
+    - source: patio
+      privacyfilter:
+        window: 30
+      dogbarkfilter:
+        window: 120
+        windowseconds: 90
+    - source: garden
+`)
+
+	fromVersion, migrated := migrateConfig(v)
+	assert.Equal(t, 1, fromVersion)
+	assert.True(t, migrated)
+
+	settings := &Settings{}
+	unknownKeys, err := decodeSettings(v, settings)
+	require.NoError(t, err)
+	assert.Empty(t, unknownKeys)
+	require.Len(t, settings.Realtime.SourceOverrides, 2)
+	patio := settings.Realtime.SourceOverrides[0]
+	require.NotNil(t, patio.PrivacyFilter)
+	assert.Equal(t, 30, patio.PrivacyFilter.WindowSeconds)
+	require.NotNil(t, patio.DogBarkFilter)
+	assert.Equal(t, 90, patio.DogBarkFilter.WindowSeconds, "an existing windowseconds should be kept")
+}
+
+func TestMigrateConfigCurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, fmt.Sprintf("configversion: %d\nrealtime:\n  openweather:\n    enabled: true\n", CurrentConfigVersion))
+
+	fromVersion, migrated := migrateConfig(v)
+	assert.Equal(t, CurrentConfigVersion, fromVersion)
+	assert.False(t, migrated)
+	assert.False(t, v.IsSet("realtime.weather.provider"), "migrations of older versions should not run")
+}
+
+func TestMigrateConfigNewerVersion(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, "configversion: 99\n")
+
+	fromVersion, migrated := migrateConfig(v)
+	assert.Equal(t, 99, fromVersion)
+	assert.False(t, migrated)
+	assert.Equal(t, 99, v.GetInt("configversion"))
+}
+
+func TestDecodeSettingsReportsUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, `
+debug: true
+realtime:
+  audio:
+    sourse: sysdefault
+`)
+	v.SetDefault("realtime.notused", true)
+
+	settings := &Settings{}
+	unknownKeys, err := decodeSettings(v, settings)
+	require.NoError(t, err)
+	assert.True(t, settings.Debug)
+	assert.Equal(t, []string{"realtime.audio.sourse"}, unknownKeys)
+
+	reportUnknownKeys(settings, unknownKeys)
+	require.Len(t, settings.ValidationWarnings, 1)
+	assert.Contains(t, settings.ValidationWarnings[0], "realtime.audio.sourse")
+}
+
+func TestDecodeSettingsDefaultConfigHasNoUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, getDefaultConfig())
+
+	settings := &Settings{}
+	unknownKeys, err := decodeSettings(v, settings)
+	require.NoError(t, err)
+	assert.Empty(t, unknownKeys)
+	assert.Equal(t, CurrentConfigVersion, settings.ConfigVersion)
+}
+
+func TestDecodeSettingsInvalidType(t *testing.T) {
+	t.Parallel()
+
+	v := newTestViper(t, "realtime:\n  interval: often\n")
+
+	_, err := decodeSettings(v, &Settings{})
+	require.Error(t, err)
+	assert.Contains(t, strings.ToLower(err.Error()), "realtime.interval")
+}
+
+func TestWriteMigratedConfig(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := []byte("realtime:\n  openweather:\n    enabled: true\n")
+	require.NoError(t, os.WriteFile(configPath, original, 0o600))
+
+	settings := &Settings{ConfigVersion: CurrentConfigVersion}
+	settings.Realtime.Weather.Provider = "openweather"
+	require.NoError(t, writeMigratedConfig(configPath, 0, settings))
+
+	backup, err := os.ReadFile(configPath + ".v0.bak")
+	require.NoError(t, err)
+	assert.Equal(t, original, backup)
+
+	migrated := newTestViper(t, string(mustReadFile(t, configPath)))
+	assert.Equal(t, CurrentConfigVersion, migrated.GetInt("configversion"))
+	assert.Equal(t, "openweather", migrated.GetString("realtime.weather.provider"))
+	assert.False(t, migrated.InConfig("realtime.openweather"), "legacy settings should not be written")
+}
+
+// mustReadFile returns the contents of path
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,61 @@
+// conf/outbound_proxy.go proxy and transport of connections to external services
+package conf
+
+import (
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxySchemes lists the proxy URL schemes supported by HTTP transports
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ProxyFunc returns the proxy selection of HTTP transports. Without a proxy URL the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+func (s *OutboundProxySettings) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if s.URL == "" {
+		return http.ProxyFromEnvironment
+	}
+	proxyFunc := (&httpproxy.Config{HTTPProxy: s.URL, HTTPSProxy: s.URL, NoProxy: s.NoProxy}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// RedactedURL returns the proxy URL with the password masked, for logs and backups
+func (s *OutboundProxySettings) RedactedURL() string {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// ConfigureOutboundTransport applies the outbound proxy and TLS settings to a transport of
+// connections to external services. insecureSkipVerify is the setting of the integration.
+func (s *Settings) ConfigureOutboundTransport(transport *http.Transport, insecureSkipVerify bool) error {
+	tlsConfig, err := s.OutboundTLS.ClientTLSConfig("", insecureSkipVerify)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	transport.Proxy = s.OutboundProxy.ProxyFunc()
+	return nil
+}
+
+// OutboundHTTPTransport returns the transport of HTTP clients connecting to external services.
+// It returns nil, which makes http.Client use http.DefaultTransport with the proxy environment
+// variables, when no setting differs from the defaults.
+func (s *Settings) OutboundHTTPTransport(insecureSkipVerify bool) (http.RoundTripper, error) {
+	if s.OutboundProxy.URL == "" && !s.OutboundTLS.Configured(insecureSkipVerify) {
+		return nil, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if err := s.ConfigureOutboundTransport(transport, insecureSkipVerify); err != nil {
+		return nil, err
+	}
+	return transport, nil
+}
@@ -0,0 +1,80 @@
+// conf/outbound_tls.go TLS configuration of connections to external services
+package conf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Configured reports whether the outbound TLS settings differ from the Go defaults for a
+// connection that skips certificate verification when insecureSkipVerify is set
+func (s *OutboundTLSSettings) Configured(insecureSkipVerify bool) bool {
+	return s.CACert != "" || s.ClientCert != "" || insecureSkipVerify
+}
+
+// ClientTLSConfig returns the TLS configuration of a connection to serverName with the CA
+// bundle and client certificate. serverName may be empty to derive it from the address.
+// It returns nil when no setting differs from the defaults.
+func (s *OutboundTLSSettings) ClientTLSConfig(serverName string, insecureSkipVerify bool) (*tls.Config, error) {
+	if !s.Configured(insecureSkipVerify) {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+		// Skipping verification is an explicit per-integration choice for self-signed servers
+		InsecureSkipVerify: insecureSkipVerify, // #nosec G402 -- controlled by user configuration
+	}
+
+	if s.CACert != "" {
+		pool, err := loadCABundle(s.CACert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if s.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(s.ClientCert, s.ClientKey)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("config").
+				Category(errors.CategoryConfiguration).
+				Context("client_cert_path", s.ClientCert).
+				Context("client_key_path", s.ClientKey).
+				Build()
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCABundle returns the system roots with the certificates of a PEM CA bundle added
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("config").
+			Category(errors.CategoryConfiguration).
+			Context("ca_cert_path", path).
+			Build()
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Newf("no certificates found in CA bundle").
+			Component("config").
+			Category(errors.CategoryConfiguration).
+			Context("ca_cert_path", path).
+			Build()
+	}
+	return pool, nil
+}
@@ -0,0 +1,125 @@
+package conf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its key to dir and
+// returns the certificate and the file paths
+func writeClientCertificate(t *testing.T, dir string) (cert *x509.Certificate, certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "birdnet-go"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(dir, "client.crt")
+	keyPath = filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, certPath, keyPath
+}
+
+func TestOutboundTLSDefaults(t *testing.T) {
+	t.Parallel()
+
+	settings := &OutboundTLSSettings{}
+	tlsConfig, err := settings.ClientTLSConfig("", false)
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "unconfigured settings keep the Go defaults")
+
+	transport, err := (&Settings{}).OutboundHTTPTransport(false)
+	require.NoError(t, err)
+	assert.Nil(t, transport)
+
+	tlsConfig, err = settings.ClientTLSConfig("", true)
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Nil(t, tlsConfig.RootCAs)
+}
+
+func TestOutboundTLSInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	for name, settings := range map[string]*OutboundTLSSettings{
+		"missing CA bundle":              {CACert: filepath.Join(dir, "missing.pem")},
+		"CA bundle without certificates": {CACert: notPEM},
+		"missing client certificate":     {ClientCert: filepath.Join(dir, "client.crt"), ClientKey: filepath.Join(dir, "client.key")},
+	} {
+		_, err := settings.ClientTLSConfig("", false)
+		require.Error(t, err, name)
+		_, err = (&Settings{OutboundTLS: *settings}).OutboundHTTPTransport(false)
+		require.Error(t, err, name)
+	}
+}
+
+func TestOutboundTLSMutualAuthentication(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clientCert, certPath, keyPath := writeClientCertificate(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caPath := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	get := func(settings *OutboundTLSSettings) error {
+		transport, err := (&Settings{OutboundTLS: *settings}).OutboundHTTPTransport(false)
+		require.NoError(t, err)
+		client := &http.Client{Timeout: 5 * time.Second, Transport: transport}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close() //nolint:errcheck // test response body
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		return nil
+	}
+
+	require.NoError(t, get(&OutboundTLSSettings{CACert: caPath, ClientCert: certPath, ClientKey: keyPath}))
+	require.Error(t, get(&OutboundTLSSettings{CACert: caPath}), "server requires a client certificate")
+	require.Error(t, get(&OutboundTLSSettings{ClientCert: certPath, ClientKey: keyPath}), "server certificate is not trusted")
+}
@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -8,15 +9,47 @@ import (
 
 var (
 	speciesListMutex sync.RWMutex
+
+	// Listeners notified after the included species list is updated
+	speciesListenersMutex sync.Mutex
+	speciesListeners      = make(map[int]func([]string))
+	nextSpeciesListenerID int
 )
 
 // UpdateIncludedSpecies updates the included species list in the RangeFilter
 func (s *Settings) UpdateIncludedSpecies(species []string) {
 	speciesListMutex.Lock()
-	defer speciesListMutex.Unlock()
 	s.BirdNET.RangeFilter.Species = make([]string, len(species))
 	copy(s.BirdNET.RangeFilter.Species, species)
 	s.BirdNET.RangeFilter.LastUpdated = time.Now()
+	speciesListMutex.Unlock()
+
+	speciesListenersMutex.Lock()
+	listeners := make([]func([]string), 0, len(speciesListeners))
+	for _, listener := range speciesListeners {
+		listeners = append(listeners, listener)
+	}
+	speciesListenersMutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(slices.Clone(species))
+	}
+}
+
+// OnIncludedSpeciesUpdate registers a function called with a copy of the included species list
+// after each update. Listeners must not block. The returned function removes the listener.
+func OnIncludedSpeciesUpdate(listener func(species []string)) (remove func()) {
+	speciesListenersMutex.Lock()
+	defer speciesListenersMutex.Unlock()
+	id := nextSpeciesListenerID
+	nextSpeciesListenerID++
+	speciesListeners[id] = listener
+
+	return func() {
+		speciesListenersMutex.Lock()
+		defer speciesListenersMutex.Unlock()
+		delete(speciesListeners, id)
+	}
 }
 
 // GetIncludedSpecies returns the current included species list from the RangeFilter
@@ -0,0 +1,23 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnIncludedSpeciesUpdate(t *testing.T) {
+	settings := &Settings{}
+
+	var received [][]string
+	remove := OnIncludedSpeciesUpdate(func(species []string) {
+		received = append(received, species)
+	})
+
+	settings.UpdateIncludedSpecies([]string{"Turdus merula_Eurasian Blackbird"})
+	remove()
+	settings.UpdateIncludedSpecies([]string{"Parus major_Great Tit"})
+
+	assert.Equal(t, [][]string{{"Turdus merula_Eurasian Blackbird"}}, received, "removed listeners are not called")
+	assert.Equal(t, []string{"Parus major_Great Tit"}, settings.GetIncludedSpecies())
+}
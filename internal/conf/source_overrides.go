@@ -0,0 +1,30 @@
+// conf/source_overrides.go: helpers for per audio source detection overrides
+
+package conf
+
+import "strings"
+
+// IncludesSpecies reports whether any of the given species names is on the
+// override's include list. Names are compared case-insensitively.
+func (o *SourceOverride) IncludesSpecies(names ...string) bool {
+	return containsSpecies(o.Include, names)
+}
+
+// ExcludesSpecies reports whether any of the given species names is on the
+// override's exclude list. Names are compared case-insensitively.
+func (o *SourceOverride) ExcludesSpecies(names ...string) bool {
+	return containsSpecies(o.Exclude, names)
+}
+
+// containsSpecies checks if any of the names matches an entry in the list
+func containsSpecies(list, names []string) bool {
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		for _, name := range names {
+			if name != "" && strings.EqualFold(entry, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
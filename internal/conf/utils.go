@@ -12,6 +12,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -436,6 +437,15 @@ func IsSoxAvailable() (isAvailable bool, formats []string) {
 	return true, audioFormats // SoX is available, return the list of supported formats
 }
 
+// soxExportTypes are the audio export types SoX can write in place of FFmpeg
+var soxExportTypes = []string{"wav", "flac", "mp3"}
+
+// IsSoxExportType reports whether SoX can export an audio type, given the audio file formats
+// reported by the SoX binary
+func IsSoxExportType(exportType string, soxAudioTypes []string) bool {
+	return slices.Contains(soxExportTypes, exportType) && slices.Contains(soxAudioTypes, exportType)
+}
+
 // ValidateToolPath checks if a tool is available, either at an explicit path or in the system PATH.
 // It returns the validated path to the tool if found, or an empty string and an error otherwise.
 func ValidateToolPath(configuredPath, toolName string) (string, error) {
@@ -678,3 +688,12 @@ func getIPv4Subnet(ip net.IP, bits int) net.IP {
 	// Apply the subnet mask (e.g., for bits=24, this creates a 255.255.255.0 mask)
 	return ipv4.Mask(net.CIDRMask(bits, 32))
 }
+
+// ParseClockTime parses a local time of day in HH:MM format and returns the hour and minute.
+func ParseClockTime(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.Hour(), t.Minute(), nil
+}
@@ -3,15 +3,24 @@
 package conf
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
 	"log"
+	"math"
 	"net"
+	"net/mail"
+	"net/url"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/privacy"
 )
 
 // MinSoundLevelInterval is the minimum sound level interval in seconds to prevent excessive CPU usage
@@ -33,6 +42,9 @@ const (
 	MaxTruePeak      = 0.0   // Maximum true peak in dBTP
 )
 
+// apiTokenHashPattern matches a hex encoded SHA-256 hash of an API token
+var apiTokenHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
 // ValidationError represents a collection of validation errors
 type ValidationError struct {
 	Errors []string
@@ -43,6 +55,110 @@ func (ve ValidationError) Error() string {
 	return fmt.Sprintf("Validation errors: %v", ve.Errors)
 }
 
+// FieldError describes an invalid configuration value by its configuration key and
+// the values the setting accepts
+type FieldError struct {
+	Path    string   // configuration key, e.g. realtime.weather.provider
+	Value   string   // configured value
+	Allowed []string // accepted values
+}
+
+// Error returns a message naming the setting, its value and the accepted values
+func (fe FieldError) Error() string {
+	return fmt.Sprintf("%s: invalid value %q, allowed values are: %s", fe.Path, fe.Value, strings.Join(fe.Allowed, ", "))
+}
+
+// enumSetting is a setting that accepts one of a fixed set of values
+type enumSetting struct {
+	path       string
+	value      func(settings *Settings) string
+	allowed    []string
+	allowEmpty bool // empty value selects the built-in default
+}
+
+// enumSettings lists the settings whose invalid values were previously replaced with
+// defaults at runtime without notice
+var enumSettings = []enumSetting{
+	{
+		path:       "main.timestamps",
+		value:      func(s *Settings) string { return s.Main.Timestamps },
+		allowed:    []string{TimestampsSegment, TimestampsClock},
+		allowEmpty: true,
+	},
+	{
+		path:       "realtime.weather.provider",
+		value:      func(s *Settings) string { return s.Realtime.Weather.Provider },
+		allowed:    []string{"none", "yrno", "openweather", "wunderground", "openmeteo", "mqtt"},
+		allowEmpty: true,
+	},
+	{
+		path:    "realtime.audio.export.retention.policy",
+		value:   func(s *Settings) string { return s.Realtime.Audio.Export.Retention.Policy },
+		allowed: []string{"none", "age", "usage"},
+	},
+	{
+		path:       "realtime.rtsp.health.restartpolicy",
+		value:      func(s *Settings) string { return s.Realtime.RTSP.Health.RestartPolicy },
+		allowed:    []string{RTSPRestartAlways, RTSPRestartExit},
+		allowEmpty: true,
+	},
+	{
+		path:       "realtime.audio.streamtransport",
+		value:      func(s *Settings) string { return s.Realtime.Audio.StreamTransport },
+		allowed:    []string{"auto", "sse", "ws"},
+		allowEmpty: true,
+	},
+	{
+		path:       "realtime.dashboard.thumbnails.imageprovider",
+		value:      func(s *Settings) string { return s.Realtime.Dashboard.Thumbnails.ImageProvider },
+		allowed:    []string{"auto", "wikimedia", "avicommons", "macaulay", "local"},
+		allowEmpty: true,
+	},
+	{
+		path:       "realtime.dashboard.thumbnails.fallbackpolicy",
+		value:      func(s *Settings) string { return s.Realtime.Dashboard.Thumbnails.FallbackPolicy },
+		allowed:    []string{"none", "all"},
+		allowEmpty: true,
+	},
+	{
+		path:       "security.allowsubnetbypass.role",
+		value:      func(s *Settings) string { return s.Security.AllowSubnetBypass.Role },
+		allowed:    []string{RoleAdmin, RoleViewer},
+		allowEmpty: true,
+	},
+	{
+		path:       "webserver.locationprivacy.policy",
+		value:      func(s *Settings) string { return s.WebServer.LocationPrivacy.Policy },
+		allowed:    []string{LocationPolicyExact, LocationPolicyRounded, LocationPolicyHidden},
+		allowEmpty: true,
+	},
+	{
+		path:       "realtime.birdweather.normalization",
+		value:      func(s *Settings) string { return s.Realtime.Birdweather.Normalization },
+		allowed:    []string{NormalizationTwoPass, NormalizationSinglePass, NormalizationCached},
+		allowEmpty: true,
+	},
+}
+
+// validateEnumSettings checks the settings listed in enumSettings and returns an error
+// for each setting with a value it does not accept
+func validateEnumSettings(settings *Settings) []error {
+	var errs []error
+	for _, setting := range enumSettings {
+		value := setting.value(settings)
+		if (value == "" && setting.allowEmpty) || slices.Contains(setting.allowed, value) {
+			continue
+		}
+		errs = append(errs, errors.New(FieldError{Path: setting.path, Value: value, Allowed: setting.allowed}).
+			Component("conf").
+			Category(errors.CategoryValidation).
+			Context("validation_type", "enum-setting").
+			Context("path", setting.path).
+			Build())
+	}
+	return errs
+}
+
 // logValidationWarning logs a validation warning for telemetry purposes without returning an error
 func logValidationWarning(err error, validationType, warningType string) {
 	// Create an enhanced error for telemetry tracking
@@ -62,6 +178,16 @@ func ValidateSettings(settings *Settings) error {
 		ve.Errors = append(ve.Errors, err.Error())
 	}
 
+	// Validate the time zone of detection timestamps
+	if err := validateTimeZone(settings.Main.TimeZone); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate UI settings
+	if err := validateUISettings(&settings.UI); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
 	// Validate WebServer settings
 	if err := validateWebServerSettings(&settings.WebServer); err != nil {
 		ve.Errors = append(ve.Errors, err.Error())
@@ -102,6 +228,71 @@ func ValidateSettings(settings *Settings) error {
 		ve.Errors = append(ve.Errors, err.Error())
 	}
 
+	// Validate self-update settings
+	if err := validateUpdateSettings(&settings.Update); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate email report settings
+	if err := validateEmailSettings(&settings.Email); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate bulk export settings
+	if err := validateDataExportSettings(&settings.DataExport); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the rotation and retention of the log files
+	if err := validateLogConfig(&settings.Main.Log); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the retention of deleted detections
+	if err := validateTrashSettings(&settings.Trash); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the buffering of detections while the database is unavailable
+	if err := validateDatastoreSpoolSettings(&settings.Output.Spool); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the log levels of components
+	if err := validateLoggingSettings(&settings.Logging); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the forwarding of events to a message broker
+	if err := validateEventSinkSettings(&settings.EventSink); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the Web Push notification settings
+	if err := validateWebPushSettings(&settings.WebPush); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate TLS settings of connections to external services
+	if err := validateOutboundTLSSettings(&settings.OutboundTLS); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the proxy of connections to external services
+	if err := validateOutboundProxySettings(&settings.OutboundProxy); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate the anonymization policy
+	if err := validatePrivacySettings(&settings.Privacy); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate settings that accept a fixed set of values
+	for _, err := range validateEnumSettings(settings) {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
 	// If there are any errors, return the ValidationError
 	if len(ve.Errors) > 0 {
 		return ve
@@ -124,8 +315,8 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 	}
 
 	// Check if overlap is within valid range
-	if birdnetSettings.Overlap < 0 || birdnetSettings.Overlap > 2.99 {
-		errs = append(errs, "BirdNET overlap value must be between 0 and 2.99 seconds")
+	if birdnetSettings.Overlap < 0 || birdnetSettings.Overlap > MaxAnalysisOverlap {
+		errs = append(errs, fmt.Sprintf("BirdNET overlap value must be between 0 and %.2f seconds", MaxAnalysisOverlap))
 	}
 
 	// Check if longitude is within valid range
@@ -143,6 +334,31 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 		errs = append(errs, "BirdNET threads must be at least 0")
 	}
 
+	// Check if the scheduler queue limit is within valid range
+	if birdnetSettings.Scheduler.QueueLimit < 1 || birdnetSettings.Scheduler.QueueLimit > MaxInferenceQueueLimit {
+		errs = append(errs, fmt.Sprintf("BirdNET scheduler queue limit must be between 1 and %d", MaxInferenceQueueLimit))
+	}
+
+	// Check the number of inference workers and the batch size
+	if birdnetSettings.Scheduler.Workers < 1 || birdnetSettings.Scheduler.Workers > MaxInferenceWorkers {
+		errs = append(errs, fmt.Sprintf("BirdNET scheduler workers must be between 1 and %d", MaxInferenceWorkers))
+	}
+	if birdnetSettings.Scheduler.BatchSize < 1 || birdnetSettings.Scheduler.BatchSize > MaxInferenceBatchSize {
+		errs = append(errs, fmt.Sprintf("BirdNET scheduler batch size must be between 1 and %d", MaxInferenceBatchSize))
+	}
+
+	// Check the silence gate levels
+	gate := &birdnetSettings.SilenceGate
+	if gate.Threshold < -120 || gate.Threshold > 0 {
+		errs = append(errs, "BirdNET silence gate threshold must be between -120 and 0 dBFS")
+	}
+	if gate.Hysteresis < 0 || gate.Hysteresis > 40 {
+		errs = append(errs, "BirdNET silence gate hysteresis must be between 0 and 40 dB")
+	}
+	if gate.MaxEntropy < 0 || gate.MaxEntropy > 1 {
+		errs = append(errs, "BirdNET silence gate max entropy must be between 0 and 1")
+	}
+
 	// Validate RangeFilter settings
 	if birdnetSettings.RangeFilter.Model == "" {
 		errs = append(errs, "RangeFilter model must not be empty")
@@ -185,6 +401,37 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 	return nil
 }
 
+// validateUISettings validates the species name locale and normalizes it to a locale code
+func validateUISettings(settings *UISettings) error {
+	if settings.Locale == "" {
+		return nil
+	}
+
+	normalizedLocale, err := NormalizeLocale(settings.Locale)
+	if err != nil {
+		return errors.New(fmt.Errorf("UI locale '%s' is not supported", settings.Locale)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "ui-locale").
+			Build()
+	}
+	settings.Locale = normalizedLocale
+	return nil
+}
+
+// validateTimeZone checks that the time zone of detection timestamps is known
+func validateTimeZone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return errors.New(fmt.Errorf("main.timezone: unknown time zone %q", name)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "time-zone").
+			Build()
+	}
+	return nil
+}
+
 // validateWebServerSettings validates the WebServer-specific settings
 func validateWebServerSettings(settings *WebServerSettings) error {
 	if settings.Enabled {
@@ -195,226 +442,1498 @@ func validateWebServerSettings(settings *WebServerSettings) error {
 				Context("validation_type", "webserver-port-required").
 				Build()
 		}
-		// You might want to add more specific port validation here
+		// You might want to add more specific port validation here
+	}
+
+	// Validate LiveStream settings
+	if settings.LiveStream.BitRate < 16 || settings.LiveStream.BitRate > 320 {
+		return errors.New(fmt.Errorf("LiveStream bitrate must be between 16 and 320 kbps, got %d", settings.LiveStream.BitRate)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "livestream-bitrate").
+			Context("bitrate", settings.LiveStream.BitRate).
+			Build()
+	}
+
+	if settings.LiveStream.SegmentLength < 1 || settings.LiveStream.SegmentLength > 30 {
+		return errors.New(fmt.Errorf("LiveStream segment length must be between 1 and 30 seconds, got %d", settings.LiveStream.SegmentLength)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "livestream-segment-length").
+			Context("segment_length", settings.LiveStream.SegmentLength).
+			Build()
+	}
+
+	if settings.LocationPrivacy.Precision < 0 || settings.LocationPrivacy.Precision > 6 {
+		return errors.New(fmt.Errorf("location privacy precision must be between 0 and 6 decimal places, got %d", settings.LocationPrivacy.Precision)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "location-privacy-precision").
+			Context("precision", settings.LocationPrivacy.Precision).
+			Build()
+	}
+
+	return nil
+}
+
+// validateSecuritySettings validates the security-specific settings
+func validateSecuritySettings(settings *Security) error {
+	// Check if any OAuth provider is enabled (OAuth providers require host for redirect URLs)
+	// Note: BasicAuth doesn't require host as it doesn't use OAuth redirects
+	if (settings.GoogleAuth.Enabled || settings.GithubAuth.Enabled) && settings.Host == "" {
+		return errors.New(fmt.Errorf("security.host must be set when using OAuth authentication providers (Google or GitHub)")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "security-oauth-host").
+			Context("google_enabled", settings.GoogleAuth.Enabled).
+			Context("github_enabled", settings.GithubAuth.Enabled).
+			Build()
+	}
+
+	// AutoTLS validation
+	if settings.AutoTLS {
+		// Host is required for AutoTLS
+		if settings.Host == "" {
+			return errors.New(fmt.Errorf("security.host must be set when AutoTLS is enabled")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "security-autotls-host").
+				Build()
+		}
+
+		// Warning about port requirements when running in container
+		if RunningInContainer() {
+			log.Println("WARNING: AutoTLS requires ports 80 and 443 to be exposed.")
+			log.Println("Ensure your Docker configuration maps these ports:")
+			log.Println("  ports:")
+			log.Println("    - \"80:80\"    # Required for ACME HTTP-01 challenge")
+			log.Println("    - \"443:443\"  # Required for HTTPS/AutoTLS")
+			log.Println("Consider using docker-compose.autotls.yml for proper AutoTLS configuration.")
+		}
+	}
+
+	// Validate the subnet bypass setting against the allowed pattern
+	if settings.AllowSubnetBypass.Enabled {
+		subnets := strings.Split(settings.AllowSubnetBypass.Subnet, ",")
+		for _, subnet := range subnets {
+			_, _, err := net.ParseCIDR(strings.TrimSpace(subnet))
+			if err != nil {
+				return errors.New(err).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "security-subnet-format").
+					Context("subnet", subnet).
+					Build()
+			}
+		}
+	}
+
+	// Validate session duration
+	if settings.SessionDuration <= 0 {
+		return errors.New(fmt.Errorf("security.sessionduration must be a positive duration")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "security-session-duration").
+			Build()
+	}
+
+	if err := validateOIDCProvider(&settings.OIDCAuth); err != nil {
+		return err
+	}
+
+	if err := validateUserAccounts(settings.Users); err != nil {
+		return err
+	}
+
+	return validateAPITokens(settings.APITokens)
+}
+
+// validateOIDCProvider checks that an enabled OpenID Connect provider has an issuer, a client
+// and at least one group mapped to a role, without groups nobody could log in
+func validateOIDCProvider(settings *OIDCProvider) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	var problem string
+	switch {
+	case settings.IssuerURL == "":
+		problem = "issuerurl is required"
+	case !strings.HasPrefix(settings.IssuerURL, "https://") && !strings.HasPrefix(settings.IssuerURL, "http://"):
+		problem = "issuerurl must be an http or https URL"
+	case settings.ClientID == "":
+		problem = "clientid is required"
+	case settings.RedirectURI == "":
+		problem = "redirecturi is required"
+	case strings.TrimSpace(settings.GroupsClaim) == "":
+		problem = "groupsclaim is required"
+	case len(settings.AdminGroups) == 0 && len(settings.ViewerGroups) == 0:
+		problem = "admingroups or viewergroups must list at least one group"
+	}
+	if problem != "" {
+		return errors.New(fmt.Errorf("security.oidcauth: %s", problem)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "security-oidc").
+			Context("issuer_url", settings.IssuerURL).
+			Build()
+	}
+	return nil
+}
+
+// validateUserAccounts checks that users have unique names, a known role and a bcrypt password hash
+func validateUserAccounts(users []UserAccount) error {
+	seen := make(map[string]bool, len(users))
+	for i := range users {
+		user := &users[i]
+		var problem string
+		switch {
+		case strings.TrimSpace(user.Username) == "":
+			problem = "username is required"
+		case seen[strings.ToLower(user.Username)]:
+			problem = "username is used more than once"
+		case user.Role != RoleAdmin && user.Role != RoleViewer:
+			problem = fmt.Sprintf("role must be %s or %s, got %q", RoleAdmin, RoleViewer, user.Role)
+		case !strings.HasPrefix(user.PasswordHash, "$2"):
+			problem = "passwordhash must be a bcrypt hash"
+		default:
+			if err := user.Notifications.Validate(); err != nil {
+				problem = "notifications: " + err.Error()
+			}
+		}
+		if problem != "" {
+			return errors.New(fmt.Errorf("security.users[%d]: %s", i, problem)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "security-user").
+				Context("username", user.Username).
+				Build()
+		}
+		seen[strings.ToLower(user.Username)] = true
+	}
+	return nil
+}
+
+// validateAPITokens checks that API tokens have unique names, a known role, a SHA-256 hash
+// and known scopes
+func validateAPITokens(tokens []APIToken) error {
+	seen := make(map[string]bool, len(tokens))
+	for i := range tokens {
+		token := &tokens[i]
+		var problem string
+		switch {
+		case strings.TrimSpace(token.Name) == "":
+			problem = "name is required"
+		case seen[token.Name]:
+			problem = "name is used more than once"
+		case token.Role != RoleAdmin && token.Role != RoleViewer:
+			problem = fmt.Sprintf("role must be %s or %s, got %q", RoleAdmin, RoleViewer, token.Role)
+		case !apiTokenHashPattern.MatchString(token.TokenHash):
+			problem = "tokenhash must be a hex encoded SHA-256 hash"
+		default:
+			if err := ValidateAPITokenScopes(token.Scopes, token.Role); err != nil {
+				problem = err.Error()
+			}
+		}
+		if problem != "" {
+			return errors.New(fmt.Errorf("security.apitokens[%d]: %s", i, problem)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "security-api-token").
+				Context("name", token.Name).
+				Build()
+		}
+		seen[token.Name] = true
+	}
+	return nil
+}
+
+// ValidateAPITokenScopes checks that scopes are known and that scopes allowing changes
+// are only given to admin tokens
+func ValidateAPITokenScopes(scopes []string, role string) error {
+	for _, scope := range scopes {
+		switch {
+		case !slices.Contains(APITokenScopes, scope):
+			return fmt.Errorf("unknown scope %q, valid scopes are %s", scope, strings.Join(APITokenScopes, ", "))
+		case AdminScope(scope) && role != RoleAdmin:
+			return fmt.Errorf("scope %s requires the %s role", scope, RoleAdmin)
+		}
+	}
+	return nil
+}
+
+// validateRealtimeSettings validates the Realtime-specific settings
+func validateRealtimeSettings(settings *RealtimeSettings) error {
+	// Check if interval is non-negative
+	if settings.Interval < 0 {
+		return errors.New(fmt.Errorf("realtime interval must be non-negative")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "realtime-interval").
+			Build()
+	}
+
+	// Validate the sound card declaration
+	if err := validateAudioSource(settings.Audio.Source); err != nil {
+		return err
+	}
+
+	// Validate RTSP restart backoff
+	if err := validateRTSPHealthSettings(&settings.RTSP.Health); err != nil {
+		return err
+	}
+
+	// Validate MQTT settings
+	if err := validateMQTTSettings(&settings.MQTT); err != nil {
+		return err
+	}
+
+	// Validate sound level settings
+	if err := validateSoundLevelSettings(&settings.Audio.SoundLevel); err != nil {
+		return err
+	}
+
+	// Validate species settings
+	if err := validateSpeciesConfigSettings(&settings.Species); err != nil {
+		return err
+	}
+
+	// Validate morning brief settings
+	if err := validateMorningBriefSettings(&settings.MorningBrief); err != nil {
+		return err
+	}
+
+	// Validate privacy filter settings
+	if err := validatePrivacyFilterSettings(&settings.PrivacyFilter); err != nil {
+		return err
+	}
+
+	// Validate per-source overrides
+	if err := validateSourceOverrides(settings.SourceOverrides); err != nil {
+		return err
+	}
+
+	// Validate processing profiles
+	if err := validateProcessingProfiles(settings.Profiles); err != nil {
+		return err
+	}
+
+	// Validate analysis schedule
+	if err := validateAnalysisSchedule(&settings.Schedule); err != nil {
+		return err
+	}
+
+	// Validate seasonal prior
+	if err := validateSeasonalPrior(&settings.SeasonalPrior); err != nil {
+		return err
+	}
+
+	// Validate rarity scoring
+	if err := validateRarity(&settings.Rarity); err != nil {
+		return err
+	}
+
+	// Validate drain settings
+	if err := validateDrainSettings(&settings.Drain); err != nil {
+		return err
+	}
+
+	// Validate systemd notification settings
+	if err := validateSystemdSettings(&settings.Systemd); err != nil {
+		return err
+	}
+
+	// Validate duplicate suppression intervals
+	if err := settings.EventIntervals.Validate(); err != nil {
+		return err
+	}
+
+	// Validate overload settings
+	if err := validateOverloadSettings(&settings.Overload); err != nil {
+		return err
+	}
+
+	// Validate training data collection settings
+	if err := validateCollectionSettings(&settings.Collection); err != nil {
+		return err
+	}
+
+	// Validate ExecuteCommand rate limits
+	if err := validateCommandGuardSettings(&settings.CommandGuard, &settings.Species); err != nil {
+		return err
+	}
+
+	// Add more realtime settings validation as needed
+	return nil
+}
+
+// validateAudioSource validates the sound card declaration, streams and files are read by
+// FFmpeg and declared under realtime.rtsp.urls
+func validateAudioSource(source string) error {
+	prefix, _, _ := strings.Cut(strings.ToLower(source), ":")
+	switch prefix {
+	case "rtsp", "rtsps", "srt", "rtmp", "rtmps", "http", "https", "udp", "file":
+		return errors.New(fmt.Errorf("audio source %q is not a sound card, declare streams and files under realtime.rtsp.urls", source)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-source").
+			Build()
+	}
+	return nil
+}
+
+// validateRTSPHealthSettings validates the restart backoff of RTSP streams, zero values select
+// the defaults
+func validateRTSPHealthSettings(settings *RTSPHealthSettings) error {
+	if settings.InitialBackoff < 0 || settings.InitialBackoff > 300 {
+		return errors.New(fmt.Errorf("RTSP initial backoff must be between 0 and 300 seconds, got %d", settings.InitialBackoff)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "rtsp-health-initial-backoff").
+			Build()
+	}
+	if settings.MaxBackoff < 0 || settings.MaxBackoff > 3600 ||
+		(settings.MaxBackoff > 0 && settings.MaxBackoff < settings.InitialBackoff) {
+		return errors.New(fmt.Errorf("RTSP max backoff must be between the initial backoff and 3600 seconds, got %d", settings.MaxBackoff)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "rtsp-health-max-backoff").
+			Build()
+	}
+	return nil
+}
+
+// validateMQTTSettings validates the MQTT-specific settings
+func validateMQTTSettings(settings *MQTTSettings) error {
+	if settings.Enabled {
+		// Check if broker is provided when enabled
+		if settings.Broker == "" {
+			return errors.New(fmt.Errorf("MQTT broker URL is required when MQTT is enabled")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "mqtt-broker-required").
+				Build()
+		}
+
+		// Check if topic is provided when enabled
+		if settings.Topic == "" {
+			return errors.New(fmt.Errorf("MQTT topic is required when MQTT is enabled")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "mqtt-topic-required").
+				Build()
+		}
+
+		// Explicitly support anonymous connections (empty username and password)
+		// No validation required for username/password - they can be empty for anonymous connections
+
+		// Validate retry settings if enabled
+		if settings.RetrySettings.Enabled {
+			if settings.RetrySettings.MaxRetries < 0 {
+				return errors.New(fmt.Errorf("MQTT max retries must be non-negative")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-max-retries").
+					Build()
+			}
+			if settings.RetrySettings.InitialDelay < 0 {
+				return errors.New(fmt.Errorf("MQTT initial delay must be non-negative")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-initial-delay").
+					Build()
+			}
+			if settings.RetrySettings.MaxDelay < settings.RetrySettings.InitialDelay {
+				return errors.New(fmt.Errorf("MQTT max delay must be greater than or equal to initial delay")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-max-delay").
+					Build()
+			}
+			if settings.RetrySettings.BackoffMultiplier <= 0 {
+				return errors.New(fmt.Errorf("MQTT backoff multiplier must be positive")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-backoff-multiplier").
+					Build()
+			}
+		}
+
+		// Validate batch settings if enabled
+		if settings.Batch.Enabled {
+			if settings.Batch.Interval < 1 || settings.Batch.Interval > 3600 {
+				return errors.New(fmt.Errorf("MQTT batch interval must be between 1 and 3600 seconds")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-batch-interval").
+					Build()
+			}
+			if settings.Batch.MaxSize < 1 || settings.Batch.MaxSize > 1000 {
+				return errors.New(fmt.Errorf("MQTT batch max size must be between 1 and 1000")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-batch-max-size").
+					Build()
+			}
+		}
+
+		// The message expiry interval is a four byte integer in MQTT 5
+		if settings.V5.Enabled && (settings.V5.MessageExpiry < 0 || int64(settings.V5.MessageExpiry) > math.MaxUint32) {
+			return errors.New(fmt.Errorf("MQTT 5 message expiry must be between 0 and %d seconds", uint32(math.MaxUint32))).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "mqtt-v5-message-expiry").
+				Build()
+		}
+
+		// A shared subscription group is a single topic level without wildcards
+		if settings.V5.Enabled && settings.V5.SharedGroup != "" {
+			if settings.V5.CommandTopic == "" {
+				return errors.New(fmt.Errorf("MQTT 5 shared group requires a command topic")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-v5-shared-group").
+					Build()
+			}
+			if strings.ContainsAny(settings.V5.SharedGroup, "/+#") {
+				return errors.New(fmt.Errorf("MQTT 5 shared group must not contain '/', '+' or '#'")).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-v5-shared-group").
+					Build()
+			}
+		}
+	}
+	return nil
+}
+
+// validateMorningBriefSettings validates the morning brief notification settings
+func validateMorningBriefSettings(settings *MorningBriefSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if _, _, err := ParseClockTime(settings.Time); err != nil {
+		return errors.New(fmt.Errorf("morning brief time must be in HH:MM format, got %q", settings.Time)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "morning-brief-time").
+			Build()
+	}
+
+	if settings.CompareDays < 1 || settings.CompareDays > 90 {
+		return errors.New(fmt.Errorf("morning brief compare days must be between 1 and 90, got %d", settings.CompareDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "morning-brief-compare-days").
+			Context("compare_days", settings.CompareDays).
+			Build()
+	}
+
+	return nil
+}
+
+// validateAnalysisSchedule validates the sunrise/sunset based analysis window
+func validateAnalysisSchedule(settings *AnalysisScheduleSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	switch settings.Mode {
+	case ScheduleModeDay, ScheduleModeNight:
+		return nil
+	case ScheduleModeCustom:
+	default:
+		return errors.New(fmt.Errorf("analysis schedule mode must be day, night or custom, got %q", settings.Mode)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "analysis-schedule-mode").
+			Build()
+	}
+
+	for name, offset := range map[string]SunEventOffset{"start": settings.Start, "end": settings.End} {
+		switch offset.Event {
+		case "civildawn", "sunrise", "sunset", "civildusk":
+		default:
+			return errors.New(fmt.Errorf("analysis schedule %s event must be civildawn, sunrise, sunset or civildusk, got %q", name, offset.Event)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "analysis-schedule-event").
+				Build()
+		}
+		if offset.Offset < -720 || offset.Offset > 720 {
+			return errors.New(fmt.Errorf("analysis schedule %s offset must be between -720 and 720 minutes, got %d", name, offset.Offset)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "analysis-schedule-offset").
+				Context("offset", offset.Offset).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+// validateSeasonalPrior validates the seasonal occurrence threshold adjustment settings
+func validateSeasonalPrior(settings *SeasonalPriorSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.Strength < 0 || settings.Strength > 0.5 {
+		return errors.New(fmt.Errorf("seasonal prior strength must be between 0 and 0.5, got %g", settings.Strength)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "seasonal-prior-strength").
+			Build()
+	}
+
+	if settings.Pivot <= 0 || settings.Pivot >= 1 {
+		return errors.New(fmt.Errorf("seasonal prior pivot must be between 0 and 1 exclusive, got %g", settings.Pivot)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "seasonal-prior-pivot").
+			Build()
+	}
+
+	if settings.Min < 0 || settings.Max > 1 || settings.Min >= settings.Max {
+		return errors.New(fmt.Errorf("seasonal prior min and max must satisfy 0 <= min < max <= 1, got min %g max %g", settings.Min, settings.Max)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "seasonal-prior-bounds").
+			Build()
+	}
+
+	return nil
+}
+
+// validateDrainSettings validates the graceful drain settings
+func validateDrainSettings(settings *DrainSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.Timeout < 1 || settings.Timeout > 600 {
+		return errors.New(fmt.Errorf("drain timeout must be between 1 and 600 seconds, got %d", settings.Timeout)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "drain-timeout").
+			Build()
+	}
+
+	return nil
+}
+
+// validateDatastoreSpoolSettings validates the buffering of detections while the database
+// is unavailable
+func validateDatastoreSpoolSettings(settings *DatastoreSpoolSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.Path == "" {
+		return errors.New(fmt.Errorf("spool path must not be empty")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "spool-path").
+			Build()
+	}
+	if settings.MaxDetections < 1 || settings.MaxDetections > 1000000 {
+		return errors.New(fmt.Errorf("spool maxDetections must be between 1 and 1000000, got %d", settings.MaxDetections)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "spool-max-detections").
+			Build()
+	}
+	if settings.RetryInterval < 1 || settings.RetryInterval > 3600 {
+		return errors.New(fmt.Errorf("spool retryInterval must be between 1 and 3600 seconds, got %d", settings.RetryInterval)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "spool-retry-interval").
+			Build()
+	}
+
+	return nil
+}
+
+// validateSystemdSettings validates the systemd notification settings
+func validateSystemdSettings(settings *SystemdSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	// Chunks are analyzed every few seconds, a shorter timeout would restart healthy services
+	// after a brief pause of a source
+	if settings.StallTimeout < 30 || settings.StallTimeout > 3600 {
+		return errors.New(fmt.Errorf("systemd stall timeout must be between 30 and 3600 seconds, got %d", settings.StallTimeout)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "systemd-stall-timeout").
+			Build()
+	}
+
+	return nil
+}
+
+// validateOverloadSettings validates the per-detection processing deadline
+func validateOverloadSettings(settings *OverloadSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	// Detections are held for the capture length before processing starts, a shorter
+	// deadline would shed the optional actions of every detection
+	if settings.Deadline < 30 || settings.Deadline > 3600 {
+		return errors.New(fmt.Errorf("overload deadline must be between 30 and 3600 seconds, got %d", settings.Deadline)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "overload-deadline").
+			Build()
+	}
+
+	return nil
+}
+
+// validateCommandGuardSettings validates the ExecuteCommand rate limits and their overrides
+// in species actions
+func validateCommandGuardSettings(settings *CommandGuardSettings, species *SpeciesSettings) error {
+	if settings.MaxPerHour < 0 || settings.MaxConcurrent < 0 || settings.TripAfter < 0 {
+		return errors.New(fmt.Errorf("command guard limits must not be negative, got maxPerHour %d, maxConcurrent %d, tripAfter %d",
+			settings.MaxPerHour, settings.MaxConcurrent, settings.TripAfter)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "command-guard-limits").
+			Build()
+	}
+
+	for name, config := range species.Config {
+		for i := range config.Actions {
+			action := &config.Actions[i]
+			if action.MaxPerHour < 0 || action.MaxConcurrent < 0 {
+				return errors.New(fmt.Errorf("command limits of %s action %d must not be negative", name, i+1)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "command-guard-action-limits").
+					Build()
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateCollectionSettings validates the training data collection mode settings
+func validateCollectionSettings(settings *CollectionSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.Path == "" {
+		return errors.New(fmt.Errorf("collection path is required when collection mode is enabled")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "collection-path").
+			Build()
+	}
+
+	if settings.MinLevel < -120 || settings.MinLevel > 0 {
+		return errors.New(fmt.Errorf("collection minimum level must be between -120 and 0 dBFS, got %v", settings.MinLevel)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "collection-min-level").
+			Build()
+	}
+
+	if settings.MaxSizeMB < 1 {
+		return errors.New(fmt.Errorf("collection disk quota must be at least 1 MB, got %d", settings.MaxSizeMB)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "collection-quota").
+			Build()
+	}
+
+	if settings.RetentionDays < 1 || settings.RetentionDays > 365 {
+		return errors.New(fmt.Errorf("collection retention must be between 1 and 365 days, got %d", settings.RetentionDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "collection-retention").
+			Build()
+	}
+
+	return nil
+}
+
+// validateRarity validates the detection rarity scoring settings
+func validateRarity(settings *RaritySettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.RangeWeight < 0 || settings.RangeWeight > 1 {
+		return errors.New(fmt.Errorf("rarity range weight must be between 0 and 1, got %g", settings.RangeWeight)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "rarity-range-weight").
+			Build()
+	}
+
+	if settings.HistoryYears < 1 || settings.HistoryYears > 20 {
+		return errors.New(fmt.Errorf("rarity history years must be between 1 and 20, got %d", settings.HistoryYears)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "rarity-history-years").
+			Build()
+	}
+
+	if settings.WindowDays < 0 || settings.WindowDays > 30 {
+		return errors.New(fmt.Errorf("rarity window days must be between 0 and 30, got %d", settings.WindowDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "rarity-window-days").
+			Build()
+	}
+
+	if settings.NotifyThreshold < 0 || settings.NotifyThreshold > 1 {
+		return errors.New(fmt.Errorf("rarity notify threshold must be between 0 and 1, got %g", settings.NotifyThreshold)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "rarity-notify-threshold").
+			Build()
+	}
+
+	return nil
+}
+
+// validatePrivacyFilterSettings validates the privacy filter mode and window
+func validatePrivacyFilterSettings(settings *PrivacyFilterSettings) error {
+	switch settings.Mode {
+	case "", PrivacyFilterModeDiscard, PrivacyFilterModeRedact:
+	default:
+		return errors.New(fmt.Errorf("privacy filter mode must be discard or redact, got %q", settings.Mode)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "privacy-filter-mode").
+			Build()
+	}
+	if settings.Window < 0 {
+		return errors.New(fmt.Errorf("privacy filter window must not be negative, got %d", settings.Window)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "privacy-filter-window").
+			Build()
+	}
+	return nil
+}
+
+// validateSourceOverrides validates per audio source threshold and species filter overrides
+func validateSourceOverrides(overrides []SourceOverride) error {
+	seen := make(map[string]bool, len(overrides))
+	for i := range overrides {
+		source := strings.TrimSpace(overrides[i].Source)
+		if source == "" {
+			return errors.New(fmt.Errorf("source override %d is missing the source", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "source-override-source").
+				Build()
+		}
+
+		key := strings.ToLower(source)
+		if seen[key] {
+			return errors.New(fmt.Errorf("source override %d duplicates an earlier override for the same source", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "source-override-duplicate").
+				Build()
+		}
+		seen[key] = true
+
+		if overrides[i].Threshold < 0 || overrides[i].Threshold > 1 {
+			return errors.New(fmt.Errorf("source override threshold must be between 0 and 1, got %.2f", overrides[i].Threshold)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "source-override-threshold").
+				Context("threshold", overrides[i].Threshold).
+				Build()
+		}
+
+		if err := validateSourceFilterOverride("privacy filter", overrides[i].PrivacyFilter); err != nil {
+			return err
+		}
+		if err := validateSourceFilterOverride("dog bark filter", overrides[i].DogBarkFilter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSourceFilterOverride validates the privacy or dog bark filter settings of a source override
+func validateSourceFilterOverride(filter string, override *SourceFilterOverride) error {
+	if override == nil {
+		return nil
+	}
+	if override.Confidence < 0 || override.Confidence > 1 {
+		return errors.New(fmt.Errorf("source override %s confidence must be between 0 and 1, got %.2f", filter, override.Confidence)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "source-override-filter-confidence").
+			Context("filter", filter).
+			Build()
+	}
+	if override.WindowSeconds < 0 {
+		return errors.New(fmt.Errorf("source override %s window must not be negative, got %d", filter, override.WindowSeconds)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "source-override-filter-window").
+			Context("filter", filter).
+			Build()
+	}
+	return nil
+}
+
+// validateProcessingProfiles validates analysis profiles and their source assignments
+func validateProcessingProfiles(profiles []ProcessingProfile) error {
+	names := make(map[string]bool, len(profiles))
+
+	for i := range profiles {
+		profile := &profiles[i]
+		name := strings.TrimSpace(profile.Name)
+		if name == "" {
+			return errors.New(fmt.Errorf("processing profile %d is missing a name", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "profile-name").
+				Build()
+		}
+		if names[strings.ToLower(name)] {
+			return errors.New(fmt.Errorf("duplicate processing profile name %q", name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "profile-duplicate").
+				Context("profile", name).
+				Build()
+		}
+		names[strings.ToLower(name)] = true
+
+		if len(profile.Sources) == 0 {
+			return errors.New(fmt.Errorf("processing profile %q has no sources assigned", name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "profile-sources").
+				Context("profile", name).
+				Build()
+		}
+		for _, source := range profile.Sources {
+			if strings.TrimSpace(source) == "" {
+				return errors.New(fmt.Errorf("processing profile %q has an empty source", name)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "profile-sources").
+					Context("profile", name).
+					Build()
+			}
+		}
+
+		if profile.Threshold < 0 || profile.Threshold > 1 {
+			return errors.New(fmt.Errorf("processing profile %q threshold must be between 0 and 1, got %.2f", name, profile.Threshold)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "profile-threshold").
+				Context("profile", name).
+				Context("threshold", profile.Threshold).
+				Build()
+		}
+
+		if (profile.ModelPath == "") != (profile.LabelPath == "") {
+			return errors.New(fmt.Errorf("processing profile %q needs both a model path and a label path for a custom model", name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "profile-model").
+				Context("profile", name).
+				Build()
+		}
+
+		if err := validateSpeciesConfigSettings(&profile.Species); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSoundLevelSettings validates the SoundLevel-specific settings
+func validateSoundLevelSettings(settings *SoundLevelSettings) error {
+	// Sound level settings are optional, only validate if enabled
+	if settings.Enabled {
+		// Check if interval is at least the minimum to avoid excessive CPU usage
+		if settings.Interval < MinSoundLevelInterval {
+			return errors.New(fmt.Errorf("sound level interval must be at least %d seconds to avoid excessive CPU usage, got %d", MinSoundLevelInterval, settings.Interval)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "sound-level-interval").
+				Context("interval", settings.Interval).
+				Context("minimum_interval", MinSoundLevelInterval).
+				Build()
+		}
+	}
+	return nil
+}
+
+// validateBirdweatherSettings validates the Birdweather-specific settings
+func validateBirdweatherSettings(settings *BirdweatherSettings) error {
+	if settings.Enabled {
+		// Check if ID is provided when enabled
+		if settings.ID == "" {
+			log.Println("Error: Birdweather ID is required when enabled. Disabling Birdweather.")
+			settings.Enabled = false
+			return nil
+		}
+
+		// Validate Birdweather ID format
+		validIDPattern := regexp.MustCompile("^[a-zA-Z0-9]{24}$")
+		if !validIDPattern.MatchString(settings.ID) {
+			log.Println("Error: Invalid Birdweather ID format: must be 24 alphanumeric characters. Disabling Birdweather.")
+			settings.Enabled = false
+			return nil
+		}
+
+		// Check if threshold is within valid range
+		if settings.Threshold < 0 || settings.Threshold > 1 {
+			return errors.New(fmt.Errorf("birdweather threshold must be between 0 and 1")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "birdweather-threshold").
+				Build()
+		}
+
+		// Check if location accuracy is non-negative
+		if settings.LocationAccuracy < 0 {
+			return errors.New(fmt.Errorf("birdweather location accuracy must be non-negative")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "birdweather-location-accuracy").
+				Build()
+		}
+
+		if err := validateHTTPRecorderSettings(&settings.Recorder); err != nil {
+			return err
+		}
+
+		if err := validateUploadQuotaSettings(&settings.UploadQuota); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateUploadQuotaSettings validates the daily upload limits per species
+func validateUploadQuotaSettings(settings *UploadQuotaSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.DefaultLimit < 0 {
+		return errors.New(fmt.Errorf("upload quota default limit must be non-negative, got %d", settings.DefaultLimit)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "upload-quota-limit").
+			Build()
+	}
+
+	for species, limit := range settings.Species {
+		if limit < 0 {
+			return errors.New(fmt.Errorf("upload quota of %s must be non-negative, got %d", species, limit)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "upload-quota-limit").
+				Build()
+		}
+	}
+	return nil
+}
+
+// validateHTTPRecorderSettings validates the size limits of an HTTP recorder
+func validateHTTPRecorderSettings(settings *HTTPRecorderSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.Size < 1 || settings.Size > 500 {
+		return errors.New(fmt.Errorf("HTTP recorder size must be between 1 and 500, got %d", settings.Size)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "http-recorder-size").
+			Build()
+	}
+
+	if settings.MaxBodySize < 0 || settings.MaxBodySize > 1<<20 {
+		return errors.New(fmt.Errorf("HTTP recorder body size must be between 0 and 1048576 bytes, got %d", settings.MaxBodySize)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "http-recorder-body-size").
+			Build()
+	}
+
+	return nil
+}
+
+// validateUpdateSettings validates the binary self-update settings
+func validateUpdateSettings(settings *UpdateSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.Channel != UpdateChannelStable && settings.Channel != UpdateChannelBeta {
+		return errors.New(fmt.Errorf("update channel must be stable or beta, got %q", settings.Channel)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "update-channel").
+			Build()
+	}
+
+	feedURL, err := url.Parse(settings.FeedURL)
+	if err != nil || (feedURL.Scheme != "https" && feedURL.Scheme != "http") || feedURL.Host == "" {
+		return errors.New(fmt.Errorf("update feed URL must be an http or https URL, got %q", settings.FeedURL)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "update-feed-url").
+			Build()
+	}
+
+	key, err := base64.StdEncoding.DecodeString(settings.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New(fmt.Errorf("update public key must be a base64 encoded ed25519 public key")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "update-public-key").
+			Build()
+	}
+
+	if settings.HealthTimeout < 10 {
+		return errors.New(fmt.Errorf("update health timeout must be at least 10 seconds, got %d", settings.HealthTimeout)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "update-health-timeout").
+			Build()
+	}
+
+	return nil
+}
+
+// validateEmailSettings validates the SMTP server and digest settings for email reports
+func validateEmailSettings(settings *EmailSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	smtp := &settings.SMTP
+	if smtp.Host == "" {
+		return errors.New(fmt.Errorf("SMTP host is required when email reports are enabled")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-smtp-host").
+			Build()
+	}
+
+	if smtp.Port < 1 || smtp.Port > 65535 {
+		return errors.New(fmt.Errorf("SMTP port must be between 1 and 65535, got %d", smtp.Port)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-smtp-port").
+			Build()
+	}
+
+	switch smtp.Encryption {
+	case SMTPEncryptionNone, SMTPEncryptionStartTLS, SMTPEncryptionTLS:
+	default:
+		return errors.New(fmt.Errorf("SMTP encryption must be none, starttls or tls, got %q", smtp.Encryption)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-smtp-encryption").
+			Build()
+	}
+
+	if _, err := mail.ParseAddress(smtp.From); err != nil {
+		return errors.New(fmt.Errorf("email sender address %q is invalid: %w", smtp.From, err)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-from").
+			Build()
+	}
+
+	if len(smtp.To) == 0 {
+		return errors.New(fmt.Errorf("at least one email recipient is required")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-to").
+			Build()
+	}
+	for _, to := range smtp.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return errors.New(fmt.Errorf("email recipient address %q is invalid: %w", to, err)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "email-to").
+				Build()
+		}
+	}
+
+	digest := &settings.Digest
+	if digest.Interval != DigestIntervalDaily && digest.Interval != DigestIntervalWeekly {
+		return errors.New(fmt.Errorf("email digest interval must be daily or weekly, got %q", digest.Interval)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-digest-interval").
+			Build()
+	}
+
+	if _, _, err := ParseClockTime(digest.Time); err != nil {
+		return errors.New(fmt.Errorf("email digest time must be in HH:MM format, got %q", digest.Time)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-digest-time").
+			Build()
+	}
+
+	if digest.Interval == DigestIntervalWeekly {
+		if _, err := ParseWeekday(digest.Weekday); err != nil {
+			return errors.New(fmt.Errorf("email digest weekday must be a day of the week, got %q", digest.Weekday)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "email-digest-weekday").
+				Build()
+		}
+	}
+
+	if digest.MaxClips < 0 || digest.MaxClips > 50 {
+		return errors.New(fmt.Errorf("email digest max clips must be between 0 and 50, got %d", digest.MaxClips)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "email-digest-max-clips").
+			Build()
+	}
+
+	if digest.BaseURL != "" {
+		baseURL, err := url.Parse(digest.BaseURL)
+		if err != nil || (baseURL.Scheme != "https" && baseURL.Scheme != "http") || baseURL.Host == "" {
+			return errors.New(fmt.Errorf("email digest base URL must be an http or https URL, got %q", digest.BaseURL)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "email-digest-base-url").
+				Build()
+		}
+	}
+
+	return nil
+}
+
+// validateOutboundTLSSettings validates that the client certificate and its key are set together
+func validateOutboundTLSSettings(settings *OutboundTLSSettings) error {
+	if (settings.ClientCert == "") != (settings.ClientKey == "") {
+		return errors.New(fmt.Errorf("outbound TLS client certificate and client key must be set together")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "outbound-tls-client-cert").
+			Build()
+	}
+	return nil
+}
+
+// validateLogConfig validates the rotation size and retention of a log file
+func validateLogConfig(config *LogConfig) error {
+	for field, value := range map[string]int64{
+		"maxsize":    config.MaxSize,
+		"maxbackups": int64(config.MaxBackups),
+		"maxage":     int64(config.MaxAge),
+	} {
+		if value < 0 {
+			return errors.New(fmt.Errorf("log %s must not be negative, got %d", field, value)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "log-rotation").
+				Build()
+		}
+	}
+	return nil
+}
+
+// validateTrashSettings validates the retention of deleted detections
+func validateTrashSettings(settings *TrashSettings) error {
+	if settings.RetentionDays < 0 {
+		return errors.New(fmt.Errorf("trash retention must not be negative, got %d days", settings.RetentionDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "trash-retention").
+			Build()
+	}
+	return nil
+}
+
+// logLevelNames are the level names accepted for component log levels
+var logLevelNames = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// SyslogFacilities are the syslog facility names accepted by the syslog output
+var SyslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// validateLoggingSettings validates the log levels of components and the log outputs
+func validateLoggingSettings(settings *LoggingSettings) error {
+	if err := validateSyslogSettings(&settings.Syslog); err != nil {
+		return err
+	}
+	if settings.Journald.Enabled && settings.Journald.Level != "" && !slices.Contains(logLevelNames, strings.ToLower(settings.Journald.Level)) {
+		return errors.New(fmt.Errorf("unknown journald log level %q, must be one of %s", settings.Journald.Level, strings.Join(logLevelNames, ", "))).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "logging-journald").
+			Build()
+	}
+
+	for component, level := range settings.Levels {
+		if strings.TrimSpace(component) == "" {
+			return errors.New(fmt.Errorf("log level component name must not be empty")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "logging-levels").
+				Build()
+		}
+		if !slices.Contains(logLevelNames, strings.ToLower(level)) {
+			return errors.New(fmt.Errorf("unknown log level %q for component %q, must be one of %s", level, component, strings.Join(logLevelNames, ", "))).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "logging-levels").
+				Build()
+		}
+	}
+	return nil
+}
+
+// validateSyslogSettings validates the syslog server and message settings
+func validateSyslogSettings(settings *SyslogSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	var err error
+	switch {
+	case !slices.Contains([]string{"udp", "tcp", "tls"}, strings.ToLower(settings.Network)):
+		err = fmt.Errorf("syslog network must be udp, tcp or tls, got %q", settings.Network)
+	case settings.Address == "":
+		err = fmt.Errorf("syslog address must be set when syslog output is enabled")
+	case !slices.Contains(SyslogFacilities, strings.ToLower(settings.Facility)):
+		err = fmt.Errorf("unknown syslog facility %q, must be one of %s", settings.Facility, strings.Join(SyslogFacilities, ", "))
+	case len(settings.Tag) > 48 || strings.ContainsAny(settings.Tag, " \t\n"):
+		err = fmt.Errorf("syslog tag %q must be at most 48 characters without spaces", settings.Tag)
+	case settings.Level != "" && !slices.Contains(logLevelNames, strings.ToLower(settings.Level)):
+		err = fmt.Errorf("unknown syslog log level %q, must be one of %s", settings.Level, strings.Join(logLevelNames, ", "))
+	default:
+		if _, _, splitErr := net.SplitHostPort(settings.Address); splitErr != nil {
+			err = fmt.Errorf("syslog address %q must be host:port", settings.Address)
+		}
+	}
+	if err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "logging-syslog").
+			Build()
+	}
+
+	return nil
+}
+
+// EventSinkBrokers lists the supported message brokers of event forwarding
+var EventSinkBrokers = []string{"nats", "kafka", "redis"}
+
+// eventSinkTopicPattern matches topics of event forwarding, placeholders included
+var eventSinkTopicPattern = regexp.MustCompile(`^[A-Za-z0-9._{}-]+$`)
+
+// validateEventSinkSettings validates the broker, addresses, topics and batching of event forwarding
+func validateEventSinkSettings(settings *EventSinkSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	var err error
+	switch {
+	case !slices.Contains(EventSinkBrokers, strings.ToLower(settings.Broker)):
+		err = fmt.Errorf("event sink broker must be one of %s, got %q", strings.Join(EventSinkBrokers, ", "), settings.Broker)
+	case strings.TrimSpace(settings.Address) == "":
+		err = fmt.Errorf("event sink address must be set when event forwarding is enabled")
+	case strings.EqualFold(settings.Broker, "kafka") && (settings.Username != "" || settings.Password != ""):
+		err = fmt.Errorf("event sink credentials are not supported with kafka, use TLS client certificates")
+	case settings.Topics.Errors == "" && settings.Topics.Resources == "" && settings.Topics.Detections == "":
+		err = fmt.Errorf("event sink needs a topic for at least one event type")
+	case settings.BatchSize < 1:
+		err = fmt.Errorf("event sink batchSize must be at least 1, got %d", settings.BatchSize)
+	case settings.FlushInterval < 1:
+		err = fmt.Errorf("event sink flushInterval must be at least 1 second, got %d", settings.FlushInterval)
+	case settings.MaxPending < settings.BatchSize:
+		err = fmt.Errorf("event sink maxPending (%d) must be at least batchSize (%d)", settings.MaxPending, settings.BatchSize)
+	case settings.StreamMaxLen < 0:
+		err = fmt.Errorf("event sink streamMaxLen must not be negative, got %d", settings.StreamMaxLen)
+	}
+
+	if err == nil {
+		for _, address := range strings.Split(settings.Address, ",") {
+			if _, _, splitErr := net.SplitHostPort(strings.TrimSpace(address)); splitErr != nil {
+				err = fmt.Errorf("event sink address %q must be host:port", strings.TrimSpace(address))
+				break
+			}
+		}
 	}
 
-	// Validate LiveStream settings
-	if settings.LiveStream.BitRate < 16 || settings.LiveStream.BitRate > 320 {
-		return errors.New(fmt.Errorf("LiveStream bitrate must be between 16 and 320 kbps, got %d", settings.LiveStream.BitRate)).
-			Category(errors.CategoryValidation).
-			Context("validation_type", "livestream-bitrate").
-			Context("bitrate", settings.LiveStream.BitRate).
-			Build()
+	if err == nil {
+		topics := map[string]string{
+			"errors":     settings.Topics.Errors,
+			"resources":  settings.Topics.Resources,
+			"detections": settings.Topics.Detections,
+		}
+		placeholders := map[string]string{"errors": "{component}", "resources": "{resource}", "detections": "{species}"}
+		for _, eventType := range []string{"errors", "resources", "detections"} {
+			topic := topics[eventType]
+			if topic == "" {
+				continue
+			}
+			remaining := strings.NewReplacer("{node}", "", placeholders[eventType], "").Replace(topic)
+			if !eventSinkTopicPattern.MatchString(topic) || strings.ContainsAny(remaining, "{}") {
+				err = fmt.Errorf("event sink %s topic %q may only contain letters, digits, '.', '_', '-', {node} and %s",
+					eventType, topic, placeholders[eventType])
+				break
+			}
+		}
 	}
 
-	if settings.LiveStream.SegmentLength < 1 || settings.LiveStream.SegmentLength > 30 {
-		return errors.New(fmt.Errorf("LiveStream segment length must be between 1 and 30 seconds, got %d", settings.LiveStream.SegmentLength)).
+	if err != nil {
+		return errors.New(err).
 			Category(errors.CategoryValidation).
-			Context("validation_type", "livestream-segment-length").
-			Context("segment_length", settings.LiveStream.SegmentLength).
+			Context("validation_type", "event-sink").
 			Build()
 	}
 
 	return nil
 }
 
-// validateSecuritySettings validates the security-specific settings
-func validateSecuritySettings(settings *Security) error {
-	// Check if any OAuth provider is enabled (OAuth providers require host for redirect URLs)
-	// Note: BasicAuth doesn't require host as it doesn't use OAuth redirects
-	if (settings.GoogleAuth.Enabled || settings.GithubAuth.Enabled) && settings.Host == "" {
-		return errors.New(fmt.Errorf("security.host must be set when using OAuth authentication providers (Google or GitHub)")).
+// webPushMaxTTL is the longest time push services keep notifications, 28 days
+const webPushMaxTTL = 28 * 24 * 60 * 60
+
+// validateWebPushSettings validates the contact, VAPID keys and delivery options of Web Push
+func validateWebPushSettings(settings *WebPushSettings) error {
+	var err error
+	switch {
+	case settings.TTL < 0 || settings.TTL > webPushMaxTTL:
+		err = fmt.Errorf("web push ttl must be between 0 and %d seconds, got %d", webPushMaxTTL, settings.TTL)
+	case !slices.Contains(WebPushUrgencies, settings.Urgency):
+		err = fmt.Errorf("web push urgency must be one of %s, got %q", strings.Join(WebPushUrgencies, ", "), settings.Urgency)
+	case (settings.PublicKey == "") != (settings.PrivateKey == ""):
+		err = fmt.Errorf("web push needs both VAPID keys, or neither to generate them")
+	case settings.PublicKey != "" && !isBase64URLKey(settings.PublicKey, 65):
+		err = fmt.Errorf("web push public key must be an uncompressed P-256 point, base64url encoded")
+	case settings.PrivateKey != "" && !isBase64URLKey(settings.PrivateKey, 32):
+		err = fmt.Errorf("web push private key must be a P-256 private key, base64url encoded")
+	case settings.Enabled && !strings.HasPrefix(settings.Subject, "mailto:") && !strings.HasPrefix(settings.Subject, "https://"):
+		err = fmt.Errorf("web push subject must be a mailto: or https: URL push services can contact, got %q", settings.Subject)
+	}
+
+	if err != nil {
+		return errors.New(err).
 			Category(errors.CategoryValidation).
-			Context("validation_type", "security-oauth-host").
-			Context("google_enabled", settings.GoogleAuth.Enabled).
-			Context("github_enabled", settings.GithubAuth.Enabled).
+			Context("validation_type", "web-push").
 			Build()
 	}
 
-	// AutoTLS validation
-	if settings.AutoTLS {
-		// Host is required for AutoTLS
-		if settings.Host == "" {
-			return errors.New(fmt.Errorf("security.host must be set when AutoTLS is enabled")).
+	return nil
+}
+
+// isBase64URLKey reports whether key is the unpadded base64url encoding of size bytes
+func isBase64URLKey(key string, size int) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(key)
+	return err == nil && len(decoded) == size
+}
+
+// validatePrivacySettings validates the scrubber names of the anonymization policy
+func validatePrivacySettings(settings *PrivacySettings) error {
+	for _, name := range settings.Scrubbers {
+		if !privacy.IsScrubber(name) {
+			return errors.New(fmt.Errorf("unknown privacy scrubber %q, must be one of %s", name, strings.Join(privacy.Scrubbers, ", "))).
 				Category(errors.CategoryValidation).
-				Context("validation_type", "security-autotls-host").
+				Context("validation_type", "privacy-scrubbers").
 				Build()
 		}
+	}
 
-		// Warning about port requirements when running in container
-		if RunningInContainer() {
-			log.Println("WARNING: AutoTLS requires ports 80 and 443 to be exposed.")
-			log.Println("Ensure your Docker configuration maps these ports:")
-			log.Println("  ports:")
-			log.Println("    - \"80:80\"    # Required for ACME HTTP-01 challenge")
-			log.Println("    - \"443:443\"  # Required for HTTPS/AutoTLS")
-			log.Println("Consider using docker-compose.autotls.yml for proper AutoTLS configuration.")
+	for name := range settings.Replacements {
+		if !privacy.IsScrubber(name) {
+			return errors.New(fmt.Errorf("privacy replacement for unknown scrubber %q", name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "privacy-replacements").
+				Build()
 		}
 	}
 
-	// Validate the subnet bypass setting against the allowed pattern
-	if settings.AllowSubnetBypass.Enabled {
-		subnets := strings.Split(settings.AllowSubnetBypass.Subnet, ",")
-		for _, subnet := range subnets {
-			_, _, err := net.ParseCIDR(strings.TrimSpace(subnet))
-			if err != nil {
-				return errors.New(err).
-					Category(errors.CategoryValidation).
-					Context("validation_type", "security-subnet-format").
-					Context("subnet", subnet).
-					Build()
-			}
+	for _, host := range settings.AllowedHosts {
+		if strings.TrimSpace(host) == "" || (strings.ContainsAny(host, "/: ") && net.ParseIP(host) == nil) {
+			return errors.New(fmt.Errorf("privacy allowed host %q must be a host name or IP address", host)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "privacy-allowed-hosts").
+				Build()
 		}
 	}
 
-	// Validate session duration
-	if settings.SessionDuration <= 0 {
-		return errors.New(fmt.Errorf("security.sessionduration must be a positive duration")).
+	return nil
+}
+
+// validateOutboundProxySettings validates the scheme and host of the outbound proxy URL
+func validateOutboundProxySettings(settings *OutboundProxySettings) error {
+	if settings.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil || !slices.Contains(proxySchemes, u.Scheme) || u.Hostname() == "" {
+		return errors.New(fmt.Errorf("outbound proxy URL must be an http, https or socks5 URL with a host, got %q", settings.RedactedURL())).
 			Category(errors.CategoryValidation).
-			Context("validation_type", "security-session-duration").
+			Context("validation_type", "outbound-proxy-url").
 			Build()
 	}
-
 	return nil
 }
 
-// validateRealtimeSettings validates the Realtime-specific settings
-func validateRealtimeSettings(settings *RealtimeSettings) error {
-	// Check if interval is non-negative
-	if settings.Interval < 0 {
-		return errors.New(fmt.Errorf("realtime interval must be non-negative")).
+// validateDataExportSettings validates the bulk detection export settings
+func validateDataExportSettings(settings *DataExportSettings) error {
+	if strings.TrimSpace(settings.Path) == "" {
+		return errors.New(fmt.Errorf("export path must not be empty")).
 			Category(errors.CategoryValidation).
-			Context("validation_type", "realtime-interval").
+			Context("validation_type", "export-path").
 			Build()
 	}
 
-	// Validate MQTT settings
-	if err := validateMQTTSettings(&settings.MQTT); err != nil {
-		return err
+	ebird := &settings.EBird
+	if ebird.Grouping != ChecklistGroupingHourly && ebird.Grouping != ChecklistGroupingDaily {
+		return errors.New(fmt.Errorf("eBird export grouping must be hourly or daily, got %q", ebird.Grouping)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "export-ebird-grouping").
+			Build()
 	}
 
-	// Validate sound level settings
-	if err := validateSoundLevelSettings(&settings.Audio.SoundLevel); err != nil {
-		return err
+	if ebird.MinConfidence < 0 || ebird.MinConfidence > 1 {
+		return errors.New(fmt.Errorf("eBird export minimum confidence must be between 0 and 1, got %v", ebird.MinConfidence)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "export-ebird-min-confidence").
+			Build()
 	}
 
-	// Validate species settings
-	if err := validateSpeciesConfigSettings(&settings.Species); err != nil {
-		return err
+	if ebird.CountryCode != "" && !regexp.MustCompile(`^[A-Za-z]{2}$`).MatchString(ebird.CountryCode) {
+		return errors.New(fmt.Errorf("eBird export country code must be two letters, got %q", ebird.CountryCode)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "export-ebird-country-code").
+			Build()
 	}
 
-	// Add more realtime settings validation as needed
-	return nil
-}
-
-// validateMQTTSettings validates the MQTT-specific settings
-func validateMQTTSettings(settings *MQTTSettings) error {
-	if settings.Enabled {
-		// Check if broker is provided when enabled
-		if settings.Broker == "" {
-			return errors.New(fmt.Errorf("MQTT broker URL is required when MQTT is enabled")).
-				Category(errors.CategoryValidation).
-				Context("validation_type", "mqtt-broker-required").
-				Build()
-		}
+	raven := &settings.Raven
+	if raven.Grouping != RavenTablePerDay && raven.Grouping != RavenTablePerClip {
+		return errors.New(fmt.Errorf("raven export grouping must be day or clip, got %q", raven.Grouping)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "export-raven-grouping").
+			Build()
+	}
 
-		// Check if topic is provided when enabled
-		if settings.Topic == "" {
-			return errors.New(fmt.Errorf("MQTT topic is required when MQTT is enabled")).
-				Category(errors.CategoryValidation).
-				Context("validation_type", "mqtt-topic-required").
-				Build()
+	if err := validateRavenFrequencyRange("default", raven.LowFreq, raven.HighFreq); err != nil {
+		return err
+	}
+	for species, bounds := range raven.Species {
+		if err := validateRavenFrequencyRange(species, bounds.LowFreq, bounds.HighFreq); err != nil {
+			return err
 		}
+	}
 
-		// Explicitly support anonymous connections (empty username and password)
-		// No validation required for username/password - they can be empty for anonymous connections
+	return nil
+}
 
-		// Validate retry settings if enabled
-		if settings.RetrySettings.Enabled {
-			if settings.RetrySettings.MaxRetries < 0 {
-				return errors.New(fmt.Errorf("MQTT max retries must be non-negative")).
-					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-max-retries").
-					Build()
-			}
-			if settings.RetrySettings.InitialDelay < 0 {
-				return errors.New(fmt.Errorf("MQTT initial delay must be non-negative")).
-					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-initial-delay").
-					Build()
-			}
-			if settings.RetrySettings.MaxDelay < settings.RetrySettings.InitialDelay {
-				return errors.New(fmt.Errorf("MQTT max delay must be greater than or equal to initial delay")).
-					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-max-delay").
-					Build()
-			}
-			if settings.RetrySettings.BackoffMultiplier <= 0 {
-				return errors.New(fmt.Errorf("MQTT backoff multiplier must be positive")).
-					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-backoff-multiplier").
-					Build()
-			}
-		}
+// validateRavenFrequencyRange checks that a selection frequency band is within the audible
+// range of 48 kHz recordings
+func validateRavenFrequencyRange(name string, low, high float64) error {
+	if low < 0 || high > 24000 || low >= high {
+		return errors.New(fmt.Errorf("raven %s frequency range must satisfy 0 <= low < high <= 24000 Hz, got %v-%v", name, low, high)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "export-raven-frequency").
+			Build()
 	}
 	return nil
 }
 
-// validateSoundLevelSettings validates the SoundLevel-specific settings
-func validateSoundLevelSettings(settings *SoundLevelSettings) error {
-	// Sound level settings are optional, only validate if enabled
-	if settings.Enabled {
-		// Check if interval is at least the minimum to avoid excessive CPU usage
-		if settings.Interval < MinSoundLevelInterval {
-			return errors.New(fmt.Errorf("sound level interval must be at least %d seconds to avoid excessive CPU usage, got %d", MinSoundLevelInterval, settings.Interval)).
-				Category(errors.CategoryValidation).
-				Context("validation_type", "sound-level-interval").
-				Context("interval", settings.Interval).
-				Context("minimum_interval", MinSoundLevelInterval).
-				Build()
-		}
+// validateAudioSettings validates the audio settings and sets ffmpeg and sox paths
+// validateEncoderSettings validates the limits of the encoder pool
+func validateEncoderSettings(settings *EncoderSettings) error {
+	if settings.Workers < 1 {
+		return errors.New(fmt.Errorf("encoder workers must be at least 1, got %d", settings.Workers)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-export-encoder-workers").
+			Context("workers", settings.Workers).
+			Build()
+	}
+	if settings.QueueSize < 0 {
+		return errors.New(fmt.Errorf("encoder queue size must not be negative, got %d", settings.QueueSize)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-export-encoder-queue").
+			Context("queue_size", settings.QueueSize).
+			Build()
+	}
+	if settings.Timeout < 1 {
+		return errors.New(fmt.Errorf("encoder timeout must be at least 1 second, got %d", settings.Timeout)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-export-encoder-timeout").
+			Context("timeout", settings.Timeout).
+			Build()
+	}
+	if settings.FlacEncoder != "native" && settings.FlacEncoder != "ffmpeg" {
+		return errors.New(fmt.Errorf("FLAC encoder must be native or ffmpeg, got %q", settings.FlacEncoder)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-export-encoder-flac").
+			Context("flac_encoder", settings.FlacEncoder).
+			Build()
 	}
 	return nil
 }
 
-// validateBirdweatherSettings validates the Birdweather-specific settings
-func validateBirdweatherSettings(settings *BirdweatherSettings) error {
-	if settings.Enabled {
-		// Check if ID is provided when enabled
-		if settings.ID == "" {
-			log.Println("Error: Birdweather ID is required when enabled. Disabling Birdweather.")
-			settings.Enabled = false
-			return nil
-		}
-
-		// Validate Birdweather ID format
-		validIDPattern := regexp.MustCompile("^[a-zA-Z0-9]{24}$")
-		if !validIDPattern.MatchString(settings.ID) {
-			log.Println("Error: Invalid Birdweather ID format: must be 24 alphanumeric characters. Disabling Birdweather.")
-			settings.Enabled = false
-			return nil
-		}
-
-		// Check if threshold is within valid range
-		if settings.Threshold < 0 || settings.Threshold > 1 {
-			return errors.New(fmt.Errorf("birdweather threshold must be between 0 and 1")).
+// validateCalibrationSettings validates the calibration window and the stored gain offsets
+func validateCalibrationSettings(settings *CalibrationSettings) error {
+	if settings.Window < 30 || settings.Window > 3600 {
+		return errors.New(fmt.Errorf("calibration window must be between 30 and 3600 seconds, got %d", settings.Window)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-calibration-window").
+			Context("window", settings.Window).
+			Build()
+	}
+	for _, profile := range settings.Profiles {
+		if profile.Source == "" {
+			return errors.New(fmt.Errorf("calibration profile has no source")).
 				Category(errors.CategoryValidation).
-				Context("validation_type", "birdweather-threshold").
+				Context("validation_type", "audio-calibration-profile").
 				Build()
 		}
-
-		// Check if location accuracy is non-negative
-		if settings.LocationAccuracy < 0 {
-			return errors.New(fmt.Errorf("birdweather location accuracy must be non-negative")).
+		if math.Abs(profile.Gain) > 30 {
+			return errors.New(fmt.Errorf("calibration gain of %s must be between -30 and 30 dB, got %.1f", profile.Source, profile.Gain)).
 				Category(errors.CategoryValidation).
-				Context("validation_type", "birdweather-location-accuracy").
+				Context("validation_type", "audio-calibration-profile").
+				Context("gain", profile.Gain).
 				Build()
 		}
 	}
 	return nil
 }
 
-// validateAudioSettings validates the audio settings and sets ffmpeg and sox paths
 func validateAudioSettings(settings *AudioSettings) error {
 	// Validate and determine the effective FFmpeg path
 	validatedFfmpegPath, ffmpegErr := ValidateToolPath(settings.FfmpegPath, GetFfmpegBinaryName())
@@ -441,6 +1960,14 @@ func validateAudioSettings(settings *AudioSettings) error {
 		settings.SoxAudioTypes = formats
 	}
 
+	if err := validateEncoderSettings(&settings.Export.Encoder); err != nil {
+		return err
+	}
+
+	if err := validateCalibrationSettings(&settings.Calibration); err != nil {
+		return err
+	}
+
 	// Validate audio export settings
 	if settings.Export.Enabled {
 		// Validate capture length (10-60 seconds)
@@ -516,10 +2043,17 @@ func validateAudioSettings(settings *AudioSettings) error {
 			}
 		}
 
-		if settings.FfmpegPath == "" {
+		switch {
+		case settings.FfmpegPath == "" && settings.Export.Type == "flac" &&
+			(settings.Export.Encoder.FlacEncoder == "native" || !IsSoxExportType("flac", settings.SoxAudioTypes)):
+			log.Printf("FFmpeg not available, using the native encoder for FLAC audio export")
+		case settings.FfmpegPath == "" && IsSoxExportType(settings.Export.Type, settings.SoxAudioTypes):
+			log.Printf("FFmpeg not available, using SoX for %s audio export", settings.Export.Type)
+		case settings.FfmpegPath == "":
 			settings.Export.Type = "wav"
 			log.Printf("FFmpeg not available, using WAV format for audio export")
-		} else {
+		}
+		if settings.Export.Type != "wav" {
 			// Validate audio type and bitrate
 			switch settings.Export.Type {
 			case "aac", "opus", "mp3":
@@ -590,6 +2124,23 @@ func validateDashboardSettings(settings *Dashboard) error {
 		}
 	}
 
+	// Validate the image disk cache folder
+	if settings.Thumbnails.DiskCache.Enabled && strings.TrimSpace(settings.Thumbnails.DiskCache.Path) == "" {
+		return errors.New(fmt.Errorf("image disk cache path is required when the disk cache is enabled")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "thumbnails-disk-cache-path").
+			Build()
+	}
+
+	// Validate the image prefetch bandwidth limit
+	if settings.Thumbnails.Prefetch.BandwidthLimit < 0 {
+		return errors.New(fmt.Errorf("image prefetch bandwidth limit must not be negative, got %d", settings.Thumbnails.Prefetch.BandwidthLimit)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "thumbnails-prefetch-bandwidth").
+			Context("bandwidth_limit", settings.Thumbnails.Prefetch.BandwidthLimit).
+			Build()
+	}
+
 	return nil
 }
 
@@ -614,6 +2165,16 @@ func validateWeatherSettings(settings *WeatherSettings) error {
 		}
 	}
 
+	// A local sensor needs a broker and a topic to subscribe to
+	if settings.Provider == string(WeatherMQTT) {
+		if settings.MQTT.Broker == "" || settings.MQTT.Topic == "" {
+			return errors.New(fmt.Errorf("weather MQTT broker and topic are required when provider is mqtt")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "weather-mqtt-settings").
+				Build()
+		}
+	}
+
 	return nil
 }
 
@@ -770,6 +2331,56 @@ func validateSpeciesConfigSettings(settings *SpeciesSettings) error {
 				Context("threshold", config.Threshold).
 				Build()
 		}
+
+		for i := range config.Actions {
+			if err := validateActionCondition(&config.Actions[i].When); err != nil {
+				return errors.New(fmt.Errorf("species config for '%s': action %d: %w", speciesName, i+1, err)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "species-config-action-condition").
+					Context("species_name", speciesName).
+					Build()
+			}
+		}
+
+		for i := range config.Recipes {
+			if err := validateSpeciesRecipe(&config.Recipes[i]); err != nil {
+				return errors.New(fmt.Errorf("species config for '%s': %w", speciesName, err)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "species-config-recipe").
+					Context("species_name", speciesName).
+					Build()
+			}
+		}
+	}
+	return nil
+}
+
+// validateActionCondition validates the detection period and rarity of an action condition
+func validateActionCondition(condition *ActionCondition) error {
+	if condition.Period != "" && condition.Period != ActionPeriodDay && condition.Period != ActionPeriodNight {
+		return fmt.Errorf("condition period must be %q or %q, got %q", ActionPeriodDay, ActionPeriodNight, condition.Period)
+	}
+	if condition.MinRarity < 0 || condition.MinRarity > 1 {
+		return fmt.Errorf("condition minRarity must be between 0 and 1, got %g", condition.MinRarity)
+	}
+	return nil
+}
+
+// validateSpeciesRecipe validates the name and options of a selected action recipe
+func validateSpeciesRecipe(selected *SpeciesRecipe) error {
+	recipe, ok := LookupActionRecipe(selected.Name)
+	if !ok {
+		names := make([]string, 0, len(actionRecipes))
+		for i := range actionRecipes {
+			names = append(names, actionRecipes[i].Name)
+		}
+		return fmt.Errorf("unknown action recipe %q, must be one of %s", selected.Name, strings.Join(names, ", "))
+	}
+	if recipe.RequiresCommand && !filepath.IsAbs(selected.Command) {
+		return fmt.Errorf("action recipe %q requires the absolute path of a command, got %q", recipe.Name, selected.Command)
+	}
+	if selected.MinRarity < 0 || selected.MinRarity > 1 {
+		return fmt.Errorf("action recipe %q minRarity must be between 0 and 1, got %g", recipe.Name, selected.MinRarity)
 	}
 	return nil
 }
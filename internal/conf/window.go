@@ -0,0 +1,25 @@
+// conf/window.go analysis window and hop helpers
+package conf
+
+import "time"
+
+// AnalysisWindow is the length of audio data fed to BirdNET Analyzer at once
+const AnalysisWindow = CaptureLength * time.Second
+
+// AnalysisHop returns the time in seconds between the starts of consecutive analysis
+// windows that overlap by overlap seconds
+func AnalysisHop(overlap float64) float64 {
+	return CaptureLength - overlap
+}
+
+// AnalysisHopDuration returns the time between the starts of consecutive analysis windows
+// that overlap by overlap seconds
+func AnalysisHopDuration(overlap float64) time.Duration {
+	return time.Duration(AnalysisHop(overlap) * float64(time.Second))
+}
+
+// AnalysisHopSamples returns the number of samples per channel between the starts of
+// consecutive analysis windows that overlap by overlap seconds at the sample rate
+func AnalysisHopSamples(overlap float64, sampleRate int) int {
+	return int(AnalysisHop(overlap) * float64(sampleRate))
+}
@@ -0,0 +1,32 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalysisHop(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 3*time.Second, AnalysisWindow)
+	assert.Equal(t, 144000, AnalysisWindowSamples)
+
+	tests := []struct {
+		overlap      float64
+		wantHop      float64
+		wantDuration time.Duration
+		wantSamples  int
+	}{
+		{0, 3, 3 * time.Second, 144000},
+		{1.5, 1.5, 1500 * time.Millisecond, 72000},
+		{MaxAnalysisOverlap, 0.01, 10 * time.Millisecond, 480},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, tt.wantHop, AnalysisHop(tt.overlap), 1e-9)
+		assert.InDelta(t, tt.wantDuration, AnalysisHopDuration(tt.overlap), float64(time.Microsecond))
+		assert.InDelta(t, tt.wantSamples, AnalysisHopSamples(tt.overlap, SampleRate), 1)
+	}
+}
@@ -0,0 +1,78 @@
+// analysis_snapshots.go: model and analysis settings in effect for stored detections
+package datastore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AnalysisSnapshotStore reads the analysis snapshots of stored detections.
+type AnalysisSnapshotStore interface {
+	GetAnalysisSnapshot(id uint) (*AnalysisSnapshot, error)
+	GetAnalysisSnapshots() ([]AnalysisSnapshot, error)
+}
+
+// fingerprint returns the hash identifying snapshots with identical values
+func (s *AnalysisSnapshot) fingerprint() string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%g\x00%g\x00%g\x00%s",
+		s.ModelVersion, s.AppVersion, s.Overlap, s.Sensitivity, s.Threshold, s.SourceFormat))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveAnalysisSnapshot sets the snapshot ID of a note to the stored snapshot with the same
+// values, storing the snapshot first when it is new. It runs again on every save attempt, as
+// a snapshot stored by a rolled back attempt is gone.
+func resolveAnalysisSnapshot(tx *gorm.DB, note *Note) error {
+	if note.AnalysisSnapshot == nil {
+		return nil
+	}
+
+	snapshot := *note.AnalysisSnapshot
+	snapshot.ID = 0
+	snapshot.Fingerprint = snapshot.fingerprint()
+
+	// Concurrent saves may store the same snapshot, the unique fingerprint keeps one of them
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshot).Error; err != nil {
+		return dbError(err, "save_analysis_snapshot", errors.PriorityMedium,
+			"table", "analysis_snapshots")
+	}
+
+	var stored AnalysisSnapshot
+	if err := tx.Where("fingerprint = ?", snapshot.Fingerprint).First(&stored).Error; err != nil {
+		return dbError(err, "get_analysis_snapshot", errors.PriorityMedium,
+			"table", "analysis_snapshots")
+	}
+
+	note.AnalysisSnapshotID = &stored.ID
+	note.AnalysisSnapshot = &stored
+	return nil
+}
+
+// GetAnalysisSnapshot returns an analysis snapshot by ID
+func (ds *DataStore) GetAnalysisSnapshot(id uint) (*AnalysisSnapshot, error) {
+	var snapshot AnalysisSnapshot
+	if err := ds.DB.First(&snapshot, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("analysis_snapshot", fmt.Sprintf("%d", id))
+		}
+		return nil, dbError(err, "get_analysis_snapshot", errors.PriorityLow,
+			"snapshot_id", fmt.Sprintf("%d", id),
+			"table", "analysis_snapshots")
+	}
+	return &snapshot, nil
+}
+
+// GetAnalysisSnapshots returns all analysis snapshots, oldest first
+func (ds *DataStore) GetAnalysisSnapshots() ([]AnalysisSnapshot, error) {
+	var snapshots []AnalysisSnapshot
+	if err := ds.DB.Order("id ASC").Find(&snapshots).Error; err != nil {
+		return nil, dbError(err, "get_analysis_snapshots", errors.PriorityLow,
+			"table", "analysis_snapshots")
+	}
+	return snapshots, nil
+}
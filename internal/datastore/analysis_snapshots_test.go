@@ -0,0 +1,58 @@
+// analysis_snapshots_test.go: Tests for the analysis settings snapshots of detections
+package datastore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalysisSnapshots(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&Results{}, &NoteReview{}, &NoteLock{}, &NoteComment{}, &AnalysisSnapshot{}))
+
+	snapshot := AnalysisSnapshot{
+		ModelVersion: "BirdNET_GLOBAL_6K_V2.4",
+		AppVersion:   "1.2.0",
+		Overlap:      1.5,
+		Sensitivity:  1.0,
+		Threshold:    0.8,
+		SourceFormat: "rtsp 48000Hz 16bit 1ch",
+	}
+
+	save := func(s AnalysisSnapshot) *Note {
+		t.Helper()
+		note := &Note{Date: "2024-06-01", Time: "06:00:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", AnalysisSnapshot: &s}
+		require.NoError(t, ds.Save(note, nil))
+		require.NotNil(t, note.AnalysisSnapshotID)
+		return note
+	}
+
+	first := save(snapshot)
+	second := save(snapshot)
+	assert.Equal(t, *first.AnalysisSnapshotID, *second.AnalysisSnapshotID, "identical settings share a snapshot")
+
+	changed := snapshot
+	changed.Threshold = 0.7
+	third := save(changed)
+	assert.NotEqual(t, *first.AnalysisSnapshotID, *third.AnalysisSnapshotID)
+
+	snapshots, err := ds.GetAnalysisSnapshots()
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+
+	note, err := ds.Get(fmt.Sprintf("%d", third.ID))
+	require.NoError(t, err)
+	require.NotNil(t, note.AnalysisSnapshot)
+	assert.InDelta(t, 0.7, note.AnalysisSnapshot.Threshold, 1e-9)
+	assert.Equal(t, "rtsp 48000Hz 16bit 1ch", note.AnalysisSnapshot.SourceFormat)
+
+	// Notes saved without a snapshot keep working
+	legacy := &Note{Date: "2024-06-01", Time: "07:00:00", ScientificName: "Parus major"}
+	require.NoError(t, ds.Save(legacy, nil))
+	note, err = ds.Get(fmt.Sprintf("%d", legacy.ID))
+	require.NoError(t, err)
+	assert.Nil(t, note.AnalysisSnapshot)
+}
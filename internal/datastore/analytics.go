@@ -16,6 +16,14 @@ func isDebugLoggingEnabled() bool {
 	return settings != nil && settings.Debug && datastoreLogger != nil
 }
 
+// rejectedNotesFilter matches the notes no review rejected as a false positive or a
+// misidentification, so statistics only count detections of the reported species
+const rejectedNotesFilter = "id NOT IN (SELECT note_id FROM note_reviews WHERE verified IN ('false_positive', 'misidentified'))"
+
+// trashedNotesFilter matches the notes that are not in the trash, for raw queries that
+// GORM does not scope to notes that are not deleted
+const trashedNotesFilter = "deleted_at IS NULL"
+
 // SpeciesSummaryData contains overall statistics for a bird species
 type SpeciesSummaryData struct {
 	ScientificName string
@@ -102,19 +110,19 @@ func (ds *DataStore) GetSpeciesSummaryData(startDate, endDate string) ([]Species
 		FROM notes
 	`, dateTimeFormat, dateTimeFormat)
 
-	// Add WHERE clause if date filters are provided
-	var whereClause string
+	// Add WHERE clause, with date filters if provided
+	whereClause := "WHERE " + trashedNotesFilter + " AND " + rejectedNotesFilter
 	var args []interface{}
 
 	switch {
 	case startDate != "" && endDate != "":
-		whereClause = "WHERE date >= ? AND date <= ?"
+		whereClause += " AND date >= ? AND date <= ?"
 		args = append(args, startDate, endDate)
 	case startDate != "":
-		whereClause = "WHERE date >= ?"
+		whereClause += " AND date >= ?"
 		args = append(args, startDate)
 	case endDate != "":
-		whereClause = "WHERE date <= ?"
+		whereClause += " AND date <= ?"
 		args = append(args, endDate)
 	}
 
@@ -216,8 +224,9 @@ func (ds *DataStore) GetHourlyAnalyticsData(date, species string) ([]HourlyAnaly
 	hourFormat := ds.GetHourFormat()
 
 	// Base query
-	query := ds.DB.Table("notes").
+	query := ds.DB.Model(&Note{}).
 		Select(fmt.Sprintf("%s as hour, COUNT(*) as count", hourFormat)).
+		Where(rejectedNotesFilter).
 		Group(hourFormat).
 		Order("hour")
 
@@ -249,8 +258,9 @@ func (ds *DataStore) GetDailyAnalyticsData(startDate, endDate, species string) (
 	var analytics []DailyAnalyticsData
 
 	// Base query
-	query := ds.DB.Table("notes").
+	query := ds.DB.Model(&Note{}).
 		Select("date, COUNT(*) as count").
+		Where(rejectedNotesFilter).
 		Group("date").
 		Order("date")
 
@@ -308,11 +318,11 @@ func (ds *DataStore) GetDetectionTrends(period string, limit int) ([]DailyAnalyt
 		query := fmt.Sprintf(`
 			SELECT date, COUNT(*) as count
 			FROM notes
-			WHERE date >= %s
+			WHERE date >= %s AND %s AND %s
 			GROUP BY date
 			ORDER BY date DESC
 			LIMIT ?
-		`, startDate)
+		`, startDate, trashedNotesFilter, rejectedNotesFilter)
 
 		if err := ds.DB.Raw(query, limit).Scan(&trends).Error; err != nil {
 			return nil, errors.New(err).
@@ -328,11 +338,11 @@ func (ds *DataStore) GetDetectionTrends(period string, limit int) ([]DailyAnalyt
 		query := fmt.Sprintf(`
 			SELECT date, COUNT(*) as count
 			FROM notes
-			WHERE date >= %s
+			WHERE date >= %s AND %s AND %s
 			GROUP BY date
 			ORDER BY date DESC
 			LIMIT ?
-		`, startDate)
+		`, startDate, trashedNotesFilter, rejectedNotesFilter)
 
 		if err := ds.DB.Raw(query, limit).Scan(&trends).Error; err != nil {
 			return nil, errors.New(err).
@@ -406,11 +416,12 @@ func (ds *DataStore) GetHourlyDistribution(startDate, endDate, species string) (
 	}
 
 	// Prepare the SQL query
-	query := ds.DB.Table("notes")
+	query := ds.DB.Model(&Note{})
 
 	// Extract hour from the time field using database-specific hour format
 	hourExpr := ds.GetHourFormat()
-	query = query.Select(fmt.Sprintf("%s AS hour, COUNT(*) AS count", hourExpr))
+	query = query.Select(fmt.Sprintf("%s AS hour, COUNT(*) AS count", hourExpr)).
+		Where(rejectedNotesFilter)
 
 	// Apply date range filter conditionally
 	switch {
@@ -492,6 +503,7 @@ func (ds *DataStore) GetSpeciesFirstDetectionInPeriod(startDate, endDate string,
 	WHERE date BETWEEN ? AND ?
 		AND date != ''
 		AND date IS NOT NULL
+		AND deleted_at IS NULL
 	GROUP BY scientific_name
 	ORDER BY first_detection_date ASC
 	LIMIT ? OFFSET ?
@@ -561,6 +573,7 @@ func (ds *DataStore) GetNewSpeciesDetections(startDate, endDate string, limit, o
 	        scientific_name, 
 	        MIN(CASE WHEN date != '' AND date IS NOT NULL THEN date ELSE NULL END) as first_detection_date
 	    FROM notes
+	    WHERE deleted_at IS NULL
 	    GROUP BY scientific_name
 	    HAVING first_detection_date IS NOT NULL AND first_detection_date != '' 
 	), 
@@ -570,7 +583,7 @@ func (ds *DataStore) GetNewSpeciesDetections(startDate, endDate string, limit, o
 	        COUNT(*) as count_in_period,
 			MAX(common_name) as common_name -- Reverted from ANY_VALUE for testing
 	    FROM notes
-	    WHERE date BETWEEN ? AND ?
+	    WHERE date BETWEEN ? AND ? AND deleted_at IS NULL
 	    GROUP BY scientific_name
 	)
 	SELECT 
@@ -20,8 +20,8 @@ func setupTestDB(t *testing.T) *DataStore {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	// Create the notes table schema
-	err = db.AutoMigrate(&Note{})
+	// Create the notes and reviews table schema
+	err = db.AutoMigrate(&Note{}, &NoteReview{})
 	require.NoError(t, err)
 
 	return &DataStore{DB: db}
@@ -265,6 +265,61 @@ func TestGetSpeciesSummaryDataTimeFormat(t *testing.T) {
 	assert.Equal(t, expectedTime, summary.LastSeen)
 }
 
+// TestStatisticsExcludeRejectedDetections tests that detections reviewed as false positives
+// or misidentifications are left out of statistics
+func TestStatisticsExcludeRejectedDetections(t *testing.T) {
+	t.Parallel()
+	ds := setupTestDB(t)
+	seedTestData(t, ds)
+
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 1, Verified: ReviewFalsePositive}))
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 3, Verified: ReviewMisidentified, CorrectedScientificName: "Corvus brachyrhynchos"}))
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 5, Verified: ReviewCorrect}))
+
+	summaries, err := ds.GetSpeciesSummaryData("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, findSpeciesByScientificName(summaries, "Turdus migratorius").Count)
+	assert.Equal(t, 1, findSpeciesByScientificName(summaries, "Cyanocitta cristata").Count)
+	assert.Equal(t, 1, findSpeciesByScientificName(summaries, "Cardinalis cardinalis").Count) //nolint:misspell // This is the correct scientific name
+
+	daily, err := ds.GetDailyAnalyticsData("2024-01-15", "2024-01-17", "")
+	require.NoError(t, err)
+	assert.Equal(t, []DailyAnalyticsData{
+		{Date: "2024-01-15", Count: 1},
+		{Date: "2024-01-16", Count: 1},
+		{Date: "2024-01-17", Count: 1},
+	}, daily)
+
+	hourly, err := ds.GetHourlyDistribution("2024-01-15", "2024-01-15", "")
+	require.NoError(t, err)
+	require.Len(t, hourly, 1)
+	assert.Equal(t, 9, hourly[0].Hour)
+}
+
+// TestSaveNoteReviewClearsCorrection tests that a new review replaces the reviewer and
+// clears the species correction of a previous misidentified review
+func TestSaveNoteReviewClearsCorrection(t *testing.T) {
+	t.Parallel()
+	ds := setupTestDB(t)
+	seedTestData(t, ds)
+
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{
+		NoteID:                  2,
+		Verified:                ReviewMisidentified,
+		CorrectedScientificName: "Turdus merula",
+		CorrectedCommonName:     "Eurasian Blackbird",
+		Reviewer:                "alice",
+	}))
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 2, Verified: ReviewCorrect, Reviewer: "bob"}))
+
+	review, err := ds.GetNoteReview("2")
+	require.NoError(t, err)
+	assert.Equal(t, ReviewCorrect, review.Verified)
+	assert.Equal(t, "bob", review.Reviewer)
+	assert.Empty(t, review.CorrectedScientificName)
+	assert.Empty(t, review.CorrectedCommonName)
+}
+
 // Helper function to find species by scientific name
 func findSpeciesByScientificName(summaries []SpeciesSummaryData, scientificName string) *SpeciesSummaryData {
 	for i := range summaries {
@@ -0,0 +1,82 @@
+// attribution.go: species image attribution ledger
+package datastore
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttributionLedger records license and author information for species images
+// that have been used in notifications, integrations or the web interface.
+type AttributionLedger interface {
+	RecordImageAttribution(entry *ImageAttribution) error
+	GetImageAttributions(scientificName string) ([]ImageAttribution, error)
+}
+
+// RecordImageAttribution inserts or updates the ledger entry for a species image.
+// On repeated use the attribution details are refreshed and the usage count incremented.
+func (ds *DataStore) RecordImageAttribution(entry *ImageAttribution) error {
+	if entry == nil {
+		return validationError("attribution entry cannot be nil", "entry", nil)
+	}
+	if entry.ScientificName == "" {
+		return validationError("scientific name cannot be empty", "scientific_name", "")
+	}
+	if entry.SourceProvider == "" {
+		return validationError("source provider cannot be empty", "source_provider", "")
+	}
+
+	now := time.Now()
+	if entry.FirstUsedAt.IsZero() {
+		entry.FirstUsedAt = now
+	}
+	if entry.LastUsedAt.IsZero() {
+		entry.LastUsedAt = now
+	}
+	if entry.UsageCount < 1 {
+		entry.UsageCount = 1
+	}
+
+	if err := ds.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scientific_name"}, {Name: "source_provider"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"url":          entry.URL,
+			"license_name": entry.LicenseName,
+			"license_url":  entry.LicenseURL,
+			"author_name":  entry.AuthorName,
+			"author_url":   entry.AuthorURL,
+			"last_usage":   entry.LastUsage,
+			"last_used_at": entry.LastUsedAt,
+			"usage_count":  gorm.Expr("usage_count + ?", 1),
+		}),
+	}).Create(entry).Error; err != nil {
+		return dbError(err, "record_image_attribution", errors.PriorityLow,
+			"table", "image_attributions",
+			"scientific_name", entry.ScientificName,
+			"provider", entry.SourceProvider)
+	}
+
+	return nil
+}
+
+// GetImageAttributions returns ledger entries ordered by species name.
+// When scientificName is empty all entries are returned.
+func (ds *DataStore) GetImageAttributions(scientificName string) ([]ImageAttribution, error) {
+	var attributions []ImageAttribution
+
+	query := ds.DB.Model(&ImageAttribution{})
+	if scientificName != "" {
+		query = query.Where("scientific_name = ?", scientificName)
+	}
+
+	if err := query.Order("scientific_name ASC, source_provider ASC").Find(&attributions).Error; err != nil {
+		return nil, dbError(err, "get_image_attributions", errors.PriorityLow,
+			"table", "image_attributions",
+			"scientific_name", scientificName)
+	}
+
+	return attributions, nil
+}
@@ -0,0 +1,63 @@
+// attribution_test.go: Tests for the species image attribution ledger
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordImageAttribution(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&ImageAttribution{}))
+
+	var ledger AttributionLedger = ds
+
+	require.NoError(t, ledger.RecordImageAttribution(&ImageAttribution{
+		ScientificName: "Turdus merula",
+		SourceProvider: "wikimedia",
+		URL:            "https://example.com/old.jpg",
+		LicenseName:    "CC BY-SA 4.0",
+		AuthorName:     "Alice",
+		LastUsage:      "web",
+	}))
+	require.NoError(t, ledger.RecordImageAttribution(&ImageAttribution{
+		ScientificName: "Turdus merula",
+		SourceProvider: "wikimedia",
+		URL:            "https://example.com/new.jpg",
+		LicenseName:    "CC BY 2.0",
+		AuthorName:     "Bob",
+		LastUsage:      "mqtt",
+	}))
+	require.NoError(t, ledger.RecordImageAttribution(&ImageAttribution{
+		ScientificName: "Parus major",
+		SourceProvider: "flickr",
+		URL:            "https://example.com/tit.jpg",
+		LicenseName:    "CC BY 2.0",
+		LastUsage:      "sse",
+	}))
+
+	all, err := ledger.GetImageAttributions("")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Parus major", all[0].ScientificName, "entries should be ordered by species")
+
+	blackbird, err := ledger.GetImageAttributions("Turdus merula")
+	require.NoError(t, err)
+	require.Len(t, blackbird, 1)
+	assert.Equal(t, 2, blackbird[0].UsageCount)
+	assert.Equal(t, "https://example.com/new.jpg", blackbird[0].URL)
+	assert.Equal(t, "Bob", blackbird[0].AuthorName)
+	assert.Equal(t, "mqtt", blackbird[0].LastUsage)
+	assert.False(t, blackbird[0].FirstUsedAt.IsZero())
+}
+
+func TestRecordImageAttributionValidation(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&ImageAttribution{}))
+
+	assert.Error(t, ds.RecordImageAttribution(nil))
+	assert.Error(t, ds.RecordImageAttribution(&ImageAttribution{SourceProvider: "wikimedia"}))
+	assert.Error(t, ds.RecordImageAttribution(&ImageAttribution{ScientificName: "Turdus merula"}))
+}
@@ -0,0 +1,133 @@
+// deployments.go: station deployments referenced by detections
+package datastore
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// DeploymentStore records the deployments of the station.
+type DeploymentStore interface {
+	StartDeployment(previous, next *Deployment) error
+	EndDeployment(endedAt time.Time) (*Deployment, error)
+	GetCurrentDeployment() (*Deployment, error)
+	GetDeployments() ([]Deployment, error)
+}
+
+// StartDeployment ends the current deployment at next.StartedAt and records next as the
+// current one. When no deployment was recorded yet, previous describes the station before
+// the change and is recorded as a deployment starting at the first detection, so earlier
+// detections stay attributable. previous may be nil.
+func (ds *DataStore) StartDeployment(previous, next *Deployment) error {
+	err := ds.DB.Transaction(func(tx *gorm.DB) error {
+		var recorded int64
+		if err := tx.Model(&Deployment{}).Count(&recorded).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case recorded > 0:
+			if err := tx.Model(&Deployment{}).Where("ended_at IS NULL").Update("ended_at", next.StartedAt).Error; err != nil {
+				return err
+			}
+		case previous != nil:
+			var firstDate sql.NullString
+			if err := tx.Model(&Note{}).Select("MIN(date)").Scan(&firstDate).Error; err != nil {
+				return err
+			}
+			previous.StartedAt = next.StartedAt
+			if firstDate.Valid {
+				if started, err := time.ParseInLocation(time.DateOnly, firstDate.String, time.Local); err == nil && started.Before(next.StartedAt) {
+					previous.StartedAt = started
+				}
+			}
+			endedAt := next.StartedAt
+			previous.EndedAt = &endedAt
+			if err := tx.Create(previous).Error; err != nil {
+				return err
+			}
+			// Detections made before the first recorded deployment belong to it
+			if err := tx.Model(&Note{}).Where("deployment_id IS NULL").Update("deployment_id", previous.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		next.EndedAt = nil
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return dbError(err, "start_deployment", errors.PriorityMedium,
+			"table", "deployments",
+			"deployment", next.Name)
+	}
+	return nil
+}
+
+// EndDeployment ends the current deployment and returns it. Detections saved afterwards
+// reference no deployment until a new one is started.
+func (ds *DataStore) EndDeployment(endedAt time.Time) (*Deployment, error) {
+	current, err := ds.GetCurrentDeployment()
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, notFoundError("deployment", "current")
+	}
+
+	if err := ds.DB.Model(current).Update("ended_at", endedAt).Error; err != nil {
+		return nil, dbError(err, "end_deployment", errors.PriorityMedium,
+			"table", "deployments",
+			"deployment_id", current.ID)
+	}
+	current.EndedAt = &endedAt
+	return current, nil
+}
+
+// GetCurrentDeployment returns the deployment that has not ended, or nil when there is none
+func (ds *DataStore) GetCurrentDeployment() (*Deployment, error) {
+	var deployments []Deployment
+	if err := ds.DB.Where("ended_at IS NULL").Order("started_at DESC, id DESC").Limit(1).Find(&deployments).Error; err != nil {
+		return nil, dbError(err, "get_current_deployment", errors.PriorityLow,
+			"table", "deployments")
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	return &deployments[0], nil
+}
+
+// GetDeployments returns the recorded deployments, the current one first
+func (ds *DataStore) GetDeployments() ([]Deployment, error) {
+	var deployments []Deployment
+	if err := ds.DB.Order("started_at DESC, id DESC").Find(&deployments).Error; err != nil {
+		return nil, dbError(err, "get_deployments", errors.PriorityLow,
+			"table", "deployments")
+	}
+	return deployments, nil
+}
+
+// resolveDeployment links a note to the deployment that was current when the detection was
+// made, unless the note already references one
+func resolveDeployment(tx *gorm.DB, note *Note) error {
+	if note.DeploymentID != nil || !tx.Migrator().HasTable(&Deployment{}) {
+		return nil
+	}
+
+	query := tx.Model(&Deployment{}).Where("ended_at IS NULL")
+	if !note.BeginTime.IsZero() {
+		query = query.Where("started_at <= ?", note.BeginTime)
+	}
+	var ids []uint
+	if err := query.Order("started_at DESC, id DESC").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return dbError(err, "resolve_deployment", errors.PriorityMedium,
+			"table", "deployments")
+	}
+	if len(ids) > 0 {
+		note.DeploymentID = &ids[0]
+	}
+	return nil
+}
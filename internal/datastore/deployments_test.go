@@ -0,0 +1,70 @@
+// deployments_test.go: Tests for station deployments
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentStore(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&Note{}, &Results{}, &Deployment{}))
+	early := &Note{Date: "2024-03-01", Time: "06:00:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"}
+	require.NoError(t, ds.DB.Create(early).Error)
+
+	var store DeploymentStore = ds
+	moved := time.Date(2024, 6, 1, 8, 0, 0, 0, time.Local)
+
+	current, err := store.GetCurrentDeployment()
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	// The station before the first recorded deployment starts at the first detection
+	previous := &Deployment{Latitude: 60.1, Longitude: 24.9}
+	require.NoError(t, store.StartDeployment(previous, &Deployment{Name: "garden", Latitude: 61.5, Longitude: 23.8, Microphone: "Clippy EM272", StartedAt: moved}))
+
+	deployments, err := store.GetDeployments()
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	assert.Equal(t, "garden", deployments[0].Name, "current deployment first")
+	assert.Nil(t, deployments[0].EndedAt)
+	assert.InDelta(t, 60.1, deployments[1].Latitude, 1e-9)
+	assert.True(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local).Equal(deployments[1].StartedAt))
+	require.NotNil(t, deployments[1].EndedAt)
+	assert.True(t, moved.Equal(*deployments[1].EndedAt))
+
+	// Earlier detections reference the previous deployment
+	require.NoError(t, ds.DB.First(early, early.ID).Error)
+	require.NotNil(t, early.DeploymentID)
+	assert.Equal(t, deployments[1].ID, *early.DeploymentID)
+
+	// Saved detections reference the current deployment
+	note := &Note{Date: "2024-06-02", Time: "06:00:00", BeginTime: moved.AddDate(0, 0, 1), ScientificName: "Parus major", CommonName: "Great Tit"}
+	require.NoError(t, ds.Save(note, nil))
+	require.NotNil(t, note.DeploymentID)
+	assert.Equal(t, deployments[0].ID, *note.DeploymentID)
+
+	// Later deployments end the current one, previous is only used for the first one
+	changed := moved.AddDate(0, 2, 0)
+	require.NoError(t, store.StartDeployment(&Deployment{Latitude: 1, Longitude: 1}, &Deployment{Name: "garden", Latitude: 61.5, Longitude: 23.8, Microphone: "AudioMoth", StartedAt: changed}))
+	deployments, err = store.GetDeployments()
+	require.NoError(t, err)
+	require.Len(t, deployments, 3)
+	assert.Equal(t, "AudioMoth", deployments[0].Microphone)
+	require.NotNil(t, deployments[1].EndedAt)
+	assert.True(t, changed.Equal(*deployments[1].EndedAt))
+
+	// Ending the deployment leaves later detections without one
+	ended, err := store.EndDeployment(changed.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, deployments[0].ID, ended.ID)
+	_, err = store.EndDeployment(time.Now())
+	require.Error(t, err, "no current deployment")
+
+	note = &Note{Date: "2024-09-02", Time: "06:00:00", BeginTime: changed.AddDate(0, 1, 1), ScientificName: "Parus major", CommonName: "Great Tit"}
+	require.NoError(t, ds.Save(note, nil))
+	assert.Nil(t, note.DeploymentID)
+}
@@ -0,0 +1,49 @@
+// dynamic_thresholds.go: persistence of per-species dynamic threshold state
+package datastore
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// DynamicThresholdStore persists dynamic threshold state across restarts.
+type DynamicThresholdStore interface {
+	SaveDynamicThresholds(profile string, states []DynamicThresholdState) error
+	GetDynamicThresholds(profile string) ([]DynamicThresholdState, error)
+}
+
+// SaveDynamicThresholds replaces the stored thresholds of a processing profile with states
+func (ds *DataStore) SaveDynamicThresholds(profile string, states []DynamicThresholdState) error {
+	err := ds.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("profile = ?", profile).Delete(&DynamicThresholdState{}).Error; err != nil {
+			return err
+		}
+		if len(states) == 0 {
+			return nil
+		}
+		for i := range states {
+			states[i].ID = 0
+			states[i].Profile = profile
+		}
+		return tx.Create(&states).Error
+	})
+	if err != nil {
+		return dbError(err, "save_dynamic_thresholds", errors.PriorityLow,
+			"table", "dynamic_threshold_states",
+			"profile", profile,
+			"count", len(states))
+	}
+	return nil
+}
+
+// GetDynamicThresholds returns the stored thresholds of a processing profile
+func (ds *DataStore) GetDynamicThresholds(profile string) ([]DynamicThresholdState, error) {
+	var states []DynamicThresholdState
+	if err := ds.DB.Where("profile = ?", profile).Order("species").Find(&states).Error; err != nil {
+		return nil, dbError(err, "get_dynamic_thresholds", errors.PriorityLow,
+			"table", "dynamic_threshold_states",
+			"profile", profile)
+	}
+	return states, nil
+}
@@ -0,0 +1,49 @@
+// dynamic_thresholds_test.go: Tests for dynamic threshold persistence
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicThresholdStore(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&DynamicThresholdState{}))
+
+	var store DynamicThresholdStore = ds
+	timer := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.SaveDynamicThresholds("", []DynamicThresholdState{
+		{Species: "eurasian blackbird", Level: 1, CurrentValue: 0.6, Timer: timer, HighConfCount: 1, ValidHours: 24},
+		{Species: "great tit", Level: 2, CurrentValue: 0.4, Timer: timer, HighConfCount: 2, ValidHours: 24},
+	}))
+	require.NoError(t, store.SaveDynamicThresholds("night", []DynamicThresholdState{
+		{Species: "tawny owl", Level: 3, CurrentValue: 0.2, Timer: timer, HighConfCount: 3, ValidHours: 24},
+	}))
+
+	// Saving replaces the thresholds of the profile only
+	require.NoError(t, store.SaveDynamicThresholds("", []DynamicThresholdState{
+		{Species: "great tit", Level: 3, CurrentValue: 0.2, Timer: timer, HighConfCount: 3, ValidHours: 24},
+	}))
+
+	states, err := store.GetDynamicThresholds("")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "great tit", states[0].Species)
+	assert.Equal(t, 3, states[0].Level)
+	assert.InDelta(t, 0.2, states[0].CurrentValue, 1e-9)
+	assert.True(t, timer.Equal(states[0].Timer))
+
+	states, err = store.GetDynamicThresholds("night")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "tawny owl", states[0].Species)
+
+	require.NoError(t, store.SaveDynamicThresholds("night", nil))
+	states, err = store.GetDynamicThresholds("night")
+	require.NoError(t, err)
+	assert.Empty(t, states)
+}
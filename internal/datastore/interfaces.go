@@ -36,6 +36,12 @@ var (
 // Optional methods:
 //   - CheckpointWAL() error - Implemented by stores that support Write-Ahead Logging (e.g., SQLite)
 //     Call via type assertion: if sqliteStore, ok := store.(*SQLiteStore); ok { sqliteStore.CheckpointWAL() }
+//
+// Features added after Interface, such as the trash, the review queue or the settings audit
+// log, are optional capabilities: smaller interfaces implemented by *DataStore, which callers
+// reach through a type assertion so stores and mocks without the feature keep working.
+//
+//	if trashStore, ok := store.(datastore.TrashStore); ok { trashStore.RestoreNote(id) }
 type Interface interface {
 	Open() error
 	Save(note *Note, results []Results) error
@@ -231,6 +237,15 @@ func (ds *DataStore) Get(id string) (Note, error) {
 			"action", "retrieve_detection_record")
 	}
 
+	// Load the analysis snapshot, detections saved before snapshots were recorded have none
+	if note.AnalysisSnapshotID != nil {
+		snapshot, err := ds.GetAnalysisSnapshot(*note.AnalysisSnapshotID)
+		if err != nil {
+			return Note{}, err
+		}
+		note.AnalysisSnapshot = snapshot
+	}
+
 	// Populate virtual Verified field
 	if note.Review != nil {
 		note.Verified = note.Review.Verified
@@ -242,7 +257,8 @@ func (ds *DataStore) Get(id string) (Note, error) {
 	return note, nil
 }
 
-// Delete removes a note and its associated results from the database.
+// Delete moves a note to the trash, hiding it from queries until it is restored or purged
+// after the trash retention window, see TrashStore.
 func (ds *DataStore) Delete(id string) error {
 	// Convert the id from string to unsigned integer
 	noteID, err := strconv.ParseUint(id, 10, 32)
@@ -264,24 +280,15 @@ func (ds *DataStore) Delete(id string) error {
 			"action", "delete_detection_record")
 	}
 
-	// Perform the deletion within a transaction
-	return ds.DB.Transaction(func(tx *gorm.DB) error {
-		// Delete the full results entry associated with the note
-		if err := tx.Where("note_id = ?", noteID).Delete(&Results{}).Error; err != nil {
-			return dbError(err, "delete_results", errors.PriorityMedium,
-				"note_id", fmt.Sprintf("%d", noteID),
-				"table", "results",
-				"action", "delete_detection_results")
-		}
-		// Delete the note itself
-		if err := tx.Delete(&Note{}, noteID).Error; err != nil {
-			return dbError(err, "delete_note", errors.PriorityMedium,
-				"note_id", fmt.Sprintf("%d", noteID),
-				"table", "notes",
-				"action", "delete_detection_record")
-		}
-		return nil
-	})
+	// Move the note to the trash, its results, review, comments and clip are kept until
+	// the note is purged
+	if err := ds.DB.Delete(&Note{}, noteID).Error; err != nil {
+		return dbError(err, "delete_note", errors.PriorityMedium,
+			"note_id", fmt.Sprintf("%d", noteID),
+			"table", "notes",
+			"action", "delete_detection_record")
+	}
+	return nil
 }
 
 // GetNoteClipPath retrieves the path to the audio clip associated with a note.
@@ -347,6 +354,27 @@ func (ds *DataStore) GetAllNotes() ([]Note, error) {
 	return notes, nil
 }
 
+// GetNotesByDate retrieves all notes for a date ordered by time, optionally with their
+// secondary results preloaded. It is not part of Interface and is used for bulk exports.
+func (ds *DataStore) GetNotesByDate(date string, includeResults bool) ([]Note, error) {
+	var notes []Note
+	query := ds.DB.Where("date = ?", date).Order("time ASC, id ASC")
+	if includeResults {
+		query = query.Preload("Results", func(db *gorm.DB) *gorm.DB {
+			return db.Order("confidence DESC")
+		})
+	}
+	if err := query.Find(&notes).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_notes_by_date").
+			Context("date", date).
+			Build()
+	}
+	return notes, nil
+}
+
 // GetTopBirdsData retrieves the top bird sightings based on a selected date and minimum confidence threshold.
 func (ds *DataStore) GetTopBirdsData(selectedDate string, minConfidenceNormalized float64) ([]Note, error) {
 	// Define a temporary struct to hold the query results including the count
@@ -366,7 +394,7 @@ func (ds *DataStore) GetTopBirdsData(selectedDate string, minConfidenceNormalize
 	reportCount := conf.Setting().Realtime.Dashboard.SummaryLimit
 
 	// First, get the count and common names
-	query := ds.DB.Table("notes").
+	query := ds.DB.Model(&Note{}).
 		Select("common_name, scientific_name, species_code, COUNT(*) as count, MAX(confidence) as confidence, date, MAX(time) as time").
 		Where("date = ? AND confidence >= ?", selectedDate, minConfidenceNormalized).
 		Group("common_name, scientific_name, species_code, date").
@@ -611,7 +639,7 @@ func (ds *DataStore) GetLastDetections(numDetections int) ([]Note, error) {
 func (ds *DataStore) GetAllDetectedSpecies() ([]Note, error) {
 	var results []Note
 
-	err := ds.DB.Table("notes").
+	err := ds.DB.Model(&Note{}).
 		Select("scientific_name").
 		Group("scientific_name").
 		Scan(&results).Error
@@ -944,9 +972,16 @@ func (ds *DataStore) GetNoteReview(noteID string) (*NoteReview, error) {
 
 // SaveNoteReview saves or updates a note review
 func (ds *DataStore) SaveNoteReview(review *NoteReview) error {
-	// Use upsert operation to either create or update the review
+	// Use upsert operation to either create or update the review. Fields are assigned as a
+	// map so empty correction fields clear the correction of a previous review.
 	result := ds.DB.Where("note_id = ?", review.NoteID).
-		Assign(*review).
+		Assign(map[string]any{
+			"verified":                  review.Verified,
+			"corrected_scientific_name": review.CorrectedScientificName,
+			"corrected_common_name":     review.CorrectedCommonName,
+			"reviewer":                  review.Reviewer,
+			"updated_at":                review.UpdatedAt,
+		}).
 		FirstOrCreate(review)
 
 	if result.Error != nil {
@@ -1403,6 +1438,23 @@ func (ds *DataStore) GetAllImageCaches(providerName string) ([]ImageCache, error
 	return caches, nil
 }
 
+// ImageCacheRemover deletes cached species images.
+type ImageCacheRemover interface {
+	DeleteImageCache(providerName, scientificName string) error
+}
+
+// DeleteImageCache deletes the image cache entry of a species for a provider
+func (ds *DataStore) DeleteImageCache(providerName, scientificName string) error {
+	if err := ds.DB.Where("provider_name = ? AND scientific_name = ?", providerName, scientificName).
+		Delete(&ImageCache{}).Error; err != nil {
+		return dbError(err, "delete_image_cache", errors.PriorityLow,
+			"table", "image_caches",
+			"scientific_name", scientificName,
+			"provider", providerName)
+	}
+	return nil
+}
+
 // GetImageCacheBatch retrieves multiple image cache entries for a provider in a single query
 func (ds *DataStore) GetImageCacheBatch(providerName string, scientificNames []string) (map[string]*ImageCache, error) {
 	if providerName == "" {
@@ -1593,7 +1645,7 @@ func applyCommonFilters(query *gorm.DB, filters *SearchFilters, ds *DataStore) *
 		query = query.Where("note_reviews.verified = ?", "correct")
 	} else if filters.UnverifiedOnly {
 		// Handle NULL case explicitly for unverified
-		query = query.Where("(note_reviews.verified IS NULL OR (note_reviews.verified != ? AND note_reviews.verified != ?))", "correct", "false_positive")
+		query = query.Where("(note_reviews.verified IS NULL OR note_reviews.verified NOT IN (?, ?, ?))", ReviewCorrect, ReviewFalsePositive, ReviewMisidentified)
 	}
 
 	if filters.LockedOnly {
@@ -1821,11 +1873,11 @@ func (ds *DataStore) SearchDetections(filters *SearchFilters) ([]DetectionRecord
 	}
 
 	// Build the query with GORM query builder
-	query := ds.DB.Table("notes")
+	query := ds.DB.Model(&Note{})
 
 	// Select necessary fields, including potentially null fields from joins
 	query = query.Select("notes.id, notes.date, notes.time, notes.scientific_name, notes.common_name, notes.confidence, " +
-		"notes.latitude, notes.longitude, notes.clip_name, notes.source_node, " +
+		"notes.latitude, notes.longitude, notes.clip_name, notes.source_node, notes.rarity_score, " +
 		"note_reviews.verified AS review_verified, " + // Select review status
 		"note_locks.id IS NOT NULL AS is_locked") // Select lock status as boolean
 
@@ -1838,7 +1890,7 @@ func (ds *DataStore) SearchDetections(filters *SearchFilters) ([]DetectionRecord
 
 	// --- Count Query ---
 	// Create a separate query for counting to avoid issues with GROUP BY if added later
-	countQuery := ds.DB.Table("notes").
+	countQuery := ds.DB.Model(&Note{}).
 		Joins("LEFT JOIN note_reviews ON notes.id = note_reviews.note_id").
 		Joins("LEFT JOIN note_locks ON notes.id = note_locks.note_id")
 
@@ -1865,6 +1917,9 @@ func (ds *DataStore) SearchDetections(filters *SearchFilters) ([]DetectionRecord
 		query = query.Order("notes.common_name ASC")
 	case "confidence_desc":
 		query = query.Order("notes.confidence DESC")
+	case "rarity_desc":
+		// Unscored detections last, portable across SQLite and MySQL
+		query = query.Order("notes.rarity_score IS NULL, notes.rarity_score DESC, notes.date DESC, notes.time DESC")
 	default:
 		query = query.Order("notes.date DESC, notes.time DESC") // Default sort by date, newest first
 	}
@@ -1886,6 +1941,7 @@ func (ds *DataStore) SearchDetections(filters *SearchFilters) ([]DetectionRecord
 		Longitude      float64
 		ClipName       string
 		SourceNode     string
+		RarityScore    *float64
 		ReviewVerified *string // Use pointer to handle NULL for review status
 		IsLocked       bool    // Boolean result from IS NOT NULL
 	}
@@ -1978,6 +2034,7 @@ func (ds *DataStore) SearchDetections(filters *SearchFilters) ([]DetectionRecord
 			Device:         scanned.SourceNode,
 			Source:         "", // Source field was runtime-only, not stored in database
 			TimeOfDay:      timeOfDay, // Include calculated time of day
+			RarityScore:    scanned.RarityScore,
 		}
 
 		results = append(results, record)
@@ -2173,6 +2230,24 @@ func (ds *DataStore) executeTransaction(tx *gorm.DB, note *Note, results []Resul
 		}
 	}()
 
+	// Link the analysis snapshot of the note
+	if err := resolveAnalysisSnapshot(tx, note); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Link the current deployment of the station
+	if err := resolveDeployment(tx, note); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Store detections of renamed species under the current name
+	if err := resolveSpeciesAlias(tx, note); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Save the note
 	if err := ds.saveNoteInTransaction(tx, note, txID, attempt, txLogger); err != nil {
 		tx.Rollback()
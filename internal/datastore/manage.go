@@ -461,6 +461,15 @@ func migrateTables(db *gorm.DB, dbType string, lgr *slog.Logger) (int, error) {
 		{&HourlyWeather{}, "hourly_weather"},
 		{&NoteLock{}, "note_locks"},
 		{&ImageCache{}, "image_caches"},
+		{&ImageAttribution{}, "image_attributions"},
+		{&NoteSource{}, "note_sources"},
+		{&Run{}, "runs"},
+		{&SettingsChange{}, "settings_changes"},
+		{&AnalysisSnapshot{}, "analysis_snapshots"},
+		{&DynamicThresholdState{}, "dynamic_threshold_states"},
+		{&Deployment{}, "deployments"},
+		{&SpeciesAlias{}, "species_aliases"},
+		{&WebPushSubscription{}, "web_push_subscriptions"},
 	}
 	
 	lgr.Info("Starting table migrations",
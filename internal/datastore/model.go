@@ -1,7 +1,11 @@
 // model.go this code defines the data model for the application
 package datastore
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // AudioSource represents a structured audio source with ID, safe string, and display name
 // This allows safe separation of concerns: ID for buffer operations, SafeString for logging, DisplayName for UI
@@ -38,6 +42,46 @@ type Note struct {
 	Comments       []NoteComment `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"` // One-to-many relationship with cascade delete
 	Lock           *NoteLock     `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"` // One-to-one relationship with cascade delete
 
+	// Weather conditions nearest to the detection time, nil when no reading was available
+	WeatherTemperature   *float64 // Air temperature in °C
+	WeatherWindSpeed     *float64 // Wind speed in m/s
+	WeatherPrecipitation *float64 // Precipitation amount in mm
+	WeatherIcon          string   // Standardized weather icon code
+
+	// Seasonal prior applied to the confidence threshold, nil when the prior was not applied
+	SeasonalOccurrence *float64 // Range model occurrence probability for the detection week
+	SeasonalAdjustment *float64 // Amount added to the confidence threshold, negative when lowered
+
+	// How unusual the species is for this station and week of the year, from 0 for common species
+	// to 1 for species not expected here, nil when the detection was not scored
+	RarityScore *float64 `gorm:"index:idx_notes_rarity_score"`
+
+	// Processing profile that made the detection, empty for the default pipeline. A source
+	// analyzed by several profiles can have a detection of the same call from each of them.
+	Profile string `gorm:"index:idx_notes_profile"`
+
+	// Additional acoustic event detector that made the detection, empty for BirdNET
+	Detector string `gorm:"index:idx_notes_detector"`
+
+	// Model, software version and analysis settings in effect for the detection, nil for
+	// detections saved before snapshots were recorded. AnalysisSnapshot is resolved to
+	// AnalysisSnapshotID when the note is saved and loaded by Get.
+	AnalysisSnapshotID *uint             `gorm:"index:idx_notes_analysis_snapshot"`
+	AnalysisSnapshot   *AnalysisSnapshot `gorm:"-"`
+
+	// Set by re-scoring when the detection no longer meets the current thresholds or species
+	// filters. Disqualified detections are kept, DisqualifiedReason tells why they were marked.
+	Disqualified       bool `gorm:"index:idx_notes_disqualified"`
+	DisqualifiedReason string
+
+	// Deployment the detection was made in, nil when no deployment was active. Set to the
+	// current deployment when the note is saved.
+	DeploymentID *uint `gorm:"index:idx_notes_deployment_id"`
+
+	// Set when the note is deleted. Trashed notes are hidden from queries, exports and
+	// statistics and can be restored until they are purged, see TrashStore.
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_notes_deleted_at" json:"-"`
+
 	// Virtual fields to maintain compatibility with templates
 	Verified string `gorm:"-"` // This will be populated from Review.Verified
 	Locked   bool   `gorm:"-"` // This will be populated from Lock presence
@@ -51,6 +95,19 @@ type Results struct {
 	Confidence float32
 }
 
+// NoteSource records an audio source that contributed to an approved detection
+// when cross-source correlation is enabled. The source with the highest confidence
+// is tagged as primary and matches the source of the stored note.
+type NoteSource struct {
+	ID         uint    `gorm:"primaryKey"`
+	NoteID     uint    `gorm:"index;not null;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;foreignKey:NoteID;references:ID"` // Foreign key to associate with Note
+	SourceID   string  `gorm:"not null"`                                                                                    // Audio source ID
+	SourceName string  // Display name of the audio source
+	Confidence float64 // Highest confidence reported by this source
+	Count      int     // Number of matches from this source
+	IsPrimary  bool    // true for the source with the highest confidence
+}
+
 // Copy creates a deep copy of the Results struct
 func (r Results) Copy() Results {
 	return Results{
@@ -66,11 +123,22 @@ func (r Results) Copy() Results {
 type NoteReview struct {
 	ID        uint      `gorm:"primaryKey"`
 	NoteID    uint      `gorm:"uniqueIndex;not null;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;foreignKey:NoteID;references:ID"` // Foreign key to associate with Note
-	Verified  string    `gorm:"type:varchar(20)"`                                                                                  // Values: "correct", "false_positive"
+	Verified  string    `gorm:"type:varchar(20)"`                                                                                  // Values: "correct", "false_positive", "misidentified"
 	CreatedAt time.Time `gorm:"index"`                                                                                             // When the review was created
 	UpdatedAt time.Time // When the review was last updated
+
+	CorrectedScientificName string `gorm:"type:varchar(100)"` // Species actually heard, set for misidentified detections
+	CorrectedCommonName     string `gorm:"type:varchar(100)"` // Common name of the corrected species
+	Reviewer                string `gorm:"type:varchar(100)"` // Who made the last review
 }
 
+// Review statuses stored in NoteReview.Verified
+const (
+	ReviewCorrect       = "correct"
+	ReviewFalsePositive = "false_positive"
+	ReviewMisidentified = "misidentified"
+)
+
 // NoteComment represents user comments on a detection
 // GORM will automatically create table name as 'note_comments'
 type NoteComment struct {
@@ -114,6 +182,7 @@ type HourlyWeather struct {
 	WindSpeed     float64
 	WindDeg       int
 	WindGust      float64
+	Precipitation float64 // Precipitation amount in mm
 	Clouds        int
 	WeatherMain   string
 	WeatherDesc   string
@@ -134,6 +203,126 @@ type ImageCache struct {
 	CachedAt       time.Time `gorm:"index"` // When the image was cached
 }
 
+// ImageAttribution is a ledger entry recording the license and author of a species
+// image that has been displayed or shared, so users can comply with CC licensing.
+type ImageAttribution struct {
+	ID             uint      `gorm:"primaryKey"`
+	ScientificName string    `gorm:"index:idx_imageattribution_species_provider,unique;not null"` // Scientific name of the species
+	SourceProvider string    `gorm:"index:idx_imageattribution_species_provider,unique;not null"` // Provider that supplied the image (e.g., "wikimedia", "flickr")
+	URL            string    // The URL of the image
+	LicenseName    string    // The name of the license for the image
+	LicenseURL     string    // The URL of the license details
+	AuthorName     string    // The name of the image author
+	AuthorURL      string    // The URL of the author's page or profile
+	LastUsage      string    // Where the image was last used (e.g., "web", "mqtt", "sse")
+	UsageCount     int       // Number of recorded uses
+	FirstUsedAt    time.Time // When the image was first used
+	LastUsedAt     time.Time `gorm:"index"` // When the image was last used
+}
+
+// Run records one run of the application from process start to stop, so gaps in the
+// detection data can be explained. A run without a stop time found at startup means
+// the previous process did not shut down cleanly.
+type Run struct {
+	ID          uint       `gorm:"primaryKey"`
+	StartedAt   time.Time  `gorm:"index;not null"` // When the process started
+	StoppedAt   *time.Time // When the process stopped, nil while running or until a crash is detected
+	LastSeenAt  time.Time  // Updated periodically while running, approximates the time of a crash
+	Version     string     // Application version of the run
+	StartReason string     // Why the run started (e.g., "first_start", "startup", "upgrade", "crash_recovery")
+	StopReason  string     // Why the run stopped (e.g., "user_request", "crash", "error")
+	StopDetail  string     // Additional stop context such as the received signal or error
+}
+
+// AnalysisSnapshot records the model, application version and analysis settings in effect
+// when detections were made, so they remain interpretable after configuration changes.
+// Notes made with identical settings share one snapshot.
+type AnalysisSnapshot struct {
+	ID           uint      `gorm:"primaryKey"`
+	Fingerprint  string    `gorm:"uniqueIndex;size:64;not null"` // SHA-256 of the snapshot values
+	CreatedAt    time.Time // When the snapshot was first used
+	ModelVersion string    // BirdNET model identifier (e.g., "BirdNET_GLOBAL_6K_V2.4")
+	AppVersion   string    // Application version
+	Overlap      float64   // Analysis window overlap in seconds
+	Sensitivity  float64   // Sigmoid sensitivity
+	Threshold    float64   // Global confidence threshold
+	SourceFormat string    // Audio source type and format fed to the model (e.g., "rtsp 48000Hz 16bit 1ch")
+}
+
+// Deployment records a period the station ran at one location with the same equipment, so
+// placement and equipment changes are tracked alongside the detections referencing it.
+// The current deployment has no end time.
+type Deployment struct {
+	ID          uint       `gorm:"primaryKey"`
+	Name        string     `gorm:"index"` // Short name of the deployment (e.g., "garden-2024"), may be empty
+	Latitude    float64    // Station latitude during the deployment
+	Longitude   float64    // Station longitude during the deployment
+	Microphone  string     // Microphone make and model
+	Gain        string     // Gain settings of the microphone, preamp and software (e.g., "preamp +20 dB")
+	Placement   string     // Microphone placement (e.g., "2 m above ground, facing north")
+	StartedAt   time.Time  `gorm:"index;not null"` // When the deployment started
+	EndedAt     *time.Time // When the deployment ended, nil for the current deployment
+	Description string     // Free-form notes, e.g. the reason for the change
+}
+
+// SpeciesAlias links a scientific name replaced by a taxonomy update, such as a rename or a
+// split, to the current name. Detections saved with the old name are stored with the current
+// one, and MergeSpeciesAliases moves historical notes to the current name.
+type SpeciesAlias struct {
+	ID                uint      `gorm:"primaryKey"`
+	OldScientificName string    `gorm:"uniqueIndex;size:255;not null"` // Scientific name replaced by the taxonomy update
+	ScientificName    string    `gorm:"index;size:255;not null"`       // Current scientific name
+	CommonName        string    // Current common name, empty to keep the common names of the notes
+	SpeciesCode       string    // Current eBird species code, empty to keep the codes of the notes
+	Reason            string    // Free-form reason, e.g. "eBird/Clements 2024 split"
+	CreatedAt         time.Time // When the alias was added
+}
+
+// WebPushSubscription is the push subscription of a browser or installed dashboard receiving
+// Web Push detection notifications. The endpoint, p256dh key and auth secret come from the
+// PushSubscription of the browser.
+type WebPushSubscription struct {
+	ID         uint       `gorm:"primaryKey"`
+	Endpoint   string     `gorm:"uniqueIndex;size:512;not null"` // Push service URL of the subscription
+	P256dh     string     `gorm:"not null"`                      // Public key of the browser, base64url encoded
+	Auth       string     `gorm:"not null"`                      // Authentication secret of the browser, base64url encoded
+	Username   string     `gorm:"index"`                         // User that subscribed, empty without authentication
+	Name       string     // Device name shown in the subscription list (e.g., "Pixel 8")
+	Species    string     // Comma-separated common or scientific names to push, empty for all species
+	CreatedAt  time.Time  // When the browser subscribed
+	LastSentAt *time.Time // When a notification was last accepted by the push service
+	Failures   int        // Consecutive failed deliveries
+}
+
+// DynamicThresholdState persists the dynamic confidence threshold of a species so it survives
+// restarts. Each processing profile keeps its own thresholds, the default pipeline uses an
+// empty profile.
+type DynamicThresholdState struct {
+	ID            uint      `gorm:"primaryKey"`
+	Profile       string    `gorm:"index:idx_dynamicthreshold_profile_species,unique;size:100"`          // Processing profile name
+	Species       string    `gorm:"index:idx_dynamicthreshold_profile_species,unique;size:200;not null"` // Lowercase common name
+	Level         int       // Adjustment level, 0 when the base threshold applies
+	CurrentValue  float64   // Threshold in effect
+	Timer         time.Time // When the adjusted threshold expires
+	HighConfCount int       // High confidence detections since the last reset
+	ValidHours    int       // Hours an adjustment stays valid
+	UpdatedAt     time.Time // When the state was last persisted
+}
+
+// SettingsChange records the change of one setting, who changed it and when. Values are
+// stored as JSON with secrets masked.
+type SettingsChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	ChangedAt time.Time `gorm:"index;not null"` // When the change was saved
+	Actor     string    // Who made the change, the authenticated user name or the authentication method
+	Source    string    // How the change was made (e.g., "api", "config_reload")
+	RemoteIP  string    // Client address of API changes
+	Section   string    `gorm:"index"` // Top-level settings section (e.g., "realtime")
+	Path      string    // Setting path (e.g., "realtime.mqtt.broker")
+	OldValue  string    // Previous value as JSON
+	NewValue  string    // New value as JSON
+}
+
 // ImageCacheQuery encapsulates parameters for querying the image cache.
 type ImageCacheQuery struct {
 	ScientificName string
@@ -157,4 +346,5 @@ type DetectionRecord struct {
 	Device         string    `json:"device,omitempty"`
 	Source         string    `json:"source,omitempty"`
 	TimeOfDay      string    `json:"timeOfDay,omitempty"`
+	RarityScore    *float64  `json:"rarityScore,omitempty"`
 }
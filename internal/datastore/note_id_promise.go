@@ -0,0 +1,209 @@
+// note_id_promise.go: provisional note IDs handed out before a note is saved. The note is still
+// saved by the caller through Interface.Save, concurrently with the users of the provisional ID.
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	// provisionalIDBase is the first provisional note ID. Database IDs stay far below it, so a
+	// provisional ID never names a saved note, and it fits the 32-bit uint of ARM builds.
+	provisionalIDBase uint = 1 << 31
+
+	// provisionalIDRetention is how long a provisional ID is kept, long enough for clients
+	// that received it in a live broadcast to load the detection
+	provisionalIDRetention = 10 * time.Minute
+)
+
+// ErrNoteNotSaved is returned when resolving a provisional ID of a note that was not saved
+var ErrNoteNotSaved = errors.Newf("note was not saved").Component("datastore").Category(errors.CategoryNotFound).Build()
+
+// ErrNotePending is returned when resolving a provisional ID of a note buffered until the
+// database recovers
+var ErrNotePending = errors.Newf("note is buffered until the database recovers").Component("datastore").Category(errors.CategoryDatabase).Build()
+
+// NoteIDPromise is the ID of a note saved concurrently with its broadcast. It holds a
+// provisional ID from creation, so the note can be broadcast before the save completes, and
+// the database ID once the note is saved. Provisional IDs are resolved to database IDs by
+// ResolveNoteID. A note buffered while the database is unavailable stays pending until
+// ResolveBufferedNoteID reports the save of the buffered note.
+type NoteIDPromise struct {
+	provisional uint
+	created     time.Time
+	done        chan struct{}
+	once        sync.Once
+	id          uint // database ID, 0 when the note was not saved; set before done is closed
+
+	buffered   chan struct{} // closed when the note is buffered instead of saved
+	bufferOnce sync.Once
+}
+
+// provisionalIDs holds the promises of recent provisional IDs. The run identifies this process
+// in buffer tokens, provisional IDs restart at provisionalIDBase on every run.
+var provisionalIDs = struct {
+	mu       sync.Mutex
+	next     uint
+	run      string
+	promises map[uint]*NoteIDPromise
+}{
+	next:     provisionalIDBase,
+	run:      strconv.FormatInt(time.Now().UnixNano(), 36),
+	promises: make(map[uint]*NoteIDPromise),
+}
+
+// NewNoteIDPromise returns a promise with a new provisional ID for a note about to be saved
+func NewNoteIDPromise() *NoteIDPromise {
+	now := time.Now()
+	provisionalIDs.mu.Lock()
+	defer provisionalIDs.mu.Unlock()
+
+	// Drop promises older than the retention, including ones whose save never ran. Buffered
+	// notes are kept until their buffered save is reported.
+	for id, p := range provisionalIDs.promises {
+		if now.Sub(p.created) > provisionalIDRetention && !p.isBuffered() {
+			delete(provisionalIDs.promises, id)
+		}
+	}
+
+	p := &NoteIDPromise{provisional: provisionalIDs.next, created: now, done: make(chan struct{}), buffered: make(chan struct{})}
+	provisionalIDs.promises[p.provisional] = p
+	provisionalIDs.next++
+	if provisionalIDs.next < provisionalIDBase {
+		provisionalIDs.next = provisionalIDBase
+	}
+	return p
+}
+
+// Provisional returns the provisional ID of the note
+func (p *NoteIDPromise) Provisional() uint {
+	return p.provisional
+}
+
+// ID returns the database ID once the note is saved, and the provisional ID until then or
+// when the note was not saved
+func (p *NoteIDPromise) ID() uint {
+	select {
+	case <-p.done:
+		if p.id != 0 {
+			return p.id
+		}
+	default:
+	}
+	return p.provisional
+}
+
+// Resolve completes the promise with the database ID of the saved note, or 0 when the note
+// was not saved. Only the first call has an effect. It is safe to call on a nil promise.
+func (p *NoteIDPromise) Resolve(id uint) {
+	if p == nil {
+		return
+	}
+	p.once.Do(func() {
+		p.id = id
+		close(p.done)
+	})
+}
+
+// Buffer marks the note as buffered until the database recovers and returns the token to
+// pass to ResolveBufferedNoteID once the buffered note is saved. The promise stays pending,
+// requests for the provisional ID fail with ErrNotePending meanwhile. It returns an empty
+// token on a nil promise.
+func (p *NoteIDPromise) Buffer() string {
+	if p == nil {
+		return ""
+	}
+	p.bufferOnce.Do(func() { close(p.buffered) })
+	return provisionalIDs.run + "/" + strconv.FormatUint(uint64(p.provisional), 10)
+}
+
+// isBuffered reports whether the note is buffered and its buffered save not yet reported
+func (p *NoteIDPromise) isBuffered() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+	}
+	select {
+	case <-p.buffered:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait returns the database ID once the note is saved. It returns ErrNoteNotSaved when the
+// note was not saved, ErrNotePending while the note is buffered, or the context error when
+// ctx is done first.
+func (p *NoteIDPromise) Wait(ctx context.Context) (uint, error) {
+	select {
+	case <-p.done:
+	case <-p.buffered:
+		if p.isBuffered() {
+			return 0, ErrNotePending
+		}
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	if p.id == 0 {
+		return 0, ErrNoteNotSaved
+	}
+	return p.id, nil
+}
+
+// ResolveBufferedNoteID resolves the promise of a buffered note with the database ID once
+// the buffered note is saved, or with 0 when it was dropped. token is the token returned by
+// Buffer. Tokens of previous runs and of promises no longer kept are ignored.
+func ResolveBufferedNoteID(token string, id uint) {
+	run, provisional, ok := strings.Cut(token, "/")
+	if !ok || run != provisionalIDs.run {
+		return
+	}
+	value, err := strconv.ParseUint(provisional, 10, 0)
+	if err != nil {
+		return
+	}
+
+	provisionalIDs.mu.Lock()
+	p, ok := provisionalIDs.promises[uint(value)]
+	provisionalIDs.mu.Unlock()
+	if ok {
+		p.Resolve(id)
+	}
+}
+
+// IsProvisionalNoteID reports whether id is in the range of provisional IDs
+func IsProvisionalNoteID(id uint) bool {
+	return id >= provisionalIDBase
+}
+
+// ResolveNoteID returns the database ID of a note ID in a request. A provisional ID is replaced
+// by the database ID, waiting for the save until ctx is done; other IDs are returned unchanged.
+// It returns ErrNoteNotSaved for a provisional ID of a note that was not saved or is unknown,
+// and ErrNotePending for a note buffered until the database recovers.
+func ResolveNoteID(ctx context.Context, id string) (string, error) {
+	value, err := strconv.ParseUint(id, 10, 0)
+	if err != nil || !IsProvisionalNoteID(uint(value)) {
+		return id, nil
+	}
+
+	provisionalIDs.mu.Lock()
+	p, ok := provisionalIDs.promises[uint(value)]
+	provisionalIDs.mu.Unlock()
+	if !ok {
+		return "", ErrNoteNotSaved
+	}
+
+	noteID, err := p.Wait(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(uint64(noteID), 10), nil
+}
@@ -0,0 +1,87 @@
+// note_id_promise_test.go: Tests for provisional note IDs
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoteIDPromise(t *testing.T) {
+	t.Parallel()
+
+	p := NewNoteIDPromise()
+	assert.True(t, IsProvisionalNoteID(p.Provisional()))
+	assert.Equal(t, p.Provisional(), p.ID(), "the provisional ID is used until the note is saved")
+	assert.NotEqual(t, p.Provisional(), NewNoteIDPromise().Provisional())
+
+	provisional := strconv.FormatUint(uint64(p.Provisional()), 10)
+	resolved := make(chan string, 1)
+	go func() {
+		id, err := ResolveNoteID(context.Background(), provisional)
+		assert.NoError(t, err)
+		resolved <- id
+	}()
+
+	p.Resolve(42)
+	p.Resolve(43)
+	assert.Equal(t, "42", <-resolved, "a request with the provisional ID waits for the database ID")
+	assert.Equal(t, uint(42), p.ID())
+
+	id, err := ResolveNoteID(context.Background(), "17")
+	require.NoError(t, err)
+	assert.Equal(t, "17", id, "database IDs are not changed")
+	id, err = ResolveNoteID(context.Background(), "not-a-number")
+	require.NoError(t, err)
+	assert.Equal(t, "not-a-number", id)
+}
+
+func TestNoteIDPromise_NotSaved(t *testing.T) {
+	t.Parallel()
+
+	p := NewNoteIDPromise()
+	provisional := strconv.FormatUint(uint64(p.Provisional()), 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := ResolveNoteID(ctx, provisional)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "a pending save is waited for until the request ends")
+
+	p.Resolve(0)
+	_, err = ResolveNoteID(context.Background(), provisional)
+	require.ErrorIs(t, err, ErrNoteNotSaved)
+	assert.Equal(t, p.Provisional(), p.ID())
+
+	_, err = ResolveNoteID(context.Background(), strconv.FormatUint(uint64(provisionalIDBase)+1<<20, 10))
+	require.ErrorIs(t, err, ErrNoteNotSaved, "unknown provisional IDs are not found")
+}
+
+func TestNoteIDPromise_Buffered(t *testing.T) {
+	t.Parallel()
+
+	p := NewNoteIDPromise()
+	provisional := strconv.FormatUint(uint64(p.Provisional()), 10)
+	token := p.Buffer()
+
+	_, err := ResolveNoteID(context.Background(), provisional)
+	require.ErrorIs(t, err, ErrNotePending, "a buffered note is pending without waiting for the request timeout")
+	assert.Equal(t, p.Provisional(), p.ID())
+
+	// Tokens of other runs do not resolve promises of this run
+	ResolveBufferedNoteID("previous-run/"+provisional, 7)
+	_, err = ResolveNoteID(context.Background(), provisional)
+	require.ErrorIs(t, err, ErrNotePending)
+
+	ResolveBufferedNoteID(token, 42)
+	id, err := ResolveNoteID(context.Background(), provisional)
+	require.NoError(t, err)
+	assert.Equal(t, "42", id, "the provisional ID resolves once the buffered note is saved")
+	assert.Equal(t, uint(42), p.ID())
+
+	var nilPromise *NoteIDPromise
+	assert.Empty(t, nilPromise.Buffer())
+}
@@ -0,0 +1,72 @@
+// note_sources.go: audio sources contributing to cross-source correlated detections
+package datastore
+
+import (
+	"fmt"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// NoteSourceStore stores the audio sources that contributed to a detection.
+type NoteSourceStore interface {
+	SaveNoteSources(noteID uint, sources []NoteSource) error
+	GetNoteSources(noteID uint) ([]NoteSource, error)
+}
+
+// SaveNoteSources replaces the contributing sources recorded for a note
+func (ds *DataStore) SaveNoteSources(noteID uint, sources []NoteSource) error {
+	if noteID == 0 {
+		return validationError("note ID cannot be zero", "note_id", noteID)
+	}
+
+	tx := ds.DB.Begin()
+	if tx.Error != nil {
+		return dbError(tx.Error, "begin_transaction", errors.PriorityMedium,
+			"note_id", fmt.Sprintf("%d", noteID),
+			"table", "note_sources")
+	}
+
+	if err := tx.Where("note_id = ?", noteID).Delete(&NoteSource{}).Error; err != nil {
+		tx.Rollback()
+		return dbError(err, "delete_note_sources", errors.PriorityMedium,
+			"note_id", fmt.Sprintf("%d", noteID),
+			"table", "note_sources")
+	}
+
+	if len(sources) > 0 {
+		rows := make([]NoteSource, len(sources))
+		for i := range sources {
+			rows[i] = sources[i]
+			rows[i].ID = 0
+			rows[i].NoteID = noteID
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			tx.Rollback()
+			return dbError(err, "save_note_sources", errors.PriorityMedium,
+				"note_id", fmt.Sprintf("%d", noteID),
+				"table", "note_sources",
+				"source_count", len(sources))
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return dbError(err, "commit_transaction", errors.PriorityMedium,
+			"note_id", fmt.Sprintf("%d", noteID),
+			"table", "note_sources")
+	}
+
+	return nil
+}
+
+// GetNoteSources returns the contributing sources of a note, primary source first
+func (ds *DataStore) GetNoteSources(noteID uint) ([]NoteSource, error) {
+	var sources []NoteSource
+	if err := ds.DB.Where("note_id = ?", noteID).
+		Order("is_primary DESC, confidence DESC").
+		Find(&sources).Error; err != nil {
+		return nil, dbError(err, "get_note_sources", errors.PriorityLow,
+			"note_id", fmt.Sprintf("%d", noteID),
+			"table", "note_sources")
+	}
+	return sources, nil
+}
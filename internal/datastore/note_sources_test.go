@@ -0,0 +1,39 @@
+// note_sources_test.go: Tests for cross-source detection source records
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveNoteSources(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&NoteSource{}))
+
+	var store NoteSourceStore = ds
+
+	require.NoError(t, store.SaveNoteSources(42, []NoteSource{
+		{SourceID: "rtsp_1", SourceName: "Garden", Confidence: 0.71, Count: 2},
+		{SourceID: "rtsp_2", SourceName: "Pond", Confidence: 0.93, Count: 3, IsPrimary: true},
+	}))
+
+	sources, err := store.GetNoteSources(42)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	assert.True(t, sources[0].IsPrimary, "primary source should be listed first")
+	assert.Equal(t, "rtsp_2", sources[0].SourceID)
+	assert.Equal(t, uint(42), sources[1].NoteID)
+
+	// Saving again replaces the previous records
+	require.NoError(t, store.SaveNoteSources(42, []NoteSource{
+		{SourceID: "rtsp_1", SourceName: "Garden", Confidence: 0.8, Count: 1, IsPrimary: true},
+	}))
+	sources, err = store.GetNoteSources(42)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.InDelta(t, 0.8, sources[0].Confidence, 0.0001)
+
+	assert.Error(t, store.SaveNoteSources(0, nil))
+}
@@ -0,0 +1,166 @@
+// rescore.go: re-evaluation of stored detections against current thresholds and species filters
+package datastore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+// Reasons detections are disqualified by re-scoring
+const (
+	DisqualifiedBelowThreshold = "below_threshold" // Confidence is below the current threshold
+	DisqualifiedExcluded       = "excluded"        // Species is on the exclude list
+)
+
+// rescoreBatchSize is the number of detections evaluated per database query
+const rescoreBatchSize = 500
+
+// RescoreCriteria are the thresholds and species filters stored detections are evaluated against
+type RescoreCriteria struct {
+	Threshold         float64            // Confidence threshold of species without a custom threshold
+	SpeciesThresholds map[string]float64 // Custom thresholds by lowercase common or scientific name
+	Exclude           []string           // Excluded common or scientific names
+}
+
+// RescoreCriteriaFromSettings returns the criteria realtime analysis currently applies
+func RescoreCriteriaFromSettings(settings *conf.Settings) RescoreCriteria {
+	criteria := RescoreCriteria{
+		Threshold:         settings.BirdNET.Threshold,
+		SpeciesThresholds: make(map[string]float64, len(settings.Realtime.Species.Config)),
+		Exclude:           settings.Realtime.Species.Exclude,
+	}
+	for name, config := range settings.Realtime.Species.Config {
+		criteria.SpeciesThresholds[strings.ToLower(strings.TrimSpace(name))] = config.Threshold
+	}
+	return criteria
+}
+
+// Evaluate returns the reason the detection no longer qualifies, or an empty string when it does
+func (c *RescoreCriteria) Evaluate(note *Note) string {
+	for _, entry := range c.Exclude {
+		entry = strings.TrimSpace(entry)
+		if strings.EqualFold(entry, note.CommonName) || strings.EqualFold(entry, note.ScientificName) {
+			return DisqualifiedExcluded
+		}
+	}
+
+	threshold := c.Threshold
+	if custom, ok := c.SpeciesThresholds[strings.ToLower(note.CommonName)]; ok {
+		threshold = custom
+	} else if custom, ok := c.SpeciesThresholds[strings.ToLower(note.ScientificName)]; ok {
+		threshold = custom
+	}
+	if note.Confidence < threshold {
+		return DisqualifiedBelowThreshold
+	}
+	return ""
+}
+
+// RescoreSummary holds the statistics of a re-scoring run
+type RescoreSummary struct {
+	Evaluated    int            // Detections evaluated
+	Disqualified int            // Detections not meeting the criteria
+	NewlyMarked  int            // Detections marked by this run
+	Cleared      int            // Previously marked detections meeting the criteria again
+	Skipped      int64          // Locked or verified detections left unchanged
+	Reasons      map[string]int // Disqualified detections by reason
+	Species      map[string]int // Disqualified detections by common name
+}
+
+// DetectionRescorer re-evaluates stored detections.
+type DetectionRescorer interface {
+	RescoreDetections(ctx context.Context, from, to string, criteria RescoreCriteria, dryRun bool) (*RescoreSummary, error)
+}
+
+// RescoreDetections evaluates the detections between the from and to dates (YYYY-MM-DD, empty
+// for no bound) against criteria. Detections that no longer qualify are marked disqualified and
+// marked detections that qualify again are cleared; nothing is deleted. Locked detections and
+// detections verified as correct are left unchanged. With dryRun only the summary is computed.
+func (ds *DataStore) RescoreDetections(ctx context.Context, from, to string, criteria RescoreCriteria, dryRun bool) (*RescoreSummary, error) {
+	summary := &RescoreSummary{
+		Reasons: make(map[string]int),
+		Species: make(map[string]int),
+	}
+
+	inRange := func(db *gorm.DB) *gorm.DB {
+		if from != "" {
+			db = db.Where("date >= ?", from)
+		}
+		if to != "" {
+			db = db.Where("date <= ?", to)
+		}
+		return db
+	}
+	protected := "id IN (SELECT note_id FROM note_locks) OR id IN (SELECT note_id FROM note_reviews WHERE verified = 'correct')"
+
+	if err := inRange(ds.DB.WithContext(ctx).Model(&Note{})).Where(protected).Count(&summary.Skipped).Error; err != nil {
+		return nil, dbError(err, "count_protected_detections", errors.PriorityMedium,
+			"table", "notes")
+	}
+
+	var batch []Note
+	query := inRange(ds.DB.WithContext(ctx).Model(&Note{})).
+		Select("id", "common_name", "scientific_name", "confidence", "disqualified", "disqualified_reason").
+		Where("NOT (" + protected + ")")
+	result := query.FindInBatches(&batch, rescoreBatchSize, func(tx *gorm.DB, _ int) error {
+		marks := make(map[string][]uint)
+		var cleared []uint
+
+		for i := range batch {
+			note := &batch[i]
+			summary.Evaluated++
+			reason := criteria.Evaluate(note)
+			if reason == "" {
+				if note.Disqualified {
+					cleared = append(cleared, note.ID)
+				}
+				continue
+			}
+
+			summary.Disqualified++
+			summary.Reasons[reason]++
+			summary.Species[note.CommonName]++
+			if !note.Disqualified || note.DisqualifiedReason != reason {
+				marks[reason] = append(marks[reason], note.ID)
+				if !note.Disqualified {
+					summary.NewlyMarked++
+				}
+			}
+		}
+		summary.Cleared += len(cleared)
+
+		if dryRun {
+			return nil
+		}
+		return ds.applyRescore(ctx, marks, cleared)
+	})
+	if result.Error != nil {
+		return nil, dbError(result.Error, "rescore_detections", errors.PriorityMedium,
+			"table", "notes")
+	}
+
+	return summary, nil
+}
+
+// applyRescore stores the disqualification marks of one batch of detections
+func (ds *DataStore) applyRescore(ctx context.Context, marks map[string][]uint, cleared []uint) error {
+	return ds.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for reason, ids := range marks {
+			if err := tx.Model(&Note{}).Where("id IN ?", ids).
+				Updates(map[string]any{"disqualified": true, "disqualified_reason": reason}).Error; err != nil {
+				return err
+			}
+		}
+		if len(cleared) > 0 {
+			if err := tx.Model(&Note{}).Where("id IN ?", cleared).
+				Updates(map[string]any{"disqualified": false, "disqualified_reason": ""}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
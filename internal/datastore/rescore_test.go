@@ -0,0 +1,86 @@
+// rescore_test.go: Tests for re-scoring stored detections
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestRescoreCriteriaEvaluate(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{"House Sparrow": {Threshold: 0.9}}
+	settings.Realtime.Species.Exclude = []string{"Dog"}
+	criteria := RescoreCriteriaFromSettings(settings)
+
+	tests := []struct {
+		name string
+		note Note
+		want string
+	}{
+		{"above global threshold", Note{CommonName: "Great Tit", Confidence: 0.85}, ""},
+		{"below global threshold", Note{CommonName: "Great Tit", Confidence: 0.75}, DisqualifiedBelowThreshold},
+		{"below custom threshold", Note{CommonName: "House Sparrow", Confidence: 0.85}, DisqualifiedBelowThreshold},
+		{"above custom threshold", Note{CommonName: "house sparrow", Confidence: 0.95}, ""},
+		{"excluded species", Note{CommonName: "dog", Confidence: 0.99}, DisqualifiedExcluded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, criteria.Evaluate(&tt.note))
+		})
+	}
+}
+
+func TestRescoreDetections(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&NoteReview{}, &NoteLock{}))
+
+	notes := []Note{
+		{ID: 1, Date: "2024-06-01", CommonName: "Great Tit", Confidence: 0.95},
+		{ID: 2, Date: "2024-06-01", CommonName: "Great Tit", Confidence: 0.72},
+		{ID: 3, Date: "2024-06-02", CommonName: "Eurasian Wren", Confidence: 0.70},
+		{ID: 4, Date: "2024-06-02", CommonName: "Eurasian Wren", Confidence: 0.71},
+		{ID: 5, Date: "2024-06-02", CommonName: "Dunnock", Confidence: 0.90, Disqualified: true, DisqualifiedReason: DisqualifiedBelowThreshold},
+		{ID: 6, Date: "2024-05-01", CommonName: "Great Tit", Confidence: 0.50},
+	}
+	require.NoError(t, ds.DB.Create(&notes).Error)
+	require.NoError(t, ds.DB.Create(&NoteLock{NoteID: 3}).Error)
+	require.NoError(t, ds.DB.Create(&NoteReview{NoteID: 4, Verified: "correct"}).Error)
+
+	criteria := RescoreCriteria{Threshold: 0.8}
+
+	dryRun, err := ds.RescoreDetections(t.Context(), "2024-06-01", "", criteria, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, dryRun.Evaluated)
+	assert.Equal(t, int64(2), dryRun.Skipped, "locked and verified detections are skipped")
+	assert.Equal(t, 1, dryRun.NewlyMarked)
+	assert.Equal(t, 1, dryRun.Cleared)
+
+	var marked int64
+	require.NoError(t, ds.DB.Model(&Note{}).Where("disqualified = ?", true).Count(&marked).Error)
+	assert.Equal(t, int64(1), marked, "dry run leaves detections unchanged")
+
+	summary, err := ds.RescoreDetections(t.Context(), "2024-06-01", "", criteria, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{DisqualifiedBelowThreshold: 1}, summary.Reasons)
+	assert.Equal(t, map[string]int{"Great Tit": 1}, summary.Species)
+
+	var got []Note
+	require.NoError(t, ds.DB.Order("id").Find(&got).Error)
+	disqualified := make(map[uint]bool)
+	for i := range got {
+		disqualified[got[i].ID] = got[i].Disqualified
+	}
+	assert.Equal(t, map[uint]bool{1: false, 2: true, 3: false, 4: false, 5: false, 6: false}, disqualified)
+	assert.Equal(t, DisqualifiedBelowThreshold, got[1].DisqualifiedReason)
+
+	// Running again changes nothing
+	again, err := ds.RescoreDetections(t.Context(), "2024-06-01", "", criteria, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.NewlyMarked)
+	assert.Equal(t, 0, again.Cleared)
+	assert.Equal(t, 1, again.Disqualified)
+}
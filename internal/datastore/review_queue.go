@@ -0,0 +1,113 @@
+// review_queue.go: unreviewed detections awaiting human review and reviewed detections
+package datastore
+
+import (
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+// Review queue orders
+const (
+	ReviewQueueByConfidence = "confidence" // least confident detections first
+	ReviewQueueByNovelty    = "novelty"    // detections of the least detected species first
+)
+
+// ReviewQueueStore lists detections for human review and the reviewed detections.
+type ReviewQueueStore interface {
+	GetReviewQueue(order string, limit, offset int) ([]ReviewQueueItem, int64, error)
+	GetReviewedNotes(startDate, endDate string) ([]Note, error)
+}
+
+// ReviewQueueItem is an unreviewed detection in the review queue
+type ReviewQueueItem struct {
+	Note         Note
+	SpeciesCount int64 // Detections of the species, novelty ranks rarely detected species first
+}
+
+// unreviewedNotes returns a query of the notes without a review
+func (ds *DataStore) unreviewedNotes() *gorm.DB {
+	return ds.DB.Model(&Note{}).
+		Joins("LEFT JOIN note_reviews ON note_reviews.note_id = notes.id").
+		Where("note_reviews.id IS NULL OR note_reviews.verified = ''")
+}
+
+// GetReviewQueue returns a page of the unreviewed detections in the given order and the total
+// number of unreviewed detections
+func (ds *DataStore) GetReviewQueue(order string, limit, offset int) ([]ReviewQueueItem, int64, error) {
+	var total int64
+	if err := ds.unreviewedNotes().Count(&total).Error; err != nil {
+		return nil, 0, dbError(err, "count_review_queue", errors.PriorityLow,
+			"table", "notes")
+	}
+
+	query := ds.unreviewedNotes().Select("notes.*")
+	switch order {
+	case ReviewQueueByConfidence, "":
+		query = query.Order("notes.confidence ASC, notes.id DESC")
+	case ReviewQueueByNovelty:
+		query = query.
+			Joins("JOIN (SELECT scientific_name, COUNT(*) AS species_count FROM notes WHERE deleted_at IS NULL GROUP BY scientific_name) species_counts ON species_counts.scientific_name = notes.scientific_name").
+			Order("species_counts.species_count ASC, notes.confidence ASC, notes.id DESC")
+	default:
+		return nil, 0, validationError("must be confidence or novelty", "order", order)
+	}
+
+	var notes []Note
+	if err := query.Limit(limit).Offset(offset).Find(&notes).Error; err != nil {
+		return nil, 0, dbError(err, "get_review_queue", errors.PriorityLow,
+			"table", "notes",
+			"order", order)
+	}
+	if len(notes) == 0 {
+		return []ReviewQueueItem{}, total, nil
+	}
+
+	species := make([]string, 0, len(notes))
+	for i := range notes {
+		species = append(species, notes[i].ScientificName)
+	}
+	var counts []struct {
+		ScientificName string
+		Count          int64
+	}
+	if err := ds.DB.Model(&Note{}).
+		Select("scientific_name, COUNT(*) AS count").
+		Where("scientific_name IN ?", species).
+		Group("scientific_name").
+		Scan(&counts).Error; err != nil {
+		return nil, 0, dbError(err, "count_review_queue_species", errors.PriorityLow,
+			"table", "notes")
+	}
+	speciesCounts := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		speciesCounts[count.ScientificName] = count.Count
+	}
+
+	items := make([]ReviewQueueItem, len(notes))
+	for i := range notes {
+		items[i] = ReviewQueueItem{Note: notes[i], SpeciesCount: speciesCounts[notes[i].ScientificName]}
+	}
+	return items, total, nil
+}
+
+// GetReviewedNotes returns the reviewed detections between startDate and endDate, YYYY-MM-DD,
+// with their reviews
+func (ds *DataStore) GetReviewedNotes(startDate, endDate string) ([]Note, error) {
+	var notes []Note
+	if err := ds.DB.Preload("Review").
+		Joins("JOIN note_reviews ON note_reviews.note_id = notes.id AND note_reviews.verified != ''").
+		Where("notes.date >= ? AND notes.date <= ?", startDate, endDate).
+		Order("notes.date ASC, notes.time ASC, notes.id ASC").
+		Find(&notes).Error; err != nil {
+		return nil, dbError(err, "get_reviewed_notes", errors.PriorityLow,
+			"table", "notes",
+			"start_date", startDate,
+			"end_date", endDate)
+	}
+	for i := range notes {
+		if notes[i].Review != nil {
+			notes[i].Verified = notes[i].Review.Verified
+		}
+	}
+	return notes, nil
+}
@@ -0,0 +1,54 @@
+// review_queue_test.go: Tests for the review queue and reviewed detections
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReviewQueue(t *testing.T) {
+	ds := setupTestDB(t)
+	seedTestData(t, ds)
+
+	var store ReviewQueueStore = ds
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 2, Verified: ReviewCorrect}))
+
+	items, total, err := store.GetReviewQueue(ReviewQueueByConfidence, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total, "the reviewed detection is not queued")
+	require.Len(t, items, 4)
+	for i := 1; i < len(items); i++ {
+		assert.LessOrEqual(t, items[i-1].Note.Confidence, items[i].Note.Confidence)
+	}
+
+	// The only Northern Cardinal detection leads the novelty order
+	items, _, err = store.GetReviewQueue(ReviewQueueByNovelty, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Northern Cardinal", items[0].Note.CommonName)
+	assert.Equal(t, int64(1), items[0].SpeciesCount)
+	assert.Equal(t, int64(2), items[1].SpeciesCount)
+
+	_, _, err = store.GetReviewQueue("random", 10, 0)
+	assert.Error(t, err)
+}
+
+func TestGetReviewedNotes(t *testing.T) {
+	ds := setupTestDB(t)
+	seedTestData(t, ds)
+
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 1, Verified: ReviewCorrect}))
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 4, Verified: ReviewFalsePositive}))
+	require.NoError(t, ds.SaveNoteReview(&NoteReview{NoteID: 5, Verified: ReviewCorrect}))
+
+	notes, err := ds.GetReviewedNotes("2024-01-15", "2024-01-16")
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, uint(1), notes[0].ID)
+	assert.Equal(t, ReviewCorrect, notes[0].Verified)
+	assert.Equal(t, uint(4), notes[1].ID)
+	require.NotNil(t, notes[1].Review)
+	assert.Equal(t, ReviewFalsePositive, notes[1].Review.Verified)
+}
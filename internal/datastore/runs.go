@@ -0,0 +1,128 @@
+// runs.go: application run history with start and stop reasons
+package datastore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Run start reasons
+const (
+	RunStartFirst         = "first_start"    // No earlier run was recorded
+	RunStartNormal        = "startup"        // Previous run stopped cleanly
+	RunStartUpgrade       = "upgrade"        // Application version changed since the previous run
+	RunStartCrashRecovery = "crash_recovery" // Previous run did not shut down cleanly
+)
+
+// Run stop reasons
+const (
+	RunStopUserRequest = "user_request" // Stopped by a signal or a user action
+	RunStopCrash       = "crash"        // Process ended without a clean shutdown
+	RunStopError       = "error"        // Stopped because of a fatal error
+)
+
+// RunLedger records application runs.
+type RunLedger interface {
+	StartRun(version string, startedAt time.Time) (*Run, error)
+	HeartbeatRun(runID uint, at time.Time) error
+	StopRun(runID uint, reason, detail string, at time.Time) error
+	GetRuns(limit int) ([]Run, error)
+}
+
+// StartRun records the start of a new run. A previous run that was never stopped is closed
+// as crashed at the time it was last seen, and the start reason is derived from it.
+func (ds *DataStore) StartRun(version string, startedAt time.Time) (*Run, error) {
+	var previous []Run
+	if err := ds.DB.Order("started_at DESC, id DESC").Limit(1).Find(&previous).Error; err != nil {
+		return nil, dbError(err, "get_previous_run", errors.PriorityMedium,
+			"table", "runs")
+	}
+
+	run := &Run{
+		StartedAt:   startedAt,
+		LastSeenAt:  startedAt,
+		Version:     version,
+		StartReason: RunStartFirst,
+	}
+
+	if len(previous) > 0 {
+		last := &previous[0]
+		switch {
+		case last.StoppedAt == nil:
+			run.StartReason = RunStartCrashRecovery
+			stoppedAt := last.LastSeenAt
+			if stoppedAt.IsZero() {
+				stoppedAt = last.StartedAt
+			}
+			if err := ds.DB.Model(last).Updates(map[string]any{
+				"stopped_at":  stoppedAt,
+				"stop_reason": RunStopCrash,
+				"stop_detail": "unclean shutdown detected at next start",
+			}).Error; err != nil {
+				return nil, dbError(err, "close_crashed_run", errors.PriorityMedium,
+					"table", "runs",
+					"run_id", fmt.Sprintf("%d", last.ID))
+			}
+		case last.Version != version:
+			run.StartReason = RunStartUpgrade
+		default:
+			run.StartReason = RunStartNormal
+		}
+	}
+
+	if err := ds.DB.Create(run).Error; err != nil {
+		return nil, dbError(err, "start_run", errors.PriorityMedium,
+			"table", "runs",
+			"version", version)
+	}
+
+	return run, nil
+}
+
+// HeartbeatRun updates the time a running run was last seen alive
+func (ds *DataStore) HeartbeatRun(runID uint, at time.Time) error {
+	if runID == 0 {
+		return validationError("run ID cannot be zero", "run_id", runID)
+	}
+	if err := ds.DB.Model(&Run{}).Where("id = ?", runID).Update("last_seen_at", at).Error; err != nil {
+		return dbError(err, "heartbeat_run", errors.PriorityLow,
+			"table", "runs",
+			"run_id", fmt.Sprintf("%d", runID))
+	}
+	return nil
+}
+
+// StopRun records a clean stop of a run with its reason
+func (ds *DataStore) StopRun(runID uint, reason, detail string, at time.Time) error {
+	if runID == 0 {
+		return validationError("run ID cannot be zero", "run_id", runID)
+	}
+	if err := ds.DB.Model(&Run{}).Where("id = ?", runID).Updates(map[string]any{
+		"stopped_at":   at,
+		"last_seen_at": at,
+		"stop_reason":  reason,
+		"stop_detail":  detail,
+	}).Error; err != nil {
+		return dbError(err, "stop_run", errors.PriorityMedium,
+			"table", "runs",
+			"run_id", fmt.Sprintf("%d", runID),
+			"reason", reason)
+	}
+	return nil
+}
+
+// GetRuns returns the most recent runs, newest first
+func (ds *DataStore) GetRuns(limit int) ([]Run, error) {
+	var runs []Run
+	query := ds.DB.Order("started_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, dbError(err, "get_runs", errors.PriorityLow,
+			"table", "runs")
+	}
+	return runs, nil
+}
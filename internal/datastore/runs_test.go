@@ -0,0 +1,57 @@
+// runs_test.go: Tests for the application run history
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLedger(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&Run{}))
+
+	var ledger RunLedger = ds
+	base := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	first, err := ledger.StartRun("1.0.0", base)
+	require.NoError(t, err)
+	assert.Equal(t, RunStartFirst, first.StartReason)
+	require.NoError(t, ledger.StopRun(first.ID, RunStopUserRequest, "interrupt", base.Add(time.Hour)))
+
+	second, err := ledger.StartRun("1.0.0", base.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, RunStartNormal, second.StartReason)
+	require.NoError(t, ledger.HeartbeatRun(second.ID, base.Add(3*time.Hour)))
+
+	// The second run is never stopped, so the next start detects a crash
+	third, err := ledger.StartRun("1.1.0", base.Add(5*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, RunStartCrashRecovery, third.StartReason, "crash takes precedence over upgrade")
+	require.NoError(t, ledger.StopRun(third.ID, RunStopUserRequest, "terminated", base.Add(6*time.Hour)))
+
+	fourth, err := ledger.StartRun("1.2.0", base.Add(7*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, RunStartUpgrade, fourth.StartReason)
+
+	runs, err := ledger.GetRuns(0)
+	require.NoError(t, err)
+	require.Len(t, runs, 4)
+	assert.Equal(t, fourth.ID, runs[0].ID, "newest run should be listed first")
+	assert.Nil(t, runs[0].StoppedAt)
+
+	crashed := runs[2]
+	assert.Equal(t, second.ID, crashed.ID)
+	assert.Equal(t, RunStopCrash, crashed.StopReason)
+	require.NotNil(t, crashed.StoppedAt)
+	assert.True(t, crashed.StoppedAt.Equal(base.Add(3*time.Hour)), "crash time should be the last heartbeat")
+
+	runs, err = ledger.GetRuns(2)
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	assert.Error(t, ledger.StopRun(0, RunStopError, "", base))
+	assert.Error(t, ledger.HeartbeatRun(0, base))
+}
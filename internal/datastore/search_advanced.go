@@ -22,6 +22,7 @@ type AdvancedSearchFilters struct {
 	Location       []string // Maps to source field
 	Locked         *bool
 	SortAscending  bool
+	SortBy         string // "rarity" sorts by rarity score, anything else by detection ID
 	Limit          int
 	Offset         int
 }
@@ -116,6 +117,10 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 	if filters.SortAscending {
 		order = "ASC"
 	}
+	if filters.SortBy == "rarity" {
+		// Unscored detections last in both directions
+		query = query.Order("rarity_score IS NULL").Order("rarity_score " + order)
+	}
 	query = query.Order("id " + order)
 
 	// Apply pagination
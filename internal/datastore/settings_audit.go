@@ -0,0 +1,53 @@
+// settings_audit.go: audit log of configuration changes
+package datastore
+
+import (
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// SettingsAuditLog records who changed which setting and when.
+type SettingsAuditLog interface {
+	SaveSettingsChanges(changes []SettingsChange) error
+	GetSettingsChanges(section string, limit, offset int) ([]SettingsChange, int64, error)
+}
+
+// SaveSettingsChanges stores the changes of one settings update in a single transaction
+func (ds *DataStore) SaveSettingsChanges(changes []SettingsChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := ds.DB.Create(&changes).Error; err != nil {
+		return dbError(err, "save_settings_changes", errors.PriorityLow,
+			"table", "settings_changes",
+			"count", len(changes))
+	}
+	return nil
+}
+
+// GetSettingsChanges returns recorded settings changes, newest first, with the total number
+// of matching changes. An empty section returns changes of all sections.
+func (ds *DataStore) GetSettingsChanges(section string, limit, offset int) ([]SettingsChange, int64, error) {
+	query := ds.DB.Model(&SettingsChange{})
+	if section != "" {
+		query = query.Where("section = ?", section)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, dbError(err, "count_settings_changes", errors.PriorityLow,
+			"table", "settings_changes",
+			"section", section)
+	}
+
+	var changes []SettingsChange
+	query = query.Order("changed_at DESC, id DESC").Offset(max(offset, 0))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&changes).Error; err != nil {
+		return nil, 0, dbError(err, "get_settings_changes", errors.PriorityLow,
+			"table", "settings_changes",
+			"section", section)
+	}
+	return changes, total, nil
+}
@@ -0,0 +1,40 @@
+// settings_audit_test.go: Tests for the settings audit log
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsAuditLog(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&SettingsChange{}))
+
+	var audit SettingsAuditLog = ds
+	base := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	require.NoError(t, audit.SaveSettingsChanges(nil))
+	require.NoError(t, audit.SaveSettingsChanges([]SettingsChange{
+		{ChangedAt: base, Actor: "admin", Source: "api", Section: "realtime", Path: "realtime.mqtt.broker", OldValue: `""`, NewValue: `"tcp://broker:1883"`},
+		{ChangedAt: base, Actor: "admin", Source: "api", Section: "realtime", Path: "realtime.mqtt.enabled", OldValue: "false", NewValue: "true"},
+	}))
+	require.NoError(t, audit.SaveSettingsChanges([]SettingsChange{
+		{ChangedAt: base.Add(time.Hour), Actor: "config file", Source: "config_reload", Section: "birdnet", Path: "birdnet.threshold", OldValue: "0.8", NewValue: "0.7"},
+	}))
+
+	changes, total, err := audit.GetSettingsChanges("", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "birdnet.threshold", changes[0].Path, "newest change should be listed first")
+
+	changes, total, err = audit.GetSettingsChanges("realtime", 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total, "total should not depend on paging")
+	require.Len(t, changes, 1)
+	assert.Equal(t, "realtime.mqtt.broker", changes[0].Path)
+	assert.Equal(t, "admin", changes[0].Actor)
+}
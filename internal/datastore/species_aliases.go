@@ -0,0 +1,193 @@
+// species_aliases.go: species names replaced by taxonomy updates linked to their current names
+package datastore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+// SpeciesAliasReport lists the notes recorded under the old name of an alias
+type SpeciesAliasReport struct {
+	OldScientificName string `json:"oldScientificName"`
+	ScientificName    string `json:"scientificName"`
+	Notes             int64  `json:"notes"`               // Notes with the old name, trashed notes included
+	FirstDate         string `json:"firstDate,omitempty"` // Date of the oldest note with the old name
+	LastDate          string `json:"lastDate,omitempty"`  // Date of the newest note with the old name
+}
+
+// SpeciesAliasStore maps species names replaced by taxonomy updates to their current names,
+// so queries, statistics and exports count old and new detections as one species.
+type SpeciesAliasStore interface {
+	GetSpeciesAliases() ([]SpeciesAlias, error)
+	SaveSpeciesAlias(alias *SpeciesAlias) error
+	DeleteSpeciesAlias(oldScientificName string) error
+	MergeSpeciesAliases(ctx context.Context, dryRun bool) ([]SpeciesAliasReport, error)
+}
+
+// GetSpeciesAliases returns the species aliases ordered by old scientific name
+func (ds *DataStore) GetSpeciesAliases() ([]SpeciesAlias, error) {
+	var aliases []SpeciesAlias
+	if err := ds.DB.Order("old_scientific_name").Find(&aliases).Error; err != nil {
+		return nil, dbError(err, "get_species_aliases", errors.PriorityLow,
+			"table", "species_aliases")
+	}
+	return aliases, nil
+}
+
+// SaveSpeciesAlias adds an alias or replaces the alias of the same old name. Aliases can't
+// chain: the current name of an alias must not be the old name of another alias.
+func (ds *DataStore) SaveSpeciesAlias(alias *SpeciesAlias) error {
+	alias.OldScientificName = strings.TrimSpace(alias.OldScientificName)
+	alias.ScientificName = strings.TrimSpace(alias.ScientificName)
+	alias.CommonName = strings.TrimSpace(alias.CommonName)
+	alias.SpeciesCode = strings.TrimSpace(alias.SpeciesCode)
+
+	switch {
+	case alias.OldScientificName == "":
+		return validationError("old scientific name is required", "old_scientific_name", alias.OldScientificName)
+	case alias.ScientificName == "":
+		return validationError("scientific name is required", "scientific_name", alias.ScientificName)
+	case strings.EqualFold(alias.OldScientificName, alias.ScientificName):
+		return validationError("alias must link two different names", "scientific_name", alias.ScientificName)
+	}
+
+	return ds.DB.Transaction(func(tx *gorm.DB) error {
+		var chained int64
+		if err := tx.Model(&SpeciesAlias{}).
+			Where("old_scientific_name = ? OR (scientific_name = ? AND old_scientific_name <> ?)",
+				alias.ScientificName, alias.OldScientificName, alias.OldScientificName).
+			Count(&chained).Error; err != nil {
+			return dbError(err, "check_species_alias_chain", errors.PriorityLow,
+				"table", "species_aliases")
+		}
+		if chained > 0 {
+			return validationError("aliases can't chain, link the old name to the newest name", "scientific_name", alias.ScientificName)
+		}
+
+		var existing SpeciesAlias
+		err := tx.Where("old_scientific_name = ?", alias.OldScientificName).Limit(1).Find(&existing).Error
+		if err != nil {
+			return dbError(err, "get_species_alias", errors.PriorityLow,
+				"table", "species_aliases")
+		}
+		if existing.ID != 0 {
+			alias.ID = existing.ID
+			alias.CreatedAt = existing.CreatedAt
+		}
+		if err := tx.Save(alias).Error; err != nil {
+			return dbError(err, "save_species_alias", errors.PriorityMedium,
+				"table", "species_aliases",
+				"old_scientific_name", alias.OldScientificName)
+		}
+		return nil
+	})
+}
+
+// DeleteSpeciesAlias removes the alias of an old scientific name. Notes already merged keep
+// the current name.
+func (ds *DataStore) DeleteSpeciesAlias(oldScientificName string) error {
+	result := ds.DB.Where("old_scientific_name = ?", oldScientificName).Delete(&SpeciesAlias{})
+	if result.Error != nil {
+		return dbError(result.Error, "delete_species_alias", errors.PriorityMedium,
+			"table", "species_aliases",
+			"old_scientific_name", oldScientificName)
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("species alias", oldScientificName)
+	}
+	return nil
+}
+
+// MergeSpeciesAliases moves the notes recorded under the old names of the aliases to the
+// current names, trashed notes included, and reports the notes affected per alias. With
+// dryRun only the report is computed.
+func (ds *DataStore) MergeSpeciesAliases(ctx context.Context, dryRun bool) ([]SpeciesAliasReport, error) {
+	aliases, err := ds.GetSpeciesAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]SpeciesAliasReport, 0, len(aliases))
+	for i := range aliases {
+		alias := &aliases[i]
+		report := SpeciesAliasReport{
+			OldScientificName: alias.OldScientificName,
+			ScientificName:    alias.ScientificName,
+		}
+
+		var span struct {
+			Notes     int64
+			FirstDate *string
+			LastDate  *string
+		}
+		if err := ds.DB.WithContext(ctx).Unscoped().Model(&Note{}).
+			Select("COUNT(*) AS notes, MIN(date) AS first_date, MAX(date) AS last_date").
+			Where("scientific_name = ?", alias.OldScientificName).
+			Scan(&span).Error; err != nil {
+			return nil, dbError(err, "report_species_alias", errors.PriorityLow,
+				"table", "notes",
+				"old_scientific_name", alias.OldScientificName)
+		}
+		report.Notes = span.Notes
+		if span.FirstDate != nil {
+			report.FirstDate = *span.FirstDate
+		}
+		if span.LastDate != nil {
+			report.LastDate = *span.LastDate
+		}
+		reports = append(reports, report)
+
+		if dryRun || report.Notes == 0 {
+			continue
+		}
+		if err := ds.DB.WithContext(ctx).Unscoped().Model(&Note{}).
+			Where("scientific_name = ?", alias.OldScientificName).
+			Updates(alias.noteUpdates()).Error; err != nil {
+			return reports, dbError(err, "merge_species_alias", errors.PriorityMedium,
+				"table", "notes",
+				"old_scientific_name", alias.OldScientificName)
+		}
+	}
+	return reports, nil
+}
+
+// noteUpdates returns the columns a note with the old name is updated with
+func (a *SpeciesAlias) noteUpdates() map[string]any {
+	updates := map[string]any{"scientific_name": a.ScientificName}
+	if a.CommonName != "" {
+		updates["common_name"] = a.CommonName
+	}
+	if a.SpeciesCode != "" {
+		updates["species_code"] = a.SpeciesCode
+	}
+	return updates
+}
+
+// resolveSpeciesAlias stores a note detected under an old species name with the current name
+func resolveSpeciesAlias(tx *gorm.DB, note *Note) error {
+	if note.ScientificName == "" || !tx.Migrator().HasTable(&SpeciesAlias{}) {
+		return nil
+	}
+
+	var aliases []SpeciesAlias
+	if err := tx.Where("old_scientific_name = ?", note.ScientificName).Limit(1).Find(&aliases).Error; err != nil {
+		return dbError(err, "resolve_species_alias", errors.PriorityMedium,
+			"table", "species_aliases")
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	alias := &aliases[0]
+	note.ScientificName = alias.ScientificName
+	if alias.CommonName != "" {
+		note.CommonName = alias.CommonName
+	}
+	if alias.SpeciesCode != "" {
+		note.SpeciesCode = alias.SpeciesCode
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// species_aliases_test.go: Tests for linking renamed species to their current names
+package datastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveSpeciesAlias(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&SpeciesAlias{}))
+
+	var store SpeciesAliasStore = ds
+	require.NoError(t, store.SaveSpeciesAlias(&SpeciesAlias{
+		OldScientificName: " Cyanocitta cristata ",
+		ScientificName:    "Cyanocitta cristatus",
+		Reason:            "rename",
+	}))
+	require.NoError(t, store.SaveSpeciesAlias(&SpeciesAlias{
+		OldScientificName: "Cyanocitta cristata",
+		ScientificName:    "Cyanocitta cristatus",
+		CommonName:        "Blue Jay",
+	}), "saving the same old name replaces the alias")
+
+	aliases, err := store.GetSpeciesAliases()
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+	assert.Equal(t, "Cyanocitta cristata", aliases[0].OldScientificName)
+	assert.Equal(t, "Blue Jay", aliases[0].CommonName)
+
+	assert.Error(t, store.SaveSpeciesAlias(&SpeciesAlias{OldScientificName: "Turdus migratorius", ScientificName: "Turdus migratorius"}),
+		"an alias must link two different names")
+	assert.Error(t, store.SaveSpeciesAlias(&SpeciesAlias{OldScientificName: "Cyanocitta cristatus", ScientificName: "Cyanocitta nova"}),
+		"the current name of an alias can't be aliased")
+	assert.Error(t, store.SaveSpeciesAlias(&SpeciesAlias{OldScientificName: "Garrulus cristatus", ScientificName: "Cyanocitta cristata"}),
+		"the old name of an alias can't be a current name")
+
+	require.NoError(t, store.DeleteSpeciesAlias("Cyanocitta cristata"))
+	assert.Error(t, store.DeleteSpeciesAlias("Cyanocitta cristata"))
+}
+
+func TestMergeSpeciesAliases(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&Results{}, &NoteLock{}, &NoteComment{}, &SpeciesAlias{}))
+	seedTestData(t, ds)
+	require.NoError(t, ds.Delete("4"), "trashed notes are merged too")
+
+	require.NoError(t, ds.SaveSpeciesAlias(&SpeciesAlias{
+		OldScientificName: "Cyanocitta cristata",
+		ScientificName:    "Cyanocitta cristatus",
+		CommonName:        "Crested Jay",
+		SpeciesCode:       "crejay",
+	}))
+
+	reports, err := ds.MergeSpeciesAliases(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, int64(2), reports[0].Notes)
+	assert.Equal(t, "2024-01-16", reports[0].FirstDate)
+
+	note, err := ds.Get("3")
+	require.NoError(t, err)
+	assert.Equal(t, "Cyanocitta cristata", note.ScientificName, "dry run leaves the notes unchanged")
+
+	reports, err = ds.MergeSpeciesAliases(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), reports[0].Notes)
+
+	note, err = ds.Get("3")
+	require.NoError(t, err)
+	assert.Equal(t, "Cyanocitta cristatus", note.ScientificName)
+	assert.Equal(t, "Crested Jay", note.CommonName)
+	assert.Equal(t, "crejay", note.SpeciesCode)
+
+	var remaining int64
+	require.NoError(t, ds.DB.Unscoped().Model(&Note{}).Where("scientific_name = ?", "Cyanocitta cristata").Count(&remaining).Error)
+	assert.Zero(t, remaining)
+
+	reports, err = ds.MergeSpeciesAliases(context.Background(), true)
+	require.NoError(t, err)
+	assert.Zero(t, reports[0].Notes, "merged notes are no longer reported")
+
+	// New detections with the old name are stored under the current name
+	detection := &Note{Date: "2024-02-01", Time: "07:00:00", ScientificName: "Cyanocitta cristata", CommonName: "Blue Jay", Confidence: 0.8}
+	require.NoError(t, ds.Save(detection, nil))
+	assert.Equal(t, "Cyanocitta cristatus", detection.ScientificName)
+	assert.Equal(t, "Crested Jay", detection.CommonName)
+}
@@ -0,0 +1,291 @@
+// spool.go: bounded on-disk buffer of detections waiting for the database
+package datastore
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Spool file names in the spool directory
+const (
+	spoolPendingFile  = "pending.jsonl"  // detections waiting to be saved, oldest first
+	spoolRejectedFile = "rejected.jsonl" // detections the database refused while reachable
+)
+
+// spoolMaxLineSize is the largest spooled detection read back, far above a note with results
+const spoolMaxLineSize = 4 << 20
+
+// ErrSpoolFull is returned by Spool.Add when the spool holds its limit of detections
+var ErrSpoolFull = errors.NewStd("detection spool is full")
+
+// unavailablePattern matches messages of database errors without a typed error, such as
+// MySQL server errors, that mean the database is unreachable or busy
+var unavailablePattern = regexp.MustCompile(`(?i)(database is locked|database table is locked|resource busy|` +
+	`connection refused|connection reset|broken pipe|bad connection|invalid connection|` +
+	`server has gone away|lost connection|too many connections|unable to open database file|disk i/o error)`)
+
+// IsUnavailableError reports whether err means the database is unreachable or busy, so the
+// same save may succeed later. Errors of a reachable database refusing the data, such as
+// constraint violations, are not unavailability.
+func IsUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code {
+		case sqlite3.ErrBusy, sqlite3.ErrLocked, sqlite3.ErrIoErr, sqlite3.ErrCantOpen:
+			return true
+		default:
+			return false
+		}
+	}
+
+	return unavailablePattern.MatchString(err.Error())
+}
+
+// SpooledNote is a detection that could not be saved, with everything needed to save it later
+// and to publish its detection events once saved
+type SpooledNote struct {
+	Note      Note         `json:"note"`
+	Results   []Results    `json:"results"`
+	Sources   []NoteSource `json:"sources,omitempty"`
+	SpooledAt time.Time    `json:"spooledAt"`
+
+	// New species state of the detection when it was made, the species tracker has moved on
+	// by the time the detection is saved
+	IsNewSpecies       bool `json:"isNewSpecies,omitempty"`
+	DaysSinceFirstSeen int  `json:"daysSinceFirstSeen,omitempty"`
+
+	// NoteIDToken resolves the provisional ID of the live broadcast once the detection is saved,
+	// see ResolveBufferedNoteID
+	NoteIDToken string `json:"noteIdToken,omitempty"`
+}
+
+// Spool buffers detections on disk while the database is unavailable, one JSON line per
+// detection, so they survive a restart and are saved in order once the database recovers.
+// It is safe for concurrent use.
+type Spool struct {
+	dir   string
+	limit int
+
+	mu    sync.Mutex // protects the pending file and count
+	count int
+
+	replayMu sync.Mutex // serializes Replay
+}
+
+// OpenSpool opens the spool in dir holding at most limit detections, creating dir if needed.
+// Detections spooled by a previous run are kept for replay.
+func OpenSpool(dir string, limit int) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryFileIO).
+			Context("operation", "open_spool").
+			Context("path", dir).
+			Build()
+	}
+
+	s := &Spool{dir: dir, limit: max(limit, 1)}
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	s.count = len(entries)
+	return s, nil
+}
+
+// Len returns the number of spooled detections
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Add appends a detection to the spool. It returns ErrSpoolFull when the spool is full.
+func (s *Spool) Add(entry *SpooledNote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count >= s.limit {
+		return ErrSpoolFull
+	}
+	if err := s.appendTo(spoolPendingFile, entry); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// Reject keeps a detection the database refused in the rejected file for manual recovery
+func (s *Spool) Reject(entry *SpooledNote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendTo(spoolRejectedFile, entry)
+}
+
+// Replay saves the spooled detections in order with save, removing those saved. It stops at
+// the first error, keeping that detection and the later ones, and returns the number saved.
+// Detections added while replaying are kept for the next replay.
+func (s *Spool) Replay(save func(entry *SpooledNote) error) (int, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	s.mu.Lock()
+	entries, err := s.read()
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	// Save without holding mu, so detections can be spooled while the database is slow
+	saved := 0
+	var saveErr error
+	for i := range entries {
+		if saveErr = save(&entries[i]); saveErr != nil {
+			break
+		}
+		saved++
+	}
+	if saved == 0 {
+		return 0, saveErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.read()
+	if err != nil {
+		return saved, err
+	}
+	// Only Replay removes detections, so the saved ones are still the oldest
+	remaining := current[min(saved, len(current)):]
+	if err := s.rewrite(remaining); err != nil {
+		return saved, err
+	}
+	s.count = len(remaining)
+	return saved, saveErr
+}
+
+// appendTo appends an entry to a spool file and syncs it to disk. The caller must hold mu.
+func (s *Spool) appendTo(name string, entry *SpooledNote) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "encode_spooled_note").
+			Build()
+	}
+
+	path := filepath.Join(s.dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // G304: path within the configured spool directory
+	if err != nil {
+		return spoolFileError(err, "open_spool_file", path)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return spoolFileError(err, "write_spool_file", path)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return spoolFileError(err, "sync_spool_file", path)
+	}
+	if err := f.Close(); err != nil {
+		return spoolFileError(err, "close_spool_file", path)
+	}
+	return nil
+}
+
+// read returns the pending detections. Lines that cannot be decoded, such as one cut short
+// by a crash, are skipped. The caller must hold mu.
+func (s *Spool) read() ([]SpooledNote, error) {
+	path := filepath.Join(s.dir, spoolPendingFile)
+	f, err := os.Open(path) //nolint:gosec // G304: path within the configured spool directory
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, spoolFileError(err, "open_spool_file", path)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []SpooledNote
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), spoolMaxLineSize)
+	for scanner.Scan() {
+		var entry SpooledNote
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			getLogger().Warn("Skipping unreadable spooled detection",
+				"path", path,
+				"error", err,
+				"operation", "read_spool")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, spoolFileError(err, "read_spool_file", path)
+	}
+	return entries, nil
+}
+
+// rewrite replaces the pending detections with entries, removing the file when empty. The
+// caller must hold mu.
+func (s *Spool) rewrite(entries []SpooledNote) error {
+	path := filepath.Join(s.dir, spoolPendingFile)
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return spoolFileError(err, "remove_spool_file", path)
+		}
+		return nil
+	}
+
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	for i := range entries {
+		if err := s.appendTo(spoolPendingFile+".tmp", &entries[i]); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return spoolFileError(err, "replace_spool_file", path)
+	}
+	return nil
+}
+
+// spoolFileError wraps a spool file error
+func spoolFileError(err error, operation, path string) error {
+	return errors.New(err).
+		Component("datastore").
+		Category(errors.CategoryFileIO).
+		Context("operation", operation).
+		Context("path", path).
+		Build()
+}
@@ -0,0 +1,145 @@
+// spool_test.go: Tests for the on-disk detection spool
+package datastore
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+func spooledNote(name string) *SpooledNote {
+	return &SpooledNote{
+		Note:      Note{CommonName: name, ScientificName: name + " sp.", Date: "2026-05-01", Time: "06:00:00", Confidence: 0.9},
+		Results:   []Results{{Species: name, Confidence: 0.9}},
+		Sources:   []NoteSource{{SourceID: "rtsp_1", IsPrimary: true}},
+		SpooledAt: time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 3)
+	require.NoError(t, err)
+	assert.Zero(t, spool.Len())
+
+	for _, name := range []string{"Robin", "Wren", "Blackbird"} {
+		require.NoError(t, spool.Add(spooledNote(name)))
+	}
+	require.ErrorIs(t, spool.Add(spooledNote("Jay")), ErrSpoolFull)
+	assert.Equal(t, 3, spool.Len())
+
+	// Spooled detections survive a restart
+	spool, err = OpenSpool(dir, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, spool.Len())
+
+	// Replay stops at the first failure and keeps the rest in order
+	var saved []string
+	failure := errors.NewStd("database is locked")
+	n, err := spool.Replay(func(entry *SpooledNote) error {
+		if entry.Note.CommonName == "Wren" {
+			return failure
+		}
+		saved = append(saved, entry.Note.CommonName)
+		assert.Equal(t, "rtsp_1", entry.Sources[0].SourceID)
+		assert.Len(t, entry.Results, 1)
+		return nil
+	})
+	require.ErrorIs(t, err, failure)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"Robin"}, saved)
+	assert.Equal(t, 2, spool.Len())
+
+	// Detections spooled during a replay are kept for the next one
+	n, err = spool.Replay(func(entry *SpooledNote) error {
+		if entry.Note.CommonName == "Wren" {
+			require.NoError(t, spool.Add(spooledNote("Jay")))
+		}
+		saved = append(saved, entry.Note.CommonName)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, spool.Len())
+
+	n, err = spool.Replay(func(entry *SpooledNote) error {
+		saved = append(saved, entry.Note.CommonName)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"Robin", "Wren", "Blackbird", "Jay"}, saved)
+	assert.Zero(t, spool.Len())
+	assert.NoFileExists(t, filepath.Join(dir, spoolPendingFile), "empty spool removes its file")
+}
+
+func TestSpool_SkipsUnreadableLines(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 10)
+	require.NoError(t, err)
+	require.NoError(t, spool.Add(spooledNote("Robin")))
+
+	// A line cut short by a crash
+	f, err := os.OpenFile(filepath.Join(dir, spoolPendingFile), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"note":{"CommonName":"Wr` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	spool, err = OpenSpool(dir, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, spool.Len())
+}
+
+func TestSpool_Reject(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, spool.Reject(spooledNote("Robin")))
+	assert.Zero(t, spool.Len(), "rejected detections are not replayed")
+	data, err := os.ReadFile(filepath.Join(dir, spoolRejectedFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Robin")
+}
+
+func TestIsUnavailableError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad connection", driver.ErrBadConn, true},
+		{"wrapped bad connection", fmt.Errorf("save note: %w", driver.ErrBadConn), true},
+		{"enhanced error", errors.New(syscall.ECONNREFUSED).Component("datastore").Build(), true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"sqlite busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"sqlite constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"mysql server gone", errors.NewStd("Error 2006: MySQL server has gone away"), true},
+		{"locked message", errors.NewStd("database is locked"), true},
+		{"constraint message", errors.NewStd("UNIQUE constraint failed: notes.id"), false},
+		{"validation", errors.NewStd("invalid confidence"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, IsUnavailableError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// trash.go: deleted detections kept in the trash until they are restored or purged
+package datastore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+// trashPurgeBatchSize is the number of trashed notes purged per transaction
+const trashPurgeBatchSize = 500
+
+// TrashStore lists, restores and purges the notes moved to the trash by Delete. Trashed
+// notes are hidden from queries, exports and statistics.
+type TrashStore interface {
+	GetTrashedNotes(limit, offset int) ([]Note, int64, error)
+	RestoreNote(id string) error
+	PurgeTrash(before time.Time) ([]Note, error)
+}
+
+// trashedNotes returns a query of the notes in the trash
+func (ds *DataStore) trashedNotes() *gorm.DB {
+	return ds.DB.Unscoped().Model(&Note{}).Where("deleted_at IS NOT NULL")
+}
+
+// GetTrashedNotes returns a page of the trashed notes, most recently deleted first, and the
+// total number of trashed notes
+func (ds *DataStore) GetTrashedNotes(limit, offset int) ([]Note, int64, error) {
+	var total int64
+	if err := ds.trashedNotes().Count(&total).Error; err != nil {
+		return nil, 0, dbError(err, "count_trashed_notes", errors.PriorityLow,
+			"table", "notes")
+	}
+
+	var notes []Note
+	if err := ds.trashedNotes().
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&notes).Error; err != nil {
+		return nil, 0, dbError(err, "get_trashed_notes", errors.PriorityLow,
+			"table", "notes")
+	}
+	return notes, total, nil
+}
+
+// RestoreNote moves a note out of the trash
+func (ds *DataStore) RestoreNote(id string) error {
+	result := ds.trashedNotes().Where("id = ?", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return dbError(result.Error, "restore_note", errors.PriorityMedium,
+			"note_id", id,
+			"table", "notes")
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("trashed note", id)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes the notes trashed before the given time with their
+// results, and returns the purged notes so their clips can be removed
+func (ds *DataStore) PurgeTrash(before time.Time) ([]Note, error) {
+	var purged []Note
+	for {
+		var batch []Note
+		if err := ds.trashedNotes().
+			Select("id", "scientific_name", "clip_name", "deleted_at").
+			Where("deleted_at < ?", before).
+			Order("id").
+			Limit(trashPurgeBatchSize).
+			Find(&batch).Error; err != nil {
+			return purged, dbError(err, "get_expired_trash", errors.PriorityMedium,
+				"table", "notes")
+		}
+		if len(batch) == 0 {
+			return purged, nil
+		}
+
+		ids := make([]uint, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].ID
+		}
+		if err := ds.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("note_id IN ?", ids).Delete(&Results{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&Note{}, ids).Error
+		}); err != nil {
+			return purged, dbError(err, "purge_trash", errors.PriorityMedium,
+				"table", "notes",
+				"batch_size", fmt.Sprintf("%d", len(ids)))
+		}
+		purged = append(purged, batch...)
+
+		if len(batch) < trashPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// trash_test.go: Tests for trashing, restoring and purging detections
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteMovesNoteToTrash(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&Results{}, &NoteLock{}, &NoteComment{}))
+	seedTestData(t, ds)
+	require.NoError(t, ds.DB.Create(&Results{NoteID: 5, Species: "Cardinalis cardinalis", Confidence: 0.95}).Error)
+
+	var store TrashStore = ds
+	require.NoError(t, ds.Delete("5"))
+
+	_, err := ds.Get("5")
+	require.Error(t, err, "trashed notes are not found")
+
+	notes, err := ds.GetAllNotes()
+	require.NoError(t, err)
+	assert.Len(t, notes, 4)
+
+	summaries, err := ds.GetSpeciesSummaryData("", "")
+	require.NoError(t, err)
+	assert.Nil(t, findSpeciesByScientificName(summaries, "Cardinalis cardinalis"), "statistics exclude trashed notes")
+
+	trashed, total, err := store.GetTrashedNotes(10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, uint(5), trashed[0].ID)
+	assert.True(t, trashed[0].DeletedAt.Valid)
+
+	var results int64
+	require.NoError(t, ds.DB.Model(&Results{}).Where("note_id = ?", 5).Count(&results).Error)
+	assert.Equal(t, int64(1), results, "results are kept while the note is in the trash")
+
+	require.NoError(t, store.RestoreNote("5"))
+	note, err := ds.Get("5")
+	require.NoError(t, err)
+	assert.Equal(t, "Northern Cardinal", note.CommonName)
+
+	assert.Error(t, store.RestoreNote("5"), "only trashed notes can be restored")
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&Results{}, &NoteLock{}, &NoteComment{}))
+	seedTestData(t, ds)
+	require.NoError(t, ds.DB.Model(&Note{}).Where("id = ?", 3).Update("clip_name", "2024/01/blue_jay.wav").Error)
+	require.NoError(t, ds.DB.Create(&Results{NoteID: 3, Species: "Cyanocitta cristata", Confidence: 0.75}).Error)
+
+	require.NoError(t, ds.Delete("3"))
+	require.NoError(t, ds.Delete("4"))
+	deletedAt := time.Now()
+	require.NoError(t, ds.DB.Unscoped().Model(&Note{}).Where("id = ?", 4).Update("deleted_at", deletedAt.Add(time.Hour)).Error)
+
+	purged, err := ds.PurgeTrash(deletedAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, purged, 1, "notes trashed after the cutoff are kept")
+	assert.Equal(t, uint(3), purged[0].ID)
+	assert.Equal(t, "2024/01/blue_jay.wav", purged[0].ClipName)
+
+	var notes, results int64
+	require.NoError(t, ds.DB.Unscoped().Model(&Note{}).Where("id = ?", 3).Count(&notes).Error)
+	require.NoError(t, ds.DB.Model(&Results{}).Where("note_id = ?", 3).Count(&results).Error)
+	assert.Zero(t, notes, "purged notes are deleted")
+	assert.Zero(t, results, "results of purged notes are deleted")
+
+	_, total, err := ds.GetTrashedNotes(10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+}
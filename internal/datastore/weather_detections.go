@@ -0,0 +1,224 @@
+// weather_detections.go: weather readings attached to detections and detections-by-weather analytics
+package datastore
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// WeatherDetectionStore looks up weather readings for detections and aggregates detections
+// by the weather they were heard in.
+type WeatherDetectionStore interface {
+	NearestHourlyWeather(t time.Time, window time.Duration) (*HourlyWeather, error)
+	GetDetectionsByWeather(startDate, endDate string) (*WeatherDetectionSummary, error)
+}
+
+// WeatherDetectionBucket counts detections heard in a range of weather conditions
+type WeatherDetectionBucket struct {
+	Label      string   `json:"label"`
+	Min        *float64 `json:"min,omitempty"` // inclusive lower bound, nil for open-ended
+	Max        *float64 `json:"max,omitempty"` // exclusive upper bound, nil for open-ended
+	Detections int64    `json:"detections"`
+	Species    int      `json:"species"` // distinct species heard in the bucket
+}
+
+// WeatherDetectionSummary groups detections with weather data by temperature, wind speed and precipitation
+type WeatherDetectionSummary struct {
+	StartDate     string                   `json:"start_date"`
+	EndDate       string                   `json:"end_date"`
+	Detections    int64                    `json:"detections"` // detections with weather data
+	Temperature   []WeatherDetectionBucket `json:"temperature"`
+	WindSpeed     []WeatherDetectionBucket `json:"wind_speed"`
+	Precipitation []WeatherDetectionBucket `json:"precipitation"`
+}
+
+// temperatureBucketSize is the width of temperature buckets in °C
+const temperatureBucketSize = 5.0
+
+// weatherRange is a labelled range of a weather measurement, max is exclusive
+type weatherRange struct {
+	label    string
+	min, max float64
+}
+
+// Wind speed buckets in m/s, roughly following the Beaufort scale
+var windSpeedBuckets = []weatherRange{
+	{"calm", 0, 1.5},
+	{"light", 1.5, 5.5},
+	{"moderate", 5.5, 10.8},
+	{"strong", 10.8, math.Inf(1)},
+}
+
+// Precipitation buckets in mm per hour
+var precipitationBuckets = []weatherRange{
+	{"none", 0, 0.1},
+	{"light", 0.1, 2.5},
+	{"moderate", 2.5, 7.6},
+	{"heavy", 7.6, math.Inf(1)},
+}
+
+// NearestHourlyWeather returns the weather reading closest in time to t, considering only
+// readings within the window before or after t. It returns nil without error if none exists.
+func (ds *DataStore) NearestHourlyWeather(t time.Time, window time.Duration) (*HourlyWeather, error) {
+	var before, after []HourlyWeather
+
+	if err := ds.DB.Where("time <= ? AND time >= ?", t, t.Add(-window)).
+		Order("time DESC").Limit(1).Find(&before).Error; err != nil {
+		return nil, dbError(err, "get_nearest_weather", errors.PriorityLow,
+			"table", "hourly_weathers")
+	}
+	if err := ds.DB.Where("time > ? AND time <= ?", t, t.Add(window)).
+		Order("time ASC").Limit(1).Find(&after).Error; err != nil {
+		return nil, dbError(err, "get_nearest_weather", errors.PriorityLow,
+			"table", "hourly_weathers")
+	}
+
+	switch {
+	case len(before) == 0 && len(after) == 0:
+		return nil, nil
+	case len(after) == 0:
+		return &before[0], nil
+	case len(before) == 0:
+		return &after[0], nil
+	case t.Sub(before[0].Time) <= after[0].Time.Sub(t):
+		return &before[0], nil
+	default:
+		return &after[0], nil
+	}
+}
+
+// weatherDetectionRow is a detection with the weather recorded for it
+type weatherDetectionRow struct {
+	ScientificName       string
+	WeatherTemperature   float64
+	WeatherWindSpeed     *float64
+	WeatherPrecipitation *float64
+}
+
+// GetDetectionsByWeather counts detections between two dates (inclusive, YYYY-MM-DD) by the
+// temperature, wind speed and precipitation recorded for them. Detections without weather
+// data are not included.
+func (ds *DataStore) GetDetectionsByWeather(startDate, endDate string) (*WeatherDetectionSummary, error) {
+	if startDate == "" || endDate == "" {
+		return nil, validationError("start and end date are required", "date_range", fmt.Sprintf("%s..%s", startDate, endDate))
+	}
+
+	rows, err := ds.DB.Model(&Note{}).
+		Select("scientific_name, weather_temperature, weather_wind_speed, weather_precipitation").
+		Where("date >= ? AND date <= ? AND weather_temperature IS NOT NULL", startDate, endDate).
+		Rows()
+	if err != nil {
+		return nil, dbError(err, "get_detections_by_weather", errors.PriorityLow,
+			"table", "notes",
+			"start_date", startDate,
+			"end_date", endDate)
+	}
+	defer func() { _ = rows.Close() }()
+
+	summary := &WeatherDetectionSummary{StartDate: startDate, EndDate: endDate}
+	temperature := newBucketCounter()
+	wind := newBucketCounter()
+	precipitation := newBucketCounter()
+
+	for rows.Next() {
+		var row weatherDetectionRow
+		if err := rows.Scan(&row.ScientificName, &row.WeatherTemperature, &row.WeatherWindSpeed, &row.WeatherPrecipitation); err != nil {
+			return nil, dbError(err, "scan_detections_by_weather", errors.PriorityLow,
+				"table", "notes")
+		}
+		summary.Detections++
+
+		low := math.Floor(row.WeatherTemperature/temperatureBucketSize) * temperatureBucketSize
+		temperature.add(low, row.ScientificName)
+
+		if row.WeatherWindSpeed != nil {
+			wind.add(float64(rangeBucketIndex(windSpeedBuckets, *row.WeatherWindSpeed)), row.ScientificName)
+		}
+		if row.WeatherPrecipitation != nil {
+			precipitation.add(float64(rangeBucketIndex(precipitationBuckets, *row.WeatherPrecipitation)), row.ScientificName)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "get_detections_by_weather", errors.PriorityLow,
+			"table", "notes")
+	}
+
+	for _, low := range temperature.sortedKeys() {
+		lower, upper := low, low+temperatureBucketSize
+		summary.Temperature = append(summary.Temperature, temperature.bucket(low,
+			fmt.Sprintf("%g..%g °C", lower, upper), &lower, &upper))
+	}
+	summary.WindSpeed = rangeBuckets(windSpeedBuckets, wind)
+	summary.Precipitation = rangeBuckets(precipitationBuckets, precipitation)
+
+	return summary, nil
+}
+
+// bucketCounter counts detections and distinct species per bucket key
+type bucketCounter struct {
+	detections map[float64]int64
+	species    map[float64]map[string]struct{}
+}
+
+func newBucketCounter() *bucketCounter {
+	return &bucketCounter{
+		detections: make(map[float64]int64),
+		species:    make(map[float64]map[string]struct{}),
+	}
+}
+
+func (c *bucketCounter) add(key float64, scientificName string) {
+	c.detections[key]++
+	if c.species[key] == nil {
+		c.species[key] = make(map[string]struct{})
+	}
+	c.species[key][scientificName] = struct{}{}
+}
+
+func (c *bucketCounter) sortedKeys() []float64 {
+	keys := make([]float64, 0, len(c.detections))
+	for key := range c.detections {
+		keys = append(keys, key)
+	}
+	sort.Float64s(keys)
+	return keys
+}
+
+func (c *bucketCounter) bucket(key float64, label string, lower, upper *float64) WeatherDetectionBucket {
+	return WeatherDetectionBucket{
+		Label:      label,
+		Min:        lower,
+		Max:        upper,
+		Detections: c.detections[key],
+		Species:    len(c.species[key]),
+	}
+}
+
+// rangeBucketIndex returns the index of the range containing value
+func rangeBucketIndex(ranges []weatherRange, value float64) int {
+	for i, r := range ranges {
+		if value < r.max {
+			return i
+		}
+	}
+	return len(ranges) - 1
+}
+
+// rangeBuckets converts counted fixed ranges to buckets, including empty ranges
+func rangeBuckets(ranges []weatherRange, counter *bucketCounter) []WeatherDetectionBucket {
+	buckets := make([]WeatherDetectionBucket, 0, len(ranges))
+	for i, r := range ranges {
+		lower := r.min
+		var upper *float64
+		if !math.IsInf(r.max, 1) {
+			upperValue := r.max
+			upper = &upperValue
+		}
+		buckets = append(buckets, counter.bucket(float64(i), r.label, &lower, upper))
+	}
+	return buckets
+}
@@ -0,0 +1,74 @@
+// weather_detections_test.go: Tests for weather readings attached to detections
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNearestHourlyWeather(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&HourlyWeather{}))
+
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, ds.DB.Create(&[]HourlyWeather{
+		{Time: base, Temperature: 15},
+		{Time: base.Add(time.Hour), Temperature: 17},
+	}).Error)
+
+	var store WeatherDetectionStore = ds
+
+	reading, err := store.NearestHourlyWeather(base.Add(20*time.Minute), time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, reading)
+	assert.InDelta(t, 15, reading.Temperature, 0.0001)
+
+	reading, err = store.NearestHourlyWeather(base.Add(50*time.Minute), time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, reading)
+	assert.InDelta(t, 17, reading.Temperature, 0.0001, "later reading is closer")
+
+	reading, err = store.NearestHourlyWeather(base.Add(5*time.Hour), time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, reading, "readings outside the window should not be returned")
+}
+
+func TestGetDetectionsByWeather(t *testing.T) {
+	ds := setupTestDB(t)
+
+	float := func(v float64) *float64 { return &v }
+	require.NoError(t, ds.DB.Create(&[]Note{
+		{Date: "2024-06-01", ScientificName: "Parus major", WeatherTemperature: float(12.5), WeatherWindSpeed: float(0.5), WeatherPrecipitation: float(0)},
+		{Date: "2024-06-01", ScientificName: "Parus major", WeatherTemperature: float(14.9), WeatherWindSpeed: float(3), WeatherPrecipitation: float(1.2)},
+		{Date: "2024-06-02", ScientificName: "Turdus merula", WeatherTemperature: float(-2), WeatherWindSpeed: float(12), WeatherPrecipitation: float(0)},
+		{Date: "2024-06-02", ScientificName: "Turdus merula"},                                   // no weather data
+		{Date: "2024-06-05", ScientificName: "Erithacus rubecula", WeatherTemperature: float(20)}, // outside range
+	}).Error)
+
+	summary, err := ds.GetDetectionsByWeather("2024-06-01", "2024-06-02")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), summary.Detections)
+
+	require.Len(t, summary.Temperature, 2)
+	assert.Equal(t, "-5..0 °C", summary.Temperature[0].Label)
+	assert.Equal(t, int64(1), summary.Temperature[0].Detections)
+	assert.Equal(t, "10..15 °C", summary.Temperature[1].Label)
+	assert.Equal(t, int64(2), summary.Temperature[1].Detections)
+	assert.Equal(t, 1, summary.Temperature[1].Species)
+
+	require.Len(t, summary.WindSpeed, len(windSpeedBuckets))
+	assert.Equal(t, int64(1), summary.WindSpeed[0].Detections)
+	assert.Equal(t, int64(1), summary.WindSpeed[1].Detections)
+	assert.Equal(t, int64(1), summary.WindSpeed[3].Detections)
+	assert.Nil(t, summary.WindSpeed[3].Max, "last bucket is open-ended")
+
+	require.Len(t, summary.Precipitation, len(precipitationBuckets))
+	assert.Equal(t, int64(2), summary.Precipitation[0].Detections)
+	assert.Equal(t, int64(1), summary.Precipitation[1].Detections)
+
+	_, err = ds.GetDetectionsByWeather("", "2024-06-02")
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+// webpush.go: push subscriptions of browsers receiving Web Push notifications
+package datastore
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+// Sizes of the keys of a push subscription
+const (
+	webPushP256dhSize      = 65 // uncompressed P-256 point
+	webPushAuthSize        = 16
+	webPushMaxEndpointSize = 512
+)
+
+// WebPushStore stores the push subscriptions of Web Push notifications.
+type WebPushStore interface {
+	GetWebPushSubscriptions() ([]WebPushSubscription, error)
+	GetWebPushSubscription(id uint) (*WebPushSubscription, error)
+	SaveWebPushSubscription(sub *WebPushSubscription) error
+	DeleteWebPushSubscription(id uint) error
+	DeleteWebPushSubscriptions() (int64, error)
+	RecordWebPushDelivery(id uint, delivered bool) error
+}
+
+// SpeciesList returns the species filter of the subscription, empty for all species
+func (s *WebPushSubscription) SpeciesList() []string {
+	var species []string
+	for name := range strings.SplitSeq(s.Species, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			species = append(species, name)
+		}
+	}
+	return species
+}
+
+// SetSpeciesList sets the species filter of the subscription, empty for all species
+func (s *WebPushSubscription) SetSpeciesList(species []string) {
+	names := make([]string, 0, len(species))
+	for _, name := range species {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	s.Species = strings.Join(names, ",")
+}
+
+// GetWebPushSubscriptions returns the push subscriptions ordered by ID
+func (ds *DataStore) GetWebPushSubscriptions() ([]WebPushSubscription, error) {
+	var subscriptions []WebPushSubscription
+	if err := ds.DB.Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, dbError(err, "get_web_push_subscriptions", errors.PriorityLow,
+			"table", "web_push_subscriptions")
+	}
+	return subscriptions, nil
+}
+
+// GetWebPushSubscription returns a push subscription by ID
+func (ds *DataStore) GetWebPushSubscription(id uint) (*WebPushSubscription, error) {
+	var subscription WebPushSubscription
+	result := ds.DB.Where("id = ?", id).Limit(1).Find(&subscription)
+	if result.Error != nil {
+		return nil, dbError(result.Error, "get_web_push_subscription", errors.PriorityLow,
+			"table", "web_push_subscriptions")
+	}
+	if result.RowsAffected == 0 {
+		return nil, notFoundError("web push subscription", strconv.FormatUint(uint64(id), 10))
+	}
+	return &subscription, nil
+}
+
+// SaveWebPushSubscription adds a subscription or updates the subscription of the same
+// endpoint, so a browser subscribing again keeps one subscription
+func (ds *DataStore) SaveWebPushSubscription(sub *WebPushSubscription) error {
+	sub.Endpoint = strings.TrimSpace(sub.Endpoint)
+	sub.P256dh = strings.TrimRight(strings.TrimSpace(sub.P256dh), "=")
+	sub.Auth = strings.TrimRight(strings.TrimSpace(sub.Auth), "=")
+	sub.Name = strings.TrimSpace(sub.Name)
+	sub.SetSpeciesList(sub.SpeciesList())
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	switch {
+	case err != nil || endpoint.Scheme != "https" || endpoint.Host == "":
+		return validationError("endpoint must be an https URL", "endpoint", sub.Endpoint)
+	case len(sub.Endpoint) > webPushMaxEndpointSize:
+		return validationError("endpoint is too long", "endpoint", len(sub.Endpoint))
+	case !isBase64URL(sub.P256dh, webPushP256dhSize):
+		return validationError("p256dh must be a base64url encoded P-256 public key", "p256dh", sub.P256dh)
+	case !isBase64URL(sub.Auth, webPushAuthSize):
+		return validationError("auth must be a base64url encoded 16 byte secret", "auth", len(sub.Auth))
+	}
+
+	return ds.DB.Transaction(func(tx *gorm.DB) error {
+		var existing WebPushSubscription
+		if err := tx.Where("endpoint = ?", sub.Endpoint).Limit(1).Find(&existing).Error; err != nil {
+			return dbError(err, "get_web_push_subscription", errors.PriorityLow,
+				"table", "web_push_subscriptions")
+		}
+		if existing.ID != 0 {
+			sub.ID = existing.ID
+			sub.CreatedAt = existing.CreatedAt
+			sub.LastSentAt = existing.LastSentAt
+		}
+		if err := tx.Save(sub).Error; err != nil {
+			return dbError(err, "save_web_push_subscription", errors.PriorityMedium,
+				"table", "web_push_subscriptions")
+		}
+		return nil
+	})
+}
+
+// DeleteWebPushSubscription removes a push subscription
+func (ds *DataStore) DeleteWebPushSubscription(id uint) error {
+	result := ds.DB.Where("id = ?", id).Delete(&WebPushSubscription{})
+	if result.Error != nil {
+		return dbError(result.Error, "delete_web_push_subscription", errors.PriorityMedium,
+			"table", "web_push_subscriptions")
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("web push subscription", strconv.FormatUint(uint64(id), 10))
+	}
+	return nil
+}
+
+// DeleteWebPushSubscriptions removes all push subscriptions, e.g. after the VAPID keys
+// they were made with were replaced, and returns the number removed
+func (ds *DataStore) DeleteWebPushSubscriptions() (int64, error) {
+	result := ds.DB.Where("1 = 1").Delete(&WebPushSubscription{})
+	if result.Error != nil {
+		return 0, dbError(result.Error, "delete_web_push_subscriptions", errors.PriorityMedium,
+			"table", "web_push_subscriptions")
+	}
+	return result.RowsAffected, nil
+}
+
+// RecordWebPushDelivery records the outcome of a delivery: a delivered notification resets
+// the failure count, a failed one increments it
+func (ds *DataStore) RecordWebPushDelivery(id uint, delivered bool) error {
+	updates := map[string]any{"failures": gorm.Expr("failures + 1")}
+	if delivered {
+		updates = map[string]any{"failures": 0, "last_sent_at": time.Now()}
+	}
+	if err := ds.DB.Model(&WebPushSubscription{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return dbError(err, "record_web_push_delivery", errors.PriorityLow,
+			"table", "web_push_subscriptions")
+	}
+	return nil
+}
+
+// isBase64URL reports whether value is the unpadded base64url encoding of size bytes
+func isBase64URL(value string, size int) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	return err == nil && len(decoded) == size
+}
@@ -0,0 +1,89 @@
+// webpush_test.go: Tests for the push subscriptions of Web Push notifications
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Keys of a browser push subscription
+const (
+	testPushP256dh = "BHIxT1wVNP2HLwGt6x5tC3igIfar2bIFOEvtiDL0wNps8YcioRxd2_dRK0PqdXcbINY2GnfUxlbhi9yNDDLuX4c"
+	testPushAuth   = "vKzmwVNKb1WcZ1xmvGUOzA"
+)
+
+func TestSaveWebPushSubscription(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&WebPushSubscription{}))
+
+	var store WebPushStore = ds
+	sub := &WebPushSubscription{
+		Endpoint: "https://fcm.googleapis.com/fcm/send/abc123",
+		P256dh:   testPushP256dh,
+		Auth:     testPushAuth + "==",
+		Username: "alice",
+		Name:     " Pixel ",
+	}
+	sub.SetSpeciesList([]string{" Strix aluco ", "", "Eurasian Blackbird"})
+	require.NoError(t, store.SaveWebPushSubscription(sub))
+	assert.Equal(t, "Strix aluco,Eurasian Blackbird", sub.Species)
+	assert.Equal(t, testPushAuth, sub.Auth, "padding is removed")
+
+	again := &WebPushSubscription{
+		Endpoint: "https://fcm.googleapis.com/fcm/send/abc123",
+		P256dh:   testPushP256dh,
+		Auth:     testPushAuth,
+		Name:     "Pixel 8",
+	}
+	require.NoError(t, store.SaveWebPushSubscription(again), "subscribing again updates the subscription")
+	assert.Equal(t, sub.ID, again.ID)
+
+	subscriptions, err := store.GetWebPushSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, "Pixel 8", subscriptions[0].Name)
+	assert.Empty(t, subscriptions[0].SpeciesList())
+
+	invalid := []*WebPushSubscription{
+		{Endpoint: "http://push.example.org/1", P256dh: testPushP256dh, Auth: testPushAuth},
+		{Endpoint: "https://push.example.org/1", P256dh: testPushP256dh[:40], Auth: testPushAuth},
+		{Endpoint: "https://push.example.org/1", P256dh: testPushP256dh, Auth: "c2VjcmV0"},
+	}
+	for _, sub := range invalid {
+		assert.Error(t, store.SaveWebPushSubscription(sub), sub.Endpoint)
+	}
+}
+
+func TestWebPushDeliveries(t *testing.T) {
+	ds := setupTestDB(t)
+	require.NoError(t, ds.DB.AutoMigrate(&WebPushSubscription{}))
+
+	sub := &WebPushSubscription{Endpoint: "https://updates.push.services.mozilla.com/wpush/v2/abc", P256dh: testPushP256dh, Auth: testPushAuth}
+	require.NoError(t, ds.SaveWebPushSubscription(sub))
+
+	require.NoError(t, ds.RecordWebPushDelivery(sub.ID, false))
+	require.NoError(t, ds.RecordWebPushDelivery(sub.ID, false))
+	stored, err := ds.GetWebPushSubscription(sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Failures)
+	assert.Nil(t, stored.LastSentAt)
+
+	require.NoError(t, ds.RecordWebPushDelivery(sub.ID, true))
+	stored, err = ds.GetWebPushSubscription(sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored.Failures)
+	assert.NotNil(t, stored.LastSentAt)
+
+	require.NoError(t, ds.DeleteWebPushSubscription(sub.ID))
+	assert.Error(t, ds.DeleteWebPushSubscription(sub.ID))
+	_, err = ds.GetWebPushSubscription(sub.ID)
+	assert.Error(t, err)
+
+	require.NoError(t, ds.SaveWebPushSubscription(&WebPushSubscription{Endpoint: "https://push.example.org/1", P256dh: testPushP256dh, Auth: testPushAuth}))
+	require.NoError(t, ds.SaveWebPushSubscription(&WebPushSubscription{Endpoint: "https://push.example.org/2", P256dh: testPushP256dh, Auth: testPushAuth}))
+	deleted, err := ds.DeleteWebPushSubscriptions()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+}
@@ -0,0 +1,185 @@
+// Package debugcapture enables debug logging and debug file dumps for a single component
+// for a limited time. Sessions revert automatically when they expire, so debug output is
+// never left enabled by accident and cannot fill the disk.
+package debugcapture
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Components supporting debug capture
+const (
+	ComponentBirdWeather = "birdweather"
+	ComponentMyAudio     = "myaudio"
+	ComponentProcessor   = "processor"
+)
+
+// MaxDuration is the longest a debug capture session may run
+const MaxDuration = 2 * time.Hour
+
+// Session is an active debug capture session
+type Session struct {
+	Component string    `json:"component"`
+	StartedAt time.Time `json:"startedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// component holds the log level controls and session state of a registered component
+type component struct {
+	levels  []*slog.LevelVar
+	saved   []slog.Level
+	session *Session
+	timer   *time.Timer
+}
+
+var (
+	mu         sync.RWMutex
+	components = make(map[string]*component)
+)
+
+// Register adds log level controls for a component. The levels are raised to debug while
+// a session is active and restored afterwards. Register may be called more than once for
+// the same component, e.g. from packages with several loggers.
+func Register(name string, levels ...*slog.LevelVar) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, exists := components[name]
+	if !exists {
+		c = &component{}
+		components[name] = c
+	}
+	c.levels = append(c.levels, levels...)
+}
+
+// Components returns the names of registered components in sorted order
+func Components() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Enable starts a debug capture session for the component that ends after d. Enabling a
+// component with an active session extends it to end d from now.
+func Enable(name string, d time.Duration) (Session, error) {
+	if d <= 0 || d > MaxDuration {
+		return Session{}, errors.Newf("debug capture duration must be between 1s and %v, got %v", MaxDuration, d).
+			Component("debugcapture").
+			Category(errors.CategoryValidation).
+			Context("component", name).
+			Build()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, exists := components[name]
+	if !exists {
+		return Session{}, errors.Newf("unknown debug capture component %q", name).
+			Component("debugcapture").
+			Category(errors.CategoryNotFound).
+			Context("component", name).
+			Build()
+	}
+
+	now := time.Now()
+	if c.session == nil {
+		c.saved = make([]slog.Level, len(c.levels))
+		for i, level := range c.levels {
+			c.saved[i] = level.Level()
+			level.Set(slog.LevelDebug)
+		}
+		c.session = &Session{Component: name, StartedAt: now}
+	} else {
+		c.timer.Stop()
+	}
+
+	session := c.session
+	session.ExpiresAt = now.Add(d)
+	c.timer = time.AfterFunc(d, func() { expire(name, session) })
+
+	slog.Default().Info("Debug capture enabled",
+		"component", name,
+		"expires_at", session.ExpiresAt.Format(time.RFC3339))
+	return *session, nil
+}
+
+// Disable ends the active session of the component. It returns false if no session is active.
+func Disable(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, exists := components[name]
+	if !exists || c.session == nil {
+		return false
+	}
+	c.timer.Stop()
+	c.revert()
+
+	slog.Default().Info("Debug capture disabled", "component", name)
+	return true
+}
+
+// Active reports whether a debug capture session is active for the component
+func Active(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	c, exists := components[name]
+	return exists && c.session != nil
+}
+
+// Sessions returns the active sessions sorted by component name
+func Sessions() []Session {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	sessions := make([]Session, 0, len(components))
+	for _, c := range components {
+		if c.session != nil {
+			sessions = append(sessions, *c.session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b Session) int {
+		return strings.Compare(a.Component, b.Component)
+	})
+	return sessions
+}
+
+// expire ends session when its timer fires, unless it was disabled or replaced meanwhile
+func expire(name string, session *Session) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, exists := components[name]
+	if !exists || c.session != session || time.Now().Before(session.ExpiresAt) {
+		return
+	}
+	c.revert()
+
+	slog.Default().Info("Debug capture expired", "component", name)
+}
+
+// revert restores the saved log levels and clears the session, mu must be held
+func (c *component) revert() {
+	for i, level := range c.levels {
+		if i < len(c.saved) {
+			level.Set(c.saved[i])
+		}
+	}
+	c.saved = nil
+	c.session = nil
+	c.timer = nil
+}
@@ -0,0 +1,64 @@
+package debugcapture
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableAndDisable(t *testing.T) {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	Register("test-enable", level)
+
+	assert.Contains(t, Components(), "test-enable")
+	assert.False(t, Active("test-enable"))
+
+	session, err := Enable("test-enable", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "test-enable", session.Component)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Second)
+	assert.True(t, Active("test-enable"))
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Contains(t, Sessions(), session)
+
+	// Enabling again extends the session and keeps the original level for restoring
+	extended, err := Enable("test-enable", 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, session.StartedAt, extended.StartedAt)
+	assert.True(t, extended.ExpiresAt.After(session.ExpiresAt))
+
+	assert.True(t, Disable("test-enable"))
+	assert.False(t, Active("test-enable"))
+	assert.Equal(t, slog.LevelWarn, level.Level())
+	assert.False(t, Disable("test-enable"), "disabling without a session reports false")
+}
+
+func TestSessionExpires(t *testing.T) {
+	level := new(slog.LevelVar)
+	Register("test-expire", level)
+
+	_, err := Enable("test-expire", 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, Active("test-expire"))
+
+	assert.Eventually(t, func() bool { return !Active("test-expire") }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, slog.LevelInfo, level.Level())
+}
+
+func TestEnableValidation(t *testing.T) {
+	Register("test-validation")
+
+	_, err := Enable("unknown-component", time.Minute)
+	require.Error(t, err)
+
+	_, err = Enable("test-validation", 0)
+	require.Error(t, err)
+
+	_, err = Enable("test-validation", MaxDuration+time.Minute)
+	require.Error(t, err)
+	assert.False(t, Active("test-validation"))
+}
@@ -0,0 +1,213 @@
+// Package detectionstats keeps rolling detection statistics in memory. It consumes the detection
+// events published for every saved detection, so dashboards can show the recent activity
+// without querying the datastore on every refresh.
+package detectionstats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/events"
+)
+
+const (
+	// bucketSize is the resolution of the rolling statistics
+	bucketSize = time.Minute
+	// retention is the longest period statistics are kept for
+	retention = 24 * time.Hour
+	// bucketCount is the number of buckets covering the retention period
+	bucketCount = int(retention / bucketSize)
+)
+
+// consumerName is the name of the aggregator on the event bus
+const consumerName = "detection-stats-aggregator"
+
+// speciesBucket holds the detections of one species in a bucket
+type speciesBucket struct {
+	commonName string
+	count      int
+	lastSeen   time.Time
+}
+
+// bucket holds the detections of one minute
+type bucket struct {
+	start   time.Time
+	total   int
+	species map[string]*speciesBucket // keyed by scientific name
+}
+
+// Aggregator maintains rolling per-minute detection counts for the last 24 hours.
+// It implements events.DetectionEventConsumer and is safe for concurrent use.
+type Aggregator struct {
+	mu      sync.Mutex
+	buckets [bucketCount]bucket
+	started time.Time
+	now     func() time.Time
+}
+
+// SpeciesCount is the number of detections of a species in a period
+type SpeciesCount struct {
+	CommonName     string    `json:"commonName"`
+	ScientificName string    `json:"scientificName"`
+	Count          int       `json:"count"`
+	LastSeen       time.Time `json:"lastSeen"`
+}
+
+// Period summarizes the detections of a rolling period
+type Period struct {
+	Total       int            `json:"total"`       // Number of detections
+	Species     int            `json:"species"`     // Number of distinct species
+	RatePerHour float64        `json:"ratePerHour"` // Detections per hour, over the part of the period covered by the statistics
+	Top         []SpeciesCount `json:"top"`         // Most detected species, most detections first
+}
+
+// Snapshot is the state of the rolling statistics at a point in time
+type Snapshot struct {
+	LastHour    Period    `json:"lastHour"`
+	LastDay     Period    `json:"lastDay"`
+	Since       time.Time `json:"since"` // Statistics only include detections since this time
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// New creates an empty aggregator
+func New() *Aggregator {
+	return &Aggregator{started: time.Now(), now: time.Now}
+}
+
+// Name returns the consumer name for identification
+func (a *Aggregator) Name() string {
+	return consumerName
+}
+
+// ProcessEvent implements the EventConsumer interface (not used for detection statistics)
+func (a *Aggregator) ProcessEvent(event events.ErrorEvent) error {
+	return nil
+}
+
+// ProcessBatch implements the EventConsumer interface (not used)
+func (a *Aggregator) ProcessBatch(errorEvents []events.ErrorEvent) error {
+	return nil
+}
+
+// SupportsBatching indicates whether this consumer supports batch processing
+func (a *Aggregator) SupportsBatching() bool {
+	return false
+}
+
+// ProcessDetectionEvent adds a saved detection to the statistics. New and rare species
+// events duplicate a saved detection and are ignored.
+func (a *Aggregator) ProcessDetectionEvent(event events.DetectionEvent) error {
+	if _, ok := event.GetMetadata()[events.MetadataNoteID]; !ok {
+		return nil
+	}
+	a.Add(event.GetSpeciesName(), event.GetScientificName(), event.GetTimestamp())
+	return nil
+}
+
+// Add records a detection of a species at time t. Detections older than the
+// retention period are ignored.
+func (a *Aggregator) Add(commonName, scientificName string, t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if t.After(now) {
+		t = now
+	}
+	if now.Sub(t) >= retention {
+		return
+	}
+
+	b := a.bucketFor(t)
+	b.total++
+	s, ok := b.species[scientificName]
+	if !ok {
+		s = &speciesBucket{}
+		b.species[scientificName] = s
+	}
+	s.commonName = commonName
+	s.count++
+	if t.After(s.lastSeen) {
+		s.lastSeen = t
+	}
+}
+
+// bucketFor returns the bucket of time t, clearing it when it still holds older detections
+func (a *Aggregator) bucketFor(t time.Time) *bucket {
+	start := t.Truncate(bucketSize)
+	b := &a.buckets[int(start.Unix()/int64(bucketSize/time.Second))%bucketCount]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start, species: make(map[string]*speciesBucket)}
+	}
+	return b
+}
+
+// Snapshot returns the statistics of the last hour and the last day with at most limit
+// species in each top list. A limit of zero or less returns all species.
+func (a *Aggregator) Snapshot(limit int) Snapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	return Snapshot{
+		LastHour:    a.period(now, time.Hour, limit),
+		LastDay:     a.period(now, retention, limit),
+		Since:       a.started,
+		GeneratedAt: now,
+	}
+}
+
+// period summarizes the buckets within length before now
+func (a *Aggregator) period(now time.Time, length time.Duration, limit int) Period {
+	cutoff := now.Add(-length)
+	species := make(map[string]*SpeciesCount)
+	var total int
+
+	for i := range a.buckets {
+		b := &a.buckets[i]
+		if b.species == nil || !b.start.Add(bucketSize).After(cutoff) || b.start.After(now) {
+			continue
+		}
+		total += b.total
+		for name, s := range b.species {
+			count, ok := species[name]
+			if !ok {
+				count = &SpeciesCount{ScientificName: name}
+				species[name] = count
+			}
+			count.Count += s.count
+			if s.lastSeen.After(count.LastSeen) {
+				count.LastSeen = s.lastSeen
+				count.CommonName = s.commonName
+			}
+		}
+	}
+
+	top := make([]SpeciesCount, 0, len(species))
+	for _, count := range species {
+		top = append(top, *count)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].LastSeen.After(top[j].LastSeen)
+	})
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+
+	// The rate only covers the time the statistics have been collected for
+	covered := length
+	if since := now.Sub(a.started); since < covered {
+		covered = max(since, bucketSize)
+	}
+
+	return Period{
+		Total:       total,
+		Species:     len(species),
+		RatePerHour: float64(total) / covered.Hours(),
+		Top:         top,
+	}
+}
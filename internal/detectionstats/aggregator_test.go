@@ -0,0 +1,109 @@
+package detectionstats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/events"
+)
+
+// newTestAggregator returns an aggregator with a clock controlled by the test
+func newTestAggregator(start time.Time) (aggregator *Aggregator, now *time.Time) {
+	current := start
+	aggregator = &Aggregator{started: start.Add(-48 * time.Hour), now: func() time.Time { return current }}
+	return aggregator, &current
+}
+
+func TestAggregatorSnapshot(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	aggregator, _ := newTestAggregator(start)
+
+	aggregator.Add("Eurasian Blackbird", "Turdus merula", start.Add(-5*time.Minute))
+	aggregator.Add("Eurasian Blackbird", "Turdus merula", start.Add(-10*time.Minute))
+	aggregator.Add("Eurasian Blackbird", "Turdus merula", start.Add(-3*time.Hour))
+	aggregator.Add("Great Tit", "Parus major", start.Add(-2*time.Minute))
+	aggregator.Add("Eurasian Nuthatch", "Sitta europaea", start.Add(-5*time.Hour))
+	aggregator.Add("Eurasian Nuthatch", "Sitta europaea", start.Add(-6*time.Hour))
+	aggregator.Add("Eurasian Nuthatch", "Sitta europaea", start.Add(-7*time.Hour))
+	aggregator.Add("Eurasian Nuthatch", "Sitta europaea", start.Add(-8*time.Hour))
+	aggregator.Add("Common Cuckoo", "Cuculus canorus", start.Add(-25*time.Hour))
+
+	snapshot := aggregator.Snapshot(0)
+
+	assert.Equal(t, 3, snapshot.LastHour.Total)
+	assert.Equal(t, 2, snapshot.LastHour.Species)
+	assert.InDelta(t, 3.0, snapshot.LastHour.RatePerHour, 1e-9)
+	require.Len(t, snapshot.LastHour.Top, 2)
+	assert.Equal(t, "Turdus merula", snapshot.LastHour.Top[0].ScientificName)
+	assert.Equal(t, "Eurasian Blackbird", snapshot.LastHour.Top[0].CommonName)
+	assert.Equal(t, 2, snapshot.LastHour.Top[0].Count)
+	assert.Equal(t, start.Add(-5*time.Minute), snapshot.LastHour.Top[0].LastSeen)
+
+	assert.Equal(t, 8, snapshot.LastDay.Total, "detections older than a day should be ignored")
+	assert.Equal(t, 3, snapshot.LastDay.Species)
+	require.Len(t, snapshot.LastDay.Top, 3)
+	assert.Equal(t, "Sitta europaea", snapshot.LastDay.Top[0].ScientificName)
+	assert.Equal(t, 4, snapshot.LastDay.Top[0].Count)
+	assert.Equal(t, "Turdus merula", snapshot.LastDay.Top[1].ScientificName)
+
+	limited := aggregator.Snapshot(1)
+	assert.Len(t, limited.LastDay.Top, 1)
+	assert.Equal(t, 8, limited.LastDay.Total, "limit should not change the totals")
+}
+
+func TestAggregatorExpiresOldBuckets(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	aggregator, now := newTestAggregator(start)
+
+	aggregator.Add("Eurasian Blackbird", "Turdus merula", start)
+
+	// A day later the same bucket is reused for a new minute
+	*now = start.Add(retention)
+	aggregator.Add("Great Tit", "Parus major", *now)
+
+	snapshot := aggregator.Snapshot(0)
+	assert.Equal(t, 1, snapshot.LastDay.Total)
+	require.Len(t, snapshot.LastDay.Top, 1)
+	assert.Equal(t, "Parus major", snapshot.LastDay.Top[0].ScientificName)
+}
+
+func TestAggregatorRateCoversCollectionTime(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	aggregator, _ := newTestAggregator(start)
+	aggregator.started = start.Add(-30 * time.Minute)
+
+	for i := range 6 {
+		aggregator.Add("Eurasian Blackbird", "Turdus merula", start.Add(-time.Duration(i)*time.Minute))
+	}
+
+	snapshot := aggregator.Snapshot(0)
+	assert.InDelta(t, 12.0, snapshot.LastHour.RatePerHour, 1e-9)
+	assert.InDelta(t, 12.0, snapshot.LastDay.RatePerHour, 1e-9)
+}
+
+func TestAggregatorProcessDetectionEvent(t *testing.T) {
+	t.Parallel()
+
+	aggregator := New()
+
+	saved, err := events.NewDetectionEvent("Eurasian Blackbird", "Turdus merula", 0.9, "backyard", false, 3)
+	require.NoError(t, err)
+	saved.GetMetadata()[events.MetadataNoteID] = uint(42)
+	require.NoError(t, aggregator.ProcessDetectionEvent(saved))
+
+	// New species notifications duplicate a saved detection
+	newSpecies, err := events.NewDetectionEvent("Eurasian Blackbird", "Turdus merula", 0.9, "backyard", true, 0)
+	require.NoError(t, err)
+	require.NoError(t, aggregator.ProcessDetectionEvent(newSpecies))
+
+	snapshot := aggregator.Snapshot(0)
+	assert.Equal(t, 1, snapshot.LastHour.Total)
+}
@@ -0,0 +1,35 @@
+// trash.go - removal of the clips of detections purged from the trash
+package diskmanager
+
+import (
+	"os"
+)
+
+// trashPolicy labels the metrics and logs of clips removed with purged detections
+const trashPolicy = "trash"
+
+// DeleteTrashedClips removes the audio clips and spectrograms of detections purged from
+// the trash. Clips already removed by the retention policy are skipped.
+func DeleteTrashedClips(clipPaths []string, debug bool) CleanupResult {
+	var result CleanupResult
+	for _, clipPath := range clipPaths {
+		info, err := os.Stat(clipPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				serviceLogger.Warn("Failed to stat clip of purged detection",
+					"policy", trashPolicy,
+					"path", clipPath,
+					"error", err)
+			}
+			continue
+		}
+
+		file := &FileInfo{Path: clipPath, Size: info.Size(), Timestamp: info.ModTime()}
+		if err := deleteFileAndOptionalSpectrogram(file, "detection purged from trash", false, debug, trashPolicy); err != nil {
+			result.Err = err
+			continue
+		}
+		result.ClipsRemoved++
+	}
+	return result
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/patrickmn/go-cache"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
 	"github.com/tphakala/birdnet-go/internal/logging"
 )
 
@@ -23,7 +24,6 @@ import (
 var (
 	logger          *slog.Logger
 	serviceLevelVar = new(slog.LevelVar) // Dynamic level control
-	closeLogger     func() error
 )
 
 func init() {
@@ -34,14 +34,15 @@ func init() {
 	serviceLevelVar.Set(initialLevel)
 
 	// Initialize the service-specific file logger
-	logger, closeLogger, err = logging.NewFileLogger(logFilePath, "ebird", serviceLevelVar)
+	// The log file is owned by the logging registry and closed by logging.CloseAll on shutdown,
+	// clients share the package logger so closing a client must not close it
+	logger, _, err = logging.NewFileLogger(logFilePath, "ebird", serviceLevelVar)
 	if err != nil {
 		// Fallback: Log error to standard log and potentially disable service logging
 		log.Printf("FATAL: Failed to initialize ebird file logger at %s: %v. Service logging disabled.", logFilePath, err)
 		// Set logger to a disabled handler to prevent nil panics, but respects level var
 		fbHandler := slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: serviceLevelVar})
 		logger = slog.New(fbHandler).With("service", "ebird")
-		closeLogger = func() error { return nil } // No-op closer
 	}
 }
 
@@ -95,11 +96,17 @@ func NewClient(config Config) (*Client, error) {
 	settings := conf.GetSettings()
 	debug := settings != nil && settings.Debug
 
+	httpClient, err := httpclient.New(settings, httpclient.Options{
+		Integration: "ebird",
+		Timeout:     config.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+		config:      config,
+		httpClient:  httpClient,
 		cache:       cache.New(config.CacheTTL, config.CacheTTL*2),
 		rateLimiter: time.NewTicker(time.Duration(config.RateLimitMS) * time.Millisecond),
 		debug:       debug,
@@ -120,15 +127,6 @@ func NewClient(config Config) (*Client, error) {
 func (c *Client) Close() {
 	c.rateLimiter.Stop()
 	logger.Info("Closing eBird client")
-	
-	// Close the logger if it was successfully initialized
-	if closeLogger != nil {
-		logger.Debug("Closing eBird service log file")
-		if err := closeLogger(); err != nil {
-			// Use standard log since our logger might be closing
-			log.Printf("Error closing eBird logger: %v", err)
-		}
-	}
 }
 
 // GetTaxonomy retrieves the complete eBird taxonomy, optionally filtered by locale
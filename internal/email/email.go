@@ -0,0 +1,271 @@
+// Package email sends multipart HTML and plain text email through an SMTP server.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// dialTimeout limits how long connecting to the SMTP server may take
+const dialTimeout = 30 * time.Second
+
+// Message is an email with an HTML body and a plain text alternative
+type Message struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Sender delivers messages to the configured recipients through an SMTP server
+type Sender struct {
+	settings conf.SMTPSettings
+	from     *mail.Address
+	to       []*mail.Address
+	now      func() time.Time
+}
+
+// NewSender creates a sender from SMTP settings. Addresses are parsed up front so a
+// misconfiguration is reported when the sender is created rather than on first use.
+func NewSender(settings *conf.SMTPSettings) (*Sender, error) {
+	from, err := mail.ParseAddress(settings.From)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("email").
+			Category(errors.CategoryConfiguration).
+			Context("field", "from").
+			Build()
+	}
+
+	to := make([]*mail.Address, 0, len(settings.To))
+	for _, recipient := range settings.To {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("email").
+				Category(errors.CategoryConfiguration).
+				Context("field", "to").
+				Build()
+		}
+		to = append(to, addr)
+	}
+	if len(to) == 0 {
+		return nil, errors.Newf("no email recipients configured").
+			Component("email").
+			Category(errors.CategoryConfiguration).
+			Build()
+	}
+
+	return &Sender{settings: *settings, from: from, to: to, now: time.Now}, nil
+}
+
+// Send delivers the message to all configured recipients
+func (s *Sender) Send(ctx context.Context, msg *Message) error {
+	body, err := s.compose(msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := s.deliver(client, body); err != nil {
+		return errors.New(err).
+			Component("email").
+			Category(errors.CategoryNetwork).
+			Context("operation", "smtp_deliver").
+			Context("host", s.settings.Host).
+			Build()
+	}
+	return nil
+}
+
+// dial connects to the SMTP server using the configured encryption
+func (s *Sender) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(s.settings.Host, strconv.Itoa(s.settings.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         s.settings.Host,
+		InsecureSkipVerify: s.settings.InsecureSkipVerify, // #nosec G402 -- opt-in for self-signed relays
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	var conn net.Conn
+	var err error
+	if s.settings.Encryption == conf.SMTPEncryptionTLS {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(dialCtx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(dialCtx, "tcp", address)
+	}
+	if err != nil {
+		return nil, errors.New(err).
+			Component("email").
+			Category(errors.CategoryNetwork).
+			Context("operation", "smtp_connect").
+			Context("host", s.settings.Host).
+			Context("port", s.settings.Port).
+			Build()
+	}
+
+	client, err := smtp.NewClient(conn, s.settings.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.New(err).
+			Component("email").
+			Category(errors.CategoryNetwork).
+			Context("operation", "smtp_handshake").
+			Context("host", s.settings.Host).
+			Build()
+	}
+
+	if s.settings.Encryption == conf.SMTPEncryptionStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Close()
+			return nil, errors.New(err).
+				Component("email").
+				Category(errors.CategoryNetwork).
+				Context("operation", "smtp_starttls").
+				Context("host", s.settings.Host).
+				Build()
+		}
+	}
+
+	if s.settings.Username != "" {
+		auth := smtp.PlainAuth("", s.settings.Username, s.settings.Password, s.settings.Host)
+		if err := client.Auth(auth); err != nil {
+			_ = client.Close()
+			return nil, errors.New(err).
+				Component("email").
+				Category(errors.CategoryNetwork).
+				Context("operation", "smtp_auth").
+				Context("host", s.settings.Host).
+				Build()
+		}
+	}
+
+	return client, nil
+}
+
+// deliver sends the envelope and the composed message over an established connection
+func (s *Sender) deliver(client *smtp.Client, body []byte) error {
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	for _, to := range s.to {
+		if err := client.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose renders the message as a multipart/alternative MIME document
+func (s *Sender) compose(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	recipients := make([]string, 0, len(s.to))
+	for _, to := range s.to {
+		recipients = append(recipients, to.String())
+	}
+
+	header := []string{
+		"From: " + s.from.String(),
+		"To: " + strings.Join(recipients, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + s.now().Format(time.RFC1123Z),
+		"Message-ID: " + s.messageID(),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	var doc bytes.Buffer
+	doc.WriteString(strings.Join(header, "\r\n"))
+	doc.WriteString("\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.TextBody},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	} {
+		if part.body == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, composeError(err)
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, composeError(err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, composeError(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, composeError(err)
+	}
+
+	doc.Write(buf.Bytes())
+	return doc.Bytes(), nil
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func (s *Sender) messageID() string {
+	domain := "birdnet-go.local"
+	if at := strings.LastIndex(s.from.Address, "@"); at >= 0 && at < len(s.from.Address)-1 {
+		domain = s.from.Address[at+1:]
+	}
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", s.now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// composeError wraps a MIME encoding error
+func composeError(err error) error {
+	return errors.New(err).
+		Component("email").
+		Category(errors.CategoryProcessing).
+		Context("operation", "compose_message").
+		Build()
+}
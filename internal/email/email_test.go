@@ -0,0 +1,173 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// receivedMail is a message accepted by the fake SMTP server
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTPServer accepts a single plain SMTP session and reports the received message
+func startFakeSMTPServer(t *testing.T) (port int, received <-chan receivedMail) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	ch := make(chan receivedMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		tp := textproto.NewConn(conn)
+
+		var msg receivedMail
+		_ = tp.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				_ = tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				msg.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				_ = tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				msg.data = string(data)
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				ch <- msg
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestNewSenderRejectsInvalidAddresses(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSender(&conf.SMTPSettings{From: "not an address", To: []string{"a@example.com"}})
+	require.Error(t, err)
+
+	_, err = NewSender(&conf.SMTPSettings{From: "birdnet@example.com"})
+	require.Error(t, err)
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+	port, received := startFakeSMTPServer(t)
+
+	sender, err := NewSender(&conf.SMTPSettings{
+		Host:       "127.0.0.1",
+		Port:       port,
+		From:       "BirdNET-Go <birdnet@example.com>",
+		To:         []string{"birder@example.com", "Second <second@example.com>"},
+		Encryption: conf.SMTPEncryptionNone,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, sender.Send(ctx, &Message{
+		Subject:  "Daily digest: 3 species — 1 new",
+		HTMLBody: "<p>Eurasian Blackbird</p>",
+		TextBody: "Eurasian Blackbird",
+	}))
+
+	var msg receivedMail
+	select {
+	case msg = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	assert.Equal(t, "birdnet@example.com", msg.from)
+	assert.Equal(t, []string{"birder@example.com", "second@example.com"}, msg.to)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Daily digest: 3 species — 1 new", subject)
+	assert.NotEmpty(t, parsed.Header.Get("Message-ID"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(bufio.NewReader(part))
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Eurasian Blackbird")
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+}
+
+func TestSendConnectionError(t *testing.T) {
+	t.Parallel()
+
+	// Reserve a port and close it so the connection is refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	sender, err := NewSender(&conf.SMTPSettings{
+		Host:       "127.0.0.1",
+		Port:       port,
+		From:       "birdnet@example.com",
+		To:         []string{"birder@example.com"},
+		Encryption: conf.SMTPEncryptionNone,
+	})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), &Message{Subject: "test", TextBody: "test"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), strconv.Itoa(port))
+}
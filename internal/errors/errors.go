@@ -403,6 +403,12 @@ func init() {
 	RegisterComponent("backup", "backup")
 	RegisterComponent("audiocore", "audiocore")
 	RegisterComponent("api", "api")
+	RegisterComponent("updater", "updater")
+	RegisterComponent("email", "email")
+	RegisterComponent("debugcapture", "debugcapture")
+	RegisterComponent("export", "export")
+	RegisterComponent("importer", "importer")
+	RegisterComponent("backfill", "backfill")
 	
 	// Analysis package components - use slash-separated paths for subpackages
 	RegisterComponent("analysis", "analysis")
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultConsumerQueueSize is the number of events queued for each consumer when the
+	// configuration does not set a size
+	defaultConsumerQueueSize = 1000
+
+	// dropWarningInterval limits the warnings about a consumer dropping events
+	dropWarningInterval = time.Minute
+)
+
+// ConsumerStats contains the dispatch statistics of one consumer
+type ConsumerStats struct {
+	Name          string
+	Queued        uint64        // Events queued for the consumer
+	Processed     uint64        // Events the consumer finished, with or without an error
+	Dropped       uint64        // Events dropped because the queue of the consumer was full
+	QueueLength   int           // Events waiting in the queue
+	QueueCapacity int           // Size of the queue
+	AvgLatency    time.Duration // Average time from queuing an event to the end of its processing
+	MaxLatency    time.Duration // Longest time from queuing an event to the end of its processing
+}
+
+// consumerTask is an event queued for a consumer
+type consumerTask struct {
+	process   func() error
+	logFields map[string]any
+	queued    time.Time
+}
+
+// consumerQueue feeds the events of one consumer to a goroutine of its own, so a slow
+// consumer delays only its own events. When the queue is full, new events for the consumer
+// are dropped while the other consumers continue to receive them.
+type consumerQueue struct {
+	name  string
+	tasks chan consumerTask
+
+	queued          atomic.Uint64
+	processed       atomic.Uint64
+	dropped         atomic.Uint64
+	totalLatency    atomic.Int64 // nanoseconds
+	maxLatency      atomic.Int64 // nanoseconds
+	lastDropWarning atomic.Int64 // unix nanoseconds
+}
+
+// newConsumerQueue creates a queue of the given size for a consumer
+func newConsumerQueue(name string, size int) *consumerQueue {
+	if size <= 0 {
+		size = defaultConsumerQueueSize
+	}
+	return &consumerQueue{name: name, tasks: make(chan consumerTask, size)}
+}
+
+// enqueue queues an event without blocking and reports whether it was accepted
+func (q *consumerQueue) enqueue(process func() error, logFields map[string]any) bool {
+	select {
+	case q.tasks <- consumerTask{process: process, logFields: logFields, queued: time.Now()}:
+		q.queued.Add(1)
+		return true
+	default:
+		q.dropped.Add(1)
+		return false
+	}
+}
+
+// shouldWarnDrop reports whether a dropped event is logged as a warning, at most once per
+// dropWarningInterval so a stuck consumer does not flood the log
+func (q *consumerQueue) shouldWarnDrop(now time.Time) bool {
+	last := q.lastDropWarning.Load()
+	if now.UnixNano()-last < int64(dropWarningInterval) {
+		return false
+	}
+	return q.lastDropWarning.CompareAndSwap(last, now.UnixNano())
+}
+
+// recordLatency records the time from queuing an event to the end of its processing
+func (q *consumerQueue) recordLatency(latency time.Duration) {
+	q.processed.Add(1)
+	q.totalLatency.Add(int64(latency))
+	for {
+		current := q.maxLatency.Load()
+		if int64(latency) <= current || q.maxLatency.CompareAndSwap(current, int64(latency)) {
+			return
+		}
+	}
+}
+
+// stats returns the dispatch statistics of the queue
+func (q *consumerQueue) stats() ConsumerStats {
+	stats := ConsumerStats{
+		Name:          q.name,
+		Queued:        q.queued.Load(),
+		Processed:     q.processed.Load(),
+		Dropped:       q.dropped.Load(),
+		QueueLength:   len(q.tasks),
+		QueueCapacity: cap(q.tasks),
+		MaxLatency:    time.Duration(q.maxLatency.Load()),
+	}
+	if stats.Processed > 0 {
+		stats.AvgLatency = time.Duration(q.totalLatency.Load() / int64(stats.Processed))
+	}
+	return stats
+}
+
+// dispatch queues an event for a consumer. Events the queue has no room for are dropped and
+// counted in the statistics of the bus and the consumer.
+func (eb *EventBus) dispatch(q *consumerQueue, process func() error, logFields map[string]any, logger *slog.Logger) {
+	if q.enqueue(process, logFields) {
+		return
+	}
+
+	atomic.AddUint64(&eb.stats.ConsumerDrops, 1)
+	level := slog.LevelDebug
+	if q.shouldWarnDrop(time.Now()) {
+		level = slog.LevelWarn
+	}
+	fields := make([]any, 0, 6+len(logFields)*2)
+	fields = append(fields, "consumer", q.name, "queue_capacity", cap(q.tasks), "total_dropped", q.dropped.Load())
+	for k, v := range logFields {
+		fields = append(fields, k, v)
+	}
+	logger.Log(eb.ctx, level, "event dropped for slow consumer", fields...)
+}
+
+// runConsumerQueue processes the queued events of a consumer until the bus shuts down
+func (eb *EventBus) runConsumerQueue(q *consumerQueue) {
+	defer eb.wg.Done()
+
+	logger := eb.logger.With("consumer", q.name)
+	logger.Debug("consumer queue started", "capacity", cap(q.tasks))
+
+	for {
+		select {
+		case <-eb.ctx.Done():
+			logger.Debug("consumer queue stopping due to context cancellation",
+				"pending", len(q.tasks))
+			return
+
+		case task := <-q.tasks:
+			eb.processEvent(q.name, task.process, task.logFields, eb.logger)
+			q.recordLatency(time.Since(task.queued))
+		}
+	}
+}
+
+// GetConsumerStats returns the dispatch statistics of the registered consumers, in the order
+// of registration
+func (eb *EventBus) GetConsumerStats() []ConsumerStats {
+	if eb == nil {
+		return nil
+	}
+
+	eb.mu.Lock()
+	queues := make([]*consumerQueue, 0, len(eb.consumers))
+	for _, consumer := range eb.consumers {
+		if q := eb.queues[consumer.Name()]; q != nil {
+			queues = append(queues, q)
+		}
+	}
+	eb.mu.Unlock()
+
+	stats := make([]ConsumerStats, 0, len(queues))
+	for _, q := range queues {
+		stats = append(stats, q.stats())
+	}
+	return stats
+}
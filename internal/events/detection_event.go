@@ -8,6 +8,14 @@ import (
 	"github.com/tphakala/birdnet-go/internal/errors"
 )
 
+// MetadataRarityScore is the metadata key of the rarity score of detections published because
+// the species is unusual for the station and week of the year
+const MetadataRarityScore = "rarity_score"
+
+// MetadataNoteID is the metadata key of the database ID of a saved detection. It is set on the
+// event published for every saved detection, new and rare species events do not carry it.
+const MetadataNoteID = "note_id"
+
 // DetectionEvent represents a bird detection event that can be processed asynchronously
 type DetectionEvent interface {
 	// GetSpeciesName returns the common name of the detected species
@@ -123,6 +123,9 @@ type EventBus struct {
 	resourceConsumers  []ResourceEventConsumer  // Separate slice for resource event consumers
 	detectionConsumers []DetectionEventConsumer // Separate slice for detection event consumers
 	
+	// Dispatch queues by consumer name, replaced rather than modified when a consumer registers
+	queues map[string]*consumerQueue
+	
 	// Deduplication
 	deduplicator *ErrorDeduplicator
 	
@@ -164,10 +167,11 @@ func ResetForTesting() {
 // DefaultConfig returns the default event bus configuration
 func DefaultConfig() *Config {
 	return &Config{
-		BufferSize:   10000,
-		Workers:      4,
-		Enabled:      true,
-		Deduplication: DefaultDeduplicationConfig(),
+		BufferSize:        10000,
+		ConsumerQueueSize: defaultConsumerQueueSize,
+		Workers:           4,
+		Enabled:           true,
+		Deduplication:     DefaultDeduplicationConfig(),
 	}
 }
 
@@ -175,6 +179,7 @@ func DefaultConfig() *Config {
 type Config struct {
 	BufferSize         int  // Buffer size for error events
 	ResourceBufferSize int  // Buffer size for resource events (if 0, uses BufferSize)
+	ConsumerQueueSize  int  // Events queued for each consumer (if 0, uses defaultConsumerQueueSize)
 	Workers            int
 	Enabled            bool
 	Debug              bool // Enable debug logging
@@ -286,6 +291,21 @@ func (eb *EventBus) RegisterConsumer(consumer EventConsumer) error {
 	
 	eb.consumers = append(eb.consumers, consumer)
 	
+	// Give the consumer a queue and goroutine of its own, so a slow consumer cannot delay the others
+	queueSize := 0
+	if eb.config != nil {
+		queueSize = eb.config.ConsumerQueueSize
+	}
+	queue := newConsumerQueue(consumer.Name(), queueSize)
+	queues := make(map[string]*consumerQueue, len(eb.queues)+1)
+	for name, q := range eb.queues {
+		queues[name] = q
+	}
+	queues[consumer.Name()] = queue
+	eb.queues = queues
+	eb.wg.Add(1)
+	go eb.runConsumerQueue(queue)
+	
 	// Check if consumer also implements ResourceEventConsumer
 	if resourceConsumer, ok := consumer.(ResourceEventConsumer); ok {
 		eb.resourceConsumers = append(eb.resourceConsumers, resourceConsumer)
@@ -305,6 +325,7 @@ func (eb *EventBus) RegisterConsumer(consumer EventConsumer) error {
 		"supports_batching", consumer.SupportsBatching(),
 		"duration_ms", duration.Milliseconds(),
 		"total_consumers", len(eb.consumers),
+		"queue_capacity", cap(queue.tasks),
 	)
 	
 	// Start workers if this is the first consumer and not already running
@@ -646,11 +667,12 @@ func (eb *EventBus) processEvent(
 	}
 }
 
-// processErrorEvent sends the error event to all registered consumers
+// processErrorEvent queues the error event for all registered consumers
 func (eb *EventBus) processErrorEvent(event ErrorEvent, logger *slog.Logger) {
 	eb.mu.Lock()
 	consumers := make([]EventConsumer, len(eb.consumers))
 	copy(consumers, eb.consumers)
+	queues := eb.queues
 	eb.mu.Unlock()
 	
 	for _, consumer := range consumers {
@@ -658,8 +680,8 @@ func (eb *EventBus) processErrorEvent(event ErrorEvent, logger *slog.Logger) {
 			"component": event.GetComponent(),
 			"category":  event.GetCategory(),
 		}
-		eb.processEvent(
-			consumer.Name(),
+		eb.dispatch(
+			queues[consumer.Name()],
 			func() error { return consumer.ProcessEvent(event) },
 			logFields,
 			logger,
@@ -667,11 +689,12 @@ func (eb *EventBus) processErrorEvent(event ErrorEvent, logger *slog.Logger) {
 	}
 }
 
-// processResourceEvent sends the resource event to all registered resource consumers
+// processResourceEvent queues the resource event for all registered resource consumers
 func (eb *EventBus) processResourceEvent(event ResourceEvent, logger *slog.Logger) {
 	eb.mu.Lock()
 	resourceConsumers := make([]ResourceEventConsumer, len(eb.resourceConsumers))
 	copy(resourceConsumers, eb.resourceConsumers)
+	queues := eb.queues
 	eb.mu.Unlock()
 	
 	// No type assertions needed - iterate directly over resource consumers
@@ -680,8 +703,8 @@ func (eb *EventBus) processResourceEvent(event ResourceEvent, logger *slog.Logge
 			"resource_type": event.GetResourceType(),
 			"severity":      event.GetSeverity(),
 		}
-		eb.processEvent(
-			consumer.Name(),
+		eb.dispatch(
+			queues[consumer.Name()],
 			func() error { return consumer.ProcessResourceEvent(event) },
 			logFields,
 			logger,
@@ -689,11 +712,12 @@ func (eb *EventBus) processResourceEvent(event ResourceEvent, logger *slog.Logge
 	}
 }
 
-// processDetectionEvent sends the detection event to all registered detection consumers
+// processDetectionEvent queues the detection event for all registered detection consumers
 func (eb *EventBus) processDetectionEvent(event DetectionEvent, logger *slog.Logger) {
 	eb.mu.Lock()
 	detectionConsumers := make([]DetectionEventConsumer, len(eb.detectionConsumers))
 	copy(detectionConsumers, eb.detectionConsumers)
+	queues := eb.queues
 	eb.mu.Unlock()
 	
 	// No type assertions needed - iterate directly over detection consumers
@@ -702,8 +726,8 @@ func (eb *EventBus) processDetectionEvent(event DetectionEvent, logger *slog.Log
 			"species":        event.GetSpeciesName(),
 			"is_new_species": event.IsNewSpecies(),
 		}
-		eb.processEvent(
-			consumer.Name(),
+		eb.dispatch(
+			queues[consumer.Name()],
 			func() error { return consumer.ProcessDetectionEvent(event) },
 			logFields,
 			logger,
@@ -766,6 +790,7 @@ func (eb *EventBus) GetStats() EventBusStats {
 		EventsProcessed:  atomic.LoadUint64(&eb.stats.EventsProcessed),
 		EventsDropped:    atomic.LoadUint64(&eb.stats.EventsDropped),
 		ConsumerErrors:   atomic.LoadUint64(&eb.stats.ConsumerErrors),
+		ConsumerDrops:    atomic.LoadUint64(&eb.stats.ConsumerDrops),
 		FastPathHits:     atomic.LoadUint64(&eb.stats.FastPathHits),
 	}
 }
@@ -838,6 +863,7 @@ func (eb *EventBus) logMetrics(reason string) {
 		"events_suppressed", stats.EventsSuppressed,
 		"events_per_second", fmt.Sprintf("%.2f", eventsPerSecond),
 		"consumer_errors", stats.ConsumerErrors,
+		"consumer_drops", stats.ConsumerDrops,
 		"fast_path_hits", stats.FastPathHits,
 		"fast_path_percent", fmt.Sprintf("%.2f%%", fastPathPercent),
 		"active_consumers", len(eb.consumers),
@@ -851,4 +877,18 @@ func (eb *EventBus) logMetrics(reason string) {
 		"dedup_cache_size", dedupStats.CacheSize,
 		"uptime_hours", fmt.Sprintf("%.2f", uptime/3600),
 	)
+	
+	for _, consumer := range eb.GetConsumerStats() {
+		eb.logger.Info("event consumer metrics",
+			"reason", reason,
+			"consumer", consumer.Name,
+			"events_queued", consumer.Queued,
+			"events_processed", consumer.Processed,
+			"events_dropped", consumer.Dropped,
+			"queue_length", consumer.QueueLength,
+			"queue_capacity", consumer.QueueCapacity,
+			"avg_latency_ms", consumer.AvgLatency.Milliseconds(),
+			"max_latency_ms", consumer.MaxLatency.Milliseconds(),
+		)
+	}
 }
\ No newline at end of file
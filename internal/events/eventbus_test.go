@@ -552,4 +552,91 @@ func (b *blockingConsumer) ProcessBatch(events []ErrorEvent) error {
 	return nil
 }
 
-func (b *blockingConsumer) SupportsBatching() bool { return false }
\ No newline at end of file
+func (b *blockingConsumer) SupportsBatching() bool { return false }
+// TestSlowConsumerIsolation tests that a blocked consumer does not delay other consumers
+// Note: This test cannot run in parallel because it modifies the global
+// hasActiveConsumers flag.
+func TestSlowConsumerIsolation(t *testing.T) {
+	// Don't run in parallel - modifies global state
+
+	logging.Init()
+
+	// Reset global state after test
+	defer resetGlobalStateForTesting()
+
+	eb := createTestEventBus(t, 100, 1)
+	eb.config.ConsumerQueueSize = 2
+
+	blockChan := make(chan struct{}, 1)
+	releaseChan := make(chan struct{})
+	slow := &blockingConsumer{
+		name:        "slow-consumer",
+		blockChan:   blockChan,
+		releaseChan: releaseChan,
+	}
+	if err := eb.RegisterConsumer(slow); err != nil {
+		t.Fatalf("failed to register consumer: %v", err)
+	}
+	fast := &mockConsumer{name: "fast-consumer"}
+	if err := eb.RegisterConsumer(fast); err != nil {
+		t.Fatalf("failed to register consumer: %v", err)
+	}
+	ensureEventBusStarted(t, eb)
+
+	defer func() {
+		close(releaseChan)
+		if err := eb.Shutdown(1 * time.Second); err != nil {
+			t.Logf("shutdown error: %v", err)
+		}
+	}()
+
+	publish := func(i int) {
+		event := &mockErrorEvent{
+			component: "test",
+			category:  "isolation-test",
+			message:   fmt.Sprintf("event %d", i),
+			timestamp: time.Now(),
+		}
+		if !eb.TryPublish(event) {
+			t.Fatalf("expected publish of event %d to succeed", i)
+		}
+	}
+
+	// Block the slow consumer on its first event
+	publish(0)
+	select {
+	case <-blockChan:
+	case <-time.After(time.Second):
+		t.Fatal("slow consumer did not receive the first event")
+	}
+
+	// The slow consumer queues two events and drops the rest, the fast consumer gets them all
+	for i := 1; i <= 10; i++ {
+		publish(i)
+		waitForProcessed(t, fast, int32(i+1), time.Second)
+	}
+
+	var slowStats, fastStats ConsumerStats
+	for _, stats := range eb.GetConsumerStats() {
+		switch stats.Name {
+		case slow.name:
+			slowStats = stats
+		case fast.name:
+			fastStats = stats
+		}
+	}
+
+	if slowStats.Dropped != 8 {
+		t.Errorf("expected 8 events dropped for the slow consumer, got %d", slowStats.Dropped)
+	}
+	if slowStats.QueueLength != 2 || slowStats.QueueCapacity != 2 {
+		t.Errorf("expected a full queue of 2 events, got %d of %d", slowStats.QueueLength, slowStats.QueueCapacity)
+	}
+	if fastStats.Dropped != 0 || fastStats.Queued != 11 {
+		t.Errorf("expected 11 events queued for the fast consumer without drops, got %d queued and %d dropped",
+			fastStats.Queued, fastStats.Dropped)
+	}
+	if stats := eb.GetStats(); stats.ConsumerDrops != 8 {
+		t.Errorf("expected 8 consumer drops in the bus statistics, got %d", stats.ConsumerDrops)
+	}
+}
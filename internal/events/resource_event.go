@@ -61,6 +61,43 @@ func NewResourceEventWithPath(resourceType string, currentValue, threshold float
 	return event
 }
 
+// NewAudioSourceEvent creates a health event of an audio source. The current value is the
+// number of seconds since the source delivered audio and the threshold the number of seconds
+// after which it is unhealthy; the path is the display name of the source.
+func NewAudioSourceEvent(sourceID, name string, secondsSinceData, threshold float64, severity string, metadata map[string]interface{}) ResourceEvent {
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["source_id"] = sourceID
+	metadata["source_name"] = name
+	return &resourceEventImpl{
+		resourceType: ResourceAudioSource,
+		currentValue: secondsSinceData,
+		threshold:    threshold,
+		severity:     severity,
+		timestamp:    time.Now(),
+		metadata:     metadata,
+		path:         name,
+	}
+}
+
+// NewDatastoreEvent creates an event of the database degraded mode. While the database is
+// unavailable the current value is the number of detections buffered on disk and the threshold
+// the buffer limit; on recovery the current value is the number of buffered detections saved.
+func NewDatastoreEvent(detections, limit int, severity string, metadata map[string]interface{}) ResourceEvent {
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	return &resourceEventImpl{
+		resourceType: ResourceDatastore,
+		currentValue: float64(detections),
+		threshold:    float64(limit),
+		severity:     severity,
+		timestamp:    time.Now(),
+		metadata:     metadata,
+	}
+}
+
 // GetResourceType returns the type of resource
 func (e *resourceEventImpl) GetResourceType() string {
 	return e.resourceType
@@ -93,6 +130,13 @@ func (e *resourceEventImpl) GetMetadata() map[string]interface{} {
 
 // GetMessage returns a human-readable message
 func (e *resourceEventImpl) GetMessage() string {
+	if e.resourceType == ResourceAudioSource {
+		return e.audioSourceMessage()
+	}
+	if e.resourceType == ResourceDatastore {
+		return e.datastoreMessage()
+	}
+
 	var resourceName string
 	switch e.resourceType {
 	case "cpu":
@@ -122,7 +166,35 @@ func (e *resourceEventImpl) GetMessage() string {
 	}
 }
 
-// GetPath returns the path for disk resources or empty string for others
+// audioSourceMessage returns the message of an audio source health event
+func (e *resourceEventImpl) audioSourceMessage() string {
+	switch e.severity {
+	case "recovery":
+		return fmt.Sprintf("Audio source %s is delivering audio again", e.path)
+	case "critical":
+		return fmt.Sprintf("Audio source %s has not delivered audio for %.0f seconds and is not restarted automatically (threshold: %.0f seconds)", e.path, e.currentValue, e.threshold)
+	default:
+		if e.currentValue == 0 {
+			return fmt.Sprintf("Audio source %s has not delivered any audio since it started", e.path)
+		}
+		return fmt.Sprintf("Audio source %s has not delivered audio for %.0f seconds (threshold: %.0f seconds)", e.path, e.currentValue, e.threshold)
+	}
+}
+
+// datastoreMessage returns the message of a database degraded mode event
+func (e *resourceEventImpl) datastoreMessage() string {
+	switch e.severity {
+	case "recovery":
+		return fmt.Sprintf("Database is available again, %.0f buffered detections were saved", e.currentValue)
+	case "critical":
+		return fmt.Sprintf("Database is unavailable and the detection buffer is full (%.0f detections), new detections are lost", e.currentValue)
+	default:
+		return fmt.Sprintf("Database is unavailable, detections are buffered on disk until it recovers (%.0f of %.0f buffered)", e.currentValue, e.threshold)
+	}
+}
+
+// GetPath returns the path for disk resources, the source name for audio sources, or empty
+// string for others
 func (e *resourceEventImpl) GetPath() string {
 	return e.path
 }
@@ -139,4 +211,7 @@ const (
 	ResourceCPU    = "cpu"
 	ResourceMemory = "memory"
 	ResourceDisk   = "disk"
+
+	ResourceAudioSource = "audio_source" // health of an audio source, see NewAudioSourceEvent
+	ResourceDatastore   = "datastore"    // database degraded mode, see NewDatastoreEvent
 )
\ No newline at end of file
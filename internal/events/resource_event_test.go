@@ -196,4 +196,45 @@ func TestResourceEventMessage(t *testing.T) {
 // hasPrefix is a simple string prefix check to avoid importing strings package
 func hasPrefix(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
-}
\ No newline at end of file
+}
+func TestAudioSourceEvent(t *testing.T) {
+	t.Parallel()
+
+	event := NewAudioSourceEvent("rtsp_1", "Garden", 75, 60, SeverityWarning, map[string]interface{}{"restart_count": 2})
+	if event.GetResourceType() != ResourceAudioSource || event.GetPath() != "Garden" {
+		t.Errorf("unexpected type %q or path %q", event.GetResourceType(), event.GetPath())
+	}
+	if id := event.GetMetadata()["source_id"]; id != "rtsp_1" {
+		t.Errorf("source_id metadata = %v, want rtsp_1", id)
+	}
+
+	messages := map[string]string{
+		SeverityWarning:  "Audio source Garden has not delivered audio for 75 seconds (threshold: 60 seconds)",
+		SeverityCritical: "Audio source Garden has not delivered audio for 75 seconds and is not restarted automatically (threshold: 60 seconds)",
+		SeverityRecovery: "Audio source Garden is delivering audio again",
+	}
+	for severity, want := range messages {
+		if got := NewAudioSourceEvent("rtsp_1", "Garden", 75, 60, severity, nil).GetMessage(); got != want {
+			t.Errorf("GetMessage() for %s = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+func TestDatastoreEvent(t *testing.T) {
+	t.Parallel()
+
+	messages := map[string]string{
+		SeverityWarning:  "Database is unavailable, detections are buffered on disk until it recovers (12 of 10000 buffered)",
+		SeverityCritical: "Database is unavailable and the detection buffer is full (12 detections), new detections are lost",
+		SeverityRecovery: "Database is available again, 12 buffered detections were saved",
+	}
+	for severity, want := range messages {
+		event := NewDatastoreEvent(12, 10000, severity, nil)
+		if event.GetResourceType() != ResourceDatastore {
+			t.Errorf("unexpected type %q", event.GetResourceType())
+		}
+		if got := event.GetMessage(); got != want {
+			t.Errorf("GetMessage() for %s = %q, want %q", severity, got, want)
+		}
+	}
+}
@@ -65,12 +65,13 @@ type EventBusStats struct {
 	EventsProcessed  uint64
 	EventsDropped    uint64
 	ConsumerErrors   uint64
+	ConsumerDrops    uint64 // Events dropped for a consumer because its queue was full
 	FastPathHits     uint64 // Number of times fast path was taken (no consumers)
 }
 
 // ResourceEvent represents a system resource monitoring event
 type ResourceEvent interface {
-	// GetResourceType returns the type of resource (cpu, memory, disk)
+	// GetResourceType returns the type of resource (cpu, memory, disk, audio_source)
 	GetResourceType() string
 	
 	// GetCurrentValue returns the current usage percentage
@@ -91,6 +92,6 @@ type ResourceEvent interface {
 	// GetMessage returns a human-readable message
 	GetMessage() string
 	
-	// GetPath returns the path (for disk resources) or empty string
+	// GetPath returns the path (for disk resources), the source name (for audio sources) or empty string
 	GetPath() string
 }
\ No newline at end of file
@@ -0,0 +1,66 @@
+package eventsink
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// dialTimeout bounds connecting to a broker
+const dialTimeout = 10 * time.Second
+
+// brokerDialer opens TCP connections to brokers, with TLS when configured
+type brokerDialer struct {
+	tls                bool
+	insecureSkipVerify bool
+	outboundTLS        *conf.OutboundTLSSettings
+}
+
+// dial connects to a broker at host:port
+func (d *brokerDialer) dial(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := d.dialPlain(ctx, address)
+	if err != nil || !d.tls {
+		return conn, err
+	}
+	return d.secure(ctx, conn, address)
+}
+
+// dialPlain connects to a broker without TLS, for protocols starting TLS after a greeting
+func (d *brokerDialer) dialPlain(ctx context.Context, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+// secure performs the TLS handshake over a broker connection, closing it on failure
+func (d *brokerDialer) secure(ctx context.Context, conn net.Conn, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("invalid broker address %q: %w", address, err)
+	}
+	tlsConfig, err := d.outboundTLS.ClientTLSConfig(host, d.insecureSkipVerify)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("TLS handshake with broker %s failed: %w", address, err)
+	}
+	return tlsConn, nil
+}
+
+// setDeadline applies the deadline of a context to a connection
+func setDeadline(ctx context.Context, conn net.Conn) error {
+	deadline, _ := ctx.Deadline() // zero without a deadline, clearing the connection deadline
+	return conn.SetDeadline(deadline)
+}
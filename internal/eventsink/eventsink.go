@@ -0,0 +1,399 @@
+// Package eventsink forwards error, resource and detection events of the event bus to an
+// external message broker: NATS, Kafka or Redis streams. Events are batched and stay queued
+// until the broker acknowledges them, so they are delivered at least once while the
+// application runs.
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/logging"
+)
+
+const (
+	// consumerName is the name of the forwarder on the event bus
+	consumerName = "event-sink"
+
+	// publishTimeout bounds a single publish of a batch, connecting included
+	publishTimeout = 30 * time.Second
+
+	// minRetryDelay and maxRetryDelay bound the backoff after failed publishes
+	minRetryDelay = 1 * time.Second
+	maxRetryDelay = 1 * time.Minute
+
+	// dropLogInterval rate limits the warnings about events dropped from a full queue
+	dropLogInterval = 1 * time.Minute
+)
+
+var logger *slog.Logger
+
+func init() {
+	logger = logging.ForService("eventsink")
+	if logger == nil {
+		logger = slog.Default().With("service", "eventsink")
+	}
+}
+
+// message is an event encoded for the broker
+type message struct {
+	id      string // unique event ID, for deduplication of redelivered events by consumers
+	kind    string // event type: error, resource or detection
+	topic   string // topic, subject or stream key
+	key     string // partitioning key of kafka
+	time    time.Time
+	payload []byte // JSON envelope of the event
+}
+
+// publisher publishes batches of messages to a message broker. A batch is published when
+// publish returns nil, a failed batch is retried as a whole.
+type publisher interface {
+	publish(ctx context.Context, batch []message) error
+	Close() error
+}
+
+// Stats are the counters of a forwarder
+type Stats struct {
+	Pending       int       `json:"pending"`                 // events waiting for the broker
+	Published     uint64    `json:"published"`               // events acknowledged by the broker
+	Dropped       uint64    `json:"dropped"`                 // events dropped because the queue was full
+	Failures      uint64    `json:"failures"`                // failed publishes of a batch
+	LastError     string    `json:"lastError,omitempty"`     // error of the last failed publish
+	LastPublished time.Time `json:"lastPublished,omitempty"` // time of the last acknowledged batch
+}
+
+// Forwarder is an event bus consumer forwarding events to a message broker in batches
+type Forwarder struct {
+	topics        conf.EventSinkTopics
+	node          string
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+	publisher     publisher
+
+	mu            sync.Mutex
+	pending       []message
+	closed        bool
+	lastError     string
+	lastPublished time.Time
+	lastDropLog   time.Time
+
+	published atomic.Uint64
+	dropped   atomic.Uint64
+	failures  atomic.Uint64
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newForwarder creates a forwarder publishing with pub and starts its publish loop
+func newForwarder(settings *conf.EventSinkSettings, node string, pub publisher) *Forwarder {
+	f := &Forwarder{
+		topics:        settings.Topics,
+		node:          node,
+		batchSize:     settings.BatchSize,
+		flushInterval: time.Duration(settings.FlushInterval) * time.Second,
+		maxPending:    settings.MaxPending,
+		publisher:     pub,
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Name returns the consumer name
+func (f *Forwarder) Name() string {
+	return consumerName
+}
+
+// ProcessEvent queues an error event
+func (f *Forwarder) ProcessEvent(event events.ErrorEvent) error {
+	if f.topics.Errors == "" || event.GetComponent() == "eventsink" {
+		return nil
+	}
+	msg, err := errorMessage(event, f.node, f.topics.Errors)
+	if err != nil {
+		return err
+	}
+	f.enqueue(msg)
+	return nil
+}
+
+// ProcessBatch queues several error events
+func (f *Forwarder) ProcessBatch(errorEvents []events.ErrorEvent) error {
+	for _, event := range errorEvents {
+		if err := f.ProcessEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SupportsBatching returns false, events are batched by the forwarder itself
+func (f *Forwarder) SupportsBatching() bool {
+	return false
+}
+
+// ProcessResourceEvent queues a resource monitoring event
+func (f *Forwarder) ProcessResourceEvent(event events.ResourceEvent) error {
+	if f.topics.Resources == "" {
+		return nil
+	}
+	msg, err := resourceMessage(event, f.node, f.topics.Resources)
+	if err != nil {
+		return err
+	}
+	f.enqueue(msg)
+	return nil
+}
+
+// ProcessDetectionEvent queues a detection event
+func (f *Forwarder) ProcessDetectionEvent(event events.DetectionEvent) error {
+	if f.topics.Detections == "" {
+		return nil
+	}
+	msg, err := detectionMessage(event, f.node, f.topics.Detections)
+	if err != nil {
+		return err
+	}
+	f.enqueue(msg)
+	return nil
+}
+
+// enqueue adds a message to the queue, or drops it when the queue is full. Queued events are
+// never dropped, so nothing accepted is lost while the broker is unreachable.
+func (f *Forwarder) enqueue(msg message) {
+	f.mu.Lock()
+	if f.closed || len(f.pending) >= f.maxPending {
+		dropped := f.dropped.Add(1)
+		logDrop := !f.closed && time.Since(f.lastDropLog) >= dropLogInterval
+		if logDrop {
+			f.lastDropLog = time.Now()
+		}
+		f.mu.Unlock()
+		if logDrop {
+			logger.Warn("event sink queue full, dropping events",
+				"max_pending", f.maxPending,
+				"dropped_total", dropped)
+		}
+		return
+	}
+	f.pending = append(f.pending, msg)
+	full := len(f.pending) >= f.batchSize
+	f.mu.Unlock()
+
+	if full {
+		select {
+		case f.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run publishes full batches right away and partial batches every flush interval, backing
+// off while the broker fails
+func (f *Forwarder) run() {
+	defer close(f.done)
+
+	timer := time.NewTimer(f.flushInterval)
+	defer timer.Stop()
+	retryDelay := time.Duration(0)
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-f.wake:
+			if retryDelay > 0 {
+				// Wait for the backoff instead of hammering a failing broker
+				continue
+			}
+		case <-timer.C:
+		}
+
+		if err := f.flush(); err != nil {
+			retryDelay = min(max(retryDelay*2, minRetryDelay), maxRetryDelay)
+			timer.Reset(retryDelay)
+			continue
+		}
+		retryDelay = 0
+		timer.Reset(f.flushInterval)
+	}
+}
+
+// flush publishes the queued messages batch by batch until the queue is empty or a publish fails
+func (f *Forwarder) flush() error {
+	for {
+		f.mu.Lock()
+		batch := f.pending[:min(len(f.pending), f.batchSize)]
+		f.mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err := f.publisher.publish(ctx, batch)
+		cancel()
+		if err != nil {
+			f.failures.Add(1)
+			f.mu.Lock()
+			repeated := f.lastError == err.Error()
+			f.lastError = err.Error()
+			f.mu.Unlock()
+			if !repeated {
+				logger.Warn("failed to publish events to the message broker, retrying",
+					"events", len(batch),
+					"error", err)
+			}
+			return err
+		}
+
+		f.mu.Lock()
+		f.pending = f.pending[len(batch):]
+		if len(f.pending) == 0 {
+			f.pending = nil // release the backing array of drained queues
+		}
+		recovered := f.lastError != ""
+		f.lastError = ""
+		f.lastPublished = time.Now()
+		f.mu.Unlock()
+		f.published.Add(uint64(len(batch)))
+		if recovered {
+			logger.Info("publishing events to the message broker recovered")
+		}
+	}
+}
+
+// Stats returns the counters of the forwarder
+func (f *Forwarder) Stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Stats{
+		Pending:       len(f.pending),
+		Published:     f.published.Load(),
+		Dropped:       f.dropped.Load(),
+		Failures:      f.failures.Load(),
+		LastError:     f.lastError,
+		LastPublished: f.lastPublished,
+	}
+}
+
+// Close stops accepting events, publishes the queued events until the timeout and closes
+// the broker connection. It returns an error when events are left unpublished.
+func (f *Forwarder) Close(timeout time.Duration) error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	f.mu.Unlock()
+
+	close(f.stop)
+	<-f.done
+
+	deadline := time.Now().Add(timeout)
+	for f.Stats().Pending > 0 && time.Now().Before(deadline) {
+		if f.flush() != nil {
+			time.Sleep(min(minRetryDelay, time.Until(deadline)))
+		}
+	}
+
+	closeErr := f.publisher.Close()
+	if pending := f.Stats().Pending; pending > 0 {
+		return fmt.Errorf("%d events left unpublished", pending)
+	}
+	return closeErr
+}
+
+var (
+	forwarder   *Forwarder
+	forwarderMu sync.Mutex
+)
+
+// Initialize starts forwarding events to the configured message broker and registers the
+// forwarder with the event bus. It does nothing when event forwarding is disabled or the
+// event bus is not running.
+func Initialize(settings *conf.Settings) error {
+	if !settings.EventSink.Enabled {
+		return nil
+	}
+	eventBus := events.GetEventBus()
+	if eventBus == nil {
+		logger.Warn("event bus not initialized, skipping event forwarding")
+		return nil
+	}
+
+	forwarderMu.Lock()
+	defer forwarderMu.Unlock()
+	if forwarder != nil {
+		return nil
+	}
+
+	pub, err := newPublisher(&settings.EventSink, &settings.OutboundTLS)
+	if err != nil {
+		return err
+	}
+	f := newForwarder(&settings.EventSink, settings.Main.Name, pub)
+	if err := eventBus.RegisterConsumer(f); err != nil {
+		_ = f.Close(0)
+		return fmt.Errorf("failed to register event sink: %w", err)
+	}
+	forwarder = f
+
+	logger.Info("forwarding events to message broker",
+		"broker", strings.ToLower(settings.EventSink.Broker),
+		"address", settings.EventSink.Address,
+		"batch_size", settings.EventSink.BatchSize,
+		"flush_interval", settings.EventSink.FlushInterval)
+	return nil
+}
+
+// newPublisher creates the publisher of the configured broker
+func newPublisher(settings *conf.EventSinkSettings, outboundTLS *conf.OutboundTLSSettings) (publisher, error) {
+	dialer := &brokerDialer{tls: settings.TLS, insecureSkipVerify: settings.InsecureSkipVerify, outboundTLS: outboundTLS}
+	switch strings.ToLower(settings.Broker) {
+	case "nats":
+		return newNATSPublisher(settings, dialer), nil
+	case "kafka":
+		return newKafkaPublisher(settings, dialer), nil
+	case "redis":
+		return newRedisPublisher(settings, dialer), nil
+	default:
+		return nil, fmt.Errorf("unsupported event sink broker %q", settings.Broker)
+	}
+}
+
+// GetStats returns the counters of the running forwarder, false when events are not forwarded
+func GetStats() (Stats, bool) {
+	forwarderMu.Lock()
+	f := forwarder
+	forwarderMu.Unlock()
+	if f == nil {
+		return Stats{}, false
+	}
+	return f.Stats(), true
+}
+
+// Shutdown publishes the queued events until the timeout and stops forwarding. Events
+// arriving afterwards are dropped.
+func Shutdown(timeout time.Duration) error {
+	forwarderMu.Lock()
+	f := forwarder
+	forwarder = nil
+	forwarderMu.Unlock()
+	if f == nil {
+		return nil
+	}
+	return f.Close(timeout)
+}
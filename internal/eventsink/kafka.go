@@ -0,0 +1,531 @@
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Kafka protocol API keys and versions used by the publisher
+const (
+	kafkaAPIProduce       = 0
+	kafkaAPIMetadata      = 3
+	kafkaProduceVersion   = 3 // first version with v2 record batches, the oldest Kafka 4 accepts
+	kafkaMetadataVersion  = 4
+	kafkaProduceTimeoutMs = 10000
+	kafkaAcksAll          = -1
+	kafkaClientID         = "birdnet-go"
+	kafkaMaxResponseSize  = 64 << 20
+)
+
+// kafkaCRC is the CRC-32C table of record batch checksums
+var kafkaCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// kafkaPublisher produces events to Kafka topics with acks from all in-sync replicas. The
+// partition of an event is chosen by its key, the node name, so the events of a node keep
+// their order. Topics are created by the broker when auto creation is enabled.
+type kafkaPublisher struct {
+	bootstrap []string
+	dialer    *brokerDialer
+
+	conns         map[string]*kafkaConn       // connections by broker address
+	brokers       map[int32]string            // broker addresses by node ID
+	partitions    map[string][]kafkaPartition // partitions of topics with a leader
+	correlationID int32
+}
+
+// kafkaPartition is a topic partition and the node ID of its leader
+type kafkaPartition struct {
+	index  int32
+	leader int32
+}
+
+// kafkaConn is a connection to a Kafka broker
+type kafkaConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// newKafkaPublisher creates a Kafka publisher, connecting on the first publish
+func newKafkaPublisher(settings *conf.EventSinkSettings, dialer *brokerDialer) *kafkaPublisher {
+	var bootstrap []string
+	for _, address := range strings.Split(settings.Address, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			bootstrap = append(bootstrap, address)
+		}
+	}
+	return &kafkaPublisher{
+		bootstrap:  bootstrap,
+		dialer:     dialer,
+		conns:      make(map[string]*kafkaConn),
+		brokers:    make(map[int32]string),
+		partitions: make(map[string][]kafkaPartition),
+	}
+}
+
+// kafkaTarget is a topic partition of a produce request
+type kafkaTarget struct {
+	topic     string
+	partition int32
+}
+
+// publish produces the events of a batch, grouped by the leaders of their partitions
+func (p *kafkaPublisher) publish(ctx context.Context, batch []message) error {
+	if err := p.refreshMetadata(ctx, batch); err != nil {
+		return err
+	}
+
+	// Group records by leader broker and topic partition
+	requests := make(map[string]map[kafkaTarget][]*message)
+	for i := range batch {
+		msg := &batch[i]
+		partitions := p.partitions[msg.topic]
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(msg.key))
+		partition := partitions[hash.Sum32()%uint32(len(partitions))] //nolint:gosec // G115: partition counts fit uint32
+		address, ok := p.brokers[partition.leader]
+		if !ok {
+			delete(p.partitions, msg.topic)
+			return fmt.Errorf("no address known for the leader of kafka topic %s partition %d", msg.topic, partition.index)
+		}
+		if requests[address] == nil {
+			requests[address] = make(map[kafkaTarget][]*message)
+		}
+		target := kafkaTarget{topic: msg.topic, partition: partition.index}
+		requests[address][target] = append(requests[address][target], msg)
+	}
+
+	for address, records := range requests {
+		if err := p.produce(ctx, address, records); err != nil {
+			// Leaders may have moved, look them up again on the retry
+			clear(p.partitions)
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshMetadata looks up the partition leaders of the topics of a batch not known yet
+func (p *kafkaPublisher) refreshMetadata(ctx context.Context, batch []message) error {
+	var topics []string
+	for i := range batch {
+		if _, known := p.partitions[batch[i].topic]; !known && !slices.Contains(topics, batch[i].topic) {
+			topics = append(topics, batch[i].topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	for _, address := range p.metadataBrokers() {
+		conn, err := p.connection(ctx, address)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		var req kafkaEncoder
+		req.int32(int32(len(topics))) //nolint:gosec // G115: at most a batch of topics
+		for _, topic := range topics {
+			req.string(topic)
+		}
+		req.int8(1) // allow auto topic creation
+		response, err := p.roundTrip(ctx, address, conn, kafkaAPIMetadata, kafkaMetadataVersion, req.bytes())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return p.parseMetadata(response)
+	}
+	return fmt.Errorf("kafka metadata request failed: %w", lastErr)
+}
+
+// metadataBrokers returns the addresses to request metadata from: known brokers, then the
+// bootstrap brokers
+func (p *kafkaPublisher) metadataBrokers() []string {
+	addresses := make([]string, 0, len(p.brokers)+len(p.bootstrap))
+	for _, address := range p.brokers {
+		addresses = append(addresses, address)
+	}
+	for _, address := range p.bootstrap {
+		if !slices.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// parseMetadata records the brokers and partition leaders of a metadata response
+func (p *kafkaPublisher) parseMetadata(response []byte) error {
+	d := kafkaDecoder{b: response}
+	d.int32() // throttle time
+
+	brokers := make(map[int32]string)
+	for range d.arrayLen() {
+		nodeID := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.nullableString() // rack
+		brokers[nodeID] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.nullableString() // cluster ID
+	d.int32()          // controller ID
+
+	type topicMetadata struct {
+		errorCode  int16
+		partitions []kafkaPartition
+	}
+	topics := make(map[string]topicMetadata)
+	for range d.arrayLen() {
+		errorCode := d.int16()
+		name := d.string()
+		d.int8() // internal
+		var partitions []kafkaPartition
+		for range d.arrayLen() {
+			partitionError := d.int16()
+			index := d.int32()
+			leader := d.int32()
+			for range d.arrayLen() { // replicas
+				d.int32()
+			}
+			for range d.arrayLen() { // in-sync replicas
+				d.int32()
+			}
+			if partitionError == 0 && leader >= 0 {
+				partitions = append(partitions, kafkaPartition{index: index, leader: leader})
+			} else if errorCode == 0 {
+				errorCode = partitionError
+			}
+		}
+		slices.SortFunc(partitions, func(a, b kafkaPartition) int { return int(a.index - b.index) })
+		topics[name] = topicMetadata{errorCode: errorCode, partitions: partitions}
+	}
+	if d.err != nil {
+		return fmt.Errorf("invalid kafka metadata response: %w", d.err)
+	}
+
+	p.brokers = brokers
+	for name, topic := range topics {
+		if len(topic.partitions) == 0 {
+			// Auto created topics report LEADER_NOT_AVAILABLE until their leaders are elected
+			return fmt.Errorf("kafka topic %s is not available: %s", name, kafkaErrorName(topic.errorCode))
+		}
+		p.partitions[name] = topic.partitions
+	}
+	return nil
+}
+
+// produce sends the records of topic partitions led by a broker
+func (p *kafkaPublisher) produce(ctx context.Context, address string, records map[kafkaTarget][]*message) error {
+	conn, err := p.connection(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	byTopic := make(map[string][]kafkaTarget)
+	var topicOrder []string
+	for target := range records {
+		if _, ok := byTopic[target.topic]; !ok {
+			topicOrder = append(topicOrder, target.topic)
+		}
+		byTopic[target.topic] = append(byTopic[target.topic], target)
+	}
+
+	var req kafkaEncoder
+	req.int16(-1) // no transactional ID
+	req.int16(kafkaAcksAll)
+	req.int32(kafkaProduceTimeoutMs)
+	req.int32(int32(len(topicOrder))) //nolint:gosec // G115: at most a batch of topics
+	for _, topic := range topicOrder {
+		req.string(topic)
+		req.int32(int32(len(byTopic[topic]))) //nolint:gosec // G115: at most the partition count
+		for _, target := range byTopic[topic] {
+			req.int32(target.partition)
+			req.bytesField(encodeRecordBatch(records[target]))
+		}
+	}
+
+	response, err := p.roundTrip(ctx, address, conn, kafkaAPIProduce, kafkaProduceVersion, req.bytes())
+	if err != nil {
+		return err
+	}
+
+	d := kafkaDecoder{b: response}
+	for range d.arrayLen() {
+		topic := d.string()
+		for range d.arrayLen() {
+			partition := d.int32()
+			errorCode := d.int16()
+			d.int64() // base offset
+			d.int64() // log append time
+			if errorCode != 0 && d.err == nil {
+				return fmt.Errorf("kafka rejected events for topic %s partition %d: %s", topic, partition, kafkaErrorName(errorCode))
+			}
+		}
+	}
+	if d.err != nil {
+		return fmt.Errorf("invalid kafka produce response: %w", d.err)
+	}
+	return nil
+}
+
+// connection returns the connection to a broker, connecting when needed
+func (p *kafkaPublisher) connection(ctx context.Context, address string) (*kafkaConn, error) {
+	if conn, ok := p.conns[address]; ok {
+		return conn, nil
+	}
+	conn, err := p.dialer.dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	kc := &kafkaConn{conn: conn, reader: bufio.NewReader(conn)}
+	p.conns[address] = kc
+	return kc, nil
+}
+
+// roundTrip sends a request to a broker and returns the response body, closing the
+// connection on failure
+func (p *kafkaPublisher) roundTrip(ctx context.Context, address string, conn *kafkaConn, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	response, err := p.exchange(ctx, conn, apiKey, apiVersion, body)
+	if err != nil {
+		_ = conn.conn.Close()
+		delete(p.conns, address)
+		return nil, fmt.Errorf("kafka request to %s failed: %w", address, err)
+	}
+	return response, nil
+}
+
+// exchange writes a request with a v1 header and reads the response with a v0 header
+func (p *kafkaPublisher) exchange(ctx context.Context, conn *kafkaConn, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	if err := setDeadline(ctx, conn.conn); err != nil {
+		return nil, err
+	}
+
+	p.correlationID++
+	var header kafkaEncoder
+	header.int16(apiKey)
+	header.int16(apiVersion)
+	header.int32(p.correlationID)
+	header.string(kafkaClientID)
+
+	request := make([]byte, 4, 4+len(header.buf)+len(body))
+	binary.BigEndian.PutUint32(request, uint32(len(header.buf)+len(body))) //nolint:gosec // G115: requests are far below 4 GiB
+	request = append(append(request, header.buf...), body...)
+	if _, err := conn.conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(conn.reader, size[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(size[:])
+	if length < 4 || length > kafkaMaxResponseSize {
+		return nil, fmt.Errorf("invalid response size %d", length)
+	}
+	response := make([]byte, length)
+	if _, err := io.ReadFull(conn.reader, response); err != nil {
+		return nil, err
+	}
+	if correlationID := int32(binary.BigEndian.Uint32(response)); correlationID != p.correlationID { //nolint:gosec // G115: wire format
+		return nil, fmt.Errorf("response correlation ID %d does not match request %d", correlationID, p.correlationID)
+	}
+	return response[4:], nil
+}
+
+// Close closes the broker connections
+func (p *kafkaPublisher) Close() error {
+	for address, conn := range p.conns {
+		_ = conn.conn.Close()
+		delete(p.conns, address)
+	}
+	return nil
+}
+
+// encodeRecordBatch encodes messages as an uncompressed v2 record batch
+func encodeRecordBatch(messages []*message) []byte {
+	baseTimestamp, maxTimestamp := messages[0].time.UnixMilli(), messages[0].time.UnixMilli()
+	for _, msg := range messages {
+		baseTimestamp = min(baseTimestamp, msg.time.UnixMilli())
+		maxTimestamp = max(maxTimestamp, msg.time.UnixMilli())
+	}
+
+	var records kafkaEncoder
+	for i, msg := range messages {
+		timestamp := msg.time.UnixMilli()
+		var record kafkaEncoder
+		record.int8(0) // attributes
+		record.varint(timestamp - baseTimestamp)
+		record.varint(int64(i)) // offset delta
+		record.varint(int64(len(msg.key)))
+		record.buf = append(record.buf, msg.key...)
+		record.varint(int64(len(msg.payload)))
+		record.buf = append(record.buf, msg.payload...)
+		record.varint(0) // headers
+
+		records.varint(int64(len(record.buf)))
+		records.buf = append(records.buf, record.buf...)
+	}
+
+	// The checksum covers everything from the attributes to the end of the batch
+	var checked kafkaEncoder
+	checked.int16(0)                        // attributes: no compression, create time
+	checked.int32(int32(len(messages) - 1)) //nolint:gosec // G115: last offset delta of a batch
+	checked.int64(baseTimestamp)
+	checked.int64(maxTimestamp)
+	checked.int64(-1)                   // producer ID
+	checked.int16(-1)                   // producer epoch
+	checked.int32(-1)                   // base sequence
+	checked.int32(int32(len(messages))) //nolint:gosec // G115: records of a batch
+	checked.buf = append(checked.buf, records.buf...)
+
+	var batch kafkaEncoder
+	batch.int64(0)                                            // base offset
+	batch.int32(int32(4 + 1 + 4 + len(checked.buf)))          //nolint:gosec // G115: batch length after this field
+	batch.int32(-1)                                           // partition leader epoch
+	batch.int8(2)                                             // magic
+	batch.int32(int32(crc32.Checksum(checked.buf, kafkaCRC))) //nolint:gosec // G115: unsigned checksum on the wire
+	batch.buf = append(batch.buf, checked.buf...)
+	return batch.buf
+}
+
+// kafkaEncoder encodes Kafka protocol primitives
+type kafkaEncoder struct {
+	buf []byte
+}
+
+func (e *kafkaEncoder) int8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *kafkaEncoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) } //nolint:gosec // G115: two's complement on the wire
+func (e *kafkaEncoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) } //nolint:gosec // G115: two's complement on the wire
+func (e *kafkaEncoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) } //nolint:gosec // G115: two's complement on the wire
+
+// varint appends a zigzag encoded variable length integer
+func (e *kafkaEncoder) varint(v int64) { e.buf = binary.AppendVarint(e.buf, v) }
+
+// string appends a string with an int16 length
+func (e *kafkaEncoder) string(s string) {
+	e.int16(int16(len(s))) //nolint:gosec // G115: topics and client IDs are short
+	e.buf = append(e.buf, s...)
+}
+
+// bytesField appends bytes with an int32 length
+func (e *kafkaEncoder) bytesField(b []byte) {
+	e.int32(int32(len(b))) //nolint:gosec // G115: record batches are far below 2 GiB
+	e.buf = append(e.buf, b...)
+}
+
+func (e *kafkaEncoder) bytes() []byte { return e.buf }
+
+// kafkaDecoder decodes Kafka protocol primitives, recording the first error and returning
+// zero values after it
+type kafkaDecoder struct {
+	b   []byte
+	off int
+	err error
+}
+
+// next returns the next n bytes
+func (d *kafkaDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.off+n > len(d.b) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.b[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *kafkaDecoder) int8() int8 {
+	if b := d.next(1); b != nil {
+		return int8(b[0]) //nolint:gosec // G115: two's complement on the wire
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	if b := d.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b)) //nolint:gosec // G115: two's complement on the wire
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	if b := d.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b)) //nolint:gosec // G115: two's complement on the wire
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	if b := d.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b)) //nolint:gosec // G115: two's complement on the wire
+	}
+	return 0
+}
+
+// string decodes a string with an int16 length
+func (d *kafkaDecoder) string() string {
+	return string(d.next(int(d.int16())))
+}
+
+// nullableString decodes a string with an int16 length, -1 for null
+func (d *kafkaDecoder) nullableString() string {
+	length := d.int16()
+	if length < 0 {
+		return ""
+	}
+	return string(d.next(int(length)))
+}
+
+// arrayLen decodes an array length, 0 for null arrays and after errors
+func (d *kafkaDecoder) arrayLen() int {
+	length := d.int32()
+	if length < 0 || d.err != nil {
+		return 0
+	}
+	if int(length) > len(d.b)-d.off {
+		d.err = fmt.Errorf("array length %d exceeds the response", length)
+		return 0
+	}
+	return int(length)
+}
+
+// kafkaErrorName returns the name of common Kafka error codes
+func kafkaErrorName(code int16) string {
+	switch code {
+	case 0:
+		return "NONE"
+	case 3:
+		return "UNKNOWN_TOPIC_OR_PARTITION"
+	case 5:
+		return "LEADER_NOT_AVAILABLE"
+	case 6:
+		return "NOT_LEADER_OR_FOLLOWER"
+	case 7:
+		return "REQUEST_TIMED_OUT"
+	case 10:
+		return "MESSAGE_TOO_LARGE"
+	case 17:
+		return "INVALID_TOPIC_EXCEPTION"
+	case 19:
+		return "NOT_ENOUGH_REPLICAS"
+	case 29:
+		return "TOPIC_AUTHORIZATION_FAILED"
+	default:
+		return "error code " + strconv.Itoa(int(code))
+	}
+}
@@ -0,0 +1,230 @@
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// kafkaProduced is a record batch received by the fake Kafka broker
+type kafkaProduced struct {
+	topic     string
+	partition int32
+	batch     []byte
+}
+
+// startFakeKafka serves a single node Kafka cluster whose topics have two partitions.
+// Produce requests are answered with errorCode and their record batches sent on the
+// returned channel.
+func startFakeKafka(t *testing.T, errorCode int16) (address string, produced <-chan kafkaProduced) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	host, portString, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portString)
+
+	batches := make(chan kafkaProduced, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeKafka(conn, host, int32(port), errorCode, batches) //nolint:gosec // G115: port numbers fit int32
+		}
+	}()
+	return listener.Addr().String(), batches
+}
+
+// serveFakeKafka answers the Metadata and Produce requests of a connection
+func serveFakeKafka(conn net.Conn, host string, port int32, errorCode int16, batches chan<- kafkaProduced) {
+	defer func() { _ = conn.Close() }()
+	reader := bufio.NewReader(conn)
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			return
+		}
+		request := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(reader, request); err != nil {
+			return
+		}
+		d := kafkaDecoder{b: request}
+		apiKey := d.int16()
+		d.int16() // version
+		correlationID := d.int32()
+		d.string() // client ID
+
+		var response kafkaEncoder
+		response.int32(correlationID)
+		switch apiKey {
+		case kafkaAPIMetadata:
+			var topics []string
+			for range d.arrayLen() {
+				topics = append(topics, d.string())
+			}
+			response.int32(0) // throttle time
+			response.int32(1) // brokers
+			response.int32(1)
+			response.string(host)
+			response.int32(port)
+			response.int16(-1)                 // rack
+			response.int16(-1)                 // cluster ID
+			response.int32(1)                  // controller
+			response.int32(int32(len(topics))) //nolint:gosec // G115: test topics
+			for _, topic := range topics {
+				response.int16(0)
+				response.string(topic)
+				response.int8(0)
+				response.int32(2)
+				for partition := range int32(2) {
+					response.int16(0)
+					response.int32(partition)
+					response.int32(1) // leader
+					response.int32(1) // replicas
+					response.int32(1)
+					response.int32(1) // in-sync replicas
+					response.int32(1)
+				}
+			}
+		case kafkaAPIProduce:
+			d.int16() // transactional ID
+			d.int16() // acks
+			d.int32() // timeout
+			var results []kafkaProduced
+			for range d.arrayLen() {
+				topic := d.string()
+				for range d.arrayLen() {
+					partition := d.int32()
+					batch := d.next(int(d.int32()))
+					results = append(results, kafkaProduced{topic: topic, partition: partition, batch: batch})
+				}
+			}
+			response.int32(int32(len(results))) //nolint:gosec // G115: test results
+			for _, result := range results {
+				batches <- result
+				response.string(result.topic)
+				response.int32(1)
+				response.int32(result.partition)
+				response.int16(errorCode)
+				response.int64(0)
+				response.int64(-1)
+			}
+			response.int32(0) // throttle time
+		default:
+			return
+		}
+
+		frame := binary.BigEndian.AppendUint32(nil, uint32(len(response.buf))) //nolint:gosec // G115: test responses
+		if _, err := conn.Write(append(frame, response.buf...)); err != nil {
+			return
+		}
+	}
+}
+
+// kafkaRecord is a decoded record of a record batch
+type kafkaRecord struct {
+	timestamp int64
+	key       string
+	value     string
+}
+
+// decodeRecordBatch checks the header and checksum of a v2 record batch and decodes its records
+func decodeRecordBatch(t *testing.T, batch []byte) []kafkaRecord {
+	t.Helper()
+	d := kafkaDecoder{b: batch}
+	assert.Equal(t, int64(0), d.int64(), "base offset")
+	assert.Equal(t, int32(len(batch)-12), d.int32(), "batch length") //nolint:gosec // G115: test batches
+	d.int32()                                                        // partition leader epoch
+	require.Equal(t, int8(2), d.int8(), "magic")
+	checksum := uint32(d.int32()) //nolint:gosec // G115: unsigned checksum on the wire
+	require.Equal(t, crc32.Checksum(batch[d.off:], kafkaCRC), checksum, "CRC-32C")
+
+	assert.Equal(t, int16(0), d.int16(), "attributes")
+	lastOffsetDelta := d.int32()
+	baseTimestamp := d.int64()
+	d.int64() // max timestamp
+	assert.Equal(t, int64(-1), d.int64(), "producer ID")
+	d.int16() // producer epoch
+	d.int32() // base sequence
+	count := d.int32()
+	assert.Equal(t, count-1, lastOffsetDelta)
+
+	records := make([]kafkaRecord, 0, count)
+	varint := func() int64 {
+		v, n := binary.Varint(d.b[d.off:])
+		require.Positive(t, n)
+		d.off += n
+		return v
+	}
+	for range count {
+		varint() // length
+		d.int8() // attributes
+		timestampDelta := varint()
+		varint() // offset delta
+		key := string(d.next(int(varint())))
+		value := string(d.next(int(varint())))
+		assert.Equal(t, int64(0), varint(), "headers")
+		records = append(records, kafkaRecord{timestamp: baseTimestamp + timestampDelta, key: key, value: value})
+	}
+	require.NoError(t, d.err)
+	assert.Equal(t, len(batch), d.off, "no trailing bytes")
+	return records
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	t.Parallel()
+
+	address, produced := startFakeKafka(t, 0)
+	p := newKafkaPublisher(&conf.EventSinkSettings{Address: "127.0.0.1:1, " + address}, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	batch := append(testMessages(), message{
+		id: "id-3", kind: kindError, topic: "birdnet.node.errors", key: "node",
+		time: time.UnixMilli(1699999999000), payload: []byte(`{"n":3}`),
+	})
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.publish(ctx, batch), "unreachable bootstrap brokers are skipped")
+
+	byTopic := make(map[string]kafkaProduced)
+	for range 2 {
+		result := <-produced
+		byTopic[result.topic] = result
+	}
+	errors := byTopic["birdnet.node.errors"]
+	detections := byTopic["birdnet.node.detections"]
+	assert.Equal(t, errors.partition, detections.partition, "events of a node share the partition of its key")
+
+	records := decodeRecordBatch(t, errors.batch)
+	assert.Equal(t, []kafkaRecord{
+		{timestamp: 1700000000000, key: "node", value: `{"n":1}`},
+		{timestamp: 1699999999000, key: "node", value: `{"n":3}`},
+	}, records)
+	assert.Equal(t, []kafkaRecord{{timestamp: 1700000000500, key: "node", value: `{"n":2}`}}, decodeRecordBatch(t, detections.batch))
+}
+
+func TestKafkaPublisherProduceError(t *testing.T) {
+	t.Parallel()
+
+	address, _ := startFakeKafka(t, 6)
+	p := newKafkaPublisher(&conf.EventSinkSettings{Address: address}, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	err := p.publish(ctx, testMessages()[:1])
+	require.ErrorContains(t, err, "NOT_LEADER_OR_FOLLOWER")
+	assert.Empty(t, p.partitions, "leaders are looked up again after produce errors")
+}
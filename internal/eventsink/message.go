@@ -0,0 +1,173 @@
+package eventsink
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// Event types of forwarded events
+const (
+	kindError     = "error"
+	kindResource  = "resource"
+	kindDetection = "detection"
+)
+
+// envelope is the JSON document published for an event
+type envelope struct {
+	ID        string            `json:"id"`   // unique event ID, the same when an event is redelivered
+	Type      string            `json:"type"` // error, resource or detection
+	Node      string            `json:"node"` // name of the BirdNET-Go node
+	Time      time.Time         `json:"time"`
+	Error     *errorPayload     `json:"error,omitempty"`
+	Resource  *resourcePayload  `json:"resource,omitempty"`
+	Detection *detectionPayload `json:"detection,omitempty"`
+}
+
+// errorPayload describes an error event. Messages and context values are scrubbed of
+// sensitive data with the privacy policy.
+type errorPayload struct {
+	Component string         `json:"component"`
+	Category  string         `json:"category"`
+	Message   string         `json:"message"`
+	Context   map[string]any `json:"context,omitempty"`
+}
+
+// resourcePayload describes a resource monitoring event
+type resourcePayload struct {
+	Resource  string         `json:"resource"` // cpu, memory or disk
+	Value     float64        `json:"value"`    // usage percentage
+	Threshold float64        `json:"threshold"`
+	Severity  string         `json:"severity"` // warning, critical or recovery
+	Message   string         `json:"message"`
+	Path      string         `json:"path,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// detectionPayload describes a detection event. Saved detections carry the note ID in their
+// metadata, new and rare species events duplicate a saved detection.
+type detectionPayload struct {
+	Species            string         `json:"species"`
+	ScientificName     string         `json:"scientificName"`
+	Confidence         float64        `json:"confidence"`
+	Source             string         `json:"source,omitempty"`
+	NewSpecies         bool           `json:"newSpecies"`
+	DaysSinceFirstSeen int            `json:"daysSinceFirstSeen"`
+	Metadata           map[string]any `json:"metadata,omitempty"`
+}
+
+// errorMessage encodes an error event
+func errorMessage(event events.ErrorEvent, node, topic string) (message, error) {
+	context := make(map[string]any, len(event.GetContext()))
+	for key, value := range event.GetContext() {
+		if s, ok := value.(string); ok {
+			value = privacy.ScrubMessage(s)
+		}
+		context[key] = value
+	}
+	return newMessage(kindError, node, event.GetTimestamp(),
+		expandTopic(topic, node, "{component}", event.GetComponent()),
+		func(e *envelope) {
+			e.Error = &errorPayload{
+				Component: event.GetComponent(),
+				Category:  event.GetCategory(),
+				Message:   privacy.ScrubMessage(event.GetMessage()),
+				Context:   context,
+			}
+		})
+}
+
+// resourceMessage encodes a resource monitoring event
+func resourceMessage(event events.ResourceEvent, node, topic string) (message, error) {
+	return newMessage(kindResource, node, event.GetTimestamp(),
+		expandTopic(topic, node, "{resource}", event.GetResourceType()),
+		func(e *envelope) {
+			e.Resource = &resourcePayload{
+				Resource:  event.GetResourceType(),
+				Value:     event.GetCurrentValue(),
+				Threshold: event.GetThreshold(),
+				Severity:  event.GetSeverity(),
+				Message:   event.GetMessage(),
+				Path:      event.GetPath(),
+				Metadata:  event.GetMetadata(),
+			}
+		})
+}
+
+// detectionMessage encodes a detection event
+func detectionMessage(event events.DetectionEvent, node, topic string) (message, error) {
+	return newMessage(kindDetection, node, event.GetTimestamp(),
+		expandTopic(topic, node, "{species}", event.GetScientificName()),
+		func(e *envelope) {
+			e.Detection = &detectionPayload{
+				Species:            event.GetSpeciesName(),
+				ScientificName:     event.GetScientificName(),
+				Confidence:         event.GetConfidence(),
+				Source:             event.GetLocation(),
+				NewSpecies:         event.IsNewSpecies(),
+				DaysSinceFirstSeen: event.GetDaysSinceFirstSeen(),
+				Metadata:           event.GetMetadata(),
+			}
+		})
+}
+
+// newMessage encodes the envelope of an event filled in by fill
+func newMessage(kind, node string, timestamp time.Time, topic string, fill func(*envelope)) (message, error) {
+	id, err := newEventID()
+	if err != nil {
+		return message{}, err
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	e := envelope{ID: id, Type: kind, Node: node, Time: timestamp.UTC()}
+	fill(&e)
+	payload, err := json.Marshal(&e)
+	if err != nil {
+		return message{}, fmt.Errorf("failed to encode %s event: %w", kind, err)
+	}
+	return message{id: id, kind: kind, topic: topic, key: node, time: timestamp, payload: payload}, nil
+}
+
+// newEventID returns a random event ID
+func newEventID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate event ID: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// expandTopic replaces the {node} placeholder and the placeholder of the event type with
+// topic-safe forms of their values
+func expandTopic(topic, node, placeholder, value string) string {
+	if !strings.Contains(topic, "{") {
+		return topic
+	}
+	return strings.NewReplacer("{node}", topicToken(node), placeholder, topicToken(value)).Replace(topic)
+}
+
+// topicToken returns a value as a lower-case topic token of letters, digits, '_' and '-',
+// valid in Kafka topics, NATS subjects and Redis keys alike
+func topicToken(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "unknown"
+	}
+	return b.String()
+}
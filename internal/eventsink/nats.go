@@ -0,0 +1,342 @@
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// natsMaxControlLine bounds the protocol lines read from a NATS server
+const natsMaxControlLine = 4096
+
+// natsPublisher publishes events with the NATS client protocol. Without JetStream a batch is
+// published once the server answers a PING sent after it. With JetStream every event is
+// acknowledged by the stream storing it, and carries a Nats-Msg-Id header so the stream
+// discards redelivered events within its duplicate window.
+type natsPublisher struct {
+	address   string
+	username  string
+	password  string
+	jetStream bool
+	dialer    *brokerDialer
+
+	conn   net.Conn
+	reader *bufio.Reader
+	inbox  string // subject prefix of JetStream acknowledgements
+}
+
+// newNATSPublisher creates a NATS publisher, connecting on the first publish
+func newNATSPublisher(settings *conf.EventSinkSettings, dialer *brokerDialer) *natsPublisher {
+	return &natsPublisher{
+		address:   strings.TrimSpace(settings.Address),
+		username:  settings.Username,
+		password:  settings.Password,
+		jetStream: settings.JetStream,
+		dialer:    dialer,
+	}
+}
+
+// natsInfo is the part of the INFO message of a NATS server used by the publisher
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	Headers     bool `json:"headers"`
+}
+
+// natsConnect is the CONNECT message of the publisher
+type natsConnect struct {
+	Verbose      bool   `json:"verbose"`
+	Pedantic     bool   `json:"pedantic"`
+	Name         string `json:"name"`
+	Lang         string `json:"lang"`
+	Version      string `json:"version"`
+	Protocol     int    `json:"protocol"`
+	Headers      bool   `json:"headers"`
+	NoResponders bool   `json:"no_responders"` // empty replies instead of timeouts without a stream
+	User         string `json:"user,omitempty"`
+	Pass         string `json:"pass,omitempty"`
+	AuthToken    string `json:"auth_token,omitempty"`
+}
+
+// natsPubAck is the JetStream acknowledgement of a published message
+type natsPubAck struct {
+	Stream string `json:"stream"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+// publish sends a batch and waits for the server, or for JetStream, to acknowledge it
+func (p *natsPublisher) publish(ctx context.Context, batch []message) error {
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+	if err := p.publishBatch(ctx, batch); err != nil {
+		p.disconnect()
+		return err
+	}
+	return nil
+}
+
+// publishBatch writes the messages of a batch and reads the acknowledgements
+func (p *natsPublisher) publishBatch(ctx context.Context, batch []message) error {
+	if err := setDeadline(ctx, p.conn); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(p.conn)
+	for i := range batch {
+		msg := &batch[i]
+		if p.jetStream {
+			header := "NATS/1.0\r\nNats-Msg-Id: " + msg.id + "\r\n\r\n"
+			fmt.Fprintf(w, "HPUB %s %s.%d %d %d\r\n%s", msg.topic, p.inbox, i, len(header), len(header)+len(msg.payload), header)
+		} else {
+			fmt.Fprintf(w, "PUB %s %d\r\n", msg.topic, len(msg.payload))
+		}
+		_, _ = w.Write(msg.payload)
+		_, _ = w.WriteString("\r\n")
+	}
+	if !p.jetStream {
+		_, _ = w.WriteString("PING\r\n")
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+
+	if !p.jetStream {
+		return p.readUntilPong()
+	}
+
+	acked := make([]bool, len(batch))
+	for remaining := len(batch); remaining > 0; {
+		subject, payload, err := p.readMsg()
+		if err != nil {
+			return err
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(subject, p.inbox+"."))
+		if err != nil || index < 0 || index >= len(batch) || acked[index] {
+			continue // not an acknowledgement of this batch
+		}
+
+		if len(payload) == 0 {
+			// No responders: no stream captures the subject
+			return fmt.Errorf("no JetStream stream stores subject %s", batch[index].topic)
+		}
+		var ack natsPubAck
+		if err := json.Unmarshal(payload, &ack); err != nil {
+			return fmt.Errorf("invalid JetStream acknowledgement: %w", err)
+		}
+		if ack.Error != nil {
+			return fmt.Errorf("JetStream rejected event for %s: %s (%d)", batch[index].topic, ack.Error.Description, ack.Error.Code)
+		}
+		if ack.Stream == "" {
+			return fmt.Errorf("JetStream acknowledgement without a stream for %s", batch[index].topic)
+		}
+		acked[index] = true
+		remaining--
+	}
+	return nil
+}
+
+// connect opens the connection, upgrading to TLS and authenticating as the server requires
+func (p *natsPublisher) connect(ctx context.Context) error {
+	if p.conn != nil {
+		return nil
+	}
+
+	conn, err := p.dialer.dialPlain(ctx, p.address)
+	if err != nil {
+		return err
+	}
+	if err := setDeadline(ctx, conn); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	// The server greets with INFO before the TLS handshake
+	reader := bufio.NewReaderSize(conn, natsMaxControlLine)
+	line, err := readLine(reader)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to read NATS server info: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		_ = conn.Close()
+		return fmt.Errorf("unexpected NATS greeting %q", truncate(line))
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("invalid NATS server info: %w", err)
+	}
+	switch {
+	case info.TLSRequired && !p.dialer.tls:
+		_ = conn.Close()
+		return fmt.Errorf("NATS server %s requires TLS", p.address)
+	case p.jetStream && !info.Headers:
+		_ = conn.Close()
+		return fmt.Errorf("NATS server %s does not support headers required by JetStream publishing", p.address)
+	}
+	if p.dialer.tls {
+		if conn, err = p.dialer.secure(ctx, conn, p.address); err != nil {
+			return err
+		}
+		reader = bufio.NewReaderSize(conn, natsMaxControlLine)
+	}
+
+	connectMsg := natsConnect{Name: "birdnet-go", Lang: "go", Version: "1.0.0", Protocol: 1, Headers: p.jetStream, NoResponders: p.jetStream}
+	if p.username != "" {
+		connectMsg.User, connectMsg.Pass = p.username, p.password
+	} else {
+		connectMsg.AuthToken = p.password
+	}
+	connectJSON, err := json.Marshal(&connectMsg)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	p.conn, p.reader = conn, reader
+	commands := "CONNECT " + string(connectJSON) + "\r\n"
+	if p.jetStream {
+		inboxID, err := newEventID()
+		if err != nil {
+			p.disconnect()
+			return err
+		}
+		p.inbox = "_INBOX." + inboxID
+		commands += "SUB " + p.inbox + ".* 1\r\n"
+	}
+	if _, err := io.WriteString(conn, commands+"PING\r\n"); err != nil {
+		p.disconnect()
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	if err := p.readUntilPong(); err != nil {
+		p.disconnect()
+		return err
+	}
+	return nil
+}
+
+// readUntilPong reads server messages until the PONG answering a PING
+func (p *natsPublisher) readUntilPong() error {
+	for {
+		line, err := p.readControl()
+		if err != nil {
+			return err
+		}
+		if line == "PONG" {
+			return nil
+		}
+		if strings.HasPrefix(line, "MSG ") || strings.HasPrefix(line, "HMSG ") {
+			if _, _, err := p.readPayload(line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readMsg reads server messages until a message delivered to the publisher
+func (p *natsPublisher) readMsg() (subject string, payload []byte, err error) {
+	for {
+		line, err := p.readControl()
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.HasPrefix(line, "MSG ") || strings.HasPrefix(line, "HMSG ") {
+			return p.readPayload(line)
+		}
+	}
+}
+
+// readControl reads the next protocol line, answering server PINGs and failing on -ERR
+func (p *natsPublisher) readControl() (string, error) {
+	for {
+		line, err := readLine(p.reader)
+		if err != nil {
+			return "", fmt.Errorf("failed to read from NATS: %w", err)
+		}
+		switch {
+		case line == "PING":
+			if _, err := io.WriteString(p.conn, "PONG\r\n"); err != nil {
+				return "", fmt.Errorf("failed to answer NATS ping: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return "", fmt.Errorf("NATS server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case line == "+OK", strings.HasPrefix(line, "INFO "):
+		default:
+			return line, nil
+		}
+	}
+}
+
+// readPayload reads the payload of a MSG or HMSG line, without the headers of HMSG
+func (p *natsPublisher) readPayload(line string) (subject string, payload []byte, err error) {
+	// MSG <subject> <sid> [reply-to] <#bytes>
+	// HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+	fields := strings.Fields(line)
+	headers := fields[0] == "HMSG"
+	minFields := 4
+	if headers {
+		minFields = 5
+	}
+	if len(fields) < minFields {
+		return "", nil, fmt.Errorf("invalid NATS message line %q", truncate(line))
+	}
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	headerSize := 0
+	if err == nil && headers {
+		headerSize, err = strconv.Atoi(fields[len(fields)-2])
+	}
+	if err != nil || total < 0 || headerSize < 0 || headerSize > total {
+		return "", nil, fmt.Errorf("invalid NATS message line %q", truncate(line))
+	}
+
+	data := make([]byte, total+2)
+	if _, err := io.ReadFull(p.reader, data); err != nil {
+		return "", nil, fmt.Errorf("failed to read NATS message: %w", err)
+	}
+	return fields[1], data[headerSize:total], nil
+}
+
+// disconnect closes the connection after a failure, the next publish reconnects
+func (p *natsPublisher) disconnect() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.reader = nil, nil
+}
+
+// Close closes the connection
+func (p *natsPublisher) Close() error {
+	p.disconnect()
+	return nil
+}
+
+// readLine reads a CRLF terminated line without its terminator
+func readLine(reader *bufio.Reader) (string, error) {
+	line, isPrefix, err := reader.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	if isPrefix {
+		return "", fmt.Errorf("protocol line longer than %d bytes", reader.Size())
+	}
+	return string(line), nil
+}
+
+// truncate shortens protocol lines quoted in errors
+func truncate(line string) string {
+	const maxLength = 80
+	if len(line) > maxLength {
+		return line[:maxLength] + "..."
+	}
+	return line
+}
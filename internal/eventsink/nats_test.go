@@ -0,0 +1,131 @@
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// natsPublished is a message received by the fake NATS server
+type natsPublished struct {
+	subject string
+	header  string
+	payload string
+}
+
+// startFakeNATS serves one NATS client connection, acknowledging HPUB messages like a
+// JetStream stream unless noStream is set. Published messages are sent on the returned channel.
+func startFakeNATS(t *testing.T, noStream bool) (address string, published <-chan natsPublished) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	messages := make(chan natsPublished, 16)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"fake\",\"headers\":true}\r\n")
+
+		for {
+			line, err := readLine(reader)
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 0:
+			case fields[0] == "PING":
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			case fields[0] == "PUB" || fields[0] == "HPUB":
+				total, _ := strconv.Atoi(fields[len(fields)-1])
+				headerSize := 0
+				if fields[0] == "HPUB" {
+					headerSize, _ = strconv.Atoi(fields[len(fields)-2])
+				}
+				data := make([]byte, total+2)
+				if _, err := io.ReadFull(reader, data); err != nil {
+					return
+				}
+				messages <- natsPublished{subject: fields[1], header: string(data[:headerSize]), payload: string(data[headerSize:total])}
+
+				if fields[0] == "HPUB" {
+					ack := `{"stream":"EVENTS","seq":1}`
+					if noStream {
+						ack = ""
+					}
+					_, _ = fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+				}
+			}
+		}
+	}()
+	return listener.Addr().String(), messages
+}
+
+func testMessages() []message {
+	return []message{
+		{id: "id-1", kind: kindError, topic: "birdnet.node.errors", key: "node", time: time.UnixMilli(1700000000000), payload: []byte(`{"n":1}`)},
+		{id: "id-2", kind: kindDetection, topic: "birdnet.node.detections", key: "node", time: time.UnixMilli(1700000000500), payload: []byte(`{"n":2}`)},
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	t.Parallel()
+
+	address, published := startFakeNATS(t, false)
+	p := newNATSPublisher(&conf.EventSinkSettings{Address: address}, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.publish(ctx, testMessages()))
+
+	first, second := <-published, <-published
+	assert.Equal(t, natsPublished{subject: "birdnet.node.errors", payload: `{"n":1}`}, first)
+	assert.Equal(t, natsPublished{subject: "birdnet.node.detections", payload: `{"n":2}`}, second)
+}
+
+func TestNATSPublisherJetStream(t *testing.T) {
+	t.Parallel()
+
+	address, published := startFakeNATS(t, false)
+	p := newNATSPublisher(&conf.EventSinkSettings{Address: address, JetStream: true}, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.publish(ctx, testMessages()))
+
+	first := <-published
+	assert.Equal(t, "birdnet.node.errors", first.subject)
+	assert.Contains(t, first.header, "Nats-Msg-Id: id-1\r\n", "events carry their ID for deduplication")
+	assert.Equal(t, `{"n":1}`, first.payload)
+}
+
+func TestNATSPublisherJetStreamWithoutStream(t *testing.T) {
+	t.Parallel()
+
+	address, _ := startFakeNATS(t, true)
+	p := newNATSPublisher(&conf.EventSinkSettings{Address: address, JetStream: true}, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	err := p.publish(ctx, testMessages())
+	require.ErrorContains(t, err, "no JetStream stream stores subject birdnet.node.errors")
+	assert.Nil(t, p.conn, "failed publishes reconnect")
+}
@@ -0,0 +1,192 @@
+package eventsink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// redisPublisher appends events to Redis streams with pipelined XADD commands. A batch is
+// published once Redis has answered every command with the ID of the added entry.
+type redisPublisher struct {
+	address      string
+	username     string
+	password     string
+	streamMaxLen int
+	dialer       *brokerDialer
+
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// newRedisPublisher creates a Redis publisher, connecting on the first publish
+func newRedisPublisher(settings *conf.EventSinkSettings, dialer *brokerDialer) *redisPublisher {
+	return &redisPublisher{
+		address:      strings.TrimSpace(settings.Address),
+		username:     settings.Username,
+		password:     settings.Password,
+		streamMaxLen: settings.StreamMaxLen,
+		dialer:       dialer,
+	}
+}
+
+// publish adds the events of a batch to their streams as entries with id, type and event fields
+func (p *redisPublisher) publish(ctx context.Context, batch []message) error {
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+	if err := setDeadline(ctx, p.conn); err != nil {
+		p.disconnect()
+		return err
+	}
+
+	var commands bytes.Buffer
+	for i := range batch {
+		msg := &batch[i]
+		args := []string{"XADD", msg.topic}
+		if p.streamMaxLen > 0 {
+			args = append(args, "MAXLEN", "~", strconv.Itoa(p.streamMaxLen))
+		}
+		args = append(args, "*", "id", msg.id, "type", msg.kind, "event", string(msg.payload))
+		writeRESPCommand(&commands, args...)
+	}
+	if _, err := p.conn.Write(commands.Bytes()); err != nil {
+		p.disconnect()
+		return fmt.Errorf("failed to publish to Redis: %w", err)
+	}
+
+	// Read every reply to keep the connection in sync, failing with the first error
+	var firstErr error
+	for i := range batch {
+		if _, err := readRESPReply(p.reader); err != nil {
+			var replyErr redisError
+			if !errors.As(err, &replyErr) {
+				p.disconnect()
+				return err
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("redis rejected event for stream %s: %w", batch[i].topic, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// connect opens the connection and authenticates when a password is configured
+func (p *redisPublisher) connect(ctx context.Context) error {
+	if p.conn != nil {
+		return nil
+	}
+
+	conn, err := p.dialer.dial(ctx, p.address)
+	if err != nil {
+		return err
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+	if p.password == "" {
+		return nil
+	}
+
+	if err := setDeadline(ctx, conn); err != nil {
+		p.disconnect()
+		return err
+	}
+	var auth bytes.Buffer
+	if p.username != "" {
+		writeRESPCommand(&auth, "AUTH", p.username, p.password)
+	} else {
+		writeRESPCommand(&auth, "AUTH", p.password)
+	}
+	if _, err := conn.Write(auth.Bytes()); err != nil {
+		p.disconnect()
+		return fmt.Errorf("failed to authenticate with Redis: %w", err)
+	}
+	if _, err := readRESPReply(p.reader); err != nil {
+		p.disconnect()
+		return fmt.Errorf("redis authentication failed: %w", err)
+	}
+	return nil
+}
+
+// disconnect closes the connection after a failure, the next publish reconnects
+func (p *redisPublisher) disconnect() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.reader = nil, nil
+}
+
+// Close closes the connection
+func (p *redisPublisher) Close() error {
+	p.disconnect()
+	return nil
+}
+
+// redisError is an error reply of Redis
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// writeRESPCommand encodes a command as a RESP array of bulk strings
+func writeRESPCommand(b *bytes.Buffer, args ...string) {
+	fmt.Fprintf(b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// readRESPReply reads a RESP2 reply, returning simple and bulk strings and integers as
+// strings. Error replies are returned as redisError.
+func readRESPReply(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read from Redis: %w", err)
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	if line == "" {
+		return "", fmt.Errorf("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", redisError(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid Redis bulk length %q", truncate(line))
+		}
+		if size < 0 {
+			return "", nil // null bulk string
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return "", fmt.Errorf("failed to read from Redis: %w", err)
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid Redis array length %q", truncate(line))
+		}
+		for range max(count, 0) {
+			if _, err := readRESPReply(reader); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected Redis reply %q", truncate(line))
+	}
+}
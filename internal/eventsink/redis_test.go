@@ -0,0 +1,150 @@
+package eventsink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// startFakeRedis serves one Redis client connection, answering AUTH with OK and XADD with
+// an entry ID, or with an error for streams named "rejected". Received commands are sent on
+// the returned channel.
+func startFakeRedis(t *testing.T) (address string, commands <-chan []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan []string, 16)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+
+		for {
+			args, err := readRESPCommand(reader)
+			if err != nil {
+				return
+			}
+			received <- args
+			switch {
+			case args[0] == "AUTH":
+				_, _ = io.WriteString(conn, "+OK\r\n")
+			case args[1] == "rejected":
+				_, _ = io.WriteString(conn, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n")
+			default:
+				_, _ = io.WriteString(conn, "$15\r\n1700000000000-0\r\n")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+// readRESPCommand reads a command sent as a RESP array of bulk strings
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimPrefix(line, "*"))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(line, "$"))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func TestRedisPublisher(t *testing.T) {
+	t.Parallel()
+
+	address, commands := startFakeRedis(t)
+	settings := &conf.EventSinkSettings{Address: address, Username: "birdnet", Password: "secret", StreamMaxLen: 1000}
+	p := newRedisPublisher(settings, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.publish(ctx, testMessages()))
+
+	assert.Equal(t, []string{"AUTH", "birdnet", "secret"}, <-commands)
+	assert.Equal(t, []string{"XADD", "birdnet.node.errors", "MAXLEN", "~", "1000", "*", "id", "id-1", "type", kindError, "event", `{"n":1}`}, <-commands)
+	assert.Equal(t, []string{"XADD", "birdnet.node.detections", "MAXLEN", "~", "1000", "*", "id", "id-2", "type", kindDetection, "event", `{"n":2}`}, <-commands)
+}
+
+func TestRedisPublisherRejectedEntry(t *testing.T) {
+	t.Parallel()
+
+	address, _ := startFakeRedis(t)
+	p := newRedisPublisher(&conf.EventSinkSettings{Address: address}, &brokerDialer{})
+	t.Cleanup(func() { _ = p.Close() })
+
+	batch := testMessages()
+	batch[0].topic = "rejected"
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	err := p.publish(ctx, batch)
+	require.ErrorContains(t, err, "redis rejected event for stream rejected: WRONGTYPE")
+
+	// Every reply was read, so the connection stays usable
+	require.NotNil(t, p.conn)
+	require.NoError(t, p.publish(ctx, testMessages()))
+}
+
+func TestReadRESPReply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		reply    string
+		expected string
+		wantErr  string
+	}{
+		{name: "simple string", reply: "+OK\r\n", expected: "OK"},
+		{name: "integer", reply: ":42\r\n", expected: "42"},
+		{name: "bulk string", reply: "$5\r\nhello\r\n", expected: "hello"},
+		{name: "null bulk string", reply: "$-1\r\n", expected: ""},
+		{name: "array", reply: "*2\r\n+a\r\n$1\r\nb\r\n", expected: ""},
+		{name: "error", reply: "-ERR unknown command\r\n", wantErr: "ERR unknown command"},
+		{name: "unexpected", reply: "?\r\n", wantErr: "unexpected Redis reply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reply, err := readRESPReply(bufio.NewReader(bytes.NewBufferString(tt.reply)))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, reply)
+		})
+	}
+}
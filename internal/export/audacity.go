@@ -0,0 +1,169 @@
+// audacity.go: export of detections as Audacity label tracks
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// defaultAudacitySource names the label track of detections without a recorded source
+const defaultAudacitySource = "default"
+
+// unsafeFileNameChars matches characters replaced in label file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// AudacityOptions selects the detections and alignment of an Audacity label export
+type AudacityOptions struct {
+	StartDate     string        // first date to export, YYYY-MM-DD
+	EndDate       string        // last date to export, YYYY-MM-DD
+	Source        string        // only export this source, empty for all sources
+	MinConfidence float64       // detections below this confidence are left out
+	Offset        time.Duration // time of day the recordings start, labels are relative to it
+}
+
+// AudacitySummary reports what an Audacity export wrote
+type AudacitySummary struct {
+	Files  int `json:"files"`
+	Labels int `json:"labels"`
+}
+
+// Validate checks the confidence, offset and date range of the options
+func (o *AudacityOptions) Validate() error {
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return errors.Newf("minimum confidence must be between 0 and 1, got %v", o.MinConfidence).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if o.Offset < 0 || o.Offset >= 24*time.Hour {
+		return errors.Newf("recording start must be within the day, got %v", o.Offset).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	opts := Options{Format: FormatCSV, StartDate: o.StartDate, EndDate: o.EndDate}
+	return opts.Validate()
+}
+
+// audacityLabel is a label of a track being assembled
+type audacityLabel struct {
+	start float64
+	text  string
+}
+
+// WriteAudacityArchive writes the detections in the date range as a zip archive of Audacity
+// label files, one per day and audio source. Label times are seconds from the recording
+// start of each day, so importing a file as labels over a day-long recording of the source
+// lines the detections up with the audio. The source of a detection is its primary
+// contributing source when sources are recorded, or else the node that stored it.
+func WriteAudacityArchive(w io.Writer, store Store, opts *AudacityOptions) (AudacitySummary, error) {
+	var summary AudacitySummary
+	if err := opts.Validate(); err != nil {
+		return summary, err
+	}
+
+	sourceStore, _ := store.(datastore.NoteSourceStore)
+	zw := zip.NewWriter(w)
+	dateOpts := Options{StartDate: opts.StartDate, EndDate: opts.EndDate}
+	for _, date := range dateOpts.Dates() {
+		notes, err := store.GetNotesByDate(date, false)
+		if err != nil {
+			return summary, errors.New(err).
+				Component("export").
+				Category(errors.CategoryDatabase).
+				Context("date", date).
+				Build()
+		}
+
+		day, _ := time.ParseInLocation(time.DateOnly, date, time.Local)
+		recordingStart := day.Add(opts.Offset)
+
+		tracks := make(map[string][]audacityLabel)
+		for i := range notes {
+			note := &notes[i]
+			if note.Confidence < opts.MinConfidence {
+				continue
+			}
+			source := audacitySource(sourceStore, note)
+			if opts.Source != "" && !strings.EqualFold(source, opts.Source) {
+				continue
+			}
+			offset := noteBeginTime(note).Sub(recordingStart)
+			if offset < 0 {
+				continue
+			}
+			tracks[source] = append(tracks[source], audacityLabel{
+				start: offset.Seconds(),
+				text:  fmt.Sprintf("%s (%.0f%%)", note.CommonName, note.Confidence*100),
+			})
+		}
+
+		sources := make([]string, 0, len(tracks))
+		for source := range tracks {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+
+		for _, source := range sources {
+			labels := tracks[source]
+			sort.SliceStable(labels, func(i, j int) bool { return labels[i].start < labels[j].start })
+			name := fmt.Sprintf("%s_%s.txt", date, unsafeFileNameChars.ReplaceAllString(source, "_"))
+			if err := writeAudacityLabels(zw, name, labels); err != nil {
+				return summary, err
+			}
+			summary.Files++
+			summary.Labels += len(labels)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return summary, errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Build()
+	}
+	return summary, nil
+}
+
+// audacitySource returns the audio source of a note for grouping label tracks
+func audacitySource(sourceStore datastore.NoteSourceStore, note *datastore.Note) string {
+	if sourceStore != nil && note.ID != 0 {
+		if sources, err := sourceStore.GetNoteSources(note.ID); err == nil && len(sources) > 0 {
+			// Primary source is returned first
+			if sources[0].SourceName != "" {
+				return sources[0].SourceName
+			}
+			return sources[0].SourceID
+		}
+	}
+	if note.SourceNode != "" {
+		return note.SourceNode
+	}
+	return defaultAudacitySource
+}
+
+// writeAudacityLabels adds a label file with one tab-separated start, end and text line per
+// label to the archive
+func writeAudacityLabels(zw *zip.Writer, name string, labels []audacityLabel) error {
+	f, err := zw.Create(name)
+	for i := 0; err == nil && i < len(labels); i++ {
+		label := &labels[i]
+		_, err = fmt.Fprintf(f, "%.6f\t%.6f\t%s\n", label.start, label.start+detectionLength.Seconds(), label.text)
+	}
+	if err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("file", name).
+			Build()
+	}
+	return nil
+}
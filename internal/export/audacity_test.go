@@ -0,0 +1,128 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// sourceStore adds recorded note sources to the mock store
+type sourceStore struct {
+	*mockStore
+	sources map[uint][]datastore.NoteSource
+}
+
+func (s *sourceStore) SaveNoteSources(noteID uint, sources []datastore.NoteSource) error {
+	s.sources[noteID] = sources
+	return nil
+}
+
+func (s *sourceStore) GetNoteSources(noteID uint) ([]datastore.NoteSource, error) {
+	return s.sources[noteID], nil
+}
+
+func newAudacityTestStore() *sourceStore {
+	at := func(h, m, s int) time.Time { return time.Date(2025, 5, 10, h, m, s, 0, time.Local) }
+	return &sourceStore{
+		mockStore: &mockStore{notes: map[string][]datastore.Note{
+			"2025-05-10": {
+				{ID: 1, Date: "2025-05-10", Time: "06:15:30", BeginTime: at(6, 15, 30), CommonName: "Eurasian Blackbird", Confidence: 0.92, SourceNode: "station"},
+				{ID: 2, Date: "2025-05-10", Time: "05:00:00", BeginTime: at(5, 0, 0), CommonName: "Tawny Owl", Confidence: 0.81, SourceNode: "station"},
+				{ID: 3, Date: "2025-05-10", Time: "07:00:00", CommonName: "Great Tit", Confidence: 0.85, SourceNode: "station"},
+				{ID: 4, Date: "2025-05-10", Time: "08:00:00", CommonName: "Great Tit", Confidence: 0.3, SourceNode: "station"},
+			},
+		}},
+		sources: map[uint][]datastore.NoteSource{
+			3: {{SourceID: "rtsp_1", SourceName: "Garden mic", IsPrimary: true}, {SourceID: "malgo_1", SourceName: "Sound card"}},
+		},
+	}
+}
+
+// readArchiveLines returns the lines of each file in a zip archive
+func readArchiveLines(t *testing.T, data []byte) map[string][]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string][]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+	return files
+}
+
+func TestWriteAudacityArchive(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	summary, err := WriteAudacityArchive(&buf, newAudacityTestStore(), &AudacityOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-11", MinConfidence: 0.5, Offset: 5 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AudacitySummary{Files: 2, Labels: 3}, summary)
+
+	files := readArchiveLines(t, buf.Bytes())
+	assert.Equal(t, []string{
+		"0.000000\t3.000000\tTawny Owl (81%)",
+		"4530.000000\t4533.000000\tEurasian Blackbird (92%)",
+	}, files["2025-05-10_station.txt"])
+	assert.Equal(t, []string{"7200.000000\t7203.000000\tGreat Tit (85%)"}, files["2025-05-10_Garden_mic.txt"])
+}
+
+func TestWriteAudacityArchiveSourceFilter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	summary, err := WriteAudacityArchive(&buf, newAudacityTestStore(), &AudacityOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-10", Source: "garden mic",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AudacitySummary{Files: 1, Labels: 1}, summary)
+	assert.Equal(t, []string{"25200.000000\t25203.000000\tGreat Tit (85%)"}, readArchiveLines(t, buf.Bytes())["2025-05-10_Garden_mic.txt"])
+}
+
+func TestWriteAudacityArchiveWithoutSources(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	store := newAudacityTestStore().mockStore
+	summary, err := WriteAudacityArchive(&buf, store, &AudacityOptions{StartDate: "2025-05-10", EndDate: "2025-05-10", MinConfidence: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, AudacitySummary{Files: 1, Labels: 3}, summary, "detections are grouped by node without recorded sources")
+}
+
+func TestAudacityOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := AudacityOptions{StartDate: "2025-05-10", EndDate: "2025-05-10"}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(o *AudacityOptions)
+	}{
+		{"negative confidence", func(o *AudacityOptions) { o.MinConfidence = -0.5 }},
+		{"offset beyond day", func(o *AudacityOptions) { o.Offset = 24 * time.Hour }},
+		{"negative offset", func(o *AudacityOptions) { o.Offset = -time.Minute }},
+		{"invalid date", func(o *AudacityOptions) { o.StartDate = "2025-13-01" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+		})
+	}
+}
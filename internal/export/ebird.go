@@ -0,0 +1,236 @@
+// ebird.go: export of detections as eBird Record Format checklists
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// eBird checklist grouping rules
+const (
+	GroupingHourly = "hourly" // one checklist per hour with detections
+	GroupingDaily  = "daily"  // one checklist per day, from the first to the last detection
+)
+
+// ebirdSubmissionComment is added to every exported checklist
+const ebirdSubmissionComment = "Automated acoustic detections by BirdNET-Go, review before submitting."
+
+// Taxonomy resolves BirdNET labels to eBird species. *birdnet.BirdNET implements Taxonomy.
+type Taxonomy interface {
+	// GetSpeciesCode returns the eBird code of a "Scientific_Common" label and whether the
+	// species is in the taxonomy
+	GetSpeciesCode(label string) (string, bool)
+	// GetSpeciesNameFromCode returns the "Scientific_Common" eBird name of a species code
+	GetSpeciesNameFromCode(code string) (string, bool)
+}
+
+// EBirdOptions selects the detections and checklist details of an eBird Record Format export
+type EBirdOptions struct {
+	StartDate     string  // first date to export, YYYY-MM-DD
+	EndDate       string  // last date to export, YYYY-MM-DD
+	Grouping      string  // GroupingHourly or GroupingDaily
+	MinConfidence float64 // detections below this confidence are left out
+	LocationName  string
+	Latitude      float64
+	Longitude     float64
+	StateCode     string // state or province code, e.g. "CA"
+	CountryCode   string // two-letter country code, e.g. "US"
+}
+
+// EBirdSummary reports what an eBird export wrote
+type EBirdSummary struct {
+	Checklists int `json:"checklists"`
+	Records    int `json:"records"` // species rows across all checklists
+	Skipped    int `json:"skipped"` // detections of labels missing from the eBird taxonomy
+}
+
+// Validate checks the grouping and date range of the options
+func (o *EBirdOptions) Validate() error {
+	if o.Grouping != GroupingHourly && o.Grouping != GroupingDaily {
+		return errors.Newf("eBird checklist grouping must be hourly or daily, got %q", o.Grouping).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return errors.Newf("minimum confidence must be between 0 and 1, got %v", o.MinConfidence).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	opts := o.options()
+	return opts.Validate()
+}
+
+// options returns the date range as export options for range validation and iteration
+func (o *EBirdOptions) options() Options {
+	return Options{Format: FormatCSV, StartDate: o.StartDate, EndDate: o.EndDate}
+}
+
+// ebirdChecklist collects the species detected during one checklist period
+type ebirdChecklist struct {
+	start, first, last time.Time
+	species            map[string]*ebirdSpecies
+}
+
+// ebirdSpecies is a species row of a checklist
+type ebirdSpecies struct {
+	common, genus, epithet string
+	detections             int
+	maxConfidence          float64
+}
+
+// WriteEBird writes the detections in the date range as eBird Record Format (Extended) CSV.
+// Detections are grouped into stationary checklists per hour or per day, with one row per
+// species. Labels missing from the eBird taxonomy, such as non-bird sounds, are skipped.
+// The format has no header row.
+func WriteEBird(w io.Writer, store Store, taxonomy Taxonomy, opts *EBirdOptions) (EBirdSummary, error) {
+	var summary EBirdSummary
+	if err := opts.Validate(); err != nil {
+		return summary, err
+	}
+
+	dateOpts := opts.options()
+	cw := csv.NewWriter(w)
+	for _, date := range dateOpts.Dates() {
+		notes, err := store.GetNotesByDate(date, false)
+		if err != nil {
+			return summary, errors.New(err).
+				Component("export").
+				Category(errors.CategoryDatabase).
+				Context("date", date).
+				Build()
+		}
+
+		checklists := make(map[time.Time]*ebirdChecklist)
+		for i := range notes {
+			note := &notes[i]
+			if note.Confidence < opts.MinConfidence {
+				continue
+			}
+			species, ok := resolveEBirdSpecies(taxonomy, note.ScientificName, note.CommonName)
+			if !ok {
+				summary.Skipped++
+				continue
+			}
+			detected, err := time.ParseInLocation(time.DateTime, note.Date+" "+note.Time, time.Local)
+			if err != nil {
+				continue
+			}
+
+			hour := 0
+			if opts.Grouping == GroupingHourly {
+				hour = detected.Hour()
+			}
+			start := time.Date(detected.Year(), detected.Month(), detected.Day(), hour, 0, 0, 0, time.Local)
+			checklist := checklists[start]
+			if checklist == nil {
+				checklist = &ebirdChecklist{start: start, first: detected, last: detected, species: make(map[string]*ebirdSpecies)}
+				checklists[start] = checklist
+			}
+			if detected.Before(checklist.first) {
+				checklist.first = detected
+			}
+			if detected.After(checklist.last) {
+				checklist.last = detected
+			}
+			if existing := checklist.species[species.common]; existing != nil {
+				species = existing
+			} else {
+				checklist.species[species.common] = species
+			}
+			species.detections++
+			species.maxConfidence = max(species.maxConfidence, note.Confidence)
+		}
+
+		starts := make([]time.Time, 0, len(checklists))
+		for start := range checklists {
+			starts = append(starts, start)
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+		for _, start := range starts {
+			records := checklists[start].records(opts)
+			if err := cw.WriteAll(records); err != nil {
+				return summary, errors.New(err).
+					Component("export").
+					Category(errors.CategoryFileIO).
+					Build()
+			}
+			summary.Checklists++
+			summary.Records += len(records)
+		}
+	}
+
+	return summary, nil
+}
+
+// resolveEBirdSpecies maps a detection to its eBird common name, genus and species epithet
+func resolveEBirdSpecies(taxonomy Taxonomy, scientific, common string) (*ebirdSpecies, bool) {
+	code, ok := taxonomy.GetSpeciesCode(scientific + "_" + common)
+	if !ok {
+		return nil, false
+	}
+	name, ok := taxonomy.GetSpeciesNameFromCode(code)
+	if !ok {
+		return nil, false
+	}
+
+	// eBird names can differ from the BirdNET labels, the taxonomy names take precedence
+	ebirdScientific, ebirdCommon, found := strings.Cut(name, "_")
+	if !found || ebirdCommon == "" {
+		ebirdScientific, ebirdCommon = scientific, common
+	}
+	genus, epithet, _ := strings.Cut(ebirdScientific, " ")
+	return &ebirdSpecies{common: ebirdCommon, genus: genus, epithet: epithet}, true
+}
+
+// records returns the eBird Record Format rows of the checklist ordered by common name
+func (c *ebirdChecklist) records(opts *EBirdOptions) [][]string {
+	// Hourly checklists cover the full hour, daily ones the span between the first and last detection
+	start, duration := c.start, 60
+	if opts.Grouping == GroupingDaily {
+		start = c.first.Truncate(time.Minute)
+		duration = min(max(int(c.last.Sub(start).Minutes())+1, 1), 24*60)
+	}
+
+	names := make([]string, 0, len(c.species))
+	for name := range c.species {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	records := make([][]string, 0, len(names))
+	for _, name := range names {
+		s := c.species[name]
+		records = append(records, []string{
+			s.common,
+			s.genus,
+			s.epithet,
+			"X", // presence only, detections do not count individuals
+			fmt.Sprintf("BirdNET-Go: %d detections, max confidence %.0f%%", s.detections, s.maxConfidence*100),
+			opts.LocationName,
+			strconv.FormatFloat(opts.Latitude, 'f', 6, 64),
+			strconv.FormatFloat(opts.Longitude, 'f', 6, 64),
+			start.Format("01/02/2006"),
+			start.Format("15:04"),
+			opts.StateCode,
+			opts.CountryCode,
+			"Stationary",
+			"1",
+			strconv.Itoa(duration),
+			"N", // automated detections are not a complete list of observed species
+			"",  // distance is only used for traveling counts
+			"",  // area is only used for area counts
+			ebirdSubmissionComment,
+		})
+	}
+	return records
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// mockTaxonomy maps labels to codes and codes to eBird names
+type mockTaxonomy map[string]string
+
+func (m mockTaxonomy) GetSpeciesCode(label string) (string, bool) {
+	code, ok := m[label]
+	return code, ok
+}
+
+func (m mockTaxonomy) GetSpeciesNameFromCode(code string) (string, bool) {
+	name, ok := m[code]
+	return name, ok
+}
+
+func newEBirdTestStore() *mockStore {
+	return &mockStore{
+		notes: map[string][]datastore.Note{
+			"2025-05-10": {
+				{Date: "2025-05-10", Time: "06:05:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9},
+				{Date: "2025-05-10", Time: "06:40:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.95},
+				{Date: "2025-05-10", Time: "06:50:00", ScientificName: "Parus major", CommonName: "Great Tit", Confidence: 0.85},
+				{Date: "2025-05-10", Time: "07:20:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.5},
+				{Date: "2025-05-10", Time: "08:15:30", ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.8},
+				{Date: "2025-05-10", Time: "08:30:00", ScientificName: "Human vocal", CommonName: "Human vocal", Confidence: 0.99},
+			},
+		},
+	}
+}
+
+func newEBirdTestTaxonomy() mockTaxonomy {
+	return mockTaxonomy{
+		"Turdus merula_Eurasian Blackbird": "eurbla",
+		"eurbla":                           "Turdus merula_Eurasian Blackbird",
+		"Parus major_Great Tit":            "gretit1",
+		"gretit1":                          "Parus major_Great Tit",
+		"Strix aluco_Tawny Owl":            "tawowl1",
+		"tawowl1":                          "Strix aluco_Tawny Owl",
+	}
+}
+
+func writeEBirdRecords(t *testing.T, opts *EBirdOptions) ([][]string, EBirdSummary) {
+	t.Helper()
+	var buf bytes.Buffer
+	summary, err := WriteEBird(&buf, newEBirdTestStore(), newEBirdTestTaxonomy(), opts)
+	require.NoError(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	return records, summary
+}
+
+func TestWriteEBirdHourly(t *testing.T) {
+	t.Parallel()
+
+	records, summary := writeEBirdRecords(t, &EBirdOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-11", Grouping: GroupingHourly, MinConfidence: 0.8,
+		LocationName: "Backyard", Latitude: 60.1699, Longitude: 24.9384, CountryCode: "FI",
+	})
+
+	assert.Equal(t, EBirdSummary{Checklists: 2, Records: 3, Skipped: 1}, summary)
+	require.Len(t, records, 3)
+	for _, record := range records {
+		assert.Len(t, record, 19, "eBird Record Format has 19 columns")
+	}
+
+	blackbird := records[0]
+	assert.Equal(t, []string{"Eurasian Blackbird", "Turdus", "merula", "X"}, blackbird[:4])
+	assert.Equal(t, "BirdNET-Go: 2 detections, max confidence 95%", blackbird[4])
+	assert.Equal(t, []string{"Backyard", "60.169900", "24.938400", "05/10/2025", "06:00", "", "FI", "Stationary", "1", "60", "N"}, blackbird[5:16])
+	assert.Equal(t, "Great Tit", records[1][0])
+	assert.Equal(t, []string{"Tawny Owl", "08:00"}, []string{records[2][0], records[2][9]})
+}
+
+func TestWriteEBirdDaily(t *testing.T) {
+	t.Parallel()
+
+	records, summary := writeEBirdRecords(t, &EBirdOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-10", Grouping: GroupingDaily,
+	})
+
+	assert.Equal(t, 1, summary.Checklists)
+	require.Len(t, records, 3)
+	assert.Equal(t, "BirdNET-Go: 3 detections, max confidence 95%", records[0][4])
+	for _, record := range records {
+		assert.Equal(t, "06:05", record[9], "daily checklists start at the first detection")
+		assert.Equal(t, "131", record[14], "daily checklists last until the last detection")
+	}
+}
+
+func TestEBirdOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := EBirdOptions{StartDate: "2025-05-10", EndDate: "2025-05-11", Grouping: GroupingHourly}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(o *EBirdOptions)
+	}{
+		{"unknown grouping", func(o *EBirdOptions) { o.Grouping = "weekly" }},
+		{"negative confidence", func(o *EBirdOptions) { o.MinConfidence = -1 }},
+		{"invalid date", func(o *EBirdOptions) { o.StartDate = "" }},
+		{"end before start", func(o *EBirdOptions) { o.EndDate = "2025-05-01" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package export writes detections, optionally joined with their secondary results and
+// weather, to date-partitioned CSV or Parquet files for offline analysis. Exports run as
+// background jobs that record completed partitions so an interrupted job can be resumed.
+package export
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Export file formats
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// MaxDays is the longest date range a single export job may cover
+const MaxDays = 3660
+
+// Status is the state of an export job
+type Status string
+
+// Export job states
+const (
+	StatusRunning     Status = "running"
+	StatusCompleted   Status = "completed"
+	StatusFailed      Status = "failed"
+	StatusCancelled   Status = "cancelled"
+	StatusInterrupted Status = "interrupted" // the application stopped while the job was running
+)
+
+// Store provides the detection and weather data for exports. *datastore.DataStore and
+// the stores embedding it implement Store.
+type Store interface {
+	GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error)
+	GetHourlyWeather(date string) ([]datastore.HourlyWeather, error)
+}
+
+// Options selects the data and format of an export
+type Options struct {
+	Format         string `json:"format"`         // "csv" or "parquet"
+	StartDate      string `json:"startDate"`      // first date to export, YYYY-MM-DD
+	EndDate        string `json:"endDate"`        // last date to export, YYYY-MM-DD
+	IncludeResults bool   `json:"includeResults"` // one row per secondary result instead of one per detection
+	IncludeWeather bool   `json:"includeWeather"` // add the weather reading nearest to each detection
+}
+
+// Validate checks the format and date range of the options
+func (o *Options) Validate() error {
+	if o.Format != FormatCSV && o.Format != FormatParquet {
+		return errors.Newf("export format must be csv or parquet, got %q", o.Format).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+
+	start, end, err := o.dateRange()
+	if err != nil {
+		return err
+	}
+	if end.Before(start) {
+		return errors.Newf("export end date %s is before start date %s", o.EndDate, o.StartDate).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > MaxDays {
+		return errors.Newf("export range of %d days exceeds the maximum of %d", days, MaxDays).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	return nil
+}
+
+// Dates returns every date in the export range in order
+func (o *Options) Dates() []string {
+	start, end, err := o.dateRange()
+	if err != nil {
+		return nil
+	}
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format(time.DateOnly))
+	}
+	return dates
+}
+
+// dateRange parses the start and end dates
+func (o *Options) dateRange() (start, end time.Time, err error) {
+	if start, err = time.Parse(time.DateOnly, o.StartDate); err != nil {
+		return start, end, errors.Newf("invalid export start date %q, expected YYYY-MM-DD", o.StartDate).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if end, err = time.Parse(time.DateOnly, o.EndDate); err != nil {
+		return start, end, errors.Newf("invalid export end date %q, expected YYYY-MM-DD", o.EndDate).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	return start, end, nil
+}
+
+// Job is the persisted state and progress of an export job
+type Job struct {
+	ID             string     `json:"id"`
+	Options        Options    `json:"options"`
+	Status         Status     `json:"status"`
+	TotalDays      int        `json:"totalDays"`
+	CompletedDates []string   `json:"completedDates"` // partitions that are fully written
+	Rows           int64      `json:"rows"`           // rows written by completed partitions
+	Files          []string   `json:"files"`          // written files relative to the job directory
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+}
+
+// Progress returns the share of completed partitions as a percentage
+func (j *Job) Progress() float64 {
+	if j.TotalDays == 0 {
+		return 0
+	}
+	return float64(len(j.CompletedDates)) * 100 / float64(j.TotalDays)
+}
+
+// Resumable reports whether the job stopped before finishing and can be resumed
+func (j *Job) Resumable() bool {
+	return j.Status == StatusFailed || j.Status == StatusCancelled || j.Status == StatusInterrupted
+}
@@ -0,0 +1,28 @@
+// logger.go: structured logging for bulk exports
+package export
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/tphakala/birdnet-go/internal/logging"
+)
+
+// Package-level logger for export jobs
+var (
+	exportLogger   *slog.Logger
+	exportLevelVar = new(slog.LevelVar) // Dynamic level control
+)
+
+func init() {
+	var err error
+	exportLevelVar.Set(slog.LevelInfo)
+
+	exportLogger, _, err = logging.NewFileLogger("logs/export.log", "export", exportLevelVar)
+	if err != nil {
+		logging.Error("Failed to initialize export file logger", "error", err)
+		// Fallback to a disabled logger (writes to io.Discard) but respects the level var
+		fbHandler := slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: exportLevelVar})
+		exportLogger = slog.New(fbHandler).With("service", "export")
+	}
+}
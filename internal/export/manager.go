@@ -0,0 +1,450 @@
+// manager.go: background export jobs with resumable progress
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// stateFileName is the job state file in each job directory
+const stateFileName = "job.json"
+
+// Manager runs export jobs and persists their progress. Each job writes to its own
+// directory below the export directory, with one subdirectory per date partition.
+type Manager struct {
+	store Store
+	dir   string
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running map[string]*runningJob
+	wg      sync.WaitGroup
+}
+
+// runningJob holds the cancellation of a running job and the status it stops with
+type runningJob struct {
+	cancel   context.CancelFunc
+	stopWith Status
+}
+
+// NewManager creates a manager writing to dir and loads jobs from previous runs. Jobs
+// that were running when the application stopped are marked interrupted.
+func NewManager(store Store, dir string) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_export_dir").
+			Context("path", dir).
+			Build()
+	}
+
+	m := &Manager{
+		store:   store,
+		dir:     dir,
+		jobs:    make(map[string]*Job),
+		running: make(map[string]*runningJob),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_export_dir").
+			Context("path", dir).
+			Build()
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == TrainingDirName {
+			continue
+		}
+		job, err := m.loadState(entry.Name())
+		if err != nil {
+			exportLogger.Warn("skipping export job with unreadable state", "job_id", entry.Name(), "error", err)
+			continue
+		}
+		if job.Status == StatusRunning {
+			job.Status = StatusInterrupted
+			if err := m.saveState(job); err != nil {
+				exportLogger.Warn("failed to mark export job interrupted", "job_id", job.ID, "error", err)
+			}
+		}
+		m.jobs[job.ID] = job
+	}
+
+	return m, nil
+}
+
+// Start validates the options and starts a new export job
+func (m *Manager) Start(opts Options) (Job, error) {
+	if err := opts.Validate(); err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:             now.Format("20060102-150405") + "-" + uuid.New().String()[:8],
+		Options:        opts,
+		Status:         StatusRunning,
+		TotalDays:      len(opts.Dates()),
+		CompletedDates: []string{},
+		Files:          []string{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.jobDir(job.ID), 0o755); err != nil {
+		return Job{}, errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_job_dir").
+			Build()
+	}
+	if err := m.saveState(job); err != nil {
+		return Job{}, err
+	}
+	m.jobs[job.ID] = job
+	m.launch(job)
+
+	exportLogger.Info("export job started",
+		"job_id", job.ID,
+		"format", opts.Format,
+		"start_date", opts.StartDate,
+		"end_date", opts.EndDate,
+		"include_results", opts.IncludeResults,
+		"include_weather", opts.IncludeWeather)
+	return *job, nil
+}
+
+// Resume restarts a failed, cancelled or interrupted job from its first incomplete partition
+func (m *Manager) Resume(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.jobs[id]
+	if !exists {
+		return Job{}, errJobNotFound(id)
+	}
+	if !job.Resumable() {
+		return Job{}, errors.Newf("export job %s is %s and cannot be resumed", id, job.Status).
+			Component("export").
+			Category(errors.CategoryConflict).
+			Build()
+	}
+
+	job.Status = StatusRunning
+	job.Error = ""
+	job.UpdatedAt = time.Now()
+	if err := m.saveState(job); err != nil {
+		return Job{}, err
+	}
+	m.launch(job)
+
+	exportLogger.Info("export job resumed", "job_id", id, "completed_days", len(job.CompletedDates), "total_days", job.TotalDays)
+	return *job, nil
+}
+
+// Cancel stops a running job. Completed partitions are kept so the job can be resumed.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.jobs[id]; !exists {
+		return errJobNotFound(id)
+	}
+	run, exists := m.running[id]
+	if !exists {
+		return errors.Newf("export job %s is not running", id).
+			Component("export").
+			Category(errors.CategoryConflict).
+			Build()
+	}
+	run.stopWith = StatusCancelled
+	run.cancel()
+	return nil
+}
+
+// Get returns a copy of the job
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.jobs[id]
+	if !exists {
+		return Job{}, false
+	}
+	return copyJob(job), true
+}
+
+// List returns copies of all jobs, newest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, copyJob(job))
+	}
+	slices.SortFunc(jobs, func(a, b Job) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return jobs
+}
+
+// JobDir returns the directory a job writes its partitions to
+func (m *Manager) JobDir(id string) string {
+	return m.jobDir(id)
+}
+
+// Stop interrupts running jobs and waits for them to save their progress
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	for _, run := range m.running {
+		run.stopWith = StatusInterrupted
+		run.cancel()
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+// launch starts the job goroutine, the caller must hold m.mu
+func (m *Manager) launch(job *Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &runningJob{cancel: cancel, stopWith: StatusInterrupted}
+	m.running[job.ID] = run
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.run(ctx, job.ID, run)
+	}()
+}
+
+// run writes the partitions that are not yet complete
+func (m *Manager) run(ctx context.Context, id string, run *runningJob) {
+	m.mu.Lock()
+	job := m.jobs[id]
+	opts := job.Options
+	done := make(map[string]bool, len(job.CompletedDates))
+	for _, date := range job.CompletedDates {
+		done[date] = true
+	}
+	m.mu.Unlock()
+
+	var runErr error
+	for _, date := range opts.Dates() {
+		if done[date] {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		rows, file, err := m.writePartition(&opts, id, date)
+		if err != nil {
+			runErr = err
+			break
+		}
+
+		m.mu.Lock()
+		job.CompletedDates = append(job.CompletedDates, date)
+		job.Rows += rows
+		if file != "" {
+			job.Files = append(job.Files, file)
+		}
+		job.UpdatedAt = time.Now()
+		err = m.saveState(job)
+		m.mu.Unlock()
+		if err != nil {
+			runErr = err
+			break
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.running, id)
+
+	now := time.Now()
+	job.UpdatedAt = now
+	switch {
+	case runErr != nil:
+		job.Status = StatusFailed
+		job.Error = runErr.Error()
+		exportLogger.Error("export job failed", "job_id", id, "error", runErr)
+	case ctx.Err() != nil && len(job.CompletedDates) < job.TotalDays:
+		job.Status = run.stopWith
+		exportLogger.Info("export job stopped", "job_id", id, "status", job.Status, "completed_days", len(job.CompletedDates))
+	default:
+		job.Status = StatusCompleted
+		job.CompletedAt = &now
+		exportLogger.Info("export job completed", "job_id", id, "rows", job.Rows, "files", len(job.Files))
+	}
+	if err := m.saveState(job); err != nil {
+		exportLogger.Error("failed to save export job state", "job_id", id, "error", err)
+	}
+}
+
+// writePartition writes the detections of a date to the date partition and returns the
+// number of rows and the file path relative to the job directory. Dates without
+// detections produce no file. The file is written under a temporary name and renamed
+// when complete, so an interrupted partition never looks finished.
+func (m *Manager) writePartition(opts *Options, id, date string) (rows int64, file string, err error) {
+	notes, err := m.store.GetNotesByDate(date, opts.IncludeResults)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(notes) == 0 {
+		return 0, "", nil
+	}
+
+	var weather []datastore.HourlyWeather
+	if opts.IncludeWeather {
+		if weather, err = m.store.GetHourlyWeather(date); err != nil {
+			return 0, "", err
+		}
+	}
+
+	file = filepath.Join("date="+date, "detections."+opts.Format)
+	path := filepath.Join(m.jobDir(id), file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, "", partitionError(err, date)
+	}
+
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return 0, "", partitionError(err, date)
+	}
+	defer func() {
+		if f != nil {
+			_ = f.Close()
+			_ = os.Remove(path + ".tmp")
+		}
+	}()
+
+	buf := bufio.NewWriter(f)
+	w, err := newRowWriter(opts.Format, buf, opts.Columns())
+	if err != nil {
+		return 0, "", err
+	}
+	for i := range notes {
+		for _, row := range opts.rowsForNote(&notes[i], weather) {
+			if err := w.Write(row); err != nil {
+				return 0, "", partitionError(err, date)
+			}
+			rows++
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, "", partitionError(err, date)
+	}
+	if err := buf.Flush(); err != nil {
+		return 0, "", partitionError(err, date)
+	}
+	err = f.Close()
+	f = nil
+	if err != nil {
+		_ = os.Remove(path + ".tmp")
+		return 0, "", partitionError(err, date)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return 0, "", partitionError(err, date)
+	}
+
+	return rows, file, nil
+}
+
+// jobDir returns the directory of a job
+func (m *Manager) jobDir(id string) string {
+	return filepath.Join(m.dir, id)
+}
+
+// saveState writes the job state atomically, the caller must hold m.mu for jobs in m.jobs
+func (m *Manager) saveState(job *Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryGeneric).
+			Context("operation", "encode_job_state").
+			Build()
+	}
+
+	path := filepath.Join(m.jobDir(job.ID), stateFileName)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_job_state").
+			Build()
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_job_state").
+			Build()
+	}
+	return nil
+}
+
+// loadState reads the state of the job in the directory
+func (m *Manager) loadState(id string) (*Job, error) {
+	data, err := os.ReadFile(filepath.Join(m.jobDir(id), stateFileName))
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	if job.ID != id {
+		return nil, errors.Newf("state belongs to export job %q", job.ID).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	return &job, nil
+}
+
+// copyJob returns a copy of the job that does not share slices with it
+func copyJob(job *Job) Job {
+	c := *job
+	c.CompletedDates = slices.Clone(job.CompletedDates)
+	c.Files = slices.Clone(job.Files)
+	return c
+}
+
+// errJobNotFound returns the error for an unknown job
+func errJobNotFound(id string) error {
+	return errors.Newf("export job %s not found", id).
+		Component("export").
+		Category(errors.CategoryNotFound).
+		Build()
+}
+
+// partitionError wraps an error writing a partition
+func partitionError(err error, date string) error {
+	return errors.New(err).
+		Component("export").
+		Category(errors.CategoryFileIO).
+		Context("operation", "write_partition").
+		Context("date", date).
+		Build()
+}
@@ -0,0 +1,301 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// mockStore serves notes per date and can block or fail on a date
+type mockStore struct {
+	mu      sync.Mutex
+	notes   map[string][]datastore.Note
+	weather map[string][]datastore.HourlyWeather
+	failOn  string
+	blockOn string
+	block   chan struct{}
+	calls   []string
+}
+
+func (m *mockStore) GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, date)
+	failOn, blockOn := m.failOn, m.blockOn
+	m.mu.Unlock()
+
+	if date == blockOn {
+		<-m.block
+	}
+	if date == failOn {
+		return nil, errors.Newf("database unavailable").Component("export").Category(errors.CategoryDatabase).Build()
+	}
+	return m.notes[date], nil
+}
+
+func (m *mockStore) GetHourlyWeather(date string) ([]datastore.HourlyWeather, error) {
+	return m.weather[date], nil
+}
+
+func newTestStore() *mockStore {
+	return &mockStore{
+		notes: map[string][]datastore.Note{
+			"2025-05-10": {
+				{ID: 1, Date: "2025-05-10", Time: "06:15:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9,
+					BeginTime: time.Date(2025, 5, 10, 6, 15, 0, 0, time.UTC),
+					Results:   []datastore.Results{{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.9}, {Species: "Turdus philomelos_Song Thrush", Confidence: 0.2}}},
+				{ID: 2, Date: "2025-05-10", Time: "21:40:00", ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.8},
+			},
+			"2025-05-12": {
+				{ID: 3, Date: "2025-05-12", Time: "07:00:00", ScientificName: "Parus major", CommonName: "Great Tit", Confidence: 0.85},
+			},
+		},
+		weather: map[string][]datastore.HourlyWeather{
+			"2025-05-10": {{Time: time.Date(2025, 5, 10, 6, 0, 0, 0, time.UTC), Temperature: 8.5, Humidity: 80, WeatherMain: "Clouds"}},
+		},
+		block: make(chan struct{}),
+	}
+}
+
+// waitForStatus waits until the job leaves the running state
+func waitForStatus(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		job, _ = m.Get(id)
+		return job.Status != StatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := Options{Format: FormatCSV, StartDate: "2025-05-01", EndDate: "2025-05-03"}
+	require.NoError(t, valid.Validate())
+	assert.Equal(t, []string{"2025-05-01", "2025-05-02", "2025-05-03"}, valid.Dates())
+
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+	}{
+		{"unknown format", func(o *Options) { o.Format = "xlsx" }},
+		{"invalid start", func(o *Options) { o.StartDate = "05/01/2025" }},
+		{"invalid end", func(o *Options) { o.EndDate = "" }},
+		{"end before start", func(o *Options) { o.EndDate = "2025-04-30" }},
+		{"range too long", func(o *Options) { o.StartDate = "2000-01-01" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+		})
+	}
+}
+
+func TestExportCSVWithResultsAndWeather(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewManager(newTestStore(), t.TempDir())
+	require.NoError(t, err)
+	defer m.Stop()
+
+	job, err := m.Start(Options{Format: FormatCSV, StartDate: "2025-05-10", EndDate: "2025-05-12", IncludeResults: true, IncludeWeather: true})
+	require.NoError(t, err)
+
+	job = waitForStatus(t, m, job.ID)
+	require.Equal(t, StatusCompleted, job.Status, job.Error)
+	assert.Equal(t, 3, job.TotalDays)
+	assert.Equal(t, []string{"2025-05-10", "2025-05-11", "2025-05-12"}, job.CompletedDates)
+	assert.InDelta(t, 100.0, job.Progress(), 0.001)
+	assert.Equal(t, int64(4), job.Rows, "two results, one note without results, one note on the next day")
+	assert.Equal(t, []string{filepath.Join("date=2025-05-10", "detections.csv"), filepath.Join("date=2025-05-12", "detections.csv")}, job.Files)
+
+	records := readCSV(t, filepath.Join(m.JobDir(job.ID), job.Files[0]))
+	require.Len(t, records, 4)
+	header := records[0]
+	col := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("column %s missing", name)
+		return -1
+	}
+	assert.Equal(t, "Turdus merula_Eurasian Blackbird", records[1][col("result_species")])
+	assert.Equal(t, "Turdus philomelos_Song Thrush", records[2][col("result_species")])
+	assert.Equal(t, "8.5", records[1][col("temperature")])
+	assert.Equal(t, "Clouds", records[1][col("weather_main")])
+	assert.Equal(t, "2025-05-10T06:15:00Z", records[1][col("begin_time")])
+	assert.Equal(t, "Tawny Owl", records[3][col("common_name")])
+	assert.Empty(t, records[3][col("result_species")], "left join keeps notes without results")
+	assert.Empty(t, records[3][col("temperature")], "no weather reading within an hour")
+}
+
+func TestExportParquet(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewManager(newTestStore(), t.TempDir())
+	require.NoError(t, err)
+	defer m.Stop()
+
+	job, err := m.Start(Options{Format: FormatParquet, StartDate: "2025-05-10", EndDate: "2025-05-10"})
+	require.NoError(t, err)
+	job = waitForStatus(t, m, job.ID)
+	require.Equal(t, StatusCompleted, job.Status, job.Error)
+
+	data, err := os.ReadFile(filepath.Join(m.JobDir(job.ID), "date=2025-05-10", "detections.parquet"))
+	require.NoError(t, err)
+	metadata := readParquetFooter(t, data)
+	assert.Equal(t, int64(2), metadata[3])
+	assert.Len(t, metadata[2], len(detectionColumns)+1)
+}
+
+func TestExportResumeAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore()
+	store.failOn = "2025-05-12"
+	m, err := NewManager(store, t.TempDir())
+	require.NoError(t, err)
+	defer m.Stop()
+
+	job, err := m.Start(Options{Format: FormatCSV, StartDate: "2025-05-10", EndDate: "2025-05-12"})
+	require.NoError(t, err)
+	job = waitForStatus(t, m, job.ID)
+	require.Equal(t, StatusFailed, job.Status)
+	assert.Contains(t, job.Error, "database unavailable")
+	assert.Equal(t, []string{"2025-05-10", "2025-05-11"}, job.CompletedDates)
+	assert.True(t, job.Resumable())
+
+	store.mu.Lock()
+	store.failOn = ""
+	store.calls = nil
+	store.mu.Unlock()
+
+	_, err = m.Resume(job.ID)
+	require.NoError(t, err)
+	job = waitForStatus(t, m, job.ID)
+	require.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, int64(3), job.Rows)
+
+	store.mu.Lock()
+	assert.Equal(t, []string{"2025-05-12"}, store.calls, "completed partitions are not exported again")
+	store.mu.Unlock()
+
+	_, err = m.Resume(job.ID)
+	assert.Error(t, err, "completed jobs cannot be resumed")
+}
+
+func TestExportCancelAndReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := newTestStore()
+	store.blockOn = "2025-05-11"
+	m, err := NewManager(store, dir)
+	require.NoError(t, err)
+
+	job, err := m.Start(Options{Format: FormatCSV, StartDate: "2025-05-10", EndDate: "2025-05-12"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(job.ID)
+		return len(j.CompletedDates) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, m.Cancel(job.ID))
+	close(store.block)
+	job = waitForStatus(t, m, job.ID)
+	assert.Equal(t, StatusCancelled, job.Status)
+	assert.Error(t, m.Cancel(job.ID), "job is no longer running")
+	assert.Error(t, m.Cancel("missing"))
+	m.Stop()
+
+	// A new manager loads the saved progress
+	reloaded, err := NewManager(store, dir)
+	require.NoError(t, err)
+	defer reloaded.Stop()
+
+	jobs := reloaded.List()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, StatusCancelled, jobs[0].Status)
+	assert.Contains(t, jobs[0].CompletedDates, "2025-05-10")
+
+	_, err = reloaded.Resume(job.ID)
+	require.NoError(t, err)
+	job = waitForStatus(t, reloaded, job.ID)
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Len(t, job.CompletedDates, 3)
+}
+
+func TestNewManagerMarksRunningJobsInterrupted(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &Manager{dir: dir}
+	job := &Job{ID: "job-1", Status: StatusRunning, Options: Options{Format: FormatCSV, StartDate: "2025-05-10", EndDate: "2025-05-10"}}
+	require.NoError(t, os.MkdirAll(m.jobDir(job.ID), 0o755))
+	require.NoError(t, m.saveState(job))
+
+	reloaded, err := NewManager(newTestStore(), dir)
+	require.NoError(t, err)
+	defer reloaded.Stop()
+
+	loaded, ok := reloaded.Get("job-1")
+	require.True(t, ok)
+	assert.Equal(t, StatusInterrupted, loaded.Status)
+	assert.True(t, loaded.Resumable())
+}
+
+func TestExportFromSQLite(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	ds := datastore.New(settings)
+	require.NoError(t, ds.Open())
+	defer func() { _ = ds.Close() }()
+
+	note := &datastore.Note{Date: "2025-05-10", Time: "06:15:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9}
+	require.NoError(t, ds.Save(note, []datastore.Results{{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.9}}))
+
+	store, ok := ds.(Store)
+	require.True(t, ok, "datastore must implement export.Store")
+
+	notes, err := store.GetNotesByDate("2025-05-10", true)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Len(t, notes[0].Results, 1)
+
+	m, err := NewManager(store, t.TempDir())
+	require.NoError(t, err)
+	defer m.Stop()
+
+	job, err := m.Start(Options{Format: FormatCSV, StartDate: "2025-05-10", EndDate: "2025-05-10", IncludeResults: true, IncludeWeather: true})
+	require.NoError(t, err)
+	job = waitForStatus(t, m, job.ID)
+	require.Equal(t, StatusCompleted, job.Status, job.Error)
+	assert.Equal(t, int64(1), job.Rows)
+}
@@ -0,0 +1,353 @@
+// parquet.go: minimal Apache Parquet writer for flat detection tables
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// parquetMagic starts and ends every Parquet file
+const parquetMagic = "PAR1"
+
+// Parquet physical types, encodings and converted types from parquet.thrift
+const (
+	parquetTypeBoolean   = 0
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetRepetitionOptional = 1
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageTypeData      = 0
+)
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetWriter buffers rows in memory and writes them as a single row group when closed.
+// Every column is optional, PLAIN encoded and uncompressed, which keeps the writer small
+// while producing files that pandas, R arrow and DuckDB read without conversion.
+type parquetWriter struct {
+	w       io.Writer
+	columns []Column
+	values  [][]any
+	rows    int
+}
+
+// newParquetWriter creates a Parquet writer for the columns
+func newParquetWriter(w io.Writer, columns []Column) *parquetWriter {
+	return &parquetWriter{
+		w:       w,
+		columns: columns,
+		values:  make([][]any, len(columns)),
+	}
+}
+
+// Write buffers a row. Values must match the column types, nil is written as null.
+func (p *parquetWriter) Write(row []any) error {
+	if len(row) != len(p.columns) {
+		return errors.Newf("row has %d values, expected %d", len(row), len(p.columns)).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	for i, value := range row {
+		if value != nil && !p.columns[i].Type.accepts(value) {
+			return errors.Newf("column %s: unexpected value type %T", p.columns[i].Name, value).
+				Component("export").
+				Category(errors.CategoryValidation).
+				Build()
+		}
+		p.values[i] = append(p.values[i], value)
+	}
+	p.rows++
+	return nil
+}
+
+// Close writes the buffered rows and the file footer. It does not close the underlying writer.
+func (p *parquetWriter) Close() error {
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+
+	chunks := make([]parquetChunk, len(p.columns))
+	for i, column := range p.columns {
+		if p.rows == 0 {
+			break
+		}
+		page := encodeParquetPage(column.Type, p.values[i])
+		header := encodeParquetPageHeader(len(page), p.rows)
+
+		chunks[i] = parquetChunk{
+			offset: int64(out.Len()),
+			size:   int64(len(header) + len(page)),
+		}
+		out.Write(header)
+		out.Write(page)
+	}
+
+	footer := p.encodeFooter(chunks)
+	out.Write(footer)
+	_ = binary.Write(&out, binary.LittleEndian, uint32(len(footer))) //nolint:gosec // footer size is far below 4 GiB
+	out.WriteString(parquetMagic)
+
+	if _, err := p.w.Write(out.Bytes()); err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_parquet").
+			Build()
+	}
+	return nil
+}
+
+// parquetChunk records where a column chunk was written
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+// encodeParquetPage encodes definition levels and PLAIN values of a data page
+func encodeParquetPage(columnType ColumnType, values []any) []byte {
+	levels := encodeDefinitionLevels(values)
+
+	var page bytes.Buffer
+	_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels))) //nolint:gosec // bounded by row count
+	page.Write(levels)
+
+	var bits, nbits byte
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		switch columnType {
+		case ColumnString:
+			s := value.(string)
+			_ = binary.Write(&page, binary.LittleEndian, uint32(len(s))) //nolint:gosec // strings are short
+			page.WriteString(s)
+		case ColumnInt64:
+			_ = binary.Write(&page, binary.LittleEndian, value.(int64))
+		case ColumnDouble:
+			_ = binary.Write(&page, binary.LittleEndian, math.Float64bits(value.(float64)))
+		case ColumnTimestamp:
+			_ = binary.Write(&page, binary.LittleEndian, value.(time.Time).UnixMilli())
+		case ColumnBool:
+			// Booleans are bit-packed, least significant bit first
+			if value.(bool) {
+				bits |= 1 << nbits
+			}
+			nbits++
+			if nbits == 8 {
+				page.WriteByte(bits)
+				bits, nbits = 0, 0
+			}
+		}
+	}
+	if nbits > 0 {
+		page.WriteByte(bits)
+	}
+	return page.Bytes()
+}
+
+// encodeDefinitionLevels encodes the null markers of a column as RLE runs of bit width 1
+func encodeDefinitionLevels(values []any) []byte {
+	var buf []byte
+	for i := 0; i < len(values); {
+		level := byte(0)
+		if values[i] != nil {
+			level = 1
+		}
+		run := 1
+		for i+run < len(values) && (values[i+run] != nil) == (level == 1) {
+			run++
+		}
+		buf = binary.AppendUvarint(buf, uint64(run)<<1) //nolint:gosec // run is positive
+		buf = append(buf, level)
+		i += run
+	}
+	return buf
+}
+
+// encodeParquetPageHeader encodes the PageHeader of an uncompressed PLAIN data page
+func encodeParquetPageHeader(pageSize, numValues int) []byte {
+	t := &thriftWriter{}
+	t.i32(1, parquetPageTypeData)
+	t.i32(2, int32(pageSize)) //nolint:gosec // pages are far below 2 GiB
+	t.i32(3, int32(pageSize)) //nolint:gosec // pages are far below 2 GiB
+	t.structBegin(5)
+	t.i32(1, int32(numValues)) //nolint:gosec // row groups are far below 2^31 rows
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRLE)
+	t.i32(4, parquetEncodingRLE)
+	t.structEnd()
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// encodeFooter encodes the FileMetaData describing the schema and the single row group
+func (p *parquetWriter) encodeFooter(chunks []parquetChunk) []byte {
+	t := &thriftWriter{}
+	t.i32(1, 1)
+
+	t.listBegin(2, thriftStruct, len(p.columns)+1)
+	t.elemBegin()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(p.columns))) //nolint:gosec // few columns
+	t.elemEnd()
+	for _, column := range p.columns {
+		physical, converted := column.Type.parquetTypes()
+		t.elemBegin()
+		t.i32(1, physical)
+		t.i32(3, parquetRepetitionOptional)
+		t.binary(4, column.Name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.elemEnd()
+	}
+
+	t.i64(3, int64(p.rows))
+
+	if p.rows == 0 {
+		t.listBegin(4, thriftStruct, 0)
+	} else {
+		var totalSize int64
+		for _, chunk := range chunks {
+			totalSize += chunk.size
+		}
+
+		t.listBegin(4, thriftStruct, 1)
+		t.elemBegin()
+		t.listBegin(1, thriftStruct, len(p.columns))
+		for i, column := range p.columns {
+			physical, _ := column.Type.parquetTypes()
+			t.elemBegin()
+			t.i64(2, chunks[i].offset)
+			t.structBegin(3)
+			t.i32(1, physical)
+			t.listBegin(2, thriftI32, 2)
+			t.listI32(parquetEncodingPlain)
+			t.listI32(parquetEncodingRLE)
+			t.listBegin(3, thriftBinary, 1)
+			t.listBinary(column.Name)
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, int64(p.rows))
+			t.i64(6, chunks[i].size)
+			t.i64(7, chunks[i].size)
+			t.i64(9, chunks[i].offset)
+			t.structEnd()
+			t.elemEnd()
+		}
+		t.i64(2, totalSize)
+		t.i64(3, int64(p.rows))
+		t.elemEnd()
+	}
+
+	t.binary(6, "birdnet-go")
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// thriftWriter encodes structs with the Thrift compact protocol used by Parquet metadata
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+	stack  []int16
+}
+
+// field writes a field header, using the short form when the id delta fits in four bits
+func (t *thriftWriter) field(id int16, fieldType byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(uint64(zigzag(int64(id))))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listBinary(s)
+}
+
+func (t *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType) //nolint:gosec // size < 15
+	} else {
+		t.buf.WriteByte(0xF0 | elemType)
+		t.varint(uint64(size)) //nolint:gosec // size is non-negative
+	}
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) listBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+// structBegin starts a struct field, elemBegin starts a struct list element
+func (t *thriftWriter) structBegin(id int16) {
+	t.field(id, thriftStruct)
+	t.elemBegin()
+}
+
+func (t *thriftWriter) elemBegin() {
+	t.stack = append(t.stack, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) structEnd() {
+	t.elemEnd()
+}
+
+func (t *thriftWriter) elemEnd() {
+	t.stop()
+	t.lastID = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// stop terminates the current struct
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+// zigzag maps signed integers to unsigned so small negative values stay short
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63)) //nolint:gosec // zigzag encoding
+}
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftReader decodes Thrift compact protocol structs into maps keyed by field id
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) int() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1) //nolint:gosec // zigzag decoding
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var lastID int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.int()) //nolint:gosec // field ids are small
+		}
+		fields[id] = r.readValue(header & 0x0f)
+		lastID = id
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) any {
+	switch valueType {
+	case thriftI32, thriftI64:
+		return r.int()
+	case thriftBinary:
+		n := int(r.varint()) //nolint:gosec // test data
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint()) //nolint:gosec // test data
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic("unsupported thrift type")
+}
+
+// readParquetFooter checks the magic bytes and decodes the FileMetaData of a Parquet file
+func readParquetFooter(t *testing.T, data []byte) map[int16]any {
+	t.Helper()
+	require.Equal(t, parquetMagic, string(data[:4]))
+	require.Equal(t, parquetMagic, string(data[len(data)-4:]))
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[len(data)-8-footerLen : len(data)-8]}
+	metadata := footer.readStruct()
+	require.Equal(t, footerLen, footer.pos, "footer must be fully consumed")
+	return metadata
+}
+
+func TestParquetWriter(t *testing.T) {
+	t.Parallel()
+
+	columns := []Column{
+		{"species", ColumnString},
+		{"confidence", ColumnDouble},
+		{"count", ColumnInt64},
+		{"verified", ColumnBool},
+		{"begin_time", ColumnTimestamp},
+	}
+	begin := time.Date(2025, 5, 11, 6, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	w := newParquetWriter(&buf, columns)
+	require.NoError(t, w.Write([]any{"Turdus merula", 0.91, int64(3), true, begin}))
+	require.NoError(t, w.Write([]any{nil, nil, nil, nil, nil}))
+	require.NoError(t, w.Write([]any{"Parus major", 0.75, int64(1), false, begin.Add(time.Minute)}))
+	require.Error(t, w.Write([]any{"too few"}))
+	require.Error(t, w.Write([]any{1, 0.5, int64(1), true, begin}), "type mismatch is rejected")
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	metadata := readParquetFooter(t, data)
+	assert.Equal(t, int64(1), metadata[1])
+	assert.Equal(t, int64(3), metadata[3])
+
+	schema := metadata[2].([]any)
+	require.Len(t, schema, len(columns)+1)
+	assert.Equal(t, "schema", schema[0].(map[int16]any)[4])
+	assert.Equal(t, int64(len(columns)), schema[0].(map[int16]any)[5])
+	species := schema[1].(map[int16]any)
+	assert.Equal(t, "species", species[4])
+	assert.Equal(t, int64(parquetTypeByteArray), species[1])
+	assert.Equal(t, int64(parquetConvertedUTF8), species[6])
+	assert.Equal(t, int64(parquetConvertedTimestampMillis), schema[5].(map[int16]any)[6])
+
+	rowGroups := metadata[4].([]any)
+	require.Len(t, rowGroups, 1)
+	chunks := rowGroups[0].(map[int16]any)[1].([]any)
+	require.Len(t, chunks, len(columns))
+
+	// Decode the string and double columns from their data pages
+	readPage := func(chunk map[int16]any) (levels, values []byte) {
+		meta := chunk[3].(map[int16]any)
+		r := &thriftReader{data: data, pos: int(meta[9].(int64))}
+		header := r.readStruct()
+		assert.Equal(t, int64(3), header[5].(map[int16]any)[1], "page num_values counts nulls")
+		page := data[r.pos : r.pos+int(header[2].(int64))]
+		levelsLen := int(binary.LittleEndian.Uint32(page))
+		return page[4 : 4+levelsLen], page[4+levelsLen:]
+	}
+
+	levels, values := readPage(chunks[0].(map[int16]any))
+	assert.Equal(t, []byte{2, 1, 2, 0, 2, 1}, levels, "RLE runs of present, null, present")
+	assert.Equal(t, uint32(len("Turdus merula")), binary.LittleEndian.Uint32(values))
+	assert.Equal(t, "Turdus merula", string(values[4:4+len("Turdus merula")]))
+
+	_, values = readPage(chunks[1].(map[int16]any))
+	require.Len(t, values, 16)
+	assert.InDelta(t, 0.91, math.Float64frombits(binary.LittleEndian.Uint64(values)), 1e-9)
+	assert.InDelta(t, 0.75, math.Float64frombits(binary.LittleEndian.Uint64(values[8:])), 1e-9)
+
+	_, values = readPage(chunks[3].(map[int16]any))
+	assert.Equal(t, []byte{0b01}, values, "booleans are bit-packed")
+
+	_, values = readPage(chunks[4].(map[int16]any))
+	assert.Equal(t, begin.UnixMilli(), int64(binary.LittleEndian.Uint64(values))) //nolint:gosec // test data
+}
+
+func TestParquetWriterEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := newParquetWriter(&buf, []Column{{"species", ColumnString}})
+	require.NoError(t, w.Close())
+
+	metadata := readParquetFooter(t, buf.Bytes())
+	assert.Equal(t, int64(0), metadata[3])
+	assert.Empty(t, metadata[4])
+}
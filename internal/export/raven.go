@@ -0,0 +1,254 @@
+// raven.go: export of detections as Raven Pro selection tables
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Raven selection table grouping rules
+const (
+	RavenPerDay  = "day"  // one table per day, times relative to local midnight
+	RavenPerClip = "clip" // one table per saved audio clip, times relative to the clip start
+)
+
+// detectionLength is the length of a selection or label, one BirdNET analysis window
+const detectionLength = conf.AnalysisWindow
+
+// ravenTableSuffix is the file name suffix Raven uses to pair selection tables with sound files
+const ravenTableSuffix = ".Table.1.selections.txt"
+
+// ravenHeader lists the selection table columns. Raven requires the first seven, the rest
+// are shown as annotations.
+var ravenHeader = []string{
+	"Selection", "View", "Channel", "Begin Time (s)", "End Time (s)", "Low Freq (Hz)", "High Freq (Hz)",
+	"Begin File", "Begin Date", "Begin Clock Time", "Species Code", "Common Name", "Scientific Name", "Confidence",
+}
+
+// FrequencyRange is the frequency band of a selection in Hz
+type FrequencyRange struct {
+	Low  float64
+	High float64
+}
+
+// RavenOptions selects the detections and layout of a Raven selection table export
+type RavenOptions struct {
+	StartDate     string                    // first date to export, YYYY-MM-DD
+	EndDate       string                    // last date to export, YYYY-MM-DD
+	Grouping      string                    // RavenPerDay or RavenPerClip
+	MinConfidence float64                   // detections below this confidence are left out
+	PreCapture    time.Duration             // audio saved before the detection at the start of each clip
+	Frequency     FrequencyRange            // default selection band
+	Species       map[string]FrequencyRange // selection bands by lowercase scientific or common name
+}
+
+// RavenSummary reports what a Raven export wrote
+type RavenSummary struct {
+	Tables     int `json:"tables"`
+	Selections int `json:"selections"`
+	Skipped    int `json:"skipped"` // detections without a saved clip in per-clip exports
+}
+
+// Validate checks the grouping, frequency bands and date range of the options
+func (o *RavenOptions) Validate() error {
+	if o.Grouping != RavenPerDay && o.Grouping != RavenPerClip {
+		return errors.Newf("raven table grouping must be day or clip, got %q", o.Grouping).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return errors.Newf("minimum confidence must be between 0 and 1, got %v", o.MinConfidence).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if o.Frequency.Low < 0 || o.Frequency.Low >= o.Frequency.High {
+		return errors.Newf("invalid raven frequency range %v-%v Hz", o.Frequency.Low, o.Frequency.High).
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	opts := Options{Format: FormatCSV, StartDate: o.StartDate, EndDate: o.EndDate}
+	return opts.Validate()
+}
+
+// frequency returns the selection band of a species
+func (o *RavenOptions) frequency(note *datastore.Note) FrequencyRange {
+	if band, ok := o.Species[strings.ToLower(note.ScientificName)]; ok {
+		return band
+	}
+	if band, ok := o.Species[strings.ToLower(note.CommonName)]; ok {
+		return band
+	}
+	return o.Frequency
+}
+
+// ravenTable is a selection table being assembled
+type ravenTable struct {
+	name string
+	rows [][]string
+}
+
+// WriteRavenArchive writes the detections in the date range as a zip archive of Raven Pro
+// selection tables, one per day or one per saved audio clip. Per-clip tables are named after
+// the clip so Raven opens them together with the sound file.
+func WriteRavenArchive(w io.Writer, store Store, opts *RavenOptions) (RavenSummary, error) {
+	var summary RavenSummary
+	if err := opts.Validate(); err != nil {
+		return summary, err
+	}
+
+	zw := zip.NewWriter(w)
+	dateOpts := Options{StartDate: opts.StartDate, EndDate: opts.EndDate}
+	for _, date := range dateOpts.Dates() {
+		notes, err := store.GetNotesByDate(date, false)
+		if err != nil {
+			return summary, errors.New(err).
+				Component("export").
+				Category(errors.CategoryDatabase).
+				Context("date", date).
+				Build()
+		}
+
+		tables, skipped := ravenTables(date, notes, opts)
+		summary.Skipped += skipped
+		for _, table := range tables {
+			if err := writeRavenTable(zw, table); err != nil {
+				return summary, err
+			}
+			summary.Tables++
+			summary.Selections += len(table.rows)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return summary, errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Build()
+	}
+	return summary, nil
+}
+
+// ravenTables groups the notes of a day into selection tables ordered by name
+func ravenTables(date string, notes []datastore.Note, opts *RavenOptions) (tables []*ravenTable, skipped int) {
+	// Selections are numbered in time order within each table
+	sort.SliceStable(notes, func(i, j int) bool { return noteBeginTime(&notes[i]).Before(noteBeginTime(&notes[j])) })
+
+	byName := make(map[string]*ravenTable)
+	for i := range notes {
+		note := &notes[i]
+		if note.Confidence < opts.MinConfidence {
+			continue
+		}
+
+		begin := noteBeginTime(note)
+		var name string
+		var offset time.Duration
+		if opts.Grouping == RavenPerClip {
+			clip := ravenClipPath(note.ClipName)
+			if clip == "" {
+				skipped++
+				continue
+			}
+			name = strings.TrimSuffix(clip, path.Ext(clip)) + ravenTableSuffix
+			offset = opts.PreCapture
+		} else {
+			name = date + ravenTableSuffix
+			midnight := time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, begin.Location())
+			offset = begin.Sub(midnight)
+		}
+
+		table := byName[name]
+		if table == nil {
+			table = &ravenTable{name: name}
+			byName[name] = table
+			tables = append(tables, table)
+		}
+
+		beginFile := ""
+		if note.ClipName != "" {
+			beginFile = filepath.Base(note.ClipName)
+		}
+
+		band := opts.frequency(note)
+		table.rows = append(table.rows, []string{
+			fmt.Sprint(len(table.rows) + 1),
+			"Spectrogram 1",
+			"1",
+			fmt.Sprintf("%.3f", offset.Seconds()),
+			fmt.Sprintf("%.3f", (offset + detectionLength).Seconds()),
+			fmt.Sprintf("%.1f", band.Low),
+			fmt.Sprintf("%.1f", band.High),
+			beginFile,
+			begin.Format("2006/01/02"),
+			begin.Format("15:04:05"),
+			note.SpeciesCode,
+			note.CommonName,
+			note.ScientificName,
+			fmt.Sprintf("%.4f", note.Confidence),
+		})
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i].name < tables[j].name })
+	return tables, skipped
+}
+
+// writeRavenTable adds a tab-separated selection table to the archive
+func writeRavenTable(zw *zip.Writer, table *ravenTable) error {
+	f, err := zw.Create(table.name)
+	if err == nil {
+		_, err = io.WriteString(f, strings.Join(ravenHeader, "\t")+"\n")
+		for _, row := range table.rows {
+			if err != nil {
+				break
+			}
+			_, err = io.WriteString(f, strings.Join(row, "\t")+"\n")
+		}
+	}
+	if err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("table", table.name).
+			Build()
+	}
+	return nil
+}
+
+// noteBeginTime returns the start of the detection, falling back to the stored date and
+// time for records without a begin time
+func noteBeginTime(note *datastore.Note) time.Time {
+	if !note.BeginTime.IsZero() {
+		return note.BeginTime.Local()
+	}
+	t, err := time.ParseInLocation(time.DateTime, note.Date+" "+note.Time, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// ravenClipPath returns the clip path as a relative archive path, or an empty string when
+// the note has no usable clip
+func ravenClipPath(clipName string) string {
+	if clipName == "" {
+		return ""
+	}
+	clip := path.Clean(strings.TrimLeft(filepath.ToSlash(clipName), "/"))
+	if clip == "." || clip == ".." || strings.HasPrefix(clip, "../") {
+		return ""
+	}
+	return clip
+}
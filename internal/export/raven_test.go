@@ -0,0 +1,128 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func newRavenTestStore() *mockStore {
+	day := func(h, m, s int) time.Time { return time.Date(2025, 5, 10, h, m, s, 0, time.Local) }
+	return &mockStore{
+		notes: map[string][]datastore.Note{
+			"2025-05-10": {
+				{Date: "2025-05-10", Time: "21:40:00", BeginTime: day(21, 40, 0), ScientificName: "Strix aluco", CommonName: "Tawny Owl", SpeciesCode: "tawowl1",
+					Confidence: 0.8, ClipName: "clips/2025/05/strix_aluco_80p_20250510T214000Z.wav"},
+				{Date: "2025-05-10", Time: "06:15:30", BeginTime: day(6, 15, 30), ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", SpeciesCode: "eurbla",
+					Confidence: 0.9, ClipName: "clips/2025/05/turdus_merula_90p_20250510T061530Z.wav"},
+				{Date: "2025-05-10", Time: "07:00:00", ScientificName: "Parus major", CommonName: "Great Tit", SpeciesCode: "gretit1", Confidence: 0.85},
+				{Date: "2025-05-10", Time: "08:00:00", ScientificName: "Parus major", CommonName: "Great Tit", SpeciesCode: "gretit1", Confidence: 0.3},
+			},
+		},
+	}
+}
+
+// readRavenArchive returns the tables of an archive as rows of tab-separated fields
+func readRavenArchive(t *testing.T, data []byte) map[string][][]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	tables := make(map[string][][]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		var rows [][]string
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			rows = append(rows, strings.Split(line, "\t"))
+		}
+		tables[f.Name] = rows
+	}
+	return tables
+}
+
+func TestWriteRavenArchivePerDay(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	summary, err := WriteRavenArchive(&buf, newRavenTestStore(), &RavenOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-11", Grouping: RavenPerDay, MinConfidence: 0.5,
+		Frequency: FrequencyRange{Low: 0, High: 15000},
+		Species:   map[string]FrequencyRange{"strix aluco": {Low: 300, High: 2000}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RavenSummary{Tables: 1, Selections: 3}, summary)
+
+	tables := readRavenArchive(t, buf.Bytes())
+	rows, ok := tables["2025-05-10.Table.1.selections.txt"]
+	require.True(t, ok, "tables: %v", tables)
+	require.Len(t, rows, 4)
+	assert.Equal(t, ravenHeader, rows[0])
+
+	assert.Equal(t, []string{"1", "Spectrogram 1", "1", "22530.000", "22533.000", "0.0", "15000.0", "turdus_merula_90p_20250510T061530Z.wav"}, rows[1][:8])
+	assert.Equal(t, "Great Tit", rows[2][11], "records without a begin time use the stored time")
+	assert.Equal(t, "25200.000", rows[2][3])
+	assert.Equal(t, []string{"3", "78000.000", "300.0", "2000.0", "Strix aluco", "0.8000"}, []string{rows[3][0], rows[3][3], rows[3][5], rows[3][6], rows[3][12], rows[3][13]})
+}
+
+func TestWriteRavenArchivePerClip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	summary, err := WriteRavenArchive(&buf, newRavenTestStore(), &RavenOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-10", Grouping: RavenPerClip, MinConfidence: 0.5,
+		PreCapture: 3 * time.Second, Frequency: FrequencyRange{Low: 0, High: 15000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RavenSummary{Tables: 2, Selections: 2, Skipped: 1}, summary)
+
+	tables := readRavenArchive(t, buf.Bytes())
+	rows, ok := tables["clips/2025/05/turdus_merula_90p_20250510T061530Z.Table.1.selections.txt"]
+	require.True(t, ok, "tables: %v", tables)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []string{"1", "Spectrogram 1", "1", "3.000", "6.000"}, rows[1][:5])
+}
+
+func TestRavenOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := RavenOptions{StartDate: "2025-05-10", EndDate: "2025-05-10", Grouping: RavenPerDay, Frequency: FrequencyRange{High: 15000}}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(o *RavenOptions)
+	}{
+		{"unknown grouping", func(o *RavenOptions) { o.Grouping = "file" }},
+		{"confidence above one", func(o *RavenOptions) { o.MinConfidence = 2 }},
+		{"empty frequency range", func(o *RavenOptions) { o.Frequency = FrequencyRange{} }},
+		{"invalid date", func(o *RavenOptions) { o.EndDate = "tomorrow" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+		})
+	}
+}
+
+func TestRavenClipPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "clips/a.wav", ravenClipPath("/clips/a.wav"))
+	assert.Equal(t, "a.wav", ravenClipPath("clips/../a.wav"))
+	assert.Empty(t, ravenClipPath("../a.wav"))
+	assert.Empty(t, ravenClipPath(""))
+}
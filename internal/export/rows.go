@@ -0,0 +1,227 @@
+// rows.go: export columns and conversion of detections to rows
+package export
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// weatherMaxDistance is how far from a detection an hourly weather reading may be to be joined
+const weatherMaxDistance = time.Hour
+
+// ColumnType is the value type of an export column
+type ColumnType int
+
+// Export column types
+const (
+	ColumnString ColumnType = iota
+	ColumnInt64
+	ColumnDouble
+	ColumnBool
+	ColumnTimestamp
+)
+
+// accepts reports whether a non-nil value matches the column type
+func (t ColumnType) accepts(value any) bool {
+	switch value.(type) {
+	case string:
+		return t == ColumnString
+	case int64:
+		return t == ColumnInt64
+	case float64:
+		return t == ColumnDouble
+	case bool:
+		return t == ColumnBool
+	case time.Time:
+		return t == ColumnTimestamp
+	}
+	return false
+}
+
+// parquetTypes returns the Parquet physical type and converted type, -1 for none
+func (t ColumnType) parquetTypes() (physical, converted int32) {
+	switch t {
+	case ColumnString:
+		return parquetTypeByteArray, parquetConvertedUTF8
+	case ColumnInt64:
+		return parquetTypeInt64, -1
+	case ColumnDouble:
+		return parquetTypeDouble, -1
+	case ColumnBool:
+		return parquetTypeBoolean, -1
+	default:
+		return parquetTypeInt64, parquetConvertedTimestampMillis
+	}
+}
+
+// Column is a named, typed export column
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+var (
+	detectionColumns = []Column{
+		{"id", ColumnInt64},
+		{"date", ColumnString},
+		{"time", ColumnString},
+		{"begin_time", ColumnTimestamp},
+		{"end_time", ColumnTimestamp},
+		{"source_node", ColumnString},
+		{"scientific_name", ColumnString},
+		{"common_name", ColumnString},
+		{"species_code", ColumnString},
+		{"confidence", ColumnDouble},
+		{"latitude", ColumnDouble},
+		{"longitude", ColumnDouble},
+		{"threshold", ColumnDouble},
+		{"sensitivity", ColumnDouble},
+		{"clip_name", ColumnString},
+		{"processing_time_ms", ColumnInt64},
+	}
+
+	resultColumns = []Column{
+		{"result_species", ColumnString},
+		{"result_confidence", ColumnDouble},
+	}
+
+	weatherColumns = []Column{
+		{"weather_time", ColumnTimestamp},
+		{"temperature", ColumnDouble},
+		{"feels_like", ColumnDouble},
+		{"humidity", ColumnInt64},
+		{"pressure", ColumnInt64},
+		{"wind_speed", ColumnDouble},
+		{"wind_gust", ColumnDouble},
+		{"wind_deg", ColumnInt64},
+		{"precipitation", ColumnDouble},
+		{"clouds", ColumnInt64},
+		{"weather_main", ColumnString},
+		{"weather_description", ColumnString},
+	}
+)
+
+// Columns returns the export columns for the options
+func (o *Options) Columns() []Column {
+	columns := append([]Column(nil), detectionColumns...)
+	if o.IncludeResults {
+		columns = append(columns, resultColumns...)
+	}
+	if o.IncludeWeather {
+		columns = append(columns, weatherColumns...)
+	}
+	return columns
+}
+
+// rowsForNote converts a detection to export rows. With results included the detection is
+// left joined with its results, producing one row per result.
+func (o *Options) rowsForNote(note *datastore.Note, weather []datastore.HourlyWeather) [][]any {
+	base := []any{
+		int64(note.ID),
+		note.Date,
+		note.Time,
+		timeOrNil(note.BeginTime),
+		timeOrNil(note.EndTime),
+		note.SourceNode,
+		note.ScientificName,
+		note.CommonName,
+		note.SpeciesCode,
+		note.Confidence,
+		note.Latitude,
+		note.Longitude,
+		note.Threshold,
+		note.Sensitivity,
+		note.ClipName,
+		note.ProcessingTime.Milliseconds(),
+	}
+
+	var weatherValues []any
+	if o.IncludeWeather {
+		weatherValues = weatherRow(note, nearestWeather(weather, detectionTime(note)))
+	}
+
+	if !o.IncludeResults {
+		return [][]any{append(base, weatherValues...)}
+	}
+
+	if len(note.Results) == 0 {
+		row := append(append([]any(nil), base...), nil, nil)
+		return [][]any{append(row, weatherValues...)}
+	}
+
+	rows := make([][]any, 0, len(note.Results))
+	for _, result := range note.Results {
+		row := append(append([]any(nil), base...), result.Species, float64(result.Confidence))
+		rows = append(rows, append(row, weatherValues...))
+	}
+	return rows
+}
+
+// weatherRow returns the weather values for a detection. Readings stored with the
+// detection take precedence over the hourly reading.
+func weatherRow(note *datastore.Note, hourly *datastore.HourlyWeather) []any {
+	row := make([]any, len(weatherColumns))
+	if hourly != nil {
+		row = []any{
+			hourly.Time,
+			hourly.Temperature,
+			hourly.FeelsLike,
+			int64(hourly.Humidity),
+			int64(hourly.Pressure),
+			hourly.WindSpeed,
+			hourly.WindGust,
+			int64(hourly.WindDeg),
+			hourly.Precipitation,
+			int64(hourly.Clouds),
+			hourly.WeatherMain,
+			hourly.WeatherDesc,
+		}
+	}
+	if note.WeatherTemperature != nil {
+		row[1] = *note.WeatherTemperature
+	}
+	if note.WeatherWindSpeed != nil {
+		row[5] = *note.WeatherWindSpeed
+	}
+	if note.WeatherPrecipitation != nil {
+		row[8] = *note.WeatherPrecipitation
+	}
+	return row
+}
+
+// nearestWeather returns the hourly reading closest to t within weatherMaxDistance
+func nearestWeather(weather []datastore.HourlyWeather, t time.Time) *datastore.HourlyWeather {
+	if t.IsZero() {
+		return nil
+	}
+	var nearest *datastore.HourlyWeather
+	var best time.Duration
+	for i := range weather {
+		distance := weather[i].Time.Sub(t).Abs()
+		if distance <= weatherMaxDistance && (nearest == nil || distance < best) {
+			nearest, best = &weather[i], distance
+		}
+	}
+	return nearest
+}
+
+// detectionTime returns the begin time of a detection, falling back to its date and time
+func detectionTime(note *datastore.Note) time.Time {
+	if !note.BeginTime.IsZero() {
+		return note.BeginTime
+	}
+	t, err := time.ParseInLocation(time.DateTime, note.Date+" "+note.Time, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// timeOrNil returns nil for the zero time so it is exported as null
+func timeOrNil(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
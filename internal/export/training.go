@@ -0,0 +1,217 @@
+// training.go: export of reviewed clips as a labeled dataset for training custom models
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// TrainingNoiseClass is the class folder of clips reviewed as false positives
+const TrainingNoiseClass = "Noise"
+
+// TrainingDirName is the directory in the export directory holding training datasets, the
+// export manager does not treat it as a job
+const TrainingDirName = "training"
+
+// trainingLabelsFile lists every exported clip with its label and review
+const trainingLabelsFile = "labels.csv"
+
+// trainingHeader lists the columns of the labels file
+var trainingHeader = []string{
+	"file", "label", "review", "scientific_name", "common_name", "confidence", "date", "time", "detection_id",
+}
+
+// TrainingStore provides the reviewed detections of a training dataset export.
+// *datastore.DataStore implements TrainingStore.
+type TrainingStore interface {
+	GetReviewedNotes(startDate, endDate string) ([]datastore.Note, error)
+}
+
+// TrainingOptions selects the reviewed detections of a training dataset export
+type TrainingOptions struct {
+	StartDate    string // first date to export, YYYY-MM-DD
+	EndDate      string // last date to export, YYYY-MM-DD
+	ClipsDir     string // directory the clip paths of detections are relative to
+	IncludeNoise bool   // export false positives into the TrainingNoiseClass folder
+}
+
+// TrainingSummary reports what a training dataset export wrote
+type TrainingSummary struct {
+	Clips   int `json:"clips"`
+	Classes int `json:"classes"`
+	Skipped int `json:"skipped"` // reviewed detections without a readable clip
+}
+
+// Validate checks the clips directory and date range of the options
+func (o *TrainingOptions) Validate() error {
+	if o.ClipsDir == "" {
+		return errors.Newf("clips directory is required for a training dataset export").
+			Component("export").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	opts := Options{Format: FormatCSV, StartDate: o.StartDate, EndDate: o.EndDate}
+	return opts.Validate()
+}
+
+// WriteTrainingDataset copies the clips of reviewed detections in the date range into dir,
+// one folder per class named "Scientific name_Common name" as in BirdNET labels, which is
+// the layout the BirdNET-Analyzer training scripts expect. Detections reviewed as correct
+// are filed under their species, misidentified ones under the corrected species and false
+// positives under TrainingNoiseClass when enabled. labels.csv in dir lists every clip.
+func WriteTrainingDataset(dir string, store TrainingStore, opts *TrainingOptions) (TrainingSummary, error) {
+	var summary TrainingSummary
+	if err := opts.Validate(); err != nil {
+		return summary, err
+	}
+
+	notes, err := store.GetReviewedNotes(opts.StartDate, opts.EndDate)
+	if err != nil {
+		return summary, errors.New(err).
+			Component("export").
+			Category(errors.CategoryDatabase).
+			Context("start_date", opts.StartDate).
+			Context("end_date", opts.EndDate).
+			Build()
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return summary, trainingFileError(err, "create_training_dir", dir)
+	}
+	labelsPath := filepath.Join(dir, trainingLabelsFile)
+	labelsFile, err := os.Create(labelsPath)
+	if err != nil {
+		return summary, trainingFileError(err, "create_training_labels", labelsPath)
+	}
+	defer labelsFile.Close()
+
+	cw := csv.NewWriter(labelsFile)
+	if err := cw.Write(trainingHeader); err != nil {
+		return summary, trainingFileError(err, "write_training_labels", labelsPath)
+	}
+
+	classes := make(map[string]struct{})
+	for i := range notes {
+		note := &notes[i]
+		label, ok := trainingLabel(note, opts.IncludeNoise)
+		if !ok {
+			continue
+		}
+		clip := ravenClipPath(note.ClipName)
+		if clip == "" {
+			summary.Skipped++
+			continue
+		}
+
+		class := trainingClassDir(label)
+		name := strconv.FormatUint(uint64(note.ID), 10) + "_" + path.Base(clip)
+		dst := filepath.Join(dir, class, name)
+		copied, err := copyTrainingClip(filepath.Join(opts.ClipsDir, filepath.FromSlash(clip)), dst)
+		if err != nil {
+			return summary, err
+		}
+		if !copied {
+			summary.Skipped++
+			continue
+		}
+
+		if err := cw.Write([]string{
+			class + "/" + name,
+			label,
+			note.Review.Verified,
+			note.ScientificName,
+			note.CommonName,
+			strconv.FormatFloat(note.Confidence, 'f', 4, 64),
+			note.Date,
+			note.Time,
+			strconv.FormatUint(uint64(note.ID), 10),
+		}); err != nil {
+			return summary, trainingFileError(err, "write_training_labels", labelsPath)
+		}
+		classes[class] = struct{}{}
+		summary.Clips++
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return summary, trainingFileError(err, "write_training_labels", labelsPath)
+	}
+	if err := labelsFile.Close(); err != nil {
+		return summary, trainingFileError(err, "write_training_labels", labelsPath)
+	}
+	summary.Classes = len(classes)
+	return summary, nil
+}
+
+// trainingLabel returns the "Scientific name_Common name" label of a reviewed detection and
+// whether it belongs in the dataset
+func trainingLabel(note *datastore.Note, includeNoise bool) (string, bool) {
+	if note.Review == nil {
+		return "", false
+	}
+	switch note.Review.Verified {
+	case datastore.ReviewCorrect:
+		return note.ScientificName + "_" + note.CommonName, true
+	case datastore.ReviewMisidentified:
+		if note.Review.CorrectedScientificName == "" {
+			return "", false
+		}
+		return note.Review.CorrectedScientificName + "_" + note.Review.CorrectedCommonName, true
+	case datastore.ReviewFalsePositive:
+		return TrainingNoiseClass, includeNoise
+	default:
+		return "", false
+	}
+}
+
+// trainingClassDir returns the label as a folder name, replacing path separators
+func trainingClassDir(label string) string {
+	return strings.NewReplacer("/", "-", "\\", "-").Replace(label)
+}
+
+// copyTrainingClip copies a clip into the dataset. It returns false without an error when
+// the clip no longer exists.
+func copyTrainingClip(src, dst string) (bool, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, trainingFileError(err, "open_training_clip", src)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return false, trainingFileError(err, "create_training_class_dir", filepath.Dir(dst))
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return false, trainingFileError(err, "create_training_clip", dst)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return false, trainingFileError(err, "copy_training_clip", dst)
+	}
+	if err := out.Close(); err != nil {
+		return false, trainingFileError(err, "copy_training_clip", dst)
+	}
+	return true, nil
+}
+
+// trainingFileError wraps a file error of a training dataset export
+func trainingFileError(err error, operation, filePath string) error {
+	return errors.New(err).
+		Component("export").
+		Category(errors.CategoryFileIO).
+		Context("operation", operation).
+		Context("path", filePath).
+		Build()
+}
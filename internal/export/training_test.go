@@ -0,0 +1,98 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// trainingStore serves a fixed set of reviewed notes
+type trainingStore []datastore.Note
+
+func (s trainingStore) GetReviewedNotes(startDate, endDate string) ([]datastore.Note, error) {
+	return s, nil
+}
+
+func TestWriteTrainingDataset(t *testing.T) {
+	t.Parallel()
+
+	clipsDir := t.TempDir()
+	for _, clip := range []string{"2025/05/robin.wav", "2025/05/thrush.wav", "2025/05/noise.wav"} {
+		path := filepath.Join(clipsDir, filepath.FromSlash(clip))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(clip), 0o644))
+	}
+
+	note := func(id uint, clip string, review datastore.NoteReview) datastore.Note {
+		return datastore.Note{
+			ID: id, Date: "2025-05-10", Time: "06:00:00", ScientificName: "Erithacus rubecula", CommonName: "European Robin",
+			Confidence: 0.8, ClipName: clip, Review: &review,
+		}
+	}
+	store := trainingStore{
+		note(1, "2025/05/robin.wav", datastore.NoteReview{Verified: datastore.ReviewCorrect}),
+		note(2, "2025/05/thrush.wav", datastore.NoteReview{Verified: datastore.ReviewMisidentified,
+			CorrectedScientificName: "Turdus philomelos", CorrectedCommonName: "Song Thrush"}),
+		note(3, "2025/05/noise.wav", datastore.NoteReview{Verified: datastore.ReviewFalsePositive}),
+		note(4, "2025/05/deleted.wav", datastore.NoteReview{Verified: datastore.ReviewCorrect}),
+		note(5, "../outside.wav", datastore.NoteReview{Verified: datastore.ReviewCorrect}),
+	}
+
+	dir := filepath.Join(t.TempDir(), "dataset")
+	summary, err := WriteTrainingDataset(dir, store, &TrainingOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-10", ClipsDir: clipsDir, IncludeNoise: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TrainingSummary{Clips: 3, Classes: 3, Skipped: 2}, summary)
+
+	data, err := os.ReadFile(filepath.Join(dir, "Turdus philomelos_Song Thrush", "2_thrush.wav"))
+	require.NoError(t, err)
+	assert.Equal(t, "2025/05/thrush.wav", string(data))
+	assert.FileExists(t, filepath.Join(dir, "Erithacus rubecula_European Robin", "1_robin.wav"))
+	assert.FileExists(t, filepath.Join(dir, TrainingNoiseClass, "3_noise.wav"))
+
+	f, err := os.Open(filepath.Join(dir, trainingLabelsFile))
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, trainingHeader, rows[0])
+	assert.Equal(t, []string{"Turdus philomelos_Song Thrush/2_thrush.wav", "Turdus philomelos_Song Thrush", "misidentified",
+		"Erithacus rubecula", "European Robin", "0.8000", "2025-05-10", "06:00:00", "2"}, rows[2])
+}
+
+func TestWriteTrainingDatasetWithoutNoise(t *testing.T) {
+	t.Parallel()
+
+	clipsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(clipsDir, "noise.wav"), []byte("noise"), 0o644))
+	store := trainingStore{{ID: 1, Date: "2025-05-10", ClipName: "noise.wav",
+		Review: &datastore.NoteReview{Verified: datastore.ReviewFalsePositive}}}
+
+	dir := t.TempDir()
+	summary, err := WriteTrainingDataset(dir, store, &TrainingOptions{
+		StartDate: "2025-05-10", EndDate: "2025-05-10", ClipsDir: clipsDir,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TrainingSummary{}, summary)
+	assert.NoDirExists(t, filepath.Join(dir, TrainingNoiseClass))
+}
+
+func TestTrainingOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	opts := TrainingOptions{StartDate: "2025-05-10", EndDate: "2025-05-10"}
+	require.Error(t, opts.Validate(), "clips directory is required")
+
+	opts.ClipsDir = "clips"
+	require.NoError(t, opts.Validate())
+
+	opts.EndDate = "2025-05-01"
+	require.Error(t, opts.Validate())
+}
@@ -0,0 +1,85 @@
+// writer.go: CSV and Parquet partition writers
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// rowWriter writes export rows in a file format
+type rowWriter interface {
+	Write(row []any) error
+	Close() error
+}
+
+// newRowWriter creates a writer for the format that writes the columns to w
+func newRowWriter(format string, w io.Writer, columns []Column) (rowWriter, error) {
+	if format == FormatParquet {
+		return newParquetWriter(w, columns), nil
+	}
+
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	if err := cw.w.Write(header); err != nil {
+		return nil, errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_csv_header").
+			Build()
+	}
+	return cw, nil
+}
+
+// csvWriter writes rows as CSV with RFC 3339 timestamps and empty fields for nulls
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+// Write writes a row
+func (c *csvWriter) Write(row []any) error {
+	c.record = c.record[:0]
+	for _, value := range row {
+		c.record = append(c.record, formatCSVValue(value))
+	}
+	return c.w.Write(c.record)
+}
+
+// Close flushes buffered rows
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return errors.New(err).
+			Component("export").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_csv").
+			Build()
+	}
+	return nil
+}
+
+// formatCSVValue formats a value for a CSV field
+func formatCSVValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return ""
+}
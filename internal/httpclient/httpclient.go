@@ -0,0 +1,119 @@
+// Package httpclient creates the HTTP clients used to connect to external services. Every
+// client uses the outbound proxy and TLS settings, sets a user agent and records request
+// latency and status per integration.
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+)
+
+// Defaults of clients created without the corresponding option
+const (
+	DefaultTimeout   = 30 * time.Second
+	DefaultUserAgent = "BirdNET-Go https://github.com/tphakala/birdnet-go"
+)
+
+// Options configures an HTTP client for an integration
+type Options struct {
+	Integration        string        // Integration name in metrics, e.g. "birdweather"
+	Timeout            time.Duration // Overall request timeout, DefaultTimeout if zero
+	UserAgent          string        // User agent of requests that do not set one, DefaultUserAgent if empty
+	InsecureSkipVerify bool          // TLS certificate verification setting of the integration
+
+	// Connection pool tuning, zero values keep the defaults of http.DefaultTransport
+	MaxIdleConns        int
+	IdleConnTimeout     time.Duration
+	TLSHandshakeTimeout time.Duration
+}
+
+// outboundMetrics records requests of all clients once metrics are enabled
+var outboundMetrics atomic.Pointer[metrics.OutboundHTTPMetrics]
+
+// SetMetrics sets the metrics recorder of outbound requests
+func SetMetrics(m *metrics.OutboundHTTPMetrics) {
+	outboundMetrics.Store(m)
+}
+
+// New returns an HTTP client for an integration. settings provides the outbound proxy and
+// TLS settings; with nil settings the proxy environment variables apply.
+func New(settings *conf.Settings, opts Options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.MaxIdleConns > 0 {
+		transport.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+	}
+	if settings != nil {
+		if err := settings.ConfigureOutboundTransport(transport, opts.InsecureSkipVerify); err != nil {
+			return nil, errors.New(err).
+				Component("httpclient").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "create_http_client").
+				Context("integration", opts.Integration).
+				Build()
+		}
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &instrumentedTransport{
+			next:        transport,
+			integration: opts.Integration,
+			userAgent:   userAgent,
+		},
+	}, nil
+}
+
+// instrumentedTransport sets the user agent of requests and records their metrics
+type instrumentedTransport struct {
+	next        http.RoundTripper
+	integration string
+	userAgent   string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// Round trippers must not modify the request of the caller
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if m := outboundMetrics.Load(); m != nil {
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		m.RecordRequest(t.integration, req.Method, status, time.Since(start).Seconds())
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes the idle connections of the underlying transport
+func (t *instrumentedTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
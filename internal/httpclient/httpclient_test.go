@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+)
+
+func TestNewSetsUserAgentAndRecordsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := metrics.NewOutboundHTTPMetrics(registry)
+	require.NoError(t, err)
+	SetMetrics(m)
+	t.Cleanup(func() { SetMetrics(nil) })
+
+	userAgents := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(&conf.Settings{}, Options{Integration: "test", Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	// Requests without a user agent get the default one
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, DefaultUserAgent, <-userAgents)
+
+	// A user agent set on the request is kept
+	req, err := http.NewRequest(http.MethodGet, server.URL, http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "custom")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "custom", <-userAgents)
+
+	assert.InDelta(t, 2, requestCount(t, registry, "418"), 0)
+}
+
+func TestNewRecordsFailedRequests(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := metrics.NewOutboundHTTPMetrics(registry)
+	require.NoError(t, err)
+	SetMetrics(m)
+	t.Cleanup(func() { SetMetrics(nil) })
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client, err := New(nil, Options{Integration: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, client.Timeout)
+
+	_, err = client.Get(url)
+	require.Error(t, err)
+	assert.InDelta(t, 1, requestCount(t, registry, "error"), 0)
+}
+
+// requestCount returns the number of GET requests of the test integration with a status
+func requestCount(t *testing.T, registry *prometheus.Registry, status string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "outbound_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["integration"] == "test" && labels["method"] == http.MethodGet && labels["status"] == status {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -25,12 +25,12 @@ func (s *Server) initAuthRoutes() {
 
 	// Clean OAuth routes (preferred going forward - not versioned)
 	g.GET("/auth/:provider", s.Handlers.WithErrorHandling(handleGothProvider))
-	g.GET("/auth/:provider/callback", s.Handlers.WithErrorHandling(handleGothCallback))
+	g.GET("/auth/:provider/callback", s.Handlers.WithErrorHandling(s.handleGothCallback))
 
 	// Legacy v1 API routes (kept for backward compatibility)
 	// TODO: Remove when v1 API is deprecated
 	g.GET("/api/v1/auth/:provider", s.Handlers.WithErrorHandling(handleGothProvider))
-	g.GET("/api/v1/auth/:provider/callback", s.Handlers.WithErrorHandling(handleGothCallback))
+	g.GET("/api/v1/auth/:provider/callback", s.Handlers.WithErrorHandling(s.handleGothCallback))
 
 	// Basic authentication routes
 	g.GET("/login", s.Handlers.WithErrorHandling(s.handleLoginPage))
@@ -77,7 +77,7 @@ func storeInGothicSession(c echo.Context, key, value, userEmail, providerName st
 }
 
 // handleGothCallback handles callbacks from OAuth2 providers
-func handleGothCallback(c echo.Context) error {
+func (s *Server) handleGothCallback(c echo.Context) error {
 	request := c.Request()
 	response := c.Response().Writer
 	providerName := c.Param("provider") // Get provider early
@@ -90,6 +90,16 @@ func handleGothCallback(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Authentication failed. See server logs for details.") // More generic user message
 	}
 
+	// OpenID Connect users get the role of their groups, users without one may not log in
+	var oidcRole string
+	if providerName == security.OIDCProviderName {
+		role, ok := s.OAuth2Server.OIDCRole(&user)
+		if !ok {
+			return echo.NewHTTPError(http.StatusForbidden, "Your account is not in a group allowed to use this station.")
+		}
+		oidcRole = role
+	}
+
 	// Log session regeneration attempt (Security relevant: Session Fixation Mitigation)
 	if err := gothic.Logout(c.Response().Writer, c.Request()); err != nil {
 		// Log warning but continue - Use Security Logger
@@ -158,6 +168,23 @@ func handleGothCallback(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Session error after social login (code: EMAIL)")
 	}
 
+	if oidcRole != "" {
+		if err := security.StoreOIDCLogin(c, security.OIDCUsername(&user), oidcRole); err != nil {
+			security.LogError("Rolling back session due to failure storing OpenID Connect login",
+				"provider", providerName,
+				"user_email", user.Email,
+				"error", err.Error(),
+			)
+			if err := gothic.Logout(c.Response().Writer, c.Request()); err != nil {
+				security.LogError("Failed to logout session during rollback after OpenID Connect login failure",
+					"provider", providerName,
+					"user_email", user.Email,
+					"rollback_error", err.Error())
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "Session error after social login (code: OIDC)")
+		}
+	}
+
 	// Optional: Store raw data (Consider logging this via security.LogInfo if enabled)
 	// rawDataKey := fmt.Sprintf("%s_raw", providerName)
 	// if err := gothic.StoreInSession(rawDataKey, user.RawData, request, response); err != nil {
@@ -223,6 +250,8 @@ func (s *Server) handleLoginPage(c echo.Context) error {
 			"BasicEnabled":  s.Settings.Security.BasicAuth.Enabled,
 			"GoogleEnabled": s.Settings.Security.GoogleAuth.Enabled,
 			"GithubEnabled": s.Settings.Security.GithubAuth.Enabled,
+			"OIDCEnabled":   s.Settings.Security.OIDCAuth.Enabled,
+			"OIDCName":      s.Settings.Security.OIDCAuth.Name,
 			"CSRFToken":     c.Get(CSRFContextKey),
 		})
 	}
@@ -320,6 +349,12 @@ func (s *Server) handleLogout(c echo.Context) error {
 			"provider", provider,
 			"error", err.Error())
 	}
+	if err := security.ClearOIDCLogin(c); err != nil {
+		security.LogWarn("Failed to clear OpenID Connect login during logout",
+			"user_identifier", userIdentifier,
+			"provider", provider,
+			"error", err.Error())
+	}
 
 	// Logout from gothic session
 	err := gothic.Logout(c.Response().Writer, c.Request())
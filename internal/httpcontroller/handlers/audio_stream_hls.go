@@ -31,6 +31,7 @@ type HLSStreamInfo struct {
 	OutputDir    string
 	PlaylistPath string
 	FifoPipe     string // Windows named pipe path for platform compatibility
+	Continuous   bool   // Kept running without listeners, see KeepContinuousHLSStreams
 	// Add context and cancellation for managing stream lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -413,8 +414,18 @@ func buildFFmpegArgs(inputSource, outputDir, playlistPath string) []string {
 		logLevel = liveStreamSettings.FfmpegLogLevel
 	}
 
+	// Low latency mode trades more playlist requests for a shorter delay behind the microphone
+	initTime := 3
+	if liveStreamSettings.LowLatency {
+		segmentLength = 1
+		initTime = 1
+	}
+
 	// Base arguments, starting with input format if needed
 	args := []string{}
+	if liveStreamSettings.LowLatency {
+		args = append(args, "-fflags", "nobuffer") // Do not buffer input before encoding
+	}
 
 	// Input format arguments common to both FIFO and pipe:0
 	inputFormatArgs := []string{
@@ -437,14 +448,19 @@ func buildFFmpegArgs(inputSource, outputDir, playlistPath string) []string {
 		"-hls_flags", "delete_segments+temp_file", // Delete old segments and use temp files
 		"-hls_segment_type", "fmp4", // Use fmp4 segments
 		"-hls_fmp4_init_filename", "init.mp4",
-		"-hls_init_time", "3", // Initial segment length: 3 seconds for faster startup
+		"-hls_init_time", fmt.Sprintf("%d", initTime), // Initial segment length for faster startup
 		"-hls_allow_cache", "1", // Allow caching
 		"-movflags", "faststart+empty_moov+separate_moof",
 		"-start_number", "0", // Start with segment 0
 		"-loglevel", logLevel, // Set ffmpeg logging level from config
+	}
+	if liveStreamSettings.LowLatency {
+		outputArgs = append(outputArgs, "-flush_packets", "1") // Write segments as soon as packets are encoded
+	}
+	outputArgs = append(outputArgs,
 		"-hls_segment_filename", filepath.ToSlash(filepath.Join(outputDir, "segment%03d.m4s")),
 		playlistPath, // Output playlist
-	}
+	)
 
 	args = append(args, outputArgs...)
 
@@ -1019,13 +1035,8 @@ func syncHLSClientActivity() {
 	// Check for inactive streams and store streams to clean up
 	streamsToCleanup := []string{}
 
-	// First, get a list of all current streams
-	hlsStreamMutex.Lock()
-	activeStreamIDs := make([]string, 0, len(hlsStreams))
-	for sourceID := range hlsStreams {
-		activeStreamIDs = append(activeStreamIDs, sourceID)
-	}
-	hlsStreamMutex.Unlock()
+	// First, get a list of all current streams, continuous streams never become inactive
+	activeStreamIDs := listHLSStreamIDs(false)
 
 	// Check each stream's activity - we check for each known stream
 	// rather than iterating hlsStreamActivity to catch any potential orphans
@@ -1239,8 +1250,10 @@ func (h *Handlers) StartHLSStream(c echo.Context, sourceID string) (*StreamStatu
 		return nil, echo.NewHTTPError(http.StatusNotFound, "Audio source not found")
 	}
 
-	// Ensure any existing stream is cleaned up
-	h.cleanupExistingStream(sourceID)
+	// Ensure any existing stream is cleaned up, continuous streams are joined as they are
+	if !isContinuousStream(sourceID) {
+		h.cleanupExistingStream(sourceID)
+	}
 
 	// Add client to stream tracking with a longer initial timeout
 	// to give FFmpeg time to start up and generate the playlist
@@ -1396,7 +1409,10 @@ func (h *Handlers) StopHLSClientStream(c echo.Context, sourceID string) error {
 	if lastClient {
 		hlsStreamMutex.Lock()
 		stream, exists := hlsStreams[sourceID]
-		if exists {
+		if exists && stream.Continuous {
+			// Continuous streams keep running without listeners
+			hlsStreamMutex.Unlock()
+		} else if exists {
 			log.Printf("🧹 Last client disconnected, stopping FFmpeg for source: %s", privacy.SanitizeRTSPUrl(sourceID))
 
 			// Remove from map immediately to prevent new clients
@@ -1759,13 +1775,8 @@ func isStreamActive(sourceID string) bool {
 func (h *Handlers) CleanupIdleHLSStreams() {
 	log.Printf("🧹 Running HLS stream cleanup task")
 
-	// Get a list of all current streams
-	hlsStreamMutex.Lock()
-	activeStreamIDs := make([]string, 0, len(hlsStreams))
-	for sourceID := range hlsStreams {
-		activeStreamIDs = append(activeStreamIDs, sourceID)
-	}
-	hlsStreamMutex.Unlock()
+	// Get a list of all current streams, continuous streams are not cleaned up when idle
+	activeStreamIDs := listHLSStreamIDs(false)
 
 	// Track cleanup stats
 	cleanupCount := 0
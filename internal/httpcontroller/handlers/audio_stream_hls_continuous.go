@@ -0,0 +1,107 @@
+// audio_stream_hls_continuous.go: HLS live streams kept running for every audio source
+package handlers
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// continuousStreamCheckInterval is how often continuous streams are checked and restarted
+const continuousStreamCheckInterval = 30 * time.Second
+
+// KeepContinuousHLSStreams keeps an HLS live stream running for every audio source until quit
+// is closed, so listeners can tune in without waiting for FFmpeg to start. Streams that stop,
+// for example when FFmpeg exits, and sources added later are started on the next check.
+func KeepContinuousHLSStreams(quit <-chan struct{}) {
+	ticker := time.NewTicker(continuousStreamCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		// Streams are not tied to this loop so listeners keep them when it stops
+		ensureContinuousHLSStreams(context.Background(), myaudio.GetCaptureBufferSources())
+
+		select {
+		case <-quit:
+			stopContinuousHLSStreams()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ensureContinuousHLSStreams starts a continuous stream for each source without one and marks
+// streams started by listeners as continuous
+func ensureContinuousHLSStreams(ctx context.Context, sources []string) {
+	for _, sourceID := range sources {
+		stream, err := getOrCreateHLSStream(ctx, sourceID)
+		if err != nil {
+			log.Printf("❌ Error starting continuous HLS stream for source %s: %v", privacy.SanitizeRTSPUrl(sourceID), err)
+			continue
+		}
+
+		hlsStreamMutex.Lock()
+		started := !stream.Continuous
+		stream.Continuous = true
+		hlsStreamMutex.Unlock()
+
+		if started {
+			log.Printf("📡 Continuous HLS stream running for source: %s", privacy.SanitizeRTSPUrl(sourceID))
+		}
+	}
+}
+
+// stopContinuousHLSStreams stops the continuous streams that have no listeners and hands the
+// others back to the regular inactivity cleanup
+func stopContinuousHLSStreams() {
+	for _, sourceID := range listHLSStreamIDs(true) {
+		hlsStreamClientMutex.Lock()
+		listeners := len(hlsStreamClients[sourceID])
+		hlsStreamClientMutex.Unlock()
+
+		hlsStreamMutex.Lock()
+		stream, exists := hlsStreams[sourceID]
+		if !exists || !stream.Continuous {
+			hlsStreamMutex.Unlock()
+			continue
+		}
+		stream.Continuous = false
+		if listeners > 0 {
+			hlsStreamMutex.Unlock()
+			continue
+		}
+		delete(hlsStreams, sourceID)
+		hlsStreamMutex.Unlock()
+
+		performStreamCleanup(sourceID, stream, "continuous streaming stopped")
+	}
+}
+
+// listHLSStreamIDs returns the sorted source IDs of the running streams, including continuous
+// streams only when includeContinuous is true
+func listHLSStreamIDs(includeContinuous bool) []string {
+	hlsStreamMutex.Lock()
+	sourceIDs := make([]string, 0, len(hlsStreams))
+	for sourceID, stream := range hlsStreams {
+		if stream.Continuous && !includeContinuous {
+			continue
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+	hlsStreamMutex.Unlock()
+
+	slices.Sort(sourceIDs)
+	return sourceIDs
+}
+
+// isContinuousStream reports whether the running stream of a source is a continuous stream
+func isContinuousStream(sourceID string) bool {
+	hlsStreamMutex.Lock()
+	defer hlsStreamMutex.Unlock()
+	stream, exists := hlsStreams[sourceID]
+	return exists && stream.Continuous
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setTestHLSStreams replaces the running streams for the duration of a test
+func setTestHLSStreams(t *testing.T, streams map[string]*HLSStreamInfo) {
+	t.Helper()
+	hlsStreamMutex.Lock()
+	saved := hlsStreams
+	hlsStreams = streams
+	hlsStreamMutex.Unlock()
+
+	t.Cleanup(func() {
+		hlsStreamMutex.Lock()
+		hlsStreams = saved
+		hlsStreamMutex.Unlock()
+	})
+}
+
+func TestListHLSStreamIDs(t *testing.T) {
+	setTestHLSStreams(t, map[string]*HLSStreamInfo{
+		"mic2": {SourceID: "mic2"},
+		"mic1": {SourceID: "mic1", Continuous: true},
+		"mic3": {SourceID: "mic3"},
+	})
+
+	assert.Equal(t, []string{"mic2", "mic3"}, listHLSStreamIDs(false))
+	assert.Equal(t, []string{"mic1", "mic2", "mic3"}, listHLSStreamIDs(true))
+	assert.True(t, isContinuousStream("mic1"))
+	assert.False(t, isContinuousStream("mic2"))
+	assert.False(t, isContinuousStream("missing"))
+}
+
+func TestEnsureContinuousHLSStreamsMarksRunningStreams(t *testing.T) {
+	setTestHLSStreams(t, map[string]*HLSStreamInfo{
+		"mic1": {SourceID: "mic1"},
+	})
+
+	ensureContinuousHLSStreams(context.Background(), []string{"mic1"})
+
+	assert.True(t, isContinuousStream("mic1"))
+	assert.Empty(t, listHLSStreamIDs(false), "continuous streams must be exempt from inactivity cleanup")
+}
+
+func TestStopContinuousHLSStreams(t *testing.T) {
+	setTestHLSStreams(t, map[string]*HLSStreamInfo{
+		"idle":      {SourceID: "idle", Continuous: true},
+		"listened":  {SourceID: "listened", Continuous: true},
+		"on-demand": {SourceID: "on-demand"},
+	})
+
+	hlsStreamClientMutex.Lock()
+	hlsStreamClients["listened"] = map[string]bool{"client": true}
+	hlsStreamClientMutex.Unlock()
+	t.Cleanup(func() {
+		hlsStreamClientMutex.Lock()
+		delete(hlsStreamClients, "listened")
+		hlsStreamClientMutex.Unlock()
+	})
+
+	stopContinuousHLSStreams()
+
+	// Idle continuous streams stop, listened ones return to the on-demand lifecycle
+	assert.Equal(t, []string{"listened", "on-demand"}, listHLSStreamIDs(true))
+	assert.False(t, isContinuousStream("listened"))
+}
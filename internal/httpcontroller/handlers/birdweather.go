@@ -60,6 +60,7 @@ func (h *Handlers) TestBirdWeather(c echo.Context) error {
 					Debug:            testConfig.Debug,
 				},
 			},
+			OutboundTLS: h.Settings.OutboundTLS,
 		}
 	} else {
 		// For GET requests, use the current settings
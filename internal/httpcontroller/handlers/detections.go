@@ -5,7 +5,6 @@ import (
 	"log"
 	"math"
 	"net/http"
-	"os"
 	"runtime"
 	"strconv"
 	"strings"
@@ -188,7 +187,7 @@ func (h *Handlers) Detections(c echo.Context) error {
 		ItemsPerPage:      itemsPerPage,
 		WeatherEnabled:    weatherEnabled,
 		Security: map[string]interface{}{
-			"Enabled":       h.Settings.Security.BasicAuth.Enabled || h.Settings.Security.GoogleAuth.Enabled || h.Settings.Security.GithubAuth.Enabled,
+			"Enabled":       h.Settings.Security.BasicAuth.Enabled || h.Settings.Security.GoogleAuth.Enabled || h.Settings.Security.GithubAuth.Enabled || h.Settings.Security.OIDCAuth.Enabled,
 			"AccessAllowed": h.Server.IsAccessAllowed(c),
 		},
 	}
@@ -391,7 +390,7 @@ func (h *Handlers) RecentDetections(c echo.Context) error {
 		Notes:             notes,
 		DashboardSettings: *h.DashboardSettings,
 		Security: map[string]interface{}{
-			"Enabled":       h.Settings.Security.BasicAuth.Enabled || h.Settings.Security.GoogleAuth.Enabled || h.Settings.Security.GithubAuth.Enabled,
+			"Enabled":       h.Settings.Security.BasicAuth.Enabled || h.Settings.Security.GoogleAuth.Enabled || h.Settings.Security.GithubAuth.Enabled || h.Settings.Security.OIDCAuth.Enabled,
 			"AccessAllowed": h.Server.IsAccessAllowed(c),
 		},
 	}
@@ -500,7 +499,8 @@ func (h *Handlers) addWeatherAndTimeOfDay(notes []datastore.Note) ([]NoteWithWea
 	return notesWithWeather, nil
 }
 
-// DeleteDetection handles the deletion of a detection and its associated files
+// DeleteDetection moves a detection to the trash, its clip is removed when the detection
+// is purged after the trash retention window
 // API: DELETE /api/v1/detections/delete
 func (h *Handlers) DeleteDetection(c echo.Context) error {
 	operationStart := time.Now()
@@ -526,31 +526,9 @@ func (h *Handlers) DeleteDetection(c echo.Context) error {
 		return h.NewHandlerError(enhancedErr, "Missing detection ID", http.StatusBadRequest)
 	}
 
-	// Get the clip path before deletion with telemetry
-	dbStart := time.Now()
-	clipPath, err := h.DS.GetNoteClipPath(id)
-	if h.Telemetry != nil {
-		h.Telemetry.RecordDatabaseOperation(handlerName, "get_note_clip_path", time.Since(dbStart), err)
-	}
-	if err != nil {
-		enhancedErr := errors.New(err).
-			Component("http-controller").
-			Category(errors.CategoryDatabase).
-			Context("operation", "get_note_clip_path").
-			Context("handler", handlerName).
-			Context("detection_id", id).
-			Build()
-		h.Debug("Failed to get clip path: %v", enhancedErr)
-		h.SSE.SendNotification(Notification{
-			Message: fmt.Sprintf("Failed to get clip path: %v", err),
-			Type:    "error",
-		})
-		return h.NewHandlerError(enhancedErr, "Failed to get clip path", http.StatusInternalServerError)
-	}
-
-	// Delete the note from the database with telemetry
+	// Move the note to the trash with telemetry
 	deleteStart := time.Now()
-	err = h.DS.Delete(id)
+	err := h.DS.Delete(id)
 	if h.Telemetry != nil {
 		h.Telemetry.RecordDatabaseOperation(handlerName, "delete_note", time.Since(deleteStart), err)
 	}
@@ -570,61 +548,12 @@ func (h *Handlers) DeleteDetection(c echo.Context) error {
 		return h.NewHandlerError(enhancedErr, "Failed to delete note", http.StatusInternalServerError)
 	}
 
-	// If there was a clip associated, delete the audio file and spectrogram with telemetry
-	if clipPath != "" {
-		// Delete audio file with telemetry
-		audioPath := fmt.Sprintf("%s/%s", h.Settings.Realtime.Audio.Export.Path, clipPath)
-		audioDeleteStart := time.Now()
-		audioErr := os.Remove(audioPath)
-		if h.Telemetry != nil {
-			h.Telemetry.RecordHandlerOperationDuration(handlerName, "delete_audio_file", time.Since(audioDeleteStart).Seconds())
-			if audioErr != nil && !os.IsNotExist(audioErr) {
-				h.Telemetry.RecordHandlerOperationError(handlerName, "delete_audio_file", "file_io")
-			} else {
-				h.Telemetry.RecordHandlerOperation(handlerName, "delete_audio_file", "success")
-			}
-		}
-		if audioErr != nil && !os.IsNotExist(audioErr) {
-			enhancedErr := errors.New(audioErr).
-				Component("http-controller").
-				Category(errors.CategoryFileIO).
-				Context("operation", "delete_audio_file").
-				Context("handler", handlerName).
-				Context("audio_path", audioPath).
-				Build()
-			h.Debug("Failed to delete audio file %s: %v", audioPath, enhancedErr)
-		}
-
-		// Delete spectrogram file with telemetry
-		spectrogramPath := fmt.Sprintf("%s/%s.png", h.Settings.Realtime.Audio.Export.Path, strings.TrimSuffix(clipPath, ".wav"))
-		spectrogramDeleteStart := time.Now()
-		spectrogramErr := os.Remove(spectrogramPath)
-		if h.Telemetry != nil {
-			h.Telemetry.RecordHandlerOperationDuration(handlerName, "delete_spectrogram_file", time.Since(spectrogramDeleteStart).Seconds())
-			if spectrogramErr != nil && !os.IsNotExist(spectrogramErr) {
-				h.Telemetry.RecordHandlerOperationError(handlerName, "delete_spectrogram_file", "file_io")
-			} else {
-				h.Telemetry.RecordHandlerOperation(handlerName, "delete_spectrogram_file", "success")
-			}
-		}
-		if spectrogramErr != nil && !os.IsNotExist(spectrogramErr) {
-			enhancedErr := errors.New(spectrogramErr).
-				Component("http-controller").
-				Category(errors.CategoryFileIO).
-				Context("operation", "delete_spectrogram_file").
-				Context("handler", handlerName).
-				Context("spectrogram_path", spectrogramPath).
-				Build()
-			h.Debug("Failed to delete spectrogram file %s: %v", spectrogramPath, enhancedErr)
-		}
-	}
-
 	// Log the successful deletion
-	h.Debug("Successfully deleted detection %s", id)
+	h.Debug("Successfully moved detection %s to trash", id)
 
 	// Send success notification
 	h.SSE.SendNotification(Notification{
-		Message: "Detection deleted successfully",
+		Message: "Detection moved to trash",
 		Type:    "success",
 	})
 
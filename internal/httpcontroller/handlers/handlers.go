@@ -327,7 +327,7 @@ func (h *Handlers) GetSecurity(c echo.Context) *Security {
 	}
 
 	return &Security{
-		Enabled:       h.Settings.Security.BasicAuth.Enabled || h.Settings.Security.GoogleAuth.Enabled || h.Settings.Security.GithubAuth.Enabled,
+		Enabled:       h.Settings.Security.BasicAuth.Enabled || h.Settings.Security.GoogleAuth.Enabled || h.Settings.Security.GithubAuth.Enabled || h.Settings.Security.OIDCAuth.Enabled,
 		AccessAllowed: accessAllowed,
 	}
 }
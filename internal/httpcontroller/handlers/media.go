@@ -26,6 +26,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/logging"
 	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/resources"
 )
 
 // MaxClipNameLength is the maximum allowed length for a clip name
@@ -46,8 +47,8 @@ var (
 	ErrPathTraversal     = errors.New("path traversal attempt detected")
 )
 
-// Limit concurrent spectrogram generations to avoid overloading the system
-const MaxConcurrentSpectrograms = 4
+// MaxConcurrentSpectrograms limits concurrent spectrogram generations to avoid overloading the system
+var MaxConcurrentSpectrograms = resources.Detect().FFmpegConcurrency()
 
 var spectrogramSemaphore = make(chan struct{}, MaxConcurrentSpectrograms)
 
@@ -93,7 +93,8 @@ func (h *Handlers) SaveSettings(c echo.Context) error {
 	}
 
 	// Check if species interval settings have changed
-	if speciesIntervalSettingsChanged(&oldSettings, settings) || oldSettings.Realtime.Interval != settings.Realtime.Interval {
+	if speciesIntervalSettingsChanged(&oldSettings, settings) || oldSettings.Realtime.Interval != settings.Realtime.Interval ||
+		oldSettings.Realtime.EventIntervals != settings.Realtime.EventIntervals {
 		h.SSE.SendNotification(Notification{
 			Message: "Updating detection rate limits...",
 			Type:    "info",
@@ -212,7 +213,7 @@ func (h *Handlers) updateAuthenticationSettings(settings *conf.Settings) {
 	basicAuth := &settings.Security.BasicAuth
 
 	// Check if any authentication settings are enabled
-	if !settings.Security.GoogleAuth.Enabled && !settings.Security.GithubAuth.Enabled && !basicAuth.Enabled {
+	if !settings.Security.GoogleAuth.Enabled && !settings.Security.GithubAuth.Enabled && !settings.Security.OIDCAuth.Enabled && !basicAuth.Enabled {
 		return
 	}
 
@@ -210,6 +210,7 @@ func (s *Server) initializeServer() {
 	s.configureMiddleware() // Configure other standard middleware
 	s.initRoutes()          // Initialize HTML/V1 routes
 	s.initHLSCleanupTask()  // Initialize HLS cleanup task
+	s.initContinuousHLS()   // Keep live streams running when configured
 
 	// Initialize the JSON API v2 - Pass OAuth2Server directly
 	s.Debug("Initializing JSON API v2")
@@ -260,6 +261,23 @@ func (s *Server) initHLSCleanupTask() {
 	})
 }
 
+// initContinuousHLS keeps an HLS live stream running for every audio source when continuous
+// live streaming is enabled
+func (s *Server) initContinuousHLS() {
+	if !s.Settings.WebServer.LiveStream.Continuous {
+		return
+	}
+	s.Debug("Initializing continuous HLS live streams")
+
+	quit := make(chan struct{})
+	go handlers.KeepContinuousHLSStreams(quit)
+
+	s.Echo.Server.RegisterOnShutdown(func() {
+		s.Debug("Stopping continuous HLS live streams")
+		close(quit)
+	})
+}
+
 // configureDefaultSettings sets default values for server settings.
 func configureDefaultSettings(settings *conf.Settings) {
 	if settings.WebServer.Port == "" {
@@ -0,0 +1,68 @@
+// attribution.go: records species image license/author usage in the datastore attribution ledger
+package imageprovider
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Usage contexts recorded in the attribution ledger
+const (
+	UsageWeb  = "web"  // Image served through the web interface or API
+	UsageMQTT = "mqtt" // Image URL published in an MQTT message
+	UsageSSE  = "sse"  // Image URL broadcast to SSE clients
+)
+
+// attributionRecordInterval limits how often the same image usage is written to the ledger
+const attributionRecordInterval = time.Hour
+
+// RecordUsage records the attribution details of an image that has been displayed or
+// shared. Writes for the same species, provider and usage context are throttled to
+// avoid a database write on every page view. The call is a no-op when the configured
+// datastore does not implement datastore.AttributionLedger.
+func (c *BirdImageCache) RecordUsage(image *BirdImage, usage string) {
+	if c == nil || image == nil || image.URL == "" || image.IsNegativeEntry() || image.ScientificName == "" {
+		return
+	}
+
+	ledger, ok := c.store.(datastore.AttributionLedger)
+	if !ok {
+		return
+	}
+
+	provider := image.SourceProvider
+	if provider == "" {
+		provider = c.providerName
+	}
+
+	now := time.Now()
+	key := image.ScientificName + "|" + provider + "|" + usage
+	if last, loaded := c.attributions.Load(key); loaded {
+		if t, ok := last.(time.Time); ok && now.Sub(t) < attributionRecordInterval {
+			return
+		}
+	}
+	c.attributions.Store(key, now)
+
+	entry := &datastore.ImageAttribution{
+		ScientificName: image.ScientificName,
+		SourceProvider: provider,
+		URL:            image.URL,
+		LicenseName:    image.LicenseName,
+		LicenseURL:     image.LicenseURL,
+		AuthorName:     image.AuthorName,
+		AuthorURL:      image.AuthorURL,
+		LastUsage:      usage,
+		LastUsedAt:     now,
+	}
+	if err := ledger.RecordImageAttribution(entry); err != nil {
+		// Allow a retry on the next use
+		c.attributions.Delete(key)
+		imageProviderLogger.Warn("Failed to record image attribution",
+			"scientific_name", image.ScientificName,
+			"provider", provider,
+			"usage", usage,
+			"error", err)
+	}
+}
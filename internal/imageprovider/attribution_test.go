@@ -0,0 +1,75 @@
+package imageprovider_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
+	"github.com/tphakala/birdnet-go/internal/observability"
+)
+
+// mockLedgerStore extends mockStore with the optional attribution ledger capability
+type mockLedgerStore struct {
+	*mockStore
+	mu      sync.Mutex
+	entries []datastore.ImageAttribution
+}
+
+func (m *mockLedgerStore) RecordImageAttribution(entry *datastore.ImageAttribution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockLedgerStore) GetImageAttributions(scientificName string) ([]datastore.ImageAttribution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]datastore.ImageAttribution(nil), m.entries...), nil
+}
+
+func TestRecordUsage(t *testing.T) {
+	t.Parallel()
+
+	store := &mockLedgerStore{mockStore: newMockStore()}
+	metrics, err := observability.NewMetrics()
+	require.NoError(t, err)
+	cache, err := imageprovider.CreateDefaultCache(metrics, store)
+	require.NoError(t, err)
+	cache.SetImageProvider(&mockImageProvider{})
+	defer func() { assert.NoError(t, cache.Close()) }()
+
+	image, err := cache.Get("Turdus merula")
+	require.NoError(t, err)
+
+	cache.RecordUsage(&image, imageprovider.UsageWeb)
+	cache.RecordUsage(&image, imageprovider.UsageWeb) // throttled
+	cache.RecordUsage(&image, imageprovider.UsageMQTT)
+	cache.RecordUsage(&imageprovider.BirdImage{ScientificName: "Parus major"}, imageprovider.UsageWeb) // no URL
+
+	entries, err := store.GetImageAttributions("")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Turdus merula", entries[0].ScientificName)
+	assert.Equal(t, image.AuthorName, entries[0].AuthorName)
+	assert.Equal(t, image.LicenseName, entries[0].LicenseName)
+	assert.Equal(t, imageprovider.UsageWeb, entries[0].LastUsage)
+	assert.Equal(t, imageprovider.UsageMQTT, entries[1].LastUsage)
+	assert.NotEmpty(t, entries[0].SourceProvider)
+}
+
+func TestRecordUsageWithoutLedger(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := observability.NewMetrics()
+	require.NoError(t, err)
+	cache, err := imageprovider.CreateDefaultCache(metrics, newMockStore())
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, cache.Close()) }()
+
+	// Must not panic when the store has no ledger support
+	cache.RecordUsage(&imageprovider.BirdImage{URL: "http://example.com/a.jpg", ScientificName: "Turdus merula"}, imageprovider.UsageWeb)
+}
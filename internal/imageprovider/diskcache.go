@@ -0,0 +1,202 @@
+// diskcache.go: Keeps copies of species images on disk so they survive restarts.
+package imageprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
+)
+
+const (
+	maxDiskCacheImageSize    = 10 << 20 // Largest image stored in the disk cache
+	diskCacheDownloadTimeout = 30 * time.Second
+)
+
+// diskCacheExtensions maps the accepted image content types to file extensions
+var diskCacheExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// DiskCache stores copies of provider images in a directory, one subdirectory per provider.
+// An image is downloaded on first use and served from disk afterwards, so thumbnails keep
+// working across restarts and while the provider is unreachable.
+type DiskCache struct {
+	dir    string
+	client *http.Client
+	mu     sync.Mutex // Serializes downloads so an image is fetched once
+}
+
+// NewDiskCache creates a disk cache in dir, creating the directory when needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryFileIO).
+			Context("path", dir).
+			Context("operation", "create_image_disk_cache").
+			Build()
+	}
+
+	client, err := httpclient.New(conf.Setting(), httpclient.Options{
+		Integration: "imageprovider",
+		Timeout:     diskCacheDownloadTimeout,
+	})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_image_disk_cache_transport").
+			Build()
+	}
+
+	return &DiskCache{
+		dir:    dir,
+		client: client,
+	}, nil
+}
+
+// diskCacheName turns a provider or species name into a safe file name
+func diskCacheName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(name))
+}
+
+// Cacheable reports whether an image is downloaded to the disk cache. Images served by
+// BirdNET-Go itself, such as local photos, are not.
+func (d *DiskCache) Cacheable(img *BirdImage) bool {
+	return !img.IsNegativeEntry() &&
+		(strings.HasPrefix(img.URL, "https://") || strings.HasPrefix(img.URL, "http://"))
+}
+
+// basePath returns the path of an image without extension. It includes a hash of the URL,
+// so a provider switching to another image results in a new download.
+func (d *DiskCache) basePath(img *BirdImage) string {
+	sum := sha256.Sum256([]byte(img.URL))
+	return filepath.Join(d.dir, diskCacheName(img.SourceProvider),
+		diskCacheName(img.ScientificName)+"-"+hex.EncodeToString(sum[:8]))
+}
+
+// lookup returns the path of a stored copy of an image
+func (d *DiskCache) lookup(img *BirdImage) (string, bool) {
+	matches, err := filepath.Glob(d.basePath(img) + ".*")
+	if err != nil || len(matches) == 0 {
+		return "", false
+	}
+	return matches[0], true
+}
+
+// File returns the path of the stored copy of an image, downloading the image when needed.
+func (d *DiskCache) File(ctx context.Context, img *BirdImage) (string, error) {
+	if !d.Cacheable(img) {
+		return "", ErrImageNotFound
+	}
+	if path, ok := d.lookup(img); ok {
+		return path, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if path, ok := d.lookup(img); ok {
+		return path, nil
+	}
+
+	path, err := d.download(ctx, img)
+	if err != nil {
+		return "", errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryImageCache).
+			Context("provider", img.SourceProvider).
+			Context("scientific_name", img.ScientificName).
+			Context("operation", "image_disk_cache_download").
+			Build()
+	}
+	return path, nil
+}
+
+// download stores an image in the cache directory and returns its path
+func (d *DiskCache) download(ctx context.Context, img *BirdImage) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, img.URL, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "BirdNET-Go")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Newf("image download returned status %d", resp.StatusCode).Build()
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	ext, ok := diskCacheExtensions[mediaType]
+	if !ok {
+		return "", errors.Newf("unsupported image content type %q", mediaType).Build()
+	}
+
+	base := d.basePath(img)
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(base), ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	written, err := io.Copy(tmp, io.LimitReader(resp.Body, maxDiskCacheImageSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if written > maxDiskCacheImageSize {
+		return "", errors.Newf("image exceeds %d bytes", maxDiskCacheImageSize).Build()
+	}
+
+	if err := os.Rename(tmp.Name(), base+ext); err != nil {
+		return "", err
+	}
+	return base + ext, nil
+}
+
+// Remove deletes the stored copies of a species image from one provider.
+func (d *DiskCache) Remove(providerName, scientificName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pattern := filepath.Join(d.dir, diskCacheName(providerName), diskCacheName(scientificName)+"-*")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package imageprovider_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
+)
+
+func TestDiskCacheFile(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		switch r.URL.Path {
+		case "/blackbird.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("jpeg data"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	diskCache, err := imageprovider.NewDiskCache(dir)
+	require.NoError(t, err)
+
+	img := &imageprovider.BirdImage{URL: server.URL + "/blackbird.jpg", ScientificName: "Turdus merula", SourceProvider: "wikimedia"}
+	path, err := diskCache.File(t.Context(), img)
+	require.NoError(t, err)
+	assert.Equal(t, ".jpg", filepath.Ext(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg data", string(data))
+
+	// The stored copy is served without downloading again, also by a new cache on the same folder
+	reopened, err := imageprovider.NewDiskCache(dir)
+	require.NoError(t, err)
+	again, err := reopened.File(t.Context(), img)
+	require.NoError(t, err)
+	assert.Equal(t, path, again)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	// Responses that are not images are not stored
+	_, err = diskCache.File(t.Context(), &imageprovider.BirdImage{URL: server.URL + "/page.html", ScientificName: "Parus major", SourceProvider: "wikimedia"})
+	assert.Error(t, err)
+	_, err = diskCache.File(t.Context(), &imageprovider.BirdImage{URL: server.URL + "/missing.jpg", ScientificName: "Parus major", SourceProvider: "wikimedia"})
+	assert.Error(t, err)
+
+	// Images served by BirdNET-Go itself are not cached
+	assert.False(t, diskCache.Cacheable(&imageprovider.BirdImage{URL: imageprovider.LocalImageRoute + "Turdus_merula.jpg"}))
+
+	require.NoError(t, diskCache.Remove("wikimedia", "Turdus merula"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestBirdImageCachePurge(t *testing.T) {
+	store := newMockStore()
+	provider := &mockImageProvider{}
+	cache := imageprovider.InitCache("test", provider, nil, store)
+	defer func() { assert.NoError(t, cache.Close()) }()
+
+	_, err := cache.Get("Turdus merula")
+	require.NoError(t, err)
+	_, err = cache.Get("Turdus merula")
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.fetchCounter, "second request is served from the cache")
+
+	require.NoError(t, cache.Purge("Turdus merula"))
+	_, err = store.GetImageCache(datastore.ImageCacheQuery{ScientificName: "Turdus merula", ProviderName: "test"})
+	require.Error(t, err, "database entry is removed")
+
+	_, err = cache.Get("Turdus merula")
+	require.NoError(t, err)
+	assert.Equal(t, 2, provider.fetchCounter, "purged image is fetched again")
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -110,6 +111,8 @@ type BirdImageCache struct {
 	quit         chan struct{}                         // Channel to signal shutdown
 	Initializing sync.Map                              // Track which species are being initialized
 	registry     atomic.Pointer[ImageProviderRegistry] // Use atomic pointer
+	attributions sync.Map                              // Last attribution ledger write per species/provider/usage
+	diskCache    atomic.Pointer[DiskCache]             // Optional on-disk copies of the images
 }
 
 // Package-level logger for image provider related events
@@ -1006,7 +1009,8 @@ func (c *BirdImageCache) tryFallbackProviders(scientificName string, triedProvid
 		localTriedProviders[provider] = true
 	}
 
-	registry.RangeProviders(func(name string, cache *BirdImageCache) bool {
+	priority := conf.Setting().Realtime.Dashboard.Thumbnails.ProviderPriority
+	registry.RangeProvidersByPriority(priority, func(name string, cache *BirdImageCache) bool {
 		if localTriedProviders[name] {
 			logger.Debug("Skipping already tried provider", "provider", name)
 			return true // Continue ranging
@@ -1145,6 +1149,7 @@ func CreateDefaultCache(metricsCollector *observability.Metrics, store datastore
 // ImageProviderRegistry holds multiple named ImageProvider caches.
 type ImageProviderRegistry struct {
 	caches map[string]*BirdImageCache
+	order  []string // Provider names in registration order
 	mu     sync.RWMutex
 }
 
@@ -1189,6 +1194,7 @@ func (r *ImageProviderRegistry) Register(name string, cache *BirdImageCache) err
 		return enhancedErr
 	}
 	r.caches[name] = cache
+	r.order = append(r.order, name)
 	return nil
 }
 
@@ -1265,19 +1271,85 @@ func (c *BirdImageCache) GetRegistry() *ImageProviderRegistry {
 	return c.registry.Load() // Use atomic Load
 }
 
-// RangeProviders iterates over all registered caches, applying the callback function.
-// It creates a snapshot of the cache map to avoid concurrent modification issues
-// during iteration.
+// SetDiskCache sets the disk cache keeping copies of the images of this cache
+func (c *BirdImageCache) SetDiskCache(diskCache *DiskCache) {
+	c.diskCache.Store(diskCache)
+}
+
+// GetDiskCache returns the disk cache of this cache, or nil when images are not kept on disk
+func (c *BirdImageCache) GetDiskCache() *DiskCache {
+	return c.diskCache.Load()
+}
+
+// Purge removes the cached image of a species from memory, the database and the disk cache,
+// so the next request fetches it from the provider again.
+func (c *BirdImageCache) Purge(scientificName string) error {
+	imageProviderLogger.Info("Purging cached image", "provider", c.providerName, "scientific_name", scientificName)
+	c.dataMap.Delete(scientificName)
+
+	var errs []error
+	if remover, ok := c.store.(datastore.ImageCacheRemover); ok {
+		if err := remover.DeleteImageCache(c.providerName, scientificName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if diskCache := c.diskCache.Load(); diskCache != nil {
+		if err := diskCache.Remove(c.providerName, scientificName); err != nil {
+			errs = append(errs, errors.New(err).
+				Component("imageprovider").
+				Category(errors.CategoryFileIO).
+				Context("provider", c.providerName).
+				Context("scientific_name", scientificName).
+				Context("operation", "purge_image_disk_cache").
+				Build())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Purge removes the cached image of a species from all registered caches.
+func (r *ImageProviderRegistry) Purge(scientificName string) error {
+	var errs []error
+	r.RangeProviders(func(name string, cache *BirdImageCache) bool {
+		if err := cache.Purge(scientificName); err != nil {
+			errs = append(errs, err)
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// RangeProviders iterates over all registered caches in registration order, applying the
+// callback function. It creates a snapshot of the registered caches to avoid concurrent
+// modification issues during iteration.
 func (r *ImageProviderRegistry) RangeProviders(cb func(name string, cache *BirdImageCache) bool) {
+	r.RangeProvidersByPriority(nil, cb)
+}
+
+// RangeProvidersByPriority iterates over the registered caches named in priority first, in
+// that order, followed by the other caches in registration order. Unregistered names in
+// priority are skipped.
+func (r *ImageProviderRegistry) RangeProvidersByPriority(priority []string, cb func(name string, cache *BirdImageCache) bool) {
 	r.mu.RLock()
-	snapshot := make(map[string]*BirdImageCache, len(r.caches))
-	for k, v := range r.caches {
-		snapshot[k] = v
+	names := make([]string, 0, len(r.order))
+	for _, name := range priority {
+		if _, ok := r.caches[name]; ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range r.order {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	snapshot := make([]*BirdImageCache, len(names))
+	for i, name := range names {
+		snapshot[i] = r.caches[name]
 	}
 	r.mu.RUnlock()
 
-	for name, cache := range snapshot {
-		if !cb(name, cache) {
+	for i, cache := range snapshot {
+		if !cb(names[i], cache) {
 			return // Callback requested stop
 		}
 	}
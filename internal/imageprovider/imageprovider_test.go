@@ -124,6 +124,13 @@ func (m *mockStore) SaveImageCache(cache *datastore.ImageCache) error {
 	return nil
 }
 
+func (m *mockStore) DeleteImageCache(providerName, scientificName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.images, scientificName+"_"+providerName)
+	return nil
+}
+
 func (m *mockStore) GetAllImageCaches(providerName string) ([]datastore.ImageCache, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -0,0 +1,125 @@
+// local.go: Implements an ImageProvider serving custom species photos from a local folder.
+package imageprovider
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/observability"
+)
+
+const (
+	localProviderName = "local"
+	// LocalImageRoute is the API route serving the photos of the local provider
+	LocalImageRoute = "/api/v2/media/species-image/local/"
+)
+
+// localImageExtensions lists the file extensions of photos picked up from the local folder
+var localImageExtensions = []string{".jpg", ".jpeg", ".png", ".webp"}
+
+// LocalProvider serves custom photos from a folder. Photos are named by the scientific name
+// of the species, e.g. "Turdus_merula.jpg" or "turdus merula.png". The folder is read on
+// each fetch, so photos added later are picked up when the cached entry is refreshed.
+type LocalProvider struct {
+	dir string
+}
+
+// NewLocalProvider creates a provider for the photos in dir.
+func NewLocalProvider(dir string) (*LocalProvider, error) {
+	info, err := os.Stat(dir)
+	if err == nil && !info.IsDir() {
+		err = errors.NewStd("not a directory")
+	}
+	if err != nil {
+		return nil, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryConfiguration).
+			Context("provider", localProviderName).
+			Context("path", dir).
+			Context("operation", "open_local_image_folder").
+			Build()
+	}
+	return &LocalProvider{dir: dir}, nil
+}
+
+// localImageKey normalizes a scientific name or file name for matching
+func localImageKey(name string) string {
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// Fetch returns the photo of the given species in the local folder.
+func (p *LocalProvider) Fetch(scientificName string) (BirdImage, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return BirdImage{}, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryFileIO).
+			Context("provider", localProviderName).
+			Context("scientific_name", scientificName).
+			Context("operation", "read_local_image_folder").
+			Build()
+	}
+
+	key := localImageKey(scientificName)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if !slices.Contains(localImageExtensions, strings.ToLower(ext)) {
+			continue
+		}
+		if localImageKey(strings.TrimSuffix(name, ext)) != key {
+			continue
+		}
+		return BirdImage{
+			URL:            LocalImageRoute + url.PathEscape(name),
+			ScientificName: scientificName,
+			AuthorName:     "Local photo",
+			SourceProvider: localProviderName,
+		}, nil
+	}
+
+	return BirdImage{}, ErrImageNotFound
+}
+
+// LocalImagePath returns the path of a photo in the local folder. Names with path separators
+// or unsupported extensions are rejected so that nothing outside the folder is served.
+func LocalImagePath(dir, name string) (string, error) {
+	if dir == "" || name == "" || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") ||
+		!slices.Contains(localImageExtensions, strings.ToLower(filepath.Ext(name))) {
+		return "", ErrImageNotFound
+	}
+	path := filepath.Join(dir, name)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", ErrImageNotFound
+	}
+	return path, nil
+}
+
+// RegisterLocalProvider creates and registers a provider for the photos in dir with the registry.
+func RegisterLocalProvider(registry *ImageProviderRegistry, dir string, metrics *observability.Metrics, store datastore.Interface) error {
+	logger := imageProviderLogger.With("provider", localProviderName)
+	provider, err := NewLocalProvider(dir)
+	if err != nil {
+		logger.Error("Failed to create local image provider", "path", dir, "error", err)
+		return err
+	}
+
+	cache := InitCache(localProviderName, provider, metrics, store)
+	if err := registry.Register(localProviderName, cache); err != nil {
+		_ = cache.Close()
+		logger.Error("Failed to register local image provider", "error", err)
+		return err
+	}
+
+	logger.Info("Successfully registered local image provider", "path", dir)
+	return nil
+}
@@ -0,0 +1,62 @@
+package imageprovider_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
+)
+
+func TestLocalProviderFetch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"Turdus_merula.JPG", "parus major.png", "Passer domesticus.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("photo"), 0o600))
+	}
+
+	provider, err := imageprovider.NewLocalProvider(dir)
+	require.NoError(t, err)
+
+	img, err := provider.Fetch("Turdus merula")
+	require.NoError(t, err)
+	assert.Equal(t, imageprovider.LocalImageRoute+"Turdus_merula.JPG", img.URL)
+	assert.Equal(t, "local", img.SourceProvider)
+
+	img, err = provider.Fetch("Parus major")
+	require.NoError(t, err)
+	assert.Equal(t, imageprovider.LocalImageRoute+"parus%20major.png", img.URL)
+
+	_, err = provider.Fetch("Passer domesticus")
+	require.ErrorIs(t, err, imageprovider.ErrImageNotFound, "unsupported extensions are ignored")
+}
+
+func TestNewLocalProviderMissingFolder(t *testing.T) {
+	t.Parallel()
+
+	_, err := imageprovider.NewLocalProvider(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestLocalImagePath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Turdus_merula.jpg"), []byte("photo"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("text"), 0o600))
+
+	path, err := imageprovider.LocalImagePath(dir, "Turdus_merula.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "Turdus_merula.jpg"), path)
+
+	for _, name := range []string{"", "notes.txt", "../Turdus_merula.jpg", "sub/Turdus_merula.jpg", "missing.jpg"} {
+		_, err := imageprovider.LocalImagePath(dir, name)
+		assert.Error(t, err, "name %q must be rejected", name)
+	}
+
+	_, err = imageprovider.LocalImagePath("", "Turdus_merula.jpg")
+	assert.Error(t, err, "no folder configured")
+}
@@ -0,0 +1,180 @@
+// macaulay.go: Implements an ImageProvider using the Macaulay Library of the Cornell Lab of Ornithology.
+package imageprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
+	"github.com/tphakala/birdnet-go/internal/observability"
+)
+
+const (
+	macaulayProviderName = "macaulay"
+	macaulaySearchURL    = "https://search.macaulaylibrary.org/api/v1/search"
+	macaulayAssetURL     = "https://cdn.download.ams.birds.cornell.edu/api/v1/asset/%s/480"
+	macaulayAssetPageURL = "https://macaulaylibrary.org/asset/%s"
+	macaulayTimeout      = 15 * time.Second
+)
+
+// macaulaySearchResponse is the part of the Macaulay Library search response used for images
+type macaulaySearchResponse struct {
+	Results struct {
+		Content []struct {
+			AssetID         json.Number `json:"assetId"`
+			UserDisplayName string      `json:"userDisplayName"`
+		} `json:"content"`
+	} `json:"results"`
+}
+
+// MacaulayProvider fetches the top rated photo of a species from the Macaulay Library.
+// Species are looked up by their eBird species code.
+type MacaulayProvider struct {
+	client      *http.Client
+	searchURL   string
+	speciesCode func(scientificName string) (string, bool)
+}
+
+// NewMacaulayProvider creates a Macaulay Library provider using the embedded eBird taxonomy
+// for species codes.
+func NewMacaulayProvider() (*MacaulayProvider, error) {
+	client, err := httpclient.New(conf.Setting(), httpclient.Options{
+		Integration: "imageprovider",
+		Timeout:     macaulayTimeout,
+	})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryConfiguration).
+			Context("provider", macaulayProviderName).
+			Context("operation", "create_macaulay_transport").
+			Build()
+	}
+
+	return &MacaulayProvider{
+		client:      client,
+		searchURL:   macaulaySearchURL,
+		speciesCode: taxonomySpeciesCode(),
+	}, nil
+}
+
+// taxonomySpeciesCode returns a lookup of eBird species codes, loading the taxonomy on first use
+func taxonomySpeciesCode() func(scientificName string) (string, bool) {
+	var (
+		once          sync.Once
+		taxonomyMap   birdnet.TaxonomyMap
+		scientificIdx birdnet.ScientificNameIndex
+	)
+	return func(scientificName string) (string, bool) {
+		once.Do(func() {
+			var err error
+			taxonomyMap, scientificIdx, err = birdnet.LoadTaxonomyData("")
+			if err != nil {
+				imageProviderLogger.Error("Failed to load eBird taxonomy for Macaulay Library lookups", "error", err)
+			}
+		})
+		if scientificIdx == nil {
+			return "", false
+		}
+		return birdnet.GetSpeciesCodeFromName(taxonomyMap, scientificIdx, scientificName)
+	}
+}
+
+// Fetch retrieves the top rated photo of the given species.
+func (p *MacaulayProvider) Fetch(scientificName string) (BirdImage, error) {
+	logger := imageProviderLogger.With("provider", macaulayProviderName, "scientific_name", scientificName)
+
+	code, found := p.speciesCode(scientificName)
+	if !found {
+		logger.Debug("No eBird species code for species")
+		return BirdImage{}, ErrImageNotFound
+	}
+
+	query := url.Values{}
+	query.Set("taxonCode", code)
+	query.Set("mediaType", "photo")
+	query.Set("sort", "rating_rank_desc")
+	query.Set("count", "1")
+
+	resp, err := p.client.Get(p.searchURL + "?" + query.Encode())
+	if err != nil {
+		return BirdImage{}, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryNetwork).
+			Context("provider", macaulayProviderName).
+			Context("scientific_name", scientificName).
+			Context("operation", "macaulay_search").
+			Build()
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return BirdImage{}, errors.Newf("macaulay library search returned status %d", resp.StatusCode).
+			Component("imageprovider").
+			Category(errors.CategoryImageFetch).
+			Context("provider", macaulayProviderName).
+			Context("scientific_name", scientificName).
+			Context("status_code", resp.StatusCode).
+			Context("operation", "macaulay_search").
+			Build()
+	}
+
+	var result macaulaySearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return BirdImage{}, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryFileParsing).
+			Context("provider", macaulayProviderName).
+			Context("scientific_name", scientificName).
+			Context("operation", "decode_macaulay_search").
+			Build()
+	}
+
+	for _, asset := range result.Results.Content {
+		if asset.AssetID == "" {
+			continue
+		}
+		id := asset.AssetID.String()
+		logger.Debug("Image found in Macaulay Library", "asset_id", id)
+		return BirdImage{
+			URL:            fmt.Sprintf(macaulayAssetURL, url.PathEscape(id)),
+			ScientificName: scientificName,
+			LicenseName:    "© Macaulay Library",
+			LicenseURL:     fmt.Sprintf(macaulayAssetPageURL, url.PathEscape(id)),
+			AuthorName:     asset.UserDisplayName,
+			AuthorURL:      fmt.Sprintf(macaulayAssetPageURL, url.PathEscape(id)),
+			SourceProvider: macaulayProviderName,
+		}, nil
+	}
+
+	logger.Debug("No photos found in Macaulay Library", "species_code", code)
+	return BirdImage{}, ErrImageNotFound
+}
+
+// RegisterMacaulayProvider creates and registers a Macaulay Library provider with the registry.
+func RegisterMacaulayProvider(registry *ImageProviderRegistry, metrics *observability.Metrics, store datastore.Interface) error {
+	logger := imageProviderLogger.With("provider", macaulayProviderName)
+	provider, err := NewMacaulayProvider()
+	if err != nil {
+		logger.Error("Failed to create Macaulay Library provider", "error", err)
+		return err
+	}
+
+	cache := InitCache(macaulayProviderName, provider, metrics, store)
+	if err := registry.Register(macaulayProviderName, cache); err != nil {
+		_ = cache.Close()
+		logger.Error("Failed to register Macaulay Library provider", "error", err)
+		return err
+	}
+
+	logger.Info("Successfully registered Macaulay Library provider")
+	return nil
+}
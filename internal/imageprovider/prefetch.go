@@ -0,0 +1,174 @@
+// prefetch.go: background fetching of images for species added to the range filter
+package imageprovider
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefetcher fetches the images of newly included species in the background, one species at
+// a time, so the first detection of a species finds its image already cached.
+type Prefetcher struct {
+	cache     *BirdImageCache
+	bandwidth int64         // Maximum download rate of disk cache downloads in bytes per second, 0 for no limit
+	delay     time.Duration // Pause after each species fetched from a provider
+
+	mu      sync.Mutex
+	seen    map[string]struct{} // Scientific names already scheduled
+	queue   []string            // Scientific names waiting to be fetched
+	pending chan struct{}       // Signals that the queue has entries
+}
+
+// NewPrefetcher creates a prefetcher for cache. bandwidthKiB limits the download rate of images
+// stored in the disk cache in KiB/s, 0 for no limit. delay is the pause after each species that
+// had to be fetched from a provider, keeping the prefetch a low priority background job.
+func NewPrefetcher(cache *BirdImageCache, bandwidthKiB int, delay time.Duration) *Prefetcher {
+	return &Prefetcher{
+		cache:     cache,
+		bandwidth: int64(bandwidthKiB) * 1024,
+		delay:     delay,
+		seen:      make(map[string]struct{}),
+		pending:   make(chan struct{}, 1),
+	}
+}
+
+// Schedule queues the species of an included species list that were not scheduled before.
+// Entries are BirdNET labels ("Scientific name_Common name") or scientific names. It does not
+// block and is safe to use as a range filter update listener.
+func (p *Prefetcher) Schedule(species []string) {
+	p.mu.Lock()
+	added := 0
+	for _, label := range species {
+		scientificName, _, _ := strings.Cut(label, "_")
+		scientificName = strings.TrimSpace(scientificName)
+		if scientificName == "" {
+			continue
+		}
+		if _, ok := p.seen[scientificName]; ok {
+			continue
+		}
+		p.seen[scientificName] = struct{}{}
+		p.queue = append(p.queue, scientificName)
+		added++
+	}
+	p.mu.Unlock()
+
+	if added == 0 {
+		return
+	}
+	imageProviderLogger.Info("Scheduled species image prefetch", "provider", p.cache.providerName, "species_count", added)
+	select {
+	case p.pending <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the number of species waiting to be fetched
+func (p *Prefetcher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// Run fetches scheduled species until quit is closed
+func (p *Prefetcher) Run(quit <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-quit
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.pending:
+		}
+
+		for {
+			scientificName, ok := p.next()
+			if !ok {
+				break
+			}
+			wait := p.prefetch(ctx, scientificName)
+			if wait <= 0 {
+				continue
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// next pops the next scheduled species
+func (p *Prefetcher) next() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.queue) == 0 {
+		return "", false
+	}
+	scientificName := p.queue[0]
+	p.queue = p.queue[1:]
+	return scientificName, true
+}
+
+// prefetch caches the image of one species and returns how long to pause before the next one.
+// Species already cached in memory cost nothing and are not paced.
+func (p *Prefetcher) prefetch(ctx context.Context, scientificName string) time.Duration {
+	logger := imageProviderLogger.With("provider", p.cache.providerName, "scientific_name", scientificName)
+	wait := time.Duration(0)
+
+	var img BirdImage
+	if cached, ok := p.cache.dataMap.Load(scientificName); ok {
+		img = *cached.(*BirdImage)
+	} else {
+		fetched, err := p.cache.Get(scientificName)
+		if err != nil {
+			logger.Debug("Species image prefetch found no image", "error", err)
+			return p.delay
+		}
+		img = fetched
+		wait = p.delay
+	}
+
+	diskCache := p.cache.GetDiskCache()
+	if diskCache == nil || !diskCache.Cacheable(&img) {
+		return wait
+	}
+	if _, ok := diskCache.lookup(&img); ok {
+		return wait
+	}
+
+	start := time.Now()
+	path, err := diskCache.File(ctx, &img)
+	if err != nil {
+		logger.Debug("Species image prefetch download failed", "error", err)
+		return max(wait, p.delay)
+	}
+	logger.Debug("Prefetched species image", "path", path)
+
+	// Keep the average download rate below the bandwidth limit
+	if info, err := os.Stat(path); err == nil {
+		wait = max(wait, p.bandwidthWait(info.Size(), time.Since(start)))
+	}
+	return max(wait, p.delay)
+}
+
+// bandwidthWait returns how long to pause after downloading size bytes in elapsed time to stay
+// below the bandwidth limit
+func (p *Prefetcher) bandwidthWait(size int64, elapsed time.Duration) time.Duration {
+	if p.bandwidth <= 0 || size <= 0 {
+		return 0
+	}
+	required := time.Duration(float64(size) / float64(p.bandwidth) * float64(time.Second))
+	return max(0, required-elapsed)
+}
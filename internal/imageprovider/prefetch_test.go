@@ -0,0 +1,97 @@
+package imageprovider_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/imageprovider"
+)
+
+// imageURLProvider returns images hosted by a test server
+type imageURLProvider struct {
+	baseURL string
+}
+
+func (p *imageURLProvider) Fetch(scientificName string) (imageprovider.BirdImage, error) {
+	return imageprovider.BirdImage{URL: p.baseURL + "/image.jpg", ScientificName: scientificName, SourceProvider: "test"}, nil
+}
+
+func TestPrefetcherSchedule(t *testing.T) {
+	store := newMockStore()
+	provider := &mockImageProvider{}
+	cache := imageprovider.InitCache("test", provider, nil, store)
+	defer func() { assert.NoError(t, cache.Close()) }()
+
+	prefetcher := imageprovider.NewPrefetcher(cache, 0, time.Millisecond)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		prefetcher.Run(quit)
+		close(done)
+	}()
+	defer func() {
+		close(quit)
+		<-done
+	}()
+
+	fetchCount := func() int {
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
+		return provider.fetchCounter
+	}
+
+	prefetcher.Schedule([]string{"Turdus merula_Eurasian Blackbird", "Parus major_Great Tit"})
+	require.Eventually(t, func() bool { return fetchCount() == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	// Species scheduled before are not fetched again
+	prefetcher.Schedule([]string{"Turdus merula_Eurasian Blackbird", "Parus major_Great Tit", "Erithacus rubecula_European Robin"})
+	require.Eventually(t, func() bool { return fetchCount() == 3 && prefetcher.Pending() == 0 }, 5*time.Second, 5*time.Millisecond)
+
+	// Prefetched images are served from the cache
+	_, err := cache.Get("Erithacus rubecula")
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetchCount())
+}
+
+func TestPrefetcherDiskCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(make([]byte, 2048))
+	}))
+	defer server.Close()
+
+	cache := imageprovider.InitCache("test", &imageURLProvider{baseURL: server.URL}, nil, newMockStore())
+	defer func() { assert.NoError(t, cache.Close()) }()
+	dir := t.TempDir()
+	diskCache, err := imageprovider.NewDiskCache(dir)
+	require.NoError(t, err)
+	cache.SetDiskCache(diskCache)
+
+	// 2 KiB at 1 KiB/s paces the next species by about two seconds
+	prefetcher := imageprovider.NewPrefetcher(cache, 1, 0)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		prefetcher.Run(quit)
+		close(done)
+	}()
+
+	prefetcher.Schedule([]string{"Turdus merula_Eurasian Blackbird", "Parus major_Great Tit"})
+	require.Eventually(t, func() bool { return prefetcher.Pending() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(filepath.Join(dir, "test", "turdus_merula-*.jpg"))
+		return len(matches) == 1
+	}, 5*time.Second, 5*time.Millisecond, "image is stored in the disk cache")
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 1, prefetcher.Pending(), "bandwidth limit delays the next species")
+
+	close(quit)
+	<-done
+}
@@ -255,4 +255,26 @@ func (m *mockStoreWithTracking) WasProviderQueried(providerName string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.queriedProviders[providerName]
-}
\ No newline at end of file
+}
+// TestRangeProvidersByPriority verifies that prioritized providers are visited first,
+// followed by the remaining providers in registration order
+func TestRangeProvidersByPriority(t *testing.T) {
+	t.Parallel()
+
+	registry := imageprovider.NewImageProviderRegistry()
+	for _, name := range []string{"wikimedia", "avicommons", "macaulay", "local"} {
+		require.NoError(t, registry.Register(name, &imageprovider.BirdImageCache{}))
+	}
+
+	visit := func(priority []string) []string {
+		var names []string
+		registry.RangeProvidersByPriority(priority, func(name string, cache *imageprovider.BirdImageCache) bool {
+			names = append(names, name)
+			return true
+		})
+		return names
+	}
+
+	assert.Equal(t, []string{"wikimedia", "avicommons", "macaulay", "local"}, visit(nil))
+	assert.Equal(t, []string{"local", "macaulay", "wikimedia", "avicommons"}, visit([]string{"local", "unknown", "macaulay", "local"}))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/k3a/html2text"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
 	"golang.org/x/net/html"
 	"golang.org/x/time/rate"
 )
@@ -608,7 +609,7 @@ func (l *wikiMediaProvider) makeRateLimitedRequest(ctx context.Context, requestU
 	}
 	req.Header.Set("User-Agent", l.userAgent)
 	
-	httpClient := &http.Client{Timeout: diagnosticRequestTimeout}
+	httpClient := &http.Client{Timeout: diagnosticRequestTimeout, Transport: l.httpClient.Transport}
 	return httpClient.Do(req)
 }
 
@@ -816,15 +817,22 @@ func NewWikiMediaProvider() (*wikiMediaProvider, error) {
 		"user_agent", userAgent,
 		"app_version", settings.Version)
 
-	// Create HTTP client with reasonable timeouts
-	httpClient := &http.Client{
-		Timeout: httpClientTimeout,
-		Transport: &http.Transport{
-			MaxIdleConns:        httpClientMaxIdleConns,
-			IdleConnTimeout:     httpClientIdleConnTimeout,
-			DisableCompression:  false, // Allow gzip compression
-			TLSHandshakeTimeout: httpClientTLSTimeout,
-		},
+	// Create HTTP client with reasonable timeouts, using the outbound proxy and TLS settings
+	httpClient, err := httpclient.New(settings, httpclient.Options{
+		Integration:         "imageprovider",
+		Timeout:             httpClientTimeout,
+		UserAgent:           userAgent,
+		MaxIdleConns:        httpClientMaxIdleConns,
+		IdleConnTimeout:     httpClientIdleConnTimeout,
+		TLSHandshakeTimeout: httpClientTLSTimeout,
+	})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("imageprovider").
+			Category(errors.CategoryConfiguration).
+			Context("provider", wikiProviderName).
+			Context("operation", "configure_http_transport").
+			Build()
 	}
 
 	// Global rate limiting for ALL Wikipedia requests to respect their API limits
@@ -0,0 +1,121 @@
+// analyzer.go: import of detections from BirdNET-Analyzer CSV results
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// recordingTimePattern matches the recording start in file names such as
+// 20250510_061500.WAV written by AudioMoth and most field recorders
+var recordingTimePattern = regexp.MustCompile(`(\d{8})[_T-]?(\d{6})`)
+
+// ImportAnalyzerCSV imports a BirdNET-Analyzer CSV result file (--rtype csv). Detection
+// times are offsets into the recording, so the recording start is taken from start when set,
+// or else parsed from the File column or the name of the result file.
+func ImportAnalyzerCSV(path string, start time.Time, store Store, opts *Options) (Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Summary{}, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryFileIO).
+			Context("path", path).
+			Build()
+	}
+	defer func() { _ = f.Close() }()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return Summary{}, analyzerFormatError(path, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"start (s)", "scientific name", "common name", "confidence"} {
+		if _, ok := columns[required]; !ok {
+			return Summary{}, analyzerFormatError(path, fmt.Errorf("missing column %q", required))
+		}
+	}
+	fileColumn, hasFileColumn := columns["file"]
+
+	im := newImporter(store, opts)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return im.summary, analyzerFormatError(path, err)
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		d := &detection{
+			scientificName: field("scientific name"),
+			commonName:     field("common name"),
+			confidence:     -1,
+		}
+		if confidence, err := strconv.ParseFloat(field("confidence"), 64); err == nil {
+			d.confidence = confidence
+		}
+
+		recordingStart := start
+		if recordingStart.IsZero() && hasFileColumn && fileColumn < len(record) {
+			recordingStart = parseRecordingTime(record[fileColumn])
+		}
+		if recordingStart.IsZero() {
+			recordingStart = parseRecordingTime(path)
+		}
+		if offset, err := strconv.ParseFloat(field("start (s)"), 64); err == nil && !recordingStart.IsZero() {
+			d.begin = recordingStart.Add(time.Duration(offset * float64(time.Second)))
+		}
+
+		if err := im.add(d); err != nil {
+			return im.summary, err
+		}
+	}
+
+	return im.summary, nil
+}
+
+// parseRecordingTime returns the local recording start encoded in a file name, or the zero
+// time when the name has none
+func parseRecordingTime(name string) time.Time {
+	match := recordingTimePattern.FindStringSubmatch(filepath.Base(name))
+	if match == nil {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation("20060102150405", match[1]+match[2], time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// analyzerFormatError reports a result file that is not valid BirdNET-Analyzer CSV
+func analyzerFormatError(path string, err error) error {
+	return errors.New(err).
+		Component("importer").
+		Category(errors.CategoryValidation).
+		Context("path", path).
+		Context("hint", "expected BirdNET-Analyzer CSV results (--rtype csv)").
+		Build()
+}
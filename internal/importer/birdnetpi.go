@@ -0,0 +1,95 @@
+// birdnetpi.go: import of detections and clips from a BirdNET-Pi database
+package importer
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// birdnetPiQuery reads the detections table of BirdNET-Pi. Dates and times are cast to text
+// so the driver does not convert them to timestamps in UTC.
+const birdnetPiQuery = `SELECT CAST(Date AS TEXT), CAST(Time AS TEXT), Sci_Name, Com_Name, Confidence,
+	Lat, Lon, Cutoff, Sens, File_Name FROM detections ORDER BY Date, Time`
+
+// ImportBirdNETPi imports the detections of a BirdNET-Pi birds.db database. When
+// opts.ClipsDir is set to the BirdNET-Pi Extracted directory, the clip of each detection is
+// copied from By_Date/<date>/<common name>/<file name>.
+func ImportBirdNETPi(dbPath string, store Store, opts *Options) (Summary, error) {
+	db, err := gorm.Open(sqlite.Open("file:"+dbPath+"?mode=ro"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return Summary{}, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryDatabase).
+			Context("path", dbPath).
+			Build()
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer func() { _ = sqlDB.Close() }()
+	}
+
+	rows, err := db.Raw(birdnetPiQuery).Rows()
+	if err != nil {
+		return Summary{}, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryDatabase).
+			Context("path", dbPath).
+			Context("hint", "not a BirdNET-Pi birds.db database").
+			Build()
+	}
+	defer func() { _ = rows.Close() }()
+
+	im := newImporter(store, opts)
+	for rows.Next() {
+		var date, clock, scientificName, commonName, fileName sql.NullString
+		var confidence, lat, lon, cutoff, sens sql.NullFloat64
+		if err := rows.Scan(&date, &clock, &scientificName, &commonName, &confidence, &lat, &lon, &cutoff, &sens, &fileName); err != nil {
+			return im.summary, errors.New(err).
+				Component("importer").
+				Category(errors.CategoryDatabase).
+				Build()
+		}
+
+		d := &detection{
+			scientificName: strings.TrimSpace(scientificName.String),
+			commonName:     strings.TrimSpace(commonName.String),
+			confidence:     confidence.Float64,
+			latitude:       lat.Float64,
+			longitude:      lon.Float64,
+			threshold:      cutoff.Float64,
+			sensitivity:    sens.Float64,
+		}
+		if !confidence.Valid {
+			d.confidence = -1
+		}
+		if begin, err := time.ParseInLocation(time.DateTime, date.String+" "+clock.String, time.Local); err == nil {
+			d.begin = begin
+		}
+		if opts.ClipsDir != "" && fileName.String != "" {
+			d.clipPath = filepath.Join(opts.ClipsDir, "By_Date", date.String, birdnetPiSpeciesDir(d.commonName), filepath.Base(fileName.String))
+		}
+
+		if err := im.add(d); err != nil {
+			return im.summary, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return im.summary, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryDatabase).
+			Build()
+	}
+
+	return im.summary, nil
+}
+
+// birdnetPiSpeciesDir returns the directory BirdNET-Pi extracts clips of a species to
+func birdnetPiSpeciesDir(commonName string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(commonName, "'", ""), " ", "_")
+}
@@ -0,0 +1,223 @@
+// Package importer migrates detections from BirdNET-Pi databases and BirdNET-Analyzer CSV
+// results into the BirdNET-Go datastore. Detections already present with the same timestamp
+// and species are skipped, so an import can be repeated safely.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// detectionLength is the length of a BirdNET analysis chunk, used as the detection end time
+const detectionLength = conf.AnalysisWindow
+
+// Store is the datastore the detections are imported into. *datastore.DataStore and the
+// stores embedding it implement Store.
+type Store interface {
+	GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error)
+	Save(note *datastore.Note, results []datastore.Results) error
+}
+
+// Options controls how detections are imported
+type Options struct {
+	NodeName      string  // source node recorded on imported detections
+	MinConfidence float64 // detections below this confidence are skipped
+	ClipsDir      string  // directory with the source audio clips, empty to import detections only
+	ClipsDest     string  // clip export directory of BirdNET-Go that clips are copied to
+	DryRun        bool    // count what would be imported without writing anything
+}
+
+// Summary reports the outcome of an import
+type Summary struct {
+	Read         int // detections read from the source
+	Imported     int // detections saved to the datastore
+	Duplicates   int // detections already in the datastore or repeated in the source
+	Skipped      int // detections below the minimum confidence or with invalid values
+	ClipsCopied  int
+	ClipsMissing int // detections whose source clip was not found
+}
+
+// detection is a detection read from an import source
+type detection struct {
+	begin          time.Time
+	scientificName string
+	commonName     string
+	confidence     float64
+	latitude       float64
+	longitude      float64
+	threshold      float64
+	sensitivity    float64
+	clipPath       string // source clip, empty when the source has none
+}
+
+// importer saves detections while tracking the detections already present per date
+type importer struct {
+	store   Store
+	opts    *Options
+	summary Summary
+	seen    map[string]map[string]bool // date -> detection keys
+}
+
+// newImporter returns an importer writing to store
+func newImporter(store Store, opts *Options) *importer {
+	return &importer{store: store, opts: opts, seen: make(map[string]map[string]bool)}
+}
+
+// detectionKey identifies a detection by its timestamp to the second and species
+func detectionKey(clock, scientificName string) string {
+	return clock + "|" + strings.ToLower(scientificName)
+}
+
+// add imports a single detection unless it is a duplicate
+func (im *importer) add(d *detection) error {
+	im.summary.Read++
+	if d.scientificName == "" || d.begin.IsZero() || d.confidence < 0 || d.confidence > 1 || d.confidence < im.opts.MinConfidence {
+		im.summary.Skipped++
+		return nil
+	}
+
+	date, clock := d.begin.Format(time.DateOnly), d.begin.Format(time.TimeOnly)
+	keys, err := im.existing(date)
+	if err != nil {
+		return err
+	}
+	key := detectionKey(clock, d.scientificName)
+	if keys[key] {
+		im.summary.Duplicates++
+		return nil
+	}
+	keys[key] = true
+
+	note := &datastore.Note{
+		SourceNode:     im.opts.NodeName,
+		Date:           date,
+		Time:           clock,
+		BeginTime:      d.begin,
+		EndTime:        d.begin.Add(detectionLength),
+		ScientificName: d.scientificName,
+		CommonName:     d.commonName,
+		Confidence:     d.confidence,
+		Latitude:       d.latitude,
+		Longitude:      d.longitude,
+		Threshold:      d.threshold,
+		Sensitivity:    d.sensitivity,
+	}
+
+	if d.clipPath != "" {
+		clipName, err := im.copyClip(d)
+		if err != nil {
+			return err
+		}
+		note.ClipName = clipName
+	}
+
+	if !im.opts.DryRun {
+		results := []datastore.Results{{Species: d.scientificName + "_" + d.commonName, Confidence: float32(d.confidence)}}
+		if err := im.store.Save(note, results); err != nil {
+			return errors.New(err).
+				Component("importer").
+				Category(errors.CategoryDatabase).
+				Context("date", date).
+				Context("time", clock).
+				Build()
+		}
+	}
+	im.summary.Imported++
+	return nil
+}
+
+// existing returns the detection keys of a date, loading them from the datastore once
+func (im *importer) existing(date string) (map[string]bool, error) {
+	if keys, ok := im.seen[date]; ok {
+		return keys, nil
+	}
+
+	notes, err := im.store.GetNotesByDate(date, false)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryDatabase).
+			Context("date", date).
+			Build()
+	}
+	keys := make(map[string]bool, len(notes))
+	for i := range notes {
+		keys[detectionKey(notes[i].Time, notes[i].ScientificName)] = true
+	}
+	im.seen[date] = keys
+	return keys, nil
+}
+
+// copyClip copies the source clip of a detection into the clip export directory using the
+// BirdNET-Go naming scheme and returns the clip name relative to that directory. A missing
+// source clip is counted and imports the detection without a clip.
+func (im *importer) copyClip(d *detection) (string, error) {
+	if _, err := os.Stat(d.clipPath); err != nil {
+		im.summary.ClipsMissing++
+		return "", nil
+	}
+
+	clipName := filepath.ToSlash(filepath.Join(
+		d.begin.Format("2006"),
+		d.begin.Format("01"),
+		fmt.Sprintf("%s_%.0fp_%s%s",
+			strings.ToLower(strings.ReplaceAll(d.scientificName, " ", "_")),
+			d.confidence*100,
+			d.begin.Format("20060102T150405Z"),
+			strings.ToLower(filepath.Ext(d.clipPath))),
+	))
+
+	if !im.opts.DryRun {
+		dest := filepath.Join(im.opts.ClipsDest, filepath.FromSlash(clipName))
+		if err := copyFile(d.clipPath, dest); err != nil {
+			return "", errors.New(err).
+				Component("importer").
+				Category(errors.CategoryFileIO).
+				Context("clip", d.clipPath).
+				Build()
+		}
+	}
+	im.summary.ClipsCopied++
+	return clipName, nil
+}
+
+// copyFile copies src to dest, keeping an existing dest file from an earlier import
+func copyFile(src, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	// Copy to a temporary file so an interrupted import leaves no partial clip behind
+	tmp := dest + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
@@ -0,0 +1,215 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// mockStore keeps saved notes in memory
+type mockStore struct {
+	notes []datastore.Note
+}
+
+func (m *mockStore) GetNotesByDate(date string, includeResults bool) ([]datastore.Note, error) {
+	var notes []datastore.Note
+	for i := range m.notes {
+		if m.notes[i].Date == date {
+			notes = append(notes, m.notes[i])
+		}
+	}
+	return notes, nil
+}
+
+func (m *mockStore) Save(note *datastore.Note, results []datastore.Results) error {
+	note.Results = results
+	m.notes = append(m.notes, *note)
+	return nil
+}
+
+// createBirdNETPiDatabase creates a birds.db with the BirdNET-Pi detections schema
+func createBirdNETPiDatabase(t *testing.T, dir string) string {
+	t.Helper()
+	dbPath := filepath.Join(dir, "birds.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`CREATE TABLE detections (Date DATE, Time TIME, Sci_Name VARCHAR(100) NOT NULL,
+		Com_Name VARCHAR(100) NOT NULL, Confidence FLOAT, Lat FLOAT, Lon FLOAT, Cutoff FLOAT, Week INT, Sens FLOAT,
+		Overlap FLOAT, File_Name VARCHAR(100) NOT NULL)`).Error)
+	rows := []struct {
+		date, clock, sci, com string
+		confidence            float64
+		file                  string
+	}{
+		{"2023-05-10", "06:15:30", "Turdus merula", "Eurasian Blackbird", 0.9123, "Eurasian_Blackbird-91-2023-05-10-birdnet-06:15:30.mp3"},
+		{"2023-05-10", "06:15:30", "Turdus merula", "Eurasian Blackbird", 0.9123, "Eurasian_Blackbird-91-2023-05-10-birdnet-06:15:30.mp3"},
+		{"2023-05-10", "21:40:00", "Strix aluco", "Tawny Owl", 0.8, "Tawny_Owl-80-2023-05-10-birdnet-21:40:00.mp3"},
+		{"2023-05-11", "05:00:00", "Cuculus canorus", "Common Cuckoo", 0.2, "Common_Cuckoo-20-2023-05-11-birdnet-05:00:00.mp3"},
+	}
+	for _, r := range rows {
+		require.NoError(t, db.Exec(`INSERT INTO detections VALUES (?, ?, ?, ?, ?, 60.17, 24.94, 0.7, 19, 1.25, 0.0, ?)`,
+			r.date, r.clock, r.sci, r.com, r.confidence, r.file).Error)
+	}
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	return dbPath
+}
+
+func TestImportBirdNETPi(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := createBirdNETPiDatabase(t, dir)
+
+	extracted := filepath.Join(dir, "Extracted")
+	clip := filepath.Join(extracted, "By_Date", "2023-05-10", "Eurasian_Blackbird", "Eurasian_Blackbird-91-2023-05-10-birdnet-06:15:30.mp3")
+	require.NoError(t, os.MkdirAll(filepath.Dir(clip), 0o755))
+	require.NoError(t, os.WriteFile(clip, []byte("mp3 data"), 0o644))
+
+	store := &mockStore{}
+	clipsDest := filepath.Join(dir, "clips")
+	opts := &Options{NodeName: "station", MinConfidence: 0.5, ClipsDir: extracted, ClipsDest: clipsDest}
+	summary, err := ImportBirdNETPi(dbPath, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Read: 4, Imported: 2, Duplicates: 1, Skipped: 1, ClipsCopied: 1, ClipsMissing: 1}, summary)
+
+	require.Len(t, store.notes, 2)
+	blackbird := store.notes[0]
+	assert.Equal(t, "2023-05-10", blackbird.Date)
+	assert.Equal(t, "06:15:30", blackbird.Time)
+	assert.InDelta(t, 0.9123, blackbird.Confidence, 1e-9, "original confidence is preserved")
+	assert.InDelta(t, 0.7, blackbird.Threshold, 1e-9)
+	assert.Equal(t, "station", blackbird.SourceNode)
+	assert.Equal(t, "2023/05/turdus_merula_91p_20230510T061530Z.mp3", blackbird.ClipName)
+	assert.Equal(t, "Turdus merula_Eurasian Blackbird", blackbird.Results[0].Species)
+	data, err := os.ReadFile(filepath.Join(clipsDest, "2023", "05", "turdus_merula_91p_20230510T061530Z.mp3"))
+	require.NoError(t, err)
+	assert.Equal(t, "mp3 data", string(data))
+	assert.Empty(t, store.notes[1].ClipName, "missing clips are not linked")
+
+	// Importing again finds every detection in the datastore
+	summary, err = ImportBirdNETPi(dbPath, store, opts)
+	require.NoError(t, err)
+	assert.Zero(t, summary.Imported)
+	assert.Equal(t, 3, summary.Duplicates)
+	assert.Len(t, store.notes, 2)
+}
+
+func TestImportBirdNETPiDryRun(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	summary, err := ImportBirdNETPi(createBirdNETPiDatabase(t, t.TempDir()), store, &Options{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Imported)
+	assert.Empty(t, store.notes)
+}
+
+func TestImportBirdNETPiInvalidDatabase(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "other.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("CREATE TABLE notes (id INTEGER)").Error)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	_, err = ImportBirdNETPi(dbPath, &mockStore{}, &Options{})
+	assert.Error(t, err)
+}
+
+func TestImportAnalyzerCSV(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "20250510_061500.BirdNET.results.csv")
+	content := "Start (s),End (s),Scientific name,Common name,Confidence\n" +
+		"0.0,3.0,Turdus merula,Eurasian Blackbird,0.8765\n" +
+		"3.0,6.0,Turdus merula,Eurasian Blackbird,0.9\n" +
+		"3.0,6.0,Parus major,Great Tit,0.7\n" +
+		"6.0,9.0,Parus major,Great Tit,not a number\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	store := &mockStore{}
+	summary, err := ImportAnalyzerCSV(path, time.Time{}, store, &Options{})
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Read: 4, Imported: 3, Skipped: 1}, summary)
+	assert.Equal(t, "06:15:00", store.notes[0].Time)
+	assert.InDelta(t, 0.8765, store.notes[0].Confidence, 1e-9)
+	assert.Equal(t, "06:15:03", store.notes[1].Time)
+
+	// An explicit recording start takes precedence over the file name
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	summary, err = ImportAnalyzerCSV(path, start, store, &Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Imported)
+	assert.Equal(t, "2025-06-01", store.notes[3].Date)
+}
+
+func TestImportAnalyzerCSVFileColumn(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "combined.csv")
+	content := "Start (s),End (s),Scientific name,Common name,Confidence,File\n" +
+		"12.0,15.0,Strix aluco,Tawny Owl,0.81,/recordings/20250510_214000.WAV\n" +
+		"0.0,3.0,Strix aluco,Tawny Owl,0.75,/recordings/unknown.wav\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	store := &mockStore{}
+	summary, err := ImportAnalyzerCSV(path, time.Time{}, store, &Options{})
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Read: 2, Imported: 1, Skipped: 1}, summary, "detections without a recording start are skipped")
+	assert.Equal(t, "21:40:12", store.notes[0].Time)
+}
+
+func TestImportAnalyzerCSVInvalidHeader(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "results.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Selection\tView\tChannel\n"), 0o644))
+
+	_, err := ImportAnalyzerCSV(path, time.Time{}, &mockStore{}, &Options{})
+	assert.Error(t, err)
+}
+
+func TestImportIntoSQLite(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(dir, "birdnet.db")
+	ds := datastore.New(settings)
+	require.NoError(t, ds.Open())
+	defer func() { _ = ds.Close() }()
+
+	store, ok := ds.(Store)
+	require.True(t, ok, "datastore must implement importer.Store")
+
+	dbPath := createBirdNETPiDatabase(t, dir)
+	summary, err := ImportBirdNETPi(dbPath, store, &Options{MinConfidence: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Imported)
+
+	summary, err = ImportBirdNETPi(dbPath, store, &Options{MinConfidence: 0.5})
+	require.NoError(t, err)
+	assert.Zero(t, summary.Imported, "detections are de-duplicated against the datastore")
+
+	notes, err := store.GetNotesByDate("2023-05-10", true)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	require.Len(t, notes[0].Results, 1)
+}
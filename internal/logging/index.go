@@ -0,0 +1,197 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIndexCapacity is the number of recent entries kept by the log index
+const DefaultIndexCapacity = 5000
+
+// Entry is a log record written by a file logger, as kept by the log index
+type Entry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Service string         `json:"service"`
+	Message string         `json:"message"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// Query filters the entries of the log index. Zero fields do not filter.
+type Query struct {
+	Service string    // service name, matched exactly
+	Level   string    // minimum level name, e.g. WARN
+	From    time.Time // earliest entry time, inclusive
+	To      time.Time // latest entry time, inclusive
+	Text    string    // case-insensitive text searched in the message and attribute values
+	Limit   int       // maximum number of entries returned, all matching entries when zero
+}
+
+// entryIndex is a ring buffer of the most recent entries written by the file loggers
+type entryIndex struct {
+	mu      sync.RWMutex
+	entries []Entry
+	next    int // position of the next entry once the buffer is full
+}
+
+// index keeps the recent entries of every file logger for remote log viewing
+var index = newEntryIndex(DefaultIndexCapacity)
+
+// newEntryIndex creates an empty index keeping up to capacity entries
+func newEntryIndex(capacity int) *entryIndex {
+	return &entryIndex{entries: make([]Entry, 0, capacity)}
+}
+
+// Write indexes a JSON log line written by a file logger. Lines that are not JSON
+// records are ignored; Write never fails so it can't break the log file it is teed from.
+func (ix *entryIndex) Write(p []byte) (int, error) {
+	if entry, ok := parseEntry(p); ok {
+		ix.add(entry)
+	}
+	return len(p), nil
+}
+
+// add appends an entry, replacing the oldest entry once the buffer is full
+func (ix *entryIndex) add(entry Entry) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if len(ix.entries) < cap(ix.entries) {
+		ix.entries = append(ix.entries, entry)
+		return
+	}
+	if len(ix.entries) == 0 {
+		return
+	}
+	ix.entries[ix.next] = entry
+	ix.next = (ix.next + 1) % len(ix.entries)
+}
+
+// query returns the entries matching q, newest first
+func (ix *entryIndex) query(q Query) []Entry {
+	minLevel, filterLevel := parseLevelName(q.Level)
+	text := strings.ToLower(q.Text)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	matches := []Entry{}
+	for i := len(ix.entries) - 1; i >= 0; i-- {
+		entry := ix.entries[(ix.next+i)%len(ix.entries)]
+		if q.Service != "" && entry.Service != q.Service {
+			continue
+		}
+		if filterLevel {
+			if level, ok := parseLevelName(entry.Level); ok && level < minLevel {
+				continue
+			}
+		}
+		if !q.From.IsZero() && entry.Time.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && entry.Time.After(q.To) {
+			continue
+		}
+		if text != "" && !entry.contains(text) {
+			continue
+		}
+		matches = append(matches, entry)
+		if q.Limit > 0 && len(matches) == q.Limit {
+			break
+		}
+	}
+	return matches
+}
+
+// services returns the sorted names of the services with indexed entries
+func (ix *entryIndex) services() []string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	services := []string{}
+	for i := range ix.entries {
+		if service := ix.entries[i].Service; service != "" && !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	slices.Sort(services)
+	return services
+}
+
+// contains reports whether the message or an attribute value contains the lower-case text
+func (e *Entry) contains(text string) bool {
+	if strings.Contains(strings.ToLower(e.Message), text) {
+		return true
+	}
+	for _, value := range e.Attrs {
+		if s, ok := value.(string); ok && strings.Contains(strings.ToLower(s), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEntry converts a JSON log line written by a file logger to an Entry
+func parseEntry(line []byte) (Entry, bool) {
+	var record map[string]any
+	if err := json.Unmarshal(line, &record); err != nil {
+		return Entry{}, false
+	}
+
+	var entry Entry
+	if raw, ok := record[slog.TimeKey].(string); ok {
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			entry.Time = t
+		}
+	}
+	entry.Level, _ = record[slog.LevelKey].(string)
+	entry.Message, _ = record[slog.MessageKey].(string)
+	entry.Service, _ = record["service"].(string)
+	delete(record, slog.TimeKey)
+	delete(record, slog.LevelKey)
+	delete(record, slog.MessageKey)
+	delete(record, "service")
+	if len(record) > 0 {
+		entry.Attrs = record
+	}
+	return entry, true
+}
+
+// parseLevelName parses a level name written by the file loggers, including TRACE and FATAL
+func parseLevelName(name string) (slog.Level, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for level, label := range levelNames {
+		if strings.EqualFold(label, name) {
+			return level.Level(), true
+		}
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, false
+	}
+	return level, true
+}
+
+// IsLevelName reports whether name is a level name accepted by Query
+func IsLevelName(name string) bool {
+	_, ok := parseLevelName(name)
+	return ok
+}
+
+// QueryEntries returns the recent log entries of the file loggers matching q, newest first
+func QueryEntries(q Query) []Entry {
+	return index.query(q)
+}
+
+// IndexedServices returns the names of the services with recent log entries
+func IndexedServices() []string {
+	return index.services()
+}
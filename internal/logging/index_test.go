@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLoggerEntriesAreIndexed(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "indexed.log")
+	logger, closeLogger, err := NewFileLogger(logPath, "index-test", new(slog.LevelVar))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeLogger() })
+
+	logger.Info("station started", "source", "rtsp")
+	logger.Warn("buffer overrun", "count", 3)
+
+	entries := QueryEntries(Query{Service: "index-test"})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "buffer overrun", entries[0].Message, "newest entry first")
+	assert.Equal(t, "WARN", entries[0].Level)
+	assert.InDelta(t, 3.0, entries[0].Attrs["count"], 0)
+	assert.Equal(t, "rtsp", entries[1].Attrs["source"])
+	assert.False(t, entries[1].Time.IsZero())
+	assert.Contains(t, IndexedServices(), "index-test")
+}
+
+func TestEntryIndexQuery(t *testing.T) {
+	t.Parallel()
+
+	ix := newEntryIndex(10)
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	lines := []string{
+		`{"time":"2025-05-01T12:00:00Z","level":"DEBUG","msg":"polling","service":"weather"}`,
+		`{"time":"2025-05-01T12:01:00Z","level":"INFO","msg":"connected","service":"mqtt","broker":"Local-Broker"}`,
+		`{"time":"2025-05-01T12:02:00Z","level":"ERROR","msg":"upload failed","service":"birdweather"}`,
+		`{"time":"2025-05-01T12:03:00Z","level":"FATAL","msg":"database lost","service":"datastore"}`,
+		`not a json line`,
+	}
+	for _, line := range lines {
+		n, err := ix.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+		assert.Equal(t, len(line)+1, n)
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"all entries", Query{}, []string{"database lost", "upload failed", "connected", "polling"}},
+		{"service", Query{Service: "mqtt"}, []string{"connected"}},
+		{"minimum level", Query{Level: "warn"}, []string{"database lost", "upload failed"}},
+		{"time range", Query{From: base.Add(time.Minute), To: base.Add(2 * time.Minute)}, []string{"upload failed", "connected"}},
+		{"text in attribute", Query{Text: "local-broker"}, []string{"connected"}},
+		{"text in message", Query{Text: "UPLOAD"}, []string{"upload failed"}},
+		{"limit", Query{Limit: 1}, []string{"database lost"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			messages := []string{}
+			for _, entry := range ix.query(tt.query) {
+				messages = append(messages, entry.Message)
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+
+	assert.Equal(t, []string{"birdweather", "datastore", "mqtt", "weather"}, ix.services())
+}
+
+func TestEntryIndexKeepsMostRecentEntries(t *testing.T) {
+	t.Parallel()
+
+	ix := newEntryIndex(3)
+	for i := range 5 {
+		_, _ = fmt.Fprintf(ix, `{"level":"INFO","msg":"entry %d","service":"ring"}`, i)
+	}
+
+	entries := ix.query(Query{})
+	require.Len(t, entries, 3)
+	assert.Equal(t, "entry 4", entries[0].Message)
+	assert.Equal(t, "entry 2", entries[2].Message)
+}
+
+func TestIsLevelName(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"trace", "DEBUG", "info", "Warn", "ERROR", "fatal"} {
+		assert.True(t, IsLevelName(name), name)
+	}
+	assert.False(t, IsLevelName("verbose"))
+	assert.False(t, IsLevelName(""))
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// journaldSocket is the socket of the systemd journal native protocol
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldIdentifier is the SYSLOG_IDENTIFIER of the records sent to the journal
+const journaldIdentifier = "birdnet-go"
+
+// journaldSink sends records to the systemd journal over its native protocol, with the
+// attributes of a record as upper-case journal fields
+type journaldSink struct {
+	conn lazyConn
+}
+
+// newJournaldSink creates a journald sink writing to the journal socket at path
+func newJournaldSink(path string) *journaldSink {
+	return &journaldSink{conn: lazyConn{dial: func() (net.Conn, error) {
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}}}
+}
+
+// send writes an entry to the journal as a single datagram
+func (s *journaldSink) send(entry *Entry) error {
+	return s.conn.write(journaldMessage(entry))
+}
+
+// Close closes the connection to the journal socket
+func (s *journaldSink) Close() error {
+	return s.conn.Close()
+}
+
+// journaldMessage encodes an entry in the journal native protocol. Attributes are sorted by
+// name and never replace the message, priority, identifier and service fields.
+func journaldMessage(entry *Entry) []byte {
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", entry.Message)
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(syslogSeverity(entry.Level)))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", journaldIdentifier)
+	reserved := []string{"MESSAGE", "PRIORITY", "SYSLOG_IDENTIFIER"}
+	if entry.Service != "" {
+		writeJournalField(&b, "SERVICE", entry.Service)
+		reserved = append(reserved, "SERVICE")
+	}
+
+	names := make([]string, 0, len(entry.Attrs))
+	for name := range entry.Attrs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		field := journalFieldName(name)
+		if field == "" || slices.Contains(reserved, field) {
+			continue
+		}
+		writeJournalField(&b, field, attrString(entry.Attrs[name]))
+	}
+	return b.Bytes()
+}
+
+// writeJournalField writes a field as NAME=value, or with the binary length-prefixed
+// encoding when the value spans several lines
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName returns the journal field name of an attribute: upper-case letters, digits
+// and underscores, at most 64 characters and starting with a letter. It returns an empty
+// name for attributes without a valid field name.
+func journalFieldName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if b.Len() == 64 {
+			break
+		}
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9' && b.Len() > 0:
+			b.WriteRune(r)
+		case b.Len() > 0:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"operation":   "OPERATION",
+		"duration_ms": "DURATION_MS",
+		"client.ip":   "CLIENT_IP",
+		"_trusted":    "TRUSTED",
+		"3d":          "D",
+		"---":         "",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, journalFieldName(name), name)
+	}
+}
+
+func TestJournaldSink(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	sink := newJournaldSink(socket)
+	t.Cleanup(func() { _ = sink.Close() })
+
+	require.NoError(t, sink.send(&Entry{
+		Level:   "ERROR",
+		Service: "mqtt",
+		Message: "Connection lost\nbroker unreachable",
+		Attrs:   map[string]any{"broker": "tcp://localhost:1883", "message": "ignored", "retries": float64(2)},
+	}))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	var expected bytes.Buffer
+	expected.WriteString("MESSAGE\n")
+	require.NoError(t, binary.Write(&expected, binary.LittleEndian, uint64(len("Connection lost\nbroker unreachable"))))
+	expected.WriteString("Connection lost\nbroker unreachable\n")
+	expected.WriteString("PRIORITY=3\nSYSLOG_IDENTIFIER=birdnet-go\nSERVICE=mqtt\nBROKER=tcp://localhost:1883\nRETRIES=2\n")
+	assert.Equal(t, expected.String(), string(buf[:n]))
+}
+
+func TestJournaldSinkWithoutJournal(t *testing.T) {
+	sink := newJournaldSink(filepath.Join(t.TempDir(), "missing.socket"))
+	require.Error(t, sink.send(&Entry{Message: "lost"}))
+	assert.ErrorContains(t, sink.send(&Entry{Message: "lost"}), "retrying", "redialing is delayed after a failure")
+}
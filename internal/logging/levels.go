@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// MainComponent is the component name of the main application logger
+const MainComponent = "app"
+
+// componentLevels holds the level variables of the components logging through
+// NewFileLogger and the main logger, so their levels can be changed at runtime from
+// the configuration or the API. Component names are case-insensitive.
+var componentLevels = struct {
+	sync.Mutex
+	vars      map[string][]*slog.LevelVar // level variables of each component
+	defaults  map[string]slog.Level       // level of each component when it registered
+	overrides map[string]slog.Level       // levels set by the configuration or the API
+}{
+	vars:      make(map[string][]*slog.LevelVar),
+	defaults:  make(map[string]slog.Level),
+	overrides: make(map[string]slog.Level),
+}
+
+// componentKey returns the registry key of a component name
+func componentKey(component string) string {
+	return strings.ToLower(strings.TrimSpace(component))
+}
+
+// RegisterLevel registers the level variable of a component. NewFileLogger registers the
+// level variable of every file logger under its service name; other loggers can register
+// theirs directly. A level configured for the component is applied immediately.
+func RegisterLevel(component string, levelVar *slog.LevelVar) {
+	key := componentKey(component)
+	if key == "" || levelVar == nil {
+		return
+	}
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	for _, registered := range componentLevels.vars[key] {
+		if registered == levelVar {
+			return
+		}
+	}
+	componentLevels.vars[key] = append(componentLevels.vars[key], levelVar)
+	if _, ok := componentLevels.defaults[key]; !ok {
+		componentLevels.defaults[key] = levelVar.Level()
+	}
+	if level, ok := componentLevels.overrides[key]; ok {
+		levelVar.Set(level)
+	}
+}
+
+// SetComponentLevel changes the level of a registered component at runtime
+func SetComponentLevel(component string, level slog.Level) error {
+	key := componentKey(component)
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	vars, ok := componentLevels.vars[key]
+	if !ok {
+		return fmt.Errorf("unknown log component %q", component)
+	}
+	componentLevels.overrides[key] = level
+	for _, levelVar := range vars {
+		levelVar.Set(level)
+	}
+	return nil
+}
+
+// ApplyComponentLevels replaces the configured component levels, a map of component names
+// to level names. Components that are not registered yet get their level when they
+// register, and registered components no longer configured return to the level they had
+// when they registered.
+func ApplyComponentLevels(configured map[string]string) error {
+	overrides := make(map[string]slog.Level, len(configured))
+	for component, name := range configured {
+		level, ok := parseLevelName(name)
+		if !ok {
+			return fmt.Errorf("unknown log level %q for component %q", name, component)
+		}
+		overrides[componentKey(component)] = level
+	}
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	componentLevels.overrides = overrides
+	for key, vars := range componentLevels.vars {
+		level, ok := overrides[key]
+		if !ok {
+			level = componentLevels.defaults[key]
+		}
+		for _, levelVar := range vars {
+			levelVar.Set(level)
+		}
+	}
+	return nil
+}
+
+// ComponentLevels returns the current level of every registered component
+func ComponentLevels() map[string]slog.Level {
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	levels := make(map[string]slog.Level, len(componentLevels.vars))
+	for key, vars := range componentLevels.vars {
+		levels[key] = vars[0].Level()
+	}
+	return levels
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN", including TRACE and FATAL
+func ParseLevel(name string) (slog.Level, error) {
+	level, ok := parseLevelName(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+// LevelName returns the name the loggers write for a level
+func LevelName(level slog.Level) string {
+	if label, ok := levelNames[level]; ok {
+		return label
+	}
+	return level.String()
+}
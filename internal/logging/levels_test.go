@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentLevels(t *testing.T) {
+	// Not parallel, the component levels are shared by the package
+	t.Cleanup(func() { _ = ApplyComponentLevels(nil) })
+
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(slog.LevelInfo)
+	_, closeLogger, err := NewFileLogger(filepath.Join(t.TempDir(), "levels.log"), "Level-Test", levelVar)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeLogger() })
+
+	assert.Equal(t, slog.LevelInfo, ComponentLevels()["level-test"], "file loggers register under their service name")
+
+	require.NoError(t, SetComponentLevel("LEVEL-TEST", slog.LevelDebug))
+	assert.Equal(t, slog.LevelDebug, levelVar.Level())
+	assert.Error(t, SetComponentLevel("not-registered", slog.LevelDebug))
+
+	require.NoError(t, ApplyComponentLevels(map[string]string{"level-test": "warn", "late-test": "error"}))
+	assert.Equal(t, slog.LevelWarn, levelVar.Level())
+
+	lateVar := new(slog.LevelVar)
+	RegisterLevel("late-test", lateVar)
+	assert.Equal(t, slog.LevelError, lateVar.Level(), "configured level is applied on registration")
+
+	require.NoError(t, ApplyComponentLevels(nil))
+	assert.Equal(t, slog.LevelInfo, levelVar.Level(), "unconfigured components return to their registration level")
+
+	assert.Error(t, ApplyComponentLevels(map[string]string{"level-test": "verbose"}))
+}
+
+func TestLevelName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "TRACE", LevelName(LevelTrace))
+	assert.Equal(t, "WARN", LevelName(slog.LevelWarn))
+
+	level, err := ParseLevel("fatal")
+	require.NoError(t, err)
+	assert.Equal(t, LevelFatal, level)
+}
+
+func TestLevelAttributeDoesNotPanic(t *testing.T) {
+	logger, closeLogger, err := NewFileLogger(filepath.Join(t.TempDir(), "attr.log"), "level-attr-test", new(slog.LevelVar))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeLogger() })
+
+	assert.NotPanics(t, func() { logger.Info("level changed", "level", "DEBUG") })
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -51,14 +52,15 @@ func defaultReplaceAttr(groups []string, a slog.Attr) slog.Attr {
 	if a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
 		a.Value = slog.StringValue(a.Value.Time().Format("2006-01-02T15:04:05Z07:00"))
 	}
-	// Customize level names
-	if a.Key == slog.LevelKey {
-		level := a.Value.Any().(slog.Level)
-		levelLabel, exists := levelNames[level]
-		if !exists {
-			levelLabel = level.String()
+	// Customize level names of the record level, attributes named "level" are left alone
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			levelLabel, exists := levelNames[level]
+			if !exists {
+				levelLabel = level.String()
+			}
+			a.Value = slog.StringValue(levelLabel)
 		}
-		a.Value = slog.StringValue(levelLabel)
 	}
 	// Truncate float64 values to 2 decimal places
 	if a.Value.Kind() == slog.KindFloat64 {
@@ -77,6 +79,7 @@ func Init() {
 		// TODO: Determine if a global config setting should drive this initial level.
 		// For now, we rely on the default LevelInfo or explicit SetLevel calls.
 		currentLogLevel.Set(slog.LevelInfo)
+		RegisterLevel(MainComponent, currentLogLevel)
 
 		// Ensure logs directory exists
 		err := os.MkdirAll("logs", 0o755) //nolint:gosec // accept 0o755 for now
@@ -98,7 +101,8 @@ func Init() {
 			currentStructuredOutputCloser = nil // Ensure it's nil if we fell back to stderr
 		}
 
-		structuredHandler := slog.NewJSONHandler(structuredLogFile, &slog.HandlerOptions{
+		// Records are also forwarded to the syslog and journald outputs
+		structuredHandler := slog.NewJSONHandler(io.MultiWriter(structuredLogFile, outputs), &slog.HandlerOptions{
 			Level:       currentLogLevel,
 			ReplaceAttr: defaultReplaceAttr,
 		})
@@ -267,7 +271,12 @@ func Trace(msg string, args ...any) {
 // NewFileLogger creates a new slog.Logger instance configured to write JSON logs
 // to the specified file path using lumberjack for rotation based on global config.
 // It includes a 'service' attribute in all logs.
-// It returns the logger, a function to close the underlying log writer, and an error if setup fails.
+// Records are also kept in the in-memory log index queried by QueryEntries and forwarded to
+// the outputs set up by ConfigureOutputs, and the level variable is registered under the
+// service name for runtime level control.
+// Loggers created for the same path share one rotating writer owned by the logging registry.
+// It returns the logger, a function to release the logger's log file, and an error if setup fails.
+// The file is closed once every logger using it has been released, or by CloseAll.
 func NewFileLogger(filePath, serviceName string, levelVar *slog.LevelVar) (*slog.Logger, func() error, error) {
 	// Ensure the directory exists (lumberjack doesn't create directories)
 	logDir := filepath.Dir(filePath)
@@ -277,61 +286,62 @@ func NewFileLogger(filePath, serviceName string, levelVar *slog.LevelVar) (*slog
 		}
 	}
 
-	// Configure lumberjack logger based on global config settings
+	lj, closeFunc := acquireFileWriter(filePath, func() *rotatingFile {
+		return newRotatingWriter(filePath)
+	})
+
+	// Create the slog handler using the lumberjack writer, teed to the log index so
+	// recent entries can be queried through the API, and to the syslog and journald outputs
+	handler := slog.NewJSONHandler(io.MultiWriter(lj, index, outputs), &slog.HandlerOptions{
+		AddSource:   false, // Keep this false unless specifically needed for debugging
+		Level:       levelVar,
+		ReplaceAttr: defaultReplaceAttr,
+	})
+
+	// Create the logger and add the service attribute
+	logger := slog.New(handler).With("service", serviceName)
+
+	// Register the level so it can be changed at runtime per component
+	RegisterLevel(serviceName, levelVar)
+
+	return logger, closeFunc, nil
+}
+
+// newRotatingWriter creates a rotating writer for a log file with the rotation and retention
+// settings of the main log
+func newRotatingWriter(filePath string) *rotatingFile {
 	// Using Main.Log settings as the default for all file loggers created via this func
 	mainLogConf := conf.Setting().Main.Log
 
-	lj := &lumberjack.Logger{
-		Filename: filePath,
-		Compress: false, // Compression can be added later if needed
-	}
-
-	// Apply rotation settings from config
-	// Default values
+	// Files are rotated at the configured size with every rotation type, so a noisy service
+	// can't fill the disk between daily or weekly rotations
 	maxSizeMB := 100
-	maxBackups := 3
-	maxAge := 28 // days
-
-	configMaxSizeMB := int(mainLogConf.MaxSize / (1024 * 1024))
-	if configMaxSizeMB > 0 {
+	if configMaxSizeMB := int(mainLogConf.MaxSize / (1024 * 1024)); configMaxSizeMB > 0 {
 		maxSizeMB = configMaxSizeMB
 	}
 
+	lj := &lumberjack.Logger{
+		Filename:   filePath,
+		MaxSize:    maxSizeMB,
+		MaxBackups: mainLogConf.MaxBackups,
+		MaxAge:     mainLogConf.MaxAge,
+		Compress:   mainLogConf.Compress,
+	}
+
+	rotationDay := time.Sunday
 	switch mainLogConf.Rotation {
-	case conf.RotationDaily:
-		maxAge = 1
-		maxBackups = 30
 	case conf.RotationWeekly:
-		maxAge = 7
-		maxBackups = 4
-	case conf.RotationSize:
-		// Size-based rotation uses maxSizeMB derived from config (or default)
+		day, err := mainLogConf.GetRotationDay()
+		if err != nil {
+			slog.Warn("Invalid log rotation day in config, rotating on Sunday", "configuredDay", mainLogConf.RotationDay)
+		} else {
+			rotationDay = day
+		}
+	case conf.RotationDaily, conf.RotationSize:
+		// No rotation day
 	default:
-		slog.Warn("Unknown log rotation type in config, using size-based defaults", "configuredType", mainLogConf.Rotation)
-	}
-
-	lj.MaxSize = maxSizeMB
-	lj.MaxBackups = maxBackups
-	lj.MaxAge = maxAge
-
-	// Create the slog handler using the lumberjack writer
-	handler := slog.NewJSONHandler(lj, &slog.HandlerOptions{
-		AddSource:   false, // Keep this false unless specifically needed for debugging
-		Level:       levelVar,
-		ReplaceAttr: defaultReplaceAttr,
-	})
-
-	// Create the logger and add the service attribute
-	logger := slog.New(handler).With("service", serviceName)
-
-	// Return the logger and the lumberjack closer function
-	// Note: lumberjack.Logger.Close() doesn't actually close the file handle
-	// immediately in the typical sense, it's more for resource cleanup related
-	// to its internal state if needed. The actual file handle management
-	// happens internally based on rotation.
-	closeFunc := func() error {
-		return lj.Close() // Call lumberjack's Close method
+		slog.Warn("Unknown log rotation type in config, using size-based rotation", "configuredType", mainLogConf.Rotation)
 	}
 
-	return logger, closeFunc, nil
+	return newRotatingFile(lj, mainLogConf.Rotation, rotationDay)
 }
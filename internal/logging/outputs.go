@@ -0,0 +1,233 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+const (
+	// outputQueueSize is the number of records an output buffers while its server is slow
+	// or unreachable, further records are dropped
+	outputQueueSize = 1000
+	// outputCloseTimeout is how long closing an output waits for queued records to be sent
+	outputCloseTimeout = 2 * time.Second
+	// outputRedialInterval is the minimum time between connection attempts to a log server
+	outputRedialInterval = 10 * time.Second
+	// outputErrorInterval is the minimum time between reports of failed or dropped records
+	outputErrorInterval = time.Minute
+	// outputWriteTimeout bounds the time to connect and write a record
+	outputWriteTimeout = 5 * time.Second
+)
+
+// outputSink sends log records to a log collector
+type outputSink interface {
+	send(entry *Entry) error
+	Close() error
+}
+
+// output forwards log records at or above its minimum level to a sink. Records are sent
+// from a bounded queue so a slow or unreachable log server never blocks logging.
+type output struct {
+	name     string
+	sink     outputSink
+	minLevel slog.Level
+	filter   bool // false to forward records of every level
+	queue    chan Entry
+	done     chan struct{}
+	dropped  atomic.Int64 // records dropped since the last report
+	abandon  atomic.Bool  // true once close has stopped waiting for the queued records
+}
+
+// newOutput starts forwarding records of at least the named level to sink, every record
+// when level is empty
+func newOutput(name string, sink outputSink, level string) *output {
+	o := &output{
+		name:  name,
+		sink:  sink,
+		queue: make(chan Entry, outputQueueSize),
+		done:  make(chan struct{}),
+	}
+	o.minLevel, o.filter = parseLevelName(level)
+	go o.run()
+	return o
+}
+
+// enqueue queues an entry for sending, dropping it when the queue is full
+func (o *output) enqueue(entry *Entry) {
+	if o.filter {
+		if level, ok := parseLevelName(entry.Level); ok && level < o.minLevel {
+			return
+		}
+	}
+	select {
+	case o.queue <- *entry:
+	default:
+		o.dropped.Add(1)
+	}
+}
+
+// run sends queued entries until the queue is closed. Failures are reported on stderr,
+// as logging them would feed them back into the output.
+func (o *output) run() {
+	defer close(o.done)
+
+	var lastReport time.Time
+	for entry := range o.queue {
+		if o.abandon.Load() {
+			continue
+		}
+		err := o.sink.send(&entry)
+		if err == nil && o.dropped.Load() == 0 {
+			continue
+		}
+		if time.Since(lastReport) < outputErrorInterval {
+			continue
+		}
+		lastReport = time.Now()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send log record to %s: %v\n", o.name, err)
+		}
+		if dropped := o.dropped.Swap(0); dropped > 0 {
+			fmt.Fprintf(os.Stderr, "Dropped %d log records for %s, the log server is not keeping up\n", dropped, o.name)
+		}
+	}
+}
+
+// close stops the output after sending the queued records, waiting at most
+// outputCloseTimeout, and closes its sink
+func (o *output) close() error {
+	close(o.queue)
+	select {
+	case <-o.done:
+		return o.sink.Close()
+	case <-time.After(outputCloseTimeout):
+		// The sink is still in use by run, close it once the current write times out
+		o.abandon.Store(true)
+		go func() {
+			<-o.done
+			_ = o.sink.Close()
+		}()
+		return fmt.Errorf("timed out sending queued log records to %s", o.name)
+	}
+}
+
+// outputSet is the writer file loggers tee their records to for forwarding to the
+// configured outputs
+type outputSet struct {
+	mu      sync.RWMutex
+	outputs []*output
+}
+
+// outputs forwards the records of every file logger to syslog and journald
+var outputs = &outputSet{}
+
+// Write forwards a JSON log line to the outputs. Lines that are not JSON records are
+// ignored; Write never fails so it can't break the log file it is teed from.
+func (s *outputSet) Write(p []byte) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.outputs) == 0 {
+		return len(p), nil
+	}
+	if entry, ok := parseEntry(p); ok {
+		for _, o := range s.outputs {
+			o.enqueue(&entry)
+		}
+	}
+	return len(p), nil
+}
+
+// replace swaps the outputs and closes the previous ones
+func (s *outputSet) replace(next []*output) error {
+	s.mu.Lock()
+	previous := s.outputs
+	s.outputs = next
+	s.mu.Unlock()
+
+	var closeErrors []error
+	for _, o := range previous {
+		if err := o.close(); err != nil {
+			closeErrors = append(closeErrors, err)
+		}
+	}
+	return errors.Join(closeErrors...)
+}
+
+// ConfigureOutputs replaces the syslog and journald outputs with the ones enabled in the
+// logging settings. Outputs connect on their first record and reconnect after failures, so
+// an unreachable log server does not fail configuration.
+func ConfigureOutputs(settings *conf.Settings) error {
+	var configured []*output
+
+	if syslogSettings := &settings.Logging.Syslog; syslogSettings.Enabled {
+		sink, err := newSyslogSink(syslogSettings, &settings.OutboundTLS)
+		if err != nil {
+			return fmt.Errorf("failed to configure syslog output: %w", err)
+		}
+		configured = append(configured, newOutput("syslog", sink, syslogSettings.Level))
+	}
+
+	if journaldSettings := &settings.Logging.Journald; journaldSettings.Enabled {
+		configured = append(configured, newOutput("journald", newJournaldSink(journaldSocket), journaldSettings.Level))
+	}
+
+	return outputs.replace(configured)
+}
+
+// lazyConn is a connection to a log server dialed on first use and redialed after a
+// failure, at most once per outputRedialInterval
+type lazyConn struct {
+	dial    func() (net.Conn, error)
+	conn    net.Conn
+	retryAt time.Time
+}
+
+// write writes p to the connection, dialing it when needed. The connection is closed on a
+// write error so the next write redials.
+func (c *lazyConn) write(p []byte) error {
+	if c.conn == nil {
+		if time.Now().Before(c.retryAt) {
+			return fmt.Errorf("not connected, retrying in %s", c.retryAt.Sub(time.Now()).Round(time.Second))
+		}
+		conn, err := c.dial()
+		if err != nil {
+			c.retryAt = time.Now().Add(outputRedialInterval)
+			return err
+		}
+		c.conn = conn
+	}
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(outputWriteTimeout)); err != nil {
+		return c.fail(err)
+	}
+	if _, err := c.conn.Write(p); err != nil {
+		return c.fail(err)
+	}
+	return nil
+}
+
+// fail closes the connection after a write error and returns the error
+func (c *lazyConn) fail(err error) error {
+	_ = c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// Close closes the connection if it is open
+func (c *lazyConn) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
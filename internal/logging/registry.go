@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// fileWriter is a rotating log file shared by the file loggers writing to the same path
+type fileWriter struct {
+	lj   *rotatingFile
+	refs int // number of file loggers not yet closed
+}
+
+// registry owns the log files opened by NewFileLogger. Loggers writing to the same path
+// share a single rotating writer so rotation of one logger does not race another, and a
+// file is closed when its last logger is closed or by CloseAll during shutdown.
+var registry = struct {
+	sync.Mutex
+	writers map[string]*fileWriter
+}{writers: make(map[string]*fileWriter)}
+
+// registryKey returns the registry key of a log file path
+func registryKey(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		return abs
+	}
+	return filepath.Clean(filePath)
+}
+
+// acquireFileWriter returns the shared writer of a log file, creating it with newWriter
+// if the file is not open, and a function that releases the caller's reference. The
+// release function is safe to call more than once.
+func acquireFileWriter(filePath string, newWriter func() *rotatingFile) (*rotatingFile, func() error) {
+	key := registryKey(filePath)
+
+	registry.Lock()
+	writer, ok := registry.writers[key]
+	if !ok {
+		writer = &fileWriter{lj: newWriter()}
+		registry.writers[key] = writer
+	}
+	writer.refs++
+	registry.Unlock()
+
+	var once sync.Once
+	release := func() error {
+		var err error
+		once.Do(func() {
+			err = releaseFileWriter(key, writer)
+		})
+		return err
+	}
+	return writer.lj, release
+}
+
+// releaseFileWriter drops a reference to a shared writer and closes the file once no
+// logger uses it
+func releaseFileWriter(key string, writer *fileWriter) error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	// The writer has been closed by CloseAll or replaced after its last release
+	if registry.writers[key] != writer {
+		return nil
+	}
+	writer.refs--
+	if writer.refs > 0 {
+		return nil
+	}
+	delete(registry.writers, key)
+	return writer.lj.Close()
+}
+
+// CloseAll closes every log file opened by NewFileLogger regardless of how many loggers
+// still use it, and stops the syslog and journald outputs after sending their queued
+// records. It is called once during coordinated shutdown, after the services have
+// stopped; closers returned by NewFileLogger become no-ops. A logger that still writes
+// afterwards reopens its file.
+func CloseAll() error {
+	registry.Lock()
+	writers := registry.writers
+	registry.writers = make(map[string]*fileWriter)
+	registry.Unlock()
+
+	var closeErrors []error
+	if err := outputs.replace(nil); err != nil {
+		closeErrors = append(closeErrors, err)
+	}
+	for path, writer := range writers {
+		if err := writer.lj.Close(); err != nil {
+			closeErrors = append(closeErrors, fmt.Errorf("failed to close log file %s: %w", path, err))
+		}
+	}
+	return errors.Join(closeErrors...)
+}
+
+// OpenFileCount returns the number of log files opened by NewFileLogger that are not closed
+func OpenFileCount() int {
+	registry.Lock()
+	defer registry.Unlock()
+	return len(registry.writers)
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLoggersShareWriter(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "shared.log")
+	levelVar := new(slog.LevelVar)
+	before := OpenFileCount()
+
+	first, closeFirst, err := NewFileLogger(logPath, "first", levelVar)
+	require.NoError(t, err)
+	second, closeSecond, err := NewFileLogger(logPath, "second", levelVar)
+	require.NoError(t, err)
+	assert.Equal(t, before+1, OpenFileCount(), "loggers for the same path share one file")
+
+	first.Info("from first")
+	require.NoError(t, closeFirst())
+	require.NoError(t, closeFirst(), "closing twice must not release the file of the other logger")
+	assert.Equal(t, before+1, OpenFileCount())
+
+	second.Info("from second")
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "from first")
+	assert.Contains(t, string(data), "from second")
+
+	require.NoError(t, closeSecond())
+	assert.Equal(t, before, OpenFileCount(), "file is closed with its last logger")
+}
+
+func TestCloseAll(t *testing.T) {
+	dir := t.TempDir()
+	levelVar := new(slog.LevelVar)
+
+	_, closeA, err := NewFileLogger(filepath.Join(dir, "a.log"), "a", levelVar)
+	require.NoError(t, err)
+	_, _, err = NewFileLogger(filepath.Join(dir, "b.log"), "b", levelVar)
+	require.NoError(t, err)
+
+	require.NoError(t, CloseAll())
+	assert.Zero(t, OpenFileCount())
+	assert.NoError(t, closeA(), "closers are no-ops after CloseAll")
+
+	// A logger created after shutdown gets a new writer that is not affected by old closers
+	_, closeC, err := NewFileLogger(filepath.Join(dir, "a.log"), "c", levelVar)
+	require.NoError(t, err)
+	assert.NoError(t, closeA())
+	assert.Equal(t, 1, OpenFileCount())
+	require.NoError(t, closeC())
+}
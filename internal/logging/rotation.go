@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotatingFile is a service log file rotated by lumberjack when it reaches its maximum size,
+// and at midnight of every day or of the rotation day of the week for daily and weekly
+// rotation. Lumberjack removes and compresses the rotated files.
+type rotatingFile struct {
+	*lumberjack.Logger
+
+	mu          sync.Mutex
+	rotation    conf.RotationType
+	rotationDay time.Weekday
+	next        time.Time        // next time-based rotation, zero for size-based rotation
+	now         func() time.Time // clock, replaced in tests
+}
+
+// newRotatingFile creates a rotating log file. A file left over from an earlier run is
+// rotated on the first write when it was last written before the current period.
+func newRotatingFile(lj *lumberjack.Logger, rotation conf.RotationType, rotationDay time.Weekday) *rotatingFile {
+	f := &rotatingFile{
+		Logger:      lj,
+		rotation:    rotation,
+		rotationDay: rotationDay,
+		now:         time.Now,
+	}
+	lastWrite := f.now()
+	if info, err := os.Stat(lj.Filename); err == nil {
+		lastWrite = info.ModTime()
+	}
+	f.next = nextRotation(lastWrite, rotation, rotationDay)
+	return f
+}
+
+// Write rotates the file when a time-based rotation is due and writes p to it
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	if !f.next.IsZero() {
+		if now := f.now(); !now.Before(f.next) {
+			if err := f.Logger.Rotate(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rotate log file %s: %v\n", f.Filename, err)
+			}
+			f.next = nextRotation(now, f.rotation, f.rotationDay)
+		}
+	}
+	f.mu.Unlock()
+	return f.Logger.Write(p)
+}
+
+// nextRotation returns the time of the first time-based rotation after t, or the zero time
+// for rotation types without one
+func nextRotation(t time.Time, rotation conf.RotationType, rotationDay time.Weekday) time.Time {
+	year, month, day := t.Date()
+	switch rotation {
+	case conf.RotationDaily:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+	case conf.RotationWeekly:
+		days := (int(rotationDay) - int(t.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return time.Date(year, month, day+days, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func TestNextRotation(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday afternoon
+	wednesday := time.Date(2025, 5, 14, 15, 30, 0, 0, time.UTC)
+	sundayEvening := time.Date(2025, 5, 18, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		rotation conf.RotationType
+		day      time.Weekday
+		expected time.Time
+	}{
+		{"daily", wednesday, conf.RotationDaily, time.Sunday, time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"weekly", wednesday, conf.RotationWeekly, time.Sunday, time.Date(2025, 5, 18, 0, 0, 0, 0, time.UTC)},
+		{"weekly on the rotation day", sundayEvening, conf.RotationWeekly, time.Sunday, time.Date(2025, 5, 25, 0, 0, 0, 0, time.UTC)},
+		{"weekly later in the week", wednesday, conf.RotationWeekly, time.Monday, time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{"size", wednesday, conf.RotationSize, time.Sunday, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, nextRotation(tt.from, tt.rotation, tt.day))
+		})
+	}
+}
+
+func TestRotatingFileRotatesDaily(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "service.log")
+
+	clock := time.Date(2025, 5, 14, 23, 59, 0, 0, time.Local)
+	lj := &lumberjack.Logger{Filename: logPath, MaxSize: 1, MaxBackups: 1, Compress: true}
+	file := newRotatingFile(lj, conf.RotationDaily, time.Sunday)
+	file.now = func() time.Time { return clock }
+	file.next = nextRotation(clock, conf.RotationDaily, time.Sunday)
+	t.Cleanup(func() { _ = file.Close() })
+
+	_, err := file.Write([]byte("first day\n"))
+	require.NoError(t, err)
+
+	clock = clock.Add(2 * time.Minute)
+	_, err = file.Write([]byte("second day\n"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, "second day\n", string(data), "the file is rotated at midnight")
+
+	require.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(filepath.Join(dir, "service-*.log.gz"))
+		return len(matches) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the rotated file is compressed")
+}
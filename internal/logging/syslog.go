@@ -0,0 +1,228 @@
+package logging
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// syslogFacilities maps the facility names of conf.SyslogFacilities to their codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSDID is the structured data ID carrying the attributes of a record. The project has
+// no private enterprise number, so the number reserved for documentation (RFC 5612) is used.
+const syslogSDID = "birdnet@32473"
+
+// syslogDefaultTag is the application name of messages when no tag is configured
+const syslogDefaultTag = "birdnet-go"
+
+// syslogSink sends records to a syslog server as RFC 5424 messages over UDP, TCP or TLS
+type syslogSink struct {
+	conn     lazyConn
+	framed   bool // octet-counting framing of stream transports (RFC 6587)
+	facility int
+	hostname string
+	tag      string
+	procID   string
+}
+
+// newSyslogSink creates a syslog sink for the syslog settings. TLS connections use the CA
+// bundle and client certificate of the outbound TLS settings.
+func newSyslogSink(settings *conf.SyslogSettings, outboundTLS *conf.OutboundTLSSettings) (*syslogSink, error) {
+	facility, ok := syslogFacilities[strings.ToLower(settings.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", settings.Facility)
+	}
+
+	address := settings.Address
+	dialer := &net.Dialer{Timeout: outputWriteTimeout}
+	var dial func() (net.Conn, error)
+	switch network := strings.ToLower(settings.Network); network {
+	case "udp", "tcp":
+		dial = func() (net.Conn, error) { return dialer.Dial(network, address) }
+	case "tls":
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid syslog address %q: %w", address, err)
+		}
+		tlsConfig, err := outboundTLS.ClientTLSConfig(host, settings.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		}
+		dial = func() (net.Conn, error) { return tls.DialWithDialer(dialer, "tcp", address, tlsConfig) }
+	default:
+		return nil, fmt.Errorf("unknown syslog network %q", settings.Network)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	tag := settings.Tag
+	if tag == "" {
+		tag = syslogDefaultTag
+	}
+
+	return &syslogSink{
+		conn:     lazyConn{dial: dial},
+		framed:   !strings.EqualFold(settings.Network, "udp"),
+		facility: facility,
+		hostname: syslogHeaderField(hostname, 255),
+		tag:      syslogHeaderField(tag, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// send writes the message of an entry to the syslog server
+func (s *syslogSink) send(entry *Entry) error {
+	message := s.format(entry)
+	if s.framed {
+		message = strconv.Itoa(len(message)) + " " + message
+	}
+	return s.conn.write([]byte(message))
+}
+
+// Close closes the connection to the syslog server
+func (s *syslogSink) Close() error {
+	return s.conn.Close()
+}
+
+// format returns the RFC 5424 message of an entry. The service is the message ID and the
+// attributes are structured data.
+func (s *syslogSink) format(entry *Entry) string {
+	timestamp := "-"
+	if !entry.Time.IsZero() {
+		timestamp = entry.Time.Format(time.RFC3339)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		s.facility*8+syslogSeverity(entry.Level),
+		timestamp, s.hostname, s.tag, s.procID,
+		syslogHeaderField(entry.Service, 32))
+	writeStructuredData(&b, entry.Attrs)
+	if entry.Message != "" {
+		b.WriteByte(' ')
+		b.WriteString(entry.Message)
+	}
+	return b.String()
+}
+
+// writeStructuredData writes the attributes of an entry as an RFC 5424 structured data
+// element with its parameters sorted by name, or the nil value without attributes
+func writeStructuredData(b *strings.Builder, attrs map[string]any) {
+	if len(attrs) == 0 {
+		b.WriteByte('-')
+		return
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	b.WriteString("[" + syslogSDID)
+	for _, name := range names {
+		paramName := syslogParamName(name)
+		if paramName == "" {
+			continue
+		}
+		b.WriteString(" " + paramName + `="`)
+		for _, r := range attrString(attrs[name]) {
+			if r == '"' || r == '\\' || r == ']' {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('"')
+	}
+	b.WriteByte(']')
+}
+
+// attrString returns an attribute value as text, values other than strings as JSON
+func attrString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// syslogParamName returns the structured data parameter name of an attribute, at most 32
+// printable ASCII characters other than '=', ' ', ']' and '"'
+func syslogParamName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if b.Len() == 32 {
+			break
+		}
+		if r > ' ' && r < 127 && r != '=' && r != ']' && r != '"' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// syslogHeaderField returns a header field of at most maxLen printable ASCII characters,
+// or the nil value "-" when it is empty
+func syslogHeaderField(value string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range value {
+		if b.Len() == maxLen {
+			break
+		}
+		if r > ' ' && r < 127 {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+// syslogSeverity returns the syslog severity of a level name, informational for unknown levels
+func syslogSeverity(levelName string) int {
+	level, ok := parseLevelName(levelName)
+	switch {
+	case !ok:
+		return 6
+	case level >= LevelFatal:
+		return 2 // critical
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
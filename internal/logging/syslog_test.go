@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestSyslogFormat(t *testing.T) {
+	t.Parallel()
+
+	sink := &syslogSink{facility: 16, hostname: "birdnet", tag: "birdnet-go", procID: "42"}
+	entry := &Entry{
+		Time:    time.Date(2025, 5, 14, 6, 30, 0, 0, time.UTC),
+		Level:   "WARN",
+		Service: "mqtt",
+		Message: "Publish failed",
+		Attrs:   map[string]any{"topic": `birdnet/"detections"]`, "retries": float64(3), "error": nil},
+	}
+
+	assert.Equal(t,
+		`<132>1 2025-05-14T06:30:00Z birdnet birdnet-go 42 mqtt [birdnet@32473 error="" retries="3" topic="birdnet/\"detections\"\]"] Publish failed`,
+		sink.format(entry))
+
+	assert.Equal(t, "<135>1 - birdnet birdnet-go 42 - - debug message",
+		sink.format(&Entry{Level: "DEBUG", Message: "debug message"}), "missing fields use the nil value")
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{"FATAL": 2, "ERROR": 3, "WARN": 4, "INFO": 6, "DEBUG": 7, "TRACE": 7, "": 6}
+	for level, severity := range tests {
+		assert.Equal(t, severity, syslogSeverity(level), level)
+	}
+}
+
+func TestSyslogOutputTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	settings := &conf.Settings{}
+	settings.Logging.Syslog = conf.SyslogSettings{
+		Enabled:  true,
+		Network:  "tcp",
+		Address:  listener.Addr().String(),
+		Facility: "daemon",
+		Tag:      "birdnet-go",
+		Level:    "info",
+	}
+	require.NoError(t, ConfigureOutputs(settings))
+	t.Cleanup(func() { _ = outputs.replace(nil) })
+
+	_, _ = outputs.Write([]byte(`{"time":"2025-05-14T06:30:00Z","level":"DEBUG","msg":"below the output level","service":"mqtt"}` + "\n"))
+	_, _ = outputs.Write([]byte(`{"time":"2025-05-14T06:30:01Z","level":"ERROR","msg":"Connection lost","service":"mqtt","broker":"tcp://localhost:1883"}` + "\n"))
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	// Messages are framed by octet counting
+	reader := bufio.NewReader(conn)
+	length, err := reader.ReadString(' ')
+	require.NoError(t, err)
+	size, err := strconv.Atoi(strings.TrimSpace(length))
+	require.NoError(t, err)
+	message := make([]byte, size)
+	_, err = io.ReadFull(reader, message)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(message), "<27>1 2025-05-14T06:30:01Z "), string(message))
+	assert.Contains(t, string(message), ` birdnet-go `)
+	assert.True(t, strings.HasSuffix(string(message), ` mqtt [birdnet@32473 broker="tcp://localhost:1883"] Connection lost`), string(message))
+
+	// Disabling the output closes the connection
+	require.NoError(t, ConfigureOutputs(&conf.Settings{}))
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestSyslogOutputUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	sink, err := newSyslogSink(&conf.SyslogSettings{
+		Enabled:  true,
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: "local0",
+	}, &conf.OutboundTLSSettings{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sink.Close() })
+
+	require.NoError(t, sink.send(&Entry{Level: "INFO", Service: "birdweather", Message: "Uploaded"}))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 2048)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	message := string(buf[:n])
+	assert.True(t, strings.HasPrefix(message, "<134>1 - "), "datagrams are not framed: %s", message)
+	assert.True(t, strings.HasSuffix(message, " birdweather - Uploaded"), message)
+	assert.Contains(t, message, " "+syslogDefaultTag+" ", "the default tag is used without a configured one")
+}
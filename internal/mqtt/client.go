@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/eclipse/paho.golang/autopaho"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
@@ -36,6 +37,11 @@ type client struct {
 	reconnectStop   chan struct{}
 	metrics         *metrics.MQTTMetrics
 	controlChan     chan string // Channel for control signals
+
+	// MQTT 5 connection, used instead of internalClient when config.V5.Enabled is set
+	v5Conn      *autopaho.ConnectionManager
+	v5Cancel    context.CancelFunc // stops the reconnect loop of v5Conn
+	v5Connected bool               // true while the MQTT 5 connection is up
 }
 
 // NewClient creates a new MQTT client with the provided configuration.
@@ -56,6 +62,15 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 	config.TLS.CACert = settings.Realtime.MQTT.TLS.CACert
 	config.TLS.ClientCert = settings.Realtime.MQTT.TLS.ClientCert
 	config.TLS.ClientKey = settings.Realtime.MQTT.TLS.ClientKey
+	config.TLS.Outbound = settings.OutboundTLS
+
+	// Configure MQTT 5 features
+	config.V5.Enabled = settings.Realtime.MQTT.V5.Enabled
+	config.V5.MessageExpiry = time.Duration(settings.Realtime.MQTT.V5.MessageExpiry) * time.Second
+	config.V5.ContentType = settings.Realtime.MQTT.V5.ContentType
+	config.V5.StationID = settings.Main.Name
+	config.V5.CommandTopic = settings.Realtime.MQTT.V5.CommandTopic
+	config.V5.SharedGroup = settings.Realtime.MQTT.V5.SharedGroup
 
 	// Auto-detect TLS from broker URL scheme
 	if strings.HasPrefix(config.Broker, "ssl://") || strings.HasPrefix(config.Broker, "tls://") || strings.HasPrefix(config.Broker, "mqtts://") {
@@ -81,6 +96,7 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 		"debug", config.Debug,
 		"tls_enabled", config.TLS.Enabled,
 		"tls_skip_verify", config.TLS.InsecureSkipVerify,
+		"mqtt5", config.V5.Enabled,
 	)
 
 	return &client{
@@ -130,6 +146,9 @@ func (c *client) SetDebug(debug bool) {
 // It holds the mutex only while checking state and creating the client instance,
 // releasing it before blocking network operations.
 func (c *client) Connect(ctx context.Context) error {
+	if c.config.V5.Enabled {
+		return c.connectV5(ctx)
+	}
 	return c.connectWithOptions(ctx, false)
 }
 
@@ -279,6 +298,10 @@ func (c *client) Publish(ctx context.Context, topic, payload string) error {
 		return err
 	}
 
+	if c.config.V5.Enabled {
+		return c.publishV5(ctx, topic, payload)
+	}
+
 	c.mu.Lock() // Lock to safely read internalClient and check connection status
 	// Directly check the internal client state while holding the lock
 	// Avoids calling IsConnected() which would re-lock.
@@ -362,6 +385,9 @@ func (c *client) IsConnected() bool {
 	// RLock is sufficient for read-only check
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	if c.config.V5.Enabled {
+		return c.v5Conn != nil && c.v5Connected
+	}
 	connected := c.internalClient != nil && c.internalClient.IsConnected()
 	// Reduce log noise by removing debug log from here
 	// mqttLogger.Debug("Checking MQTT connection status", "is_connected", connected)
@@ -657,6 +683,11 @@ func (c *client) Disconnect() {
 
 // disconnectWithTimeout closes the connection with a specific timeout
 func (c *client) disconnectWithTimeout(timeout time.Duration) {
+	if c.config.V5.Enabled {
+		c.disconnectV5(timeout)
+		return
+	}
+
 	c.mu.Lock() // Lock required to safely access reconnectStop, reconnectTimer, internalClient
 
 	logger := mqttLogger.With("broker", c.config.Broker, "client_id", c.config.ClientID)
@@ -828,6 +859,17 @@ func (c *client) createTLSConfig() (*tls.Config, error) {
 		InsecureSkipVerify: c.config.TLS.InsecureSkipVerify, // #nosec G402 -- InsecureSkipVerify is controlled by user configuration for self-signed certificates
 	}
 
+	// Start from the shared outbound CA bundle and client certificate, the settings of the
+	// broker below take precedence
+	shared, err := c.config.TLS.Outbound.ClientTLSConfig(hostname, false)
+	if err != nil {
+		return nil, err
+	}
+	if shared != nil {
+		tlsConfig.RootCAs = shared.RootCAs
+		tlsConfig.Certificates = shared.Certificates
+	}
+
 	// Load CA certificate if provided
 	if c.config.TLS.CACert != "" {
 		// Check if file exists for better error message
@@ -5,7 +5,15 @@ package mqtt
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -545,3 +553,76 @@ func BenchmarkTLSConnection(b *testing.B) {
 		}
 	})
 }
+
+// writeTestCA writes a self-signed CA certificate in PEM format and returns its path
+func writeTestCA(t *testing.T, dir, name string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	path := filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	return path
+}
+
+// TestTLSConfigUsesOutboundSettings verifies that the shared outbound CA bundle is used when
+// the broker has no CA certificate of its own
+func TestTLSConfigUsesOutboundSettings(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	sharedCA := writeTestCA(t, dir, "shared")
+	brokerCA := writeTestCA(t, dir, "broker")
+
+	newTestClient := func(tlsConfig TLSConfig) *client {
+		return &client{config: Config{Broker: "tls://broker.local:8883", TLS: tlsConfig}}
+	}
+
+	tlsConfig, err := newTestClient(TLSConfig{Enabled: true}).createTLSConfig()
+	if err != nil {
+		t.Fatalf("createTLSConfig() error = %v", err)
+	}
+	if tlsConfig.RootCAs != nil {
+		t.Error("Expected system roots without CA certificates")
+	}
+
+	tlsConfig, err = newTestClient(TLSConfig{Enabled: true, Outbound: conf.OutboundTLSSettings{CACert: sharedCA}}).createTLSConfig()
+	if err != nil {
+		t.Fatalf("createTLSConfig() error = %v", err)
+	}
+	if tlsConfig.RootCAs == nil || tlsConfig.ServerName != "broker.local" {
+		t.Errorf("Expected shared CA bundle for broker.local, got RootCAs=%v ServerName=%q", tlsConfig.RootCAs, tlsConfig.ServerName)
+	}
+
+	// The CA certificate of the broker takes precedence over the shared bundle
+	tlsConfig, err = newTestClient(TLSConfig{Enabled: true, CACert: brokerCA, Outbound: conf.OutboundTLSSettings{CACert: sharedCA}}).createTLSConfig()
+	if err != nil {
+		t.Fatalf("createTLSConfig() error = %v", err)
+	}
+	brokerPool := x509.NewCertPool()
+	brokerPEM, _ := os.ReadFile(brokerCA)
+	brokerPool.AppendCertsFromPEM(brokerPEM)
+	if !tlsConfig.RootCAs.Equal(brokerPool) {
+		t.Error("Expected only the broker CA certificate to be trusted")
+	}
+
+	_, err = newTestClient(TLSConfig{Enabled: true, Outbound: conf.OutboundTLSSettings{CACert: filepath.Join(dir, "missing.pem")}}).createTLSConfig()
+	if err == nil {
+		t.Error("Expected error for missing shared CA bundle")
+	}
+}
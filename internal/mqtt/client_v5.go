@@ -0,0 +1,402 @@
+// client_v5.go: MQTT 5 connection and publishing for the MQTT client
+package mqtt
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// v5KeepAlive is the keepalive period in seconds of MQTT 5 connections, same as for MQTT 3.1.1
+const v5KeepAlive = 30
+
+// commandSubscribeTimeout limits how long subscribing to the command topic may take
+const commandSubscribeTimeout = 10 * time.Second
+
+// allowedCommands are the control signals accepted on the command topic. Other control
+// signals reconfigure the station from its own settings and are not exposed to the broker.
+var allowedCommands = map[string]bool{
+	"reload_birdnet":       true,
+	"rebuild_range_filter": true,
+}
+
+// commandTopicFilter returns the topic filter to subscribe to the command topic with. With
+// a shared group the filter is $share/<group>/<topic>, so the broker delivers each command
+// to one subscriber of the group instead of to every station of an HA cluster.
+func commandTopicFilter(topic, group string) string {
+	if group == "" {
+		return topic
+	}
+	return "$share/" + group + "/" + topic
+}
+
+// connectV5 connects to the broker with MQTT 5. Once connected, the connection manager
+// reconnects on its own after connection loss until Disconnect is called.
+func (c *client) connectV5(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		mqttLogger.Warn("Connect context already cancelled", "error", err)
+		return err
+	}
+
+	logger := mqttLogger.With("broker", c.config.Broker, "client_id", c.config.ClientID, "protocol", "mqtt5")
+	logger.Info("Attempting to connect to MQTT broker")
+
+	c.mu.Lock()
+	if err := c.checkConnectionCooldownLocked(logger); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	oldConn, oldCancel := c.v5Conn, c.v5Cancel
+	c.v5Conn, c.v5Cancel, c.v5Connected = nil, nil, false
+	c.mu.Unlock()
+
+	// Close the previous connection outside the lock
+	if oldConn != nil {
+		logger.Info("Closing existing MQTT 5 connection before reconnecting")
+		stopV5Connection(oldConn, oldCancel, GracefulDisconnectTimeout)
+	}
+
+	if err := c.performDNSResolution(ctx, logger); err != nil {
+		return err
+	}
+
+	connectErrs := make(chan error, 1)
+	cfg, err := c.configureV5Options(logger, connectErrs)
+	if err != nil {
+		return err
+	}
+
+	// The connection manager outlives ctx, it is stopped by Disconnect
+	managerCtx, cancel := context.WithCancel(context.Background())
+	conn, err := autopaho.NewConnection(managerCtx, cfg)
+	if err != nil {
+		cancel()
+		return errors.New(err).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("client_id", c.config.ClientID).
+			Context("operation", "mqtt5_connect").
+			Build()
+	}
+
+	awaitCtx, awaitCancel := context.WithTimeout(ctx, c.config.ConnectTimeout+ConnectTimeoutGrace)
+	defer awaitCancel()
+	connectErr := conn.AwaitConnection(awaitCtx)
+
+	c.mu.Lock()
+	c.lastConnAttempt = time.Now()
+	c.mu.Unlock()
+
+	if connectErr != nil {
+		stopV5Connection(conn, cancel, CancelDisconnectTimeout)
+		c.metrics.UpdateConnectionStatus(false)
+
+		if ctx.Err() != nil {
+			logger.Error("Context cancelled during MQTT connection wait", "error", ctx.Err())
+			return ctx.Err()
+		}
+		// Report the reason of the last failed attempt, such as a refused CONNACK
+		select {
+		case err := <-connectErrs:
+			connectErr = err
+		default:
+		}
+		logger.Error("MQTT connection failed", "error", connectErr)
+		return errors.New(connectErr).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("client_id", c.config.ClientID).
+			Context("operation", "mqtt5_connect").
+			Context("connect_timeout", c.config.ConnectTimeout).
+			Build()
+	}
+
+	c.mu.Lock()
+	c.v5Conn, c.v5Cancel = conn, cancel
+	c.mu.Unlock()
+
+	logger.Info("Successfully connected to MQTT broker")
+	return nil
+}
+
+// configureV5Options creates the MQTT 5 connection configuration. Errors of failed
+// connection attempts are sent to connectErrs without blocking.
+func (c *client) configureV5Options(logger *slog.Logger, connectErrs chan<- error) (autopaho.ClientConfig, error) {
+	brokerURL, err := url.Parse(c.config.Broker)
+	if err != nil {
+		logger.Error("Invalid broker URL", "error", err)
+		return autopaho.ClientConfig{}, errors.New(err).
+			Component("mqtt").
+			Category(errors.CategoryConfiguration).
+			Context("broker", c.config.Broker).
+			Context("client_id", c.config.ClientID).
+			Context("operation", "parse_broker_url").
+			Build()
+	}
+
+	cfg := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{brokerURL},
+		KeepAlive:                     v5KeepAlive,
+		CleanStartOnInitialConnection: true,
+		ReconnectBackoff:              autopaho.NewConstantBackoff(c.config.ReconnectDelay),
+		ConnectTimeout:                c.config.ConnectTimeout,
+		OnConnectionUp:                c.onConnectV5,
+		OnConnectionDown:              c.onConnectionLostV5,
+		OnConnectError: func(err error) {
+			select {
+			case connectErrs <- err:
+			default:
+			}
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID: c.config.ClientID,
+		},
+	}
+	if c.config.V5.CommandTopic != "" {
+		cfg.OnPublishReceived = []func(paho.PublishReceived) (bool, error){c.onCommandV5}
+	}
+	if c.config.Username != "" {
+		cfg.ConnectUsername = c.config.Username
+		cfg.ConnectPassword = []byte(c.config.Password) // Do not log the password
+	}
+
+	if c.config.TLS.Enabled {
+		tlsConfig, err := c.createTLSConfig()
+		if err != nil {
+			logger.Error("Failed to create TLS configuration", "error", err)
+			return autopaho.ClientConfig{}, errors.New(err).
+				Component("mqtt").
+				Category(errors.CategoryConfiguration).
+				Context("broker", c.config.Broker).
+				Context("client_id", c.config.ClientID).
+				Context("operation", "create_tls_config").
+				Build()
+		}
+		cfg.TlsCfg = tlsConfig
+	}
+
+	return cfg, nil
+}
+
+// onConnectV5 is called by the connection manager when the connection is up, including reconnects
+func (c *client) onConnectV5(cm *autopaho.ConnectionManager, connack *paho.Connack) {
+	c.mu.Lock()
+	c.v5Connected = true
+	c.mu.Unlock()
+
+	mqttLogger.Info("Connected to MQTT broker",
+		"broker", c.config.Broker,
+		"client_id", c.config.ClientID,
+		"protocol", "mqtt5",
+		"session_present", connack.SessionPresent)
+	c.metrics.UpdateConnectionStatus(true)
+
+	// The session starts clean, so subscribe again on every connection
+	if c.config.V5.CommandTopic != "" {
+		go c.subscribeCommandsV5(cm)
+	}
+}
+
+// subscribeCommandsV5 subscribes to the command topic, as a shared subscription when a
+// shared group is configured
+func (c *client) subscribeCommandsV5(cm *autopaho.ConnectionManager) {
+	filter := commandTopicFilter(c.config.V5.CommandTopic, c.config.V5.SharedGroup)
+	logger := mqttLogger.With("broker", c.config.Broker, "client_id", c.config.ClientID, "topic", filter, "protocol", "mqtt5")
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandSubscribeTimeout)
+	defer cancel()
+
+	suback, err := cm.Subscribe(ctx, &paho.Subscribe{
+		Subscriptions: []paho.SubscribeOptions{{Topic: filter, QoS: defaultQoS}},
+	})
+	if err == nil && len(suback.Reasons) > 0 && suback.Reasons[0] >= 0x80 {
+		err = errors.Newf("broker refused subscription with reason code 0x%02x", suback.Reasons[0]).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Build()
+	}
+	if err != nil {
+		logger.Error("Failed to subscribe to command topic", "error", err, "operation", "subscribe_commands")
+		c.metrics.IncrementErrorsWithCategory("mqtt-subscribe", "subscribe_commands")
+		return
+	}
+	logger.Info("Subscribed to command topic", "shared_group", c.config.V5.SharedGroup)
+}
+
+// onCommandV5 forwards commands received on the command topic to the control channel.
+// Unknown commands are ignored, and commands are dropped while the control channel is
+// unset or full.
+func (c *client) onCommandV5(pr paho.PublishReceived) (bool, error) {
+	command := strings.TrimSpace(string(pr.Packet.Payload))
+	logger := mqttLogger.With("topic", pr.Packet.Topic, "command", command, "protocol", "mqtt5")
+
+	if !allowedCommands[command] {
+		logger.Warn("Ignoring unknown command")
+		return true, nil
+	}
+
+	c.mu.RLock()
+	controlChan := c.controlChan
+	c.mu.RUnlock()
+
+	if controlChan == nil {
+		logger.Warn("Dropping command, control channel is not set")
+		return true, nil
+	}
+	select {
+	case controlChan <- command:
+		logger.Info("Received command")
+	default:
+		logger.Warn("Dropping command, control channel is full")
+	}
+	return true, nil
+}
+
+// onConnectionLostV5 is called by the connection manager when the connection drops.
+// It returns true to let the connection manager reconnect.
+func (c *client) onConnectionLostV5() bool {
+	c.mu.Lock()
+	c.v5Connected = false
+	c.mu.Unlock()
+
+	enhancedErr := errors.Newf("connection to MQTT broker lost").
+		Component("mqtt").
+		Category(errors.CategoryMQTTConnection).
+		Context("broker", c.config.Broker).
+		Context("client_id", c.config.ClientID).
+		Context("operation", "connection_lost").
+		Build()
+
+	mqttLogger.Error("Connection to MQTT broker lost, reconnecting",
+		"broker", c.config.Broker,
+		"client_id", c.config.ClientID,
+		"protocol", "mqtt5",
+		"reconnect_delay", c.config.ReconnectDelay)
+	c.metrics.UpdateConnectionStatus(false)
+	c.metrics.IncrementErrorsWithCategory("mqtt-connection", "connection_lost")
+	notification.NotifyIntegrationFailure("MQTT", enhancedErr)
+	return true
+}
+
+// publishV5 publishes a message with the MQTT 5 properties of the client configuration
+func (c *client) publishV5(ctx context.Context, topic, payload string) error {
+	c.mu.RLock()
+	conn := c.v5Conn
+	connected := c.v5Connected
+	currentRetain := c.config.Retain
+	c.mu.RUnlock()
+
+	if conn == nil || !connected {
+		mqttLogger.Warn("Publish failed: client is not connected")
+		return errors.Newf("not connected to MQTT broker").
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("client_id", c.config.ClientID).
+			Context("topic", topic).
+			Context("operation", "publish_not_connected").
+			Build()
+	}
+
+	logger := mqttLogger.With("topic", topic, "qos", defaultQoS, "retain", currentRetain, "protocol", "mqtt5")
+	timer := c.metrics.StartPublishTimer()
+	defer timer.ObserveDuration()
+
+	logger.Debug("Attempting to publish message", "payload_size", len(payload))
+
+	pubCtx, pubCancel := context.WithTimeout(ctx, c.config.PublishTimeout)
+	defer pubCancel()
+
+	if _, err := conn.Publish(pubCtx, c.newV5Publish(topic, payload, currentRetain)); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			logger.Error("Context was cancelled during publish wait", "error", ctxErr)
+			return ctxErr
+		}
+
+		operation := "publish_error"
+		if pubCtx.Err() != nil {
+			operation = "publish_timeout"
+		}
+		logger.Error("MQTT publish failed", "error", err, "operation", operation)
+		c.metrics.IncrementErrorsWithCategory("mqtt-publish", operation)
+		return errors.New(err).
+			Component("mqtt").
+			Category(errors.CategoryMQTTPublish).
+			Context("broker", c.config.Broker).
+			Context("client_id", c.config.ClientID).
+			Context("topic", topic).
+			Context("payload_size", len(payload)).
+			Context("qos", defaultQoS).
+			Context("retain", currentRetain).
+			Context("publish_timeout", c.config.PublishTimeout).
+			Context("operation", operation).
+			Build()
+	}
+
+	logger.Debug("Publish completed successfully")
+	c.metrics.IncrementMessagesDelivered()
+	c.metrics.ObserveMessageSize(float64(len(payload)))
+	return nil
+}
+
+// newV5Publish creates a PUBLISH packet carrying the content type, message expiry and
+// the user properties with the payload schema version and the station name
+func (c *client) newV5Publish(topic, payload string, retain bool) *paho.Publish {
+	properties := &paho.PublishProperties{
+		ContentType: c.config.V5.ContentType,
+	}
+	if c.config.V5.MessageExpiry > 0 {
+		expiry := uint32(c.config.V5.MessageExpiry / time.Second) // #nosec G115 -- expiry is validated to fit uint32
+		properties.MessageExpiry = &expiry
+	}
+	properties.User.Add(UserPropertySchemaVersion, PayloadSchemaVersion)
+	if c.config.V5.StationID != "" {
+		properties.User.Add(UserPropertyStationID, c.config.V5.StationID)
+	}
+
+	return &paho.Publish{
+		QoS:        defaultQoS,
+		Retain:     retain,
+		Topic:      topic,
+		Payload:    []byte(payload),
+		Properties: properties,
+	}
+}
+
+// disconnectV5 closes the MQTT 5 connection and stops reconnecting
+func (c *client) disconnectV5(timeout time.Duration) {
+	c.mu.Lock()
+	conn, cancel := c.v5Conn, c.v5Cancel
+	c.v5Conn, c.v5Cancel, c.v5Connected = nil, nil, false
+	c.mu.Unlock()
+
+	logger := mqttLogger.With("broker", c.config.Broker, "client_id", c.config.ClientID, "protocol", "mqtt5")
+	if conn == nil {
+		logger.Debug("Client was not initialized when disconnect called")
+	} else {
+		logger.Info("Disconnecting from MQTT broker")
+		stopV5Connection(conn, cancel, timeout)
+	}
+	c.metrics.UpdateConnectionStatus(false)
+}
+
+// stopV5Connection sends DISCONNECT if the connection is up and waits up to timeout for
+// the connection manager to shut down
+func stopV5Connection(conn *autopaho.ConnectionManager, cancel context.CancelFunc, timeout time.Duration) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), timeout)
+	defer ctxCancel()
+
+	if err := conn.Disconnect(ctx); err != nil {
+		mqttLogger.Debug("MQTT 5 connection manager did not shut down in time", "timeout", timeout, "error", err)
+	}
+	cancel()
+}
@@ -0,0 +1,356 @@
+// client_v5_test.go: tests for MQTT 5 connections, publish properties and the command topic
+
+package mqtt
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/observability"
+)
+
+// testCommandTopic is the command topic of MQTT 5 test clients
+const testCommandTopic = "birdnet/command"
+
+// fakeV5Broker is a minimal MQTT 5 broker that accepts one connection, acknowledges
+// QoS 1 messages and subscriptions and records the CONNECT, PUBLISH and SUBSCRIBE packets
+// it receives. After a subscription it sends its commands to the client.
+type fakeV5Broker struct {
+	listener   net.Listener
+	connects   chan *packets.Connect
+	published  chan *packets.Publish
+	subscribes chan *packets.Subscribe
+	commands   []string
+}
+
+// newFakeV5Broker starts a fake broker on a local port that sends commands to subscribers
+func newFakeV5Broker(t *testing.T, commands ...string) *fakeV5Broker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	broker := &fakeV5Broker{
+		listener:   listener,
+		connects:   make(chan *packets.Connect, 1),
+		published:  make(chan *packets.Publish, 10),
+		subscribes: make(chan *packets.Subscribe, 1),
+		commands:   commands,
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go broker.serve()
+	return broker
+}
+
+// url returns the broker URL of the fake broker
+func (b *fakeV5Broker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *fakeV5Broker) serve() {
+	conn, err := b.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	for {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		var reply *packets.ControlPacket
+		switch content := packet.Content.(type) {
+		case *packets.Connect:
+			b.connects <- content
+			reply = packets.NewControlPacket(packets.CONNACK)
+		case *packets.Publish:
+			b.published <- content
+			reply = packets.NewControlPacket(packets.PUBACK)
+			reply.Content.(*packets.Puback).PacketID = content.PacketID
+		case *packets.Subscribe:
+			b.subscribes <- content
+			reply = packets.NewControlPacket(packets.SUBACK)
+			suback := reply.Content.(*packets.Suback)
+			suback.PacketID = content.PacketID
+			suback.Reasons = []byte{defaultQoS}
+			if _, err := reply.WriteTo(conn); err != nil {
+				return
+			}
+			if !b.sendCommands(conn) {
+				return
+			}
+			continue
+		case *packets.Pingreq:
+			reply = packets.NewControlPacket(packets.PINGRESP)
+		case *packets.Disconnect:
+			return
+		default:
+			continue
+		}
+		if _, err := reply.WriteTo(conn); err != nil {
+			return
+		}
+	}
+}
+
+// sendCommands publishes the commands of the broker on testCommandTopic with QoS 0
+func (b *fakeV5Broker) sendCommands(conn net.Conn) bool {
+	for _, command := range b.commands {
+		publish := packets.NewControlPacket(packets.PUBLISH)
+		content := publish.Content.(*packets.Publish)
+		content.Topic = testCommandTopic
+		content.Payload = []byte(command)
+		if _, err := publish.WriteTo(conn); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// newV5TestClient creates an MQTT 5 client for broker
+func newV5TestClient(t *testing.T, broker string) *client {
+	t.Helper()
+	settings := &conf.Settings{}
+	settings.Main.Name = sanitizeClientID(t.Name())
+	settings.Realtime.MQTT.Broker = broker
+	settings.Realtime.MQTT.Topic = testTopic
+	settings.Realtime.MQTT.V5 = conf.MQTTV5Settings{Enabled: true, MessageExpiry: 600, ContentType: "application/json"}
+
+	metrics, err := observability.NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+	c, err := NewClient(settings, metrics)
+	if err != nil {
+		t.Fatalf("Failed to create MQTT client: %v", err)
+	}
+	return c.(*client)
+}
+
+func TestNewV5Publish(t *testing.T) {
+	t.Parallel()
+
+	c := &client{config: DefaultConfig()}
+	c.config.V5 = V5Config{
+		Enabled:       true,
+		MessageExpiry: 10 * time.Minute,
+		ContentType:   "application/json",
+		StationID:     "backyard",
+	}
+
+	publish := c.newV5Publish("birdnet", `{"a":1}`, true)
+	if publish.Topic != "birdnet" || string(publish.Payload) != `{"a":1}` || !publish.Retain || publish.QoS != defaultQoS {
+		t.Errorf("Unexpected publish packet: %+v", publish)
+	}
+	if publish.Properties.ContentType != "application/json" {
+		t.Errorf("Expected content type application/json, got %q", publish.Properties.ContentType)
+	}
+	if publish.Properties.MessageExpiry == nil || *publish.Properties.MessageExpiry != 600 {
+		t.Errorf("Expected message expiry of 600 seconds, got %v", publish.Properties.MessageExpiry)
+	}
+	user := map[string]string{}
+	for _, property := range publish.Properties.User {
+		user[property.Key] = property.Value
+	}
+	if user[UserPropertySchemaVersion] != PayloadSchemaVersion || user[UserPropertyStationID] != "backyard" {
+		t.Errorf("Unexpected user properties: %v", publish.Properties.User)
+	}
+
+	// Without expiry the property is left out
+	c.config.V5.MessageExpiry = 0
+	if publish := c.newV5Publish("birdnet", "{}", false); publish.Properties.MessageExpiry != nil {
+		t.Errorf("Expected no message expiry, got %d", *publish.Properties.MessageExpiry)
+	}
+}
+
+func TestV5ClientPublish(t *testing.T) {
+	t.Parallel()
+
+	broker := newFakeV5Broker(t)
+	c := newV5TestClient(t, broker.url())
+	defer c.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if !c.IsConnected() {
+		t.Fatal("Expected client to be connected")
+	}
+
+	select {
+	case connect := <-broker.connects:
+		if connect.ProtocolVersion != 5 {
+			t.Errorf("Expected protocol version 5, got %d", connect.ProtocolVersion)
+		}
+		if connect.ClientID != c.config.ClientID {
+			t.Errorf("Expected client ID %q, got %q", c.config.ClientID, connect.ClientID)
+		}
+	case <-ctx.Done():
+		t.Fatal("Broker did not receive CONNECT")
+	}
+
+	if err := c.Publish(ctx, testTopic, `{"commonName":"Whooper Swan"}`); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	select {
+	case publish := <-broker.published:
+		if publish.Topic != testTopic {
+			t.Errorf("Expected topic %q, got %q", testTopic, publish.Topic)
+		}
+		if publish.Properties.ContentType != "application/json" {
+			t.Errorf("Expected content type application/json, got %q", publish.Properties.ContentType)
+		}
+		if publish.Properties.MessageExpiry == nil || *publish.Properties.MessageExpiry != 600 {
+			t.Errorf("Expected message expiry of 600 seconds, got %v", publish.Properties.MessageExpiry)
+		}
+		found := false
+		for _, property := range publish.Properties.User {
+			if property.Key == UserPropertySchemaVersion && property.Value == PayloadSchemaVersion {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected schema version user property, got %v", publish.Properties.User)
+		}
+	case <-ctx.Done():
+		t.Fatal("Broker did not receive PUBLISH")
+	}
+
+	c.Disconnect()
+	if c.IsConnected() {
+		t.Error("Expected client to be disconnected after Disconnect")
+	}
+}
+
+func TestV5ClientPublishNotConnected(t *testing.T) {
+	t.Parallel()
+
+	c := newV5TestClient(t, "tcp://127.0.0.1:1883")
+	err := c.Publish(context.Background(), testTopic, "{}")
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("Expected not connected error, got %v", err)
+	}
+}
+
+func TestV5ClientConnectFailure(t *testing.T) {
+	t.Parallel()
+
+	// Reserve a port and close it so that nothing is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	broker := "tcp://" + listener.Addr().String()
+	_ = listener.Close()
+
+	c := newV5TestClient(t, broker)
+	c.config.ConnectTimeout = 500 * time.Millisecond
+	defer c.Disconnect()
+
+	if err := c.Connect(context.Background()); err == nil {
+		t.Fatal("Expected connection to fail")
+	}
+	if c.IsConnected() {
+		t.Error("Expected client to not be connected")
+	}
+}
+
+func TestCommandTopicFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		topic string
+		group string
+		want  string
+	}{
+		{"regular subscription", "birdnet/command", "", "birdnet/command"},
+		{"shared subscription", "birdnet/command", "cluster", "$share/cluster/birdnet/command"},
+		{"shared single level topic", "commands", "ha", "$share/ha/commands"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := commandTopicFilter(tt.topic, tt.group); got != tt.want {
+				t.Errorf("commandTopicFilter(%q, %q) = %q, want %q", tt.topic, tt.group, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestV5ClientSharedCommandSubscription(t *testing.T) {
+	t.Parallel()
+
+	// The unknown command is dropped, the allowed one reaches the control channel
+	broker := newFakeV5Broker(t, "delete_everything", " reload_birdnet\n")
+	c := newV5TestClient(t, broker.url())
+	c.config.V5.CommandTopic = testCommandTopic
+	c.config.V5.SharedGroup = "cluster"
+	controlChan := make(chan string, 2)
+	c.SetControlChannel(controlChan)
+	defer c.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	select {
+	case subscribe := <-broker.subscribes:
+		if len(subscribe.Subscriptions) != 1 {
+			t.Fatalf("Expected one subscription, got %d", len(subscribe.Subscriptions))
+		}
+		if got := subscribe.Subscriptions[0].Topic; got != "$share/cluster/"+testCommandTopic {
+			t.Errorf("Expected shared subscription topic, got %q", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("Broker did not receive SUBSCRIBE")
+	}
+
+	select {
+	case command := <-controlChan:
+		if command != "reload_birdnet" {
+			t.Errorf("Expected reload_birdnet command, got %q", command)
+		}
+	case <-ctx.Done():
+		t.Fatal("Command was not forwarded to the control channel")
+	}
+	select {
+	case command := <-controlChan:
+		t.Errorf("Expected only one command, got %q", command)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestV5ClientWithoutCommandTopicDoesNotSubscribe(t *testing.T) {
+	t.Parallel()
+
+	broker := newFakeV5Broker(t)
+	c := newV5TestClient(t, broker.url())
+	defer c.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	select {
+	case subscribe := <-broker.subscribes:
+		t.Errorf("Expected no subscription, got %+v", subscribe.Subscriptions)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -9,9 +9,21 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/logging"
 )
 
+// User properties of messages published with MQTT 5
+const (
+	// PayloadSchemaVersion is the version of the detection message schema. Increase it when
+	// fields of published messages change in a way subscribers have to handle.
+	PayloadSchemaVersion = "1"
+	// UserPropertySchemaVersion is the user property carrying PayloadSchemaVersion
+	UserPropertySchemaVersion = "schema_version"
+	// UserPropertyStationID is the user property carrying the station name
+	UserPropertyStationID = "station_id"
+)
+
 // Timeout constants for MQTT operations
 const (
 	// GracefulDisconnectTimeout is the timeout for graceful disconnect operations
@@ -69,6 +81,18 @@ type Config struct {
 	ShutdownDisconnectTimeout time.Duration // Timeout for disconnect during shutdown (shorter than normal)
 	// TLS configuration
 	TLS TLSConfig
+	// MQTT 5 configuration
+	V5 V5Config
+}
+
+// V5Config holds the MQTT 5 features used when connecting with MQTT 5
+type V5Config struct {
+	Enabled       bool          // true to connect with MQTT 5 instead of MQTT 3.1.1
+	MessageExpiry time.Duration // how long the broker keeps undelivered messages, 0 for no expiry
+	ContentType   string        // content type property of published messages
+	StationID     string        // station name sent as user property of published messages
+	CommandTopic  string        // topic to receive control commands from, empty to not subscribe
+	SharedGroup   string        // shared subscription group of CommandTopic, empty for a regular subscription
 }
 
 // TLSConfig holds TLS/SSL configuration for secure MQTT connections
@@ -78,6 +102,10 @@ type TLSConfig struct {
 	CACert             string // path to CA certificate file
 	ClientCert         string // path to client certificate file
 	ClientKey          string // path to client key file
+
+	// Outbound holds the shared CA bundle and client certificate, used when the broker has
+	// no CA certificate or client certificate of its own
+	Outbound conf.OutboundTLSSettings
 }
 
 // Package-level logger for MQTT related events
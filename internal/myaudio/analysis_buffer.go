@@ -6,6 +6,7 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/smallnest/ringbuffer"
@@ -13,6 +14,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
 )
 
 const (
@@ -26,14 +28,14 @@ var (
 	overlapSize          int                               // overlapSize is the number of bytes to overlap between chunks
 	readSize             int                               // readSize is the number of bytes to read from the ring buffer
 	analysisBuffers      map[string]*ringbuffer.RingBuffer // analysisBuffers is a map to store ring buffers for each audio source
-	prevData             map[string][]byte                 // prevData is a map to store the previous data for each audio source
+	prevData             map[string][]byte                 // prevData is a map to store the unanalyzed and overlap data for each audio source
 	abMutex              sync.RWMutex                      // Mutex to protect access to the analysisBuffers and prevData maps
 	warningCounter       map[string]int
 	warningCounterMutex  sync.Mutex              // Mutex to protect access to warningCounter map
 	analysisMetrics      *metrics.MyAudioMetrics // Global metrics instance for analysis buffer operations
 	analysisMetricsMutex sync.RWMutex            // Mutex for thread-safe access to analysisMetrics
 	analysisMetricsOnce  sync.Once               // Ensures metrics are only set once
-	readBufferPool       *BufferPool             // Global buffer pool for read operations
+	segmentPool          *BufferPool             // Global pool of analysis segments handed out by reads
 )
 
 // init initializes the warningCounter map
@@ -107,17 +109,17 @@ func AllocateAnalysisBuffer(capacity int, sourceID string) error {
 		overlapSize = SecondsToBytes(settings.BirdNET.Overlap)
 		readSize = conf.BufferSize - overlapSize
 
-		// Initialize the read buffer pool if not already done
-		if readBufferPool == nil {
+		// Initialize the analysis segment pool if not already done
+		if segmentPool == nil {
 			var err error
-			readBufferPool, err = NewBufferPool(readSize)
+			segmentPool, err = NewBufferPool(conf.BufferSize)
 			if err != nil {
 				enhancedErr := errors.New(err).
 					Component("myaudio").
 					Category(errors.CategorySystem).
 					Context("operation", "allocate_analysis_buffer").
 					Context("source", sourceID).
-					Context("buffer_pool_size", readSize).
+					Context("buffer_pool_size", conf.BufferSize).
 					Build()
 				return enhancedErr
 			}
@@ -219,13 +221,14 @@ func RemoveAnalysisBuffer(sourceID string) error {
 	// Remove from all maps
 	delete(analysisBuffers, sourceID)
 	delete(prevData, sourceID)
+	sourceWrites.Delete(sourceID)
 	delete(warningCounter, sourceID)
 
 	// Clean up buffer pool if this was the last buffer (prevents memory leak)
-	if len(analysisBuffers) == 0 && readBufferPool != nil {
+	if len(analysisBuffers) == 0 && segmentPool != nil {
 		// Clear the buffer pool to release all cached buffers
-		readBufferPool.Clear()
-		readBufferPool = nil
+		segmentPool.Clear()
+		segmentPool = nil
 		overlapSize = 0
 		readSize = 0
 	}
@@ -289,6 +292,11 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 		displayName = sourceID
 	}
 
+	// Bring calibrated sources to their common level before analysis when configured
+	if settings := conf.GetSettings(); settings != nil && settings.Realtime.Audio.Calibration.ApplyToAnalysis {
+		data = ApplySourceCalibration(sourceID, data)
+	}
+
 	start := time.Now()
 
 	abMutex.RLock()
@@ -312,6 +320,9 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 		return enhancedErr
 	}
 
+	lastAudioWrite.Store(start.UnixNano())
+	sourceWriteTime(sourceID).Store(start.UnixNano())
+
 	// Get buffer capacity information
 	capacity := ab.Capacity()
 	if capacity == 0 {
@@ -451,6 +462,7 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 }
 
 // ReadFromAnalysisBuffer reads a sliding chunk of audio data from the ring buffer for a given source ID.
+// The chunk is a pooled buffer owned by the caller, who hands it back with ReleaseAnalysisSegment.
 func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 	start := time.Now()
 
@@ -497,18 +509,17 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 		return nil, nil
 	}
 
-	// Get a buffer from the pool instead of allocating new
-	var data []byte
-	if readBufferPool != nil {
-		data = readBufferPool.Get()
-	} else {
-		// Fallback if pool not initialized
-		data = make([]byte, readSize)
+	// Read directly behind the data kept from previous reads. The buffer holds up to one read
+	// more than a segment, so it is allocated once per source.
+	pending := prevData[sourceID]
+	if cap(pending) < conf.BufferSize+readSize {
+		grown := make([]byte, len(pending), conf.BufferSize+readSize)
+		copy(grown, pending)
+		pending = grown
 	}
-
-	// Read data from the ring buffer
-	bytesRead, err := ab.Read(data)
+	bytesRead, err := ab.Read(pending[len(pending) : len(pending)+readSize])
 	if err != nil {
+		prevData[sourceID] = pending
 		enhancedErr := errors.New(err).
 			Component("myaudio").
 			Category(errors.CategorySystem).
@@ -524,46 +535,52 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 			m.RecordBufferRead("analysis", sourceID, "error")
 			m.RecordBufferReadError("analysis", sourceID, "read_failed")
 		}
+		return nil, enhancedErr
+	}
+	pending = pending[:len(pending)+bytesRead]
 
-		// Return buffer to pool on error
-		if readBufferPool != nil {
-			readBufferPool.Put(data)
+	if len(pending) < conf.BufferSize {
+		// If there isn't enough data even after appending, keep it for the next read
+		prevData[sourceID] = pending
+
+		if m := getAnalysisMetrics(); m != nil {
+			m.RecordBufferRead("analysis", sourceID, "insufficient_data")
 		}
-		return nil, enhancedErr
+		return nil, nil
 	}
 
-	// Join with previous data to ensure we're processing chunkSize bytes
-	var fullData []byte
-	prevData[sourceID] = append(prevData[sourceID], data...)
-	fullData = prevData[sourceID]
+	// Hand out the segment in a pooled buffer and keep the overlap for the next iteration
+	segment := getAnalysisSegment()
+	copy(segment, pending[:conf.BufferSize])
+	prevData[sourceID] = pending[:copy(pending, pending[readSize:])]
 
-	// Return buffer to pool after copying data
-	if readBufferPool != nil {
-		readBufferPool.Put(data)
+	// Record successful read metrics
+	if m := getAnalysisMetrics(); m != nil {
+		duration := time.Since(start).Seconds()
+		m.RecordBufferRead("analysis", sourceID, "success")
+		m.RecordBufferReadDuration("analysis", sourceID, duration)
+		m.RecordBufferReadBytes("analysis", sourceID, len(segment))
 	}
-	if len(fullData) >= conf.BufferSize {
-		// Update prevData for the next iteration
-		prevData[sourceID] = fullData[readSize:]
-		fullData = fullData[:conf.BufferSize]
 
-		// Record successful read metrics
-		if m := getAnalysisMetrics(); m != nil {
-			duration := time.Since(start).Seconds()
-			m.RecordBufferRead("analysis", sourceID, "success")
-			m.RecordBufferReadDuration("analysis", sourceID, duration)
-			m.RecordBufferReadBytes("analysis", sourceID, len(fullData))
-		}
+	return segment, nil
+}
 
-		//log.Printf("✅ Read %d bytes from analysis buffer for source ID %s (%s)", len(fullData), sourceID, displayName)
-		return fullData, nil
-	} else {
-		// If there isn't enough data even after appending, update prevData and return nil
-		prevData[sourceID] = fullData
+// getAnalysisSegment returns a buffer for an analysis segment, pooled when the pool is initialized.
+// Callers must hold abMutex.
+func getAnalysisSegment() []byte {
+	if segmentPool != nil {
+		return segmentPool.Get()
+	}
+	return make([]byte, conf.BufferSize)
+}
 
-		if m := getAnalysisMetrics(); m != nil {
-			m.RecordBufferRead("analysis", sourceID, "insufficient_data")
-		}
-		return nil, nil
+// ReleaseAnalysisSegment returns a segment read by ReadFromAnalysisBuffer to the segment pool.
+// The segment must not be used afterwards; segments that are not released are garbage collected.
+func ReleaseAnalysisSegment(segment []byte) {
+	abMutex.RLock()
+	defer abMutex.RUnlock()
+	if segmentPool != nil {
+		segmentPool.Put(segment)
 	}
 }
 
@@ -578,13 +595,96 @@ func AnalysisBufferExists(sourceID string) bool {
 	return exists
 }
 
-// AnalysisBufferMonitor monitors the buffer and processes audio data when enough data is present.
+// lastAudioWrite is the time audio was last written to any analysis buffer in Unix nanoseconds
+var lastAudioWrite atomic.Int64
+
+// LastAudioTime returns when audio was last received from any source, or the zero time
+// if no audio has been received yet
+func LastAudioTime() time.Time {
+	nanos := lastAudioWrite.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// lastChunkRead is the time a buffer monitor last read a chunk in Unix nanoseconds
+var lastChunkRead atomic.Int64
+
+// LastChunkTime returns when a buffer monitor last read a chunk from an analysis buffer, or
+// the zero time if no chunk has been read yet. Chunks skipped while analysis is paused or
+// silent count, so it shows whether the monitors keep up with the captured audio.
+func LastChunkTime() time.Time {
+	nanos := lastChunkRead.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// SourceAudioTimes returns when audio was last received from each source with an analysis
+// buffer, the zero time for sources that have not received audio yet
+func SourceAudioTimes() map[string]time.Time {
+	abMutex.RLock()
+	defer abMutex.RUnlock()
+
+	times := make(map[string]time.Time, len(analysisBuffers))
+	for sourceID := range analysisBuffers {
+		times[sourceID] = time.Time{}
+		if written, ok := sourceWrites.Load(sourceID); ok {
+			if nanos := written.(*atomic.Int64).Load(); nanos != 0 {
+				times[sourceID] = time.Unix(0, nanos)
+			}
+		}
+	}
+	return times
+}
+
+// sourceWrites holds the time audio was last written to the analysis buffer of each source
+// in Unix nanoseconds, as *atomic.Int64
+var sourceWrites sync.Map
+
+// sourceWriteTime returns the last write time of the analysis buffer of a source
+func sourceWriteTime(sourceID string) *atomic.Int64 {
+	if written, ok := sourceWrites.Load(sourceID); ok {
+		return written.(*atomic.Int64)
+	}
+	written, _ := sourceWrites.LoadOrStore(sourceID, new(atomic.Int64))
+	return written.(*atomic.Int64)
+}
+
+// analysisGate reports whether audio should currently be analyzed, nil means always
+var analysisGate atomic.Pointer[func() bool]
+
+// SetAnalysisGate installs a function that pauses analysis while it returns false, e.g. outside
+// a scheduled analysis window. Audio is still drained from the analysis buffers while paused
+// so analysis resumes on fresh data. Pass nil to remove the gate.
+func SetAnalysisGate(gate func() bool) {
+	if gate == nil {
+		analysisGate.Store(nil)
+		return
+	}
+	analysisGate.Store(&gate)
+}
+
+// analysisAllowed reports whether the analysis gate currently allows analysis
+func analysisAllowed() bool {
+	gate := analysisGate.Load()
+	return gate == nil || (*gate)()
+}
+
+// AnalysisBufferMonitor monitors the buffer and queues audio data for inference when enough data
+// is present. The inference scheduler takes chunks from all sources in turn, so the monitor of a
+// chatty source cannot starve the others.
 func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan chan struct{}, sourceID string) {
 	wg.Add(1)
 	defer func() {
 		wg.Done()
 	}()
 
+	inferenceQueue.register(sourceID)
+	defer inferenceQueue.unregister(sourceID)
+
 	// This is the offset to subtract from the begin time of the data to account for BirdNET prediction and
 	// processing delays, goal is to ensure that captured audio clip contains detection sound.
 	const detectionOffset = 10 * time.Second
@@ -593,6 +693,8 @@ func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan cha
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
+	var gate silenceGate
+
 	for {
 		select {
 		case <-quitChan:
@@ -611,6 +713,28 @@ func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan cha
 				time.Sleep(1 * time.Second) // Wait for 1 second before trying again
 				continue
 			}
+			if len(data) == conf.BufferSize {
+				lastChunkRead.Store(time.Now().UnixNano())
+			}
+
+			// Skip inference while analysis is paused
+			if len(data) == conf.BufferSize && !analysisAllowed() {
+				ReleaseAnalysisSegment(data)
+				if m := getAnalysisMetrics(); m != nil {
+					m.RecordAnalysisBufferPoll(sourceID, "paused")
+				}
+				continue
+			}
+
+			// Skip inference on silent windows, counting them so the gate can be verified
+			if settings := &conf.Setting().BirdNET.SilenceGate; settings.Enabled && len(data) == conf.BufferSize {
+				activity := measureActivity(data, conf.BitDepth)
+				if gate.skip(settings, activity) {
+					ReleaseAnalysisSegment(data)
+					inferenceQueue.skip(sourceID, activity.level)
+					continue
+				}
+			}
 
 			// if buffer has 3 seconds of data, process it
 			if len(data) == conf.BufferSize {
@@ -623,18 +747,21 @@ func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan cha
 				// account for BirdNET prediction delay
 				beginTimeOffset := time.Duration(conf.Setting().Realtime.Audio.Export.PreCapture)*time.Second + detectionOffset
 				startTime := time.Now().Add(-beginTimeOffset)
-				processingStart := time.Now()
-
-				err := ProcessData(bn, data, startTime, sourceID)
 
-				if m := getAnalysisMetrics(); m != nil {
-					processingDuration := time.Since(processingStart).Seconds()
-					m.RecordAnalysisBufferProcessingDuration(sourceID, processingDuration)
+				// The newest audio of the chunk was written with the last write to the buffer
+				if written := sourceWriteTime(sourceID).Load(); written != 0 {
+					pipeline.Record(pipeline.StageSegment, time.Since(time.Unix(0, written)))
 				}
 
-				if err != nil {
-					log.Printf("❌ Error processing data for source ID %s: %v", sourceID, err)
-				}
+				dumpAnalysisChunk(sourceID, data, startTime)
+				inferenceQueue.submit(sourceID, func() error {
+					processingStart := time.Now()
+					err := ProcessData(bn, data, startTime, sourceID)
+					if m := getAnalysisMetrics(); m != nil {
+						m.RecordAnalysisBufferProcessingDuration(sourceID, time.Since(processingStart).Seconds())
+					}
+					return err
+				})
 			} else if m := getAnalysisMetrics(); m != nil {
 				m.RecordAnalysisBufferPoll(sourceID, "insufficient_data")
 			}
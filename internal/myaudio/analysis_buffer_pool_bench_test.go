@@ -23,7 +23,7 @@ func BenchmarkReadFromAnalysisBuffer_WithPool(b *testing.B) {
 
 	// Initialize buffer pool
 	var err error
-	readBufferPool, err = NewBufferPool(readSize)
+	segmentPool, err = NewBufferPool(conf.BufferSize)
 	if err != nil {
 		b.Fatalf("Failed to create buffer pool: %v", err)
 	}
@@ -94,11 +94,12 @@ func BenchmarkReadFromAnalysisBuffer_WithPool(b *testing.B) {
 		if len(data) != conf.BufferSize {
 			b.Fatalf("ReadFromAnalysisBuffer returned wrong size: got %d, want %d", len(data), conf.BufferSize)
 		}
+		ReleaseAnalysisSegment(data)
 	}
 
 	// Get pool stats
-	if readBufferPool != nil {
-		stats := readBufferPool.GetStats()
+	if segmentPool != nil {
+		stats := segmentPool.GetStats()
 		b.Logf("Buffer pool stats - Hits: %d, Misses: %d, Hit Rate: %.2f%%", 
 			stats.Hits, stats.Misses, 
 			float64(stats.Hits)/float64(stats.Hits+stats.Misses)*100)
@@ -109,14 +110,14 @@ func BenchmarkReadFromAnalysisBuffer_WithPool(b *testing.B) {
 	delete(analysisBuffers, testStream)
 	delete(prevData, testStream)
 	abMutex.Unlock()
-	readBufferPool = nil
+	segmentPool = nil
 }
 
 // BenchmarkComparison runs both implementations for easy comparison
 func BenchmarkComparison(b *testing.B) {
 	b.Run("Original", func(b *testing.B) {
 		// Ensure pool is nil for original test
-		readBufferPool = nil
+		segmentPool = nil
 		BenchmarkReadFromAnalysisBuffer_Original(b)
 	})
 	
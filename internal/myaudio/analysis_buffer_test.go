@@ -0,0 +1,53 @@
+package myaudio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestReadFromAnalysisBufferSegments(t *testing.T) {
+	const sourceID = "test_analysis_segments"
+	require.NoError(t, AllocateAnalysisBuffer(conf.BufferSize*8, sourceID))
+	t.Cleanup(func() { _ = RemoveAnalysisBuffer(sourceID) })
+
+	// Audio numbered by byte position, so each segment shows where it starts
+	audio := make([]byte, conf.BufferSize*6)
+	for i := range audio {
+		audio[i] = byte(i % 251)
+	}
+	require.NoError(t, WriteToAnalysisBuffer(sourceID, audio))
+
+	var segments [][]byte
+	for range 20 {
+		segment, err := ReadFromAnalysisBuffer(sourceID)
+		require.NoError(t, err)
+		if segment != nil {
+			segments = append(segments, segment)
+		}
+	}
+	require.GreaterOrEqual(t, len(segments), 3)
+
+	// Segments slide by the read size and are not overwritten by later reads
+	abMutex.RLock()
+	hop := readSize
+	abMutex.RUnlock()
+	for i, segment := range segments {
+		start := i * hop
+		require.Len(t, segment, conf.BufferSize)
+		assert.Equal(t, audio[start:start+conf.BufferSize], segment, "segment %d", i)
+	}
+
+	// Released segments are reused instead of allocated
+	for _, segment := range segments {
+		ReleaseAnalysisSegment(segment)
+	}
+	require.NoError(t, WriteToAnalysisBuffer(sourceID, audio[:conf.BufferSize*2]))
+	allocs := testing.AllocsPerRun(5, func() {
+		segment, _ := ReadFromAnalysisBuffer(sourceID)
+		ReleaseAnalysisSegment(segment)
+	})
+	assert.LessOrEqual(t, allocs, 1.0, "steady state reads allocate no segments")
+}
@@ -0,0 +1,136 @@
+// calibration.go: per-source gain offsets measured from the typical loudness of a source
+package myaudio
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	// calibrationStep is the interval between the loudness measurements of a calibration
+	calibrationStep = 10 * time.Second
+
+	// calibrationSegment is the seconds of audio of one loudness measurement
+	calibrationSegment = 3
+
+	// minCalibrationMeasurements is the fewest measurements a calibration accepts
+	minCalibrationMeasurements = 3
+)
+
+// calibrating holds the IDs of the sources being calibrated
+var calibrating sync.Map
+
+// CalibrateSource measures the typical loudness of a source over window and returns the
+// profile with the gain offset bringing it to targetLUFS. The loudness of a few seconds of
+// captured audio is measured every calibrationStep and the median taken, so bird song and
+// passing noise do not skew the result. Only one calibration of a source runs at a time.
+func CalibrateSource(ctx context.Context, sourceID string, window time.Duration, targetLUFS float64) (conf.CalibrationProfile, error) {
+	source, exists := GetRegistry().GetSourceByID(sourceID)
+	if !exists {
+		return conf.CalibrationProfile{}, errors.New(ErrSourceNotFound).
+			Component("myaudio").
+			Category(errors.CategoryNotFound).
+			Context("operation", "calibrate_source").
+			Context("source_id", sourceID).
+			Build()
+	}
+	if _, running := calibrating.LoadOrStore(sourceID, struct{}{}); running {
+		return conf.CalibrationProfile{}, errors.Newf("source %s is already being calibrated", sourceID).
+			Component("myaudio").
+			Category(errors.CategoryConflict).
+			Context("operation", "calibrate_source").
+			Context("source_id", sourceID).
+			Build()
+	}
+	defer calibrating.Delete(sourceID)
+
+	read := func(ctx context.Context) ([]byte, error) {
+		start := time.Now().Add(-calibrationSegment * time.Second)
+		return ReadSegmentFromCaptureBuffer(ctx, sourceID, start, calibrationSegment, calibrationStep)
+	}
+	measured, err := measureTypicalLoudness(ctx, window, calibrationStep, read)
+	if err != nil {
+		return conf.CalibrationProfile{}, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryAudio).
+			Context("operation", "calibrate_source").
+			Context("source_id", sourceID).
+			Build()
+	}
+
+	return conf.CalibrationProfile{
+		Source:       source.SafeString,
+		Gain:         LoudnessGain(measured, targetLUFS),
+		MeasuredLUFS: measured,
+		CalibratedAt: time.Now(),
+	}, nil
+}
+
+// IsCalibrating reports whether a calibration of the source is running
+func IsCalibrating(sourceID string) bool {
+	_, running := calibrating.Load(sourceID)
+	return running
+}
+
+// measureTypicalLoudness measures the loudness of the audio returned by read every step until
+// window has passed and returns the median. Failed reads, such as during a reconnect, are
+// skipped.
+func measureTypicalLoudness(ctx context.Context, window, step time.Duration, read func(ctx context.Context) ([]byte, error)) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	ticker := time.NewTicker(step)
+	defer ticker.Stop()
+
+	var levels []float64
+	for {
+		select {
+		case <-ctx.Done():
+			if len(levels) < minCalibrationMeasurements {
+				if errors.Is(ctx.Err(), context.Canceled) {
+					return 0, ctx.Err()
+				}
+				return 0, errors.Newf("calibration measured %d segments, need at least %d", len(levels), minCalibrationMeasurements).
+					Component("myaudio").
+					Category(errors.CategoryAudio).
+					Build()
+			}
+			slices.Sort(levels)
+			return levels[len(levels)/2], nil
+		case <-ticker.C:
+			pcmData, err := read(ctx)
+			if err != nil || len(pcmData) == 0 {
+				continue
+			}
+			levels = append(levels, EstimatePCMLoudness(pcmData))
+		}
+	}
+}
+
+// SourceCalibration returns the calibration profile of a source
+func SourceCalibration(sourceID string) (conf.CalibrationProfile, bool) {
+	settings := conf.GetSettings()
+	if settings == nil || len(settings.Realtime.Audio.Calibration.Profiles) == 0 {
+		return conf.CalibrationProfile{}, false
+	}
+	source, exists := GetRegistry().GetSourceByID(sourceID)
+	if !exists {
+		return conf.CalibrationProfile{}, false
+	}
+	return settings.Realtime.Audio.Calibration.Profile(source.SafeString)
+}
+
+// ApplySourceCalibration returns PCM data of a source adjusted by its calibration gain offset,
+// or pcmData itself when the source has no offset
+func ApplySourceCalibration(sourceID string, pcmData []byte) []byte {
+	profile, ok := SourceCalibration(sourceID)
+	if !ok || profile.Gain == 0 {
+		return pcmData
+	}
+	return applyPCMGain(pcmData, profile.Gain)
+}
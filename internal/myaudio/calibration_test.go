@@ -0,0 +1,60 @@
+package myaudio
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tonePCM returns 16-bit PCM of a tone with the given RMS level in dBFS
+func tonePCM(levelDB float64) []byte {
+	amplitude := math.Pow(10, levelDB/20) * math.Sqrt2 * 32768
+	samples := make([]float64, 4800)
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*float64(i)/48)
+	}
+	return testPCM(samples)
+}
+
+func TestMeasureTypicalLoudness(t *testing.T) {
+	t.Parallel()
+
+	t.Run("median of the measurements", func(t *testing.T) {
+		t.Parallel()
+		// Background at -40 dBFS with a loud song and a failed read
+		levels := []float64{-40, -41, -12, -39, -40, -40, -42}
+		var reads int
+		read := func(ctx context.Context) ([]byte, error) {
+			reads++
+			if reads == 3 {
+				return nil, errors.New("reconnecting")
+			}
+			return tonePCM(levels[reads%len(levels)]), nil
+		}
+		measured, err := measureTypicalLoudness(context.Background(), 200*time.Millisecond, 10*time.Millisecond, read)
+		require.NoError(t, err)
+		assert.InDelta(t, -40, measured, 1.1)
+	})
+
+	t.Run("too few measurements", func(t *testing.T) {
+		t.Parallel()
+		read := func(ctx context.Context) ([]byte, error) { return nil, errors.New("no audio") }
+		_, err := measureTypicalLoudness(context.Background(), 50*time.Millisecond, 10*time.Millisecond, read)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "need at least")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		read := func(ctx context.Context) ([]byte, error) { return tonePCM(-40), nil }
+		_, err := measureTypicalLoudness(ctx, time.Second, 10*time.Millisecond, read)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
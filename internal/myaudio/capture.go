@@ -382,18 +382,13 @@ func ValidateAudioDevice(settings *conf.Settings) error {
 		return nil
 	}
 
-	var backend malgo.Backend
-	switch runtime.GOOS {
-	case "linux":
-		backend = malgo.BackendAlsa
-	case "windows":
-		backend = malgo.BackendWasapi
-	case "darwin":
-		backend = malgo.BackendCoreaudio
+	spec := ParseSourceSpec(settings.Realtime.Audio.Source)
+	if !spec.IsSoundCard() {
+		return fmt.Errorf("audio source '%s' is a stream, declare it under realtime.rtsp.urls", settings.Realtime.Audio.Source)
 	}
 
 	// Initialize malgo context
-	malgoCtx, err := malgo.InitContext([]malgo.Backend{backend}, malgo.ContextConfig{}, nil)
+	malgoCtx, err := malgo.InitContext([]malgo.Backend{spec.captureBackend()}, malgo.ContextConfig{}, nil)
 	if err != nil {
 		settings.Realtime.Audio.Source = ""
 		return fmt.Errorf("failed to initialize audio context: %w", err)
@@ -407,9 +402,9 @@ func ValidateAudioDevice(settings *conf.Settings) error {
 		return fmt.Errorf("failed to get capture devices: %w", err)
 	}
 
-	// Filter to get only hardware devices to check if any are available
-	hardwareDevices := getHardwareDevices(infos)
-	if len(hardwareDevices) == 0 {
+	// Filter to get only hardware devices to check if any are available, sound servers
+	// list their own sources instead
+	if spec.hardwareDevicesOnly() && len(getHardwareDevices(infos)) == 0 {
 		settings.Realtime.Audio.Source = ""
 		return fmt.Errorf("no hardware audio capture devices found")
 	}
@@ -421,7 +416,7 @@ func ValidateAudioDevice(settings *conf.Settings) error {
 			continue
 		}
 
-		if matchesDeviceSettings(decodedID, &infos[i], settings.Realtime.Audio.Source) {
+		if matchesDeviceSettings(decodedID, &infos[i], spec.Device) {
 			if TestCaptureDevice(malgoCtx, &infos[i]) {
 				return nil
 			}
@@ -436,17 +431,8 @@ func ValidateAudioDevice(settings *conf.Settings) error {
 
 // selectCaptureSource selects and tests an appropriate capture device based on the provided settings.
 func selectCaptureSource(settings *conf.Settings) (captureSource, error) {
-	var backend malgo.Backend
-	switch runtime.GOOS {
-	case "linux":
-		backend = malgo.BackendAlsa
-	case "windows":
-		backend = malgo.BackendWasapi
-	case "darwin":
-		backend = malgo.BackendCoreaudio
-	}
-
-	malgoCtx, err := malgo.InitContext([]malgo.Backend{backend}, malgo.ContextConfig{}, func(message string) {
+	spec := ParseSourceSpec(settings.Realtime.Audio.Source)
+	malgoCtx, err := malgo.InitContext([]malgo.Backend{spec.captureBackend()}, malgo.ContextConfig{}, func(message string) {
 		if settings.Debug {
 			fmt.Print(message)
 		}
@@ -475,7 +461,7 @@ func selectCaptureSource(settings *conf.Settings) (captureSource, error) {
 			output = fmt.Sprintf("%s, %s", output, decodedID)
 		}
 
-		if matchesDeviceSettings(decodedID, &infos[i], settings.Realtime.Audio.Source) {
+		if matchesDeviceSettings(decodedID, &infos[i], spec.Device) {
 			if TestCaptureDevice(malgoCtx, &infos[i]) {
 				fmt.Printf("%s (✅ selected)\n", output)
 				return captureSource{
@@ -701,16 +687,7 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 		fmt.Println("Initializing context")
 	}
 
-	var backend malgo.Backend
-	switch runtime.GOOS {
-	case "linux":
-		backend = malgo.BackendAlsa
-	case "windows":
-		backend = malgo.BackendWasapi
-	case "darwin":
-		backend = malgo.BackendCoreaudio
-	}
-
+	backend := ParseSourceSpec(settings.Realtime.Audio.Source).captureBackend()
 	malgoCtx, err := malgo.InitContext([]malgo.Backend{backend}, malgo.ContextConfig{}, func(message string) {
 		if settings.Debug {
 			fmt.Print(message)
@@ -2,8 +2,11 @@
 package myaudio
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -21,8 +24,11 @@ type CaptureBuffer struct {
 	bytesPerSample int
 	bufferSize     int
 	bufferDuration time.Duration
-	startTime      time.Time
-	initialized    bool
+	startTime      time.Time     // estimated capture time of the oldest audio in the buffer
+	wrapped        bool          // whether the buffer was filled once
+	lastWrite      time.Time     // time of the latest write, audio up to it is in the buffer
+	written        chan struct{} // closed by the next write, nil while no reader waits
+	drift          clockDrift    // rate the source delivers audio at
 	lock           sync.Mutex
 	source         string // Source identifier for metrics tracking
 }
@@ -297,6 +303,17 @@ func HasCaptureBuffer(sourceID string) bool {
 	return exists
 }
 
+// GetCaptureBufferDuration returns how much audio the capture buffer of a source holds
+func GetCaptureBufferDuration(sourceID string) (time.Duration, bool) {
+	cbMutex.RLock()
+	defer cbMutex.RUnlock()
+	cb, exists := captureBuffers[sourceID]
+	if !exists {
+		return 0, false
+	}
+	return cb.bufferDuration, true
+}
+
 // Note: hasAnalysisBuffer function removed to fix encapsulation violation.
 // Use the exported AnalysisBufferExists(sourceID) function instead.
 
@@ -338,7 +355,8 @@ func WriteToCaptureBuffer(sourceID string, data []byte) error {
 }
 
 // ReadSegmentFromCaptureBuffer extracts a segment of audio data from the buffer for a given source ID.
-func ReadSegmentFromCaptureBuffer(sourceID string, requestedStartTime time.Time, duration int) ([]byte, error) {
+// It waits for the audio as CaptureBuffer.ReadSegment does.
+func ReadSegmentFromCaptureBuffer(ctx context.Context, sourceID string, requestedStartTime time.Time, duration int, timeout time.Duration) ([]byte, error) {
 	cbMutex.RLock()
 	cb, exists := captureBuffers[sourceID]
 	cbMutex.RUnlock()
@@ -347,7 +365,7 @@ func ReadSegmentFromCaptureBuffer(sourceID string, requestedStartTime time.Time,
 		return nil, fmt.Errorf("no capture buffer found for source ID: %s", sourceID)
 	}
 
-	return cb.ReadSegment(requestedStartTime, duration)
+	return cb.ReadSegment(ctx, requestedStartTime, duration, timeout)
 }
 
 // NewCaptureBuffer initializes a new CaptureBuffer with timestamp tracking
@@ -360,7 +378,7 @@ func NewCaptureBuffer(durationSeconds, sampleRate, bytesPerSample int, source st
 		bytesPerSample: bytesPerSample,
 		bufferSize:     alignedBufferSize,
 		bufferDuration: time.Second * time.Duration(durationSeconds),
-		initialized:    false,
+		drift:          newClockDrift(sampleRate * bytesPerSample),
 		source:         source,
 	}
 
@@ -395,20 +413,36 @@ func (cb *CaptureBuffer) Write(data []byte) {
 		}
 	}
 
-	if !cb.initialized {
-		// Initialize the buffer's start time based on the current time.
-		cb.startTime = time.Now()
-		cb.initialized = true
+	now := time.Now()
+	cb.drift.update(now, len(data))
+
+	// Wake readers waiting for this audio
+	cb.lastWrite = now
+	if cb.written != nil {
+		close(cb.written)
+		cb.written = nil
 	}
 
-	// Store the current write index to determine if we've wrapped around the buffer.
-	prevWriteIndex := cb.writeIndex
+	// Only the latest audio fits when a write is larger than the buffer
+	if dropped := len(data) - cb.bufferSize; dropped > 0 {
+		cb.writeIndex = (cb.writeIndex + dropped) % cb.bufferSize
+		cb.wrapped = true
+		data = data[dropped:]
+	}
 
-	// Copy the incoming data into the buffer starting at the current write index.
+	// Copy the incoming data into the buffer starting at the current write index, continuing
+	// at the start of the buffer when it wraps around.
 	bytesWritten := copy(cb.data[cb.writeIndex:], data)
-
-	// Update the write index, wrapping around the buffer if necessary.
+	wrapped := bytesWritten < len(data) || cb.writeIndex+bytesWritten == cb.bufferSize
+	bytesWritten += copy(cb.data, data[bytesWritten:])
 	cb.writeIndex = (cb.writeIndex + bytesWritten) % cb.bufferSize
+	if wrapped {
+		cb.wrapped = true
+	}
+
+	// The latest audio was captured up to now at the rate the source delivers it, which
+	// dates the oldest audio without assuming the nominal sample rate
+	cb.startTime = now.Add(-cb.audioDuration(cb.filled()))
 
 	// Record metrics for buffer write
 	if m := getCaptureMetrics(); m != nil {
@@ -421,14 +455,12 @@ func (cb *CaptureBuffer) Write(data []byte) {
 		utilization := float64(cb.writeIndex) / float64(cb.bufferSize)
 		m.UpdateBufferUtilization("capture", cb.source, utilization)
 		m.UpdateBufferSize("capture", cb.source, cb.writeIndex)
+		m.UpdateCaptureClockDrift(cb.source, cb.drift.ppm())
 	}
 
-	// Determine if the write operation has overwritten old data.
-	if cb.writeIndex <= prevWriteIndex {
-		// If old data has been overwritten, adjust startTime to maintain accurate timekeeping.
-		cb.startTime = time.Now().Add(-cb.bufferDuration)
+	if wrapped {
 		if conf.Setting().Realtime.Audio.Export.Debug {
-			log.Printf("Buffer wrapped during write, adjusting start time to %v", cb.startTime)
+			log.Printf("Buffer wrapped during write, oldest audio is from %v", cb.startTime)
 		}
 
 		// Record buffer wraparound
@@ -438,48 +470,65 @@ func (cb *CaptureBuffer) Write(data []byte) {
 	}
 }
 
+// filled returns how many bytes of audio the buffer holds
+func (cb *CaptureBuffer) filled() int {
+	if cb.wrapped {
+		return cb.bufferSize
+	}
+	return cb.writeIndex
+}
+
+// audioDuration returns the duration of numBytes of audio from the source
+func (cb *CaptureBuffer) audioDuration(numBytes int) time.Duration {
+	return time.Duration(float64(numBytes) / cb.drift.bytesPerSecond() * float64(time.Second))
+}
+
+// bytesAfter returns how many bytes of audio were written after t, in whole samples.
+// Positions are counted back from the latest write at the measured rate of the source.
+func (cb *CaptureBuffer) bytesAfter(t time.Time) int {
+	n := int(math.Round(cb.lastWrite.Sub(t).Seconds() * cb.drift.bytesPerSecond()))
+	return n - n%cb.bytesPerSample
+}
+
 // ReadSegment extracts a segment of audio data based on precise start and end times, handling wraparounds.
-// It waits until the current time is past the requested end time.
-func (cb *CaptureBuffer) ReadSegment(requestedStartTime time.Time, duration int) ([]byte, error) {
+// Times are located counting back from the latest write at the measured rate of the source, so
+// clock drift of the source does not offset segments.
+// It waits until audio past the requested end time was written, for at most timeout after the end
+// time, and returns early when ctx is canceled.
+func (cb *CaptureBuffer) ReadSegment(ctx context.Context, requestedStartTime time.Time, duration int, timeout time.Duration) ([]byte, error) {
 	operationStart := time.Now()
 	requestedEndTime := requestedStartTime.Add(time.Duration(duration) * time.Second)
 
+	deadline := time.NewTimer(time.Until(requestedEndTime) + timeout)
+	defer deadline.Stop()
+
 	for {
 		cb.lock.Lock()
 
-		startOffset := requestedStartTime.Sub(cb.startTime)
-		endOffset := requestedEndTime.Sub(cb.startTime)
+		startBack := cb.bytesAfter(requestedStartTime)
+		endBack := cb.bytesAfter(requestedEndTime)
 
-		startIndex := int(startOffset.Seconds()) * cb.sampleRate * cb.bytesPerSample
-		endIndex := int(endOffset.Seconds()) * cb.sampleRate * cb.bytesPerSample
-
-		startIndex %= cb.bufferSize
-		endIndex %= cb.bufferSize
-
-		if startOffset < 0 {
-			if cb.writeIndex == 0 || cb.writeIndex+int(startOffset.Seconds())*cb.sampleRate*cb.bytesPerSample > cb.bufferSize {
-				cb.lock.Unlock()
+		if startBack > cb.filled() {
+			cb.lock.Unlock()
 
-				enhancedErr := errors.Newf("requested start time is outside the buffer's current timeframe").
-					Component("myaudio").
-					Category(errors.CategoryValidation).
-					Context("operation", "read_capture_buffer_segment").
-					Context("requested_start_time", requestedStartTime.Format(time.RFC3339Nano)).
-					Context("buffer_start_time", cb.startTime.Format(time.RFC3339Nano)).
-					Context("start_offset_seconds", startOffset.Seconds()).
-					Context("buffer_duration_seconds", cb.bufferDuration.Seconds()).
-					Build()
+			enhancedErr := errors.Newf("requested start time is outside the buffer's current timeframe").
+				Component("myaudio").
+				Category(errors.CategoryValidation).
+				Context("operation", "read_capture_buffer_segment").
+				Context("requested_start_time", requestedStartTime.Format(time.RFC3339Nano)).
+				Context("buffer_start_time", cb.startTime.Format(time.RFC3339Nano)).
+				Context("buffer_duration_seconds", cb.bufferDuration.Seconds()).
+				Context("clock_drift_ppm", cb.drift.ppm()).
+				Build()
 
-				if m := getCaptureMetrics(); m != nil {
-					m.RecordCaptureBufferSegmentRead(cb.source, "error")
-					m.RecordCaptureBufferTimestampError(cb.source, "outside_timeframe")
-				}
-				return nil, enhancedErr
+			if m := getCaptureMetrics(); m != nil {
+				m.RecordCaptureBufferSegmentRead(cb.source, "error")
+				m.RecordCaptureBufferTimestampError(cb.source, "outside_timeframe")
 			}
-			startIndex = (cb.bufferSize + startIndex) % cb.bufferSize
+			return nil, enhancedErr
 		}
 
-		if endOffset < 0 || endOffset <= startOffset {
+		if duration <= 0 {
 			cb.lock.Unlock()
 
 			enhancedErr := errors.Newf("requested times are outside the buffer's current timeframe").
@@ -489,8 +538,6 @@ func (cb *CaptureBuffer) ReadSegment(requestedStartTime time.Time, duration int)
 				Context("requested_start_time", requestedStartTime.Format(time.RFC3339Nano)).
 				Context("requested_end_time", requestedEndTime.Format(time.RFC3339Nano)).
 				Context("buffer_start_time", cb.startTime.Format(time.RFC3339Nano)).
-				Context("start_offset_seconds", startOffset.Seconds()).
-				Context("end_offset_seconds", endOffset.Seconds()).
 				Build()
 
 			if m := getCaptureMetrics(); m != nil {
@@ -500,8 +547,11 @@ func (cb *CaptureBuffer) ReadSegment(requestedStartTime time.Time, duration int)
 			return nil, enhancedErr
 		}
 
-		// Wait until the current time is past the requested end time
-		if time.Now().After(requestedEndTime) {
+		// Wait until audio past the requested end time was written
+		if cb.lastWrite.After(requestedEndTime) {
+			startIndex := ((cb.writeIndex-startBack)%cb.bufferSize + cb.bufferSize) % cb.bufferSize
+			endIndex := ((cb.writeIndex-endBack)%cb.bufferSize + cb.bufferSize) % cb.bufferSize
+
 			var segment []byte
 			if startIndex < endIndex {
 				if conf.Setting().Realtime.Audio.Export.Debug {
@@ -537,7 +587,56 @@ func (cb *CaptureBuffer) ReadSegment(requestedStartTime time.Time, duration int)
 		if conf.Setting().Realtime.Audio.Export.Debug {
 			log.Printf("Buffer is not filled yet, waiting for data to be available")
 		}
+		if cb.written == nil {
+			cb.written = make(chan struct{})
+		}
+		written := cb.written
 		cb.lock.Unlock()
-		time.Sleep(1 * time.Second) // Sleep briefly to avoid busy waiting
+
+		select {
+		case <-written:
+		case <-ctx.Done():
+			if m := getCaptureMetrics(); m != nil {
+				m.RecordCaptureBufferSegmentRead(cb.source, "error")
+			}
+			return nil, errors.New(ctx.Err()).
+				Component("myaudio").
+				Category(errors.CategoryTimeout).
+				Context("operation", "read_capture_buffer_segment").
+				Context("requested_end_time", requestedEndTime.Format(time.RFC3339Nano)).
+				Build()
+		case <-deadline.C:
+			if m := getCaptureMetrics(); m != nil {
+				m.RecordCaptureBufferSegmentRead(cb.source, "error")
+			}
+			return nil, errors.Newf("timed out waiting for audio to be written to the capture buffer").
+				Component("myaudio").
+				Category(errors.CategoryTimeout).
+				Context("operation", "read_capture_buffer_segment").
+				Context("requested_end_time", requestedEndTime.Format(time.RFC3339Nano)).
+				Context("last_write_time", cb.lastWriteTime().Format(time.RFC3339Nano)).
+				Context("timeout_seconds", timeout.Seconds()).
+				Build()
+		}
 	}
 }
+
+// lastWriteTime returns the time of the latest write
+func (cb *CaptureBuffer) lastWriteTime() time.Time {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	return cb.lastWrite
+}
+
+// GetCaptureBufferSources returns the sorted source IDs of all capture buffers
+func GetCaptureBufferSources() []string {
+	cbMutex.RLock()
+	sources := make([]string, 0, len(captureBuffers))
+	for sourceID := range captureBuffers {
+		sources = append(sources, sourceID)
+	}
+	cbMutex.RUnlock()
+
+	slices.Sort(sources)
+	return sources
+}
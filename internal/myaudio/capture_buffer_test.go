@@ -0,0 +1,151 @@
+package myaudio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureBufferReadSegmentWait(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate, bytesPerSample = 8000, 2
+	second := make([]byte, sampleRate*bytesPerSample)
+	newBuffer := func() *CaptureBuffer {
+		cb := NewCaptureBuffer(10, sampleRate, bytesPerSample, "test_read_segment")
+		cb.Write(second)
+		cb.Write(second)
+		return cb
+	}
+
+	t.Run("audio already written", func(t *testing.T) {
+		t.Parallel()
+		cb := newBuffer()
+		start := cb.lastWrite.Add(-time.Second)
+		time.Sleep(1100 * time.Millisecond)
+		cb.Write(second)
+
+		segment, err := cb.ReadSegment(context.Background(), start, 1, time.Second)
+		require.NoError(t, err)
+		assert.Len(t, segment, len(second))
+	})
+
+	t.Run("waits for the next write", func(t *testing.T) {
+		t.Parallel()
+		cb := newBuffer()
+		start := cb.lastWrite
+		go func() {
+			time.Sleep(1200 * time.Millisecond)
+			cb.Write(second)
+		}()
+
+		began := time.Now()
+		segment, err := cb.ReadSegment(context.Background(), start, 1, 5*time.Second)
+		require.NoError(t, err)
+		assert.Len(t, segment, len(second))
+		assert.Less(t, time.Since(began), 1500*time.Millisecond, "woken by the write instead of polling")
+	})
+
+	t.Run("times out without audio", func(t *testing.T) {
+		t.Parallel()
+		cb := newBuffer()
+
+		began := time.Now()
+		_, err := cb.ReadSegment(context.Background(), cb.lastWrite, 1, 100*time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+		assert.Less(t, time.Since(began), 2*time.Second)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		cb := newBuffer()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		began := time.Now()
+		_, err := cb.ReadSegment(ctx, cb.lastWrite, 5, time.Minute)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(began), time.Second)
+	})
+}
+
+func TestCaptureBufferLocatesAudioAtMeasuredRate(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate, bytesPerSample = 8000, 2
+	cb := NewCaptureBuffer(4, sampleRate, bytesPerSample, "test_capture_drift")
+
+	// Audio numbered by sample, written past the end of the buffer
+	audio := make([]byte, cb.bufferSize+3*sampleRate*bytesPerSample)
+	for i := range audio {
+		audio[i] = byte(i / bytesPerSample % 251)
+	}
+	cb.Write(audio[:cb.bufferSize/2])
+	cb.Write(audio[cb.bufferSize/2:])
+	require.True(t, cb.wrapped)
+	assert.Equal(t, 3*sampleRate*bytesPerSample, cb.writeIndex, "writes continue at the start of the buffer")
+	assert.Equal(t, cb.lastWrite.Add(-4096*time.Millisecond), cb.startTime, "buffer size is rounded up to 4.096s")
+
+	// The source delivers 0.1% more audio than its nominal rate
+	cb.drift.ratio = 1.001
+	segment, err := cb.ReadSegment(context.Background(), cb.lastWrite.Add(-3*time.Second), 1, time.Second)
+	require.NoError(t, err)
+
+	back := 3 * sampleRate * bytesPerSample * 1001 / 1000
+	start := len(audio) - back
+	require.Len(t, segment, back/3)
+	assert.Equal(t, audio[start:start+len(segment)], segment)
+
+	_, err = cb.ReadSegment(context.Background(), cb.lastWrite.Add(-5*time.Second), 1, time.Second)
+	require.Error(t, err, "audio older than the buffer")
+}
+
+func TestClockDrift(t *testing.T) {
+	t.Parallel()
+
+	const bytesPerSecond = 96000
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// deliver writes numBytes of audio every 100ms
+	deliver := func(d *clockDrift, from time.Time, duration time.Duration, numBytes int) time.Time {
+		now := from
+		for end := from.Add(duration); now.Before(end); now = now.Add(100 * time.Millisecond) {
+			d.update(now, numBytes)
+		}
+		return now
+	}
+
+	t.Run("measures fast source", func(t *testing.T) {
+		t.Parallel()
+		d := newClockDrift(bytesPerSecond)
+		deliver(&d, start, 2*driftWindow+time.Second, 9602)
+		assert.InDelta(t, 208, d.ppm(), 1)
+		assert.InDelta(t, 96020, d.bytesPerSecond(), 0.1)
+	})
+
+	t.Run("first window is skipped", func(t *testing.T) {
+		t.Parallel()
+		d := newClockDrift(bytesPerSecond)
+		deliver(&d, start, driftWindow+time.Second, 9602)
+		assert.InDelta(t, 0, d.ppm(), 0)
+	})
+
+	t.Run("stalls restart the measurement", func(t *testing.T) {
+		t.Parallel()
+		d := newClockDrift(bytesPerSecond)
+		now := deliver(&d, start, driftWindow-time.Minute, 9600)
+		deliver(&d, now.Add(time.Minute), 2*driftWindow+time.Second, 9600)
+		assert.InDelta(t, 0, d.ppm(), 5, "the pause is not measured as slow clock")
+	})
+
+	t.Run("implausible rates are ignored", func(t *testing.T) {
+		t.Parallel()
+		d := newClockDrift(bytesPerSecond)
+		deliver(&d, start, 2*driftWindow+time.Second, 10080)
+		assert.InDelta(t, 0, d.ppm(), 0)
+	})
+}
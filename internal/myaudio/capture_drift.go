@@ -0,0 +1,82 @@
+// capture_drift.go: clock drift of audio sources against the wall clock
+package myaudio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// driftWindow is how long audio is counted for one drift measurement
+	driftWindow = 5 * time.Minute
+
+	// driftGap is the longest pause between writes that keeps a measurement going. Longer
+	// pauses are stalls or reconnects, not drift, and start a new measurement.
+	driftGap = 2 * time.Second
+
+	// maxDrift is the largest deviation from the nominal rate accepted as drift. Sound card
+	// and camera clocks are off by tens to hundreds of ppm; larger deviations are bursts of
+	// buffered audio or lost packets.
+	maxDrift = 0.005
+)
+
+// clockDrift estimates the rate a source actually delivers audio at from the bytes received
+// over the wall clock. Sources run on their own clock: a camera sampling at 48000.5 Hz or a
+// sound card crystal off by 100 ppm delivers a few seconds more or less audio per day than
+// the nominal rate, which offsets clips extracted by wall clock time on long sessions.
+type clockDrift struct {
+	nominal     float64   // bytes per second at the nominal sample rate
+	windowStart time.Time // arrival of the write starting the current measurement
+	windowBytes int64     // bytes received after windowStart
+	lastWrite   time.Time
+	warmedUp    bool    // whether the first window after a (re)start was skipped
+	measured    bool    // whether ratio holds a measurement
+	ratio       float64 // delivered rate / nominal rate
+}
+
+// newClockDrift returns an estimator for a source with the given nominal byte rate
+func newClockDrift(bytesPerSecond int) clockDrift {
+	return clockDrift{nominal: float64(bytesPerSecond), ratio: 1}
+}
+
+// update records numBytes of audio arriving at now
+func (d *clockDrift) update(now time.Time, numBytes int) {
+	if d.windowStart.IsZero() || now.Sub(d.lastWrite) > driftGap {
+		// The audio of the first write was captured before the window starts
+		d.windowStart, d.windowBytes, d.warmedUp = now, 0, false
+		d.lastWrite = now
+		return
+	}
+	d.windowBytes += int64(numBytes)
+	d.lastWrite = now
+
+	elapsed := now.Sub(d.windowStart)
+	if elapsed < driftWindow {
+		return
+	}
+
+	// Sources deliver buffered audio in a burst when they connect, so the first window is
+	// skipped
+	if d.warmedUp {
+		measured := float64(d.windowBytes) / elapsed.Seconds() / d.nominal
+		if math.Abs(measured-1) <= maxDrift {
+			if d.measured {
+				d.ratio = (d.ratio + measured) / 2
+			} else {
+				d.ratio, d.measured = measured, true
+			}
+		}
+	}
+	d.windowStart, d.windowBytes, d.warmedUp = now, 0, true
+}
+
+// bytesPerSecond returns the rate the source delivers audio at
+func (d *clockDrift) bytesPerSecond() float64 {
+	return d.nominal * d.ratio
+}
+
+// ppm returns the drift of the source clock in parts per million, positive when the source
+// delivers more audio than its nominal rate
+func (d *clockDrift) ppm() float64 {
+	return (d.ratio - 1) * 1e6
+}
@@ -0,0 +1,38 @@
+// debug_dump.go: save analyzed audio chunks while a debug capture session is active
+package myaudio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
+)
+
+// debugDumpDir is the directory analysis chunks are saved to during debug capture
+var debugDumpDir = filepath.Join("debug", "myaudio", "pcm")
+
+// dumpAnalysisChunk saves a chunk passed to BirdNET as raw PCM when debug capture is
+// active for myaudio, so the exact input of a questionable detection can be inspected
+func dumpAnalysisChunk(sourceID string, data []byte, startTime time.Time) {
+	if !debugcapture.Active(debugcapture.ComponentMyAudio) {
+		return
+	}
+
+	// Source IDs are generated, but keep them from escaping the debug directory
+	safeID := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(sourceID)
+	dir := filepath.Join(debugDumpDir, safeID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		integrationLogger.Warn("Could not create debug PCM directory", "directory", dir, "error", err)
+		return
+	}
+
+	filename := filepath.Join(dir, fmt.Sprintf("chunk_%s.raw", startTime.Format("20060102_150405.000")))
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		integrationLogger.Warn("Could not save debug PCM file", "filename", filename, "error", err)
+		return
+	}
+	integrationLogger.Debug("Saved debug PCM file", "filename", filename, "source_id", sourceID)
+}
@@ -0,0 +1,171 @@
+// encoder_pool.go: shared pool running FFmpeg, SoX and native encoder jobs
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Defaults of the encoder pool when the settings leave them unset
+const (
+	defaultEncoderWorkers   = 2
+	defaultEncoderQueueSize = 32
+	defaultEncoderTimeout   = 30 * time.Second
+)
+
+// EncoderPool runs encoding jobs, such as clip exports, BirdWeather uploads and snapshot
+// encodes, on a bounded number of workers. FLAC without filters is encoded in process by the
+// native encoder (EncodeFLAC), only formats and filters it lacks start an FFmpeg or SoX
+// process, which is expensive on single board computers. Bursts of detections queue for a
+// worker instead of encoding or starting dozens of processes at once. Jobs beyond the queue
+// size are rejected and every job is limited to the pool timeout, not counting the time it
+// waited.
+type EncoderPool struct {
+	workers   chan struct{} // holds a token for every running job
+	timeout   time.Duration
+	queueSize int
+
+	mu     sync.Mutex
+	queued int // jobs waiting for a worker
+}
+
+// NewEncoderPool returns a pool running at most workers jobs at once with up to queueSize
+// jobs waiting, each limited to timeout
+func NewEncoderPool(workers, queueSize int, timeout time.Duration) *EncoderPool {
+	if workers < 1 {
+		workers = defaultEncoderWorkers
+	}
+	if queueSize < 0 {
+		queueSize = defaultEncoderQueueSize
+	}
+	if timeout <= 0 {
+		timeout = defaultEncoderTimeout
+	}
+	return &EncoderPool{
+		workers:   make(chan struct{}, workers),
+		timeout:   timeout,
+		queueSize: queueSize,
+	}
+}
+
+// Run waits for a free worker and runs job with a context limited to the pool timeout.
+// It returns an error without running the job when the queue is full or ctx is done
+// before a worker is free.
+func (p *EncoderPool) Run(ctx context.Context, operation string, job func(ctx context.Context) error) error {
+	select {
+	case p.workers <- struct{}{}:
+	default:
+		if err := p.wait(ctx, operation); err != nil {
+			return err
+		}
+	}
+	defer func() { <-p.workers }()
+
+	jobCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	err := job(jobCtx)
+	if err != nil && errors.Is(jobCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryTimeout).
+			Context("operation", operation).
+			Context("timeout_seconds", p.timeout.Seconds()).
+			Build()
+	}
+	return err
+}
+
+// wait queues for a worker
+func (p *EncoderPool) wait(ctx context.Context, operation string) error {
+	p.mu.Lock()
+	if p.queued >= p.queueSize {
+		queued := p.queued
+		p.mu.Unlock()
+		return errors.Newf("encoder queue is full").
+			Component("myaudio").
+			Category(errors.CategoryJobQueue).
+			Context("operation", operation).
+			Context("queued_jobs", queued).
+			Build()
+	}
+	p.queued++
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.queued--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errors.New(ctx.Err()).
+			Component("myaudio").
+			Category(errors.CategoryTimeout).
+			Context("operation", operation).
+			Context("stage", "encoder_queue").
+			Build()
+	}
+}
+
+// Queued returns the number of jobs waiting for a worker
+func (p *EncoderPool) Queued() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queued
+}
+
+// encoderPool is the shared encoder pool with the settings it was created from
+var encoderPool struct {
+	mu       sync.Mutex
+	pool     *EncoderPool
+	settings conf.EncoderSettings
+}
+
+// getEncoderPool returns the shared encoder pool of the current export settings. When the
+// worker count, queue size or timeout change, such as on a settings reload, a new pool
+// replaces the previous one. Jobs of the previous pool finish there, so the new limits apply
+// to all jobs once those are done.
+func getEncoderPool() *EncoderPool {
+	var settings conf.EncoderSettings
+	if s := conf.GetSettings(); s != nil {
+		settings = s.Realtime.Audio.Export.Encoder
+	}
+	settings.FlacEncoder = "" // the encoder choice does not change the pool
+
+	encoderPool.mu.Lock()
+	defer encoderPool.mu.Unlock()
+	if encoderPool.pool == nil || settings != encoderPool.settings {
+		encoderPool.pool = NewEncoderPool(settings.Workers, settings.QueueSize, time.Duration(settings.Timeout)*time.Second)
+		encoderPool.settings = settings
+	}
+	return encoderPool.pool
+}
+
+// RunEncoderJob runs an encoding job on the shared encoder pool
+func RunEncoderJob(ctx context.Context, operation string, job func(ctx context.Context) error) error {
+	return getEncoderPool().Run(ctx, operation, job)
+}
+
+// EncodeFLAC encodes PCM data to FLAC with the native encoder as a job of the shared encoder
+// pool, applying gainDB. It replaces FFmpeg runs that only apply a gain and encode FLAC.
+func EncodeFLAC(ctx context.Context, operation string, pcmData []byte, gainDB float64) (*bytes.Buffer, error) {
+	var audio *bytes.Buffer
+	err := RunEncoderJob(ctx, operation, func(ctx context.Context) error {
+		var err error
+		audio, err = encodePCMtoFLAC(ctx, pcmData, gainDB)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audio, nil
+}
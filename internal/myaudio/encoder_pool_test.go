@@ -0,0 +1,147 @@
+package myaudio
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+func TestEncoderPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limits running jobs", func(t *testing.T) {
+		t.Parallel()
+		pool := NewEncoderPool(2, 10, time.Second)
+
+		var running, peak atomic.Int32
+		var wg sync.WaitGroup
+		for range 6 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := pool.Run(context.Background(), "test", func(ctx context.Context) error {
+					n := running.Add(1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					running.Add(-1)
+					return nil
+				})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	// block occupies the only worker of a pool until release is closed
+	block := func(t *testing.T, pool *EncoderPool) (release chan struct{}) {
+		t.Helper()
+		release = make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_ = pool.Run(context.Background(), "test", func(ctx context.Context) error {
+				close(started)
+				<-release
+				return nil
+			})
+		}()
+		<-started
+		return release
+	}
+
+	t.Run("rejects jobs beyond the queue", func(t *testing.T) {
+		t.Parallel()
+		pool := NewEncoderPool(1, 1, time.Second)
+		release := block(t, pool)
+		defer close(release)
+
+		queued := make(chan error, 1)
+		go func() {
+			queued <- pool.Run(context.Background(), "test", func(ctx context.Context) error { return nil })
+		}()
+		require.Eventually(t, func() bool { return pool.Queued() == 1 }, time.Second, time.Millisecond)
+
+		err := pool.Run(context.Background(), "test", func(ctx context.Context) error { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "encoder queue is full")
+
+		release <- struct{}{}
+		assert.NoError(t, <-queued, "queued job runs once a worker is free")
+	})
+
+	t.Run("stops waiting when canceled", func(t *testing.T) {
+		t.Parallel()
+		pool := NewEncoderPool(1, 1, time.Second)
+		release := block(t, pool)
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		ran := false
+		err := pool.Run(ctx, "test", func(ctx context.Context) error { ran = true; return nil })
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.False(t, ran)
+		assert.Zero(t, pool.Queued())
+	})
+
+	t.Run("limits job duration", func(t *testing.T) {
+		t.Parallel()
+		pool := NewEncoderPool(1, 1, 50*time.Millisecond)
+
+		began := time.Now()
+		err := pool.Run(context.Background(), "test", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.Error(t, err)
+		var enhanced *errors.EnhancedError
+		require.ErrorAs(t, err, &enhanced)
+		assert.Equal(t, errors.CategoryTimeout, enhanced.Category)
+		assert.Less(t, time.Since(began), time.Second)
+	})
+}
+
+// Not parallel, the test replaces the global settings
+func TestGetEncoderPoolFollowsSettings(t *testing.T) {
+	defer conf.SetTestSettings(conf.GetSettings())
+
+	settings := conf.GetTestSettings()
+	settings.Realtime.Audio.Export.Encoder = conf.EncoderSettings{Workers: 2, QueueSize: 4, Timeout: 30, FlacEncoder: "native"}
+	conf.SetTestSettings(settings)
+	pool := getEncoderPool()
+	assert.Same(t, pool, getEncoderPool(), "unchanged settings keep the pool")
+
+	settings.Realtime.Audio.Export.Encoder.FlacEncoder = "ffmpeg"
+	assert.Same(t, pool, getEncoderPool(), "the FLAC encoder choice keeps the pool")
+
+	settings.Realtime.Audio.Export.Encoder.Workers = 1
+	resized := getEncoderPool()
+	assert.NotSame(t, pool, resized, "a new worker count replaces the pool")
+	assert.Equal(t, 1, cap(resized.workers))
+}
+
+func TestEncodeFLAC(t *testing.T) {
+	t.Parallel()
+
+	pcm := make([]byte, conf.SampleRate*conf.BitDepth/8)
+	audio, err := EncodeFLAC(context.Background(), "test", pcm, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "fLaC", audio.String()[:4])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = EncodeFLAC(ctx, "test", pcm, 0)
+	require.ErrorIs(t, err, context.Canceled)
+}
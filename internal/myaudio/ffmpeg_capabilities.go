@@ -0,0 +1,192 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// ffmpegProbeTimeout limits how long each FFmpeg capability query may take
+const ffmpegProbeTimeout = 10 * time.Second
+
+// ffmpegMinimumMajor is the oldest FFmpeg major version exports are tested with
+const ffmpegMinimumMajor = 4
+
+// ffmpegEncoders are the audio encoders exports and streaming may use
+var ffmpegEncoders = []string{"aac", "libfdk_aac", "libmp3lame", "libshine", "libopus", "opus", "flac", "alac", "pcm_s16le"}
+
+// encoderFallbacks lists the encoders used in place of an encoder the FFmpeg build lacks
+var encoderFallbacks = map[string][]string{
+	"libopus":    {"opus"},
+	"libmp3lame": {"libshine"},
+	"aac":        {"libfdk_aac"},
+}
+
+// experimentalEncoders need -strict experimental to be used
+var experimentalEncoders = map[string]bool{"opus": true}
+
+// ffmpegVersionPattern matches release versions such as "6.1.1-3ubuntu5" or "n7.0"
+var ffmpegVersionPattern = regexp.MustCompile(`ffmpeg version n?(\d+)\.(\d+)`)
+
+// FFmpegCapabilities describes the version and the features of an FFmpeg build
+type FFmpegCapabilities struct {
+	Path       string    `json:"path"`
+	Version    string    `json:"version"`            // Version string as reported by FFmpeg
+	Major      int       `json:"major"`              // Major version, 0 for git builds
+	Minor      int       `json:"minor"`              // Minor version
+	Encoders   []string  `json:"encoders"`           // Available encoders of ffmpegEncoders
+	Loudnorm   bool      `json:"loudnorm"`           // true if the loudnorm filter is available
+	Warnings   []string  `json:"warnings,omitempty"` // Incompatibilities found
+	DetectedAt time.Time `json:"detectedAt"`         // When the capabilities were detected
+}
+
+// ffmpegCapabilities holds the capabilities of the configured FFmpeg once detected
+var ffmpegCapabilities atomic.Pointer[FFmpegCapabilities]
+
+// GetFFmpegCapabilities returns the detected capabilities of the configured FFmpeg, or nil if
+// they have not been detected
+func GetFFmpegCapabilities() *FFmpegCapabilities {
+	return ffmpegCapabilities.Load()
+}
+
+// DetectFFmpegCapabilities queries the version, encoders and filters of an FFmpeg binary and
+// stores the result for argument selection. Exports use default arguments until detection
+// has succeeded.
+func DetectFFmpegCapabilities(ctx context.Context, ffmpegPath string) (*FFmpegCapabilities, error) {
+	if err := validateFFmpegPath(ffmpegPath); err != nil {
+		return nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "detect_ffmpeg_capabilities").
+			Build()
+	}
+
+	versionOutput, err := runFFmpegProbe(ctx, ffmpegPath, "-version")
+	if err != nil {
+		return nil, err
+	}
+	encodersOutput, err := runFFmpegProbe(ctx, ffmpegPath, "-encoders")
+	if err != nil {
+		return nil, err
+	}
+	filtersOutput, err := runFFmpegProbe(ctx, ffmpegPath, "-filters")
+	if err != nil {
+		return nil, err
+	}
+
+	caps := parseFFmpegCapabilities(versionOutput, encodersOutput, filtersOutput)
+	caps.Path = ffmpegPath
+	caps.DetectedAt = time.Now()
+	ffmpegCapabilities.Store(caps)
+	return caps, nil
+}
+
+// runFFmpegProbe runs FFmpeg with a single informational option and returns its output
+func runFFmpegProbe(ctx context.Context, ffmpegPath, option string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ffmpegProbeTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", option) //nolint:gosec // G204: ffmpegPath is validated, option is a constant
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.New(fmt.Errorf("FFmpeg %s failed: %w, stderr: %s", option, err, stderr.String())).
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "detect_ffmpeg_capabilities").
+			Context("option", option).
+			Build()
+	}
+	return stdout.String(), nil
+}
+
+// parseFFmpegCapabilities builds the capabilities from the output of ffmpeg -version,
+// -encoders and -filters
+func parseFFmpegCapabilities(versionOutput, encodersOutput, filtersOutput string) *FFmpegCapabilities {
+	caps := &FFmpegCapabilities{}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(versionOutput), "\n")
+	if version, found := strings.CutPrefix(firstLine, "ffmpeg version "); found {
+		caps.Version, _, _ = strings.Cut(version, " ")
+	}
+	if match := ffmpegVersionPattern.FindStringSubmatch(firstLine); match != nil {
+		caps.Major, _ = strconv.Atoi(match[1])
+		caps.Minor, _ = strconv.Atoi(match[2])
+	}
+
+	available := parseFFmpegListNames(encodersOutput)
+	for _, encoder := range ffmpegEncoders {
+		if available[encoder] {
+			caps.Encoders = append(caps.Encoders, encoder)
+		}
+	}
+	caps.Loudnorm = parseFFmpegListNames(filtersOutput)["loudnorm"]
+
+	if caps.Major > 0 && caps.Major < ffmpegMinimumMajor {
+		caps.Warnings = append(caps.Warnings, fmt.Sprintf("FFmpeg %s is older than %d.0, audio exports may fail", caps.Version, ffmpegMinimumMajor))
+	}
+	if !caps.Loudnorm {
+		caps.Warnings = append(caps.Warnings, "loudnorm filter is missing, audio clip normalization is disabled")
+	}
+	for _, encoder := range []string{"aac", "libopus", "libmp3lame", "flac"} {
+		if caps.HasEncoder(encoder) {
+			continue
+		}
+		if fallback := caps.Encoder(encoder); fallback != encoder {
+			caps.Warnings = append(caps.Warnings, fmt.Sprintf("encoder %s is missing, using %s instead", encoder, fallback))
+		} else {
+			caps.Warnings = append(caps.Warnings, fmt.Sprintf("encoder %s is missing, exports using it will fail", encoder))
+		}
+	}
+	return caps
+}
+
+// parseFFmpegListNames returns the names listed by ffmpeg -encoders or -filters. Each entry
+// is a line of capability flags followed by the name; legend lines are skipped.
+func parseFFmpegListNames(output string) map[string]bool {
+	names := make(map[string]bool)
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] == "=" || strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		names[fields[1]] = true
+	}
+	return names
+}
+
+// HasEncoder reports whether the FFmpeg build has an encoder
+func (c *FFmpegCapabilities) HasEncoder(encoder string) bool {
+	return slices.Contains(c.Encoders, encoder)
+}
+
+// Encoder returns the encoder to use for a preferred encoder: the preferred encoder when
+// available or unknown, otherwise the first available fallback
+func (c *FFmpegCapabilities) Encoder(preferred string) string {
+	if c == nil || c.HasEncoder(preferred) {
+		return preferred
+	}
+	for _, fallback := range encoderFallbacks[preferred] {
+		if c.HasEncoder(fallback) {
+			return fallback
+		}
+	}
+	return preferred
+}
+
+// loudnormAvailable reports whether the loudnorm filter can be used, assuming it can while
+// capabilities are unknown
+func loudnormAvailable() bool {
+	caps := GetFFmpegCapabilities()
+	return caps == nil || caps.Loudnorm
+}
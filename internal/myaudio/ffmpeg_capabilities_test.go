@@ -0,0 +1,109 @@
+package myaudio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+const testEncodersOutput = `Encoders:
+ V..... = Video
+ A..... = Audio
+ ------
+ A....D aac                  AAC (Advanced Audio Coding)
+ A..... flac                 FLAC (Free Lossless Audio Codec)
+ A..X.. opus                 Opus
+ A..... libshine             libshine MP3 (MPEG audio layer 3)
+`
+
+const testFiltersOutput = `Filters:
+  T.. = Timeline support
+  | = Source or sink filter
+ ... anull             A->A       Pass the source unchanged to the output.
+ ... volume            A->A       Change input volume.
+`
+
+func TestParseFFmpegCapabilities(t *testing.T) {
+	caps := parseFFmpegCapabilities("ffmpeg version 3.4.8-0ubuntu0.2 Copyright (c) 2000-2020 the FFmpeg developers\nbuilt with gcc 7",
+		testEncodersOutput, testFiltersOutput)
+
+	assert.Equal(t, "3.4.8-0ubuntu0.2", caps.Version)
+	assert.Equal(t, 3, caps.Major)
+	assert.Equal(t, 4, caps.Minor)
+	assert.Equal(t, []string{"aac", "libshine", "opus", "flac"}, caps.Encoders)
+	assert.False(t, caps.Loudnorm)
+	assert.Equal(t, []string{
+		"FFmpeg 3.4.8-0ubuntu0.2 is older than 4.0, audio exports may fail",
+		"loudnorm filter is missing, audio clip normalization is disabled",
+		"encoder libopus is missing, using opus instead",
+		"encoder libmp3lame is missing, using libshine instead",
+	}, caps.Warnings)
+
+	assert.Equal(t, "opus", caps.Encoder("libopus"))
+	assert.Equal(t, "libshine", caps.Encoder("libmp3lame"))
+	assert.Equal(t, "aac", caps.Encoder("aac"))
+	assert.Equal(t, "alac", caps.Encoder("alac"), "encoders without fallback are kept")
+
+	var unknown *FFmpegCapabilities
+	assert.Equal(t, "libopus", unknown.Encoder("libopus"), "encoders are kept while capabilities are unknown")
+}
+
+func TestParseFFmpegCapabilitiesGitBuild(t *testing.T) {
+	caps := parseFFmpegCapabilities("ffmpeg version N-113046-g8f5b1a6 Copyright (c) 2000-2024", "",
+		" ... loudnorm          A->A       EBU R128 loudness normalization\n")
+
+	assert.Equal(t, "N-113046-g8f5b1a6", caps.Version)
+	assert.Zero(t, caps.Major, "git builds have no release version")
+	assert.True(t, caps.Loudnorm)
+	assert.NotContains(t, caps.Warnings, "loudnorm filter is missing, audio clip normalization is disabled")
+}
+
+func TestParseLoudnessStats(t *testing.T) {
+	// FFmpeg 4 and later print string values including target_offset
+	stats, err := parseLoudnessStats(`[Parsed_loudnorm_0 @ 0x55] 
+{
+	"input_i" : "-27.61",
+	"input_tp" : "-4.47",
+	"input_lra" : "18.06",
+	"input_thresh" : "-39.20",
+	"output_i" : "-16.58",
+	"output_tp" : "-1.50",
+	"output_lra" : "14.78",
+	"output_thresh" : "-27.71",
+	"normalization_type" : "dynamic",
+	"target_offset" : "0.58"
+}`)
+	require.NoError(t, err)
+	assert.Equal(t, "-27.61", stats.InputI)
+	assert.Equal(t, "0.58", stats.TargetOffset)
+
+	// Older builds lack target_offset and normalization_type
+	stats, err = parseLoudnessStats(`{"input_i": -27.61, "input_tp": "-4.47", "input_lra": "18.06", "input_thresh": "-39.20"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "-27.61", stats.InputI)
+	assert.Empty(t, stats.TargetOffset)
+
+	_, err = parseLoudnessStats("no statistics")
+	require.Error(t, err)
+	_, err = parseLoudnessStats(`{"output_i": "-16.58"}`)
+	require.Error(t, err)
+}
+
+func TestBuildAudioFilterWithoutLoudnorm(t *testing.T) {
+	settings := &conf.AudioSettings{
+		Export: conf.ExportSettings{
+			Gain:          3.0,
+			Normalization: conf.NormalizationSettings{Enabled: true, TargetLUFS: -23.0, TruePeak: -2.0, LoudnessRange: 7.0},
+		},
+	}
+
+	ffmpegCapabilities.Store(&FFmpegCapabilities{Encoders: []string{"aac", "opus"}})
+	t.Cleanup(func() { ffmpegCapabilities.Store(nil) })
+
+	assert.Equal(t, "volume=+3.0dB", buildAudioFilter(settings), "normalization falls back to gain without loudnorm")
+	assert.Equal(t, "opus", getEncoder("opus"))
+	assert.Equal(t, []string{"-strict", "experimental"}, appendEncoderOptions(nil, getEncoder("opus")))
+	assert.Empty(t, appendEncoderOptions(nil, getEncoder("aac")))
+}
@@ -0,0 +1,154 @@
+package myaudio
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// maxTrackedFFmpegErrors limits the number of error fingerprints kept in memory. When the
+// limit is reached the least recently seen fingerprint is dropped.
+const maxTrackedFFmpegErrors = 200
+
+// FFmpegErrorSummary describes a recurring FFmpeg error identified by its fingerprint
+type FFmpegErrorSummary struct {
+	Fingerprint string           `json:"fingerprint"` // hash of the normalized message
+	Message     string           `json:"message"`     // normalized message without credentials, addresses or numbers
+	Count       int64            `json:"count"`       // occurrences on all streams
+	FirstSeen   time.Time        `json:"firstSeen"`
+	LastSeen    time.Time        `json:"lastSeen"`
+	Streams     map[string]int64 `json:"streams"` // occurrences per sanitized stream URL
+}
+
+// FFmpegStreamFailures summarizes the recorded FFmpeg errors of one stream
+type FFmpegStreamFailures struct {
+	URL            string    `json:"url"`            // sanitized stream URL
+	Count          int64     `json:"count"`          // errors on this stream
+	Fingerprints   int       `json:"fingerprints"`   // distinct errors on this stream
+	TopFingerprint string    `json:"topFingerprint"` // most frequent error on this stream
+	LastSeen       time.Time `json:"lastSeen"`
+}
+
+// ffmpegErrorStats counts FFmpeg errors per fingerprint and stream
+type ffmpegErrorStats struct {
+	mu     sync.Mutex
+	errors map[string]*FFmpegErrorSummary
+}
+
+// ffmpegErrors holds the FFmpeg error counts of all streams since startup
+var ffmpegErrors = &ffmpegErrorStats{errors: make(map[string]*FFmpegErrorSummary)}
+
+// record counts one occurrence of an error on the stream
+func (s *ffmpegErrorStats) record(streamURL, errorMessage string, now time.Time) {
+	fingerprint := privacy.FFmpegErrorFingerprint(errorMessage)
+	streamURL = privacy.SanitizeRTSPUrl(streamURL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary, ok := s.errors[fingerprint]
+	if !ok {
+		if len(s.errors) >= maxTrackedFFmpegErrors {
+			s.evictOldest()
+		}
+		summary = &FFmpegErrorSummary{
+			Fingerprint: fingerprint,
+			Message:     privacy.NormalizeFFmpegError(errorMessage),
+			FirstSeen:   now,
+			Streams:     make(map[string]int64),
+		}
+		s.errors[fingerprint] = summary
+	}
+	summary.Count++
+	summary.LastSeen = now
+	summary.Streams[streamURL]++
+}
+
+// evictOldest drops the least recently seen fingerprint. The caller must hold mu.
+func (s *ffmpegErrorStats) evictOldest() {
+	var oldest *FFmpegErrorSummary
+	for _, summary := range s.errors {
+		if oldest == nil || summary.LastSeen.Before(oldest.LastSeen) {
+			oldest = summary
+		}
+	}
+	if oldest != nil {
+		delete(s.errors, oldest.Fingerprint)
+	}
+}
+
+// topErrors returns copies of the most frequent errors, at most limit
+func (s *ffmpegErrorStats) topErrors(limit int) []FFmpegErrorSummary {
+	s.mu.Lock()
+	result := make([]FFmpegErrorSummary, 0, len(s.errors))
+	for _, summary := range s.errors {
+		summaryCopy := *summary
+		summaryCopy.Streams = make(map[string]int64, len(summary.Streams))
+		for url, count := range summary.Streams {
+			summaryCopy.Streams[url] = count
+		}
+		result = append(result, summaryCopy)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(result, func(a, b FFmpegErrorSummary) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), b.LastSeen.Compare(a.LastSeen), cmp.Compare(a.Fingerprint, b.Fingerprint))
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// topStreams returns the streams with the most errors, at most limit
+func (s *ffmpegErrorStats) topStreams(limit int) []FFmpegStreamFailures {
+	s.mu.Lock()
+	streams := make(map[string]*FFmpegStreamFailures)
+	topCounts := make(map[string]int64)
+	for _, summary := range s.errors {
+		for url, count := range summary.Streams {
+			stream, ok := streams[url]
+			if !ok {
+				stream = &FFmpegStreamFailures{URL: url}
+				streams[url] = stream
+			}
+			stream.Count += count
+			stream.Fingerprints++
+			if summary.LastSeen.After(stream.LastSeen) {
+				stream.LastSeen = summary.LastSeen
+			}
+			if count > topCounts[url] || (count == topCounts[url] && summary.Fingerprint < stream.TopFingerprint) {
+				topCounts[url] = count
+				stream.TopFingerprint = summary.Fingerprint
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	result := make([]FFmpegStreamFailures, 0, len(streams))
+	for _, stream := range streams {
+		result = append(result, *stream)
+	}
+	slices.SortFunc(result, func(a, b FFmpegStreamFailures) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.URL, b.URL))
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// GetTopFFmpegErrors returns the most frequent FFmpeg errors since startup, at most limit,
+// with the number of occurrences on each stream
+func GetTopFFmpegErrors(limit int) []FFmpegErrorSummary {
+	return ffmpegErrors.topErrors(limit)
+}
+
+// GetTopFailingStreams returns the streams with the most FFmpeg errors since startup,
+// at most limit
+func GetTopFailingStreams(limit int) []FFmpegStreamFailures {
+	return ffmpegErrors.topStreams(limit)
+}
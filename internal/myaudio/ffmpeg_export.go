@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -267,16 +268,19 @@ func finalizeOutput(tempFilePath string) error {
 	return nil
 }
 
-// runFFmpegCommand executes the FFmpeg command to process the audio
-// This version includes a context timeout to prevent hangs.
+// runFFmpegCommand executes the FFmpeg command to process the audio on the encoder pool,
+// which limits how long it may run to prevent hangs.
 func runFFmpegCommand(ffmpegPath string, pcmData []byte, tempFilePath string, settings *conf.AudioSettings) error {
+	return RunEncoderJob(context.Background(), "export_audio_ffmpeg", func(ctx context.Context) error {
+		return runFFmpegExport(ctx, ffmpegPath, pcmData, tempFilePath, settings)
+	})
+}
+
+// runFFmpegExport runs FFmpeg to export PCM data to tempFilePath
+func runFFmpegExport(ctx context.Context, ffmpegPath string, pcmData []byte, tempFilePath string, settings *conf.AudioSettings) error {
 	// Build the FFmpeg command arguments
 	args := buildFFmpegArgs(tempFilePath, settings)
 
-	// Create a context with a timeout (e.g., 30 seconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// Create the FFmpeg command with context
 	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
 
@@ -372,8 +376,9 @@ func buildFFmpegArgs(tempFilePath string, settings *conf.AudioSettings) []string
 	}
 
 	// Add output encoding settings
+	args = append(args, "-c:a", outputEncoder)
+	args = appendEncoderOptions(args, outputEncoder)
 	args = append(args,
-		"-c:a", outputEncoder,
 		"-b:a", outputBitrate,
 		"-f", outputFormat, // Specify the output format
 		"-y",         // Overwrite output file if it exists
@@ -385,8 +390,8 @@ func buildFFmpegArgs(tempFilePath string, settings *conf.AudioSettings) []string
 
 // buildAudioFilter constructs the audio filter string for FFmpeg
 func buildAudioFilter(settings *conf.AudioSettings) string {
-	// Normalization takes precedence over gain
-	if settings.Export.Normalization.Enabled {
+	// Normalization takes precedence over gain, FFmpeg builds without loudnorm fall back to gain
+	if settings.Export.Normalization.Enabled && loudnormAvailable() {
 		// Use loudnorm filter for EBU R128 normalization
 		// Format: loudnorm=I=target:TP=truepeak:LRA=range
 		return fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f",
@@ -408,22 +413,33 @@ func buildAudioFilter(settings *conf.AudioSettings) string {
 	return "" // No audio filtering needed
 }
 
-// getCodec returns the appropriate codec to use with FFmpeg based on the format
+// getCodec returns the appropriate codec to use with FFmpeg based on the format, replaced by a
+// fallback encoder when the detected FFmpeg build lacks it
 func getEncoder(format string) string {
+	var encoder string
 	switch format {
 	case "flac":
-		return "flac"
+		encoder = "flac"
 	case "alac":
-		return "alac"
+		encoder = "alac"
 	case "opus":
-		return "libopus"
+		encoder = "libopus"
 	case "aac":
-		return "aac"
+		encoder = "aac"
 	case "mp3":
-		return "libmp3lame"
+		encoder = "libmp3lame"
 	default:
-		return format
+		encoder = format
+	}
+	return GetFFmpegCapabilities().Encoder(encoder)
+}
+
+// appendEncoderOptions adds the options an encoder needs to be usable
+func appendEncoderOptions(args []string, encoder string) []string {
+	if experimentalEncoders[encoder] {
+		args = append(args, "-strict", "experimental")
 	}
+	return args
 }
 
 // getOutputFormat returns the appropriate output format for FFmpeg based on the export type
@@ -541,7 +557,12 @@ func ExportAudioWithCustomFFmpegArgsContext(ctx context.Context, pcmData []byte,
 	}
 
 	// Run the FFmpeg command, capturing output to a buffer
-	outputBuffer, err := runCustomFFmpegCommandToBufferWithContext(ctx, ffmpegPath, pcmData, customArgs)
+	var outputBuffer *bytes.Buffer
+	err := RunEncoderJob(ctx, "export_custom_ffmpeg", func(ctx context.Context) error {
+		var err error
+		outputBuffer, err = runCustomFFmpegCommandToBufferWithContext(ctx, ffmpegPath, pcmData, customArgs)
+		return err
+	})
 	if err != nil {
 		enhancedErr := errors.New(err).
 			Component("myaudio").
@@ -572,6 +593,13 @@ func ExportAudioWithCustomFFmpegArgsContext(ctx context.Context, pcmData []byte,
 // runCustomFFmpegCommandToBufferWithContext executes FFmpeg, piping PCM input and capturing codec output to a buffer.
 // This version accepts a context to allow for timeout/cancellation.
 func runCustomFFmpegCommandToBufferWithContext(ctx context.Context, ffmpegPath string, pcmData []byte, customArgs []string) (*bytes.Buffer, error) {
+	var stderr bytes.Buffer
+	return runFFmpegToBuffer(ctx, ffmpegPath, pcmData, customArgs, &stderr)
+}
+
+// runFFmpegToBuffer executes FFmpeg like runCustomFFmpegCommandToBufferWithContext, writing
+// the diagnostic output of FFmpeg to stderr
+func runFFmpegToBuffer(ctx context.Context, ffmpegPath string, pcmData []byte, customArgs []string, stderr *bytes.Buffer) (*bytes.Buffer, error) {
 	// Get standard input format arguments
 	ffmpegSampleRate, ffmpegNumChannels, ffmpegFormat := getFFmpegFormat(conf.SampleRate, conf.NumChannels, conf.BitDepth)
 
@@ -604,8 +632,7 @@ func runCustomFFmpegCommandToBufferWithContext(ctx context.Context, ffmpegPath s
 	}
 
 	// Capture stderr for better error reporting
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	cmd.Stderr = stderr
 
 	// Start the FFmpeg command
 	if err := cmd.Start(); err != nil {
@@ -747,6 +774,17 @@ func AnalyzeAudioLoudnessWithContext(ctx context.Context, pcmData []byte, ffmpeg
 		return nil, fmt.Errorf("FFmpeg path provided is empty")
 	}
 
+	var stats *LoudnessStats
+	err := RunEncoderJob(ctx, "analyze_loudness", func(ctx context.Context) error {
+		var err error
+		stats, err = analyzeLoudness(ctx, pcmData, ffmpegPath)
+		return err
+	})
+	return stats, err
+}
+
+// analyzeLoudness runs the FFmpeg loudnorm analysis of PCM data
+func analyzeLoudness(ctx context.Context, pcmData []byte, ffmpegPath string) (*LoudnessStats, error) {
 	// Get standard input format arguments
 	ffmpegSampleRate, ffmpegNumChannels, ffmpegFormat := getFFmpegFormat(conf.SampleRate, conf.NumChannels, conf.BitDepth)
 
@@ -825,22 +863,96 @@ func AnalyzeAudioLoudnessWithContext(ctx context.Context, pcmData []byte, ffmpeg
 		// We'll continue parsing the output from stderr instead of returning an error
 	}
 
-	// Extract the JSON from the stderr output
-	stderrStr := stderr.String()
-	jsonStartIdx := strings.Index(stderrStr, "{")
-	jsonEndIdx := strings.LastIndex(stderrStr, "}")
+	return parseLoudnessStats(stderr.String())
+}
+
+// ExportAudioWithLoudnormContext encodes PCM data with FFmpeg, normalizing it in a single pass
+// with the dynamic mode of the loudnorm filter instead of analyzing it first. outputArgs select
+// the codec and format of the output. The returned statistics are the ones loudnorm reports for
+// its input and output, nil if FFmpeg did not print them.
+func ExportAudioWithLoudnormContext(ctx context.Context, pcmData []byte, ffmpegPath string, targetLUFS, loudnessRange, truePeak float64, outputArgs []string) (*bytes.Buffer, *LoudnessStats, error) {
+	if ffmpegPath == "" {
+		return nil, nil, errors.Newf("FFmpeg path provided is empty").
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "export_loudnorm_ffmpeg").
+			Build()
+	}
+	if len(pcmData) == 0 {
+		return nil, nil, errors.Newf("empty PCM data provided for loudnorm export").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "export_loudnorm_ffmpeg").
+			Build()
+	}
+
+	filter := fmt.Sprintf("loudnorm=I=%.1f:LRA=%.1f:TP=%.1f:print_format=json", targetLUFS, loudnessRange, truePeak)
+	args := append([]string{"-af", filter}, outputArgs...)
+
+	var outputBuffer *bytes.Buffer
+	var stderr bytes.Buffer
+	err := RunEncoderJob(ctx, "export_loudnorm_ffmpeg", func(ctx context.Context) error {
+		stderr.Reset()
+		var err error
+		outputBuffer, err = runFFmpegToBuffer(ctx, ffmpegPath, pcmData, args, &stderr)
+		return err
+	})
+	if err != nil {
+		return nil, nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "export_loudnorm_ffmpeg").
+			Build()
+	}
+
+	stats, err := parseLoudnessStats(stderr.String())
+	if err != nil {
+		return outputBuffer, nil, nil
+	}
+	return outputBuffer, stats, nil
+}
+
+// parseLoudnessStats extracts the loudnorm statistics from FFmpeg output. Values are accepted
+// as strings or numbers and fields missing in the output of older FFmpeg versions, such as
+// target_offset, are left empty.
+func parseLoudnessStats(output string) (*LoudnessStats, error) {
+	jsonStartIdx := strings.Index(output, "{")
+	jsonEndIdx := strings.LastIndex(output, "}")
 	if jsonStartIdx == -1 || jsonEndIdx == -1 || jsonEndIdx < jsonStartIdx {
-		return nil, fmt.Errorf("failed to extract JSON from FFmpeg output: %s", stderrStr)
+		return nil, fmt.Errorf("failed to extract JSON from FFmpeg output: %s", output)
 	}
-	jsonStr := stderrStr[jsonStartIdx : jsonEndIdx+1]
 
-	// Parse the JSON output
-	var stats LoudnessStats
-	if err := json.Unmarshal([]byte(jsonStr), &stats); err != nil {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(output[jsonStartIdx:jsonEndIdx+1]), &fields); err != nil {
 		return nil, fmt.Errorf("failed to parse FFmpeg loudnorm analysis: %w", err)
 	}
+	field := func(name string) string {
+		switch value := fields[name].(type) {
+		case string:
+			return value
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			return ""
+		}
+	}
 
-	return &stats, nil
+	stats := &LoudnessStats{
+		InputI:            field("input_i"),
+		InputTP:           field("input_tp"),
+		InputLRA:          field("input_lra"),
+		InputThresh:       field("input_thresh"),
+		OutputI:           field("output_i"),
+		OutputTP:          field("output_tp"),
+		OutputLRA:         field("output_lra"),
+		OutputThresh:      field("output_thresh"),
+		NormalizationType: field("normalization_type"),
+		TargetOffset:      field("target_offset"),
+	}
+	if stats.InputI == "" {
+		return nil, fmt.Errorf("FFmpeg loudnorm analysis has no input_i value")
+	}
+	return stats, nil
 }
 
 // EncodePCMtoWAVWithContext encodes PCM data in WAV format using context for cancellation/timeout
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/logging"
 	"github.com/tphakala/birdnet-go/internal/privacy"
@@ -39,11 +40,13 @@ func init() {
 		integrationLogger = slog.Default().With("service", "ffmpeg-input")
 		closeIntegrationLogger = func() error { return nil } // No-op closer
 	}
+
+	debugcapture.Register(debugcapture.ComponentMyAudio, integrationLevelVar, soundLevelLevelVar)
 }
 
 // UpdateFFmpegLogLevel updates the logger level based on configuration
 func UpdateFFmpegLogLevel() {
-	if conf.Setting().Debug {
+	if conf.Setting().Debug || debugcapture.Active(debugcapture.ComponentMyAudio) {
 		integrationLevelVar.Set(slog.LevelDebug)
 	} else {
 		integrationLevelVar.Set(slog.LevelInfo)
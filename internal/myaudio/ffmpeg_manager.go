@@ -56,15 +56,20 @@ type FFmpegManager struct {
 	ctx       context.Context
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
+
+	// unhealthy holds the streams reported unhealthy on the event bus, only accessed by
+	// checkStreamHealth
+	unhealthy map[string]bool
 }
 
 // NewFFmpegManager creates a new FFmpeg manager
 func NewFFmpegManager() *FFmpegManager {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &FFmpegManager{
-		streams: make(map[string]*FFmpegStream),
-		ctx:     ctx,
-		cancel:  cancel,
+		streams:   make(map[string]*FFmpegStream),
+		ctx:       ctx,
+		cancel:    cancel,
+		unhealthy: make(map[string]bool),
 	}
 }
 
@@ -84,6 +89,11 @@ func (m *FFmpegManager) StartStream(url, transport string, audioChan chan Unifie
 			Build()
 	}
 
+	// Reject streams whose declaration FFmpeg cannot be started for
+	if err := validateStreamDeclaration(url); err != nil {
+		return err
+	}
+
 	// Create new stream first to get the source ID
 	stream := NewFFmpegStream(url, transport, audioChan)
 
@@ -328,9 +338,12 @@ func (m *FFmpegManager) StartMonitoring(interval time.Duration) {
 	}()
 }
 
-// checkStreamHealth checks health of all streams
+// checkStreamHealth checks health of all streams, restarts unhealthy streams as the restart
+// policy allows, and publishes changes of their health on the event bus
 func (m *FFmpegManager) checkStreamHealth() {
 	health := m.HealthCheck()
+	m.forgetRemovedStreams(health)
+	m.recordStreamTiming(health)
 
 	if conf.Setting().Debug {
 		managerLogger.Debug("performing health check on all streams",
@@ -353,8 +366,15 @@ func (m *FFmpegManager) checkStreamHealth() {
 				continue
 			}
 
+			// Report streams still unhealthy after their startup, including streams failing
+			// to restart; stalled streams the restart policy leaves running are critical
+			restarting := stream.IsRestarting()
+			if time.Since(stream.streamCreatedAt) >= minimumStreamRuntime {
+				m.reportUnhealthy(url, stream, &h, restarting || restartPolicy() == conf.RTSPRestartAlways)
+			}
+
 			// Check if stream is already in the process of restarting
-			if stream.IsRestarting() {
+			if restarting {
 				if conf.Setting().Debug {
 					managerLogger.Debug("skipping restart for stream already in restart process",
 						"url", privacy.SanitizeRTSPUrl(url),
@@ -394,6 +414,16 @@ func (m *FFmpegManager) checkStreamHealth() {
 			log.Printf("⚠️ Unhealthy stream detected: %s (last data: %s ago)",
 				privacy.SanitizeRTSPUrl(url), formatTimeSinceData(h.LastDataReceived))
 
+			if restartPolicy() != conf.RTSPRestartAlways {
+				if conf.Setting().Debug {
+					managerLogger.Debug("restart policy leaves unhealthy stream running",
+						"url", privacy.SanitizeRTSPUrl(url),
+						"restart_policy", conf.RTSPRestartExit,
+						"operation", "health_check_skip_restart")
+				}
+				continue
+			}
+
 			// Restart unhealthy streams
 			if conf.Setting().Debug {
 				managerLogger.Debug("attempting to restart unhealthy stream",
@@ -425,18 +455,26 @@ func (m *FFmpegManager) checkStreamHealth() {
 					"url", privacy.SanitizeRTSPUrl(url),
 					"operation", "health_check_restart_success")
 			}
-		} else if conf.Setting().Debug {
+		} else {
+			m.streamsMu.RLock()
+			stream, exists := m.streams[url]
+			m.streamsMu.RUnlock()
+			if !exists {
+				continue
+			}
+			m.reportHealthy(url, stream, &h)
+
+			if !conf.Setting().Debug {
+				continue
+			}
+
 			// Get current PID for the stream
 			var currentPID int
-			m.streamsMu.RLock()
-			if stream, exists := m.streams[url]; exists {
-				stream.cmdMu.Lock()
-				if stream.cmd != nil && stream.cmd.Process != nil {
-					currentPID = stream.cmd.Process.Pid
-				}
-				stream.cmdMu.Unlock()
+			stream.cmdMu.Lock()
+			if stream.cmd != nil && stream.cmd.Process != nil {
+				currentPID = stream.cmd.Process.Pid
 			}
-			m.streamsMu.RUnlock()
+			stream.cmdMu.Unlock()
 
 			// Log healthy streams at debug level
 			managerLogger.Debug("stream is healthy",
@@ -209,7 +209,11 @@ type StreamHealth struct {
 	// Data statistics
 	TotalBytesReceived int64
 	BytesPerSecond     float64
+	FramesPerSecond    float64 // audio frames per second, conf.SampleRate for a live stream
 	IsReceivingData    bool
+	// Delivery timing
+	Latency time.Duration // delay above the fastest delivery of the last minutes
+	Jitter  time.Duration // smoothed variation of the delivery delay
 }
 
 // FFmpegStream manages a single FFmpeg process for audio streaming.
@@ -255,6 +259,7 @@ type FFmpegStream struct {
 	totalBytesReceived int64
 	bytesReceivedMu    sync.RWMutex
 	dataRateCalc       *dataRateCalculator
+	timing             streamTiming
 
 	// Process timing
 	processStartTime time.Time
@@ -315,6 +320,8 @@ func NewFFmpegStream(url, transport string, audioChan chan UnifiedAudioData) *FF
 	// Register or get existing source from registry
 	registry := GetRegistry()
 	var source *AudioSource
+
+	sourceType := StreamSourceType(url)
 	
 	if registry == nil {
 		log.Printf("⚠️ Registry not available during startup, creating fallback source: %s", privacy.SanitizeRTSPUrl(url))
@@ -323,14 +330,14 @@ func NewFFmpegStream(url, transport string, audioChan chan UnifiedAudioData) *FF
 		source = &AudioSource{
 			ID:               fallbackID,
 			DisplayName:      "RTSP Stream (Fallback)",
-			Type:             SourceTypeRTSP,
+			Type:             sourceType,
 			connectionString: url,
 			SafeString:       privacy.SanitizeRTSPUrl(url),
 			RegisteredAt:     time.Now(),
 			IsActive:         true,
 		}
 	} else {
-		source = registry.GetOrCreateSource(url, SourceTypeRTSP)
+		source = registry.GetOrCreateSource(url, sourceType)
 		if source == nil {
 			log.Printf("❌ Failed to register RTSP source: %s", privacy.SanitizeRTSPUrl(url))
 			// Create a fallback source for robustness with unique ID
@@ -338,7 +345,7 @@ func NewFFmpegStream(url, transport string, audioChan chan UnifiedAudioData) *FF
 			source = &AudioSource{
 				ID:               fallbackID,
 				DisplayName:      "RTSP Stream (Fallback)",
-				Type:             SourceTypeRTSP,
+				Type:             sourceType,
 				connectionString: url,
 				SafeString:       privacy.SanitizeRTSPUrl(url),
 				RegisteredAt:     time.Now(),
@@ -347,14 +354,15 @@ func NewFFmpegStream(url, transport string, audioChan chan UnifiedAudioData) *FF
 		}
 	}
 
+	backoff, maxBackoff := restartBackoff(&conf.Setting().Realtime.RTSP.Health)
 	return &FFmpegStream{
 		source:                         source,
 		transport:                      transport,
 		audioChan:                      audioChan,
 		restartChan:                    make(chan struct{}, 1),
 		stopChan:                       make(chan struct{}),
-		backoffDuration:                defaultBackoffDuration,
-		maxBackoff:                     maxBackoffDuration,
+		backoffDuration:                backoff,
+		maxBackoff:                     maxBackoff,
 		lastDataTime:                   time.Time{}, // Zero time - no data received yet
 		dataRateCalc:                   newDataRateCalculator(source.SafeString, dataRateWindowSize),
 		lastDropLogTime:                time.Now(),
@@ -416,6 +424,7 @@ func (s *FFmpegStream) Run(parentCtx context.Context) {
 					"component", "ffmpeg-stream",
 					"operation", "start_process")
 				log.Printf("❌ Failed to start FFmpeg for %s: %v", privacy.SanitizeRTSPUrl(s.source.SafeString), err)
+				ffmpegErrors.record(s.source.SafeString, err.Error(), time.Now())
 				s.recordFailure(0) // No runtime for startup failure
 				s.handleRestartBackoff()
 				continue
@@ -447,6 +456,8 @@ func (s *FFmpegStream) Run(parentCtx context.Context) {
 				// Log process exit with sanitized error message
 				errorMsg := err.Error()
 				sanitizedError := privacy.SanitizeFFmpegError(errorMsg)
+				// Count the error by fingerprint to find recurring failures
+				ffmpegErrors.record(s.source.SafeString, errorMsg, time.Now())
 
 				// Check if this was a silence timeout
 				isSilenceTimeout := strings.Contains(errorMsg, "silence timeout")
@@ -591,6 +602,17 @@ func (s *FFmpegStream) startProcess() error {
 			Build()
 	}
 
+	// Files, named pipes and streams of other protocols take no RTSP options
+	switch spec := ParseSourceSpec(connStr); spec.Backend {
+	case InputBackendFile:
+		connStr = spec.Device
+		args = fileInputArgs(connStr)
+	case InputBackendSRT, InputBackendRTMP, InputBackendHTTP, InputBackendUDP:
+		if args, connStr, err = streamInputArgs(spec, defaultTimeoutMicroseconds*time.Microsecond); err != nil {
+			return err
+		}
+	}
+
 	// Add input and output parameters
 	args = append(args,
 		"-i", connStr,
@@ -706,6 +728,7 @@ func (s *FFmpegStream) processAudio() error {
 	s.bytesReceivedMu.Lock()
 	s.totalBytesReceived = 0
 	s.bytesReceivedMu.Unlock()
+	s.timing.reset()
 
 	for {
 		// Set read deadline for timeout handling
@@ -755,8 +778,9 @@ func (s *FFmpegStream) processAudio() error {
 			totalReceived := s.totalBytesReceived
 			s.bytesReceivedMu.Unlock()
 
-			// Update data rate
+			// Update data rate and delivery timing
 			s.dataRateCalc.addSample(int64(n))
+			s.timing.addSample(time.Now(), n)
 
 			// Check if we should reset failures after stable operation
 			s.conditionalFailureReset(totalReceived)
@@ -812,7 +836,8 @@ func (s *FFmpegStream) processAudio() error {
 				return time.Since(lastData)
 			}()
 
-			if effectiveAge > 0 && effectiveAge > silenceTimeout {
+			// The exit restart policy leaves silent processes running, the manager reports them
+			if effectiveAge > 0 && effectiveAge > silenceTimeout && restartPolicy() == conf.RTSPRestartAlways {
 				streamLogger.Warn("no data received from RTSP source, triggering restart",
 					"url", privacy.SanitizeRTSPUrl(s.source.SafeString),
 					"timeout_seconds", silenceTimeout.Seconds(),
@@ -1367,8 +1392,9 @@ func (s *FFmpegStream) GetHealth() StreamHealth {
 	totalBytes := s.totalBytesReceived
 	s.bytesReceivedMu.RUnlock()
 
-	// Get current data rate
+	// Get current data rate and delivery timing
 	dataRate := s.dataRateCalc.getRate()
+	latency, jitter := s.timing.stats()
 
 	// Get configurable thresholds
 	settings := conf.Setting()
@@ -1434,7 +1460,10 @@ func (s *FFmpegStream) GetHealth() StreamHealth {
 		RestartCount:       restarts,
 		TotalBytesReceived: totalBytes,
 		BytesPerSecond:     dataRate,
+		FramesPerSecond:    dataRate / float64(conf.NumChannels*conf.BitDepth/8),
 		IsReceivingData:    isReceivingData,
+		Latency:            latency,
+		Jitter:             jitter,
 	}
 }
 
@@ -0,0 +1,138 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// transcodeTimeout limits how long transcoding a single clip may take
+const transcodeTimeout = 60 * time.Second
+
+// TranscodeAudioFile converts an audio file to another export format (mp3, opus, aac, flac)
+// with FFmpeg. The output is written to a temporary file and renamed once complete so readers
+// never see a partial file.
+func TranscodeAudioFile(ctx context.Context, ffmpegPath, inputPath, outputPath, format, bitrate string) error {
+	if err := validateFFmpegPath(ffmpegPath); err != nil {
+		return errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "transcode_audio_file").
+			Build()
+	}
+	if inputPath == "" || outputPath == "" {
+		return errors.Newf("empty input or output path provided for transcoding").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "transcode_audio_file").
+			Build()
+	}
+
+	tempFilePath, err := createTempFile(outputPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, transcodeTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, buildTranscodeArgs(inputPath, tempFilePath, format, bitrate)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(tempFilePath)
+		return errors.New(fmt.Errorf("FFmpeg transcoding failed: %w, stderr: %s", err, stderr.String())).
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "transcode_audio_file").
+			Context("format", format).
+			Build()
+	}
+
+	if err := finalizeOutput(tempFilePath); err != nil {
+		_ = os.Remove(tempFilePath)
+		return err
+	}
+	return nil
+}
+
+// buildTranscodeArgs constructs the FFmpeg arguments for transcoding an audio file
+func buildTranscodeArgs(inputPath, tempFilePath, format, bitrate string) []string {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+		"-vn", // Drop embedded cover art or other video streams
+		"-c:a", getEncoder(format),
+	}
+	args = appendEncoderOptions(args, getEncoder(format))
+	if bitrate != "" && format != "flac" && format != "alac" {
+		args = append(args, "-b:a", getMaxBitrate(format, bitrate))
+	}
+	return append(args,
+		"-f", getOutputFormat(format),
+		"-y",
+		tempFilePath,
+	)
+}
+
+// DecodeAudioFile decodes an audio file the file readers do not support, such as MP3, into a
+// 16-bit WAV file with the sample rate and channel count BirdNET analyzes. Unlike transcoding
+// it is limited only by ctx, since recordings may be hours long.
+func DecodeAudioFile(ctx context.Context, ffmpegPath, inputPath, outputPath string) error {
+	if err := validateFFmpegPath(ffmpegPath); err != nil {
+		return errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "decode_audio_file").
+			Build()
+	}
+	if inputPath == "" || outputPath == "" {
+		return errors.Newf("empty input or output path provided for decoding").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "decode_audio_file").
+			Build()
+	}
+
+	tempFilePath, err := createTempFile(outputPath)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+		"-vn",
+		"-ac", strconv.Itoa(conf.NumChannels),
+		"-ar", strconv.Itoa(conf.SampleRate),
+		"-c:a", "pcm_s16le",
+		"-f", "wav",
+		"-y",
+		tempFilePath,
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(tempFilePath)
+		return errors.New(fmt.Errorf("FFmpeg decoding failed: %w, stderr: %s", err, stderr.String())).
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "decode_audio_file").
+			Build()
+	}
+
+	if err := finalizeOutput(tempFilePath); err != nil {
+		_ = os.Remove(tempFilePath)
+		return err
+	}
+	return nil
+}
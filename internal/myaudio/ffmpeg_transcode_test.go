@@ -0,0 +1,24 @@
+package myaudio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTranscodeArgs(t *testing.T) {
+	args := buildTranscodeArgs("/clips/a.wav", "/cache/a.mp3.temp", "mp3", "512k")
+	assert.Equal(t, []string{
+		"-hide_banner", "-loglevel", "error",
+		"-i", "/clips/a.wav", "-vn",
+		"-c:a", "libmp3lame", "-b:a", "320k",
+		"-f", "mp3", "-y", "/cache/a.mp3.temp",
+	}, args)
+
+	args = buildTranscodeArgs("/clips/a.wav", "/cache/a.opus.temp", "opus", "128k")
+	assert.Contains(t, args, "libopus")
+	assert.Contains(t, args, "128k")
+
+	args = buildTranscodeArgs("/clips/a.wav", "/cache/a.flac.temp", "flac", "96k")
+	assert.NotContains(t, args, "-b:a", "lossless formats have no bitrate")
+}
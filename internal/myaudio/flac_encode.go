@@ -0,0 +1,483 @@
+// flac_encode.go: native FLAC encoder for installations without FFmpeg
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"os"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	flacBlockSize         = 4096 // samples per frame, the libFLAC default
+	flacMaxPartitionOrder = 8    // largest residual partition order tried
+	flacMaxRiceParameter  = 14   // 15 is the escape code of 4-bit Rice parameters
+)
+
+// EncodePCMtoFLAC encodes mono PCM data at conf.SampleRate and conf.BitDepth to FLAC in
+// memory, applying gainDB. Frames use FLAC's fixed predictors with partitioned Rice coding,
+// which compresses less than libFLAC's LPC but needs no FFmpeg.
+func EncodePCMtoFLAC(pcmData []byte, gainDB float64) (*bytes.Buffer, error) {
+	return encodePCMtoFLAC(context.Background(), pcmData, gainDB)
+}
+
+// encodePCMtoFLAC encodes PCM data to FLAC like EncodePCMtoFLAC, stopping with the context
+// error between frames once ctx is done
+func encodePCMtoFLAC(ctx context.Context, pcmData []byte, gainDB float64) (*bytes.Buffer, error) {
+	bytesPerSample := conf.BitDepth / 8
+	if len(pcmData) == 0 || len(pcmData)%bytesPerSample != 0 || conf.BitDepth != 16 {
+		return nil, errors.Newf("PCM data must be non-empty 16-bit samples for FLAC encoding").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "encode_pcm_to_flac").
+			Context("data_size", len(pcmData)).
+			Context("bit_depth", conf.BitDepth).
+			Build()
+	}
+
+	if gainDB != 0 {
+		pcmData = applyPCMGain(pcmData, gainDB)
+	}
+	samples := make([]int32, len(pcmData)/2)
+	for i := range samples {
+		samples[i] = int32(int16(binary.LittleEndian.Uint16(pcmData[i*2:]))) //nolint:gosec // G115: reinterpreting PCM bytes as signed samples
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(pcmData)*2/3))
+	out.WriteString("fLaC")
+	writeFLACStreamInfo(out, len(samples), md5.Sum(pcmData))
+
+	var frame bitWriter
+	for number, start := 0, 0; start < len(samples); number, start = number+1, start+flacBlockSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := samples[start:min(start+flacBlockSize, len(samples))]
+		frame.reset()
+		encodeFLACFrame(&frame, uint64(number), block) //nolint:gosec // G115: frame numbers are positive
+		out.Write(frame.buf)
+	}
+	return out, nil
+}
+
+// ExportAudioWithNativeFlac exports PCM data to a FLAC file with the native encoder. The export
+// gain is applied; normalization is approximated from the RMS level of the clip and limited by
+// the true peak target, as the SoX export does.
+func ExportAudioWithNativeFlac(pcmData []byte, outputPath string, settings *conf.AudioSettings) error {
+	start := time.Now()
+	if settings == nil || outputPath == "" {
+		return errors.Newf("audio settings or output path missing for FLAC export").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "export_audio_native_flac").
+			Build()
+	}
+
+	audio, err := EncodeFLAC(context.Background(), "export_audio_native_flac", pcmData, ExportGain(pcmData, settings))
+	if err != nil {
+		return recordFileOperationError("export_native_flac", "flac", "encode_failed", err)
+	}
+
+	tempFilePath, err := createTempFile(outputPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(tempFilePath, audio.Bytes(), 0o644); err != nil { //nolint:gosec // G306: clips are served by the web server
+		_ = os.Remove(tempFilePath)
+		return recordFileOperationError("export_native_flac", "flac", "write_failed", errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryFileIO).
+			Context("operation", "export_audio_native_flac").
+			Build())
+	}
+	if err := finalizeOutput(tempFilePath); err != nil {
+		_ = os.Remove(tempFilePath)
+		return err
+	}
+
+	if fileMetrics != nil {
+		fileMetrics.RecordFileOperation("export_native_flac", "flac", "success")
+		fileMetrics.RecordFileOperationDuration("export_native_flac", "flac", time.Since(start).Seconds())
+		fileMetrics.RecordFileSize("export_native_flac", "flac", int64(audio.Len()))
+	}
+	return nil
+}
+
+// UseNativeFlac reports whether FLAC is encoded with the native encoder. When it is preferred,
+// FFmpeg is only used for filters the native encoder lacks, such as loudness normalization;
+// otherwise the native encoder is used when neither FFmpeg nor SoX can encode FLAC.
+func UseNativeFlac(settings *conf.AudioSettings, needsFilters bool) bool {
+	if settings.Export.Encoder.FlacEncoder == "native" {
+		return !needsFilters || settings.FfmpegPath == ""
+	}
+	soxFlac := settings.SoxPath != "" && conf.IsSoxExportType("flac", settings.SoxAudioTypes)
+	return settings.FfmpegPath == "" && !soxFlac
+}
+
+// ExportGain returns the gain in dB the export settings apply to a clip without FFmpeg filters
+func ExportGain(pcmData []byte, settings *conf.AudioSettings) float64 {
+	if !settings.Export.Normalization.Enabled {
+		return settings.Export.Gain
+	}
+	return NormalizationGain(pcmData, settings.Export.Normalization.TargetLUFS, settings.Export.Normalization.TruePeak)
+}
+
+// NormalizationGain returns the gain in dB bringing 16-bit PCM data to the target loudness,
+// estimated from its RMS level, without raising its sample peak above truePeak dBFS
+func NormalizationGain(pcmData []byte, targetLUFS, truePeak float64) float64 {
+	gain := LoudnessGain(EstimatePCMLoudness(pcmData), targetLUFS)
+	return math.Min(gain, truePeak-pcmPeakLevel(pcmData))
+}
+
+// pcmPeakLevel returns the sample peak of 16-bit PCM data in dBFS
+func pcmPeakLevel(pcmData []byte) float64 {
+	var peak int
+	for i := 0; i+1 < len(pcmData); i += 2 {
+		sample := int(int16(binary.LittleEndian.Uint16(pcmData[i:]))) //nolint:gosec // G115: reinterpreting PCM bytes as signed samples
+		peak = max(peak, sample, -sample)
+	}
+	if peak == 0 {
+		return silenceLoudness
+	}
+	return 20 * math.Log10(float64(peak)/32768.0)
+}
+
+// applyPCMGain returns a copy of 16-bit PCM data amplified by gainDB, clipping samples that
+// exceed full scale
+func applyPCMGain(pcmData []byte, gainDB float64) []byte {
+	factor := math.Pow(10, gainDB/20)
+	out := make([]byte, len(pcmData))
+	for i := 0; i+1 < len(pcmData); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcmData[i:]))) * factor //nolint:gosec // G115: reinterpreting PCM bytes as signed samples
+		sample = math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(sample)))
+		binary.LittleEndian.PutUint16(out[i:], uint16(int16(sample))) //nolint:gosec // G115: sample is clipped to the int16 range
+	}
+	return out
+}
+
+// writeFLACStreamInfo writes the STREAMINFO metadata block, the only metadata of the stream
+func writeFLACStreamInfo(out *bytes.Buffer, totalSamples int, checksum [md5.Size]byte) {
+	var w bitWriter
+	w.write(1, 1)   // last metadata block
+	w.write(0, 7)   // STREAMINFO
+	w.write(34, 24) // block length
+	w.write(flacBlockSize, 16)
+	w.write(flacBlockSize, 16)
+	w.write(0, 24) // minimum frame size unknown
+	w.write(0, 24) // maximum frame size unknown
+	w.write(uint64(conf.SampleRate), 20)
+	w.write(uint64(conf.NumChannels-1), 3)
+	w.write(uint64(conf.BitDepth-1), 5)
+	w.write(uint64(totalSamples), 36) //nolint:gosec // G115: sample counts are positive
+	out.Write(w.buf)
+	out.Write(checksum[:])
+}
+
+// encodeFLACFrame writes a frame holding one block of mono samples
+func encodeFLACFrame(w *bitWriter, number uint64, block []int32) {
+	w.write(0x3FFE, 14) // sync code
+	w.write(0, 1)       // reserved
+	w.write(0, 1)       // fixed block size stream
+	w.write(0x7, 4)     // block size stored as 16 bits after the frame number
+	w.write(flacSampleRateCode(conf.SampleRate), 4)
+	w.write(uint64(conf.NumChannels-1), 4)
+	w.write(flacSampleSizeCode(conf.BitDepth), 3)
+	w.write(0, 1) // reserved
+	w.writeUTF8(number)
+	w.write(uint64(len(block)-1), 16)
+	w.write(uint64(crc8(w.buf)), 8)
+
+	encodeFLACSubframe(w, block, conf.BitDepth)
+
+	w.align()
+	w.write(uint64(crc16(w.buf)), 16)
+}
+
+// encodeFLACSubframe writes the samples of a channel as the smallest of a constant, fixed
+// predictor or verbatim subframe
+func encodeFLACSubframe(w *bitWriter, block []int32, bps int) {
+	constant := true
+	for _, sample := range block[1:] {
+		if sample != block[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		w.write(0, 8) // zero padding, SUBFRAME_CONSTANT, no wasted bits
+		w.writeSigned(block[0], bps)
+		return
+	}
+
+	order := bestFixedOrder(block)
+	residual := fixedResidual(block, order)
+	partitionOrder, parameters, residualBits := bestRicePartitioning(residual, len(block), order)
+
+	if order*bps+6+residualBits >= len(block)*bps {
+		w.write(0x02, 8) // zero padding, SUBFRAME_VERBATIM, no wasted bits
+		for _, sample := range block {
+			w.writeSigned(sample, bps)
+		}
+		return
+	}
+
+	w.write(uint64(0x08|order)<<1, 8) //nolint:gosec // G115: order is 0 to 4; zero padding, SUBFRAME_FIXED, no wasted bits
+	for _, sample := range block[:order] {
+		w.writeSigned(sample, bps)
+	}
+	w.write(0, 2)                      // partitioned Rice coding with 4-bit parameters
+	w.write(uint64(partitionOrder), 4) //nolint:gosec // G115: partition order is 0 to 8
+
+	start := 0
+	for i, k := range parameters {
+		end := (i+1)*(len(block)>>partitionOrder) - order
+		w.write(uint64(k), 4) //nolint:gosec // G115: Rice parameters are 0 to 14
+		for _, r := range residual[start:end] {
+			u := zigzag(r)
+			w.writeUnary(u >> k)
+			w.write(u&(1<<k-1), k)
+		}
+		start = end
+	}
+}
+
+// bestFixedOrder returns the fixed predictor order with the smallest residual
+func bestFixedOrder(block []int32) int {
+	best, bestSum := 0, uint64(math.MaxUint64)
+	for order := 0; order <= 4 && order < len(block); order++ {
+		var sum uint64
+		for _, r := range fixedResidual(block, order) {
+			sum += zigzag(r)
+		}
+		if sum < bestSum {
+			best, bestSum = order, sum
+		}
+	}
+	return best
+}
+
+// fixedResidual returns the residual of a fixed predictor of the given order
+func fixedResidual(block []int32, order int) []int64 {
+	residual := make([]int64, len(block)-order)
+	for i := order; i < len(block); i++ {
+		x := func(j int) int64 { return int64(block[i-j]) }
+		var r int64
+		switch order {
+		case 0:
+			r = x(0)
+		case 1:
+			r = x(0) - x(1)
+		case 2:
+			r = x(0) - 2*x(1) + x(2)
+		case 3:
+			r = x(0) - 3*x(1) + 3*x(2) - x(3)
+		case 4:
+			r = x(0) - 4*x(1) + 6*x(2) - 4*x(3) + x(4)
+		}
+		residual[i-order] = r
+	}
+	return residual
+}
+
+// bestRicePartitioning returns the partition order and Rice parameters coding the residual
+// in the fewest bits, and that number of bits
+func bestRicePartitioning(residual []int64, blockSize, order int) (partitionOrder int, parameters []uint, totalBits int) {
+	totalBits = math.MaxInt
+	for p := 0; p <= flacMaxPartitionOrder; p++ {
+		partitionSize := blockSize >> p
+		if blockSize%(1<<p) != 0 || partitionSize <= order {
+			break
+		}
+
+		candidate := make([]uint, 1<<p)
+		candidateBits := 0
+		start := 0
+		for i := range candidate {
+			end := (i+1)*partitionSize - order
+			k, n := bestRiceParameter(residual[start:end])
+			candidate[i] = k
+			candidateBits += 4 + n
+			start = end
+		}
+		if candidateBits < totalBits {
+			partitionOrder, parameters, totalBits = p, candidate, candidateBits
+		}
+	}
+	return partitionOrder, parameters, totalBits
+}
+
+// bestRiceParameter returns the Rice parameter coding a partition in the fewest bits, tried
+// around the parameter estimated from the mean, and that number of bits
+func bestRiceParameter(partition []int64) (parameter uint, size int) {
+	if len(partition) == 0 {
+		return 0, 0
+	}
+	var sum uint64
+	for _, r := range partition {
+		sum += zigzag(r)
+	}
+	estimate := 0
+	if mean := sum / uint64(len(partition)); mean > 0 {
+		estimate = bits.Len64(mean) - 1
+	}
+
+	size = math.MaxInt
+	for k := max(0, estimate-1); k <= min(flacMaxRiceParameter, estimate+1); k++ {
+		n := len(partition) * (k + 1)
+		for _, r := range partition {
+			n += int(zigzag(r) >> k) //nolint:gosec // G115: quotients of audio residuals are small
+		}
+		if n < size {
+			parameter, size = uint(k), n //nolint:gosec // G115: k is 0 to 14
+		}
+	}
+	return parameter, size
+}
+
+// zigzag maps signed residuals to unsigned values as FLAC's Rice coding does
+func zigzag(r int64) uint64 {
+	return uint64(r<<1) ^ uint64(r>>63) //nolint:gosec // G115: zigzag encoding reinterprets the bits
+}
+
+// flacSampleRateCode returns the frame header code of a sample rate, 0 to read it from STREAMINFO
+func flacSampleRateCode(sampleRate int) uint64 {
+	switch sampleRate {
+	case 8000:
+		return 0x4
+	case 16000:
+		return 0x5
+	case 22050:
+		return 0x6
+	case 24000:
+		return 0x7
+	case 32000:
+		return 0x8
+	case 44100:
+		return 0x9
+	case 48000:
+		return 0xA
+	case 96000:
+		return 0xB
+	default:
+		return 0x0
+	}
+}
+
+// flacSampleSizeCode returns the frame header code of a sample size, 0 to read it from STREAMINFO
+func flacSampleSizeCode(bitDepth int) uint64 {
+	switch bitDepth {
+	case 8:
+		return 0x1
+	case 12:
+		return 0x2
+	case 16:
+		return 0x4
+	case 20:
+		return 0x5
+	case 24:
+		return 0x6
+	default:
+		return 0x0
+	}
+}
+
+// bitWriter writes values of any bit length, most significant bit first
+type bitWriter struct {
+	buf  []byte
+	acc  uint64 // bits not yet written to buf
+	nacc uint   // number of bits in acc, below 8 between writes
+}
+
+func (w *bitWriter) reset() {
+	w.buf, w.acc, w.nacc = w.buf[:0], 0, 0
+}
+
+// write writes the low n bits of v, n up to 56
+func (w *bitWriter) write(v uint64, n uint) {
+	if n > 32 {
+		w.write(v>>32, n-32)
+		n = 32
+	}
+	w.acc = w.acc<<n | v&(1<<n-1)
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.nacc -= 8
+		w.buf = append(w.buf, byte(w.acc>>w.nacc))
+	}
+}
+
+// writeSigned writes v as a two's complement number of n bits
+func (w *bitWriter) writeSigned(v int32, n int) {
+	w.write(uint64(int64(v)), uint(n)) //nolint:gosec // G115: two's complement bits are intended
+}
+
+// writeUnary writes q zero bits followed by a one bit
+func (w *bitWriter) writeUnary(q uint64) {
+	for ; q >= 32; q -= 32 {
+		w.write(0, 32)
+	}
+	w.write(1, uint(q)+1)
+}
+
+// writeUTF8 writes v in the extended UTF-8 coding FLAC uses for frame numbers
+func (w *bitWriter) writeUTF8(v uint64) {
+	if v < 0x80 {
+		w.write(v, 8)
+		return
+	}
+	// Number of continuation bytes, each carrying 6 bits
+	n := 1
+	for v >= 1<<(5*n+6) && n < 6 {
+		n++
+	}
+	w.write(uint64(0xFF00>>(n+1))&0xFF|v>>(6*n), 8)
+	for i := n - 1; i >= 0; i-- {
+		w.write(0x80|(v>>(6*i))&0x3F, 8)
+	}
+}
+
+// align pads the last byte with zero bits
+func (w *bitWriter) align() {
+	if w.nacc > 0 {
+		w.write(0, 8-w.nacc)
+	}
+}
+
+// crc8 returns the CRC-8 of FLAC frame headers, polynomial x^8 + x^2 + x + 1
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// crc16 returns the CRC-16 of FLAC frames, polynomial x^16 + x^15 + x^2 + 1
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,151 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/flac"
+)
+
+// testPCM returns 16-bit PCM of the given samples
+func testPCM(samples []float64) []byte {
+	pcm := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(s))) //nolint:gosec // G115: test samples are in range
+	}
+	return pcm
+}
+
+func TestEncodePCMtoFLAC(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // G404: deterministic test noise
+	birdsong := make([]float64, conf.SampleRate*3+123)
+	var phase float64
+	for i := range birdsong {
+		// Song sweeping from 1 to 5 kHz over background noise
+		phase += 2 * math.Pi * (3000 + 2000*math.Sin(float64(i)/4800)) / conf.SampleRate
+		birdsong[i] = 8000*math.Sin(phase) + 30*rng.NormFloat64()
+	}
+	noise := make([]float64, flacBlockSize*2)
+	for i := range noise {
+		noise[i] = math.Max(-32768, math.Min(32767, 20000*rng.NormFloat64()))
+	}
+
+	tests := []struct {
+		name    string
+		samples []float64
+	}{
+		{"birdsong", birdsong},
+		{"silence", make([]float64, flacBlockSize+10)},
+		{"full scale noise", noise},
+		{"single sample", []float64{-1234}},
+		{"short block", birdsong[:37]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pcm := testPCM(tt.samples)
+
+			encoded, err := EncodePCMtoFLAC(pcm, 0)
+			require.NoError(t, err)
+
+			// The decoder verifies the MD5 checksum of the decoded audio
+			decoded, info, err := flac.Decode(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, pcm, decoded)
+			assert.Equal(t, conf.SampleRate, info.SampleRate)
+			assert.Equal(t, 16, info.BitsPerSample)
+			assert.Equal(t, 1, info.NChannels)
+		})
+	}
+
+	encoded, err := EncodePCMtoFLAC(testPCM(birdsong), 0)
+	require.NoError(t, err)
+	assert.Less(t, encoded.Len(), len(birdsong)*2*3/4, "birdsong compresses")
+
+	_, err = EncodePCMtoFLAC([]byte{1, 2, 3}, 0)
+	assert.Error(t, err, "partial samples")
+}
+
+func TestEncodePCMtoFLACGain(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := EncodePCMtoFLAC(testPCM([]float64{1000, -1000, 20000, -20000}), 6.0206)
+	require.NoError(t, err)
+	decoded, _, err := flac.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, testPCM([]float64{2000, -2000, 32767, -32768}), decoded, "doubled and clipped")
+
+	settings := &conf.AudioSettings{}
+	settings.Export.Gain = 3
+	pcm := testPCM([]float64{1000, -16384})
+	assert.InDelta(t, 3, ExportGain(pcm, settings), 0)
+
+	settings.Export.Normalization = conf.NormalizationSettings{Enabled: true, TargetLUFS: -3, TruePeak: -2}
+	assert.InDelta(t, 4.02, ExportGain(pcm, settings), 0.01, "limited by the true peak target")
+}
+
+func TestFLACBitstream(t *testing.T) {
+	t.Parallel()
+
+	// Check values of the CRC parameters FLAC uses
+	assert.Equal(t, byte(0xF4), crc8([]byte("123456789")))
+	assert.Equal(t, uint16(0xFEE8), crc16([]byte("123456789")))
+
+	tests := []struct {
+		number uint64
+		want   []byte
+	}{
+		{0x00, []byte{0x00}},
+		{0x7F, []byte{0x7F}},
+		{0x80, []byte{0xC2, 0x80}},
+		{0x7FF, []byte{0xDF, 0xBF}},
+		{0x800, []byte{0xE0, 0xA0, 0x80}},
+		{0x10000, []byte{0xF0, 0x90, 0x80, 0x80}},
+	}
+	for _, tt := range tests {
+		var w bitWriter
+		w.writeUTF8(tt.number)
+		assert.Equal(t, tt.want, w.buf, "frame number %#x", tt.number)
+	}
+}
+
+func TestUseNativeFlac(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		preferred    string
+		ffmpeg, sox  bool
+		needsFilters bool
+		want         bool
+	}{
+		{"native preferred", "native", true, true, false, true},
+		{"native preferred, normalization needs FFmpeg", "native", true, false, true, false},
+		{"native preferred, normalization without FFmpeg", "native", false, false, true, true},
+		{"FFmpeg preferred", "ffmpeg", true, false, false, false},
+		{"FFmpeg preferred, SoX available", "ffmpeg", false, true, false, false},
+		{"FFmpeg preferred, no external encoder", "ffmpeg", false, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			settings := &conf.AudioSettings{}
+			settings.Export.Encoder.FlacEncoder = tt.preferred
+			if tt.ffmpeg {
+				settings.FfmpegPath = "/usr/bin/ffmpeg"
+			}
+			if tt.sox {
+				settings.SoxPath = "/usr/bin/sox"
+				settings.SoxAudioTypes = []string{"wav", "flac"}
+			}
+			assert.Equal(t, tt.want, UseNativeFlac(settings, tt.needsFilters))
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package myaudio
+
+import (
+	"cmp"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
+)
+
+// InferenceShare describes the inference time used by one audio source since startup
+type InferenceShare struct {
+	SourceID         string  `json:"sourceId"`
+	Name             string  `json:"name,omitempty"` // display name of the source
+	Active           bool    `json:"active"`         // source is currently analyzed
+	Processed        int64   `json:"processed"`      // chunks analyzed
+	Dropped          int64   `json:"dropped"`        // chunks dropped because the queue of the source was full
+	Silent           int64   `json:"silent"`         // chunks skipped by the silence gate
+	SilentMaxLevel   float64 `json:"silentMaxLevel"` // loudest frame level of the skipped chunks in dBFS
+	Queued           int     `json:"queued"`         // chunks waiting for inference
+	InferenceSeconds float64 `json:"inferenceSeconds"`
+	Share            float64 `json:"share"` // share of all inference time, 0-1
+}
+
+// inferenceRequest is an analysis chunk waiting for inference
+type inferenceRequest struct {
+	sourceID string
+	queuedAt time.Time
+	run      func() error // runs inference on the chunk
+}
+
+// sourceInferenceQueue holds the waiting chunks and the statistics of one source
+type sourceInferenceQueue struct {
+	requests      []inferenceRequest
+	registered    bool // a buffer monitor submits chunks of the source
+	inFlight      bool // a chunk of the source is being analyzed
+	processed     int64
+	dropped       int64
+	silent        int64
+	silentLevel   float64 // loudest frame level of the skipped chunks
+	inferenceTime time.Duration
+}
+
+// inferenceScheduler runs the inference of all audio sources on a set of workers. Sources
+// take turns, one chunk each, and each source queues at most queueLimit chunks, dropping the
+// oldest, so a source that produces chunks faster than they are analyzed delays only itself.
+// A source has at most one chunk in inference, so its chunks are analyzed in order and the
+// workers analyze chunks of different sources in parallel.
+type inferenceScheduler struct {
+	mu         sync.Mutex
+	changed    *sync.Cond // broadcast when requests are queued or finished and sources leave
+	queues     map[string]*sourceInferenceQueue
+	order      []string  // registered sources in round-robin order
+	next       int       // index in order of the source whose turn is next
+	running    int       // worker goroutines running
+	progress   time.Time // when a chunk last finished, or chunks arrived while none were pending
+	totalTime  time.Duration
+	queueLimit func() int
+	workers    func() int
+}
+
+// newInferenceScheduler returns a scheduler that reads the per-source queue limit from
+// queueLimit and the number of workers from workers
+func newInferenceScheduler(queueLimit, workers func() int) *inferenceScheduler {
+	s := &inferenceScheduler{
+		queues:     make(map[string]*sourceInferenceQueue),
+		queueLimit: queueLimit,
+		workers:    workers,
+	}
+	s.changed = sync.NewCond(&s.mu)
+	return s
+}
+
+// inferenceQueue schedules the inference of all analysis buffer monitors. It runs a worker
+// for each chunk the BirdNET interpreters analyze at a time, so they are kept busy when
+// several sources wait.
+var inferenceQueue = newInferenceScheduler(func() int {
+	return conf.Setting().BirdNET.Scheduler.QueueLimit
+}, func() int {
+	scheduler := &conf.Setting().BirdNET.Scheduler
+	return max(scheduler.Workers, 1) * max(scheduler.BatchSize, 1)
+})
+
+// register adds a source to the round-robin order and starts the worker if needed
+func (s *inferenceScheduler) register(sourceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queue(sourceID)
+	if queue.registered {
+		return
+	}
+	queue.registered = true
+	s.order = append(s.order, sourceID)
+	s.startWorkers()
+}
+
+// startWorkers starts workers until the configured number runs. The caller must hold mu.
+func (s *inferenceScheduler) startWorkers() {
+	for s.running < max(s.workers(), 1) {
+		s.running++
+		go s.work()
+	}
+}
+
+// unregister removes a source, discards its waiting chunks and waits until its chunk in
+// inference, if any, has been analyzed
+func (s *inferenceScheduler) unregister(sourceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, ok := s.queues[sourceID]
+	if !ok || !queue.registered {
+		return
+	}
+	queue.registered = false
+	clear(queue.requests)
+	queue.requests = nil
+	s.updateQueueLength(sourceID, 0)
+
+	index := slices.Index(s.order, sourceID)
+	s.order = slices.Delete(s.order, index, index+1)
+	if index < s.next {
+		s.next--
+	}
+	if s.next >= len(s.order) {
+		s.next = 0
+	}
+	s.changed.Broadcast()
+
+	for queue.inFlight {
+		s.changed.Wait()
+	}
+}
+
+// submit queues a chunk of a registered source. When the queue of the source is full the
+// oldest chunk is dropped.
+func (s *inferenceScheduler) submit(sourceID string, run func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, ok := s.queues[sourceID]
+	if !ok || !queue.registered {
+		return
+	}
+
+	if !s.pending() {
+		s.progress = time.Now()
+	}
+
+	limit := max(s.queueLimit(), 1)
+	for len(queue.requests) >= limit {
+		queue.requests[0] = inferenceRequest{}
+		queue.requests = queue.requests[1:]
+		queue.dropped++
+		if m := getAnalysisMetrics(); m != nil {
+			m.RecordInferenceRequest(sourceID, "dropped")
+		}
+	}
+	queue.requests = append(queue.requests, inferenceRequest{sourceID: sourceID, queuedAt: time.Now(), run: run})
+	s.updateQueueLength(sourceID, len(queue.requests))
+	s.startWorkers()
+	s.changed.Broadcast()
+}
+
+// skip counts a chunk of a source that the silence gate kept from inference
+func (s *inferenceScheduler) skip(sourceID string, level float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queue(sourceID)
+	if queue.silent == 0 || level > queue.silentLevel {
+		queue.silentLevel = level
+	}
+	queue.silent++
+	if m := getAnalysisMetrics(); m != nil {
+		m.RecordInferenceRequest(sourceID, "silent")
+	}
+}
+
+// work analyzes queued chunks until no source is registered
+func (s *inferenceScheduler) work() {
+	for {
+		req, ok := s.take()
+		if !ok {
+			return
+		}
+		wait := time.Since(req.queuedAt)
+		pipeline.Record(pipeline.StageInferenceQueue, wait)
+		if m := getAnalysisMetrics(); m != nil {
+			m.RecordInferenceQueueWait(req.sourceID, wait.Seconds())
+		}
+
+		start := time.Now()
+		s.runRequest(req)
+		s.finish(req.sourceID, time.Since(start))
+	}
+}
+
+// take waits for the next chunk in round-robin order, skipping sources with a chunk in
+// inference. ok is false when no source is registered or more workers run than configured,
+// the worker then exits.
+func (s *inferenceScheduler) take() (req inferenceRequest, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if len(s.order) == 0 || s.running > max(s.workers(), 1) {
+			s.running--
+			return inferenceRequest{}, false
+		}
+		for i := range len(s.order) {
+			index := (s.next + i) % len(s.order)
+			queue := s.queues[s.order[index]]
+			if len(queue.requests) == 0 || queue.inFlight {
+				continue
+			}
+			req = queue.requests[0]
+			queue.requests[0] = inferenceRequest{}
+			queue.requests = queue.requests[1:]
+			queue.inFlight = true
+			s.next = (index + 1) % len(s.order)
+			s.updateQueueLength(req.sourceID, len(queue.requests))
+			return req, true
+		}
+		s.changed.Wait()
+	}
+}
+
+// runRequest analyzes a chunk. A panic in inference is logged so the other sources
+// continue to be analyzed.
+func (s *inferenceScheduler) runRequest(req inferenceRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ Inference panicked for source ID %s: %v", req.sourceID, r)
+		}
+	}()
+	if err := req.run(); err != nil {
+		log.Printf("❌ Error processing data for source ID %s: %v", req.sourceID, err)
+	}
+}
+
+// finish records the inference time of an analyzed chunk
+func (s *inferenceScheduler) finish(sourceID string, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[sourceID]
+	queue.inFlight = false
+	queue.processed++
+	s.progress = time.Now()
+	queue.inferenceTime += elapsed
+	s.totalTime += elapsed
+	s.changed.Broadcast()
+
+	if m := getAnalysisMetrics(); m != nil {
+		m.RecordInferenceRequest(sourceID, "processed")
+		for id, q := range s.queues {
+			m.UpdateInferenceShare(id, s.share(q))
+		}
+	}
+}
+
+// pending reports whether chunks are queued or in inference. The caller must hold mu.
+func (s *inferenceScheduler) pending() bool {
+	for _, queue := range s.queues {
+		if len(queue.requests) > 0 || queue.inFlight {
+			return true
+		}
+	}
+	return false
+}
+
+// stalled reports whether chunks have been pending at now for longer than timeout without
+// any chunk finishing inference
+func (s *inferenceScheduler) stalled(now time.Time, timeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending() && now.Sub(s.progress) > timeout
+}
+
+// queue returns the queue of a source, creating it on first use. The caller must hold mu.
+func (s *inferenceScheduler) queue(sourceID string) *sourceInferenceQueue {
+	queue, ok := s.queues[sourceID]
+	if !ok {
+		queue = &sourceInferenceQueue{}
+		s.queues[sourceID] = queue
+	}
+	return queue
+}
+
+// share returns the share of all inference time used by a queue. The caller must hold mu.
+func (s *inferenceScheduler) share(queue *sourceInferenceQueue) float64 {
+	if s.totalTime == 0 {
+		return 0
+	}
+	return float64(queue.inferenceTime) / float64(s.totalTime)
+}
+
+// updateQueueLength publishes the number of waiting chunks of a source
+func (s *inferenceScheduler) updateQueueLength(sourceID string, length int) {
+	if m := getAnalysisMetrics(); m != nil {
+		m.UpdateInferenceQueueLength(sourceID, length)
+	}
+}
+
+// shares returns the statistics of all sources, largest share first
+func (s *inferenceScheduler) shares() []InferenceShare {
+	s.mu.Lock()
+	result := make([]InferenceShare, 0, len(s.queues))
+	for id, queue := range s.queues {
+		result = append(result, InferenceShare{
+			SourceID:         id,
+			Active:           queue.registered,
+			Processed:        queue.processed,
+			Dropped:          queue.dropped,
+			Silent:           queue.silent,
+			SilentMaxLevel:   queue.silentLevel,
+			Queued:           len(queue.requests),
+			InferenceSeconds: queue.inferenceTime.Seconds(),
+			Share:            s.share(queue),
+		})
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(result, func(a, b InferenceShare) int {
+		return cmp.Or(cmp.Compare(b.Share, a.Share), cmp.Compare(a.SourceID, b.SourceID))
+	})
+	return result
+}
+
+// InferenceStalled reports whether chunks have waited for longer than timeout without any
+// chunk finishing inference, e.g. because an interpreter hangs
+func InferenceStalled(timeout time.Duration) bool {
+	return inferenceQueue.stalled(time.Now(), timeout)
+}
+
+// GetInferenceShares returns the inference time used by each audio source since startup,
+// largest share first, with the number of chunks analyzed, dropped and skipped as silent
+func GetInferenceShares() []InferenceShare {
+	shares := inferenceQueue.shares()
+	registry := GetRegistry()
+	for i := range shares {
+		if source, ok := registry.GetSourceByID(shares[i].SourceID); ok {
+			shares[i].Name = source.DisplayName
+		}
+	}
+	return shares
+}
@@ -0,0 +1,248 @@
+// inference_scheduler_test.go
+// Tests for fair inference scheduling across audio sources
+
+package myaudio
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schedulerRecorder records the order in which chunks are analyzed and holds the worker
+// on the first chunk until released
+type schedulerRecorder struct {
+	mu      sync.Mutex
+	order   []string
+	started chan struct{}
+	release chan struct{}
+	done    sync.WaitGroup
+}
+
+func newSchedulerRecorder() *schedulerRecorder {
+	return &schedulerRecorder{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+// job returns a chunk that records its name; the blocking chunk waits for release
+func (r *schedulerRecorder) job(name string, blocking bool) func() error {
+	r.done.Add(1)
+	return func() error {
+		defer r.done.Done()
+		if blocking {
+			close(r.started)
+			<-r.release
+		}
+		r.mu.Lock()
+		r.order = append(r.order, name)
+		r.mu.Unlock()
+		return nil
+	}
+}
+
+// waitStarted waits until the worker is held on the blocking chunk
+func (r *schedulerRecorder) waitStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case <-r.started:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "worker did not start the first chunk")
+	}
+}
+
+func TestInferenceScheduler_RoundRobin(t *testing.T) {
+	t.Parallel()
+
+	s := newInferenceScheduler(func() int { return 5 }, func() int { return 1 })
+	for _, source := range []string{"chatty", "quiet", "other"} {
+		s.register(source)
+	}
+	rec := newSchedulerRecorder()
+
+	s.submit("chatty", rec.job("chatty-1", true))
+	rec.waitStarted(t)
+	s.submit("chatty", rec.job("chatty-2", false))
+	s.submit("chatty", rec.job("chatty-3", false))
+	s.submit("chatty", rec.job("chatty-4", false))
+	s.submit("quiet", rec.job("quiet-1", false))
+	s.submit("other", rec.job("other-1", false))
+	close(rec.release)
+	rec.done.Wait()
+
+	assert.Equal(t, []string{"chatty-1", "quiet-1", "other-1", "chatty-2", "chatty-3", "chatty-4"}, rec.order,
+		"sources with waiting chunks take turns")
+
+	for _, source := range []string{"chatty", "quiet", "other"} {
+		s.unregister(source)
+	}
+	shares := s.shares()
+	require.Len(t, shares, 3)
+	processed := make(map[string]int64)
+	for _, share := range shares {
+		processed[share.SourceID] = share.Processed
+		assert.False(t, share.Active)
+		assert.Zero(t, share.Dropped)
+	}
+	assert.Equal(t, map[string]int64{"chatty": 4, "quiet": 1, "other": 1}, processed)
+}
+
+func TestInferenceScheduler_DropsOldestWhenFull(t *testing.T) {
+	t.Parallel()
+
+	s := newInferenceScheduler(func() int { return 2 }, func() int { return 1 })
+	s.register("cam")
+	rec := newSchedulerRecorder()
+
+	s.submit("cam", rec.job("chunk-1", true))
+	rec.waitStarted(t)
+	// The queue holds two chunks, so the oldest waiting chunk is dropped
+	s.submit("cam", func() error { t.Error("dropped chunk was analyzed"); return nil })
+	s.submit("cam", rec.job("chunk-3", false))
+	s.submit("cam", rec.job("chunk-4", false))
+
+	shares := s.shares()
+	require.Len(t, shares, 1)
+	assert.Equal(t, int64(1), shares[0].Dropped)
+	assert.Equal(t, 2, shares[0].Queued)
+
+	close(rec.release)
+	rec.done.Wait()
+	assert.Equal(t, []string{"chunk-1", "chunk-3", "chunk-4"}, rec.order)
+
+	s.unregister("cam")
+	shares = s.shares()
+	assert.Equal(t, int64(3), shares[0].Processed)
+	assert.InDelta(t, 1.0, shares[0].Share, 1e-9)
+}
+
+func TestInferenceScheduler_UnregisterDiscardsQueue(t *testing.T) {
+	t.Parallel()
+
+	s := newInferenceScheduler(func() int { return 3 }, func() int { return 1 })
+	s.register("a")
+	s.register("b")
+	rec := newSchedulerRecorder()
+
+	s.submit("a", rec.job("a-1", true))
+	rec.waitStarted(t)
+	s.submit("b", func() error { t.Error("chunk of removed source was analyzed"); return nil })
+
+	unregistered := make(chan struct{})
+	go func() {
+		s.unregister("b")
+		close(unregistered)
+	}()
+	select {
+	case <-unregistered:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "unregister of an idle source blocked")
+	}
+
+	// Unregistering a source waits for its chunk in inference
+	unregistered = make(chan struct{})
+	go func() {
+		s.unregister("a")
+		close(unregistered)
+	}()
+	select {
+	case <-unregistered:
+		require.FailNow(t, "unregister returned while the chunk was analyzed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(rec.release)
+	<-unregistered
+	rec.done.Wait()
+
+	// Submissions of unregistered sources are ignored
+	s.submit("a", func() error { t.Error("chunk of unregistered source was analyzed"); return nil })
+	assert.Equal(t, []string{"a-1"}, rec.order)
+
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.running == 0
+	}, 5*time.Second, 10*time.Millisecond, "worker exits when no source is registered")
+}
+
+func TestInferenceScheduler_ParallelWorkers(t *testing.T) {
+	t.Parallel()
+
+	s := newInferenceScheduler(func() int { return 5 }, func() int { return 2 })
+	s.register("a")
+	s.register("b")
+
+	var mu sync.Mutex
+	running := make(map[string]int)
+	var concurrent, sameSource int
+	var done sync.WaitGroup
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	job := func(source string) func() error {
+		done.Add(1)
+		return func() error {
+			defer done.Done()
+			mu.Lock()
+			running[source]++
+			if running[source] > 1 {
+				sameSource++
+			}
+			if running["a"] > 0 && running["b"] > 0 {
+				concurrent++
+			}
+			mu.Unlock()
+			started <- struct{}{}
+			<-release
+			mu.Lock()
+			running[source]--
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	s.submit("a", job("a"))
+	s.submit("a", job("a"))
+	s.submit("b", job("b"))
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "workers did not start chunks of both sources")
+		}
+	}
+	close(release)
+	done.Wait()
+
+	assert.Positive(t, concurrent, "chunks of different sources are analyzed in parallel")
+	assert.Zero(t, sameSource, "a source has one chunk in inference at a time")
+
+	s.unregister("a")
+	s.unregister("b")
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.running == 0
+	}, 5*time.Second, 10*time.Millisecond, "workers exit when no source is registered")
+}
+
+func TestInferenceScheduler_Stalled(t *testing.T) {
+	t.Parallel()
+
+	s := newInferenceScheduler(func() int { return 3 }, func() int { return 1 })
+	s.register("cam")
+	assert.False(t, s.stalled(time.Now().Add(time.Hour), time.Minute), "an idle scheduler is not stalled")
+
+	rec := newSchedulerRecorder()
+	s.submit("cam", rec.job("chunk-1", true))
+	rec.waitStarted(t)
+	s.submit("cam", rec.job("chunk-2", false))
+	assert.False(t, s.stalled(time.Now(), time.Minute))
+	assert.True(t, s.stalled(time.Now().Add(2*time.Minute), time.Minute), "a hung chunk stalls the scheduler")
+
+	close(rec.release)
+	rec.done.Wait()
+	assert.Eventually(t, func() bool { return !s.stalled(time.Now().Add(2*time.Minute), time.Minute) },
+		5*time.Second, 10*time.Millisecond, "nothing pending after the chunks finished")
+	s.unregister("cam")
+}
@@ -0,0 +1,356 @@
+// input_backend.go: audio source declarations and the input backends capturing them
+package myaudio
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/malgo"
+)
+
+// Input backends of audio source declarations such as "pulse:alsa_input.usb-mic.analog-mono"
+const (
+	InputBackendALSA     = "alsa"
+	InputBackendPulse    = "pulse"
+	InputBackendPipeWire = "pipewire"
+	InputBackendRTSP     = "rtsp"
+	InputBackendSRT      = "srt"
+	InputBackendRTMP     = "rtmp"
+	InputBackendHTTP     = "http"
+	InputBackendUDP      = "udp"
+	InputBackendFile     = "file"
+)
+
+// pcmFormats are the raw PCM sample formats a UDP stream can declare
+var pcmFormats = []string{"s16le", "s16be", "s24le", "s32le", "f32le", "u8"}
+
+// pcmStreamFormat is the sample format of a raw PCM stream
+type pcmStreamFormat struct {
+	format   string
+	rate     int
+	channels int
+}
+
+// SourceSpec is a parsed audio source declaration
+type SourceSpec struct {
+	Backend string // an InputBackend constant, empty for the default sound card backend of the platform
+	Device  string // device ID or name, stream URL or file path
+}
+
+// ParseSourceSpec parses an audio source declaration. Sound cards are declared as alsa:, pulse:
+// or pipewire: followed by a device ID or name, streams as rtsp://, rtsps://, srt://, rtmp://,
+// rtmps://, http://, https:// or udp:// URLs and files or named pipes as file: paths. Declarations without a known prefix
+// are sound cards of the default backend of the platform, so ALSA names such as "hw:1,0" or
+// "sysdefault" keep working.
+func ParseSourceSpec(declaration string) SourceSpec {
+	prefix, rest, found := strings.Cut(declaration, ":")
+	if !found {
+		return SourceSpec{Device: declaration}
+	}
+	switch backend := strings.ToLower(prefix); backend {
+	case InputBackendALSA, InputBackendPulse, InputBackendPipeWire:
+		return SourceSpec{Backend: backend, Device: rest}
+	case InputBackendRTSP, "rtsps":
+		return SourceSpec{Backend: InputBackendRTSP, Device: declaration}
+	case InputBackendSRT:
+		return SourceSpec{Backend: InputBackendSRT, Device: declaration}
+	case InputBackendRTMP, "rtmps":
+		return SourceSpec{Backend: InputBackendRTMP, Device: declaration}
+	case InputBackendHTTP, "https":
+		return SourceSpec{Backend: InputBackendHTTP, Device: declaration}
+	case InputBackendUDP:
+		return SourceSpec{Backend: InputBackendUDP, Device: declaration}
+	case InputBackendFile:
+		return SourceSpec{Backend: InputBackendFile, Device: strings.TrimPrefix(rest, "//")}
+	}
+	return SourceSpec{Device: declaration}
+}
+
+// String returns the declaration of the source
+func (s SourceSpec) String() string {
+	switch s.Backend {
+	case "", InputBackendRTSP, InputBackendSRT, InputBackendRTMP, InputBackendHTTP, InputBackendUDP:
+		return s.Device
+	}
+	return s.Backend + ":" + s.Device
+}
+
+// IsSoundCard reports whether the source is captured from a sound card through miniaudio
+// rather than by FFmpeg
+func (s SourceSpec) IsSoundCard() bool {
+	switch s.Backend {
+	case InputBackendRTSP, InputBackendSRT, InputBackendRTMP, InputBackendHTTP, InputBackendUDP, InputBackendFile:
+		return false
+	}
+	return true
+}
+
+// Listens reports whether FFmpeg waits for a publisher to push the stream rather than pulling
+// it: SRT streams in listener mode, and SRT and RTMP streams on a wildcard address such as
+// srt://0.0.0.0:9000 or rtmp://[::]:1935/live/mic
+func (s SourceSpec) Listens() bool {
+	if s.Backend != InputBackendSRT && s.Backend != InputBackendRTMP {
+		return false
+	}
+	u, err := url.Parse(s.Device)
+	if err != nil {
+		return false
+	}
+	if s.Backend == InputBackendSRT && u.Query().Get("mode") == "listener" {
+		return true
+	}
+	switch u.Hostname() {
+	case "", "0.0.0.0", "::":
+		return true
+	}
+	return false
+}
+
+// StreamSourceType returns the registry type of a stream read by FFmpeg: SRT and RTMP streams
+// are network streams like RTSP ones, files and named pipes declared as file: are files
+func StreamSourceType(declaration string) SourceType {
+	if ParseSourceSpec(declaration).Backend == InputBackendFile {
+		return SourceTypeFile
+	}
+	return SourceTypeRTSP
+}
+
+// captureBackend returns the miniaudio backend capturing a sound card source
+func (s SourceSpec) captureBackend() malgo.Backend {
+	switch s.Backend {
+	case InputBackendALSA:
+		return malgo.BackendAlsa
+	case InputBackendPulse, InputBackendPipeWire:
+		// miniaudio has no native PipeWire backend, PipeWire serves PulseAudio clients
+		// through pipewire-pulse
+		return malgo.BackendPulseaudio
+	}
+	return defaultCaptureBackend()
+}
+
+// hardwareDevicesOnly reports whether the backend of the source lists hardware devices that
+// ValidateAudioDevice requires, sound servers only list their own sources
+func (s SourceSpec) hardwareDevicesOnly() bool {
+	return s.Backend == "" || s.Backend == InputBackendALSA
+}
+
+// defaultCaptureBackend returns the miniaudio backend of sources without a backend prefix
+func defaultCaptureBackend() malgo.Backend {
+	switch runtime.GOOS {
+	case "windows":
+		return malgo.BackendWasapi
+	case "darwin":
+		return malgo.BackendCoreaudio
+	}
+	return malgo.BackendAlsa
+}
+
+// streamInputArgs returns the FFmpeg input options and input URL of an SRT, RTMP, HTTP or UDP
+// stream. Read timeouts end stalled connections so the stream restarts, publishers of pushed
+// streams and UDP senders are waited for indefinitely.
+func streamInputArgs(spec SourceSpec, timeout time.Duration) (args []string, input string, err error) {
+	input = spec.Device
+	microseconds := strconv.FormatInt(timeout.Microseconds(), 10)
+	switch spec.Backend {
+	case InputBackendHTTP:
+		// Icecast and other HTTP streams end on server restarts, reconnect within FFmpeg
+		return []string{"-reconnect", "1", "-reconnect_streamed", "1", "-reconnect_delay_max", "30",
+			"-rw_timeout", microseconds}, input, nil
+	case InputBackendUDP:
+		format, input, err := parsePCMStream(input)
+		if err != nil {
+			return nil, "", err
+		}
+		return []string{"-f", format.format, "-ar", strconv.Itoa(format.rate), "-ac", strconv.Itoa(format.channels)},
+			input, nil
+	case InputBackendSRT:
+		if spec.Listens() {
+			// FFmpeg calls out unless the URL selects listener mode
+			if u, err := url.Parse(input); err == nil && u.Query().Get("mode") == "" {
+				query := u.Query()
+				query.Set("mode", "listener")
+				u.RawQuery = query.Encode()
+				input = u.String()
+			}
+			return nil, input, nil
+		}
+		return []string{"-timeout", microseconds}, input, nil
+	case InputBackendRTMP:
+		// The timeout option of RTMP is a listen timeout, rw_timeout limits reads
+		args = []string{"-rw_timeout", microseconds}
+		if spec.Listens() {
+			args = []string{"-listen", "1"}
+		}
+		return args, input, nil
+	}
+	return nil, input, nil
+}
+
+// validateStreamDeclaration checks a stream declaration before its FFmpeg process is started
+func validateStreamDeclaration(declaration string) error {
+	if ParseSourceSpec(declaration).Backend == InputBackendUDP {
+		_, _, err := parsePCMStream(declaration)
+		return err
+	}
+	return nil
+}
+
+// parsePCMStream returns the sample format of a raw PCM stream declared in the query of its
+// URL, as in udp://:5000?format=s16le&rate=16000&channels=1, and the URL FFmpeg reads. Omitted
+// parameters default to 16-bit mono at the sample rate BirdNET analyzes.
+func parsePCMStream(declaration string) (format pcmStreamFormat, input string, err error) {
+	u, err := url.Parse(declaration)
+	if err != nil {
+		return format, "", errors.New(fmt.Errorf("invalid UDP stream URL: %w", err)).
+			Category(errors.CategoryValidation).
+			Component("myaudio").
+			Context("operation", "parse_pcm_stream").
+			Build()
+	}
+
+	query := u.Query()
+	format = pcmStreamFormat{format: "s16le", rate: conf.SampleRate, channels: 1}
+	if value := query.Get("format"); value != "" {
+		format.format = strings.ToLower(value)
+	}
+	if value := query.Get("rate"); value != "" {
+		format.rate, err = strconv.Atoi(value)
+	}
+	if value := query.Get("channels"); value != "" && err == nil {
+		format.channels, err = strconv.Atoi(value)
+	}
+	if err != nil || !slices.Contains(pcmFormats, format.format) ||
+		format.rate < 8000 || format.rate > 384000 || format.channels < 1 || format.channels > 8 {
+		return format, "", errors.Newf("invalid UDP stream format %q, expected format one of %s, rate 8000-384000 and channels 1-8",
+			u.RawQuery, strings.Join(pcmFormats, ", ")).
+			Category(errors.CategoryValidation).
+			Component("myaudio").
+			Context("operation", "parse_pcm_stream").
+			Build()
+	}
+
+	// The format parameters are no FFmpeg URL options; a full receive buffer drops packets
+	// instead of ending the stream
+	query.Del("format")
+	query.Del("rate")
+	query.Del("channels")
+	if !query.Has("overrun_nonfatal") {
+		query.Set("overrun_nonfatal", "1")
+	}
+	u.RawQuery = query.Encode()
+	return format, u.String(), nil
+}
+
+// fileInputArgs returns the FFmpeg input options of a file source. Regular files are read
+// at their native rate to simulate a live source, named pipes are read as they are written.
+func fileInputArgs(path string) []string {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		return nil
+	}
+	return []string{"-re"}
+}
+
+// InputDevice is a capture device of an input backend
+type InputDevice struct {
+	Name        string `json:"name"`
+	ID          string `json:"id"`
+	Declaration string `json:"declaration"` // realtime.audio.source value selecting the device
+	Default     bool   `json:"default"`
+}
+
+// InputBackendInfo describes an input backend and the capture devices it provides
+type InputBackendInfo struct {
+	Backend   string        `json:"backend"`
+	Prefix    string        `json:"prefix"` // declaration prefix, empty for the default backend of the platform
+	Available bool          `json:"available"`
+	Devices   []InputDevice `json:"devices"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// ListInputBackends enumerates the input backends of the platform with their capture devices.
+// Stream backends are available when FFmpeg is configured and have no devices to list.
+func ListInputBackends(settings *conf.Settings) []InputBackendInfo {
+	var backends []InputBackendInfo
+	switch runtime.GOOS {
+	case "linux":
+		backends = append(backends,
+			listBackendDevices(InputBackendALSA, InputBackendALSA, malgo.BackendAlsa),
+			listBackendDevices(InputBackendPulse, InputBackendPulse, malgo.BackendPulseaudio))
+		if pipeWireRunning() {
+			backends = append(backends, listBackendDevices(InputBackendPipeWire, InputBackendPipeWire, malgo.BackendPulseaudio))
+		}
+	case "windows":
+		backends = append(backends, listBackendDevices("wasapi", "", malgo.BackendWasapi))
+	case "darwin":
+		backends = append(backends, listBackendDevices("coreaudio", "", malgo.BackendCoreaudio))
+	}
+
+	ffmpeg := settings.Realtime.Audio.FfmpegPath != ""
+	for _, backend := range []string{InputBackendRTSP, InputBackendSRT, InputBackendRTMP, InputBackendHTTP, InputBackendUDP, InputBackendFile} {
+		info := InputBackendInfo{Backend: backend, Prefix: backend, Available: ffmpeg, Devices: []InputDevice{}}
+		if !ffmpeg {
+			info.Error = "FFmpeg is not available"
+		}
+		backends = append(backends, info)
+	}
+	return backends
+}
+
+// listBackendDevices lists the capture devices of a miniaudio backend
+func listBackendDevices(name, prefix string, backend malgo.Backend) InputBackendInfo {
+	info := InputBackendInfo{Backend: name, Prefix: prefix, Devices: []InputDevice{}}
+
+	malgoCtx, err := malgo.InitContext([]malgo.Backend{backend}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		info.Error = fmt.Sprintf("backend not available: %v", err)
+		return info
+	}
+	defer malgoCtx.Uninit() //nolint:errcheck // Listing devices does not depend on it
+
+	infos, err := malgoCtx.Devices(malgo.Capture)
+	if err != nil {
+		info.Error = fmt.Sprintf("failed to get capture devices: %v", err)
+		return info
+	}
+	info.Available = true
+
+	for i := range infos {
+		// Skip the discard/null device
+		if strings.Contains(infos[i].Name(), "Discard all samples") {
+			continue
+		}
+		decodedID, err := hexToASCII(infos[i].ID.String())
+		if err != nil {
+			continue
+		}
+		info.Devices = append(info.Devices, InputDevice{
+			Name:        infos[i].Name(),
+			ID:          decodedID,
+			Declaration: SourceSpec{Backend: prefix, Device: decodedID}.String(),
+			Default:     infos[i].IsDefault == 1,
+		})
+	}
+	return info
+}
+
+// pipeWireRunning reports whether a PipeWire daemon serves the current user
+func pipeWireRunning() bool {
+	dir := os.Getenv("PIPEWIRE_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.Getenv("XDG_RUNTIME_DIR")
+	}
+	if dir == "" {
+		dir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	_, err := os.Stat(filepath.Join(dir, "pipewire-0"))
+	return err == nil
+}
@@ -0,0 +1,111 @@
+package myaudio
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourceSpec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		declaration string
+		want        SourceSpec
+		soundCard   bool
+	}{
+		{"sysdefault", SourceSpec{Device: "sysdefault"}, true},
+		{"hw:1,0", SourceSpec{Device: "hw:1,0"}, true},
+		{"sysdefault:CARD=Device", SourceSpec{Device: "sysdefault:CARD=Device"}, true},
+		{"alsa:hw:1,0", SourceSpec{Backend: InputBackendALSA, Device: "hw:1,0"}, true},
+		{"pulse:alsa_input.usb-mic.analog-mono", SourceSpec{Backend: InputBackendPulse, Device: "alsa_input.usb-mic.analog-mono"}, true},
+		{"PipeWire:USB Audio", SourceSpec{Backend: InputBackendPipeWire, Device: "USB Audio"}, true},
+		{"rtsp://camera.local/stream", SourceSpec{Backend: InputBackendRTSP, Device: "rtsp://camera.local/stream"}, false},
+		{"rtsps://camera.local/stream", SourceSpec{Backend: InputBackendRTSP, Device: "rtsps://camera.local/stream"}, false},
+		{"srt://relay.local:9000?latency=2000000", SourceSpec{Backend: InputBackendSRT, Device: "srt://relay.local:9000?latency=2000000"}, false},
+		{"rtmps://relay.local/live/mic", SourceSpec{Backend: InputBackendRTMP, Device: "rtmps://relay.local/live/mic"}, false},
+		{"http://radio.local:8000/birds.mp3", SourceSpec{Backend: InputBackendHTTP, Device: "http://radio.local:8000/birds.mp3"}, false},
+		{"udp://:5000?rate=16000", SourceSpec{Backend: InputBackendUDP, Device: "udp://:5000?rate=16000"}, false},
+		{"file:/run/birdnet/pipe.wav", SourceSpec{Backend: InputBackendFile, Device: "/run/birdnet/pipe.wav"}, false},
+		{"file:///run/birdnet/pipe.wav", SourceSpec{Backend: InputBackendFile, Device: "/run/birdnet/pipe.wav"}, false},
+	}
+	for _, tt := range tests {
+		spec := ParseSourceSpec(tt.declaration)
+		assert.Equal(t, tt.want, spec, tt.declaration)
+		assert.Equal(t, tt.soundCard, spec.IsSoundCard(), tt.declaration)
+	}
+
+	// Declarations round trip, apart from the backend prefix case and file URLs
+	for _, declaration := range []string{"hw:1,0", "pulse:USB Audio", "rtsp://camera.local/stream", "file:/tmp/a.wav"} {
+		assert.Equal(t, declaration, ParseSourceSpec(declaration).String())
+	}
+
+	assert.Equal(t, SourceTypeFile, StreamSourceType("file:/run/birdnet/pipe.wav"))
+	assert.Equal(t, SourceTypeRTSP, StreamSourceType("rtsp://camera.local/stream"))
+	assert.Equal(t, SourceTypeRTSP, StreamSourceType("srt://relay.local:9000"))
+}
+
+func TestStreamInputArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		declaration string
+		args        []string
+		input       string
+	}{
+		// Pulled streams time out
+		{"srt://relay.local:9000?latency=2000000", []string{"-timeout", "10000000"}, "srt://relay.local:9000?latency=2000000"},
+		{"rtmp://relay.local/live/mic", []string{"-rw_timeout", "10000000"}, "rtmp://relay.local/live/mic"},
+		// Pushed streams wait for their publisher
+		{"srt://:9000?mode=listener", nil, "srt://:9000?mode=listener"},
+		{"srt://0.0.0.0:9000", nil, "srt://0.0.0.0:9000?mode=listener"},
+		{"rtmp://[::]:1935/live/mic", []string{"-listen", "1"}, "rtmp://[::]:1935/live/mic"},
+		// Icecast reconnects within FFmpeg
+		{"https://radio.example.com/birds.mp3", []string{"-reconnect", "1", "-reconnect_streamed", "1", "-reconnect_delay_max", "30", "-rw_timeout", "10000000"}, "https://radio.example.com/birds.mp3"},
+		// Raw PCM takes its format from the URL
+		{"udp://:5000", []string{"-f", "s16le", "-ar", "48000", "-ac", "1"}, "udp://:5000?overrun_nonfatal=1"},
+		{"udp://0.0.0.0:5000?format=s32le&rate=16000&channels=2&fifo_size=100000", []string{"-f", "s32le", "-ar", "16000", "-ac", "2"}, "udp://0.0.0.0:5000?fifo_size=100000&overrun_nonfatal=1"},
+	}
+	for _, tt := range tests {
+		args, input, err := streamInputArgs(ParseSourceSpec(tt.declaration), 10*time.Second)
+		require.NoError(t, err, tt.declaration)
+		assert.Equal(t, tt.args, args, tt.declaration)
+		assert.Equal(t, tt.input, input, tt.declaration)
+	}
+
+	for _, declaration := range []string{"udp://:5000?format=mp3", "udp://:5000?rate=1000", "udp://:5000?channels=zero"} {
+		assert.Error(t, validateStreamDeclaration(declaration), declaration)
+	}
+	assert.NoError(t, validateStreamDeclaration("udp://:5000?format=F32LE"))
+}
+
+func TestFileInputArgs(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	// Regular files are replayed in real time, named pipes are read as written
+	assert.Equal(t, []string{"-re"}, fileInputArgs(filepath.Join(dir, "recording.wav")))
+
+	pipe := filepath.Join(dir, "pipe")
+	if err := exec.Command("mkfifo", pipe).Run(); err != nil {
+		t.Skipf("named pipes not supported: %v", err)
+	}
+	assert.Empty(t, fileInputArgs(pipe))
+}
+
+func TestRegisterFileSource(t *testing.T) {
+	registry := GetRegistry()
+	require.NotNil(t, registry)
+
+	source := registry.GetOrCreateSource("file:/run/birdnet/hydrophone", SourceTypeRTSP)
+	require.NotNil(t, source)
+	assert.Equal(t, SourceTypeFile, source.Type)
+	assert.Equal(t, "Audio File: hydrophone", source.DisplayName)
+
+	// System directories stay out of reach behind the prefix
+	assert.Nil(t, registry.GetOrCreateSource("file:/etc/shadow", SourceTypeFile))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+	"github.com/tphakala/birdnet-go/internal/observability/pipeline"
 )
 
 var (
@@ -67,13 +68,18 @@ func ReturnFloat32Buffer(buffer []float32) {
 
 // processData processes the given audio data to detect bird species, logs the detected species
 // and optionally saves the audio clip if a bird species is detected above the configured threshold.
+// It takes ownership of data, a segment from ReadFromAnalysisBuffer, which returns to the segment
+// pool once the results consumers released it.
 func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source string) error {
 	// get current time to track processing time
 	predictStart := time.Now()
 
+	pcmRef := birdnet.NewPCMRef(func() { ReleaseAnalysisSegment(data) })
+
 	// convert audio data to float32
 	sampleData, err := ConvertToFloat32(data, conf.BitDepth)
 	if err != nil {
+		pcmRef.Release()
 		return fmt.Errorf("error converting %v bit PCM data to float32: %w", conf.BitDepth, err)
 	}
 
@@ -87,11 +93,13 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 	}
 
 	if err != nil {
+		pcmRef.Release()
 		return fmt.Errorf("error predicting species: %w", err)
 	}
 
 	// get elapsed time
 	elapsedTime := time.Since(predictStart)
+	pipeline.Record(pipeline.StageInference, elapsedTime)
 
 	// DEBUG print all BirdNET results
 	if conf.Setting().BirdNET.Debug {
@@ -117,10 +125,8 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 	// Get the current settings
 	settings := conf.Setting()
 
-	// Calculate the effective buffer duration
-	bufferDuration := 3 * time.Second // base duration
-	overlapDuration := time.Duration(settings.BirdNET.Overlap * float64(time.Second))
-	effectiveBufferDuration := bufferDuration - overlapDuration
+	// The effective buffer duration is the time until the next analysis window
+	effectiveBufferDuration := conf.AnalysisHopDuration(settings.BirdNET.Overlap)
 
 	// Check if processing time exceeds effective buffer duration
 	if elapsedTime > effectiveBufferDuration {
@@ -170,6 +176,7 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 		PCMdata:     data,
 		Results:     results,
 		Source:      audioSource,
+		PCMRef:      pcmRef,
 	}
 
 	// Send the results to the queue
@@ -180,6 +187,7 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 	default:
 		log.Println("❌ Results queue is full!")
 		// Queue is full
+		pcmRef.Release()
 	}
 	return nil
 }
@@ -33,8 +33,8 @@ type AudioInfo struct {
 
 // GetTotalChunks calculates the total number of chunks for a given audio file
 func GetTotalChunks(sampleRate, totalSamples int, overlap float64) int {
-	chunkSamples := 3 * sampleRate                          // samples in 3 seconds
-	stepSamples := int((3 - overlap) * float64(sampleRate)) // samples per step based on overlap
+	chunkSamples := conf.CaptureLength * sampleRate             // samples in an analysis window
+	stepSamples := conf.AnalysisHopSamples(overlap, sampleRate) // samples per step based on overlap
 
 	if stepSamples <= 0 {
 		return 0
@@ -58,9 +58,9 @@ func readFLACBuffered(file *os.File, settings *conf.Settings, callback AudioChun
 		return err
 	}
 
-	step := int((3 - settings.BirdNET.Overlap) * conf.SampleRate)
-	minLenSamples := int(1.5 * conf.SampleRate)
-	secondsSamples := int(3 * conf.SampleRate)
+	step := conf.AnalysisHopSamples(settings.BirdNET.Overlap, conf.SampleRate)
+	minLenSamples := conf.AnalysisWindowSamples / 2
+	secondsSamples := conf.AnalysisWindowSamples
 
 	var currentChunk []float32
 
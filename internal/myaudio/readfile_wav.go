@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"time"
 
 	"github.com/go-audio/audio"
 	"github.com/go-audio/wav"
@@ -75,9 +74,9 @@ func readWAVBuffered(file *os.File, settings *conf.Settings, callback AudioChunk
 		return err
 	}
 
-	step := int((3 - settings.BirdNET.Overlap) * conf.SampleRate)
-	minLenSamples := int(1.5 * conf.SampleRate)
-	secondsSamples := int(3 * conf.SampleRate)
+	step := conf.AnalysisHopSamples(settings.BirdNET.Overlap, conf.SampleRate)
+	minLenSamples := conf.AnalysisWindowSamples / 2
+	secondsSamples := conf.AnalysisWindowSamples
 
 	var currentChunk []float32
 
@@ -173,7 +172,7 @@ func readWAVDirectBytes(file *os.File, decoder *wav.Decoder, settings *conf.Sett
 
 	// Create a more manageable buffer for reading - just read a few seconds at a time
 	// This ensures we don't try to load too much data at once
-	chunkDuration := 3 * time.Second // Read 3 seconds at a time
+	chunkDuration := conf.AnalysisWindow // Read one analysis window at a time
 	chunkSamples := int(chunkDuration.Seconds() * float64(decoder.SampleRate))
 
 	// Start by seeking to the data chunk
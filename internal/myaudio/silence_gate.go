@@ -0,0 +1,100 @@
+package myaudio
+
+import (
+	"math"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// silenceFrameSamples is the length of the frames whose levels are measured, 100 ms
+const silenceFrameSamples = conf.SampleRate / 10
+
+// silenceFloor is the level reported for digital silence, in dBFS
+const silenceFloor = -150.0
+
+// windowActivity is the loudness of an analysis window
+type windowActivity struct {
+	level   float64 // RMS level of the loudest frame in dBFS
+	entropy float64 // normalized entropy of the frame energies: 0 when one frame holds all energy, 1 when all are equal
+}
+
+// measureActivity measures the loudness of a window of PCM samples of the given bit depth.
+// A bird call stands out as a loud frame, or as a few frames holding most of the energy
+// when it is not much louder than the background.
+func measureActivity(data []byte, bitDepth int) windowActivity {
+	bytesPerSample := bitDepth / 8
+	if bytesPerSample < 2 || bytesPerSample > 4 {
+		return windowActivity{level: silenceFloor, entropy: 1}
+	}
+	samples := len(data) / bytesPerSample
+	fullScale := math.Ldexp(1, bitDepth-1)
+
+	energies := make([]float64, 0, samples/silenceFrameSamples+1)
+	var total, loudest float64
+	for start := 0; start < samples; start += silenceFrameSamples {
+		end := min(start+silenceFrameSamples, samples)
+		var sum float64
+		for i := start; i < end; i++ {
+			value := float64(pcmSample(data[i*bytesPerSample:], bytesPerSample)) / fullScale
+			sum += value * value
+		}
+		energy := sum / float64(end-start)
+		energies = append(energies, energy)
+		total += energy
+		loudest = max(loudest, energy)
+	}
+
+	activity := windowActivity{level: silenceFloor, entropy: 1}
+	if loudest > 0 {
+		// RMS level of the loudest frame, the square root is half of the logarithm
+		activity.level = max(10*math.Log10(loudest), silenceFloor)
+	}
+	if total > 0 && len(energies) > 1 {
+		var entropy float64
+		for _, energy := range energies {
+			if p := energy / total; p > 0 {
+				entropy -= p * math.Log(p)
+			}
+		}
+		activity.entropy = entropy / math.Log(float64(len(energies)))
+	}
+	return activity
+}
+
+// pcmSample decodes a little-endian signed PCM sample of 2 to 4 bytes
+func pcmSample(data []byte, bytesPerSample int) int32 {
+	switch bytesPerSample {
+	case 2:
+		return int32(int16(uint16(data[0]) | uint16(data[1])<<8))
+	case 3:
+		// Shift the 24-bit sample to the top of an int32 and back to extend the sign
+		return int32(uint32(data[0])<<8|uint32(data[1])<<16|uint32(data[2])<<24) >> 8
+	default:
+		return int32(uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24)
+	}
+}
+
+// silenceGate decides whether the windows of an audio source are analyzed. It closes on a
+// silent window and opens again only on clearly louder audio, so a level hovering around
+// the threshold does not toggle analysis on every window.
+type silenceGate struct {
+	closed bool // the previous window was silent
+}
+
+// skip reports whether inference is skipped for a window with the measured activity
+func (g *silenceGate) skip(settings *conf.SilenceGateSettings, activity windowActivity) bool {
+	if !settings.Enabled {
+		g.closed = false
+		return false
+	}
+
+	var active bool
+	if g.closed {
+		active = activity.level >= settings.Threshold+settings.Hysteresis ||
+			(settings.MaxEntropy > 0 && activity.level >= settings.Threshold && activity.entropy < settings.MaxEntropy)
+	} else {
+		active = activity.level >= settings.Threshold
+	}
+	g.closed = !active
+	return g.closed
+}
@@ -0,0 +1,95 @@
+// silence_gate_test.go
+// Tests for skipping inference on silent analysis windows
+
+package myaudio
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// testWindow returns a 3 second 16-bit window of noise at noiseLevel dBFS RMS with a 1 kHz
+// tone at toneLevel dBFS peak in the frames between toneStart and toneEnd, 0 for no tone
+func testWindow(noiseLevel, toneLevel float64, toneStart, toneEnd int) []byte {
+	rng := rand.New(rand.NewPCG(1, 2))
+	noise := math.Pow(10, noiseLevel/20) * math.Sqrt(3) // RMS of uniform noise is amplitude/sqrt(3)
+	tone := math.Pow(10, toneLevel/20)
+	samples := conf.SampleRate * conf.CaptureLength
+	data := make([]byte, samples*2)
+	for i := range samples {
+		value := noise * (2*rng.Float64() - 1)
+		if frame := i / silenceFrameSamples; toneLevel != 0 && frame >= toneStart && frame < toneEnd {
+			value += tone * math.Sin(2*math.Pi*1000*float64(i)/conf.SampleRate)
+		}
+		sample := int16(max(min(value*32768, 32767), -32768))
+		data[i*2] = byte(sample)
+		data[i*2+1] = byte(uint16(sample) >> 8)
+	}
+	return data
+}
+
+func TestMeasureActivity(t *testing.T) {
+	t.Parallel()
+
+	silence := measureActivity(make([]byte, conf.BufferSize), 16)
+	assert.InDelta(t, silenceFloor, silence.level, 1e-9)
+	assert.InDelta(t, 1.0, silence.entropy, 1e-9)
+
+	noise := measureActivity(testWindow(-60, 0, 0, 0), 16)
+	assert.InDelta(t, -60, noise.level, 1, "steady noise is as loud in every frame")
+	assert.Greater(t, noise.entropy, 0.99)
+
+	call := measureActivity(testWindow(-80, -50, 10, 12), 16)
+	assert.InDelta(t, -53, call.level, 1, "the RMS level of a sine is 3 dB below its peak")
+	assert.Less(t, call.entropy, 0.3, "a short call holds most of the energy of the window")
+
+	// 24-bit samples decode with their sign
+	assert.Equal(t, int32(-2), pcmSample([]byte{0xfe, 0xff, 0xff}, 3))
+	assert.Equal(t, int32(0x123456), pcmSample([]byte{0x56, 0x34, 0x12}, 3))
+}
+
+func TestSilenceGate(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.SilenceGateSettings{Enabled: true, Threshold: -70, Hysteresis: 6, MaxEntropy: 0.85}
+	var gate silenceGate
+	steady := func(level float64) windowActivity { return windowActivity{level: level, entropy: 0.99} }
+
+	assert.False(t, gate.skip(settings, steady(-65)), "audio above the threshold is analyzed")
+	assert.True(t, gate.skip(settings, steady(-75)), "silent windows are skipped")
+	assert.True(t, gate.skip(settings, steady(-67)), "the gate stays closed within the hysteresis")
+	assert.False(t, gate.skip(settings, steady(-63)), "audio above the hysteresis opens the gate")
+	assert.False(t, gate.skip(settings, steady(-69)), "the open gate closes only below the threshold")
+	assert.True(t, gate.skip(settings, steady(-71)))
+	assert.False(t, gate.skip(settings, windowActivity{level: -68, entropy: 0.4}), "a quiet call opens the gate by its low entropy")
+	assert.True(t, gate.skip(settings, steady(-90)))
+	assert.True(t, gate.skip(settings, windowActivity{level: -90, entropy: 0.1}), "entropy does not open the gate below the threshold")
+
+	settings.MaxEntropy = 0
+	assert.True(t, gate.skip(settings, windowActivity{level: -68, entropy: 0.1}), "the entropy check is disabled")
+
+	settings.Enabled = false
+	assert.False(t, gate.skip(settings, steady(-120)), "nothing is skipped when the gate is disabled")
+}
+
+func TestInferenceScheduler_CountsSilentChunks(t *testing.T) {
+	t.Parallel()
+
+	s := newInferenceScheduler(func() int { return 2 }, func() int { return 1 })
+	s.register("mic")
+	s.skip("mic", -80)
+	s.skip("mic", -72.5)
+	s.skip("mic", -90)
+
+	shares := s.shares()
+	require.Len(t, shares, 1)
+	assert.Equal(t, int64(3), shares[0].Silent)
+	assert.InDelta(t, -72.5, shares[0].SilentMaxLevel, 1e-9)
+	assert.Zero(t, shares[0].Processed)
+	s.unregister("mic")
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/debugcapture"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/logging"
 )
@@ -543,7 +544,7 @@ func getSoundLevelLogger() *slog.Logger {
 		logFilePath := filepath.Join("logs", "soundlevel.log")
 		// Set initial level based on debug flag
 		initialLevel := slog.LevelInfo
-		if conf.Setting().Realtime.Audio.SoundLevel.Debug {
+		if conf.Setting().Realtime.Audio.SoundLevel.Debug || debugcapture.Active(debugcapture.ComponentMyAudio) {
 			initialLevel = slog.LevelDebug
 		}
 		soundLevelLevelVar.Set(initialLevel)
@@ -565,7 +566,7 @@ func getSoundLevelLogger() *slog.Logger {
 
 // UpdateSoundLevelDebugSetting updates the debug log level for sound level processing
 func UpdateSoundLevelDebugSetting(debug bool) {
-	if debug {
+	if debug || debugcapture.Active(debugcapture.ComponentMyAudio) {
 		soundLevelLevelVar.Set(slog.LevelDebug)
 	} else {
 		soundLevelLevelVar.Set(slog.LevelInfo)
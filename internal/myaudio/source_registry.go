@@ -180,13 +180,24 @@ func (r *AudioSourceRegistry) GetOrCreateSource(connectionString string, sourceT
 
 // detectSourceTypeFromString determines source type from connection string
 func detectSourceTypeFromString(connectionString string) SourceType {
-	// RTSP URLs (including test URLs for testing)
+	// RTSP, SRT, RTMP, HTTP and UDP URLs (including test URLs for testing) are network streams
 	if strings.HasPrefix(connectionString, "rtsp://") ||
 		strings.HasPrefix(connectionString, "rtsps://") ||
+		strings.HasPrefix(connectionString, "srt://") ||
+		strings.HasPrefix(connectionString, "rtmp://") ||
+		strings.HasPrefix(connectionString, "rtmps://") ||
+		strings.HasPrefix(connectionString, "http://") ||
+		strings.HasPrefix(connectionString, "https://") ||
+		strings.HasPrefix(connectionString, "udp://") ||
 		strings.HasPrefix(connectionString, "test://") {
 		return SourceTypeRTSP
 	}
 
+	// Files and named pipes read by FFmpeg
+	if strings.HasPrefix(connectionString, "file:") {
+		return SourceTypeFile
+	}
+
 	// Audio device patterns
 	if strings.HasPrefix(connectionString, "hw:") ||
 		strings.HasPrefix(connectionString, "plughw:") ||
@@ -518,7 +529,7 @@ func (r *AudioSourceRegistry) validateConnectionString(connectionString string,
 	case SourceTypeRTSP:
 		return r.validateRTSPURL(connectionString)
 	case SourceTypeFile:
-		return r.validateFilePath(connectionString)
+		return r.validateFilePath(ParseSourceSpec(connectionString).Device)
 	case SourceTypeAudioCard:
 		return r.validateAudioDevice(connectionString)
 	default:
@@ -550,8 +561,14 @@ func (r *AudioSourceRegistry) validateRTSPURL(rtspURL string) error {
 	lowerURL := strings.ToLower(rtspURL)
 	if !strings.HasPrefix(lowerURL, "rtsp://") &&
 		!strings.HasPrefix(lowerURL, "rtsps://") &&
+		!strings.HasPrefix(lowerURL, "srt://") &&
+		!strings.HasPrefix(lowerURL, "rtmp://") &&
+		!strings.HasPrefix(lowerURL, "rtmps://") &&
+		!strings.HasPrefix(lowerURL, "http://") &&
+		!strings.HasPrefix(lowerURL, "https://") &&
+		!strings.HasPrefix(lowerURL, "udp://") &&
 		!strings.HasPrefix(lowerURL, "test://") {
-		return errors.Newf("invalid scheme, expected rtsp://, rtsps://, or test://").
+		return errors.Newf("invalid scheme, expected rtsp://, rtsps://, srt://, rtmp://, rtmps://, http://, https://, udp://, or test://").
 			Component("myaudio").
 			Category(errors.CategoryRTSP).
 			Context("operation", "validate_rtsp_url").
@@ -110,9 +110,9 @@ func TestRTSPValidationWithQueryParameters(t *testing.T) {
 		},
 		{
 			name:        "Invalid scheme",
-			rtspURL:     "http://192.168.1.100/stream",
+			rtspURL:     "ftp://192.168.1.100/stream",
 			shouldPass:  false,
-			description: "Should reject non-RTSP schemes",
+			description: "Should reject schemes that are no audio streams",
 		},
 	}
 
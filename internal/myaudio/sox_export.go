@@ -0,0 +1,185 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Limits of the SoX fallback pipeline
+const (
+	soxMaxGainDB    = 30.0  // Largest gain applied to reach a loudness target
+	silenceLoudness = -70.0 // Loudness reported for silent audio, in dBFS
+)
+
+// ExportAudio exports PCM data to the configured export type with FFmpeg, or with SoX when
+// FFmpeg is not available and SoX can write the export type. FLAC is encoded natively when
+// UseNativeFlac selects the native encoder.
+func ExportAudio(pcmData []byte, outputPath string, settings *conf.AudioSettings) error {
+	if settings != nil && settings.Export.Type == "flac" && UseNativeFlac(settings, settings.Export.Normalization.Enabled) {
+		return ExportAudioWithNativeFlac(pcmData, outputPath, settings)
+	}
+	if settings != nil && settings.FfmpegPath == "" && settings.SoxPath != "" &&
+		conf.IsSoxExportType(settings.Export.Type, settings.SoxAudioTypes) {
+		return ExportAudioWithSox(pcmData, outputPath, settings)
+	}
+	return ExportAudioWithFFmpeg(pcmData, outputPath, settings)
+}
+
+// ExportAudioWithSox exports PCM data to a WAV, FLAC or MP3 file using SoX. Normalization is
+// approximated by a gain that brings the RMS level of the clip to the target loudness, with
+// SoX's limiter preventing clipping.
+func ExportAudioWithSox(pcmData []byte, outputPath string, settings *conf.AudioSettings) error {
+	if settings == nil || settings.SoxPath == "" {
+		return errors.Newf("SoX path is not configured or invalid").
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "export_audio_sox").
+			Build()
+	}
+	if outputPath == "" || len(pcmData) == 0 {
+		return errors.Newf("empty output path or PCM data provided for export").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "export_audio_sox").
+			Context("data_size", len(pcmData)).
+			Build()
+	}
+
+	tempFilePath, err := createTempFile(outputPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := runSox(context.Background(), settings.SoxPath, pcmData, buildSoxExportArgs(tempFilePath, settings, pcmData)); err != nil {
+		_ = os.Remove(tempFilePath)
+		return errors.New(err).
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "export_audio_sox").
+			Context("export_type", settings.Export.Type).
+			Build()
+	}
+
+	if err := finalizeOutput(tempFilePath); err != nil {
+		_ = os.Remove(tempFilePath)
+		return err
+	}
+
+	if fileMetrics != nil {
+		fileMetrics.RecordFileOperation("export_sox", settings.Export.Type, "success")
+	}
+	return nil
+}
+
+// EncodePCMWithSoxContext encodes PCM data to an audio format in memory using SoX, applying
+// the given SoX effects such as "gain -l 6".
+func EncodePCMWithSoxContext(ctx context.Context, pcmData []byte, soxPath, format string, effects []string) (*bytes.Buffer, error) {
+	if soxPath == "" || len(pcmData) == 0 {
+		return nil, errors.Newf("SoX path or PCM data is empty").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "encode_pcm_sox").
+			Build()
+	}
+
+	args := append(soxInputArgs(), "-t", format, "-")
+	args = append(args, effects...)
+	return runSox(ctx, soxPath, pcmData, args)
+}
+
+// runSox runs SoX with PCM data on stdin on the encoder pool and returns what it wrote to stdout
+func runSox(ctx context.Context, soxPath string, pcmData []byte, args []string) (*bytes.Buffer, error) {
+	var stdout *bytes.Buffer
+	err := RunEncoderJob(ctx, "run_sox", func(ctx context.Context) error {
+		var err error
+		stdout, err = runSoxProcess(ctx, soxPath, pcmData, args)
+		return err
+	})
+	return stdout, err
+}
+
+// runSoxProcess runs a SoX process with PCM data on stdin
+func runSoxProcess(ctx context.Context, soxPath string, pcmData []byte, args []string) (*bytes.Buffer, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, soxPath, args...) //nolint:gosec // G204: soxPath is validated at startup, args are built internally
+	cmd.Stdin = bytes.NewReader(pcmData)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("SoX command timed out: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("SoX command failed: %w, stderr: %s", err, stderr.String())
+	}
+	return &stdout, nil
+}
+
+// soxInputArgs describes the raw PCM data written to SoX's stdin
+func soxInputArgs() []string {
+	return []string{
+		"-t", "raw",
+		"-r", strconv.Itoa(conf.SampleRate),
+		"-e", "signed-integer",
+		"-b", strconv.Itoa(conf.BitDepth),
+		"-c", strconv.Itoa(conf.NumChannels),
+		"-",
+	}
+}
+
+// buildSoxExportArgs constructs the SoX arguments for exporting a clip to tempFilePath
+func buildSoxExportArgs(tempFilePath string, settings *conf.AudioSettings, pcmData []byte) []string {
+	args := soxInputArgs()
+	if settings.Export.Type == "mp3" {
+		// SoX takes the MP3 bitrate in kbps as compression factor
+		bitrate := strings.TrimSuffix(getMaxBitrate("mp3", settings.Export.Bitrate), "k")
+		args = append(args, "-C", bitrate)
+	}
+	// The temporary file has no audio extension, so the type is given explicitly
+	args = append(args, "-t", settings.Export.Type, tempFilePath)
+
+	switch {
+	case settings.Export.Normalization.Enabled:
+		gain := LoudnessGain(EstimatePCMLoudness(pcmData), settings.Export.Normalization.TargetLUFS)
+		args = append(args, "gain", "-l", strconv.FormatFloat(gain, 'f', 2, 64))
+	case settings.Export.Gain != 0:
+		args = append(args, "gain", strconv.FormatFloat(settings.Export.Gain, 'f', 1, 64))
+	}
+	return args
+}
+
+// EstimatePCMLoudness returns the RMS level of 16-bit PCM data in dBFS. Without K-weighting
+// and gating it only approximates integrated loudness in LUFS, which is close enough to pick
+// a normalization gain when FFmpeg's loudnorm filter is not available.
+func EstimatePCMLoudness(pcmData []byte) float64 {
+	samples := len(pcmData) / 2
+	if samples == 0 {
+		return silenceLoudness
+	}
+
+	var sumSquares float64
+	for i := 0; i+1 < len(pcmData); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcmData[i:]))) / 32768.0 //nolint:gosec // G115: reinterpreting PCM bytes as signed samples
+		sumSquares += sample * sample
+	}
+	rms := math.Sqrt(sumSquares / float64(samples))
+	if rms == 0 {
+		return silenceLoudness
+	}
+	return math.Max(silenceLoudness, 20*math.Log10(rms))
+}
+
+// LoudnessGain returns the gain in dB that brings audio at measured loudness to target,
+// limited to ±30 dB so near silent clips are not amplified into noise
+func LoudnessGain(measured, target float64) float64 {
+	return math.Max(-soxMaxGainDB, math.Min(soxMaxGainDB, target-measured))
+}
@@ -0,0 +1,87 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// sinePCM returns one second of a 16-bit 1 kHz sine wave with the given peak amplitude
+func sinePCM(amplitude float64) []byte {
+	pcm := make([]byte, conf.SampleRate*2)
+	for i := range conf.SampleRate {
+		sample := int16(amplitude * 32767 * math.Sin(2*math.Pi*1000*float64(i)/float64(conf.SampleRate)))
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(sample)) //nolint:gosec // G115: two's complement encoding of the sample
+	}
+	return pcm
+}
+
+func TestEstimatePCMLoudness(t *testing.T) {
+	// A full scale sine has an RMS level of -3 dBFS
+	assert.InDelta(t, -3.01, EstimatePCMLoudness(sinePCM(1)), 0.05)
+	assert.InDelta(t, -23.01, EstimatePCMLoudness(sinePCM(0.1)), 0.05)
+	assert.InDelta(t, silenceLoudness, EstimatePCMLoudness(make([]byte, 1000)), 0)
+	assert.InDelta(t, silenceLoudness, EstimatePCMLoudness(nil), 0)
+}
+
+func TestLoudnessGain(t *testing.T) {
+	assert.InDelta(t, 10.0, LoudnessGain(-33, -23), 0)
+	assert.InDelta(t, -5.0, LoudnessGain(-18, -23), 0)
+	assert.InDelta(t, soxMaxGainDB, LoudnessGain(silenceLoudness, -23), 0, "gain is limited for near silent clips")
+}
+
+func TestBuildSoxExportArgs(t *testing.T) {
+	input := []string{"-t", "raw", "-r", "48000", "-e", "signed-integer", "-b", "16", "-c", "1", "-"}
+
+	settings := &conf.AudioSettings{Export: conf.ExportSettings{Type: "mp3", Bitrate: "128k", Gain: -6}}
+	assert.Equal(t, append(append([]string{}, input...), "-C", "128", "-t", "mp3", "clip.temp", "gain", "-6.0"),
+		buildSoxExportArgs("clip.temp", settings, nil))
+
+	settings = &conf.AudioSettings{Export: conf.ExportSettings{
+		Type:          "flac",
+		Gain:          6,
+		Normalization: conf.NormalizationSettings{Enabled: true, TargetLUFS: -23},
+	}}
+	assert.Equal(t, append(append([]string{}, input...), "-t", "flac", "clip.temp", "gain", "-l", "-19.99"),
+		buildSoxExportArgs("clip.temp", settings, sinePCM(1)), "normalization takes precedence over gain")
+
+	settings = &conf.AudioSettings{Export: conf.ExportSettings{Type: "wav"}}
+	assert.Equal(t, append(append([]string{}, input...), "-t", "wav", "clip.temp"),
+		buildSoxExportArgs("clip.temp", settings, nil))
+}
+
+func TestExportAudioSelectsSox(t *testing.T) {
+	dir := t.TempDir()
+	settings := &conf.AudioSettings{
+		SoxPath:       filepath.Join(dir, "missing-sox"),
+		SoxAudioTypes: []string{"wav", "flac"},
+		Export:        conf.ExportSettings{Type: "flac"},
+	}
+
+	err := ExportAudio(sinePCM(0.5), filepath.Join(dir, "clip.flac"), settings)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SoX command failed", "FLAC export uses SoX without FFmpeg")
+
+	settings.Export.Type = "opus"
+	err = ExportAudio(sinePCM(0.5), filepath.Join(dir, "clip.opus"), settings)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FFmpeg path is not configured", "SoX does not export opus")
+}
+
+func TestExportAudioWithSox(t *testing.T) {
+	soxPath, err := exec.LookPath(conf.GetSoxBinaryName())
+	if err != nil {
+		t.Skip("sox not available")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "clip.flac")
+	settings := &conf.AudioSettings{SoxPath: soxPath, Export: conf.ExportSettings{Type: "flac"}}
+	require.NoError(t, ExportAudioWithSox(sinePCM(0.5), outputPath, settings))
+	assert.FileExists(t, outputPath)
+}
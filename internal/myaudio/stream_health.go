@@ -0,0 +1,168 @@
+// stream_health.go: RTSP source health for the API and the event bus
+package myaudio
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// SourceHealth is the health of an RTSP, SRT or RTMP source as reported by the API
+type SourceHealth struct {
+	ID                      string    `json:"id"`
+	Name                    string    `json:"name"`
+	URL                     string    `json:"url"` // sanitized, without credentials
+	Healthy                 bool      `json:"healthy"`
+	ReceivingData           bool      `json:"receivingData"`
+	LastDataReceived        time.Time `json:"lastDataReceived,omitzero"`
+	SecondsSinceData        float64   `json:"secondsSinceData"` // 0 when no audio was ever received
+	BytesPerSecond          float64   `json:"bytesPerSecond"`
+	FramesPerSecond         float64   `json:"framesPerSecond"`
+	ExpectedFramesPerSecond int       `json:"expectedFramesPerSecond"`
+	LatencySeconds          float64   `json:"latencySeconds"` // delay above the fastest delivery of the last minutes
+	JitterSeconds           float64   `json:"jitterSeconds"`
+	RestartCount            int       `json:"restartCount"`
+	Restarting              bool      `json:"restarting"` // restart in progress, in backoff or circuit breaker cooldown
+	RestartPolicy           string    `json:"restartPolicy"`
+}
+
+// restartBackoff returns the wait before the first restart of a stream and the longest wait
+// between restarts, the defaults for unset values
+func restartBackoff(settings *conf.RTSPHealthSettings) (initial, maximum time.Duration) {
+	initial, maximum = defaultBackoffDuration, maxBackoffDuration
+	if settings.InitialBackoff > 0 {
+		initial = time.Duration(settings.InitialBackoff) * time.Second
+	}
+	if settings.MaxBackoff > 0 {
+		maximum = time.Duration(settings.MaxBackoff) * time.Second
+	}
+	return initial, max(initial, maximum)
+}
+
+// restartPolicy returns the configured restart policy of RTSP streams
+func restartPolicy() string {
+	if policy := conf.Setting().Realtime.RTSP.Health.RestartPolicy; policy != "" {
+		return policy
+	}
+	return conf.RTSPRestartAlways
+}
+
+// healthyDataThreshold returns the seconds without audio after which a stream is unhealthy
+func healthyDataThreshold() float64 {
+	if threshold := conf.Setting().Realtime.RTSP.Health.HealthyDataThreshold; threshold > 0 {
+		return float64(threshold)
+	}
+	return defaultHealthyDataThreshold.Seconds()
+}
+
+// SourceHealth returns the health of all managed streams sorted by name
+func (m *FFmpegManager) SourceHealth() []SourceHealth {
+	m.streamsMu.RLock()
+	streams := make([]*FFmpegStream, 0, len(m.streams))
+	for _, stream := range m.streams {
+		streams = append(streams, stream)
+	}
+	m.streamsMu.RUnlock()
+
+	policy := restartPolicy()
+	health := make([]SourceHealth, 0, len(streams))
+	for _, stream := range streams {
+		h := stream.GetHealth()
+		health = append(health, SourceHealth{
+			ID:                      stream.source.ID,
+			Name:                    stream.source.DisplayName,
+			URL:                     privacy.SanitizeRTSPUrl(stream.source.SafeString),
+			Healthy:                 h.IsHealthy,
+			ReceivingData:           h.IsReceivingData,
+			LastDataReceived:        h.LastDataReceived,
+			SecondsSinceData:        getTimeSinceDataSeconds(h.LastDataReceived),
+			BytesPerSecond:          h.BytesPerSecond,
+			FramesPerSecond:         h.FramesPerSecond,
+			ExpectedFramesPerSecond: conf.SampleRate,
+			LatencySeconds:          h.Latency.Seconds(),
+			JitterSeconds:           h.Jitter.Seconds(),
+			RestartCount:            h.RestartCount,
+			Restarting:              stream.IsRestarting(),
+			RestartPolicy:           policy,
+		})
+	}
+	slices.SortFunc(health, func(a, b SourceHealth) int {
+		return strings.Compare(a.Name+a.ID, b.Name+b.ID)
+	})
+	return health
+}
+
+// GetRTSPSourceHealth returns the health of all RTSP sources sorted by name, without starting
+// the FFmpeg manager when no stream was started yet
+func GetRTSPSourceHealth() []SourceHealth {
+	managerMutex.RLock()
+	manager := globalManager
+	managerMutex.RUnlock()
+	if manager == nil {
+		return []SourceHealth{}
+	}
+	return manager.SourceHealth()
+}
+
+// reportUnhealthy publishes a health event when a stream is first found unhealthy, a
+// critical one when it is not being restarted
+func (m *FFmpegManager) reportUnhealthy(url string, stream *FFmpegStream, h *StreamHealth, restarting bool) {
+	if m.unhealthy[url] {
+		return
+	}
+	m.unhealthy[url] = true
+
+	severity := events.SeverityWarning
+	if !restarting {
+		severity = events.SeverityCritical
+	}
+	publishSourceHealth(stream, h, severity)
+}
+
+// reportHealthy publishes a recovery event when a stream reported unhealthy delivers audio again
+func (m *FFmpegManager) reportHealthy(url string, stream *FFmpegStream, h *StreamHealth) {
+	if !m.unhealthy[url] {
+		return
+	}
+	delete(m.unhealthy, url)
+	publishSourceHealth(stream, h, events.SeverityRecovery)
+}
+
+// forgetRemovedStreams drops the health state of streams that are no longer managed
+func (m *FFmpegManager) forgetRemovedStreams(health map[string]StreamHealth) {
+	for url := range m.unhealthy {
+		if _, ok := health[url]; !ok {
+			delete(m.unhealthy, url)
+		}
+	}
+}
+
+// publishSourceHealth publishes a health event of a stream on the event bus
+func publishSourceHealth(stream *FFmpegStream, h *StreamHealth, severity string) {
+	eventBus := events.GetEventBus()
+	if eventBus == nil {
+		return
+	}
+
+	event := events.NewAudioSourceEvent(stream.source.ID, stream.source.DisplayName,
+		getTimeSinceDataSeconds(h.LastDataReceived), healthyDataThreshold(), severity,
+		map[string]interface{}{
+			"url":               privacy.SanitizeRTSPUrl(stream.source.SafeString),
+			"restart_count":     h.RestartCount,
+			"bytes_per_second":  h.BytesPerSecond,
+			"frames_per_second": h.FramesPerSecond,
+			"latency_seconds":   h.Latency.Seconds(),
+			"jitter_seconds":    h.Jitter.Seconds(),
+			"restart_policy":    restartPolicy(),
+		})
+	if !eventBus.TryPublishResource(event) {
+		managerLogger.Debug("source health event not published",
+			"source_id", stream.source.ID,
+			"severity", severity,
+			"operation", "publish_source_health")
+	}
+}
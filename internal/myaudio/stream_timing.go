@@ -0,0 +1,86 @@
+// stream_timing.go: delivery latency and jitter of FFmpeg streams
+package myaudio
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// timingWindow is how long the fastest delivery of a stream remains the baseline of its latency
+const timingWindow = time.Minute
+
+// streamTiming estimates the latency and jitter of a stream from the arrival times of its audio,
+// as RTP receivers do (RFC 3550) with the duration of the received audio as media clock. The
+// transit time of audio is its arrival time less its position in the stream. Latency is the
+// transit above the smallest one of the last one to two minutes, the delay a congested link or
+// retransmissions add; jitter is the smoothed change in transit between consecutive reads.
+type streamTiming struct {
+	mu          sync.Mutex
+	start       time.Time     // arrival of the first audio
+	media       time.Duration // duration of the audio received since start
+	lastTransit time.Duration
+	windowStart time.Time
+	windowMin   time.Duration // smallest transit of the current window
+	previousMin time.Duration // smallest transit of the previous window
+	latency     time.Duration
+	jitter      float64 // seconds
+}
+
+// reset forgets the timing of the previous FFmpeg process
+func (t *streamTiming) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start, t.media, t.latency, t.jitter = time.Time{}, 0, 0, 0
+}
+
+// addSample records audio bytes arriving at now
+func (t *streamTiming) addSample(now time.Time, numBytes int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bytesPerSecond := conf.SampleRate * conf.NumChannels * conf.BitDepth / 8
+	t.media += time.Duration(numBytes) * time.Second / time.Duration(bytesPerSecond)
+	if t.start.IsZero() {
+		t.start = now
+		t.lastTransit = -t.media
+		t.windowStart, t.windowMin, t.previousMin = now, t.lastTransit, t.lastTransit
+		return
+	}
+
+	transit := now.Sub(t.start) - t.media
+	t.jitter += (math.Abs((transit - t.lastTransit).Seconds()) - t.jitter) / 16
+	t.lastTransit = transit
+
+	if now.Sub(t.windowStart) >= timingWindow {
+		t.previousMin, t.windowMin, t.windowStart = t.windowMin, transit, now
+	} else {
+		t.windowMin = min(t.windowMin, transit)
+	}
+	t.latency = transit - min(t.windowMin, t.previousMin)
+}
+
+// stats returns the current latency and jitter of the stream
+func (t *streamTiming) stats() (latency, jitter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.latency, time.Duration(t.jitter * float64(time.Second))
+}
+
+// recordStreamTiming updates the latency and jitter metrics of the managed streams
+func (m *FFmpegManager) recordStreamTiming(health map[string]StreamHealth) {
+	metrics := getAnalysisMetrics()
+	if metrics == nil {
+		return
+	}
+
+	m.streamsMu.RLock()
+	defer m.streamsMu.RUnlock()
+	for url, h := range health {
+		if stream, ok := m.streams[url]; ok {
+			metrics.UpdateStreamTiming(stream.source.ID, h.Latency.Seconds(), h.Jitter.Seconds())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package myaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestStreamTiming(t *testing.T) {
+	t.Parallel()
+
+	// 100 ms of audio per read
+	chunk := conf.SampleRate * conf.NumChannels * conf.BitDepth / 8 / 10
+	var timing streamTiming
+	now := time.Date(2025, 5, 10, 6, 0, 0, 0, time.UTC)
+
+	// Steady delivery has neither latency nor jitter
+	for range 50 {
+		timing.addSample(now, chunk)
+		now = now.Add(100 * time.Millisecond)
+	}
+	latency, jitter := timing.stats()
+	assert.Zero(t, latency)
+	assert.Zero(t, jitter)
+
+	// A two second stall delays the audio behind it until the sender catches up
+	now = now.Add(2 * time.Second)
+	timing.addSample(now, chunk)
+	latency, jitter = timing.stats()
+	assert.Equal(t, 2*time.Second, latency)
+	assert.InDelta(t, 2.0/16, jitter.Seconds(), 0.001)
+
+	for range 20 {
+		timing.addSample(now, chunk)
+	}
+	latency, _ = timing.stats()
+	assert.Zero(t, latency)
+
+	// A restarted process starts over
+	timing.reset()
+	latency, jitter = timing.stats()
+	assert.Zero(t, latency)
+	assert.Zero(t, jitter)
+}
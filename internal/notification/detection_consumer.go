@@ -48,8 +48,11 @@ func (c *DetectionNotificationConsumer) SupportsBatching() bool {
 
 // ProcessDetectionEvent processes a single detection event
 func (c *DetectionNotificationConsumer) ProcessDetectionEvent(event events.DetectionEvent) error {
-	// Only process new species detections
 	if !event.IsNewSpecies() {
+		// Rare species events carry the rarity score that triggered them
+		if score, ok := event.GetMetadata()[events.MetadataRarityScore].(float64); ok {
+			return c.processRareSpeciesEvent(event, score)
+		}
 		return nil
 	}
 
@@ -77,18 +80,9 @@ func (c *DetectionNotificationConsumer) ProcessDetectionEvent(event events.Detec
 		WithMetadata("days_since_first_seen", event.GetDaysSinceFirstSeen()).
 		WithExpiry(24 * time.Hour) // New species notifications expire after 24 hours
 
-	// Add the notification through the service
-	// First save to store
-	if err := c.service.store.Save(notification); err != nil {
-		c.logger.Error("failed to save new species notification",
-			"species", event.GetSpeciesName(),
-			"error", err,
-		)
-		return fmt.Errorf("failed to save notification: %w", err)
+	if err := c.publish(notification, event); err != nil {
+		return err
 	}
-	
-	// Then broadcast to subscribers
-	c.service.broadcast(notification)
 
 	c.logger.Info("created new species notification",
 		"species", event.GetSpeciesName(),
@@ -97,4 +91,54 @@ func (c *DetectionNotificationConsumer) ProcessDetectionEvent(event events.Detec
 	)
 
 	return nil
-}
\ No newline at end of file
+}
+
+// processRareSpeciesEvent creates a notification for a species that is unusual for the
+// station and week of the year
+func (c *DetectionNotificationConsumer) processRareSpeciesEvent(event events.DetectionEvent, score float64) error {
+	displayLocation := event.GetLocation()
+
+	title := fmt.Sprintf("Rare Species Detected: %s", event.GetSpeciesName())
+	// Score and confidence are left out of the message so repeated detections deduplicate
+	message := fmt.Sprintf(
+		"%s (%s) is unusual at %s for this time of year",
+		event.GetSpeciesName(),
+		event.GetScientificName(),
+		displayLocation,
+	)
+
+	notification := NewNotification(TypeDetection, PriorityHigh, title, message).
+		WithComponent("detection").
+		WithMetadata("species", event.GetSpeciesName()).
+		WithMetadata("scientific_name", event.GetScientificName()).
+		WithMetadata("confidence", event.GetConfidence()).
+		WithMetadata("location", displayLocation).
+		WithMetadata("rarity_score", score).
+		WithExpiry(24 * time.Hour)
+
+	if err := c.publish(notification, event); err != nil {
+		return err
+	}
+
+	c.logger.Info("created rare species notification",
+		"species", event.GetSpeciesName(),
+		"rarity_score", score,
+		"location", displayLocation,
+	)
+
+	return nil
+}
+
+// publish saves a detection notification and broadcasts it to subscribers
+func (c *DetectionNotificationConsumer) publish(notification *Notification, event events.DetectionEvent) error {
+	if err := c.service.store.Save(notification); err != nil {
+		c.logger.Error("failed to save detection notification",
+			"species", event.GetSpeciesName(),
+			"error", err,
+		)
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	c.service.broadcast(notification)
+	return nil
+}
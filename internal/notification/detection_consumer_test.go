@@ -113,6 +113,40 @@ func TestDetectionNotificationConsumer(t *testing.T) {
 	assert.Len(t, notifications, 1)
 }
 
+func TestDetectionNotificationConsumer_RareSpecies(t *testing.T) {
+	t.Parallel()
+
+	config := &ServiceConfig{
+		MaxNotifications:   100,
+		CleanupInterval:    5 * time.Minute,
+		RateLimitWindow:    1 * time.Minute,
+		RateLimitMaxEvents: 100,
+	}
+	service := NewService(config)
+	require.NotNil(t, service)
+	defer service.Stop()
+
+	consumer := NewDetectionNotificationConsumer(service)
+
+	event, err := events.NewDetectionEvent("Hoopoe", "Upupa epops", 0.81, "garden", false, 400)
+	require.NoError(t, err)
+	event.GetMetadata()[events.MetadataRarityScore] = 0.93
+
+	require.NoError(t, consumer.ProcessDetectionEvent(event))
+
+	notifications, err := service.List(&FilterOptions{Types: []Type{TypeDetection}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+
+	notif := notifications[0]
+	assert.Equal(t, PriorityHigh, notif.Priority)
+	assert.Equal(t, "Rare Species Detected: Hoopoe", notif.Title)
+	assert.Contains(t, notif.Message, "Upupa epops")
+	assert.Contains(t, notif.Message, "garden")
+	assert.NotContains(t, notif.Message, "0.93", "Message should not contain the score to allow deduplication")
+	assert.InDelta(t, 0.93, notif.Metadata["rarity_score"], 0.001)
+}
+
 // TestDetectionNotificationConsumer_PreSanitizedLocations verifies that the notification
 // consumer correctly handles pre-sanitized location data from the audio source registry.
 // In the new architecture, RTSP URL sanitization happens at the audio source registry level,
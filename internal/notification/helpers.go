@@ -57,21 +57,17 @@ func NotifyDetection(species string, confidence float64, metadata map[string]any
 	title := fmt.Sprintf("Detected: %s", species)
 	message := fmt.Sprintf("Confidence: %.1f%%", confidence*100)
 
-	notification, err := service.CreateWithComponent(
-		TypeDetection,
-		PriorityMedium,
-		title,
-		message,
-		"detection",
-	)
-
-	if err == nil && notification != nil && metadata != nil {
-		// Add metadata
-		for k, v := range metadata {
-			notification.WithMetadata(k, v)
-		}
-		_ = service.store.Update(notification)
+	// Metadata is added before the notification is broadcast, so subscribers such as
+	// push notifications can filter by species
+	notification := NewNotification(TypeDetection, PriorityMedium, title, message).
+		WithComponent("detection")
+	for k, v := range metadata {
+		notification.WithMetadata(k, v)
 	}
+	notification.WithMetadata("species", species).
+		WithMetadata("confidence", confidence)
+
+	_ = service.CreateWithMetadata(notification)
 }
 
 // NotifyIntegrationFailure creates a notification for integration failures
@@ -115,7 +115,7 @@ func (w *ResourceEventWorker) ProcessResourceEvent(event events.ResourceEvent) e
 	// Create alert key for throttling - include path for disk resources
 	// Use "|" as separator since it cannot appear in file paths
 	alertKey := fmt.Sprintf("%s|%s", event.GetResourceType(), event.GetSeverity())
-	if event.GetResourceType() == events.ResourceDisk && event.GetPath() != "" {
+	if (event.GetResourceType() == events.ResourceDisk || event.GetResourceType() == events.ResourceAudioSource) && event.GetPath() != "" {
 		// Sanitize path by replacing any "|" characters (though they shouldn't exist)
 		sanitizedPath := strings.ReplaceAll(event.GetPath(), "|", "_")
 		alertKey = fmt.Sprintf("%s|%s|%s", event.GetResourceType(), sanitizedPath, event.GetSeverity())
@@ -144,35 +144,49 @@ func (w *ResourceEventWorker) ProcessResourceEvent(event events.ResourceEvent) e
 
 	resourceName := getResourceDisplayName(event.GetResourceType())
 
-	// Include path in resource name for disk resources
-	if event.GetResourceType() == events.ResourceDisk && event.GetPath() != "" {
+	// Include path in resource name for disk resources and the name of audio sources
+	if (event.GetResourceType() == events.ResourceDisk || event.GetResourceType() == events.ResourceAudioSource) && event.GetPath() != "" {
 		resourceName = fmt.Sprintf("%s (%s)", resourceName, event.GetPath())
 	}
 
-	switch event.GetSeverity() {
-	case events.SeverityRecovery:
-		notifType = TypeInfo
-		// Use higher priority for disk recovery
-		if event.GetResourceType() == events.ResourceDisk {
-			priority = PriorityMedium
-		} else {
-			priority = PriorityLow
+	if event.GetResourceType() == events.ResourceAudioSource {
+		notifType, priority, title = audioSourceAlert(event.GetSeverity(), resourceName)
+		if title == "" {
+			// Unknown severity, skip
+			return nil
 		}
-		title = fmt.Sprintf("%s Usage Recovered", resourceName)
+	} else if event.GetResourceType() == events.ResourceDatastore {
+		notifType, priority, title = datastoreAlert(event.GetSeverity())
+		if title == "" {
+			// Unknown severity, skip
+			return nil
+		}
+	} else {
+		switch event.GetSeverity() {
+		case events.SeverityRecovery:
+			notifType = TypeInfo
+			// Use higher priority for disk recovery
+			if event.GetResourceType() == events.ResourceDisk {
+				priority = PriorityMedium
+			} else {
+				priority = PriorityLow
+			}
+			title = fmt.Sprintf("%s Usage Recovered", resourceName)
 		
-	case events.SeverityWarning:
-		notifType = TypeWarning
-		priority = PriorityHigh
-		title = fmt.Sprintf("High %s Usage", resourceName)
+		case events.SeverityWarning:
+			notifType = TypeWarning
+			priority = PriorityHigh
+			title = fmt.Sprintf("High %s Usage", resourceName)
 		
-	case events.SeverityCritical:
-		notifType = TypeWarning
-		priority = PriorityCritical
-		title = fmt.Sprintf("Critical %s Usage", resourceName)
+		case events.SeverityCritical:
+			notifType = TypeWarning
+			priority = PriorityCritical
+			title = fmt.Sprintf("Critical %s Usage", resourceName)
 		
-	default:
-		// Unknown severity, skip
-		return nil
+		default:
+			// Unknown severity, skip
+			return nil
+		}
 	}
 
 	// Create notification
@@ -267,6 +281,36 @@ func (w *ResourceEventWorker) updateLastAlertTime(alertKey string) {
 	w.mu.Unlock()
 }
 
+// audioSourceAlert returns the notification type, priority and title of an audio source
+// health event, an empty title for severities that are not notified
+func audioSourceAlert(severity, resourceName string) (Type, Priority, string) {
+	switch severity {
+	case events.SeverityRecovery:
+		return TypeInfo, PriorityLow, fmt.Sprintf("%s Recovered", resourceName)
+	case events.SeverityWarning:
+		return TypeWarning, PriorityHigh, fmt.Sprintf("%s Stopped Delivering Audio", resourceName)
+	case events.SeverityCritical:
+		return TypeWarning, PriorityCritical, fmt.Sprintf("%s Stalled", resourceName)
+	default:
+		return "", "", ""
+	}
+}
+
+// datastoreAlert returns the notification type, priority and title of a database degraded
+// mode event, an empty title for severities that are not notified
+func datastoreAlert(severity string) (Type, Priority, string) {
+	switch severity {
+	case events.SeverityRecovery:
+		return TypeInfo, PriorityMedium, "Database Recovered"
+	case events.SeverityWarning:
+		return TypeWarning, PriorityHigh, "Database Unavailable"
+	case events.SeverityCritical:
+		return TypeError, PriorityCritical, "Database Unavailable, Detections Lost"
+	default:
+		return "", "", ""
+	}
+}
+
 // getResourceDisplayName returns a display-friendly name for a resource type
 func getResourceDisplayName(resourceType string) string {
 	switch resourceType {
@@ -276,6 +320,10 @@ func getResourceDisplayName(resourceType string) string {
 		return "Memory"
 	case events.ResourceDisk:
 		return "Disk"
+	case events.ResourceAudioSource:
+		return "Audio Source"
+	case events.ResourceDatastore:
+		return "Database"
 	default:
 		return resourceType
 	}
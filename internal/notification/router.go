@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Router decides which users receive a notification on a channel, by the notification
+// preferences of their user accounts. Channels fanning out notifications to users, such as
+// the dashboard stream and Web Push, ask the router before delivering.
+type Router struct {
+	settings *conf.Settings
+	now      func() time.Time
+}
+
+// NewRouter creates a router for the user accounts of the settings. The users are read on
+// every decision, so changed preferences apply without a restart.
+func NewRouter(settings *conf.Settings) *Router {
+	return &Router{settings: settings, now: time.Now}
+}
+
+// Allows reports whether a notification is delivered to a user on a channel. Users without
+// a user account, such as the basic auth admin, receive all notifications.
+//
+// A user receives a notification when the channel is one of the chosen channels, detection
+// notifications are about a species of interest, and the quiet hours are not in effect.
+// Critical notifications are delivered during quiet hours.
+func (r *Router) Allows(username, channel string, notif *Notification) bool {
+	prefs := r.preferences(username)
+	if prefs == nil || notif == nil {
+		return true
+	}
+
+	if len(prefs.Channels) > 0 && !slices.Contains(prefs.Channels, channel) {
+		return false
+	}
+	if notif.Type == TypeDetection && !interestedIn(prefs.Species, notif) {
+		return false
+	}
+	if notif.Priority != PriorityCritical && prefs.QuietHours.Contains(r.now()) {
+		return false
+	}
+	return true
+}
+
+// preferences returns the notification preferences of a user, nil for unknown users
+func (r *Router) preferences(username string) *conf.UserNotificationSettings {
+	if r.settings == nil || username == "" {
+		return nil
+	}
+	// The users slice is replaced, not modified, when accounts change
+	users := r.settings.Security.Users
+	for i := range users {
+		if strings.EqualFold(users[i].Username, username) {
+			return &users[i].Notifications
+		}
+	}
+	return nil
+}
+
+// interestedIn reports whether a detection notification is about one of the species, by
+// common or scientific name. An empty list includes every species.
+func interestedIn(species []string, notif *Notification) bool {
+	if len(species) == 0 {
+		return true
+	}
+	common, _ := notif.Metadata["species"].(string)
+	scientific, _ := notif.Metadata["scientific_name"].(string)
+	for _, name := range species {
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, common) || strings.EqualFold(name, scientific) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestRouterAllows(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Security.Users = []conf.UserAccount{
+		{Username: "alice", Role: conf.RoleAdmin},
+		{Username: "Bob", Role: conf.RoleViewer, Notifications: conf.UserNotificationSettings{
+			Channels:   []string{conf.NotificationChannelPush},
+			Species:    []string{"Strix aluco", "Eurasian Blackbird"},
+			QuietHours: conf.QuietHoursSettings{Enabled: true, Start: "22:00", End: "07:00"},
+		}},
+	}
+	router := NewRouter(settings)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.Local)
+	router.now = func() time.Time { return now }
+
+	owl := NewNotification(TypeDetection, PriorityHigh, "New Species Detected: Tawny Owl", "").
+		WithMetadata("species", "Tawny Owl").
+		WithMetadata("scientific_name", "Strix aluco")
+	blackbird := NewNotification(TypeDetection, PriorityHigh, "Rare Species Detected: Eurasian Blackbird", "").
+		WithMetadata("species", "Eurasian Blackbird").
+		WithMetadata("scientific_name", "Turdus merula")
+	robin := NewNotification(TypeDetection, PriorityHigh, "New Species Detected: European Robin", "").
+		WithMetadata("species", "European Robin").
+		WithMetadata("scientific_name", "Erithacus rubecula")
+	diskFull := NewNotification(TypeError, PriorityHigh, "Disk full", "")
+	failure := NewNotification(TypeError, PriorityCritical, "Audio capture failed", "")
+
+	tests := []struct {
+		name     string
+		username string
+		channel  string
+		notif    *Notification
+		hour     int
+		want     bool
+	}{
+		{"user without preferences", "alice", conf.NotificationChannelDashboard, robin, 12, true},
+		{"user without account", "oauth@example.org", conf.NotificationChannelPush, robin, 23, true},
+		{"species of interest", "bob", conf.NotificationChannelPush, owl, 12, true},
+		{"species of interest by common name", "bob", conf.NotificationChannelPush, blackbird, 12, true},
+		{"other species", "bob", conf.NotificationChannelPush, robin, 12, false},
+		{"channel not chosen", "bob", conf.NotificationChannelDashboard, owl, 12, false},
+		{"not a detection", "bob", conf.NotificationChannelPush, diskFull, 12, true},
+		{"quiet hours", "bob", conf.NotificationChannelPush, owl, 23, false},
+		{"quiet hours after midnight", "bob", conf.NotificationChannelPush, diskFull, 6, false},
+		{"critical during quiet hours", "bob", conf.NotificationChannelPush, failure, 23, true},
+	}
+
+	for _, tt := range tests {
+		now = time.Date(2026, 5, 1, tt.hour, 30, 0, 0, time.Local)
+		assert.Equal(t, tt.want, router.Allows(tt.username, tt.channel, tt.notif), tt.name)
+	}
+}
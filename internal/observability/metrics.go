@@ -10,7 +10,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/birdweather"
 	"github.com/tphakala/birdnet-go/internal/diskmanager"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
 	"github.com/tphakala/birdnet-go/internal/myaudio"
 	"github.com/tphakala/birdnet-go/internal/observability/metrics"
 )
@@ -28,6 +30,8 @@ type Metrics struct {
 	MyAudio       *metrics.MyAudioMetrics
 	SoundLevel    *metrics.SoundLevelMetrics
 	HTTP          *metrics.HTTPMetrics
+	OutboundHTTP  *metrics.OutboundHTTPMetrics
+	BirdWeather   *metrics.BirdWeatherMetrics
 }
 
 // NewMetrics creates a new instance of Metrics, initializing all metric collectors.
@@ -85,6 +89,16 @@ func NewMetrics() (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create HTTP metrics: %w", err)
 	}
 
+	outboundHTTPMetrics, err := metrics.NewOutboundHTTPMetrics(registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbound HTTP metrics: %w", err)
+	}
+
+	birdWeatherMetrics, err := metrics.NewBirdWeatherMetrics(registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BirdWeather metrics: %w", err)
+	}
+
 	m := &Metrics{
 		registry:      registry,
 		MQTT:          mqttMetrics,
@@ -97,6 +111,8 @@ func NewMetrics() (*Metrics, error) {
 		MyAudio:       myAudioMetrics,
 		SoundLevel:    soundLevelMetrics,
 		HTTP:          httpMetrics,
+		OutboundHTTP:  outboundHTTPMetrics,
+		BirdWeather:   birdWeatherMetrics,
 	}
 
 	// Initialize tracing with metrics
@@ -108,6 +124,10 @@ func NewMetrics() (*Metrics, error) {
 	// Initialize myaudio with metrics
 	initializeMyAudioMetrics(myAudioMetrics)
 
+	// Initialize clients of external services with metrics
+	httpclient.SetMetrics(outboundHTTPMetrics)
+	birdweather.SetMetrics(birdWeatherMetrics)
+
 	return m, nil
 }
 
@@ -25,11 +25,17 @@ type BirdNETMetrics struct {
 	PredictionErrors *prometheus.CounterVec
 	ModelLoadTotal   *prometheus.CounterVec
 	ModelLoadErrors  *prometheus.CounterVec
+	ShedActionsTotal *prometheus.CounterVec
 
 	// Current state gauges
 	ActiveProcessingGauge prometheus.Gauge
 	ModelLoadedGauge      prometheus.Gauge
 
+	// Inference worker pool
+	InferenceQueueLength prometheus.Gauge
+	InferenceWorkersBusy prometheus.Gauge
+	InferenceBatchSize   prometheus.Histogram
+
 	registry *prometheus.Registry
 }
 
@@ -134,6 +140,14 @@ func (m *BirdNETMetrics) initMetrics() error {
 		[]string{"model", "error_type"},
 	)
 
+	m.ShedActionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "birdnet_shed_actions_total",
+			Help: "Total number of optional detection actions skipped because the detection missed its processing deadline",
+		},
+		[]string{"action"},
+	)
+
 	// State gauges
 	m.ActiveProcessingGauge = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -149,6 +163,29 @@ func (m *BirdNETMetrics) initMetrics() error {
 		},
 	)
 
+	// Inference worker pool
+	m.InferenceQueueLength = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "birdnet_inference_queue_length",
+			Help: "Number of predictions waiting for a free model interpreter",
+		},
+	)
+
+	m.InferenceWorkersBusy = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "birdnet_inference_workers_busy",
+			Help: "Number of model interpreters currently running inference",
+		},
+	)
+
+	m.InferenceBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "birdnet_inference_batch_size",
+			Help:    "Number of analysis chunks analyzed per model invoke",
+			Buckets: prometheus.LinearBuckets(1, 1, 8), // 1 to 8 chunks
+		},
+	)
+
 	return nil
 }
 
@@ -158,6 +195,12 @@ func (m *BirdNETMetrics) IncrementDetectionCounter(speciesName string) {
 	m.DetectionCounter.WithLabelValues(speciesName).Inc()
 }
 
+// RecordShedAction records an optional action, e.g. "sse" or "mqtt", skipped because the
+// detection missed its processing deadline
+func (m *BirdNETMetrics) RecordShedAction(action string) {
+	m.ShedActionsTotal.WithLabelValues(action).Inc()
+}
+
 // SetProcessTime sets the most recent processing time for a BirdNET detection request.
 func (m *BirdNETMetrics) SetProcessTime(milliseconds float64) {
 	m.ProcessTimeGauge.Set(milliseconds)
@@ -206,6 +249,21 @@ func (m *BirdNETMetrics) SetActiveProcessing(count float64) {
 	m.ActiveProcessingGauge.Set(count)
 }
 
+// SetInferenceQueueLength sets the number of predictions waiting for a free interpreter
+func (m *BirdNETMetrics) SetInferenceQueueLength(length int) {
+	m.InferenceQueueLength.Set(float64(length))
+}
+
+// SetInferenceWorkersBusy sets the number of interpreters running inference
+func (m *BirdNETMetrics) SetInferenceWorkersBusy(count int) {
+	m.InferenceWorkersBusy.Set(float64(count))
+}
+
+// RecordInferenceBatch records the number of chunks analyzed by one model invoke
+func (m *BirdNETMetrics) RecordInferenceBatch(size int) {
+	m.InferenceBatchSize.Observe(float64(size))
+}
+
 // categorizeError returns a category string for the error type using enhanced error categories
 func categorizeError(err error) string {
 	if err == nil {
@@ -263,10 +321,16 @@ func (m *BirdNETMetrics) Describe(ch chan<- *prometheus.Desc) {
 	m.PredictionErrors.Describe(ch)
 	m.ModelLoadTotal.Describe(ch)
 	m.ModelLoadErrors.Describe(ch)
+	m.ShedActionsTotal.Describe(ch)
 
 	// State gauges
 	ch <- m.ActiveProcessingGauge.Desc()
 	ch <- m.ModelLoadedGauge.Desc()
+
+	// Inference worker pool
+	ch <- m.InferenceQueueLength.Desc()
+	ch <- m.InferenceWorkersBusy.Desc()
+	ch <- m.InferenceBatchSize.Desc()
 }
 
 // Collect implements the prometheus.Collector interface.
@@ -285,10 +349,16 @@ func (m *BirdNETMetrics) Collect(ch chan<- prometheus.Metric) {
 	m.PredictionErrors.Collect(ch)
 	m.ModelLoadTotal.Collect(ch)
 	m.ModelLoadErrors.Collect(ch)
+	m.ShedActionsTotal.Collect(ch)
 
 	// State gauges
 	ch <- m.ActiveProcessingGauge
 	ch <- m.ModelLoadedGauge
+
+	// Inference worker pool
+	ch <- m.InferenceQueueLength
+	ch <- m.InferenceWorkersBusy
+	ch <- m.InferenceBatchSize
 }
 
 // RecordOperation implements the Recorder interface.
@@ -0,0 +1,85 @@
+// Package metrics provides custom Prometheus metrics for various components of the BirdNET-Go application.
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BirdWeatherMetrics contains Prometheus metrics for soundscapes uploaded to BirdWeather. The
+// loudness error and encode duration are labeled by normalization mode so the accuracy and cost
+// of the modes can be compared.
+type BirdWeatherMetrics struct {
+	registry *prometheus.Registry
+
+	loudnessError  *prometheus.HistogramVec
+	encodeDuration *prometheus.HistogramVec
+}
+
+// NewBirdWeatherMetrics creates and registers new BirdWeather upload metrics
+func NewBirdWeatherMetrics(registry *prometheus.Registry) (*BirdWeatherMetrics, error) {
+	m := &BirdWeatherMetrics{registry: registry}
+	m.initMetrics()
+	if err := registry.Register(m); err != nil {
+		return nil, fmt.Errorf("failed to register BirdWeather metrics: %w", err)
+	}
+	return m, nil
+}
+
+// initMetrics initializes all Prometheus metrics
+func (m *BirdWeatherMetrics) initMetrics() {
+	m.loudnessError = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "birdweather_upload_loudness_error_lufs",
+			Help:    "Absolute difference between the loudness of uploaded soundscapes and the target loudness",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 8, 12, 20},
+		},
+		[]string{"mode"}, // mode: twopass, singlepass, cached
+	)
+
+	m.encodeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "birdweather_upload_encode_duration_seconds",
+			Help:    "Time taken to normalize and encode soundscapes for upload",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10), // 50ms to ~25s
+		},
+		[]string{"mode"},
+	)
+}
+
+// getCollectors returns all collectors in order for Describe/Collect operations
+func (m *BirdWeatherMetrics) getCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.loudnessError,
+		m.encodeDuration,
+	}
+}
+
+// Describe implements the Collector interface
+func (m *BirdWeatherMetrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range m.getCollectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements the Collector interface
+func (m *BirdWeatherMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range m.getCollectors() {
+		collector.Collect(ch)
+	}
+}
+
+// RecordLoudnessError records how far the loudness of a soundscape normalized in mode is from
+// the target, in LU
+func (m *BirdWeatherMetrics) RecordLoudnessError(mode string, errorLU float64) {
+	if errorLU < 0 {
+		errorLU = -errorLU
+	}
+	m.loudnessError.WithLabelValues(mode).Observe(errorLU)
+}
+
+// RecordEncodeDuration records the time taken to normalize and encode a soundscape in mode
+func (m *BirdWeatherMetrics) RecordEncodeDuration(mode string, duration float64) {
+	m.encodeDuration.WithLabelValues(mode).Observe(duration)
+}
@@ -50,6 +50,7 @@ type MyAudioMetrics struct {
 	captureBufferSegmentReadsTotal    *prometheus.CounterVec
 	captureBufferSegmentReadDuration  *prometheus.HistogramVec
 	captureBufferTimestampErrorsTotal *prometheus.CounterVec
+	captureClockDrift                 *prometheus.GaugeVec
 
 	// Audio quality metrics
 	audioDataValidationErrors *prometheus.CounterVec
@@ -76,6 +77,16 @@ type MyAudioMetrics struct {
 	birdnetResultsTotal     *prometheus.CounterVec
 	audioQueueOperations    *prometheus.CounterVec
 
+	// Inference scheduling metrics
+	inferenceRequestsTotal *prometheus.CounterVec
+	inferenceQueueLength   *prometheus.GaugeVec
+	inferenceQueueWait     *prometheus.HistogramVec
+	inferenceShare         *prometheus.GaugeVec
+
+	// Stream delivery metrics
+	streamLatency *prometheus.GaugeVec
+	streamJitter  *prometheus.GaugeVec
+
 	// collectors is a slice of all collectors for easier iteration
 	collectors []prometheus.Collector
 }
@@ -315,6 +326,14 @@ func (m *MyAudioMetrics) initMetrics() error {
 		[]string{"source", "error_type"}, // error_type: outside_timeframe, invalid_duration
 	)
 
+	m.captureClockDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_capture_clock_drift_ppm",
+			Help: "Measured drift of the source clock against the wall clock in parts per million",
+		},
+		[]string{"source"},
+	)
+
 	// Audio quality metrics
 	m.audioDataValidationErrors = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -474,6 +493,55 @@ func (m *MyAudioMetrics) initMetrics() error {
 		[]string{"source", "operation", "status"}, // operation: enqueue, dequeue
 	)
 
+	m.inferenceRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "myaudio_inference_requests_total",
+			Help: "Total number of analysis chunks handled by the inference scheduler",
+		},
+		[]string{"source", "result"}, // result: processed, dropped, silent
+	)
+
+	m.inferenceQueueLength = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_inference_queue_length",
+			Help: "Number of analysis chunks waiting for inference",
+		},
+		[]string{"source"},
+	)
+
+	m.inferenceQueueWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "myaudio_inference_queue_wait_seconds",
+			Help:    "Time analysis chunks wait in the queue before inference",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 15), // 1ms to ~32s
+		},
+		[]string{"source"},
+	)
+
+	m.inferenceShare = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_inference_share_ratio",
+			Help: "Share of inference time used by the source since startup (0-1)",
+		},
+		[]string{"source"},
+	)
+
+	m.streamLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_stream_latency_seconds",
+			Help: "Delivery delay of a stream above its fastest delivery of the last minutes",
+		},
+		[]string{"source"},
+	)
+
+	m.streamJitter = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_stream_jitter_seconds",
+			Help: "Smoothed variation of the delivery delay of a stream",
+		},
+		[]string{"source"},
+	)
+
 	// Initialize collectors slice with all metrics
 	m.collectors = []prometheus.Collector{
 		m.bufferAllocationsTotal,
@@ -502,6 +570,7 @@ func (m *MyAudioMetrics) initMetrics() error {
 		m.captureBufferSegmentReadsTotal,
 		m.captureBufferSegmentReadDuration,
 		m.captureBufferTimestampErrorsTotal,
+		m.captureClockDrift,
 		m.audioDataValidationErrors,
 		m.audioSilenceDetections,
 		m.audioDataCorruptionTotal,
@@ -521,6 +590,12 @@ func (m *MyAudioMetrics) initMetrics() error {
 		m.audioSampleCountTotal,
 		m.birdnetResultsTotal,
 		m.audioQueueOperations,
+		m.inferenceRequestsTotal,
+		m.inferenceQueueLength,
+		m.inferenceQueueWait,
+		m.inferenceShare,
+		m.streamLatency,
+		m.streamJitter,
 	}
 
 	return nil
@@ -692,6 +767,11 @@ func (m *MyAudioMetrics) RecordCaptureBufferTimestampError(source, errorType str
 	m.captureBufferTimestampErrorsTotal.WithLabelValues(source, errorType).Inc()
 }
 
+// UpdateCaptureClockDrift updates the measured clock drift of a capture source
+func (m *MyAudioMetrics) UpdateCaptureClockDrift(source string, ppm float64) {
+	m.captureClockDrift.WithLabelValues(source).Set(ppm)
+}
+
 // Audio quality recording methods
 
 // RecordAudioDataValidationError records an audio data validation error
@@ -795,3 +875,29 @@ func (m *MyAudioMetrics) RecordBirdNETResults(source string, resultCount int) {
 func (m *MyAudioMetrics) RecordAudioQueueOperation(source, operation, status string) {
 	m.audioQueueOperations.WithLabelValues(source, operation, status).Inc()
 }
+
+// RecordInferenceRequest records an analysis chunk processed or dropped by the inference scheduler
+func (m *MyAudioMetrics) RecordInferenceRequest(source, result string) {
+	m.inferenceRequestsTotal.WithLabelValues(source, result).Inc()
+}
+
+// UpdateInferenceQueueLength updates the number of chunks of a source waiting for inference
+func (m *MyAudioMetrics) UpdateInferenceQueueLength(source string, length int) {
+	m.inferenceQueueLength.WithLabelValues(source).Set(float64(length))
+}
+
+// RecordInferenceQueueWait records how long a chunk waited in the queue before inference
+func (m *MyAudioMetrics) RecordInferenceQueueWait(source string, seconds float64) {
+	m.inferenceQueueWait.WithLabelValues(source).Observe(seconds)
+}
+
+// UpdateInferenceShare updates the share of inference time used by a source
+func (m *MyAudioMetrics) UpdateInferenceShare(source string, share float64) {
+	m.inferenceShare.WithLabelValues(source).Set(share)
+}
+
+// UpdateStreamTiming updates the delivery latency and jitter of a stream
+func (m *MyAudioMetrics) UpdateStreamTiming(source string, latency, jitter float64) {
+	m.streamLatency.WithLabelValues(source).Set(latency)
+	m.streamJitter.WithLabelValues(source).Set(jitter)
+}
@@ -0,0 +1,76 @@
+// Package metrics provides custom Prometheus metrics for various components of the BirdNET-Go application.
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OutboundHTTPMetrics contains Prometheus metrics for requests to external services such as
+// BirdWeather, image providers and weather providers
+type OutboundHTTPMetrics struct {
+	registry *prometheus.Registry
+
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+// NewOutboundHTTPMetrics creates and registers new outbound HTTP request metrics
+func NewOutboundHTTPMetrics(registry *prometheus.Registry) (*OutboundHTTPMetrics, error) {
+	m := &OutboundHTTPMetrics{registry: registry}
+	m.initMetrics()
+	if err := registry.Register(m); err != nil {
+		return nil, fmt.Errorf("failed to register outbound HTTP metrics: %w", err)
+	}
+	return m, nil
+}
+
+// initMetrics initializes all Prometheus metrics
+func (m *OutboundHTTPMetrics) initMetrics() {
+	m.requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbound_http_requests_total",
+			Help: "Total number of HTTP requests to external services",
+		},
+		[]string{"integration", "method", "status"}, // integration: birdweather, weather; status: HTTP status code or error
+	)
+
+	m.requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbound_http_request_duration_seconds",
+			Help:    "Time until the response headers of HTTP requests to external services are received",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms to ~20s
+		},
+		[]string{"integration"},
+	)
+}
+
+// getCollectors returns all collectors in order for Describe/Collect operations
+func (m *OutboundHTTPMetrics) getCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requestsTotal,
+		m.requestDuration,
+	}
+}
+
+// Describe implements the Collector interface
+func (m *OutboundHTTPMetrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range m.getCollectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements the Collector interface
+func (m *OutboundHTTPMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range m.getCollectors() {
+		collector.Collect(ch)
+	}
+}
+
+// RecordRequest records a request to an external service. status is the HTTP status code, or
+// "error" when no response was received.
+func (m *OutboundHTTPMetrics) RecordRequest(integration, method, status string, duration float64) {
+	m.requestsTotal.WithLabelValues(integration, method, status).Inc()
+	m.requestDuration.WithLabelValues(integration).Observe(duration)
+}
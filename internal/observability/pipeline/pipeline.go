@@ -0,0 +1,171 @@
+// Package pipeline records the latency of each stage of the analysis pipeline, from captured
+// audio to executed detection actions, so slow stages behind missed detections can be found
+// on a running node without a rebuild or a metrics server.
+package pipeline
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Stages of the analysis pipeline in processing order
+const (
+	StageSegment        = "capture_to_segment" // audio written to the analysis buffer until its chunk is queued
+	StageInferenceQueue = "inference_queue"    // chunk queued until inference starts
+	StageInference      = "inference"          // BirdNET prediction of a chunk
+	StagePostProcessing = "post_processing"    // filtering and holding of the results of a chunk
+	StageActions        = "action_execution"   // execution of one detection action, such as a database save
+)
+
+// Stages lists the stages in processing order
+var Stages = []string{StageSegment, StageInferenceQueue, StageInference, StagePostProcessing, StageActions}
+
+// sampleWindow is the number of recent latencies of a stage kept for the percentiles
+const sampleWindow = 512
+
+// StageStats summarizes the latencies of a stage. The percentiles cover the most recent
+// samples, the count and maximum all samples since the tracker was reset.
+type StageStats struct {
+	Stage  string  `json:"stage"`
+	Count  int64   `json:"count"`
+	MeanMs float64 `json:"meanMs"`
+	P50Ms  float64 `json:"p50Ms"`
+	P95Ms  float64 `json:"p95Ms"`
+	P99Ms  float64 `json:"p99Ms"`
+	MaxMs  float64 `json:"maxMs"`
+	LastMs float64 `json:"lastMs"`
+}
+
+// Report is a snapshot of the latencies of all stages
+type Report struct {
+	Since  time.Time    `json:"since"` // when recording started or was last reset
+	Stages []StageStats `json:"stages"`
+}
+
+// stageSamples holds the latencies of a stage
+type stageSamples struct {
+	recent []time.Duration // ring of the most recent latencies
+	next   int             // index in recent of the next latency
+	count  int64
+	max    time.Duration
+	last   time.Duration
+}
+
+// Tracker records stage latencies. It is safe for concurrent use.
+type Tracker struct {
+	mu     sync.Mutex
+	since  time.Time
+	stages map[string]*stageSamples
+}
+
+// NewTracker returns an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{since: time.Now(), stages: make(map[string]*stageSamples)}
+}
+
+// defaultTracker records the latencies of the running analysis pipeline
+var defaultTracker = NewTracker()
+
+// Record records a latency of stage in the pipeline tracker
+func Record(stage string, latency time.Duration) {
+	defaultTracker.Record(stage, latency)
+}
+
+// Snapshot returns the latencies of the pipeline tracker
+func Snapshot() Report {
+	return defaultTracker.Snapshot()
+}
+
+// Reset clears the latencies of the pipeline tracker
+func Reset() {
+	defaultTracker.Reset()
+}
+
+// Record records a latency of stage
+func (t *Tracker) Record(stage string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples, ok := t.stages[stage]
+	if !ok {
+		samples = &stageSamples{recent: make([]time.Duration, 0, sampleWindow)}
+		t.stages[stage] = samples
+	}
+	if len(samples.recent) < sampleWindow {
+		samples.recent = append(samples.recent, latency)
+	} else {
+		samples.recent[samples.next] = latency
+	}
+	samples.next = (samples.next + 1) % sampleWindow
+	samples.count++
+	samples.max = max(samples.max, latency)
+	samples.last = latency
+}
+
+// Snapshot returns the latencies of all stages in processing order. Stages without samples
+// are included with zero values, followed by stages not in Stages.
+func (t *Tracker) Snapshot() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{Since: t.since, Stages: make([]StageStats, 0, len(Stages))}
+	for _, stage := range Stages {
+		report.Stages = append(report.Stages, t.stats(stage))
+	}
+	var others []string
+	for stage := range t.stages {
+		if !slices.Contains(Stages, stage) {
+			others = append(others, stage)
+		}
+	}
+	slices.Sort(others)
+	for _, stage := range others {
+		report.Stages = append(report.Stages, t.stats(stage))
+	}
+	return report
+}
+
+// Reset clears the latencies of all stages
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.since = time.Now()
+	clear(t.stages)
+}
+
+// stats summarizes the latencies of a stage. The caller must hold mu.
+func (t *Tracker) stats(stage string) StageStats {
+	stats := StageStats{Stage: stage}
+	samples, ok := t.stages[stage]
+	if !ok || len(samples.recent) == 0 {
+		return stats
+	}
+
+	sorted := slices.Clone(samples.recent)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+
+	stats.Count = samples.count
+	stats.MeanMs = milliseconds(total / time.Duration(len(sorted)))
+	stats.P50Ms = milliseconds(percentile(sorted, 0.50))
+	stats.P95Ms = milliseconds(percentile(sorted, 0.95))
+	stats.P99Ms = milliseconds(percentile(sorted, 0.99))
+	stats.MaxMs = milliseconds(samples.max)
+	stats.LastMs = milliseconds(samples.last)
+	return stats
+}
+
+// percentile returns the latency below which fraction p of the sorted latencies fall
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(p * float64(len(sorted)-1))
+	return sorted[index]
+}
+
+// milliseconds returns a duration in milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
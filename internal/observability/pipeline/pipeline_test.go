@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker()
+	for i := 1; i <= 100; i++ {
+		tracker.Record(StageInference, time.Duration(i)*time.Millisecond)
+	}
+	tracker.Record("custom", time.Second)
+
+	report := tracker.Snapshot()
+	require.Len(t, report.Stages, len(Stages)+1)
+	assert.Equal(t, StageSegment, report.Stages[0].Stage, "stages in processing order")
+	assert.Zero(t, report.Stages[0].Count, "stages without samples are reported")
+	assert.Equal(t, "custom", report.Stages[len(Stages)].Stage, "other stages follow")
+
+	inference := report.Stages[2]
+	assert.Equal(t, StageInference, inference.Stage)
+	assert.Equal(t, int64(100), inference.Count)
+	assert.InDelta(t, 50.5, inference.MeanMs, 0.01)
+	assert.InDelta(t, 50, inference.P50Ms, 0.01)
+	assert.InDelta(t, 95, inference.P95Ms, 0.01)
+	assert.InDelta(t, 99, inference.P99Ms, 0.01)
+	assert.InDelta(t, 100, inference.MaxMs, 0.01)
+	assert.InDelta(t, 100, inference.LastMs, 0.01)
+
+	// Percentiles cover the most recent samples, the maximum all samples
+	for range sampleWindow {
+		tracker.Record(StageInference, time.Millisecond)
+	}
+	inference = tracker.Snapshot().Stages[2]
+	assert.Equal(t, int64(100+sampleWindow), inference.Count)
+	assert.InDelta(t, 1, inference.P99Ms, 0.01)
+	assert.InDelta(t, 100, inference.MaxMs, 0.01)
+
+	tracker.Reset()
+	report = tracker.Snapshot()
+	assert.Len(t, report.Stages, len(Stages))
+	assert.Zero(t, report.Stages[2].Count)
+}
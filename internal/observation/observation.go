@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -49,6 +50,45 @@ func ParseSpeciesString(species string) (scientificName, commonName, speciesCode
 	return species, species, ""
 }
 
+// locations caches the time zones of detection timestamps by name
+var locations sync.Map
+
+// Timestamp returns the date and time of day stored with a detection starting at beginTime.
+// With the default segment timestamps they are the start of the analyzed audio in the
+// configured time zone, so delayed processing and analysis of recordings date detections
+// when the sound was recorded. Clock timestamps and begin times that are only offsets into
+// a file, as in file analysis, use the wall clock time of the detection.
+func Timestamp(settings *conf.Settings, beginTime time.Time) (date, clock string) {
+	t := LocalTime(settings, beginTime)
+	if settings.Main.Timestamps == conf.TimestampsClock || beginTime.Year() <= 1 {
+		// time now minus 2 seconds to account for the delay in the detection
+		t = LocalTime(settings, time.Now().Add(-2*time.Second))
+	}
+	return t.Format(time.DateOnly), t.Format(time.TimeOnly)
+}
+
+// LocalTime returns t in the configured time zone of detection timestamps
+func LocalTime(settings *conf.Settings, t time.Time) time.Time {
+	return t.In(location(settings.Main.TimeZone))
+}
+
+// location returns the named time zone, the system time zone when the name is empty or
+// unknown; unknown names are reported by configuration validation
+func location(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
 // NoteParams holds the parameters for creating a new Note.
 type NoteParams struct {
 	Begin       time.Time
@@ -61,25 +101,21 @@ type NoteParams struct {
 	Occurrence  float64
 }
 
-// NewWith creates and returns a new Note with the provided NoteParams and current date and time.
+// NewWith creates and returns a new Note with the provided NoteParams, dated by Timestamp.
 // It uses the configuration and parsing functions to set the appropriate fields.
 // For custom models, species may have placeholder taxonomy codes if not in the eBird taxonomy.
 func NewWith(settings *conf.Settings, p *NoteParams) datastore.Note {
 	return New(settings, p.Begin, p.End, p.Species, p.Confidence, p.Source, p.ClipName, p.Elapsed, p.Occurrence)
 }
 
-// New creates and returns a new Note with the provided parameters and current date and time.
+// New creates and returns a new Note with the provided parameters, dated by Timestamp.
 // It uses the configuration and parsing functions to set the appropriate fields.
 // For custom models, species may have placeholder taxonomy codes if not in the eBird taxonomy.
 func New(settings *conf.Settings, beginTime, endTime time.Time, species string, confidence float64, source, clipName string, elapsedTime time.Duration, occurrence float64) datastore.Note {
 	// Parse the species string to get the scientific name, common name, and species code.
 	scientificName, commonName, speciesCode := ParseSpeciesString(species)
 
-	// detectionTime is time now minus 3 seconds to account for the delay in the detection
-	now := time.Now()
-	date := now.Format("2006-01-02")
-	detectionTime := now.Add(-2 * time.Second)
-	timeStr := detectionTime.Format("15:04:05")
+	date, timeStr := Timestamp(settings, beginTime)
 
 	// Create AudioSource struct with proper fields
 	var audioSourceStruct datastore.AudioSource
@@ -101,7 +137,7 @@ func New(settings *conf.Settings, beginTime, endTime time.Time, species string,
 	// Round confidence to two decimal places
 	roundedConfidence := math.Round(confidence*100) / 100
 
-	// Return a new Note struct populated with the provided parameters as well as the current date and time.
+	// Return a new Note struct populated with the provided parameters and the detection date and time.
 	return datastore.Note{
 		SourceNode:     settings.Main.Name,           // From the provided configuration settings.
 		Date:           date,                         // Use ISO 8601 date format.
@@ -0,0 +1,135 @@
+package privacy
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Scrubber names of a Policy
+const (
+	ScrubberURLs        = "urls"        // URLs, anonymized to a hash of their structure
+	ScrubberEmails      = "emails"      // email addresses
+	ScrubberUUIDs       = "uuids"       // UUIDs
+	ScrubberIPs         = "ips"         // IP addresses outside URLs, anonymized to a hash
+	ScrubberCoordinates = "coordinates" // GPS coordinate pairs
+	ScrubberTokens      = "tokens"      // API tokens, keys, secrets and bearer tokens
+)
+
+// Scrubbers lists the scrubber names in the order a policy runs them
+var Scrubbers = []string{ScrubberURLs, ScrubberEmails, ScrubberUUIDs, ScrubberIPs, ScrubberCoordinates, ScrubberTokens}
+
+// IsScrubber reports whether name is a known scrubber name
+func IsScrubber(name string) bool {
+	return slices.Contains(Scrubbers, name)
+}
+
+// Policy is an anonymization policy deciding which scrubbers run over a message and what
+// the scrubbed data is replaced with. The zero Policy runs every scrubber with the default
+// replacements, the same as ScrubMessage without a configured policy.
+type Policy struct {
+	// Scrubbers are the names of the scrubbers to run, every scrubber when empty
+	Scrubbers []string
+
+	// Replacements maps scrubber names to the token replacing scrubbed data. URLs and IP
+	// addresses with a replacement are replaced by it instead of being anonymized to a hash,
+	// a coordinates replacement replaces the whole coordinate pair.
+	Replacements map[string]string
+
+	// AllowedHosts are host names and IP addresses kept as-is, in URLs and as standalone
+	// IP addresses. Subdomains of an allowed host name are allowed too.
+	AllowedHosts []string
+}
+
+// currentPolicy is the policy used by ScrubMessage, nil for the default policy
+var currentPolicy atomic.Pointer[Policy]
+
+// SetPolicy replaces the policy used by ScrubMessage, and thus by telemetry, support
+// bundles and notifications. A nil policy restores the default policy.
+func SetPolicy(policy *Policy) {
+	currentPolicy.Store(policy)
+}
+
+// CurrentPolicy returns the policy used by ScrubMessage
+func CurrentPolicy() *Policy {
+	if policy := currentPolicy.Load(); policy != nil {
+		return policy
+	}
+	return &Policy{}
+}
+
+// Scrub removes or anonymizes the sensitive information selected by the policy from a message
+func (p *Policy) Scrub(message string) string {
+	result := message
+	if p.runs(ScrubberURLs) {
+		result = urlPattern.ReplaceAllStringFunc(result, p.scrubURL)
+	}
+	if p.runs(ScrubberEmails) {
+		result = emailPattern.ReplaceAllLiteralString(result, p.replacement(ScrubberEmails, "[EMAIL]"))
+	}
+	if p.runs(ScrubberUUIDs) {
+		result = uuidPattern.ReplaceAllLiteralString(result, p.replacement(ScrubberUUIDs, "[UUID]"))
+	}
+	if p.runs(ScrubberIPs) {
+		result = scrubStandaloneIPs(result, p.scrubIP)
+	}
+	if p.runs(ScrubberCoordinates) {
+		result = coordinatesPattern.ReplaceAllLiteralString(result, p.replacement(ScrubberCoordinates, "[LAT],[LON]"))
+	}
+	if p.runs(ScrubberTokens) {
+		result = scrubAPITokens(result, p.replacement(ScrubberTokens, "[TOKEN]"))
+	}
+	return result
+}
+
+// runs reports whether the policy runs a scrubber
+func (p *Policy) runs(scrubber string) bool {
+	return len(p.Scrubbers) == 0 || slices.Contains(p.Scrubbers, scrubber)
+}
+
+// replacement returns the token replacing data removed by a scrubber
+func (p *Policy) replacement(scrubber, defaultToken string) string {
+	if token, ok := p.Replacements[scrubber]; ok {
+		return token
+	}
+	return defaultToken
+}
+
+// scrubURL anonymizes a URL unless its host is allowed
+func (p *Policy) scrubURL(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil && p.allowsHost(parsed.Hostname()) {
+		return rawURL
+	}
+	if token, ok := p.Replacements[ScrubberURLs]; ok {
+		return token
+	}
+	return AnonymizeURL(rawURL)
+}
+
+// scrubIP anonymizes an IP address unless it is allowed
+func (p *Policy) scrubIP(ip string) string {
+	if p.allowsHost(ip) {
+		return ip
+	}
+	if token, ok := p.Replacements[ScrubberIPs]; ok {
+		return token
+	}
+	return AnonymizeIP(ip)
+}
+
+// allowsHost reports whether a host name or IP address is allowed by the policy
+func (p *Policy) allowsHost(host string) bool {
+	if host == "" {
+		return false
+	}
+	host = strings.ToLower(host)
+	isIP := isIPAddress(host)
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || !isIP && strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -42,12 +42,15 @@ var (
 	// Separator normalization pattern for API tokens
 	separatorRegex = regexp.MustCompile(`[:=]\s*`)
 	
-	// RTSP URL pattern for finding and sanitizing RTSP URLs with credentials
+	// Stream URL pattern for finding and sanitizing RTSP, SRT, RTMP and HTTP URLs with credentials
 	// Supports various formats including IPv6 addresses in brackets
-	rtspURLPattern = regexp.MustCompile(`rtsp://(?:[^:]+:[^@]+@)?(?:\[[0-9a-fA-F:]+\]|[^/:\s]+)(?::[0-9]+)?(?:/[^\s]*)?`)
+	rtspURLPattern = regexp.MustCompile(`(?:rtsps?|srt|rtmps?|https?)://(?:[^:]+:[^@]+@)?(?:\[[0-9a-fA-F:]+\]|[^/:\s]+)(?::[0-9]+)?(?:[/?][^\s]*)?`)
 	
 	// FFmpeg error prefix pattern - matches memory addresses like [rtsp @ 0x55d4a4808980]
 	ffmpegPrefixPattern = regexp.MustCompile(`\[\w+\s*@\s*0x[0-9a-fA-F]+\]\s*`)
+
+	// Number pattern for FFmpeg error normalization - matches hex values, decimals and integers
+	ffmpegNumberPattern = regexp.MustCompile(`0x[0-9a-fA-F]+|\d+(?:\.\d+)?`)
 )
 
 // Common two-part TLDs that need special handling
@@ -59,16 +62,10 @@ var commonTwoPartTLDs = map[string]bool{
 }
 
 // ScrubMessage removes or anonymizes sensitive information from telemetry messages
-// It finds URLs and other sensitive data in the message and replaces them with anonymized versions
+// It finds URLs and other sensitive data in the message and replaces them as configured
+// by the current policy, see SetPolicy
 func ScrubMessage(message string) string {
-	// Apply all scrubbing functions in sequence
-	result := urlPattern.ReplaceAllStringFunc(message, AnonymizeURL)
-	result = ScrubEmails(result)
-	result = ScrubUUIDs(result)
-	result = ScrubStandaloneIPs(result)
-	result = ScrubCoordinates(result)
-	result = ScrubAPITokens(result)
-	return result
+	return CurrentPolicy().Scrub(message)
 }
 
 // AnonymizeURL converts a URL to an anonymized form while preserving debugging value
@@ -116,8 +113,9 @@ func AnonymizeURL(rawURL string) string {
 	return fmt.Sprintf("url-%x", hash[:12])
 }
 
-// SanitizeRTSPUrl removes sensitive information from RTSP URL and returns a display-friendly version
-// It strips credentials while preserving the host, port, and path for debugging
+// SanitizeRTSPUrl removes sensitive information from RTSP, SRT, RTMP and HTTP stream URLs and
+// returns a display-friendly version. It strips credentials and SRT passphrases while preserving the
+// host, port, and path for debugging
 func SanitizeRTSPUrl(source string) string {
 	// Parse the URL using standard library
 	parsedURL, err := url.Parse(source)
@@ -126,13 +124,21 @@ func SanitizeRTSPUrl(source string) string {
 		return source
 	}
 
-	// Only process RTSP URLs
-	if parsedURL.Scheme != "rtsp" {
+	// Only process stream URLs
+	switch parsedURL.Scheme {
+	case "rtsp", "rtsps", "srt", "rtmp", "rtmps", "http", "https":
+	default:
 		return source
 	}
 
 	// Remove user credentials only
 	parsedURL.User = nil
+
+	// SRT carries its encryption passphrase as a query parameter
+	if query := parsedURL.Query(); query.Has("passphrase") {
+		query.Del("passphrase")
+		parsedURL.RawQuery = query.Encode()
+	}
 	
 	// Keep path, query, and fragment for debugging purposes
 	
@@ -155,6 +161,26 @@ func SanitizeFFmpegError(text string) string {
 	return SanitizeRTSPUrls(text)
 }
 
+// NormalizeFFmpegError reduces an FFmpeg error message to the parts that stay the same when
+// the error recurs. On top of SanitizeFFmpegError it replaces URLs, IP addresses and numbers
+// such as timestamps, ports and byte counts with placeholders and collapses whitespace,
+// so the same failure on different streams or at different times normalizes equally.
+func NormalizeFFmpegError(text string) string {
+	text = SanitizeFFmpegError(text)
+	text = urlPattern.ReplaceAllString(text, "<url>")
+	text = ipv4Pattern.ReplaceAllString(text, "<ip>")
+	text = ffmpegNumberPattern.ReplaceAllString(text, "<n>")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// FFmpegErrorFingerprint returns a short stable identifier for an FFmpeg error message,
+// the first 16 hex characters of the SHA-256 hash of the case-folded normalized message.
+// Recurring errors get the same fingerprint and can be counted together.
+func FFmpegErrorFingerprint(text string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(NormalizeFFmpegError(text))))
+	return hex.EncodeToString(hash[:8])
+}
+
 // GenerateSystemID creates a unique system identifier
 // The ID is 12 characters long, URL-safe, and case-insensitive
 // Format: XXXX-XXXX-XXXX (14 chars total with hyphens)
@@ -215,6 +241,11 @@ func ScrubUUIDs(message string) string {
 // ScrubStandaloneIPs removes or anonymizes standalone IP addresses from text messages
 // It handles both IPv4 and IPv6 addresses that are not part of URLs
 func ScrubStandaloneIPs(message string) string {
+	return scrubStandaloneIPs(message, AnonymizeIP)
+}
+
+// scrubStandaloneIPs replaces the IP addresses that are not part of URLs using replace
+func scrubStandaloneIPs(message string, replace func(string) string) string {
 	// First mark all URLs to avoid processing IPs within them
 	urlPositions := make(map[int]int) // start -> end position of URLs
 	urlMatches := urlPattern.FindAllStringIndex(message, -1)
@@ -241,7 +272,7 @@ func ScrubStandaloneIPs(message string) string {
 			continue
 		}
 		ip := message[match[0]:match[1]]
-		anonymized := AnonymizeIP(ip)
+		anonymized := replace(ip)
 		adjustedStart := match[0] + offset
 		adjustedEnd := match[1] + offset
 		result = result[:adjustedStart] + anonymized + result[adjustedEnd:]
@@ -257,7 +288,7 @@ func ScrubStandaloneIPs(message string) string {
 			continue
 		}
 		ip := message[match[0]:match[1]]
-		anonymized := AnonymizeIP(ip)
+		anonymized := replace(ip)
 		adjustedStart := match[0] + offset
 		adjustedEnd := match[1] + offset
 		result = result[:adjustedStart] + anonymized + result[adjustedEnd:]
@@ -276,22 +307,27 @@ func ScrubCoordinates(message string) string {
 // ScrubAPITokens removes or anonymizes API tokens, keys, and secrets from text messages
 // It replaces tokens with generic placeholders while preserving message structure
 func ScrubAPITokens(message string) string {
+	return scrubAPITokens(message, "[TOKEN]")
+}
+
+// scrubAPITokens replaces API tokens, keys, and secrets with the given token
+func scrubAPITokens(message, token string) string {
 	return apiTokenPattern.ReplaceAllStringFunc(message, func(match string) string {
 		// Check if it's a bearer token
 		lowerMatch := strings.ToLower(match)
 		if strings.Contains(lowerMatch, "bearer") {
-			return "Bearer [TOKEN]"
+			return "Bearer " + token
 		}
 		// Check if it's "with token/key/etc" pattern
 		if strings.HasPrefix(lowerMatch, "with ") {
 			// Extract the keyword (token, key, etc)
 			parts := strings.Fields(match)
 			if len(parts) >= 2 {
-				return parts[0] + " " + parts[1] + " [TOKEN]"
+				return parts[0] + " " + parts[1] + " " + token
 			}
 		}
 		// Use pre-compiled regex to find and replace just the token part within the match
-		result := tokenRegex.ReplaceAllString(match, "[TOKEN]")
+		result := tokenRegex.ReplaceAllLiteralString(match, token)
 		// Normalize separators to colon for consistency using pre-compiled regex
 		result = separatorRegex.ReplaceAllString(result, ": ")
 		return result
@@ -0,0 +1,191 @@
+// Package resources detects the CPU and memory available to BirdNET-Go and sizes worker
+// pools, buffers and external process concurrency to fit.
+//
+// On bare metal the host core count is used as before. Inside a container the cgroup CPU
+// quota and memory limit are honored, as runtime.NumCPU reports the host cores even when
+// the container is limited to a fraction of them.
+package resources
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/logging"
+)
+
+// cgroupRoot is the mount point of the cgroup filesystem
+const cgroupRoot = "/sys/fs/cgroup"
+
+// unlimitedMemory is the threshold above which a cgroup v1 memory limit means no limit,
+// the kernel reports a page aligned maximum int64 when unset.
+const unlimitedMemory = int64(1) << 60
+
+// Default sizes used when no limits apply, matching the previous fixed values
+const (
+	defaultEventBusWorkers    = 4
+	defaultEventBusBufferSize = 10000
+	defaultFFmpegConcurrency  = 4
+)
+
+// Limits describes the resources available to the process
+type Limits struct {
+	InContainer bool    // true when running inside a container
+	CgroupV2    bool    // true when limits were read from the unified cgroup v2 hierarchy
+	HostCPUs    int     // logical CPUs reported by the runtime
+	CPUQuota    float64 // CPU quota in cores, 0 when unlimited
+	CPUs        int     // usable CPUs, the quota rounded up and capped at HostCPUs
+	MemoryLimit int64   // memory limit in bytes, 0 when unlimited
+}
+
+var (
+	detectOnce sync.Once
+	detected   Limits
+)
+
+// Detect returns the resource limits of the process. Limits are read once and logged
+// together with the values derived from them.
+func Detect() Limits {
+	detectOnce.Do(func() {
+		detected = detectLimits(cgroupRoot, conf.RunningInContainer(), runtime.NumCPU())
+		logLimits(detected)
+	})
+	return detected
+}
+
+// detectLimits reads cgroup limits below root when running in a container
+func detectLimits(root string, inContainer bool, hostCPUs int) Limits {
+	limits := Limits{InContainer: inContainer, HostCPUs: hostCPUs, CPUs: hostCPUs}
+	if !inContainer {
+		return limits
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		limits.CgroupV2 = true
+		limits.CPUQuota = readCPUMaxV2(filepath.Join(root, "cpu.max"))
+		limits.MemoryLimit = readMemoryLimit(filepath.Join(root, "memory.max"))
+	} else {
+		limits.CPUQuota = readCPUQuotaV1(filepath.Join(root, "cpu", "cpu.cfs_quota_us"), filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		limits.MemoryLimit = readMemoryLimit(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+	}
+
+	if limits.CPUQuota > 0 {
+		limits.CPUs = max(1, min(hostCPUs, int(math.Ceil(limits.CPUQuota))))
+	}
+	return limits
+}
+
+// readCPUMaxV2 parses a cgroup v2 cpu.max file in the form "<quota|max> <period>"
+func readCPUMaxV2(path string) float64 {
+	fields := strings.Fields(readFile(path))
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0
+	}
+	return cpuQuota(fields[0], fields[1])
+}
+
+// readCPUQuotaV1 parses the cgroup v1 CFS quota and period files
+func readCPUQuotaV1(quotaPath, periodPath string) float64 {
+	return cpuQuota(readFile(quotaPath), readFile(periodPath))
+}
+
+// cpuQuota converts a CFS quota and period in microseconds to cores, 0 when unlimited
+func cpuQuota(quotaStr, periodStr string) float64 {
+	quota, err := strconv.ParseInt(quotaStr, 10, 64)
+	if err != nil || quota <= 0 {
+		return 0
+	}
+	period, err := strconv.ParseInt(periodStr, 10, 64)
+	if err != nil || period <= 0 {
+		return 0
+	}
+	return float64(quota) / float64(period)
+}
+
+// readMemoryLimit parses a cgroup memory limit file, 0 when unlimited
+func readMemoryLimit(path string) int64 {
+	value := readFile(path)
+	if value == "" || value == "max" {
+		return 0
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 || limit >= unlimitedMemory {
+		return 0
+	}
+	return limit
+}
+
+// readFile returns the trimmed content of a small cgroup file, empty if it cannot be read
+func readFile(path string) string {
+	data, err := os.ReadFile(path) // #nosec G304 -- fixed cgroup filesystem paths
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// EventBusWorkers returns the number of event bus workers, one per usable CPU up to the default
+func (l Limits) EventBusWorkers() int {
+	return max(1, min(defaultEventBusWorkers, l.CPUs))
+}
+
+// EventBusBufferSize returns the event bus channel buffer size. Buffers are reduced under
+// tight memory limits so a burst of queued events cannot push the container into the OOM killer.
+func (l Limits) EventBusBufferSize() int {
+	const mib = 1024 * 1024
+	switch {
+	case l.MemoryLimit == 0:
+		return defaultEventBusBufferSize
+	case l.MemoryLimit < 256*mib:
+		return 1000
+	case l.MemoryLimit < 512*mib:
+		return 2500
+	case l.MemoryLimit < 1024*mib:
+		return 5000
+	default:
+		return defaultEventBusBufferSize
+	}
+}
+
+// FFmpegConcurrency returns how many FFmpeg or SoX processes may run at the same time for
+// on-demand work such as spectrogram generation
+func (l Limits) FFmpegConcurrency() int {
+	return max(1, min(defaultFFmpegConcurrency, l.CPUs))
+}
+
+// ApplyMemoryLimit sets the Go runtime soft memory limit to 90% of the cgroup memory limit
+// so the garbage collector works harder before the container is killed. An explicit
+// GOMEMLIMIT environment variable takes precedence. Returns the applied limit, 0 if none.
+func (l Limits) ApplyMemoryLimit() int64 {
+	if l.MemoryLimit == 0 || os.Getenv("GOMEMLIMIT") != "" {
+		return 0
+	}
+	limit := l.MemoryLimit / 10 * 9
+	debug.SetMemoryLimit(limit)
+	return limit
+}
+
+// logLimits logs detected limits and the values derived from them
+func logLimits(l Limits) {
+	logger := logging.ForService("resources")
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info("Resource limits detected",
+		"in_container", l.InContainer,
+		"cgroup_v2", l.CgroupV2,
+		"host_cpus", l.HostCPUs,
+		"cpu_quota", l.CPUQuota,
+		"cpus", l.CPUs,
+		"memory_limit_bytes", l.MemoryLimit,
+		"event_bus_workers", l.EventBusWorkers(),
+		"event_bus_buffer_size", l.EventBusBufferSize(),
+		"ffmpeg_concurrency", l.FFmpegConcurrency())
+}
@@ -0,0 +1,125 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCgroupFiles creates cgroup files below a temporary root
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content+"\n"), 0o600))
+	}
+	return root
+}
+
+func TestDetectLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		inContainer bool
+		wantCPUs    int
+		wantQuota   float64
+		wantMemory  int64
+		wantV2      bool
+	}{
+		{
+			name:        "not in container uses host CPUs",
+			files:       map[string]string{"cgroup.controllers": "cpu memory", "cpu.max": "100000 100000"},
+			inContainer: false,
+			wantCPUs:    8,
+		},
+		{
+			name:        "cgroup v2 limits",
+			files:       map[string]string{"cgroup.controllers": "cpu memory", "cpu.max": "150000 100000", "memory.max": "536870912"},
+			inContainer: true,
+			wantCPUs:    2,
+			wantQuota:   1.5,
+			wantMemory:  536870912,
+			wantV2:      true,
+		},
+		{
+			name:        "cgroup v2 unlimited",
+			files:       map[string]string{"cgroup.controllers": "cpu memory", "cpu.max": "max 100000", "memory.max": "max"},
+			inContainer: true,
+			wantCPUs:    8,
+			wantV2:      true,
+		},
+		{
+			name: "cgroup v1 limits",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "50000",
+				"cpu/cpu.cfs_period_us":        "100000",
+				"memory/memory.limit_in_bytes": "268435456",
+			},
+			inContainer: true,
+			wantCPUs:    1,
+			wantQuota:   0.5,
+			wantMemory:  268435456,
+		},
+		{
+			name: "cgroup v1 unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1",
+				"cpu/cpu.cfs_period_us":        "100000",
+				"memory/memory.limit_in_bytes": "9223372036854771712",
+			},
+			inContainer: true,
+			wantCPUs:    8,
+		},
+		{
+			name:        "quota above host is capped",
+			files:       map[string]string{"cgroup.controllers": "cpu", "cpu.max": "1600000 100000"},
+			inContainer: true,
+			wantCPUs:    8,
+			wantQuota:   16,
+			wantV2:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := writeCgroupFiles(t, tt.files)
+			limits := detectLimits(root, tt.inContainer, 8)
+			assert.Equal(t, tt.wantCPUs, limits.CPUs)
+			assert.InDelta(t, tt.wantQuota, limits.CPUQuota, 0.001)
+			assert.Equal(t, tt.wantMemory, limits.MemoryLimit)
+			assert.Equal(t, tt.wantV2, limits.CgroupV2)
+			assert.Equal(t, 8, limits.HostCPUs)
+		})
+	}
+}
+
+func TestDerivedSizes(t *testing.T) {
+	t.Parallel()
+	const mib = 1024 * 1024
+
+	host := Limits{HostCPUs: 16, CPUs: 16}
+	assert.Equal(t, defaultEventBusWorkers, host.EventBusWorkers())
+	assert.Equal(t, defaultEventBusBufferSize, host.EventBusBufferSize())
+	assert.Equal(t, defaultFFmpegConcurrency, host.FFmpegConcurrency())
+
+	small := Limits{InContainer: true, HostCPUs: 16, CPUQuota: 1.5, CPUs: 2, MemoryLimit: 200 * mib}
+	assert.Equal(t, 2, small.EventBusWorkers())
+	assert.Equal(t, 1000, small.EventBusBufferSize())
+	assert.Equal(t, 2, small.FFmpegConcurrency())
+
+	medium := Limits{InContainer: true, HostCPUs: 16, CPUs: 16, MemoryLimit: 768 * mib}
+	assert.Equal(t, 5000, medium.EventBusBufferSize())
+}
+
+func TestApplyMemoryLimitWithoutLimit(t *testing.T) {
+	t.Parallel()
+	assert.Zero(t, Limits{}.ApplyMemoryLimit())
+}
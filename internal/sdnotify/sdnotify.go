@@ -0,0 +1,69 @@
+// Package sdnotify implements the systemd service notification protocol, telling the service
+// manager when a Type=notify service is ready, stopping, and, with WatchdogSec set in the
+// unit, still healthy. Without a notification socket every call is a no-op.
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Notification states
+const (
+	Ready    = "READY=1"    // startup finished
+	Stopping = "STOPPING=1" // shutdown started
+	Watchdog = "WATCHDOG=1" // watchdog heartbeat
+)
+
+// Status returns a state setting the status text shown by systemctl status
+func Status(text string) string {
+	return "STATUS=" + text
+}
+
+// Notify sends a state to the service manager. It reports false without an error when the
+// process was not started with a notification socket.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// Names starting with @ are abstract sockets, which net maps to the abstract namespace
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, errors.New(err).
+			Component("sdnotify").
+			Category(errors.CategorySystem).
+			Context("operation", "dial_notify_socket").
+			Build()
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, errors.New(err).
+			Component("sdnotify").
+			Category(errors.CategorySystem).
+			Context("operation", "write_notify_socket").
+			Build()
+	}
+	return true, nil
+}
+
+// WatchdogTimeout returns the time within which the service manager expects a heartbeat,
+// reporting false when the watchdog is not enabled for this process. Heartbeats should be
+// sent at half the timeout.
+func WatchdogTimeout() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	// The watchdog applies to another process when WATCHDOG_PID names it
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
@@ -0,0 +1,70 @@
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(Ready)
+	require.NoError(t, err)
+	assert.False(t, sent, "no socket, nothing sent")
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	for _, state := range []string{Ready, Watchdog, Status("capture stalled")} {
+		sent, err := Notify(state)
+		require.NoError(t, err)
+		assert.True(t, sent)
+
+		buf := make([]byte, 256)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, state, string(buf[:n]))
+	}
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	sent, err = Notify(Ready)
+	require.Error(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdogTimeout(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		usec    string
+		pid     string
+		want    time.Duration
+		enabled bool
+	}{
+		{"not set", "", "", 0, false},
+		{"enabled", "30000000", "", 30 * time.Second, true},
+		{"enabled for this process", "20000000", pid, 20 * time.Second, true},
+		{"enabled for another process", "20000000", "1", 0, false},
+		{"invalid", "soon", "", 0, false},
+		{"zero", "0", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+			timeout, enabled := WatchdogTimeout()
+			assert.Equal(t, tt.enabled, enabled)
+			assert.Equal(t, tt.want, timeout)
+		})
+	}
+}
@@ -32,11 +32,15 @@ import (
 type AuthCode struct {
 	Code      string
 	ExpiresAt time.Time
+	Username  string // user who logged in, empty for the basic auth admin
+	Role      string // role of the user, empty for the basic auth admin
 }
 
 type AccessToken struct {
 	Token     string
 	ExpiresAt time.Time
+	Username  string // user the token was issued to, empty for the basic auth admin
+	Role      string // role of the user, empty for the basic auth admin
 }
 
 type OAuth2Server struct {
@@ -242,7 +246,7 @@ func InitializeGoth(settings *conf.Settings) {
 initProviders:
 	logger().Info("Configuring Goth providers")
 	// Initialize Gothic providers
-	providers := make([]goth.Provider, 0, 2)
+	providers := make([]goth.Provider, 0, 3)
 	if settings.Security.GoogleAuth.Enabled && settings.Security.GoogleAuth.ClientID != "" && settings.Security.GoogleAuth.ClientSecret != "" {
 		logger().Info("Enabling Google Auth provider")
 		googleProvider :=
@@ -266,6 +270,18 @@ initProviders:
 	} else {
 		logger().Info("GitHub Auth provider disabled or not configured")
 	}
+	if settings.Security.OIDCAuth.Enabled && settings.Security.OIDCAuth.IssuerURL != "" && settings.Security.OIDCAuth.ClientID != "" {
+		logger().Info("Enabling OpenID Connect provider", "issuer_url", settings.Security.OIDCAuth.IssuerURL)
+		oidcProvider, err := newOIDCProvider(&settings.Security.OIDCAuth)
+		if err != nil {
+			logger().Error("Failed to configure OpenID Connect provider, login with it is unavailable",
+				"issuer_url", settings.Security.OIDCAuth.IssuerURL, "error", err)
+		} else {
+			providers = append(providers, oidcProvider)
+		}
+	} else {
+		logger().Info("OpenID Connect provider disabled or not configured")
+	}
 
 	if len(providers) > 0 {
 		goth.UseProviders(providers...)
@@ -348,6 +364,11 @@ func (s *OAuth2Server) IsUserAuthenticated(c echo.Context) bool {
 		}
 	}
 
+	if username, _, ok := s.oidcSessionIdentity(c); ok {
+		logger.Info("User authenticated: valid OpenID Connect session found", "username", username)
+		return true
+	}
+
 	logger.Info("User not authenticated")
 	return false
 }
@@ -383,8 +404,14 @@ func isValidUserId(configuredIds, providedId string) bool {
 	return false
 }
 
-// GenerateAuthCode generates a new authorization code
+// GenerateAuthCode generates a new authorization code for the basic auth admin
 func (s *OAuth2Server) GenerateAuthCode() (string, error) {
+	return s.GenerateAuthCodeForUser("", "")
+}
+
+// GenerateAuthCodeForUser generates a new authorization code. The access token exchanged
+// for the code carries the user name and role.
+func (s *OAuth2Server) GenerateAuthCodeForUser(username, role string) (string, error) {
 	logger().Debug("Generating new authorization code")
 	code := make([]byte, 32)
 	_, err := rand.Read(code)
@@ -401,6 +428,8 @@ func (s *OAuth2Server) GenerateAuthCode() (string, error) {
 	s.authCodes[authCode] = AuthCode{
 		Code:      authCode,
 		ExpiresAt: expiresAt,
+		Username:  username,
+		Role:      role,
 	}
 	// Do not log the authCode itself
 	logger().Info("Generated and stored new authorization code", "expires_at", expiresAt)
@@ -456,6 +485,8 @@ func (s *OAuth2Server) ExchangeAuthCode(ctx context.Context, code string) (strin
 	s.accessTokens[accessToken] = AccessToken{
 		Token:     accessToken,
 		ExpiresAt: expiresAt,
+		Username:  authCode.Username,
+		Role:      authCode.Role,
 	}
 
 	// Invalidate the auth code after use
@@ -498,11 +529,13 @@ func (s *OAuth2Server) IsAuthenticationEnabled(ip string) bool {
 		logger.Info("Authentication bypassed: request from allowed subnet")
 		return false // Authentication not required for allowed subnets
 	}
-	if s.Settings.Security.BasicAuth.Enabled || s.Settings.Security.GoogleAuth.Enabled || s.Settings.Security.GithubAuth.Enabled {
+	if s.LoginConfigured() {
 		logger.Info("Authentication required: at least one provider enabled and IP not in allowed subnet",
 			"basic_enabled", s.Settings.Security.BasicAuth.Enabled,
 			"google_enabled", s.Settings.Security.GoogleAuth.Enabled,
 			"github_enabled", s.Settings.Security.GithubAuth.Enabled,
+			"oidc_enabled", s.Settings.Security.OIDCAuth.Enabled,
+			"users", len(s.Settings.Security.Users),
 		)
 		return true
 	}
@@ -0,0 +1,201 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/openidConnect"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	intErrors "github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// OIDCProviderName is the goth provider name of the OpenID Connect login, used in the
+// /auth/oidc and /auth/oidc/callback routes
+const OIDCProviderName = "oidc"
+
+// Session keys of an OpenID Connect login
+const (
+	oidcUserSessionKey = "oidc_user"
+	oidcRoleSessionKey = "oidc_role"
+)
+
+// oidcHTTPTimeout limits discovery, token and userinfo requests to the identity provider
+const oidcHTTPTimeout = 10 * time.Second
+
+// oidcDiscovery holds the fields of the OpenID Connect discovery document used for login
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// oidcDiscoveryURL returns the discovery document URL of an issuer. A URL that already
+// points to the discovery document is returned unchanged.
+func oidcDiscoveryURL(issuerURL string) string {
+	const wellKnown = "/.well-known/openid-configuration"
+	issuerURL = strings.TrimRight(issuerURL, "/")
+	if strings.HasSuffix(issuerURL, wellKnown) {
+		return issuerURL
+	}
+	return issuerURL + wellKnown
+}
+
+// newOIDCProvider reads the discovery document of the issuer and returns the goth provider
+// for the OpenID Connect login
+func newOIDCProvider(settings *conf.OIDCProvider) (goth.Provider, error) {
+	client := &http.Client{Timeout: oidcHTTPTimeout}
+	discoveryURL := oidcDiscoveryURL(settings.IssuerURL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcHTTPTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, http.NoBody)
+	if err != nil {
+		return nil, oidcConfigError(err, discoveryURL)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, intErrors.New(err).
+			Component("security").
+			Category(intErrors.CategoryNetwork).
+			Context("discovery_url", discoveryURL).
+			Build()
+	}
+	defer resp.Body.Close() //nolint:errcheck // Body is only read
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, oidcConfigError(fmt.Errorf("discovery document request returned status %d", resp.StatusCode), discoveryURL)
+	}
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, oidcConfigError(fmt.Errorf("invalid discovery document: %w", err), discoveryURL)
+	}
+	if discovery.Issuer == "" || discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return nil, oidcConfigError(fmt.Errorf("discovery document lacks issuer, authorization or token endpoint"), discoveryURL)
+	}
+
+	provider, err := openidConnect.NewCustomisedURL(settings.ClientID, settings.ClientSecret, settings.RedirectURI,
+		discovery.AuthorizationEndpoint, discovery.TokenEndpoint, discovery.Issuer,
+		discovery.UserInfoEndpoint, discovery.EndSessionEndpoint, settings.Scopes...)
+	if err != nil {
+		return nil, oidcConfigError(err, discoveryURL)
+	}
+	provider.HTTPClient = client
+	provider.SetName(OIDCProviderName)
+	return provider, nil
+}
+
+// oidcConfigError wraps an error in reading the configuration of the identity provider
+func oidcConfigError(err error, discoveryURL string) error {
+	return intErrors.New(err).
+		Component("security").
+		Category(intErrors.CategoryConfiguration).
+		Context("discovery_url", discoveryURL).
+		Build()
+}
+
+// OIDCGroups returns the groups in a claim of an OpenID Connect user. Providers send the
+// groups as a list or, with a single group, as a string.
+func OIDCGroups(claims map[string]any, claim string) []string {
+	switch value := claims[claim].(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case []string:
+		return value
+	case []any:
+		groups := make([]string, 0, len(value))
+		for _, group := range value {
+			if name, ok := group.(string); ok && name != "" {
+				groups = append(groups, name)
+			}
+		}
+		return groups
+	}
+	return nil
+}
+
+// OIDCRoleForGroups returns the role given to members of groups. Admin groups take
+// precedence over viewer groups. ok is false when no group has a role, such users
+// may not log in.
+func OIDCRoleForGroups(settings *conf.OIDCProvider, groups []string) (role string, ok bool) {
+	inAny := func(configured []string) bool {
+		return slices.ContainsFunc(groups, func(group string) bool {
+			return slices.Contains(configured, group)
+		})
+	}
+	switch {
+	case inAny(settings.AdminGroups):
+		return conf.RoleAdmin, true
+	case inAny(settings.ViewerGroups):
+		return conf.RoleViewer, true
+	}
+	return "", false
+}
+
+// OIDCUsername returns the name of an OpenID Connect user shown in logs and the audit log,
+// the preferred user name or nickname if present, else the email or subject
+func OIDCUsername(user *goth.User) string {
+	for _, name := range []string{user.NickName, user.Email, user.UserID} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// OIDCRole returns the role of a user who logged in with the OpenID Connect provider
+func (s *OAuth2Server) OIDCRole(user *goth.User) (role string, ok bool) {
+	oidc := &s.Settings.Security.OIDCAuth
+	groups := OIDCGroups(user.RawData, oidc.GroupsClaim)
+	role, ok = OIDCRoleForGroups(oidc, groups)
+	if !ok {
+		LogWarn("OpenID Connect user is in no group with a role", "username", OIDCUsername(user), "groups", groups)
+	}
+	return role, ok
+}
+
+// StoreOIDCLogin stores the user name and role of an OpenID Connect login in the session
+func StoreOIDCLogin(c echo.Context, username, role string) error {
+	if err := gothic.StoreInSession(oidcUserSessionKey, username, c.Request(), c.Response()); err != nil {
+		return err
+	}
+	return gothic.StoreInSession(oidcRoleSessionKey, role, c.Request(), c.Response())
+}
+
+// ClearOIDCLogin removes an OpenID Connect login from the session
+func ClearOIDCLogin(c echo.Context) error {
+	if err := gothic.StoreInSession(oidcUserSessionKey, "", c.Request(), c.Response()); err != nil {
+		return err
+	}
+	return gothic.StoreInSession(oidcRoleSessionKey, "", c.Request(), c.Response())
+}
+
+// oidcSessionIdentity returns the OpenID Connect login stored in the session. ok is false
+// when there is none or the provider has been disabled since the login.
+func (s *OAuth2Server) oidcSessionIdentity(c echo.Context) (username, role string, ok bool) {
+	if !s.Settings.Security.OIDCAuth.Enabled {
+		return "", "", false
+	}
+	role, err := gothic.GetFromSession(oidcRoleSessionKey, c.Request())
+	if err != nil || (role != conf.RoleAdmin && role != conf.RoleViewer) {
+		return "", "", false
+	}
+	username, err = gothic.GetFromSession(oidcUserSessionKey, c.Request())
+	if err != nil || username == "" {
+		return "", "", false
+	}
+	return username, role, true
+}
@@ -0,0 +1,145 @@
+package security
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/markbates/goth"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// TestOIDCGroups tests reading groups from the claim formats used by providers
+func TestOIDCGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   []string
+	}{
+		{"list", map[string]any{"groups": []any{"admins", "family", 42}}, []string{"admins", "family"}},
+		{"single group", map[string]any{"groups": "family"}, []string{"family"}},
+		{"empty string", map[string]any{"groups": ""}, nil},
+		{"missing claim", map[string]any{"roles": []any{"admins"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OIDCGroups(tt.claims, "groups"); !slices.Equal(got, tt.want) {
+				t.Errorf("OIDCGroups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOIDCRoleForGroups tests mapping groups to roles
+func TestOIDCRoleForGroups(t *testing.T) {
+	settings := &conf.OIDCProvider{
+		AdminGroups:  []string{"birdnet-admins"},
+		ViewerGroups: []string{"family", "birdnet-admins-readonly"},
+	}
+	tests := []struct {
+		name     string
+		groups   []string
+		wantRole string
+		wantOK   bool
+	}{
+		{"admin group", []string{"users", "birdnet-admins"}, conf.RoleAdmin, true},
+		{"viewer group", []string{"family"}, conf.RoleViewer, true},
+		{"admin wins over viewer", []string{"family", "birdnet-admins"}, conf.RoleAdmin, true},
+		{"group names are case sensitive", []string{"Birdnet-Admins"}, "", false},
+		{"no matching group", []string{"users"}, "", false},
+		{"no groups", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, ok := OIDCRoleForGroups(settings, tt.groups)
+			if role != tt.wantRole || ok != tt.wantOK {
+				t.Errorf("OIDCRoleForGroups() = %q, %v, want %q, %v", role, ok, tt.wantRole, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestOIDCRoleUsesGroupsClaim tests that the role is read from the configured claim
+func TestOIDCRoleUsesGroupsClaim(t *testing.T) {
+	s, _ := newRolesTestServer(t)
+	s.Settings.Security.OIDCAuth = conf.OIDCProvider{
+		Enabled:      true,
+		GroupsClaim:  "roles",
+		ViewerGroups: []string{"family"},
+	}
+
+	user := &goth.User{NickName: "robin", RawData: map[string]any{"groups": []any{"family"}, "roles": []any{"family"}}}
+	if role, ok := s.OIDCRole(user); !ok || role != conf.RoleViewer {
+		t.Errorf("Expected viewer role from roles claim, got %q %v", role, ok)
+	}
+
+	user.RawData = map[string]any{"groups": []any{"family"}}
+	if _, ok := s.OIDCRole(user); ok {
+		t.Error("Expected groups outside the configured claim to be ignored")
+	}
+}
+
+// TestOIDCUsername tests the choice of the name shown for OpenID Connect users
+func TestOIDCUsername(t *testing.T) {
+	if got := OIDCUsername(&goth.User{NickName: "robin", Email: "robin@example.com", UserID: "1234"}); got != "robin" {
+		t.Errorf("Expected preferred user name, got %q", got)
+	}
+	if got := OIDCUsername(&goth.User{Email: "robin@example.com", UserID: "1234"}); got != "robin@example.com" {
+		t.Errorf("Expected email, got %q", got)
+	}
+	if got := OIDCUsername(&goth.User{UserID: "1234"}); got != "1234" {
+		t.Errorf("Expected subject, got %q", got)
+	}
+}
+
+// TestNewOIDCProvider tests configuring the provider from a discovery document
+func TestNewOIDCProvider(t *testing.T) {
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/realms/home/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": issuer + "/auth",
+			"token_endpoint":         issuer + "/token",
+			"userinfo_endpoint":      issuer + "/userinfo",
+		})
+	}))
+	defer server.Close()
+	issuer = server.URL + "/realms/home"
+
+	settings := &conf.OIDCProvider{
+		IssuerURL:   issuer + "/",
+		ClientID:    "birdnet",
+		RedirectURI: "https://birdnet.example.com/auth/oidc/callback",
+		Scopes:      []string{"openid", "groups"},
+	}
+	provider, err := newOIDCProvider(settings)
+	if err != nil {
+		t.Fatalf("newOIDCProvider failed: %v", err)
+	}
+	if provider.Name() != OIDCProviderName {
+		t.Errorf("Expected provider name %q, got %q", OIDCProviderName, provider.Name())
+	}
+	session, err := provider.BeginAuth("state")
+	if err != nil {
+		t.Fatalf("BeginAuth failed: %v", err)
+	}
+	authURL, err := session.GetAuthURL()
+	if err != nil || !strings.HasPrefix(authURL, issuer+"/auth?") {
+		t.Errorf("Expected auth URL at the discovered endpoint, got %q %v", authURL, err)
+	}
+
+	settings.IssuerURL = server.URL + "/unknown"
+	if _, err := newOIDCProvider(settings); err == nil {
+		t.Error("Expected error for missing discovery document")
+	}
+}
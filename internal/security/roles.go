@@ -0,0 +1,171 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markbates/goth/gothic"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// ErrAPITokenNotFound is returned when a bearer token matches no configured API token
+var ErrAPITokenNotFound = errors.New("API token not found")
+
+// dummyPasswordHash is checked when a user name is unknown so that logins with unknown
+// and known user names take the same time
+const dummyPasswordHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+// HashPassword returns the bcrypt hash of a password for conf.UserAccount.PasswordHash
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// AuthenticateUser checks the password of a configured user and returns the role of the user
+func (s *OAuth2Server) AuthenticateUser(username, password string) (role string, ok bool) {
+	hash := dummyPasswordHash
+	for i := range s.Settings.Security.Users {
+		user := &s.Settings.Security.Users[i]
+		if strings.EqualFold(user.Username, username) {
+			hash, role = user.PasswordHash, user.Role
+			break
+		}
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil || role == "" {
+		LogWarn("User authentication failed", "username", username)
+		return "", false
+	}
+	LogInfo("User authentication successful", "username", username, "role", role)
+	return role, true
+}
+
+// HasUser reports whether a user with the name is configured
+func (s *OAuth2Server) HasUser(username string) bool {
+	for i := range s.Settings.Security.Users {
+		if strings.EqualFold(s.Settings.Security.Users[i].Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIToken returns a new random API token and the hash stored in conf.APIToken.TokenHash.
+// The token itself is not stored and can only be shown once.
+func GenerateAPIToken() (token, hash string, err error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(tokenBytes)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns the hex encoded SHA-256 hash of an API token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateAPIToken returns the name and role of the configured API token matching token
+func (s *OAuth2Server) ValidateAPIToken(token string) (name, role string, err error) {
+	hash := HashAPIToken(token)
+	for i := range s.Settings.Security.APITokens {
+		apiToken := &s.Settings.Security.APITokens[i]
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(apiToken.TokenHash)) == 1 {
+			return apiToken.Name, apiToken.Role, nil
+		}
+	}
+	return "", "", ErrAPITokenNotFound
+}
+
+// APITokenScopes returns the scopes the configured API token matching token is limited
+// to. It returns nil for tokens without scopes and for other tokens.
+func (s *OAuth2Server) APITokenScopes(token string) []string {
+	hash := HashAPIToken(token)
+	for i := range s.Settings.Security.APITokens {
+		apiToken := &s.Settings.Security.APITokens[i]
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(apiToken.TokenHash)) == 1 {
+			return apiToken.Scopes
+		}
+	}
+	return nil
+}
+
+// AccessTokenIdentity returns the user name and role of a valid access token. Tokens of
+// the basic auth admin and tokens issued before roles existed have the admin role.
+func (s *OAuth2Server) AccessTokenIdentity(token string) (username, role string, err error) {
+	if err := s.ValidateAccessToken(token); err != nil {
+		return "", "", err
+	}
+
+	s.mutex.RLock()
+	accessToken := s.accessTokens[token]
+	s.mutex.RUnlock()
+
+	if accessToken.Role == "" {
+		return accessToken.Username, conf.RoleAdmin, nil
+	}
+	return accessToken.Username, accessToken.Role, nil
+}
+
+// SessionIdentity returns the user name and role of the login stored in the browser
+// session. ok is false when the session has no valid login; unlike IsUserAuthenticated
+// it does not treat clients in the local subnet as logged in.
+func (s *OAuth2Server) SessionIdentity(c echo.Context) (username, role string, ok bool) {
+	if token, err := gothic.GetFromSession("access_token", c.Request()); err == nil && token != "" {
+		if username, role, err := s.AccessTokenIdentity(token); err == nil {
+			return username, role, true
+		}
+	}
+
+	// Users of the OpenID Connect provider have the role of their groups
+	if username, role, ok := s.oidcSessionIdentity(c); ok {
+		return username, role, true
+	}
+
+	// Users allowed by the social providers are admins
+	userID, err := gothic.GetFromSession("userId", c.Request())
+	if err != nil || userID == "" {
+		return "", "", false
+	}
+	if s.Settings.Security.GoogleAuth.Enabled && isValidUserId(s.Settings.Security.GoogleAuth.UserId, userID) {
+		if googleUser, err := gothic.GetFromSession("google", c.Request()); err == nil && googleUser != "" {
+			return userID, conf.RoleAdmin, true
+		}
+	}
+	if s.Settings.Security.GithubAuth.Enabled && isValidUserId(s.Settings.Security.GithubAuth.UserId, userID) {
+		if githubUser, err := gothic.GetFromSession("github", c.Request()); err == nil && githubUser != "" {
+			return userID, conf.RoleAdmin, true
+		}
+	}
+	return "", "", false
+}
+
+// LoginConfigured reports whether any way to log in is configured
+func (s *OAuth2Server) LoginConfigured() bool {
+	security := &s.Settings.Security
+	return security.BasicAuth.Enabled || security.GoogleAuth.Enabled || security.GithubAuth.Enabled || security.OIDCAuth.Enabled ||
+		len(security.Users) > 0
+}
+
+// SubnetBypassRole returns the role of clients that use the service without login because
+// they are in an allowed subnet. It defaults to admin, the behavior before roles existed,
+// and is admin when no login is configured as viewers could not log in for full access.
+func (s *OAuth2Server) SubnetBypassRole() string {
+	if s.Settings.Security.AllowSubnetBypass.Role == conf.RoleViewer && s.LoginConfigured() {
+		return conf.RoleViewer
+	}
+	return conf.RoleAdmin
+}
@@ -0,0 +1,140 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// newRolesTestServer returns a server with one viewer account and one admin API token
+func newRolesTestServer(t *testing.T) (server *OAuth2Server, apiToken string) {
+	t.Helper()
+
+	hash, err := HashPassword("viewer-password")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	apiToken, tokenHash, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("GenerateAPIToken failed: %v", err)
+	}
+
+	server = &OAuth2Server{
+		Settings: &conf.Settings{
+			Security: conf.Security{
+				BasicAuth: conf.BasicAuth{AuthCodeExp: time.Minute, AccessTokenExp: time.Hour},
+				Users: []conf.UserAccount{
+					{Username: "Guest", PasswordHash: hash, Role: conf.RoleViewer},
+				},
+				APITokens: []conf.APIToken{
+					{Name: "home-assistant", TokenHash: tokenHash, Role: conf.RoleAdmin},
+				},
+			},
+		},
+		authCodes:    make(map[string]AuthCode),
+		accessTokens: make(map[string]AccessToken),
+	}
+	return server, apiToken
+}
+
+// TestAuthenticateUser tests password checks of configured user accounts
+func TestAuthenticateUser(t *testing.T) {
+	s, _ := newRolesTestServer(t)
+
+	if role, ok := s.AuthenticateUser("guest", "viewer-password"); !ok || role != conf.RoleViewer {
+		t.Errorf("Expected viewer login with case-insensitive username, got role %q ok %v", role, ok)
+	}
+	if _, ok := s.AuthenticateUser("Guest", "wrong-password"); ok {
+		t.Error("Expected wrong password to be rejected")
+	}
+	if _, ok := s.AuthenticateUser("nobody", "viewer-password"); ok {
+		t.Error("Expected unknown user to be rejected")
+	}
+	if !s.HasUser("GUEST") || s.HasUser("nobody") {
+		t.Error("HasUser returned unexpected result")
+	}
+}
+
+// TestValidateAPIToken tests lookup of configured API tokens by their hash
+func TestValidateAPIToken(t *testing.T) {
+	s, apiToken := newRolesTestServer(t)
+
+	name, role, err := s.ValidateAPIToken(apiToken)
+	if err != nil || name != "home-assistant" || role != conf.RoleAdmin {
+		t.Errorf("Expected home-assistant admin token, got %q %q %v", name, role, err)
+	}
+	if _, _, err := s.ValidateAPIToken(apiToken + "x"); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Errorf("Expected ErrAPITokenNotFound, got %v", err)
+	}
+	if scopes := s.APITokenScopes(apiToken); scopes != nil {
+		t.Errorf("Expected no scopes, got %v", scopes)
+	}
+	s.Settings.Security.APITokens[0].Scopes = []string{conf.ScopeDetectionsRead}
+	if scopes := s.APITokenScopes(apiToken); len(scopes) != 1 || scopes[0] != conf.ScopeDetectionsRead {
+		t.Errorf("Expected detections:read scope, got %v", scopes)
+	}
+	if got := HashAPIToken(apiToken); len(got) != 64 {
+		t.Errorf("Expected 64 character hex hash, got %q", got)
+	}
+}
+
+// TestAccessTokenIdentity tests that access tokens carry the role of the login
+func TestAccessTokenIdentity(t *testing.T) {
+	s, _ := newRolesTestServer(t)
+	ctx := context.Background()
+
+	code, err := s.GenerateAuthCodeForUser("Guest", conf.RoleViewer)
+	if err != nil {
+		t.Fatalf("GenerateAuthCodeForUser failed: %v", err)
+	}
+	token, err := s.ExchangeAuthCode(ctx, code)
+	if err != nil {
+		t.Fatalf("ExchangeAuthCode failed: %v", err)
+	}
+	if username, role, err := s.AccessTokenIdentity(token); err != nil || username != "Guest" || role != conf.RoleViewer {
+		t.Errorf("Expected Guest viewer, got %q %q %v", username, role, err)
+	}
+
+	// Tokens of the basic auth admin have no role and are admins
+	code, err = s.GenerateAuthCode()
+	if err != nil {
+		t.Fatalf("GenerateAuthCode failed: %v", err)
+	}
+	token, err = s.ExchangeAuthCode(ctx, code)
+	if err != nil {
+		t.Fatalf("ExchangeAuthCode failed: %v", err)
+	}
+	if _, role, err := s.AccessTokenIdentity(token); err != nil || role != conf.RoleAdmin {
+		t.Errorf("Expected admin role, got %q %v", role, err)
+	}
+
+	if _, _, err := s.AccessTokenIdentity("unknown"); err == nil {
+		t.Error("Expected unknown token to be rejected")
+	}
+}
+
+// TestSubnetBypassRole tests the role of clients bypassing authentication
+func TestSubnetBypassRole(t *testing.T) {
+	s, _ := newRolesTestServer(t)
+
+	if got := s.SubnetBypassRole(); got != conf.RoleAdmin {
+		t.Errorf("Expected admin by default, got %q", got)
+	}
+
+	s.Settings.Security.AllowSubnetBypass.Role = conf.RoleViewer
+	if got := s.SubnetBypassRole(); got != conf.RoleViewer {
+		t.Errorf("Expected viewer, got %q", got)
+	}
+	if !s.IsAuthenticationEnabled("203.0.113.10") {
+		t.Error("Expected user accounts to require authentication")
+	}
+
+	// Without any login viewers could never get full access
+	s.Settings.Security.Users = nil
+	if got := s.SubnetBypassRole(); got != conf.RoleAdmin {
+		t.Errorf("Expected admin without configured login, got %q", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net/http"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -106,6 +107,20 @@ func enableDebugLogging() {
 	logTelemetryInfo(nil, "telemetry debug logging enabled")
 }
 
+// sentryTransport returns the transport of Sentry requests with the outbound proxy and TLS
+// settings, or nil for the Sentry default transport, which uses the proxy environment variables
+func sentryTransport(settings *conf.Settings) http.RoundTripper {
+	if settings == nil {
+		return nil
+	}
+	transport, err := settings.OutboundHTTPTransport(false)
+	if err != nil {
+		logTelemetryWarn(nil, "outbound proxy and TLS settings not applied to telemetry", "error", err)
+		return nil
+	}
+	return transport
+}
+
 // initializeSentrySDK initializes the Sentry SDK with privacy-compliant options
 func initializeSentrySDK(settings *conf.Settings) error {
 	// Use hardcoded DSN for BirdNET-Go project
@@ -127,6 +142,9 @@ func initializeSentrySDK(settings *conf.Settings) error {
 
 		// BeforeSend allows us to filter sensitive data
 		BeforeSend: createBeforeSendHook(settings),
+
+		// Send events through the outbound proxy
+		HTTPTransport: sentryTransport(settings),
 	})
 
 	if err != nil {
@@ -528,6 +546,7 @@ func InitMinimalSentryForSupport(systemID, version string) error {
 		Environment:      "production",
 		ServerName:       "", // No server identification
 		Release:          fmt.Sprintf("birdnet-go@%s", version),
+		HTTPTransport:    sentryTransport(conf.GetSettings()),
 		// Only allow support dump events
 		BeforeSend: func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
 			// Only allow events tagged as support dumps
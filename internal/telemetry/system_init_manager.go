@@ -11,6 +11,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/events"
 	"github.com/tphakala/birdnet-go/internal/notification"
+	"github.com/tphakala/birdnet-go/internal/resources"
 )
 
 // Default configuration values for notification service
@@ -167,9 +168,11 @@ func (m *SystemInitManager) initializeEventBus() error {
 		}
 		
 		// Initialize event bus for async error processing
+		// Size the event bus to the CPU and memory available, containers may be limited
+		limits := resources.Detect()
 		eventBusConfig := &events.Config{
-			BufferSize: 10000,
-			Workers:    4,
+			BufferSize: limits.EventBusBufferSize(),
+			Workers:    limits.EventBusWorkers(),
 			Enabled:    true,
 			Debug:      debug,
 			Deduplication: &events.DeduplicationConfig{
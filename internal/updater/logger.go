@@ -0,0 +1,28 @@
+// logger.go: structured logging for the self-updater
+package updater
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/tphakala/birdnet-go/internal/logging"
+)
+
+// Package-level logger for update operations
+var (
+	updaterLogger   *slog.Logger
+	updaterLevelVar = new(slog.LevelVar) // Dynamic level control
+)
+
+func init() {
+	var err error
+	updaterLevelVar.Set(slog.LevelInfo)
+
+	updaterLogger, _, err = logging.NewFileLogger("logs/updater.log", "updater", updaterLevelVar)
+	if err != nil {
+		logging.Error("Failed to initialize updater file logger", "error", err)
+		// Fallback to a disabled logger (writes to io.Discard) but respects the level var
+		fbHandler := slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: updaterLevelVar})
+		updaterLogger = slog.New(fbHandler).With("service", "updater")
+	}
+}
@@ -0,0 +1,50 @@
+// restart.go: restart the service after installing or rolling back a binary
+package updater
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Restart restarts the service so the installed binary is run. A configured restart
+// command, e.g. "systemctl restart birdnet-go", is started without waiting as it usually
+// stops this process. Without a command the process terminates itself gracefully and
+// relies on the service manager restart policy to start it again.
+func Restart(command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		updaterLogger.Info("Terminating to let the service manager restart the updated binary")
+		proc, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = proc.Signal(syscall.SIGTERM)
+		}
+		if err != nil {
+			return errors.New(err).
+				Component("updater").
+				Category(errors.CategorySystem).
+				Context("operation", "signal_restart").
+				Build()
+		}
+		return nil
+	}
+
+	updaterLogger.Info("Restarting service", "command", command)
+	cmd := exec.Command(fields[0], fields[1:]...) // #nosec G204 -- command comes from the local configuration file
+	if err := cmd.Start(); err != nil {
+		return errors.New(err).
+			Component("updater").
+			Category(errors.CategoryCommandExecution).
+			Context("operation", "run_restart_command").
+			Build()
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			updaterLogger.Warn("Restart command failed", "command", command, "error", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,184 @@
+// rollback.go: post-update health check and rollback to the previous binary
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	// pendingSuffix is appended to the executable path for the pending update marker
+	pendingSuffix = ".update.json"
+
+	// maxStartAttempts is how many times an updated binary may start without passing its
+	// health check, e.g. because it crashes before the check completes, before rollback
+	maxStartAttempts = 3
+
+	healthPollInterval = 5 * time.Second
+)
+
+// PendingUpdate records an installed update awaiting its health check
+type PendingUpdate struct {
+	FromVersion string    `json:"from_version"`
+	ToVersion   string    `json:"to_version"`
+	BackupPath  string    `json:"backup_path"`
+	AppliedAt   time.Time `json:"applied_at"`
+	Attempts    int       `json:"attempts"` // starts of the updated binary so far
+}
+
+// savePending writes the pending update marker next to the executable
+func savePending(executable string, pending *PendingUpdate) error {
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "encode_pending_update").
+			Build()
+	}
+	if err := os.WriteFile(executable+pendingSuffix, data, 0o600); err != nil {
+		return errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_pending_update").
+			Build()
+	}
+	return nil
+}
+
+// loadPending reads the pending update marker, reporting false if there is none
+func loadPending(executable string) (*PendingUpdate, bool, error) {
+	data, err := os.ReadFile(executable + pendingSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_pending_update").
+			Build()
+	}
+
+	var pending PendingUpdate
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, false, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileParsing).
+			Context("operation", "decode_pending_update").
+			Build()
+	}
+	return &pending, true, nil
+}
+
+// clearPending removes the pending update marker
+func clearPending(executable string) {
+	if err := os.Remove(executable + pendingSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		updaterLogger.Warn("Failed to remove pending update marker", "error", err)
+	}
+}
+
+// VerifyPendingUpdate confirms an update installed by Apply once healthURL responds
+// successfully. If the health check does not pass within the configured timeout, or the
+// updated binary has already failed to start too many times, the previous binary is
+// restored. It returns true when a rollback was performed and the service must be
+// restarted to run the previous binary.
+func (u *Updater) VerifyPendingUpdate(ctx context.Context, healthURL string) (bool, error) {
+	pending, found, err := loadPending(u.executable)
+	if err != nil || !found {
+		return false, err
+	}
+
+	logger := updaterLogger.With("from_version", pending.FromVersion, "to_version", pending.ToVersion)
+
+	// The marker belongs to another binary, e.g. after a manual downgrade
+	if pending.ToVersion != u.version {
+		logger.Info("Discarding stale pending update", "running_version", u.version)
+		clearPending(u.executable)
+		return false, nil
+	}
+
+	pending.Attempts++
+	if pending.Attempts > maxStartAttempts {
+		logger.Warn("Updated binary failed to start repeatedly, rolling back", "attempts", pending.Attempts-1)
+		return u.rollback(pending)
+	}
+	if err := savePending(u.executable, pending); err != nil {
+		return false, err
+	}
+
+	timeout := time.Duration(u.settings.HealthTimeout) * time.Second
+	if err := u.waitHealthy(ctx, healthURL, timeout); err != nil {
+		if ctx.Err() != nil {
+			// Shutting down before the check completed, retry on next start
+			return false, nil
+		}
+		logger.Warn("Updated binary failed its health check, rolling back", "error", err)
+		return u.rollback(pending)
+	}
+
+	logger.Info("Update passed health check", "attempts", pending.Attempts)
+	clearPending(u.executable)
+	return false, nil
+}
+
+// waitHealthy polls healthURL until it responds with 200 OK or the timeout expires
+func (u *Updater) waitHealthy(ctx context.Context, healthURL string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		body, err := u.get(ctx, u.localClient, healthURL)
+		if err == nil {
+			_ = body.Close()
+			return nil
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return errors.New(lastErr).
+				Component("updater").
+				Category(errors.CategoryTimeout).
+				Context("operation", "update_health_check").
+				Context("timeout_seconds", timeout.Seconds()).
+				Build()
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollback restores the previous binary and removes the pending update marker.
+// It reports whether the previous binary was restored.
+func (u *Updater) rollback(pending *PendingUpdate) (bool, error) {
+	if err := os.Rename(pending.BackupPath, u.executable); err != nil {
+		return false, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "rollback_binary").
+			Build()
+	}
+	clearPending(u.executable)
+	updaterLogger.Info("Rolled back to previous binary",
+		"from_version", pending.ToVersion,
+		"to_version", pending.FromVersion)
+	return true, nil
+}
+
+// HealthURL returns the web server health endpoint used to verify an update
+func HealthURL(settings *conf.Settings) string {
+	if settings.Security.AutoTLS && settings.Security.Host != "" {
+		// AutoTLS serves HTTPS on the standard port with a certificate for the configured host
+		return "https://" + settings.Security.Host + "/api/v2/health"
+	}
+	return "http://127.0.0.1:" + settings.WebServer.Port + "/api/v2/health"
+}
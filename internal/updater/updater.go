@@ -0,0 +1,469 @@
+// Package updater implements the optional binary self-update. It checks a release feed
+// for the configured channel, verifies the ed25519 signature of the new binary, swaps it
+// into place and rolls back to the previous binary when the updated one does not pass
+// its health check after the restart.
+//
+// The release feed is a JSON document listing the latest release of each channel:
+//
+//	{
+//	  "channels": {
+//	    "stable": {
+//	      "version": "v0.7.0",
+//	      "published": "2025-06-01T12:00:00Z",
+//	      "notes": "Release notes",
+//	      "assets": {
+//	        "linux/arm64": {"url": "https://...", "sha256": "<hex>", "signature": "<base64>"}
+//	      }
+//	    }
+//	  }
+//	}
+//
+// Assets are keyed by GOOS/GOARCH. The signature is an ed25519 signature of a message
+// binding the binary to its release, so a signed binary cannot be served as another
+// version, channel or platform:
+//
+//	birdnet-go-release
+//	version=v0.7.0
+//	channel=stable
+//	platform=linux/arm64
+//	sha256=<hex digest of the binary>
+//
+// Each line ends with a newline. Releases that are not newer than the running version are
+// refused, so a feed cannot downgrade the binary to an older signed release.
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
+)
+
+const (
+	feedRequestTimeout = 30 * time.Second
+	downloadTimeout    = 30 * time.Minute
+	maxFeedSize        = 1 << 20   // 1 MiB
+	maxBinarySize      = 512 << 20 // 512 MiB
+
+	// BackupSuffix is appended to the executable path to keep the previous binary for rollback
+	BackupSuffix = ".previous"
+)
+
+// Sentinel errors returned by the updater
+var (
+	ErrUpdatesDisabled    = errors.NewStd("self-update is disabled")
+	ErrNoUpdateAvailable  = errors.NewStd("no update available")
+	ErrNoAssetForPlatform = errors.NewStd("release has no binary for this platform")
+	ErrChecksumMismatch   = errors.NewStd("release checksum mismatch")
+	ErrSignatureInvalid   = errors.NewStd("release signature verification failed")
+	ErrContainerInstall   = errors.NewStd("self-update is not supported in containers, update the container image instead")
+)
+
+// Feed is the release feed listing the latest release of each channel
+type Feed struct {
+	Channels map[string]Release `json:"channels"`
+}
+
+// Release describes a published build
+type Release struct {
+	Version   string           `json:"version"`
+	Published time.Time        `json:"published"`
+	Notes     string           `json:"notes,omitempty"`
+	Assets    map[string]Asset `json:"assets"` // keyed by GOOS/GOARCH
+}
+
+// Asset is a downloadable binary for one platform
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`    // hex encoded SHA-256 digest of the binary
+	Signature string `json:"signature"` // base64 encoded ed25519 signature of the release message, see signedMessage
+}
+
+// CheckResult is the outcome of checking the release feed
+type CheckResult struct {
+	CurrentVersion string   `json:"current_version"`
+	Channel        string   `json:"channel"`
+	Platform       string   `json:"platform"`
+	Available      bool     `json:"available"`
+	Latest         *Release `json:"latest,omitempty"`
+}
+
+// Updater checks for, downloads and installs new releases of the running binary
+type Updater struct {
+	settings    conf.UpdateSettings
+	version     string
+	publicKey   ed25519.PublicKey
+	httpClient  *http.Client // release feed and binary downloads, uses the outbound proxy and TLS settings
+	localClient *http.Client // health checks of the local web server
+	executable  string       // path of the binary to replace
+	platform    string       // GOOS/GOARCH asset key
+	inContainer func() bool  // reports whether the binary runs inside a container
+}
+
+// New creates an updater for the running binary from the settings. channel overrides the
+// configured release channel when not empty.
+func New(settings *conf.Settings, channel string) (*Updater, error) {
+	update := settings.Update
+	if !update.Enabled {
+		return nil, ErrUpdatesDisabled
+	}
+	if channel != "" {
+		update.Channel = channel
+	}
+
+	key, err := base64.StdEncoding.DecodeString(update.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.Newf("invalid update public key").
+			Component("updater").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_public_key").
+			Build()
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		return nil, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "locate_executable").
+			Build()
+	}
+
+	// Binary downloads outlast the default client timeout, feed requests set a shorter deadline
+	httpClient, err := httpclient.New(settings, httpclient.Options{
+		Integration: "updater",
+		Timeout:     downloadTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Updater{
+		settings:    update,
+		version:     settings.Version,
+		publicKey:   ed25519.PublicKey(key),
+		httpClient:  httpClient,
+		localClient: &http.Client{},
+		executable:  executable,
+		platform:    runtime.GOOS + "/" + runtime.GOARCH,
+		inContainer: conf.RunningInContainer,
+	}, nil
+}
+
+// Check fetches the release feed and reports whether the configured channel has a newer release
+func (u *Updater) Check(ctx context.Context) (*CheckResult, error) {
+	feed, err := u.fetchFeed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{
+		CurrentVersion: u.version,
+		Channel:        u.settings.Channel,
+		Platform:       u.platform,
+	}
+
+	release, ok := feed.Channels[u.settings.Channel]
+	if !ok || release.Version == "" {
+		return result, nil
+	}
+	result.Latest = &release
+
+	if _, ok := release.Assets[u.platform]; ok {
+		result.Available = isNewer(release.Version, u.version)
+	}
+
+	return result, nil
+}
+
+// fetchFeed downloads and decodes the release feed
+func (u *Updater) fetchFeed(ctx context.Context) (*Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedRequestTimeout)
+	defer cancel()
+
+	body, err := u.get(ctx, u.httpClient, u.settings.FeedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var feed Feed
+	if err := json.NewDecoder(io.LimitReader(body, maxFeedSize)).Decode(&feed); err != nil {
+		return nil, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileParsing).
+			Context("operation", "decode_release_feed").
+			Build()
+	}
+
+	return &feed, nil
+}
+
+// get performs a GET request with client and returns the response body of a successful response
+func (u *Updater) get(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryNetwork).
+			Context("operation", "create_request").
+			Build()
+	}
+	req.Header.Set("User-Agent", "BirdNET-Go/"+u.version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("updater").
+			Category(errors.CategoryNetwork).
+			NetworkContext(url, 0).
+			Context("operation", "http_get").
+			Build()
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, errors.New(fmt.Errorf("unexpected HTTP status %d", resp.StatusCode)).
+			Component("updater").
+			Category(errors.CategoryNetwork).
+			NetworkContext(url, 0).
+			Context("operation", "http_get").
+			Build()
+	}
+
+	return resp.Body, nil
+}
+
+// Apply downloads the release binary for this platform, verifies it and replaces the
+// running binary with it. The previous binary is kept next to it for rollback and the
+// update is recorded as pending until the new binary passes its health check.
+// The caller restarts the service afterwards with Restart.
+func (u *Updater) Apply(ctx context.Context, release *Release) error {
+	if u.inContainer() {
+		return ErrContainerInstall
+	}
+	if release == nil || !isNewer(release.Version, u.version) {
+		return ErrNoUpdateAvailable
+	}
+	asset, ok := release.Assets[u.platform]
+	if !ok {
+		return ErrNoAssetForPlatform
+	}
+
+	logger := updaterLogger.With("from_version", u.version, "to_version", release.Version)
+	logger.Info("Downloading update", "url", asset.URL, "platform", u.platform)
+
+	tmpPath, err := u.download(ctx, release.Version, &asset)
+	if err != nil {
+		logger.Error("Update download failed", "error", err)
+		return err
+	}
+	defer func() {
+		// No-op once the binary has been moved into place
+		_ = os.Remove(tmpPath)
+	}()
+
+	if err := u.swap(tmpPath); err != nil {
+		logger.Error("Failed to replace binary", "error", err)
+		return err
+	}
+
+	pending := &PendingUpdate{
+		FromVersion: u.version,
+		ToVersion:   release.Version,
+		BackupPath:  u.executable + BackupSuffix,
+		AppliedAt:   time.Now(),
+	}
+	if err := savePending(u.executable, pending); err != nil {
+		// Without the marker the health check cannot roll back, so undo the swap
+		logger.Error("Failed to record pending update, restoring previous binary", "error", err)
+		if rbErr := os.Rename(pending.BackupPath, u.executable); rbErr != nil {
+			logger.Error("Failed to restore previous binary", "error", rbErr)
+		}
+		return err
+	}
+
+	logger.Info("Update installed, restart required", "executable", u.executable)
+	return nil
+}
+
+// download fetches the asset of a release version into a temporary file next to the
+// executable and verifies its checksum and signature. It returns the path of the verified file.
+func (u *Updater) download(ctx context.Context, version string, asset *Asset) (string, error) {
+	signature, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return "", ErrSignatureInvalid
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	body, err := u.get(ctx, u.httpClient, asset.URL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	// Create the file in the executable's directory so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(u.executable), ".birdnet-go-update-*")
+	if err != nil {
+		return "", errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_temp_file").
+			Build()
+	}
+	tmpPath := tmp.Name()
+
+	hasher := sha256.New()
+	written, copyErr := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(body, maxBinarySize+1))
+	closeErr := tmp.Close()
+	if copyErr == nil && written > maxBinarySize {
+		copyErr = fmt.Errorf("binary exceeds maximum size of %d bytes", maxBinarySize)
+	}
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		_ = os.Remove(tmpPath)
+		return "", errors.New(copyErr).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "download_binary").
+			Build()
+	}
+
+	digest := hasher.Sum(nil)
+	if asset.SHA256 != "" && !strings.EqualFold(asset.SHA256, hex.EncodeToString(digest)) {
+		_ = os.Remove(tmpPath)
+		return "", ErrChecksumMismatch
+	}
+	if !ed25519.Verify(u.publicKey, signedMessage(version, u.settings.Channel, u.platform, digest), signature) {
+		_ = os.Remove(tmpPath)
+		return "", ErrSignatureInvalid
+	}
+
+	return tmpPath, nil
+}
+
+// signedMessage returns the message signed for the binary of a release: the release
+// version, channel and platform and the SHA-256 digest of the binary
+func signedMessage(version, channel, platform string, digest []byte) []byte {
+	return fmt.Appendf(nil, "birdnet-go-release\nversion=%s\nchannel=%s\nplatform=%s\nsha256=%x\n",
+		version, channel, platform, digest)
+}
+
+// swap moves the verified binary into place, keeping the current binary as the backup
+func (u *Updater) swap(newPath string) error {
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(u.executable); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(newPath, mode); err != nil {
+		return errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "chmod_binary").
+			Build()
+	}
+
+	backupPath := u.executable + BackupSuffix
+	if err := os.Rename(u.executable, backupPath); err != nil {
+		return errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "backup_binary").
+			Build()
+	}
+	if err := os.Rename(newPath, u.executable); err != nil {
+		if rbErr := os.Rename(backupPath, u.executable); rbErr != nil {
+			updaterLogger.Error("Failed to restore previous binary", "error", rbErr)
+		}
+		return errors.New(err).
+			Component("updater").
+			Category(errors.CategoryFileIO).
+			Context("operation", "install_binary").
+			Build()
+	}
+
+	return nil
+}
+
+// isNewer reports whether candidate is a newer version than current. Versions are
+// compared as dotted numbers with an optional pre-release suffix. Versions that cannot be
+// parsed are never newer, as a downgrade could not be told apart from an upgrade.
+func isNewer(candidate, current string) bool {
+	a, okA := parseVersion(candidate)
+	b, okB := parseVersion(current)
+	if !okA || !okB {
+		return false
+	}
+
+	for i := range max(len(a.numbers), len(b.numbers)) {
+		var x, y int
+		if i < len(a.numbers) {
+			x = a.numbers[i]
+		}
+		if i < len(b.numbers) {
+			y = b.numbers[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+
+	// A release is newer than any of its pre-releases
+	switch {
+	case a.pre == b.pre:
+		return false
+	case a.pre == "":
+		return true
+	case b.pre == "":
+		return false
+	default:
+		return a.pre > b.pre
+	}
+}
+
+// version is a parsed release version
+type version struct {
+	numbers []int
+	pre     string
+}
+
+// parseVersion parses versions such as "v0.6.3" or "1.2.0-beta.1"
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	core, pre, _ := strings.Cut(s, "-")
+	if core == "" {
+		return version{}, false
+	}
+
+	parts := strings.Split(core, ".")
+	v := version{numbers: make([]int, 0, len(parts)), pre: pre}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.numbers = append(v.numbers, n)
+	}
+	return v, true
+}
@@ -0,0 +1,254 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+const testPlatform = "linux/amd64"
+
+// newTestUpdater creates an updater for a fake executable in a temporary directory
+func newTestUpdater(t *testing.T, publicKey ed25519.PublicKey, feedURL, version string) *Updater {
+	t.Helper()
+	executable := filepath.Join(t.TempDir(), "birdnet-go")
+	require.NoError(t, os.WriteFile(executable, []byte("old binary"), 0o755))
+
+	return &Updater{
+		settings: conf.UpdateSettings{
+			Enabled:       true,
+			Channel:       conf.UpdateChannelStable,
+			FeedURL:       feedURL,
+			HealthTimeout: 1,
+		},
+		version:     version,
+		publicKey:   publicKey,
+		httpClient:  &http.Client{},
+		localClient: &http.Client{},
+		executable:  executable,
+		platform:    testPlatform,
+		inContainer: func() bool { return false },
+	}
+}
+
+// signedRelease serves binary from a test server and returns a stable channel release
+// signed with key
+func signedRelease(t *testing.T, key ed25519.PrivateKey, version string, binary []byte) *Release {
+	t.Helper()
+	return signedReleaseFor(t, key, version, version, conf.UpdateChannelStable, binary)
+}
+
+// signedReleaseFor returns a release labelled version whose binary was signed for
+// signedVersion and channel
+func signedReleaseFor(t *testing.T, key ed25519.PrivateKey, version, signedVersion, channel string, binary []byte) *Release {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(binary)
+	}))
+	t.Cleanup(server.Close)
+
+	digest := sha256.Sum256(binary)
+	return &Release{
+		Version: version,
+		Assets: map[string]Asset{
+			testPlatform: {
+				URL:       server.URL,
+				SHA256:    hex.EncodeToString(digest[:]),
+				Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, signedMessage(signedVersion, channel, testPlatform, digest[:]))),
+			},
+		},
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		candidate, current string
+		want               bool
+	}{
+		{"v0.7.0", "v0.6.3", true},
+		{"v0.6.3", "v0.6.3", false},
+		{"v0.6.2", "v0.6.3", false},
+		{"0.10.0", "v0.9.9", true},
+		{"v1.0.0", "v1.0.0-beta.1", true},
+		{"v1.0.0-beta.2", "v1.0.0-beta.1", true},
+		{"v1.0.0-beta.1", "v1.0.0", false},
+		{"v1.0.0", "Development Build", false},
+		{"nightly-20250601", "v0.6.3", false},
+		{"", "Development Build", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.candidate+" vs "+tt.current, func(t *testing.T) {
+			assert.Equal(t, tt.want, isNewer(tt.candidate, tt.current))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	feed := Feed{Channels: map[string]Release{
+		conf.UpdateChannelStable: {Version: "v0.7.0", Assets: map[string]Asset{testPlatform: {URL: "https://example.com/bin"}}},
+		conf.UpdateChannelBeta:   {Version: "v0.8.0-beta.1", Assets: map[string]Asset{"linux/arm64": {URL: "https://example.com/bin"}}},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(feed)
+	}))
+	defer server.Close()
+
+	u := newTestUpdater(t, nil, server.URL, "v0.6.3")
+
+	result, err := u.Check(t.Context())
+	require.NoError(t, err)
+	assert.True(t, result.Available)
+	require.NotNil(t, result.Latest)
+	assert.Equal(t, "v0.7.0", result.Latest.Version)
+
+	// The beta release has no binary for this platform
+	u.settings.Channel = conf.UpdateChannelBeta
+	result, err = u.Check(t.Context())
+	require.NoError(t, err)
+	assert.False(t, result.Available)
+}
+
+func TestApply(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	t.Run("installs signed binary", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		require.NoError(t, u.Apply(t.Context(), signedRelease(t, privateKey, "v0.7.0", []byte("new binary"))))
+
+		data, err := os.ReadFile(u.executable)
+		require.NoError(t, err)
+		assert.Equal(t, "new binary", string(data))
+
+		backup, err := os.ReadFile(u.executable + BackupSuffix)
+		require.NoError(t, err)
+		assert.Equal(t, "old binary", string(backup))
+
+		pending, found, err := loadPending(u.executable)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, "v0.6.3", pending.FromVersion)
+		assert.Equal(t, "v0.7.0", pending.ToVersion)
+	})
+
+	t.Run("rejects binary signed with another key", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		err := u.Apply(t.Context(), signedRelease(t, otherKey, "v0.7.0", []byte("new binary")))
+		require.ErrorIs(t, err, ErrSignatureInvalid)
+
+		data, err := os.ReadFile(u.executable)
+		require.NoError(t, err)
+		assert.Equal(t, "old binary", string(data))
+	})
+
+	t.Run("rejects binary signed for an older version", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		err := u.Apply(t.Context(), signedReleaseFor(t, privateKey, "v0.7.0", "v0.6.0", conf.UpdateChannelStable, []byte("old binary")))
+		require.ErrorIs(t, err, ErrSignatureInvalid)
+	})
+
+	t.Run("rejects binary signed for another channel", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		err := u.Apply(t.Context(), signedReleaseFor(t, privateKey, "v0.7.0", "v0.7.0", conf.UpdateChannelBeta, []byte("beta binary")))
+		require.ErrorIs(t, err, ErrSignatureInvalid)
+	})
+
+	t.Run("rejects checksum mismatch", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		release := signedRelease(t, privateKey, "v0.7.0", []byte("new binary"))
+		asset := release.Assets[testPlatform]
+		asset.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+		release.Assets[testPlatform] = asset
+		require.ErrorIs(t, u.Apply(t.Context(), release), ErrChecksumMismatch)
+	})
+
+	t.Run("refuses older release", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.7.0")
+		err := u.Apply(t.Context(), signedRelease(t, privateKey, "v0.6.3", []byte("old")))
+		require.ErrorIs(t, err, ErrNoUpdateAvailable)
+	})
+
+	t.Run("refuses container install", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		u.inContainer = func() bool { return true }
+		err := u.Apply(t.Context(), signedRelease(t, privateKey, "v0.7.0", []byte("new binary")))
+		require.ErrorIs(t, err, ErrContainerInstall)
+	})
+}
+
+func TestVerifyPendingUpdate(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	// install applies an update and returns an updater running the new version
+	install := func(t *testing.T) *Updater {
+		t.Helper()
+		u := newTestUpdater(t, publicKey, "", "v0.6.3")
+		require.NoError(t, u.Apply(t.Context(), signedRelease(t, privateKey, "v0.7.0", []byte("new binary"))))
+		u.version = "v0.7.0"
+		return u
+	}
+
+	t.Run("healthy update is confirmed", func(t *testing.T) {
+		u := install(t)
+		health := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer health.Close()
+
+		rolledBack, err := u.VerifyPendingUpdate(t.Context(), health.URL)
+		require.NoError(t, err)
+		assert.False(t, rolledBack)
+
+		_, found, err := loadPending(u.executable)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("unhealthy update is rolled back", func(t *testing.T) {
+		u := install(t)
+		health := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer health.Close()
+
+		rolledBack, err := u.VerifyPendingUpdate(t.Context(), health.URL)
+		require.NoError(t, err)
+		assert.True(t, rolledBack)
+
+		data, err := os.ReadFile(u.executable)
+		require.NoError(t, err)
+		assert.Equal(t, "old binary", string(data))
+	})
+
+	t.Run("repeated failed starts are rolled back", func(t *testing.T) {
+		u := install(t)
+		pending, _, err := loadPending(u.executable)
+		require.NoError(t, err)
+		pending.Attempts = maxStartAttempts
+		require.NoError(t, savePending(u.executable, pending))
+
+		rolledBack, err := u.VerifyPendingUpdate(t.Context(), "http://127.0.0.1:0")
+		require.NoError(t, err)
+		assert.True(t, rolledBack)
+	})
+
+	t.Run("no pending update", func(t *testing.T) {
+		u := newTestUpdater(t, publicKey, "", "v0.7.0")
+		rolledBack, err := u.VerifyPendingUpdate(t.Context(), "http://127.0.0.1:0")
+		require.NoError(t, err)
+		assert.False(t, rolledBack)
+	})
+}
@@ -57,6 +57,38 @@ var OpenWeatherToIcon = map[string]IconCode{
 	"50n": IconFog,
 }
 
+// OpenMeteoToIcon maps WMO weather interpretation codes used by Open-Meteo to standardized icon codes
+var OpenMeteoToIcon = map[string]IconCode{
+	"0":  IconClearSky,     // clear sky
+	"1":  IconFair,         // mainly clear
+	"2":  IconPartlyCloudy, // partly cloudy
+	"3":  IconCloudy,       // overcast
+	"45": IconFog,          // fog
+	"48": IconFog,          // depositing rime fog
+	"51": IconRain,         // drizzle
+	"53": IconRain,
+	"55": IconRain,
+	"56": IconSleet, // freezing drizzle
+	"57": IconSleet,
+	"61": IconRain, // rain
+	"63": IconRain,
+	"65": IconRain,
+	"66": IconSleet, // freezing rain
+	"67": IconSleet,
+	"71": IconSnow, // snow fall
+	"73": IconSnow,
+	"75": IconSnow,
+	"77": IconSnow,        // snow grains
+	"80": IconRainShowers, // rain showers
+	"81": IconRainShowers,
+	"82": IconRainShowers,
+	"85": IconSnow, // snow showers
+	"86": IconSnow,
+	"95": IconThunderstorm, // thunderstorm
+	"96": IconThunderstorm, // thunderstorm with hail
+	"99": IconThunderstorm,
+}
+
 // IconDescription maps standardized icon codes to human-readable descriptions
 var IconDescription = map[IconCode]string{
 	IconClearSky:     "Clear Sky",
@@ -83,6 +115,10 @@ func GetStandardIconCode(code, provider string) IconCode {
 		if iconCode, ok := OpenWeatherToIcon[code]; ok {
 			return iconCode
 		}
+	case "openmeteo":
+		if iconCode, ok := OpenMeteoToIcon[code]; ok {
+			return iconCode
+		}
 	}
 	// Return Unknown if no mapping found
 	weatherLogger.Warn("No standard icon mapping found for provider code", "provider", provider, "code", code)
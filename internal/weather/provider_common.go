@@ -1,6 +1,12 @@
 package weather
 
-import "time"
+import (
+	"net/http"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
+)
 
 const (
 	RequestTimeout = 10 * time.Second
@@ -8,3 +14,26 @@ const (
 	RetryDelay     = 2 * time.Second
 	MaxRetries     = 3
 )
+
+// NewHTTPClient returns the HTTP client for weather provider requests, using the shared
+// outbound proxy and TLS settings
+func NewHTTPClient(settings *conf.Settings) (*http.Client, error) {
+	return httpclient.New(settings, httpclient.Options{
+		Integration:        "weather",
+		Timeout:            RequestTimeout,
+		UserAgent:          UserAgent,
+		InsecureSkipVerify: settings.OutboundTLS.Weather.InsecureSkipVerify,
+	})
+}
+
+// newDefaultHTTPClient returns the HTTP client of providers created without one, which
+// ignores the outbound settings
+func newDefaultHTTPClient(timeout time.Duration) *http.Client {
+	// Creating a client without settings cannot fail
+	client, _ := httpclient.New(nil, httpclient.Options{
+		Integration: "weather",
+		Timeout:     timeout,
+		UserAgent:   UserAgent,
+	})
+	return client
+}
@@ -0,0 +1,273 @@
+// provider_mqtt.go: local weather sensor readings received over MQTT
+package weather
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	mqttProviderName     = "mqtt"
+	mqttClientIDPrefix   = "birdnet-go-weather"
+	mqttTestPollInterval = 100 * time.Millisecond
+)
+
+// SensorReading is the JSON payload a local weather sensor publishes. Only temperature
+// is required; the time defaults to the moment the message was received.
+type SensorReading struct {
+	Time          *time.Time `json:"time,omitempty"`
+	Temperature   *float64   `json:"temperature"`   // °C
+	Humidity      float64    `json:"humidity"`      // %
+	Pressure      float64    `json:"pressure"`      // hPa
+	WindSpeed     float64    `json:"windSpeed"`     // m/s
+	WindDirection float64    `json:"windDirection"` // degrees
+	WindGust      float64    `json:"windGust"`      // m/s
+	Precipitation float64    `json:"precipitation"` // mm in the last hour
+	// Solar radiation in W/m², used to infer cloud cover when present
+	SolarRadiation *float64 `json:"solarRadiation,omitempty"`
+}
+
+// MQTTProvider implements the Provider interface by subscribing to the topic of a local
+// weather sensor and returning the most recent reading on each poll
+type MQTTProvider struct {
+	mu         sync.Mutex
+	client     mqtt.Client
+	latest     *WeatherData
+	returned   time.Time  // time of the last reading handed out by FetchWeather
+	subscribed chan error // result of the latest subscription to the sensor topic
+}
+
+// NewMQTTProvider creates a new local sensor weather provider. The broker connection
+// is established on the first fetch.
+func NewMQTTProvider() *MQTTProvider {
+	return &MQTTProvider{}
+}
+
+// mqttClientID returns a client ID unique to one connection. Brokers drop the session of a
+// client when another connects with the same ID, so the weather poller and a settings test
+// must not share one.
+func mqttClientID(nodeName string) string {
+	var suffix [4]byte
+	_, _ = rand.Read(suffix[:])
+	if nodeName == "" {
+		return fmt.Sprintf("%s-%s", mqttClientIDPrefix, hex.EncodeToString(suffix[:]))
+	}
+	return fmt.Sprintf("%s-%s-%s", mqttClientIDPrefix, nodeName, hex.EncodeToString(suffix[:]))
+}
+
+// FetchWeather implements the Provider interface for MQTTProvider
+func (p *MQTTProvider) FetchWeather(settings *conf.Settings) (*WeatherData, error) {
+	if err := p.connect(settings); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.latest == nil {
+		return nil, errors.New(fmt.Errorf("no reading received from weather sensor yet")).
+			Component("weather").
+			Category(errors.CategoryNotFound).
+			Context("provider", mqttProviderName).
+			Context("topic", settings.Realtime.Weather.MQTT.Topic).
+			Build()
+	}
+	if !p.latest.Time.After(p.returned) {
+		return nil, ErrWeatherDataNotModified
+	}
+
+	p.returned = p.latest.Time
+	data := *p.latest
+	data.Location = Location{
+		Latitude:  settings.BirdNET.Latitude,
+		Longitude: settings.BirdNET.Longitude,
+	}
+	return &data, nil
+}
+
+// TestConnection connects to the broker and subscribes to the sensor topic, then waits up
+// to wait for a reading, such as one the broker retained. A working connection without a
+// reading in that time returns nil data and no error, as sensors may publish rarely.
+func (p *MQTTProvider) TestConnection(ctx context.Context, settings *conf.Settings, wait time.Duration) (*WeatherData, error) {
+	if err := p.connect(settings); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	subscribed := p.subscribed
+	p.mu.Unlock()
+	select {
+	case err := <-subscribed:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(mqttTestPollInterval)
+	defer ticker.Stop()
+	for {
+		p.mu.Lock()
+		latest := p.latest
+		p.mu.Unlock()
+		if latest != nil {
+			data := *latest
+			return &data, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close disconnects from the broker
+func (p *MQTTProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		p.client.Disconnect(250)
+		p.client = nil
+	}
+	return nil
+}
+
+// connect establishes the broker connection and subscription if not already done
+func (p *MQTTProvider) connect(allSettings *conf.Settings) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return nil
+	}
+
+	settings := &allSettings.Realtime.Weather.MQTT
+	clientID := mqttClientID(allSettings.Main.Name)
+	logger := weatherLogger.With("provider", mqttProviderName, "client_id", clientID)
+
+	subscribed := make(chan error, 1)
+	p.subscribed = subscribed
+
+	opts := mqtt.NewClientOptions()
+	opts.AddBroker(settings.Broker)
+	opts.SetClientID(clientID)
+	opts.SetUsername(settings.Username)
+	opts.SetPassword(settings.Password)
+	opts.SetAutoReconnect(true)
+	opts.SetConnectTimeout(RequestTimeout)
+	// Subscribe in the connect handler so the subscription is restored after reconnects
+	opts.SetOnConnectHandler(func(c mqtt.Client) {
+		token := c.Subscribe(settings.Topic, 0, func(_ mqtt.Client, msg mqtt.Message) {
+			p.handleMessage(msg.Payload(), time.Now())
+		})
+		var err error
+		if !token.WaitTimeout(RequestTimeout) {
+			err = fmt.Errorf("subscription to %s timed out", settings.Topic)
+		} else {
+			err = token.Error()
+		}
+		if err != nil {
+			logger.Error("Failed to subscribe to weather sensor topic", "topic", settings.Topic, "error", err)
+			err = errors.New(err).
+				Component("weather").
+				Category(errors.CategoryNetwork).
+				Context("operation", "mqtt_subscribe").
+				Context("provider", mqttProviderName).
+				Context("topic", settings.Topic).
+				Build()
+		} else {
+			logger.Info("Subscribed to weather sensor topic", "topic", settings.Topic)
+		}
+
+		// Keep only the latest result, a reconnect replaces one nobody has read
+		select {
+		case <-subscribed:
+		default:
+		}
+		select {
+		case subscribed <- err:
+		default:
+		}
+	})
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(RequestTimeout) || token.Error() != nil {
+		err := token.Error()
+		if err == nil {
+			err = fmt.Errorf("connection to %s timed out", settings.Broker)
+		}
+		logger.Error("Failed to connect to weather sensor broker", "error", err)
+		return errors.New(err).
+			Component("weather").
+			Category(errors.CategoryNetwork).
+			Context("operation", "mqtt_connect").
+			Context("provider", mqttProviderName).
+			Build()
+	}
+
+	p.client = client
+	return nil
+}
+
+// handleMessage parses a sensor reading and stores it as the latest weather data
+func (p *MQTTProvider) handleMessage(payload []byte, received time.Time) {
+	var reading SensorReading
+	if err := json.Unmarshal(payload, &reading); err != nil || reading.Temperature == nil {
+		weatherLogger.Warn("Ignoring invalid weather sensor reading", "provider", mqttProviderName, "error", err)
+		return
+	}
+
+	readingTime := received
+	if reading.Time != nil && !reading.Time.IsZero() {
+		readingTime = *reading.Time
+	}
+
+	data := &WeatherData{
+		Time: readingTime,
+		Temperature: Temperature{
+			Current: *reading.Temperature,
+		},
+		Wind: Wind{
+			Speed: reading.WindSpeed,
+			Deg:   int(reading.WindDirection),
+			Gust:  reading.WindGust,
+		},
+		Precipitation: Precipitation{
+			Amount: reading.Precipitation,
+		},
+		Pressure: int(reading.Pressure),
+		Humidity: int(reading.Humidity),
+	}
+
+	// Local sensors do not report conditions, infer them from the measurements like for
+	// personal weather stations. Cloud cover can only be inferred from solar radiation.
+	if reading.SolarRadiation != nil || reading.Precipitation > 0 {
+		solarRadiation := DayClearSRThreshold
+		if reading.SolarRadiation != nil {
+			solarRadiation = *reading.SolarRadiation
+		}
+		iconCode := InferWundergroundIcon(data.Temperature.Current, reading.Precipitation, reading.Humidity, solarRadiation, reading.WindGust)
+		data.Icon = string(iconCode)
+		data.Description = IconDescription[iconCode]
+	}
+
+	p.mu.Lock()
+	p.latest = data
+	p.mu.Unlock()
+}
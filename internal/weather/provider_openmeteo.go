@@ -0,0 +1,179 @@
+// provider_openmeteo.go: Open-Meteo integration for BirdNET-Go
+package weather
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	OpenMeteoBaseURL      = "https://api.open-meteo.com/v1/forecast"
+	openMeteoProviderName = "openmeteo"
+
+	// openMeteoCurrentFields lists the current conditions requested from the API
+	openMeteoCurrentFields = "temperature_2m,relative_humidity_2m,apparent_temperature,precipitation,rain,snowfall," +
+		"weather_code,cloud_cover,pressure_msl,wind_speed_10m,wind_direction_10m,wind_gusts_10m"
+)
+
+// OpenMeteoProvider implements the Provider interface for Open-Meteo, which requires no API key
+type OpenMeteoProvider struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewOpenMeteoProvider creates a new Open-Meteo weather provider
+func NewOpenMeteoProvider(client *http.Client) Provider {
+	if client == nil {
+		client = newDefaultHTTPClient(RequestTimeout)
+	}
+	return &OpenMeteoProvider{
+		httpClient: client,
+		baseURL:    OpenMeteoBaseURL,
+	}
+}
+
+// OpenMeteoResponse represents the current conditions part of the Open-Meteo forecast response
+type OpenMeteoResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Current   struct {
+		Time                int64   `json:"time"`
+		Temperature         float64 `json:"temperature_2m"`
+		RelativeHumidity    float64 `json:"relative_humidity_2m"`
+		ApparentTemperature float64 `json:"apparent_temperature"`
+		Precipitation       float64 `json:"precipitation"`
+		Rain                float64 `json:"rain"`
+		Snowfall            float64 `json:"snowfall"`
+		WeatherCode         int     `json:"weather_code"`
+		CloudCover          float64 `json:"cloud_cover"`
+		PressureMSL         float64 `json:"pressure_msl"`
+		WindSpeed           float64 `json:"wind_speed_10m"`
+		WindDirection       float64 `json:"wind_direction_10m"`
+		WindGusts           float64 `json:"wind_gusts_10m"`
+	} `json:"current"`
+}
+
+// FetchWeather implements the Provider interface for OpenMeteoProvider
+func (p *OpenMeteoProvider) FetchWeather(settings *conf.Settings) (*WeatherData, error) {
+	query := url.Values{}
+	query.Set("latitude", fmt.Sprintf("%.3f", settings.BirdNET.Latitude))
+	query.Set("longitude", fmt.Sprintf("%.3f", settings.BirdNET.Longitude))
+	query.Set("current", openMeteoCurrentFields)
+	query.Set("wind_speed_unit", "ms")
+	query.Set("timeformat", "unixtime")
+	requestURL := p.baseURL + "?" + query.Encode()
+
+	logger := weatherLogger.With("provider", openMeteoProviderName)
+	logger.Info("Fetching weather data", "url", requestURL)
+
+	var response OpenMeteoResponse
+	for i := 0; i < MaxRetries; i++ {
+		body, statusCode, err := p.doRequest(requestURL)
+		if err == nil && statusCode == http.StatusOK {
+			if err := json.Unmarshal(body, &response); err != nil {
+				logger.Error("Failed to unmarshal response JSON", "error", err)
+				return nil, errors.New(err).
+					Component("weather").
+					Category(errors.CategoryValidation).
+					Context("operation", "unmarshal_weather_data").
+					Context("provider", openMeteoProviderName).
+					Build()
+			}
+			break
+		}
+
+		if err == nil {
+			err = fmt.Errorf("received non-OK response (%d)", statusCode)
+		}
+		logger.Warn("Weather request failed", "attempt", i+1, "max_attempts", MaxRetries, "error", err)
+		if i == MaxRetries-1 {
+			return nil, errors.New(err).
+				Component("weather").
+				Category(errors.CategoryNetwork).
+				Context("operation", "weather_api_request").
+				Context("provider", openMeteoProviderName).
+				Context("max_retries", fmt.Sprintf("%d", MaxRetries)).
+				Build()
+		}
+		time.Sleep(RetryDelay)
+	}
+
+	if response.Current.Time == 0 {
+		return nil, errors.New(fmt.Errorf("no current weather data in response")).
+			Component("weather").
+			Category(errors.CategoryValidation).
+			Context("operation", "validate_weather_response").
+			Context("provider", openMeteoProviderName).
+			Build()
+	}
+
+	current := response.Current
+	iconCode := GetStandardIconCode(strconv.Itoa(current.WeatherCode), openMeteoProviderName)
+
+	precipitationType := ""
+	switch {
+	case current.Snowfall > 0:
+		precipitationType = "snow"
+	case current.Rain > 0:
+		precipitationType = "rain"
+	}
+
+	data := &WeatherData{
+		Time: time.Unix(current.Time, 0),
+		Location: Location{
+			Latitude:  settings.BirdNET.Latitude,
+			Longitude: settings.BirdNET.Longitude,
+		},
+		Temperature: Temperature{
+			Current:   current.Temperature,
+			FeelsLike: current.ApparentTemperature,
+		},
+		Wind: Wind{
+			Speed: current.WindSpeed,
+			Deg:   int(current.WindDirection),
+			Gust:  current.WindGusts,
+		},
+		Precipitation: Precipitation{
+			Amount: current.Precipitation,
+			Type:   precipitationType,
+		},
+		Clouds:      int(current.CloudCover),
+		Pressure:    int(current.PressureMSL),
+		Humidity:    int(current.RelativeHumidity),
+		Description: IconDescription[iconCode],
+		Icon:        string(iconCode),
+	}
+
+	logger.Debug("Mapped API response to WeatherData structure", "time", data.Time, "temp", data.Temperature.Current)
+	return data, nil
+}
+
+// doRequest performs a single GET request and returns the response body and status code
+func (p *OpenMeteoProvider) doRequest(requestURL string) (body []byte, statusCode int, err error) {
+	req, err := http.NewRequest("GET", requestURL, http.NoBody)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			weatherLogger.Debug("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err = io.ReadAll(resp.Body)
+	return body, resp.StatusCode, err
+}
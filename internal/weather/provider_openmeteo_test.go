@@ -0,0 +1,72 @@
+package weather
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestOpenMeteoFetchWeather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "60.170", r.URL.Query().Get("latitude"))
+		assert.Equal(t, "ms", r.URL.Query().Get("wind_speed_unit"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"latitude":60.17,"longitude":24.94,"current":{"time":1717243200,
+			"temperature_2m":14.2,"relative_humidity_2m":71,"apparent_temperature":13.1,"precipitation":0.6,
+			"rain":0.6,"snowfall":0,"weather_code":61,"cloud_cover":88,"pressure_msl":1008.4,
+			"wind_speed_10m":3.4,"wind_direction_10m":225,"wind_gusts_10m":7.9}}`))
+	}))
+	defer server.Close()
+
+	provider := &OpenMeteoProvider{httpClient: server.Client(), baseURL: server.URL}
+	settings := &conf.Settings{}
+	settings.BirdNET.Latitude = 60.17
+	settings.BirdNET.Longitude = 24.94
+
+	data, err := provider.FetchWeather(settings)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1717243200, 0), data.Time)
+	assert.InDelta(t, 14.2, data.Temperature.Current, 0.0001)
+	assert.InDelta(t, 3.4, data.Wind.Speed, 0.0001)
+	assert.Equal(t, 225, data.Wind.Deg)
+	assert.InDelta(t, 0.6, data.Precipitation.Amount, 0.0001)
+	assert.Equal(t, "rain", data.Precipitation.Type)
+	assert.Equal(t, 1008, data.Pressure)
+	assert.Equal(t, string(IconRain), data.Icon)
+	assert.Equal(t, "Rain", data.Description)
+}
+
+func TestMQTTProviderHandleMessage(t *testing.T) {
+	provider := &MQTTProvider{}
+	received := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	provider.handleMessage([]byte(`{"humidity": 80}`), received)
+	assert.Nil(t, provider.latest, "readings without temperature should be ignored")
+
+	provider.handleMessage([]byte(`not json`), received)
+	assert.Nil(t, provider.latest)
+
+	provider.handleMessage([]byte(`{"temperature": 3.5, "windSpeed": 2.1, "precipitation": 1.4}`), received)
+	require.NotNil(t, provider.latest)
+	assert.Equal(t, received, provider.latest.Time)
+	assert.InDelta(t, 3.5, provider.latest.Temperature.Current, 0.0001)
+	assert.InDelta(t, 1.4, provider.latest.Precipitation.Amount, 0.0001)
+	assert.Equal(t, string(IconRain), provider.latest.Icon)
+
+	provider.handleMessage([]byte(`{"time": "2024-06-01T11:55:00Z", "temperature": 4}`), received)
+	assert.Equal(t, time.Date(2024, 6, 1, 11, 55, 0, 0, time.UTC), provider.latest.Time)
+	assert.Empty(t, provider.latest.Icon, "conditions are not inferred without precipitation or solar radiation")
+}
+
+func TestMQTTClientIDIsUnique(t *testing.T) {
+	first := mqttClientID("garden")
+	assert.True(t, strings.HasPrefix(first, "birdnet-go-weather-garden-"), first)
+	assert.NotEqual(t, first, mqttClientID("garden"), "every connection needs its own client ID")
+	assert.True(t, strings.HasPrefix(mqttClientID(""), "birdnet-go-weather-"))
+}
@@ -79,8 +79,10 @@ func (p *OpenWeatherProvider) FetchWeather(settings *conf.Settings) (*WeatherDat
 	logger := weatherLogger.With("provider", openWeatherProviderName)
 	logger.Info("Fetching weather data", "url", safeURL)
 
-	client := &http.Client{
-		Timeout: RequestTimeout,
+	client, err := NewHTTPClient(settings)
+	if err != nil {
+		logger.Error("Failed to configure HTTP client", "error", err)
+		return nil, err
 	}
 
 	req, err := http.NewRequest("GET", apiURL, http.NoBody)
@@ -61,8 +61,10 @@ func (p *YrNoProvider) FetchWeather(settings *conf.Settings) (*WeatherData, erro
 	logger := weatherLogger.With("provider", yrNoProviderName)
 	logger.Info("Fetching weather data", "url", url)
 
-	client := &http.Client{
-		Timeout: RequestTimeout,
+	client, err := NewHTTPClient(settings)
+	if err != nil {
+		logger.Error("Failed to configure HTTP client", "error", err)
+		return nil, err
 	}
 
 	req, err := http.NewRequest("GET", url, http.NoBody)
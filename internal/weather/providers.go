@@ -24,9 +24,7 @@ type OpenWeatherProvider struct{}
 // NewWundergroundProvider creates a new WeatherUnderground provider with shared HTTP client
 func NewWundergroundProvider(client *http.Client) Provider {
 	if client == nil {
-		client = &http.Client{
-			Timeout: 30 * time.Second,
-		}
+		client = newDefaultHTTPClient(30 * time.Second)
 	}
 	return &WundergroundProvider{
 		httpClient: client,
@@ -96,6 +96,11 @@ type Precipitation struct {
 func NewService(settings *conf.Settings, db datastore.Interface, weatherMetrics *metrics.WeatherMetrics) (*Service, error) {
 	var provider Provider
 
+	client, err := NewHTTPClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Select weather provider based on configuration
 	switch settings.Realtime.Weather.Provider {
 	case "yrno":
@@ -103,7 +108,11 @@ func NewService(settings *conf.Settings, db datastore.Interface, weatherMetrics
 	case "openweather":
 		provider = NewOpenWeatherProvider()
 	case "wunderground":
-		provider = NewWundergroundProvider(nil)
+		provider = NewWundergroundProvider(client)
+	case "openmeteo":
+		provider = NewOpenMeteoProvider(client)
+	case "mqtt":
+		provider = NewMQTTProvider()
 	default:
 		return nil, errors.New(fmt.Errorf("invalid weather provider: %s", settings.Realtime.Weather.Provider)).
 			Component("weather").
@@ -169,6 +178,7 @@ func (s *Service) SaveWeatherData(data *WeatherData) error {
 		WindSpeed:     data.Wind.Speed,
 		WindDeg:       data.Wind.Deg,
 		WindGust:      data.Wind.Gust,
+		Precipitation: data.Precipitation.Amount,
 		Clouds:        data.Clouds,
 		WeatherDesc:   data.Description,
 		WeatherIcon:   data.Icon,
@@ -253,6 +263,12 @@ func (s *Service) StartPolling(stopChan <-chan struct{}) {
 			}
 		case <-stopChan:
 			weatherLogger.Info("Stopping weather polling service")
+			// Release provider connections, such as the local sensor MQTT subscription
+			if closer, ok := s.provider.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					weatherLogger.Warn("Failed to close weather provider", "error", err)
+				}
+			}
 			return
 		}
 	}
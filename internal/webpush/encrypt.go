@@ -0,0 +1,100 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Sizes of the aes128gcm content coding (RFC 8188) of push messages
+const (
+	saltSize      = 16
+	recordSize    = 4096
+	keySize       = 65 // uncompressed P-256 point
+	headerSize    = saltSize + 4 + 1 + keySize
+	tagSize       = 16
+	paddingMarker = 0x02 // delimiter of the last and only record
+
+	// maxPayloadSize is the largest payload fitting the 4096 bytes push services accept
+	maxPayloadSize = recordSize - headerSize - tagSize - 1
+)
+
+// encrypt encrypts a payload for a subscription as a single aes128gcm record (RFC 8291),
+// with a new ephemeral key and salt for every message
+func encrypt(payload []byte, p256dh, auth string) ([]byte, error) {
+	if len(payload) > maxPayloadSize {
+		return nil, fmt.Errorf("push payload of %d bytes exceeds %d bytes", len(payload), maxPayloadSize)
+	}
+
+	uaPublicBytes, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key of subscription: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key of subscription: %w", err)
+	}
+	authSecret, err := decodeKey(auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret of subscription: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement failed: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	// The input keying material combines the shared secret with the auth secret of the browser
+	keyInfo := "WebPush: info\x00" + string(uaPublicBytes) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, 0, headerSize+len(payload)+1+tagSize)
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, recordSize)
+	body = append(body, byte(len(asPublic)))
+	body = append(body, asPublic...)
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), paddingMarker)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+// decodeKey decodes a base64url key of a subscription, with or without padding
+func decodeKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
@@ -0,0 +1,93 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// vapidTokenLifetime is the validity of VAPID tokens, push services reject more than 24 hours
+const vapidTokenLifetime = 12 * time.Hour
+
+// GenerateVAPIDKeys creates a VAPID key pair: the uncompressed P-256 public key and the
+// private key, base64url encoded without padding as used by browsers
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	private, err := key.Bytes()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode VAPID private key: %w", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode VAPID public key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(public), base64.RawURLEncoding.EncodeToString(private), nil
+}
+
+// vapidKey is the key pair identifying the server to push services (RFC 8292)
+type vapidKey struct {
+	private   *ecdsa.PrivateKey
+	publicKey string // base64url encoded public key sent with each request
+}
+
+// parseVAPIDKeys decodes a VAPID key pair and checks that the keys belong together
+func parseVAPIDKeys(publicKey, privateKey string) (*vapidKey, error) {
+	private, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key encoding: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), private)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	if base64.RawURLEncoding.EncodeToString(public) != publicKey {
+		return nil, fmt.Errorf("VAPID public key does not match the private key")
+	}
+	return &vapidKey{private: key, publicKey: publicKey}, nil
+}
+
+// authorization returns the Authorization header of a push request to endpoint: a signed
+// JWT for the origin of the push service and the public key
+func (k *vapidKey) authorization(endpoint, subject string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTokenLifetime).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString(claims)
+
+	// ES256 signatures are the fixed size concatenation of r and s
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, k.private, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return "vapid t=" + token + ", k=" + k.publicKey, nil
+}
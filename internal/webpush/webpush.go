@@ -0,0 +1,381 @@
+// Package webpush sends detection notifications to browsers with the Web Push protocol, so
+// dashboards installed as PWAs receive them on phones through the push service of the
+// browser, without third-party notification services. Messages are encrypted for each
+// subscription (RFC 8291) and signed with the VAPID key of the server (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
+	"github.com/tphakala/birdnet-go/internal/logging"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// pushTimeout bounds a single request to a push service
+const pushTimeout = 15 * time.Second
+
+var logger *slog.Logger
+
+func init() {
+	logger = logging.ForService("webpush")
+	if logger == nil {
+		logger = slog.Default().With("service", "webpush")
+	}
+}
+
+// Errors of push deliveries and of the package functions
+var (
+	// ErrSubscriptionGone is returned when the push service no longer knows a subscription,
+	// e.g. because the user revoked the notification permission
+	ErrSubscriptionGone = errors.NewStd("push subscription expired or unsubscribed")
+	// ErrNotEnabled is returned when Web Push notifications are not enabled
+	ErrNotEnabled = errors.NewStd("web push notifications are not enabled")
+)
+
+// Payload is the JSON message delivered to the service worker of the dashboard, which shows
+// it with showNotification
+type Payload struct {
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	Tag       string      `json:"tag,omitempty"` // replaces the shown notification with the same tag
+	Timestamp int64       `json:"timestamp"`     // Unix milliseconds of the notification
+	Data      PayloadData `json:"data"`
+}
+
+// PayloadData is the detection the notification is about
+type PayloadData struct {
+	NotificationID string  `json:"notificationId,omitempty"`
+	Species        string  `json:"species,omitempty"`
+	ScientificName string  `json:"scientificName,omitempty"`
+	Confidence     float64 `json:"confidence,omitempty"`
+}
+
+// Sender pushes the detection notifications of the notification service to the stored
+// subscriptions whose species filter matches, as the notification preferences of their
+// users allow
+type Sender struct {
+	store  datastore.WebPushStore
+	router *notification.Router
+	client *http.Client
+
+	mu       sync.RWMutex
+	settings conf.WebPushSettings
+	key      *vapidKey
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newSender creates a sender with the VAPID keys of the settings
+func newSender(settings *conf.WebPushSettings, router *notification.Router, store datastore.WebPushStore, client *http.Client) (*Sender, error) {
+	s := &Sender{store: store, router: router, client: client}
+	if err := s.update(settings); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// update applies changed settings, the VAPID keys included
+func (s *Sender) update(settings *conf.WebPushSettings) error {
+	key, err := parseVAPIDKeys(settings.PublicKey, settings.PrivateKey)
+	if err != nil {
+		return errors.New(err).
+			Component("webpush").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_vapid_keys").
+			Build()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings = *settings
+	s.key = key
+	return nil
+}
+
+// start pushes the detection notifications of a notification service until stopped
+func (s *Sender) start(service *notification.Service) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	ch, ctx := service.Subscribe()
+
+	go func() {
+		defer close(s.done)
+		defer service.Unsubscribe(ch)
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ctx.Done():
+				return
+			case notif := <-ch:
+				if notif != nil && notif.Type == notification.TypeDetection {
+					s.pushNotification(ctx, notif)
+				}
+			}
+		}
+	}()
+}
+
+// close stops pushing notifications, waiting for the delivery in progress
+func (s *Sender) close() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+}
+
+// pushNotification pushes a detection notification to the matching subscriptions
+func (s *Sender) pushNotification(ctx context.Context, notif *notification.Notification) {
+	subscriptions, err := s.store.GetWebPushSubscriptions()
+	if err != nil {
+		logger.Error("failed to get push subscriptions", "error", err)
+		return
+	}
+
+	payload := detectionPayload(notif)
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		if !matchesSpecies(sub, &payload.Data) ||
+			!s.router.Allows(sub.Username, conf.NotificationChannelPush, notif) {
+			continue
+		}
+		s.deliver(ctx, sub, payload)
+	}
+}
+
+// deliver sends a payload to a subscription and records the outcome. Subscriptions the push
+// service no longer knows are deleted.
+func (s *Sender) deliver(ctx context.Context, sub *datastore.WebPushSubscription, payload *Payload) {
+	err := s.Send(ctx, sub, payload)
+	switch {
+	case err == nil:
+		if err := s.store.RecordWebPushDelivery(sub.ID, true); err != nil {
+			logger.Warn("failed to record push delivery", "subscription_id", sub.ID, "error", err)
+		}
+	case errors.Is(err, ErrSubscriptionGone):
+		logger.Info("removing expired push subscription", "subscription_id", sub.ID, "name", sub.Name)
+		if err := s.store.DeleteWebPushSubscription(sub.ID); err != nil {
+			logger.Warn("failed to remove expired push subscription", "subscription_id", sub.ID, "error", err)
+		}
+	default:
+		logger.Warn("push delivery failed", "subscription_id", sub.ID, "name", sub.Name, "error", err)
+		if err := s.store.RecordWebPushDelivery(sub.ID, false); err != nil {
+			logger.Warn("failed to record push delivery", "subscription_id", sub.ID, "error", err)
+		}
+	}
+}
+
+// Send encrypts a payload for a subscription and sends it to its push service. It returns
+// ErrSubscriptionGone when the push service no longer knows the subscription.
+func (s *Sender) Send(ctx context.Context, sub *datastore.WebPushSubscription, payload *Payload) error {
+	s.mu.RLock()
+	settings, key := s.settings, s.key
+	s.mu.RUnlock()
+
+	message, err := encodePayload(payload)
+	if err != nil {
+		return err
+	}
+	body, err := encrypt(message, sub.P256dh, sub.Auth)
+	if err != nil {
+		return err
+	}
+	authorization, err := key.authorization(sub.Endpoint, settings.Subject, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid push endpoint: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(settings.TTL))
+	req.Header.Set("Urgency", settings.Urgency)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	default:
+		return fmt.Errorf("push service responded %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+}
+
+// SendTest sends a test notification to a subscription
+func (s *Sender) SendTest(ctx context.Context, sub *datastore.WebPushSubscription) error {
+	return s.Send(ctx, sub, &Payload{
+		Title:     "BirdNET-Go",
+		Body:      "Push notifications are working on this device",
+		Tag:       "test",
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
+
+// detectionPayload creates the payload of a detection notification
+func detectionPayload(notif *notification.Notification) *Payload {
+	payload := &Payload{
+		Title:     notif.Title,
+		Body:      notif.Message,
+		Timestamp: notif.Timestamp.UnixMilli(),
+		Data:      PayloadData{NotificationID: notif.ID},
+	}
+	payload.Data.Species, _ = notif.Metadata["species"].(string)
+	payload.Data.ScientificName, _ = notif.Metadata["scientific_name"].(string)
+	payload.Data.Confidence, _ = notif.Metadata["confidence"].(float64)
+	if payload.Data.ScientificName != "" {
+		payload.Tag = "detection-" + payload.Data.ScientificName
+	}
+	return payload
+}
+
+// encodePayload encodes a payload as JSON, shortening the body to fit a push message
+func encodePayload(payload *Payload) ([]byte, error) {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	shortened := *payload
+	body := []rune(payload.Body)
+	for len(message) > maxPayloadSize && len(body) > 0 {
+		// Every rune takes at least a byte, cutting the excess in runes shortens enough
+		// unless JSON escaping made the removed runes longer
+		cut := min(len(body), len(message)-maxPayloadSize+len("…"))
+		body = body[:len(body)-cut]
+		shortened.Body = string(body) + "…"
+		if message, err = json.Marshal(&shortened); err != nil {
+			return nil, err
+		}
+	}
+	return message, nil
+}
+
+// matchesSpecies reports whether the species filter of a subscription includes a detection,
+// by common or scientific name. Subscriptions without a filter receive every detection.
+func matchesSpecies(sub *datastore.WebPushSubscription, detection *PayloadData) bool {
+	species := sub.SpeciesList()
+	if len(species) == 0 {
+		return true
+	}
+	for _, name := range species {
+		if strings.EqualFold(name, detection.Species) || strings.EqualFold(name, detection.ScientificName) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	senderMu sync.Mutex
+	sender   *Sender
+)
+
+// Configure starts, updates or stops pushing notifications to match the settings. When push
+// is enabled without VAPID keys, a key pair is generated and saved to the configuration.
+func Configure(settings *conf.Settings, store datastore.WebPushStore) error {
+	senderMu.Lock()
+	defer senderMu.Unlock()
+
+	if !settings.WebPush.Enabled {
+		if sender != nil {
+			sender.close()
+			sender = nil
+			logger.Info("web push notifications stopped")
+		}
+		return nil
+	}
+	if store == nil {
+		return errors.Newf("datastore does not support push subscriptions").
+			Component("webpush").
+			Category(errors.CategoryConfiguration).
+			Build()
+	}
+
+	if settings.WebPush.PublicKey == "" || settings.WebPush.PrivateKey == "" {
+		publicKey, privateKey, err := GenerateVAPIDKeys()
+		if err != nil {
+			return errors.New(err).
+				Component("webpush").
+				Category(errors.CategorySystem).
+				Context("operation", "generate_vapid_keys").
+				Build()
+		}
+		settings.WebPush.PublicKey, settings.WebPush.PrivateKey = publicKey, privateKey
+		logger.Info("generated VAPID keys for web push notifications")
+		if err := conf.SaveSettings(); err != nil {
+			// The keys work until restart, subscriptions made until then are invalidated
+			logger.Warn("failed to save generated VAPID keys", "error", err)
+		}
+	}
+
+	if sender != nil {
+		return sender.update(&settings.WebPush)
+	}
+
+	service := notification.GetService()
+	if service == nil {
+		return errors.Newf("notification service not initialized").
+			Component("webpush").
+			Category(errors.CategorySystem).
+			Build()
+	}
+	client, err := httpclient.New(settings, httpclient.Options{Integration: "webpush", Timeout: pushTimeout})
+	if err != nil {
+		return err
+	}
+	s, err := newSender(&settings.WebPush, notification.NewRouter(settings), store, client)
+	if err != nil {
+		return err
+	}
+	s.start(service)
+	sender = s
+
+	logger.Info("web push notifications started",
+		"ttl", settings.WebPush.TTL,
+		"urgency", settings.WebPush.Urgency)
+	return nil
+}
+
+// GetSender returns the running sender, nil when push notifications are not enabled
+func GetSender() *Sender {
+	senderMu.Lock()
+	defer senderMu.Unlock()
+	return sender
+}
+
+// Shutdown stops pushing notifications
+func Shutdown() {
+	senderMu.Lock()
+	defer senderMu.Unlock()
+	if sender != nil {
+		sender.close()
+		sender = nil
+	}
+}
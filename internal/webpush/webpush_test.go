@@ -0,0 +1,324 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// browser is the key pair and auth secret of a browser push subscription
+type browser struct {
+	private *ecdh.PrivateKey
+	auth    []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	t.Helper()
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &browser{private: private, auth: auth}
+}
+
+func (b *browser) subscription(id uint, endpoint string) datastore.WebPushSubscription {
+	return datastore.WebPushSubscription{
+		ID:       id,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.private.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt decrypts a push message like a browser does
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	require.Greater(t, len(body), headerSize)
+	salt := body[:saltSize]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[saltSize:]))
+	require.Equal(t, byte(keySize), body[saltSize+4])
+	asPublicBytes := body[saltSize+5 : headerSize]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	require.NoError(t, err)
+	sharedSecret, err := b.private.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := "WebPush: info\x00" + string(b.private.PublicKey().Bytes()) + string(asPublicBytes)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, b.auth, keyInfo, 32)
+	require.NoError(t, err)
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[headerSize:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(paddingMarker), plaintext[len(plaintext)-1], "last record delimiter")
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+
+	b := newBrowser(t)
+	sub := b.subscription(1, "https://push.example.org/1")
+	body, err := encrypt([]byte(`{"title":"Tawny Owl"}`), sub.P256dh, sub.Auth+"==")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Tawny Owl"}`, string(b.decrypt(t, body)))
+
+	second, err := encrypt([]byte(`{"title":"Tawny Owl"}`), sub.P256dh, sub.Auth)
+	require.NoError(t, err)
+	assert.NotEqual(t, body[:headerSize], second[:headerSize], "every message has its own salt and key")
+
+	_, err = encrypt(make([]byte, maxPayloadSize+1), sub.P256dh, sub.Auth)
+	require.Error(t, err)
+	full, err := encrypt(make([]byte, maxPayloadSize), sub.P256dh, sub.Auth)
+	require.NoError(t, err)
+	assert.Len(t, full, recordSize)
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	key, err := parseVAPIDKeys(publicKey, privateKey)
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	_, err = parseVAPIDKeys(otherPublicKey, privateKey)
+	require.ErrorContains(t, err, "does not match")
+
+	now := time.Unix(1760000000, 0)
+	header, err := key.authorization("https://fcm.googleapis.com/fcm/send/abc", "mailto:admin@example.org", now)
+	require.NoError(t, err)
+	token, public, found := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	require.True(t, found)
+	assert.Equal(t, publicKey, public)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+	assert.Equal(t, "https://fcm.googleapis.com", claims.Aud)
+	assert.Equal(t, now.Add(vapidTokenLifetime).Unix(), claims.Exp)
+	assert.Equal(t, "mailto:admin@example.org", claims.Sub)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(&key.private.PublicKey, digest[:], r, s))
+}
+
+func TestEncodePayloadShortensBody(t *testing.T) {
+	t.Parallel()
+
+	message, err := encodePayload(&Payload{Title: "Detected: Eurasian Blackbird", Body: strings.Repeat("<ä>", 2000)})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(message), maxPayloadSize)
+	var payload Payload
+	require.NoError(t, json.Unmarshal(message, &payload))
+	assert.True(t, strings.HasSuffix(payload.Body, "…"))
+}
+
+// memoryStore keeps push subscriptions in memory
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions []datastore.WebPushSubscription
+	delivered     map[uint]int
+	failed        map[uint]int
+}
+
+func (m *memoryStore) GetWebPushSubscriptions() ([]datastore.WebPushSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]datastore.WebPushSubscription(nil), m.subscriptions...), nil
+}
+
+func (m *memoryStore) GetWebPushSubscription(id uint) (*datastore.WebPushSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.subscriptions {
+		if m.subscriptions[i].ID == id {
+			sub := m.subscriptions[i]
+			return &sub, nil
+		}
+	}
+	return nil, ErrSubscriptionGone
+}
+
+func (m *memoryStore) SaveWebPushSubscription(sub *datastore.WebPushSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions = append(m.subscriptions, *sub)
+	return nil
+}
+
+func (m *memoryStore) DeleteWebPushSubscription(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.subscriptions {
+		if m.subscriptions[i].ID == id {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSubscriptionGone
+}
+
+func (m *memoryStore) DeleteWebPushSubscriptions() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := int64(len(m.subscriptions))
+	m.subscriptions = nil
+	return count, nil
+}
+
+func (m *memoryStore) RecordWebPushDelivery(id uint, delivered bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if delivered {
+		m.delivered[id]++
+	} else {
+		m.failed[id]++
+	}
+	return nil
+}
+
+func (m *memoryStore) counts(id uint) (delivered, failed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.delivered[id], m.failed[id]
+}
+
+// pushRequest is a request received by the fake push service
+type pushRequest struct {
+	path    string
+	header  http.Header
+	payload Payload
+}
+
+func TestSenderPushesDetectionNotifications(t *testing.T) {
+	t.Parallel()
+
+	b := newBrowser(t)
+	requests := make(chan pushRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		if r.URL.Path == "/broken" {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload Payload
+		assert.NoError(t, json.Unmarshal(b.decrypt(t, body), &payload))
+		requests <- pushRequest{path: r.URL.Path, header: r.Header.Clone(), payload: payload}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	owls := b.subscription(1, server.URL+"/owls")
+	owls.SetSpeciesList([]string{"strix aluco"})
+	everything := b.subscription(2, server.URL+"/all")
+	blackbirds := b.subscription(3, server.URL+"/blackbirds")
+	blackbirds.SetSpeciesList([]string{"Eurasian Blackbird"})
+	dashboardOnly := b.subscription(6, server.URL+"/carol")
+	dashboardOnly.Username = "carol"
+	store := &memoryStore{
+		subscriptions: []datastore.WebPushSubscription{owls, everything, blackbirds, b.subscription(4, server.URL+"/gone"), b.subscription(5, server.URL+"/broken"), dashboardOnly},
+		delivered:     make(map[uint]int),
+		failed:        make(map[uint]int),
+	}
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	settings := &conf.WebPushSettings{Enabled: true, Subject: "mailto:admin@example.org", PublicKey: publicKey, PrivateKey: privateKey, TTL: 600, Urgency: "high"}
+	users := &conf.Settings{}
+	users.Security.Users = []conf.UserAccount{{Username: "carol", Role: conf.RoleViewer, Notifications: conf.UserNotificationSettings{
+		Channels: []string{conf.NotificationChannelDashboard},
+	}}}
+	s, err := newSender(settings, notification.NewRouter(users), store, server.Client())
+	require.NoError(t, err)
+
+	service := notification.NewService(notification.DefaultServiceConfig())
+	t.Cleanup(service.Stop)
+	s.start(service)
+
+	_, err = service.Create(notification.TypeSystem, notification.PriorityHigh, "Disk full", "not a detection")
+	require.NoError(t, err)
+	detection := notification.NewNotification(notification.TypeDetection, notification.PriorityHigh, "New Species Detected: Tawny Owl", "First detection of Tawny Owl").
+		WithMetadata("species", "Tawny Owl").
+		WithMetadata("scientific_name", "Strix aluco").
+		WithMetadata("confidence", 0.91)
+	require.NoError(t, service.CreateWithMetadata(detection))
+
+	received := map[string]pushRequest{}
+	for range 2 {
+		select {
+		case req := <-requests:
+			received[req.path] = req
+		case <-time.After(5 * time.Second):
+			t.Fatal("push not received")
+		}
+	}
+	s.close()
+
+	require.Contains(t, received, "/owls", "the species filter matches scientific names case-insensitively")
+	require.Contains(t, received, "/all", "subscriptions without a filter receive every detection")
+	assert.Empty(t, requests, "the blackbird subscription does not receive owls, carol does not want push notifications, system notifications are not pushed")
+
+	req := received["/owls"]
+	assert.Equal(t, "New Species Detected: Tawny Owl", req.payload.Title)
+	assert.Equal(t, "detection-Strix aluco", req.payload.Tag)
+	assert.Equal(t, PayloadData{NotificationID: detection.ID, Species: "Tawny Owl", ScientificName: "Strix aluco", Confidence: 0.91}, req.payload.Data)
+	assert.Equal(t, "aes128gcm", req.header.Get("Content-Encoding"))
+	assert.Equal(t, "600", req.header.Get("TTL"))
+	assert.Equal(t, "high", req.header.Get("Urgency"))
+	assert.True(t, strings.HasPrefix(req.header.Get("Authorization"), "vapid t="))
+
+	delivered, _ := store.counts(1)
+	assert.Equal(t, 1, delivered)
+	_, failed := store.counts(5)
+	assert.Equal(t, 1, failed)
+	_, err = store.GetWebPushSubscription(4)
+	assert.Error(t, err, "subscriptions the push service no longer knows are removed")
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/httpcontroller"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
+	"github.com/tphakala/birdnet-go/internal/logging"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+	"github.com/tphakala/birdnet-go/internal/resources"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
 )
 
@@ -111,6 +114,29 @@ func mainWithExitCode() int {
 	fmt.Printf("🐦 \033[37mBirdNET-Go %s (built: %s), using config file: %s\033[0m\n",
 		settings.Version, settings.BuildDate, viper.ConfigFileUsed())
 
+	// Honor container CPU and memory limits instead of the host resources
+	if limits := resources.Detect(); limits.InContainer && (limits.CPUQuota > 0 || limits.MemoryLimit > 0) {
+		memory := "unlimited"
+		if limits.MemoryLimit > 0 {
+			memory = fmt.Sprintf("%d MiB", limits.MemoryLimit/(1024*1024))
+		}
+		fmt.Printf("📦 Container limits: using %d of %d CPUs, memory %s\n", limits.CPUs, limits.HostCPUs, memory)
+		limits.ApplyMemoryLimit()
+	}
+
+	// Apply the configured anonymization policy before anything reports or logs scrubbed data
+	privacy.SetPolicy(settings.Privacy.Policy())
+
+	// Apply the configured component log levels, components registering later pick them up
+	if err := logging.ApplyComponentLevels(settings.Logging.Levels); err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying log levels: %v\n", err)
+	}
+
+	// Forward log records to the configured syslog server and systemd journal
+	if err := logging.ConfigureOutputs(settings); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring log outputs: %v\n", err)
+	}
+
 	// Initialize core systems (telemetry and notification)
 	if err := telemetry.InitializeSystem(settings); err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing core systems: %v\n", err)